@@ -17,6 +17,9 @@ type EventListener struct {
 
 	// projectName stores which project this event listener is associated with (empty for all projects).
 	projectName string
+
+	// connKey is the key of the event connection used by this listener (the project name unless replaying past events).
+	connKey     string
 	targets     []*EventTarget
 	targetsLock sync.Mutex
 }
@@ -83,11 +86,11 @@ func (e *EventListener) Disconnect() {
 	}
 
 	// Locate and remove it from the global list
-	for i, listener := range e.r.eventListeners[e.projectName] {
+	for i, listener := range e.r.eventListeners[e.connKey] {
 		if listener == e {
-			copy(e.r.eventListeners[e.projectName][i:], e.r.eventListeners[e.projectName][i+1:])
-			e.r.eventListeners[e.projectName][len(e.r.eventListeners[e.projectName])-1] = nil
-			e.r.eventListeners[e.projectName] = e.r.eventListeners[e.projectName][:len(e.r.eventListeners[e.projectName])-1]
+			copy(e.r.eventListeners[e.connKey][i:], e.r.eventListeners[e.connKey][i+1:])
+			e.r.eventListeners[e.connKey][len(e.r.eventListeners[e.connKey])-1] = nil
+			e.r.eventListeners[e.connKey] = e.r.eventListeners[e.connKey][:len(e.r.eventListeners[e.connKey])-1]
 			break
 		}
 	}
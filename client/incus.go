@@ -27,13 +27,14 @@ type ProtocolIncus struct {
 	ctxConnected       context.Context
 	ctxConnectedCancel context.CancelFunc
 
-	// eventConns contains event listener connections associated to a project name (or empty for all projects).
+	// eventConns contains event listener connections associated to a project name (or empty for all projects),
+	// or to a single listener replaying past events.
 	eventConns map[string]*websocket.Conn
 
 	// eventConnsLock controls write access to the eventConns.
 	eventConnsLock sync.Mutex
 
-	// eventListeners is a slice of event listeners associated to a project name (or empty for all projects).
+	// eventListeners is a slice of event listeners associated to an event connection key.
 	eventListeners     map[string][]*EventListener
 	eventListenersLock sync.Mutex
 
@@ -455,10 +456,21 @@ func (r *ProtocolIncus) rawWebsocket(url string) (*websocket.Conn, error) {
 		NetDialTLSContext: httpTransport.DialTLSContext,
 		NetDialContext:    httpTransport.DialContext,
 		TLSClientConfig:   httpTransport.TLSClientConfig,
-		Proxy:             httpTransport.Proxy,
 		HandshakeTimeout:  time.Second * 5,
 	}
 
+	// The websocket dialer would use the TLS dial function to reach the proxy itself,
+	// so let it do the TLS handshake through the tunnel instead.
+	proxyURL, err := websocketProxyURL(httpTransport.Proxy, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if proxyURL != nil {
+		dialer.NetDialTLSContext = nil
+		dialer.Proxy = http.ProxyURL(proxyURL)
+	}
+
 	// Create temporary http.Request using the http url, not the ws one, so that we can add the client headers
 	// for the websocket request.
 	req := &http.Request{URL: &r.httpBaseURL, Header: http.Header{}}
@@ -488,6 +500,41 @@ func (r *ProtocolIncus) rawWebsocket(url string) (*websocket.Conn, error) {
 	return conn, nil
 }
 
+// websocketProxyURL returns the proxy to use for a websocket URL, adapted to the schemes supported by the websocket dialer.
+// The websocket SOCKS5 dialer always lets the proxy resolve the target, so socks5h proxies are handled as socks5.
+func websocketProxyURL(proxy func(*http.Request) (*neturl.URL, error), url string) (*neturl.URL, error) {
+	if proxy == nil {
+		return nil, nil
+	}
+
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return nil, err
+	}
+
+	// Proxy functions select the proxy based on the HTTP scheme.
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+
+	proxyURL, err := proxy(&http.Request{URL: u})
+	if err != nil || proxyURL == nil {
+		return proxyURL, err
+	}
+
+	if proxyURL.Scheme == "socks5h" {
+		wsProxyURL := *proxyURL
+		wsProxyURL.Scheme = "socks5"
+
+		return &wsProxyURL, nil
+	}
+
+	return proxyURL, nil
+}
+
 // websocket generates a websocket URL based on the provided path and the base URL of the ProtocolIncus receiver.
 // It then leverages the rawWebsocket method to establish and return a websocket connection to the generated URL.
 func (r *ProtocolIncus) websocket(path string) (*websocket.Conn, error) {
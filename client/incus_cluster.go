@@ -189,6 +189,22 @@ func (r *ProtocolIncus) GetClusterMemberState(name string) (*api.ClusterMemberSt
 	return &state, etag, err
 }
 
+// GetClusterDatabase gets the state of the cluster database.
+func (r *ProtocolIncus) GetClusterDatabase() (*api.ClusterDatabase, error) {
+	err := r.CheckExtension("cluster_database_status")
+	if err != nil {
+		return nil, err
+	}
+
+	database := api.ClusterDatabase{}
+	_, err = r.queryStruct("GET", "/cluster/database", nil, "", &database)
+	if err != nil {
+		return nil, err
+	}
+
+	return &database, nil
+}
+
 // UpdateClusterMemberState evacuates or restores a cluster member.
 func (r *ProtocolIncus) UpdateClusterMemberState(name string, state api.ClusterMemberStatePost) (Operation, error) {
 	if !r.HasExtension("clustering_evacuation") {
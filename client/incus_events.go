@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	neturl "net/url"
 	"slices"
 	"time"
 
@@ -15,7 +16,7 @@ import (
 // Event handling functions
 
 // getEvents connects to the Incus monitoring interface.
-func (r *ProtocolIncus) getEvents(allProjects bool) (*EventListener, error) {
+func (r *ProtocolIncus) getEvents(allProjects bool, since string) (*EventListener, error) {
 	// Prevent anything else from interacting with the listeners
 	r.eventListenersLock.Lock()
 	defer r.eventListenersLock.Unlock()
@@ -38,21 +39,35 @@ func (r *ProtocolIncus) getEvents(allProjects bool) (*EventListener, error) {
 		listener.projectName = connInfo.Project
 	}
 
+	// Listeners replaying past events need their own connection.
+	listener.connKey = listener.projectName
+	if since != "" {
+		listener.connKey = fmt.Sprintf("%s?since=%s&listener=%p", listener.projectName, since, &listener)
+	}
+
 	// There is an existing Go routine for the required project filter, so just add another target.
-	if r.eventListeners[listener.projectName] != nil {
-		r.eventListeners[listener.projectName] = append(r.eventListeners[listener.projectName], &listener)
+	if r.eventListeners[listener.connKey] != nil {
+		r.eventListeners[listener.connKey] = append(r.eventListeners[listener.connKey], &listener)
 		return &listener, nil
 	}
 
 	// Setup a new connection with Incus
-	var url string
-	var err error
+	values := neturl.Values{}
 	if allProjects {
-		url, err = r.setQueryAttributes("/events?all-projects=true")
-	} else {
-		url, err = r.setQueryAttributes("/events")
+		values.Set("all-projects", "true")
+	}
+
+	if since != "" {
+		values.Set("since", since)
+	}
+
+	path := "/events"
+	if len(values) > 0 {
+		path += "?" + values.Encode()
 	}
 
+	url, err := r.setQueryAttributes(path)
+
 	if err != nil {
 		return nil, err
 	}
@@ -64,11 +79,11 @@ func (r *ProtocolIncus) getEvents(allProjects bool) (*EventListener, error) {
 	}
 
 	r.eventConnsLock.Lock()
-	r.eventConns[listener.projectName] = wsConn // Save for others to use.
+	r.eventConns[listener.connKey] = wsConn // Save for others to use.
 	r.eventConnsLock.Unlock()
 
 	// Initialize the event listener list if we were able to connect to the events websocket.
-	r.eventListeners[listener.projectName] = []*EventListener{&listener}
+	r.eventListeners[listener.connKey] = []*EventListener{&listener}
 
 	// Spawn a watcher that will close the websocket connection after all
 	// listeners are gone.
@@ -83,14 +98,14 @@ func (r *ProtocolIncus) getEvents(allProjects bool) (*EventListener, error) {
 
 			r.eventListenersLock.Lock()
 			r.eventConnsLock.Lock()
-			if len(r.eventListeners[listener.projectName]) == 0 {
+			if len(r.eventListeners[listener.connKey]) == 0 {
 				// We don't need the connection anymore, disconnect and clear.
-				if r.eventListeners[listener.projectName] != nil {
-					_ = r.eventConns[listener.projectName].Close()
-					delete(r.eventConns, listener.projectName)
+				if r.eventListeners[listener.connKey] != nil {
+					_ = r.eventConns[listener.connKey].Close()
+					delete(r.eventConns, listener.connKey)
 				}
 
-				r.eventListeners[listener.projectName] = nil
+				r.eventListeners[listener.connKey] = nil
 				r.eventListenersLock.Unlock()
 				r.eventConnsLock.Unlock()
 
@@ -112,14 +127,14 @@ func (r *ProtocolIncus) getEvents(allProjects bool) (*EventListener, error) {
 				defer r.eventListenersLock.Unlock()
 
 				// Tell all the current listeners about the failure
-				for _, listener := range r.eventListeners[listener.projectName] {
+				for _, listener := range r.eventListeners[listener.connKey] {
 					listener.err = err
 					listener.ctxCancel()
 				}
 
 				// And remove them all from the list so that when watcher routine runs it will
 				// close the websocket connection.
-				r.eventListeners[listener.projectName] = nil
+				r.eventListeners[listener.connKey] = nil
 
 				close(stopCh) // Instruct watcher go routine to cleanup.
 
@@ -140,7 +155,7 @@ func (r *ProtocolIncus) getEvents(allProjects bool) (*EventListener, error) {
 
 			// Send the message to all handlers
 			r.eventListenersLock.Lock()
-			for _, listener := range r.eventListeners[listener.projectName] {
+			for _, listener := range r.eventListeners[listener.connKey] {
 				listener.targetsLock.Lock()
 				for _, target := range listener.targets {
 					if target.types != nil && !slices.Contains(target.types, event.Type) {
@@ -162,12 +177,34 @@ func (r *ProtocolIncus) getEvents(allProjects bool) (*EventListener, error) {
 
 // GetEvents gets the events for the project defined on the client.
 func (r *ProtocolIncus) GetEvents() (*EventListener, error) {
-	return r.getEvents(false)
+	return r.getEvents(false, "")
 }
 
 // GetEventsAllProjects gets events for all projects.
 func (r *ProtocolIncus) GetEventsAllProjects() (*EventListener, error) {
-	return r.getEvents(true)
+	return r.getEvents(true, "")
+}
+
+// GetEventsSince gets the events for the project defined on the client, starting with the events
+// from the server's event history which followed the given event cursor or RFC3339 timestamp.
+func (r *ProtocolIncus) GetEventsSince(since string) (*EventListener, error) {
+	err := r.CheckExtension("event_history")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.getEvents(false, since)
+}
+
+// GetEventsAllProjectsSince gets events for all projects, starting with the events
+// from the server's event history which followed the given event cursor or RFC3339 timestamp.
+func (r *ProtocolIncus) GetEventsAllProjectsSince(since string) (*EventListener, error) {
+	err := r.CheckExtension("event_history")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.getEvents(true, since)
 }
 
 // SendEvent send an event to the server via the client's event listener connection.
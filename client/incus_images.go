@@ -438,6 +438,34 @@ func (r *ProtocolIncus) GetImageAliasArchitectures(imageType string, name string
 	return map[string]*api.ImageAliasesEntry{img.Architecture: alias}, nil
 }
 
+// BuildImage requests that Incus builds a new image from a recipe, using the provided image as the base.
+func (r *ProtocolIncus) BuildImage(source ImageServer, image api.Image, build api.ImageBuildPost) (Operation, error) {
+	if !r.HasExtension("image_build") {
+		return nil, fmt.Errorf("The server is missing the required \"image_build\" API extension")
+	}
+
+	info, err := r.getSourceImageConnectionInfo(source, image, &build.Recipe.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	// Have the server retrieve the base image if it's not local.
+	if info != nil {
+		if len(info.Addresses) == 0 {
+			return nil, fmt.Errorf("The source server isn't listening on the network")
+		}
+
+		build.Recipe.Source.Server = info.Addresses[0]
+	}
+
+	op, _, err := r.queryOperation("POST", "/images/build", build, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // CreateImage requests that Incus creates, copies or import a new image.
 func (r *ProtocolIncus) CreateImage(image api.ImagesPost, args *ImageCreateArgs) (Operation, error) {
 	if image.CompressionAlgorithm != "" {
@@ -592,6 +620,10 @@ func (r *ProtocolIncus) CreateImage(image api.ImagesPost, args *ImageCreateArgs)
 		req.Header.Set("X-Incus-aliases", imgProfiles.Encode())
 	}
 
+	if image.CompressionAlgorithm != "" {
+		req.Header.Set("X-Incus-compression_algorithm", image.CompressionAlgorithm)
+	}
+
 	// Set the user agent
 	if image.Source != nil && image.Source.Fingerprint != "" && image.Source.Secret != "" && image.Source.Mode == "push" {
 		// Set fingerprint
@@ -754,6 +786,16 @@ func (r *ProtocolIncus) CopyImage(source ImageServer, image api.Image, args *Ima
 		image.Profiles = nil
 	}
 
+	// Handle re-compression on the target.
+	compression := ""
+	if args != nil && args.Compression != "" {
+		if !r.HasExtension("image_copy_compression") {
+			return nil, fmt.Errorf("The server is missing the required \"image_copy_compression\" API extension")
+		}
+
+		compression = args.Compression
+	}
+
 	// Get source server connection information
 	info, err := source.GetConnectionInfo()
 	if err != nil {
@@ -786,6 +828,7 @@ func (r *ProtocolIncus) CopyImage(source ImageServer, image api.Image, args *Ima
 		imagesPost.ExpiresAt = image.ExpiresAt
 		imagesPost.Properties = image.Properties
 		imagesPost.Public = args.Public
+		imagesPost.CompressionAlgorithm = compression
 
 		// Receive token from target server. This token is later passed to the source which will use
 		// it, together with the URL and certificate, to connect to the target.
@@ -871,6 +914,7 @@ func (r *ProtocolIncus) CopyImage(source ImageServer, image api.Image, args *Ima
 		imagePost := api.ImagesPost{}
 		imagePost.Public = args.Public
 		imagePost.Profiles = image.Profiles
+		imagePost.CompressionAlgorithm = compression
 
 		imagePost.Aliases = args.Aliases
 		if args.CopyAliases {
@@ -919,11 +963,20 @@ func (r *ProtocolIncus) CopyImage(source ImageServer, image api.Image, args *Ima
 				return
 			}
 
+			// Re-compressing the image changes its fingerprint.
+			fingerprint := image.Fingerprint
+			if compression != "" {
+				newFingerprint, ok := rop.targetOp.Get().Metadata["fingerprint"].(string)
+				if ok {
+					fingerprint = newFingerprint
+				}
+			}
+
 			// Apply the aliases.
 			for _, entry := range imagePost.Aliases {
 				alias := api.ImageAliasesPost{}
 				alias.Name = entry.Name
-				alias.Target = image.Fingerprint
+				alias.Target = fingerprint
 
 				err := r.CreateImageAlias(alias)
 				if err != nil {
@@ -972,6 +1025,7 @@ func (r *ProtocolIncus) CopyImage(source ImageServer, image api.Image, args *Ima
 		req.Aliases = args.Aliases
 		req.AutoUpdate = args.AutoUpdate
 		req.Public = args.Public
+		req.CompressionAlgorithm = compression
 
 		if args.CopyAliases {
 			req.Aliases = image.Aliases
@@ -1021,6 +1075,23 @@ func (r *ProtocolIncus) RefreshImage(fingerprint string) (Operation, error) {
 	return op, nil
 }
 
+// GetImageRefresh returns the latest version of the image available from its source, without refreshing it.
+func (r *ProtocolIncus) GetImageRefresh(fingerprint string) (*api.Image, error) {
+	if !r.HasExtension("image_refresh_dry_run") {
+		return nil, fmt.Errorf("The server is missing the required \"image_refresh_dry_run\" API extension")
+	}
+
+	image := api.Image{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/images/%s/refresh", url.PathEscape(fingerprint)), nil, "", &image)
+	if err != nil {
+		return nil, err
+	}
+
+	return &image, nil
+}
+
 // CreateImageSecret requests that Incus issues a temporary image secret.
 func (r *ProtocolIncus) CreateImageSecret(fingerprint string) (Operation, error) {
 	// Send the request
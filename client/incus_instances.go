@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/websocket"
@@ -130,6 +131,35 @@ func (r *ProtocolIncus) GetInstancesWithFilter(instanceType api.InstanceType, fi
 	return instances, nil
 }
 
+// GetInstancesWithLabels returns a list of instances matching the label selector and filters.
+func (r *ProtocolIncus) GetInstancesWithLabels(instanceType api.InstanceType, selector string, filters []string) ([]api.Instance, error) {
+	if !r.HasExtension("instance_labels") {
+		return nil, fmt.Errorf("The server is missing the required \"instance_labels\" API extension")
+	}
+
+	instances := []api.Instance{}
+
+	path, v, err := r.instanceTypeToPath(instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Set("recursion", "1")
+	v.Set("labels", selector)
+
+	if len(filters) > 0 {
+		v.Set("filter", parseFilters(filters))
+	}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s?%s", path, v.Encode()), nil, "", &instances)
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
 // GetInstancesAllProjects returns a list of instances from all projects.
 func (r *ProtocolIncus) GetInstancesAllProjects(instanceType api.InstanceType) ([]api.Instance, error) {
 	instances := []api.Instance{}
@@ -185,6 +215,36 @@ func (r *ProtocolIncus) GetInstancesAllProjectsWithFilter(instanceType api.Insta
 	return instances, nil
 }
 
+// GetInstancesAllProjectsWithLabels returns a list of instances from all projects matching the label selector and filters.
+func (r *ProtocolIncus) GetInstancesAllProjectsWithLabels(instanceType api.InstanceType, selector string, filters []string) ([]api.Instance, error) {
+	if !r.HasExtension("instance_labels") {
+		return nil, fmt.Errorf("The server is missing the required \"instance_labels\" API extension")
+	}
+
+	instances := []api.Instance{}
+
+	path, v, err := r.instanceTypeToPath(instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Set("recursion", "1")
+	v.Set("all-projects", "true")
+	v.Set("labels", selector)
+
+	if len(filters) > 0 {
+		v.Set("filter", parseFilters(filters))
+	}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s?%s", path, v.Encode()), nil, "", &instances)
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
 // UpdateInstances updates all instances to match the requested state.
 func (r *ProtocolIncus) UpdateInstances(state api.InstancesPut, ETag string) (Operation, error) {
 	path, v, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -380,6 +440,65 @@ func (r *ProtocolIncus) GetInstancesFullWithFilter(instanceType api.InstanceType
 	return instances, nil
 }
 
+// GetInstancesFullWithLabels returns a list of instances including snapshots, backups and state matching the label selector and filters.
+func (r *ProtocolIncus) GetInstancesFullWithLabels(instanceType api.InstanceType, selector string, filters []string) ([]api.InstanceFull, error) {
+	if !r.HasExtension("instance_labels") {
+		return nil, fmt.Errorf("The server is missing the required \"instance_labels\" API extension")
+	}
+
+	instances := []api.InstanceFull{}
+
+	path, v, err := r.instanceTypeToPath(instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Set("recursion", "2")
+	v.Set("labels", selector)
+
+	if len(filters) > 0 {
+		v.Set("filter", parseFilters(filters))
+	}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s?%s", path, v.Encode()), nil, "", &instances)
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// GetInstancesFullAllProjectsWithLabels returns a list of instances including snapshots, backups and state from all projects matching the label selector and filters.
+func (r *ProtocolIncus) GetInstancesFullAllProjectsWithLabels(instanceType api.InstanceType, selector string, filters []string) ([]api.InstanceFull, error) {
+	if !r.HasExtension("instance_labels") {
+		return nil, fmt.Errorf("The server is missing the required \"instance_labels\" API extension")
+	}
+
+	instances := []api.InstanceFull{}
+
+	path, v, err := r.instanceTypeToPath(instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Set("recursion", "2")
+	v.Set("all-projects", "true")
+	v.Set("labels", selector)
+
+	if len(filters) > 0 {
+		v.Set("filter", parseFilters(filters))
+	}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s?%s", path, v.Encode()), nil, "", &instances)
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
 // GetInstancesFullAllProjects returns a list of instances including snapshots, backups and state from all projects.
 func (r *ProtocolIncus) GetInstancesFullAllProjects(instanceType api.InstanceType) ([]api.InstanceFull, error) {
 	instances := []api.InstanceFull{}
@@ -521,7 +640,7 @@ func (r *ProtocolIncus) CreateInstanceFromBackup(args InstanceBackupArgs) (Opera
 		return nil, err
 	}
 
-	if args.PoolName == "" && args.Name == "" {
+	if args.PoolName == "" && args.Name == "" && args.EncryptionPassphrase == "" && !args.ConfigOnly && !args.InstanceOnly && args.Snapshot == "" {
 		// Send the request
 		op, _, err := r.queryOperation("POST", path, args.BackupFile, "")
 		if err != nil {
@@ -539,6 +658,14 @@ func (r *ProtocolIncus) CreateInstanceFromBackup(args InstanceBackupArgs) (Opera
 		return nil, fmt.Errorf(`The server is missing the required "backup_override_name" API extension`)
 	}
 
+	if args.EncryptionPassphrase != "" && !r.HasExtension("backup_encryption") {
+		return nil, fmt.Errorf(`The server is missing the required "backup_encryption" API extension`)
+	}
+
+	if (args.ConfigOnly || args.InstanceOnly || args.Snapshot != "") && !r.HasExtension("backup_import_selective") {
+		return nil, fmt.Errorf(`The server is missing the required "backup_import_selective" API extension`)
+	}
+
 	// Prepare the HTTP request
 	reqURL, err := r.setQueryAttributes(fmt.Sprintf("%s/1.0%s", r.httpBaseURL.String(), path))
 	if err != nil {
@@ -560,6 +687,22 @@ func (r *ProtocolIncus) CreateInstanceFromBackup(args InstanceBackupArgs) (Opera
 		req.Header.Set("X-Incus-name", args.Name)
 	}
 
+	if args.EncryptionPassphrase != "" {
+		req.Header.Set("X-Incus-encryption-passphrase", args.EncryptionPassphrase)
+	}
+
+	if args.ConfigOnly {
+		req.Header.Set("X-Incus-config-only", "true")
+	}
+
+	if args.InstanceOnly {
+		req.Header.Set("X-Incus-instance-only", "true")
+	}
+
+	if args.Snapshot != "" {
+		req.Header.Set("X-Incus-snapshot", args.Snapshot)
+	}
+
 	// Send the request
 	resp, err := r.DoHTTP(req)
 	if err != nil {
@@ -792,6 +935,10 @@ func (r *ProtocolIncus) CopyInstance(source InstanceServer, instance api.Instanc
 			}
 		}
 
+		if args.Stateful && !r.HasExtension("instance_copy_stateful") {
+			return nil, fmt.Errorf("The server is missing the required \"instance_copy_stateful\" API extension")
+		}
+
 		// Allow overriding the target name
 		if args.Name != "" {
 			req.Name = args.Name
@@ -802,6 +949,7 @@ func (r *ProtocolIncus) CopyInstance(source InstanceServer, instance api.Instanc
 		req.Source.Refresh = args.Refresh
 		req.Source.RefreshExcludeOlder = args.RefreshExcludeOlder
 		req.Source.AllowInconsistent = args.AllowInconsistent
+		req.Source.Stateful = args.Stateful
 	}
 
 	if req.Source.Live {
@@ -853,6 +1001,10 @@ func (r *ProtocolIncus) CopyInstance(source InstanceServer, instance api.Instanc
 		return &rop, nil
 	}
 
+	if req.Source.Stateful {
+		return nil, fmt.Errorf("Stateful copies are only supported within the same server")
+	}
+
 	// Source request
 	sourceReq := api.InstancePost{
 		Migration:         true,
@@ -2412,6 +2564,48 @@ func (r *ProtocolIncus) UpdateInstanceMetadata(name string, metadata api.ImageMe
 	return nil
 }
 
+// GetInstanceLabels returns the instance labels.
+func (r *ProtocolIncus) GetInstanceLabels(name string) (*api.InstanceLabels, string, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !r.HasExtension("instance_labels") {
+		return nil, "", fmt.Errorf("The server is missing the required \"instance_labels\" API extension")
+	}
+
+	labels := api.InstanceLabels{}
+
+	uri := fmt.Sprintf("%s/%s/labels", path, url.PathEscape(name))
+	etag, err := r.queryStruct("GET", uri, nil, "", &labels)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &labels, etag, err
+}
+
+// UpdateInstanceLabels replaces the instance labels.
+func (r *ProtocolIncus) UpdateInstanceLabels(name string, labels api.InstanceLabels, ETag string) error {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	if !r.HasExtension("instance_labels") {
+		return fmt.Errorf("The server is missing the required \"instance_labels\" API extension")
+	}
+
+	uri := fmt.Sprintf("%s/%s/labels", path, url.PathEscape(name))
+	_, _, err = r.query("PUT", uri, labels, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetInstanceTemplateFiles returns the list of names of template files for a instance.
 func (r *ProtocolIncus) GetInstanceTemplateFiles(instanceName string) ([]string, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2434,6 +2628,28 @@ func (r *ProtocolIncus) GetInstanceTemplateFiles(instanceName string) ([]string,
 	return templates, nil
 }
 
+// GetInstanceTemplates returns the list of names and triggers of template files for a instance.
+func (r *ProtocolIncus) GetInstanceTemplates(instanceName string) ([]api.InstanceTemplate, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.HasExtension("instance_template_triggers") {
+		return nil, fmt.Errorf("The server is missing the required \"instance_template_triggers\" API extension")
+	}
+
+	templates := []api.InstanceTemplate{}
+
+	uri := fmt.Sprintf("%s/%s/metadata/templates?recursion=1", path, url.PathEscape(instanceName))
+	_, err = r.queryStruct("GET", uri, nil, "", &templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
 // GetInstanceTemplateFile returns the content of a template file for a instance.
 func (r *ProtocolIncus) GetInstanceTemplateFile(instanceName string, templateName string) (io.ReadCloser, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2520,6 +2736,67 @@ func (r *ProtocolIncus) CreateInstanceTemplateFile(instanceName string, template
 	return err
 }
 
+// CreateInstanceTemplate creates or replaces a template file for a instance and optionally sets the path it's rendered to.
+func (r *ProtocolIncus) CreateInstanceTemplate(instanceName string, templateName string, content io.ReadSeeker, trigger *api.InstanceTemplateTrigger) error {
+	if trigger == nil {
+		return r.CreateInstanceTemplateFile(instanceName, templateName, content)
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	if !r.HasExtension("instance_template_triggers") {
+		return fmt.Errorf("The server is missing the required \"instance_template_triggers\" API extension")
+	}
+
+	values := url.Values{}
+	values.Set("path", templateName)
+	values.Set("render_path", trigger.Path)
+	values.Set("when", strings.Join(trigger.When, ","))
+	values.Set("create_only", strconv.FormatBool(trigger.CreateOnly))
+
+	uri := fmt.Sprintf("%s/1.0%s/%s/metadata/templates?%s", r.httpBaseURL.String(), path, url.PathEscape(instanceName), values.Encode())
+
+	uri, err = r.setQueryAttributes(uri)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", uri, content)
+	if err != nil {
+		return err
+	}
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		_, err := content.Seek(0, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return io.NopCloser(content), nil
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	// Send the request
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return err
+	}
+
+	// Check the return value for a cleaner error
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := incusParseResponse(resp)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // DeleteInstanceTemplateFile deletes a template file for a instance.
 func (r *ProtocolIncus) DeleteInstanceTemplateFile(name string, templateName string) error {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2729,7 +3006,7 @@ func (r *ProtocolIncus) ConsoleInstanceDynamic(instanceName string, console api.
 // GetInstanceConsoleLog requests that Incus attaches to the console device of a instance.
 //
 // Note that it's the caller's responsibility to close the returned ReadCloser.
-func (r *ProtocolIncus) GetInstanceConsoleLog(instanceName string, _ *InstanceConsoleLogArgs) (io.ReadCloser, error) {
+func (r *ProtocolIncus) GetInstanceConsoleLog(instanceName string, args *InstanceConsoleLogArgs) (io.ReadCloser, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
 	if err != nil {
 		return nil, err
@@ -2742,6 +3019,14 @@ func (r *ProtocolIncus) GetInstanceConsoleLog(instanceName string, _ *InstanceCo
 	// Prepare the HTTP request
 	uri := fmt.Sprintf("%s/1.0%s/%s/console", r.httpBaseURL.String(), path, url.PathEscape(instanceName))
 
+	if args != nil && args.Type != "" {
+		if args.Type == "vga" && !r.HasExtension("instance_console_screenshot") {
+			return nil, fmt.Errorf("The server is missing the required \"instance_console_screenshot\" API extension")
+		}
+
+		uri += "?type=" + url.QueryEscape(args.Type)
+	}
+
 	uri, err = r.setQueryAttributes(uri)
 	if err != nil {
 		return nil, err
@@ -2866,6 +3151,10 @@ func (r *ProtocolIncus) CreateInstanceBackup(instanceName string, backup api.Ins
 		return nil, fmt.Errorf("The server is missing the required \"container_backup\" API extension")
 	}
 
+	if backup.EncryptionPassphrase != "" && !r.HasExtension("backup_encryption") {
+		return nil, fmt.Errorf(`The server is missing the required "backup_encryption" API extension`)
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/backups", path, url.PathEscape(instanceName)), backup, "")
 	if err != nil {
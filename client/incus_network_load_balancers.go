@@ -68,6 +68,11 @@ func (r *ProtocolIncus) CreateNetworkLoadBalancer(networkName string, loadBalanc
 		return err
 	}
 
+	err = r.checkNetworkLoadBalancerBackends(loadBalancer.Backends)
+	if err != nil {
+		return err
+	}
+
 	// Send the request.
 	u := api.NewURL().Path("networks", networkName, "load-balancers")
 	_, _, err = r.query("POST", u.String(), loadBalancer, "")
@@ -85,6 +90,11 @@ func (r *ProtocolIncus) UpdateNetworkLoadBalancer(networkName string, listenAddr
 		return err
 	}
 
+	err = r.checkNetworkLoadBalancerBackends(loadBalancer.Backends)
+	if err != nil {
+		return err
+	}
+
 	// Send the request.
 	u := api.NewURL().Path("networks", networkName, "load-balancers", listenAddress)
 	_, _, err = r.query("PUT", u.String(), loadBalancer, ETag)
@@ -130,3 +140,14 @@ func (r *ProtocolIncus) GetNetworkLoadBalancerState(networkName string, listenAd
 
 	return &lbState, nil
 }
+
+// checkNetworkLoadBalancerBackends checks that the server supports the backend settings in use.
+func (r *ProtocolIncus) checkNetworkLoadBalancerBackends(backends []api.NetworkLoadBalancerBackend) error {
+	for _, backend := range backends {
+		if backend.Weight != nil {
+			return r.CheckExtension("network_load_balancer_weight")
+		}
+	}
+
+	return nil
+}
@@ -133,6 +133,22 @@ func (r *ProtocolIncus) GetNetworkLeases(name string) ([]api.NetworkLease, error
 	return leases, nil
 }
 
+// DeleteNetworkLease releases the network's DHCP leases matching the IP or MAC address.
+func (r *ProtocolIncus) DeleteNetworkLease(name string, address string) error {
+	err := r.CheckExtension("network_lease_delete")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("DELETE", fmt.Sprintf("/networks/%s/leases/%s", url.PathEscape(name), url.PathEscape(address)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetNetworkState returns metrics and information on the running network.
 func (r *ProtocolIncus) GetNetworkState(name string) (*api.NetworkState, error) {
 	if !r.HasExtension("network_state") {
@@ -150,6 +166,24 @@ func (r *ProtocolIncus) GetNetworkState(name string) (*api.NetworkState, error)
 	return &state, nil
 }
 
+// TraceNetwork traces the path of a packet through the network.
+func (r *ProtocolIncus) TraceNetwork(name string, req api.NetworkTracePost) (*api.NetworkTrace, error) {
+	err := r.CheckExtension("network_ovn_trace")
+	if err != nil {
+		return nil, err
+	}
+
+	trace := api.NetworkTrace{}
+
+	// Send the request
+	_, err = r.queryStruct("POST", fmt.Sprintf("/networks/%s/trace", url.PathEscape(name)), req, "", &trace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &trace, nil
+}
+
 // CreateNetwork defines a new network using the provided Network struct.
 func (r *ProtocolIncus) CreateNetwork(network api.NetworksPost) error {
 	if !r.HasExtension("network") {
@@ -139,3 +139,19 @@ func (r *ProtocolIncus) DeleteOperation(uuid string) error {
 
 	return nil
 }
+
+// UpdateOperation updates the modifiable fields of a running operation, like its bandwidth limit.
+func (r *ProtocolIncus) UpdateOperation(uuid string, operation api.OperationPut) error {
+	err := r.CheckExtension("operation_throttle")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("PUT", fmt.Sprintf("/operations/%s", url.PathEscape(uuid)), operation, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
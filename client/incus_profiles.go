@@ -136,6 +136,21 @@ func (r *ProtocolIncus) RenameProfile(name string, profile api.ProfilePost) erro
 	return nil
 }
 
+// AssignProfile adds the profile to all the provided instances at once.
+func (r *ProtocolIncus) AssignProfile(name string, req api.ProfileAssignPost) (Operation, error) {
+	if !r.HasExtension("profile_assign_bulk") {
+		return nil, fmt.Errorf("The server is missing the required \"profile_assign_bulk\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/profiles/%s/assign", url.PathEscape(name)), req, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // DeleteProfile deletes a profile.
 func (r *ProtocolIncus) DeleteProfile(name string) error {
 	// Send the request
@@ -1,6 +1,7 @@
 package incus
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 
@@ -83,11 +84,28 @@ func (r *ProtocolIncus) UpdateStoragePool(name string, pool api.StoragePoolPut,
 	}
 
 	// Send the request
-	_, _, err := r.query("PUT", fmt.Sprintf("/storage-pools/%s", url.PathEscape(name)), pool, ETag)
+	resp, _, err := r.query("PUT", fmt.Sprintf("/storage-pools/%s", url.PathEscape(name)), pool, ETag)
 	if err != nil {
 		return err
 	}
 
+	// Resizing a pool runs in the background, wait for it to complete.
+	if resp.Type == api.AsyncResponse {
+		respOperation, err := resp.MetadataAsOperation()
+		if err != nil {
+			return err
+		}
+
+		op, _, err := r.GetOperationWait(respOperation.ID, -1)
+		if err != nil {
+			return err
+		}
+
+		if op.Err != "" {
+			return errors.New(op.Err)
+		}
+	}
+
 	return nil
 }
 
@@ -122,3 +140,35 @@ func (r *ProtocolIncus) GetStoragePoolResources(name string) (*api.ResourcesStor
 
 	return &res, nil
 }
+
+// ScrubStoragePool runs a data integrity check of the storage pool.
+func (r *ProtocolIncus) ScrubStoragePool(name string) (Operation, error) {
+	err := r.CheckExtension("storage_pool_scrub")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/scrub", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// MigrateStoragePool moves all the instance and custom volumes of the storage pool to another pool.
+func (r *ProtocolIncus) MigrateStoragePool(name string, pool api.StoragePoolMigratePost) (Operation, error) {
+	err := r.CheckExtension("storage_pool_migrate")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/migrate", url.PathEscape(name)), pool, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
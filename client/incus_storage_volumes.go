@@ -828,6 +828,26 @@ func (r *ProtocolIncus) RenameStoragePoolVolume(pool string, volType string, nam
 	return nil
 }
 
+// ConvertStoragePoolVolume converts a custom storage volume to a different content type.
+func (r *ProtocolIncus) ConvertStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePost) (Operation, error) {
+	if !r.HasExtension("storage_volume_convert") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_volume_convert\" API extension")
+	}
+
+	path := fmt.Sprintf("/storage-pools/%s/volumes/%s/%s", url.PathEscape(pool), url.PathEscape(volType), url.PathEscape(name))
+
+	// The volume keeps its name.
+	volume.Name = name
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", path, volume, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // GetStorageVolumeBackupNames returns a list of volume backup names.
 func (r *ProtocolIncus) GetStorageVolumeBackupNames(pool string, volName string) ([]string, error) {
 	if !r.HasExtension("custom_volume_backup") {
@@ -885,6 +905,10 @@ func (r *ProtocolIncus) CreateStorageVolumeBackup(pool string, volName string, b
 		return nil, fmt.Errorf("The server is missing the required \"custom_volume_backup\" API extension")
 	}
 
+	if backup.EncryptionPassphrase != "" && !r.HasExtension("backup_encryption") {
+		return nil, fmt.Errorf(`The server is missing the required "backup_encryption" API extension`)
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/custom/%s/backups", url.PathEscape(pool), url.PathEscape(volName)), backup, "")
 	if err != nil {
@@ -1071,6 +1095,10 @@ func (r *ProtocolIncus) CreateStoragePoolVolumeFromBackup(pool string, args Stor
 		return nil, fmt.Errorf(`The server is missing the required "backup_override_name" API extension`)
 	}
 
+	if args.EncryptionPassphrase != "" && !r.HasExtension("backup_encryption") {
+		return nil, fmt.Errorf(`The server is missing the required "backup_encryption" API extension`)
+	}
+
 	path := fmt.Sprintf("/storage-pools/%s/volumes/custom", url.PathEscape(pool))
 
 	// Prepare the HTTP request.
@@ -1090,6 +1118,10 @@ func (r *ProtocolIncus) CreateStoragePoolVolumeFromBackup(pool string, args Stor
 		req.Header.Set("X-Incus-name", args.Name)
 	}
 
+	if args.EncryptionPassphrase != "" {
+		req.Header.Set("X-Incus-encryption-passphrase", args.EncryptionPassphrase)
+	}
+
 	// Send the request.
 	resp, err := r.DoHTTP(req)
 	if err != nil {
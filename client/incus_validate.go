@@ -0,0 +1,23 @@
+package incus
+
+import (
+	"fmt"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// ValidateConfig validates a proposed configuration without applying it.
+func (r *ProtocolIncus) ValidateConfig(req api.ConfigValidatePost) (*api.ConfigValidateResult, error) {
+	if !r.HasExtension("config_validate") {
+		return nil, fmt.Errorf("The server is missing the required \"config_validate\" API extension")
+	}
+
+	result := api.ConfigValidateResult{}
+
+	_, err := r.queryStruct("POST", "/validate", req, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
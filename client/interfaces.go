@@ -107,6 +107,10 @@ type InstanceServer interface {
 	GetInstancesFullWithFilter(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
 	GetInstancesAllProjectsWithFilter(instanceType api.InstanceType, filters []string) (instances []api.Instance, err error)
 	GetInstancesFullAllProjectsWithFilter(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
+	GetInstancesWithLabels(instanceType api.InstanceType, selector string, filters []string) (instances []api.Instance, err error)
+	GetInstancesFullWithLabels(instanceType api.InstanceType, selector string, filters []string) (instances []api.InstanceFull, err error)
+	GetInstancesAllProjectsWithLabels(instanceType api.InstanceType, selector string, filters []string) (instances []api.Instance, err error)
+	GetInstancesFullAllProjectsWithLabels(instanceType api.InstanceType, selector string, filters []string) (instances []api.InstanceFull, err error)
 	GetInstance(name string) (instance *api.Instance, ETag string, err error)
 	GetInstanceFull(name string) (instance *api.InstanceFull, ETag string, err error)
 	CreateInstance(instance api.InstancesPost) (op Operation, err error)
@@ -165,9 +169,14 @@ type InstanceServer interface {
 	GetInstanceMetadata(name string) (metadata *api.ImageMetadata, ETag string, err error)
 	UpdateInstanceMetadata(name string, metadata api.ImageMetadata, ETag string) (err error)
 
+	GetInstanceLabels(name string) (labels *api.InstanceLabels, ETag string, err error)
+	UpdateInstanceLabels(name string, labels api.InstanceLabels, ETag string) (err error)
+
 	GetInstanceTemplateFiles(instanceName string) (templates []string, err error)
+	GetInstanceTemplates(instanceName string) (templates []api.InstanceTemplate, err error)
 	GetInstanceTemplateFile(instanceName string, templateName string) (content io.ReadCloser, err error)
 	CreateInstanceTemplateFile(instanceName string, templateName string, content io.ReadSeeker) (err error)
+	CreateInstanceTemplate(instanceName string, templateName string, content io.ReadSeeker, trigger *api.InstanceTemplateTrigger) (err error)
 	DeleteInstanceTemplateFile(name string, templateName string) (err error)
 
 	GetInstanceDebugMemory(name string, format string) (rc io.ReadCloser, err error)
@@ -175,14 +184,18 @@ type InstanceServer interface {
 	// Event handling functions
 	GetEvents() (listener *EventListener, err error)
 	GetEventsAllProjects() (listener *EventListener, err error)
+	GetEventsSince(since string) (listener *EventListener, err error)
+	GetEventsAllProjectsSince(since string) (listener *EventListener, err error)
 	SendEvent(event api.Event) error
 
 	// Image functions
 	CreateImage(image api.ImagesPost, args *ImageCreateArgs) (op Operation, err error)
 	CopyImage(source ImageServer, image api.Image, args *ImageCopyArgs) (op RemoteOperation, err error)
+	BuildImage(source ImageServer, image api.Image, build api.ImageBuildPost) (op Operation, err error)
 	UpdateImage(fingerprint string, image api.ImagePut, ETag string) (err error)
 	DeleteImage(fingerprint string) (op Operation, err error)
 	RefreshImage(fingerprint string) (op Operation, err error)
+	GetImageRefresh(fingerprint string) (image *api.Image, err error)
 	CreateImageSecret(fingerprint string) (op Operation, err error)
 	CreateImageAlias(alias api.ImageAliasesPost) (err error)
 	UpdateImageAlias(name string, alias api.ImageAliasesEntryPut, ETag string) (err error)
@@ -192,6 +205,9 @@ type InstanceServer interface {
 	// Configuration metadata functions
 	GetMetadataConfiguration() (meta *api.MetadataConfiguration, err error)
 
+	// Configuration validation functions ("config_validate" API extension)
+	ValidateConfig(req api.ConfigValidatePost) (result *api.ConfigValidateResult, err error)
+
 	// Network functions ("network" API extension)
 	GetNetworkNames() (names []string, err error)
 	GetNetworks() (networks []api.Network, err error)
@@ -200,12 +216,22 @@ type InstanceServer interface {
 	GetNetworksAllProjectsWithFilter(filters []string) (networks []api.Network, err error)
 	GetNetwork(name string) (network *api.Network, ETag string, err error)
 	GetNetworkLeases(name string) (leases []api.NetworkLease, err error)
+	DeleteNetworkLease(name string, address string) (err error)
 	GetNetworkState(name string) (state *api.NetworkState, err error)
+	TraceNetwork(name string, req api.NetworkTracePost) (trace *api.NetworkTrace, err error)
 	CreateNetwork(network api.NetworksPost) (err error)
 	UpdateNetwork(name string, network api.NetworkPut, ETag string) (err error)
 	RenameNetwork(name string, network api.NetworkPost) (err error)
 	DeleteNetwork(name string) (err error)
 
+	// Network DHCP reservation functions ("network_dhcp_reservations" API extension)
+	GetNetworkDHCPReservationAddresses(networkName string) ([]string, error)
+	GetNetworkDHCPReservations(networkName string) ([]api.NetworkDHCPReservation, error)
+	GetNetworkDHCPReservation(networkName string, hwaddr string) (reservation *api.NetworkDHCPReservation, ETag string, err error)
+	CreateNetworkDHCPReservation(networkName string, reservation api.NetworkDHCPReservationsPost) error
+	UpdateNetworkDHCPReservation(networkName string, hwaddr string, reservation api.NetworkDHCPReservationPut, ETag string) (err error)
+	DeleteNetworkDHCPReservation(networkName string, hwaddr string) (err error)
+
 	// Network forward functions ("network_forward" API extension)
 	GetNetworkForwardAddresses(networkName string) ([]string, error)
 	GetNetworkForwards(networkName string) ([]api.NetworkForward, error)
@@ -290,6 +316,7 @@ type InstanceServer interface {
 	GetOperationWaitSecret(uuid string, secret string, timeout int) (op *api.Operation, ETag string, err error)
 	GetOperationWebsocket(uuid string, secret string) (conn *websocket.Conn, err error)
 	DeleteOperation(uuid string) (err error)
+	UpdateOperation(uuid string, operation api.OperationPut) (err error)
 
 	// Profile functions
 	GetProfilesAllProjects() (profiles []api.Profile, err error)
@@ -301,6 +328,7 @@ type InstanceServer interface {
 	CreateProfile(profile api.ProfilesPost) (err error)
 	UpdateProfile(name string, profile api.ProfilePut, ETag string) (err error)
 	RenameProfile(name string, profile api.ProfilePost) (err error)
+	AssignProfile(name string, req api.ProfileAssignPost) (op Operation, err error)
 	DeleteProfile(name string) (err error)
 
 	// Project functions
@@ -324,6 +352,8 @@ type InstanceServer interface {
 	CreateStoragePool(pool api.StoragePoolsPost) (err error)
 	UpdateStoragePool(name string, pool api.StoragePoolPut, ETag string) (err error)
 	DeleteStoragePool(name string) (err error)
+	ScrubStoragePool(name string) (op Operation, err error)
+	MigrateStoragePool(name string, pool api.StoragePoolMigratePost) (op Operation, err error)
 
 	// Storage bucket functions ("storage_buckets" API extension)
 	GetStoragePoolBucketNames(poolName string) ([]string, error)
@@ -361,6 +391,7 @@ type InstanceServer interface {
 	UpdateStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePut, ETag string) (err error)
 	DeleteStoragePoolVolume(pool string, volType string, name string) (err error)
 	RenameStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePost) (err error)
+	ConvertStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePost) (op Operation, err error)
 	CopyStoragePoolVolume(pool string, source InstanceServer, sourcePool string, volume api.StorageVolume, args *StoragePoolVolumeCopyArgs) (op RemoteOperation, err error)
 	MoveStoragePoolVolume(pool string, source InstanceServer, sourcePool string, volume api.StorageVolume, args *StoragePoolVolumeMoveArgs) (op RemoteOperation, err error)
 	MigrateStoragePoolVolume(pool string, volume api.StorageVolumePost) (op Operation, err error)
@@ -401,6 +432,7 @@ type InstanceServer interface {
 	UpdateClusterCertificate(certs api.ClusterCertificatePut, ETag string) (err error)
 	GetClusterMemberState(name string) (*api.ClusterMemberState, string, error)
 	UpdateClusterMemberState(name string, state api.ClusterMemberStatePost) (op Operation, err error)
+	GetClusterDatabase() (database *api.ClusterDatabase, err error)
 	GetClusterGroups() ([]api.ClusterGroup, error)
 	GetClusterGroupNames() ([]string, error)
 	RenameClusterGroup(name string, group api.ClusterGroupPost) error
@@ -528,6 +560,9 @@ type ImageCopyArgs struct {
 
 	// List of profiles to apply on the target.
 	Profiles []string
+
+	// Compression algorithm to re-compress the image with on the target
+	Compression string
 }
 
 // The StoragePoolVolumeCopyArgs struct is used to pass additional options
@@ -566,6 +601,8 @@ type StorageVolumeBackupArgs struct {
 
 	// Name to import backup as
 	Name string
+	// Passphrase to decrypt an encrypted backup with
+	EncryptionPassphrase string
 }
 
 // The InstanceBackupArgs struct is used when creating a instance from a backup.
@@ -578,6 +615,18 @@ type InstanceBackupArgs struct {
 
 	// Name to import backup as
 	Name string
+
+	// Passphrase to decrypt an encrypted backup with
+	EncryptionPassphrase string
+
+	// If set, only the instance config is restored (with an empty root volume)
+	ConfigOnly bool
+
+	// If set, none of the snapshots are restored
+	InstanceOnly bool
+
+	// If set, only this snapshot is restored
+	Snapshot string
 }
 
 // The InstanceCopyArgs struct is used to pass additional options during instance copy.
@@ -603,6 +652,10 @@ type InstanceCopyArgs struct {
 
 	// API extension: instance_allow_inconsistent_copy
 	AllowInconsistent bool
+
+	// API extension: instance_copy_stateful
+	// If set, the running state of the instance is copied into a new running instance (same server only)
+	Stateful bool
 }
 
 // The InstanceSnapshotCopyArgs struct is used to pass additional options during instance copy.
@@ -633,7 +686,10 @@ type InstanceConsoleArgs struct {
 
 // The InstanceConsoleLogArgs struct is used to pass additional options during a
 // instance console log request.
-type InstanceConsoleLogArgs struct{}
+type InstanceConsoleLogArgs struct {
+	// Type of console output, either "log" (default) or "vga" for a PNG screenshot of a VM's VGA console
+	Type string
+}
 
 // The InstanceExecArgs struct is used to pass additional options during instance exec.
 type InstanceExecArgs struct {
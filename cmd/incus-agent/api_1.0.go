@@ -25,6 +25,7 @@ var api10Cmd = APIEndpoint{
 
 var api10 = []APIEndpoint{
 	api10Cmd,
+	clockCmd,
 	execCmd,
 	eventsCmd,
 	metricsCmd,
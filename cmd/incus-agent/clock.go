@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/lxc/incus/v6/internal/server/response"
+	agentAPI "github.com/lxc/incus/v6/shared/api/agent"
+)
+
+var clockCmd = APIEndpoint{
+	Name: "clock",
+	Path: "clock",
+
+	Put: APIEndpointAction{Handler: clockPut},
+}
+
+func clockPut(d *Daemon, r *http.Request) response.Response {
+	var req agentAPI.ClockPut
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Time.IsZero() {
+		return response.BadRequest(fmt.Errorf("No time provided"))
+	}
+
+	err = osSetClock(req.Time)
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed setting the clock: %w", err))
+	}
+
+	return response.EmptySyncResponse
+}
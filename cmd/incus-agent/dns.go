@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// dnsConfig is the DNS resolver configuration currently requested by the host.
+var (
+	dnsConfig   deviceConfig.DNSConfig
+	dnsConfigMu sync.Mutex
+)
+
+// dnsLoadConfig reads the DNS configuration from DNSConfigFile in the config share and applies it.
+func dnsLoadConfig() {
+	dnsBytes, err := os.ReadFile(deviceConfig.DNSConfigFile)
+	if err != nil {
+		// Abort if configuration file does not exist (nothing to do), otherwise log and return.
+		if errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+
+		logger.Error("Could not read DNS configuration file", logger.Ctx{"err": err})
+		return
+	}
+
+	dnsConfigMu.Lock()
+	defer dnsConfigMu.Unlock()
+
+	err = json.Unmarshal(dnsBytes, &dnsConfig)
+	if err != nil {
+		logger.Error("Could not parse DNS configuration file", logger.Ctx{"err": err})
+		return
+	}
+
+	err = osApplyDNS(dnsConfig)
+	if err != nil {
+		logger.Error("Failed to apply DNS configuration", logger.Ctx{"err": err})
+	}
+
+	if dnsConfig.Hostname != "" {
+		err = osSetHostname(dnsConfig.Hostname)
+		if err != nil {
+			logger.Error("Failed to set the hostname", logger.Ctx{"err": err, "hostname": dnsConfig.Hostname})
+		}
+	}
+}
+
+// dnsUpdateConfig updates the DNS configuration following a change of one of the dns.* instance keys.
+func dnsUpdateConfig(key string, value string) {
+	dnsConfigMu.Lock()
+	defer dnsConfigMu.Unlock()
+
+	if !dnsConfigSet(&dnsConfig, key, value) {
+		return
+	}
+
+	err := osApplyDNS(dnsConfig)
+	if err != nil {
+		logger.Error("Failed to apply DNS configuration", logger.Ctx{"err": err, "key": key})
+		return
+	}
+
+	logger.Info("Applied DNS configuration", logger.Ctx{"nameservers": dnsConfig.Nameservers, "search": dnsConfig.Search})
+}
+
+// dnsConfigSet sets the value of a dns.* instance key in the DNS configuration.
+// It returns false if the key doesn't affect the DNS resolver.
+func dnsConfigSet(conf *deviceConfig.DNSConfig, key string, value string) bool {
+	switch key {
+	case "dns.nameservers":
+		conf.Nameservers = util.SplitNTrimSpace(value, ",", -1, true)
+	case "dns.search":
+		conf.Search = util.SplitNTrimSpace(value, ",", -1, true)
+	default:
+		return false
+	}
+
+	return true
+}
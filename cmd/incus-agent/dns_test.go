@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+)
+
+func TestDNSConfigSet(t *testing.T) {
+	conf := deviceConfig.DNSConfig{Hostname: "vm1"}
+
+	assert.True(t, dnsConfigSet(&conf, "dns.nameservers", "192.0.2.1, 2001:db8::1"))
+	assert.True(t, dnsConfigSet(&conf, "dns.search", "example.com"))
+	assert.Equal(t, deviceConfig.DNSConfig{Nameservers: []string{"192.0.2.1", "2001:db8::1"}, Search: []string{"example.com"}, Hostname: "vm1"}, conf)
+
+	// Unsetting a key clears its part of the configuration.
+	assert.True(t, dnsConfigSet(&conf, "dns.nameservers", ""))
+	assert.Empty(t, conf.Nameservers)
+	assert.Equal(t, []string{"example.com"}, conf.Search)
+
+	// Other keys are ignored.
+	assert.False(t, dnsConfigSet(&conf, "dns.other", "value"))
+	assert.Equal(t, deviceConfig.DNSConfig{Search: []string{"example.com"}, Hostname: "vm1"}, conf)
+}
@@ -113,7 +113,12 @@ func eventsPost(d *Daemon, r *http.Request) response.Response {
 }
 
 func eventsProcess(event api.Event) {
-	// We currently only need to react to device events.
+	if event.Type == "config" {
+		eventsProcessConfig(event)
+		return
+	}
+
+	// Other than config changes, we only need to react to device events.
 	if event.Type != "device" {
 		return
 	}
@@ -130,6 +135,18 @@ func eventsProcess(event api.Event) {
 		return
 	}
 
+	// Grow the root partition and filesystem when the root disk was resized.
+	if e.Action == "updated" && e.Config["type"] == "disk" && e.Config["path"] == "/" && e.Config["size"] != "" {
+		err = osGrowRootDisk()
+		if err != nil {
+			logger.Warn("Failed to grow the root disk", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Grew the root disk", logger.Ctx{"size": e.Config["size"]})
+		return
+	}
+
 	// Only care about device additions, we don't try to handle remove.
 	if e.Action != "added" {
 		return
@@ -164,3 +181,23 @@ func eventsProcess(event api.Event) {
 
 	logger.Infof("Mounted hotplug %q (Type: %q) to %q", mntSource, "virtiofs", e.Config["path"])
 }
+
+func eventsProcessConfig(event api.Event) {
+	type configEvent struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+
+	e := configEvent{}
+	err := json.Unmarshal(event.Metadata, &e)
+	if err != nil {
+		return
+	}
+
+	// We only handle DNS configuration changes.
+	if !strings.HasPrefix(e.Key, "dns.") {
+		return
+	}
+
+	dnsUpdateConfig(e.Key, e.Value)
+}
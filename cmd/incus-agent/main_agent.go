@@ -110,6 +110,9 @@ func (c *cmdAgent) Run(cmd *cobra.Command, args []string) error {
 
 	osReconfigureNetworkInterfaces()
 
+	// Apply the DNS configuration.
+	dnsLoadConfig()
+
 	// Load the kernel driver.
 	err = osLoadModules()
 	if err != nil {
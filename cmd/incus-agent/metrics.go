@@ -2,6 +2,9 @@ package main
 
 import (
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/lxc/incus/v6/internal/server/metrics"
 	"github.com/lxc/incus/v6/internal/server/response"
@@ -21,6 +24,10 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 	out := metrics.Metrics{}
 
+	// The server passes the mount points and MAC addresses of the devices excluded from metrics.
+	excludedMountpoints := r.URL.Query()["exclude-mountpoint"]
+	excludedHwaddrs := r.URL.Query()["exclude-hwaddr"]
+
 	diskStats, err := osGetDiskMetrics(d)
 	if err != nil {
 		logger.Warn("Failed to get disk metrics", logger.Ctx{"err": err})
@@ -28,7 +35,7 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		out.Disk = diskStats
 	}
 
-	filesystemStats, err := osGetFilesystemMetrics(d)
+	filesystemStats, err := osGetFilesystemMetrics(d, excludedMountpoints)
 	if err != nil {
 		logger.Warn("Failed to get filesystem metrics", logger.Ctx{"err": err})
 	} else {
@@ -42,7 +49,7 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		out.Memory = memStats
 	}
 
-	netStats, err := getNetworkMetrics(d)
+	netStats, err := getNetworkMetrics(d, excludedHwaddrs)
 	if err != nil {
 		logger.Warn("Failed to get network metrics", logger.Ctx{"err": err})
 	} else {
@@ -58,13 +65,21 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		out.CPU = cpuStats
 	}
 
+	// Used by the server to compute the clock offset.
+	out.Time = time.Now()
+
 	return response.SyncResponse(true, &out)
 }
 
-func getNetworkMetrics(d *Daemon) ([]metrics.NetworkMetrics, error) {
+func getNetworkMetrics(d *Daemon, excludedHwaddrs []string) ([]metrics.NetworkMetrics, error) {
 	out := []metrics.NetworkMetrics{}
 
 	for dev, state := range osGetNetworkState() {
+		// Skip interfaces excluded from metrics.
+		if slices.ContainsFunc(excludedHwaddrs, func(hwaddr string) bool { return strings.EqualFold(hwaddr, state.Hwaddr) }) {
+			continue
+		}
+
 		stats := metrics.NetworkMetrics{}
 
 		stats.ReceiveBytes = uint64(state.Counters.BytesReceived)
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the interfaces excluded from metrics are skipped.
+func TestGetNetworkMetricsExclude(t *testing.T) {
+	var excluded string
+	var hwaddr string
+	for name, state := range osGetNetworkState() {
+		if state.Hwaddr != "" {
+			excluded = name
+			hwaddr = state.Hwaddr
+			break
+		}
+	}
+
+	if hwaddr == "" {
+		t.Skip("No network interface with a MAC address")
+	}
+
+	devices := func(excludedHwaddrs []string) []string {
+		netStats, err := getNetworkMetrics(nil, excludedHwaddrs)
+		require.NoError(t, err)
+
+		names := make([]string, 0, len(netStats))
+		for _, stats := range netStats {
+			names = append(names, stats.Device)
+		}
+
+		return names
+	}
+
+	assert.Contains(t, devices(nil), excluded)
+
+	// MAC addresses are matched regardless of their case.
+	assert.NotContains(t, devices([]string{strings.ToUpper(hwaddr)}), excluded)
+}
@@ -299,7 +299,7 @@ func osGetDiskMetrics(d *Daemon) ([]metrics.DiskMetrics, error) {
 	return out, nil
 }
 
-func osGetFilesystemMetrics(d *Daemon) ([]metrics.FilesystemMetrics, error) {
+func osGetFilesystemMetrics(d *Daemon, excludedMountpoints []string) ([]metrics.FilesystemMetrics, error) {
 	mounts, err := os.ReadFile("/proc/mounts")
 	if err != nil {
 		return nil, fmt.Errorf("Failed to read /proc/mounts: %w", err)
@@ -321,6 +321,11 @@ func osGetFilesystemMetrics(d *Daemon) ([]metrics.FilesystemMetrics, error) {
 			continue
 		}
 
+		// Skip mounts excluded from metrics.
+		if slices.Contains(excludedMountpoints, fields[1]) {
+			continue
+		}
+
 		stats := metrics.FilesystemMetrics{}
 
 		stats.Mountpoint = fields[1]
@@ -655,6 +660,27 @@ func osGetOSState() *api.InstanceStateOSInfo {
 	return osInfo
 }
 
+func osGetClockState() *api.InstanceStateClock {
+	clock := &api.InstanceStateClock{Time: time.Now()}
+
+	synchronized, err := linux.ClockSynchronized()
+	if err == nil {
+		if synchronized {
+			clock.NTPStatus = "synchronized"
+		} else {
+			clock.NTPStatus = "unsynchronized"
+		}
+	}
+
+	return clock
+}
+
+func osSetClock(t time.Time) error {
+	tv := unix.NsecToTimeval(t.UnixNano())
+
+	return unix.Settimeofday(&tv)
+}
+
 // osReconfigureNetworkInterfaces checks for the existence of files under NICConfigDir in the config share.
 // Each file is named <device>.json and contains the Device Name, NIC Name, MTU and MAC address.
 func osReconfigureNetworkInterfaces() {
@@ -791,6 +817,199 @@ func osReconfigureNetworkInterfaces() {
 	}
 }
 
+// osApplyDNS configures the DNS resolver of the guest.
+func osApplyDNS(conf deviceConfig.DNSConfig) error {
+	return applyDNS("/", conf, func() error {
+		_, err := subprocess.RunCommand("systemctl", "try-restart", "systemd-resolved.service")
+		return err
+	})
+}
+
+// applyDNS configures the DNS resolver of the system found under root.
+// When systemd-resolved manages /etc/resolv.conf, a drop-in configuration file is used instead of overwriting it
+// and restartResolved is called to apply it.
+func applyDNS(root string, conf deviceConfig.DNSConfig, restartResolved func() error) error {
+	resolvConfPath := filepath.Join(root, "etc", "resolv.conf")
+
+	resolvConf, err := filepath.EvalSymlinks(resolvConfPath)
+	if err == nil && strings.HasPrefix(resolvConf, filepath.Join(root, "run", "systemd", "resolve")+"/") {
+		dropInPath := filepath.Join(root, "run", "systemd", "resolved.conf.d", "incus-agent.conf")
+
+		if len(conf.Nameservers) == 0 && len(conf.Search) == 0 {
+			err = os.Remove(dropInPath)
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+
+				return err
+			}
+		} else {
+			err = os.MkdirAll(filepath.Dir(dropInPath), 0o755)
+			if err != nil {
+				return err
+			}
+
+			var sb strings.Builder
+			sb.WriteString("# Generated by incus-agent\n[Resolve]\n")
+
+			if len(conf.Nameservers) > 0 {
+				sb.WriteString("DNS=" + strings.Join(conf.Nameservers, " ") + "\n")
+			}
+
+			if len(conf.Search) > 0 {
+				sb.WriteString("Domains=" + strings.Join(conf.Search, " ") + "\n")
+			}
+
+			err = os.WriteFile(dropInPath, []byte(sb.String()), 0o644)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = restartResolved()
+		if err != nil {
+			return fmt.Errorf("Failed to restart systemd-resolved: %w", err)
+		}
+
+		return nil
+	}
+
+	// Without systemd-resolved, leave the existing configuration alone unless we have something to set.
+	if len(conf.Nameservers) == 0 && len(conf.Search) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Generated by incus-agent\n")
+
+	for _, nameserver := range conf.Nameservers {
+		sb.WriteString("nameserver " + nameserver + "\n")
+	}
+
+	if len(conf.Search) > 0 {
+		sb.WriteString("search " + strings.Join(conf.Search, " ") + "\n")
+	}
+
+	// Write the new file next to the existing one and rename it to atomically replace it.
+	tmpPath := filepath.Join(root, "etc", ".resolv.conf.incus-agent")
+
+	err = os.WriteFile(tmpPath, []byte(sb.String()), 0o644)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpPath, resolvConfPath)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+// osSetHostname sets the hostname of the guest, both persistently and for the running system.
+func osSetHostname(hostname string) error {
+	err := os.WriteFile("/etc/hostname", []byte(hostname+"\n"), 0o644)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile("/proc/sys/kernel/hostname", []byte(hostname), 0o644)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// osGrowRootDisk grows the root partition and filesystem to fill the root disk after it was resized.
+func osGrowRootDisk() error {
+	// Find the device and filesystem backing the root filesystem.
+	out, err := subprocess.RunCommand("findmnt", "-n", "-o", "SOURCE,FSTYPE", "/")
+	if err != nil {
+		return fmt.Errorf("Failed to find the root filesystem: %w", err)
+	}
+
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return fmt.Errorf("Unexpected root filesystem details %q", strings.TrimSpace(out))
+	}
+
+	devPath, err := filepath.EvalSymlinks(fields[0])
+	if err != nil {
+		return err
+	}
+
+	fsType := fields[1]
+
+	// Check the filesystem supports online growth before touching the partition.
+	growCmd, err := osGrowFilesystemCommand(fsType, devPath, "/")
+	if err != nil {
+		return err
+	}
+
+	// Grow the partition first if the root filesystem is on one.
+	diskPath, partition, err := osBlockDevPartition("/sys/class/block", filepath.Base(devPath))
+	if err != nil {
+		return err
+	}
+
+	if diskPath != "" {
+		// growpart fails with NOCHANGE when the partition already fills the disk.
+		out, err := subprocess.RunCommand("growpart", diskPath, partition)
+		if err != nil && !strings.Contains(out, "NOCHANGE") && !strings.Contains(err.Error(), "NOCHANGE") {
+			return fmt.Errorf("Failed to grow partition %q: %w", devPath, err)
+		}
+	}
+
+	// Then grow the filesystem.
+	_, err = subprocess.RunCommand(growCmd[0], growCmd[1:]...)
+	if err != nil {
+		return fmt.Errorf("Failed to grow %q filesystem on %q: %w", fsType, devPath, err)
+	}
+
+	return nil
+}
+
+// osBlockDevPartition returns the disk path and partition number of the given block device.
+// The disk path is empty if the block device isn't a partition.
+func osBlockDevPartition(sysBlockPath string, devName string) (string, string, error) {
+	sysPath := filepath.Join(sysBlockPath, devName)
+
+	partition, err := os.ReadFile(filepath.Join(sysPath, "partition"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", "", nil
+		}
+
+		return "", "", err
+	}
+
+	// The partition is a child of its disk in sysfs.
+	partPath, err := filepath.EvalSymlinks(sysPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	return filepath.Join("/dev", filepath.Base(filepath.Dir(partPath))), strings.TrimSpace(string(partition)), nil
+}
+
+// osGrowFilesystemCommand returns the command growing the filesystem mounted on the given path.
+// Only filesystems supporting online growth are handled.
+func osGrowFilesystemCommand(fsType string, devPath string, mountPath string) ([]string, error) {
+	switch fsType {
+	case "ext4":
+		return []string{"resize2fs", devPath}, nil
+	case "xfs":
+		return []string{"xfs_growfs", mountPath}, nil
+	case "btrfs":
+		return []string{"btrfs", "filesystem", "resize", "max", mountPath}, nil
+	}
+
+	return nil, fmt.Errorf("Online growth of %q filesystems isn't supported", fsType)
+}
+
 func osGetInteractiveConsole(s *execWs) (*os.File, *os.File, error) {
 	pty, tty, err := linux.OpenPty(int64(s.uid), int64(s.gid))
 	if err != nil {
@@ -848,7 +1067,15 @@ func osHandleExecControl(control api.InstanceExecControl, s *execWs, pty io.Read
 			}
 		}
 	} else if control.Command == "signal" {
-		err := unix.Kill(cmd.Process.Pid, unix.Signal(control.Signal))
+		var err error
+
+		// Kill the whole process tree so that no process outlives a killed command.
+		if unix.Signal(control.Signal) == unix.SIGKILL {
+			err = linux.KillProcessTree(cmd.Process.Pid)
+		} else {
+			err = unix.Kill(cmd.Process.Pid, unix.Signal(control.Signal))
+		}
+
 		if err != nil {
 			l.Debug("Failed forwarding signal", logger.Ctx{"err": err, "signal": control.Signal})
 			return
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+)
+
+func TestApplyDNS(t *testing.T) {
+	conf := deviceConfig.DNSConfig{Nameservers: []string{"192.0.2.1", "2001:db8::1"}, Search: []string{"example.com", "example.net"}}
+
+	t.Run("resolv.conf", func(t *testing.T) {
+		root := t.TempDir()
+		resolvConf := filepath.Join(root, "etc", "resolv.conf")
+		require.NoError(t, os.MkdirAll(filepath.Dir(resolvConf), 0o755))
+		require.NoError(t, os.WriteFile(resolvConf, []byte("nameserver 198.51.100.1\n"), 0o644))
+
+		restarted := false
+		restart := func() error {
+			restarted = true
+			return nil
+		}
+
+		// Nothing to set leaves the existing configuration alone.
+		require.NoError(t, applyDNS(root, deviceConfig.DNSConfig{}, restart))
+		content, err := os.ReadFile(resolvConf)
+		require.NoError(t, err)
+		assert.Equal(t, "nameserver 198.51.100.1\n", string(content))
+
+		require.NoError(t, applyDNS(root, conf, restart))
+		content, err = os.ReadFile(resolvConf)
+		require.NoError(t, err)
+		assert.Equal(t, "# Generated by incus-agent\nnameserver 192.0.2.1\nnameserver 2001:db8::1\nsearch example.com example.net\n", string(content))
+		assert.NoFileExists(t, filepath.Join(root, "etc", ".resolv.conf.incus-agent"))
+		assert.False(t, restarted)
+	})
+
+	t.Run("systemd-resolved", func(t *testing.T) {
+		root := t.TempDir()
+		stubPath := filepath.Join(root, "run", "systemd", "resolve", "stub-resolv.conf")
+		require.NoError(t, os.MkdirAll(filepath.Dir(stubPath), 0o755))
+		require.NoError(t, os.WriteFile(stubPath, []byte("nameserver 127.0.0.53\n"), 0o644))
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0o755))
+		require.NoError(t, os.Symlink(stubPath, filepath.Join(root, "etc", "resolv.conf")))
+
+		restarts := 0
+		restart := func() error {
+			restarts++
+			return nil
+		}
+
+		dropInPath := filepath.Join(root, "run", "systemd", "resolved.conf.d", "incus-agent.conf")
+
+		require.NoError(t, applyDNS(root, conf, restart))
+		content, err := os.ReadFile(dropInPath)
+		require.NoError(t, err)
+		assert.Equal(t, "# Generated by incus-agent\n[Resolve]\nDNS=192.0.2.1 2001:db8::1\nDomains=example.com example.net\n", string(content))
+		assert.Equal(t, 1, restarts)
+
+		// The stub configuration managed by systemd-resolved is left alone.
+		content, err = os.ReadFile(stubPath)
+		require.NoError(t, err)
+		assert.Equal(t, "nameserver 127.0.0.53\n", string(content))
+
+		// Clearing the configuration removes the drop-in file.
+		require.NoError(t, applyDNS(root, deviceConfig.DNSConfig{}, restart))
+		assert.NoFileExists(t, dropInPath)
+		assert.Equal(t, 2, restarts)
+
+		// Nothing is restarted when there was no drop-in file to remove.
+		require.NoError(t, applyDNS(root, deviceConfig.DNSConfig{}, restart))
+		assert.Equal(t, 2, restarts)
+
+		// Restart failures are reported.
+		err = applyDNS(root, conf, func() error { return errors.New("failed") })
+		assert.Error(t, err)
+	})
+}
+
+func TestBlockDevPartition(t *testing.T) {
+	sysfsPath := t.TempDir()
+
+	// A disk with a partition, linked from the block class like in sysfs.
+	require.NoError(t, os.MkdirAll(filepath.Join(sysfsPath, "devices", "vda", "vda2"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(sysfsPath, "devices", "vda", "vda2", "partition"), []byte("2\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(sysfsPath, "class"), 0o755))
+	require.NoError(t, os.Symlink(filepath.Join(sysfsPath, "devices", "vda"), filepath.Join(sysfsPath, "class", "vda")))
+	require.NoError(t, os.Symlink(filepath.Join(sysfsPath, "devices", "vda", "vda2"), filepath.Join(sysfsPath, "class", "vda2")))
+
+	diskPath, partition, err := osBlockDevPartition(filepath.Join(sysfsPath, "class"), "vda2")
+	require.NoError(t, err)
+	assert.Equal(t, "/dev/vda", diskPath)
+	assert.Equal(t, "2", partition)
+
+	// A filesystem directly on the disk.
+	diskPath, partition, err = osBlockDevPartition(filepath.Join(sysfsPath, "class"), "vda")
+	require.NoError(t, err)
+	assert.Empty(t, diskPath)
+	assert.Empty(t, partition)
+}
+
+func TestGrowFilesystemCommand(t *testing.T) {
+	cmd, err := osGrowFilesystemCommand("ext4", "/dev/vda2", "/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"resize2fs", "/dev/vda2"}, cmd)
+
+	cmd, err = osGrowFilesystemCommand("xfs", "/dev/vda2", "/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"xfs_growfs", "/"}, cmd)
+
+	cmd, err = osGrowFilesystemCommand("btrfs", "/dev/vda2", "/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"btrfs", "filesystem", "resize", "max", "/"}, cmd)
+
+	// Filesystems which can't be grown online are refused.
+	for _, fsType := range []string{"ext2", "vfat", "zfs"} {
+		_, err = osGrowFilesystemCommand(fsType, "/dev/vda2", "/")
+		assert.Error(t, err, fsType)
+	}
+}
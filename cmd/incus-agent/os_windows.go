@@ -12,10 +12,12 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/registry"
 
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/metrics"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
@@ -63,7 +65,7 @@ func osGetDiskMetrics(d *Daemon) ([]metrics.DiskMetrics, error) {
 	return []metrics.DiskMetrics{}, errors.New("Metrics aren't supported on Windows")
 }
 
-func osGetFilesystemMetrics(d *Daemon) ([]metrics.FilesystemMetrics, error) {
+func osGetFilesystemMetrics(d *Daemon, excludedMountpoints []string) ([]metrics.FilesystemMetrics, error) {
 	return []metrics.FilesystemMetrics{}, errors.New("Metrics aren't supported on Windows")
 }
 
@@ -146,11 +148,33 @@ func osGetOSState() *api.InstanceStateOSInfo {
 	return osInfo
 }
 
+func osGetClockState() *api.InstanceStateClock {
+	return &api.InstanceStateClock{Time: time.Now()}
+}
+
+func osSetClock(t time.Time) error {
+	return errors.New("Setting the clock isn't supported on Windows")
+}
+
 func osReconfigureNetworkInterfaces() {
 	// Agent assisted network reconfiguration isn't currently supported.
 	return
 }
 
+func osApplyDNS(conf deviceConfig.DNSConfig) error {
+	// Agent assisted DNS configuration isn't currently supported.
+	return nil
+}
+
+func osSetHostname(hostname string) error {
+	// Agent assisted hostname configuration isn't currently supported.
+	return nil
+}
+
+func osGrowRootDisk() error {
+	return errors.New("Growing the root disk isn't supported on Windows")
+}
+
 func osGetInteractiveConsole(s *execWs) (io.ReadWriteCloser, io.ReadWriteCloser, error) {
 	return nil, nil, errors.New("Only non-interactive exec sessions are currently supported on Windows")
 }
@@ -31,5 +31,6 @@ func renderState() *api.InstanceState {
 		Pid:       1,
 		Processes: osGetProcessesState(),
 		OSInfo:    osGetOSState(),
+		Clock:     osGetClockState(),
 	}
 }
@@ -54,7 +54,10 @@ func (c *cmdPause) Command() *cobra.Command {
 	cmd.Use = usage("pause", i18n.G("[<remote>:]<instance> [[<remote>:]<instance>...]"))
 	cmd.Short = i18n.G("Pause instances")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Pause instances`))
+		`Pause instances
+
+With --to-disk, the state of virtual machines is saved to disk and their memory
+released (hibernation). Use "resume" or "start" to restore them.`))
 	cmd.Aliases = []string{"freeze"}
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -79,7 +82,9 @@ func (c *cmdResume) Command() *cobra.Command {
 	cmd.Use = usage("resume", i18n.G("[<remote>:]<instance> [[<remote>:]<instance>...]"))
 	cmd.Short = i18n.G("Resume instances")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Resume instances`))
+		`Resume instances
+
+Hibernated virtual machines are restored from their saved state.`))
 	cmd.Aliases = []string{"unfreeze"}
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -146,6 +151,7 @@ type cmdAction struct {
 	flagStateful  bool
 	flagStateless bool
 	flagTimeout   int
+	flagToDisk    bool
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -160,6 +166,8 @@ func (c *cmdAction) Command(action string) *cobra.Command {
 		cmd.Flags().BoolVar(&c.flagStateful, "stateful", false, i18n.G("Store the instance state"))
 	case "start":
 		cmd.Flags().BoolVar(&c.flagStateless, "stateless", false, i18n.G("Ignore the instance state"))
+	case "pause":
+		cmd.Flags().BoolVar(&c.flagToDisk, "to-disk", false, i18n.G("Save the virtual machine state to disk and release its memory"))
 	}
 
 	if slices.Contains([]string{"start", "restart", "stop"}, action) {
@@ -189,10 +197,18 @@ func (c *cmdAction) doActionAll(action string, resource remoteResource) error {
 		return err
 	}
 
-	// Pause is called freeze, resume is called unfreeze.
+	// Pause is called freeze (or hibernate when saving to disk), resume is called unfreeze.
 	switch action {
 	case "pause":
 		action = "freeze"
+		if c.flagToDisk {
+			if !d.HasExtension("instance_hibernate") {
+				return errors.New(i18n.G("The server doesn't support hibernating instances"))
+			}
+
+			action = "hibernate"
+		}
+
 	case "resume":
 		action = "unfreeze"
 	}
@@ -241,9 +257,12 @@ func (c *cmdAction) doActionAll(action string, resource remoteResource) error {
 func (c *cmdAction) doAction(action string, conf *config.Config, nameArg string) error {
 	state := false
 
-	// Pause is called freeze
+	// Pause is called freeze, or hibernate when saving to disk
 	if action == "pause" {
 		action = "freeze"
+		if c.flagToDisk {
+			action = "hibernate"
+		}
 	}
 
 	// Resume is called unfreeze
@@ -274,6 +293,24 @@ func (c *cmdAction) doAction(action string, conf *config.Config, nameArg string)
 		return fmt.Errorf(i18n.G("Must supply instance name for: ")+"\"%s\"", nameArg)
 	}
 
+	if action == "hibernate" {
+		if !d.HasExtension("instance_hibernate") {
+			return errors.New(i18n.G("The server doesn't support hibernating instances"))
+		}
+	}
+
+	// "resume" for a hibernated instance means a stateful "start"
+	if action == "unfreeze" {
+		current, _, err := d.GetInstance(name)
+		if err != nil {
+			return err
+		}
+
+		if current.Status == api.InstanceStatusHibernated {
+			action = "start"
+		}
+	}
+
 	if action == "start" {
 		current, _, err := d.GetInstance(name)
 		if err != nil {
@@ -107,6 +107,9 @@ func (c *cmdCluster) Command() *cobra.Command {
 	clusterRoleCmd := cmdClusterRole{global: c.global, cluster: c}
 	cmd.AddCommand(clusterRoleCmd.Command())
 
+	clusterDatabaseCmd := cmdClusterDatabase{global: c.global, cluster: c}
+	cmd.AddCommand(clusterDatabaseCmd.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
@@ -416,13 +419,29 @@ func (c *cmdClusterInfo) Run(cmd *cobra.Command, args []string) error {
 	resource := resources[0]
 
 	// Get the member state information.
-	member, _, err := resource.server.GetClusterMemberState(resource.name)
+	memberState, _, err := resource.server.GetClusterMemberState(resource.name)
 	if err != nil {
 		return err
 	}
 
+	// Get the member roles.
+	member, _, err := resource.server.GetClusterMember(resource.name)
+	if err != nil {
+		return err
+	}
+
+	info := struct {
+		Roles                  []string `yaml:"roles"`
+		FailureDomain          string   `yaml:"failure_domain"`
+		api.ClusterMemberState `yaml:",inline"`
+	}{
+		Roles:              member.Roles,
+		FailureDomain:      member.FailureDomain,
+		ClusterMemberState: *memberState,
+	}
+
 	// Render as YAML.
-	data, err := yaml.Marshal(&member)
+	data, err := yaml.Marshal(&info)
 	if err != nil {
 		return err
 	}
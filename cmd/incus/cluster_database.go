@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+)
+
+type cmdClusterDatabase struct {
+	global  *cmdGlobal
+	cluster *cmdCluster
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdClusterDatabase) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("database")
+	cmd.Aliases = []string{"db"}
+	cmd.Short = i18n.G("Inspect the cluster database")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Inspect the cluster database`))
+
+	// Status
+	clusterDatabaseStatusCmd := cmdClusterDatabaseStatus{global: c.global, cluster: c.cluster, clusterDatabase: c}
+	cmd.AddCommand(clusterDatabaseStatusCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdClusterDatabaseStatus struct {
+	global          *cmdGlobal
+	cluster         *cmdCluster
+	clusterDatabase *cmdClusterDatabase
+
+	flagFormat string
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdClusterDatabaseStatus) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("status", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Show the state of the cluster database")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show the state of the cluster database
+
+This lists the raft role of each database member (voter, stand-by or spare),
+which member is the leader and whether it's online. A warning is shown when
+losing one more voter would make the database unavailable.`))
+
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", c.global.defaultListFormat(), i18n.G(`Format (csv|json|table|yaml|compact), use suffix ",noheader" to disable headers and ",header" to enable it if missing, e.g. csv,header`)+"``")
+
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
+		return cli.ValidateFlagFormatForListOutput(cmd.Flag("format").Value.String())
+	}
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(toComplete, false)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdClusterDatabaseStatus) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) == 1 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.parseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	database, err := resource.server.GetClusterDatabase()
+	if err != nil {
+		return err
+	}
+
+	// Render the table
+	data := [][]string{}
+	onlineVoters := 0
+	for _, member := range database.Members {
+		leader := i18n.G("NO")
+		if member.Leader {
+			leader = i18n.G("YES")
+		}
+
+		status := i18n.G("OFFLINE")
+		if member.Online {
+			status = i18n.G("ONLINE")
+
+			if member.Role == "voter" {
+				onlineVoters++
+			}
+		}
+
+		data = append(data, []string{member.ServerName, member.Address, fmt.Sprintf("%d", member.ID), strings.ToUpper(member.Role), leader, status})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("ADDRESS"),
+		i18n.G("ID"),
+		i18n.G("ROLE"),
+		i18n.G("LEADER"),
+		i18n.G("STATUS"),
+	}
+
+	err = cli.RenderTable(os.Stdout, c.flagFormat, header, data, database)
+	if err != nil {
+		return err
+	}
+
+	// Warn about the database availability.
+	if onlineVoters < database.Quorum {
+		fmt.Fprintf(os.Stderr, i18n.G("WARNING: Database quorum lost, only %d online voters out of the %d required")+"\n", onlineVoters, database.Quorum)
+	} else if onlineVoters == database.Quorum && len(database.Members) > 1 {
+		fmt.Fprintf(os.Stderr, i18n.G("WARNING: Database quorum at risk, losing one of the %d online voters will make the database unavailable")+"\n", onlineVoters)
+	}
+
+	return nil
+}
@@ -50,7 +50,10 @@ func (c *cmdClusterRoleAdd) Command() *cobra.Command {
 	cmd.Use = usage("add", i18n.G("[<remote>:]<member> <role[,role...]>"))
 	cmd.Short = i18n.G("Add roles to a cluster member")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Add roles to a cluster member`))
+		`Add roles to a cluster member
+
+Adding the "database" or "database-standby" role promotes the member to a database voter or stand-by.
+To keep the configured number of database members, another member takes over the previous database role of the member.`))
 
 	cmd.RunE = c.Run
 
@@ -114,7 +117,10 @@ func (c *cmdClusterRoleRemove) Command() *cobra.Command {
 	cmd.Use = usage("remove", i18n.G("[<remote>:]<member> <role[,role...]>"))
 	cmd.Short = i18n.G("Remove roles from a cluster member")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Remove roles from a cluster member`))
+		`Remove roles from a cluster member
+
+Removing the "database" or "database-standby" role turns the member into a database spare.
+To keep the configured number of database members, a spare member takes over the database role.`))
 
 	cmd.RunE = c.Run
 
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -25,7 +28,7 @@ type cmdConfig struct {
 }
 
 // Command creates a Cobra command for managing instance and server configurations,
-// including options for device, edit, get, metadata, profile, set, show, template, trust, and unset.
+// including options for device, edit, get, metadata, profile, set, show, template, trust, unset, and validate.
 func (c *cmdConfig) Command() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Use = usage("config")
@@ -76,6 +79,10 @@ func (c *cmdConfig) Command() *cobra.Command {
 	configUnsetCmd := cmdConfigUnset{global: c.global, config: c, configSet: &configSetCmd}
 	cmd.AddCommand(configUnsetCmd.Command())
 
+	// Validate
+	configValidateCmd := cmdConfigValidate{global: c.global, config: c}
+	cmd.AddCommand(configValidateCmd.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
@@ -376,8 +383,9 @@ type cmdConfigGet struct {
 	global *cmdGlobal
 	config *cmdConfig
 
-	flagExpanded   bool
-	flagIsProperty bool
+	flagExpanded     bool
+	flagIsProperty   bool
+	flagShowVolatile bool
 }
 
 // Command creates a Cobra command to fetch values for given instance or server configuration keys,
@@ -387,10 +395,16 @@ func (c *cmdConfigGet) Command() *cobra.Command {
 	cmd.Use = usage("get", i18n.G("[<remote>:][<instance>] <key>"))
 	cmd.Short = i18n.G("Get values for instance or server configuration keys")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Get values for instance or server configuration keys`))
+		`Get values for instance or server configuration keys
+
+With --show-volatile and no key, all the volatile keys of the instance are shown.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus config get [<remote>:]<instance> --show-volatile
+    Will show all the volatile keys of the instance.`))
 
 	cmd.Flags().BoolVarP(&c.flagExpanded, "expanded", "e", false, i18n.G("Access the expanded configuration"))
 	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Get the key as an instance property"))
+	cmd.Flags().BoolVar(&c.flagShowVolatile, "show-volatile", false, i18n.G("Show the volatile keys of the instance"))
 	cmd.Flags().StringVar(&c.config.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.RunE = c.Run
 
@@ -419,7 +433,7 @@ func (c *cmdConfigGet) Run(cmd *cobra.Command, args []string) error {
 
 	// Parse remote
 	remote := ""
-	if len(args) > 1 {
+	if len(args) > 1 || c.flagShowVolatile {
 		remote = args[0]
 	}
 
@@ -432,6 +446,11 @@ func (c *cmdConfigGet) Run(cmd *cobra.Command, args []string) error {
 	fields := strings.SplitN(resource.name, "/", 2)
 	isSnapshot := len(fields) == 2
 
+	// List the volatile keys
+	if c.flagShowVolatile && len(args) == 1 {
+		return c.showVolatile(resource, fields, isSnapshot)
+	}
+
 	// Get the config key
 	if resource.name != "" {
 		// Quick checks.
@@ -510,12 +529,100 @@ func (c *cmdConfigGet) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// showVolatile prints all the volatile keys of an instance or instance snapshot.
+func (c *cmdConfigGet) showVolatile(resource remoteResource, fields []string, isSnapshot bool) error {
+	// Quick checks.
+	if resource.name == "" {
+		return errors.New(i18n.G("--show-volatile can only be used with instances"))
+	}
+
+	if c.flagIsProperty {
+		return errors.New(i18n.G("--show-volatile cannot be used with --property"))
+	}
+
+	if c.config.flagTarget != "" {
+		return errors.New(i18n.G("--target cannot be used with instances"))
+	}
+
+	var config map[string]string
+	if isSnapshot {
+		inst, _, err := resource.server.GetInstanceSnapshot(fields[0], fields[1])
+		if err != nil {
+			return err
+		}
+
+		config = inst.Config
+		if c.flagExpanded {
+			config = inst.ExpandedConfig
+		}
+	} else {
+		inst, _, err := resource.server.GetInstance(resource.name)
+		if err != nil {
+			return err
+		}
+
+		config = inst.Config
+		if c.flagExpanded {
+			config = inst.ExpandedConfig
+		}
+	}
+
+	keys := []string{}
+	for k := range config {
+		if strings.HasPrefix(k, instance.ConfigVolatilePrefix) {
+			keys = append(keys, k)
+		}
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		fmt.Printf("%s: %s\n", k, config[k])
+	}
+
+	return nil
+}
+
+// unsetInstanceConfigKeys removes the keys from an instance configuration, volatile keys being set to an empty value.
+// Volatile keys are expected to be cleared with --volatile, so it returns whether one was cleared without it.
+func unsetInstanceConfigKeys(config map[string]string, keys []string, volatile bool) (bool, error) {
+	warnVolatile := false
+
+	for _, k := range keys {
+		_, ok := config[k]
+		if !ok {
+			return false, fmt.Errorf(i18n.G("Can't unset key '%s', it's not currently set"), k)
+		}
+
+		isVolatile := strings.HasPrefix(k, instance.ConfigVolatilePrefix)
+		if !isVolatile && volatile {
+			return false, fmt.Errorf(i18n.G("Key '%s' isn't a volatile key"), k)
+		}
+
+		if isVolatile && !volatile {
+			warnVolatile = true
+		}
+
+		// Volatile keys are cleared with an empty value rather than left out,
+		// as the server only guards the volatile keys named in the request.
+		if isVolatile {
+			config[k] = ""
+			continue
+		}
+
+		delete(config, k)
+	}
+
+	return warnVolatile, nil
+}
+
 // Set.
 type cmdConfigSet struct {
 	global *cmdGlobal
 	config *cmdConfig
 
 	flagIsProperty bool
+	flagVolatile   bool
 }
 
 // Command creates a new Cobra command to set instance or server configuration keys and returns it.
@@ -674,18 +781,18 @@ func (c *cmdConfigSet) Run(cmd *cobra.Command, args []string) error {
 					return fmt.Errorf(i18n.G("Error setting properties: %v"), err)
 				}
 			}
+		} else if cmd.Name() == "unset" {
+			warnVolatile, err := unsetInstanceConfigKeys(writable.Config, slices.Sorted(maps.Keys(keys)), c.flagVolatile)
+			if err != nil {
+				return err
+			}
+
+			if warnVolatile {
+				fmt.Fprintln(os.Stderr, i18n.G("WARNING: Volatile keys hold internal state, clearing them can leave the instance in an inconsistent state (use --volatile to acknowledge)"))
+			}
 		} else {
 			for k, v := range keys {
-				if cmd.Name() == "unset" {
-					_, ok := writable.Config[k]
-					if !ok {
-						return fmt.Errorf(i18n.G("Can't unset key '%s', it's not currently set"), k)
-					}
-
-					delete(writable.Config, k)
-				} else {
-					writable.Config[k] = v
-				}
+				writable.Config[k] = v
 			}
 		}
 
@@ -697,6 +804,11 @@ func (c *cmdConfigSet) Run(cmd *cobra.Command, args []string) error {
 		return op.Wait()
 	}
 
+	// Quick check.
+	if c.flagVolatile {
+		return errors.New(i18n.G("--volatile can only be used with instances"))
+	}
+
 	// Targeting
 	if c.config.flagTarget != "" {
 		if !resource.server.IsClustered() {
@@ -742,6 +854,7 @@ type cmdConfigShow struct {
 	config *cmdConfig
 
 	flagExpanded bool
+	flagSources  string
 }
 
 // Command sets up the "show" command, which displays instance or server configurations based on the provided arguments.
@@ -750,9 +863,21 @@ func (c *cmdConfigShow) Command() *cobra.Command {
 	cmd.Use = usage("show", i18n.G("[<remote>:][<instance>[/<snapshot>]]"))
 	cmd.Short = i18n.G("Show instance or server configurations")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Show instance or server configurations`))
+		`Show instance or server configurations
+
+With --sources, the expanded configuration keys and devices are annotated with
+where they come from, either the instance itself or one of its profiles.
+Use --sources=yaml or --sources=json for a machine-readable list of sources.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus config show c1 --expanded --sources
+    Show the expanded configuration of instance "c1" with the source of each key and device.
+
+incus config show c1 --expanded --sources=json
+    Show the source of each expanded key and device of instance "c1" as JSON.`))
 
 	cmd.Flags().BoolVarP(&c.flagExpanded, "expanded", "e", false, i18n.G("Show the expanded configuration"))
+	cmd.Flags().StringVar(&c.flagSources, "sources", "", i18n.G("Show where the expanded configuration comes from (comments|yaml|json)")+"``")
+	cmd.Flags().Lookup("sources").NoOptDefVal = "comments"
 	cmd.Flags().StringVar(&c.config.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.RunE = c.Run
 
@@ -788,6 +913,16 @@ func (c *cmdConfigShow) Run(cmd *cobra.Command, args []string) error {
 
 	resource := resources[0]
 
+	if c.flagSources != "" {
+		if !c.flagExpanded {
+			return errors.New(i18n.G("--sources requires --expanded"))
+		}
+
+		if !slices.Contains([]string{"comments", "yaml", "json"}, c.flagSources) {
+			return fmt.Errorf(i18n.G("Invalid sources format %q"), c.flagSources)
+		}
+	}
+
 	// Show configuration
 	var data []byte
 
@@ -825,6 +960,7 @@ func (c *cmdConfigShow) Run(cmd *cobra.Command, args []string) error {
 
 		// Instance or snapshot config
 		var brief any
+		var sources *configShowSources
 
 		if instance.IsSnapshot(resource.name) {
 			// Snapshot
@@ -835,6 +971,13 @@ func (c *cmdConfigShow) Run(cmd *cobra.Command, args []string) error {
 				return err
 			}
 
+			if c.flagSources != "" {
+				sources, err = c.getSources(resource.server, snap.Profiles, snap.Config, snap.Devices, snap.ExpandedConfig, snap.ExpandedDevices)
+				if err != nil {
+					return err
+				}
+			}
+
 			brief = snap
 			if c.flagExpanded {
 				brief.(*api.InstanceSnapshot).Config = snap.ExpandedConfig
@@ -847,6 +990,13 @@ func (c *cmdConfigShow) Run(cmd *cobra.Command, args []string) error {
 				return err
 			}
 
+			if c.flagSources != "" {
+				sources, err = c.getSources(resource.server, inst.Profiles, inst.Config, inst.Devices, inst.ExpandedConfig, inst.ExpandedDevices)
+				if err != nil {
+					return err
+				}
+			}
+
 			writable := inst.Writable()
 			brief = &writable
 
@@ -856,7 +1006,18 @@ func (c *cmdConfigShow) Run(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		data, err = yaml.Marshal(&brief)
+		switch c.flagSources {
+		case "comments":
+			data, err = sources.annotate(brief)
+		case "yaml":
+			data, err = yaml.Marshal(sources)
+		case "json":
+			data, err = json.MarshalIndent(sources, "", "  ")
+			data = append(data, '\n')
+		default:
+			data, err = yaml.Marshal(&brief)
+		}
+
 		if err != nil {
 			return err
 		}
@@ -867,6 +1028,136 @@ func (c *cmdConfigShow) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// configShowSource represents where an expanded configuration key or device comes from.
+type configShowSource struct {
+	// Source is either "instance" or "profile:<name>" (or "unknown" if the profiles changed since expansion).
+	Source string `json:"source" yaml:"source"`
+
+	// Overrides lists the overridden sources which also set the key or device.
+	Overrides []string `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// configShowSources represents the sources of the expanded configuration of an instance or snapshot.
+type configShowSources struct {
+	Config  map[string]configShowSource `json:"config" yaml:"config"`
+	Devices map[string]configShowSource `json:"devices" yaml:"devices"`
+}
+
+// getSources computes the source of each expanded configuration key and device by expanding the
+// local configuration and devices with the current profiles.
+func (c *cmdConfigShow) getSources(server incus.InstanceServer, profileNames []string, config map[string]string, devices map[string]map[string]string, expandedConfig map[string]string, expandedDevices map[string]map[string]string) (*configShowSources, error) {
+	profiles := make([]api.Profile, 0, len(profileNames))
+	for _, name := range profileNames {
+		profile, _, err := server.GetProfile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		profiles = append(profiles, *profile)
+	}
+
+	localConfig, configSources := instance.ExpandConfig(config, profiles)
+	localDevices, deviceSources := instance.ExpandDevices(devices, profiles)
+
+	newSource := func(sources []string, match bool) configShowSource {
+		if !match || len(sources) == 0 {
+			return configShowSource{Source: "unknown"}
+		}
+
+		return configShowSource{Source: sources[len(sources)-1], Overrides: sources[:len(sources)-1]}
+	}
+
+	result := configShowSources{
+		Config:  make(map[string]configShowSource, len(expandedConfig)),
+		Devices: make(map[string]configShowSource, len(expandedDevices)),
+	}
+
+	for key, value := range expandedConfig {
+		localValue, found := localConfig[key]
+		result.Config[key] = newSource(configSources[key], found && localValue == value)
+	}
+
+	for name, device := range expandedDevices {
+		localDevice, found := localDevices[name]
+		result.Devices[name] = newSource(deviceSources[name], found && maps.Equal(localDevice, device))
+	}
+
+	return &result, nil
+}
+
+// annotate renders the configuration as YAML with a comment indicating the source of each key and device.
+func (s *configShowSources) annotate(brief any) ([]byte, error) {
+	data, err := yaml.Marshal(brief)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := func(source configShowSource) string {
+		if len(source.Overrides) == 0 {
+			return source.Source
+		}
+
+		return fmt.Sprintf(i18n.G("%s (overrides %s)"), source.Source, strings.Join(source.Overrides, ", "))
+	}
+
+	// Map the rendered form of each key to its comment, matching the way the encoder quotes keys.
+	comments := func(sources map[string]configShowSource) (map[string]string, error) {
+		result := make(map[string]string, len(sources))
+		for key, source := range sources {
+			rendered, err := yaml.Marshal(key)
+			if err != nil {
+				return nil, err
+			}
+
+			result["  "+strings.TrimSuffix(string(rendered), "\n")+":"] = comment(source)
+		}
+
+		return result, nil
+	}
+
+	configComments, err := comments(s.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceComments, err := comments(s.Devices)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the comments to the top-level entries of the config and devices sections.
+	var section map[string]string
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if line != "" && !strings.HasPrefix(line, " ") {
+			switch line {
+			case "config:":
+				section = configComments
+			case "devices:":
+				section = deviceComments
+			default:
+				section = nil
+			}
+
+			continue
+		}
+
+		if section == nil || strings.HasPrefix(line, "   ") {
+			continue
+		}
+
+		for prefix, text := range section {
+			rest, found := strings.CutPrefix(line, prefix)
+			if found && (rest == "" || strings.HasPrefix(rest, " ")) {
+				lines[i] = line + " # " + text
+				break
+			}
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
 // Unset.
 type cmdConfigUnset struct {
 	global    *cmdGlobal
@@ -874,6 +1165,7 @@ type cmdConfigUnset struct {
 	configSet *cmdConfigSet
 
 	flagIsProperty bool
+	flagVolatile   bool
 }
 
 // Command generates a new "unset" command to remove specific configuration keys for an instance or server.
@@ -882,10 +1174,18 @@ func (c *cmdConfigUnset) Command() *cobra.Command {
 	cmd.Use = usage("unset", i18n.G("[<remote>:][<instance>] <key>"))
 	cmd.Short = i18n.G("Unset instance or server configuration keys")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Unset instance or server configuration keys`))
+		`Unset instance or server configuration keys
+
+Volatile instance keys can only be unset while the instance is stopped.
+They hold internal state and clearing them can leave the instance in an inconsistent state,
+which --volatile acknowledges.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus config unset [<remote>:]<instance> volatile.eth0.last_state.vf.id --volatile
+    Will clear the recorded VF of the eth0 device of the stopped instance.`))
 
 	cmd.Flags().StringVar(&c.config.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Unset the key as an instance property"))
+	cmd.Flags().BoolVar(&c.flagVolatile, "volatile", false, i18n.G("Acknowledge unsetting volatile instance keys"))
 	cmd.RunE = c.Run
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -911,7 +1211,12 @@ func (c *cmdConfigUnset) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if c.flagVolatile && c.flagIsProperty {
+		return errors.New(i18n.G("--volatile cannot be used with --property"))
+	}
+
 	c.configSet.flagIsProperty = c.flagIsProperty
+	c.configSet.flagVolatile = c.flagVolatile
 
 	args = append(args, "")
 	return c.configSet.Run(cmd, args)
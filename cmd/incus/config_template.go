@@ -7,11 +7,13 @@ import (
 	"io"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	cli "github.com/lxc/incus/v6/internal/cmd"
 	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/termios"
 )
 
@@ -59,6 +61,10 @@ type cmdConfigTemplateCreate struct {
 	global         *cmdGlobal
 	config         *cmdConfig
 	configTemplate *cmdConfigTemplate
+
+	flagPath       string
+	flagWhen       []string
+	flagCreateOnly bool
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -71,7 +77,13 @@ func (c *cmdConfigTemplateCreate) Command() *cobra.Command {
 	cmd.Example = cli.FormatSection("", i18n.G(`incus config template create u1 t1
 
 incus config template create u1 t1 < config.tpl
-    Create template t1 for instance u1 from config.tpl`))
+    Create template t1 for instance u1 from config.tpl
+
+incus config template create u1 hostname.tpl --path /etc/hostname --when create,copy < hostname.tpl
+    Create template hostname.tpl for instance u1, rendered to /etc/hostname on create and copy`))
+	cmd.Flags().StringVar(&c.flagPath, "path", "", i18n.G("Path in the instance to render the template to")+"``")
+	cmd.Flags().StringSliceVar(&c.flagWhen, "when", []string{"create", "copy"}, i18n.G("When to render the template (create, copy or start)")+"``")
+	cmd.Flags().BoolVar(&c.flagCreateOnly, "create-only", false, i18n.G("Only render the template if the target file is missing"))
 
 	cmd.RunE = c.Run
 
@@ -120,7 +132,33 @@ func (c *cmdConfigTemplateCreate) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create instance file template
-	return resource.server.CreateInstanceTemplateFile(resource.name, args[1], stdinData)
+	trigger, err := configTemplateTrigger(cmd, c.flagPath, c.flagWhen, c.flagCreateOnly)
+	if err != nil {
+		return err
+	}
+
+	if stdinData == nil {
+		stdinData = bytes.NewReader(nil)
+	}
+
+	return resource.server.CreateInstanceTemplate(resource.name, args[1], stdinData, trigger)
+}
+
+// configTemplateTrigger returns the template trigger requested through the command line flags, if any.
+func configTemplateTrigger(cmd *cobra.Command, path string, when []string, createOnly bool) (*api.InstanceTemplateTrigger, error) {
+	if path == "" {
+		if cmd.Flags().Changed("when") || cmd.Flags().Changed("create-only") {
+			return nil, errors.New(i18n.G("--when and --create-only require --path"))
+		}
+
+		return nil, nil
+	}
+
+	return &api.InstanceTemplateTrigger{
+		Path:       path,
+		When:       when,
+		CreateOnly: createOnly,
+	}, nil
 }
 
 // Delete.
@@ -185,6 +223,10 @@ type cmdConfigTemplateEdit struct {
 	global         *cmdGlobal
 	config         *cmdConfig
 	configTemplate *cmdConfigTemplate
+
+	flagPath       string
+	flagWhen       []string
+	flagCreateOnly bool
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -194,6 +236,9 @@ func (c *cmdConfigTemplateEdit) Command() *cobra.Command {
 	cmd.Short = i18n.G("Edit instance file templates")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
 		`Edit instance file templates`))
+	cmd.Flags().StringVar(&c.flagPath, "path", "", i18n.G("Path in the instance to render the template to")+"``")
+	cmd.Flags().StringSliceVar(&c.flagWhen, "when", []string{"create", "copy"}, i18n.G("When to render the template (create, copy or start)")+"``")
+	cmd.Flags().BoolVar(&c.flagCreateOnly, "create-only", false, i18n.G("Only render the template if the target file is missing"))
 
 	cmd.RunE = c.Run
 
@@ -232,9 +277,19 @@ func (c *cmdConfigTemplateEdit) Run(cmd *cobra.Command, args []string) error {
 		return errors.New(i18n.G("Missing instance name"))
 	}
 
+	trigger, err := configTemplateTrigger(cmd, c.flagPath, c.flagWhen, c.flagCreateOnly)
+	if err != nil {
+		return err
+	}
+
 	// Edit instance file template
 	if !termios.IsTerminal(getStdinFd()) {
-		return resource.server.CreateInstanceTemplateFile(resource.name, args[1], os.Stdin)
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.CreateInstanceTemplate(resource.name, args[1], bytes.NewReader(contents), trigger)
 	}
 
 	reader, err := resource.server.GetInstanceTemplateFile(resource.name, args[1])
@@ -255,7 +310,7 @@ func (c *cmdConfigTemplateEdit) Run(cmd *cobra.Command, args []string) error {
 
 	for {
 		reader := bytes.NewReader(content)
-		err := resource.server.CreateInstanceTemplateFile(resource.name, args[1], reader)
+		err := resource.server.CreateInstanceTemplate(resource.name, args[1], reader, trigger)
 		// Respawn the editor
 		if err != nil {
 			fmt.Fprintf(os.Stderr, i18n.G("Error updating template file: %s")+"\n", err)
@@ -336,7 +391,28 @@ func (c *cmdConfigTemplateList) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// List the templates
-	templates, err := resource.server.GetInstanceTemplateFiles(resource.name)
+	if !resource.server.HasExtension("instance_template_triggers") {
+		templates, err := resource.server.GetInstanceTemplateFiles(resource.name)
+		if err != nil {
+			return err
+		}
+
+		// Render the table
+		data := [][]string{}
+		for _, template := range templates {
+			data = append(data, []string{template})
+		}
+
+		sort.Sort(cli.SortColumnsNaturally(data))
+
+		header := []string{
+			i18n.G("FILENAME"),
+		}
+
+		return cli.RenderTable(os.Stdout, c.flagFormat, header, data, templates)
+	}
+
+	templates, err := resource.server.GetInstanceTemplates(resource.name)
 	if err != nil {
 		return err
 	}
@@ -344,13 +420,28 @@ func (c *cmdConfigTemplateList) Run(cmd *cobra.Command, args []string) error {
 	// Render the table
 	data := [][]string{}
 	for _, template := range templates {
-		data = append(data, []string{template})
+		targets := []string{}
+		triggers := []string{}
+		for _, trigger := range template.Triggers {
+			targets = append(targets, trigger.Path)
+
+			when := strings.Join(trigger.When, ", ")
+			if trigger.CreateOnly {
+				when = fmt.Sprintf(i18n.G("%s (if missing)"), when)
+			}
+
+			triggers = append(triggers, when)
+		}
+
+		data = append(data, []string{template.Name, strings.Join(targets, "\n"), strings.Join(triggers, "\n")})
 	}
 
 	sort.Sort(cli.SortColumnsNaturally(data))
 
 	header := []string{
 		i18n.G("FILENAME"),
+		i18n.G("TARGET"),
+		i18n.G("WHEN"),
 	}
 
 	return cli.RenderTable(os.Stdout, c.flagFormat, header, data, templates)
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestConfigShowSourcesAnnotate(t *testing.T) {
+	sources := &configShowSources{
+		Config: map[string]configShowSource{
+			"limits.cpu":    {Source: "profile:large", Overrides: []string{"profile:default"}},
+			"limits.memory": {Source: "instance"},
+		},
+		Devices: map[string]configShowSource{
+			"root": {Source: "profile:default"},
+		},
+	}
+
+	brief := &api.InstancePut{
+		Config:  map[string]string{"limits.cpu": "4", "limits.memory": "8GiB"},
+		Devices: map[string]map[string]string{"root": {"path": "/", "pool": "default", "type": "disk"}},
+	}
+
+	data, err := sources.annotate(brief)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `  limits.cpu: "4" # profile:large (overrides profile:default)`)
+	assert.Contains(t, string(data), "  limits.memory: 8GiB # instance\n")
+	assert.Contains(t, string(data), "  root: # profile:default\n    path: /\n")
+
+	// Multi-line values keep their comment on the key and the result remains valid YAML.
+	sources.Config["user.user-data"] = configShowSource{Source: "profile:cloud"}
+	brief.Config["user.user-data"] = "#cloud-config\npackages:\n- curl\n"
+
+	data, err = sources.annotate(brief)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "  user.user-data: | # profile:cloud\n    #cloud-config\n")
+
+	parsed := api.InstancePut{}
+	err = yaml.Unmarshal(data, &parsed)
+	require.NoError(t, err)
+	assert.Equal(t, brief.Config, parsed.Config)
+	assert.Equal(t, brief.Devices, parsed.Devices)
+}
+
+func TestUnsetInstanceConfigKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		keys        []string
+		volatile    bool
+		wantConfig  map[string]string
+		wantWarning bool
+		wantErr     bool
+	}{
+		{
+			name:       "Regular key",
+			keys:       []string{"limits.cpu"},
+			wantConfig: map[string]string{"volatile.base_image": "abc", "volatile.eth0.hwaddr": "10:66:6a:00:00:01"},
+		},
+		{
+			name:        "Volatile key without --volatile",
+			keys:        []string{"volatile.base_image"},
+			wantConfig:  map[string]string{"limits.cpu": "2", "volatile.base_image": "", "volatile.eth0.hwaddr": "10:66:6a:00:00:01"},
+			wantWarning: true,
+		},
+		{
+			name:       "Volatile keys with --volatile",
+			keys:       []string{"volatile.base_image", "volatile.eth0.hwaddr"},
+			volatile:   true,
+			wantConfig: map[string]string{"limits.cpu": "2", "volatile.base_image": "", "volatile.eth0.hwaddr": ""},
+		},
+		{
+			name:     "Regular key with --volatile",
+			keys:     []string{"limits.cpu"},
+			volatile: true,
+			wantErr:  true,
+		},
+		{
+			name:    "Unset key",
+			keys:    []string{"limits.memory"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]string{
+				"limits.cpu":           "2",
+				"volatile.base_image":  "abc",
+				"volatile.eth0.hwaddr": "10:66:6a:00:00:01",
+			}
+
+			warning, err := unsetInstanceConfigKeys(config, tt.keys, tt.volatile)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantWarning, warning)
+			assert.Equal(t, tt.wantConfig, config)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/termios"
+)
+
+// configValidateEntities are the kinds of entities whose configuration can be validated.
+var configValidateEntities = []string{"instance", "profile", "network", "storage-pool", "storage-volume"}
+
+// Validate.
+type cmdConfigValidate struct {
+	global *cmdGlobal
+	config *cmdConfig
+
+	flagEntity string
+	flagType   string
+	flagPool   string
+}
+
+// Command creates a Cobra command to validate a configuration without applying it.
+func (c *cmdConfigValidate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("validate", i18n.G("[<remote>:][<name>]"))
+	cmd.Short = i18n.G("Validate a configuration without applying it")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Validate a configuration without applying it
+
+The configuration is read as YAML from standard input, in the same format as shown by the "show" commands.
+It goes through the same validation as when creating or updating the entity, including project limits.
+If the entity already exists, the configuration is validated as an update of it.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus config validate c1 < instance.yaml
+    Validate the configuration of instance "c1".
+
+incus config validate --entity=profile default < profile.yaml
+    Validate the configuration of profile "default".
+
+incus config validate --entity=storage-volume --pool=default vol1 < volume.yaml
+    Validate the configuration of custom storage volume "vol1" on pool "default".`))
+
+	cmd.Flags().StringVar(&c.flagEntity, "entity", "instance", i18n.G("Kind of entity (instance, profile, network, storage-pool or storage-volume)")+"``")
+	cmd.Flags().StringVar(&c.flagType, "type", "", i18n.G("Instance type, network type, storage driver or volume content type")+"``")
+	cmd.Flags().StringVar(&c.flagPool, "pool", "", i18n.G("Storage pool of the volume")+"``")
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 && c.flagEntity == "instance" {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run executes the config validate command.
+func (c *cmdConfigValidate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	if !slices.Contains(configValidateEntities, c.flagEntity) {
+		return fmt.Errorf(i18n.G("Unknown entity %q"), c.flagEntity)
+	}
+
+	if c.flagPool != "" && c.flagEntity != "storage-volume" {
+		return errors.New(i18n.G("--pool can only be used with storage volumes"))
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.parseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if termios.IsTerminal(getStdinFd()) {
+		return errors.New(i18n.G("The configuration must be provided on standard input"))
+	}
+
+	contents, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	// Accept the output of the various "show" commands.
+	data := struct {
+		Name        string                       `yaml:"name"`
+		Type        string                       `yaml:"type"`
+		Driver      string                       `yaml:"driver"`
+		ContentType string                       `yaml:"content_type"`
+		Pool        string                       `yaml:"pool"`
+		Profiles    []string                     `yaml:"profiles"`
+		Config      map[string]string            `yaml:"config"`
+		Devices     map[string]map[string]string `yaml:"devices"`
+	}{}
+
+	err = yaml.Unmarshal(contents, &data)
+	if err != nil {
+		return err
+	}
+
+	req := api.ConfigValidatePost{
+		Entity:   c.flagEntity,
+		Name:     data.Name,
+		Profiles: data.Profiles,
+		Config:   data.Config,
+		Devices:  data.Devices,
+	}
+
+	switch c.flagEntity {
+	case "instance", "network":
+		req.Type = data.Type
+	case "storage-pool":
+		req.Type = data.Driver
+	case "storage-volume":
+		req.Type = data.ContentType
+		req.Pool = data.Pool
+	}
+
+	if resource.name != "" {
+		req.Name = resource.name
+	}
+
+	if c.flagType != "" {
+		req.Type = c.flagType
+	}
+
+	if c.flagPool != "" {
+		req.Pool = c.flagPool
+	}
+
+	result, err := resource.server.ValidateConfig(req)
+	if err != nil {
+		return err
+	}
+
+	if result.Valid {
+		if !c.global.flagQuiet {
+			fmt.Println(i18n.G("The configuration is valid"))
+		}
+
+		return nil
+	}
+
+	for _, validateErr := range result.Errors {
+		if validateErr.Key != "" {
+			fmt.Printf("%s %q: %s\n", validateErr.Section, validateErr.Key, validateErr.Message)
+		} else {
+			fmt.Printf("%s: %s\n", validateErr.Section, validateErr.Message)
+		}
+	}
+
+	return errors.New(i18n.G("The configuration is invalid"))
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"image/png"
 	"io"
 	"net"
 	"os"
@@ -27,9 +29,10 @@ import (
 type cmdConsole struct {
 	global *cmdGlobal
 
-	flagForce   bool
-	flagShowLog bool
-	flagType    string
+	flagForce      bool
+	flagShowLog    bool
+	flagScreenshot string
+	flagType       string
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -41,11 +44,18 @@ func (c *cmdConsole) Command() *cobra.Command {
 		`Attach to instance consoles
 
 This command allows you to interact with the boot console of an instance
-as well as retrieve past log entries from it.`))
+as well as retrieve past log entries from it.
+
+For virtual machines, a screenshot of the current VGA console can be saved
+to a PNG file with --screenshot.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus console v1 --screenshot screen.png
+    Save a screenshot of the VGA console of the "v1" virtual machine to screen.png.`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().BoolVarP(&c.flagForce, "force", "f", false, i18n.G("Forces a connection to the console, even if there is already an active session"))
 	cmd.Flags().BoolVar(&c.flagShowLog, "show-log", false, i18n.G("Retrieve the instance's console log"))
+	cmd.Flags().StringVar(&c.flagScreenshot, "screenshot", "", i18n.G("Save a PNG screenshot of the VM's VGA console to the given file")+"``")
 	cmd.Flags().StringVarP(&c.flagType, "type", "t", "console", i18n.G("Type of connection to establish: 'console' for serial console, 'vga' for SPICE graphical output")+"``")
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -118,6 +128,16 @@ func (c *cmdConsole) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Unknown output type %q"), c.flagType)
 	}
 
+	if c.flagScreenshot != "" {
+		if c.flagShowLog {
+			return errors.New(i18n.G("The --show-log and --screenshot flags can't be used together"))
+		}
+
+		if cmd.Flags().Changed("type") && c.flagType != "vga" {
+			return errors.New(i18n.G("The --screenshot flag is only supported with the 'vga' output type"))
+		}
+	}
+
 	// Connect to the daemon.
 	remote, name, err := conf.ParseRemote(args[0])
 	if err != nil {
@@ -133,6 +153,11 @@ func (c *cmdConsole) Run(cmd *cobra.Command, args []string) error {
 }
 
 func (c *cmdConsole) console(d incus.InstanceServer, name string) error {
+	// Save a screenshot of the VGA console if requested.
+	if c.flagScreenshot != "" {
+		return c.screenshot(d, name)
+	}
+
 	// Show the current log if requested.
 	if c.flagShowLog {
 		if c.flagType != "console" {
@@ -169,6 +194,37 @@ func (c *cmdConsole) console(d incus.InstanceServer, name string) error {
 	return fmt.Errorf(i18n.G("Unknown console type %q"), c.flagType)
 }
 
+func (c *cmdConsole) screenshot(d incus.InstanceServer, name string) error {
+	content, err := d.GetInstanceConsoleLog(name, &incus.InstanceConsoleLogArgs{Type: "vga"})
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = content.Close() }()
+
+	return consoleWriteScreenshot(content, c.flagScreenshot)
+}
+
+// consoleWriteScreenshot checks that the screenshot is a valid PNG image and writes it to the target path.
+func consoleWriteScreenshot(r io.Reader, target string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf(i18n.G("Received an invalid screenshot: %w"), err)
+	}
+
+	err = os.WriteFile(target, data, 0o644)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to write screenshot to %q: %w"), target, err)
+	}
+
+	return nil
+}
+
 func (c *cmdConsole) text(d incus.InstanceServer, name string) error {
 	// Configure the terminal
 	cfd := int(os.Stdin.Fd())
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	incus "github.com/lxc/incus/v6/client"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// consoleTestPattern returns a checkerboard pattern similar to a VGA test screen.
+func consoleTestPattern() *image.RGBA {
+	pattern := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			if (x+y)%2 == 0 {
+				pattern.Set(x, y, color.White)
+			} else {
+				pattern.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	return pattern
+}
+
+// consoleTestRoundTripper serves the client requests with the given handler.
+type consoleTestRoundTripper struct {
+	handler http.Handler
+}
+
+func (rt consoleTestRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	rt.handler.ServeHTTP(recorder, req)
+
+	return recorder.Result(), nil
+}
+
+// consoleTestServer returns a client connected to a server with the given API extensions, serving the
+// screenshot for the VGA console of the "v1" instance.
+func consoleTestServer(t *testing.T, extensions []string, screenshot []byte) incus.InstanceServer {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /1.0", func(w http.ResponseWriter, r *http.Request) {
+		metadata, err := json.Marshal(api.Server{ServerUntrusted: api.ServerUntrusted{APIExtensions: extensions}})
+		require.NoError(t, err)
+
+		_ = json.NewEncoder(w).Encode(api.ResponseRaw{Type: api.SyncResponse, Status: api.Success.String(), StatusCode: int(api.Success), Metadata: json.RawMessage(metadata)})
+	})
+
+	mux.HandleFunc("GET /1.0/instances/v1/console", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("type") != "vga" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(api.ResponseRaw{Type: api.ErrorResponse, Code: http.StatusBadRequest, Error: "Console type must be vga"})
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(screenshot)
+	})
+
+	d, err := incus.ConnectIncusHTTP(nil, &http.Client{Transport: consoleTestRoundTripper{handler: mux}})
+	require.NoError(t, err)
+
+	return d
+}
+
+// Test that the screenshot is requested from the VGA console of the instance and saved to the target file.
+func TestConsoleScreenshot(t *testing.T) {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, consoleTestPattern())
+	require.NoError(t, err)
+
+	d := consoleTestServer(t, []string{"console", "instance_console_screenshot"}, buf.Bytes())
+
+	target := filepath.Join(t.TempDir(), "screen.png")
+	c := &cmdConsole{flagScreenshot: target, flagType: "console"}
+	err = c.console(d, "v1")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), content)
+}
+
+// Test that screenshots aren't requested from servers lacking the API extension.
+func TestConsoleScreenshot_MissingExtension(t *testing.T) {
+	d := consoleTestServer(t, []string{"console"}, nil)
+
+	target := filepath.Join(t.TempDir(), "screen.png")
+	c := &cmdConsole{flagScreenshot: target, flagType: "console"}
+	err := c.console(d, "v1")
+	assert.ErrorContains(t, err, "instance_console_screenshot")
+	assert.NoFileExists(t, target)
+}
+
+// Test that a VGA screenshot is written as received.
+func TestConsoleWriteScreenshot(t *testing.T) {
+	pattern := consoleTestPattern()
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, pattern)
+	require.NoError(t, err)
+
+	target := filepath.Join(t.TempDir(), "screen.png")
+	err = consoleWriteScreenshot(bytes.NewReader(buf.Bytes()), target)
+	require.NoError(t, err)
+
+	f, err := os.Open(target)
+	require.NoError(t, err)
+	defer f.Close()
+
+	screenshot, err := png.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, pattern.Bounds(), screenshot.Bounds())
+
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			r, g, b, _ := screenshot.At(x, y).RGBA()
+			wr, wg, wb, _ := pattern.At(x, y).RGBA()
+			assert.Equal(t, []uint32{wr, wg, wb}, []uint32{r, g, b}, "pixel %d,%d", x, y)
+		}
+	}
+}
+
+// Test that anything other than a PNG image is rejected.
+func TestConsoleWriteScreenshot_Invalid(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "screen.png")
+	err := consoleWriteScreenshot(strings.NewReader("There is no console to take a screendump from."), target)
+	assert.Error(t, err)
+	assert.NoFileExists(t, target)
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -32,6 +35,8 @@ type cmdCopy struct {
 	flagRefresh             bool
 	flagRefreshExcludeOlder bool
 	flagAllowInconsistent   bool
+	flagKeepIdentity        bool
+	flagStateful            bool
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -66,6 +71,8 @@ The pull transfer mode is the default as it is compatible with all server versio
 	cmd.Flags().BoolVar(&c.flagRefresh, "refresh", false, i18n.G("Perform an incremental copy"))
 	cmd.Flags().BoolVar(&c.flagRefreshExcludeOlder, "refresh-exclude-older", false, i18n.G("During incremental copy, exclude source snapshots earlier than latest target snapshot"))
 	cmd.Flags().BoolVar(&c.flagAllowInconsistent, "allow-inconsistent", false, i18n.G("Ignore copy errors for volatile files"))
+	cmd.Flags().BoolVar(&c.flagKeepIdentity, "keep-identity", false, i18n.G("Keep the MAC addresses and cloud-init instance ID of the source instance"))
+	cmd.Flags().BoolVar(&c.flagStateful, "stateful", false, i18n.G("Copy the running state of the instance into a new running instance"))
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
@@ -164,6 +171,8 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 
 	var op incus.RemoteOperation
 	var writable api.InstancePut
+	var sourceDevices map[string]map[string]string
+	var sourceConfig map[string]string
 	var start bool
 
 	if instance.IsSnapshot(sourceName) {
@@ -182,6 +191,10 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 			return errors.New(i18n.G("--refresh can only be used with instances"))
 		}
 
+		if c.flagStateful {
+			return errors.New(i18n.G("--stateful can only be used with instances"))
+		}
+
 		// Copy of a snapshot into a new instance
 		srcFields := strings.SplitN(sourceName, instance.SnapshotDelimiter, 2)
 		entry, _, err := source.GetInstanceSnapshot(srcFields[0], srcFields[1])
@@ -189,6 +202,9 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 			return err
 		}
 
+		sourceDevices = entry.ExpandedDevices
+		sourceConfig = maps.Clone(entry.Config)
+
 		// Overwrite profiles.
 		if c.flagProfile != nil {
 			entry.Profiles = c.flagProfile
@@ -245,7 +261,7 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 
 			if !keepVolatile {
 				for k := range entry.Config {
-					if !instance.InstanceIncludeWhenCopying(k, true) {
+					if !c.includeWhenCopying(k) {
 						delete(entry.Config, k)
 					}
 				}
@@ -271,6 +287,7 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 			Refresh:             c.flagRefresh,
 			RefreshExcludeOlder: c.flagRefreshExcludeOlder,
 			AllowInconsistent:   c.flagAllowInconsistent,
+			Stateful:            c.flagStateful,
 		}
 
 		// Copy of an instance into a new instance
@@ -279,6 +296,9 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 			return err
 		}
 
+		sourceDevices = entry.ExpandedDevices
+		sourceConfig = maps.Clone(entry.Config)
+
 		// Only start the instance back up if doing a stateless migration.
 		// It's the server's job to start things back up when receiving a stateful migration.
 		if entry.StatusCode == api.Running && move && !stateful {
@@ -337,7 +357,7 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 		// Strip the volatile keys if requested
 		if !keepVolatile {
 			for k := range entry.Config {
-				if !instance.InstanceIncludeWhenCopying(k, true) {
+				if !c.includeWhenCopying(k) {
 					delete(entry.Config, k)
 				}
 			}
@@ -382,6 +402,11 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 
 	progress.Done("")
 
+	// Static addresses aren't changed by the copy, warn about any the new instance shares with its source.
+	if !keepVolatile && destName != "" && sourceRemote == destRemote && c.flagTargetProject == "" {
+		c.warnAddressConflicts(dest, destName, sourceConfig, sourceDevices)
+	}
+
 	if c.flagRefresh {
 		inst, etag, err := dest.GetInstance(destName)
 		if err != nil {
@@ -447,6 +472,75 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 	return nil
 }
 
+// includeWhenCopying returns whether the config key should be kept on a new copy of an instance.
+func (c *cmdCopy) includeWhenCopying(configKey string) bool {
+	if c.flagKeepIdentity && instance.InstanceIdentityKey(configKey) {
+		return true
+	}
+
+	return instance.InstanceIncludeWhenCopying(configKey, true)
+}
+
+// warnAddressConflicts prints a warning for every NIC of the copied instance using the same MAC address or static
+// IP address on the same network as its source. The server refuses to start the copy until the conflict is resolved.
+func (c *cmdCopy) warnAddressConflicts(dest incus.InstanceServer, destName string, sourceConfig map[string]string, sourceDevices map[string]map[string]string) {
+	inst, _, err := dest.GetInstance(destName)
+	if err != nil {
+		return
+	}
+
+	nicNetwork := func(dev map[string]string) string {
+		if dev["network"] != "" {
+			return dev["network"]
+		}
+
+		return dev["parent"]
+	}
+
+	nicMAC := func(config map[string]string, devName string, dev map[string]string) string {
+		if dev["hwaddr"] != "" {
+			return dev["hwaddr"]
+		}
+
+		return config[fmt.Sprintf("volatile.%s.hwaddr", devName)]
+	}
+
+	devNames := make([]string, 0, len(inst.ExpandedDevices))
+	for devName := range inst.ExpandedDevices {
+		devNames = append(devNames, devName)
+	}
+
+	slices.Sort(devNames)
+
+	for _, devName := range devNames {
+		dev := inst.ExpandedDevices[devName]
+		network := nicNetwork(dev)
+		if dev["type"] != "nic" || network == "" {
+			continue
+		}
+
+		for sourceDevName, sourceDev := range sourceDevices {
+			if sourceDev["type"] != "nic" || nicNetwork(sourceDev) != network {
+				continue
+			}
+
+			mac := nicMAC(inst.Config, devName, dev)
+			if mac != "" && strings.EqualFold(mac, nicMAC(sourceConfig, sourceDevName, sourceDev)) {
+				fmt.Fprintf(os.Stderr, i18n.G("WARNING: Device %q uses the same MAC address %s on %q as the source instance")+"\n", devName, mac, network)
+			}
+
+			for _, key := range []string{"ipv4.address", "ipv6.address"} {
+				address := dev[key]
+				if address == "" || address == "none" || sourceDev[key] != address {
+					continue
+				}
+
+				fmt.Fprintf(os.Stderr, i18n.G("WARNING: Device %q uses the same static address %s on %q as the source instance")+"\n", devName, address, network)
+			}
+		}
+	}
+}
+
 // Run runs the actual command logic.
 func (c *cmdCopy) Run(cmd *cobra.Command, args []string) error {
 	conf := c.global.conf
@@ -469,6 +563,10 @@ func (c *cmdCopy) Run(cmd *cobra.Command, args []string) error {
 		mode = c.flagMode
 	}
 
+	if c.flagStateful && (c.flagStateless || c.flagRefresh) {
+		return errors.New(i18n.G("--stateful can't be used with --stateless or --refresh"))
+	}
+
 	stateful := !c.flagStateless && !c.flagRefresh
 	keepVolatile := c.flagRefresh
 	instanceOnly := c.flagInstanceOnly
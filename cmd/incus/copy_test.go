@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyTestConfig returns the config keys of the source instance kept on its copy with the given arguments.
+func copyTestConfig(t *testing.T, args ...string) map[string]string {
+	c := &cmdCopy{global: &cmdGlobal{}}
+
+	cmd := c.Command()
+	require.NoError(t, cmd.ParseFlags(args))
+
+	config := map[string]string{
+		"limits.cpu":                      "2",
+		"volatile.base_image":             "abcdef",
+		"volatile.uuid":                   "7c5a9fbc-ef02-4bd7-9e0c-ac9a1a3bb1a5",
+		"volatile.eth0.hwaddr":            "10:66:6a:01:02:03",
+		"volatile.eth0.host_name":         "veth1234",
+		"volatile.eth0.last_state.hwaddr": "10:66:6a:04:05:06",
+		"volatile.cloud-init.instance-id": "0ec4b8c2-0e7d-4b36-8d0a-e55e76d54b88",
+	}
+
+	kept := map[string]string{}
+	for k, v := range config {
+		if c.includeWhenCopying(k) {
+			kept[k] = v
+		}
+	}
+
+	return kept
+}
+
+// Test that the MAC addresses and cloud-init instance ID of the source are regenerated by default.
+func TestCopyIncludeWhenCopying(t *testing.T) {
+	kept := copyTestConfig(t)
+
+	assert.Equal(t, map[string]string{
+		"limits.cpu":          "2",
+		"volatile.base_image": "abcdef",
+	}, kept)
+}
+
+// Test that --keep-identity keeps the MAC addresses and cloud-init instance ID, and only those.
+func TestCopyIncludeWhenCopyingKeepIdentity(t *testing.T) {
+	kept := copyTestConfig(t, "--keep-identity")
+
+	assert.Equal(t, map[string]string{
+		"limits.cpu":                      "2",
+		"volatile.base_image":             "abcdef",
+		"volatile.eth0.hwaddr":            "10:66:6a:01:02:03",
+		"volatile.cloud-init.instance-id": "0ec4b8c2-0e7d-4b36-8d0a-e55e76d54b88",
+	}, kept)
+}
@@ -27,6 +27,8 @@ type cmdExec struct {
 	flagForceInteractive    bool
 	flagForceNonInteractive bool
 	flagDisableStdin        bool
+	flagStdinFile           string
+	flagPty                 bool
 	flagUser                uint32
 	flagGroup               uint32
 	flagCwd                 string
@@ -54,7 +56,10 @@ Mode defaults to non-interactive, interactive mode is selected if both stdin AND
 	Run the "bash" command in instance "c1"
 
 incus exec c1 -- ls -lh /
-	Run the "ls -lh /" command in instance "c1"`))
+	Run the "ls -lh /" command in instance "c1"
+
+incus exec c1 --stdin-file script.sh --pty -- sh
+	Run "sh" in instance "c1" on a pseudo-terminal, feeding it the content of "script.sh"`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().StringArrayVar(&c.flagEnvironment, "env", nil, i18n.G("Environment variable to set (e.g. HOME=/home/foo)")+"``")
@@ -62,6 +67,8 @@ incus exec c1 -- ls -lh /
 	cmd.Flags().BoolVarP(&c.flagForceInteractive, "force-interactive", "t", false, i18n.G("Force pseudo-terminal allocation"))
 	cmd.Flags().BoolVarP(&c.flagForceNonInteractive, "force-noninteractive", "T", false, i18n.G("Disable pseudo-terminal allocation"))
 	cmd.Flags().BoolVarP(&c.flagDisableStdin, "disable-stdin", "n", false, i18n.G("Disable stdin (reads from /dev/null)"))
+	cmd.Flags().StringVar(&c.flagStdinFile, "stdin-file", "", i18n.G("Read stdin from a file")+"``")
+	cmd.Flags().BoolVar(&c.flagPty, "pty", false, i18n.G("Allocate a pseudo-terminal, even when stdin isn't a terminal"))
 	cmd.Flags().Uint32Var(&c.flagUser, "user", 0, i18n.G("User ID to run the command as (default 0)")+"``")
 	cmd.Flags().Uint32Var(&c.flagGroup, "group", 0, i18n.G("Group ID to run the command as (default 0)")+"``")
 	cmd.Flags().StringVar(&c.flagCwd, "cwd", "", i18n.G("Directory to run the command in (default /root)")+"``")
@@ -112,6 +119,24 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 		return errors.New(i18n.G("You can't pass -t or -T at the same time as --mode"))
 	}
 
+	if c.flagPty && (c.flagForceNonInteractive || c.flagMode == "non-interactive") {
+		return errors.New(i18n.G("You can't pass --pty with -T or a non-interactive --mode"))
+	}
+
+	if c.flagStdinFile != "" && c.flagDisableStdin {
+		return errors.New(i18n.G("You can't pass --stdin-file and -n at the same time"))
+	}
+
+	var stdinFile *os.File
+	if c.flagStdinFile != "" {
+		stdinFile, err = os.Open(c.flagStdinFile)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = stdinFile.Close() }()
+	}
+
 	// Connect to the daemon
 	remote, name, err := conf.ParseRemote(args[0])
 	if err != nil {
@@ -144,13 +169,13 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 	stdinFd := getStdinFd()
 	stdoutFd := getStdoutFd()
 
-	stdinTerminal := termios.IsTerminal(stdinFd)
+	stdinTerminal := stdinFile == nil && termios.IsTerminal(stdinFd)
 	stdoutTerminal := termios.IsTerminal(stdoutFd)
 
 	// Determine interaction mode
 	if c.flagDisableStdin {
 		c.interactive = false
-	} else if c.flagMode == "interactive" || c.flagForceInteractive {
+	} else if c.flagMode == "interactive" || c.flagForceInteractive || c.flagPty {
 		c.interactive = true
 	} else if c.flagMode == "non-interactive" || c.flagForceNonInteractive {
 		c.interactive = false
@@ -185,6 +210,13 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 	stdin = os.Stdin
 	if c.flagDisableStdin {
 		stdin = bytes.NewReader(nil)
+	} else if stdinFile != nil {
+		stdin = stdinFile
+
+		// The pseudo-terminal doesn't see the end of the file, have it sent as the terminal's end-of-file character.
+		if c.interactive {
+			stdin = &execPtyReader{reader: stdinFile}
+		}
 	}
 
 	stdout := getStdout()
@@ -235,3 +267,51 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// execPtyReader wraps a reader feeding a pseudo-terminal, following its content with the end-of-file
+// character (Ctrl-D) so the process reading from the terminal sees end-of-input.
+type execPtyReader struct {
+	reader  io.Reader
+	last    byte
+	pending []byte
+	done    bool
+}
+
+// Read reads from the wrapped reader and, once it's exhausted, returns the end-of-file characters.
+func (r *execPtyReader) Read(p []byte) (int, error) {
+	if !r.done {
+		n, err := r.reader.Read(p)
+		if n > 0 {
+			r.last = p[n-1]
+		}
+
+		if err == nil {
+			return n, nil
+		}
+
+		if err != io.EOF {
+			return n, err
+		}
+
+		r.done = true
+
+		// In canonical mode, Ctrl-D on a partial line only flushes it, a second one is needed for end-of-input.
+		r.pending = []byte{0x04}
+		if r.last != 0 && r.last != '\n' {
+			r.pending = append(r.pending, 0x04)
+		}
+
+		if n > 0 {
+			return n, nil
+		}
+	}
+
+	if len(r.pending) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+
+	return n, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the content fed to a pseudo-terminal is followed by the end-of-file characters it needs.
+func TestExecPtyReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Empty file",
+			input:    "",
+			expected: "\x04",
+		},
+		{
+			name:     "Complete line",
+			input:    "echo foo\n",
+			expected: "echo foo\n\x04",
+		},
+		{
+			name:     "Partial line",
+			input:    "echo foo\necho bar",
+			expected: "echo foo\necho bar\x04\x04",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := &execPtyReader{reader: strings.NewReader(test.input)}
+
+			out, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(out))
+
+			// Further reads keep reporting the end of the stream.
+			n, err := reader.Read(make([]byte, 1))
+			assert.Equal(t, 0, n)
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+// Test that data returned along with io.EOF and single byte reads are handled.
+func TestExecPtyReaderShortReads(t *testing.T) {
+	reader := &execPtyReader{reader: iotest.DataErrReader(strings.NewReader("abc"))}
+	out, err := io.ReadAll(iotest.OneByteReader(reader))
+	require.NoError(t, err)
+	assert.Equal(t, "abc\x04\x04", string(out))
+}
+
+// Test that read errors are passed through.
+func TestExecPtyReaderError(t *testing.T) {
+	reader := &execPtyReader{reader: iotest.ErrReader(iotest.ErrTimeout)}
+	_, err := reader.Read(make([]byte, 16))
+	assert.ErrorIs(t, err, iotest.ErrTimeout)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/termios"
 )
 
 func (c *cmdExec) getTERM() (string, bool) {
@@ -37,6 +38,15 @@ func (c *cmdExec) controlSocketHandler(control *websocket.Conn) {
 	closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
 	defer func() { _ = control.WriteMessage(websocket.CloseMessage, closeMsg) }()
 
+	// Catch up on any resize which happened while the session was being set up.
+	if c.interactive && termios.IsTerminal(getStdoutFd()) {
+		err := c.sendTermSize(control)
+		if err != nil {
+			logger.Debugf("error setting term size %s", err)
+			return
+		}
+	}
+
 	for {
 		sig := <-ch
 
@@ -47,6 +57,11 @@ func (c *cmdExec) controlSocketHandler(control *websocket.Conn) {
 				continue
 			}
 
+			if !termios.IsTerminal(getStdoutFd()) {
+				// There is no window to track when the output is redirected.
+				continue
+			}
+
 			logger.Debugf("Received '%s signal', updating window geometry.", sig)
 			err := c.sendTermSize(control)
 			if err != nil {
@@ -6,12 +6,14 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"golang.org/x/sys/windows"
 
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/termios"
 )
 
 // Windows doesn't process ANSI sequences natively, so we wrap
@@ -29,6 +31,9 @@ func (c *cmdExec) getTERM() (string, bool) {
 	return "dumb", true
 }
 
+// execResizeInterval is how often the console size is checked for changes, as Windows doesn't signal them.
+const execResizeInterval = 250 * time.Millisecond
+
 func (c *cmdExec) controlSocketHandler(control *websocket.Conn) {
 	ch := make(chan os.Signal, 10)
 	signal.Notify(ch, os.Interrupt)
@@ -36,19 +41,47 @@ func (c *cmdExec) controlSocketHandler(control *websocket.Conn) {
 	closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
 	defer control.WriteMessage(websocket.CloseMessage, closeMsg)
 
+	// Poll the console size to keep the remote terminal in sync, there is no window to track when the output
+	// is redirected. The first check catches up on any resize which happened while the session was being set up.
+	var resize <-chan time.Time
+	if c.interactive && termios.IsTerminal(getStdoutFd()) {
+		ticker := time.NewTicker(execResizeInterval)
+		defer ticker.Stop()
+
+		resize = ticker.C
+	}
+
+	var width, height int
 	for {
-		sig := <-ch
-		switch sig {
-		case os.Interrupt:
-			logger.Debugf("Received '%s signal', forwarding to executing program.", sig)
-			err := c.forwardSignal(control, windows.SIGINT)
+		select {
+		case sig := <-ch:
+			switch sig {
+			case os.Interrupt:
+				logger.Debugf("Received '%s signal', forwarding to executing program.", sig)
+				err := c.forwardSignal(control, windows.SIGINT)
+				if err != nil {
+					logger.Debugf("Failed to forward signal '%s'.", windows.SIGINT)
+					return
+				}
+
+			default:
+				break
+			}
+
+		case <-resize:
+			newWidth, newHeight, err := termios.GetSize(getStdoutFd())
+			if err != nil || (newWidth == width && newHeight == height) {
+				continue
+			}
+
+			width, height = newWidth, newHeight
+
+			logger.Debugf("Console resized, updating window geometry.")
+			err = c.sendTermSize(control)
 			if err != nil {
-				logger.Debugf("Failed to forward signal '%s'.", windows.SIGINT)
+				logger.Debugf("error setting term size %s", err)
 				return
 			}
-
-		default:
-			break
 		}
 	}
 }
@@ -23,6 +23,8 @@ type cmdExport struct {
 	flagInstanceOnly         bool
 	flagOptimizedStorage     bool
 	flagCompressionAlgorithm string
+	flagEncrypt              bool
+	flagPassphraseFile       string
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -34,7 +36,10 @@ func (c *cmdExport) Command() *cobra.Command {
 		`Export instances as backup tarballs.`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`incus export u1 backup0.tar.gz
-    Download a backup tarball of the u1 instance.`))
+    Download a backup tarball of the u1 instance.
+
+incus export u1 backup0.tar.gz.enc --encrypt
+    Download a backup of the u1 instance, encrypted with a passphrase asked for interactively.`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().BoolVar(&c.flagInstanceOnly, "instance-only", false,
@@ -42,6 +47,8 @@ func (c *cmdExport) Command() *cobra.Command {
 	cmd.Flags().BoolVar(&c.flagOptimizedStorage, "optimized-storage", false,
 		i18n.G("Use storage driver optimized format (can only be restored on a similar pool)"))
 	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", i18n.G("Compression algorithm to use (none for uncompressed)")+"``")
+	cmd.Flags().BoolVar(&c.flagEncrypt, "encrypt", false, i18n.G("Encrypt the backup with a passphrase"))
+	cmd.Flags().StringVar(&c.flagPassphraseFile, "passphrase-file", "", i18n.G("File to read the encryption passphrase from (implies --encrypt)")+"``")
 
 	return cmd
 }
@@ -69,12 +76,27 @@ func (c *cmdExport) Run(cmd *cobra.Command, args []string) error {
 
 	instanceOnly := c.flagInstanceOnly
 
+	// Get and validate the passphrase before starting the backup.
+	var passphrase string
+	if c.flagEncrypt || c.flagPassphraseFile != "" {
+		passphrase, err = getBackupPassphrase(&c.global.asker, c.flagPassphraseFile, true)
+		if err != nil {
+			return err
+		}
+
+		err = archive.ValidateEncryptionPassphrase(passphrase)
+		if err != nil {
+			return err
+		}
+	}
+
 	req := api.InstanceBackupsPost{
 		Name:                 "",
 		ExpiresAt:            time.Now().Add(24 * time.Hour),
 		InstanceOnly:         instanceOnly,
 		OptimizedStorage:     c.flagOptimizedStorage,
 		CompressionAlgorithm: c.flagCompressionAlgorithm,
+		EncryptionPassphrase: passphrase,
 	}
 
 	op, err := d.CreateInstanceBackup(name, req)
@@ -174,9 +196,13 @@ func (c *cmdExport) Run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		_, ext, _, err := archive.DetectCompressionFile(target)
-		if err != nil {
-			return err
+		// Encrypted backups don't reveal their compression.
+		ext := ".enc"
+		if passphrase == "" {
+			_, ext, _, err = archive.DetectCompressionFile(target)
+			if err != nil {
+				return err
+			}
 		}
 
 		err = os.Rename(targetName, name+ext)
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
@@ -80,7 +81,7 @@ func (c *cmdFile) Command() *cobra.Command {
 	cmd.AddCommand(filePushCmd.Command())
 
 	// Edit
-	fileEditCmd := cmdFileEdit{global: c.global, file: c, filePull: &filePullCmd, filePush: &filePushCmd}
+	fileEditCmd := cmdFileEdit{global: c.global, file: c, filePush: &filePushCmd}
 	cmd.AddCommand(fileEditCmd.Command())
 
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
@@ -378,7 +379,6 @@ func (c *cmdFileDelete) Run(cmd *cobra.Command, args []string) error {
 type cmdFileEdit struct {
 	global   *cmdGlobal
 	file     *cmdFile
-	filePull *cmdFilePull
 	filePush *cmdFilePush
 }
 
@@ -388,7 +388,10 @@ func (c *cmdFileEdit) Command() *cobra.Command {
 	cmd.Use = usage("edit", i18n.G("[<remote>:]<instance>/<path>"))
 	cmd.Short = i18n.G("Edit files in instances")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Edit files in instances`))
+		`Edit files in instances
+
+The file is created if it doesn't exist yet. Its mode and ownership are kept
+when it's written back, and binary files are refused.`))
 
 	cmd.RunE = c.Run
 
@@ -418,48 +421,136 @@ func (c *cmdFileEdit) Run(cmd *cobra.Command, args []string) error {
 		return c.filePush.Run(cmd, append([]string{os.Stdin.Name()}, args[0]))
 	}
 
+	pathSpec := strings.SplitN(args[0], "/", 2)
+	if len(pathSpec) != 2 {
+		return fmt.Errorf(i18n.G("Invalid path %s"), args[0])
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(pathSpec[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// Connect to SFTP.
+	sftpConn, err := resource.server.GetInstanceFileSFTP(resource.name)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = sftpConn.Close() }()
+
+	return c.edit(sftpConn, filepath.Clean("/"+pathSpec[1]), textEditor)
+}
+
+// edit lets the user modify the file in the instance through the given editor and writes the result back.
+func (c *cmdFileEdit) edit(sftpConn *sftp.Client, targetPath string, editor func(path string, content []byte) ([]byte, error)) error {
+	// Get the current content, starting from an empty file if it doesn't exist yet.
+	content, err := fileEditRead(sftpConn, targetPath)
+	if err != nil {
+		return err
+	}
+
+	if fileEditIsBinary(content) {
+		return fmt.Errorf(i18n.G("Refusing to edit binary file %q"), targetPath)
+	}
+
 	// Create temp file
-	f, err := os.CreateTemp("", fmt.Sprintf("incus_file_edit_*%s", filepath.Ext(args[0])))
+	f, err := os.CreateTemp("", fmt.Sprintf("incus_file_edit_*%s", filepath.Ext(targetPath)))
 	if err != nil {
 		return fmt.Errorf(i18n.G("Unable to create a temporary file: %v"), err)
 	}
 
 	fname := f.Name()
-	_ = f.Close()
-	_ = os.Remove(fname)
+	defer func() { _ = os.Remove(fname) }()
 
-	// Tell pull/push that they're called from edit.
-	c.filePull.edit = true
-	c.filePush.edit = true
+	_, err = f.Write(content)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
 
-	// Extract current value
-	defer func() { _ = os.Remove(fname) }()
-	err = c.filePull.Run(cmd, append([]string{args[0]}, fname))
+	err = f.Close()
 	if err != nil {
 		return err
 	}
 
 	// Spawn the editor
-	_, err = textEditor(fname, []byte{})
+	newContent, err := editor(fname, []byte{})
 	if err != nil {
 		return err
 	}
 
-	// Push the result
-	err = c.filePush.Run(cmd, append([]string{fname}, args[0]))
+	// Nothing to push if the file wasn't modified.
+	if bytes.Equal(content, newContent) {
+		return nil
+	}
+
+	// Check that the file wasn't modified in the instance while being edited.
+	currentContent, err := fileEditRead(sftpConn, targetPath)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if sha256.Sum256(currentContent) != sha256.Sum256(content) {
+		overwrite, err := c.global.asker.AskBool(fmt.Sprintf(i18n.G("The file %q was modified in the instance while being edited, overwrite it?"), targetPath)+" (yes/no) [default=no]: ", "no")
+		if err != nil {
+			return err
+		}
+
+		if !overwrite {
+			return fmt.Errorf(i18n.G("File %q was modified in the instance, not overwriting it"), targetPath)
+		}
+	}
+
+	// Write the new content, keeping the mode and ownership of existing files.
+	fileArgs := incus.InstanceFileArgs{
+		Type:    "file",
+		UID:     -1,
+		GID:     -1,
+		Mode:    -1,
+		Content: bytes.NewReader(newContent),
+	}
+
+	return c.file.sftpCreateFile(sftpConn, targetPath, fileArgs, true)
+}
+
+// fileEditRead returns the content of a file in the instance, or nothing if it doesn't exist.
+func fileEditRead(sftpConn *sftp.Client, targetPath string) ([]byte, error) {
+	file, err := sftpConn.Open(targetPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []byte{}, nil
+		}
+
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if !fileInfo.Mode().IsRegular() {
+		return nil, fmt.Errorf(i18n.G("%q isn't a regular file"), targetPath)
+	}
+
+	return io.ReadAll(file)
+}
+
+// fileEditIsBinary returns whether the content looks like binary data rather than text.
+func fileEditIsBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0
 }
 
 // Pull.
 type cmdFilePull struct {
 	global *cmdGlobal
 	file   *cmdFile
-
-	edit bool
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -596,7 +687,16 @@ func (c *cmdFilePull) Run(cmd *cobra.Command, args []string) error {
 					targetIsDir = true
 				}
 
-				err := c.file.recursivePullFile(sftpConn, pathSpec[1], target)
+				// Compute the total size for progress reporting.
+				total := &fileTransferTotal{}
+				walker := sftpConn.Walk(pathSpec[1])
+				for walker.Step() {
+					if walker.Err() == nil && walker.Stat().Mode().IsRegular() {
+						total.total += walker.Stat().Size()
+					}
+				}
+
+				err := c.file.recursivePullFile(sftpConn, pathSpec[1], target, total)
 				if err != nil {
 					return err
 				}
@@ -692,7 +792,6 @@ type cmdFilePush struct {
 	global *cmdGlobal
 	file   *cmdFile
 
-	edit         bool
 	noModeChange bool
 }
 
@@ -818,9 +917,24 @@ func (c *cmdFilePush) Run(cmd *cobra.Command, args []string) error {
 			}
 		}
 
+		// Compute the total size for progress reporting.
+		total := &fileTransferTotal{}
+		for _, fname := range sourcefilenames {
+			err := filepath.Walk(fname, func(_ string, fInfo os.FileInfo, err error) error {
+				if err == nil && fInfo.Mode().IsRegular() {
+					total.total += fInfo.Size()
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
 		// Transfer the files
 		for _, fname := range sourcefilenames {
-			err := c.file.recursivePushFile(sftpConn, fname, targetPath)
+			err := c.file.recursivePushFile(sftpConn, fname, targetPath, total)
 			if err != nil {
 				return err
 			}
@@ -1074,7 +1188,22 @@ func (c *cmdFile) sftpCreateFile(sftpConn *sftp.Client, targetPath string, args
 	return nil
 }
 
-func (c *cmdFile) recursivePullFile(sftpConn *sftp.Client, p string, targetDir string) error {
+// fileTransferTotal tracks the overall progress of a recursive file transfer.
+type fileTransferTotal struct {
+	done  int64
+	total int64
+}
+
+// text renders the progress of the current file along with the overall progress.
+func (t *fileTransferTotal) text(current int64, speed int64) string {
+	return fmt.Sprintf(i18n.G("%s (%s/s), %s of %s in total"),
+		units.GetByteSizeString(current, 2),
+		units.GetByteSizeString(speed, 2),
+		units.GetByteSizeString(t.done+current, 2),
+		units.GetByteSizeString(t.total, 2))
+}
+
+func (c *cmdFile) recursivePullFile(sftpConn *sftp.Client, p string, targetDir string, total *fileTransferTotal) error {
 	fInfo, err := sftpConn.Lstat(p)
 	if err != nil {
 		return err
@@ -1106,7 +1235,7 @@ func (c *cmdFile) recursivePullFile(sftpConn *sftp.Client, p string, targetDir s
 		for _, ent := range entries {
 			nextP := filepath.Join(p, ent.Name())
 
-			err := c.recursivePullFile(sftpConn, nextP, target)
+			err := c.recursivePullFile(sftpConn, nextP, target, total)
 			if err != nil {
 				return err
 			}
@@ -1141,9 +1270,7 @@ func (c *cmdFile) recursivePullFile(sftpConn *sftp.Client, p string, targetDir s
 			Tracker: &ioprogress.ProgressTracker{
 				Handler: func(bytesReceived int64, speed int64) {
 					progress.UpdateProgress(ioprogress.ProgressData{
-						Text: fmt.Sprintf("%s (%s/s)",
-							units.GetByteSizeString(bytesReceived, 2),
-							units.GetByteSizeString(speed, 2)),
+						Text: total.text(bytesReceived, speed),
 					})
 				},
 			},
@@ -1162,6 +1289,8 @@ func (c *cmdFile) recursivePullFile(sftpConn *sftp.Client, p string, targetDir s
 			}
 		}
 
+		total.done += fInfo.Size()
+
 		err = src.Close()
 		if err != nil {
 			progress.Done("")
@@ -1192,7 +1321,7 @@ func (c *cmdFile) recursivePullFile(sftpConn *sftp.Client, p string, targetDir s
 	return nil
 }
 
-func (c *cmdFile) recursivePushFile(sftpConn *sftp.Client, source string, target string) error {
+func (c *cmdFile) recursivePushFile(sftpConn *sftp.Client, source string, target string, total *fileTransferTotal) error {
 	source = filepath.Clean(source)
 
 	sourceDir, _ := filepath.Split(source)
@@ -1270,15 +1399,17 @@ func (c *cmdFile) recursivePushFile(sftpConn *sftp.Client, source string, target
 			args.Content = internalIO.NewReadSeeker(&ioprogress.ProgressReader{
 				ReadCloser: readCloser,
 				Tracker: &ioprogress.ProgressTracker{
-					Length: contentLength,
-					Handler: func(percent int64, speed int64) {
+					Handler: func(bytesSent int64, speed int64) {
 						progress.UpdateProgress(ioprogress.ProgressData{
-							Text: fmt.Sprintf("%d%% (%s/s)", percent,
-								units.GetByteSizeString(speed, 2)),
+							Text: total.text(bytesSent, speed),
 						})
 					},
 				},
 			}, args.Content)
+
+			if args.Type == "file" {
+				defer func() { total.done += contentLength }()
+			}
 		}
 
 		logger.Infof("Pushing %s to %s (%s)", p, targetPath, args.Type)
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/ask"
+)
+
+// fileEditTestSFTP returns a client connected to an in-memory SFTP server standing for the instance.
+func fileEditTestSFTP(t *testing.T) *sftp.Client {
+	clientConn, serverConn := net.Pipe()
+
+	server := sftp.NewRequestServer(serverConn, sftp.InMemHandler())
+	go func() { _ = server.Serve() }()
+
+	client, err := sftp.NewClientPipe(clientConn, clientConn)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+	})
+
+	return client
+}
+
+// fileEditTestWrite sets the content of a file in the instance.
+func fileEditTestWrite(t *testing.T, sftpConn *sftp.Client, path string, content string) {
+	file, err := sftpConn.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	require.NoError(t, err)
+
+	_, err = file.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
+
+// fileEditTestRead returns the content of a file in the instance.
+func fileEditTestRead(t *testing.T, sftpConn *sftp.Client, path string) string {
+	file, err := sftpConn.Open(path)
+	require.NoError(t, err)
+
+	defer func() { _ = file.Close() }()
+
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+// fileEditTestEditor returns an editor replacing the content of the edited file, after running the hook if any.
+func fileEditTestEditor(t *testing.T, original *string, content string, hook func()) func(path string, _ []byte) ([]byte, error) {
+	return func(path string, _ []byte) ([]byte, error) {
+		current, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		if original != nil {
+			*original = string(current)
+		}
+
+		if hook != nil {
+			hook()
+		}
+
+		return []byte(content), nil
+	}
+}
+
+func fileEditTestCmd(answer string) *cmdFileEdit {
+	global := &cmdGlobal{asker: ask.NewAsker(bufio.NewReader(strings.NewReader(answer)))}
+
+	return &cmdFileEdit{global: global, file: &cmdFile{global: global}}
+}
+
+// Test that the file is edited in place in the instance.
+func TestFileEdit(t *testing.T) {
+	sftpConn := fileEditTestSFTP(t)
+	fileEditTestWrite(t, sftpConn, "/motd", "Hello\n")
+
+	var original string
+	err := fileEditTestCmd("").edit(sftpConn, "/motd", fileEditTestEditor(t, &original, "Hello world\n", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello\n", original)
+	assert.Equal(t, "Hello world\n", fileEditTestRead(t, sftpConn, "/motd"))
+}
+
+// Test that missing files are created.
+func TestFileEditCreate(t *testing.T) {
+	sftpConn := fileEditTestSFTP(t)
+
+	var original string
+	err := fileEditTestCmd("").edit(sftpConn, "/motd", fileEditTestEditor(t, &original, "Hello\n", nil))
+	require.NoError(t, err)
+
+	assert.Empty(t, original)
+	assert.Equal(t, "Hello\n", fileEditTestRead(t, sftpConn, "/motd"))
+}
+
+// Test that nothing is written back when the content wasn't changed.
+func TestFileEditUnchanged(t *testing.T) {
+	sftpConn := fileEditTestSFTP(t)
+	fileEditTestWrite(t, sftpConn, "/motd", "Hello\n")
+
+	// A change made in the instance meanwhile isn't overwritten by the unchanged content.
+	err := fileEditTestCmd("").edit(sftpConn, "/motd", fileEditTestEditor(t, nil, "Hello\n", func() {
+		fileEditTestWrite(t, sftpConn, "/motd", "Changed\n")
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Changed\n", fileEditTestRead(t, sftpConn, "/motd"))
+}
+
+// Test that the user is asked before overwriting a file modified in the instance while being edited.
+func TestFileEditConflict(t *testing.T) {
+	sftpConn := fileEditTestSFTP(t)
+	fileEditTestWrite(t, sftpConn, "/motd", "Hello\n")
+
+	editor := fileEditTestEditor(t, nil, "Hello world\n", func() {
+		fileEditTestWrite(t, sftpConn, "/motd", "Changed\n")
+	})
+
+	err := fileEditTestCmd("no\n").edit(sftpConn, "/motd", editor)
+	assert.Error(t, err)
+	assert.Equal(t, "Changed\n", fileEditTestRead(t, sftpConn, "/motd"))
+
+	fileEditTestWrite(t, sftpConn, "/motd", "Hello\n")
+
+	err = fileEditTestCmd("yes\n").edit(sftpConn, "/motd", editor)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world\n", fileEditTestRead(t, sftpConn, "/motd"))
+}
+
+// Test that binary files are refused without spawning the editor.
+func TestFileEditBinary(t *testing.T) {
+	sftpConn := fileEditTestSFTP(t)
+	fileEditTestWrite(t, sftpConn, "/bin", "ELF\x00\x01")
+
+	err := fileEditTestCmd("").edit(sftpConn, "/bin", func(path string, _ []byte) ([]byte, error) {
+		t.Fatal("Editor spawned for a binary file")
+		return nil, nil
+	})
+	assert.ErrorContains(t, err, "binary")
+	assert.Equal(t, "ELF\x00\x01", fileEditTestRead(t, sftpConn, "/bin"))
+}
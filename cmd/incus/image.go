@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -24,6 +25,7 @@ import (
 	"github.com/lxc/incus/v6/shared/archive"
 	"github.com/lxc/incus/v6/shared/subprocess"
 	"github.com/lxc/incus/v6/shared/termios"
+	"github.com/lxc/incus/v6/shared/units"
 	"github.com/lxc/incus/v6/shared/util"
 )
 
@@ -62,6 +64,10 @@ hash or alias name (if one is set).`))
 	imageAliasCmd := cmdImageAlias{global: c.global, image: c}
 	cmd.AddCommand(imageAliasCmd.Command())
 
+	// Build
+	imageBuildCmd := cmdImageBuild{global: c.global, image: c}
+	cmd.AddCommand(imageBuildCmd.Command())
+
 	// Copy
 	imageCopyCmd := cmdImageCopy{global: c.global, image: c}
 	cmd.AddCommand(imageCopyCmd.Command())
@@ -129,6 +135,256 @@ func (c *cmdImage) dereferenceAlias(d incus.ImageServer, imageType string, inNam
 	return result.Target
 }
 
+// Build.
+type cmdImageBuild struct {
+	global *cmdGlobal
+	image  *cmdImage
+
+	flagAliases     []string
+	flagArgs        []string
+	flagPublic      bool
+	flagNoCache     bool
+	flagCompression string
+}
+
+// imageBuildRecipe is the format of the recipe files read by "incus image build".
+type imageBuildRecipe struct {
+	Base       string            `yaml:"base"`
+	Type       string            `yaml:"type"`
+	Profiles   []string          `yaml:"profiles"`
+	Config     map[string]string `yaml:"config"`
+	Args       map[string]string `yaml:"args"`
+	Properties map[string]string `yaml:"properties"`
+	Steps      []struct {
+		Exec        []string          `yaml:"exec"`
+		Run         string            `yaml:"run"`
+		Environment map[string]string `yaml:"environment"`
+		Cwd         string            `yaml:"cwd"`
+		Cache       bool              `yaml:"cache"`
+		File        *struct {
+			api.ImageBuildFile `yaml:",inline"`
+
+			Source string `yaml:"source"`
+		} `yaml:"file"`
+	} `yaml:"steps"`
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdImageBuild) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("build", i18n.G("<recipe> [<remote>:]"))
+	cmd.Short = i18n.G("Build an image from a recipe")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Build an image from a recipe
+
+The recipe is a YAML file listing the base image and the steps to run to turn it into the new image.
+The server creates a temporary instance from the base image, runs the steps in it and publishes the result.
+
+Steps either run a command ("exec" or "run" for a shell command) or create a file ("file").
+Build arguments declared in the recipe can be referenced as ${name} in the steps.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus image build recipe.yaml --alias web
+    Build the image described in recipe.yaml and add the "web" alias to it.
+
+incus image build recipe.yaml remote: --arg version=1.2 --no-cache
+    Build the image on "remote:" with the "version" build argument set, ignoring any cached step.`))
+
+	cmd.Flags().StringArrayVar(&c.flagAliases, "alias", nil, i18n.G("New aliases to add to the image")+"``")
+	cmd.Flags().StringArrayVar(&c.flagArgs, "arg", nil, i18n.G("Build argument (key=value)")+"``")
+	cmd.Flags().BoolVar(&c.flagPublic, "public", false, i18n.G("Make image public"))
+	cmd.Flags().BoolVar(&c.flagNoCache, "no-cache", false, i18n.G("Don't use the cached results of previous builds"))
+	cmd.Flags().StringVar(&c.flagCompression, "compression", "", i18n.G("Compression algorithm to use (`none` for uncompressed)"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpRemotes(toComplete, false)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdImageBuild) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := conf.DefaultRemote
+	if len(args) > 1 {
+		remote, _, err = conf.ParseRemote(args[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	// Parse the recipe.
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	recipe := imageBuildRecipe{}
+	err = yaml.Unmarshal(content, &recipe)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed parsing recipe %q: %w"), args[0], err)
+	}
+
+	if recipe.Base == "" {
+		return errors.New(i18n.G("The recipe doesn't specify a base image"))
+	}
+
+	req := api.ImageBuildPost{
+		Recipe: api.ImageBuildRecipe{
+			Type:       api.InstanceType(recipe.Type),
+			Profiles:   recipe.Profiles,
+			Config:     recipe.Config,
+			Args:       recipe.Args,
+			Properties: recipe.Properties,
+		},
+		Public:               c.flagPublic,
+		NoCache:              c.flagNoCache,
+		CompressionAlgorithm: c.flagCompression,
+	}
+
+	for _, entry := range c.flagArgs {
+		key, value, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf(i18n.G("Bad key=value pair: %q"), entry)
+		}
+
+		if req.Args == nil {
+			req.Args = map[string]string{}
+		}
+
+		req.Args[key] = value
+	}
+
+	for _, alias := range c.flagAliases {
+		req.Aliases = append(req.Aliases, api.ImageAlias{Name: alias})
+	}
+
+	for i, entry := range recipe.Steps {
+		step := api.ImageBuildStep{
+			Exec:        entry.Exec,
+			Environment: entry.Environment,
+			Cwd:         entry.Cwd,
+			Cache:       entry.Cache,
+		}
+
+		if entry.Run != "" {
+			if len(step.Exec) > 0 {
+				return fmt.Errorf(i18n.G("Step %d can't have both \"exec\" and \"run\""), i+1)
+			}
+
+			step.Exec = []string{"sh", "-c", entry.Run}
+		}
+
+		if entry.File != nil {
+			step.File = &entry.File.ImageBuildFile
+
+			// Read the file content from the client, relative to the recipe.
+			if entry.File.Source != "" {
+				source := entry.File.Source
+				if !filepath.IsAbs(source) {
+					source = filepath.Join(filepath.Dir(args[0]), source)
+				}
+
+				data, err := os.ReadFile(source)
+				if err != nil {
+					return err
+				}
+
+				step.File.Content = string(data)
+			}
+		}
+
+		req.Recipe.Steps = append(req.Recipe.Steps, step)
+	}
+
+	// Resolve the base image, local to the server unless a remote is specified.
+	imgRemote := remote
+	imgName := recipe.Base
+	if strings.Contains(recipe.Base, ":") {
+		imgRemote, imgName, err = conf.ParseRemote(recipe.Base)
+		if err != nil {
+			return err
+		}
+	}
+
+	imgServer, imgInfo, err := getImgInfo(d, conf, imgRemote, remote, imgName, &req.Recipe.Source)
+	if err != nil {
+		return err
+	}
+
+	if recipe.Type == "" && conf.Remotes[imgRemote].Protocol == "incus" {
+		req.Recipe.Type = api.InstanceType(imgInfo.Type)
+	}
+
+	op, err := d.BuildImage(imgServer, *imgInfo, req)
+	if err != nil {
+		return err
+	}
+
+	// Watch the background operation.
+	progress := cli.ProgressRenderer{
+		Quiet: c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(func(op api.Operation) {
+		// Show the transfer progress if any, the current build step otherwise.
+		for key := range op.Metadata {
+			if strings.HasSuffix(key, "_progress") {
+				progress.UpdateOp(op)
+				return
+			}
+		}
+
+		step, ok := op.Metadata["build_step"].(string)
+		if ok {
+			progress.Update(step)
+		}
+	})
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	opAPI := op.Get()
+
+	fingerprint, ok := opAPI.Metadata["fingerprint"].(string)
+	if !ok {
+		return errors.New("Bad fingerprint")
+	}
+
+	progress.Done(fmt.Sprintf(i18n.G("Image built with fingerprint: %s"), fingerprint))
+
+	return nil
+}
+
 // Copy.
 type cmdImageCopy struct {
 	global *cmdGlobal
@@ -142,6 +398,7 @@ type cmdImageCopy struct {
 	flagMode          string
 	flagTargetProject string
 	flagProfile       []string
+	flagCompression   string
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -154,7 +411,11 @@ func (c *cmdImageCopy) Command() *cobra.Command {
 		`Copy images between servers
 
 The auto-update flag instructs the server to keep this image up to date.
-It requires the source to be an alias and for it to be public.`))
+It requires the source to be an alias and for it to be public.
+
+The compression flag has the target server re-compress the image with the
+given algorithm and level (e.g. "zstd -19"), the copied image then gets a
+new fingerprint.`))
 
 	cmd.Flags().BoolVar(&c.flagPublic, "public", false, i18n.G("Make image public"))
 	cmd.Flags().BoolVar(&c.flagCopyAliases, "copy-aliases", false, i18n.G("Copy aliases from source"))
@@ -164,6 +425,7 @@ It requires the source to be an alias and for it to be public.`))
 	cmd.Flags().StringVar(&c.flagMode, "mode", "pull", i18n.G("Transfer mode. One of pull (default), push or relay")+"``")
 	cmd.Flags().StringVar(&c.flagTargetProject, "target-project", "", i18n.G("Copy to a project different from the source")+"``")
 	cmd.Flags().StringArrayVarP(&c.flagProfile, "profile", "p", nil, i18n.G("Profile to apply to the new image")+"``")
+	cmd.Flags().StringVar(&c.flagCompression, "compression", "", i18n.G("Compression algorithm to re-compress the image with on the target (`none` for uncompressed)"))
 	cmd.RunE = c.Run
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -195,6 +457,10 @@ func (c *cmdImageCopy) Run(cmd *cobra.Command, args []string) error {
 		return errors.New(i18n.G("Auto update is only available in pull mode"))
 	}
 
+	if c.flagCompression != "" && c.flagAutoUpdate {
+		return errors.New(i18n.G("Auto update can't be used when re-compressing the image"))
+	}
+
 	// Parse source remote
 	remoteName, name, err := c.global.conf.ParseRemote(args[0])
 	if err != nil {
@@ -268,6 +534,7 @@ func (c *cmdImageCopy) Run(cmd *cobra.Command, args []string) error {
 		Type:        imageType,
 		Mode:        c.flagMode,
 		Profiles:    c.flagProfile,
+		Compression: c.flagCompression,
 	}
 
 	// Do the copy
@@ -1434,6 +1701,8 @@ func (c *cmdImageList) Run(cmd *cobra.Command, args []string) error {
 type cmdImageRefresh struct {
 	global *cmdGlobal
 	image  *cmdImage
+
+	flagDryRun bool
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -1442,8 +1711,12 @@ func (c *cmdImageRefresh) Command() *cobra.Command {
 	cmd.Use = usage("refresh", i18n.G("[<remote>:]<image> [[<remote>:]<image>...]"))
 	cmd.Short = i18n.G("Refresh images")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Refresh images`))
+		`Refresh images
+
+With --dry-run, the server queries the image source for its latest image and
+the changes are reported without downloading anything.`))
 
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only show what would change"))
 	cmd.RunE = c.Run
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -1473,6 +1746,15 @@ func (c *cmdImageRefresh) Run(cmd *cobra.Command, args []string) error {
 		}
 
 		image := c.image.dereferenceAlias(resource.server, "", resource.name)
+		if c.flagDryRun {
+			err := c.dryRun(resource.server, image)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		progress := cli.ProgressRenderer{
 			Format: i18n.G("Refreshing the image: %s"),
 			Quiet:  c.global.flagQuiet,
@@ -1514,6 +1796,67 @@ func (c *cmdImageRefresh) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// dryRun reports what refreshing the image would change by comparing it with the latest image of its source.
+func (c *cmdImageRefresh) dryRun(d incus.InstanceServer, fingerprint string) error {
+	info, _, err := d.GetImage(fingerprint)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Image: %s")+"\n", info.Fingerprint)
+
+	source := info.UpdateSource
+	if source == nil {
+		fmt.Printf("  %s: %s\n", i18n.G("Status"), i18n.G("no update source"))
+		return nil
+	}
+
+	fmt.Printf("  %s: %s (%s, %s)\n", i18n.G("Source"), source.Server, source.Protocol, source.Alias)
+
+	// Only failures to query the source are reported as a status, any other error is returned as is.
+	latest, err := d.GetImageRefresh(info.Fingerprint)
+	if err != nil {
+		if !api.StatusErrorCheck(err, http.StatusServiceUnavailable) {
+			return err
+		}
+
+		fmt.Printf("  %s: %s (%v)\n", i18n.G("Status"), i18n.G("source unreachable"), err)
+		return nil
+	}
+
+	changes := internalUtil.ImageRefreshDiff(info, latest)
+	if !changes.UpdateAvailable() {
+		fmt.Printf("  %s: %s\n", i18n.G("Status"), i18n.G("up to date"))
+		return nil
+	}
+
+	fmt.Printf("  %s: %s\n", i18n.G("Status"), i18n.G("update available"))
+	fmt.Printf("  %s: %s -> %s\n", i18n.G("Fingerprint"), changes.OldFingerprint, changes.NewFingerprint)
+
+	sizeDelta := "+" + units.GetByteSizeStringIEC(changes.SizeDelta, 2)
+	if changes.SizeDelta < 0 {
+		sizeDelta = "-" + units.GetByteSizeStringIEC(-changes.SizeDelta, 2)
+	}
+
+	fmt.Printf("  %s: %s -> %s (%s)\n", i18n.G("Size"), units.GetByteSizeStringIEC(info.Size, 2), units.GetByteSizeStringIEC(latest.Size, 2), sizeDelta)
+
+	if len(changes.Properties) > 0 {
+		fmt.Printf("  %s:\n", i18n.G("Properties"))
+
+		keys := make([]string, 0, len(changes.Properties))
+		for key := range changes.Properties {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("    %s: %q -> %q\n", key, changes.Properties[key][0], changes.Properties[key][1])
+		}
+	}
+
+	return nil
+}
+
 // Show.
 type cmdImageShow struct {
 	global *cmdGlobal
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -17,7 +18,11 @@ import (
 type cmdImport struct {
 	global *cmdGlobal
 
-	flagStorage string
+	flagStorage        string
+	flagPassphraseFile string
+	flagConfigOnly     bool
+	flagInstanceOnly   bool
+	flagSnapshot       string
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -29,10 +34,23 @@ func (c *cmdImport) Command() *cobra.Command {
 		`Import backups of instances including their snapshots.`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`incus import backup0.tar.gz
-    Create a new instance using backup0.tar.gz as the source.`))
+    Create a new instance using backup0.tar.gz as the source.
+
+incus import backup0.tar.gz.enc --passphrase-file passphrase.txt
+    Create a new instance from an encrypted backup, reading its passphrase from passphrase.txt.
+
+incus import backup0.tar.gz --snapshot snap0
+    Create a new instance using backup0.tar.gz as the source, only restoring its snap0 snapshot.
+
+incus import backup0.tar.gz --config-only
+    Create a new instance with the configuration from backup0.tar.gz and an empty root volume.`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().StringVarP(&c.flagStorage, "storage", "s", "", i18n.G("Storage pool name")+"``")
+	cmd.Flags().StringVar(&c.flagPassphraseFile, "passphrase-file", "", i18n.G("File to read the passphrase of an encrypted backup from")+"``")
+	cmd.Flags().BoolVar(&c.flagConfigOnly, "config-only", false, i18n.G("Only restore the instance configuration (with an empty root volume)"))
+	cmd.Flags().BoolVar(&c.flagInstanceOnly, "instance-only", false, i18n.G("Don't restore the snapshots of the instance"))
+	cmd.Flags().StringVar(&c.flagSnapshot, "snapshot", "", i18n.G("Only restore the named snapshot of the instance")+"``")
 
 	return cmd
 }
@@ -45,6 +63,14 @@ func (c *cmdImport) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if c.flagConfigOnly && (c.flagInstanceOnly || c.flagSnapshot != "") {
+		return errors.New(i18n.G("--config-only can't be used with --instance-only or --snapshot"))
+	}
+
+	if c.flagInstanceOnly && c.flagSnapshot != "" {
+		return errors.New(i18n.G("--instance-only can't be used with --snapshot"))
+	}
+
 	srcFilePosition := 0
 
 	// Parse remote (identify 1st argument is remote by looking for a colon at the end).
@@ -91,6 +117,27 @@ func (c *cmdImport) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Get the passphrase of encrypted backups, which can't be detected when reading from stdin.
+	var passphrase string
+	if c.flagPassphraseFile != "" {
+		passphrase, err = getBackupPassphrase(&c.global.asker, c.flagPassphraseFile, false)
+		if err != nil {
+			return err
+		}
+	} else if srcFile != "-" {
+		encrypted, err := isEncryptedBackup(file)
+		if err != nil {
+			return err
+		}
+
+		if encrypted {
+			passphrase, err = getBackupPassphrase(&c.global.asker, "", false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	progress := cli.ProgressRenderer{
 		Format: i18n.G("Importing instance: %s"),
 		Quiet:  c.global.flagQuiet,
@@ -106,8 +153,12 @@ func (c *cmdImport) Run(cmd *cobra.Command, args []string) error {
 				},
 			},
 		},
-		PoolName: c.flagStorage,
-		Name:     instanceName,
+		PoolName:             c.flagStorage,
+		Name:                 instanceName,
+		EncryptionPassphrase: passphrase,
+		ConfigOnly:           c.flagConfigOnly,
+		InstanceOnly:         c.flagInstanceOnly,
+		Snapshot:             c.flagSnapshot,
 	}
 
 	op, err := resource.server.CreateInstanceFromBackup(createArgs)
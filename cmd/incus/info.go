@@ -7,6 +7,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -670,6 +671,18 @@ func (c *cmdInfo) instanceInfo(d incus.InstanceServer, name string, showLog bool
 		fmt.Printf(i18n.G("Last Used: %s")+"\n", inst.LastUsedAt.Local().Format(dateLayout))
 	}
 
+	if !inst.LastStartedAt.IsZero() {
+		fmt.Printf(i18n.G("Last Started: %s")+"\n", inst.LastStartedAt.Local().Format(dateLayout))
+	}
+
+	if inst.State.ConsoleLogSize > 0 {
+		fmt.Printf(i18n.G("Console log size: %s")+"\n", units.GetByteSizeStringIEC(inst.State.ConsoleLogSize, 2))
+	}
+
+	if inst.State.RestartCount > 0 {
+		fmt.Printf(i18n.G("Restarts: %d")+"\n", inst.State.RestartCount)
+	}
+
 	if inst.State.Pid != 0 {
 		if !inst.State.StartedAt.IsZero() {
 			fmt.Printf(i18n.G("Started: %s")+"\n", inst.State.StartedAt.Local().Format(dateLayout))
@@ -686,6 +699,16 @@ func (c *cmdInfo) instanceInfo(d incus.InstanceServer, name string, showLog bool
 			fmt.Print(osInfo)
 		}
 
+		// Clock info
+		if inst.State.Clock != nil {
+			fmt.Println("\n" + i18n.G("Clock:"))
+			fmt.Printf("  %s: %s\n", i18n.G("Offset from host"), time.Duration(inst.State.Clock.Offset))
+
+			if inst.State.Clock.NTPStatus != "" {
+				fmt.Printf("  %s: %s\n", i18n.G("NTP"), inst.State.Clock.NTPStatus)
+			}
+		}
+
 		fmt.Println("\n" + i18n.G("Resources:"))
 		// Processes
 		fmt.Printf("  "+i18n.G("Processes: %d")+"\n", inst.State.Processes)
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+)
+
+type cmdLabel struct {
+	global *cmdGlobal
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdLabel) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("label")
+	cmd.Short = i18n.G("Manage instance labels")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage instance labels
+
+Labels are key/value pairs attached to an instance which can be used
+to select instances, e.g. "incus list label=env=prod".
+Changing labels never affects the running instance.`))
+
+	// List
+	labelListCmd := cmdLabelList{global: c.global, label: c}
+	cmd.AddCommand(labelListCmd.Command())
+
+	// Set
+	labelSetCmd := cmdLabelSet{global: c.global, label: c}
+	cmd.AddCommand(labelSetCmd.Command())
+
+	// Unset
+	labelUnsetCmd := cmdLabelUnset{global: c.global, label: c}
+	cmd.AddCommand(labelUnsetCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdLabelList struct {
+	global *cmdGlobal
+	label  *cmdLabel
+
+	flagFormat string
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdLabelList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<instance>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List instance labels")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List instance labels`))
+
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", c.global.defaultListFormat(), i18n.G(`Format (csv|json|table|yaml|compact), use suffix ",noheader" to disable headers and ",header" to enable it if missing, e.g. csv,header`)+"``")
+
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
+		return cli.ValidateFlagFormatForListOutput(cmd.Flag("format").Value.String())
+	}
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdLabelList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance name"))
+	}
+
+	labels, _, err := resource.server.GetInstanceLabels(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for k, v := range labels.Labels {
+		data = append(data, []string{k, v})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("KEY"),
+		i18n.G("VALUE"),
+	}
+
+	return cli.RenderTable(os.Stdout, c.flagFormat, header, data, labels.Labels)
+}
+
+// Set.
+type cmdLabelSet struct {
+	global *cmdGlobal
+	label  *cmdLabel
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdLabelSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<instance> <key>=<value>..."))
+	cmd.Short = i18n.G("Set instance labels")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Set instance labels`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus label set c1 env=prod role=db
+    Set the "env" and "role" labels on instance "c1".`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdLabelSet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance name"))
+	}
+
+	// Parse the labels
+	values, err := getConfig(args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	labels, etag, err := resource.server.GetInstanceLabels(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if labels.Labels == nil {
+		labels.Labels = map[string]string{}
+	}
+
+	for k, v := range values {
+		labels.Labels[k] = v
+	}
+
+	return resource.server.UpdateInstanceLabels(resource.name, *labels, etag)
+}
+
+// Unset.
+type cmdLabelUnset struct {
+	global *cmdGlobal
+	label  *cmdLabel
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdLabelUnset) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<instance> <key>..."))
+	cmd.Short = i18n.G("Unset instance labels")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Unset instance labels`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdLabelUnset) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance name"))
+	}
+
+	labels, etag, err := resource.server.GetInstanceLabels(resource.name)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range args[1:] {
+		delete(labels.Labels, key)
+	}
+
+	return resource.server.UpdateInstanceLabels(resource.name, *labels, etag)
+}
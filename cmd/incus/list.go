@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -66,6 +67,9 @@ A key/value pair where the key is a shorthand. Multiple values must be delimited
   - location={location name}
   - ipv4={ip or CIDR}
   - ipv6={ip or CIDR}
+  - label={label selector} (e.g. "env=prod", "role in (db,cache)", "!legacy")
+  - last_used_before={date (YYYY-MM-DD) or duration (e.g. 720h)}
+  - last_started_before={date (YYYY-MM-DD) or duration (e.g. 720h)}
 
 Examples:
   - "user.blah=abc" will list all instances with the "blah" user property set to "abc".
@@ -74,6 +78,7 @@ Examples:
   - "s.privileged=true" will do the same
   - "type=container" will list all container instances
   - "type=container status=running" will list all running container instances
+  - "last_used_before=720h" will list all instances not used (started, exec or console) in the last 30 days
 
 A regular expression matching a configuration item or its value. (e.g. volatile.eth0.hwaddr=10:66:6a:.*).
 
@@ -99,7 +104,7 @@ Pre-defined column shorthand chars:
   d - Description
   D - disk usage
   e - Project name
-  l - Last used date
+  l - Last used date (start, exec or console)
   m - Memory usage
   M - Memory usage (%)
   n - Name
@@ -109,6 +114,7 @@ Pre-defined column shorthand chars:
   s - State
   S - Number of snapshots
   t - Type (persistent or ephemeral)
+  T - Last started date
   u - CPU usage (in seconds)
   U - Started date
   L - Location of the instance (e.g. its cluster member)
@@ -117,6 +123,11 @@ Pre-defined column shorthand chars:
 
 Custom columns are defined with "[config:|devices:]key[:name][:maxWidth]":
   KEY: The (extended) config or devices key to display. If [config:|devices:] is omitted then it defaults to config key.
+  Devices keys are in the form "device.key", with "device.state.field" showing runtime information about the device:
+    - ipv4, ipv6: Addresses of a NIC in the instance
+    - hwaddr, host_name: MAC address and host-side interface name of a NIC
+    - volume: Name of the storage volume attached by a disk
+    - usage: Disk usage of a disk
   NAME: Name to display in the column header.
   Defaults to the key if not specified or empty.
 
@@ -129,6 +140,9 @@ Custom columns are defined with "[config:|devices:]key[:name][:maxWidth]":
   "BASE IMAGE", "MAC" and "IMAGE OS" are custom columns generated from instance configuration keys.
   "ETHP" is a custom column generated from a device key.
 
+incus list -c n,devices:eth0.state.ipv4:ETH0
+  List instances with the IPv4 addresses of their eth0 NIC.
+
 incus list -c ns,user.comment:comment
   List instances with their running state and user comment.`))
 
@@ -158,8 +172,12 @@ const (
 	defaultColumnsAllProjects = "ens46tSL"
 	configColumnType          = "config"
 	deviceColumnType          = "devices"
+	deviceStateKeyPrefix      = "state."
 )
 
+// deviceStateFields are the runtime fields available in device columns through the "state." key prefix.
+var deviceStateFields = []string{"ipv4", "ipv6", "hwaddr", "host_name", "volume", "usage"}
+
 func (c *cmdList) shouldShow(filters []string, inst *api.Instance, state *api.InstanceState) bool {
 	c.mapShorthandFilters()
 
@@ -184,6 +202,24 @@ func (c *cmdList) shouldShow(filters []string, inst *api.Instance, state *api.In
 	return true
 }
 
+// splitLabelFilters extracts the label selectors from the filters and returns them as a single selector.
+func (c *cmdList) splitLabelFilters(filters []string) (string, []string) {
+	selectors := []string{}
+	remaining := []string{}
+
+	for _, filter := range filters {
+		key, value, found := strings.Cut(filter, "=")
+		if found && strings.ToLower(key) == "label" {
+			selectors = append(selectors, value)
+			continue
+		}
+
+		remaining = append(remaining, filter)
+	}
+
+	return strings.Join(selectors, ","), remaining
+}
+
 func (c *cmdList) evaluateShorthandFilter(key string, value string, inst *api.Instance, state *api.InstanceState) bool {
 	const shorthandValueDelimiter = ","
 	shorthandFilterFunction, isShorthandFilter := c.shorthandFilters[strings.ToLower(key)]
@@ -442,6 +478,9 @@ func (c *cmdList) Run(cmd *cobra.Command, args []string) error {
 		remote = conf.DefaultRemote
 	}
 
+	// Extract the label selectors, those are evaluated by the server.
+	labelSelector, filters := c.splitLabelFilters(filters)
+
 	// Connect to the daemon.
 	d, err := conf.GetInstanceServer(remote)
 	if err != nil {
@@ -458,11 +497,15 @@ func (c *cmdList) Run(cmd *cobra.Command, args []string) error {
 		// Using the GetInstancesFull shortcut
 		var instances []api.InstanceFull
 
-		serverFilters, clientFilters := getServerSupportedFilters(filters, []string{"ipv4", "ipv6"}, true)
+		serverFilters, clientFilters := getServerSupportedFilters(filters, []string{"ipv4", "ipv6", "last_used_before", "last_started_before"}, true)
 		serverFilters = prepareInstanceServerFilters(serverFilters, api.InstanceFull{})
 
-		if c.flagAllProjects {
+		if c.flagAllProjects && labelSelector != "" {
+			instances, err = d.GetInstancesFullAllProjectsWithLabels(api.InstanceTypeAny, labelSelector, serverFilters)
+		} else if c.flagAllProjects {
 			instances, err = d.GetInstancesFullAllProjectsWithFilter(api.InstanceTypeAny, serverFilters)
+		} else if labelSelector != "" {
+			instances, err = d.GetInstancesFullWithLabels(api.InstanceTypeAny, labelSelector, serverFilters)
 		} else {
 			instances, err = d.GetInstancesFullWithFilter(api.InstanceTypeAny, serverFilters)
 		}
@@ -476,11 +519,15 @@ func (c *cmdList) Run(cmd *cobra.Command, args []string) error {
 
 	// Get the list of instances
 	var instances []api.Instance
-	serverFilters, clientFilters := getServerSupportedFilters(filters, []string{"ipv4", "ipv6"}, true)
+	serverFilters, clientFilters := getServerSupportedFilters(filters, []string{"ipv4", "ipv6", "last_used_before", "last_started_before"}, true)
 	serverFilters = prepareInstanceServerFilters(serverFilters, api.Instance{})
 
-	if c.flagAllProjects {
+	if c.flagAllProjects && labelSelector != "" {
+		instances, err = d.GetInstancesAllProjectsWithLabels(api.InstanceTypeAny, labelSelector, serverFilters)
+	} else if c.flagAllProjects {
 		instances, err = d.GetInstancesAllProjectsWithFilter(api.InstanceTypeAny, serverFilters)
+	} else if labelSelector != "" {
+		instances, err = d.GetInstancesWithLabels(api.InstanceTypeAny, labelSelector, serverFilters)
 	} else {
 		instances, err = d.GetInstancesWithFilter(api.InstanceTypeAny, serverFilters)
 	}
@@ -515,6 +562,7 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 		'S': {i18n.G("SNAPSHOTS"), c.numberSnapshotsColumnData, false, true},
 		's': {i18n.G("STATE"), c.statusColumnData, false, false},
 		't': {i18n.G("TYPE"), c.typeColumnData, false, false},
+		'T': {i18n.G("LAST STARTED AT"), c.lastStartedColumnData, false, false},
 		'u': {i18n.G("CPU USAGE"), c.cpuUsageSecondsColumnData, true, false},
 		'U': {i18n.G("STARTED AT"), c.startedColumnData, true, false},
 	}
@@ -639,7 +687,26 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 					return v
 				}
 			}
-			if colType == deviceColumnType {
+			devName, devKey, _ := strings.Cut(k, ".")
+			field, isDeviceState := strings.CutPrefix(devKey, deviceStateKeyPrefix)
+			if colType == deviceColumnType && isDeviceState {
+				if !slices.Contains(deviceStateFields, field) {
+					return nil, false, fmt.Errorf(i18n.G("Unknown device state field '%s' in '%s'"), field, columnEntry)
+				}
+
+				column.NeedsState = true
+				column.Data = func(cInfo api.InstanceFull) string {
+					v := c.deviceStateColumnData(cInfo, devName, field)
+
+					// Truncate the data according to the max width.  A negative max width
+					// indicates there is no effective limit.
+					if maxWidth > 0 && len(v) > maxWidth {
+						return v[:maxWidth]
+					}
+
+					return v
+				}
+			} else if colType == deviceColumnType {
 				column.Data = func(cInfo api.InstanceFull) string {
 					d := strings.SplitN(k, ".", 2)
 					if len(d) == 1 || len(d) > 2 {
@@ -756,6 +823,86 @@ func (c *cmdList) ip6ColumnData(cInfo api.InstanceFull) string {
 	return ""
 }
 
+// deviceStateColumnData returns the runtime value of a device field, or an empty string if the instance doesn't have
+// the device or the value isn't available.
+func (c *cmdList) deviceStateColumnData(cInfo api.InstanceFull, devName string, field string) string {
+	dev, ok := cInfo.ExpandedDevices[devName]
+	if !ok {
+		return ""
+	}
+
+	switch field {
+	case "hwaddr", "host_name":
+		v := cInfo.Config[fmt.Sprintf("volatile.%s.%s", devName, field)]
+		if v == "" {
+			v = dev[field]
+		}
+
+		return v
+	case "volume":
+		if dev["type"] != "disk" || dev["pool"] == "" {
+			return ""
+		}
+
+		// The root disk is the instance volume.
+		if dev["path"] == "/" {
+			return cInfo.Name
+		}
+
+		return dev["source"]
+	case "usage":
+		if cInfo.State == nil || cInfo.State.Disk == nil || cInfo.State.Disk[devName].Usage <= 0 {
+			return ""
+		}
+
+		return units.GetByteSizeStringIEC(cInfo.State.Disk[devName].Usage, 2)
+	case "ipv4", "ipv6":
+		if dev["type"] != "nic" || !cInfo.IsActive() || cInfo.State == nil {
+			return ""
+		}
+
+		family := "inet"
+		if field == "ipv6" {
+			family = "inet6"
+		}
+
+		// Find the interface of the NIC in the instance, by MAC address or by name.
+		hwaddr := cInfo.Config[fmt.Sprintf("volatile.%s.hwaddr", devName)]
+		if hwaddr == "" {
+			hwaddr = dev["hwaddr"]
+		}
+
+		nicName := dev["name"]
+		if nicName == "" {
+			nicName = devName
+		}
+
+		for netName, network := range cInfo.State.Network {
+			if hwaddr != "" && !strings.EqualFold(network.Hwaddr, hwaddr) {
+				continue
+			}
+
+			if hwaddr == "" && netName != nicName {
+				continue
+			}
+
+			addresses := []string{}
+			for _, addr := range network.Addresses {
+				if addr.Family != family || slices.Contains([]string{"link", "local"}, addr.Scope) {
+					continue
+				}
+
+				addresses = append(addresses, addr.Address)
+			}
+
+			sort.Strings(addresses)
+			return strings.Join(addresses, "\n")
+		}
+	}
+
+	return ""
+}
+
 func (c *cmdList) projectColumnData(cInfo api.InstanceFull) string {
 	return cInfo.Project
 }
@@ -881,6 +1028,14 @@ func (c *cmdList) lastUsedColumnData(cInfo api.InstanceFull) string {
 	return ""
 }
 
+func (c *cmdList) lastStartedColumnData(cInfo api.InstanceFull) string {
+	if !cInfo.LastStartedAt.IsZero() {
+		return cInfo.LastStartedAt.Local().Format(dateLayout)
+	}
+
+	return ""
+}
+
 func (c *cmdList) numberOfProcessesColumnData(cInfo api.InstanceFull) string {
 	if cInfo.IsActive() && cInfo.State != nil {
 		return fmt.Sprintf("%d", cInfo.State.Processes)
@@ -942,10 +1097,38 @@ func (c *cmdList) matchByIPV4(_ *api.Instance, cState *api.InstanceState, query
 	return c.matchByNet(cState, query, "ipv4")
 }
 
+func (c *cmdList) matchByLastUsed(inst *api.Instance, _ *api.InstanceState, query string) bool {
+	return matchDateBefore(inst.LastUsedAt, query)
+}
+
+func (c *cmdList) matchByLastStarted(inst *api.Instance, _ *api.InstanceState, query string) bool {
+	return matchDateBefore(inst.LastStartedAt, query)
+}
+
+// matchDateBefore returns whether the date is before the one in the query, either a date (YYYY-MM-DD)
+// or a duration before now (e.g. 720h). Dates which were never set always match.
+func matchDateBefore(date time.Time, query string) bool {
+	var limit time.Time
+
+	duration, err := time.ParseDuration(query)
+	if err == nil {
+		limit = time.Now().Add(-duration)
+	} else {
+		limit, err = time.ParseInLocation(time.DateOnly, query, time.Local)
+		if err != nil {
+			return false
+		}
+	}
+
+	return date.Before(limit)
+}
+
 func (c *cmdList) mapShorthandFilters() {
 	c.shorthandFilters = map[string]func(*api.Instance, *api.InstanceState, string) bool{
-		"ipv4": c.matchByIPV4,
-		"ipv6": c.matchByIPV6,
+		"ipv4":                c.matchByIPV4,
+		"ipv6":                c.matchByIPV6,
+		"last_used_before":    c.matchByLastUsed,
+		"last_started_before": c.matchByLastStarted,
 	}
 }
 
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -80,11 +81,35 @@ func TestShouldShow(t *testing.T) {
 	if !list.shouldShow([]string{"ipv6=fd42:72a:89ac:e457:1266:6aff:fe83:ffff/1"}, inst, state) {
 		t.Errorf("net=fd42:72a:89ac:e457:1266:6aff:fe83:ffff/1 filter filter didn't work")
 	}
+
+	// Instances which were never used or started match any date.
+	if !list.shouldShow([]string{"last_used_before=1h", "last_started_before=2020-01-01"}, inst, state) {
+		t.Errorf("last_used_before=1h last_started_before=2020-01-01 filter didn't work")
+	}
+
+	inst.LastUsedAt = time.Now().Add(-2 * time.Hour)
+	inst.LastStartedAt = time.Date(2021, 1, 1, 12, 0, 0, 0, time.Local)
+
+	if !list.shouldShow([]string{"last_used_before=1h", "last_started_before=2021-01-02"}, inst, state) {
+		t.Errorf("last_used_before=1h last_started_before=2021-01-02 filter didn't work")
+	}
+
+	if list.shouldShow([]string{"last_used_before=3h"}, inst, state) {
+		t.Errorf("last_used_before=3h filter did work but should not")
+	}
+
+	if list.shouldShow([]string{"last_started_before=2021-01-01"}, inst, state) {
+		t.Errorf("last_started_before=2021-01-01 filter did work but should not")
+	}
+
+	if list.shouldShow([]string{"last_used_before=invalid"}, inst, state) {
+		t.Errorf("last_used_before=invalid filter did work but should not")
+	}
 }
 
 // Used by TestColumns and TestInvalidColumns.
 const (
-	shorthand = "46abcdDefFlmMnNpPsStuUL"
+	shorthand = "46abcdDefFlmMnNpPsStTuUL"
 	alphanum  = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 )
 
@@ -114,6 +139,8 @@ func TestColumns(t *testing.T) {
 	// Test with 'devices:'.
 	keys = append(keys, "devices:eth0.parent.rand")
 	keys = append(keys, "devices:root.path")
+	keys = append(keys, "devices:eth0.state.ipv4")
+	keys = append(keys, "devices:root.state.usage")
 
 	randShorthand := func(buffer *bytes.Buffer) {
 		buffer.WriteByte(shorthand[rand.Intn(len(shorthand))])
@@ -273,6 +300,85 @@ func TestInvalidColumns(t *testing.T) {
 	run("config:")
 	run("config:image")
 	run("devices:eth0")
+	run("devices:eth0.state.foo")
+	run("devices:eth0.state.")
+}
+
+func TestDeviceStateColumns(t *testing.T) {
+	inst := api.InstanceFull{
+		Instance: api.Instance{
+			Name:       "c1",
+			Status:     "Running",
+			StatusCode: api.Running,
+			InstancePut: api.InstancePut{
+				Config: map[string]string{
+					"volatile.eth0.hwaddr":    "00:16:3e:00:00:01",
+					"volatile.eth0.host_name": "veth1234",
+				},
+			},
+			ExpandedDevices: map[string]map[string]string{
+				"eth0": {"type": "nic", "network": "incusbr0"},
+				"eth1": {"type": "nic", "nictype": "routed", "name": "routed0"},
+				"root": {"type": "disk", "path": "/", "pool": "default"},
+				"data": {"type": "disk", "path": "/data", "pool": "default", "source": "vol1"},
+				"host": {"type": "disk", "path": "/host", "source": "/srv"},
+			},
+		},
+		State: &api.InstanceState{
+			Network: map[string]api.InstanceStateNetwork{
+				"lo": {
+					Type:      "loopback",
+					Addresses: []api.InstanceStateNetworkAddress{{Family: "inet", Address: "127.0.0.1", Scope: "local"}},
+				},
+				"eth0": {
+					Hwaddr: "00:16:3E:00:00:01",
+					Addresses: []api.InstanceStateNetworkAddress{
+						{Family: "inet", Address: "10.0.0.3", Scope: "global"},
+						{Family: "inet", Address: "10.0.0.2", Scope: "global"},
+						{Family: "inet6", Address: "fd00::2", Scope: "global"},
+						{Family: "inet6", Address: "fe80::1", Scope: "link"},
+					},
+				},
+				"routed0": {
+					Addresses: []api.InstanceStateNetworkAddress{{Family: "inet", Address: "192.0.2.10", Scope: "global"}},
+				},
+			},
+			Disk: map[string]api.InstanceStateDisk{
+				"root": {Usage: 2 * 1024 * 1024},
+			},
+		},
+	}
+
+	list := cmdList{flagColumns: "n,devices:eth0.state.ipv4:IPV4,devices:eth0.state.ipv6,devices:eth1.state.ipv4,devices:eth0.state.hwaddr,devices:eth0.state.host_name,devices:root.state.volume,devices:data.state.volume,devices:host.state.volume,devices:root.state.usage,devices:eth9.state.ipv4,devices:eth0.state.ipv4::5"}
+	columns, needsData, err := list.parseColumns(false)
+	assert.NoError(t, err)
+	assert.True(t, needsData)
+	assert.Equal(t, "IPV4", columns[1].Name)
+
+	values := []string{}
+	for _, column := range columns {
+		values = append(values, column.Data(inst))
+	}
+
+	assert.Equal(t, []string{
+		"c1",
+		"10.0.0.2\n10.0.0.3",
+		"fd00::2",
+		"192.0.2.10",
+		"00:16:3e:00:00:01",
+		"veth1234",
+		"c1",
+		"vol1",
+		"",
+		"2.00MiB",
+		"", // Instances without the device get an empty cell.
+		"10.0.",
+	}, values)
+
+	// Stopped instances have no addresses.
+	inst.Status = "Stopped"
+	inst.StatusCode = api.Stopped
+	assert.Equal(t, "", columns[1].Data(inst))
 }
 
 func TestPrepareInstanceServerFilters(t *testing.T) {
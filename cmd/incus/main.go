@@ -187,6 +187,10 @@ Custom commands can be defined through aliases, use "incus alias" to control tho
 	imageCmd := cmdImage{global: &globalCmd}
 	app.AddCommand(imageCmd.Command())
 
+	// label sub-command
+	labelCmd := cmdLabel{global: &globalCmd}
+	app.AddCommand(labelCmd.Command())
+
 	// launch sub-command
 	launchCmd := cmdLaunch{global: &globalCmd, init: &createCmd}
 	app.AddCommand(launchCmd.Command())
@@ -25,6 +25,7 @@ type cmdMonitor struct {
 	flagLogLevel    string
 	flagAllProjects bool
 	flagFormat      string
+	flagSince       string
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -44,7 +45,10 @@ incus monitor --pretty --type=logging --loglevel=info
     Show a pretty log of messages with info level or higher.
 
 incus monitor --type=lifecycle
-    Only show lifecycle events.`))
+    Only show lifecycle events.
+
+incus monitor --type=lifecycle --since=2024-05-01T10:00:00Z
+    Show the lifecycle events kept in the server's event history since that time, then the new ones.`))
 	cmd.Hidden = true
 
 	cmd.RunE = c.Run
@@ -53,6 +57,7 @@ incus monitor --type=lifecycle
 	cmd.Flags().StringArrayVar(&c.flagType, "type", nil, i18n.G("Event type to listen for")+"``")
 	cmd.Flags().StringVar(&c.flagLogLevel, "loglevel", "", i18n.G("Minimum level for log messages (only available when using pretty format)")+"``")
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "yaml", i18n.G("Format (json|pretty|yaml)")+"``")
+	cmd.Flags().StringVar(&c.flagSince, "since", "", i18n.G("Replay the events which followed this event cursor or RFC3339 timestamp")+"``")
 
 	return cmd
 }
@@ -102,7 +107,13 @@ func (c *cmdMonitor) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	var listener *incus.EventListener
-	if c.flagAllProjects {
+	if c.flagSince != "" {
+		if c.flagAllProjects {
+			listener, err = d.GetEventsAllProjectsSince(c.flagSince)
+		} else {
+			listener, err = d.GetEventsSince(c.flagSince)
+		}
+	} else if c.flagAllProjects {
 		listener, err = d.GetEventsAllProjects()
 	} else {
 		listener, err = d.GetEvents()
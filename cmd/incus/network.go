@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"reflect"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -56,6 +58,10 @@ func (c *cmdNetwork) Command() *cobra.Command {
 	networkDeleteCmd := cmdNetworkDelete{global: c.global, network: c}
 	cmd.AddCommand(networkDeleteCmd.Command())
 
+	// Delete lease
+	networkDeleteLeaseCmd := cmdNetworkDeleteLease{global: c.global, network: c}
+	cmd.AddCommand(networkDeleteLeaseCmd.Command())
+
 	// Detach
 	networkDetachCmd := cmdNetworkDetach{global: c.global, network: c}
 	cmd.AddCommand(networkDetachCmd.Command())
@@ -112,6 +118,10 @@ func (c *cmdNetwork) Command() *cobra.Command {
 	networkAddressSetCmd := cmdNetworkAddressSet{global: c.global}
 	cmd.AddCommand(networkAddressSetCmd.Command())
 
+	// DHCP reservation
+	networkDHCPReservationCmd := cmdNetworkDHCPReservation{global: c.global}
+	cmd.AddCommand(networkDHCPReservationCmd.Command())
+
 	// Forward
 	networkForwardCmd := cmdNetworkForward{global: c.global}
 	cmd.AddCommand(networkForwardCmd.Command())
@@ -124,6 +134,10 @@ func (c *cmdNetwork) Command() *cobra.Command {
 	networkLoadBalancerCmd := cmdNetworkLoadBalancer{global: c.global}
 	cmd.AddCommand(networkLoadBalancerCmd.Command())
 
+	// OVN
+	networkOVNCmd := cmdNetworkOVN{global: c.global}
+	cmd.AddCommand(networkOVNCmd.Command())
+
 	// Peer
 	networkPeerCmd := cmdNetworkPeer{global: c.global}
 	cmd.AddCommand(networkPeerCmd.Command())
@@ -516,6 +530,75 @@ func (c *cmdNetworkDelete) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Delete lease.
+type cmdNetworkDeleteLease struct {
+	global  *cmdGlobal
+	network *cmdNetwork
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkDeleteLease) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete-lease", i18n.G("[<remote>:]<network> <IP|MAC>"))
+	cmd.Short = i18n.G("Delete DHCP leases")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Delete DHCP leases
+
+Removes the dynamic leases matching the IP or MAC address from the network's DHCP server,
+making the addresses available again right away.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus network delete-lease incusbr0 10.0.0.42
+    Release the lease of 10.0.0.42 on incusbr0.
+
+incus network delete-lease incusbr0 00:16:3e:5a:12:34
+    Release all the leases of the 00:16:3e:5a:12:34 MAC address on incusbr0.`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return c.global.cmpNetworks(toComplete)
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkDeleteLease) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	// Delete the lease
+	err = resource.server.DeleteNetworkLease(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Lease %s deleted from network %s")+"\n", args[1], resource.name)
+	}
+
+	return nil
+}
+
 // Detach.
 type cmdNetworkDetach struct {
 	global  *cmdGlobal
@@ -1039,6 +1122,17 @@ func (c *cmdNetworkInfo) Run(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %s: %d\n", i18n.G("MII Frequency"), state.Bond.MIIFrequency)
 		fmt.Printf("  %s: %s\n", i18n.G("MII state"), state.Bond.MIIState)
 		fmt.Printf("  %s: %s\n", i18n.G("Lower devices"), strings.Join(state.Bond.LowerDevices, ", "))
+
+		if state.Bond.ActiveDevice != "" {
+			fmt.Printf("  %s: %s\n", i18n.G("Active device"), state.Bond.ActiveDevice)
+		}
+
+		if len(state.Bond.LowerDevicesState) > 0 {
+			fmt.Printf("  %s:\n", i18n.G("Lower devices state"))
+			for _, device := range slices.Sorted(maps.Keys(state.Bond.LowerDevicesState)) {
+				fmt.Printf("    %s: %s\n", device, state.Bond.LowerDevicesState[device])
+			}
+		}
 	}
 
 	// Bridge information.
@@ -1061,6 +1155,21 @@ func (c *cmdNetworkInfo) Run(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %s: %d\n", i18n.G("VLAN ID"), state.VLAN.VID)
 	}
 
+	// Connection tracking information.
+	if state.Conntrack != nil {
+		fmt.Println("")
+		fmt.Println(i18n.G("Connection tracking:"))
+		fmt.Printf("  %s: %d\n", i18n.G("Tracked connections"), state.Conntrack.Count)
+
+		if state.Conntrack.Max > 0 {
+			fmt.Printf("  %s: %d (%.1f%%)\n", i18n.G("Limit"), state.Conntrack.Max, float64(state.Conntrack.Count)*100/float64(state.Conntrack.Max))
+		}
+
+		if state.Conntrack.HostMax > 0 {
+			fmt.Printf("  %s: %d\n", i18n.G("Host table size"), state.Conntrack.HostMax)
+		}
+	}
+
 	// OVN information.
 	if state.OVN != nil {
 		fmt.Println("")
@@ -1085,6 +1194,67 @@ func (c *cmdNetworkInfo) Run(cmd *cobra.Command, args []string) error {
 		if state.OVN.UplinkIPv6 != "" {
 			fmt.Printf("  %s: %s\n", i18n.G("IPv6 uplink address"), state.OVN.UplinkIPv6)
 		}
+
+		for _, dhcpOptions := range []struct {
+			title   string
+			options map[string]string
+		}{
+			{title: i18n.G("DHCPv4 options"), options: state.OVN.DHCPv4Options},
+			{title: i18n.G("DHCPv6 options"), options: state.OVN.DHCPv6Options},
+		} {
+			if len(dhcpOptions.options) == 0 {
+				continue
+			}
+
+			fmt.Printf("  %s:\n", dhcpOptions.title)
+
+			names := make([]string, 0, len(dhcpOptions.options))
+			for name := range dhcpOptions.options {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+
+			for _, name := range names {
+				fmt.Printf("    %s: %s\n", name, dhcpOptions.options[name])
+			}
+		}
+	}
+
+	// DHCP reservations.
+	if client.HasExtension("network_dhcp_reservations") {
+		network, _, err := client.GetNetwork(resource.name)
+		if err != nil {
+			return err
+		}
+
+		if network.Managed && network.Type == "bridge" {
+			reservations, err := client.GetNetworkDHCPReservations(resource.name)
+			if err != nil {
+				return err
+			}
+
+			if len(reservations) > 0 {
+				fmt.Println("")
+				fmt.Println(i18n.G("DHCP reservations:"))
+
+				for _, reservation := range reservations {
+					addresses := []string{}
+					for _, address := range []string{reservation.IPv4Address, reservation.IPv6Address} {
+						if address != "" {
+							addresses = append(addresses, address)
+						}
+					}
+
+					line := fmt.Sprintf("  %s: %s", reservation.Hwaddr, strings.Join(addresses, ", "))
+					if reservation.Hostname != "" {
+						line += fmt.Sprintf(" (%s)", reservation.Hostname)
+					}
+
+					fmt.Println(line)
+				}
+			}
+		}
 	}
 
 	return nil
@@ -6,7 +6,9 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -902,6 +904,9 @@ func (c *cmdNetworkACLRule) Command() *cobra.Command {
 	// Rule Remove.
 	cmd.AddCommand(c.CommandRemove())
 
+	// Rule Move.
+	cmd.AddCommand(c.CommandMove())
+
 	return cmd
 }
 
@@ -947,8 +952,8 @@ func networkACLRuleJSONStructFieldMap() map[string]int {
 			continue // Skip unexported fields. It is empty for upper case (exported) field names.
 		}
 
-		if field.Type.Name() != "string" {
-			continue // Skip non-string fields.
+		if field.Type.Kind() != reflect.String && field.Type.Kind() != reflect.Int {
+			continue // Skip fields which can't be set from a string.
 		}
 
 		// Split the json tag into its name and options (e.g. json:"action,omitempty").
@@ -965,6 +970,36 @@ func networkACLRuleJSONStructFieldMap() map[string]int {
 	return allowedKeys
 }
 
+// networkACLRuleFieldString returns the value of a rule field as a string.
+func networkACLRuleFieldString(fieldValue reflect.Value) string {
+	if fieldValue.Kind() == reflect.Int {
+		return strconv.FormatInt(fieldValue.Int(), 10)
+	}
+
+	return fieldValue.String()
+}
+
+// networkACLRuleFilterMatch returns whether the supplied rule has matching field values in the filters supplied.
+// If no filters are supplied, then the rule is considered to have matched.
+func networkACLRuleFilterMatch(rule *api.NetworkACLRule, filters map[string]string) bool {
+	allowedKeys := networkACLRuleJSONStructFieldMap()
+	ruleValue := reflect.ValueOf(rule).Elem()
+
+	for k, v := range filters {
+		fieldIndex, found := allowedKeys[k]
+		if !found {
+			return false
+		}
+
+		fieldValue := ruleValue.Field(fieldIndex)
+		if networkACLRuleFieldString(fieldValue) != v {
+			return false
+		}
+	}
+
+	return true // Match found as all struct fields match the supplied filter values.
+}
+
 // parseConfigKeysToRule converts a map of key/value pairs into an api.NetworkACLRule using reflection.
 func (c *cmdNetworkACLRule) parseConfigToRule(config map[string]string) (*api.NetworkACLRule, error) {
 	// Use reflect to get struct field indices in NetworkACLRule for json tags.
@@ -985,7 +1020,17 @@ func (c *cmdNetworkACLRule) parseConfigToRule(config map[string]string) (*api.Ne
 			return nil, fmt.Errorf(i18n.G("Cannot set key: %s"), k)
 		}
 
-		fieldValue.SetString(v) // Set the value into the struct field.
+		// Set the value into the struct field.
+		if fieldValue.Kind() == reflect.Int {
+			value, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf(i18n.G("Invalid value for key %s: %w"), k, err)
+			}
+
+			fieldValue.SetInt(int64(value))
+		} else {
+			fieldValue.SetString(v)
+		}
 	}
 
 	return &rule, nil
@@ -1124,26 +1169,6 @@ func (c *cmdNetworkACLRule) RunRemove(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// isFilterMatch returns whether the supplied rule has matching field values in the filters supplied.
-	// If no filters are supplied, then the rule is considered to have matched.
-	isFilterMatch := func(rule *api.NetworkACLRule, filters map[string]string) bool {
-		ruleValue := reflect.ValueOf(rule).Elem()
-
-		for k, v := range filters {
-			fieldIndex, found := allowedKeys[k]
-			if !found {
-				return false
-			}
-
-			fieldValue := ruleValue.Field(fieldIndex)
-			if fieldValue.String() != v {
-				return false
-			}
-		}
-
-		return true // Match found as all struct fields match the supplied filter values.
-	}
-
 	// removeFromRules removes a single rule that matches the filters supplied. If multiple rules match then
 	// an error is returned unless c.flagRemoveForce is true, in which case all matching rules are removed.
 	removeFromRules := func(rules []api.NetworkACLRule, filters map[string]string) ([]api.NetworkACLRule, error) {
@@ -1151,7 +1176,7 @@ func (c *cmdNetworkACLRule) RunRemove(cmd *cobra.Command, args []string) error {
 		newRules := make([]api.NetworkACLRule, 0, len(rules))
 
 		for _, r := range rules {
-			if isFilterMatch(&r, filters) {
+			if networkACLRuleFilterMatch(&r, filters) {
 				if removed && !c.flagRemoveForce {
 					return nil, errors.New(i18n.G("Multiple rules match. Use --force to remove them all"))
 				}
@@ -1192,3 +1217,169 @@ func (c *cmdNetworkACLRule) RunRemove(cmd *cobra.Command, args []string) error {
 
 	return resource.server.UpdateNetworkACL(resource.name, netACL.Writable(), etag)
 }
+
+// CommandMove returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkACLRule) CommandMove() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("move", i18n.G("[<remote>:]<ACL> <direction> up|down <key>=<value>..."))
+	cmd.Short = i18n.G("Move a rule up or down in the evaluation order of an ACL")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Move a rule up or down in the evaluation order of an ACL
+
+The rule is moved by changing its priority, either above the rules it shares its priority with,
+or otherwise above or below the closest rules with a different priority.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus network acl rule move web ingress up action=allow destination_port=443
+    Have the rule allowing traffic to port 443 evaluated earlier`))
+
+	cmd.RunE = c.RunMove
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkACLs(toComplete)
+		}
+
+		if len(args) == 1 {
+			return []string{"ingress", "egress"}, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		if len(args) == 2 {
+			return []string{"up", "down"}, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return c.global.cmpNetworkACLRuleProperties()
+	}
+
+	return cmd
+}
+
+// networkACLRuleMovePriority returns the priority to give to the rule at the given index of the rules for it to
+// be evaluated before (up) or after (down) the rules sharing its priority or, if there are none, the closest
+// rules with a different priority.
+func networkACLRuleMovePriority(rules []api.NetworkACLRule, index int, up bool) (int, error) {
+	priority := rules[index].Priority
+	shared := false
+	closest := -1
+
+	for i, rule := range rules {
+		if i == index {
+			continue
+		}
+
+		if rule.Priority == priority {
+			shared = true
+		} else if up && rule.Priority > priority && (closest < 0 || rule.Priority < closest) {
+			closest = rule.Priority
+		} else if !up && rule.Priority < priority && (closest < 0 || rule.Priority > closest) {
+			closest = rule.Priority
+		}
+	}
+
+	if up {
+		if shared {
+			return priority + 1, nil
+		}
+
+		if closest < 0 {
+			return -1, errors.New(i18n.G("The rule is already evaluated first"))
+		}
+
+		return closest + 1, nil
+	}
+
+	if shared {
+		priority--
+	} else if closest < 0 {
+		return -1, errors.New(i18n.G("The rule is already evaluated last"))
+	} else {
+		priority = closest - 1
+	}
+
+	if priority < 0 {
+		return -1, errors.New(i18n.G("The rule can't be moved below rules with priority 0, raise the priority of the other rules instead"))
+	}
+
+	return priority, nil
+}
+
+// RunMove runs the actual command logic.
+func (c *cmdNetworkACLRule) RunMove(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 3, -1)
+	if exit {
+		return err
+	}
+
+	if !slices.Contains([]string{"up", "down"}, args[2]) {
+		return errors.New(i18n.G("The position argument must be one of: up, down"))
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network ACL name"))
+	}
+
+	// Get config filters from arguments.
+	filters, err := getConfig(args[3:]...)
+	if err != nil {
+		return err
+	}
+
+	allowedKeys := networkACLRuleJSONStructFieldMap()
+	for k := range filters {
+		_, found := allowedKeys[k]
+		if !found {
+			return fmt.Errorf(i18n.G("Unknown key: %s"), k)
+		}
+	}
+
+	// Get the network ACL.
+	netACL, etag, err := resource.server.GetNetworkACL(resource.name)
+	if err != nil {
+		return err
+	}
+
+	var rules []api.NetworkACLRule
+	switch args[1] {
+	case "ingress":
+		rules = netACL.Ingress
+	case "egress":
+		rules = netACL.Egress
+	default:
+		return errors.New(i18n.G("The direction argument must be one of: ingress, egress"))
+	}
+
+	// Find the rule to move.
+	index := -1
+	for i := range rules {
+		if !networkACLRuleFilterMatch(&rules[i], filters) {
+			continue
+		}
+
+		if index >= 0 {
+			return errors.New(i18n.G("Multiple rules match"))
+		}
+
+		index = i
+	}
+
+	if index < 0 {
+		return errors.New(i18n.G("No matching rule(s) found"))
+	}
+
+	priority, err := networkACLRuleMovePriority(rules, index, args[2] == "up")
+	if err != nil {
+		return err
+	}
+
+	rules[index].Priority = priority
+
+	return resource.server.UpdateNetworkACL(resource.name, netACL.Writable(), etag)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Test the priorities given to rules moved up or down.
+func TestNetworkACLRuleMovePriority(t *testing.T) {
+	rules := []api.NetworkACLRule{
+		{Action: "allow", Priority: 0},
+		{Action: "drop", Priority: 0},
+		{Action: "allow", Priority: 5},
+		{Action: "reject", Priority: 10},
+	}
+
+	tests := []struct {
+		name     string
+		index    int
+		up       bool
+		expected int
+		err      bool
+	}{
+		{name: "Up from shared priority", index: 0, up: true, expected: 1},
+		{name: "Up above closest priority", index: 2, up: true, expected: 11},
+		{name: "Up when first", index: 3, up: true, err: true},
+		{name: "Down below closest priority", index: 3, up: false, expected: 4},
+		{name: "Down when last", index: 0, up: false, err: true},
+		{name: "Down below priority 0", index: 2, up: false, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			priority, err := networkACLRuleMovePriority(rules, test.index, test.up)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, priority)
+		})
+	}
+}
+
+// Test that the priority can be set and filtered on like the other rule properties.
+func TestNetworkACLRulePriorityProperty(t *testing.T) {
+	c := cmdNetworkACLRule{}
+
+	rule, err := c.parseConfigToRule(map[string]string{"action": "allow", "priority": "10"})
+	require.NoError(t, err)
+	assert.Equal(t, api.NetworkACLRule{Action: "allow", Priority: 10}, *rule)
+
+	assert.True(t, networkACLRuleFilterMatch(rule, map[string]string{"priority": "10"}))
+	assert.False(t, networkACLRuleFilterMatch(rule, map[string]string{"priority": "0"}))
+
+	_, err = c.parseConfigToRule(map[string]string{"priority": "high"})
+	assert.Error(t, err)
+}
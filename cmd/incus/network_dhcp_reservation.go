@@ -0,0 +1,512 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/termios"
+)
+
+type cmdNetworkDHCPReservation struct {
+	global *cmdGlobal
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkDHCPReservation) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("dhcp-reservation")
+	cmd.Aliases = []string{"reservation"}
+	cmd.Short = i18n.G("Manage network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage network DHCP reservations
+
+DHCP reservations assign a fixed address to devices which aren't Incus instances.`))
+
+	// List.
+	networkDHCPReservationListCmd := cmdNetworkDHCPReservationList{global: c.global, networkDHCPReservation: c}
+	cmd.AddCommand(networkDHCPReservationListCmd.Command())
+
+	// Show.
+	networkDHCPReservationShowCmd := cmdNetworkDHCPReservationShow{global: c.global, networkDHCPReservation: c}
+	cmd.AddCommand(networkDHCPReservationShowCmd.Command())
+
+	// Create.
+	networkDHCPReservationCreateCmd := cmdNetworkDHCPReservationCreate{global: c.global, networkDHCPReservation: c}
+	cmd.AddCommand(networkDHCPReservationCreateCmd.Command())
+
+	// Edit.
+	networkDHCPReservationEditCmd := cmdNetworkDHCPReservationEdit{global: c.global, networkDHCPReservation: c}
+	cmd.AddCommand(networkDHCPReservationEditCmd.Command())
+
+	// Delete.
+	networkDHCPReservationDeleteCmd := cmdNetworkDHCPReservationDelete{global: c.global, networkDHCPReservation: c}
+	cmd.AddCommand(networkDHCPReservationDeleteCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdNetworkDHCPReservationList struct {
+	global                 *cmdGlobal
+	networkDHCPReservation *cmdNetworkDHCPReservation
+
+	flagFormat string
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkDHCPReservationList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<network>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List network DHCP reservations"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", c.global.defaultListFormat(), i18n.G(`Format (csv|json|table|yaml|compact), use suffix ",noheader" to disable headers and ",header" to enable it if missing, e.g. csv,header`)+"``")
+
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
+		return cli.ValidateFlagFormatForListOutput(cmd.Flag("format").Value.String())
+	}
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkDHCPReservationList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	reservations, err := resource.server.GetNetworkDHCPReservations(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(reservations))
+	for _, reservation := range reservations {
+		data = append(data, []string{reservation.Hwaddr, reservation.IPv4Address, reservation.IPv6Address, reservation.Hostname, reservation.Description})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("MAC ADDRESS"),
+		i18n.G("IPV4 ADDRESS"),
+		i18n.G("IPV6 ADDRESS"),
+		i18n.G("HOSTNAME"),
+		i18n.G("DESCRIPTION"),
+	}
+
+	return cli.RenderTable(os.Stdout, c.flagFormat, header, data, reservations)
+}
+
+// Show.
+type cmdNetworkDHCPReservationShow struct {
+	global                 *cmdGlobal
+	networkDHCPReservation *cmdNetworkDHCPReservation
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkDHCPReservationShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<network> <MAC>"))
+	cmd.Short = i18n.G("Show network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show network DHCP reservations"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkDHCPReservationShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing MAC address"))
+	}
+
+	// Show the DHCP reservation.
+	reservation, _, err := resource.server.GetNetworkDHCPReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&reservation)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdNetworkDHCPReservationCreate struct {
+	global                 *cmdGlobal
+	networkDHCPReservation *cmdNetworkDHCPReservation
+
+	flagIPv6        string
+	flagHostname    string
+	flagDescription string
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkDHCPReservationCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<network> <MAC> [<IPv4>]"))
+	cmd.Short = i18n.G("Create new network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new network DHCP reservations"))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus network dhcp-reservation create incusbr0 10:66:6a:a4:a5:63 10.0.0.10 --hostname printer
+    Reserve 10.0.0.10 for the device with MAC address 10:66:6a:a4:a5:63 on network incusbr0
+
+incus network dhcp-reservation create incusbr0 10:66:6a:a4:a5:63 < reservation.yaml
+    Create a new DHCP reservation for network incusbr0 from reservation.yaml`))
+
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.flagIPv6, "ipv6", "", i18n.G("Reserved IPv6 address")+"``")
+	cmd.Flags().StringVar(&c.flagHostname, "hostname", "", i18n.G("Host name handed out with the reservation")+"``")
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("DHCP reservation description")+"``")
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkDHCPReservationCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 3)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing MAC address"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var reservationPut api.NetworkDHCPReservationPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &reservationPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(args) > 2 {
+		reservationPut.IPv4Address = args[2]
+	}
+
+	if c.flagIPv6 != "" {
+		reservationPut.IPv6Address = c.flagIPv6
+	}
+
+	if c.flagHostname != "" {
+		reservationPut.Hostname = c.flagHostname
+	}
+
+	if c.flagDescription != "" {
+		reservationPut.Description = c.flagDescription
+	}
+
+	// Create the DHCP reservation.
+	reservation := api.NetworkDHCPReservationsPost{
+		Hwaddr:                    args[1],
+		NetworkDHCPReservationPut: reservationPut,
+	}
+
+	reservation.Normalise()
+
+	err = resource.server.CreateNetworkDHCPReservation(resource.name, reservation)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network DHCP reservation %s created")+"\n", reservation.Hwaddr)
+	}
+
+	return nil
+}
+
+// Edit.
+type cmdNetworkDHCPReservationEdit struct {
+	global                 *cmdGlobal
+	networkDHCPReservation *cmdNetworkDHCPReservation
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkDHCPReservationEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<network> <MAC>"))
+	cmd.Short = i18n.G("Edit network DHCP reservations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit network DHCP reservations as YAML"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkDHCPReservationEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network DHCP reservation.
+### Any line starting with a '# will be ignored.
+###
+### An example would look like:
+### hwaddr: 10:66:6a:a4:a5:63
+### ipv4_address: 10.0.0.10
+### ipv6_address: ""
+### hostname: printer
+### description: Office printer
+###
+### Note that the hwaddr cannot be changed.`)
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkDHCPReservationEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing MAC address"))
+	}
+
+	client := resource.server
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `incus network dhcp-reservation show` command to be passed in here, but only take
+		// the contents of the NetworkDHCPReservationPut fields when updating.
+		newData := api.NetworkDHCPReservation{}
+		err = yaml.UnmarshalStrict(contents, &newData)
+		if err != nil {
+			return err
+		}
+
+		newData.Normalise()
+
+		return client.UpdateNetworkDHCPReservation(resource.name, args[1], newData.NetworkDHCPReservationPut, "")
+	}
+
+	// Get the current config.
+	reservation, etag, err := client.GetNetworkDHCPReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&reservation)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := textEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.NetworkDHCPReservation{} // We show the full info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newData)
+		if err == nil {
+			newData.Normalise()
+			err = client.UpdateNetworkDHCPReservation(resource.name, args[1], newData.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = textEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkDHCPReservationDelete struct {
+	global                 *cmdGlobal
+	networkDHCPReservation *cmdNetworkDHCPReservation
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkDHCPReservationDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<network> <MAC>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete network DHCP reservations"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkDHCPReservationDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing MAC address"))
+	}
+
+	// Delete the DHCP reservation.
+	err = resource.server.DeleteNetworkDHCPReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network DHCP reservation %s deleted")+"\n", args[1])
+	}
+
+	return nil
+}
@@ -111,6 +111,7 @@ l - Listen Address
 d - Description
 D - Default Target Address
 p - Port
+P - Port mappings (listen ports to target address and ports)
 L - Location of the network zone (e.g. its cluster member)`))
 
 	cmd.RunE = c.Run
@@ -140,6 +141,7 @@ func (c *cmdNetworkForwardList) parseColumns(clustered bool) ([]networkForwardCo
 		'd': {i18n.G("DESCRIPTION"), c.descriptionColumnData},
 		'D': {i18n.G("DEFAULT TARGET ADDRESS"), c.defaultTargetAddressColumnData},
 		'p': {i18n.G("PORTS"), c.portsColumnData},
+		'P': {i18n.G("PORT MAPPINGS"), c.portMappingsColumnData},
 		'L': {i18n.G("LOCATION"), c.locationColumnData},
 	}
 
@@ -183,6 +185,25 @@ func (c *cmdNetworkForwardList) portsColumnData(forward api.NetworkForward) stri
 	return fmt.Sprintf("%d", len(forward.Ports))
 }
 
+func (c *cmdNetworkForwardList) portMappingsColumnData(forward api.NetworkForward) string {
+	mappings := make([]string, 0, len(forward.Ports))
+	for _, port := range forward.Ports {
+		targetPort := port.TargetPort
+		if targetPort == "" {
+			targetPort = port.ListenPort
+		}
+
+		if port.Hostnames != "" {
+			mappings = append(mappings, fmt.Sprintf("%s/%s (%s) -> %s:%s", port.Protocol, port.ListenPort, port.Hostnames, port.TargetAddress, targetPort))
+			continue
+		}
+
+		mappings = append(mappings, fmt.Sprintf("%s/%s -> %s:%s", port.Protocol, port.ListenPort, port.TargetAddress, targetPort))
+	}
+
+	return strings.Join(mappings, "\n")
+}
+
 func (c *cmdNetworkForwardList) locationColumnData(forward api.NetworkForward) string {
 	return forward.Location
 }
@@ -918,6 +939,7 @@ type cmdNetworkForwardPort struct {
 	networkForward  *cmdNetworkForward
 	flagRemoveForce bool
 	flagDescription string
+	flagHostnames   string
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -946,6 +968,7 @@ func (c *cmdNetworkForwardPort) CommandAdd() *cobra.Command {
 
 	cmd.Flags().StringVar(&c.networkForward.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Port description")+"``")
+	cmd.Flags().StringVar(&c.flagHostnames, "hostnames", "", i18n.G("Comma separated list of hostnames to route to the target (http and https protocols)")+"``")
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
@@ -957,7 +980,7 @@ func (c *cmdNetworkForwardPort) CommandAdd() *cobra.Command {
 		}
 
 		if len(args) == 2 {
-			return []string{"tcp", "udp"}, cobra.ShellCompDirectiveNoFileComp
+			return []string{"tcp", "udp", "http", "https"}, cobra.ShellCompDirectiveNoFileComp
 		}
 
 		return nil, cobra.ShellCompDirectiveNoFileComp
@@ -1008,6 +1031,7 @@ func (c *cmdNetworkForwardPort) RunAdd(cmd *cobra.Command, args []string) error
 		ListenPort:    args[3],
 		TargetAddress: args[4],
 		Description:   c.flagDescription,
+		Hostnames:     c.flagHostnames,
 	}
 
 	if len(args) > 5 {
@@ -1028,6 +1052,7 @@ func (c *cmdNetworkForwardPort) CommandRemove() *cobra.Command {
 	cmd.Short = i18n.G("Remove ports from a forward")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Remove ports from a forward"))
 	cmd.Flags().BoolVar(&c.flagRemoveForce, "force", false, i18n.G("Remove all ports that match"))
+	cmd.Flags().StringVar(&c.flagHostnames, "hostnames", "", i18n.G("Only remove ports with these hostnames (http and https protocols)")+"``")
 	cmd.RunE = c.RunRemove
 
 	cmd.Flags().StringVar(&c.networkForward.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
@@ -1091,6 +1116,10 @@ func (c *cmdNetworkForwardPort) RunRemove(cmd *cobra.Command, args []string) err
 	// isFilterMatch returns whether the supplied port has matching field values in the filterArgs supplied.
 	// If no filterArgs are supplied, then the rule is considered to have matched.
 	isFilterMatch := func(port *api.NetworkForwardPort, filterArgs []string) bool {
+		if c.flagHostnames != "" && port.Hostnames != c.flagHostnames {
+			return false
+		}
+
 		switch len(filterArgs) {
 		case 3:
 			if port.ListenPort != filterArgs[2] {
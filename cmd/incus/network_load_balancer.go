@@ -897,6 +897,7 @@ type cmdNetworkLoadBalancerBackend struct {
 	global              *cmdGlobal
 	networkLoadBalancer *cmdNetworkLoadBalancer
 	flagDescription     string
+	flagWeight          int
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -925,6 +926,7 @@ func (c *cmdNetworkLoadBalancerBackend) CommandAdd() *cobra.Command {
 
 	cmd.Flags().StringVar(&c.networkLoadBalancer.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Backend description")+"``")
+	cmd.Flags().IntVar(&c.flagWeight, "weight", 0, i18n.G("Backend weight relative to the other backends")+"``")
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
@@ -984,6 +986,14 @@ func (c *cmdNetworkLoadBalancerBackend) RunAdd(cmd *cobra.Command, args []string
 		Description:   c.flagDescription,
 	}
 
+	if cmd.Flags().Changed("weight") {
+		if c.flagWeight <= 0 {
+			return errors.New(i18n.G("The backend weight must be a positive integer"))
+		}
+
+		backend.Weight = &c.flagWeight
+	}
+
 	if len(args) >= 5 {
 		backend.TargetPort = args[4]
 	}
@@ -1356,24 +1366,41 @@ func (c *cmdNetworkLoadBalancerInfo) Run(cmd *cobra.Command, args []string) erro
 	}
 
 	// Render the state.
-	if lbState.BackendHealth == nil {
-		// Currently the only field in the state endpoint is the backend health, fail if it's missing.
-		return errors.New(i18n.G("No load-balancer health information available"))
+	if lbState.BackendHealth == nil && lbState.BackendWeight == nil {
+		return errors.New(i18n.G("No load-balancer state information available"))
 	}
 
-	fmt.Println(i18n.G("Backend health:"))
-	for backend, info := range lbState.BackendHealth {
-		if len(info.Ports) == 0 {
-			continue
+	if len(lbState.BackendWeight) > 0 {
+		backends := make([]string, 0, len(lbState.BackendWeight))
+		for backend := range lbState.BackendWeight {
+			backends = append(backends, backend)
 		}
 
-		fmt.Printf("  %s (%s):\n", backend, info.Address)
-		for _, port := range info.Ports {
-			fmt.Printf("    - %s/%d: %s\n", port.Protocol, port.Port, port.Status)
+		sort.Strings(backends)
+
+		fmt.Println(i18n.G("Backend weights:"))
+		for _, backend := range backends {
+			fmt.Printf("  %s: %d\n", backend, lbState.BackendWeight[backend])
 		}
 
 		fmt.Println("")
 	}
 
+	if lbState.BackendHealth != nil {
+		fmt.Println(i18n.G("Backend health:"))
+		for backend, info := range lbState.BackendHealth {
+			if len(info.Ports) == 0 {
+				continue
+			}
+
+			fmt.Printf("  %s (%s):\n", backend, info.Address)
+			for _, port := range info.Ports {
+				fmt.Printf("    - %s/%d: %s\n", port.Protocol, port.Port, port.Status)
+			}
+
+			fmt.Println("")
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+type cmdNetworkOVN struct {
+	global *cmdGlobal
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkOVN) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("ovn")
+	cmd.Short = i18n.G("Debug OVN networks")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Debug OVN networks"))
+
+	// Trace.
+	networkOVNTraceCmd := cmdNetworkOVNTrace{global: c.global, networkOVN: c}
+	cmd.AddCommand(networkOVNTraceCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// Trace.
+type cmdNetworkOVNTrace struct {
+	global     *cmdGlobal
+	networkOVN *cmdNetworkOVN
+
+	flagSource      string
+	flagDestination string
+	flagProtocol    string
+	flagPort        int
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkOVNTrace) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("trace", i18n.G("[<remote>:]<network>"))
+	cmd.Short = i18n.G("Trace a packet through an OVN network")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Trace a packet through an OVN network
+
+The packet goes through the logical flows of the network as computed by ovn-trace.
+At least one of the addresses must belong to a running instance on the network,
+the other one is considered to be reached through the network's router.
+
+The output is annotated with the network ACLs and instances behind the OVN objects it refers to.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus network ovn trace ovn0 --src 10.0.0.2 --dst 10.0.0.3 --port 80
+    Trace a TCP connection from 10.0.0.2 to port 80 of 10.0.0.3
+
+incus network ovn trace ovn0 --src 10.0.0.2 --dst 1.1.1.1 --protocol icmp
+    Trace a ping from 10.0.0.2 to an external address`))
+
+	cmd.Flags().StringVar(&c.flagSource, "src", "", i18n.G("Source address of the packet")+"``")
+	cmd.Flags().StringVar(&c.flagDestination, "dst", "", i18n.G("Destination address of the packet")+"``")
+	cmd.Flags().StringVar(&c.flagProtocol, "protocol", "tcp", i18n.G("Protocol of the packet (tcp, udp or icmp)")+"``")
+	cmd.Flags().IntVar(&c.flagPort, "port", 0, i18n.G("Destination port of the packet")+"``")
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return c.global.cmpNetworks(toComplete)
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkOVNTrace) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	if c.flagSource == "" || c.flagDestination == "" {
+		return errors.New(i18n.G("Both --src and --dst must be provided"))
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	req := api.NetworkTracePost{
+		Source:      c.flagSource,
+		Destination: c.flagDestination,
+		Protocol:    c.flagProtocol,
+		Port:        c.flagPort,
+	}
+
+	trace, err := resource.server.TraceNetwork(resource.name, req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Microflow: %s")+"\n\n", trace.Microflow)
+	fmt.Print(trace.Output)
+
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	cli "github.com/lxc/incus/v6/internal/cmd"
 	"github.com/lxc/incus/v6/internal/i18n"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/units"
 )
 
 type cmdOperation struct {
@@ -45,6 +46,10 @@ func (c *cmdOperation) Command() *cobra.Command {
 	operationShowCmd := cmdOperationShow{global: c.global, operation: c}
 	cmd.AddCommand(operationShowCmd.Command())
 
+	// Throttle
+	operationThrottleCmd := cmdOperationThrottle{global: c.global, operation: c}
+	cmd.AddCommand(operationThrottleCmd.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
@@ -340,3 +345,74 @@ func (c *cmdOperationShow) Run(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// Throttle.
+type cmdOperationThrottle struct {
+	global    *cmdGlobal
+	operation *cmdOperation
+
+	flagBandwidth string
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdOperationThrottle) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("throttle", i18n.G("[<remote>:]<operation>"))
+	cmd.Short = i18n.G("Limit the bandwidth of a background operation")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Limit the bandwidth of a background operation
+
+The limit is in bytes per second and applies to the data transferred by the operation,
+like image downloads, migrations, local copies and btrfs pool scrubs. A limit of 0 removes it.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus operation throttle 344a79e4-d88a-45bf-9c39-c72c26f6ab8a --bandwidth 10MB
+    Limit that operation to 10MB/s`))
+
+	cmd.Flags().StringVar(&c.flagBandwidth, "bandwidth", "", i18n.G("Bandwidth limit in bytes per second (0 to remove the limit)")+"``")
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdOperationThrottle) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	if c.flagBandwidth == "" {
+		return errors.New(i18n.G("A bandwidth limit must be provided with --bandwidth"))
+	}
+
+	bandwidth, err := units.ParseByteSizeString(c.flagBandwidth)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Invalid bandwidth limit %q: %w"), c.flagBandwidth, err)
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// Update the operation
+	err = resource.server.UpdateOperation(resource.name, api.OperationPut{Bandwidth: bandwidth})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		if bandwidth > 0 {
+			fmt.Printf(i18n.G("Operation %s limited to %s/s")+"\n", resource.name, units.GetByteSizeString(bandwidth, 2))
+		} else {
+			fmt.Printf(i18n.G("Operation %s no longer limited")+"\n", resource.name)
+		}
+	}
+
+	return nil
+}
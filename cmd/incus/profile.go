@@ -1,10 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
 	"slices"
@@ -73,6 +75,10 @@ func (c *cmdProfile) Command() *cobra.Command {
 	profileListCmd := cmdProfileList{global: c.global, profile: c}
 	cmd.AddCommand(profileListCmd.Command())
 
+	// List instances
+	profileListInstancesCmd := cmdProfileListInstances{global: c.global, profile: c}
+	cmd.AddCommand(profileListInstancesCmd.Command())
+
 	// Remove
 	profileRemoveCmd := cmdProfileRemove{global: c.global, profile: c}
 	cmd.AddCommand(profileRemoveCmd.Command())
@@ -179,16 +185,21 @@ func (c *cmdProfileAdd) Run(cmd *cobra.Command, args []string) error {
 type cmdProfileAssign struct {
 	global  *cmdGlobal
 	profile *cmdProfile
+
+	flagAdd bool
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
 func (c *cmdProfileAssign) Command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("assign", i18n.G("[<remote>:]<instance> <profiles>"))
+	cmd.Use = usage("assign", i18n.G("[<remote>:]<instance> <profiles> | --add [<remote>:]<profile> <instance>..."))
 	cmd.Aliases = []string{"apply"}
 	cmd.Short = i18n.G("Assign sets of profiles to instances")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Assign sets of profiles to instances`))
+		`Assign sets of profiles to instances
+
+With --add, the profile is added to all the provided instances at once.
+All the instances are validated first and none of them is modified if any fails to validate or update.`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`incus profile assign foo default,bar
     Set the profiles for "foo" to "default" and "bar".
@@ -197,11 +208,24 @@ incus profile assign foo default
     Reset "foo" to only using the "default" profile.
 
 incus profile assign foo ''
-    Remove all profile from "foo"`))
+    Remove all profile from "foo"
+
+incus profile assign --add bar foo1 foo2 foo3
+    Add the "bar" profile to "foo1", "foo2" and "foo3".`))
+
+	cmd.Flags().BoolVar(&c.flagAdd, "add", false, i18n.G("Add the profile to multiple instances at once"))
 
 	cmd.RunE = c.Run
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if c.flagAdd {
+			if len(args) == 0 {
+				return c.global.cmpProfiles(toComplete, true)
+			}
+
+			return c.global.cmpInstanceNamesFromRemote(args[0])
+		}
+
 		if len(args) == 0 {
 			return c.global.cmpInstances(toComplete)
 		}
@@ -214,6 +238,10 @@ incus profile assign foo ''
 
 // Run runs the actual command logic.
 func (c *cmdProfileAssign) Run(cmd *cobra.Command, args []string) error {
+	if c.flagAdd {
+		return c.runAdd(cmd, args)
+	}
+
 	// Quick checks.
 	exit, err := c.global.checkArgs(cmd, args, 2, 2)
 	if exit {
@@ -265,6 +293,60 @@ func (c *cmdProfileAssign) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runAdd adds the profile to all the provided instances at once.
+func (c *cmdProfileAssign) runAdd(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing profile name"))
+	}
+
+	// Add the profile
+	op, err := resource.server.AssignProfile(resource.name, api.ProfileAssignPost{Instances: args[1:]})
+	if err != nil {
+		return err
+	}
+
+	opErr := op.Wait()
+
+	// Report the outcome for each instance.
+	if !c.global.flagQuiet {
+		opAPI := op.Get()
+		results, err := opAPI.ToProfileAssignInstances()
+		if err == nil {
+			for _, result := range results {
+				if result.Error != "" {
+					fmt.Printf("%s: %s (%s)\n", result.Name, result.Status, result.Error)
+				} else {
+					fmt.Printf("%s: %s\n", result.Name, result.Status)
+				}
+			}
+		}
+	}
+
+	if opErr != nil {
+		return opErr
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Profile %s added to %s")+"\n", resource.name, strings.Join(args[1:], ", "))
+	}
+
+	return nil
+}
+
 // Copy.
 type cmdProfileCopy struct {
 	global  *cmdGlobal
@@ -358,7 +440,9 @@ type cmdProfileCreate struct {
 	global  *cmdGlobal
 	profile *cmdProfile
 
-	flagDescription string
+	flagDescription             string
+	flagFromInstance            string
+	flagIncludeInstanceSpecific bool
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -372,11 +456,16 @@ func (c *cmdProfileCreate) Command() *cobra.Command {
     Create a profile named p1
 
 incus profile create p1 < config.yaml
-    Create a profile named p1 with configuration from config.yaml`))
+    Create a profile named p1 with configuration from config.yaml
+
+incus profile create p1 --from-instance c1
+    Create a profile named p1 with the configuration and devices of instance c1`))
 
 	cmd.RunE = c.Run
 
 	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Profile description")+"``")
+	cmd.Flags().StringVar(&c.flagFromInstance, "from-instance", "", i18n.G("Copy the configuration and devices of an instance")+"``")
+	cmd.Flags().BoolVar(&c.flagIncludeInstanceSpecific, "include-instance-specific", false, i18n.G("Keep the instance specific device options (MAC and static addresses, host interface names)"))
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
@@ -399,6 +488,10 @@ func (c *cmdProfileCreate) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if c.flagIncludeInstanceSpecific && c.flagFromInstance == "" {
+		return errors.New(i18n.G("--include-instance-specific can only be used with --from-instance"))
+	}
+
 	// If stdin isn't a terminal, read text from it
 	if !termios.IsTerminal(getStdinFd()) {
 		contents, err := io.ReadAll(os.Stdin)
@@ -406,6 +499,11 @@ func (c *cmdProfileCreate) Run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		// The profile is either copied from the instance or read from stdin, not both.
+		if c.flagFromInstance != "" && len(bytes.TrimSpace(contents)) > 0 {
+			return errors.New(i18n.G("--from-instance can't be used with a profile definition on stdin"))
+		}
+
 		err = yaml.Unmarshal(contents, &stdinData)
 		if err != nil {
 			return err
@@ -424,6 +522,16 @@ func (c *cmdProfileCreate) Run(cmd *cobra.Command, args []string) error {
 		return errors.New(i18n.G("Missing profile name"))
 	}
 
+	// Copy the configuration of the instance.
+	if c.flagFromInstance != "" {
+		inst, _, err := resource.server.GetInstance(c.flagFromInstance)
+		if err != nil {
+			return err
+		}
+
+		stdinData = profileFromInstance(inst, c.flagIncludeInstanceSpecific)
+	}
+
 	// Create the profile
 	profile := api.ProfilesPost{}
 	profile.Name = resource.name
@@ -445,6 +553,41 @@ func (c *cmdProfileCreate) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// profileInstanceSpecificDeviceKeys lists the device options which only make sense for a single instance.
+var profileInstanceSpecificDeviceKeys = []string{"hwaddr", "host_name", "ipv4.address", "ipv6.address"}
+
+// profileFromInstance returns a profile with the expanded configuration and devices of the instance.
+// The volatile and image keys, which can't be set on profiles, are always left out. The device options specific
+// to the instance are left out unless includeInstanceSpecific is true.
+func profileFromInstance(inst *api.Instance, includeInstanceSpecific bool) api.ProfilePut {
+	profile := api.ProfilePut{
+		Config:  map[string]string{},
+		Devices: map[string]map[string]string{},
+	}
+
+	for key, value := range inst.ExpandedConfig {
+		if strings.HasPrefix(key, "volatile.") || strings.HasPrefix(key, "image.") {
+			continue
+		}
+
+		profile.Config[key] = value
+	}
+
+	for name, device := range inst.ExpandedDevices {
+		profile.Devices[name] = map[string]string{}
+
+		for key, value := range device {
+			if !includeInstanceSpecific && slices.Contains(profileInstanceSpecificDeviceKeys, key) {
+				continue
+			}
+
+			profile.Devices[name][key] = value
+		}
+	}
+
+	return profile
+}
+
 // Delete.
 type cmdProfileDelete struct {
 	global  *cmdGlobal
@@ -913,6 +1056,219 @@ func (c *cmdProfileList) Run(cmd *cobra.Command, args []string) error {
 	return cli.RenderTable(os.Stdout, c.flagFormat, header, data, profiles)
 }
 
+// List instances.
+type cmdProfileListInstances struct {
+	global      *cmdGlobal
+	profile     *cmdProfile
+	flagFormat  string
+	flagColumns string
+}
+
+type profileInstanceColumn struct {
+	Name string
+	Data func(api.Instance) string
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdProfileListInstances) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list-instances", i18n.G("[<remote>:]<profile>"))
+	cmd.Short = i18n.G("List instances using a profile")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List instances using a profile
+
+This includes the instances of other projects using the profile.
+
+Default column layout: nets
+
+== Columns ==
+The -c option takes a comma separated list of arguments that control
+which instance attributes to output when displaying in table or csv
+format.
+
+Commas between consecutive shorthand chars are optional.
+
+Pre-defined column shorthand chars:
+  n - Name
+  e - Project
+  t - Type
+  s - State
+  L - Location of the instance (e.g. its cluster member)`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", c.global.defaultListFormat(), i18n.G(`Format (csv|json|table|yaml|compact), use suffix ",noheader" to disable headers and ",header" to enable it if missing, e.g. csv,header`)+"``")
+	cmd.Flags().StringVarP(&c.flagColumns, "columns", "c", defaultProfileListInstancesColumns, i18n.G("Columns")+"``")
+
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
+		return cli.ValidateFlagFormatForListOutput(cmd.Flag("format").Value.String())
+	}
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpProfiles(toComplete, true)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+const defaultProfileListInstancesColumns = "nets"
+
+func (c *cmdProfileListInstances) parseColumns(clustered bool) ([]profileInstanceColumn, error) {
+	columnsShorthandMap := map[rune]profileInstanceColumn{
+		'n': {i18n.G("NAME"), c.nameColumnData},
+		'e': {i18n.G("PROJECT"), c.projectColumnData},
+		't': {i18n.G("TYPE"), c.typeColumnData},
+		's': {i18n.G("STATE"), c.stateColumnData},
+		'L': {i18n.G("LOCATION"), c.locationColumnData},
+	}
+
+	columnList := strings.Split(c.flagColumns, ",")
+	columns := []profileInstanceColumn{}
+	if c.flagColumns == defaultProfileListInstancesColumns && clustered {
+		columnList = append(columnList, "L")
+	}
+
+	for _, columnEntry := range columnList {
+		if columnEntry == "" {
+			return nil, fmt.Errorf(i18n.G("Empty column entry (redundant, leading or trailing command) in '%s'"), c.flagColumns)
+		}
+
+		for _, columnRune := range columnEntry {
+			column, ok := columnsShorthandMap[columnRune]
+			if !ok {
+				return nil, fmt.Errorf(i18n.G("Unknown column shorthand char '%c' in '%s'"), columnRune, columnEntry)
+			}
+
+			columns = append(columns, column)
+		}
+	}
+
+	return columns, nil
+}
+
+func (c *cmdProfileListInstances) nameColumnData(inst api.Instance) string {
+	return inst.Name
+}
+
+func (c *cmdProfileListInstances) projectColumnData(inst api.Instance) string {
+	return inst.Project
+}
+
+func (c *cmdProfileListInstances) typeColumnData(inst api.Instance) string {
+	if inst.Type == "" {
+		inst.Type = "container"
+	}
+
+	return strings.ToUpper(inst.Type)
+}
+
+func (c *cmdProfileListInstances) stateColumnData(inst api.Instance) string {
+	return strings.ToUpper(inst.Status)
+}
+
+func (c *cmdProfileListInstances) locationColumnData(inst api.Instance) string {
+	return inst.Location
+}
+
+// profileUsedByInstances returns the names of the instances found in a profile's used by list, grouped by project.
+func profileUsedByInstances(usedBy []string) map[string][]string {
+	instances := map[string][]string{}
+	for _, entry := range usedBy {
+		u, err := url.Parse(entry)
+		if err != nil {
+			continue
+		}
+
+		fields := strings.Split(strings.TrimPrefix(u.Path, "/1.0/"), "/")
+		if len(fields) != 2 || fields[0] != "instances" {
+			continue
+		}
+
+		name, err := url.PathUnescape(fields[1])
+		if err != nil {
+			continue
+		}
+
+		projectName := u.Query().Get("project")
+		if projectName == "" {
+			projectName = api.ProjectDefaultName
+		}
+
+		instances[projectName] = append(instances[projectName], name)
+	}
+
+	return instances
+}
+
+// Run runs the actual command logic.
+func (c *cmdProfileListInstances) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing profile name"))
+	}
+
+	profile, _, err := resource.server.GetProfile(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Get the instances using the profile, one project at a time.
+	instances := []api.Instance{}
+	for projectName, names := range profileUsedByInstances(profile.UsedBy) {
+		projectInstances, err := resource.server.UseProject(projectName).GetInstances(api.InstanceTypeAny)
+		if err != nil {
+			return err
+		}
+
+		for _, inst := range projectInstances {
+			if slices.Contains(names, inst.Name) {
+				instances = append(instances, inst)
+			}
+		}
+	}
+
+	// Parse column flags.
+	columns, err := c.parseColumns(resource.server.IsClustered())
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, inst := range instances {
+		line := []string{}
+		for _, column := range columns {
+			line = append(line, column.Data(inst))
+		}
+
+		data = append(data, line)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{}
+	for _, column := range columns {
+		header = append(header, column.Name)
+	}
+
+	return cli.RenderTable(os.Stdout, c.flagFormat, header, data, instances)
+}
+
 // Remove.
 type cmdProfileRemove struct {
 	global  *cmdGlobal
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestProfileUsedByInstances(t *testing.T) {
+	usedBy := []string{
+		"/1.0/instances/c1",
+		"/1.0/instances/c2?project=foo",
+		"/1.0/instances/c3",
+		"/1.0/instances/with%20space?project=foo",
+		"/1.0/instances/c1/snapshots/snap0",
+		"/1.0/profiles/default",
+	}
+
+	expected := map[string][]string{
+		"default": {"c1", "c3"},
+		"foo":     {"c2", "with space"},
+	}
+
+	assert.Equal(t, expected, profileUsedByInstances(usedBy))
+	assert.Empty(t, profileUsedByInstances(nil))
+}
+
+func TestProfileFromInstance(t *testing.T) {
+	inst := &api.Instance{
+		ExpandedConfig: map[string]string{
+			"limits.cpu":                "2",
+			"user.foo":                  "bar",
+			"image.os":                  "Debian",
+			"volatile.eth0.hwaddr":      "00:16:3e:00:00:01",
+			"volatile.last_state.power": "STOPPED",
+		},
+		ExpandedDevices: map[string]map[string]string{
+			"root": {"type": "disk", "path": "/", "pool": "default"},
+			"eth0": {"type": "nic", "network": "incusbr0", "ipv4.address": "10.0.0.2", "hwaddr": "00:16:3e:00:00:02"},
+		},
+	}
+
+	profile := profileFromInstance(inst, false)
+	assert.Equal(t, map[string]string{"limits.cpu": "2", "user.foo": "bar"}, profile.Config)
+	assert.Equal(t, map[string]map[string]string{
+		"root": {"type": "disk", "path": "/", "pool": "default"},
+		"eth0": {"type": "nic", "network": "incusbr0"},
+	}, profile.Devices)
+
+	// Volatile and image keys are never copied, but the instance specific device options can be kept.
+	profile = profileFromInstance(inst, true)
+	assert.Equal(t, map[string]string{"limits.cpu": "2", "user.foo": "bar"}, profile.Config)
+	assert.Equal(t, inst.ExpandedDevices["eth0"], profile.Devices["eth0"])
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"runtime"
 	"slices"
 	"sort"
@@ -83,6 +84,10 @@ func (c *cmdRemote) Command() *cobra.Command {
 	remoteSetURLCmd := cmdRemoteSetURL{global: c.global, remote: c}
 	cmd.AddCommand(remoteSetURLCmd.Command())
 
+	// Set proxy
+	remoteSetProxyCmd := cmdRemoteSetProxy{global: c.global, remote: c}
+	cmd.AddCommand(remoteSetProxyCmd.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
@@ -100,6 +105,8 @@ type cmdRemoteAdd struct {
 	flagProtocol   string
 	flagAuthType   string
 	flagProject    string
+	flagProxy      string
+	flagCACert     string
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -123,6 +130,8 @@ Basic authentication can be used when combined with the "simplestreams" protocol
 	cmd.Flags().StringVar(&c.flagAuthType, "auth-type", "", i18n.G("Server authentication type (tls or oidc)")+"``")
 	cmd.Flags().BoolVar(&c.flagPublic, "public", false, i18n.G("Public image server"))
 	cmd.Flags().StringVar(&c.flagProject, "project", "", i18n.G("Project to use for the remote")+"``")
+	cmd.Flags().StringVar(&c.flagProxy, "proxy", "", i18n.G("Proxy to use for the remote (http, socks5 or socks5h URL)")+"``")
+	cmd.Flags().StringVar(&c.flagCACert, "ca-cert", "", i18n.G("CA certificate bundle to validate the remote certificate against")+"``")
 
 	return cmd
 }
@@ -224,11 +233,16 @@ func (c *cmdRemoteAdd) addRemoteFromToken(addr string, server string, token stri
 	var certificate *x509.Certificate
 	var err error
 
-	conf.Remotes[server] = config.Remote{Addr: addr, Protocol: c.flagProtocol, AuthType: c.flagAuthType}
+	conf.Remotes[server] = config.Remote{Addr: addr, Protocol: c.flagProtocol, AuthType: c.flagAuthType, Proxy: c.flagProxy}
+
+	proxyFunc, err := conf.Remotes[server].ProxyFunc()
+	if err != nil {
+		return err
+	}
 
 	_, err = conf.GetInstanceServer(server)
 	if err != nil {
-		certificate, err = localtls.GetRemoteCertificate(addr, c.global.conf.UserAgent)
+		certificate, err = localtls.GetRemoteCertificateWithProxy(addr, c.global.conf.UserAgent, proxyFunc)
 		if err != nil {
 			return api.StatusErrorf(http.StatusServiceUnavailable, i18n.G("Unavailable remote server")+": %v", err)
 		}
@@ -317,6 +331,29 @@ func (c *cmdRemoteAdd) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Remote %s exists as <%s>"), server, remote.Addr)
 	}
 
+	// Validate the proxy.
+	if c.flagProxy != "" {
+		_, err := config.ParseProxy(c.flagProxy)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Validate the CA certificate.
+	if c.flagCACert != "" {
+		caCert, err := filepath.Abs(c.flagCACert)
+		if err != nil {
+			return err
+		}
+
+		_, err = config.LoadCACert(caCert)
+		if err != nil {
+			return err
+		}
+
+		c.flagCACert = caCert
+	}
+
 	// Parse the URL
 	var rScheme string
 	var rHost string
@@ -348,7 +385,7 @@ func (c *cmdRemoteAdd) Run(cmd *cobra.Command, args []string) error {
 			return errors.New(i18n.G("Only https URLs are supported for oci and simplestreams"))
 		}
 
-		conf.Remotes[server] = config.Remote{Addr: addr, Public: true, Protocol: c.flagProtocol}
+		conf.Remotes[server] = config.Remote{Addr: addr, Public: true, Protocol: c.flagProtocol, Proxy: c.flagProxy, CACert: c.flagCACert}
 		return conf.SaveConfig(c.global.confPath)
 	} else if c.flagProtocol != "incus" {
 		return fmt.Errorf(i18n.G("Invalid protocol: %s"), c.flagProtocol)
@@ -418,7 +455,18 @@ func (c *cmdRemoteAdd) Run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	conf.Remotes[server] = config.Remote{Addr: addr, Protocol: c.flagProtocol, AuthType: c.flagAuthType}
+	remote = config.Remote{Addr: addr, Protocol: c.flagProtocol, AuthType: c.flagAuthType}
+	if rScheme != "unix" {
+		remote.Proxy = c.flagProxy
+		remote.CACert = c.flagCACert
+	}
+
+	conf.Remotes[server] = remote
+
+	proxyFunc, err := remote.ProxyFunc()
+	if err != nil {
+		return err
+	}
 
 	// Attempt to connect
 	var d incus.ImageServer
@@ -449,11 +497,16 @@ func (c *cmdRemoteAdd) Run(cmd *cobra.Command, args []string) error {
 		return conf.SaveConfig(c.global.confPath)
 	}
 
+	// A remote using its own CA must validate against it.
+	if err != nil && remote.CACert != "" {
+		return err
+	}
+
 	// Check if the system CA worked for the TLS connection
 	var certificate *x509.Certificate
 	if err != nil {
 		// Failed to connect using the system CA, so retrieve the remote certificate
-		certificate, err = localtls.GetRemoteCertificate(addr, c.global.conf.UserAgent)
+		certificate, err = localtls.GetRemoteCertificateWithProxy(addr, c.global.conf.UserAgent, proxyFunc)
 		if err != nil {
 			return err
 		}
@@ -517,7 +570,7 @@ func (c *cmdRemoteAdd) Run(cmd *cobra.Command, args []string) error {
 
 	// Handle public remotes
 	if c.flagPublic {
-		conf.Remotes[server] = config.Remote{Addr: addr, Public: true}
+		conf.Remotes[server] = config.Remote{Addr: addr, Public: true, CACert: remote.CACert}
 		return conf.SaveConfig(c.global.confPath)
 	}
 
@@ -1145,3 +1198,84 @@ func (c *cmdRemoteSetURL) Run(cmd *cobra.Command, args []string) error {
 
 	return conf.SaveConfig(c.global.confPath)
 }
+
+// Set proxy.
+type cmdRemoteSetProxy struct {
+	global *cmdGlobal
+	remote *cmdRemote
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdRemoteSetProxy) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set-proxy", i18n.G("<remote> [<proxy URL>]"))
+	cmd.Short = i18n.G("Set the proxy for the remote")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Set the proxy for the remote
+
+Supported proxies are HTTP CONNECT (http://) and SOCKS5 (socks5:// or socks5h://).
+Credentials can be included in the URL.
+
+Omitting the proxy URL clears it, reverting to the proxy from the environment.`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemoteNames()
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run is used in the RunE field of the cobra.Command returned by Command.
+func (c *cmdRemoteSetProxy) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Set the proxy
+	remote, ok := conf.Remotes[args[0]]
+	if !ok {
+		return fmt.Errorf(i18n.G("Remote %s doesn't exist"), args[0])
+	}
+
+	if remote.Static {
+		return fmt.Errorf(i18n.G("Remote %s is static and cannot be modified"), args[0])
+	}
+
+	if strings.HasPrefix(remote.Addr, "unix:") {
+		return fmt.Errorf(i18n.G("Remote %s is a local socket and cannot use a proxy"), args[0])
+	}
+
+	proxy := ""
+	if len(args) > 1 {
+		proxy = args[1]
+
+		_, err := config.ParseProxy(proxy)
+		if err != nil {
+			return err
+		}
+	}
+
+	if remote.Global {
+		err := conf.CopyGlobalCert(args[0], args[0])
+		if err != nil {
+			return err
+		}
+
+		remote.Global = false
+	}
+
+	remote.Proxy = proxy
+	conf.Remotes[args[0]] = remote
+
+	return conf.SaveConfig(c.global.confPath)
+}
@@ -63,6 +63,14 @@ func (c *cmdStorage) Command() *cobra.Command {
 	storageListCmd := cmdStorageList{global: c.global, storage: c}
 	cmd.AddCommand(storageListCmd.Command())
 
+	// Migrate
+	storageMigrateCmd := cmdStorageMigrate{global: c.global, storage: c}
+	cmd.AddCommand(storageMigrateCmd.Command())
+
+	// Scrub
+	storageScrubCmd := cmdStorageScrub{global: c.global, storage: c}
+	cmd.AddCommand(storageScrubCmd.Command())
+
 	// Set
 	storageSetCmd := cmdStorageSet{global: c.global, storage: c}
 	cmd.AddCommand(storageSetCmd.Command())
@@ -651,6 +659,24 @@ func (c *cmdStorageInfo) Run(cmd *cobra.Command, args []string) error {
 		poolinfo[infostring][spaceusedstring] = units.GetByteSizeStringIEC(int64(res.Space.Used), 2)
 	}
 
+	if res.Scrub != nil {
+		poolinfo[infostring][i18n.G("scrub status")] = res.Scrub.Status
+
+		if !res.Scrub.StartedAt.IsZero() {
+			poolinfo[infostring][i18n.G("scrub started")] = res.Scrub.StartedAt.Local().Format(dateLayout)
+		}
+
+		if res.Scrub.Status == api.StoragePoolScrubStatusRunning {
+			poolinfo[infostring][i18n.G("scrub progress")] = fmt.Sprintf("%.2f%%", res.Scrub.Progress)
+		} else if !res.Scrub.FinishedAt.IsZero() {
+			poolinfo[infostring][i18n.G("scrub finished")] = res.Scrub.FinishedAt.Local().Format(dateLayout)
+		}
+
+		if res.Scrub.Status != api.StoragePoolScrubStatusNone {
+			poolinfo[infostring][i18n.G("scrub errors")] = strconv.FormatUint(res.Scrub.Errors, 10)
+		}
+	}
+
 	poolinfodata, err := yaml.Marshal(poolinfo)
 	if err != nil {
 		return err
@@ -836,6 +862,230 @@ func (c *cmdStorageList) Run(cmd *cobra.Command, args []string) error {
 	return cli.RenderTable(os.Stdout, c.flagFormat, header, data, pools)
 }
 
+// Migrate.
+type cmdStorageMigrate struct {
+	global  *cmdGlobal
+	storage *cmdStorage
+
+	flagStop         bool
+	flagDeleteSource bool
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdStorageMigrate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("migrate", i18n.G("[<remote>:]<pool> <target pool>"))
+	cmd.Short = i18n.G("Move all volumes of a storage pool to another pool")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Move all volumes of a storage pool to another pool
+
+This moves the instance and custom volumes of the pool, along with their snapshots,
+to the target pool and updates the instances and profiles using them.
+
+Running instances using the volumes must either be stopped beforehand or the --stop flag
+be passed to have them stopped and started again once moved.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus storage migrate old-pool new-pool
+    Move all volumes from "old-pool" to "new-pool".
+
+incus storage migrate old-pool new-pool --stop --delete-source
+    Move all volumes, stopping the running instances using them, then delete "old-pool".`))
+
+	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().BoolVar(&c.flagStop, "stop", false, i18n.G("Stop the running instances using the volumes and start them again once moved"))
+	cmd.Flags().BoolVar(&c.flagDeleteSource, "delete-source", false, i18n.G("Delete the source storage pool once all volumes are moved"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) < 2 {
+			return c.global.cmpStoragePools(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdStorageMigrate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing pool name"))
+	}
+
+	// Targeting
+	if c.storage.flagTarget != "" {
+		if !resource.server.IsClustered() {
+			return errors.New(i18n.G("To use --target, the destination remote must be a cluster"))
+		}
+
+		resource.server = resource.server.UseTarget(c.storage.flagTarget)
+	}
+
+	req := api.StoragePoolMigratePost{
+		Pool:         args[1],
+		Stop:         c.flagStop,
+		DeleteSource: c.flagDeleteSource,
+	}
+
+	op, err := resource.server.MigrateStoragePool(resource.name, req)
+	if err != nil {
+		return err
+	}
+
+	// Watch the migration progress.
+	progress := cli.ProgressRenderer{
+		Quiet: c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(func(op api.Operation) {
+		// Show the progress of the current volume copy along with the overall progress.
+		message, _ := op.Metadata["migrate_progress"].(string)
+		for key, value := range op.Metadata {
+			if key != "migrate_progress" && strings.HasSuffix(key, "_progress") {
+				message = fmt.Sprintf("%s: %v", message, value)
+				break
+			}
+		}
+
+		if message != "" {
+			progress.Update(message)
+		}
+	})
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage pool %s migrated to %s")+"\n", resource.name, args[1])
+	}
+
+	return nil
+}
+
+// Scrub.
+type cmdStorageScrub struct {
+	global  *cmdGlobal
+	storage *cmdStorage
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdStorageScrub) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("scrub", i18n.G("[<remote>:]<pool>"))
+	cmd.Short = i18n.G("Check the data integrity of storage pools")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Check the data integrity of storage pools
+
+This runs a scrub of the pool (zfs and btrfs only) and waits for it to complete.
+The result of the last scrub is shown by "incus storage info".
+
+For zfs, the whole zpool is scrubbed, even when the pool only uses a dataset of it.`))
+
+	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpStoragePools(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdStorageScrub) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing pool name"))
+	}
+
+	// Targeting
+	if c.storage.flagTarget != "" {
+		if !resource.server.IsClustered() {
+			return errors.New(i18n.G("To use --target, the destination remote must be a cluster"))
+		}
+
+		resource.server = resource.server.UseTarget(c.storage.flagTarget)
+	}
+
+	op, err := resource.server.ScrubStoragePool(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Watch the scrub progress.
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Scrubbing: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	// Report the result.
+	res, err := resource.server.GetStoragePoolResources(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet && res.Scrub != nil {
+		fmt.Printf(i18n.G("Scrub of storage pool %s %s with %d errors")+"\n", resource.name, res.Scrub.Status, res.Scrub.Errors)
+	}
+
+	return nil
+}
+
 // Set.
 type cmdStorageSet struct {
 	global  *cmdGlobal
@@ -21,10 +21,13 @@ import (
 	cli "github.com/lxc/incus/v6/internal/cmd"
 	"github.com/lxc/incus/v6/internal/i18n"
 	"github.com/lxc/incus/v6/internal/instance"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/archive"
 	"github.com/lxc/incus/v6/shared/ioprogress"
 	"github.com/lxc/incus/v6/shared/termios"
 	"github.com/lxc/incus/v6/shared/units"
+	"github.com/lxc/incus/v6/shared/util"
 )
 
 type volumeColumn struct {
@@ -72,6 +75,10 @@ Unless specified through a prefix, all volume operations affect "custom" (user c
 	storageVolumeCopyCmd := cmdStorageVolumeCopy{global: c.global, storage: c.storage, storageVolume: c}
 	cmd.AddCommand(storageVolumeCopyCmd.Command())
 
+	// Convert
+	storageVolumeConvertCmd := cmdStorageVolumeConvert{global: c.global, storage: c.storage, storageVolume: c}
+	cmd.AddCommand(storageVolumeConvertCmd.Command())
+
 	// Create
 	storageVolumeCreateCmd := cmdStorageVolumeCreate{global: c.global, storage: c.storage, storageVolume: c}
 	cmd.AddCommand(storageVolumeCreateCmd.Command())
@@ -572,6 +579,116 @@ func (c *cmdStorageVolumeCopy) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Convert.
+type cmdStorageVolumeConvert struct {
+	global        *cmdGlobal
+	storage       *cmdStorage
+	storageVolume *cmdStorageVolume
+
+	flagContentType    string
+	flagAllowHostMount bool
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdStorageVolumeConvert) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("convert", i18n.G("[<remote>:]<pool> <volume> --content-type=block|filesystem"))
+	cmd.Short = i18n.G("Convert custom storage volumes to a different content type")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Convert custom storage volumes to a different content type
+
+Converting to a block volume creates a filesystem on it holding the files of the volume.
+Converting to a filesystem volume copies out the files of the filesystem found on the block volume.
+As this mounts a filesystem which may have been written by an instance on the host, it is restricted
+to server administrators and must be confirmed with --allow-host-mount.
+
+The volume must not be attached to any instance or profile and must not have snapshots.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus storage volume convert default foo --content-type=block
+    Convert the filesystem volume "foo" in pool "default" to a block volume
+
+incus storage volume convert default bar --content-type=filesystem --allow-host-mount
+    Convert the block volume "bar" in pool "default" to a filesystem volume`))
+
+	cmd.Flags().StringVar(&c.flagContentType, "content-type", "", i18n.G("Content type to convert to, block or filesystem")+"``")
+	cmd.Flags().BoolVar(&c.flagAllowHostMount, "allow-host-mount", false, i18n.G("Allow mounting the block volume's filesystem on the host when converting to a filesystem volume"))
+	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpStoragePools(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpStoragePoolVolumes(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdStorageVolumeConvert) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	if c.flagContentType != "block" && c.flagContentType != "filesystem" {
+		return errors.New(i18n.G("--content-type must be either block or filesystem"))
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing pool name"))
+	}
+
+	client := resource.server
+
+	// Parse the input
+	volName, volType := parseVolume("custom", args[1])
+	if volType != "custom" {
+		return errors.New(i18n.G("Only custom volumes can be converted"))
+	}
+
+	// If a target member was specified, get the volume with the matching
+	// name on that member, if any.
+	if c.storage.flagTarget != "" {
+		client = client.UseTarget(c.storage.flagTarget)
+	}
+
+	req := api.StorageVolumePost{
+		ContentType:    c.flagContentType,
+		AllowHostMount: c.flagAllowHostMount,
+	}
+
+	op, err := client.ConvertStoragePoolVolume(resource.name, volType, volName, req)
+	if err != nil {
+		return err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage volume %s converted to %s")+"\n", volName, c.flagContentType)
+	}
+
+	return nil
+}
+
 // Create.
 type cmdStorageVolumeCreate struct {
 	global          *cmdGlobal
@@ -1475,6 +1592,31 @@ func (c *cmdStorageVolumeInfo) Run(cmd *cobra.Command, args []string) error {
 		fmt.Printf(i18n.G("Created: %s")+"\n", vol.CreatedAt.Local().Format(dateLayout))
 	}
 
+	// Show where the defaulted config came from.
+	configSources := util.SplitNTrimSpace(vol.Config["volatile.config_source"], ",", -1, true)
+	if len(configSources) > 0 {
+		fmt.Println("\n" + i18n.G("Defaulted configuration:"))
+		for _, entry := range configSources {
+			key, source, _ := strings.Cut(entry, "=")
+			fmt.Printf("  %s: %s ("+i18n.G("from %s")+")\n", key, vol.Config[key], source)
+		}
+	}
+
+	// Show the instances, snapshots and profiles referencing the volume.
+	if len(vol.UsedBy) > 0 {
+		fmt.Println("\n" + i18n.G("Used by:"))
+
+		usedBy := make([]string, 0, len(vol.UsedBy))
+		for _, entry := range vol.UsedBy {
+			usedBy = append(usedBy, internalUtil.UsedByDescription(entry))
+		}
+
+		sort.Strings(usedBy)
+		for _, entry := range usedBy {
+			fmt.Printf("  - %s\n", entry)
+		}
+	}
+
 	// List snapshots
 	firstSnapshot := true
 	if len(volSnapshots) > 0 {
@@ -2884,6 +3026,8 @@ type cmdStorageVolumeSnapshotRestore struct {
 	storage               *cmdStorage
 	storageVolume         *cmdStorageVolume
 	storageVolumeSnapshot *cmdStorageVolumeSnapshot
+
+	flagTargetNew string
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -2892,8 +3036,20 @@ func (c *cmdStorageVolumeSnapshotRestore) Command() *cobra.Command {
 	cmd.Use = usage("restore", i18n.G("[<remote>:]<pool> <volume> <snapshot>"))
 	cmd.Short = i18n.G("Restore storage volume snapshots")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Restore storage volume snapshots`))
+		`Restore storage volume snapshots
+
+By default the volume is rolled back to the snapshot. With --target-new, the
+snapshot is instead restored into a new volume, leaving the existing volume
+untouched.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus storage volume snapshot restore default data snap0
+    Roll back custom storage volume "data" in pool "default" to its "snap0" snapshot
+
+incus storage volume snapshot restore default data snap0 --target-new data-snap0
+    Restore snapshot "snap0" of custom storage volume "data" into the new "data-snap0" volume`))
+
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVar(&c.flagTargetNew, "target-new", "", i18n.G("Restore into a new volume with this name instead of rolling back")+"``")
 
 	cmd.RunE = c.Run
 
@@ -2943,11 +3099,15 @@ func (c *cmdStorageVolumeSnapshotRestore) Run(cmd *cobra.Command, args []string)
 	}
 
 	// Check if the requested storage volume actually exists
-	_, _, err = client.GetStoragePoolVolume(resource.name, "custom", args[1])
+	vol, _, err := client.GetStoragePoolVolume(resource.name, "custom", args[1])
 	if err != nil {
 		return err
 	}
 
+	if c.flagTargetNew != "" {
+		return c.restoreNew(client, resource.name, vol, args[2])
+	}
+
 	req := api.StorageVolumePut{
 		Restore: args[2],
 	}
@@ -2960,6 +3120,68 @@ func (c *cmdStorageVolumeSnapshotRestore) Run(cmd *cobra.Command, args []string)
 	return client.UpdateStoragePoolVolume(resource.name, "custom", args[1], req, etag)
 }
 
+// restoreNew creates a new volume from the snapshot, leaving the snapshot's volume untouched.
+func (c *cmdStorageVolumeSnapshotRestore) restoreNew(client incus.InstanceServer, pool string, vol *api.StorageVolume, snapName string) error {
+	snapshot, _, err := client.GetStoragePoolVolumeSnapshot(pool, "custom", vol.Name, snapName)
+	if err != nil {
+		return err
+	}
+
+	// Check that the new volume name is free.
+	_, _, err = client.GetStoragePoolVolume(pool, "custom", c.flagTargetNew)
+	if err == nil {
+		return fmt.Errorf(i18n.G("Storage volume %q already exists"), c.flagTargetNew)
+	} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return err
+	}
+
+	if snapshot.ContentType != vol.ContentType {
+		return fmt.Errorf(i18n.G("Snapshot content type %q doesn't match the volume content type %q"), snapshot.ContentType, vol.ContentType)
+	}
+
+	// Copy the snapshot (block or filesystem) into the new volume, using the snapshot's own config.
+	volName := vol.Name
+	vol.Name = volName + "/" + snapName
+	vol.Config = snapshot.Config
+	vol.Description = snapshot.Description
+
+	args := &incus.StoragePoolVolumeCopyArgs{
+		Name: c.flagTargetNew,
+	}
+
+	op, err := client.CopyStoragePoolVolume(pool, client, pool, *vol, args)
+	if err != nil {
+		return err
+	}
+
+	// Register progress handler
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Restoring snapshot: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	// Wait for operation to finish
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Snapshot %s/%s restored into new storage volume %s")+"\n", volName, snapName, c.flagTargetNew)
+	}
+
+	return nil
+}
+
 // Snapshot show.
 type cmdStorageVolumeSnapshotShow struct {
 	global                *cmdGlobal
@@ -3055,6 +3277,8 @@ type cmdStorageVolumeExport struct {
 	flagVolumeOnly           bool
 	flagOptimizedStorage     bool
 	flagCompressionAlgorithm string
+	flagEncrypt              bool
+	flagPassphraseFile       string
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -3069,6 +3293,8 @@ func (c *cmdStorageVolumeExport) Command() *cobra.Command {
 	cmd.Flags().BoolVar(&c.flagOptimizedStorage, "optimized-storage", false,
 		i18n.G("Use storage driver optimized format (can only be restored on a similar pool)"))
 	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", i18n.G("Define a compression algorithm: for backup or none")+"``")
+	cmd.Flags().BoolVar(&c.flagEncrypt, "encrypt", false, i18n.G("Encrypt the backup with a passphrase"))
+	cmd.Flags().StringVar(&c.flagPassphraseFile, "passphrase-file", "", i18n.G("File to read the encryption passphrase from (implies --encrypt)")+"``")
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.RunE = c.Run
 
@@ -3120,12 +3346,27 @@ func (c *cmdStorageVolumeExport) Run(cmd *cobra.Command, args []string) error {
 		return errors.New(i18n.G("Only \"custom\" volumes can be exported"))
 	}
 
+	// Get and validate the passphrase before starting the backup.
+	var passphrase string
+	if c.flagEncrypt || c.flagPassphraseFile != "" {
+		passphrase, err = getBackupPassphrase(&c.global.asker, c.flagPassphraseFile, true)
+		if err != nil {
+			return err
+		}
+
+		err = archive.ValidateEncryptionPassphrase(passphrase)
+		if err != nil {
+			return err
+		}
+	}
+
 	req := api.StorageVolumeBackupsPost{
 		Name:                 "",
 		ExpiresAt:            time.Now().Add(24 * time.Hour),
 		VolumeOnly:           volumeOnly,
 		OptimizedStorage:     c.flagOptimizedStorage,
 		CompressionAlgorithm: c.flagCompressionAlgorithm,
+		EncryptionPassphrase: passphrase,
 	}
 
 	op, err := d.CreateStorageVolumeBackup(name, volName, req)
@@ -3222,7 +3463,8 @@ type cmdStorageVolumeImport struct {
 	storage       *cmdStorage
 	storageVolume *cmdStorageVolume
 
-	flagType string
+	flagType           string
+	flagPassphraseFile string
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -3240,6 +3482,7 @@ incus storage volume import default some-installer.iso installer --type=iso
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.RunE = c.Run
 	cmd.Flags().StringVar(&c.flagType, "type", "", i18n.G("Import type, backup or iso (default \"backup\")")+"``")
+	cmd.Flags().StringVar(&c.flagPassphraseFile, "passphrase-file", "", i18n.G("File to read the passphrase of an encrypted backup from")+"``")
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
@@ -3313,6 +3556,27 @@ func (c *cmdStorageVolumeImport) Run(cmd *cobra.Command, args []string) error {
 		return errors.New(i18n.G("Importing ISO images requires a volume name to be set"))
 	}
 
+	// Get the passphrase of encrypted backups.
+	var passphrase string
+	if c.flagPassphraseFile != "" {
+		passphrase, err = getBackupPassphrase(&c.global.asker, c.flagPassphraseFile, false)
+		if err != nil {
+			return err
+		}
+	} else if c.flagType == "backup" {
+		encrypted, err := isEncryptedBackup(file)
+		if err != nil {
+			return err
+		}
+
+		if encrypted {
+			passphrase, err = getBackupPassphrase(&c.global.asker, "", false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	progress := cli.ProgressRenderer{
 		Format: i18n.G("Importing custom volume: %s"),
 		Quiet:  c.global.flagQuiet,
@@ -3328,7 +3592,8 @@ func (c *cmdStorageVolumeImport) Run(cmd *cobra.Command, args []string) error {
 				},
 			},
 		},
-		Name: volName,
+		Name:                 volName,
+		EncryptionPassphrase: passphrase,
 	}
 
 	var op incus.Operation
@@ -14,6 +14,8 @@ import (
 	"github.com/lxc/incus/v6/internal/i18n"
 	"github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/archive"
+	"github.com/lxc/incus/v6/shared/ask"
 	config "github.com/lxc/incus/v6/shared/cliconfig"
 	"github.com/lxc/incus/v6/shared/revert"
 	"github.com/lxc/incus/v6/shared/termios"
@@ -597,3 +599,43 @@ func removeElementsFromSlice[T comparable](list []T, elements ...T) []T {
 
 	return list
 }
+
+// getBackupPassphrase returns the passphrase to encrypt or decrypt a backup with.
+// It's read from passphraseFile if set, otherwise it's asked for (twice if confirm is set).
+func getBackupPassphrase(asker *ask.Asker, passphraseFile string, confirm bool) (string, error) {
+	if passphraseFile != "" {
+		content, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return "", fmt.Errorf(i18n.G("Failed reading passphrase file: %w"), err)
+		}
+
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	if !termios.IsTerminal(getStdinFd()) {
+		return "", errors.New(i18n.G("A passphrase file must be provided when not running interactively"))
+	}
+
+	if confirm {
+		return asker.AskPassword(i18n.G("Backup passphrase: ")), nil
+	}
+
+	return asker.AskPasswordOnce(i18n.G("Backup passphrase: ")), nil
+}
+
+// isEncryptedBackup returns whether the backup file is encrypted, leaving the file at its start.
+func isEncryptedBackup(file *os.File) (bool, error) {
+	header := make([]byte, len(archive.EncryptionMagic))
+
+	_, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, err
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return false, err
+	}
+
+	return archive.IsEncrypted(header), nil
+}
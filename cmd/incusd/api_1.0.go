@@ -45,6 +45,7 @@ var api10 = []APIEndpoint{
 	certificateCmd,
 	certificatesCmd,
 	clusterCmd,
+	clusterDatabaseCmd,
 	clusterGroupCmd,
 	clusterGroupsCmd,
 	clusterNodeCmd,
@@ -60,6 +61,7 @@ var api10 = []APIEndpoint{
 	instanceFileCmd,
 	instanceExecOutputCmd,
 	instanceExecOutputsCmd,
+	instanceLabelsCmd,
 	instanceLogCmd,
 	instanceLogsCmd,
 	instanceMetadataCmd,
@@ -75,6 +77,7 @@ var api10 = []APIEndpoint{
 	eventsCmd,
 	imageAliasCmd,
 	imageAliasesCmd,
+	imageBuildCmd,
 	imageCmd,
 	imageExportCmd,
 	imageRefreshCmd,
@@ -83,6 +86,8 @@ var api10 = []APIEndpoint{
 	metadataConfigurationCmd,
 	networkCmd,
 	networkLeasesCmd,
+	networkLeaseCmd,
+	networkTraceCmd,
 	networksCmd,
 	networkStateCmd,
 	networkACLCmd,
@@ -91,6 +96,8 @@ var api10 = []APIEndpoint{
 	networkAddressSetCmd,
 	networkAddressSetsCmd,
 	networkAllocationsCmd,
+	networkDHCPReservationCmd,
+	networkDHCPReservationsCmd,
 	networkForwardCmd,
 	networkForwardsCmd,
 	networkIntegrationCmd,
@@ -109,6 +116,7 @@ var api10 = []APIEndpoint{
 	operationWait,
 	operationWebsocket,
 	profileCmd,
+	profileAssignCmd,
 	profilesCmd,
 	projectCmd,
 	projectsCmd,
@@ -116,6 +124,8 @@ var api10 = []APIEndpoint{
 	projectAccessCmd,
 	storagePoolCmd,
 	storagePoolResourcesCmd,
+	storagePoolScrubCmd,
+	storagePoolMigrateCmd,
 	storagePoolsCmd,
 	storagePoolBucketsCmd,
 	storagePoolBucketCmd,
@@ -136,6 +146,7 @@ var api10 = []APIEndpoint{
 	warningsCmd,
 	warningCmd,
 	metricsCmd,
+	validateCmd,
 }
 
 // swagger:operation GET /1.0?public server server_get_untrusted
@@ -819,12 +830,18 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		case "core.bgp_asn":
 			bgpChanged = true
 
+		case "core.event_history_size":
+			s.Events.SetHistorySize(int(clusterConfig.EventHistorySize()))
+
 		case "core.https_trusted_proxy":
 			s.Endpoints.NetworkUpdateTrustedProxy(clusterChanged[key])
 
 		case "core.proxy_http", "core.proxy_https", "core.proxy_ignore_hosts":
 			daemonConfigSetProxy(d, clusterConfig)
 
+		case "core.rate_limit.burst", "core.rate_limit.exempt", "core.rate_limit.project_burst", "core.rate_limit.project_requests", "core.rate_limit.requests":
+			d.rateLimiter.configure(clusterConfig)
+
 		case "images.auto_update_interval", "images.remote_cache_expiry":
 			if !s.OS.MockMode {
 				d.taskPruneImages.Reset()
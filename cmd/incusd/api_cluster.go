@@ -72,6 +72,12 @@ var clusterNodeCmd = APIEndpoint{
 	Post:   APIEndpointAction{Handler: clusterNodePost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
 }
 
+var clusterDatabaseCmd = APIEndpoint{
+	Path: "cluster/database",
+
+	Get: APIEndpointAction{Handler: clusterDatabaseGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
+}
+
 var clusterNodeStateCmd = APIEndpoint{
 	Path: "cluster/members/{name}/state",
 
@@ -1236,6 +1242,118 @@ func clusterNodesGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, urls)
 }
 
+// swagger:operation GET /1.0/cluster/database cluster cluster_database_get
+//
+//	Get the cluster database state
+//
+//	Gets the raft role of each database member, the current leader and the quorum requirements.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Cluster database state
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ClusterDatabase"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterDatabaseGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	leaderAddress, err := s.Cluster.LeaderAddress()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	var raftNodes []db.RaftNode
+	err = s.DB.Node.Transaction(r.Context(), func(ctx context.Context, tx *db.NodeTx) error {
+		raftNodes, err = tx.GetRaftNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed loading RAFT nodes: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var members []db.NodeInfo
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		members, err = tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	membersByAddress := make(map[string]db.NodeInfo, len(members))
+	for _, member := range members {
+		membersByAddress[member.Address] = member
+	}
+
+	info := api.ClusterDatabase{
+		Members:    make([]api.ClusterDatabaseMember, 0, len(raftNodes)),
+		MaxVoters:  s.GlobalConfig.MaxVoters(),
+		MaxStandBy: s.GlobalConfig.MaxStandBy(),
+	}
+
+	voters := 0
+	for _, raftNode := range raftNodes {
+		dbMember := api.ClusterDatabaseMember{
+			ID:      raftNode.ID,
+			Address: raftNode.Address,
+			Role:    raftNode.Role.String(),
+			Leader:  raftNode.Address == leaderAddress,
+		}
+
+		member, ok := membersByAddress[raftNode.Address]
+		if ok {
+			dbMember.ServerName = member.Name
+			dbMember.Online = !member.IsOffline(s.GlobalConfig.OfflineThreshold())
+		}
+
+		if raftNode.Role == db.RaftVoter {
+			voters++
+		}
+
+		info.Members = append(info.Members, dbMember)
+	}
+
+	info.Quorum = voters/2 + 1
+
+	return response.SyncResponse(true, info)
+}
+
 var clusterNodesPostMu sync.Mutex // Used to prevent races when creating cluster join tokens.
 
 // swagger:operation POST /1.0/cluster/members cluster cluster_members_post
@@ -1526,7 +1644,7 @@ func clusterNodeGet(d *Daemon, r *http.Request) response.Response {
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func clusterNodePatch(d *Daemon, r *http.Request) response.Response {
-	return updateClusterNode(d.State(), d.gateway, r, true)
+	return updateClusterNode(d, r, true)
 }
 
 // swagger:operation PUT /1.0/cluster/members/{name} cluster cluster_member_put
@@ -1559,11 +1677,13 @@ func clusterNodePatch(d *Daemon, r *http.Request) response.Response {
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func clusterNodePut(d *Daemon, r *http.Request) response.Response {
-	return updateClusterNode(d.State(), d.gateway, r, false)
+	return updateClusterNode(d, r, false)
 }
 
 // updateClusterNode is shared between clusterNodePut and clusterNodePatch.
-func updateClusterNode(s *state.State, gateway *cluster.Gateway, r *http.Request, isPatch bool) response.Response {
+func updateClusterNode(d *Daemon, r *http.Request, isPatch bool) response.Response {
+	s := d.State()
+
 	name, err := url.PathUnescape(mux.Vars(r)["name"])
 	if err != nil {
 		return response.SmartError(err)
@@ -1644,12 +1764,10 @@ func updateClusterNode(s *state.State, gateway *cluster.Gateway, r *http.Request
 	}
 
 	// Validate the request
-	if slices.Contains(memberInfo.Roles, string(db.ClusterRoleDatabase)) && !slices.Contains(req.Roles, string(db.ClusterRoleDatabase)) {
-		return response.BadRequest(fmt.Errorf("The %q role cannot be dropped at this time", db.ClusterRoleDatabase))
-	}
-
-	if !slices.Contains(memberInfo.Roles, string(db.ClusterRoleDatabase)) && slices.Contains(req.Roles, string(db.ClusterRoleDatabase)) {
-		return response.BadRequest(fmt.Errorf("The %q role cannot be added at this time", db.ClusterRoleDatabase))
+	currentDatabaseRole := clusterMemberDatabaseRole(memberInfo.Roles)
+	newDatabaseRole, err := clusterMemberRequestedDatabaseRole(memberInfo.Roles, req.Roles)
+	if err != nil {
+		return response.BadRequest(err)
 	}
 
 	// Nodes must belong to at least one group.
@@ -1657,6 +1775,28 @@ func updateClusterNode(s *state.State, gateway *cluster.Gateway, r *http.Request
 		return response.BadRequest(fmt.Errorf("Cluster members need to belong to at least one group"))
 	}
 
+	// Database role changes must be coordinated by the leader.
+	if newDatabaseRole != currentDatabaseRole {
+		if s.LocalConfig.ClusterAddress() != leaderAddress {
+			client, err := cluster.Connect(leaderAddress, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			_, _, err = client.RawQuery(r.Method, api.NewURL().Path("cluster", "members", name).String(), req, "")
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			return response.EmptySyncResponse
+		}
+
+		err = changeClusterMemberDatabaseRole(d, r, member.Address, newDatabaseRole)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
 	// Convert the roles.
 	newRoles := make([]db.ClusterRole, 0, len(req.Roles))
 	for _, role := range req.Roles {
@@ -1728,7 +1868,7 @@ func updateClusterNode(s *state.State, gateway *cluster.Gateway, r *http.Request
 
 	// If cluster roles changed, then distribute the info to all members.
 	if s.Endpoints != nil && clusterRolesChanged(member.Roles, newRoles) {
-		cluster.NotifyHeartbeat(s, gateway)
+		cluster.NotifyHeartbeat(s, d.gateway)
 	}
 
 	requestor := request.CreateRequestor(r)
@@ -1737,6 +1877,76 @@ func updateClusterNode(s *state.State, gateway *cluster.Gateway, r *http.Request
 	return response.EmptySyncResponse
 }
 
+// clusterMemberDatabaseRole returns the database role matching the given cluster member roles.
+func clusterMemberDatabaseRole(roles []string) db.RaftRole {
+	if slices.Contains(roles, string(db.ClusterRoleDatabase)) {
+		return db.RaftVoter
+	}
+
+	if slices.Contains(roles, string(db.ClusterRoleDatabaseStandBy)) {
+		return db.RaftStandBy
+	}
+
+	return db.RaftSpare
+}
+
+// clusterMemberRequestedDatabaseRole returns the database role requested by changing the cluster member roles
+// from oldRoles to newRoles. Adding a database role requests it, dropping the current one requests a spare.
+func clusterMemberRequestedDatabaseRole(oldRoles []string, newRoles []string) (db.RaftRole, error) {
+	added := func(role db.ClusterRole) bool {
+		return !slices.Contains(oldRoles, string(role)) && slices.Contains(newRoles, string(role))
+	}
+
+	if added(db.ClusterRoleDatabase) && added(db.ClusterRoleDatabaseStandBy) {
+		return -1, fmt.Errorf("The %q and %q roles cannot be added together", db.ClusterRoleDatabase, db.ClusterRoleDatabaseStandBy)
+	}
+
+	if added(db.ClusterRoleDatabase) {
+		return db.RaftVoter, nil
+	}
+
+	if added(db.ClusterRoleDatabaseStandBy) {
+		return db.RaftStandBy, nil
+	}
+
+	currentRole := clusterMemberDatabaseRole(oldRoles)
+	if currentRole != clusterMemberDatabaseRole(newRoles) {
+		return db.RaftSpare, nil
+	}
+
+	return currentRole, nil
+}
+
+// changeClusterMemberDatabaseRole gives the member with the given address the requested database role.
+// This must be called on the leader.
+func changeClusterMemberDatabaseRole(d *Daemon, r *http.Request, address string, role db.RaftRole) error {
+	s := d.State()
+
+	d.clusterMembershipMutex.Lock()
+	defer d.clusterMembershipMutex.Unlock()
+
+	changes, err := cluster.ChangeRole(s, d.gateway, address, role)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		for _, node := range change.Nodes {
+			if node.Address == change.Address {
+				logger.Info("Changing cluster member database role", logger.Ctx{"name": node.Name, "role": node.Role})
+				break
+			}
+		}
+
+		err = changeMemberRole(s, r, change.Address, change.Nodes)
+		if err != nil {
+			return fmt.Errorf("Failed changing database role of cluster member %q: %w", change.Address, err)
+		}
+	}
+
+	return nil
+}
+
 // clusterRolesChanged checks whether the non-internal roles have changed between oldRoles and newRoles.
 func clusterRolesChanged(oldRoles []db.ClusterRole, newRoles []db.ClusterRole) bool {
 	// Build list of external-only roles from the newRoles list (excludes internal roles added by raft).
@@ -2849,14 +3059,14 @@ func clusterNodeStatePost(d *Daemon, r *http.Request) response.Response {
 			return nil
 		}
 
-		migrateFunc := func(ctx context.Context, s *state.State, inst instance.Instance, sourceMemberInfo *db.NodeInfo, targetMemberInfo *db.NodeInfo, live bool, startInstance bool, metadata map[string]any, op *operations.Operation) error {
+		migrateFunc := func(ctx context.Context, s *state.State, inst instance.Instance, sourceMemberInfo *db.NodeInfo, targetMemberInfo *db.NodeInfo, live bool, startInstance bool, progress *evacuateProgress, op *operations.Operation) error {
 			// Migrate the instance.
 			req := api.InstancePost{
 				Migration: true,
 				Live:      live,
 			}
 
-			err := migrateInstance(ctx, s, inst, req, sourceMemberInfo, targetMemberInfo, "", op)
+			err := migrateInstance(ctx, s, inst, req, sourceMemberInfo, targetMemberInfo, "", op, progress.transferHandler(inst))
 			if err != nil {
 				return fmt.Errorf("Failed to migrate instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
 			}
@@ -2873,10 +3083,7 @@ func clusterNodeStatePost(d *Daemon, r *http.Request) response.Response {
 
 			dest = dest.UseProject(inst.Project().Name)
 
-			if metadata != nil && op != nil {
-				metadata["evacuation_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name)
-				_ = op.UpdateMetadata(metadata)
-			}
+			progress.setStatus(inst, "starting", fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name))
 
 			startOp, err := dest.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "start"}, "")
 			if err != nil {
@@ -11,6 +11,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -40,7 +41,7 @@ import (
 
 type (
 	evacuateStopFunc    func(inst instance.Instance, action string) error
-	evacuateMigrateFunc func(ctx context.Context, s *state.State, inst instance.Instance, sourceMemberInfo *db.NodeInfo, targetMemberInfo *db.NodeInfo, live bool, startInstance bool, metadata map[string]any, op *operations.Operation) error
+	evacuateMigrateFunc func(ctx context.Context, s *state.State, inst instance.Instance, sourceMemberInfo *db.NodeInfo, targetMemberInfo *db.NodeInfo, live bool, startInstance bool, progress *evacuateProgress, op *operations.Operation) error
 )
 
 type evacuateOpts struct {
@@ -51,6 +52,157 @@ type evacuateOpts struct {
 	stopInstance    evacuateStopFunc
 	migrateInstance evacuateMigrateFunc
 	op              *operations.Operation
+	progress        *evacuateProgress
+}
+
+// evacuateProgress tracks the progress of each instance of an evacuation and reports it in the operation metadata.
+type evacuateProgress struct {
+	mu        sync.Mutex
+	op        *operations.Operation
+	message   string
+	instances []*evacuateInstanceProgress
+}
+
+// evacuateInstanceProgress tracks the progress of a single instance of an evacuation.
+type evacuateInstanceProgress struct {
+	api.ClusterMemberEvacuationInstance
+
+	completed uint64 // Bytes transferred for the volumes which have already been migrated.
+	processed uint64 // Bytes transferred for the volume currently being migrated.
+}
+
+// newEvacuateProgress returns a new progress tracker with all the instances pending.
+func newEvacuateProgress(op *operations.Operation, instances []instance.Instance) *evacuateProgress {
+	p := &evacuateProgress{
+		op:        op,
+		instances: make([]*evacuateInstanceProgress, 0, len(instances)),
+	}
+
+	for _, inst := range instances {
+		p.instances = append(p.instances, &evacuateInstanceProgress{
+			ClusterMemberEvacuationInstance: api.ClusterMemberEvacuationInstance{
+				Name:    inst.Name(),
+				Project: inst.Project().Name,
+				Status:  "pending",
+			},
+		})
+	}
+
+	return p
+}
+
+// update applies the change to the progress of the instance and refreshes the operation metadata.
+// If not empty, the message replaces the overall evacuation progress message.
+func (p *evacuateProgress) update(inst instance.Instance, message string, apply func(entry *evacuateInstanceProgress)) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, entry := range p.instances {
+		if entry.Name == inst.Name() && entry.Project == inst.Project().Name {
+			apply(entry)
+			break
+		}
+	}
+
+	if message != "" {
+		p.message = message
+	}
+
+	if p.op == nil {
+		return
+	}
+
+	// Render a copy so that further changes don't race with the operation.
+	instances := make([]api.ClusterMemberEvacuationInstance, 0, len(p.instances))
+	for _, entry := range p.instances {
+		instances = append(instances, entry.ClusterMemberEvacuationInstance)
+	}
+
+	metadata := map[string]any{"evacuation_instances": instances}
+	if p.message != "" {
+		metadata["evacuation_progress"] = p.message
+	}
+
+	_ = p.op.UpdateMetadata(metadata)
+}
+
+// setStatus sets the evacuation status of the instance.
+func (p *evacuateProgress) setStatus(inst instance.Instance, status string, message string) {
+	p.update(inst, message, func(entry *evacuateInstanceProgress) {
+		entry.Status = status
+
+		if status != "migrating" && status != "live-migrating" {
+			entry.ETA = 0
+		}
+	})
+}
+
+// transferHandler returns an operation handler recording the transfer progress of the instance
+// from the metadata of its migration operation. Returns nil if progress isn't tracked.
+func (p *evacuateProgress) transferHandler(inst instance.Instance) func(api.Operation) {
+	if p == nil {
+		return nil
+	}
+
+	return func(migrateOp api.Operation) {
+		progress, ok := migrateOp.Metadata["progress"].(map[string]any)
+		if !ok {
+			return
+		}
+
+		processedStr, _ := progress["processed"].(string)
+		processed, err := strconv.ParseUint(processedStr, 10, 64)
+		if err != nil {
+			return
+		}
+
+		speedStr, _ := progress["speed"].(string)
+		speed, _ := strconv.ParseUint(speedStr, 10, 64)
+
+		p.update(inst, "", func(entry *evacuateInstanceProgress) {
+			// Each volume and snapshot reports its own progress, so a lower value means a new transfer.
+			if processed < entry.processed {
+				entry.completed += entry.processed
+			}
+
+			entry.processed = processed
+			entry.BytesTransferred = entry.completed + entry.processed
+
+			entry.ETA = 0
+			if speed > 0 && entry.BytesTotal > entry.BytesTransferred {
+				entry.ETA = (entry.BytesTotal - entry.BytesTransferred) / speed
+			}
+		})
+	}
+}
+
+// evacuateBytesTotal returns the estimated number of bytes to transfer when migrating the instance along with its snapshots.
+// Volumes whose usage can't be retrieved are left out of the estimate.
+func evacuateBytesTotal(inst instance.Instance, usage func(inst instance.Instance) (int64, error)) uint64 {
+	var total uint64
+
+	used, err := usage(inst)
+	if err == nil && used > 0 {
+		total += uint64(used)
+	}
+
+	snapshots, err := inst.Snapshots()
+	if err != nil {
+		return total
+	}
+
+	for _, snap := range snapshots {
+		used, err := usage(snap)
+		if err == nil && used > 0 {
+			total += uint64(used)
+		}
+	}
+
+	return total
 }
 
 func evacuateClusterSetState(s *state.State, name string, newState int) error {
@@ -170,9 +322,18 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 	group, groupCtx := errgroup.WithContext(ctx)
 	group.SetLimit(numParallelEvacs)
 
+	// Track the progress of each instance.
+	opts.progress = newEvacuateProgress(opts.op, opts.instances)
+
 	for _, inst := range opts.instances {
 		group.Go(func() error {
-			return evacuateInstancesFunc(groupCtx, inst, opts)
+			err := evacuateInstancesFunc(groupCtx, inst, opts)
+			if err != nil {
+				opts.progress.setStatus(inst, "failed", "")
+				return err
+			}
+
+			return nil
 		})
 	}
 
@@ -185,8 +346,6 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 }
 
 func evacuateInstancesFunc(ctx context.Context, inst instance.Instance, opts evacuateOpts) error {
-	metadata := make(map[string]any)
-
 	instProject := inst.Project()
 	l := logger.AddContext(logger.Ctx{"project": instProject.Name, "instance": inst.Name()})
 
@@ -203,6 +362,7 @@ func evacuateInstancesFunc(ctx context.Context, inst instance.Instance, opts eva
 
 			if action != "migrate" {
 				// We can only migrate instances or leave them as they are.
+				opts.progress.setStatus(inst, "skipped", "")
 				return nil
 			}
 		} else if opts.mode != "auto" {
@@ -214,8 +374,10 @@ func evacuateInstancesFunc(ctx context.Context, inst instance.Instance, opts eva
 	isRunning := inst.IsRunning()
 	if action != "live-migrate" {
 		if opts.stopInstance != nil && isRunning {
-			metadata["evacuation_progress"] = fmt.Sprintf("Stopping %q in project %q", inst.Name(), instProject.Name)
-			_ = opts.op.UpdateMetadata(metadata)
+			opts.progress.update(inst, fmt.Sprintf("Stopping %q in project %q", inst.Name(), instProject.Name), func(entry *evacuateInstanceProgress) {
+				entry.Action = action
+				entry.Status = "stopping"
+			})
 
 			err := opts.stopInstance(inst, action)
 			if err != nil {
@@ -225,6 +387,11 @@ func evacuateInstancesFunc(ctx context.Context, inst instance.Instance, opts eva
 
 		if action != "migrate" {
 			// Done with this instance.
+			opts.progress.update(inst, "", func(entry *evacuateInstanceProgress) {
+				entry.Action = action
+				entry.Status = "stopped"
+			})
+
 			return nil
 		}
 	} else if !isRunning {
@@ -238,15 +405,38 @@ func evacuateInstancesFunc(ctx context.Context, inst instance.Instance, opts eva
 		if api.StatusErrorCheck(err, http.StatusNotFound) {
 			// Skip migration if no target is available.
 			l.Warn("No migration target available for instance")
+			opts.progress.setStatus(inst, "skipped", "")
 			return nil
 		}
 
 		return err
 	}
 
+	// Estimate how much data needs to be transferred, nothing is copied for remote storage pools.
+	var bytesTotal uint64
+	pool, err := storagePools.LoadByInstance(opts.s, inst)
+	if err == nil && !pool.Driver().Info().Remote {
+		bytesTotal = evacuateBytesTotal(inst, func(inst instance.Instance) (int64, error) {
+			usage, err := pool.GetInstanceUsage(inst)
+			if err != nil {
+				return -1, err
+			}
+
+			return usage.Used, nil
+		})
+	}
+
 	// Start migrating the instance.
-	metadata["evacuation_progress"] = fmt.Sprintf("Migrating %q in project %q to %q", inst.Name(), instProject.Name, targetMemberInfo.Name)
-	_ = opts.op.UpdateMetadata(metadata)
+	opts.progress.update(inst, fmt.Sprintf("Migrating %q in project %q to %q", inst.Name(), instProject.Name, targetMemberInfo.Name), func(entry *evacuateInstanceProgress) {
+		entry.Action = action
+		entry.Status = "migrating"
+		if action == "live-migrate" {
+			entry.Status = "live-migrating"
+		}
+
+		entry.Target = targetMemberInfo.Name
+		entry.BytesTotal = bytesTotal
+	})
 
 	// Set origin server (but skip if already set as that suggests more than one server being evacuated).
 	if inst.LocalConfig()["volatile.evacuate.origin"] == "" {
@@ -254,11 +444,13 @@ func evacuateInstancesFunc(ctx context.Context, inst instance.Instance, opts eva
 	}
 
 	start := isRunning || instanceShouldAutoStart(inst)
-	err = opts.migrateInstance(ctx, opts.s, inst, sourceMemberInfo, targetMemberInfo, action == "live-migrate", start, metadata, opts.op)
+	err = opts.migrateInstance(ctx, opts.s, inst, sourceMemberInfo, targetMemberInfo, action == "live-migrate", start, opts.progress, opts.op)
 	if err != nil {
 		return err
 	}
 
+	opts.progress.setStatus(inst, "done", "")
+
 	return nil
 }
 
@@ -566,6 +758,12 @@ func evacuateClusterSelectTarget(ctx context.Context, s *state.State, inst insta
 			return err
 		}
 
+		// Spread the instances of the same anti-affinity group across failure domains.
+		candidateMembers, err = tx.SpreadCandidateMembers(ctx, candidateMembers, inst.Project().Name, inst.ExpandedConfig()["cluster.anti_affinity"], inst.Name())
+		if err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -728,7 +926,7 @@ func healClusterMember(d *Daemon, op *operations.Operation, name string) error {
 	logger.Info("Starting cluster healing", logger.Ctx{"server": name})
 	defer logger.Info("Completed cluster healing", logger.Ctx{"server": name})
 
-	migrateFunc := func(ctx context.Context, s *state.State, inst instance.Instance, sourceMemberInfo *db.NodeInfo, targetMemberInfo *db.NodeInfo, live bool, startInstance bool, metadata map[string]any, op *operations.Operation) error {
+	migrateFunc := func(ctx context.Context, s *state.State, inst instance.Instance, sourceMemberInfo *db.NodeInfo, targetMemberInfo *db.NodeInfo, live bool, startInstance bool, progress *evacuateProgress, op *operations.Operation) error {
 		// This returns an error if the instance's storage pool is local.
 		// Since we only care about remote backed instances, this can be ignored and return nil instead.
 		poolName, err := inst.StoragePool()
@@ -778,6 +976,8 @@ func healClusterMember(d *Daemon, op *operations.Operation, name string) error {
 		}
 
 		// Start it back up on target.
+		progress.setStatus(inst, "starting", fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name))
+
 		startOp, err := dest.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "start"}, "")
 		if err != nil {
 			return err
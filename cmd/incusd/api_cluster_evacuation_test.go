@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// evacuateTestInstance is an instance only implementing what the evacuation progress relies on.
+type evacuateTestInstance struct {
+	instance.Instance
+
+	name      string
+	snapshots []instance.Instance
+}
+
+func (i *evacuateTestInstance) Name() string {
+	return i.name
+}
+
+func (i *evacuateTestInstance) Project() api.Project {
+	return api.Project{Name: "default"}
+}
+
+func (i *evacuateTestInstance) Snapshots() ([]instance.Instance, error) {
+	return i.snapshots, nil
+}
+
+// Test that the estimated transfer size includes the snapshots.
+func TestEvacuateBytesTotal(t *testing.T) {
+	inst := &evacuateTestInstance{
+		name: "c1",
+		snapshots: []instance.Instance{
+			&evacuateTestInstance{name: "c1/snap0"},
+			&evacuateTestInstance{name: "c1/snap1"},
+			&evacuateTestInstance{name: "c1/snap2"},
+		},
+	}
+
+	usage := map[string]int64{
+		"c1":       1000,
+		"c1/snap0": 200,
+		"c1/snap1": 30,
+		"c1/snap2": -1,
+	}
+
+	total := evacuateBytesTotal(inst, func(inst instance.Instance) (int64, error) {
+		return usage[inst.Name()], nil
+	})
+
+	assert.Equal(t, uint64(1230), total)
+
+	// Volumes whose usage isn't known are left out.
+	total = evacuateBytesTotal(inst, func(inst instance.Instance) (int64, error) {
+		if inst.Name() == "c1/snap0" {
+			return -1, errors.New("Not supported")
+		}
+
+		return usage[inst.Name()], nil
+	})
+
+	assert.Equal(t, uint64(1030), total)
+}
+
+// Test that the transfer progress of each volume is aggregated per instance.
+func TestEvacuateProgressTransfer(t *testing.T) {
+	c1 := &evacuateTestInstance{name: "c1"}
+	c2 := &evacuateTestInstance{name: "c2"}
+
+	p := newEvacuateProgress(nil, []instance.Instance{c1, c2})
+	p.update(c1, "", func(entry *evacuateInstanceProgress) { entry.BytesTotal = 1000 })
+
+	transfer := func(inst instance.Instance, processed string, speed string) {
+		p.transferHandler(inst)(api.Operation{Metadata: map[string]any{
+			"progress": map[string]any{"stage": "fs_progress", "processed": processed, "speed": speed},
+		}})
+	}
+
+	// The snapshots are transferred first, each restarting from zero.
+	transfer(c1, "100", "10")
+	transfer(c1, "200", "10")
+	transfer(c1, "50", "10")
+	transfer(c1, "300", "100")
+
+	entry := p.instances[0]
+	assert.Equal(t, uint64(500), entry.BytesTransferred)
+	assert.Equal(t, uint64(5), entry.ETA)
+
+	// Other instances aren't affected.
+	assert.Equal(t, uint64(0), p.instances[1].BytesTransferred)
+
+	// Updates without progress are ignored.
+	p.transferHandler(c1)(api.Operation{Metadata: map[string]any{}})
+	assert.Equal(t, uint64(500), entry.BytesTransferred)
+
+	// The ETA is cleared once done.
+	p.setStatus(c1, "done", "")
+	assert.Equal(t, "done", entry.Status)
+	assert.Equal(t, uint64(0), entry.ETA)
+}
@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"runtime"
 	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/gorilla/mux"
+
 	"github.com/lxc/incus/v6/internal/server/auth"
 	"github.com/lxc/incus/v6/internal/server/db"
 	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
@@ -36,6 +39,9 @@ var (
 	metricsCacheLock sync.Mutex
 )
 
+// metricsLockTimeout is how long a request waits for another one to finish collecting the metrics.
+const metricsLockTimeout = 8 * time.Second
+
 var metricsCmd = APIEndpoint{
 	Path: "metrics",
 
@@ -61,6 +67,7 @@ func allowMetrics(d *Daemon, r *http.Request) response.Response {
 //	---
 //	produces:
 //	  - text/plain
+//	  - application/openmetrics-text
 //	parameters:
 //	  - in: query
 //	    name: project
@@ -160,13 +167,13 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 	// If all valid, return immediately.
 	if len(projectsToFetch) == 0 {
-		return getFilteredMetrics(s, r, compress, metricSet)
+		return getFilteredMetrics(s, r, compress, metricSet, d.instanceTraces)
 	}
 
-	cacheDuration := time.Duration(8) * time.Second
+	cacheDuration := s.GlobalConfig.MetricsCacheTTL()
 
 	// Acquire update lock.
-	lockCtx, lockCtxCancel := context.WithTimeout(r.Context(), cacheDuration)
+	lockCtx, lockCtxCancel := context.WithTimeout(r.Context(), metricsLockTimeout)
 	defer lockCtxCancel()
 
 	unlock, err := locking.Lock(lockCtx, "metricsGet")
@@ -185,15 +192,22 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 	// If all valid, return immediately.
 	if len(projectsToFetch) == 0 {
-		return getFilteredMetrics(s, r, compress, metricSet)
+		return getFilteredMetrics(s, r, compress, metricSet, d.instanceTraces)
 	}
 
 	// Gather information about host interfaces once.
 	hostInterfaces, _ := net.Interfaces()
 
+	metricsDefault := s.GlobalConfig.InstancesMetricsEnabled()
+
 	var instances []instance.Instance
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		return tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+			// Skip excluded instances before even loading them.
+			if !instance.MetricsEnabled(db.ExpandInstanceConfig(dbInst.Config, dbInst.Profiles), metricsDefault) {
+				return nil
+			}
+
 			inst, err := instance.Load(s, dbInst, p)
 			if err != nil {
 				return fmt.Errorf("Failed loading instance %q in project %q: %w", dbInst.Name, dbInst.Project, err)
@@ -208,8 +222,47 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Gather the metrics of the instances.
+	newMetrics := instancesMetrics(instances, hostInterfaces)
+
+	// Put the new data in the global cache and in response.
+	metricsCacheLock.Lock()
+
+	if metricsCache == nil {
+		metricsCache = map[string]metricsCacheEntry{}
+	}
+
+	updatedProjects := []string{}
+	for project, entries := range newMetrics {
+		metricsCache[project] = metricsCacheEntry{
+			expiry:  time.Now().Add(cacheDuration),
+			metrics: entries,
+		}
+
+		updatedProjects = append(updatedProjects, project)
+		metricSet.Merge(entries)
+	}
+
+	for _, project := range projectsToFetch {
+		if slices.Contains(updatedProjects, *project.Project) {
+			continue
+		}
+
+		metricsCache[*project.Project] = metricsCacheEntry{
+			expiry: time.Now().Add(cacheDuration),
+		}
+	}
+
+	metricsCacheLock.Unlock()
+
+	return getFilteredMetrics(s, r, compress, metricSet, d.instanceTraces)
+}
+
+// instancesMetrics gathers the metrics of the given instances, grouped by project.
+// Stopped instances and instances failing to provide metrics are skipped.
+func instancesMetrics(instances []instance.Instance, hostInterfaces []net.Interface) map[string]*metrics.MetricSet {
 	// Prepare temporary metrics storage.
-	newMetrics := make(map[string]*metrics.MetricSet, len(projectsToFetch))
+	newMetrics := make(map[string]*metrics.MetricSet)
 	newMetricsLock := sync.Mutex{}
 
 	// Limit metrics build concurrency to number of instances or number of CPU cores (which ever is less).
@@ -260,42 +313,47 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 	wg.Wait()
 	close(instMetricsCh)
 
-	// Put the new data in the global cache and in response.
-	metricsCacheLock.Lock()
+	return newMetrics
+}
 
-	if metricsCache == nil {
-		metricsCache = map[string]metricsCacheEntry{}
+// metricsResponse renders the metrics, using the OpenMetrics format with trace exemplars when enabled and
+// requested by the client.
+func metricsResponse(s *state.State, r *http.Request, compress bool, metricSet *metrics.MetricSet, traces *metrics.InstanceTraces) response.Response {
+	if !s.GlobalConfig.MetricsExemplars() || !strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+		return response.SyncResponsePlain(true, compress, metricSet.String())
 	}
 
-	updatedProjects := []string{}
-	for project, entries := range newMetrics {
-		metricsCache[project] = metricsCacheEntry{
-			expiry:  time.Now().Add(cacheDuration),
-			metrics: entries,
-		}
+	headers := map[string]string{"Content-Type": "application/openmetrics-text; version=1.0.0; charset=utf-8"}
 
-		updatedProjects = append(updatedProjects, project)
-		metricSet.Merge(entries)
+	return response.SyncResponsePlainHeaders(true, compress, metricSet.OpenMetrics(traces.Exemplar), headers)
+}
+
+// recordInstanceTrace records the trace of a sampled API request changing an instance, so that the instance
+// metrics can reference it.
+func recordInstanceTrace(d *Daemon, r *http.Request, c APIEndpoint) {
+	if r.Method == http.MethodGet || r.Header.Get("traceparent") == "" || !strings.HasPrefix(c.Path, "instances/{name}") {
+		return
 	}
 
-	for _, project := range projectsToFetch {
-		if slices.Contains(updatedProjects, *project.Project) {
-			continue
-		}
+	exemplar := metrics.ExemplarFromTraceParent(r.Header.Get("traceparent"), time.Now())
+	if exemplar == nil || !d.State().GlobalConfig.MetricsExemplars() {
+		return
+	}
 
-		metricsCache[*project.Project] = metricsCacheEntry{
-			expiry: time.Now().Add(cacheDuration),
-		}
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return
 	}
 
-	metricsCacheLock.Unlock()
+	// Snapshot requests act on their parent instance.
+	instanceName, _, _ := api.GetParentAndSnapshotName(name)
 
-	return getFilteredMetrics(s, r, compress, metricSet)
+	d.instanceTraces.Record(request.ProjectParam(r), instanceName, *exemplar)
 }
 
-func getFilteredMetrics(s *state.State, r *http.Request, compress bool, metricSet *metrics.MetricSet) response.Response {
+func getFilteredMetrics(s *state.State, r *http.Request, compress bool, metricSet *metrics.MetricSet, traces *metrics.InstanceTraces) response.Response {
 	if !s.GlobalConfig.MetricsAuthentication() {
-		return response.SyncResponsePlain(true, compress, metricSet.String())
+		return metricsResponse(s, r, compress, metricSet, traces)
 	}
 
 	// Get instances the user is allowed to view.
@@ -311,7 +369,7 @@ func getFilteredMetrics(s *state.State, r *http.Request, compress bool, metricSe
 
 	metricSet.FilterSamples(userHasPermission)
 
-	return response.SyncResponsePlain(true, compress, metricSet.String())
+	return metricsResponse(s, r, compress, metricSet, traces)
 }
 
 func internalMetrics(ctx context.Context, daemonStartTime time.Time, tx *db.ClusterTx) *metrics.MetricSet {
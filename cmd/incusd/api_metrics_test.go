@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Benchmark serving /1.0/metrics for 1000 instances with all of them included and with most of them excluded.
+func BenchmarkMetricsGet(b *testing.B) {
+	b.Setenv("INCUS_DIR", b.TempDir())
+
+	d, err := mockStartDaemon()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	defer func() { _ = d.Stop(context.Background(), unix.SIGQUIT) }()
+
+	s := d.State()
+
+	setMetricsEnabled := func(excluded bool) {
+		err := s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+			instances, err := dbCluster.GetInstances(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			for i, inst := range instances {
+				// An empty value unsets the key.
+				value := ""
+				if excluded && i%10 != 0 {
+					value = "false"
+				}
+
+				err = tx.UpdateInstanceConfig(inst.ID, map[string]string{"metrics.enabled": value})
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	err = s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+		for i := 0; i < 1000; i++ {
+			_, err := dbCluster.CreateInstance(ctx, tx.Tx(), dbCluster.Instance{
+				Project:      api.ProjectDefaultName,
+				Name:         fmt.Sprintf("c%d", i),
+				Type:         instancetype.Container,
+				Architecture: 1,
+				Node:         s.ServerName,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), request.CtxProtocol, "unix")
+	ctx = context.WithValue(ctx, request.CtxUsername, "")
+
+	for _, excluded := range []bool{false, true} {
+		setMetricsEnabled(excluded)
+
+		b.Run(fmt.Sprintf("excluded=%v", excluded), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				// Bypass the per-project cache.
+				metricsCacheLock.Lock()
+				metricsCache = nil
+				metricsCacheLock.Unlock()
+
+				r := httptest.NewRequest(http.MethodGet, "/1.0/metrics", nil).WithContext(ctx)
+				err := metricsGet(d, r).Render(httptest.NewRecorder())
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -21,7 +21,9 @@ import (
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
 	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/logship"
 	"github.com/lxc/incus/v6/internal/server/network"
 	"github.com/lxc/incus/v6/internal/server/operations"
 	projecthelpers "github.com/lxc/incus/v6/internal/server/project"
@@ -1349,6 +1351,14 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  shortdesc: Compression algorithm to use for backups
 		"backups.compression_algorithm": validate.IsCompressionAlgorithm,
 
+		// gendoc:generate(entity=project, group=specific, key=console.log.forward)
+		// Where to forward the console output of the instances in this project, unless overridden by their own `console.log.forward`.
+		// Possible values are `journald` or a remote syslog server (`udp://<host>[:<port>]` or `tcp://<host>[:<port>]`).
+		// ---
+		//  type: string
+		//  shortdesc: Where to forward the console output of the instances
+		"console.log.forward": validate.Optional(logship.ValidateTarget),
+
 		// gendoc:generate(entity=project, group=features, key=features.profiles)
 		//
 		// ---
@@ -1495,6 +1505,22 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  shortdesc: Maximum number of networks that the project can have
 		"limits.networks": validate.Optional(validate.IsUint32),
 
+		// gendoc:generate(entity=project, group=specific, key=network.hwaddr_range)
+		// Range of MAC addresses (e.g. `02:00:00:00:00:00-02:00:00:ff:ff:ff`) to generate the MAC address of new instance network interfaces from.
+		// All the addresses of the range must be locally administered unicast addresses sharing their first byte.
+		// ---
+		//  type: string
+		//  shortdesc: MAC address range for the instances in the project
+		"network.hwaddr_range": func(value string) error {
+			if value == "" {
+				return nil
+			}
+
+			_, _, err := instance.ParseHWAddrRange(value)
+
+			return err
+		},
+
 		// gendoc:generate(entity=project, group=restricted, key=restricted)
 		// This option must be enabled to allow the `restricted.*` keys to take effect.
 		// To temporarily remove the restrictions, you can disable this option instead of clearing the related keys.
@@ -1771,6 +1797,38 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  defaultdesc: `block`
 		//  shortdesc: Whether to prevent creating instance or volume snapshots
 		"restricted.snapshots": isEitherAllowOrBlock,
+
+		// gendoc:generate(entity=project, group=specific, key=storage.volume.block.filesystem)
+		// Used by new custom volumes on block-backed storage pools which don't set `volume.block.filesystem`.
+		// Takes precedence over the server's {config:option}`server-miscellaneous:storage.volume.block.filesystem`.
+		// ---
+		//  type: string
+		//  shortdesc: Default file system of new custom storage volumes
+		"storage.volume.block.filesystem": validate.Optional(validate.IsOneOf("btrfs", "ext4", "xfs")),
+
+		// gendoc:generate(entity=project, group=specific, key=storage.volume.block.mount_options)
+		// Used by new custom volumes on block-backed storage pools which don't set `volume.block.mount_options`.
+		// Takes precedence over the server's {config:option}`server-miscellaneous:storage.volume.block.mount_options`.
+		// ---
+		//  type: string
+		//  shortdesc: Default mount options of new custom storage volumes
+		"storage.volume.block.mount_options": validate.IsAny,
+
+		// gendoc:generate(entity=project, group=specific, key=storage.volume.size)
+		// Used by new custom volumes on storage pools which don't set `volume.size`.
+		// Takes precedence over the server's {config:option}`server-miscellaneous:storage.volume.size`.
+		// ---
+		//  type: string
+		//  shortdesc: Default size of new custom storage volumes
+		"storage.volume.size": validate.Optional(validate.IsSize),
+
+		// gendoc:generate(entity=project, group=specific, key=storage.volume.zfs.blocksize)
+		// Used by new custom volumes on ZFS storage pools which don't set `volume.zfs.blocksize`.
+		// Takes precedence over the server's {config:option}`server-miscellaneous:storage.volume.zfs.blocksize`.
+		// ---
+		//  type: string
+		//  shortdesc: Default ZFS block size of new custom storage volumes
+		"storage.volume.zfs.blocksize": validate.Optional(validate.IsSize),
 	}
 
 	// Add the storage pool keys.
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+
+	clusterConfig "github.com/lxc/incus/v6/internal/server/cluster/config"
+	"github.com/lxc/incus/v6/internal/server/ratelimit"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// apiRateLimitExemptions holds the clients which are never rate limited.
+type apiRateLimitExemptions struct {
+	names   []string
+	subnets []*net.IPNet
+}
+
+// apiRateLimiter applies the per-client and per-project API request rate limits.
+type apiRateLimiter struct {
+	clients  *ratelimit.Limiter
+	projects *ratelimit.Limiter
+	exempt   atomic.Pointer[apiRateLimitExemptions]
+}
+
+// newAPIRateLimiter returns a new apiRateLimiter with rate limiting disabled until configured.
+func newAPIRateLimiter() *apiRateLimiter {
+	l := &apiRateLimiter{
+		clients:  ratelimit.NewLimiter(ratelimit.Limits{}),
+		projects: ratelimit.NewLimiter(ratelimit.Limits{}),
+	}
+
+	l.exempt.Store(&apiRateLimitExemptions{})
+
+	return l
+}
+
+// configure applies the rate limiting settings from the given cluster configuration.
+func (l *apiRateLimiter) configure(config *clusterConfig.Config) {
+	clientRate, clientBurst, projectRate, projectBurst := config.RateLimit()
+
+	// Allow bursts of twice the rate unless configured otherwise.
+	if clientBurst == 0 {
+		clientBurst = 2 * clientRate
+	}
+
+	if projectBurst == 0 {
+		projectBurst = 2 * projectRate
+	}
+
+	l.clients.SetLimits(ratelimit.Limits{Rate: float64(clientRate), Burst: clientBurst})
+	l.projects.SetLimits(ratelimit.Limits{Rate: float64(projectRate), Burst: projectBurst})
+
+	exempt := &apiRateLimitExemptions{}
+	for _, entry := range config.RateLimitExempt() {
+		ip := net.ParseIP(entry)
+		if ip != nil {
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+
+			exempt.subnets = append(exempt.subnets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, subnet, err := net.ParseCIDR(entry)
+		if err == nil {
+			exempt.subnets = append(exempt.subnets, subnet)
+			continue
+		}
+
+		exempt.names = append(exempt.names, entry)
+	}
+
+	l.exempt.Store(exempt)
+}
+
+// isExempt returns whether the client is never rate limited.
+func (l *apiRateLimiter) isExempt(protocol string, username string, address string) bool {
+	// Cluster-internal and local traffic is never limited.
+	if address == "@" || slices.Contains([]string{"unix", "cluster"}, protocol) {
+		return true
+	}
+
+	exempt := l.exempt.Load()
+	if username != "" && slices.Contains(exempt.names, username) {
+		return true
+	}
+
+	ip := net.ParseIP(address)
+	if ip != nil {
+		for _, subnet := range exempt.subnets {
+			if subnet.Contains(ip) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// check takes a token from the buckets of the client and project of the request.
+// Sets the rate limiting state headers on the response and returns an error response if a limit is exceeded.
+func (l *apiRateLimiter) check(w http.ResponseWriter, r *http.Request, trusted bool, protocol string, username string) response.Response {
+	address, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		address = r.RemoteAddr
+	}
+
+	if l.isExempt(protocol, username, address) {
+		return nil
+	}
+
+	// Identify authenticated clients by their certificate fingerprint or username, others by their address.
+	key := "address/" + address
+	if trusted && username != "" {
+		key = fmt.Sprintf("%s/%s", protocol, username)
+	}
+
+	result := l.clients.Allow(key)
+	if result.Remaining >= 0 {
+		w.Header().Set(request.HeaderRateLimitLimit, strconv.FormatFloat(result.Limit, 'f', -1, 64))
+		w.Header().Set(request.HeaderRateLimitRemaining, strconv.FormatInt(result.Remaining, 10))
+	}
+
+	if !result.Allowed {
+		return l.reject(w, r, key, result)
+	}
+
+	// Only authenticated requests count against the project limit.
+	if !trusted {
+		return nil
+	}
+
+	projectName := request.ProjectParam(r)
+	result = l.projects.Allow(projectName)
+	if !result.Allowed {
+		return l.reject(w, r, "project/"+projectName, result)
+	}
+
+	return nil
+}
+
+// reject returns the error response for a request over the rate limit.
+func (l *apiRateLimiter) reject(w http.ResponseWriter, r *http.Request, key string, result ratelimit.Result) response.Response {
+	retryAfter := int64(math.Ceil(result.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+
+	logger.Debug("Rate limiting API request", logger.Ctx{"url": r.URL.RequestURI(), "ip": r.RemoteAddr, "key": key, "retry_after": retryAfter})
+
+	return response.SmartError(api.StatusErrorf(http.StatusTooManyRequests, "Too many requests, retry in %d seconds", retryAfter))
+}
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/network"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+var validateCmd = APIEndpoint{
+	Path: "validate",
+
+	Post: APIEndpointAction{Handler: validatePost, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanView)},
+}
+
+// configValidation collects the errors found while validating a proposed configuration.
+type configValidation struct {
+	errors []api.ConfigValidateError
+}
+
+// add records a validation error.
+func (v *configValidation) add(section string, key string, err error) {
+	v.errors = append(v.errors, api.ConfigValidateError{Section: section, Key: key, Message: err.Error()})
+}
+
+// config validates each key on its own, so that errors can be attributed to it, and then the whole config for the
+// checks spanning several keys. Returns whether the config is valid.
+func (v *configValidation) config(config map[string]string, validator func(config map[string]string) error) bool {
+	valid := true
+	for _, k := range slices.Sorted(maps.Keys(config)) {
+		err := validator(map[string]string{k: config[k]})
+		if err != nil {
+			v.add("config", k, err)
+			valid = false
+		}
+	}
+
+	if !valid {
+		return false
+	}
+
+	err := validator(config)
+	if err != nil {
+		v.add("config", "", err)
+		return false
+	}
+
+	return true
+}
+
+// devices validates each device on its own, so that errors can be attributed to it, and then all devices together.
+// Returns whether the devices are valid.
+func (v *configValidation) devices(devices map[string]map[string]string, validator func(devices deviceConfig.Devices) error) bool {
+	valid := true
+	for _, name := range slices.Sorted(maps.Keys(devices)) {
+		err := validator(deviceConfig.NewDevices(map[string]map[string]string{name: devices[name]}))
+		if err != nil {
+			v.add("devices", name, err)
+			valid = false
+		}
+	}
+
+	if !valid {
+		return false
+	}
+
+	err := validator(deviceConfig.NewDevices(devices))
+	if err != nil {
+		v.add("devices", "", err)
+		return false
+	}
+
+	return true
+}
+
+// swagger:operation POST /1.0/validate validate validate_post
+//
+//	Validate a configuration
+//
+//	Runs the validation of a proposed instance, profile, network, storage pool or storage volume
+//	configuration, including project limits and restrictions, without applying it.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: config
+//	    description: Configuration to validate
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ConfigValidatePost"
+//	responses:
+//	  "200":
+//	    description: Validation result
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ConfigValidateResult"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func validatePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+	projectName := request.ProjectParam(r)
+
+	req := api.ConfigValidatePost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	if req.Devices == nil {
+		req.Devices = map[string]map[string]string{}
+	}
+
+	if len(req.Devices) > 0 && !slices.Contains([]string{"instance", "profile"}, req.Entity) {
+		return response.BadRequest(fmt.Errorf("Devices can only be validated for instances and profiles"))
+	}
+
+	v := &configValidation{errors: []api.ConfigValidateError{}}
+
+	switch req.Entity {
+	case "instance":
+		err = validateInstanceConfig(r.Context(), s, projectName, req, v)
+	case "profile":
+		err = validateProfileConfig(r.Context(), s, projectName, req, v)
+	case "network":
+		err = validateNetworkConfig(s, projectName, req, v)
+	case "storage-pool":
+		err = validateStoragePoolConfig(s, req, v)
+	case "storage-volume":
+		err = validateStorageVolumeConfig(r.Context(), s, projectName, req, v)
+	default:
+		return response.BadRequest(fmt.Errorf("Unknown entity %q", req.Entity))
+	}
+
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, api.ConfigValidateResult{Valid: len(v.errors) == 0, Errors: v.errors})
+}
+
+// validateInstanceConfig validates the config and devices of a new or existing instance, expanded with its profiles.
+func validateInstanceConfig(ctx context.Context, s *state.State, projectName string, req api.ConfigValidatePost, v *configValidation) error {
+	instanceType, err := instancetype.New(req.Type)
+	if err != nil {
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid instance type %q", req.Type)
+	}
+
+	if req.Name != "" {
+		err = instance.ValidName(req.Name, false)
+		if err != nil {
+			v.add("name", "", err)
+		}
+	}
+
+	if req.Profiles == nil {
+		req.Profiles = []string{"default"}
+	}
+
+	var p *api.Project
+	var profiles []api.Profile
+	var currentConfig map[string]string
+	var exists bool
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return fmt.Errorf("Failed loading project %q: %w", projectName, err)
+		}
+
+		p, err = dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		profiles, err = tx.GetProfiles(ctx, projectName, req.Profiles)
+		if err != nil {
+			return err
+		}
+
+		if req.Name == "" {
+			return nil
+		}
+
+		dbInst, err := dbCluster.GetInstance(ctx, tx.Tx(), projectName, req.Name)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		exists = true
+		currentConfig, err = dbCluster.GetInstanceConfig(ctx, tx.Tx(), dbInst.ID)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	configValid := v.config(req.Config, func(config map[string]string) error {
+		return instance.ValidConfig(s.OS, config, false, instanceType)
+	})
+
+	devicesValid := v.devices(req.Devices, func(devices deviceConfig.Devices) error {
+		return instance.ValidDevices(s, *p, instanceType, devices, nil)
+	})
+
+	// Only check the expanded config once the instance's own config is valid, to not report the same error twice.
+	if configValid {
+		err = instance.ValidConfig(s.OS, db.ExpandInstanceConfig(req.Config, profiles), true, instanceType)
+		if err != nil {
+			v.add("config", "", err)
+		}
+	}
+
+	if devicesValid {
+		localDevices := deviceConfig.NewDevices(req.Devices)
+
+		err = instance.ValidDevices(s, *p, instanceType, localDevices, db.ExpandInstanceDevices(localDevices, profiles))
+		if err != nil {
+			v.add("devices", "", err)
+		}
+	}
+
+	// Check project limits and restrictions.
+	instancePut := api.InstancePut{Config: req.Config, Devices: req.Devices, Profiles: req.Profiles}
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		if exists {
+			return project.AllowInstanceUpdate(tx, projectName, req.Name, instancePut, currentConfig)
+		}
+
+		return project.AllowInstanceCreation(tx, projectName, api.InstancesPost{Name: req.Name, Type: api.InstanceType(instanceType.String()), InstancePut: instancePut})
+	})
+	if err != nil {
+		v.add("project", "", err)
+	}
+
+	return nil
+}
+
+// validateProfileConfig validates the config and devices of a new or existing profile.
+func validateProfileConfig(ctx context.Context, s *state.State, projectName string, req api.ConfigValidatePost, v *configValidation) error {
+	p, err := project.ProfileProject(s.DB.Cluster, projectName)
+	if err != nil {
+		return err
+	}
+
+	if strings.Contains(req.Name, "/") {
+		v.add("name", "", errors.New("Profile names may not contain slashes"))
+	} else if slices.Contains([]string{".", ".."}, req.Name) {
+		v.add("name", "", fmt.Errorf("Invalid profile name %q", req.Name))
+	}
+
+	v.config(req.Config, func(config map[string]string) error {
+		return instance.ValidConfig(s.OS, config, false, instancetype.Any)
+	})
+
+	// Profiles can be applied to any instance type, so don't perform instance type specific checks.
+	v.devices(req.Devices, func(devices deviceConfig.Devices) error {
+		return instance.ValidDevices(s, *p, instancetype.Any, devices, nil)
+	})
+
+	// Check project limits and restrictions when updating an existing profile.
+	if req.Name == "" {
+		return nil
+	}
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := dbCluster.GetProfile(ctx, tx.Tx(), p.Name, req.Name)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		err = project.AllowProfileUpdate(tx, p.Name, req.Name, api.ProfilePut{Config: req.Config, Devices: req.Devices})
+		if err != nil {
+			v.add("project", "", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateNetworkConfig validates the config of a new or existing network.
+func validateNetworkConfig(s *state.State, projectName string, req api.ConfigValidatePost, v *configValidation) error {
+	effectiveProjectName, reqProject, err := project.NetworkProject(s.DB.Cluster, projectName)
+	if err != nil {
+		return err
+	}
+
+	var n network.Network
+	if req.Name != "" {
+		n, err = network.LoadByName(s, effectiveProjectName, req.Name)
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+	}
+
+	if n == nil {
+		if req.Type == "" {
+			if effectiveProjectName != api.ProjectDefaultName {
+				req.Type = "ovn" // Only OVN networks are allowed inside network enabled projects.
+			} else {
+				req.Type = "bridge" // Default to bridge for non-network enabled projects.
+			}
+		}
+
+		n, err = network.LoadUncreated(s, effectiveProjectName, req.Name, req.Type, req.Config)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid network type %q: %w", req.Type, err)
+		}
+
+		if req.Name != "" {
+			err = n.ValidateName(req.Name)
+			if err != nil {
+				v.add("name", "", err)
+			}
+		}
+
+		if !project.NetworkAllowed(reqProject.Config, req.Name, true) {
+			v.add("project", "", errors.New("Network not allowed in project"))
+		}
+	} else if req.Type != "" && req.Type != n.Type() {
+		return api.StatusErrorf(http.StatusBadRequest, "Network %q is of type %q", req.Name, n.Type())
+	}
+
+	// Network drivers validate the config as a whole, with rules depending on other keys.
+	err = n.Validate(req.Config)
+	if err != nil {
+		v.add("config", "", err)
+	}
+
+	return nil
+}
+
+// validateStoragePoolConfig validates the config of a new or existing storage pool.
+func validateStoragePoolConfig(s *state.State, req api.ConfigValidatePost, v *configValidation) error {
+	var poolType storagePools.Type
+
+	if req.Name != "" {
+		pool, err := storagePools.LoadByName(s, req.Name)
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		if pool != nil {
+			if req.Type != "" && req.Type != pool.Driver().Info().Name {
+				return api.StatusErrorf(http.StatusBadRequest, "Storage pool %q uses driver %q", req.Name, pool.Driver().Info().Name)
+			}
+
+			poolType = pool
+		}
+	}
+
+	if poolType == nil {
+		var err error
+
+		poolType, err = storagePools.LoadByType(s, req.Type)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid storage driver %q: %w", req.Type, err)
+		}
+
+		if req.Name != "" {
+			err = poolType.ValidateName(req.Name)
+			if err != nil {
+				v.add("name", "", err)
+			}
+		}
+	}
+
+	// Storage drivers validate the config as a whole, with rules depending on other keys.
+	err := poolType.Validate(req.Config)
+	if err != nil {
+		v.add("config", "", err)
+	}
+
+	return nil
+}
+
+// validateStorageVolumeConfig validates the config of a new or existing custom storage volume.
+func validateStorageVolumeConfig(ctx context.Context, s *state.State, projectName string, req api.ConfigValidatePost, v *configValidation) error {
+	if req.Pool == "" {
+		return api.StatusErrorf(http.StatusBadRequest, "A storage pool is required to validate a storage volume")
+	}
+
+	pool, err := storagePools.LoadByName(s, req.Pool)
+	if err != nil {
+		return err
+	}
+
+	projectName, err = project.StorageVolumeProject(s.DB.Cluster, projectName, db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	if strings.Contains(req.Name, "/") {
+		v.add("name", "", errors.New("Storage volume names may not contain slashes"))
+	}
+
+	var dbVolume *db.StorageVolume
+
+	if req.Name != "" {
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			dbVolume, err = tx.GetStoragePoolVolume(ctx, pool.ID(), projectName, db.StoragePoolVolumeTypeCustom, req.Name, true)
+
+			return err
+		})
+		if err != nil && !response.IsNotFoundError(err) {
+			return err
+		}
+	}
+
+	if req.Type == "" {
+		req.Type = db.StoragePoolVolumeContentTypeNameFS
+	}
+
+	if dbVolume != nil {
+		req.Type = dbVolume.ContentType
+	}
+
+	volDBContentType, err := storagePools.VolumeContentTypeNameToContentType(req.Type)
+	if err != nil {
+		return api.StatusErrorf(http.StatusBadRequest, "%w", err)
+	}
+
+	contentType, err := storagePools.VolumeDBContentTypeToContentType(volDBContentType)
+	if err != nil {
+		return err
+	}
+
+	// Storage drivers validate the config as a whole, with rules depending on other keys.
+	err = storagePools.VolumeValidate(pool, projectName, req.Name, contentType, req.Config)
+	if err != nil {
+		v.add("config", "", err)
+	}
+
+	// Check project limits.
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		if dbVolume != nil {
+			return project.AllowVolumeUpdate(tx, projectName, req.Name, api.StorageVolumePut{Config: req.Config}, dbVolume.Config)
+		}
+
+		return project.AllowVolumeCreation(tx, projectName, req.Pool, api.StorageVolumesPost{Name: req.Name, StorageVolumePut: api.StorageVolumePut{Config: req.Config}})
+	})
+	if err != nil {
+		v.add("project", "", err)
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -26,6 +27,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/task"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/archive"
 	"github.com/lxc/incus/v6/shared/idmap"
 	"github.com/lxc/incus/v6/shared/ioprogress"
 	"github.com/lxc/incus/v6/shared/logger"
@@ -129,6 +131,18 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 	defer func() { _ = tarFileWriter.Close() }()
 	reverter.Add(func() { _ = os.Remove(target) })
 
+	// Optionally encrypt the backup as it's written.
+	var backupFileWriter io.WriteCloser = tarFileWriter
+	var encryptWriter io.WriteCloser
+	if args.EncryptionPassphrase != "" {
+		encryptWriter, err = archive.NewEncryptionWriter(tarFileWriter, args.EncryptionPassphrase)
+		if err != nil {
+			return fmt.Errorf("Failed setting up backup encryption: %w", err)
+		}
+
+		backupFileWriter = encryptWriter
+	}
+
 	// Get IDMap to unshift container as the tarball is created.
 	var idmapSet *idmap.Set
 	if sourceInst.Type() == instancetype.Container {
@@ -167,7 +181,7 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		l.Debug("Started backup tarball writer")
 		defer l.Debug("Finished backup tarball writer")
 		if compress != "none" {
-			backupProgressWriter.WriteCloser = tarFileWriter
+			backupProgressWriter.WriteCloser = backupFileWriter
 			compressErr = compressFile(compress, tarPipeReader, backupProgressWriter)
 
 			// If a compression error occurred, close the tarPipeWriter to end the export.
@@ -175,7 +189,7 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 				_ = tarPipeWriter.Close()
 			}
 		} else {
-			backupProgressWriter.WriteCloser = tarFileWriter
+			backupProgressWriter.WriteCloser = backupFileWriter
 			_, err = io.Copy(backupProgressWriter, tarPipeReader)
 		}
 
@@ -218,6 +232,14 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		return fmt.Errorf("Error writing tarball: %w", err)
 	}
 
+	// Write the final encrypted chunk.
+	if encryptWriter != nil {
+		err = encryptWriter.Close()
+		if err != nil {
+			return fmt.Errorf("Error finalizing backup encryption: %w", err)
+		}
+	}
+
 	err = tarFileWriter.Close()
 	if err != nil {
 		return fmt.Errorf("Error closing tar file: %w", err)
@@ -229,6 +251,30 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 	return nil
 }
 
+// backupDecryptReader returns a reader for uploaded backup data, decrypting it on the fly if it's encrypted.
+func backupDecryptReader(data io.Reader, passphrase string) (io.Reader, error) {
+	bufReader := bufio.NewReader(data)
+
+	header, err := bufReader.Peek(len(archive.EncryptionMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if !archive.IsEncrypted(header) {
+		if passphrase != "" {
+			return nil, errors.New("An encryption passphrase was provided but the backup isn't encrypted")
+		}
+
+		return bufReader, nil
+	}
+
+	if passphrase == "" {
+		return nil, errors.New("The backup is encrypted, an encryption passphrase is required")
+	}
+
+	return archive.NewDecryptionReader(bufReader, passphrase)
+}
+
 // backupWriteIndex generates an index.yaml file and then writes it to the root of the backup tarball.
 func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, tarWriter *instancewriter.InstanceTarWriter) error {
 	// Indicate whether the driver will include a driver-specific optimized header.
@@ -470,6 +516,18 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 	defer func() { _ = tarFileWriter.Close() }()
 	reverter.Add(func() { _ = os.Remove(target) })
 
+	// Optionally encrypt the backup as it's written.
+	var backupFileWriter io.Writer = tarFileWriter
+	var encryptWriter io.WriteCloser
+	if args.EncryptionPassphrase != "" {
+		encryptWriter, err = archive.NewEncryptionWriter(tarFileWriter, args.EncryptionPassphrase)
+		if err != nil {
+			return fmt.Errorf("Failed setting up backup encryption: %w", err)
+		}
+
+		backupFileWriter = encryptWriter
+	}
+
 	// Create the tarball.
 	tarPipeReader, tarPipeWriter := io.Pipe()
 	defer func() { _ = tarPipeWriter.Close() }() // Ensure that go routine below always ends.
@@ -483,14 +541,14 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 		l.Debug("Started backup tarball writer")
 		defer l.Debug("Finished backup tarball writer")
 		if compress != "none" {
-			compressErr = compressFile(compress, tarPipeReader, tarFileWriter)
+			compressErr = compressFile(compress, tarPipeReader, backupFileWriter)
 
 			// If a compression error occurred, close the tarPipeWriter to end the export.
 			if compressErr != nil {
 				_ = tarPipeWriter.Close()
 			}
 		} else {
-			_, err = io.Copy(tarFileWriter, tarPipeReader)
+			_, err = io.Copy(backupFileWriter, tarPipeReader)
 		}
 
 		resCh <- err
@@ -532,6 +590,14 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 		return fmt.Errorf("Error writing tarball: %w", err)
 	}
 
+	// Write the final encrypted chunk.
+	if encryptWriter != nil {
+		err = encryptWriter.Close()
+		if err != nil {
+			return fmt.Errorf("Error finalizing backup encryption: %w", err)
+		}
+	}
+
 	err = tarFileWriter.Close()
 	if err != nil {
 		return fmt.Errorf("Error closing tar file: %w", err)
@@ -50,6 +50,7 @@ import (
 	instanceDrivers "github.com/lxc/incus/v6/internal/server/instance/drivers"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/logging"
+	"github.com/lxc/incus/v6/internal/server/metrics"
 	"github.com/lxc/incus/v6/internal/server/network/ovn"
 	"github.com/lxc/incus/v6/internal/server/network/ovs"
 	networkZone "github.com/lxc/incus/v6/internal/server/network/zone"
@@ -138,6 +139,9 @@ type Daemon struct {
 	shutdownCancel context.CancelFunc // Cancels the shutdownCtx to indicate shutdown starting.
 	shutdownDoneCh chan error         // Receives the result of the d.Stop() function and tells the daemon to end.
 
+	// Delays system shutdowns until the instances are stopped.
+	shutdownInhibitor *localUtil.SystemdShutdownInhibitor
+
 	// Device monitor for watching filesystem events
 	devmonitor fsmonitor.FSMonitor
 
@@ -173,6 +177,12 @@ type Daemon struct {
 	// API info.
 	apiExtensions int
 
+	// API rate limiting.
+	rateLimiter *apiRateLimiter
+
+	// Latest traced API request of each instance, referenced by the metrics.
+	instanceTraces *metrics.InstanceTraces
+
 	// Linstor client.
 	linstor   *linstor.Client
 	linstorMu sync.Mutex
@@ -205,6 +215,8 @@ func newDaemon(config *DaemonConfig, os *sys.OS) *Daemon {
 		shutdownCancel: shutdownCancel,
 		shutdownDoneCh: make(chan error),
 		apiExtensions:  len(version.APIExtensions),
+		rateLimiter:    newAPIRateLimiter(),
+		instanceTraces: metrics.NewInstanceTraces(),
 	}
 
 	d.serverCert = func() *localtls.CertInfo { return d.serverCertInt }
@@ -669,6 +681,13 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 			}
 		}
 
+		// Rate limiting
+		limitResp := d.rateLimiter.check(w, r, trusted, protocol, username)
+		if limitResp != nil {
+			_ = limitResp.Render(w)
+			return
+		}
+
 		logCtx := logger.Ctx{"method": r.Method, "url": r.URL.RequestURI(), "ip": r.RemoteAddr, "protocol": protocol}
 		if protocol == "cluster" {
 			logCtx["fingerprint"] = username
@@ -705,6 +724,11 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 			return
 		}
 
+		// Keep track of the traced requests acting on instances.
+		if trusted {
+			recordInstanceTrace(d, r, c)
+		}
+
 		// Dump full request JSON when in debug mode
 		if daemon.Debug && r.Method != "GET" && localUtil.IsJSONRequest(r) {
 			newBody := &bytes.Buffer{}
@@ -1435,6 +1459,8 @@ func (d *Daemon) init() error {
 	authorizationScriptlet := d.globalConfig.AuthorizationScriptlet()
 
 	d.endpoints.NetworkUpdateTrustedProxy(d.globalConfig.HTTPSTrustedProxy())
+	d.rateLimiter.configure(d.globalConfig)
+	d.events.SetHistorySize(int(d.globalConfig.EventHistorySize()))
 	d.globalConfigMu.Unlock()
 
 	d.loggingController = logging.NewLoggingController(d.internalListener)
@@ -1681,6 +1707,9 @@ func (d *Daemon) init() error {
 		// Prune expired custom volume snapshots and take snapshots of custom volumes (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d))
 
+		// Check custom volume usage against soft limits (every five minutes)
+		d.tasks.Add(storageVolumeSoftLimitTask(d))
+
 		// Remove resolved warnings (daily)
 		d.tasks.Add(pruneResolvedWarningsTask(d))
 
@@ -1700,6 +1729,12 @@ func (d *Daemon) init() error {
 	// Re-balance in case things changed while the daemon was down
 	deviceTaskBalance(d.State())
 
+	// Delay system shutdowns until the instances are stopped
+	d.shutdownInhibitor, err = localUtil.SystemdInhibitShutdown("Stopping instances", d.hostShutdown)
+	if err != nil {
+		logger.Warn("Failed taking a systemd shutdown inhibitor", logger.Ctx{"err": err})
+	}
+
 	// Unblock incoming requests
 	d.waitReady.Cancel()
 
@@ -1749,10 +1784,36 @@ func (d *Daemon) numRunningInstances(instances []instance.Instance) int {
 	return count
 }
 
+// hostShutdown stops the instances when the host starts shutting down. The systemd shutdown inhibitor is released
+// once they're stopped or instances.host_shutdown_timeout is reached, whichever comes first.
+func (d *Daemon) hostShutdown(release func()) {
+	logger.Info("Host is shutting down, stopping instances")
+
+	s := d.State()
+
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		logger.Warn("Failed loading instances to stop on host shutdown", logger.Ctx{"err": err})
+		return
+	}
+
+	timeout := s.LocalConfig.InstancesHostShutdownTimeout()
+	if timeout > 0 {
+		deadline := time.AfterFunc(timeout, release)
+		defer deadline.Stop()
+	}
+
+	instancesShutdown(instances, timeout)
+}
+
 // Stop stops the shared daemon.
 func (d *Daemon) Stop(ctx context.Context, sig os.Signal) error {
 	logger.Info("Starting shutdown sequence", logger.Ctx{"signal": sig})
 
+	if d.shutdownInhibitor != nil {
+		defer d.shutdownInhibitor.Release()
+	}
+
 	// Cancelling the context will make everyone aware that we're shutting down.
 	d.shutdownCancel()
 
@@ -1829,7 +1890,12 @@ func (d *Daemon) Stop(ctx context.Context, sig os.Signal) error {
 
 		// Full shutdown requested.
 		if sig == unix.SIGPWR {
-			instancesShutdown(instances)
+			var timeout time.Duration
+			if s.LocalConfig != nil {
+				timeout = s.LocalConfig.InstancesHostShutdownTimeout()
+			}
+
+			instancesShutdown(instances, timeout)
 
 			logger.Info("Stopping networks")
 			networkShutdown(s)
@@ -57,6 +57,72 @@ func imageOperationLock(ctx context.Context, fingerprint string) (locking.Unlock
 	return locking.Lock(ctx, fmt.Sprintf("ImageOperation_%s", fingerprint))
 }
 
+// imageConnectSource connects to the image server at the given address using the given protocol.
+func imageConnectSource(s *state.State, server string, protocol string, certificate string, sourceProjectName string) (incus.ImageServer, error) {
+	clientArgs := &incus.ConnectionArgs{
+		TLSServerCert: certificate,
+		UserAgent:     version.UserAgent,
+		Proxy:         s.Proxy,
+		CachePath:     s.OS.CacheDir,
+		CacheExpiry:   time.Hour,
+	}
+
+	switch protocol {
+	case "", "incus", "lxd":
+		// Setup client
+		remote, err := incus.ConnectPublicIncus(server, clientArgs)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to connect to the server %q: %w", server, err)
+		}
+
+		instServer, ok := remote.(incus.InstanceServer)
+		if ok {
+			return instServer.UseProject(sourceProjectName), nil
+		}
+
+		return remote, nil
+	case "oci":
+		// Setup OCI client
+		remote, err := incus.ConnectOCI(server, clientArgs)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to connect to oci server %q: %w", server, err)
+		}
+
+		return remote, nil
+	case "simplestreams":
+		// Setup simplestreams client
+		remote, err := incus.ConnectSimpleStreams(server, clientArgs)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to connect to simple streams server %q: %w", server, err)
+		}
+
+		return remote, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported image server protocol %q", protocol)
+}
+
+// imageSourceLatest returns the information of the latest image available from the given image source.
+func imageSourceLatest(s *state.State, source api.ImageSource, imageType string) (*api.Image, error) {
+	remote, err := imageConnectSource(s, source.Server, source.Protocol, source.Certificate, "")
+	if err != nil {
+		return nil, err
+	}
+
+	fp := source.Alias
+	entry, _, err := remote.GetImageAliasType(imageType, fp)
+	if err == nil {
+		fp = entry.Target
+	}
+
+	info, _, err := remote.GetImage(fp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting remote image info: %w", err)
+	}
+
+	return info, nil
+}
+
 // ImageDownload resolves the image fingerprint and if not in the database, downloads it.
 func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *operations.Operation, args *ImageDownloadArgs) (*api.Image, bool, error) {
 	var err error
@@ -79,37 +145,9 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 
 	// Attempt to resolve the alias
 	if args.Server != "" && slices.Contains([]string{"incus", "lxd", "oci", "simplestreams"}, protocol) {
-		clientArgs := &incus.ConnectionArgs{
-			TLSServerCert: args.Certificate,
-			UserAgent:     version.UserAgent,
-			Proxy:         s.Proxy,
-			CachePath:     s.OS.CacheDir,
-			CacheExpiry:   time.Hour,
-		}
-
-		if slices.Contains([]string{"incus", "lxd"}, protocol) {
-			// Setup client
-			remote, err = incus.ConnectPublicIncus(args.Server, clientArgs)
-			if err != nil {
-				return nil, false, fmt.Errorf("Failed to connect to the server %q: %w", args.Server, err)
-			}
-
-			server, ok := remote.(incus.InstanceServer)
-			if ok {
-				remote = server.UseProject(args.SourceProjectName)
-			}
-		} else if protocol == "oci" {
-			// Setup OCI client
-			remote, err = incus.ConnectOCI(args.Server, clientArgs)
-			if err != nil {
-				return nil, false, fmt.Errorf("Failed to connect to oci server %q: %w", args.Server, err)
-			}
-		} else if protocol == "simplestreams" {
-			// Setup simplestreams client
-			remote, err = incus.ConnectSimpleStreams(args.Server, clientArgs)
-			if err != nil {
-				return nil, false, fmt.Errorf("Failed to connect to simple streams server %q: %w", args.Server, err)
-			}
+		remote, err = imageConnectSource(s, args.Server, protocol, args.Certificate, args.SourceProjectName)
+		if err != nil {
+			return nil, false, err
 		}
 
 		// For public images, handle aliases and initial metadata
@@ -349,6 +387,12 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 		op.SetCanceler(canceler)
 	}
 
+	// Allow limiting the bandwidth used by the download while it runs.
+	rateLimiter := internalIO.NewRateLimiter(0)
+	if op != nil {
+		rateLimiter = op.RateLimiter()
+	}
+
 	if slices.Contains([]string{"incus", "lxd", "oci", "simplestreams"}, protocol) {
 		// Create the target files
 		dest, err := os.Create(destName)
@@ -396,8 +440,8 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 		// Download the image
 		var resp *incus.ImageFileResponse
 		request := incus.ImageFileRequest{
-			MetaFile:        io.WriteSeeker(dest),
-			RootfsFile:      io.WriteSeeker(destRootfs),
+			MetaFile:        rateLimiter.WriteSeeker(dest),
+			RootfsFile:      rateLimiter.WriteSeeker(destRootfs),
 			ProgressHandler: progress,
 			Canceler:        canceler,
 			DeltaSourceRetriever: func(fingerprint string, file string) string {
@@ -503,7 +547,7 @@ func ImageDownload(ctx context.Context, r *http.Request, s *state.State, op *ope
 		hash256 := sha256.New()
 
 		// Download the image
-		writer := internalIO.NewQuotaWriter(io.MultiWriter(f, hash256), args.Budget)
+		writer := internalIO.NewQuotaWriter(rateLimiter.Writer(io.MultiWriter(f, hash256)), args.Budget)
 		size, err := io.Copy(writer, body)
 		if err != nil {
 			return nil, false, err
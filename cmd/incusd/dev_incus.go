@@ -122,7 +122,7 @@ var devIncusMetadataGet = devIncusHandler{"/1.0/meta-data", func(d *Daemon, inst
 
 	value := inst.ExpandedConfig()["user.meta-data"]
 
-	return response.DevIncusResponse(http.StatusOK, fmt.Sprintf("#cloud-config\ninstance-id: %s\nlocal-hostname: %s\n%s", inst.CloudInitID(), inst.Name(), value), "raw", inst.Type() == instancetype.VM)
+	return response.DevIncusResponse(http.StatusOK, fmt.Sprintf("#cloud-config\ninstance-id: %s\nlocal-hostname: %s\n%s", inst.CloudInitID(), instance.Hostname(inst.Name(), inst.ExpandedConfig()), value), "raw", inst.Type() == instancetype.VM)
 }}
 
 var devIncusEventsGet = devIncusHandler{"/1.0/events", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"slices"
@@ -106,6 +107,20 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 		return api.StatusErrorf(http.StatusForbidden, "Forbidden")
 	}
 
+	var since *events.HistoryPosition
+	sinceValue := request.QueryParam(r, "since")
+	if sinceValue != "" {
+		var err error
+		since, err = s.Events.ParseHistoryPosition(sinceValue)
+		if err != nil {
+			if errors.Is(err, events.ErrHistoryUnavailable) {
+				return api.StatusErrorf(http.StatusGone, "%v", err)
+			}
+
+			return api.StatusErrorf(http.StatusBadRequest, "%v", err)
+		}
+	}
+
 	l := logger.AddContext(logger.Ctx{"remote": r.RemoteAddr})
 
 	var excludeLocations []string
@@ -148,23 +163,29 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 		}
 	}
 
+	// Register the listener before upgrading the connection so that the events to replay are checked to still
+	// be in the history and get queued together with the new events, while errors can still be returned.
+	listener, err := s.Events.AddPendingListener(since, projectName, allProjects, projectPermissionFunc, types, excludeSources, recvFunc, excludeLocations)
+	if err != nil {
+		if errors.Is(err, events.ErrHistoryUnavailable) {
+			return api.StatusErrorf(http.StatusGone, "%v", err)
+		}
+
+		return err
+	}
+
 	// Upgrade the connection to websocket as late as possible.
 	// This is because the client will assume it's getting events as soon as the upgrade is performed.
 	conn, err := ws.Upgrader.Upgrade(w, r, nil)
 	if err != nil {
+		listener.Close()
 		l.Warn("Failed upgrading event connection", logger.Ctx{"err": err})
 		return nil
 	}
 
 	defer func() { _ = conn.Close() }() // Ensure listener below ends when this function ends.
 
-	listenerConnection := events.NewWebsocketListenerConnection(conn)
-	listener, err := s.Events.AddListener(projectName, allProjects, projectPermissionFunc, listenerConnection, types, excludeSources, recvFunc, excludeLocations)
-	if err != nil {
-		l.Warn("Failed to add event listener", logger.Ctx{"err": err})
-		return nil
-	}
-
+	listener.Start(events.NewWebsocketListenerConnection(conn))
 	listener.Wait(r.Context())
 
 	return nil
@@ -194,13 +215,22 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 //	    name: all-projects
 //	    description: Retrieve instances from all projects
 //	    type: boolean
+//	  - in: query
+//	    name: since
+//	    description: Replay the events which followed this event cursor or RFC3339 timestamp before the new events
+//	    type: string
+//	    example: 1f0d5b4c:1042
 //	responses:
 //	  "200":
 //	    description: Websocket message (JSON)
 //	    schema:
 //	      $ref: "#/definitions/Event"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
 //	  "403":
 //	    $ref: "#/responses/Forbidden"
+//	  "410":
+//	    description: The requested events are no longer in the event history
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func eventsGet(d *Daemon, r *http.Request) response.Response {
@@ -59,6 +59,7 @@ import (
 	"github.com/lxc/incus/v6/shared/logger"
 	"github.com/lxc/incus/v6/shared/osarch"
 	"github.com/lxc/incus/v6/shared/util"
+	"github.com/lxc/incus/v6/shared/validate"
 )
 
 var imagesCmd = APIEndpoint{
@@ -93,6 +94,7 @@ var imageSecretCmd = APIEndpoint{
 var imageRefreshCmd = APIEndpoint{
 	Path: "images/{fingerprint}/refresh",
 
+	Get:  APIEndpointAction{Handler: imageRefreshGet, AccessHandler: allowPermission(auth.ObjectTypeImage, auth.EntitlementCanView, "fingerprint")},
 	Post: APIEndpointAction{Handler: imageRefresh, AccessHandler: allowPermission(auth.ObjectTypeImage, auth.EntitlementCanEdit, "fingerprint")},
 }
 
@@ -196,6 +198,281 @@ func compressFile(compress string, infile io.Reader, outfile io.Writer) error {
 	return nil
 }
 
+// recompressFile decompresses the image tarball at srcPath and compresses it again into dstPath.
+func recompressFile(compress string, srcPath string, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = src.Close() }()
+
+	_, algo, unpacker, err := archive.DetectCompressionFile(src)
+	if err != nil {
+		return err
+	}
+
+	if unpacker == nil {
+		return fmt.Errorf("Unsupported image compression")
+	}
+
+	_, err = src.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = dst.Close() }()
+
+	var tarReader io.Reader = src
+	var cmd *exec.Cmd
+	if len(unpacker) > 0 {
+		if algo == ".squashfs" {
+			// sqfs2tar can only read from a file
+			unpacker = append(unpacker, srcPath)
+		}
+
+		cmd = exec.Command(unpacker[0], unpacker[1:]...)
+		cmd.Stdin = src
+
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return err
+		}
+
+		err = cmd.Start()
+		if err != nil {
+			return err
+		}
+
+		tarReader = stdout
+	}
+
+	if compress != "none" {
+		err = compressFile(compress, tarReader, dst)
+	} else {
+		_, err = io.Copy(dst, tarReader)
+	}
+
+	if cmd != nil {
+		waitErr := cmd.Wait()
+		if err == nil {
+			err = waitErr
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return dst.Close()
+}
+
+// recompressRootfs re-compresses the rootfs of a split image into dstPath. Disk images are kept as qcow2 and
+// rely on its own compression, while container root filesystems are handled like the image tarball.
+func recompressRootfs(compress string, srcPath string, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+
+	_, algo, _, err := archive.DetectCompressionFile(src)
+	_ = src.Close()
+	if err != nil {
+		return err
+	}
+
+	if algo != ".qcow2" {
+		return recompressFile(compress, srcPath, dstPath)
+	}
+
+	args, err := recompressQcow2Args(compress, srcPath, dstPath)
+	if err != nil {
+		return err
+	}
+
+	output, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("qemu-img: %v (%v)", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// recompressQcow2Args returns the command converting a qcow2 disk image into one compressed according to the
+// requested algorithm. Compressed clusters use zstd when requested and the qcow2 default otherwise.
+func recompressQcow2Args(compress string, srcPath string, dstPath string) ([]string, error) {
+	args := []string{"qemu-img", "convert", "-f", "qcow2", "-O", "qcow2"}
+
+	if compress != "none" {
+		fields, err := shellquote.Split(compress)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("No compression algorithm provided")
+		}
+
+		args = append(args, "-c")
+		if fields[0] == "zstd" {
+			args = append(args, "-o", "compression_type=zstd")
+		}
+	}
+
+	return append(args, srcPath, dstPath), nil
+}
+
+// imageRecompress re-packs an image with the given compression algorithm and records it under its new
+// fingerprint. When replaceSource is set, the original image is removed from the project afterwards.
+func imageRecompress(ctx context.Context, s *state.State, projectName string, info *api.Image, compress string, replaceSource bool) (*api.Image, error) {
+	imagesPath := internalUtil.VarPath("images")
+	srcPath := filepath.Join(imagesPath, info.Fingerprint)
+
+	builddir, err := os.MkdirTemp(imagesPath, "incus_build_")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = os.RemoveAll(builddir) }()
+
+	// Re-compress the image tarball along with the rootfs of split images.
+	metaPath := filepath.Join(builddir, "meta")
+	err = recompressFile(compress, srcPath, metaPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed re-compressing image %q: %w", info.Fingerprint, err)
+	}
+
+	paths := []string{metaPath}
+	if util.PathExists(srcPath + ".rootfs") {
+		rootfsPath := filepath.Join(builddir, "rootfs")
+		err = recompressRootfs(compress, srcPath+".rootfs", rootfsPath)
+		if err != nil {
+			return nil, fmt.Errorf("Failed re-compressing rootfs of image %q: %w", info.Fingerprint, err)
+		}
+
+		paths = append(paths, rootfsPath)
+	}
+
+	// Compute the new fingerprint and size.
+
+	var size int64
+	hash256 := sha256.New()
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := io.Copy(hash256, f)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		size += n
+	}
+
+	fingerprint := fmt.Sprintf("%x", hash256.Sum(nil))
+
+	// Nothing to do if the image was already using the requested compression.
+	if fingerprint == info.Fingerprint {
+		return info, nil
+	}
+
+	var srcID int
+	var newInfo api.Image
+	var profileIDs []int64
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		exists, err := tx.ImageExists(ctx, projectName, fingerprint)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return fmt.Errorf("Image with same fingerprint already exists")
+		}
+
+		// Carry over the properties and profiles recorded for the source image.
+		var srcInfo *api.Image
+		srcID, srcInfo, err = tx.GetImage(ctx, info.Fingerprint, dbCluster.ImageFilter{Project: &projectName})
+		if err != nil {
+			return err
+		}
+
+		profileIDs = make([]int64, 0, len(srcInfo.Profiles))
+		for _, profile := range srcInfo.Profiles {
+			profileID, _, err := tx.GetProfile(ctx, projectName, profile)
+			if err != nil {
+				return fmt.Errorf("Failed loading profile %q: %w", profile, err)
+			}
+
+			profileIDs = append(profileIDs, profileID)
+		}
+
+		newInfo = *srcInfo
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	newInfo.Fingerprint = fingerprint
+	newInfo.Size = size
+	newInfo.AutoUpdate = false
+	newInfo.Aliases = info.Aliases
+
+	// Move the new image files in place.
+	newPath := filepath.Join(imagesPath, newInfo.Fingerprint)
+	err = internalUtil.FileMove(metaPath, newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) > 1 {
+		err = internalUtil.FileMove(paths[1], newPath+".rootfs")
+		if err != nil {
+			imageDeleteFromDisk(newInfo.Fingerprint)
+			return nil, err
+		}
+	}
+
+	var referenced bool
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.CreateImage(ctx, projectName, newInfo.Fingerprint, newInfo.Filename, newInfo.Size, newInfo.Public, newInfo.AutoUpdate, newInfo.Architecture, newInfo.CreatedAt, newInfo.ExpiresAt, newInfo.Properties, newInfo.Type, profileIDs)
+		if err != nil {
+			return err
+		}
+
+		if !replaceSource {
+			return nil
+		}
+
+		err = tx.DeleteImage(ctx, srcID)
+		if err != nil {
+			return err
+		}
+
+		referenced, err = tx.ImageIsReferencedByOtherProjects(ctx, projectName, info.Fingerprint)
+
+		return err
+	})
+	if err != nil {
+		imageDeleteFromDisk(newInfo.Fingerprint)
+		return nil, err
+	}
+
+	if replaceSource && !referenced {
+		imageDeleteFromDisk(info.Fingerprint)
+	}
+
+	return &newInfo, nil
+}
+
 /*
  * This function takes a container or snapshot from the local image server and
  * exports it as an image.
@@ -1095,6 +1372,11 @@ func imageCreateInPool(s *state.State, info *api.Image, storagePool string) erro
 //	      type: array
 //	      items:
 //	        type: string
+//	  - in: header
+//	    name: X-Incus-compression_algorithm
+//	    description: Compression algorithm to re-compress the image with
+//	    schema:
+//	      type: string
 //	responses:
 //	  "202":
 //	    $ref: "#/responses/Operation"
@@ -1201,14 +1483,33 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 		imageUpload = true
 	}
 
+	// Check for a requested re-compression of the image.
+	compress := req.CompressionAlgorithm
+	if imageUpload {
+		compress = r.Header.Get("X-Incus-compression_algorithm")
+		if compress == "" {
+			// Used to get the compression algorithm from push mode image copy operation.
+			compress, _ = imageMetadata["compression_algorithm"].(string)
+		}
+	}
+
+	if compress != "" && (imageUpload || slices.Contains([]string{"image", "url"}, req.Source.Type)) {
+		err = validate.IsCompressionAlgorithm(compress)
+		if err != nil {
+			cleanup(builddir, post)
+			return response.BadRequest(fmt.Errorf("Invalid compression algorithm %q: %w", compress, err))
+		}
+	}
+
 	if !imageUpload && req.Source.Mode == "push" {
 		cleanup(builddir, post)
 
 		metadata := map[string]any{
-			"aliases":    req.Aliases,
-			"expires_at": req.ExpiresAt,
-			"properties": req.Properties,
-			"public":     req.Public,
+			"aliases":               req.Aliases,
+			"compression_algorithm": req.CompressionAlgorithm,
+			"expires_at":            req.ExpiresAt,
+			"properties":            req.Properties,
+			"public":                req.Public,
 		}
 
 		return createTokenResponse(s, r, projectName, req.Source.Fingerprint, metadata)
@@ -1250,10 +1551,26 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 		// Setup the cleanup function
 		defer cleanup(builddir, post)
 
+		recompress := compress != "" && !isClusterNotification(r)
+
+		// Keep track of the images already present so they're left untouched by the re-compression.
+		var existingFingerprints []string
+
 		if imageUpload {
 			/* Processing image upload */
 			info, err = getImgPostInfo(context.TODO(), s, r, builddir, projectName, post, imageMetadata)
 		} else {
+			if recompress && slices.Contains([]string{"image", "url"}, req.Source.Type) {
+				err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+					existingFingerprints, err = tx.GetImagesFingerprints(ctx, projectName, false)
+
+					return err
+				})
+				if err != nil {
+					return err
+				}
+			}
+
 			if req.Source.Type == "image" {
 				/* Processing image copy from remote */
 				info, err = imgPostRemoteInfo(context.TODO(), s, r, req, op, projectName, budget)
@@ -1265,6 +1582,17 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 				imagePublishLock.Lock()
 				info, err = imgPostInstanceInfo(context.TODO(), s, r, req, op, builddir, budget)
 				imagePublishLock.Unlock()
+
+				// The compression algorithm was already applied when packing the image.
+				recompress = false
+			}
+		}
+
+		if err == nil && recompress {
+			var newInfo *api.Image
+			newInfo, err = imageRecompress(context.TODO(), s, projectName, info, compress, !slices.Contains(existingFingerprints, info.Fingerprint))
+			if err == nil {
+				info = newInfo
 			}
 		}
 
@@ -2302,6 +2630,21 @@ func autoUpdateImage(ctx context.Context, s *state.State, op *operations.Operati
 
 	logger.Debug("Processing image", logger.Ctx{"fingerprint": fingerprint, "server": source.Server, "protocol": source.Protocol, "alias": source.Alias})
 
+	// Log what the scheduled refresh is going to change.
+	if !manual {
+		latest, err := imageSourceLatest(s, source, info.Type)
+		if err != nil {
+			logger.Warn("Unable to check image source for updates", logger.Ctx{"err": err, "fingerprint": fingerprint, "server": source.Server, "alias": source.Alias})
+		} else {
+			changes := internalUtil.ImageRefreshDiff(info, latest)
+			if changes.UpdateAvailable() {
+				logger.Info("Image update available", logger.Ctx{"fingerprint": fingerprint, "new_fingerprint": changes.NewFingerprint, "size_delta": changes.SizeDelta, "properties": changes.Properties})
+			} else {
+				logger.Debug("Image already up to date", logger.Ctx{"fingerprint": fingerprint})
+			}
+		}
+	}
+
 	// Set operation metadata to indicate whether a refresh happened
 	setRefreshResult := func(result bool) {
 		if op == nil {
@@ -3158,15 +3501,24 @@ func imageGet(d *Daemon, r *http.Request) response.Response {
 
 	// Get the image (expand partial fingerprints).
 	var info *api.Image
-	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
-		info, err = doImageGet(ctx, tx, projectName, fingerprint, false)
-		if err != nil {
-			return err
-		}
+	getImage := func() error {
+		return s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			info, err = doImageGet(ctx, tx, projectName, fingerprint, false)
+			if err != nil {
+				return err
+			}
 
-		return nil
-	})
+			return nil
+		})
+	}
+
+	err = getImage()
 	if err != nil {
+		// Fetch the image from the upstream image server in the background when mirroring one.
+		if response.IsNotFoundError(err) {
+			imageMirrorRefresh(r.Context(), s, projectName, fingerprint)
+		}
+
 		return response.SmartError(err)
 	}
 
@@ -3757,6 +4109,9 @@ func imageAliasGet(d *Daemon, r *http.Request) response.Response {
 
 	public := d.checkTrustedClient(r) != nil || !userCanViewImageAlias
 
+	// Refresh the alias from the upstream image server in the background when mirroring one.
+	imageMirrorRefresh(r.Context(), s, projectName, name)
+
 	var alias api.ImageAliasesEntry
 	err = d.State().DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		_, alias, err = tx.GetImageAlias(ctx, projectName, name, !public)
@@ -3826,6 +4181,9 @@ func imageAliasDelete(d *Daemon, r *http.Request) response.Response {
 		logger.Error("Failed to remove image alias from authorizer", logger.Ctx{"name": name, "project": projectName, "error": err})
 	}
 
+	// Let the upstream image server be checked again for the alias.
+	imageMirrorLookups.forget(projectName, name)
+
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(projectName, lifecycle.ImageAliasDeleted.Event(name, projectName, requestor, nil))
 
@@ -4176,13 +4534,22 @@ func imageExport(d *Daemon, r *http.Request) response.Response {
 
 	var imgInfo *api.Image
 
-	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
-		// Get the image (expand the fingerprint).
-		_, imgInfo, err = tx.GetImage(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName})
+	getImage := func() error {
+		return s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			// Get the image (expand the fingerprint).
+			_, imgInfo, err = tx.GetImage(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName})
 
-		return err
-	})
+			return err
+		})
+	}
+
+	err = getImage()
 	if err != nil {
+		// Fetch the image from the upstream image server in the background when mirroring one.
+		if response.IsNotFoundError(err) && r.RemoteAddr != "@dev_incus" {
+			imageMirrorRefresh(r.Context(), s, projectName, fingerprint)
+		}
+
 		return response.SmartError(err)
 	}
 
@@ -4240,6 +4607,17 @@ func imageExport(d *Daemon, r *http.Request) response.Response {
 		return response.ForwardedResponse(client, r)
 	}
 
+	// Images cached from the upstream image server are evicted based on their last use.
+	mirrorServer, _, _ := s.GlobalConfig.ImagesMirror()
+	if mirrorServer != "" && imgInfo.Cached {
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateImageLastUseDate(ctx, projectName, imgInfo.Fingerprint, time.Now().UTC())
+		})
+		if err != nil {
+			logger.Warn("Failed updating image last use date", logger.Ctx{"fingerprint": imgInfo.Fingerprint, "project": projectName, "err": err})
+		}
+	}
+
 	// Set image type header.
 	headers := map[string]string{}
 
@@ -4566,6 +4944,89 @@ func imageImportFromNode(imagesDir string, client incus.InstanceServer, fingerpr
 	return nil
 }
 
+// swagger:operation GET /1.0/images/{fingerprint}/refresh images images_refresh_get
+//
+//	Get the latest version of an image
+//
+//	Queries the image source server for the latest version of the image
+//	without refreshing the local copy.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Image
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/Image"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func imageRefreshGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	fingerprint, err := url.PathUnescape(mux.Vars(r)["fingerprint"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var info *api.Image
+	var source api.ImageSource
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var imageID int
+
+		imageID, info, err = tx.GetImage(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName})
+		if err != nil {
+			return fmt.Errorf("Failed loading image %q: %w", fingerprint, err)
+		}
+
+		_, source, err = tx.GetImageSource(ctx, imageID)
+		if err != nil {
+			return fmt.Errorf("Failed loading source of image %q: %w", fingerprint, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Errors from the image source aren't wrapped so that their status code isn't taken for the local image one.
+	latest, err := imageSourceLatest(s, source, info.Type)
+	if err != nil {
+		return response.SmartError(api.StatusErrorf(http.StatusServiceUnavailable, "Failed querying image source %q: %v", source.Server, err))
+	}
+
+	return response.SyncResponse(true, latest)
+}
+
 // swagger:operation POST /1.0/images/{fingerprint}/refresh images images_refresh_post
 //
 //	Refresh an image
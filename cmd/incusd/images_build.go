@@ -0,0 +1,750 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/auth"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	projectutils "github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/internal/server/request"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/osarch"
+	"github.com/lxc/incus/v6/shared/validate"
+)
+
+// imageBuildCacheKey is the image property holding the cache key of the build step an image was published at.
+const imageBuildCacheKey = "build.cache_key"
+
+// imageBuildArgRegex matches references to build arguments in the recipe steps.
+var imageBuildArgRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+var imageBuildCmd = APIEndpoint{
+	Path: "images/build",
+
+	Post: APIEndpointAction{Handler: imageBuildPost, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateImages)},
+}
+
+// swagger:operation POST /1.0/images/build images images_build_post
+//
+//	Build an image
+//
+//	Builds a new image from a recipe. A temporary builder instance is created from the base image, the recipe
+//	steps are run in it and the result is published as a new image. The builder instance is always deleted.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: build
+//	    description: Image build request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ImageBuildPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func imageBuildPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	// Instances get created in the process, so check that too.
+	err := s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectProject(projectName), auth.EntitlementCanCreateInstances)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.ImageBuildPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = imageBuildValidate(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if s.ServerClustered && s.DB.Cluster.LocalNodeIsEvacuated() {
+		return response.Forbidden(errors.New("Cluster member is evacuated"))
+	}
+
+	run := func(op *operations.Operation) error {
+		info, err := imageBuild(s, r, op, projectName, &req)
+		if err != nil {
+			return err
+		}
+
+		return op.UpdateMetadata(map[string]any{"fingerprint": info.Fingerprint, "size": strconv.FormatInt(info.Size, 10)})
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.ImageBuild, nil, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// imageBuildValidate checks the build request, filling in the defaults and replacing the build arguments in the
+// recipe steps.
+func imageBuildValidate(req *api.ImageBuildPost) error {
+	recipe := &req.Recipe
+
+	if recipe.Source.Type == "" {
+		recipe.Source.Type = "image"
+	}
+
+	if recipe.Source.Type != "image" {
+		return fmt.Errorf("Invalid base image source type %q", recipe.Source.Type)
+	}
+
+	if recipe.Source.Alias == "" && recipe.Source.Fingerprint == "" && recipe.Source.Properties == nil {
+		return errors.New("No base image provided")
+	}
+
+	if recipe.Type == "" {
+		recipe.Type = api.InstanceTypeContainer
+	}
+
+	_, err := instancetype.New(string(recipe.Type))
+	if err != nil {
+		return err
+	}
+
+	if req.CompressionAlgorithm != "" {
+		err = validate.IsCompressionAlgorithm(req.CompressionAlgorithm)
+		if err != nil {
+			return fmt.Errorf("Invalid compression algorithm %q: %w", req.CompressionAlgorithm, err)
+		}
+	}
+
+	if len(recipe.Steps) == 0 {
+		return errors.New("The recipe doesn't have any steps")
+	}
+
+	// Resolve the build arguments.
+	args := map[string]string{}
+	for name, value := range recipe.Args {
+		args[name] = value
+	}
+
+	for name, value := range req.Args {
+		_, found := args[name]
+		if !found {
+			return fmt.Errorf("Build argument %q isn't declared by the recipe", name)
+		}
+
+		args[name] = value
+	}
+
+	expand := func(value string) string {
+		return imageBuildArgRegex.ReplaceAllStringFunc(value, func(match string) string {
+			value, found := args[match[2:len(match)-1]]
+			if !found {
+				return match
+			}
+
+			return value
+		})
+	}
+
+	for i := range recipe.Steps {
+		step := &recipe.Steps[i]
+
+		if (len(step.Exec) > 0) == (step.File != nil) {
+			return fmt.Errorf("Step %d must either run a command or create a file", i+1)
+		}
+
+		for j := range step.Exec {
+			step.Exec[j] = expand(step.Exec[j])
+		}
+
+		for k, v := range step.Environment {
+			step.Environment[k] = expand(v)
+		}
+
+		step.Cwd = expand(step.Cwd)
+
+		if step.File != nil {
+			step.File.Path = expand(step.File.Path)
+			step.File.Content = expand(step.File.Content)
+
+			if !filepath.IsAbs(step.File.Path) {
+				return fmt.Errorf("Step %d: File path %q must be absolute", i+1, step.File.Path)
+			}
+
+			if step.File.Mode != "" {
+				_, err := strconv.ParseUint(step.File.Mode, 8, 32)
+				if err != nil {
+					return fmt.Errorf("Step %d: Invalid file mode %q", i+1, step.File.Mode)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// imageBuildStepKeys returns the cache key of each step of the recipe, derived from the base image and everything
+// affecting the state of the builder up to that step.
+func imageBuildStepKeys(req *api.ImageBuildPost, baseFingerprint string) ([]string, error) {
+	hash := sha256.New()
+
+	data, err := json.Marshal([]any{baseFingerprint, req.Recipe.Type, req.Recipe.Profiles, req.Recipe.Config})
+	if err != nil {
+		return nil, err
+	}
+
+	_, _ = hash.Write(data)
+
+	keys := make([]string, 0, len(req.Recipe.Steps))
+	for _, step := range req.Recipe.Steps {
+		data, err := json.Marshal(step)
+		if err != nil {
+			return nil, err
+		}
+
+		_, _ = hash.Write(data)
+		keys = append(keys, fmt.Sprintf("%x", hash.Sum(nil)))
+	}
+
+	// The resulting image also depends on its own settings.
+	data, err = json.Marshal([]any{req.Recipe.Properties, req.Public})
+	if err != nil {
+		return nil, err
+	}
+
+	_, _ = hash.Write(data)
+	keys[len(keys)-1] = fmt.Sprintf("%x", hash.Sum(nil))
+
+	return keys, nil
+}
+
+// imageBuildFindCached returns the image of the project published at the latest of the given build steps, along
+// with the index of that step. A nil image is returned when no step was cached.
+func imageBuildFindCached(ctx context.Context, s *state.State, projectName string, keys []string) (*api.Image, int, error) {
+	var cached *api.Image
+	step := -1
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		fingerprints, err := tx.GetImagesFingerprintsByProperty(ctx, projectName, imageBuildCacheKey)
+		if err != nil {
+			return err
+		}
+
+		for i := len(keys) - 1; i >= 0; i-- {
+			fingerprint, found := fingerprints[keys[i]]
+			if !found {
+				continue
+			}
+
+			_, cached, err = tx.GetImageByFingerprintPrefix(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName})
+			if err != nil {
+				return err
+			}
+
+			step = i
+
+			return nil
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, -1, err
+	}
+
+	return cached, step, nil
+}
+
+// imageBuild builds the image described by the request and returns it.
+func imageBuild(s *state.State, r *http.Request, op *operations.Operation, projectName string, req *api.ImageBuildPost) (*api.Image, error) {
+	ctx := op.Context()
+
+	var p *api.Project
+	var profiles []api.Profile
+	var baseImage *api.Image
+	var baseImageRef string
+	var budget int64
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return fmt.Errorf("Failed loading project: %w", err)
+		}
+
+		p, err = dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		budget, err = projectutils.GetImageSpaceBudget(tx, projectName)
+		if err != nil {
+			return err
+		}
+
+		baseImage, err = getSourceImageFromInstanceSource(ctx, s, tx, projectName, req.Recipe.Source, &baseImageRef, string(req.Recipe.Type))
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		profileNames := req.Recipe.Profiles
+		if profileNames == nil {
+			profileNames = []string{"default"}
+		}
+
+		profileProject := projectutils.ProfileProjectFromRecord(p)
+		for _, profileName := range profileNames {
+			profile, err := dbCluster.GetProfile(ctx, tx.Tx(), profileProject, profileName)
+			if err != nil {
+				return fmt.Errorf("Failed loading profile %q: %w", profileName, err)
+			}
+
+			apiProfile, err := profile.ToAPI(ctx, tx.Tx(), nil, nil)
+			if err != nil {
+				return err
+			}
+
+			profiles = append(profiles, *apiProfile)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the base image.
+	_ = op.UpdateMetadata(map[string]any{"build_step": "Retrieving the base image"})
+
+	if req.Recipe.Source.Server != "" {
+		baseImage, err = ensureDownloadedImageFitWithinBudget(ctx, s, r, op, *p, baseImageRef, req.Recipe.Source, string(req.Recipe.Type))
+		if err != nil {
+			return nil, err
+		}
+	} else if baseImage == nil {
+		return nil, fmt.Errorf("Base image %q not found", baseImageRef)
+	}
+
+	keys, err := imageBuildStepKeys(req, baseImage.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start from the last cached step, if any.
+	firstStep := 0
+	if !req.NoCache {
+		cached, step, err := imageBuildFindCached(ctx, s, projectName, keys)
+		if err != nil {
+			return nil, err
+		}
+
+		if cached != nil {
+			baseImage = cached
+			firstStep = step + 1
+		}
+	}
+
+	var info *api.Image
+	if firstStep == len(keys) {
+		// The same image was already built.
+		info = baseImage
+	} else {
+		info, err = imageBuildRun(s, r, op, p, profiles, baseImage, req, keys, firstStep, budget)
+		if err != nil {
+			return nil, err
+		}
+
+		// Sync the image between each member in the cluster on demand.
+		err = imageSyncBetweenNodes(ctx, s, r, projectName, info.Fingerprint)
+		if err != nil {
+			return nil, fmt.Errorf("Failed syncing image between servers: %w", err)
+		}
+	}
+
+	// Point the aliases to the new image.
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		imgID, _, err := tx.GetImageByFingerprintPrefix(ctx, info.Fingerprint, dbCluster.ImageFilter{Project: &projectName})
+		if err != nil {
+			return fmt.Errorf("Fetch image %q: %w", info.Fingerprint, err)
+		}
+
+		for _, alias := range req.Aliases {
+			aliasID, _, err := tx.GetImageAlias(ctx, projectName, alias.Name, true)
+			if err == nil {
+				err = tx.UpdateImageAlias(ctx, aliasID, imgID, alias.Description)
+				if err != nil {
+					return fmt.Errorf("Update image alias %q: %w", alias.Name, err)
+				}
+
+				s.Events.SendLifecycle(projectName, lifecycle.ImageAliasUpdated.Event(alias.Name, projectName, op.Requestor(), logger.Ctx{"target": info.Fingerprint}))
+
+				continue
+			} else if !response.IsNotFoundError(err) {
+				return fmt.Errorf("Fetch image alias %q: %w", alias.Name, err)
+			}
+
+			err = tx.CreateImageAlias(ctx, projectName, alias.Name, imgID, alias.Description)
+			if err != nil {
+				return fmt.Errorf("Add new image alias to the database: %w", err)
+			}
+
+			// Add the image alias to the authorizer.
+			err = s.Authorizer.AddImageAlias(ctx, projectName, alias.Name)
+			if err != nil {
+				logger.Error("Failed to add image alias to authorizer", logger.Ctx{"name": alias.Name, "project": projectName, "error": err})
+			}
+
+			s.Events.SendLifecycle(projectName, lifecycle.ImageAliasCreated.Event(alias.Name, projectName, op.Requestor(), logger.Ctx{"target": info.Fingerprint}))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// imageBuildRun creates the builder instance from the base image, runs the remaining recipe steps in it and
+// publishes the result. The builder instance is always deleted.
+func imageBuildRun(s *state.State, r *http.Request, op *operations.Operation, p *api.Project, profiles []api.Profile, baseImage *api.Image, req *api.ImageBuildPost, keys []string, firstStep int, budget int64) (*api.Image, error) {
+	ctx := op.Context()
+
+	// Create a directory under which we keep everything while building.
+	builddir, err := os.MkdirTemp(internalUtil.VarPath("images"), "incus_build_")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = os.RemoveAll(builddir) }()
+
+	suffix, err := internalUtil.RandomHexString(8)
+	if err != nil {
+		return nil, err
+	}
+
+	instName := "build-" + suffix
+	instType, err := instancetype.New(string(req.Recipe.Type))
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]string{}
+	for k, v := range req.Recipe.Config {
+		config[k] = v
+	}
+
+	args := db.InstanceArgs{
+		Project:     p.Name,
+		Config:      config,
+		Type:        instType,
+		Description: "Image builder",
+		Devices:     deviceConfig.ApplyDeviceInitialValues(deviceConfig.Devices{}, profiles),
+		Name:        instName,
+		Profiles:    profiles,
+	}
+
+	args.Architecture, err = osarch.ArchitectureID(baseImage.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		profileNames := make([]string, 0, len(profiles))
+		for _, profile := range profiles {
+			profileNames = append(profileNames, profile.Name)
+		}
+
+		return projectutils.AllowInstanceCreation(tx, p.Name, api.InstancesPost{
+			Name:        instName,
+			Type:        req.Recipe.Type,
+			InstancePut: api.InstancePut{Config: config, Profiles: profileNames},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = op.UpdateMetadata(map[string]any{"build_step": "Creating the builder instance"})
+
+	err = ensureImageIsLocallyAvailable(ctx, s, r, baseImage, p.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the created instance directly so that it can always be cleaned up.
+	inst, err := instanceCreateFromImage(ctx, s, baseImage, args, op)
+	if err != nil {
+		return nil, err
+	}
+
+	// Always get rid of the builder instance.
+	defer func() {
+		if inst.IsRunning() {
+			_ = inst.Stop(false)
+		}
+
+		err := inst.Delete(true)
+		if err != nil {
+			logger.Warn("Failed deleting image builder instance", logger.Ctx{"project": p.Name, "instance": instName, "err": err})
+		}
+	}()
+
+	// publish stops the builder and publishes it as an image tagged with the cache key of the given step.
+	publish := func(step int, cached bool) (*api.Image, error) {
+		err := imageBuildStop(inst)
+		if err != nil {
+			return nil, err
+		}
+
+		properties := map[string]string{}
+		for k, v := range req.Recipe.Properties {
+			properties[k] = v
+		}
+
+		properties[imageBuildCacheKey] = keys[step]
+
+		imagePublishLock.Lock()
+		info, err := imgPostInstanceInfo(ctx, s, r, api.ImagesPost{
+			ImagePut:             api.ImagePut{Properties: properties, Public: req.Public && !cached},
+			Source:               &api.ImagesPostSource{Type: "instance", Name: instName},
+			CompressionAlgorithm: req.CompressionAlgorithm,
+		}, op, builddir, budget)
+		imagePublishLock.Unlock()
+		if err != nil {
+			return nil, fmt.Errorf("Failed publishing image: %w", err)
+		}
+
+		// Let intermediate images expire like other cached images.
+		if cached {
+			err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.SetImageCachedAndLastUseDate(ctx, p.Name, info.Fingerprint, time.Now().UTC())
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Add the image to the authorizer.
+		err = s.Authorizer.AddImage(s.ShutdownCtx, p.Name, info.Fingerprint)
+		if err != nil {
+			logger.Error("Failed to add image to authorizer", logger.Ctx{"fingerprint": info.Fingerprint, "project": p.Name, "error": err})
+		}
+
+		s.Events.SendLifecycle(p.Name, lifecycle.ImageCreated.Event(info.Fingerprint, p.Name, op.Requestor(), logger.Ctx{"type": info.Type}))
+
+		return info, nil
+	}
+
+	for i := firstStep; i < len(req.Recipe.Steps); i++ {
+		step := req.Recipe.Steps[i]
+
+		if !inst.IsRunning() {
+			inst.SetOperation(op)
+
+			err = inst.Start(false)
+			if err != nil {
+				return nil, fmt.Errorf("Failed starting builder instance: %w", err)
+			}
+		}
+
+		description := fmt.Sprintf("Step %d/%d", i+1, len(req.Recipe.Steps))
+		if step.File != nil {
+			description += ": File " + step.File.Path
+		} else {
+			description += ": Exec " + strings.Join(step.Exec, " ")
+		}
+
+		_ = op.UpdateMetadata(map[string]any{"build_step": description})
+
+		if step.File != nil {
+			err = imageBuildFile(inst, step.File)
+		} else {
+			err = imageBuildExec(ctx, inst, step, builddir)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Step %d failed: %w", i+1, err)
+		}
+
+		// Cache the intermediate state if requested, the final one is always kept.
+		if step.Cache && i < len(req.Recipe.Steps)-1 {
+			_, err = publish(i, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	_ = op.UpdateMetadata(map[string]any{"build_step": "Publishing the image"})
+
+	return publish(len(keys)-1, false)
+}
+
+// imageBuildStop cleanly stops the builder instance, forcefully if it doesn't shut down in time.
+func imageBuildStop(inst instance.Instance) error {
+	if !inst.IsRunning() {
+		return nil
+	}
+
+	err := inst.Shutdown(time.Minute)
+	if err == nil {
+		return nil
+	}
+
+	return inst.Stop(false)
+}
+
+// imageBuildExec runs a command in the builder instance, failing if it doesn't exit successfully.
+func imageBuildExec(ctx context.Context, inst instance.Instance, step api.ImageBuildStep, builddir string) error {
+	output, err := os.CreateTemp(builddir, "incus_build_output_")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = output.Close() }()
+
+	req := api.InstanceExecPost{
+		Command:     step.Exec,
+		Environment: step.Environment,
+		Cwd:         step.Cwd,
+	}
+
+	// Virtual machines need their agent to be running, so wait for it.
+	var cmd instance.Cmd
+	deadline := time.Now().Add(5 * time.Minute)
+	for {
+		cmd, err = inst.Exec(req, nil, output, output)
+		if err == nil || inst.Type() != instancetype.VM {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Builder instance agent isn't running: %w", err)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	exitStatus, err := cmd.Wait()
+	if err != nil {
+		return err
+	}
+
+	if exitStatus != 0 {
+		// Report the end of the output to help figuring out the failure.
+		tail := ""
+		fi, err := output.Stat()
+		if err == nil {
+			offset := max(fi.Size()-1024, 0)
+			data := make([]byte, fi.Size()-offset)
+			_, err = output.ReadAt(data, offset)
+			if err == nil || errors.Is(err, io.EOF) {
+				tail = strings.TrimSpace(string(data))
+			}
+		}
+
+		if tail != "" {
+			return fmt.Errorf("Command exited with status %d: %s", exitStatus, tail)
+		}
+
+		return fmt.Errorf("Command exited with status %d", exitStatus)
+	}
+
+	return nil
+}
+
+// imageBuildFile creates a file in the builder instance.
+func imageBuildFile(inst instance.Instance, file *api.ImageBuildFile) error {
+	client, err := inst.FileSFTP()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = client.Close() }()
+
+	mode := uint64(0o644)
+	if file.Mode != "" {
+		mode, err = strconv.ParseUint(file.Mode, 8, 32)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.MkdirAll(filepath.Dir(file.Path))
+	if err != nil {
+		return err
+	}
+
+	f, err := client.OpenFile(file.Path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	_, err = f.Write([]byte(file.Content))
+	if err != nil {
+		return err
+	}
+
+	err = f.Chmod(os.FileMode(mode))
+	if err != nil {
+		return err
+	}
+
+	err = f.Chown(int(file.UID), int(file.GID))
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestImageBuildValidate(t *testing.T) {
+	req := api.ImageBuildPost{
+		Recipe: api.ImageBuildRecipe{
+			Source: api.InstanceSource{Alias: "debian/12"},
+			Args:   map[string]string{"PKG": "curl", "DIR": "/srv"},
+			Steps: []api.ImageBuildStep{
+				{Exec: []string{"apt-get", "install", "-y", "${PKG}"}, Environment: map[string]string{"TARGET": "${DIR}/app"}, Cwd: "${DIR}"},
+				{File: &api.ImageBuildFile{Path: "${DIR}/motd", Content: "Built with ${PKG} and ${UNKNOWN}", Mode: "0644"}},
+			},
+		},
+		Args: map[string]string{"PKG": "wget"},
+	}
+
+	require.NoError(t, imageBuildValidate(&req))
+
+	// Defaults are filled in.
+	assert.Equal(t, "image", req.Recipe.Source.Type)
+	assert.Equal(t, api.InstanceTypeContainer, req.Recipe.Type)
+
+	// Request arguments override the recipe ones and undeclared references are left alone.
+	assert.Equal(t, []string{"apt-get", "install", "-y", "wget"}, req.Recipe.Steps[0].Exec)
+	assert.Equal(t, map[string]string{"TARGET": "/srv/app"}, req.Recipe.Steps[0].Environment)
+	assert.Equal(t, "/srv", req.Recipe.Steps[0].Cwd)
+	assert.Equal(t, "/srv/motd", req.Recipe.Steps[1].File.Path)
+	assert.Equal(t, "Built with wget and ${UNKNOWN}", req.Recipe.Steps[1].File.Content)
+
+	tests := []struct {
+		name string
+		req  api.ImageBuildPost
+	}{
+		{"Invalid source type", api.ImageBuildPost{Recipe: api.ImageBuildRecipe{Source: api.InstanceSource{Type: "copy", Alias: "debian/12"}, Steps: []api.ImageBuildStep{{Exec: []string{"true"}}}}}},
+		{"No base image", api.ImageBuildPost{Recipe: api.ImageBuildRecipe{Steps: []api.ImageBuildStep{{Exec: []string{"true"}}}}}},
+		{"Invalid instance type", api.ImageBuildPost{Recipe: api.ImageBuildRecipe{Source: api.InstanceSource{Alias: "debian/12"}, Type: "invalid", Steps: []api.ImageBuildStep{{Exec: []string{"true"}}}}}},
+		{"Invalid compression algorithm", api.ImageBuildPost{Recipe: api.ImageBuildRecipe{Source: api.InstanceSource{Alias: "debian/12"}, Steps: []api.ImageBuildStep{{Exec: []string{"true"}}}}, CompressionAlgorithm: "invalid"}},
+		{"No steps", api.ImageBuildPost{Recipe: api.ImageBuildRecipe{Source: api.InstanceSource{Alias: "debian/12"}}}},
+		{"Undeclared argument", api.ImageBuildPost{Recipe: api.ImageBuildRecipe{Source: api.InstanceSource{Alias: "debian/12"}, Steps: []api.ImageBuildStep{{Exec: []string{"true"}}}}, Args: map[string]string{"PKG": "curl"}}},
+		{"Empty step", api.ImageBuildPost{Recipe: api.ImageBuildRecipe{Source: api.InstanceSource{Alias: "debian/12"}, Steps: []api.ImageBuildStep{{}}}}},
+		{"Step with command and file", api.ImageBuildPost{Recipe: api.ImageBuildRecipe{Source: api.InstanceSource{Alias: "debian/12"}, Steps: []api.ImageBuildStep{{Exec: []string{"true"}, File: &api.ImageBuildFile{Path: "/etc/motd"}}}}}},
+		{"Relative file path", api.ImageBuildPost{Recipe: api.ImageBuildRecipe{Source: api.InstanceSource{Alias: "debian/12"}, Steps: []api.ImageBuildStep{{File: &api.ImageBuildFile{Path: "etc/motd"}}}}}},
+		{"Invalid file mode", api.ImageBuildPost{Recipe: api.ImageBuildRecipe{Source: api.InstanceSource{Alias: "debian/12"}, Steps: []api.ImageBuildStep{{File: &api.ImageBuildFile{Path: "/etc/motd", Mode: "0999"}}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, imageBuildValidate(&tt.req))
+		})
+	}
+}
+
+func TestImageBuildStepKeys(t *testing.T) {
+	newReq := func() *api.ImageBuildPost {
+		return &api.ImageBuildPost{
+			Recipe: api.ImageBuildRecipe{
+				Type: api.InstanceTypeContainer,
+				Steps: []api.ImageBuildStep{
+					{Exec: []string{"apt-get", "update"}},
+					{Exec: []string{"apt-get", "install", "-y", "curl"}},
+					{File: &api.ImageBuildFile{Path: "/etc/motd", Content: "hello"}},
+				},
+			},
+		}
+	}
+
+	keys, err := imageBuildStepKeys(newReq(), "abc")
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+	assert.NotEqual(t, keys[0], keys[1])
+	assert.NotEqual(t, keys[1], keys[2])
+
+	// Keys are stable.
+	same, err := imageBuildStepKeys(newReq(), "abc")
+	require.NoError(t, err)
+	assert.Equal(t, keys, same)
+
+	// A different base image invalidates every step.
+	other, err := imageBuildStepKeys(newReq(), "def")
+	require.NoError(t, err)
+	for i := range keys {
+		assert.NotEqual(t, keys[i], other[i])
+	}
+
+	// Changing a step only invalidates it and the following ones.
+	req := newReq()
+	req.Recipe.Steps[1].Exec = []string{"apt-get", "install", "-y", "wget"}
+	other, err = imageBuildStepKeys(req, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, keys[0], other[0])
+	assert.NotEqual(t, keys[1], other[1])
+	assert.NotEqual(t, keys[2], other[2])
+
+	// The image settings only affect the final step.
+	req = newReq()
+	req.Recipe.Properties = map[string]string{"os": "Debian"}
+	other, err = imageBuildStepKeys(req, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, keys[:2], other[:2])
+	assert.NotEqual(t, keys[2], other[2])
+
+	req = newReq()
+	req.Public = true
+	other, err = imageBuildStepKeys(req, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, keys[:2], other[:2])
+	assert.NotEqual(t, keys[2], other[2])
+
+	// The builder configuration affects every step.
+	req = newReq()
+	req.Recipe.Config = map[string]string{"limits.memory": "1GiB"}
+	other, err = imageBuildStepKeys(req, "abc")
+	require.NoError(t, err)
+	for i := range keys {
+		assert.NotEqual(t, keys[i], other[i])
+	}
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// imageMirrorLookupTTL is how long the result of checking the upstream image server for a name is reused for.
+const imageMirrorLookupTTL = 5 * time.Minute
+
+// imageMirrorLookups records when names were last checked against the upstream image server.
+var imageMirrorLookups = &imageMirrorLookupCache{}
+
+// imageMirrorLookupKey identifies a name looked up against an upstream image server.
+type imageMirrorLookupKey struct {
+	server  string
+	project string
+	name    string
+}
+
+// imageMirrorLookupCache tracks recent upstream lookups so that repeated requests for the same name don't each
+// contact the upstream image server. Failed lookups are recorded too.
+type imageMirrorLookupCache struct {
+	mu      sync.Mutex
+	entries map[imageMirrorLookupKey]time.Time
+}
+
+// claim returns whether a lookup for the key is due, recording one at the given time if so.
+func (c *imageMirrorLookupCache) claim(key imageMirrorLookupKey, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[imageMirrorLookupKey]time.Time{}
+	}
+
+	last, ok := c.entries[key]
+	if ok && now.Sub(last) < imageMirrorLookupTTL {
+		return false
+	}
+
+	// Drop expired entries to keep the cache bounded.
+	for k, t := range c.entries {
+		if now.Sub(t) >= imageMirrorLookupTTL {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = now
+
+	return true
+}
+
+// forget makes the next lookup of the name in the project due right away.
+func (c *imageMirrorLookupCache) forget(projectName string, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k := range c.entries {
+		if k.project == projectName && k.name == name {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// imageMirrorFetch makes sure the image referred to by the given alias or fingerprint gets available in the
+// project, fetching it in the background from the upstream image server set in images.mirror.server if it's missing
+// or if the upstream alias now points to a different image. The fetch is a server owned operation which isn't waited
+// for, the image or alias showing up once it completes. It returns a nil operation when no upstream image server is
+// configured, when the name is a local alias or when the name was already checked within imageMirrorLookupTTL.
+// Deleting the local alias makes the next lookup due right away.
+func imageMirrorFetch(ctx context.Context, s *state.State, projectName string, name string) (*operations.Operation, error) {
+	server, protocol, certificate := s.GlobalConfig.ImagesMirror()
+	if server == "" {
+		return nil, nil
+	}
+
+	var localAlias bool
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		_, alias, err := tx.GetImageAlias(ctx, projectName, name, true)
+		if err != nil {
+			if response.IsNotFoundError(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		imageID, _, err := tx.GetImage(ctx, alias.Target, dbCluster.ImageFilter{Project: &projectName})
+		if err != nil {
+			return err
+		}
+
+		_, source, err := tx.GetImageSource(ctx, imageID)
+		if err != nil && !response.IsNotFoundError(err) {
+			return err
+		}
+
+		localAlias = source.Server != server
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Aliases which weren't created from the upstream image server take precedence.
+	if localAlias {
+		return nil, nil
+	}
+
+	// Repeated requests for the same name, including anonymous ones, only contact the upstream image server once.
+	if !imageMirrorLookups.claim(imageMirrorLookupKey{server: server, project: projectName, name: name}, time.Now()) {
+		return nil, nil
+	}
+
+	run := func(op *operations.Operation) error {
+		return imageMirrorDownload(s.ShutdownCtx, s, op, projectName, name, server, protocol, certificate)
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.ImageDownload, nil, nil, run, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = op.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// imageMirrorDownload fetches the image referred to by the given alias or fingerprint from the upstream image
+// server and points the local alias at it.
+func imageMirrorDownload(ctx context.Context, s *state.State, op *operations.Operation, projectName string, name string, server string, protocol string, certificate string) error {
+	info, created, err := ImageDownload(ctx, nil, s, op, &ImageDownloadArgs{
+		ProjectName: projectName,
+		Server:      server,
+		Protocol:    protocol,
+		Certificate: certificate,
+		Alias:       name,
+		SetCached:   true,
+		AutoUpdate:  true,
+		Public:      true,
+	})
+	if err != nil {
+		return err
+	}
+
+	if created {
+		logger.Info("Cached image from upstream image server", logger.Ctx{"fingerprint": info.Fingerprint, "project": projectName, "server": server})
+
+		err = s.Authorizer.AddImage(s.ShutdownCtx, projectName, info.Fingerprint)
+		if err != nil {
+			logger.Error("Failed to add image to authorizer", logger.Ctx{"fingerprint": info.Fingerprint, "project": projectName, "error": err})
+		}
+
+		s.Events.SendLifecycle(projectName, lifecycle.ImageCreated.Event(info.Fingerprint, projectName, op.Requestor(), logger.Ctx{"type": info.Type}))
+
+		err = imageMirrorEvict(ctx, s, info.Fingerprint)
+		if err != nil {
+			logger.Warn("Failed evicting images cached from upstream image server", logger.Ctx{"server": server, "err": err})
+		}
+	}
+
+	var aliasCreated, aliasUpdated bool
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		imageID, _, err := tx.GetImage(ctx, info.Fingerprint, dbCluster.ImageFilter{Project: &projectName})
+		if err != nil {
+			return err
+		}
+
+		// Record the cache hit.
+		err = tx.UpdateImageLastUseDate(ctx, projectName, info.Fingerprint, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		// Requested by fingerprint, no alias to track.
+		if strings.HasPrefix(info.Fingerprint, name) {
+			return nil
+		}
+
+		// Point the local alias at the image the upstream alias currently refers to.
+		aliasID, alias, err := tx.GetImageAlias(ctx, projectName, name, true)
+		if err != nil {
+			if !response.IsNotFoundError(err) {
+				return err
+			}
+
+			aliasCreated = true
+
+			return tx.CreateImageAlias(ctx, projectName, name, imageID, "")
+		}
+
+		if alias.Target == info.Fingerprint {
+			return nil
+		}
+
+		aliasUpdated = true
+
+		return tx.UpdateImageAlias(ctx, aliasID, imageID, alias.Description)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed recording image cached from upstream image server: %w", err)
+	}
+
+	if aliasCreated {
+		err = s.Authorizer.AddImageAlias(s.ShutdownCtx, projectName, name)
+		if err != nil {
+			logger.Error("Failed to add image alias to authorizer", logger.Ctx{"name": name, "project": projectName, "error": err})
+		}
+
+		s.Events.SendLifecycle(projectName, lifecycle.ImageAliasCreated.Event(name, projectName, op.Requestor(), logger.Ctx{"target": info.Fingerprint}))
+	} else if aliasUpdated {
+		s.Events.SendLifecycle(projectName, lifecycle.ImageAliasUpdated.Event(name, projectName, op.Requestor(), logger.Ctx{"target": info.Fingerprint}))
+	}
+
+	return nil
+}
+
+// imageMirrorRefresh calls imageMirrorFetch, logging any failure to start the fetch.
+func imageMirrorRefresh(ctx context.Context, s *state.State, projectName string, name string) {
+	_, err := imageMirrorFetch(ctx, s, projectName, name)
+	if err != nil {
+		logger.Warn("Failed fetching image from upstream image server", logger.Ctx{"name": name, "project": projectName, "err": err})
+	}
+}
+
+// imageMirrorEvict removes the least recently used images cached from the upstream image server until their total
+// size fits within images.mirror.cache_size. The image with the given fingerprint is always kept.
+func imageMirrorEvict(ctx context.Context, s *state.State, keep string) error {
+	limit := s.GlobalConfig.ImagesMirrorCacheSize()
+	if limit <= 0 {
+		return nil
+	}
+
+	server, _, _ := s.GlobalConfig.ImagesMirror()
+
+	var images []dbCluster.Image
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		cached := true
+		cachedImages, err := dbCluster.GetImages(ctx, tx.Tx(), dbCluster.ImageFilter{Cached: &cached})
+		if err != nil {
+			return fmt.Errorf("Failed getting images: %w", err)
+		}
+
+		for _, image := range cachedImages {
+			_, source, err := tx.GetImageSource(ctx, image.ID)
+			if err != nil {
+				if response.IsNotFoundError(err) {
+					continue
+				}
+
+				return err
+			}
+
+			if source.Server == server {
+				images = append(images, image)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, image := range imageMirrorEvictionCandidates(images, limit, keep) {
+		err = imageMirrorDelete(ctx, s, image)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// imageMirrorEvictionCandidates returns the images to remove, least recently used first, for the total size of the
+// remaining ones to fit within the limit. The image with the keep fingerprint is never returned.
+func imageMirrorEvictionCandidates(images []dbCluster.Image, limit int64, keep string) []dbCluster.Image {
+	var total int64
+	for _, image := range images {
+		total += image.Size
+	}
+
+	if total <= limit {
+		return nil
+	}
+
+	lastUse := func(image dbCluster.Image) time.Time {
+		if image.LastUseDate.Valid && !image.LastUseDate.Time.IsZero() {
+			return image.LastUseDate.Time
+		}
+
+		return image.UploadDate
+	}
+
+	sorted := slices.Clone(images)
+	slices.SortStableFunc(sorted, func(a dbCluster.Image, b dbCluster.Image) int {
+		return lastUse(a).Compare(lastUse(b))
+	})
+
+	var evict []dbCluster.Image
+	for _, image := range sorted {
+		if total <= limit {
+			break
+		}
+
+		if image.Fingerprint == keep {
+			continue
+		}
+
+		evict = append(evict, image)
+		total -= image.Size
+	}
+
+	return evict
+}
+
+// imageMirrorDelete removes an image cached from the upstream image server from its project, along with its files
+// and storage volumes when no other project references it.
+func imageMirrorDelete(ctx context.Context, s *state.State, image dbCluster.Image) error {
+	unlock, err := imageOperationLock(ctx, image.Fingerprint)
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	var info *api.Image
+	var referenced bool
+	var poolNames []string
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		_, info, err = tx.GetImage(ctx, image.Fingerprint, dbCluster.ImageFilter{Project: &image.Project})
+		if err != nil {
+			return err
+		}
+
+		referenced, err = tx.ImageIsReferencedByOtherProjects(ctx, image.Project, image.Fingerprint)
+		if err != nil {
+			return err
+		}
+
+		if !referenced {
+			poolIDs, err := tx.GetPoolsWithImage(ctx, image.Fingerprint)
+			if err != nil {
+				return err
+			}
+
+			poolNames, err = tx.GetPoolNamesFromIDs(ctx, poolIDs)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.DeleteImage(ctx, image.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed deleting image %q in project %q from database: %w", image.Fingerprint, image.Project, err)
+	}
+
+	for _, alias := range info.Aliases {
+		err = s.Authorizer.DeleteImageAlias(s.ShutdownCtx, image.Project, alias.Name)
+		if err != nil {
+			logger.Error("Failed to remove image alias from authorizer", logger.Ctx{"name": alias.Name, "project": image.Project, "error": err})
+		}
+
+		s.Events.SendLifecycle(image.Project, lifecycle.ImageAliasDeleted.Event(alias.Name, image.Project, nil, nil))
+	}
+
+	err = s.Authorizer.DeleteImage(s.ShutdownCtx, image.Project, image.Fingerprint)
+	if err != nil {
+		logger.Error("Failed to remove image from authorizer", logger.Ctx{"fingerprint": image.Fingerprint, "project": image.Project, "error": err})
+	}
+
+	s.Events.SendLifecycle(image.Project, lifecycle.ImageDeleted.Event(image.Fingerprint, image.Project, nil, nil))
+
+	for _, poolName := range poolNames {
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			return fmt.Errorf("Error loading storage pool %q to delete image volume %q: %w", poolName, image.Fingerprint, err)
+		}
+
+		err = pool.DeleteImage(image.Fingerprint, nil)
+		if err != nil {
+			return fmt.Errorf("Error deleting image volume %q from storage pool %q: %w", image.Fingerprint, pool.Name(), err)
+		}
+	}
+
+	if !referenced {
+		imageDeleteFromDisk(image.Fingerprint)
+	}
+
+	logger.Info("Evicted image cached from upstream image server", logger.Ctx{"fingerprint": image.Fingerprint, "project": image.Project})
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+)
+
+func TestImageMirrorEvictionCandidates(t *testing.T) {
+	now := time.Now()
+	images := []dbCluster.Image{
+		{Fingerprint: "recent", Size: 100, LastUseDate: sql.NullTime{Time: now, Valid: true}},
+		{Fingerprint: "oldest", Size: 100, LastUseDate: sql.NullTime{Time: now.Add(-3 * time.Hour), Valid: true}},
+		{Fingerprint: "unused", Size: 100, UploadDate: now.Add(-2 * time.Hour)},
+		{Fingerprint: "older", Size: 100, LastUseDate: sql.NullTime{Time: now.Add(-time.Hour), Valid: true}},
+	}
+
+	fingerprints := func(images []dbCluster.Image) []string {
+		var result []string
+		for _, image := range images {
+			result = append(result, image.Fingerprint)
+		}
+
+		return result
+	}
+
+	// Everything fits.
+	assert.Empty(t, imageMirrorEvictionCandidates(images, 400, ""))
+
+	// Least recently used images go first.
+	assert.Equal(t, []string{"oldest"}, fingerprints(imageMirrorEvictionCandidates(images, 300, "")))
+	assert.Equal(t, []string{"oldest", "unused"}, fingerprints(imageMirrorEvictionCandidates(images, 250, "")))
+
+	// The image being fetched is never evicted.
+	assert.Equal(t, []string{"unused", "older"}, fingerprints(imageMirrorEvictionCandidates(images, 200, "oldest")))
+
+	// Everything else goes if the kept image alone is over the limit.
+	assert.Equal(t, []string{"oldest", "unused", "older"}, fingerprints(imageMirrorEvictionCandidates(images, 50, "recent")))
+}
+
+func TestImageMirrorLookupCache(t *testing.T) {
+	cache := &imageMirrorLookupCache{}
+	now := time.Now()
+	key := func(project string, name string) imageMirrorLookupKey {
+		return imageMirrorLookupKey{server: "https://upstream", project: project, name: name}
+	}
+
+	// First lookup is due, repeats within the TTL aren't.
+	assert.True(t, cache.claim(key("default", "alpine"), now))
+	assert.False(t, cache.claim(key("default", "alpine"), now.Add(time.Minute)))
+
+	// Other names and projects are tracked separately.
+	assert.True(t, cache.claim(key("default", "debian"), now))
+	assert.True(t, cache.claim(key("other", "alpine"), now))
+
+	// Forgetting a name makes its lookup due right away.
+	cache.forget("default", "debian")
+	assert.True(t, cache.claim(key("default", "debian"), now.Add(time.Minute)))
+
+	// Lookups are due again once the TTL expires and expired entries are dropped.
+	assert.True(t, cache.claim(key("default", "alpine"), now.Add(imageMirrorLookupTTL)))
+	assert.Len(t, cache.entries, 2)
+}
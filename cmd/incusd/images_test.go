@@ -0,0 +1,105 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/archive"
+)
+
+func TestRecompressQcow2Args(t *testing.T) {
+	tests := []struct {
+		compress string
+		want     []string
+	}{
+		{"none", []string{"qemu-img", "convert", "-f", "qcow2", "-O", "qcow2", "src", "dst"}},
+		{"gzip", []string{"qemu-img", "convert", "-f", "qcow2", "-O", "qcow2", "-c", "src", "dst"}},
+		{"xz -9", []string{"qemu-img", "convert", "-f", "qcow2", "-O", "qcow2", "-c", "src", "dst"}},
+		{"zstd -19", []string{"qemu-img", "convert", "-f", "qcow2", "-O", "qcow2", "-c", "-o", "compression_type=zstd", "src", "dst"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.compress, func(t *testing.T) {
+			args, err := recompressQcow2Args(tt.compress, "src", "dst")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, args)
+		})
+	}
+
+	_, err := recompressQcow2Args("", "src", "dst")
+	assert.Error(t, err)
+}
+
+func TestRecompressRootfs(t *testing.T) {
+	for _, tool := range []string{"gzip", "xz"} {
+		_, err := exec.LookPath(tool)
+		if err != nil {
+			t.Skipf("%s is required: %v", tool, err)
+		}
+	}
+
+	dir := t.TempDir()
+
+	// Build a gzip compressed rootfs tarball.
+	var rootfs bytes.Buffer
+	tw := tar.NewWriter(&rootfs)
+	content := []byte("incus\n")
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "rootfs/etc/hostname", Mode: 0o644, Size: int64(len(content))}))
+	_, err := tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	srcPath := filepath.Join(dir, "image.rootfs")
+	src, err := os.Create(srcPath)
+	require.NoError(t, err)
+	require.NoError(t, compressFile("gzip", bytes.NewReader(rootfs.Bytes()), src))
+	require.NoError(t, src.Close())
+
+	tests := []struct {
+		compress string
+		algo     string
+	}{
+		{"xz", ".tar.xz"},
+		{"none", ".tar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.compress, func(t *testing.T) {
+			dstPath := filepath.Join(dir, tt.compress)
+			require.NoError(t, recompressRootfs(tt.compress, srcPath, dstPath))
+
+			dst, err := os.Open(dstPath)
+			require.NoError(t, err)
+
+			defer func() { _ = dst.Close() }()
+
+			_, algo, unpacker, err := archive.DetectCompressionFile(dst)
+			require.NoError(t, err)
+			assert.Equal(t, tt.algo, algo)
+
+			// The re-compressed rootfs holds the same tarball.
+			_, err = dst.Seek(0, io.SeekStart)
+			require.NoError(t, err)
+
+			var data []byte
+			if len(unpacker) > 0 {
+				cmd := exec.Command(unpacker[0], unpacker[1:]...)
+				cmd.Stdin = dst
+				data, err = cmd.Output()
+			} else {
+				data, err = io.ReadAll(dst)
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, rootfs.Bytes(), data)
+		})
+	}
+}
@@ -134,18 +134,18 @@ func ensureImageIsLocallyAvailable(ctx context.Context, s *state.State, r *http.
 }
 
 // instanceCreateFromImage creates an instance from a rootfs image.
-func instanceCreateFromImage(ctx context.Context, s *state.State, img *api.Image, args db.InstanceArgs, op *operations.Operation) error {
+func instanceCreateFromImage(ctx context.Context, s *state.State, img *api.Image, args db.InstanceArgs, op *operations.Operation) (instance.Instance, error) {
 	reverter := revert.New()
 	defer reverter.Fail()
 
 	// Validate the type of the image matches the type of the instance.
 	imgType, err := instancetype.New(img.Type)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if imgType != args.Type {
-		return fmt.Errorf("Requested image's type %q doesn't match instance type %q", imgType, args.Type)
+		return nil, fmt.Errorf("Requested image's type %q doesn't match instance type %q", imgType, args.Type)
 	}
 
 	// Set the "image.*" keys.
@@ -161,7 +161,7 @@ func instanceCreateFromImage(ctx context.Context, s *state.State, img *api.Image
 	// Create the instance.
 	inst, instOp, cleanup, err := instance.CreateInternal(s, args, op, true, true)
 	if err != nil {
-		return fmt.Errorf("Failed creating instance record: %w", err)
+		return nil, fmt.Errorf("Failed creating instance record: %w", err)
 	}
 
 	reverter.Add(cleanup)
@@ -176,26 +176,26 @@ func instanceCreateFromImage(ctx context.Context, s *state.State, img *api.Image
 		return nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	pool, err := storagePools.LoadByInstance(s, inst)
 	if err != nil {
-		return fmt.Errorf("Failed loading instance storage pool: %w", err)
+		return nil, fmt.Errorf("Failed loading instance storage pool: %w", err)
 	}
 
 	// Lock this operation to ensure that concurrent image operations don't conflict.
 	// Other operations will wait for this one to finish.
 	unlock, err := imageOperationLock(ctx, img.Fingerprint)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer unlock()
 
 	err = pool.CreateInstanceFromImage(inst, img.Fingerprint, op)
 	if err != nil {
-		return fmt.Errorf("Failed creating instance from image: %w", err)
+		return nil, fmt.Errorf("Failed creating instance from image: %w", err)
 	}
 
 	reverter.Add(func() { _ = inst.Delete(true) })
@@ -208,25 +208,25 @@ func instanceCreateFromImage(ctx context.Context, s *state.State, img *api.Image
 		// Mount the instance.
 		_, err = pool.MountInstance(inst, nil)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// Parse the OCI config.
 		data, err := os.ReadFile(filepath.Join(inst.Path(), "config.json"))
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		var config ociSpecs.Spec
 		err = json.Unmarshal([]byte(data), &config)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// Unmount the instance.
 		err = pool.UnmountInstance(inst, nil)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// Update the config for the environment variables.
@@ -234,7 +234,7 @@ func instanceCreateFromImage(ctx context.Context, s *state.State, img *api.Image
 		for _, env := range config.Process.Env {
 			fields := strings.SplitN(env, "=", 2)
 			if len(fields) != 2 {
-				return fmt.Errorf("Bad OCI environment variable: %s", env)
+				return nil, fmt.Errorf("Bad OCI environment variable: %s", env)
 			}
 
 			key := fmt.Sprintf("environment.%s", fields[0])
@@ -265,17 +265,17 @@ func instanceCreateFromImage(ctx context.Context, s *state.State, img *api.Image
 
 		err = inst.Update(args, false)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	err = inst.UpdateBackupFile()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	reverter.Success()
-	return nil
+	return inst, nil
 }
 
 func instanceRebuildFromImage(ctx context.Context, s *state.State, r *http.Request, inst instance.Instance, img *api.Image, op *operations.Operation) error {
@@ -488,6 +488,14 @@ func instanceCreateAsCopy(s *state.State, opts instanceCreateAsCopyOpts, op *ope
 		}
 	}
 
+	// Don't start copying the storage volume if the operation was cancelled in the meantime.
+	if op != nil {
+		err = op.Context().Err()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Copy the storage volume.
 	pool, err := storagePools.LoadByInstance(s, inst)
 	if err != nil {
@@ -705,14 +713,8 @@ func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.
 					return fmt.Errorf("Failed loading instance %q (project %q) for snapshot task: %w", dbInst.Name, dbInst.Project, err)
 				}
 
-				// Check if instance has snapshot schedule enabled.
-				schedule, ok := inst.ExpandedConfig()["snapshots.schedule"]
-				if !ok || schedule == "" {
-					return nil
-				}
-
-				// Check if snapshot is scheduled.
-				if !snapshotIsScheduledNow(schedule, int64(inst.ID())) {
+				// Check if a snapshot is scheduled.
+				if !instanceSnapshotIsScheduledNow(inst.ExpandedConfig(), int64(inst.ID())) {
 					return nil
 				}
 
@@ -25,6 +25,7 @@ import (
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/archive"
 )
 
 // swagger:operation GET /1.0/instances/{name}/backups instances instance_backups_get
@@ -305,6 +306,14 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Backup names may not contain slashes"))
 	}
 
+	// Validate the passphrase before starting the backup.
+	if req.EncryptionPassphrase != "" {
+		err = archive.ValidateEncryptionPassphrase(req.EncryptionPassphrase)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
 	fullName := name + internalInstance.SnapshotDelimiter + req.Name
 	instanceOnly := req.InstanceOnly
 
@@ -317,6 +326,7 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 			InstanceOnly:         instanceOnly,
 			OptimizedStorage:     req.OptimizedStorage,
 			CompressionAlgorithm: req.CompressionAlgorithm,
+			EncryptionPassphrase: req.EncryptionPassphrase,
 		}
 
 		err := backupCreate(s, args, inst, op)
@@ -34,7 +34,6 @@ import (
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
-	"github.com/lxc/incus/v6/shared/util"
 	"github.com/lxc/incus/v6/shared/ws"
 )
 
@@ -659,14 +658,19 @@ func instanceConsoleLogGet(d *Daemon, r *http.Request) response.Response {
 	ent := response.FileResponseEntry{}
 
 	if !inst.IsRunning() {
-		// Check if we have data we can return.
-		consoleBufferLogPath := inst.ConsoleBufferLogPath()
-		if !util.PathExists(consoleBufferLogPath) {
+		// Check if we have data we can return, including from the rotated log files.
+		logContents, err := instance.ConsoleLogRead(inst.ConsoleBufferLogPath())
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if len(logContents) == 0 {
 			return response.FileResponse(r, nil, nil)
 		}
 
-		ent.Path = consoleBufferLogPath
-		ent.Filename = consoleBufferLogPath
+		ent.File = bytes.NewReader(logContents)
+		ent.FileModified = time.Now()
+		ent.FileSize = int64(len(logContents))
 		return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
 	}
 
@@ -699,9 +703,16 @@ func instanceConsoleLogGet(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
-		ent.File = bytes.NewReader([]byte(logContents))
+		// Prepend the content of the rotated log files.
+		rotatedContents, err := instance.ConsoleLogReadRotated(c.ConsoleBufferLogPath())
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		fullLog := append(rotatedContents, logContents...)
+		ent.File = bytes.NewReader(fullLog)
 		ent.FileModified = time.Now()
-		ent.FileSize = int64(len(logContents))
+		ent.FileSize = int64(len(fullLog))
 
 		return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
 	} else if inst.Type() == instancetype.VM {
@@ -832,6 +843,12 @@ func instanceConsoleLogDelete(d *Daemon, r *http.Request) response.Response {
 		return os.Truncate(path, 0)
 	}
 
+	// Remove the rotated log files.
+	err = instance.ConsoleLogDeleteRotated(c.ConsoleBufferLogPath())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	if !inst.IsRunning() {
 		consoleLogpath := c.ConsoleBufferLogPath()
 		return response.SmartError(truncateConsoleLogFile(consoleLogpath))
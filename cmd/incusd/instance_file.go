@@ -11,18 +11,24 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pkg/sftp"
 
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	internalIO "github.com/lxc/incus/v6/internal/io"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
 	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/ioprogress"
 	"github.com/lxc/incus/v6/shared/logger"
 	"github.com/lxc/incus/v6/shared/revert"
 )
@@ -197,15 +203,19 @@ func instanceFileGet(s *state.State, inst instance.Instance, path string, r *htt
 		cleanup := reverter.Clone()
 		reverter.Success()
 
+		// Track the transfer progress.
+		transfer := &instanceFileTransfer{s: s, inst: inst, r: r, path: path, total: stat.Size()}
+
 		// Make a file response struct.
 		files := make([]response.FileResponseEntry, 1)
 		files[0].Identifier = filepath.Base(path)
 		files[0].Filename = filepath.Base(path)
-		files[0].File = file
+		files[0].File = internalIO.NewReadSeeker(&ioprogress.ProgressReader{Reader: file, Tracker: transfer.tracker("pull")}, file)
 		files[0].FileSize = stat.Size()
 		files[0].FileModified = stat.ModTime()
 		files[0].Cleanup = func() {
 			cleanup.Fail()
+			transfer.finish(nil)
 		}
 
 		s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceFileRetrieved.Event(inst, logger.Ctx{"path": path}))
@@ -473,7 +483,9 @@ func instanceFilePost(s *state.State, inst instance.Instance, path string, r *ht
 		}
 
 		// Transfer the file into the instance.
-		_, err = io.Copy(file, r.Body)
+		transfer := &instanceFileTransfer{s: s, inst: inst, r: r, path: path, total: r.ContentLength}
+		_, err = io.Copy(file, &ioprogress.ProgressReader{Reader: r.Body, Tracker: transfer.tracker("push")})
+		transfer.finish(err)
 		if err != nil {
 			return response.InternalError(err)
 		}
@@ -606,3 +618,107 @@ func instanceFileDelete(s *state.State, inst instance.Instance, path string, _ *
 	s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceFileDeleted.Event(inst, logger.Ctx{"path": path}))
 	return response.EmptySyncResponse
 }
+
+// instanceFileTransfer reports the progress of file transfers through a background operation.
+// The operation is only created once the transfer has been running for a second so that
+// small transfers don't show up in the operation list.
+type instanceFileTransfer struct {
+	s     *state.State
+	inst  instance.Instance
+	r     *http.Request
+	path  string
+	total int64
+
+	mu       sync.Mutex
+	op       *operations.Operation
+	done     chan error
+	metadata map[string]any
+	bytes    map[string]int64
+	speeds   map[string]int64
+}
+
+// tracker returns a progress tracker feeding the transfer operation with the bytes sent in the given
+// direction ("push" into the instance or "pull" from it).
+func (t *instanceFileTransfer) tracker(direction string) *ioprogress.ProgressTracker {
+	return &ioprogress.ProgressTracker{
+		Handler: func(processed int64, speed int64) {
+			t.update(direction, processed, speed)
+		},
+	}
+}
+
+// update records the number of bytes transferred so far in the operation metadata.
+func (t *instanceFileTransfer) update(direction string, processed int64, speed int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done == nil {
+		t.done = make(chan error, 1)
+		t.bytes = map[string]int64{}
+		t.speeds = map[string]int64{}
+		t.metadata = map[string]any{}
+
+		if t.path != "" {
+			t.metadata["path"] = t.path
+		}
+
+		if t.total > 0 {
+			t.metadata["total_bytes"] = t.total
+		}
+
+		resources := map[string][]api.URL{}
+		resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", t.inst.Name())}
+
+		op, err := operations.OperationCreate(t.s, t.inst.Project().Name, operations.OperationClassTask, operationtype.InstanceFileTransfer, resources, t.metadata, func(op *operations.Operation) error {
+			return <-t.done
+		}, nil, nil, t.r)
+		if err != nil {
+			logger.Warn("Failed creating file transfer operation", logger.Ctx{"instance": t.inst.Name(), "path": t.path, "err": err})
+			return
+		}
+
+		err = op.Start()
+		if err != nil {
+			logger.Warn("Failed starting file transfer operation", logger.Ctx{"instance": t.inst.Name(), "path": t.path, "err": err})
+			return
+		}
+
+		t.op = op
+	}
+
+	if t.op == nil {
+		return
+	}
+
+	t.bytes[direction] = processed
+	t.speeds[direction] = speed
+	if direction == "push" {
+		t.metadata["pushed_bytes"] = processed
+	} else {
+		t.metadata["pulled_bytes"] = processed
+	}
+
+	processed = t.bytes["push"] + t.bytes["pull"]
+	speed = t.speeds["push"] + t.speeds["pull"]
+
+	percent := int64(0)
+	if t.total > 0 {
+		percent = min(processed*100/t.total, 100)
+	}
+
+	operations.SetProgressMetadata(t.metadata, "file_transfer", "Transferring", percent, processed, speed)
+	_ = t.op.UpdateMetadata(t.metadata)
+}
+
+// finish completes the transfer operation, if one was created.
+func (t *instanceFileTransfer) finish(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.op == nil {
+		return
+	}
+
+	t.done <- err
+	t.op = nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -10,9 +11,12 @@ import (
 	"github.com/gorilla/mux"
 
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/shared/api"
 )
 
 // swagger:operation GET /1.0/instances/{name} instances instance_get
@@ -147,5 +151,23 @@ func instanceGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Add the instance labels.
+	var labels map[string]string
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		labels, err = dbCluster.GetInstanceLabels(ctx, tx.Tx(), c.ID())
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	switch apiInst := state.(type) {
+	case *api.Instance:
+		apiInst.Labels = labels
+	case *api.InstanceFull:
+		apiInst.Labels = labels
+	}
+
 	return response.SyncResponseETag(true, state, etag)
 }
@@ -2,14 +2,19 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
+	"github.com/flosch/pongo2/v6"
 	"github.com/gorilla/mux"
 	"gopkg.in/yaml.v2"
 
@@ -381,6 +386,11 @@ func doInstanceMetadataUpdate(s *state.State, inst instance.Instance, metadata a
 //	    description: Template name
 //	    type: string
 //	    example: hostname.tpl
+//	  - in: query
+//	    name: recursion
+//	    description: Include the template triggers in the listing
+//	    type: integer
+//	    example: 1
 //	responses:
 //	  "200":
 //	     description: Raw template file or file listing
@@ -453,24 +463,42 @@ func instanceMetadataTemplatesGet(d *Daemon, r *http.Request) response.Response
 	templateName := r.FormValue("path")
 	if templateName == "" {
 		templates := []string{}
-		if !util.PathExists(filepath.Join(c.Path(), "templates")) {
+		recursion := localUtil.IsRecursionRequest(r)
+
+		if util.PathExists(filepath.Join(c.Path(), "templates")) {
+			// List templates
+			templatesPath := filepath.Join(c.Path(), "templates")
+			entries, err := os.ReadDir(templatesPath)
+			if err != nil {
+				return response.InternalError(err)
+			}
+
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					templates = append(templates, entry.Name())
+				}
+			}
+		}
+
+		if !recursion {
 			return response.SyncResponse(true, templates)
 		}
 
-		// List templates
-		templatesPath := filepath.Join(c.Path(), "templates")
-		entries, err := os.ReadDir(templatesPath)
+		// Add the triggers recorded in the metadata.
+		metadata, err := instanceMetadataLoad(c)
 		if err != nil {
-			return response.InternalError(err)
+			return response.SmartError(err)
 		}
 
-		for _, entry := range entries {
-			if !entry.IsDir() {
-				templates = append(templates, entry.Name())
-			}
+		fullTemplates := make([]api.InstanceTemplate, 0, len(templates))
+		for _, template := range templates {
+			fullTemplates = append(fullTemplates, api.InstanceTemplate{
+				Name:     template,
+				Triggers: instanceMetadataTemplateTriggers(metadata, template),
+			})
 		}
 
-		return response.SyncResponse(true, templates)
+		return response.SyncResponse(true, fullTemplates)
 	}
 
 	// Check if the template exists
@@ -540,6 +568,21 @@ func instanceMetadataTemplatesGet(d *Daemon, r *http.Request) response.Response
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: render_path
+//	    description: Path in the instance to render the template to
+//	    type: string
+//	    example: /etc/hostname
+//	  - in: query
+//	    name: when
+//	    description: Comma separated list of triggers (create, copy or start)
+//	    type: string
+//	    example: create,copy
+//	  - in: query
+//	    name: create_only
+//	    description: Only render the template if the target file is missing
+//	    type: boolean
+//	    example: false
 //	  - in: body
 //	    name: raw_file
 //	    description: Raw file content
@@ -602,6 +645,35 @@ func instanceMetadataTemplatesPost(d *Daemon, r *http.Request) response.Response
 		return response.BadRequest(fmt.Errorf("missing path argument"))
 	}
 
+	// Parse the trigger, if any.
+	var trigger *api.InstanceTemplateTrigger
+	renderPath := r.FormValue("render_path")
+	if renderPath != "" {
+		trigger = &api.InstanceTemplateTrigger{
+			Path:       renderPath,
+			When:       util.SplitNTrimSpace(r.FormValue("when"), ",", -1, true),
+			CreateOnly: util.IsTrue(r.FormValue("create_only")),
+		}
+
+		err = instanceMetadataTemplateTriggerValidate(*trigger)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	} else if r.FormValue("when") != "" || r.FormValue("create_only") != "" {
+		return response.BadRequest(fmt.Errorf("A render path is required to set template triggers"))
+	}
+
+	// Validate the template syntax.
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	_, err = pongo2.FromString("{% autoescape off %}" + string(content) + "{% endautoescape %}")
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid template %q: %w", templateName, err))
+	}
+
 	if !util.PathExists(filepath.Join(c.Path(), "templates")) {
 		err := os.MkdirAll(filepath.Join(c.Path(), "templates"), 0o711)
 		if err != nil {
@@ -621,8 +693,9 @@ func instanceMetadataTemplatesPost(d *Daemon, r *http.Request) response.Response
 		return response.SmartError(err)
 	}
 
-	_, err = io.Copy(template, r.Body)
+	_, err = template.Write(content)
 	if err != nil {
+		_ = template.Close()
 		return response.InternalError(err)
 	}
 
@@ -631,6 +704,33 @@ func instanceMetadataTemplatesPost(d *Daemon, r *http.Request) response.Response
 		return response.InternalError(err)
 	}
 
+	// Record the trigger in the metadata.
+	if trigger != nil {
+		metadata, err := instanceMetadataLoad(c)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if metadata.Templates == nil {
+			metadata.Templates = map[string]*api.ImageMetadataTemplate{}
+		}
+
+		entry, ok := metadata.Templates[trigger.Path]
+		if !ok || entry == nil {
+			entry = &api.ImageMetadataTemplate{}
+			metadata.Templates[trigger.Path] = entry
+		}
+
+		entry.Template = templateName
+		entry.When = trigger.When
+		entry.CreateOnly = trigger.CreateOnly
+
+		err = instanceMetadataSave(c, metadata)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	s.Events.SendLifecycle(projectName, lifecycle.InstanceMetadataTemplateCreated.Event(c, request.CreateRequestor(r), logger.Ctx{"path": templateName}))
 
 	return response.EmptySyncResponse
@@ -731,6 +831,27 @@ func instanceMetadataTemplatesDelete(d *Daemon, r *http.Request) response.Respon
 		return response.InternalError(err)
 	}
 
+	// Remove the triggers referencing it.
+	metadata, err := instanceMetadataLoad(c)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	changed := false
+	for path, entry := range metadata.Templates {
+		if entry != nil && entry.Template == templateName {
+			delete(metadata.Templates, path)
+			changed = true
+		}
+	}
+
+	if changed {
+		err = instanceMetadataSave(c, metadata)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	s.Events.SendLifecycle(projectName, lifecycle.InstanceMetadataTemplateDeleted.Event(c, request.CreateRequestor(r), logger.Ctx{"path": templateName}))
 
 	return response.EmptySyncResponse
@@ -744,3 +865,73 @@ func getContainerTemplatePath(c instance.Instance, filename string) (string, err
 
 	return filepath.Join(c.Path(), "templates", filename), nil
 }
+
+// instanceMetadataLoad reads the image metadata of a mounted instance.
+func instanceMetadataLoad(inst instance.Instance) (api.ImageMetadata, error) {
+	metadata := api.ImageMetadata{}
+
+	data, err := os.ReadFile(filepath.Join(inst.Path(), "metadata.yaml"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return metadata, nil
+		}
+
+		return metadata, err
+	}
+
+	err = yaml.Unmarshal(data, &metadata)
+	if err != nil {
+		return metadata, err
+	}
+
+	return metadata, nil
+}
+
+// instanceMetadataSave writes the image metadata of a mounted instance.
+func instanceMetadataSave(inst instance.Instance, metadata api.ImageMetadata) error {
+	data, err := yaml.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(inst.Path(), "metadata.yaml"), data, 0o644)
+}
+
+// instanceMetadataTemplateTriggers returns the paths rendered from the given template.
+func instanceMetadataTemplateTriggers(metadata api.ImageMetadata, templateName string) []api.InstanceTemplateTrigger {
+	triggers := []api.InstanceTemplateTrigger{}
+	for path, entry := range metadata.Templates {
+		if entry == nil || entry.Template != templateName {
+			continue
+		}
+
+		triggers = append(triggers, api.InstanceTemplateTrigger{
+			Path:       path,
+			When:       entry.When,
+			CreateOnly: entry.CreateOnly,
+		})
+	}
+
+	sort.Slice(triggers, func(i, j int) bool { return triggers[i].Path < triggers[j].Path })
+
+	return triggers
+}
+
+// instanceMetadataTemplateTriggerValidate validates a template trigger.
+func instanceMetadataTemplateTriggerValidate(trigger api.InstanceTemplateTrigger) error {
+	if !strings.HasPrefix(trigger.Path, "/") {
+		return fmt.Errorf("Template target path %q must be absolute", trigger.Path)
+	}
+
+	if len(trigger.When) == 0 {
+		return fmt.Errorf("At least one template trigger is required")
+	}
+
+	for _, when := range trigger.When {
+		if !slices.Contains([]string{"create", "copy", "start"}, when) {
+			return fmt.Errorf("Invalid template trigger %q (must be create, copy or start)", when)
+		}
+	}
+
+	return nil
+}
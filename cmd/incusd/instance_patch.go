@@ -211,6 +211,11 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	err = allowSnapshotHooksChange(s, r, projectName, name, c.ExpandedConfig(), db.ExpandInstanceConfig(req.Config, apiProfiles))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Update container configuration
 	args := db.InstanceArgs{
 		Architecture: architecture,
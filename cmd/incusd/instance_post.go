@@ -311,6 +311,18 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				if err != nil {
 					return err
 				}
+
+				// Only keep the cluster groups on which the instance can run on any member.
+				targetCandidates, err = instancePlacementCheckResources(ctx, tx, targetProject, targetGroupName, allMembers, targetCandidates, inst.ExpandedDevices())
+				if err != nil {
+					return err
+				}
+
+				// Spread the instances of the same anti-affinity group across failure domains.
+				targetCandidates, err = tx.SpreadCandidateMembers(ctx, targetCandidates, instProject, inst.ExpandedConfig()["cluster.anti_affinity"], inst.Name())
+				if err != nil {
+					return err
+				}
 			}
 
 			return nil
@@ -482,7 +494,7 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 		// Setup the instance move operation.
 		run := func(op *operations.Operation) error {
 			inst.SetOperation(op)
-			return migrateInstance(context.TODO(), s, inst, req, sourceMemberInfo, targetMemberInfo, targetGroupName, op)
+			return migrateInstance(context.TODO(), s, inst, req, sourceMemberInfo, targetMemberInfo, targetGroupName, op, nil)
 		}
 
 		resources := map[string][]api.URL{}
@@ -532,7 +544,8 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 }
 
 // Perform the server-side migration.
-func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance, req api.InstancePost, sourceMemberInfo *db.NodeInfo, targetMemberInfo *db.NodeInfo, targetGroupName string, op *operations.Operation) error {
+// If not nil, progressHandler receives the transfer operation updates instead of them replacing the operation metadata.
+func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance, req api.InstancePost, sourceMemberInfo *db.NodeInfo, targetMemberInfo *db.NodeInfo, targetGroupName string, op *operations.Operation, progressHandler func(api.Operation)) error {
 	// Load the instance storage pool.
 	sourcePool, err := storagePools.LoadByInstance(s, inst)
 	if err != nil {
@@ -775,8 +788,11 @@ func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance
 		}
 
 		// Setup a progress handler.
-		handler := func(newOp api.Operation) {
-			_ = op.UpdateMetadata(newOp.Metadata)
+		handler := progressHandler
+		if handler == nil {
+			handler = func(newOp api.Operation) {
+				_ = op.UpdateMetadata(newOp.Metadata)
+			}
 		}
 
 		_, err = destOp.AddHandler(handler)
@@ -911,8 +927,11 @@ func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance
 		}
 
 		// Setup a progress handler.
-		handler := func(newOp api.Operation) {
-			_ = op.UpdateMetadata(newOp.Metadata)
+		handler := progressHandler
+		if handler == nil {
+			handler = func(newOp api.Operation) {
+				_ = op.UpdateMetadata(newOp.Metadata)
+			}
 		}
 
 		_, err = destOp.AddHandler(handler)
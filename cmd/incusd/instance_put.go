@@ -157,6 +157,11 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
+		err = allowSnapshotHooksChange(s, r, projectName, name, inst.ExpandedConfig(), db.ExpandInstanceConfig(configRaw.Config, apiProfiles))
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		// Update container configuration
 		do = func(op *operations.Operation) error {
 			inst.SetOperation(op)
@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -87,6 +89,10 @@ func instanceSFTPHandler(d *Daemon, r *http.Request) response.Response {
 		if err != nil {
 			return response.SmartError(api.StatusErrorf(http.StatusInternalServerError, "Failed getting instance SFTP connection: %v", err))
 		}
+
+		resp.transfers = newSFTPTransferTracker(func(path string) fileTransferReporter {
+			return &instanceFileTransfer{s: s, inst: inst, r: r, path: path}
+		}, time.Second)
 	}
 
 	return resp
@@ -97,6 +103,7 @@ type sftpServeResponse struct {
 	projectName string
 	instName    string
 	instConn    net.Conn
+	transfers   *sftpTransferTracker
 }
 
 func (r *sftpServeResponse) String() string {
@@ -146,11 +153,20 @@ func (r *sftpServeResponse) Render(w http.ResponseWriter) error {
 		"err":      err,
 	})
 
+	// Track the files transferred through the connection.
+	var instReader io.Reader = r.instConn
+	var remoteReader io.Reader = remoteConn
+	if r.transfers != nil {
+		instReader = io.TeeReader(r.instConn, &sftpPacketScanner{onPacket: r.transfers.serverPacket})
+		remoteReader = io.TeeReader(remoteConn, &sftpPacketScanner{onPacket: r.transfers.clientPacket})
+		defer r.transfers.close()
+	}
+
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		_, err := io.Copy(remoteConn, r.instConn)
+		_, err := io.Copy(remoteConn, instReader)
 		if err != nil {
 			if ctx.Err() == nil {
 				l.Warn("Failed copying SFTP instance connection to remote connection", logger.Ctx{"err": err})
@@ -160,7 +176,7 @@ func (r *sftpServeResponse) Render(w http.ResponseWriter) error {
 		_ = remoteConn.Close() // Trigger the cancellation of the io.Copy reading from remoteConn.
 	}()
 
-	_, err = io.Copy(r.instConn, remoteConn)
+	_, err = io.Copy(r.instConn, remoteReader)
 	if err != nil {
 		if ctx.Err() == nil {
 			l.Warn("Failed copying SFTP remote connection to instance connection", logger.Ctx{"err": err})
@@ -177,3 +193,254 @@ func (r *sftpServeResponse) Render(w http.ResponseWriter) error {
 
 	return nil
 }
+
+// SFTP packet types followed by sftpTransferTracker.
+const (
+	sftpPacketOpen   = 3
+	sftpPacketClose  = 4
+	sftpPacketRead   = 5
+	sftpPacketWrite  = 6
+	sftpPacketStatus = 101
+	sftpPacketHandle = 102
+	sftpPacketData   = 103
+)
+
+// sftpPacketPrefixMax is how much of each SFTP packet is kept for inspection, enough for the request headers
+// and a file path.
+const sftpPacketPrefixMax = 8192
+
+// sftpPacketScanner splits a stream of SFTP packets written to it, calling onPacket with the beginning of each packet
+// (up to sftpPacketPrefixMax bytes) once the whole packet went through.
+type sftpPacketScanner struct {
+	onPacket func(prefix []byte)
+
+	length    [4]byte
+	lengthLen int
+	remaining uint32
+	prefix    []byte
+}
+
+// Write feeds data to the scanner. It never fails so that it can be used with io.TeeReader.
+func (s *sftpPacketScanner) Write(p []byte) (int, error) {
+	n := len(p)
+
+	for len(p) > 0 {
+		// Read the packet length.
+		if s.lengthLen < len(s.length) {
+			copied := copy(s.length[s.lengthLen:], p)
+			s.lengthLen += copied
+			p = p[copied:]
+
+			if s.lengthLen == len(s.length) {
+				s.remaining = binary.BigEndian.Uint32(s.length[:])
+				s.prefix = s.prefix[:0]
+			}
+
+			if s.lengthLen < len(s.length) || s.remaining > 0 {
+				continue
+			}
+		}
+
+		// Consume the packet body, keeping its beginning.
+		chunk := p[:min(uint32(len(p)), s.remaining)]
+		if len(s.prefix) < sftpPacketPrefixMax {
+			s.prefix = append(s.prefix, chunk[:min(len(chunk), sftpPacketPrefixMax-len(s.prefix))]...)
+		}
+
+		s.remaining -= uint32(len(chunk))
+		p = p[len(chunk):]
+
+		if s.remaining == 0 {
+			s.lengthLen = 0
+			s.onPacket(s.prefix)
+		}
+	}
+
+	return n, nil
+}
+
+// sftpReadUint32 reads a big endian uint32 from the start of b.
+func sftpReadUint32(b []byte) (uint32, []byte, bool) {
+	if len(b) < 4 {
+		return 0, nil, false
+	}
+
+	return binary.BigEndian.Uint32(b), b[4:], true
+}
+
+// sftpReadString reads a length prefixed string from the start of b.
+func sftpReadString(b []byte) (string, []byte, bool) {
+	length, b, ok := sftpReadUint32(b)
+	if !ok || uint32(len(b)) < length {
+		return "", nil, false
+	}
+
+	return string(b[:length]), b[length:], true
+}
+
+// fileTransferReporter is told about the progress of a file transfer.
+type fileTransferReporter interface {
+	update(direction string, processed int64, speed int64)
+	finish(err error)
+}
+
+// sftpFileTransfer is a file being transferred through an SFTP connection.
+type sftpFileTransfer struct {
+	reporter   fileTransferReporter
+	bytes      map[string]int64
+	start      time.Time
+	lastUpdate time.Time
+}
+
+// sftpTransferTracker follows the requests going through an SFTP connection to report the progress of each file
+// transferred, from the moment it's opened until it's closed.
+type sftpTransferTracker struct {
+	// newTransfer returns the reporter for a transfer of the file at path.
+	newTransfer func(path string) fileTransferReporter
+
+	// interval is how long a file transfer must be running for before being reported, and how often it then is.
+	interval time.Duration
+
+	mu    sync.Mutex
+	opens map[uint32]string
+	reads map[uint32]string
+	files map[string]*sftpFileTransfer
+}
+
+// newSFTPTransferTracker returns a new sftpTransferTracker.
+func newSFTPTransferTracker(newTransfer func(path string) fileTransferReporter, interval time.Duration) *sftpTransferTracker {
+	return &sftpTransferTracker{
+		newTransfer: newTransfer,
+		interval:    interval,
+		opens:       map[uint32]string{},
+		reads:       map[uint32]string{},
+		files:       map[string]*sftpFileTransfer{},
+	}
+}
+
+// clientPacket handles a packet sent by the SFTP client.
+func (t *sftpTransferTracker) clientPacket(packet []byte) {
+	if len(packet) < 1 {
+		return
+	}
+
+	id, body, ok := sftpReadUint32(packet[1:])
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch packet[0] {
+	case sftpPacketOpen:
+		path, _, ok := sftpReadString(body)
+		if ok {
+			t.opens[id] = path
+		}
+
+	case sftpPacketRead:
+		handle, _, ok := sftpReadString(body)
+		if ok && t.files[handle] != nil {
+			t.reads[id] = handle
+		}
+
+	case sftpPacketWrite:
+		handle, body, ok := sftpReadString(body)
+		if !ok || len(body) < 8 {
+			return
+		}
+
+		length, _, ok := sftpReadUint32(body[8:])
+		if ok {
+			t.add(handle, "push", int64(length))
+		}
+
+	case sftpPacketClose:
+		handle, _, ok := sftpReadString(body)
+		if !ok {
+			return
+		}
+
+		file := t.files[handle]
+		if file != nil {
+			file.reporter.finish(nil)
+			delete(t.files, handle)
+		}
+	}
+}
+
+// serverPacket handles a packet sent by the SFTP server in the instance.
+func (t *sftpTransferTracker) serverPacket(packet []byte) {
+	if len(packet) < 1 {
+		return
+	}
+
+	id, body, ok := sftpReadUint32(packet[1:])
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch packet[0] {
+	case sftpPacketHandle:
+		path, found := t.opens[id]
+		delete(t.opens, id)
+
+		handle, _, ok := sftpReadString(body)
+		if found && ok {
+			t.files[handle] = &sftpFileTransfer{reporter: t.newTransfer(path), bytes: map[string]int64{}, start: time.Now()}
+		}
+
+	case sftpPacketData:
+		handle, found := t.reads[id]
+		delete(t.reads, id)
+
+		length, _, ok := sftpReadUint32(body)
+		if found && ok {
+			t.add(handle, "pull", int64(length))
+		}
+
+	case sftpPacketStatus:
+		delete(t.opens, id)
+		delete(t.reads, id)
+	}
+}
+
+// add records bytes transferred for the file with the given handle, reporting them at most once per interval.
+func (t *sftpTransferTracker) add(handle string, direction string, n int64) {
+	file := t.files[handle]
+	if file == nil {
+		return
+	}
+
+	file.bytes[direction] += n
+
+	now := time.Now()
+	if now.Sub(file.start) < t.interval || now.Sub(file.lastUpdate) < t.interval {
+		return
+	}
+
+	file.lastUpdate = now
+
+	speed := int64(0)
+	elapsed := now.Sub(file.start).Seconds()
+	if elapsed > 0 {
+		speed = int64(float64(file.bytes[direction]) / elapsed)
+	}
+
+	file.reporter.update(direction, file.bytes[direction], speed)
+}
+
+// close finishes the transfers of the files left open when the connection ends.
+func (t *sftpTransferTracker) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for handle, file := range t.files {
+		file.reporter.finish(nil)
+		delete(t.files, handle)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTransferReporter records what's reported about a file transfer.
+type testTransferReporter struct {
+	mu       sync.Mutex
+	path     string
+	bytes    map[string]int64
+	finished bool
+}
+
+func (r *testTransferReporter) update(direction string, processed int64, _ int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bytes[direction] = processed
+}
+
+func (r *testTransferReporter) finish(_ error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.finished = true
+}
+
+func TestSFTPPacketScanner(t *testing.T) {
+	packet := func(body string) []byte {
+		b := binary.BigEndian.AppendUint32(nil, uint32(len(body)))
+		return append(b, body...)
+	}
+
+	stream := bytes.Join([][]byte{packet("\x03first"), packet(""), packet("\x06" + string(bytes.Repeat([]byte("x"), sftpPacketPrefixMax*2)))}, nil)
+
+	var packets [][]byte
+	scanner := &sftpPacketScanner{onPacket: func(prefix []byte) {
+		packets = append(packets, bytes.Clone(prefix))
+	}}
+
+	// Packets are split regardless of how the stream is chunked.
+	for i := range stream {
+		n, err := scanner.Write(stream[i : i+1])
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+	}
+
+	require.Len(t, packets, 3)
+	assert.Equal(t, []byte("\x03first"), packets[0])
+	assert.Empty(t, packets[1])
+	assert.Len(t, packets[2], sftpPacketPrefixMax)
+}
+
+func TestSFTPTransferTracker(t *testing.T) {
+	dir := t.TempDir()
+
+	var mu sync.Mutex
+	reporters := map[string]*testTransferReporter{}
+	tracker := newSFTPTransferTracker(func(path string) fileTransferReporter {
+		mu.Lock()
+		defer mu.Unlock()
+
+		reporter := &testTransferReporter{path: path, bytes: map[string]int64{}}
+		reporters[filepath.Base(path)] = reporter
+
+		return reporter
+	}, 0)
+
+	// Run an SFTP server with the tracker in front of it.
+	clientConn, proxyClientConn := net.Pipe()
+	proxyServerConn, serverConn := net.Pipe()
+
+	server, err := sftp.NewServer(serverConn, sftp.WithServerWorkingDirectory(dir))
+	require.NoError(t, err)
+
+	go func() { _ = server.Serve() }()
+
+	go func() {
+		_, _ = io.Copy(proxyServerConn, io.TeeReader(proxyClientConn, &sftpPacketScanner{onPacket: tracker.clientPacket}))
+		_ = proxyServerConn.Close()
+	}()
+
+	go func() {
+		_, _ = io.Copy(proxyClientConn, io.TeeReader(proxyServerConn, &sftpPacketScanner{onPacket: tracker.serverPacket}))
+		_ = proxyClientConn.Close()
+	}()
+
+	client, err := sftp.NewClientPipe(clientConn, clientConn)
+	require.NoError(t, err)
+
+	defer func() { _ = client.Close() }()
+
+	content := bytes.Repeat([]byte("incus"), 200000)
+
+	// Push a file.
+	file, err := client.Create("pushed")
+	require.NoError(t, err)
+
+	_, err = file.Write(content)
+	require.NoError(t, err)
+
+	mu.Lock()
+	assert.False(t, reporters["pushed"].finished)
+	mu.Unlock()
+
+	require.NoError(t, file.Close())
+
+	// Pull another one.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pulled"), content, 0o644))
+
+	file, err = client.Open("pulled")
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.Equal(t, content, data)
+	require.NoError(t, file.Close())
+
+	// Each file got its own transfer, finished when the file was closed.
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, reporters, 2)
+	assert.Equal(t, map[string]int64{"push": int64(len(content))}, reporters["pushed"].bytes)
+	assert.True(t, reporters["pushed"].finished)
+	assert.Equal(t, map[string]int64{"pull": int64(len(content))}, reporters["pulled"].bytes)
+	assert.True(t, reporters["pulled"].finished)
+	assert.Empty(t, tracker.files)
+	assert.Empty(t, tracker.opens)
+	assert.Empty(t, tracker.reads)
+}
@@ -14,6 +14,7 @@ import (
 
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/jmap"
+	"github.com/lxc/incus/v6/internal/server/auth"
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
 	"github.com/lxc/incus/v6/internal/server/db/operationtype"
@@ -26,6 +27,7 @@ import (
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/util"
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
@@ -288,6 +290,10 @@ func instanceSnapshotsPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	if util.IsFalse(inst.ExpandedConfig()["snapshots.enabled"]) {
+		return response.BadRequest(fmt.Errorf("Snapshots are disabled for instance %q (snapshots.enabled is false)", name))
+	}
+
 	req := api.InstanceSnapshotsPost{}
 	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
@@ -781,3 +787,36 @@ func snapshotDelete(s *state.State, r *http.Request, snapInst instance.Instance)
 
 	return operations.OperationResponse(op)
 }
+
+// snapshotHookKeys lists the config keys holding commands run inside of the instance when it's snapshotted.
+var snapshotHookKeys = []string{"snapshots.hooks.pre", "snapshots.hooks.post"}
+
+// snapshotHooksChanged returns whether the snapshot hooks differ between the two configurations.
+func snapshotHooksChanged(oldConfig map[string]string, newConfig map[string]string) bool {
+	for _, key := range snapshotHookKeys {
+		if oldConfig[key] != newConfig[key] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowSnapshotHooksChange checks that the requestor may run commands in the instance when changing its snapshot hooks.
+// The hooks run as part of snapshot creation, so setting them requires the same permission as exec.
+func allowSnapshotHooksChange(s *state.State, r *http.Request, projectName string, instanceName string, oldConfig map[string]string, newConfig map[string]string) error {
+	if !snapshotHooksChanged(oldConfig, newConfig) {
+		return nil
+	}
+
+	err := s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectInstance(projectName, instanceName), auth.EntitlementCanExec)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusForbidden) {
+			return api.StatusErrorf(http.StatusForbidden, "Changing the snapshot hooks of instance %q requires the permission to execute commands in it", instanceName)
+		}
+
+		return err
+	}
+
+	return nil
+}
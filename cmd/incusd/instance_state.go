@@ -213,6 +213,10 @@ func instanceActionToOpType(action string) (operationtype.Type, error) {
 		return operationtype.InstanceFreeze, nil
 	case internalInstance.Unfreeze:
 		return operationtype.InstanceUnfreeze, nil
+	case internalInstance.SyncClock:
+		return operationtype.InstanceSyncClock, nil
+	case internalInstance.Hibernate:
+		return operationtype.InstanceHibernate, nil
 	default:
 		return operationtype.Unknown, fmt.Errorf("Unknown action: '%s'", action)
 	}
@@ -248,6 +252,10 @@ func doInstanceStatePut(inst instance.Instance, req api.InstanceStatePut) error
 		return inst.Freeze()
 	case internalInstance.Unfreeze:
 		return inst.Unfreeze()
+	case internalInstance.SyncClock:
+		return inst.SyncClock()
+	case internalInstance.Hibernate:
+		return inst.Hibernate()
 	}
 
 	return fmt.Errorf("Unknown action: '%s'", req.Action)
@@ -22,6 +22,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/state"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	"github.com/lxc/incus/v6/internal/server/warnings"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/shared/api"
@@ -125,6 +126,14 @@ var instanceMetadataCmd = APIEndpoint{
 	Put:   APIEndpointAction{Handler: instanceMetadataPut, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "name")},
 }
 
+var instanceLabelsCmd = APIEndpoint{
+	Name: "instanceLabels",
+	Path: "instances/{name}/labels",
+
+	Get: APIEndpointAction{Handler: instanceLabelsGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "name")},
+	Put: APIEndpointAction{Handler: instanceLabelsPut, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
 var instanceMetadataTemplatesCmd = APIEndpoint{
 	Name: "instanceMetadataTemplates",
 	Path: "instances/{name}/metadata/templates",
@@ -179,13 +188,15 @@ func (slice instanceAutostartList) Len() int {
 }
 
 func (slice instanceAutostartList) Less(i, j int) bool {
-	iOrder := slice[i].ExpandedConfig()["boot.autostart.priority"]
-	jOrder := slice[j].ExpandedConfig()["boot.autostart.priority"]
+	iOrder := instanceAutostartPriority(slice[i])
+	jOrder := instanceAutostartPriority(slice[j])
 
 	if iOrder != jOrder {
-		iOrderInt, _ := strconv.Atoi(iOrder)
-		jOrderInt, _ := strconv.Atoi(jOrder)
-		return iOrderInt > jOrderInt
+		return iOrder > jOrder
+	}
+
+	if slice[i].Project().Name != slice[j].Project().Name {
+		return slice[i].Project().Name < slice[j].Project().Name
 	}
 
 	return slice[i].Name() < slice[j].Name()
@@ -195,6 +206,40 @@ func (slice instanceAutostartList) Swap(i, j int) {
 	slice[i], slice[j] = slice[j], slice[i]
 }
 
+// instanceAutostartPriority returns the boot priority of the instance, defaulting to 0.
+func instanceAutostartPriority(inst instance.Instance) int64 {
+	priority, _ := strconv.ParseInt(inst.ExpandedConfig()["boot.autostart.priority"], 10, 64)
+
+	return priority
+}
+
+// instanceAutostartGroups returns the instances to auto-start, grouped by decreasing boot priority.
+// Within a group, instances are ordered by project and name.
+func instanceAutostartGroups(instances []instance.Instance) [][]instance.Instance {
+	candidates := make([]instance.Instance, 0, len(instances))
+	for _, inst := range instances {
+		// Skip instances which shouldn't be started or are already running.
+		if !instanceShouldAutoStart(inst) || inst.IsRunning() {
+			continue
+		}
+
+		candidates = append(candidates, inst)
+	}
+
+	sort.Stable(instanceAutostartList(candidates))
+
+	groups := [][]instance.Instance{}
+	for i, inst := range candidates {
+		if i == 0 || instanceAutostartPriority(inst) != instanceAutostartPriority(candidates[i-1]) {
+			groups = append(groups, []instance.Instance{})
+		}
+
+		groups[len(groups)-1] = append(groups[len(groups)-1], inst)
+	}
+
+	return groups
+}
+
 var instancesStartMu sync.Mutex
 
 // instanceShouldAutoStart returns whether the instance should be auto-started.
@@ -217,84 +262,107 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 	instancesStartMu.Lock()
 	defer instancesStartMu.Unlock()
 
-	// Sort based on instance boot priority.
-	sort.Sort(instanceAutostartList(instances))
+	// Limit the number of instances being started at the same time.
+	concurrency := s.LocalConfig.InstancesAutostartConcurrency()
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Start the instances by decreasing boot priority, only moving to the next priority
+	// once all instances of the current one are started.
+	for _, group := range instanceAutostartGroups(instances) {
+		wg := sync.WaitGroup{}
+		slots := make(chan struct{}, concurrency)
 
-	// Let's make up to 3 attempts to start instances.
-	maxAttempts := 3
+		for _, inst := range group {
+			slots <- struct{}{}
+			wg.Add(1)
 
-	// Start the instances
-	for _, inst := range instances {
-		if !instanceShouldAutoStart(inst) {
-			continue
-		}
+			go func() {
+				defer wg.Done()
 
-		// If already running, we're done.
-		if inst.IsRunning() {
-			continue
+				instanceAutostart(s, inst)
+
+				// Release the slot only once the auto-start delay has elapsed.
+				<-slots
+			}()
 		}
 
-		// Get the instance config.
-		config := inst.ExpandedConfig()
-		autoStartDelay := config["boot.autostart.delay"]
-		shutdownAction := config["boot.host_shutdown_action"]
-
-		instLogger := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
-
-		// Try to start the instance.
-		attempt := 0
-		for {
-			attempt++
-
-			var err error
-			if shutdownAction == "stateful-stop" {
-				// Attempt to restore state.
-				err = inst.Start(true)
-			} else {
-				// Normal startup.
-				err = inst.Start(false)
-			}
+		wg.Wait()
+	}
+}
 
-			if err != nil {
-				if api.StatusErrorCheck(err, http.StatusServiceUnavailable) {
-					break // Don't log or retry instances that are not ready to start yet.
-				}
+// instanceAutostart starts an instance on host boot, retrying on failure, and then waits for its
+// auto-start delay.
+func instanceAutostart(s *state.State, inst instance.Instance) {
+	// Let's make up to 3 attempts to start instances.
+	maxAttempts := 3
 
-				instLogger.Warn("Failed auto start instance attempt", logger.Ctx{"attempt": attempt, "maxAttempts": maxAttempts, "err": err})
+	// Get the instance config.
+	config := inst.ExpandedConfig()
+	autoStartDelay := config["boot.autostart.delay"]
+	shutdownAction := config["boot.host_shutdown_action"]
+
+	instLogger := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	// Try to start the instance.
+	attempt := 0
+	for {
+		attempt++
+
+		var err error
+		if shutdownAction == "stateful-stop" && inst.IsStateful() {
+			// Attempt to restore state.
+			err = inst.Start(true)
+		} else {
+			// Normal startup.
+			err = inst.Start(false)
+		}
 
-				if attempt >= maxAttempts {
-					warnErr := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
-						// If unable to start after 3 tries, record a warning.
-						return tx.UpsertWarningLocalNode(ctx, inst.Project().Name, cluster.TypeInstance, inst.ID(), warningtype.InstanceAutostartFailure, fmt.Sprintf("%v", err))
-					})
-					if warnErr != nil {
-						instLogger.Warn("Failed to create instance autostart failure warning", logger.Ctx{"err": warnErr})
-					}
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusServiceUnavailable) {
+				return // Don't log or retry instances that are not ready to start yet.
+			}
 
-					instLogger.Error("Failed to auto start instance", logger.Ctx{"err": err})
+			if s.ShutdownCtx.Err() != nil {
+				return // Don't retry while the server is shutting down.
+			}
+
+			instLogger.Warn("Failed auto start instance attempt", logger.Ctx{"attempt": attempt, "maxAttempts": maxAttempts, "err": err})
 
-					break
+			// Don't retry instances that already waited for their dependencies to become ready.
+			if attempt >= maxAttempts || errors.Is(err, instance.ErrDependenciesNotReady) {
+				warnErr := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+					// If unable to start after 3 tries, record a warning.
+					return tx.UpsertWarningLocalNode(ctx, inst.Project().Name, cluster.TypeInstance, inst.ID(), warningtype.InstanceAutostartFailure, fmt.Sprintf("%v", err))
+				})
+				if warnErr != nil {
+					instLogger.Warn("Failed to create instance autostart failure warning", logger.Ctx{"err": warnErr})
 				}
 
-				time.Sleep(5 * time.Second)
+				instLogger.Error("Failed to auto start instance", logger.Ctx{"err": err})
 
-				continue
+				return
 			}
 
-			// Resolve any previous warning.
-			warnErr := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, inst.Project().Name, warningtype.InstanceAutostartFailure, cluster.TypeInstance, inst.ID())
-			if warnErr != nil {
-				instLogger.Warn("Failed to resolve instance autostart failure warning", logger.Ctx{"err": warnErr})
-			}
+			time.Sleep(5 * time.Second)
 
-			// Wait the auto-start delay if set.
-			autoStartDelayInt, err := strconv.Atoi(autoStartDelay)
-			if err == nil {
-				time.Sleep(time.Duration(autoStartDelayInt) * time.Second)
-			}
+			continue
+		}
+
+		// Resolve any previous warning.
+		warnErr := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, inst.Project().Name, warningtype.InstanceAutostartFailure, cluster.TypeInstance, inst.ID())
+		if warnErr != nil {
+			instLogger.Warn("Failed to resolve instance autostart failure warning", logger.Ctx{"err": warnErr})
+		}
 
-			break
+		// Wait the auto-start delay if set.
+		autoStartDelayInt, err := strconv.Atoi(autoStartDelay)
+		if err == nil {
+			time.Sleep(time.Duration(autoStartDelayInt) * time.Second)
 		}
+
+		return
 	}
 }
 
@@ -305,13 +373,11 @@ func (slice instanceStopList) Len() int {
 }
 
 func (slice instanceStopList) Less(i, j int) bool {
-	iOrder := slice[i].ExpandedConfig()["boot.stop.priority"]
-	jOrder := slice[j].ExpandedConfig()["boot.stop.priority"]
+	iOrder := instanceStopPriority(slice[i])
+	jOrder := instanceStopPriority(slice[j])
 
 	if iOrder != jOrder {
-		iOrderInt, _ := strconv.Atoi(iOrder)
-		jOrderInt, _ := strconv.Atoi(jOrder)
-		return iOrderInt > jOrderInt // check this line (prob <)
+		return iOrder > jOrder
 	}
 
 	return slice[i].Name() < slice[j].Name()
@@ -321,6 +387,20 @@ func (slice instanceStopList) Swap(i, j int) {
 	slice[i], slice[j] = slice[j], slice[i]
 }
 
+// instanceStopPriority returns the priority of the instance on host shutdown, higher priorities being stopped first.
+// Without boot.stop.priority, instances are stopped in the reverse order of their boot.autostart.priority.
+func instanceStopPriority(inst instance.Instance) int {
+	value, ok := inst.ExpandedConfig()["boot.stop.priority"]
+	if ok && value != "" {
+		priority, _ := strconv.Atoi(value)
+		return priority
+	}
+
+	priority, _ := strconv.Atoi(inst.ExpandedConfig()["boot.autostart.priority"])
+
+	return -priority
+}
+
 // Return all local instances on disk (if instance is running, it will attempt to populate the instance's local
 // and expanded config using the backup.yaml file). It will clear the instance's profiles property to avoid needing
 // to enrich them from the database.
@@ -391,9 +471,29 @@ func instancesOnDisk(s *state.State) ([]instance.Instance, error) {
 	return instances, nil
 }
 
-func instancesShutdown(instances []instance.Instance) {
+// instancesShutdownMu serializes instancesShutdown, which runs both when the host starts shutting down and when
+// the daemon is stopped.
+var instancesShutdownMu sync.Mutex
+
+// instancesShutdown stops the running instances on host shutdown, by decreasing stop priority. When timeout isn't
+// zero, instances that are still running once it has elapsed are forcefully stopped.
+func instancesShutdown(instances []instance.Instance, timeout time.Duration) {
+	instancesShutdownMu.Lock()
+	defer instancesShutdownMu.Unlock()
+
 	sort.Sort(instanceStopList(instances))
 
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+
+		// Let systemd know that stopping the daemon may take until the deadline.
+		err := localUtil.SystemdNotify(fmt.Sprintf("EXTEND_TIMEOUT_USEC=%d", timeout.Microseconds()))
+		if err != nil {
+			logger.Warn("Failed extending the systemd stop timeout", logger.Ctx{"err": err})
+		}
+	}
+
 	// Limit shutdown concurrency to number of instances or number of CPU cores (which ever is less).
 	var wg sync.WaitGroup
 	instShutdownCh := make(chan instance.Instance)
@@ -406,34 +506,7 @@ func instancesShutdown(instances []instance.Instance) {
 	for i := 0; i < maxConcurrent; i++ {
 		go func(instShutdownCh <-chan instance.Instance) {
 			for inst := range instShutdownCh {
-				// Determine how long to wait for the instance to shutdown cleanly.
-				timeoutSeconds := 30
-				value, ok := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
-				if ok {
-					timeoutSeconds, _ = strconv.Atoi(value)
-				}
-
-				action := inst.ExpandedConfig()["boot.host_shutdown_action"]
-				if action == "stateful-stop" {
-					err := inst.Stop(true)
-					if err != nil {
-						logger.Warn("Failed statefully stopping instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
-					}
-				} else if action == "force-stop" {
-					err := inst.Stop(false)
-					if err != nil {
-						logger.Warn("Failed forcefully stopping instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
-					}
-				} else {
-					err := inst.Shutdown(time.Second * time.Duration(timeoutSeconds))
-					if err != nil {
-						logger.Warn("Failed shutting down instance, forcefully stopping", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
-						err = inst.Stop(false)
-						if err != nil {
-							logger.Warn("Failed forcefully stopping instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
-						}
-					}
-				}
+				instanceHostShutdown(inst, deadline)
 
 				if inst.ID() > 0 {
 					// If DB was available then the instance shutdown process will have set
@@ -454,7 +527,7 @@ func instancesShutdown(instances []instance.Instance) {
 			continue
 		}
 
-		priority, _ := strconv.Atoi(inst.ExpandedConfig()["boot.stop.priority"])
+		priority := instanceStopPriority(inst)
 
 		// Shutdown instances in priority batches, logging at the start of each batch.
 		if i == 0 || priority != currentBatchPriority {
@@ -472,3 +545,68 @@ func instancesShutdown(instances []instance.Instance) {
 	wg.Wait()
 	close(instShutdownCh)
 }
+
+// instanceHostShutdown stops an instance on host shutdown according to its boot.host_shutdown_action. The time
+// given to the instance to shut down is capped by the deadline (when not zero), past which it is forcefully stopped.
+func instanceHostShutdown(inst instance.Instance, deadline time.Time) {
+	instLogger := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	// Determine how long to wait for the instance to shutdown cleanly.
+	timeout := 30 * time.Second
+	value, ok := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
+	if ok {
+		timeoutSeconds, _ := strconv.Atoi(value)
+		timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+
+	action := inst.ExpandedConfig()["boot.host_shutdown_action"]
+
+	if !deadline.IsZero() {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			instLogger.Warn("Host shutdown timeout reached, forcefully stopping instance")
+			action = "force-stop"
+		} else if remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	// Instances which can't be stopped statefully are shut down instead.
+	if action == "stateful-stop" && util.IsFalseOrEmpty(inst.ExpandedConfig()["migration.stateful"]) {
+		instLogger.Warn("Instance doesn't support stateful stop, shutting it down")
+		action = "stop"
+	}
+
+	if action == "stateful-stop" {
+		err := inst.Stop(true)
+		if err == nil {
+			return
+		}
+
+		if !inst.IsRunning() {
+			instLogger.Warn("Failed statefully stopping instance", logger.Ctx{"err": err})
+			return
+		}
+
+		instLogger.Warn("Failed statefully stopping instance, shutting it down", logger.Ctx{"err": err})
+		action = "stop"
+	}
+
+	if action == "force-stop" {
+		err := inst.Stop(false)
+		if err != nil {
+			instLogger.Warn("Failed forcefully stopping instance", logger.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	err := inst.Shutdown(timeout)
+	if err != nil {
+		instLogger.Warn("Failed shutting down instance, forcefully stopping", logger.Ctx{"err": err})
+		err = inst.Stop(false)
+		if err != nil {
+			instLogger.Warn("Failed forcefully stopping instance", logger.Ctx{"err": err})
+		}
+	}
+}
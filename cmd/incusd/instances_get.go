@@ -45,6 +45,11 @@ import (
 //      type: string
 //      example: default
 //    - in: query
+//      name: labels
+//      description: Label selector
+//      type: string
+//      example: env=prod,role in (db,cache)
+//    - in: query
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
@@ -103,6 +108,11 @@ import (
 //      type: string
 //      example: default
 //    - in: query
+//      name: labels
+//      description: Label selector
+//      type: string
+//      example: env=prod,role in (db,cache)
+//    - in: query
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
@@ -160,6 +170,11 @@ import (
 //      type: string
 //      example: default
 //    - in: query
+//      name: labels
+//      description: Label selector
+//      type: string
+//      example: env=prod,role in (db,cache)
+//    - in: query
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
@@ -213,6 +228,16 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 
 	mustLoadObjects := recursion > 0 || (recursion == 0 && clauses != nil && len(clauses.Clauses) > 0)
 
+	// Parse label selectors.
+	var labelSelectors []dbCluster.LabelSelector
+	labelsStr := r.FormValue("labels")
+	if labelsStr != "" {
+		labelSelectors, err = dbCluster.ParseLabelSelectors(labelsStr)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid label selector: %w", err))
+		}
+	}
+
 	// Detect project mode.
 	projectName := request.QueryParam(r, "project")
 	allProjects := util.IsTrue(r.FormValue("all-projects"))
@@ -226,6 +251,8 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 	// Get the list and location of all instances.
 	var filteredProjects []string
 	var memberAddressInstances map[string][]db.Instance
+	var labelMatches map[int]bool
+	var instanceLabels map[int]map[string]string
 
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		if allProjects {
@@ -248,6 +275,20 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 			return fmt.Errorf("Failed getting instances by member address: %w", err)
 		}
 
+		if labelSelectors != nil {
+			labelMatches, err = dbCluster.GetInstanceIDsMatchingLabels(ctx, tx.Tx(), labelSelectors)
+			if err != nil {
+				return err
+			}
+		}
+
+		if mustLoadObjects {
+			instanceLabels, err = dbCluster.GetAllInstanceLabels(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -259,7 +300,8 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 		return response.InternalError(err)
 	}
 
-	// Removes instances the user doesn't have access to.
+	// Removes instances the user doesn't have access to or not matching the label selectors.
+	labelMatchNames := map[string]bool{}
 	for address, instances := range memberAddressInstances {
 		var filteredInstances []db.Instance
 
@@ -268,6 +310,14 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 				continue
 			}
 
+			if labelMatches != nil {
+				if !labelMatches[int(inst.ID)] {
+					continue
+				}
+
+				labelMatchNames[inst.Project+"/"+inst.Name] = true
+			}
+
 			filteredInstances = append(filteredInstances, inst)
 		}
 
@@ -413,7 +463,9 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 							if err != nil {
 								resultErrListAppend(dbInst, err)
 							} else {
-								resultFullListAppend(&api.InstanceFull{Instance: *c.(*api.Instance)})
+								apiInst := c.(*api.Instance)
+								apiInst.Labels = instanceLabels[inst.ID()]
+								resultFullListAppend(&api.InstanceFull{Instance: *apiInst})
 							}
 
 							continue
@@ -423,6 +475,7 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 						if err != nil {
 							resultErrListAppend(dbInst, err)
 						} else {
+							c.Labels = instanceLabels[inst.ID()]
 							resultFullListAppend(c)
 						}
 					}
@@ -449,6 +502,18 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 		return resultFullList[i].Project < resultFullList[j].Project
 	})
 
+	// Drop instances returned by other members which don't match the label selectors.
+	if labelMatches != nil {
+		filteredList := make([]*api.InstanceFull, 0, len(resultFullList))
+		for _, instFull := range resultFullList {
+			if labelMatchNames[instFull.Project+"/"+instFull.Name] {
+				filteredList = append(filteredList, instFull)
+			}
+		}
+
+		resultFullList = filteredList
+	}
+
 	// Filter result list if needed.
 	if clauses != nil && len(clauses.Clauses) > 0 {
 		resultFullList, err = instance.FilterFull(resultFullList, *clauses)
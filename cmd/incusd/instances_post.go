@@ -10,6 +10,7 @@ import (
 	"os"
 	"slices"
 	"strings"
+	"time"
 
 	petname "github.com/dustinkirkland/golang-petname"
 	"github.com/gorilla/websocket"
@@ -117,12 +118,12 @@ func createFromImage(s *state.State, r *http.Request, p api.Project, profiles []
 		}
 
 		if req.Source.Server != "" {
-			img, err = ensureDownloadedImageFitWithinBudget(context.TODO(), s, r, op, p, imgAlias, req.Source, string(req.Type))
+			img, err = ensureDownloadedImageFitWithinBudget(op.Context(), s, r, op, p, imgAlias, req.Source, string(req.Type))
 			if err != nil {
 				return err
 			}
 		} else if img != nil {
-			err := ensureImageIsLocallyAvailable(context.TODO(), s, r, img, args.Project)
+			err := ensureImageIsLocallyAvailable(op.Context(), s, r, img, args.Project)
 			if err != nil {
 				return err
 			}
@@ -136,11 +137,13 @@ func createFromImage(s *state.State, r *http.Request, p api.Project, profiles []
 		}
 
 		// Actually create the instance.
-		err = instanceCreateFromImage(context.TODO(), s, img, args, op)
+		_, err = instanceCreateFromImage(op.Context(), s, img, args, op)
 		if err != nil {
 			return err
 		}
 
+		instanceCreateRollback(s, op, args.Project, args.Name)
+
 		return instanceCreateFinish(s, req, args, op)
 	}
 
@@ -152,6 +155,8 @@ func createFromImage(s *state.State, r *http.Request, p api.Project, profiles []
 		return response.InternalError(err)
 	}
 
+	op.AllowRollback()
+
 	return operations.OperationResponse(op)
 }
 
@@ -194,6 +199,8 @@ func createFromNone(s *state.State, r *http.Request, projectName string, profile
 			return err
 		}
 
+		instanceCreateRollback(s, op, args.Project, args.Name)
+
 		return instanceCreateFinish(s, req, args, op)
 	}
 
@@ -205,6 +212,8 @@ func createFromNone(s *state.State, r *http.Request, projectName string, profile
 		return response.InternalError(err)
 	}
 
+	op.AllowRollback()
+
 	return operations.OperationResponse(op)
 }
 
@@ -315,6 +324,7 @@ func createFromMigration(ctx context.Context, s *state.State, r *http.Request, p
 	defer reverter.Fail()
 
 	instanceOnly := req.Source.InstanceOnly
+	instanceCreated := inst == nil
 
 	if inst == nil {
 		_, err := storagePools.LoadByName(s, storagePool)
@@ -450,25 +460,36 @@ func createFromMigration(ctx context.Context, s *state.State, r *http.Request, p
 
 		runReverter.Success()
 
+		if instanceCreated {
+			instanceCreateRollback(s, op, args.Project, args.Name)
+		}
+
 		return instanceCreateFinish(s, req, args, op)
 	}
 
+	// Interrupt the transfer when cancelled, the run reverter then cleans up what was received so far.
+	cancel := func(op *operations.Operation) error {
+		sink.disconnect()
+		return nil
+	}
+
 	resources := map[string][]api.URL{}
 	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", req.Name)}
 
 	var op *operations.Operation
 	if push {
-		op, err = operations.OperationCreate(s, projectName, operations.OperationClassWebsocket, operationtype.InstanceCreate, resources, sink.Metadata(), run, nil, sink.Connect, r)
+		op, err = operations.OperationCreate(s, projectName, operations.OperationClassWebsocket, operationtype.InstanceCreate, resources, sink.Metadata(), run, cancel, sink.Connect, r)
 		if err != nil {
 			return response.InternalError(err)
 		}
 	} else {
-		op, err = operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceCreate, resources, nil, run, nil, nil, r)
+		op, err = operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceCreate, resources, nil, run, cancel, nil, r)
 		if err != nil {
 			return response.InternalError(err)
 		}
 	}
 
+	op.AllowRollback()
 	reverter.Success()
 	return operations.OperationResponse(op)
 }
@@ -494,6 +515,35 @@ func createFromCopy(ctx context.Context, s *state.State, r *http.Request, projec
 		return response.SmartError(err)
 	}
 
+	// A stateful copy forks the running state of the source into the new instance.
+	if req.Source.Stateful {
+		// CRIU can't restore a container under a different name.
+		if source.Type() != instancetype.VM {
+			return response.BadRequest(fmt.Errorf("Stateful copy is only supported for virtual machines"))
+		}
+
+		if source.IsSnapshot() {
+			return response.BadRequest(fmt.Errorf("Stateful copy requires the source to be an instance rather than a snapshot"))
+		}
+
+		if !source.IsRunning() {
+			return response.BadRequest(fmt.Errorf("Stateful copy requires the source instance to be running"))
+		}
+
+		if req.Source.Refresh {
+			return response.BadRequest(fmt.Errorf("Stateful copy can't be combined with refresh"))
+		}
+
+		// The running state is captured through a temporary snapshot.
+		if util.IsFalse(source.ExpandedConfig()["snapshots.enabled"]) {
+			return response.BadRequest(fmt.Errorf("Stateful copy requires snapshots to be enabled on the source instance (snapshots.enabled is false)"))
+		}
+
+		if s.ServerClustered && s.ServerName != source.Location() {
+			return response.BadRequest(fmt.Errorf("Stateful copy must be performed on the cluster member running the source instance"))
+		}
+	}
+
 	// When clustered, use the node name, otherwise use the hostname.
 	if s.ServerClustered {
 		serverName := s.ServerName
@@ -539,7 +589,10 @@ func createFromCopy(ctx context.Context, s *state.State, r *http.Request, projec
 	}
 
 	for key, value := range sourceConfig {
-		if !internalInstance.InstanceIncludeWhenCopying(key, false) {
+		// The machine definition is needed to restore the state of a virtual machine.
+		keepDefinition := req.Source.Stateful && key == "volatile.vm.definition"
+
+		if !internalInstance.InstanceIncludeWhenCopying(key, false) && !keepDefinition {
 			logger.Debug("Skipping key from copy source", logger.Ctx{"key": key, "sourceProject": source.Project().Name, "sourceInstance": source.Name(), "project": targetProject, "instance": req.Name})
 			continue
 		}
@@ -600,15 +653,51 @@ func createFromCopy(ctx context.Context, s *state.State, r *http.Request, projec
 		Ephemeral:    req.Ephemeral,
 		Name:         req.Name,
 		Profiles:     profiles,
-		Stateful:     req.Stateful,
+		Stateful:     req.Stateful || req.Source.Stateful,
 	}
 
 	run := func(op *operations.Operation) error {
+		// Only roll back the creation of the target, not the refresh of an existing one.
+		instanceCreated := true
+		if req.Source.Refresh {
+			_, err := instance.LoadByProjectAndName(s, args.Project, args.Name)
+			instanceCreated = err != nil
+		}
+
+		// For a stateful copy, capture the running state of the source in a temporary snapshot and
+		// copy the new instance from it. The source snapshots are not copied in that case.
+		copySource := source
+		instanceOnly := req.Source.InstanceOnly
+		if req.Source.Stateful {
+			snapName := fmt.Sprintf("copy-%s", op.ID())
+
+			source.SetOperation(op)
+			err := source.Snapshot(snapName, time.Time{}, true)
+			if err != nil {
+				return fmt.Errorf("Failed creating stateful snapshot of %q: %w", source.Name(), err)
+			}
+
+			snapInst, err := instance.LoadByProjectAndName(s, source.Project().Name, source.Name()+internalInstance.SnapshotDelimiter+snapName)
+			if err != nil {
+				return fmt.Errorf("Failed loading stateful snapshot of %q: %w", source.Name(), err)
+			}
+
+			defer func() {
+				err := snapInst.Delete(true)
+				if err != nil {
+					logger.Warn("Failed deleting temporary snapshot of stateful copy", logger.Ctx{"project": snapInst.Project().Name, "snapshot": snapInst.Name(), "err": err})
+				}
+			}()
+
+			copySource = snapInst
+			instanceOnly = true
+		}
+
 		// Actually create the instance.
 		_, err := instanceCreateAsCopy(s, instanceCreateAsCopyOpts{
-			sourceInstance:       source,
+			sourceInstance:       copySource,
 			targetInstance:       args,
-			instanceOnly:         req.Source.InstanceOnly,
+			instanceOnly:         instanceOnly,
 			refresh:              req.Source.Refresh,
 			refreshExcludeOlder:  req.Source.RefreshExcludeOlder,
 			applyTemplateTrigger: true,
@@ -618,6 +707,35 @@ func createFromCopy(ctx context.Context, s *state.State, r *http.Request, projec
 			return err
 		}
 
+		if instanceCreated {
+			instanceCreateRollback(s, op, args.Project, args.Name)
+		}
+
+		// Resume the new instance from the copied state.
+		if req.Source.Stateful {
+			inst, err := instance.LoadByProjectAndName(s, args.Project, args.Name)
+			if err != nil {
+				return fmt.Errorf("Failed to load the instance: %w", err)
+			}
+
+			inst.SetOperation(op)
+
+			err = inst.Start(true)
+			if err != nil {
+				// Don't leave behind an instance which never ran.
+				if instanceCreated {
+					deleteErr := inst.Delete(true)
+					if deleteErr != nil {
+						logger.Warn("Failed deleting instance of failed stateful copy", logger.Ctx{"project": args.Project, "instance": args.Name, "err": deleteErr})
+					}
+				}
+
+				return fmt.Errorf("Failed resuming the instance from the copied state: %w", err)
+			}
+
+			return nil
+		}
+
 		return instanceCreateFinish(s, req, args, op)
 	}
 
@@ -629,10 +747,27 @@ func createFromCopy(ctx context.Context, s *state.State, r *http.Request, projec
 		return response.InternalError(err)
 	}
 
+	op.AllowRollback()
+
 	return operations.OperationResponse(op)
 }
 
-func createFromBackup(s *state.State, r *http.Request, projectName string, data io.Reader, pool string, instanceName string) response.Response {
+// instanceBackupRestore selects the parts of a backup restored by createFromBackup.
+type instanceBackupRestore struct {
+	configOnly   bool   // Only restore the instance config, creating an empty root volume.
+	instanceOnly bool   // Don't restore any of the snapshots.
+	snapshot     string // Only restore the named snapshot.
+}
+
+func createFromBackup(s *state.State, r *http.Request, projectName string, data io.Reader, pool string, instanceName string, passphrase string, restore instanceBackupRestore) response.Response {
+	if restore.configOnly && (restore.instanceOnly || restore.snapshot != "") {
+		return response.BadRequest(fmt.Errorf("Snapshots can't be selected when only restoring the config"))
+	}
+
+	if restore.instanceOnly && restore.snapshot != "" {
+		return response.BadRequest(fmt.Errorf("A snapshot can't be selected when excluding snapshots"))
+	}
+
 	reverter := revert.New()
 	defer reverter.Fail()
 
@@ -645,6 +780,12 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 	defer func() { _ = os.Remove(backupFile.Name()) }()
 	reverter.Add(func() { _ = backupFile.Close() })
 
+	// Decrypt the uploaded backup data as it's received if needed.
+	data, err = backupDecryptReader(data, passphrase)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
 	// Stream uploaded backup data into temporary file.
 	_, err = io.Copy(backupFile, data)
 	if err != nil {
@@ -704,6 +845,17 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 		return response.BadRequest(fmt.Errorf("Backup file is missing required information"))
 	}
 
+	// Select the snapshots to restore.
+	if restore.instanceOnly {
+		err = bInfo.SelectSnapshots(nil)
+	} else if restore.snapshot != "" {
+		err = bInfo.SelectSnapshots([]string{restore.snapshot})
+	}
+
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
 	// Check project permissions.
 	var req api.InstancesPost
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -733,13 +885,14 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 	}
 
 	logger.Debug("Backup file info loaded", logger.Ctx{
-		"type":      bInfo.Type,
-		"name":      bInfo.Name,
-		"project":   bInfo.Project,
-		"backend":   bInfo.Backend,
-		"pool":      bInfo.Pool,
-		"optimized": *bInfo.OptimizedStorage,
-		"snapshots": bInfo.Snapshots,
+		"type":       bInfo.Type,
+		"name":       bInfo.Name,
+		"project":    bInfo.Project,
+		"backend":    bInfo.Backend,
+		"pool":       bInfo.Pool,
+		"optimized":  *bInfo.OptimizedStorage,
+		"snapshots":  bInfo.Snapshots,
+		"configOnly": restore.configOnly,
 	})
 
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -749,10 +902,10 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 		return err
 	})
 	if response.IsNotFoundError(err) {
-		// The storage pool doesn't exist. If backup is in binary format (so we cannot alter
-		// the backup.yaml) or the pool has been specified directly from the user restoring
-		// the backup then we cannot proceed so return an error.
-		if *bInfo.OptimizedStorage || pool != "" {
+		// The storage pool doesn't exist. If backup data is restored from binary format (so we
+		// cannot alter the backup.yaml) or the pool has been specified directly from the user
+		// restoring the backup then we cannot proceed so return an error.
+		if (*bInfo.OptimizedStorage && !restore.configOnly) || pool != "" {
 			return response.InternalError(fmt.Errorf("Storage pool not found: %w", err))
 		}
 
@@ -786,6 +939,36 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 		defer func() { _ = backupFile.Close() }()
 		defer runReverter.Fail()
 
+		// Create the instance with an empty root volume when only restoring its config.
+		if restore.configOnly {
+			bInfo.Config.Container.Name = bInfo.Name
+
+			args, err := backup.ConfigToInstanceDBArgs(s, bInfo.Config, bInfo.Project, true)
+			if err != nil {
+				return err
+			}
+
+			if bInfo.Config.Container.Devices == nil {
+				bInfo.Config.Container.Devices = make(map[string]map[string]string)
+			}
+
+			if bInfo.Config.Container.ExpandedDevices == nil {
+				bInfo.Config.Container.ExpandedDevices = make(map[string]map[string]string)
+			}
+
+			internalImportRootDevicePopulate(bInfo.Pool, bInfo.Config.Container.Devices, bInfo.Config.Container.ExpandedDevices, args.Profiles)
+			args.Devices = deviceConfig.NewDevices(bInfo.Config.Container.Devices)
+
+			_, err = instanceCreateAsEmpty(s, *args, op)
+			if err != nil {
+				return fmt.Errorf("Failed creating instance from backup config: %w", err)
+			}
+
+			runReverter.Success()
+
+			return instanceCreateFinish(s, &req, db.InstanceArgs{Name: bInfo.Name, Project: bInfo.Project}, op)
+		}
+
 		pool, err := storagePools.LoadByName(s, bInfo.Pool)
 		if err != nil {
 			return err
@@ -902,7 +1085,13 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 
 	// If we're getting binary content, process separately
 	if r.Header.Get("Content-Type") == "application/octet-stream" {
-		return createFromBackup(s, r, targetProjectName, r.Body, r.Header.Get("X-Incus-pool"), r.Header.Get("X-Incus-name"))
+		restore := instanceBackupRestore{
+			configOnly:   util.IsTrue(r.Header.Get("X-Incus-config-only")),
+			instanceOnly: util.IsTrue(r.Header.Get("X-Incus-instance-only")),
+			snapshot:     r.Header.Get("X-Incus-snapshot"),
+		}
+
+		return createFromBackup(s, r, targetProjectName, r.Body, r.Header.Get("X-Incus-pool"), r.Header.Get("X-Incus-name"), r.Header.Get("X-Incus-encryption-passphrase"), restore)
 	}
 
 	// Parse the request
@@ -1161,6 +1350,14 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			if err != nil {
 				return err
 			}
+
+			// Only keep the cluster groups on which the instance can run on any member.
+			devices := db.ExpandInstanceDevices(deviceConfig.NewDevices(req.Devices), profiles)
+
+			candidateMembers, err = instancePlacementCheckResources(ctx, tx, targetProject, targetGroupName, allMembers, candidateMembers, devices)
+			if err != nil {
+				return err
+			}
 		}
 
 		if !clusterNotification {
@@ -1183,10 +1380,38 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	if !clusterNotification && !clusterInternal {
+		err = allowSnapshotHooksChange(s, r, targetProjectName, req.Name, nil, req.Config)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	if s.ServerClustered && !clusterNotification && !clusterInternal {
 		// If a target was specified, limit the list of candidates to that target.
 		if targetMemberInfo != nil {
 			candidateMembers = []db.NodeInfo{*targetMemberInfo}
+		} else {
+			// Skip the members lacking the GPU, infiniband or PCI devices needed by the instance.
+			devices := db.ExpandInstanceDevices(deviceConfig.NewDevices(req.Devices), profiles)
+
+			candidateMembers, err = cluster.FilterMembersByDevices(s, candidateMembers, cluster.GetDeviceRequirements(devices))
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			// Spread the instances of the same anti-affinity group across failure domains.
+			antiAffinityGroup := db.ExpandInstanceConfig(req.Config, profiles)["cluster.anti_affinity"]
+			if antiAffinityGroup != "" {
+				err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+					candidateMembers, err = tx.SpreadCandidateMembers(ctx, candidateMembers, targetProjectName, antiAffinityGroup, "")
+
+					return err
+				})
+				if err != nil {
+					return response.SmartError(err)
+				}
+			}
 		}
 
 		// Run instance placement scriptlet if enabled.
@@ -1260,6 +1485,63 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 	}
 }
 
+// instancePlacementCheckResources checks that the storage pools and networks used by the instance devices are
+// available on all the members of at least one of the cluster groups the instance is restricted to. This is either
+// the targeted cluster group or one of the cluster groups the project is restricted to. Each group is checked on its
+// own and the candidate members are narrowed down to the members of the groups passing the check.
+func instancePlacementCheckResources(ctx context.Context, tx *db.ClusterTx, p *api.Project, targetGroupName string, allMembers []db.NodeInfo, candidates []db.NodeInfo, devices deviceConfig.Devices) ([]db.NodeInfo, error) {
+	groupNames := project.GetRestrictedClusterGroups(p)
+	if targetGroupName != "" {
+		groupNames = []string{targetGroupName}
+	}
+
+	if len(groupNames) == 0 {
+		return candidates, nil
+	}
+
+	req := cluster.GetResourceRequirements(devices)
+	networkProjectName := project.NetworkProjectFromRecord(p)
+
+	validGroupNames := []string{}
+	groupErrors := []string{}
+	for _, groupName := range groupNames {
+		members := []db.NodeInfo{}
+		for _, member := range allMembers {
+			if slices.Contains(member.Groups, groupName) {
+				members = append(members, member)
+			}
+		}
+
+		err := cluster.CheckMembersResources(ctx, tx, networkProjectName, members, req)
+		if err != nil {
+			if !api.StatusErrorCheck(err, http.StatusBadRequest) {
+				return nil, err
+			}
+
+			groupErrors = append(groupErrors, fmt.Sprintf("Cluster group %q: %v", groupName, err))
+			continue
+		}
+
+		validGroupNames = append(validGroupNames, groupName)
+	}
+
+	if len(validGroupNames) == 0 {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "%s", strings.Join(groupErrors, "; "))
+	}
+
+	filtered := make([]db.NodeInfo, 0, len(candidates))
+	for _, candidate := range candidates {
+		for _, groupName := range validGroupNames {
+			if slices.Contains(candidate.Groups, groupName) {
+				filtered = append(filtered, candidate)
+				break
+			}
+		}
+	}
+
+	return filtered, nil
+}
+
 func instanceFindStoragePool(ctx context.Context, s *state.State, projectName string, req *api.InstancesPost) (string, string, string, map[string]string, response.Response) {
 	// Grab the container's root device if one is specified
 	storagePool := ""
@@ -1418,11 +1700,39 @@ func clusterCopyContainerInternal(ctx context.Context, s *state.State, r *http.R
 	return createFromMigration(ctx, s, nil, projectName, profiles, req)
 }
 
+// instanceCreateRollback registers the removal of a newly created instance as a rollback step of the operation.
+func instanceCreateRollback(s *state.State, op *operations.Operation, projectName string, instanceName string) {
+	op.AddRollback(func() {
+		inst, err := instance.LoadByProjectAndName(s, projectName, instanceName)
+		if err != nil {
+			return
+		}
+
+		if inst.IsRunning() {
+			err = inst.Stop(false)
+			if err != nil {
+				logger.Warn("Failed stopping instance of cancelled operation", logger.Ctx{"project": projectName, "instance": instanceName, "err": err})
+			}
+		}
+
+		err = inst.Delete(true)
+		if err != nil {
+			logger.Warn("Failed deleting instance of cancelled operation", logger.Ctx{"project": projectName, "instance": instanceName, "err": err})
+		}
+	})
+}
+
 func instanceCreateFinish(s *state.State, req *api.InstancesPost, args db.InstanceArgs, op *operations.Operation) error {
 	if req == nil || !req.Start {
 		return nil
 	}
 
+	// Don't start the instance if the operation was cancelled in the meantime.
+	err := op.Context().Err()
+	if err != nil {
+		return err
+	}
+
 	// Start the instance.
 	inst, err := instance.LoadByProjectAndName(s, args.Project, args.Name)
 	if err != nil {
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/project"
+	storageDrivers "github.com/lxc/incus/v6/internal/server/storage/drivers"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+type instancesPostTestSuite struct {
+	daemonTestSuite
+}
+
+// instanceRecords returns whether the database holds the instance and its storage volume.
+func (suite *instancesPostTestSuite) instanceRecords(projectName string, name string) (bool, bool) {
+	var instanceExists, volumeExists bool
+
+	err := suite.d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		instanceExists, err = cluster.InstanceExists(ctx, tx.Tx(), projectName, name)
+		if err != nil {
+			return err
+		}
+
+		poolID, err := tx.GetStoragePoolID(ctx, daemonTestSuiteDefaultStoragePool)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.GetStoragePoolVolume(ctx, poolID, projectName, db.StoragePoolVolumeTypeContainer, name, true)
+		if err == nil {
+			volumeExists = true
+		} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		return nil
+	})
+	suite.Req.NoError(err)
+
+	return instanceExists, volumeExists
+}
+
+// Test that cancelling an instance creation midway removes the instance along with its storage volume.
+func (suite *instancesPostTestSuite) TestInstanceCreateCancelRollback() {
+	s := suite.d.State()
+
+	args := db.InstanceArgs{
+		Project: api.ProjectDefaultName,
+		Type:    instancetype.Container,
+		Name:    "c1",
+	}
+
+	// The mock storage driver doesn't create the volume mount path.
+	err := os.MkdirAll(storageDrivers.GetVolumeMountPath(daemonTestSuiteDefaultStoragePool, storageDrivers.VolumeTypeContainer, project.Instance(args.Project, args.Name)), 0o711)
+	suite.Req.NoError(err)
+
+	created := make(chan struct{})
+	run := func(op *operations.Operation) error {
+		_, err := instanceCreateAsEmpty(s, args, op)
+		if err != nil {
+			return err
+		}
+
+		instanceCreateRollback(s, op, args.Project, args.Name)
+		close(created)
+
+		// Stand for the remaining steps of the creation, which stop once the operation is cancelled.
+		<-op.Context().Done()
+
+		return op.Context().Err()
+	}
+
+	op, err := operations.OperationCreate(s, args.Project, operations.OperationClassTask, operationtype.InstanceCreate, nil, nil, run, nil, nil, nil)
+	suite.Req.NoError(err)
+
+	op.AllowRollback()
+	suite.Req.NoError(op.Start())
+
+	select {
+	case <-created:
+	case <-time.After(30 * time.Second):
+		suite.T().Fatal("Instance wasn't created")
+	}
+
+	instanceExists, volumeExists := suite.instanceRecords(args.Project, args.Name)
+	suite.True(instanceExists)
+	suite.True(volumeExists)
+
+	chanCancel, err := op.Cancel()
+	suite.Req.NoError(err)
+	suite.Req.NoError(<-chanCancel)
+	suite.Equal(api.Cancelled, op.Status())
+
+	instanceExists, volumeExists = suite.instanceRecords(args.Project, args.Name)
+	suite.False(instanceExists, "Instance record left behind by the cancelled creation")
+	suite.False(volumeExists, "Storage volume left behind by the cancelled creation")
+}
+
+func TestInstancesPostTestSuite(t *testing.T) {
+	suite.Run(t, &instancesPostTestSuite{})
+}
@@ -139,6 +139,18 @@ func instancesPut(d *Daemon, r *http.Request) response.Response {
 			if !inst.IsFrozen() {
 				continue
 			}
+
+		case internalInstance.SyncClock:
+			// Containers use the host clock.
+			if !inst.IsRunning() || inst.Type() != instancetype.VM {
+				continue
+			}
+
+		case internalInstance.Hibernate:
+			// Only virtual machines can be hibernated.
+			if !inst.IsRunning() || inst.Type() != instancetype.VM {
+				continue
+			}
 		}
 
 		instances = append(instances, inst)
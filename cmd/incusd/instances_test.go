@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// autostartTestInstance is an instance only implementing what the auto-start ordering relies on.
+type autostartTestInstance struct {
+	instance.Instance
+
+	name    string
+	project string
+	config  map[string]string
+	running bool
+}
+
+func (i *autostartTestInstance) Name() string {
+	return i.name
+}
+
+func (i *autostartTestInstance) Project() api.Project {
+	return api.Project{Name: i.project}
+}
+
+func (i *autostartTestInstance) ExpandedConfig() map[string]string {
+	return i.config
+}
+
+func (i *autostartTestInstance) IsRunning() bool {
+	return i.running
+}
+
+func autostartTestNames(groups [][]instance.Instance) [][]string {
+	names := [][]string{}
+	for _, group := range groups {
+		groupNames := []string{}
+		for _, inst := range group {
+			groupNames = append(groupNames, inst.Project().Name+"/"+inst.Name())
+		}
+
+		names = append(names, groupNames)
+	}
+
+	return names
+}
+
+// Test that instances are grouped by decreasing priority and ordered by project and name within a group.
+func TestInstanceAutostartGroups(t *testing.T) {
+	instances := []instance.Instance{
+		&autostartTestInstance{name: "c3", project: "default", config: map[string]string{"boot.autostart": "true"}},
+		&autostartTestInstance{name: "c2", project: "foo", config: map[string]string{"boot.autostart": "true", "boot.autostart.priority": "0"}},
+		&autostartTestInstance{name: "c1", project: "foo", config: map[string]string{"boot.autostart": "true", "boot.autostart.priority": "10"}},
+		&autostartTestInstance{name: "c2", project: "default", config: map[string]string{"boot.autostart": "true"}},
+		&autostartTestInstance{name: "v1", project: "default", config: map[string]string{"boot.autostart": "true", "boot.autostart.priority": "10"}},
+		&autostartTestInstance{name: "c4", project: "default", config: map[string]string{"boot.autostart": "true", "boot.autostart.priority": "-1"}},
+		&autostartTestInstance{name: "c5", project: "default", config: map[string]string{"volatile.last_state.power": instance.PowerStateRunning}},
+	}
+
+	expected := [][]string{
+		{"default/v1", "foo/c1"},
+		{"default/c2", "default/c3", "default/c5", "foo/c2"},
+		{"default/c4"},
+	}
+
+	assert.Equal(t, expected, autostartTestNames(instanceAutostartGroups(instances)))
+
+	// The order doesn't depend on the initial one.
+	for i, j := 0, len(instances)-1; i < j; i, j = i+1, j-1 {
+		instances[i], instances[j] = instances[j], instances[i]
+	}
+
+	assert.Equal(t, expected, autostartTestNames(instanceAutostartGroups(instances)))
+}
+
+// Test that instances which shouldn't be started are skipped.
+func TestInstanceAutostartGroups_Skipped(t *testing.T) {
+	instances := []instance.Instance{
+		&autostartTestInstance{name: "running", project: "default", config: map[string]string{"boot.autostart": "true"}, running: true},
+		&autostartTestInstance{name: "disabled", project: "default", config: map[string]string{"boot.autostart": "false", "volatile.last_state.power": instance.PowerStateRunning}},
+		&autostartTestInstance{name: "stopped", project: "default", config: map[string]string{}},
+	}
+
+	assert.Empty(t, instanceAutostartGroups(instances))
+}
+
+// shutdownTestInstance is a running instance recording how it's stopped on host shutdown.
+type shutdownTestInstance struct {
+	autostartTestInstance
+
+	record      *shutdownTestRecord
+	shutdownErr error
+}
+
+// shutdownTestRecord records the calls made to the instances, in order.
+type shutdownTestRecord struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *shutdownTestRecord) add(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, fmt.Sprintf(format, args...))
+}
+
+func (i *shutdownTestInstance) ID() int {
+	return 0
+}
+
+func (i *shutdownTestInstance) Shutdown(timeout time.Duration) error {
+	i.record.add("%s: shutdown %s", i.name, timeout.Round(time.Second))
+
+	return i.shutdownErr
+}
+
+func (i *shutdownTestInstance) Stop(stateful bool) error {
+	i.record.add("%s: stop stateful=%v", i.name, stateful)
+
+	return nil
+}
+
+// Test that instances are stopped by decreasing boot.stop.priority, falling back to the reverse of their
+// boot.autostart.priority.
+func TestInstancesShutdownOrder(t *testing.T) {
+	record := &shutdownTestRecord{}
+
+	newInstance := func(name string, config map[string]string) *shutdownTestInstance {
+		config["boot.host_shutdown_action"] = "force-stop"
+
+		return &shutdownTestInstance{
+			autostartTestInstance: autostartTestInstance{name: name, project: "default", config: config, running: true},
+			record:                record,
+		}
+	}
+
+	instances := []instance.Instance{
+		newInstance("db", map[string]string{"boot.autostart.priority": "10"}),
+		newInstance("web", map[string]string{"boot.autostart.priority": "5"}),
+		newInstance("proxy", map[string]string{}),
+		newInstance("backup", map[string]string{"boot.stop.priority": "5", "boot.autostart.priority": "100"}),
+		newInstance("worker", map[string]string{"boot.autostart.priority": "-1"}),
+	}
+
+	sort.Sort(instanceStopList(instances))
+
+	names := []string{}
+	for _, inst := range instances {
+		names = append(names, inst.Name())
+	}
+
+	assert.Equal(t, []string{"backup", "worker", "proxy", "web", "db"}, names)
+
+	// Each priority is its own batch, so instances are stopped one after the other.
+	instancesShutdown(instances, 0)
+
+	assert.Equal(t, []string{
+		"backup: stop stateful=false",
+		"worker: stop stateful=false",
+		"proxy: stop stateful=false",
+		"web: stop stateful=false",
+		"db: stop stateful=false",
+	}, record.calls)
+}
+
+// Test how a single instance is stopped on host shutdown depending on its config and the overall deadline.
+func TestInstanceHostShutdown(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]string
+		deadline    time.Duration
+		shutdownErr error
+		expected    []string
+	}{
+		{
+			name:     "Default timeout",
+			config:   map[string]string{},
+			expected: []string{"c1: shutdown 30s"},
+		},
+		{
+			name:     "Instance timeout",
+			config:   map[string]string{"boot.host_shutdown_timeout": "5"},
+			expected: []string{"c1: shutdown 5s"},
+		},
+		{
+			name:     "Capped by the deadline",
+			config:   map[string]string{"boot.host_shutdown_timeout": "120"},
+			deadline: 10 * time.Second,
+			expected: []string{"c1: shutdown 10s"},
+		},
+		{
+			name:     "Deadline reached",
+			config:   map[string]string{"boot.host_shutdown_action": "stateful-stop", "migration.stateful": "true"},
+			deadline: -time.Second,
+			expected: []string{"c1: stop stateful=false"},
+		},
+		{
+			name:     "Force stop",
+			config:   map[string]string{"boot.host_shutdown_action": "force-stop"},
+			expected: []string{"c1: stop stateful=false"},
+		},
+		{
+			name:     "Stateful stop",
+			config:   map[string]string{"boot.host_shutdown_action": "stateful-stop", "migration.stateful": "true"},
+			expected: []string{"c1: stop stateful=true"},
+		},
+		{
+			name:     "Stateful stop unsupported",
+			config:   map[string]string{"boot.host_shutdown_action": "stateful-stop"},
+			expected: []string{"c1: shutdown 30s"},
+		},
+		{
+			name:        "Failed shutdown",
+			config:      map[string]string{},
+			shutdownErr: errors.New("Timed out"),
+			expected:    []string{"c1: shutdown 30s", "c1: stop stateful=false"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &shutdownTestRecord{}
+			inst := &shutdownTestInstance{
+				autostartTestInstance: autostartTestInstance{name: "c1", project: "default", config: tt.config, running: true},
+				record:                record,
+				shutdownErr:           tt.shutdownErr,
+			}
+
+			var deadline time.Time
+			if tt.deadline != 0 {
+				deadline = time.Now().Add(tt.deadline)
+			}
+
+			instanceHostShutdown(inst, deadline)
+			assert.Equal(t, tt.expected, record.calls)
+		})
+	}
+}
@@ -138,6 +138,10 @@ func main() {
 	forksyscallCmd := cmdForksyscall{global: &globalCmd}
 	app.AddCommand(forksyscallCmd.command())
 
+	// forksysctl sub-command
+	forksysctlCmd := cmdForksysctl{global: &globalCmd}
+	app.AddCommand(forksysctlCmd.command())
+
 	// forkcoresched sub-command
 	forkcoreschedCmd := cmdForkcoresched{global: &globalCmd}
 	app.AddCommand(forkcoreschedCmd.command())
@@ -468,6 +468,29 @@ static void do_incus_forkumount(int pidfd, int ns_fd)
 	_exit(0);
 }
 
+static void do_incus_forkremount(int pidfd, int ns_fd)
+{
+	int ret;
+	char *path = NULL;
+	unsigned long flags = 0;
+
+	if (!change_namespaces(pidfd, ns_fd, CLONE_NEWNS)) {
+		fprintf(stderr, "Failed to setns to container mount namespace: %s\n", strerror(errno));
+		_exit(1);
+	}
+
+	path = advance_arg(true);
+	flags = strtoul(advance_arg(true), NULL, 10);
+
+	ret = mount(NULL, path, NULL, MS_REMOUNT | MS_BIND | flags, NULL);
+	if (ret < 0) {
+		fprintf(stderr, "Error remounting %s: %s\n", path, strerror(errno));
+		_exit(1);
+	}
+
+	_exit(0);
+}
+
 static void do_lxc_forkmount(void)
 {
 #if VERSION_AT_LEAST(3, 1, 0)
@@ -621,6 +644,22 @@ void forkmount(void)
 			_exit(EXIT_FAILURE);
 
 		do_incus_forkumount(pidfd, ns_fd);
+	} else if (strcmp(command, "go-remount") == 0) {
+		// Get the pid
+		cur = advance_arg(false);
+		if (cur == NULL || (strcmp(cur, "--help") == 0 || strcmp(cur, "--version") == 0 || strcmp(cur, "-h") == 0))
+			return;
+
+		pid = atoi(cur);
+		if (pid <= 0)
+			_exit(EXIT_FAILURE);
+
+		pidfd = atoi(advance_arg(true));
+		ns_fd = pidfd_nsfd(pidfd, pid);
+		if (ns_fd < 0)
+			_exit(EXIT_FAILURE);
+
+		do_incus_forkremount(pidfd, ns_fd);
 	} else if (strcmp(command, "lxc-umount") == 0) {
 		do_lxc_forkumount();
 	}
@@ -680,6 +719,13 @@ func (c *cmdForkmount) command() *cobra.Command {
 	cmdGoUmount.RunE = c.run
 	cmd.AddCommand(cmdGoUmount)
 
+	// remount
+	cmdGoRemount := &cobra.Command{}
+	cmdGoRemount.Use = "go-remount <PID> <PidFd> <path> <flags>"
+	cmdGoRemount.Args = cobra.ExactArgs(4)
+	cmdGoRemount.RunE = c.run
+	cmd.AddCommand(cmdGoRemount)
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
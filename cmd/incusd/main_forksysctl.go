@@ -0,0 +1,130 @@
+package main
+
+/*
+#include "config.h"
+
+#include <errno.h>
+#include <fcntl.h>
+#include <limits.h>
+#include <sched.h>
+#include <stdbool.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sys/types.h>
+#include <unistd.h>
+
+#include "file_utils.h"
+#include "incus.h"
+#include "memory_utils.h"
+
+void forksysctl(void)
+{
+	__do_close int ns_fd = -EBADF, fd = -EBADF;
+	char *cur = NULL, *nstype = NULL, *name = NULL, *value = NULL;
+	char path[PATH_MAX];
+	unsigned int flags;
+	int pidfd, ret;
+	pid_t pid;
+	size_t len;
+
+	// Get the PID.
+	cur = advance_arg(false);
+	if (cur == NULL || (strcmp(cur, "--help") == 0 || strcmp(cur, "--version") == 0 || strcmp(cur, "-h") == 0))
+		return;
+
+	// Check that we're root.
+	if (geteuid() != 0) {
+		fprintf(stderr, "Error: forksysctl requires root privileges\n");
+		_exit(1);
+	}
+
+	pid = atoi(cur);
+	pidfd = atoi(advance_arg(true));
+	nstype = advance_arg(true);
+	name = advance_arg(true);
+	value = advance_arg(true);
+
+	if (strcmp(nstype, "user") == 0)
+		flags = 0;
+	else if (strcmp(nstype, "net") == 0)
+		flags = CLONE_NEWNET;
+	else if (strcmp(nstype, "ipc") == 0)
+		flags = CLONE_NEWIPC;
+	else if (strcmp(nstype, "uts") == 0)
+		flags = CLONE_NEWUTS;
+	else {
+		fprintf(stderr, "Unsupported namespace \"%s\"\n", nstype);
+		_exit(1);
+	}
+
+	ns_fd = pidfd_nsfd(pidfd, pid);
+	if (ns_fd < 0) {
+		fprintf(stderr, "Failed to open the container namespaces: %s\n", strerror(-ns_fd));
+		_exit(1);
+	}
+
+	// Attach to the user namespace first so the write is subject to the same checks as from within the container.
+	attach_userns_fd(ns_fd);
+
+	if (flags && !change_namespaces(pidfd, ns_fd, flags)) {
+		fprintf(stderr, "Failed setns to container %s namespace: %s\n", nstype, strerror(errno));
+		_exit(1);
+	}
+
+	ret = snprintf(path, sizeof(path), "/proc/sys/%s", name);
+	if (ret < 0 || (size_t)ret >= sizeof(path)) {
+		fprintf(stderr, "Sysctl path is too long\n");
+		_exit(1);
+	}
+
+	fd = open(path, O_WRONLY | O_CLOEXEC);
+	if (fd < 0) {
+		fprintf(stderr, "Failed to open \"%s\": %s\n", path, strerror(errno));
+		_exit(1);
+	}
+
+	len = strlen(value);
+	if (write_nointr(fd, value, len) != (ssize_t)len) {
+		fprintf(stderr, "Failed to write \"%s\": %s\n", path, strerror(errno));
+		_exit(1);
+	}
+
+	_exit(EXIT_SUCCESS);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	// Used by cgo
+	_ "github.com/lxc/incus/v6/shared/cgo"
+)
+
+type cmdForksysctl struct {
+	global *cmdGlobal
+}
+
+func (c *cmdForksysctl) command() *cobra.Command {
+	// Main subcommand
+	cmd := &cobra.Command{}
+	cmd.Use = "forksysctl <PID> <PidFd> <namespace> <path> <value>"
+	cmd.Short = "Set a namespaced sysctl in a container"
+	cmd.Long = `Description:
+  Set a namespaced sysctl in a container
+
+  This command is used to change a sysctl of a running container from
+  within its user namespace and the namespace the sysctl is attached to.
+`
+	cmd.RunE = c.run
+	cmd.Hidden = true
+
+	return cmd
+}
+
+func (c *cmdForksysctl) run(_ *cobra.Command, _ []string) error {
+	return fmt.Errorf("This command should have been intercepted in cgo")
+}
@@ -344,6 +344,8 @@ __attribute__((constructor)) void init(void) {
 		forkproxy();
 	else if (strcmp(cmdline_cur, "forkuevent") == 0)
 		forkuevent();
+	else if (strcmp(cmdline_cur, "forksysctl") == 0)
+		forksysctl();
 	else if (strcmp(cmdline_cur, "forkcoresched") == 0)
 		forkcoresched();
 	else if (strcmp(cmdline_cur, "forkzfs") == 0) {
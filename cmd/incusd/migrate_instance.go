@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	internalIO "github.com/lxc/incus/v6/internal/io"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/instance/operationlock"
@@ -113,6 +114,12 @@ func (s *migrationSourceWs) do(migrateOp *operations.Operation) error {
 		return wsConn, nil
 	}
 
+	// Allow limiting the bandwidth used by the filesystem transfer while it runs.
+	rateLimiter := internalIO.NewRateLimiter(0)
+	if migrateOp != nil {
+		rateLimiter = migrateOp.RateLimiter()
+	}
+
 	filesystemConnFunc := func(ctx context.Context) (io.ReadWriteCloser, error) {
 		conn := s.conns[api.SecretNameFilesystem]
 		if conn == nil {
@@ -124,7 +131,7 @@ func (s *migrationSourceWs) do(migrateOp *operations.Operation) error {
 			return nil, fmt.Errorf("Failed getting migration source filesystem connection: %w", err)
 		}
 
-		return wsConn, nil
+		return rateLimiter.ReadWriteCloser(wsConn), nil
 	}
 
 	s.instance.SetOperation(migrateOp)
@@ -226,6 +226,11 @@ func (s *migrationSourceWs) DoStorage(state *state.State, projectName string, po
 		return err
 	}
 
+	// Allow limiting the bandwidth used by the transfer while it runs.
+	if migrateOp != nil {
+		fsConn = migrateOp.RateLimiter().ReadWriteCloser(fsConn)
+	}
+
 	err = pool.MigrateCustomVolume(projectName, fsConn, volSourceArgs, migrateOp)
 	if err != nil {
 		s.sendControl(err)
@@ -68,6 +68,18 @@ var networkLeasesCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: networkLeasesGet, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanView, "networkName")},
 }
 
+var networkLeaseCmd = APIEndpoint{
+	Path: "networks/{networkName}/leases/{address}",
+
+	Delete: APIEndpointAction{Handler: networkLeaseDelete, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanEdit, "networkName")},
+}
+
+var networkTraceCmd = APIEndpoint{
+	Path: "networks/{networkName}/trace",
+
+	Post: APIEndpointAction{Handler: networkTracePost, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanView, "networkName")},
+}
+
 var networkStateCmd = APIEndpoint{
 	Path: "networks/{networkName}/state",
 
@@ -1590,6 +1602,174 @@ func networkLeasesGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, leases)
 }
 
+// swagger:operation DELETE /1.0/networks/{networkName}/leases/{address} networks network_lease_delete
+//
+//	Delete the DHCP lease
+//
+//	Removes the dynamic DHCP leases matching the IP or MAC address from the network's DHCP server,
+//	releasing the addresses immediately.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkLeaseDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	address, err := url.PathUnescape(mux.Vars(r)["address"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Attempt to load the network.
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.LeaseDelete(address, clientType)
+	if err != nil {
+		if errors.Is(err, network.ErrNotImplemented) {
+			return response.BadRequest(fmt.Errorf("Network driver %q doesn't support deleting leases", n.Type()))
+		}
+
+		return response.SmartError(err)
+	}
+
+	if clientType != clusterRequest.ClientTypeNotifier {
+		s.Events.SendLifecycle(n.Project(), lifecycle.NetworkLeaseDeleted.Event(n, request.CreateRequestor(r), map[string]any{"address": address}))
+	}
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation POST /1.0/networks/{name}/trace networks networks_trace_post
+//
+//	Trace a packet
+//
+//	Traces the path of a packet through the logical flows of the network.
+//	This is only supported by OVN networks.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: trace
+//	    description: Packet to trace
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkTracePost"
+//	responses:
+//	  "200":
+//	    description: Trace
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkTrace"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkTracePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Parse the request.
+	req := api.NetworkTracePost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Attempt to load the network.
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	trace, err := n.Trace(reqProject.Name, req)
+	if err != nil {
+		if errors.Is(err, network.ErrNotImplemented) {
+			return response.BadRequest(fmt.Errorf("Network driver %q doesn't support packet tracing", n.Type()))
+		}
+
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, trace)
+}
+
 func networkStartup(s *state.State) error {
 	var err error
 
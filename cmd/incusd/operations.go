@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -35,6 +36,7 @@ var operationCmd = APIEndpoint{
 
 	Delete: APIEndpointAction{Handler: operationDelete, AccessHandler: allowAuthenticated},
 	Get:    APIEndpointAction{Handler: operationGet, AccessHandler: allowAuthenticated},
+	Put:    APIEndpointAction{Handler: operationPut, AccessHandler: allowAuthenticated},
 }
 
 var operationsCmd = APIEndpoint{
@@ -258,34 +260,9 @@ func operationDelete(d *Daemon, r *http.Request) response.Response {
 			projectName = api.ProjectDefaultName
 		}
 
-		objectType, entitlement := op.Permission()
-		if objectType != "" {
-			for _, v := range op.Resources() {
-				for _, u := range v {
-					// When dealing with specific objects, get the arguments from the URL.
-					var pathArgs []string
-
-					if objectType != auth.ObjectTypeProject {
-						var err error
-
-						_, _, _, pathArgs, err = dbCluster.URLToEntityType(u.String())
-						if err != nil {
-							return response.InternalError(fmt.Errorf("Unable to parse operation resource URL: %w", err))
-						}
-					}
-
-					// Check that the access is allowed.
-					object, err := auth.NewObject(objectType, projectName, pathArgs...)
-					if err != nil {
-						return response.InternalError(fmt.Errorf("Unable to create authorization object for operation: %w", err))
-					}
-
-					err = s.Authorizer.CheckPermission(r.Context(), r, object, entitlement)
-					if err != nil {
-						return response.SmartError(err)
-					}
-				}
-			}
+		resp := operationCheckPermission(s, r, op)
+		if resp != nil {
+			return resp
 		}
 
 		_, err = op.Cancel()
@@ -332,6 +309,140 @@ func operationDelete(d *Daemon, r *http.Request) response.Response {
 	return response.ForwardedResponse(client, r)
 }
 
+// operationCheckPermission checks that the requestor is allowed to act on the resources of a local operation.
+// It returns nil if access is allowed.
+func operationCheckPermission(s *state.State, r *http.Request, op *operations.Operation) response.Response {
+	projectName := op.Project()
+	if projectName == "" {
+		projectName = api.ProjectDefaultName
+	}
+
+	objectType, entitlement := op.Permission()
+	if objectType == "" {
+		return nil
+	}
+
+	for _, v := range op.Resources() {
+		for _, u := range v {
+			// When dealing with specific objects, get the arguments from the URL.
+			var pathArgs []string
+
+			if objectType != auth.ObjectTypeProject {
+				var err error
+
+				_, _, _, pathArgs, err = dbCluster.URLToEntityType(u.String())
+				if err != nil {
+					return response.InternalError(fmt.Errorf("Unable to parse operation resource URL: %w", err))
+				}
+			}
+
+			// Check that the access is allowed.
+			object, err := auth.NewObject(objectType, projectName, pathArgs...)
+			if err != nil {
+				return response.InternalError(fmt.Errorf("Unable to create authorization object for operation: %w", err))
+			}
+
+			err = s.Authorizer.CheckPermission(r.Context(), r, object, entitlement)
+			if err != nil {
+				return response.SmartError(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// swagger:operation PUT /1.0/operations/{id} operations operation_put
+//
+//	Update the operation
+//
+//	Updates the bandwidth limit of the operation if supported.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: operation
+//	    description: Operation configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/OperationPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func operationPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	id, err := url.PathUnescape(mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// First check if the query is for a local operation from this node
+	op, err := operations.OperationGetInternal(id)
+	if err == nil {
+		resp := operationCheckPermission(s, r, op)
+		if resp != nil {
+			return resp
+		}
+
+		req := api.OperationPut{}
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		err = op.Throttle(req.Bandwidth)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		return response.EmptySyncResponse
+	}
+
+	// Then check if the query is from an operation on another node, and, if so, forward it
+	var address string
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		filter := dbCluster.OperationFilter{UUID: &id}
+		ops, err := dbCluster.GetOperations(ctx, tx.Tx(), filter)
+		if err != nil {
+			return err
+		}
+
+		if len(ops) < 1 {
+			return api.StatusErrorf(http.StatusNotFound, "Operation not found")
+		}
+
+		if len(ops) > 1 {
+			return fmt.Errorf("More than one operation matches")
+		}
+
+		operation := ops[0]
+
+		address = operation.NodeAddress
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	client, err := cluster.Connect(address, s.Endpoints.NetworkCert(), s.ServerCert(), r, false)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.ForwardedResponse(client, r)
+}
+
 // operationCancel cancels an operation that exists on any member.
 func operationCancel(s *state.State, r *http.Request, projectName string, op *api.Operation) error {
 	// Check if operation is local and if so, cancel it.
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"slices"
@@ -16,22 +17,27 @@ import (
 
 	incus "github.com/lxc/incus/v6/client"
 	"github.com/lxc/incus/v6/internal/filter"
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/jmap"
 	"github.com/lxc/incus/v6/internal/server/auth"
 	"github.com/lxc/incus/v6/internal/server/cluster"
 	"github.com/lxc/incus/v6/internal/server/db"
 	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/revert"
 	"github.com/lxc/incus/v6/shared/util"
 )
 
@@ -42,6 +48,12 @@ var profilesCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: profilesPost, AccessHandler: allowPermission(auth.ObjectTypeProject, auth.EntitlementCanCreateProfiles)},
 }
 
+var profileAssignCmd = APIEndpoint{
+	Path: "profiles/{name}/assign",
+
+	Post: APIEndpointAction{Handler: profileAssignPost, AccessHandler: allowPermission(auth.ObjectTypeProfile, auth.EntitlementCanEdit, "name")},
+}
+
 var profileCmd = APIEndpoint{
 	Path: "profiles/{name}",
 
@@ -294,6 +306,41 @@ func profileUsedBy(ctx context.Context, tx *db.ClusterTx, profile dbCluster.Prof
 	return usedBy, nil
 }
 
+// profileUsedByDescription returns a human readable list of the instances using a profile from its (filtered)
+// used by list, mentioning the instances the requestor can't see only by their count.
+func profileUsedByDescription(projectName string, usedBy []string, total int) string {
+	const maxNames = 10
+
+	names := make([]string, 0, min(len(usedBy), maxNames))
+	for _, entry := range usedBy {
+		if len(names) == maxNames {
+			break
+		}
+
+		_, instProject, _, pathArgs, err := dbCluster.URLToEntityType(entry)
+		if err != nil || len(pathArgs) == 0 {
+			continue
+		}
+
+		if instProject != projectName {
+			names = append(names, fmt.Sprintf("%s (project %q)", pathArgs[0], instProject))
+		} else {
+			names = append(names, pathArgs[0])
+		}
+	}
+
+	description := fmt.Sprintf("%d instance(s)", total)
+	if len(names) > 0 {
+		description += ": " + strings.Join(names, ", ")
+
+		if total > len(names) {
+			description += fmt.Sprintf(" and %d more", total-len(names))
+		}
+	}
+
+	return description
+}
+
 // swagger:operation POST /1.0/profiles profiles profiles_post
 //
 //	Add a profile
@@ -600,6 +647,11 @@ func profilePut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	err = allowProfileSnapshotHooksChange(s, r, p.Name, name, profile.Config, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	err = doProfileUpdate(r.Context(), s, *p, name, profile, req)
 
 	if err == nil && !isClusterNotification(r) {
@@ -746,6 +798,11 @@ func profilePatch(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	err = allowProfileSnapshotHooksChange(s, r, p.Name, name, profile.Config, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(p.Name, lifecycle.ProfileUpdated.Event(name, p.Name, requestor, nil))
 
@@ -904,7 +961,7 @@ func profileDelete(d *Daemon, r *http.Request) response.Response {
 		}
 
 		if len(usedBy) > 0 {
-			return fmt.Errorf("Profile is currently in use")
+			return fmt.Errorf("Profile is currently in use by %s", profileUsedByDescription(p.Name, project.FilterUsedBy(s.Authorizer, r, usedBy), len(usedBy)))
 		}
 
 		return dbCluster.DeleteProfile(ctx, tx.Tx(), p.Name, name)
@@ -923,3 +980,376 @@ func profileDelete(d *Daemon, r *http.Request) response.Response {
 
 	return response.EmptySyncResponse
 }
+
+// swagger:operation POST /1.0/profiles/{name}/assign profiles profile_assign_post
+//
+//	Add the profile to instances
+//
+//	Adds the profile to all the provided instances at once.
+//	All the instances are validated first and the changes are reverted if any of them fails to update.
+//	The outcome for each instance is reported in the operation metadata.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: profile
+//	    description: Profile assignment request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ProfileAssignPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func profileAssignPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.ProfileAssignPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Quick checks.
+	if len(req.Instances) == 0 {
+		return response.BadRequest(fmt.Errorf("No instances provided"))
+	}
+
+	resources := map[string][]api.URL{}
+	resources["profiles"] = []api.URL{*api.NewURL().Path(version.APIVersion, "profiles", name)}
+
+	for i, instName := range req.Instances {
+		if slices.Contains(req.Instances[:i], instName) {
+			return response.BadRequest(fmt.Errorf("Instance %q provided more than once", instName))
+		}
+
+		// Check that the user can modify all the instances.
+		err = s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectInstance(projectName, instName), auth.EntitlementCanEdit)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		resources["instances"] = append(resources["instances"], *api.NewURL().Path(version.APIVersion, "instances", instName))
+	}
+
+	run := func(op *operations.Operation) error {
+		return doProfileAssign(s, r, op, projectName, name, req.Instances)
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.ProfileAssign, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// doProfileAssign adds the profile to all the instances, validating all of them first and reverting the
+// instances already updated if any of them fails. The outcome for each instance is reported in the operation
+// metadata.
+func doProfileAssign(s *state.State, r *http.Request, op *operations.Operation, projectName string, profileName string, instNames []string) error {
+	results := make([]api.ProfileAssignInstance, 0, len(instNames))
+	for _, instName := range instNames {
+		results = append(results, api.ProfileAssignInstance{Name: instName, Status: "pending"})
+	}
+
+	updateMetadata := func() {
+		err := op.UpdateMetadata(map[string]any{"instances": results})
+		if err != nil {
+			logger.Warn("Failed updating profile assignment operation metadata", logger.Ctx{"profile": profileName, "project": projectName, "err": err})
+		}
+	}
+
+	var profile api.Profile
+	var p *api.Project
+	insts := map[string]db.InstanceArgs{}
+
+	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProfiles, err := dbCluster.GetProfilesIfEnabled(ctx, tx.Tx(), projectName, []string{profileName})
+		if err != nil {
+			return err
+		}
+
+		if len(dbProfiles) != 1 {
+			return api.StatusErrorf(http.StatusNotFound, "Profile %q not found", profileName)
+		}
+
+		profiles, err := tx.GetProfiles(ctx, projectName, []string{profileName})
+		if err != nil {
+			return err
+		}
+
+		profile = profiles[0]
+
+		dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		p, err = dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		dbInstances := make([]dbCluster.Instance, 0, len(instNames))
+		for i, instName := range instNames {
+			dbInst, err := dbCluster.GetInstance(ctx, tx.Tx(), projectName, instName)
+			if err != nil {
+				results[i].Status = "failed"
+				results[i].Error = err.Error()
+				continue
+			}
+
+			dbInstances = append(dbInstances, *dbInst)
+		}
+
+		instArgs, err := tx.InstancesToInstanceArgs(ctx, true, dbInstances...)
+		if err != nil {
+			return err
+		}
+
+		for _, args := range instArgs {
+			insts[args.Name] = args
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Validate all the instances before modifying any of them.
+	reqs := map[string]api.InstancePut{}
+	currentConfigs := map[string]map[string]string{}
+	newProfiles := map[string][]string{}
+	oldProfiles := map[string][]string{}
+
+	for i := range results {
+		args, found := insts[results[i].Name]
+		if !found {
+			continue
+		}
+
+		profileNames := make([]string, 0, len(args.Profiles)+1)
+		for _, instProfile := range args.Profiles {
+			profileNames = append(profileNames, instProfile.Name)
+		}
+
+		if slices.Contains(profileNames, profileName) {
+			results[i].Status = "unchanged"
+			continue
+		}
+
+		err := profileAssignValidate(s, *p, args, profile)
+		if err != nil {
+			results[i].Status = "failed"
+			results[i].Error = err.Error()
+			continue
+		}
+
+		oldProfiles[args.Name] = profileNames
+		newProfiles[args.Name] = append(slices.Clone(profileNames), profileName)
+
+		reqs[args.Name] = api.InstancePut{
+			Config:   args.Config,
+			Devices:  args.Devices.CloneNative(),
+			Profiles: newProfiles[args.Name],
+		}
+
+		currentConfigs[args.Name] = args.Config
+	}
+
+	// Check the project limits with all the instances updated.
+	if len(reqs) > 0 {
+		err = s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return project.AllowInstancesUpdate(tx, projectName, reqs, currentConfigs)
+		})
+		if err != nil {
+			for i := range results {
+				if results[i].Status == "pending" {
+					results[i].Status = "failed"
+					results[i].Error = err.Error()
+				}
+			}
+		}
+	}
+
+	failures := 0
+	for _, result := range results {
+		if result.Status == "failed" {
+			failures++
+		}
+	}
+
+	updateMetadata()
+
+	if failures > 0 {
+		return fmt.Errorf("Failed validating %d instance(s), no instance was modified", failures)
+	}
+
+	// Apply the new profiles, reverting the instances already updated on failure.
+	assign := func(instName string, profileNames []string) error {
+		return doProfileAssignInstance(s, r, projectName, instName, profileNames)
+	}
+
+	return profileAssignApply(profileName, results, oldProfiles, newProfiles, assign, updateMetadata)
+}
+
+// profileAssignApply sets the new profiles on the pending instances, reverting the instances already updated
+// if any of them fails.
+func profileAssignApply(profileName string, results []api.ProfileAssignInstance, oldProfiles map[string][]string, newProfiles map[string][]string, assign func(instName string, profileNames []string) error, updateMetadata func()) error {
+	reverter := revert.New()
+	defer func() {
+		// Report the outcome of the revert in the operation metadata.
+		reverter.Fail()
+		updateMetadata()
+	}()
+
+	for i := range results {
+		if results[i].Status != "pending" {
+			continue
+		}
+
+		instName := results[i].Name
+
+		err := assign(instName, newProfiles[instName])
+		if err != nil {
+			results[i].Status = "failed"
+			results[i].Error = err.Error()
+
+			return fmt.Errorf("Failed adding profile %q to instance %q: %w", profileName, instName, err)
+		}
+
+		results[i].Status = "applied"
+		updateMetadata()
+
+		reverter.Add(func() {
+			err := assign(instName, oldProfiles[instName])
+			if err != nil {
+				logger.Error("Failed reverting profile assignment", logger.Ctx{"profile": profileName, "instance": instName, "err": err})
+				results[i].Error = fmt.Sprintf("Failed reverting: %v", err)
+				return
+			}
+
+			results[i].Status = "reverted"
+		})
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// profileAssignValidate checks that the instance config and devices remain valid once the profile is added.
+func profileAssignValidate(s *state.State, p api.Project, args db.InstanceArgs, profile api.Profile) error {
+	localDevices := args.Devices.CloneNative()
+
+	// Check for devices which would be silently overridden by the new profile.
+	conflicts := internalInstance.ProfileDeviceConflicts(localDevices, args.Profiles, profile)
+	if len(conflicts) > 0 {
+		devName := slices.Sorted(maps.Keys(conflicts))[0]
+		return fmt.Errorf("Device %q is already defined differently by profile %q", devName, conflicts[devName])
+	}
+
+	profiles := append(slices.Clone(args.Profiles), profile)
+
+	err := internalInstance.ValidateProfilePriorities(profiles)
+	if err != nil {
+		return err
+	}
+
+	expandedConfig, _ := internalInstance.ExpandConfig(args.Config, profiles)
+	err = instance.ValidConfig(s.OS, expandedConfig, true, instancetype.Any)
+	if err != nil {
+		return fmt.Errorf("Invalid config: %w", err)
+	}
+
+	expandedDevices, _ := internalInstance.ExpandDevices(localDevices, profiles)
+	err = instance.ValidDevices(s, p, args.Type, args.Devices, deviceConfig.NewDevices(expandedDevices))
+	if err != nil {
+		return fmt.Errorf("Invalid devices: %w", err)
+	}
+
+	return nil
+}
+
+// doProfileAssignInstance sets the profiles of an instance, forwarding the update to the cluster member
+// running it if needed.
+func doProfileAssignInstance(s *state.State, r *http.Request, projectName string, instName string, profileNames []string) error {
+	client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, instName, r)
+	if err != nil {
+		return err
+	}
+
+	if client != nil {
+		inst, etag, err := client.GetInstance(instName)
+		if err != nil {
+			return err
+		}
+
+		inst.Profiles = profileNames
+
+		op, err := client.UpdateInstance(instName, inst.Writable(), etag)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	}
+
+	unlock, err := instanceOperationLock(s.ShutdownCtx, projectName, instName)
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, instName)
+	if err != nil {
+		return err
+	}
+
+	var profiles []api.Profile
+
+	err = s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		profiles, err = tx.GetProfiles(ctx, projectName, profileNames)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return inst.Update(db.InstanceArgs{
+		Architecture: inst.Architecture(),
+		Config:       inst.LocalConfig(),
+		Description:  inst.Description(),
+		Devices:      inst.LocalDevices(),
+		Ephemeral:    inst.IsEphemeral(),
+		Profiles:     profiles,
+		Project:      inst.Project().Name,
+		Type:         inst.Type(),
+		Snapshot:     inst.IsSnapshot(),
+	}, true)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Test that a failure while assigning a profile reverts the instances already updated.
+func TestProfileAssignApplyRollback(t *testing.T) {
+	results := []api.ProfileAssignInstance{
+		{Name: "c1", Status: "pending"},
+		{Name: "c2", Status: "unchanged"},
+		{Name: "c3", Status: "pending"},
+		{Name: "c4", Status: "pending"},
+		{Name: "c5", Status: "pending"},
+	}
+
+	oldProfiles := map[string][]string{}
+	newProfiles := map[string][]string{}
+	current := map[string][]string{}
+	for _, name := range []string{"c1", "c3", "c4", "c5"} {
+		oldProfiles[name] = []string{"default"}
+		newProfiles[name] = []string{"default", "gpu"}
+		current[name] = oldProfiles[name]
+	}
+
+	var calls []string
+	assign := func(instName string, profileNames []string) error {
+		calls = append(calls, instName)
+
+		if instName == "c4" {
+			return errors.New("Instance is busy")
+		}
+
+		current[instName] = profileNames
+
+		return nil
+	}
+
+	err := profileAssignApply("gpu", results, oldProfiles, newProfiles, assign, func() {})
+	require.ErrorContains(t, err, `Failed adding profile "gpu" to instance "c4"`)
+
+	// The updated instances are reverted in reverse order and the following ones left alone.
+	assert.Equal(t, []string{"c1", "c3", "c4", "c3", "c1"}, calls)
+
+	for name, profiles := range current {
+		assert.Equal(t, []string{"default"}, profiles, name)
+	}
+
+	assert.Equal(t, []api.ProfileAssignInstance{
+		{Name: "c1", Status: "reverted"},
+		{Name: "c2", Status: "unchanged"},
+		{Name: "c3", Status: "reverted"},
+		{Name: "c4", Status: "failed", Error: "Instance is busy"},
+		{Name: "c5", Status: "pending"},
+	}, results)
+}
+
+// Test that a failed revert is reported on the instance.
+func TestProfileAssignApplyRollbackFailure(t *testing.T) {
+	results := []api.ProfileAssignInstance{
+		{Name: "c1", Status: "pending"},
+		{Name: "c2", Status: "pending"},
+	}
+
+	oldProfiles := map[string][]string{"c1": {"default"}, "c2": {"default"}}
+	newProfiles := map[string][]string{"c1": {"default", "gpu"}, "c2": {"default", "gpu"}}
+
+	assign := func(instName string, profileNames []string) error {
+		if instName == "c2" || len(profileNames) == 1 {
+			return errors.New("Instance is busy")
+		}
+
+		return nil
+	}
+
+	err := profileAssignApply("gpu", results, oldProfiles, newProfiles, assign, func() {})
+	require.Error(t, err)
+
+	assert.Equal(t, "applied", results[0].Status)
+	assert.Equal(t, "Failed reverting: Instance is busy", results[0].Error)
+	assert.Equal(t, "failed", results[1].Status)
+}
+
+// Test that all the pending instances are updated when none fails.
+func TestProfileAssignApply(t *testing.T) {
+	results := []api.ProfileAssignInstance{
+		{Name: "c1", Status: "pending"},
+		{Name: "c2", Status: "failed"},
+	}
+
+	newProfiles := map[string][]string{"c1": {"default", "gpu"}}
+
+	var assigned []string
+	assign := func(instName string, profileNames []string) error {
+		assigned = append(assigned, instName)
+		assert.Equal(t, newProfiles[instName], profileNames)
+
+		return nil
+	}
+
+	metadataUpdates := 0
+	err := profileAssignApply("gpu", results, map[string][]string{}, newProfiles, assign, func() { metadataUpdates++ })
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"c1"}, assigned)
+	assert.Equal(t, "applied", results[0].Status)
+	assert.Equal(t, "failed", results[1].Status)
+	assert.Positive(t, metadataUpdates)
+}
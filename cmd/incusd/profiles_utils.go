@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/server/db"
@@ -304,3 +305,25 @@ func getProfileInstancesInfo(ctx context.Context, dbCluster *db.Cluster, project
 
 	return instances, projects, nil
 }
+
+// allowProfileSnapshotHooksChange checks that the requestor may run commands in all the instances using the profile
+// when changing its snapshot hooks.
+func allowProfileSnapshotHooksChange(s *state.State, r *http.Request, projectName string, profileName string, oldConfig map[string]string, newConfig map[string]string) error {
+	if !snapshotHooksChanged(oldConfig, newConfig) {
+		return nil
+	}
+
+	insts, _, err := getProfileInstancesInfo(r.Context(), s.DB.Cluster, projectName, profileName)
+	if err != nil {
+		return fmt.Errorf("Failed to query instances associated with profile %q: %w", profileName, err)
+	}
+
+	for _, inst := range insts {
+		err := allowSnapshotHooksChange(s, r, inst.Project, inst.Name, oldConfig, newConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/response"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
 )
 
 var api10ResourcesCmd = APIEndpoint{
@@ -150,5 +151,11 @@ func storagePoolResourcesGet(d *Daemon, r *http.Request) response.Response {
 		return response.InternalError(err)
 	}
 
+	// Include the scrub status when supported by the driver.
+	res.Scrub, err = pool.ScrubStatus()
+	if err != nil {
+		logger.Warn("Failed getting scrub status", logger.Ctx{"pool": poolName, "err": err})
+	}
+
 	return response.SyncResponse(true, res)
 }
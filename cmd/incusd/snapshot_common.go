@@ -39,6 +39,21 @@ func snapshotIsScheduledNow(spec string, subjectID int64) bool {
 	return result
 }
 
+// instanceSnapshotIsScheduledNow returns whether the instance with the given expanded config is due a scheduled
+// snapshot. Instances with snapshots.enabled set to false never are.
+func instanceSnapshotIsScheduledNow(config map[string]string, instanceID int64) bool {
+	if util.IsFalse(config["snapshots.enabled"]) {
+		return false
+	}
+
+	schedule := config["snapshots.schedule"]
+	if schedule == "" {
+		return false
+	}
+
+	return snapshotIsScheduledNow(schedule, instanceID)
+}
+
 func buildCronSpecs(spec string, subjectID int64) []string {
 	var result []string
 
@@ -39,6 +39,35 @@ func (s *snapshotCommonTestSuite) TestSnapshotScheduling() {
 	op.Done(nil)
 }
 
+func (s *snapshotCommonTestSuite) TestInstanceSnapshotScheduling() {
+	s.True(instanceSnapshotIsScheduledNow(map[string]string{"snapshots.schedule": "* * * * *"}, 1))
+	s.True(instanceSnapshotIsScheduledNow(map[string]string{"snapshots.schedule": "* * * * *", "snapshots.enabled": "true"}, 1))
+	s.False(instanceSnapshotIsScheduledNow(map[string]string{"snapshots.schedule": "* * * * *", "snapshots.enabled": "false"}, 1))
+	s.False(instanceSnapshotIsScheduledNow(map[string]string{"snapshots.schedule": "@startup, * * * * *", "snapshots.enabled": "false"}, 1))
+	s.False(instanceSnapshotIsScheduledNow(map[string]string{}, 1))
+}
+
 func TestSnapshotCommon(t *testing.T) {
 	suite.Run(t, &snapshotCommonTestSuite{})
 }
+
+func TestSnapshotHooksChanged(t *testing.T) {
+	tests := []struct {
+		oldConfig map[string]string
+		newConfig map[string]string
+		changed   bool
+	}{
+		{nil, map[string]string{"limits.cpu": "2"}, false},
+		{map[string]string{"snapshots.hooks.pre": "sync"}, map[string]string{"snapshots.hooks.pre": "sync", "limits.cpu": "2"}, false},
+		{map[string]string{"snapshots.hooks.timeout": "10"}, map[string]string{"snapshots.hooks.timeout": "20"}, false},
+		{nil, map[string]string{"snapshots.hooks.pre": "sync"}, true},
+		{map[string]string{"snapshots.hooks.post": "true"}, map[string]string{"snapshots.hooks.post": "false"}, true},
+		{map[string]string{"snapshots.hooks.post": "true"}, nil, true},
+	}
+
+	for i, test := range tests {
+		if snapshotHooksChanged(test.oldConfig, test.newConfig) != test.changed {
+			t.Errorf("Test %d: expected changed to be %v", i, test.changed)
+		}
+	}
+}
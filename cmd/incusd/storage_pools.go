@@ -19,7 +19,9 @@ import (
 	"github.com/lxc/incus/v6/internal/server/cluster"
 	clusterRequest "github.com/lxc/incus/v6/internal/server/cluster/request"
 	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
 	"github.com/lxc/incus/v6/internal/server/lifecycle"
+	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
@@ -51,6 +53,18 @@ var storagePoolCmd = APIEndpoint{
 	Put:    APIEndpointAction{Handler: storagePoolPut, AccessHandler: allowPermission(auth.ObjectTypeStoragePool, auth.EntitlementCanEdit, "poolName")},
 }
 
+var storagePoolScrubCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/scrub",
+
+	Post: APIEndpointAction{Handler: storagePoolScrubPost, AccessHandler: allowPermission(auth.ObjectTypeStoragePool, auth.EntitlementCanEdit, "poolName")},
+}
+
+var storagePoolMigrateCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/migrate",
+
+	Post: APIEndpointAction{Handler: storagePoolMigratePost, AccessHandler: allowPermission(auth.ObjectTypeStoragePool, auth.EntitlementCanEdit, "poolName")},
+}
+
 // swagger:operation GET /1.0/storage-pools storage storage_pools_get
 //
 //  Get the storage pools
@@ -775,6 +789,8 @@ func storagePoolGet(d *Daemon, r *http.Request) response.Response {
 //	responses:
 //	  "200":
 //	    $ref: "#/responses/EmptySyncResponse"
+//	  "202":
+//	    $ref: "#/responses/Operation"
 //	  "400":
 //	    $ref: "#/responses/BadRequest"
 //	  "403":
@@ -860,7 +876,7 @@ func storagePoolPut(d *Daemon, r *http.Request) response.Response {
 
 	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
 
-	response := doStoragePoolUpdate(s, pool, req, targetNode, clientType, r.Method, s.ServerClustered)
+	response := doStoragePoolUpdate(s, r, pool, req, targetNode, clientType, r.Method, s.ServerClustered)
 
 	requestor := request.CreateRequestor(r)
 
@@ -905,6 +921,8 @@ func storagePoolPut(d *Daemon, r *http.Request) response.Response {
 //	responses:
 //	  "200":
 //	    $ref: "#/responses/EmptySyncResponse"
+//	  "202":
+//	    $ref: "#/responses/Operation"
 //	  "400":
 //	    $ref: "#/responses/BadRequest"
 //	  "403":
@@ -919,7 +937,7 @@ func storagePoolPatch(d *Daemon, r *http.Request) response.Response {
 
 // doStoragePoolUpdate takes the current local storage pool config, merges with the requested storage pool config,
 // validates and applies the changes. Will also notify other cluster nodes of non-node specific config if needed.
-func doStoragePoolUpdate(s *state.State, pool storagePools.Pool, req api.StoragePoolPut, targetNode string, clientType clusterRequest.ClientType, httpMethod string, clustered bool) response.Response {
+func doStoragePoolUpdate(s *state.State, r *http.Request, pool storagePools.Pool, req api.StoragePoolPut, targetNode string, clientType clusterRequest.ClientType, httpMethod string, clustered bool) response.Response {
 	if req.Config == nil {
 		req.Config = map[string]string{}
 	}
@@ -978,6 +996,23 @@ func doStoragePoolUpdate(s *state.State, pool storagePools.Pool, req api.Storage
 		}
 	}
 
+	// Growing a pool can take a while, so run it as a background operation.
+	if req.Config["size"] != pool.Driver().Config()["size"] {
+		run := func(op *operations.Operation) error {
+			return pool.Update(clientType, req.Description, req.Config, op)
+		}
+
+		resources := map[string][]api.URL{}
+		resources["storage_pools"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", pool.Name())}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.StoragePoolResize, resources, nil, run, nil, nil, r)
+		if err != nil {
+			return response.InternalError(err)
+		}
+
+		return operations.OperationResponse(op)
+	}
+
 	err = pool.Update(clientType, req.Description, req.Config, nil)
 	if err != nil {
 		return response.InternalError(err)
@@ -1139,3 +1174,65 @@ func storagePoolDelete(d *Daemon, r *http.Request) response.Response {
 
 	return response.EmptySyncResponse
 }
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/scrub storage storage_pool_scrub_post
+//
+//	Scrub the storage pool
+//
+//	Runs a data integrity check of the storage pool on the cluster member.
+//	If a scrub is already in progress, the operation waits for it to complete.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: server01
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolScrubPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// If a target was specified, forward the request to the relevant node.
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	do := func(op *operations.Operation) error {
+		return pool.Scrub(op)
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_pools"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", pool.Name())}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.StoragePoolScrub, resources, nil, do, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	// Cancelling the operation stops the scrub it started.
+	op.AllowRollback()
+
+	return operations.OperationResponse(op)
+}
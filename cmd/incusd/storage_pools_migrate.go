@@ -0,0 +1,581 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	incus "github.com/lxc/incus/v6/client"
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/server/auth"
+	clusterRequest "github.com/lxc/incus/v6/internal/server/cluster/request"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	instanceDrivers "github.com/lxc/incus/v6/internal/server/instance/drivers"
+	"github.com/lxc/incus/v6/internal/server/operations"
+	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
+	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/revert"
+)
+
+// storagePoolMigrateVolumeTypes lists the types of the volumes moved by a storage pool migration.
+var storagePoolMigrateVolumeTypes = []int{db.StoragePoolVolumeTypeContainer, db.StoragePoolVolumeTypeVM, db.StoragePoolVolumeTypeCustom}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/migrate storage storage_pool_migrate_post
+//
+//	Move all the volumes of the storage pool to another pool
+//
+//	Moves the instance and custom volumes of the storage pool on the cluster member to another storage pool,
+//	updating the instances and profiles using them. The volumes are moved one at a time and the ones already
+//	moved are left on the target pool if a later one fails while the failed one is left on the source pool only,
+//	so that the migration can be run again to move the remaining volumes.
+//
+//	Running instances using the volumes are only handled when requested, in which case they are stopped
+//	and started again once moved. Profile root disks are moved to the target pool once no instance volume is
+//	left on the source pool.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: server01
+//	  - in: body
+//	    name: storage pool
+//	    description: Storage pool migration request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/StoragePoolMigratePost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolMigratePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// If a target was specified, forward the request to the relevant node.
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.StoragePoolMigratePost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Quick checks.
+	if req.Pool == "" {
+		return response.BadRequest(fmt.Errorf("No target storage pool provided"))
+	}
+
+	if req.Pool == poolName {
+		return response.BadRequest(fmt.Errorf("Source and target storage pools must be different"))
+	}
+
+	// Check that the user can also modify the target pool.
+	err = s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectStoragePool(req.Pool), auth.EntitlementCanEdit)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	srcPool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	dstPool, err := storagePools.LoadByName(s, req.Pool)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if dstPool.LocalStatus() != api.StoragePoolStatusCreated {
+		return response.BadRequest(fmt.Errorf("Storage pool %q isn't available on this server", dstPool.Name()))
+	}
+
+	volumes, err := storagePoolMigrateVolumes(s, srcPool, dstPool, req.DeleteSource)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	running, err := storagePoolMigrateRunningInstances(s, srcPool, volumes)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if len(running) > 0 && !req.Stop {
+		return response.BadRequest(fmt.Errorf("Instance %q in project %q is running, its volumes can only be moved once stopped", running[0].Name(), running[0].Project().Name))
+	}
+
+	run := func(op *operations.Operation) error {
+		return doStoragePoolMigrate(s, op, srcPool, dstPool, volumes, running, req.DeleteSource)
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_pools"] = []api.URL{
+		*api.NewURL().Path(version.APIVersion, "storage-pools", srcPool.Name()),
+		*api.NewURL().Path(version.APIVersion, "storage-pools", dstPool.Name()),
+	}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.StoragePoolMigrate, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// storagePoolMigrateVolumes returns the instance and custom volumes of the source pool on this server, checking
+// that all of them can be moved to the target pool.
+// When the source pool is to be deleted, it also checks that the pool has no volume left on other cluster members.
+func storagePoolMigrateVolumes(s *state.State, srcPool storagePools.Pool, dstPool storagePools.Pool, deleteSource bool) ([]*db.StorageVolume, error) {
+	var volumes []*db.StorageVolume
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var poolVolumes []*db.StorageVolume
+		instanceLocations := map[string]string{}
+
+		for _, volType := range storagePoolMigrateVolumeTypes {
+			dbVolumes, err := tx.GetStoragePoolVolumes(ctx, srcPool.ID(), !deleteSource, db.StorageVolumeFilter{Type: &volType})
+			if err != nil {
+				return fmt.Errorf("Failed loading storage volumes: %w", err)
+			}
+
+			for _, vol := range dbVolumes {
+				// Instance volumes on remote pools have no location, use the one of their instance instead.
+				if vol.Location == "" && vol.Type != db.StoragePoolVolumeTypeNameCustom && !internalInstance.IsSnapshot(vol.Name) {
+					inst, err := dbCluster.GetInstance(ctx, tx.Tx(), vol.Project, vol.Name)
+					if err != nil {
+						return fmt.Errorf("Failed loading instance %q in project %q: %w", vol.Name, vol.Project, err)
+					}
+
+					instanceLocations[vol.Project+"/"+vol.Name] = inst.Node
+				}
+			}
+
+			poolVolumes = append(poolVolumes, dbVolumes...)
+		}
+
+		var err error
+		volumes, err = storagePoolMigrateLocalVolumes(s.ServerName, srcPool.Name(), poolVolumes, instanceLocations, deleteSource)
+		if err != nil {
+			return err
+		}
+
+		// Check that the custom volumes don't conflict with existing ones on the target pool.
+		for _, vol := range volumes {
+			if vol.Type != db.StoragePoolVolumeTypeNameCustom {
+				continue
+			}
+
+			_, err := tx.GetStoragePoolNodeVolumeID(ctx, vol.Project, vol.Name, db.StoragePoolVolumeTypeCustom, dstPool.ID())
+			if err == nil {
+				return api.StatusErrorf(http.StatusConflict, "Custom volume %q in project %q already exists in storage pool %q", vol.Name, vol.Project, dstPool.Name())
+			} else if !response.IsNotFoundError(err) {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that the target pool can host all the volumes.
+	for _, vol := range volumes {
+		volDBType, err := storagePools.VolumeTypeNameToDBType(vol.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		volType, err := storagePools.VolumeDBTypeToType(volDBType)
+		if err != nil {
+			return nil, err
+		}
+
+		contentDBType, err := storagePools.VolumeContentTypeNameToContentType(vol.ContentType)
+		if err != nil {
+			return nil, err
+		}
+
+		contentType, err := storagePools.VolumeDBContentTypeToContentType(contentDBType)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(dstPool.Driver().Info().VolumeTypes, volType) || len(dstPool.Driver().MigrationTypes(contentType, false, true, false, true)) == 0 {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Storage pool %q can't host %s volume %q in project %q with content type %q", dstPool.Name(), vol.Type, vol.Name, vol.Project, vol.ContentType)
+		}
+
+		if volDBType == db.StoragePoolVolumeTypeCustom {
+			used, err := storagePools.VolumeUsedByDaemon(s, srcPool.Name(), vol.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			if used {
+				return nil, api.StatusErrorf(http.StatusBadRequest, "Custom volume %q is used by Incus itself and cannot be moved", vol.Name)
+			}
+		}
+	}
+
+	return volumes, nil
+}
+
+// storagePoolMigrateLocalVolumes returns the volumes to be moved by the migration running on this server.
+// Instance volumes are located using the given instance locations (keyed by project and name) when the volume
+// itself has no location. Volumes located on other cluster members are skipped, unless the source pool is to be
+// deleted in which case an error is returned.
+func storagePoolMigrateLocalVolumes(serverName string, poolName string, poolVolumes []*db.StorageVolume, instanceLocations map[string]string, deleteSource bool) ([]*db.StorageVolume, error) {
+	var volumes []*db.StorageVolume
+
+	for _, vol := range poolVolumes {
+		// Snapshots are moved along with their parent volume.
+		if internalInstance.IsSnapshot(vol.Name) {
+			continue
+		}
+
+		location := vol.Location
+		if location == "" && vol.Type != db.StoragePoolVolumeTypeNameCustom {
+			location = instanceLocations[vol.Project+"/"+vol.Name]
+		}
+
+		if location != "" && location != serverName {
+			if deleteSource {
+				return nil, api.StatusErrorf(http.StatusBadRequest, "Storage pool %q still has volumes on cluster member %q", poolName, location)
+			}
+
+			continue
+		}
+
+		volumes = append(volumes, vol)
+	}
+
+	return volumes, nil
+}
+
+// storagePoolMigrateRunningInstances returns the running instances whose root volume is among the volumes or
+// which use any of the custom volumes.
+func storagePoolMigrateRunningInstances(s *state.State, srcPool storagePools.Pool, volumes []*db.StorageVolume) ([]instance.Instance, error) {
+	var running []instance.Instance
+
+	addRunning := func(inst instance.Instance) {
+		if !inst.IsRunning() {
+			return
+		}
+
+		for _, runningInst := range running {
+			if runningInst.Project().Name == inst.Project().Name && runningInst.Name() == inst.Name() {
+				return
+			}
+		}
+
+		running = append(running, inst)
+	}
+
+	for _, vol := range volumes {
+		if vol.Type != db.StoragePoolVolumeTypeNameCustom {
+			inst, err := instance.LoadByProjectAndName(s, vol.Project, vol.Name)
+			if err != nil {
+				return nil, fmt.Errorf("Failed loading instance %q in project %q: %w", vol.Name, vol.Project, err)
+			}
+
+			addRunning(inst)
+			continue
+		}
+
+		err := storagePools.VolumeUsedByInstanceDevices(s, srcPool.Name(), vol.Project, &vol.StorageVolume, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+			inst, err := instance.Load(s, dbInst, project)
+			if err != nil {
+				return err
+			}
+
+			addRunning(inst)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return running, nil
+}
+
+// doStoragePoolMigrate moves the volumes from the source pool to the target pool, stopping the running instances
+// beforehand and starting them again once done.
+func doStoragePoolMigrate(s *state.State, op *operations.Operation, srcPool storagePools.Pool, dstPool storagePools.Pool, volumes []*db.StorageVolume, running []instance.Instance, deleteSource bool) error {
+	setProgress := func(message string) {
+		err := op.UpdateMetadata(map[string]any{"migrate_progress": message})
+		if err != nil {
+			logger.Warn("Failed updating storage pool migration operation metadata", logger.Ctx{"pool": srcPool.Name(), "err": err})
+		}
+	}
+
+	// Start the stopped instances again once done, whether or not all their volumes could be moved.
+	var stopped []instance.Instance
+	defer func() {
+		for _, inst := range stopped {
+			// The instance is reloaded as it gets recreated when its root volume is moved.
+			movedInst, err := instance.LoadByProjectAndName(s, inst.Project().Name, inst.Name())
+			if err == nil {
+				err = movedInst.Start(false)
+			}
+
+			if err != nil {
+				logger.Warn("Failed starting instance after storage pool migration", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name, "err": err})
+			}
+		}
+	}()
+
+	for _, inst := range running {
+		setProgress(fmt.Sprintf("Stopping instance %q", inst.Name()))
+
+		// Get the shutdown timeout for the instance.
+		timeout := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
+		val, err := strconv.Atoi(timeout)
+		if err != nil {
+			val = evacuateHostShutdownDefaultTimeout
+		}
+
+		// Start with a clean shutdown, falling back to a forced stop.
+		err = inst.Shutdown(time.Duration(val) * time.Second)
+		if err != nil {
+			err = inst.Stop(false)
+			if err != nil && !errors.Is(err, instanceDrivers.ErrInstanceIsStopped) {
+				return fmt.Errorf("Failed stopping instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+			}
+		}
+
+		stopped = append(stopped, inst)
+	}
+
+	for i, vol := range volumes {
+		if vol.Type == db.StoragePoolVolumeTypeNameCustom {
+			setProgress(fmt.Sprintf("Moving custom volume %q (%d/%d)", vol.Name, i+1, len(volumes)))
+
+			err := storagePoolMigrateCustomVolume(s, op, srcPool, dstPool, vol)
+			if err != nil {
+				return fmt.Errorf("Failed moving custom volume %q in project %q: %w", vol.Name, vol.Project, err)
+			}
+
+			continue
+		}
+
+		message := fmt.Sprintf("Moving instance %q (%d/%d)", vol.Name, i+1, len(volumes))
+		setProgress(message)
+
+		inst, err := instance.LoadByProjectAndName(s, vol.Project, vol.Name)
+		if err != nil {
+			return fmt.Errorf("Failed loading instance %q in project %q: %w", vol.Name, vol.Project, err)
+		}
+
+		// Report the transfer progress along with the overall progress.
+		handler := func(newOp api.Operation) {
+			for key, value := range newOp.Metadata {
+				if strings.HasSuffix(key, "_progress") {
+					setProgress(fmt.Sprintf("%s: %v", message, value))
+					return
+				}
+			}
+		}
+
+		err = migrateInstance(context.TODO(), s, inst, api.InstancePost{Pool: dstPool.Name()}, nil, nil, "", op, handler)
+		if err != nil {
+			return fmt.Errorf("Failed moving instance %q in project %q: %w", vol.Name, vol.Project, err)
+		}
+	}
+
+	setProgress("Updating profiles")
+
+	err := storagePoolMigrateProfiles(context.TODO(), s, srcPool, dstPool)
+	if err != nil {
+		return err
+	}
+
+	if deleteSource {
+		setProgress(fmt.Sprintf("Deleting storage pool %q", srcPool.Name()))
+
+		// Get a local client.
+		args := &incus.ConnectionArgs{
+			SkipGetServer: true,
+			UserAgent:     clusterRequest.UserAgentClient,
+		}
+
+		client, err := incus.ConnectIncusUnix(s.OS.GetUnixSocket(), args)
+		if err != nil {
+			return err
+		}
+
+		err = client.DeleteStoragePool(srcPool.Name())
+		if err != nil {
+			return fmt.Errorf("Failed deleting storage pool %q: %w", srcPool.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// storagePoolMigrateCustomVolume moves a custom volume and its snapshots to the target pool, updating the
+// instances and profiles using it.
+func storagePoolMigrateCustomVolume(s *state.State, op *operations.Operation, srcPool storagePools.Pool, dstPool storagePools.Pool, vol *db.StorageVolume) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// Provide empty description and nil config to instruct CreateCustomVolumeFromCopy to copy it
+	// from source volume.
+	err := dstPool.CreateCustomVolumeFromCopy(vol.Project, vol.Project, vol.Name, "", nil, srcPool.Name(), vol.Name, true, op)
+	if err != nil {
+		return err
+	}
+
+	// Drop the copy on failure, leaving the source volume as the only one so that the migration can be
+	// run again.
+	reverter.Add(func() {
+		err := dstPool.DeleteCustomVolume(vol.Project, vol.Name, nil)
+		if err != nil {
+			logger.Warn("Failed deleting custom volume copy after failed storage pool migration", logger.Ctx{"pool": dstPool.Name(), "project": vol.Project, "volume": vol.Name, "err": err})
+		}
+	})
+
+	// Update devices using the volume in instances and profiles, restoring them on failure as some may
+	// have been updated already.
+	reverter.Add(func() {
+		_ = storagePoolVolumeUpdateUsers(context.TODO(), s, vol.Project, dstPool.Name(), &vol.StorageVolume, srcPool.Name(), &vol.StorageVolume)
+	})
+
+	err = storagePoolVolumeUpdateUsers(context.TODO(), s, vol.Project, srcPool.Name(), &vol.StorageVolume, dstPool.Name(), &vol.StorageVolume)
+	if err != nil {
+		return err
+	}
+
+	// The copy is kept from now on as a failed deletion may have already removed part of the source volume.
+	reverter.Success()
+
+	err = srcPool.DeleteCustomVolume(vol.Project, vol.Name, op)
+	if err != nil {
+		return fmt.Errorf("Failed deleting source volume after copying it to storage pool %q: %w", dstPool.Name(), err)
+	}
+
+	return nil
+}
+
+// storagePoolMigrateProfiles changes the root disk of the profiles using the source pool to the target pool.
+// The profiles are left untouched while the source pool still holds instance volumes, such as on other cluster
+// members, as those instances rely on them.
+func storagePoolMigrateProfiles(ctx context.Context, s *state.State, srcPool storagePools.Pool, dstPool storagePools.Pool) error {
+	var profiles []api.Profile
+	var profileProjects []api.Project
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		for _, volType := range []int{db.StoragePoolVolumeTypeContainer, db.StoragePoolVolumeTypeVM} {
+			dbVolumes, err := tx.GetStoragePoolVolumes(ctx, srcPool.ID(), false, db.StorageVolumeFilter{Type: &volType})
+			if err != nil {
+				return fmt.Errorf("Failed loading storage volumes: %w", err)
+			}
+
+			if len(dbVolumes) > 0 {
+				return nil
+			}
+		}
+
+		projects, err := dbCluster.GetProjects(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading projects: %w", err)
+		}
+
+		projectMap := make(map[string]*api.Project, len(projects))
+		for _, p := range projects {
+			projectMap[p.Name], err = p.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return fmt.Errorf("Failed loading config for project %q: %w", p.Name, err)
+			}
+		}
+
+		dbProfiles, err := dbCluster.GetProfiles(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading profiles: %w", err)
+		}
+
+		profileConfigs, err := dbCluster.GetAllProfileConfigs(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading profile configs: %w", err)
+		}
+
+		profileDevices, err := dbCluster.GetAllProfileDevices(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading profile devices: %w", err)
+		}
+
+		for _, dbProfile := range dbProfiles {
+			profile, err := dbProfile.ToAPI(ctx, tx.Tx(), profileConfigs, profileDevices)
+			if err != nil {
+				return fmt.Errorf("Failed getting API Profile %q: %w", dbProfile.Name, err)
+			}
+
+			_, rootDev, err := internalInstance.GetRootDiskDevice(profile.Devices)
+			if err != nil || rootDev["pool"] != srcPool.Name() {
+				continue
+			}
+
+			profiles = append(profiles, *profile)
+			profileProjects = append(profileProjects, *projectMap[dbProfile.Project])
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, profile := range profiles {
+		devices := deviceConfig.NewDevices(profile.Devices).CloneNative()
+		rootDevKey, _, _ := internalInstance.GetRootDiskDevice(devices)
+		devices[rootDevKey]["pool"] = dstPool.Name()
+
+		req := api.ProfilePut{
+			Config:      profile.Config,
+			Description: profile.Description,
+			Devices:     devices,
+		}
+
+		err = doProfileUpdate(ctx, s, profileProjects[i], profile.Name, &profile, req)
+		if err != nil {
+			return fmt.Errorf("Failed updating profile %q in project %q: %w", profile.Name, profileProjects[i].Name, err)
+		}
+	}
+
+	return nil
+}
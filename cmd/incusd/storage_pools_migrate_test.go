@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestStoragePoolMigrateLocalVolumes(t *testing.T) {
+	newVolume := func(volType string, name string, location string) *db.StorageVolume {
+		return &db.StorageVolume{StorageVolume: api.StorageVolume{Type: volType, Name: name, Project: "default", Location: location}}
+	}
+
+	names := func(volumes []*db.StorageVolume) []string {
+		result := []string{}
+		for _, vol := range volumes {
+			result = append(result, vol.Name)
+		}
+
+		return result
+	}
+
+	// Local pool, the volumes carry their location.
+	localVolumes := []*db.StorageVolume{
+		newVolume(db.StoragePoolVolumeTypeNameContainer, "c1", "server01"),
+		newVolume(db.StoragePoolVolumeTypeNameContainer, "c1/snap0", "server01"),
+		newVolume(db.StoragePoolVolumeTypeNameContainer, "c2", "server02"),
+		newVolume(db.StoragePoolVolumeTypeNameCustom, "vol1", "server01"),
+	}
+
+	volumes, err := storagePoolMigrateLocalVolumes("server01", "pool1", localVolumes, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c1", "vol1"}, names(volumes))
+
+	_, err = storagePoolMigrateLocalVolumes("server01", "pool1", localVolumes, nil, true)
+	assert.ErrorContains(t, err, `still has volumes on cluster member "server02"`)
+
+	// Remote pool, instance volumes are located through their instance and custom volumes are shared.
+	remoteVolumes := []*db.StorageVolume{
+		newVolume(db.StoragePoolVolumeTypeNameContainer, "c1", ""),
+		newVolume(db.StoragePoolVolumeTypeNameVM, "v1", ""),
+		newVolume(db.StoragePoolVolumeTypeNameVM, "v1/snap0", ""),
+		newVolume(db.StoragePoolVolumeTypeNameCustom, "vol1", ""),
+	}
+
+	instanceLocations := map[string]string{
+		"default/c1": "server01",
+		"default/v1": "server02",
+	}
+
+	volumes, err = storagePoolMigrateLocalVolumes("server01", "pool1", remoteVolumes, instanceLocations, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c1", "vol1"}, names(volumes))
+
+	volumes, err = storagePoolMigrateLocalVolumes("server02", "pool1", remoteVolumes, instanceLocations, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1", "vol1"}, names(volumes))
+
+	_, err = storagePoolMigrateLocalVolumes("server01", "pool1", remoteVolumes, instanceLocations, true)
+	assert.ErrorContains(t, err, `still has volumes on cluster member "server02"`)
+
+	// Standalone server.
+	instanceLocations = map[string]string{"default/c1": "none", "default/v1": "none"}
+	volumes, err = storagePoolMigrateLocalVolumes("none", "pool1", remoteVolumes, instanceLocations, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c1", "v1", "vol1"}, names(volumes))
+}
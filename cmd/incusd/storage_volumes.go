@@ -36,6 +36,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/response"
 	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	storageDrivers "github.com/lxc/incus/v6/internal/server/storage/drivers"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/internal/version"
@@ -647,7 +648,7 @@ func storagePoolVolumesPost(d *Daemon, r *http.Request) response.Response {
 			return createStoragePoolVolumeFromISO(s, r, request.ProjectParam(r), projectName, r.Body, poolName, r.Header.Get("X-Incus-name"))
 		}
 
-		return createStoragePoolVolumeFromBackup(s, r, request.ProjectParam(r), projectName, r.Body, poolName, r.Header.Get("X-Incus-name"))
+		return createStoragePoolVolumeFromBackup(s, r, request.ProjectParam(r), projectName, r.Body, poolName, r.Header.Get("X-Incus-name"), r.Header.Get("X-Incus-encryption-passphrase"))
 	}
 
 	req := api.StorageVolumesPost{}
@@ -1274,6 +1275,11 @@ func storagePoolVolumePost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// This is a content type conversion request.
+	if req.ContentType != "" {
+		return storagePoolVolumeTypePostConvert(s, r, srcPoolName, projectName, volumeName, req)
+	}
+
 	// This is a migration request so send back requested secrets.
 	if req.Migration {
 		return storagePoolVolumeTypePostMigration(s, r, request.ProjectParam(r), projectName, srcPoolName, volumeName, req)
@@ -1584,6 +1590,55 @@ func storagePoolVolumeTypePostRename(s *state.State, r *http.Request, poolName s
 	return response.SyncResponseLocation(true, nil, u.String())
 }
 
+// storagePoolVolumeTypePostConvert handles volume content type conversion POST requests.
+func storagePoolVolumeTypePostConvert(s *state.State, r *http.Request, poolName string, projectName string, volumeName string, req api.StorageVolumePost) response.Response {
+	if req.Name != volumeName || (req.Pool != "" && req.Pool != poolName) || req.Project != "" || req.Migration {
+		return response.BadRequest(fmt.Errorf("Converting a volume can't be combined with renaming, moving or migrating it"))
+	}
+
+	contentDBType, err := storagePools.VolumeContentTypeNameToContentType(req.ContentType)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	contentType, err := storagePools.VolumeDBContentTypeToContentType(contentDBType)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Converting to a filesystem volume mounts the filesystem found on the block volume on the host.
+	// As it may have been crafted by an instance, only allow server administrators to do so explicitly.
+	if contentType == storageDrivers.ContentTypeFS {
+		if !req.AllowHostMount {
+			return response.BadRequest(fmt.Errorf("Converting to a filesystem volume mounts the block volume's filesystem on the host and must be explicitly allowed"))
+		}
+
+		err = s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectServer(), auth.EntitlementCanEdit)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	run := func(op *operations.Operation) error {
+		return pool.ConvertCustomVolume(projectName, volumeName, contentType, op)
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_volumes"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "volumes", db.StoragePoolVolumeTypeNameCustom, volumeName).Project(projectName)}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.VolumeConvert, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
 // storagePoolVolumeTypePostMove handles volume move type POST requests.
 func storagePoolVolumeTypePostMove(s *state.State, r *http.Request, poolName string, requestProjectName string, projectName string, vol *api.StorageVolume, req api.StorageVolumePost) response.Response {
 	newVol := *vol
@@ -2187,9 +2242,25 @@ func storagePoolVolumeDelete(d *Daemon, r *http.Request) response.Response {
 		return usedBy.Path == img.URL.Path
 	}
 
+	// Instance snapshots referencing the volume don't prevent its deletion.
+	volumeUsedBy = slices.DeleteFunc(volumeUsedBy, func(entry string) bool {
+		usedBy, _ := url.Parse(entry)
+		return usedBy != nil && strings.HasPrefix(usedBy.Path, "/"+version.APIVersion+"/instances/") && strings.Contains(usedBy.Path, "/snapshots/")
+	})
+
 	if len(volumeUsedBy) > 0 {
 		if len(volumeUsedBy) != 1 || volumeType != db.StoragePoolVolumeTypeImage || !isImageURL(volumeUsedBy[0], dbVolume.Name) {
-			return response.BadRequest(fmt.Errorf("The storage volume is still in use"))
+			// Only mention the entities the user can see.
+			descriptions := []string{}
+			for _, entry := range project.FilterUsedBy(s.Authorizer, r, volumeUsedBy) {
+				descriptions = append(descriptions, internalUtil.UsedByDescription(entry))
+			}
+
+			if len(descriptions) == 0 {
+				return response.BadRequest(fmt.Errorf("The storage volume is still in use"))
+			}
+
+			return response.BadRequest(fmt.Errorf("The storage volume is still in use by %s", strings.Join(descriptions, ", ")))
 		}
 	}
 
@@ -2278,7 +2349,7 @@ func createStoragePoolVolumeFromISO(s *state.State, r *http.Request, requestProj
 	return operations.OperationResponse(op)
 }
 
-func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestProjectName string, projectName string, data io.Reader, pool string, volName string) response.Response {
+func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestProjectName string, projectName string, data io.Reader, pool string, volName string, passphrase string) response.Response {
 	reverter := revert.New()
 	defer reverter.Fail()
 
@@ -2291,6 +2362,12 @@ func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestP
 	defer func() { _ = os.Remove(backupFile.Name()) }()
 	reverter.Add(func() { _ = backupFile.Close() })
 
+	// Decrypt the uploaded backup data as it's received if needed.
+	data, err = backupDecryptReader(data, passphrase)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
 	// Stream uploaded backup data into temporary file.
 	_, err = io.Copy(backupFile, data)
 	if err != nil {
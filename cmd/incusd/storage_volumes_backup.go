@@ -27,6 +27,7 @@ import (
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/archive"
 	"github.com/lxc/incus/v6/shared/logger"
 )
 
@@ -428,6 +429,14 @@ func storagePoolVolumeTypeCustomBackupsPost(d *Daemon, r *http.Request) response
 		return response.BadRequest(fmt.Errorf("Backup names may not contain slashes"))
 	}
 
+	// Validate the passphrase before starting the backup.
+	if req.EncryptionPassphrase != "" {
+		err = archive.ValidateEncryptionPassphrase(req.EncryptionPassphrase)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
 	fullName := volumeName + internalInstance.SnapshotDelimiter + req.Name
 	volumeOnly := req.VolumeOnly
 
@@ -440,6 +449,7 @@ func storagePoolVolumeTypeCustomBackupsPost(d *Daemon, r *http.Request) response
 			VolumeOnly:           volumeOnly,
 			OptimizedStorage:     req.OptimizedStorage,
 			CompressionAlgorithm: req.CompressionAlgorithm,
+			EncryptionPassphrase: req.EncryptionPassphrase,
 		}
 
 		err := volumeBackupCreate(s, args, projectName, poolName, volumeName)
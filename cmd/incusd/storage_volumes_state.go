@@ -1,23 +1,32 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"slices"
+	"time"
 
 	"github.com/gorilla/mux"
 
 	"github.com/lxc/incus/v6/internal/server/auth"
 	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/request"
 	"github.com/lxc/incus/v6/internal/server/response"
+	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
 	storageDrivers "github.com/lxc/incus/v6/internal/server/storage/drivers"
+	"github.com/lxc/incus/v6/internal/server/task"
+	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/units"
 )
 
 var storagePoolVolumeTypeStateCmd = APIEndpoint{
@@ -164,3 +173,143 @@ func storagePoolVolumeTypeStateGet(d *Daemon, r *http.Request) response.Response
 
 	return response.SyncResponse(true, state)
 }
+
+// storageVolumeSoftLimitTask returns a task which periodically checks the usage of custom volumes against their
+// soft limit (size.soft_limit).
+func storageVolumeSoftLimitTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := storageVolumesCheckSoftLimit(ctx, d.State())
+		if err != nil {
+			logger.Error("Failed checking storage volume soft limits", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(5 * time.Minute)
+}
+
+// storageVolumesCheckSoftLimit raises a warning for every custom volume handled by this member whose usage is
+// above its soft limit, and resolves the warnings of the volumes which are back below it.
+func storageVolumesCheckSoftLimit(ctx context.Context, s *state.State) error {
+	var volumes []db.StorageVolumeArgs
+	var warnings []dbCluster.Warning
+	var onlineMemberIDs []int64
+	var memberCount int
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		allVolumes, err := tx.GetStoragePoolVolumesWithType(ctx, db.StoragePoolVolumeTypeCustom, true)
+		if err != nil {
+			return fmt.Errorf("Failed getting custom volumes: %w", err)
+		}
+
+		for _, v := range allVolumes {
+			if v.Config["size.soft_limit"] != "" {
+				volumes = append(volumes, v)
+			}
+		}
+
+		members, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		memberCount = len(members)
+		for _, member := range members {
+			if member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+				continue
+			}
+
+			onlineMemberIDs = append(onlineMemberIDs, member.ID)
+		}
+
+		localName, err := tx.GetLocalNodeName(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting local member name: %w", err)
+		}
+
+		typeCode := warningtype.StorageVolumeSoftLimitExceeded
+		warnings, err = dbCluster.GetWarnings(ctx, tx.Tx(), dbCluster.WarningFilter{Node: &localName, TypeCode: &typeCode})
+		if err != nil {
+			return fmt.Errorf("Failed getting warnings: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	localMemberID := s.DB.Cluster.GetNodeID()
+	exceeded := map[int]bool{}
+
+	for _, v := range volumes {
+		// If there are multiple cluster members, remote volumes are checked by a stable random online
+		// member to avoid raising the same warning on every member.
+		if v.NodeID < 0 && memberCount > 1 {
+			selectedMemberID, err := localUtil.GetStableRandomInt64FromList(v.ID, onlineMemberIDs)
+			if err != nil || localMemberID != selectedMemberID {
+				continue
+			}
+		}
+
+		l := logger.AddContext(logger.Ctx{"project": v.ProjectName, "pool": v.PoolName, "volume": v.Name})
+
+		pool, err := storagePools.LoadByName(s, v.PoolName)
+		if err != nil {
+			l.Warn("Failed loading storage pool", logger.Ctx{"err": err})
+			continue
+		}
+
+		dbVol, err := storagePools.VolumeDBGet(pool, v.ProjectName, v.Name, storageDrivers.VolumeTypeCustom)
+		if err != nil {
+			l.Warn("Failed loading storage volume", logger.Ctx{"err": err})
+			continue
+		}
+
+		vol := pool.GetVolume(storageDrivers.VolumeTypeCustom, storageDrivers.ContentType(dbVol.ContentType), project.StorageVolume(v.ProjectName, v.Name), dbVol.Config)
+
+		softLimit, err := vol.ConfigSizeSoftLimit()
+		if err != nil {
+			l.Warn("Invalid storage volume soft limit", logger.Ctx{"err": err})
+			continue
+		}
+
+		usage, err := pool.GetCustomVolumeUsage(v.ProjectName, v.Name)
+		if err != nil {
+			if !errors.Is(err, storageDrivers.ErrNotSupported) {
+				l.Warn("Failed getting storage volume usage", logger.Ctx{"err": err})
+			}
+
+			continue
+		}
+
+		if !storageDrivers.VolumeSoftLimitExceeded(usage.Used, softLimit) {
+			continue
+		}
+
+		exceeded[int(v.ID)] = true
+
+		msg := fmt.Sprintf("Volume %q in pool %q uses %s, above its soft limit of %s", v.Name, v.PoolName, units.GetByteSizeStringIEC(usage.Used, 2), units.GetByteSizeStringIEC(softLimit, 2))
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarningLocalNode(ctx, v.ProjectName, dbCluster.TypeStorageVolume, int(v.ID), warningtype.StorageVolumeSoftLimitExceeded, msg)
+		})
+		if err != nil {
+			l.Warn("Failed raising storage volume soft limit warning", logger.Ctx{"err": err})
+		}
+	}
+
+	// Resolve the warnings of volumes which are no longer above their soft limit (or no longer exist).
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		for _, w := range warnings {
+			if w.Status == warningtype.StatusResolved || exceeded[w.EntityID] {
+				continue
+			}
+
+			err := tx.UpdateWarningStatus(w.UUID, warningtype.StatusResolved)
+			if err != nil {
+				return fmt.Errorf("Failed resolving warning %q: %w", w.UUID, err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/lxc/incus/v6/internal/server/backup"
 	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
@@ -129,6 +130,14 @@ func storagePoolVolumeUsedByGet(s *state.State, requestProjectName string, poolN
 		return []string{}, err
 	}
 
+	err = storagePools.VolumeUsedByInstanceSnapshotDevices(s, poolName, vol.Project, &vol.StorageVolume, func(snap dbCluster.InstanceSnapshot, usedByDevices []string) error {
+		volumeUsedBy = append(volumeUsedBy, api.NewURL().Path(version.APIVersion, "instances", snap.Instance, "snapshots", snap.Name).Project(snap.Project).String())
+		return nil
+	})
+	if err != nil {
+		return []string{}, err
+	}
+
 	err = storagePools.VolumeUsedByProfileDevices(s, poolName, requestProjectName, &vol.StorageVolume, func(profileID int64, profile api.Profile, p api.Project, usedByDevices []string) error {
 		volumeUsedBy = append(volumeUsedBy, api.NewURL().Path(version.APIVersion, "profiles", profile.Name).Project(p.Name).String())
 		return nil
@@ -5,9 +5,11 @@ type InstanceAction string
 
 // InstanceAction types.
 const (
-	Stop     InstanceAction = "stop"
-	Start    InstanceAction = "start"
-	Restart  InstanceAction = "restart"
-	Freeze   InstanceAction = "freeze"
-	Unfreeze InstanceAction = "unfreeze"
+	Stop      InstanceAction = "stop"
+	Start     InstanceAction = "start"
+	Restart   InstanceAction = "restart"
+	Freeze    InstanceAction = "freeze"
+	Unfreeze  InstanceAction = "unfreeze"
+	SyncClock InstanceAction = "sync-clock"
+	Hibernate InstanceAction = "hibernate"
 )
@@ -3,10 +3,15 @@ package instance
 import (
 	"errors"
 	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/lxc/incus/v6/internal/server/logship"
 	scriptletLoad "github.com/lxc/incus/v6/internal/server/scriptlet/load"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/units"
@@ -56,6 +61,9 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 
 	// gendoc:generate(entity=instance, group=boot, key=boot.autostart.priority)
 	// The instance with the highest value is started first.
+	// Instances with the same priority are started in order of project and name, and only once all
+	// instances with a higher priority are started.
+	// See {config:option}`server-miscellaneous:instances.autostart.concurrency` to start several instances at the same time.
 	// ---
 	//  type: integer
 	//  defaultdesc: 0
@@ -63,11 +71,45 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What order to start the instances in
 	"boot.autostart.priority": validate.Optional(validate.IsInt64),
 
+	// gendoc:generate(entity=instance, group=boot, key=boot.restart.policy)
+	// Possible values are `never`, `on-failure` (restart when the instance stops unexpectedly) and `always`
+	// (restart whenever the instance stops on its own).
+	// `on-failure` is only supported on virtual machines, as containers don't report how they exited.
+	// Stopping the instance through Incus never triggers a restart.
+	// When set, this takes precedence over {config:option}`instance-boot:boot.autorestart`.
+	// ---
+	//  type: string
+	//  defaultdesc: `never`
+	//  liveupdate: yes
+	//  shortdesc: When to automatically restart the instance after it stopped
+	"boot.restart.policy": validate.Optional(validate.IsOneOf("never", "on-failure", "always")),
+
+	// gendoc:generate(entity=instance, group=boot, key=boot.restart.max_retries)
+	// Number of restarts done by {config:option}`instance-boot:boot.restart.policy` after which the instance is left stopped.
+	// The count is reset whenever the instance is started by the user. Set to `0` for no limit.
+	// ---
+	//  type: integer
+	//  defaultdesc: 10
+	//  liveupdate: yes
+	//  shortdesc: Maximum number of automatic restarts
+	"boot.restart.max_retries": validate.Optional(validate.IsUint32),
+
+	// gendoc:generate(entity=instance, group=boot, key=boot.restart.backoff)
+	// Number of seconds to wait before the first automatic restart.
+	// The delay doubles on each subsequent restart, up to 5 minutes.
+	// ---
+	//  type: integer
+	//  defaultdesc: 1
+	//  liveupdate: yes
+	//  shortdesc: Delay before automatically restarting the instance
+	"boot.restart.backoff": validate.Optional(validate.IsUint32),
+
 	// gendoc:generate(entity=instance, group=boot, key=boot.stop.priority)
 	// The instance with the highest value is shut down first.
+	// When not set, instances are shut down in the reverse order of {config:option}`instance-boot:boot.autostart.priority`.
 	// ---
 	//  type: integer
-	//  defaultdesc: 0
+	//  defaultdesc: opposite of `boot.autostart.priority`
 	//  liveupdate: no
 	//  shortdesc: What order to shut down the instances in
 	"boot.stop.priority": validate.Optional(validate.IsInt64),
@@ -76,6 +118,8 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	// Action to take on host shut down
 	//
 	// Valid values are: `stop`, `force-stop` or `stateful-stop`
+	//
+	// Instances that can't be stopped statefully (see {config:option}`instance-migration:migration.stateful`) are shut down instead.
 	// ---
 	//  type: string
 	//  defaultdesc: stop
@@ -92,6 +136,16 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: How long to wait for the instance to shut down
 	"boot.host_shutdown_timeout": validate.Optional(validate.IsInt64),
 
+	// gendoc:generate(entity=instance, group=boot, key=boot.ready_timeout)
+	// Number of seconds to wait on start for the managed networks and storage pools used by the instance to become ready on the server.
+	// The start fails if they're still unavailable once the timeout expires.
+	// ---
+	//  type: integer
+	//  defaultdesc: 0
+	//  liveupdate: yes
+	//  shortdesc: How long to wait for networks and storage pools to be ready on start
+	"boot.ready_timeout": validate.Optional(validate.IsUint32),
+
 	// gendoc:generate(entity=instance, group=cloud-init, key=cloud-init.network-config)
 	// The content is used as seed value for `cloud-init`.
 	// ---
@@ -149,6 +203,28 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  condition: If supported by image
 	//  shortdesc: Legacy version of `cloud-init.vendor-data`
 
+	// gendoc:generate(entity=instance, group=dns, key=dns.hostname)
+	// When set, this is used as the hostname inside the instance instead of the instance name.
+	// It can either be a short hostname or a fully qualified domain name.
+	// Managed networks register the instance in their DNS under the first label of this value.
+	// ---
+	//  type: string
+	//  defaultdesc: instance name
+	//  liveupdate: no
+	//  shortdesc: Hostname to use inside the instance
+	"dns.hostname": validate.Optional(validateHostname),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=cluster.anti_affinity)
+	// Instances of a project sharing the same anti-affinity group are spread across the cluster members
+	// when being placed, preferring members in a different failure domain than the other instances of the group.
+	//
+	// See {ref}`cluster-anti-affinity` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Anti-affinity group used for placement
+	"cluster.anti_affinity": validate.Optional(validate.IsURLSegmentSafe),
+
 	// gendoc:generate(entity=instance, group=miscellaneous, key=cluster.evacuate)
 	// The `cluster.evacuate` provides control over how instances are handled when a cluster member is being
 	// evacuated.
@@ -177,6 +253,66 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What to do when evacuating the instance
 	"cluster.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "stop", "stateful-stop", "force-stop")),
 
+	// gendoc:generate(entity=instance, group=miscellaneous, key=console.log.size)
+	// When the console log file reaches this size, it is rotated and a new one is started.
+	// The limit applies to the console output of containers and the serial output of virtual machines.
+	// ---
+	//  type: string
+	//  defaultdesc: no limit
+	//  liveupdate: yes
+	//  shortdesc: Maximum size of the console log file before rotating it
+	"console.log.size": validate.Optional(validate.IsSize),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=console.log.rotate)
+	// Rotated console log files are named `console.log.1`, `console.log.2` and so on, the first one being the most recent.
+	// Set to `0` to discard the console log when it reaches its maximum size instead.
+	// ---
+	//  type: integer
+	//  defaultdesc: `1`
+	//  liveupdate: yes
+	//  shortdesc: Number of rotated console log files to keep
+	"console.log.rotate": validate.Optional(validate.IsUint32),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=console.log.compress)
+	//
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Whether to compress rotated console log files with `gzip`
+	"console.log.compress": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=console.log.forward)
+	// The console output of containers and the serial output of virtual machines is forwarded line by line,
+	// either to the host's `journald` (`journald`) or to a remote syslog server (`udp://<host>[:<port>]` or `tcp://<host>[:<port>]`).
+	// Set to `none` to disable forwarding when it's enabled at the project level.
+	// See {ref}`instance-options-console-forward` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: value of the project's `console.log.forward`
+	//  liveupdate: no
+	//  shortdesc: Where to forward the console output of the instance
+	"console.log.forward": validate.Optional(validateConsoleLogForward),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=metrics.enabled)
+	// When disabled, the instance is skipped entirely when gathering metrics.
+	// See {ref}`metrics-exclude` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: value of the server's `instances.metrics.enabled`
+	//  liveupdate: yes
+	//  shortdesc: Whether to collect metrics for the instance
+	"metrics.enabled": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=metrics.exclude_devices)
+	// Filesystem metrics aren't collected for the listed disk devices and network metrics aren't collected for the listed NIC devices.
+	// See {ref}`metrics-exclude` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Comma-separated list of devices to exclude from metrics
+	"metrics.exclude_devices": validate.Optional(validate.IsListOf(validate.IsDeviceName)),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu)
 	// A number or a specific range of CPUs to expose to the instance.
 	//
@@ -250,6 +386,15 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 		return nil
 	},
 
+	// gendoc:generate(entity=instance, group=miscellaneous, key=linux.kernel_modules)
+	// Specify the kernel modules as a comma-separated list.
+	// The modules are loaded on the host before any of the instance's devices are started.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Kernel modules to load before starting the instance
+	"linux.kernel_modules": validate.Optional(validate.IsListOf(validate.IsKernelModuleName)),
+
 	// gendoc:generate(entity=instance, group=migration, key=migration.stateful)
 	// Enabling this option prevents the use of some features that are incompatible with it.
 	// ---
@@ -296,6 +441,16 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Prevents the instance from being deleted
 	"security.protection.delete": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.enabled)
+	// When set to `false`, both manual and scheduled snapshots of the instance are refused.
+	// Existing snapshots can still be restored, copied and deleted.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: yes
+	//  shortdesc: Whether snapshots of the instance can be created
+	"snapshots.enabled": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.schedule)
 	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-and-space-separated list of schedule aliases (`@startup`, `@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic snapshots.
 	//
@@ -329,6 +484,34 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Template for the snapshot name
 	"snapshots.pattern": validate.IsAny,
 
+	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.hooks.pre)
+	// Command run through `/bin/sh -c` inside of the instance before a snapshot is taken, for example to flush and lock a database.
+	// If the command fails or times out, the snapshot is aborted.
+	// Setting the hooks requires the permission to execute commands in the instance, they are low-level options in restricted projects.
+	// The hook only runs when the instance is running and, for virtual machines, requires the agent.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Command to run in the instance before taking a snapshot
+	"snapshots.hooks.pre": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.hooks.post)
+	// Command run through `/bin/sh -c` inside of the instance after a snapshot was taken (or failed to be taken), for example to unlock a database.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Command to run in the instance after taking a snapshot
+	"snapshots.hooks.post": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.hooks.timeout)
+	// Number of seconds to wait for each snapshot hook to complete before it is killed, along with any process it started.
+	// ---
+	//  type: integer
+	//  defaultdesc: `30`
+	//  liveupdate: yes
+	//  shortdesc: Timeout for the snapshot hooks
+	"snapshots.hooks.timeout": validate.Optional(validate.IsUint32),
+
 	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.expiry)
 	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
 	// ---
@@ -393,6 +576,13 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Instance state as of last host shutdown
 	"volatile.last_state.power": validate.IsAny,
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.last_state.hibernated)
+	//
+	// ---
+	//  type: bool
+	//  shortdesc: Whether the virtual machine state was saved to disk by hibernation
+	"volatile.last_state.hibernated": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.last_state.ready)
 	//
 	// ---
@@ -407,6 +597,13 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Timestamp of last move by automatic live-migration
 	"volatile.rebalance.last_move": validate.Optional(validate.IsInt64),
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.restart.count)
+	//
+	// ---
+	//  type: integer
+	//  shortdesc: Number of restarts done by the restart policy since the instance was last started
+	"volatile.restart.count": validate.Optional(validate.IsUint32),
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.uuid)
 	// The instance UUID is globally unique across all servers and projects.
 	// ---
@@ -534,6 +731,8 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.enforce)
 	// If the instance's memory limit is `hard`, the instance cannot exceed its limit.
 	// If it is `soft`, the instance can exceed its memory limit when extra host memory is available.
+	// Requires `limits.memory` to be set.
+	// See {ref}`instance-options-limits-memory-container` for more information.
 	// ---
 	//  type: string
 	//  defaultdesc: `hard`
@@ -546,6 +745,7 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	// When set to `true` or `false`, it controls whether the container is likely to get some of
 	// its memory swapped by the kernel. Alternatively, it can be set to a bytes value which will
 	// then allow the container to make use of additional memory through swap.
+	// See {ref}`instance-options-limits-memory-container` for more information.
 	// ---
 	//  type: string
 	//  defaultdesc: `true`
@@ -575,14 +775,16 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Maximum number of processes that can run in the instance
 	"limits.processes": validate.Optional(validate.IsInt64),
 
-	// gendoc:generate(entity=instance, group=miscellaneous, key=linux.kernel_modules)
-	// Specify the kernel modules as a comma-separated list.
+	// gendoc:generate(entity=instance, group=miscellaneous, key=linux.network_namespace)
+	// Specify either the name of a network namespace in `/run/netns` (as created by `ip netns add`) or its absolute path.
+	// The container joins this existing network namespace instead of getting its own, so it can't have any NIC devices.
+	// Incus doesn't manage the network interfaces of the namespace and never deletes it.
 	// ---
 	//  type: string
-	//  liveupdate: yes
+	//  liveupdate: no
 	//  condition: container
-	//  shortdesc: Kernel modules to load before starting the instance
-	"linux.kernel_modules": validate.IsAny,
+	//  shortdesc: Existing network namespace to run the container in
+	"linux.network_namespace": validate.Optional(validateNetworkNamespace),
 
 	// gendoc:generate(entity=instance, group=migration, key=migration.incremental.memory)
 	// Using incremental memory transfer of the instance's memory can reduce downtime.
@@ -690,6 +892,16 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 
 	// Caller is responsible for full validation of any raw.* value.
 
+	// gendoc:generate(entity=instance, group=raw, key=raw.cgroup)
+	// Values to write to cgroup v2 files of the container after the `limits.*` options were applied, one `<file> = <value>` entry per line.
+	// Only resource control files of the `cpu`, `io`, `memory` and `pids` controllers can be set, see {ref}`instance-options-raw-cgroup`.
+	// ---
+	//  type: blob
+	//  liveupdate: yes
+	//  condition: container
+	//  shortdesc: Raw cgroup configuration applied after the generated limits
+	"raw.cgroup": validate.IsAny,
+
 	// gendoc:generate(entity=instance, group=raw, key=raw.lxc)
 	//
 	// ---
@@ -708,6 +920,17 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Raw Seccomp configuration
 	"raw.seccomp": validate.IsAny,
 
+	// gendoc:generate(entity=instance, group=security, key=security.apparmor.profile)
+	// Name of an AppArmor profile loaded on the host to confine the container with, instead of the generated profile.
+	// The profile must allow everything the container needs to run, see {ref}`instance-options-security-apparmor`.
+	// This option can't be combined with `raw.apparmor`.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Custom AppArmor profile
+	"security.apparmor.profile": validate.Optional(validateAppArmorProfileName),
+
 	// gendoc:generate(entity=instance, group=security, key=security.guestapi.images)
 	//
 	// ---
@@ -920,6 +1143,27 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Whether to handle the `sysinfo` system call
 	"security.syscalls.intercept.sysinfo": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=security, key=security.syscalls.profile)
+	// A JSON document describing a custom seccomp profile.
+	// It contains a list of `rules`, each with a list of syscall `names`, an `action` (`allow`, `errno`, `kill`, `trap` or `log`) and an optional `errno`.
+	// This option can't be combined with `raw.seccomp` or `security.syscalls.allow`.
+	// ---
+	//  type: blob
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Custom seccomp profile
+	"security.syscalls.profile": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=security, key=security.syscalls.profile.mode)
+	// Set this option to `extend` to merge the custom profile with the default policy (allow rules remove matching default entries) or to `replace` to use the custom profile instead of the default policy.
+	// ---
+	//  type: string
+	//  defaultdesc: `extend`
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: How to combine the custom seccomp profile with the default policy
+	"security.syscalls.profile.mode": validate.Optional(validate.IsOneOf("extend", "replace")),
+
 	"security.syscalls.whitelist": validate.IsAny,
 
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.container.oci)
@@ -961,6 +1205,30 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 
 // InstanceConfigKeysVM is a map of config key to validator. (keys applying to VM only).
 var InstanceConfigKeysVM = map[string]func(value string) error{
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.nested)
+	// Exposes the `vmx` (Intel) or `svm` (AMD) CPU flag to the guest so it can run its own virtual machines.
+	// Nested virtualization must be enabled in the host's `kvm_intel` or `kvm_amd` kernel module.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether to enable nested virtualization
+	"limits.nested": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.model)
+	// Either `host` to pass the host CPU through, or the name of a QEMU CPU model (for example `EPYC-Rome`),
+	// optionally followed by a comma-separated list of CPU features to enable (`+feature`) or disable (`-feature`).
+	// A named CPU model allows live migration between hosts with different CPUs.
+	// See {ref}`instance-options-limits-cpu-model` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `host` or the cluster group CPU baseline
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: CPU model and features exposed to the VM
+	"limits.cpu.model": validate.Optional(validateCPUModel),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.hugepages)
 	// If this option is set to `false`, regular system memory is used.
 	// ---
@@ -971,6 +1239,27 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to back the instance using huge pages
 	"limits.memory.hugepages": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.rng.max_bytes)
+	// Maximum amount of entropy the VM can read from {config:option}`instance-miscellaneous:rng.source`
+	// over each {config:option}`instance-resource-limits:limits.rng.period`.
+	// ---
+	//  type: string
+	//  defaultdesc: no limit
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Rate limit of the random number generator
+	"limits.rng.max_bytes": validate.Optional(validate.IsSize),
+
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.rng.period)
+	// Only used when {config:option}`instance-resource-limits:limits.rng.max_bytes` is set.
+	// ---
+	//  type: integer
+	//  defaultdesc: `1000`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Period of the random number generator rate limit (in milliseconds)
+	"limits.rng.period": validate.Optional(validate.IsInRange(1, math.MaxUint32)),
+
 	// Caller is responsible for full validation of any raw.* value.
 
 	// gendoc:generate(entity=instance, group=raw, key=raw.qemu)
@@ -991,6 +1280,39 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Addition/override to the generated `qemu.conf` file
 	"raw.qemu.conf": validate.IsAny,
 
+	// gendoc:generate(entity=instance, group=raw, key=raw.qemu.hook.post-start)
+	// Path to a script on the host run after the VM has started.
+	// Its output, if any, must be a JSON list of QMP commands which are then run against the VM.
+	// See {ref}`instance-options-qemu-hooks` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Hook script to run after the VM has started
+	"raw.qemu.hook.post-start": validate.Optional(validate.IsAbsFilePath),
+
+	// gendoc:generate(entity=instance, group=raw, key=raw.qemu.hook.pre-start)
+	// Path to a script on the host run before the QEMU process is started.
+	// Its output is appended to the QEMU command line.
+	// See {ref}`instance-options-qemu-hooks` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Hook script generating additional QEMU arguments
+	"raw.qemu.hook.pre-start": validate.Optional(validate.IsAbsFilePath),
+
+	// gendoc:generate(entity=instance, group=raw, key=raw.qemu.hook.pre-stop)
+	// Path to a script on the host run before the VM is stopped or shut down.
+	// Its output, if any, must be a JSON list of QMP commands which are then run against the VM.
+	// See {ref}`instance-options-qemu-hooks` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Hook script to run before the VM is stopped
+	"raw.qemu.hook.pre-stop": validate.Optional(validate.IsAbsFilePath),
+
 	// gendoc:generate(entity=instance, group=raw, key=raw.qemu.qmp.early)
 	//
 	// ---
@@ -1118,6 +1440,49 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to use the name and MTU of the default network interfaces
 	"agent.nic_config": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=miscellaneous, key=agent.sync_clock)
+	// The clock of a virtual machine doesn't account for the time it wasn't running.
+	// Set this option to `true` to set it to the host time through the `incus-agent` after the instance is resumed, restored from a stateful snapshot or stop, or live-migrated.
+	// The clock can also be set at any time with the `sync-clock` action.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to set the clock of the instance after it's resumed
+	"agent.sync_clock": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=rng.source)
+	// The host device to read entropy from for the VM's `virtio-rng` device, one of `/dev/urandom`, `/dev/random`
+	// or `/dev/hwrng` to use a hardware random number generator.
+	// The source must exist and be readable when the instance starts.
+	// ---
+	//  type: string
+	//  defaultdesc: `/dev/urandom`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Host entropy source of the random number generator
+	"rng.source": validate.Optional(validate.IsOneOf("/dev/urandom", "/dev/random", "/dev/hwrng")),
+
+	// gendoc:generate(entity=instance, group=dns, key=dns.nameservers)
+	// The DNS servers are applied by the guest agent when the instance starts and whenever the value is changed.
+	// On systems using `systemd-resolved`, they're configured through a drop-in configuration file, otherwise `/etc/resolv.conf` is rewritten.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Comma-separated list of DNS server addresses to use inside the instance
+	"dns.nameservers": validate.Optional(validate.IsListOf(validate.IsNetworkAddress)),
+
+	// gendoc:generate(entity=instance, group=dns, key=dns.search)
+	// The search domains are applied by the guest agent alongside {config:option}`instance-dns:dns.nameservers`.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Comma-separated list of DNS search domains to use inside the instance
+	"dns.search": validate.Optional(validate.IsListOf(validateDNSDomain)),
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.apply_nvram)
 	//
 	// ---
@@ -1125,6 +1490,13 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to regenerate VM NVRAM the next time the instance starts
 	"volatile.apply_nvram": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.cpu.model)
+	//
+	// ---
+	//  type: string
+	//  shortdesc: CPU model resolved from `limits.cpu.model` (used for subsequent starts)
+	"volatile.cpu.model": validate.Optional(validateCPUModel),
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.vm.definition)
 	//
 	// ---
@@ -1140,6 +1512,141 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	"volatile.vsock_id": validate.Optional(validate.IsInt64),
 }
 
+// validateCPUModel validates a CPU model name, optionally followed by CPU features to enable (+) or disable (-).
+func validateCPUModel(value string) error {
+	fields := strings.Split(value, ",")
+
+	if !cpuModelNameRegex.MatchString(fields[0]) {
+		return fmt.Errorf("Invalid CPU model name %q", fields[0])
+	}
+
+	for _, feature := range fields[1:] {
+		if !strings.HasPrefix(feature, "+") && !strings.HasPrefix(feature, "-") || !cpuModelNameRegex.MatchString(feature[1:]) {
+			return fmt.Errorf("Invalid CPU feature %q, must be \"+<feature>\" or \"-<feature>\"", feature)
+		}
+	}
+
+	return nil
+}
+
+// cpuModelNameRegex matches QEMU CPU model and feature names.
+var cpuModelNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validateAppArmorProfileName validates the name of an AppArmor profile.
+// Stacked profiles and profiles from other namespaces aren't supported.
+func validateAppArmorProfileName(value string) error {
+	if strings.Contains(value, "//&") || strings.HasPrefix(value, ":") {
+		return fmt.Errorf("AppArmor profile %q must be a single profile of the host namespace", value)
+	}
+
+	for _, r := range value {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return fmt.Errorf("AppArmor profile %q can't contain whitespace or control characters", value)
+		}
+	}
+
+	return nil
+}
+
+// validateConsoleLogForward validates a console log forwarding target, "none" disabling forwarding.
+func validateConsoleLogForward(value string) error {
+	if value == "none" {
+		return nil
+	}
+
+	return logship.ValidateTarget(value)
+}
+
+// validateDNSDomain validates a DNS search domain.
+func validateDNSDomain(value string) error {
+	if len(value) > 253 {
+		return fmt.Errorf("Domain must be at most 253 characters long")
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(value, "."), ".") {
+		err := validate.IsHostname(label)
+		if err != nil {
+			return fmt.Errorf("Invalid domain %q: %w", value, err)
+		}
+	}
+
+	return nil
+}
+
+// validateHostname validates a hostname, either short or fully qualified.
+func validateHostname(value string) error {
+	// The kernel limits the hostname to 64 characters.
+	if len(value) > 64 {
+		return fmt.Errorf("Hostname must be at most 64 characters long")
+	}
+
+	for _, label := range strings.Split(value, ".") {
+		err := validate.IsHostname(label)
+		if err != nil {
+			return fmt.Errorf("Invalid hostname %q: %w", value, err)
+		}
+	}
+
+	return nil
+}
+
+// validateNetworkNamespace validates a network namespace name or its path in /run/netns.
+func validateNetworkNamespace(value string) error {
+	name := value
+	if strings.HasPrefix(value, "/") {
+		dir, file := filepath.Split(value)
+		if dir != "/run/netns/" {
+			return fmt.Errorf("Network namespace path %q must be in /run/netns", value)
+		}
+
+		name = file
+	}
+
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return fmt.Errorf("Invalid network namespace name %q", value)
+	}
+
+	return nil
+}
+
+// NetworkNamespacePath returns the path of the network namespace set in linux.network_namespace.
+// Names refer to namespaces in /run/netns (as created by "ip netns add"), paths are confined to it too.
+func NetworkNamespacePath(value string) string {
+	return filepath.Join("/run/netns", filepath.Base(value))
+}
+
+// sysctlNamespaces maps the prefixes of the namespaced sysctls to the namespace they're attached to.
+var sysctlNamespaces = map[string]string{
+	"fs.mqueue.":             "ipc",
+	"kernel.domainname":      "uts",
+	"kernel.msgmax":          "ipc",
+	"kernel.msgmnb":          "ipc",
+	"kernel.msgmni":          "ipc",
+	"kernel.sem":             "ipc",
+	"kernel.shm_rmid_forced": "ipc",
+	"kernel.shmall":          "ipc",
+	"kernel.shmmax":          "ipc",
+	"kernel.shmmni":          "ipc",
+	"net.":                   "net",
+	"user.":                  "user",
+}
+
+// SysctlNamespace returns the namespace type ("ipc", "net", "user" or "uts") the sysctl is attached to,
+// or an empty string if the sysctl isn't namespaced and so can't be safely set for a single container.
+func SysctlNamespace(name string) string {
+	for prefix, namespace := range sysctlNamespaces {
+		if strings.HasSuffix(prefix, ".") {
+			if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+				return namespace
+			}
+		} else if name == prefix {
+			return namespace
+		}
+	}
+
+	return ""
+}
+
 // ConfigKeyChecker returns a function that will check whether or not
 // a provide value is valid for the associate config key.  Returns an
 // error if the key is not known.  The checker function only performs
@@ -1221,6 +1728,15 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 			return validate.IsAny, nil
 		}
 
+		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.gpu.pci)
+		// The PCI address of the GPU selected for the device, which is preferred the next time the instance starts.
+		// ---
+		//  type: string
+		//  shortdesc: PCI address of the selected GPU
+		if strings.HasSuffix(key, ".gpu.pci") {
+			return validate.IsAny, nil
+		}
+
 		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.vgpu.uuid)
 		// The NVIDIA virtual GPU instance UUID.
 		// ---
@@ -1248,6 +1764,24 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 			return validate.IsAny, nil
 		}
 
+		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.last_state.io.readahead)
+		// The original read-ahead (in KiB) of the host block device, restored when the instance stops.
+		// ---
+		//  type: string
+		//  shortdesc: Disk device original read-ahead
+		if strings.HasSuffix(key, ".last_state.io.readahead") {
+			return validate.IsAny, nil
+		}
+
+		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.last_state.io.scheduler)
+		// The original I/O scheduler of the host block device, restored when the instance stops.
+		// ---
+		//  type: string
+		//  shortdesc: Disk device original I/O scheduler
+		if strings.HasSuffix(key, ".last_state.io.scheduler") {
+			return validate.IsAny, nil
+		}
+
 		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.last_state.ip_addresses)
 		// Comma-separated list of the last used IP addresses of the network device.
 		// ---
@@ -1293,6 +1827,15 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 			return validate.IsAny, nil
 		}
 
+		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.last_state.pkey.index)
+		// The original partition key table index used by an InfiniBand virtual function.
+		// ---
+		//  type: string
+		//  shortdesc: InfiniBand virtual function original partition key index
+		if strings.HasSuffix(key, ".last_state.pkey.index") {
+			return validate.IsAny, nil
+		}
+
 		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.last_state.usb.bus)
 		// The original USB bus address.
 		// ---
@@ -1531,6 +2074,13 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 		return validate.IsAny, nil
 	}
 
+	if instanceType == api.InstanceTypeContainer {
+		_, ok := InstanceConfigKeysVM[key]
+		if ok {
+			return nil, fmt.Errorf("%s isn't supported for containers", key)
+		}
+	}
+
 	return nil, fmt.Errorf("Unknown configuration key: %s", key)
 }
 
@@ -1551,3 +2101,13 @@ func InstanceIncludeWhenCopying(configKey string, remoteCopy bool) bool {
 
 	return true // Keep all other keys.
 }
+
+// InstanceIdentityKey returns whether a config key is part of the network identity of an instance as seen by
+// its guest (MAC addresses and cloud-init instance ID). Those are regenerated on copy unless asked otherwise.
+func InstanceIdentityKey(configKey string) bool {
+	if configKey == "volatile.cloud-init.instance-id" {
+		return true
+	}
+
+	return strings.HasPrefix(configKey, ConfigVolatilePrefix) && strings.HasSuffix(configKey, ".hwaddr") && !strings.Contains(configKey, ".last_state.")
+}
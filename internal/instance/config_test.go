@@ -0,0 +1,251 @@
+package instance
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestValidateNetworkNamespace(t *testing.T) {
+	checker, err := ConfigKeyChecker("linux.network_namespace", api.InstanceTypeContainer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, value := range []string{"", "foo", "ns-1", "/run/netns/foo"} {
+		err := checker(value)
+		if err != nil {
+			t.Errorf("Expected %q to be valid: %v", value, err)
+		}
+	}
+
+	for _, value := range []string{".", "..", "foo/bar", "/run/netns/../foo", "/run/netns/", "/run/netns/foo/bar", "/proc/1/ns/net", "/var/run/netns/foo"} {
+		err := checker(value)
+		if err == nil {
+			t.Errorf("Expected %q to be invalid", value)
+		}
+	}
+}
+
+func TestValidateAppArmorProfile(t *testing.T) {
+	checker, err := ConfigKeyChecker("security.apparmor.profile", api.InstanceTypeContainer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, value := range []string{"", "foo", "lxc-container-default-cgns", "/usr/bin/foo", "foo//bar"} {
+		err := checker(value)
+		if err != nil {
+			t.Errorf("Expected %q to be valid: %v", value, err)
+		}
+	}
+
+	for _, value := range []string{"foo bar", "foo\n", "foo//&bar", ":ns:foo"} {
+		err := checker(value)
+		if err == nil {
+			t.Errorf("Expected %q to be invalid", value)
+		}
+	}
+}
+
+func TestNetworkNamespacePath(t *testing.T) {
+	if NetworkNamespacePath("foo") != "/run/netns/foo" {
+		t.Errorf("Unexpected path for named network namespace: %q", NetworkNamespacePath("foo"))
+	}
+
+	if NetworkNamespacePath("/run/netns/foo") != "/run/netns/foo" {
+		t.Errorf("Unexpected path for network namespace path: %q", NetworkNamespacePath("/run/netns/foo"))
+	}
+
+	// Paths outside of /run/netns are never used.
+	if NetworkNamespacePath("/proc/1/ns/net") != "/run/netns/net" {
+		t.Errorf("Unexpected path for network namespace path: %q", NetworkNamespacePath("/proc/1/ns/net"))
+	}
+}
+
+func TestInstanceIdentityKey(t *testing.T) {
+	for _, key := range []string{"volatile.eth0.hwaddr", "volatile.cloud-init.instance-id"} {
+		if !InstanceIdentityKey(key) {
+			t.Errorf("Expected %q to be an identity key", key)
+		}
+	}
+
+	for _, key := range []string{"volatile.uuid", "volatile.eth0.host_name", "volatile.eth0.last_state.hwaddr", "volatile.eth0.last_state.vf.hwaddr", "user.hwaddr"} {
+		if InstanceIdentityKey(key) {
+			t.Errorf("Expected %q not to be an identity key", key)
+		}
+	}
+}
+
+func TestSysctlNamespace(t *testing.T) {
+	tests := map[string]string{
+		"net.ipv4.ip_forward":      "net",
+		"net.core.somaxconn":       "net",
+		"kernel.shmmax":            "ipc",
+		"kernel.domainname":        "uts",
+		"fs.mqueue.msg_max":        "ipc",
+		"user.max_user_namespaces": "user",
+		"kernel.panic":             "",
+		"kernel.hostname":          "",
+		"kernel.shmmax_foo":        "",
+		"vm.swappiness":            "",
+		"fs.file-max":              "",
+		"net.":                     "",
+		"":                         "",
+	}
+
+	for name, expected := range tests {
+		namespace := SysctlNamespace(name)
+		if namespace != expected {
+			t.Errorf("Expected %q to be in namespace %q, got %q", name, expected, namespace)
+		}
+	}
+
+	// Non-namespaced sysctls remain valid config keys so that existing containers can still be loaded.
+	_, err := ConfigKeyChecker("linux.sysctl.vm.swappiness", api.InstanceTypeContainer)
+	if err != nil {
+		t.Errorf("Expected non-namespaced sysctl to be a valid key: %v", err)
+	}
+}
+
+func TestRNGConfigKeys(t *testing.T) {
+	tests := map[string]string{
+		"rng.source":           "/dev/hwrng",
+		"limits.rng.max_bytes": "1KiB",
+		"limits.rng.period":    "500",
+	}
+
+	for key, value := range tests {
+		validator, err := ConfigKeyChecker(key, api.InstanceTypeVM)
+		if err != nil {
+			t.Errorf("Expected %q to be allowed for virtual machines: %v", key, err)
+			continue
+		}
+
+		err = validator(value)
+		if err != nil {
+			t.Errorf("Expected %q to be a valid value for %q: %v", value, key, err)
+		}
+
+		_, err = ConfigKeyChecker(key, api.InstanceTypeContainer)
+		if err == nil || err.Error() != key+" isn't supported for containers" {
+			t.Errorf("Expected %q to be rejected for containers, got: %v", key, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"rng.source":           "hwrng",
+		"limits.rng.max_bytes": "foo",
+		"limits.rng.period":    "0",
+	}
+
+	for key, value := range invalid {
+		validator, _ := ConfigKeyChecker(key, api.InstanceTypeVM)
+		if validator(value) == nil {
+			t.Errorf("Expected %q to be an invalid value for %q", value, key)
+		}
+	}
+
+	// Only entropy devices can be used as the source.
+	validator, _ := ConfigKeyChecker("rng.source", api.InstanceTypeVM)
+	for _, value := range []string{"/dev/urandom", "/dev/random", "/dev/hwrng"} {
+		if validator(value) != nil {
+			t.Errorf("Expected %q to be a valid entropy source", value)
+		}
+	}
+
+	for _, value := range []string{"/etc/shadow", "/dev/sda", "/dev/hwrng/../sda"} {
+		if validator(value) == nil {
+			t.Errorf("Expected %q to be rejected as an entropy source", value)
+		}
+	}
+}
+
+func TestQEMUHookConfigKeys(t *testing.T) {
+	for _, stage := range []string{"pre-start", "post-start", "pre-stop"} {
+		key := "raw.qemu.hook." + stage
+
+		validator, err := ConfigKeyChecker(key, api.InstanceTypeVM)
+		if err != nil {
+			t.Errorf("Expected %q to be allowed for virtual machines: %v", key, err)
+			continue
+		}
+
+		if validator("/usr/local/bin/qemu-hook") != nil {
+			t.Errorf("Expected an absolute path to be valid for %q", key)
+		}
+
+		if validator("qemu-hook") == nil {
+			t.Errorf("Expected a relative path to be invalid for %q", key)
+		}
+
+		_, err = ConfigKeyChecker(key, api.InstanceTypeContainer)
+		if err == nil {
+			t.Errorf("Expected %q to be rejected for containers", key)
+		}
+	}
+}
+
+func TestValidateDNSDomain(t *testing.T) {
+	for _, value := range []string{"example.com", "example.com.", "lan", "sub-domain.example.org", strings.Repeat("a.", 126) + "a"} {
+		err := validateDNSDomain(value)
+		if err != nil {
+			t.Errorf("Expected %q to be valid: %v", value, err)
+		}
+	}
+
+	for _, value := range []string{"", ".", "example..com", "-example.com", "example.com-", "exa_mple.com", "example .com", strings.Repeat("a", 64) + ".com", strings.Repeat("a.", 127) + "a"} {
+		err := validateDNSDomain(value)
+		if err == nil {
+			t.Errorf("Expected %q to be invalid", value)
+		}
+	}
+
+	// The search domains are validated as a list.
+	checker, err := ConfigKeyChecker("dns.search", api.InstanceTypeVM)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, value := range []string{"", "example.com", "example.com,example.net"} {
+		err := checker(value)
+		if err != nil {
+			t.Errorf("Expected %q to be valid: %v", value, err)
+		}
+	}
+
+	for _, value := range []string{"example.com,", "example.com,exa_mple.net"} {
+		err := checker(value)
+		if err == nil {
+			t.Errorf("Expected %q to be invalid", value)
+		}
+	}
+}
+
+func TestValidateHostname(t *testing.T) {
+	for _, value := range []string{"web", "web-01", "web.example.com", strings.Repeat("a", 63), strings.Repeat("a.", 31) + "aa"} {
+		err := validateHostname(value)
+		if err != nil {
+			t.Errorf("Expected %q to be valid: %v", value, err)
+		}
+	}
+
+	for _, value := range []string{"", "-web", "web-", "web_01", "123", "web..example.com", "web.example.com.", strings.Repeat("a", 64), strings.Repeat("a.", 32) + "a"} {
+		err := validateHostname(value)
+		if err == nil {
+			t.Errorf("Expected %q to be invalid", value)
+		}
+	}
+
+	// The key is optional.
+	checker, err := ConfigKeyChecker("dns.hostname", api.InstanceTypeContainer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = checker("")
+	if err != nil {
+		t.Errorf("Expected empty hostname to be valid: %v", err)
+	}
+}
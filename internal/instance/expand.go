@@ -0,0 +1,145 @@
+package instance
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// ConfigProfilePriorityKeys is the profile config key listing the keys for which the profile takes precedence
+// over the other profiles of an instance, regardless of the profile order.
+const ConfigProfilePriorityKeys = "profile.priority_keys"
+
+// ConfigSourceInstance indicates a configuration key or device set directly on the instance.
+const ConfigSourceInstance = "instance"
+
+// ConfigSourceProfile returns the source of a configuration key or device coming from the named profile.
+func ConfigSourceProfile(name string) string {
+	return "profile:" + name
+}
+
+// ProfilePriorityKeys returns the config keys marked as high priority in the given profile config.
+func ProfilePriorityKeys(config map[string]string) []string {
+	keys := []string{}
+	for _, key := range strings.Split(config[ConfigProfilePriorityKeys], ",") {
+		key = strings.TrimSpace(key)
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// ValidateProfilePriorities checks that no config key is marked as high priority by more than one of the given profiles.
+func ValidateProfilePriorities(profiles []api.Profile) error {
+	owners := map[string]string{}
+	for _, profile := range profiles {
+		for _, key := range ProfilePriorityKeys(profile.Config) {
+			owner, found := owners[key]
+			if found && owner != profile.Name {
+				return fmt.Errorf("Config key %q is marked as high priority by both profiles %q and %q", key, owner, profile.Name)
+			}
+
+			owners[key] = profile.Name
+		}
+	}
+
+	return nil
+}
+
+// ExpandConfig applies the instance config on top of the config of the given profiles (in order).
+// Keys marked as high priority by a profile aren't overridden by the profiles which follow it.
+// Returns the expanded config along with the sources which set each of its keys, the last one being effective.
+func ExpandConfig(config map[string]string, profiles []api.Profile) (map[string]string, map[string][]string) {
+	expandedConfig := map[string]string{}
+	sources := map[string][]string{}
+	priorityKeys := map[string]bool{}
+
+	// Apply all the profiles.
+	for _, profile := range profiles {
+		profilePriorityKeys := ProfilePriorityKeys(profile.Config)
+
+		for k, v := range profile.Config {
+			if k == ConfigProfilePriorityKeys {
+				continue
+			}
+
+			source := ConfigSourceProfile(profile.Name)
+			isPriority := slices.Contains(profilePriorityKeys, k)
+
+			// Keep the high priority value effective (last) in the sources.
+			if priorityKeys[k] && !isPriority {
+				sources[k] = slices.Insert(sources[k], len(sources[k])-1, source)
+				continue
+			}
+
+			expandedConfig[k] = v
+			sources[k] = append(sources[k], source)
+
+			if isPriority {
+				priorityKeys[k] = true
+			}
+		}
+	}
+
+	// Stick the given config on top.
+	for k, v := range config {
+		expandedConfig[k] = v
+		sources[k] = append(sources[k], ConfigSourceInstance)
+	}
+
+	return expandedConfig, sources
+}
+
+// ExpandDevices applies the instance devices on top of the devices of the given profiles (in order).
+// Returns the expanded devices along with the sources which defined each of them, the last one being effective.
+func ExpandDevices(devices map[string]map[string]string, profiles []api.Profile) (map[string]map[string]string, map[string][]string) {
+	expandedDevices := map[string]map[string]string{}
+	sources := map[string][]string{}
+
+	// Apply all the profiles.
+	for _, profile := range profiles {
+		for k, v := range profile.Devices {
+			expandedDevices[k] = v
+			sources[k] = append(sources[k], ConfigSourceProfile(profile.Name))
+		}
+	}
+
+	// Stick the given devices on top.
+	for k, v := range devices {
+		expandedDevices[k] = v
+		sources[k] = append(sources[k], ConfigSourceInstance)
+	}
+
+	return expandedDevices, sources
+}
+
+// ProfileDeviceConflicts returns the devices of the given profile which are already defined differently by one
+// of the other profiles (mapped to that profile's name), ignoring those overridden by the instance devices.
+func ProfileDeviceConflicts(devices map[string]map[string]string, profiles []api.Profile, profile api.Profile) map[string]string {
+	conflicts := map[string]string{}
+
+	for devName, device := range profile.Devices {
+		_, found := devices[devName]
+		if found {
+			continue
+		}
+
+		for _, p := range profiles {
+			if p.Name == profile.Name {
+				continue
+			}
+
+			otherDevice, found := p.Devices[devName]
+			if found && !maps.Equal(device, otherDevice) {
+				conflicts[devName] = p.Name
+			}
+		}
+	}
+
+	return conflicts
+}
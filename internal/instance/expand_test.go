@@ -0,0 +1,109 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestExpandConfig(t *testing.T) {
+	profiles := []api.Profile{
+		{Name: "default", ProfilePut: api.ProfilePut{Config: map[string]string{"limits.cpu": "1", "limits.memory": "1GiB"}}},
+		{Name: "large", ProfilePut: api.ProfilePut{Config: map[string]string{"limits.cpu": "4"}}},
+	}
+
+	config, sources := ExpandConfig(map[string]string{"limits.memory": "8GiB", "user.foo": "bar"}, profiles)
+	assert.Equal(t, map[string]string{"limits.cpu": "4", "limits.memory": "8GiB", "user.foo": "bar"}, config)
+	assert.Equal(t, map[string][]string{
+		"limits.cpu":    {"profile:default", "profile:large"},
+		"limits.memory": {"profile:default", "instance"},
+		"user.foo":      {"instance"},
+	}, sources)
+}
+
+func TestExpandConfigPriority(t *testing.T) {
+	profiles := []api.Profile{
+		{Name: "default", ProfilePut: api.ProfilePut{Config: map[string]string{"limits.cpu": "1", "limits.memory": "1GiB"}}},
+		{Name: "secure", ProfilePut: api.ProfilePut{Config: map[string]string{"security.nesting": "false", "limits.cpu": "2", ConfigProfilePriorityKeys: "security.nesting"}}},
+		{Name: "dev", ProfilePut: api.ProfilePut{Config: map[string]string{"security.nesting": "true", "limits.cpu": "4"}}},
+	}
+
+	// High priority keys aren't overridden by later profiles, other keys keep last-wins.
+	config, sources := ExpandConfig(nil, profiles)
+	assert.Equal(t, map[string]string{"limits.cpu": "4", "limits.memory": "1GiB", "security.nesting": "false"}, config)
+	assert.Equal(t, map[string][]string{
+		"limits.cpu":       {"profile:default", "profile:secure", "profile:dev"},
+		"limits.memory":    {"profile:default"},
+		"security.nesting": {"profile:dev", "profile:secure"},
+	}, sources)
+
+	// The instance config still takes precedence.
+	config, sources = ExpandConfig(map[string]string{"security.nesting": "true"}, profiles)
+	assert.Equal(t, "true", config["security.nesting"])
+	assert.Equal(t, []string{"profile:dev", "profile:secure", "instance"}, sources["security.nesting"])
+
+	// A high priority key set by an earlier profile overrides it.
+	profiles[0].Config[ConfigProfilePriorityKeys] = "limits.memory"
+	profiles[2].Config["limits.memory"] = "4GiB"
+	config, _ = ExpandConfig(nil, profiles)
+	assert.Equal(t, "1GiB", config["limits.memory"])
+}
+
+func TestValidateProfilePriorities(t *testing.T) {
+	profiles := []api.Profile{
+		{Name: "default", ProfilePut: api.ProfilePut{Config: map[string]string{"limits.cpu": "1", ConfigProfilePriorityKeys: "limits.cpu"}}},
+		{Name: "secure", ProfilePut: api.ProfilePut{Config: map[string]string{"security.nesting": "false", ConfigProfilePriorityKeys: "security.nesting"}}},
+	}
+
+	assert.NoError(t, ValidateProfilePriorities(profiles))
+
+	// The same profile listed twice isn't conflicting.
+	assert.NoError(t, ValidateProfilePriorities(append(profiles, profiles[0])))
+
+	// Two profiles marking the same key as high priority is an error.
+	profiles = append(profiles, api.Profile{Name: "fast", ProfilePut: api.ProfilePut{Config: map[string]string{"limits.cpu": "8", ConfigProfilePriorityKeys: "limits.memory, limits.cpu"}}})
+	assert.EqualError(t, ValidateProfilePriorities(profiles), `Config key "limits.cpu" is marked as high priority by both profiles "default" and "fast"`)
+}
+
+func TestExpandDevices(t *testing.T) {
+	profiles := []api.Profile{
+		{Name: "default", ProfilePut: api.ProfilePut{Devices: map[string]map[string]string{
+			"root": {"type": "disk", "path": "/", "pool": "default"},
+			"eth0": {"type": "nic", "network": "incusbr0"},
+		}}},
+	}
+
+	devices, sources := ExpandDevices(map[string]map[string]string{"eth0": {"type": "nic", "network": "ovn0"}}, profiles)
+	assert.Equal(t, "ovn0", devices["eth0"]["network"])
+	assert.Equal(t, "default", devices["root"]["pool"])
+	assert.Equal(t, map[string][]string{
+		"root": {"profile:default"},
+		"eth0": {"profile:default", "instance"},
+	}, sources)
+}
+
+func TestProfileDeviceConflicts(t *testing.T) {
+	profiles := []api.Profile{
+		{Name: "default", ProfilePut: api.ProfilePut{Devices: map[string]map[string]string{
+			"root": {"type": "disk", "path": "/", "pool": "default"},
+			"eth0": {"type": "nic", "network": "incusbr0"},
+		}}},
+	}
+
+	profile := api.Profile{Name: "net", ProfilePut: api.ProfilePut{Devices: map[string]map[string]string{
+		"eth0": {"type": "nic", "network": "ovn0"},
+		"eth1": {"type": "nic", "network": "incusbr0"},
+		"root": {"type": "disk", "path": "/", "pool": "default"},
+	}}}
+
+	// Identical devices aren't conflicting.
+	assert.Equal(t, map[string]string{"eth0": "default"}, ProfileDeviceConflicts(nil, profiles, profile))
+
+	// Devices overridden by the instance aren't conflicting.
+	assert.Empty(t, ProfileDeviceConflicts(map[string]map[string]string{"eth0": {"type": "none"}}, profiles, profile))
+
+	// The profile itself is ignored when already part of the profiles.
+	assert.Empty(t, ProfileDeviceConflicts(nil, []api.Profile{profile}, profile))
+}
@@ -0,0 +1,196 @@
+package io
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the combined throughput of the readers and writers wrapped through it.
+// The limit can be changed at any time, including while a transfer is in progress.
+type RateLimiter struct {
+	mu       sync.Mutex
+	limit    int64
+	next     time.Time
+	handlers map[int]func(limit int64)
+	nextID   int
+}
+
+// NewRateLimiter returns a new RateLimiter allowing limit bytes per second.
+//
+// If the given limit is zero or negative, then no limit is applied.
+func NewRateLimiter(limit int64) *RateLimiter {
+	return &RateLimiter{limit: limit}
+}
+
+// Limit returns the current limit in bytes per second.
+func (l *RateLimiter) Limit() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.limit
+}
+
+// SetLimit changes the limit to the given number of bytes per second.
+//
+// If the given limit is zero or negative, then no limit is applied.
+func (l *RateLimiter) SetLimit(limit int64) {
+	l.mu.Lock()
+	l.limit = limit
+
+	// Don't carry over the delay accumulated under the previous limit.
+	l.next = time.Time{}
+
+	handlers := make([]func(limit int64), 0, len(l.handlers))
+	for _, handler := range l.handlers {
+		handlers = append(handlers, handler)
+	}
+
+	l.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(limit)
+	}
+}
+
+// OnChange registers a handler called with the new limit whenever it changes.
+// This lets transfers which aren't going through the limiter, like those done by external tools, follow the limit.
+// The returned function unregisters the handler.
+func (l *RateLimiter) OnChange(handler func(limit int64)) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.handlers == nil {
+		l.handlers = map[int]func(limit int64){}
+	}
+
+	id := l.nextID
+	l.nextID++
+	l.handlers[id] = handler
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		delete(l.handlers, id)
+	}
+}
+
+// chunkSize returns how many of the n bytes may be transferred in one go under the current limit.
+func (l *RateLimiter) chunkSize(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit <= 0 {
+		return n
+	}
+
+	// Only allow a tenth of a second worth of data at once so that limit changes apply quickly.
+	return int(min(int64(n), max(l.limit/10, 1)))
+}
+
+// wait blocks until n more bytes fit within the limit.
+func (l *RateLimiter) wait(n int) {
+	l.mu.Lock()
+
+	if l.limit <= 0 || n <= 0 {
+		l.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.limit))
+	delay := l.next.Sub(now)
+	l.mu.Unlock()
+
+	time.Sleep(delay)
+}
+
+// write writes p to w in chunks, waiting for each of them to fit within the limit.
+func (l *RateLimiter) write(w io.Writer, p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		chunk := l.chunkSize(len(p) - written)
+		l.wait(chunk)
+
+		n, err := w.Write(p[written : written+chunk])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// read reads from r into p, waiting for the data read to fit within the limit.
+func (l *RateLimiter) read(r io.Reader, p []byte) (int, error) {
+	n, err := r.Read(p[:l.chunkSize(len(p))])
+	l.wait(n)
+
+	return n, err
+}
+
+type rateLimitedReader struct {
+	io.Reader
+	limiter *RateLimiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	return r.limiter.read(r.Reader, p)
+}
+
+// Reader returns a reader reading from the given reader within the limit.
+func (l *RateLimiter) Reader(reader io.Reader) io.Reader {
+	return &rateLimitedReader{Reader: reader, limiter: l}
+}
+
+type rateLimitedWriter struct {
+	io.Writer
+	limiter *RateLimiter
+}
+
+func (w *rateLimitedWriter) Write(p []byte) (int, error) {
+	return w.limiter.write(w.Writer, p)
+}
+
+// Writer returns a writer writing to the given writer within the limit.
+func (l *RateLimiter) Writer(writer io.Writer) io.Writer {
+	return &rateLimitedWriter{Writer: writer, limiter: l}
+}
+
+type rateLimitedWriteSeeker struct {
+	io.WriteSeeker
+	limiter *RateLimiter
+}
+
+func (w *rateLimitedWriteSeeker) Write(p []byte) (int, error) {
+	return w.limiter.write(w.WriteSeeker, p)
+}
+
+// WriteSeeker returns a io.WriteSeeker writing to the given one within the limit.
+func (l *RateLimiter) WriteSeeker(writeSeeker io.WriteSeeker) io.WriteSeeker {
+	return &rateLimitedWriteSeeker{WriteSeeker: writeSeeker, limiter: l}
+}
+
+type rateLimitedReadWriteCloser struct {
+	io.ReadWriteCloser
+	limiter *RateLimiter
+}
+
+func (c *rateLimitedReadWriteCloser) Read(p []byte) (int, error) {
+	return c.limiter.read(c.ReadWriteCloser, p)
+}
+
+func (c *rateLimitedReadWriteCloser) Write(p []byte) (int, error) {
+	return c.limiter.write(c.ReadWriteCloser, p)
+}
+
+// ReadWriteCloser returns a io.ReadWriteCloser reading from and writing to the given one within the limit.
+func (l *RateLimiter) ReadWriteCloser(conn io.ReadWriteCloser) io.ReadWriteCloser {
+	return &rateLimitedReadWriteCloser{ReadWriteCloser: conn, limiter: l}
+}
@@ -0,0 +1,67 @@
+package io
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 4096)
+
+	// Without a limit, data goes through at once.
+	limiter := NewRateLimiter(0)
+	buf := &bytes.Buffer{}
+	start := time.Now()
+	_, err := io.Copy(limiter.Writer(buf), bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, data, buf.Bytes())
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+
+	// With a limit of 16KiB/s, writing 4KiB takes about a quarter of a second.
+	limiter.SetLimit(16 * 1024)
+	require.Equal(t, int64(16*1024), limiter.Limit())
+	buf.Reset()
+	start = time.Now()
+	_, err = io.Copy(limiter.Writer(buf), bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, data, buf.Bytes())
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	// Reading is limited the same way.
+	buf.Reset()
+	start = time.Now()
+	_, err = io.Copy(buf, limiter.Reader(bytes.NewReader(data)))
+	require.NoError(t, err)
+	require.Equal(t, data, buf.Bytes())
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	// Removing the limit applies right away.
+	limiter.SetLimit(0)
+	buf.Reset()
+	start = time.Now()
+	_, err = io.Copy(limiter.Writer(buf), bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestRateLimiterOnChange(t *testing.T) {
+	limiter := NewRateLimiter(0)
+
+	var limits []int64
+	unregister := limiter.OnChange(func(limit int64) {
+		limits = append(limits, limit)
+	})
+
+	limiter.SetLimit(1024)
+	limiter.SetLimit(0)
+	require.Equal(t, []int64{1024, 0}, limits)
+
+	// Unregistered handlers aren't called anymore.
+	unregister()
+	limiter.SetLimit(2048)
+	require.Equal(t, []int64{1024, 0}, limits)
+}
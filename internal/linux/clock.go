@@ -0,0 +1,20 @@
+//go:build linux
+
+package linux
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// ClockSynchronized returns whether the system clock is kept synchronized (for example by an NTP daemon).
+// Like systemd, the clock is considered synchronized when the kernel reports a maximum error below 16s.
+func ClockSynchronized() (bool, error) {
+	var tx unix.Timex
+
+	_, err := unix.Adjtimex(&tx)
+	if err != nil {
+		return false, err
+	}
+
+	return tx.Maxerror < 16_000_000, nil
+}
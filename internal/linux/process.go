@@ -0,0 +1,134 @@
+//go:build linux
+
+package linux
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// processParents returns a map of the host processes to their parent process.
+func processParents() (map[int]int, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make(map[int]int, len(entries))
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		content, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			// The process may have exited since the directory was read.
+			continue
+		}
+
+		// The command name can contain spaces and parentheses, the state and parent PID follow its closing one.
+		idx := strings.LastIndex(string(content), ")")
+		if idx < 0 {
+			continue
+		}
+
+		fields := strings.Fields(string(content[idx+1:]))
+		if len(fields) < 2 {
+			continue
+		}
+
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		parents[pid] = ppid
+	}
+
+	return parents, nil
+}
+
+// ProcessDescendants returns the PIDs of all the processes descending from the given one.
+func ProcessDescendants(pid int) ([]int, error) {
+	parents, err := processParents()
+	if err != nil {
+		return nil, err
+	}
+
+	children := map[int][]int{}
+	for child, parent := range parents {
+		children[parent] = append(children[parent], child)
+	}
+
+	descendants := []int{}
+	queue := []int{pid}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, child := range children[current] {
+			if slices.Contains(descendants, child) {
+				continue
+			}
+
+			descendants = append(descendants, child)
+			queue = append(queue, child)
+		}
+	}
+
+	return descendants, nil
+}
+
+// KillProcessTree kills the given process along with all its descendants.
+// The processes are stopped first so that none can fork new children while the tree is collected.
+func KillProcessTree(pid int) error {
+	tree := []int{pid}
+
+	err := unix.Kill(pid, unix.SIGSTOP)
+	if err != nil {
+		return err
+	}
+
+	for {
+		descendants, err := ProcessDescendants(pid)
+		if err != nil {
+			return err
+		}
+
+		added := false
+		for _, descendant := range descendants {
+			if slices.Contains(tree, descendant) {
+				continue
+			}
+
+			// Processes which already exited are ignored.
+			err := unix.Kill(descendant, unix.SIGSTOP)
+			if err != nil && !errors.Is(err, unix.ESRCH) {
+				return err
+			}
+
+			tree = append(tree, descendant)
+			added = true
+		}
+
+		if !added {
+			break
+		}
+	}
+
+	for _, p := range tree {
+		err := unix.Kill(p, unix.SIGKILL)
+		if err != nil && !errors.Is(err, unix.ESRCH) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package linux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// processGone returns whether the process has exited (zombies included).
+func processGone(pid int) bool {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return true
+	}
+
+	idx := strings.LastIndex(string(content), ")")
+
+	return idx >= 0 && strings.HasPrefix(string(content[idx+1:]), " Z")
+}
+
+func TestKillProcessTree(t *testing.T) {
+	cmd := exec.Command("/bin/sh", "-c", "sleep 100 & (sleep 100 & sleep 100) & wait")
+	require.NoError(t, cmd.Start())
+
+	var descendants []int
+	require.Eventually(t, func() bool {
+		var err error
+		descendants, err = ProcessDescendants(cmd.Process.Pid)
+		require.NoError(t, err)
+
+		// The first sleep, the subshell (or its last sleep) and its first sleep.
+		return len(descendants) >= 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, KillProcessTree(cmd.Process.Pid))
+	_ = cmd.Wait()
+
+	for _, pid := range descendants {
+		assert.Eventually(t, func() bool { return processGone(pid) }, 5*time.Second, 10*time.Millisecond, "process %d survived", pid)
+	}
+}
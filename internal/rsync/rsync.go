@@ -9,11 +9,15 @@ import (
 	"os"
 	"os/exec"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/sys/unix"
 
+	internalIO "github.com/lxc/incus/v6/internal/io"
 	"github.com/lxc/incus/v6/internal/linux"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/internal/version"
@@ -30,6 +34,11 @@ var RunWrapper func(cmd *exec.Cmd, source string, destination string) (func(), e
 
 // rsync is a wrapper for the rsync command which will respect RunWrapper.
 func rsync(args ...string) (string, error) {
+	return rsyncWithHook(nil, args...)
+}
+
+// rsyncWithHook is like rsync, calling started with the rsync process once it's running.
+func rsyncWithHook(started func(process *os.Process), args ...string) (string, error) {
 	if len(args) < 2 {
 		return "", fmt.Errorf("rsync call expects a minimum of two arguments (source and destination)")
 	}
@@ -55,7 +64,16 @@ func rsync(args ...string) (string, error) {
 	}
 
 	// Run the command.
-	err := cmd.Run()
+	err := cmd.Start()
+	if err != nil {
+		return stdout.String(), subprocess.NewRunError("rsync", args, err, &stdout, &stderr)
+	}
+
+	if started != nil {
+		started(cmd.Process)
+	}
+
+	err = cmd.Wait()
 	if err != nil {
 		return stdout.String(), subprocess.NewRunError("rsync", args, err, &stdout, &stderr)
 	}
@@ -65,6 +83,64 @@ func rsync(args ...string) (string, error) {
 
 // LocalCopy copies a directory using rsync (with the --devices option).
 func LocalCopy(source string, dest string, bwlimit string, xattrs bool, rsyncArgs ...string) (string, error) {
+	return localCopy(nil, source, dest, bwlimit, xattrs, rsyncArgs...)
+}
+
+// LocalCopyLimited is like LocalCopy but follows the limit of the given rate limiter, when set, instead of bwlimit.
+// As rsync can't change its limit while running, it's restarted with the new limit whenever that changes,
+// resuming the copy where it was.
+func LocalCopyLimited(source string, dest string, bwlimit string, limiter *internalIO.RateLimiter, xattrs bool, rsyncArgs ...string) (string, error) {
+	if limiter == nil {
+		return LocalCopy(source, dest, bwlimit, xattrs, rsyncArgs...)
+	}
+
+	for {
+		limitedBwlimit := bwlimit
+
+		// The rate limiter is in bytes per second while rsync takes KiB per second.
+		limit := limiter.Limit()
+		if limit > 0 {
+			limitedBwlimit = strconv.FormatInt(max(limit/1024, 1), 10)
+		}
+
+		var mu sync.Mutex
+		var process *os.Process
+		restart := false
+
+		unregister := limiter.OnChange(func(_ int64) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			restart = true
+			if process != nil {
+				_ = process.Signal(unix.SIGTERM)
+			}
+		})
+
+		msg, err := localCopy(func(p *os.Process) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			process = p
+			if restart {
+				_ = process.Signal(unix.SIGTERM)
+			}
+		}, source, dest, limitedBwlimit, xattrs, rsyncArgs...)
+
+		unregister()
+
+		mu.Lock()
+		restarting := restart
+		mu.Unlock()
+
+		if !restarting {
+			return msg, err
+		}
+	}
+}
+
+// localCopy runs LocalCopy, calling started with the rsync process once it's running.
+func localCopy(started func(process *os.Process), source string, dest string, bwlimit string, xattrs bool, rsyncArgs ...string) (string, error) {
 	err := os.MkdirAll(dest, 0o755)
 	if err != nil {
 		return "", err
@@ -106,7 +182,7 @@ func LocalCopy(source string, dest string, bwlimit string, xattrs bool, rsyncArg
 		internalUtil.AddSlash(source),
 		dest)
 
-	msg, err := rsync(args...)
+	msg, err := rsyncWithHook(started, args...)
 	if err != nil {
 		var exitError *exec.ExitError
 		ok := errors.As(err, &exitError)
@@ -146,6 +146,47 @@ func hasProfile(sysOS *sys.OS, name string) (bool, error) {
 	return false, nil
 }
 
+// loadedProfilesPath lists the profiles loaded into the kernel along with their mode.
+var loadedProfilesPath = "/sys/kernel/security/apparmor/profiles"
+
+// parseLoadedProfiles parses the list of loaded profiles, made of one "<name> (<mode>)" entry per line.
+// It returns the mode of the profiles indexed by name.
+func parseLoadedProfiles(content string) map[string]string {
+	profiles := map[string]string{}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		idx := strings.LastIndex(line, " (")
+		if idx < 0 || !strings.HasSuffix(line, ")") {
+			profiles[line] = ""
+			continue
+		}
+
+		profiles[line[:idx]] = line[idx+2 : len(line)-1]
+	}
+
+	return profiles
+}
+
+// profileMode returns the mode (enforce, complain, ...) of a profile loaded into the kernel.
+func profileMode(name string) (string, error) {
+	content, err := os.ReadFile(loadedProfilesPath)
+	if err != nil {
+		return "", fmt.Errorf("Failed listing loaded AppArmor profiles: %w", err)
+	}
+
+	mode, ok := parseLoadedProfiles(string(content))[name]
+	if !ok {
+		return "", fmt.Errorf("AppArmor profile %q isn't loaded", name)
+	}
+
+	return mode, nil
+}
+
 // parseProfile parses the profile without loading it into the kernel.
 func parseProfile(sysOS *sys.OS, name string) error {
 	if !sysOS.AppArmorAvailable {
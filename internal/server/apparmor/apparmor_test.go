@@ -0,0 +1,90 @@
+package apparmor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/sys"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// testInstance is a minimal instance for profile tests.
+type testInstance struct {
+	instType instancetype.Type
+	config   map[string]string
+}
+
+func (i testInstance) Project() api.Project              { return api.Project{Name: api.ProjectDefaultName} }
+func (i testInstance) Name() string                      { return "c1" }
+func (i testInstance) ExpandedConfig() map[string]string { return i.config }
+func (i testInstance) Type() instancetype.Type           { return i.instType }
+func (i testInstance) LogPath() string                   { return "" }
+func (i testInstance) RunPath() string                   { return "" }
+func (i testInstance) Path() string                      { return "" }
+func (i testInstance) DevicesPath() string               { return "" }
+func (i testInstance) IsPrivileged() bool                { return false }
+
+func TestParseLoadedProfiles(t *testing.T) {
+	profiles := parseLoadedProfiles(`lxc-container-default-cgns (enforce)
+/usr/bin/man (complain)
+incus-c1_</var/lib/incus> (enforce)
+docker default (kill)
+broken
+`)
+
+	assert.Equal(t, map[string]string{
+		"lxc-container-default-cgns": "enforce",
+		"/usr/bin/man":               "complain",
+		"incus-c1_</var/lib/incus>":  "enforce",
+		"docker default":             "kill",
+		"broken":                     "",
+	}, profiles)
+}
+
+func TestInstanceCustomProfileCheck(t *testing.T) {
+	loadedProfilesPath = filepath.Join(t.TempDir(), "profiles")
+	t.Cleanup(func() { loadedProfilesPath = "/sys/kernel/security/apparmor/profiles" })
+
+	err := os.WriteFile(loadedProfilesPath, []byte("custom (enforce)\ncustom-complain (complain)\n/usr/bin/foo (enforce)\n"), 0o600)
+	require.NoError(t, err)
+
+	sysOS := &sys.OS{AppArmorAvailable: true, AppArmorAdmin: true}
+
+	check := func(instType instancetype.Type, config map[string]string) ([]string, error) {
+		return InstanceCustomProfileCheck(sysOS, testInstance{instType: instType, config: config})
+	}
+
+	// Instances using the generated profile or virtual machines aren't checked.
+	warnings, err := check(instancetype.Container, map[string]string{})
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	warnings, err = check(instancetype.VM, map[string]string{"security.apparmor.profile": "missing"})
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// Enforced profiles are fine, missing ones are rejected.
+	warnings, err = check(instancetype.Container, map[string]string{"security.apparmor.profile": "custom"})
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	_, err = check(instancetype.Container, map[string]string{"security.apparmor.profile": "missing"})
+	assert.Error(t, err)
+
+	// Risky profiles are reported.
+	for _, name := range []string{"custom-complain", "/usr/bin/foo", "unconfined"} {
+		warnings, err = check(instancetype.Container, map[string]string{"security.apparmor.profile": name})
+		assert.NoError(t, err, name)
+		assert.Len(t, warnings, 1, name)
+	}
+
+	sysOS.AppArmorConfined = true
+	warnings, err = check(instancetype.Container, map[string]string{"security.apparmor.profile": "missing"})
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+}
@@ -49,6 +49,59 @@ func InstanceNamespaceName(inst instance) string {
 	return profileName("", name)
 }
 
+// InstanceCustomProfile returns the name of the custom profile confining the instance instead of the generated one.
+// An empty string is returned if the instance uses the generated profile.
+func InstanceCustomProfile(inst instance) string {
+	if inst.Type() != instancetype.Container {
+		return ""
+	}
+
+	return inst.ExpandedConfig()["security.apparmor.profile"]
+}
+
+// InstanceCustomProfileCheck checks that the custom profile of the instance is loaded.
+// It returns warnings about the settings likely to prevent the instance from working as expected.
+func InstanceCustomProfileCheck(sysOS *sys.OS, inst instance) ([]string, error) {
+	name := InstanceCustomProfile(inst)
+	if name == "" || !sysOS.AppArmorAvailable {
+		return nil, nil
+	}
+
+	if sysOS.AppArmorConfined || !sysOS.AppArmorAdmin {
+		return []string{fmt.Sprintf("AppArmor profile %q is ignored as Incus itself is confined", name)}, nil
+	}
+
+	if name == "unconfined" {
+		return []string{"The container isn't confined by AppArmor"}, nil
+	}
+
+	mode, err := profileMode(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+
+	switch mode {
+	case "complain", "unconfined":
+		warnings = append(warnings, fmt.Sprintf("AppArmor profile %q is in %s mode and doesn't restrict the container", name, mode))
+	case "kill":
+		warnings = append(warnings, fmt.Sprintf("AppArmor profile %q is in kill mode, any denied access kills the container processes", name))
+	}
+
+	// Profiles named after a path are attached to a single program and rarely allow running a whole system.
+	if strings.HasPrefix(name, "/") {
+		warnings = append(warnings, fmt.Sprintf("AppArmor profile %q is attached to a program and may not allow the container to start", name))
+	}
+
+	// The custom profile isn't stacked with the instance namespace.
+	if util.IsTrue(inst.ExpandedConfig()["security.nesting"]) && sysOS.AppArmorStacking && !sysOS.AppArmorStacked {
+		warnings = append(warnings, fmt.Sprintf("AppArmor profile %q isn't stacked with a namespace, nested containers can't load their own profiles", name))
+	}
+
+	return warnings, nil
+}
+
 // instanceProfileFilename returns the name of the on-disk profile name.
 func instanceProfileFilename(inst instance) string {
 	name := project.Instance(inst.Project().Name, inst.Name())
@@ -56,7 +109,13 @@ func instanceProfileFilename(inst instance) string {
 }
 
 // InstanceLoad ensures that the instances's policy is loaded into the kernel so the it can boot.
+// Instances using a custom profile only need that profile to be loaded already.
 func InstanceLoad(sysOS *sys.OS, inst instance, extraBinaries []string) error {
+	if InstanceCustomProfile(inst) != "" {
+		_, err := InstanceCustomProfileCheck(sysOS, inst)
+		return err
+	}
+
 	if inst.Type() == instancetype.Container {
 		err := createNamespace(sysOS, InstanceNamespaceName(inst))
 		if err != nil {
@@ -96,7 +155,13 @@ func InstanceUnload(sysOS *sys.OS, inst instance) error {
 }
 
 // InstanceValidate generates the instance profile file and validates it.
+// For instances using a custom profile, it checks that the profile is loaded.
 func InstanceValidate(sysOS *sys.OS, inst instance, extraBinaries []string) error {
+	if InstanceCustomProfile(inst) != "" {
+		_, err := InstanceCustomProfileCheck(sysOS, inst)
+		return err
+	}
+
 	err := instanceProfileGenerate(sysOS, inst, extraBinaries)
 	if err != nil {
 		return err
@@ -257,6 +322,12 @@ func instanceProfile(sysOS *sys.OS, inst instance, extraBinaries []string) (stri
 			}
 		}
 
+		// Host entropy source of the random number generator.
+		rngSource := inst.ExpandedConfig()["rng.source"]
+		if rngSource == "" {
+			rngSource = "/dev/urandom"
+		}
+
 		err = qemuProfileTpl.Execute(sb, map[string]any{
 			"devicesPath":    inst.DevicesPath(),
 			"exePath":        execPath,
@@ -270,6 +341,7 @@ func instanceProfile(sysOS *sys.OS, inst instance, extraBinaries []string) (stri
 			"raw":            rawContent,
 			"edk2Paths":      edk2Paths,
 			"agentPath":      agentPath,
+			"rngSource":      rngSource,
 		})
 		if err != nil {
 			return "", err
@@ -28,6 +28,7 @@ profile "{{ .name }}" flags=(attach_disconnected,mediate_deleted) {
   /dev/vfio/**                              rw,
   /dev/vhost-net                            rw,
   /dev/vhost-vsock                          rw,
+  {{ .rngSource }}                           r,
   /etc/machine-id                           r,
   /run/udev/data/*                          r,
   @{PROC}/sys/vm/max_map_count              r,
@@ -139,6 +139,9 @@ func UpdateInstanceConfig(c *db.Cluster, b Info, mountPath string) error {
 		backup.Volume.Project = b.Project
 	}
 
+	// Only keep the snapshots being restored.
+	backup.FilterSnapshots(b.Snapshots)
+
 	var pool *api.StoragePool
 
 	err = c.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
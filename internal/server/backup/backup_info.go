@@ -3,6 +3,7 @@ package backup
 import (
 	"fmt"
 	"io"
+	"slices"
 
 	"gopkg.in/yaml.v2"
 
@@ -144,3 +145,25 @@ func GetInfo(r io.ReadSeeker, sysOS *sys.OS, outputPath string) (*Info, error) {
 
 	return &result, nil
 }
+
+// SelectSnapshots restricts the snapshots restored from the backup to the named ones.
+// The snapshots must all be part of the backup and optimized backups can only be restored with all their snapshots.
+func (b *Info) SelectSnapshots(names []string) error {
+	for _, name := range names {
+		if !slices.Contains(b.Snapshots, name) {
+			return fmt.Errorf("Snapshot %q not found in backup", name)
+		}
+	}
+
+	if len(names) < len(b.Snapshots) && b.OptimizedStorage != nil && *b.OptimizedStorage {
+		return fmt.Errorf("Snapshots can't be selected when restoring an optimized backup")
+	}
+
+	b.Snapshots = slices.Clone(names)
+
+	if b.Config != nil {
+		b.Config.FilterSnapshots(names)
+	}
+
+	return nil
+}
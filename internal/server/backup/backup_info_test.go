@@ -0,0 +1,148 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/incus/v6/internal/server/backup/config"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// testBackupWithSnapshots returns an uncompressed instance backup with three snapshots.
+func testBackupWithSnapshots(t *testing.T) *bytes.Reader {
+	snapshots := []string{"snap0", "snap1", "snap2"}
+	optimized := false
+
+	info := Info{
+		Name:             "c1",
+		Backend:          "dir",
+		Pool:             "default",
+		Snapshots:        snapshots,
+		OptimizedStorage: &optimized,
+		Type:             TypeContainer,
+		Config: &config.Config{
+			Container: &api.Instance{Name: "c1", Type: "container"},
+		},
+	}
+
+	for _, snapName := range snapshots {
+		info.Config.Snapshots = append(info.Config.Snapshots, &api.InstanceSnapshot{Name: snapName})
+		info.Config.VolumeSnapshots = append(info.Config.VolumeSnapshots, &api.StorageVolumeSnapshot{Name: snapName})
+	}
+
+	index, err := yaml.Marshal(&info)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	files := map[string][]byte{backupIndexPath: index}
+	names := []string{backupIndexPath, "backup/container/rootfs/data"}
+	files["backup/container/rootfs/data"] = []byte("instance")
+
+	for _, snapName := range snapshots {
+		name := "backup/snapshots/" + snapName + "/rootfs/data"
+		files[name] = []byte(snapName)
+		names = append(names, name)
+	}
+
+	for _, name := range names {
+		err = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(files[name]))})
+		require.NoError(t, err)
+
+		_, err = tw.Write(files[name])
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func snapshotNames(c *config.Config) ([]string, []string) {
+	instSnapshots := []string{}
+	for _, snap := range c.Snapshots {
+		instSnapshots = append(instSnapshots, snap.Name)
+	}
+
+	volSnapshots := []string{}
+	for _, snap := range c.VolumeSnapshots {
+		volSnapshots = append(volSnapshots, snap.Name)
+	}
+
+	return instSnapshots, volSnapshots
+}
+
+func TestGetInfoSelectSnapshots(t *testing.T) {
+	tests := []struct {
+		name     string
+		selected []string
+		expected []string
+		err      string
+	}{
+		{
+			name:     "Single snapshot",
+			selected: []string{"snap1"},
+			expected: []string{"snap1"},
+		},
+		{
+			name:     "No snapshots",
+			selected: nil,
+			expected: []string{},
+		},
+		{
+			name:     "All snapshots",
+			selected: []string{"snap0", "snap1", "snap2"},
+			expected: []string{"snap0", "snap1", "snap2"},
+		},
+		{
+			name:     "Missing snapshot",
+			selected: []string{"snap3"},
+			err:      `Snapshot "snap3" not found in backup`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := GetInfo(testBackupWithSnapshots(t), nil, "")
+			require.NoError(t, err)
+			require.Equal(t, []string{"snap0", "snap1", "snap2"}, info.Snapshots)
+
+			err = info.SelectSnapshots(tt.selected)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+
+				// The backup info is left untouched.
+				assert.Equal(t, []string{"snap0", "snap1", "snap2"}, info.Snapshots)
+				return
+			}
+
+			require.NoError(t, err)
+
+			instSnapshots, volSnapshots := snapshotNames(info.Config)
+			assert.ElementsMatch(t, tt.expected, info.Snapshots)
+			assert.Equal(t, tt.expected, instSnapshots)
+			assert.Equal(t, tt.expected, volSnapshots)
+		})
+	}
+}
+
+func TestSelectSnapshotsOptimized(t *testing.T) {
+	info, err := GetInfo(testBackupWithSnapshots(t), nil, "")
+	require.NoError(t, err)
+
+	optimized := true
+	info.OptimizedStorage = &optimized
+
+	// Optimized backups can't be partially restored.
+	err = info.SelectSnapshots([]string{"snap0"})
+	assert.Error(t, err)
+
+	err = info.SelectSnapshots([]string{"snap0", "snap1", "snap2"})
+	assert.NoError(t, err)
+}
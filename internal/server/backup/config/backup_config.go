@@ -1,6 +1,8 @@
 package config
 
 import (
+	"slices"
+
 	"github.com/lxc/incus/v6/shared/api"
 )
 
@@ -15,3 +17,23 @@ type Config struct {
 	Bucket          *api.StorageBucket           `yaml:"bucket,omitempty"`
 	BucketKeys      []*api.StorageBucketKey      `yaml:"bucket_keys,omitempty"`
 }
+
+// FilterSnapshots removes the instance and volume snapshots whose name isn't in the given list.
+func (c *Config) FilterSnapshots(names []string) {
+	snapshots := make([]*api.InstanceSnapshot, 0, len(names))
+	for _, snap := range c.Snapshots {
+		if snap != nil && slices.Contains(names, snap.Name) {
+			snapshots = append(snapshots, snap)
+		}
+	}
+
+	volumeSnapshots := make([]*api.StorageVolumeSnapshot, 0, len(names))
+	for _, snap := range c.VolumeSnapshots {
+		if snap != nil && slices.Contains(names, snap.Name) {
+			volumeSnapshots = append(volumeSnapshots, snap)
+		}
+	}
+
+	c.Snapshots = snapshots
+	c.VolumeSnapshots = volumeSnapshots
+}
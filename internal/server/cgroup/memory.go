@@ -0,0 +1,82 @@
+package cgroup
+
+import (
+	"fmt"
+
+	"github.com/lxc/incus/v6/shared/units"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// MemorySwapLimit returns the swap limit to apply for a memory enforcement mode and swap configuration.
+// A swap configuration of "false" prevents any swap usage and a size allows using that much swap on top of the
+// memory limit. When unset or "true", no additional swap is allowed with "hard" enforcement and swap usage is left
+// unrestricted (-1) with "soft" enforcement.
+func MemorySwapLimit(enforce string, swap string) (int64, error) {
+	if util.IsFalse(swap) {
+		return 0, nil
+	}
+
+	if util.IsTrueOrEmpty(swap) {
+		if enforce == "soft" {
+			return -1, nil
+		}
+
+		return 0, nil
+	}
+
+	swapInt, err := units.ParseByteSizeString(swap)
+	if err != nil {
+		return -1, fmt.Errorf("Invalid swap limit %q: %w", swap, err)
+	}
+
+	return swapInt, nil
+}
+
+// SetMemoryLimits applies the memory limit according to the enforcement mode, along with the swap limit
+// computed by MemorySwapLimit. The swap limit is skipped when setSwap is false.
+//
+// With "hard" enforcement, the limit is the maximum amount of memory the cgroup can use before being OOM killed.
+// With "soft" enforcement, the cgroup is throttled and has its memory reclaimed once above the limit, leaving it
+// headroom before any OOM kill. Swap can then only be limited on cgroup v2, as cgroup v1 only accounts for it
+// as part of a hard limit.
+func (cg *CGroup) SetMemoryLimits(limit int64, enforce string, swapLimit int64, setSwap bool) error {
+	version := cgControllers["memory"]
+
+	if enforce == "soft" {
+		err := cg.SetMemorySoftLimit(limit)
+		if err != nil {
+			return err
+		}
+
+		if setSwap && version == V2 {
+			err = cg.SetMemorySwapLimit(swapLimit)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	err := cg.SetMemoryLimit(limit)
+	if err != nil {
+		return err
+	}
+
+	if setSwap {
+		err = cg.SetMemorySwapLimit(swapLimit)
+		if err != nil {
+			return err
+		}
+	}
+
+	// On cgroup v1, set the soft limit to a value 10% less than the hard limit.
+	if version == V1 && limit > 0 {
+		err = cg.SetMemorySoftLimit(int64(float64(limit) * 0.9))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
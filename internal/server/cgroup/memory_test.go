@@ -0,0 +1,125 @@
+package cgroup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTestReadWriter records the values written to the cgroup files.
+type memoryTestReadWriter struct {
+	values map[string]string
+}
+
+func (rw *memoryTestReadWriter) Get(backend Backend, controller string, key string) (string, error) {
+	return rw.values[key], nil
+}
+
+func (rw *memoryTestReadWriter) Set(backend Backend, controller string, key string, value string) error {
+	rw.values[key] = value
+	return nil
+}
+
+func TestMemorySwapLimit(t *testing.T) {
+	tests := []struct {
+		enforce string
+		swap    string
+		limit   int64
+	}{
+		{"", "", 0},
+		{"hard", "true", 0},
+		{"hard", "false", 0},
+		{"hard", "1GiB", 1073741824},
+		{"soft", "", -1},
+		{"soft", "true", -1},
+		{"soft", "false", 0},
+		{"soft", "512MiB", 536870912},
+	}
+
+	for _, test := range tests {
+		limit, err := MemorySwapLimit(test.enforce, test.swap)
+		require.NoError(t, err, test.enforce+"/"+test.swap)
+		assert.Equal(t, test.limit, limit, test.enforce+"/"+test.swap)
+	}
+
+	_, err := MemorySwapLimit("hard", "invalid")
+	assert.Error(t, err)
+}
+
+func TestSetMemoryLimits(t *testing.T) {
+	oldControllers := cgControllers
+	t.Cleanup(func() { cgControllers = oldControllers })
+
+	tests := []struct {
+		name      string
+		version   Backend
+		enforce   string
+		swapLimit int64
+		setSwap   bool
+		values    map[string]string
+	}{
+		{
+			name:      "hard v2",
+			version:   V2,
+			enforce:   "hard",
+			swapLimit: 536870912,
+			setSwap:   true,
+			values:    map[string]string{"memory.max": "1073741824", "memory.swap.max": "536870912"},
+		},
+		{
+			name:      "hard v2 without swap accounting",
+			version:   V2,
+			enforce:   "",
+			swapLimit: 0,
+			setSwap:   false,
+			values:    map[string]string{"memory.max": "1073741824"},
+		},
+		{
+			name:      "soft v2",
+			version:   V2,
+			enforce:   "soft",
+			swapLimit: -1,
+			setSwap:   true,
+			values:    map[string]string{"memory.high": "1073741824", "memory.swap.max": "max"},
+		},
+		{
+			name:      "soft v2 with swap limit",
+			version:   V2,
+			enforce:   "soft",
+			swapLimit: 536870912,
+			setSwap:   true,
+			values:    map[string]string{"memory.high": "1073741824", "memory.swap.max": "536870912"},
+		},
+		{
+			name:      "hard v1",
+			version:   V1,
+			enforce:   "hard",
+			swapLimit: 536870912,
+			setSwap:   true,
+			values:    map[string]string{"memory.limit_in_bytes": "1073741824", "memory.memsw.limit_in_bytes": "1610612736", "memory.soft_limit_in_bytes": "966367641"},
+		},
+		{
+			name:      "soft v1",
+			version:   V1,
+			enforce:   "soft",
+			swapLimit: 536870912,
+			setSwap:   true,
+			values:    map[string]string{"memory.soft_limit_in_bytes": "1073741824"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cgControllers = map[string]Backend{"memory": test.version}
+
+			rw := &memoryTestReadWriter{values: map[string]string{}}
+			cg, err := New(rw)
+			require.NoError(t, err)
+
+			err = cg.SetMemoryLimits(1073741824, test.enforce, test.swapLimit, test.setSwap)
+			require.NoError(t, err)
+			assert.Equal(t, test.values, rw.values)
+		})
+	}
+}
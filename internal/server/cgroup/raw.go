@@ -0,0 +1,162 @@
+package cgroup
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RawAllowedKeys lists the cgroup v2 files which can be set through raw configuration.
+// Only resource control files of namespaced controllers are allowed, the core "cgroup.*" files as well as
+// the files managed by Incus itself (like "cpuset.*") are not.
+var RawAllowedKeys = []string{
+	"cpu.idle",
+	"cpu.max",
+	"cpu.max.burst",
+	"cpu.uclamp.max",
+	"cpu.uclamp.min",
+	"cpu.weight",
+	"cpu.weight.nice",
+	"io.bfq.weight",
+	"io.latency",
+	"io.max",
+	"io.weight",
+	"memory.high",
+	"memory.low",
+	"memory.max",
+	"memory.min",
+	"memory.oom.group",
+	"memory.swap.high",
+	"memory.swap.max",
+	"memory.zswap.max",
+	"pids.max",
+}
+
+// rawDefaults lists the values resetting the cgroup files which can be set through raw configuration.
+// Files holding per-device values are reset for the device of the removed entry.
+var rawDefaults = map[string]string{
+	"cpu.idle":         "0",
+	"cpu.max":          "max 100000",
+	"cpu.max.burst":    "0",
+	"cpu.uclamp.max":   "max",
+	"cpu.uclamp.min":   "0",
+	"cpu.weight":       "100",
+	"cpu.weight.nice":  "0",
+	"io.bfq.weight":    "100",
+	"io.latency":       "target=0",
+	"io.max":           "rbps=max wbps=max riops=max wiops=max",
+	"io.weight":        "default 100",
+	"memory.high":      "max",
+	"memory.low":       "0",
+	"memory.max":       "max",
+	"memory.min":       "0",
+	"memory.oom.group": "0",
+	"memory.swap.high": "max",
+	"memory.swap.max":  "max",
+	"memory.zswap.max": "max",
+	"pids.max":         "max",
+}
+
+// rawPerDeviceKeys lists the cgroup files whose values start with the device they apply to.
+var rawPerDeviceKeys = []string{"io.latency", "io.max"}
+
+// RawEntry represents a value to write to a cgroup file.
+type RawEntry struct {
+	Key   string
+	Value string
+}
+
+// ParseRaw parses a raw cgroup configuration, made of one "<file> = <value>" entry per line.
+// Empty lines and lines starting with "#" are ignored.
+func ParseRaw(config string) ([]RawEntry, error) {
+	entries := []RawEntry{}
+
+	for i, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("Invalid raw cgroup entry on line %d, must be of the form <file> = <value>", i+1)
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if !slices.Contains(RawAllowedKeys, key) {
+			return nil, fmt.Errorf("Cgroup file %q on line %d can't be set, must be one of: %s", key, i+1, strings.Join(RawAllowedKeys, ", "))
+		}
+
+		if value == "" {
+			return nil, fmt.Errorf("Missing value for cgroup file %q on line %d", key, i+1)
+		}
+
+		entries = append(entries, RawEntry{Key: key, Value: value})
+	}
+
+	return entries, nil
+}
+
+// rawEntryID returns what identifies the cgroup setting changed by an entry.
+func rawEntryID(entry RawEntry) string {
+	if !slices.Contains(rawPerDeviceKeys, entry.Key) {
+		return entry.Key
+	}
+
+	device, _, _ := strings.Cut(entry.Value, " ")
+
+	return entry.Key + " " + device
+}
+
+// RawReset returns the entries resetting the cgroup settings which are set by the old raw cgroup configuration
+// but not anymore by the new one.
+func RawReset(oldConfig string, newConfig string) ([]RawEntry, error) {
+	oldEntries, err := ParseRaw(oldConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	newEntries, err := ParseRaw(newConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := map[string]bool{}
+	for _, entry := range newEntries {
+		kept[rawEntryID(entry)] = true
+	}
+
+	resets := []RawEntry{}
+	for _, entry := range oldEntries {
+		id := rawEntryID(entry)
+		if kept[id] {
+			continue
+		}
+
+		// Only reset each setting once.
+		kept[id] = true
+
+		value := rawDefaults[entry.Key]
+		if slices.Contains(rawPerDeviceKeys, entry.Key) {
+			device, _, _ := strings.Cut(entry.Value, " ")
+			value = device + " " + value
+		}
+
+		resets = append(resets, RawEntry{Key: entry.Key, Value: value})
+	}
+
+	return resets, nil
+}
+
+// SetRaw writes the value to a cgroup v2 file of the cgroup.
+func (cg *CGroup) SetRaw(key string, value string) error {
+	controller, _, _ := strings.Cut(key, ".")
+
+	if cgControllers[controller] != V2 {
+		return fmt.Errorf("Controller %q isn't available through cgroup v2: %w", controller, ErrControllerMissing)
+	}
+
+	return cg.rw.Set(V2, controller, key, value)
+}
@@ -0,0 +1,46 @@
+package cgroup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRaw(t *testing.T) {
+	entries, err := ParseRaw("memory.high = 1073741824\n\n# Comment\n  io.max = 8:0 rbps=1048576\nio.max=8:16 wbps=max\n")
+	require.NoError(t, err)
+	assert.Equal(t, []RawEntry{
+		{Key: "memory.high", Value: "1073741824"},
+		{Key: "io.max", Value: "8:0 rbps=1048576"},
+		{Key: "io.max", Value: "8:16 wbps=max"},
+	}, entries)
+
+	entries, err = ParseRaw("")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	for _, config := range []string{"cgroup.procs = 1", "cgroup.subtree_control = +memory", "cpuset.cpus = 0", "devices.allow = a", "memory.high", "memory.high =", "../memory.high = 1"} {
+		_, err = ParseRaw(config)
+		assert.Error(t, err, config)
+	}
+}
+
+func TestRawReset(t *testing.T) {
+	resets, err := RawReset("memory.high = 1073741824\npids.max = 10\nio.max = 8:0 rbps=1048576\nio.max = 8:16 wbps=1048576\ncpu.max = 50000 100000\n", "pids.max = 20\nio.max = 8:16 wbps=2097152\n")
+	require.NoError(t, err)
+	assert.Equal(t, []RawEntry{
+		{Key: "memory.high", Value: "max"},
+		{Key: "io.max", Value: "8:0 rbps=max wbps=max riops=max wiops=max"},
+		{Key: "cpu.max", Value: "max 100000"},
+	}, resets)
+
+	resets, err = RawReset("", "memory.high = 1073741824")
+	require.NoError(t, err)
+	assert.Empty(t, resets)
+
+	// Every allowed file can be reset.
+	for _, key := range RawAllowedKeys {
+		assert.Contains(t, rawDefaults, key)
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/lxc/incus/v6/internal/server/config"
 	"github.com/lxc/incus/v6/internal/server/db"
 	scriptletLoad "github.com/lxc/incus/v6/internal/server/scriptlet/load"
+	"github.com/lxc/incus/v6/shared/units"
+	"github.com/lxc/incus/v6/shared/util"
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
@@ -54,6 +56,11 @@ func (c *Config) BGPASN() int64 {
 	return c.m.GetInt64("core.bgp_asn")
 }
 
+// EventHistorySize returns the number of events kept in the event history.
+func (c *Config) EventHistorySize() int64 {
+	return c.m.GetInt64("core.event_history_size")
+}
+
 // HTTPSAllowedHeaders returns the relevant CORS setting.
 func (c *Config) HTTPSAllowedHeaders() string {
 	return c.m.GetString("core.https_allowed_headers")
@@ -203,11 +210,64 @@ func (c *Config) ImagesRemoteCacheExpiryDays() int64 {
 	return c.m.GetInt64("images.remote_cache_expiry")
 }
 
+// ImagesMirror returns the address, protocol and certificate of the upstream image server to mirror, if any.
+func (c *Config) ImagesMirror() (string, string, string) {
+	return c.m.GetString("images.mirror.server"), c.m.GetString("images.mirror.protocol"), c.m.GetString("images.mirror.certificate")
+}
+
+// ImagesMirrorCacheSize returns the maximum size in bytes of the images cached from the upstream image server.
+func (c *Config) ImagesMirrorCacheSize() int64 {
+	value := c.m.GetString("images.mirror.cache_size")
+	if value == "" {
+		return 0
+	}
+
+	size, err := units.ParseByteSizeString(value)
+	if err != nil {
+		return 0
+	}
+
+	return size
+}
+
 // InstancesNICHostname returns hostname mode to use for instance NICs.
 func (c *Config) InstancesNICHostname() string {
 	return c.m.GetString("instances.nic.host_name")
 }
 
+// MetricsCacheTTL returns how long instance metrics are cached for.
+func (c *Config) MetricsCacheTTL() time.Duration {
+	return time.Duration(c.m.GetInt64("core.metrics_cache_ttl")) * time.Second
+}
+
+// MetricsCacheTTLFilesystem returns how long instance filesystem usage metrics are cached for.
+func (c *Config) MetricsCacheTTLFilesystem() time.Duration {
+	return time.Duration(c.m.GetInt64("core.metrics_cache_ttl.filesystem")) * time.Second
+}
+
+// MetricsExemplars returns whether OpenMetrics exemplars may be included in the metrics.
+func (c *Config) MetricsExemplars() bool {
+	return c.m.GetBool("core.metrics_exemplars")
+}
+
+// InstancesMetricsEnabled returns whether metrics are collected for instances which don't set metrics.enabled.
+func (c *Config) InstancesMetricsEnabled() bool {
+	return c.m.GetBool("instances.metrics.enabled")
+}
+
+// StorageVolumeDefaults returns the server level defaults of new custom storage volumes, keyed by volume config key.
+func (c *Config) StorageVolumeDefaults() map[string]string {
+	defaults := map[string]string{}
+	for _, key := range []string{"block.filesystem", "block.mount_options", "size", "zfs.blocksize"} {
+		value := c.m.GetString("storage.volume." + key)
+		if value != "" {
+			defaults[key] = value
+		}
+	}
+
+	return defaults
+}
+
 // InstancesPlacementScriptlet returns the instances placement scriptlet source code.
 func (c *Config) InstancesPlacementScriptlet() string {
 	return c.m.GetString("instances.placement.scriptlet")
@@ -287,6 +347,16 @@ func (c *Config) ClusterJoinTokenExpiry() string {
 	return c.m.GetString("cluster.join_token_expiry")
 }
 
+// RateLimit returns the API request rate and burst allowed per client and per project.
+func (c *Config) RateLimit() (int64, int64, int64, int64) {
+	return c.m.GetInt64("core.rate_limit.requests"), c.m.GetInt64("core.rate_limit.burst"), c.m.GetInt64("core.rate_limit.project_requests"), c.m.GetInt64("core.rate_limit.project_burst")
+}
+
+// RateLimitExempt returns the clients which are never rate limited.
+func (c *Config) RateLimitExempt() []string {
+	return util.SplitNTrimSpace(c.m.GetString("core.rate_limit.exempt"), ",", -1, true)
+}
+
 // RemoteTokenExpiry returns the time after which a remote add token expires.
 func (c *Config) RemoteTokenExpiry() string {
 	return c.m.GetString("core.remote_token_expiry")
@@ -640,6 +710,38 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to enforce authentication on the metrics endpoint
 	"core.metrics_authentication": {Type: config.Bool, Default: "true"},
 
+	// gendoc:generate(entity=server, group=core, key=core.metrics_cache_ttl)
+	// Instance metrics computed within this many seconds are reused instead of being collected again.
+	// This lets multiple scrapers or frequent scrapes share a single collection.
+	// Set to `0` to always collect fresh metrics.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `8`
+	//  shortdesc: Number of seconds for which instance metrics are cached
+	"core.metrics_cache_ttl": {Type: config.Int64, Default: "8", Validator: validate.Optional(validate.IsUint32)},
+
+	// gendoc:generate(entity=server, group=core, key=core.metrics_cache_ttl.filesystem)
+	// Filesystem usage metrics are more expensive to collect than the counters and change slowly,
+	// so they can be cached for longer than the rest of the instance metrics.
+	// Set to `0` to collect them along with the other instance metrics.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `60`
+	//  shortdesc: Number of seconds for which instance filesystem usage metrics are cached
+	"core.metrics_cache_ttl.filesystem": {Type: config.Int64, Default: "60", Validator: validate.Optional(validate.IsUint32)},
+
+	// gendoc:generate(entity=server, group=core, key=core.metrics_exemplars)
+	// When enabled, API requests changing an instance which carry a sampled W3C `traceparent` header are recorded,
+	// and scrapers requesting the OpenMetrics format get the ID of the latest such trace attached as an exemplar to the instance counters.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to include trace exemplars in the metrics
+	"core.metrics_exemplars": {Type: config.Bool, Default: "false"},
+
 	// gendoc:generate(entity=server, group=core, key=core.bgp_asn)
 	//
 	// ---
@@ -648,6 +750,16 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: BGP Autonomous System Number for the local server
 	"core.bgp_asn": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsInRange(0, 4294967294))},
 
+	// gendoc:generate(entity=server, group=core, key=core.event_history_size)
+	// Specify the number of recent events each server keeps in memory so that event listeners can replay them when connecting.
+	// Set to `0` to disable the event history.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: Number of events kept for replay
+	"core.event_history_size": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsInRange(0, 1000000))},
+
 	// gendoc:generate(entity=server, group=core, key=core.https_allowed_headers)
 	//
 	// ---
@@ -715,6 +827,56 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Hosts that don't need the proxy
 
 	"core.proxy_ignore_hosts": {},
+	// gendoc:generate(entity=server, group=core, key=core.rate_limit.burst)
+	// Specify the maximum number of API requests a client can make in a burst.
+	// Set to `0` to allow bursts of twice `core.rate_limit.requests`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: API request burst allowed per client
+	"core.rate_limit.burst": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
+	// gendoc:generate(entity=server, group=core, key=core.rate_limit.exempt)
+	// Specify a comma-separated list of client certificate fingerprints, usernames, addresses or subnets (in CIDR notation) which are never rate limited.
+	// Requests from other cluster members and through the local Unix socket are always exempt.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Clients exempt from API rate limiting
+	"core.rate_limit.exempt": {},
+
+	// gendoc:generate(entity=server, group=core, key=core.rate_limit.project_burst)
+	// Specify the maximum number of API requests that can be made against a single project in a burst, across all clients.
+	// Set to `0` to allow bursts of twice `core.rate_limit.project_requests`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: API request burst allowed per project
+	"core.rate_limit.project_burst": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
+	// gendoc:generate(entity=server, group=core, key=core.rate_limit.project_requests)
+	// Specify the number of API requests per second that can be made against a single project, across all clients.
+	// Set to `0` to disable the per-project rate limiting.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: API requests per second allowed per project
+	"core.rate_limit.project_requests": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
+	// gendoc:generate(entity=server, group=core, key=core.rate_limit.requests)
+	// Specify the number of API requests per second a client (identified by its certificate, username or address) can make.
+	// Requests over the limit are rejected with a `429 Too Many Requests` error.
+	// Set to `0` to disable the per-client rate limiting.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: API requests per second allowed per client
+	"core.rate_limit.requests": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
 	// gendoc:generate(entity=server, group=core, key=core.remote_token_expiry)
 	//
 	// ---
@@ -777,6 +939,42 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Default architecture to use in a mixed-architecture cluster
 	"images.default_architecture": {Validator: validate.Optional(validate.IsArchitecture)},
 
+	// gendoc:generate(entity=server, group=images, key=images.mirror.cache_size)
+	// When the images cached from the upstream image server go over this size, the least recently used ones are removed.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: no limit
+	//  shortdesc: Maximum size of the images cached from the upstream image server
+	"images.mirror.cache_size": {Validator: validate.Optional(validate.IsSize)},
+
+	// gendoc:generate(entity=server, group=images, key=images.mirror.certificate)
+	// Only needed when the certificate of the upstream image server isn't signed by a trusted CA.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: PEM encoded certificate of the upstream image server
+	"images.mirror.certificate": {},
+
+	// gendoc:generate(entity=server, group=images, key=images.mirror.protocol)
+	// Possible values are `incus` or `simplestreams`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `simplestreams`
+	//  shortdesc: Protocol of the upstream image server
+	"images.mirror.protocol": {Default: "simplestreams", Validator: validate.IsOneOf("incus", "simplestreams")},
+
+	// gendoc:generate(entity=server, group=images, key=images.mirror.server)
+	// When set, images and aliases requested from this server but missing locally are fetched from the upstream image server and cached.
+	// The fetch happens in the background, so a missing image or alias becomes available once its fetch completes.
+	// Aliases are checked against the upstream server at most once every five minutes so that updated images get picked up.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: URL of the upstream image server to mirror
+	"images.mirror.server": {Validator: validate.Optional(validate.IsRequestURL)},
+
 	// gendoc:generate(entity=server, group=images, key=images.remote_cache_expiry)
 	// Specify the number of days after which the unused cached image expires.
 	// ---
@@ -803,6 +1001,15 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to run LXCFS on a per-instance basis
 	"instances.lxcfs.per_instance": {Type: config.Bool, Validator: validate.Optional(validate.IsBool)},
 
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.metrics.enabled)
+	// Instances can override this with their own {config:option}`instance-miscellaneous:metrics.enabled` option.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `true`
+	//  shortdesc: Whether to collect metrics for instances by default
+	"instances.metrics.enabled": {Type: config.Bool, Default: "true"},
+
 	// gendoc:generate(entity=server, group=miscellaneous, key=instances.nic.host_name)
 	// Possible values are `random` and `mac`.
 	//
@@ -1034,6 +1241,38 @@ var ConfigSchema = config.Schema{
 	//  scope: global
 	//  shortdesc: LINSTOR SSL client key
 	"storage.linstor.client_key": {Default: ""},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=storage.volume.block.filesystem)
+	// Used by new custom volumes on block-backed storage pools which don't set `volume.block.filesystem`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Default file system of new custom storage volumes
+	"storage.volume.block.filesystem": {Validator: validate.Optional(validate.IsOneOf("btrfs", "ext4", "xfs"))},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=storage.volume.block.mount_options)
+	// Used by new custom volumes on block-backed storage pools which don't set `volume.block.mount_options`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Default mount options of new custom storage volumes
+	"storage.volume.block.mount_options": {},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=storage.volume.size)
+	// Used by new custom volumes on storage pools which don't set `volume.size`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Default size of new custom storage volumes
+	"storage.volume.size": {Validator: validate.Optional(validate.IsSize)},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=storage.volume.zfs.blocksize)
+	// Used by new custom volumes on ZFS storage pools which don't set `volume.zfs.blocksize`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Default ZFS block size of new custom storage volumes
+	"storage.volume.zfs.blocksize": {Validator: validate.Optional(validate.IsSize)},
 }
 
 func expiryValidator(value string) error {
@@ -10,6 +10,7 @@ import (
 	"golang.org/x/sys/unix"
 
 	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/resources"
 	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
 	"github.com/lxc/incus/v6/shared/api"
@@ -107,5 +108,12 @@ func MemberState(ctx context.Context, s *state.State, memberName string) (*api.C
 		}
 	}
 
+	// Get device availability, leaving it unknown rather than failing the whole state request.
+	memberState.Devices, err = resources.GetDevices()
+	if err != nil {
+		logger.Warn("Failed getting device availability", logger.Ctx{"err": err})
+		memberState.Devices = nil
+	}
+
 	return &memberState, nil
 }
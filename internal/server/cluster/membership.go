@@ -1019,6 +1019,111 @@ func Handover(state *state.State, gateway *Gateway, address string) (string, []d
 	return "", nil, nil
 }
 
+// RoleChange is a database role change to apply to a member, along with the resulting list of raft nodes.
+type RoleChange struct {
+	Address string
+	Nodes   []db.RaftNode
+}
+
+// ChangeRole works out the changes needed to give the member with the given address the requested database
+// role. To keep the configured number of voters and stand-bys, the member swaps roles with another online
+// member unless a spare is promoted into a free slot. The changes are returned in the order they must be
+// applied, promotions first so that the number of voters never drops.
+//
+// It should be called only by the current leader.
+func ChangeRole(state *state.State, gateway *Gateway, address string, role db.RaftRole) ([]RoleChange, error) {
+	nodes, err := gateway.currentRaftNodes()
+	if err != nil {
+		return nil, fmt.Errorf("Get current raft nodes: %w", err)
+	}
+
+	online := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		online[node.Address] = HasConnectivity(gateway.networkCert, gateway.state().ServerCert(), node.Address, false)
+	}
+
+	return planRoleChange(nodes, online, gateway.info.ID, address, role, int(state.GlobalConfig.MaxVoters()), int(state.GlobalConfig.MaxStandBy()))
+}
+
+// planRoleChange implements ChangeRole against the given cluster state.
+func planRoleChange(nodes []db.RaftNode, online map[string]bool, leaderID uint64, address string, role db.RaftRole, maxVoters int, maxStandBys int) ([]RoleChange, error) {
+	nodes = slices.Clone(nodes)
+
+	target := -1
+	counts := map[db.RaftRole]int{}
+	for i, node := range nodes {
+		counts[node.Role]++
+
+		if node.Address == address {
+			target = i
+		}
+	}
+
+	if target == -1 {
+		return nil, fmt.Errorf("Cluster member %q isn't part of the database cluster", address)
+	}
+
+	oldRole := nodes[target].Role
+	if oldRole == role {
+		return nil, nil
+	}
+
+	if len(nodes) < 3 {
+		return nil, fmt.Errorf("Database roles can only be changed in clusters of at least 3 members")
+	}
+
+	if nodes[target].ID == leaderID {
+		return nil, fmt.Errorf("The database role of the leader can't be changed")
+	}
+
+	if !online[address] {
+		return nil, fmt.Errorf("Cluster member %q is offline", nodes[target].Name)
+	}
+
+	if role == db.RaftStandBy && maxStandBys == 0 {
+		return nil, fmt.Errorf("No stand-by database members are allowed by \"cluster.max_standby\"")
+	}
+
+	changes := []RoleChange{}
+	apply := func(i int, role db.RaftRole) {
+		nodes[i].Role = role
+		changes = append(changes, RoleChange{Address: nodes[i].Address, Nodes: slices.Clone(nodes)})
+	}
+
+	// A spare can be promoted directly if the requested role has a free slot.
+	limits := map[db.RaftRole]int{db.RaftVoter: maxVoters, db.RaftStandBy: maxStandBys}
+	if oldRole == db.RaftSpare && counts[role] < limits[role] {
+		apply(target, role)
+		return changes, nil
+	}
+
+	// Otherwise swap roles with an online member currently holding the requested role.
+	partner := -1
+	for i, node := range nodes {
+		if i == target || node.Role != role || node.ID == leaderID || !online[node.Address] {
+			continue
+		}
+
+		partner = i
+		break
+	}
+
+	if partner == -1 {
+		return nil, fmt.Errorf("No online cluster member is available to swap database roles with")
+	}
+
+	// Voter is the lowest role value and spare the highest, apply the promotion first.
+	if role < oldRole {
+		apply(target, role)
+		apply(partner, oldRole)
+	} else {
+		apply(partner, oldRole)
+		apply(target, role)
+	}
+
+	return changes, nil
+}
+
 // Build an app.RolesChanges object fed with the current cluster state.
 func newRolesChanges(state *state.State, gateway *Gateway, nodes []db.RaftNode, unavailableMembers []string) (*app.RolesChanges, error) {
 	var domains map[string]uint64
@@ -0,0 +1,4 @@
+package cluster
+
+// PlanRoleChange exposes planRoleChange for testing.
+var PlanRoleChange = planRoleChange
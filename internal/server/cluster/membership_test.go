@@ -510,3 +510,75 @@ func (h *membershipFixtures) ClusterNode(address string) {
 	})
 	require.NoError(h.t, err)
 }
+
+func TestPlanRoleChange(t *testing.T) {
+	newNodes := func(roles ...db.RaftRole) []db.RaftNode {
+		nodes := []db.RaftNode{}
+		for i, role := range roles {
+			node := db.RaftNode{Name: fmt.Sprintf("n%d", i+1)}
+			node.ID = uint64(i + 1)
+			node.Address = fmt.Sprintf("10.0.0.%d:8443", i+1)
+			node.Role = role
+			nodes = append(nodes, node)
+		}
+
+		return nodes
+	}
+
+	allOnline := func(nodes []db.RaftNode) map[string]bool {
+		online := map[string]bool{}
+		for _, node := range nodes {
+			online[node.Address] = true
+		}
+
+		return online
+	}
+
+	// Promoting a stand-by to voter swaps roles with a non-leader voter, promotion first.
+	nodes := newNodes(db.RaftVoter, db.RaftVoter, db.RaftVoter, db.RaftStandBy)
+	changes, err := cluster.PlanRoleChange(nodes, allOnline(nodes), 1, "10.0.0.4:8443", db.RaftVoter, 3, 2)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "10.0.0.4:8443", changes[0].Address)
+	assert.Equal(t, db.RaftVoter, changes[0].Nodes[3].Role)
+	assert.Equal(t, "10.0.0.2:8443", changes[1].Address)
+	assert.Equal(t, db.RaftStandBy, changes[1].Nodes[1].Role)
+	assert.Equal(t, db.RaftStandBy, nodes[3].Role) // Input isn't modified.
+
+	// Demoting a voter to spare first promotes the spare it swaps with.
+	nodes = newNodes(db.RaftVoter, db.RaftVoter, db.RaftVoter, db.RaftSpare)
+	changes, err = cluster.PlanRoleChange(nodes, allOnline(nodes), 1, "10.0.0.3:8443", db.RaftSpare, 3, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "10.0.0.4:8443", changes[0].Address)
+	assert.Equal(t, "10.0.0.3:8443", changes[1].Address)
+	assert.Equal(t, db.RaftSpare, changes[1].Nodes[2].Role)
+	assert.Equal(t, db.RaftVoter, changes[1].Nodes[3].Role)
+
+	// A spare is promoted directly into a free stand-by slot.
+	nodes = newNodes(db.RaftVoter, db.RaftVoter, db.RaftVoter, db.RaftSpare)
+	changes, err = cluster.PlanRoleChange(nodes, allOnline(nodes), 1, "10.0.0.4:8443", db.RaftStandBy, 3, 2)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, db.RaftStandBy, changes[0].Nodes[3].Role)
+
+	// Nothing to do when the member already has the role.
+	changes, err = cluster.PlanRoleChange(nodes, allOnline(nodes), 1, "10.0.0.2:8443", db.RaftVoter, 3, 2)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// The leader can't be demoted.
+	_, err = cluster.PlanRoleChange(nodes, allOnline(nodes), 1, "10.0.0.1:8443", db.RaftSpare, 3, 2)
+	assert.Error(t, err)
+
+	// Demoting a voter requires an online member to take over.
+	online := allOnline(nodes)
+	online["10.0.0.4:8443"] = false
+	_, err = cluster.PlanRoleChange(nodes, online, 1, "10.0.0.3:8443", db.RaftSpare, 3, 2)
+	assert.Error(t, err)
+
+	// Roles can't be changed in small clusters.
+	nodes = newNodes(db.RaftVoter, db.RaftSpare)
+	_, err = cluster.PlanRoleChange(nodes, allOnline(nodes), 1, "10.0.0.2:8443", db.RaftVoter, 3, 2)
+	assert.Error(t, err)
+}
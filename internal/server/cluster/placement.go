@@ -0,0 +1,321 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	pcidev "github.com/lxc/incus/v6/internal/server/device/pci"
+	"github.com/lxc/incus/v6/internal/server/resources"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// DeviceRequirements represents the specialized devices an instance needs on its cluster member.
+type DeviceRequirements struct {
+	GPUs          []GPURequirement
+	InfinibandVFs uint64
+	PCIAddresses  []string
+}
+
+// GPURequirement represents the GPU selection of a GPU device.
+type GPURequirement struct {
+	Type      string
+	PCI       string
+	ID        string
+	VendorID  string
+	ProductID string
+}
+
+// matches returns whether the GPU satisfies the selection.
+func (r GPURequirement) matches(gpu api.ClusterMemberGPU) bool {
+	if !slices.Contains(gpu.Types, r.Type) {
+		return false
+	}
+
+	if r.PCI != "" && gpu.PCIAddress != r.PCI {
+		return false
+	}
+
+	if r.VendorID != "" && gpu.VendorID != r.VendorID {
+		return false
+	}
+
+	if r.ProductID != "" && gpu.ProductID != r.ProductID {
+		return false
+	}
+
+	// Only DRM card IDs can be checked, CDI identifiers are resolved on the member itself.
+	id, err := strconv.ParseUint(r.ID, 10, 64)
+	if err == nil && (gpu.DRMID == nil || *gpu.DRMID != id) {
+		return false
+	}
+
+	return true
+}
+
+// GetDeviceRequirements returns the specialized devices needed by the given (expanded) instance devices.
+func GetDeviceRequirements(devices deviceConfig.Devices) DeviceRequirements {
+	req := DeviceRequirements{}
+
+	for _, dev := range devices {
+		switch dev["type"] {
+		case "gpu":
+			gpuType := dev["gputype"]
+			if gpuType == "" {
+				gpuType = "physical"
+			}
+
+			gpu := GPURequirement{
+				Type:      gpuType,
+				ID:        dev["id"],
+				VendorID:  dev["vendorid"],
+				ProductID: dev["productid"],
+			}
+
+			if dev["pci"] != "" {
+				gpu.PCI = pcidev.NormaliseAddress(dev["pci"])
+			}
+
+			req.GPUs = append(req.GPUs, gpu)
+		case "infiniband":
+			if dev["nictype"] == "sriov" {
+				req.InfinibandVFs++
+			}
+
+		case "pci":
+			if dev["address"] != "" {
+				req.PCIAddresses = append(req.PCIAddresses, dev["address"])
+			}
+		}
+	}
+
+	return req
+}
+
+// IsEmpty returns whether no specialized device is required.
+func (r DeviceRequirements) IsEmpty() bool {
+	return len(r.GPUs) == 0 && r.InfinibandVFs == 0 && len(r.PCIAddresses) == 0
+}
+
+// SatisfiedBy returns whether the member device availability satisfies the requirements.
+func (r DeviceRequirements) SatisfiedBy(devices *api.ClusterMemberDevices) bool {
+	if !r.gpusSatisfiedBy(devices) {
+		return false
+	}
+
+	if devices.InfinibandVFFree < r.InfinibandVFs {
+		return false
+	}
+
+	for _, address := range r.PCIAddresses {
+		if !slices.Contains(devices.PCIFree, address) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// gpusSatisfiedBy returns whether the member has a matching GPU for each GPU device.
+// Physical GPUs are passed through whole so each needs its own GPU, while the other GPU types share the GPU.
+func (r DeviceRequirements) gpusSatisfiedBy(devices *api.ClusterMemberDevices) bool {
+	// Members which don't report their GPUs can only be checked on the number of GPUs.
+	if devices.GPUs == nil {
+		return devices.GPUFree >= uint64(len(r.GPUs))
+	}
+
+	// Place the most specific selections first so that they don't lose their GPU to a looser one.
+	gpus := slices.Clone(r.GPUs)
+	slices.SortStableFunc(gpus, func(a GPURequirement, b GPURequirement) int {
+		return gpuSelectionFilters(b) - gpuSelectionFilters(a)
+	})
+
+	used := make([]bool, len(devices.GPUs))
+	for _, gpu := range gpus {
+		found := false
+		for i, memberGPU := range devices.GPUs {
+			if (gpu.Type == "physical" && used[i]) || !gpu.matches(memberGPU) {
+				continue
+			}
+
+			if gpu.Type == "physical" {
+				used[i] = true
+			}
+
+			found = true
+			break
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// gpuSelectionFilters returns the number of filters set on the GPU selection.
+func gpuSelectionFilters(gpu GPURequirement) int {
+	count := 0
+	for _, filter := range []string{gpu.PCI, gpu.ID, gpu.VendorID, gpu.ProductID} {
+		if filter != "" {
+			count++
+		}
+	}
+
+	return count
+}
+
+// ResourceRequirements represents the storage pools and networks an instance needs on its cluster member.
+type ResourceRequirements struct {
+	StoragePools []string
+	Networks     []string
+}
+
+// GetResourceRequirements returns the storage pools and networks used by the given (expanded) instance devices.
+func GetResourceRequirements(devices deviceConfig.Devices) ResourceRequirements {
+	req := ResourceRequirements{}
+
+	for _, dev := range devices.Sorted() {
+		switch dev.Config["type"] {
+		case "disk":
+			pool := dev.Config["pool"]
+			if pool != "" && !slices.Contains(req.StoragePools, pool) {
+				req.StoragePools = append(req.StoragePools, pool)
+			}
+
+		case "nic":
+			network := dev.Config["network"]
+			if network != "" && !slices.Contains(req.Networks, network) {
+				req.Networks = append(req.Networks, network)
+			}
+		}
+	}
+
+	return req
+}
+
+// CheckMembersResources returns an error if any of the required storage pools or networks isn't available on
+// all of the given members. Networks are looked up in networkProjectName.
+func CheckMembersResources(ctx context.Context, tx *db.ClusterTx, networkProjectName string, members []db.NodeInfo, req ResourceRequirements) error {
+	for _, poolName := range req.StoragePools {
+		_, _, poolMembers, err := tx.GetStoragePoolInAnyState(ctx, poolName)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return api.StatusErrorf(http.StatusBadRequest, "Storage pool %q doesn't exist", poolName)
+			}
+
+			return fmt.Errorf("Failed loading storage pool %q: %w", poolName, err)
+		}
+
+		missing := []string{}
+		for _, member := range members {
+			poolMember, ok := poolMembers[member.ID]
+			if !ok || poolMember.State != db.StoragePoolCreated {
+				missing = append(missing, member.Name)
+			}
+		}
+
+		if len(missing) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "Storage pool %q isn't available on cluster members: %s", poolName, strings.Join(missing, ", "))
+		}
+	}
+
+	for _, networkName := range req.Networks {
+		networkID, err := tx.GetNetworkID(ctx, networkProjectName, networkName)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return api.StatusErrorf(http.StatusBadRequest, "Network %q doesn't exist", networkName)
+			}
+
+			return fmt.Errorf("Failed loading network %q: %w", networkName, err)
+		}
+
+		networkMembers, err := tx.NetworkNodes(ctx, networkID)
+		if err != nil {
+			return fmt.Errorf("Failed loading network %q members: %w", networkName, err)
+		}
+
+		missing := []string{}
+		for _, member := range members {
+			networkMember, ok := networkMembers[member.ID]
+			if !ok || db.NetworkStateToAPIStatus(networkMember.State) != api.NetworkStatusCreated {
+				missing = append(missing, member.Name)
+			}
+		}
+
+		if len(missing) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "Network %q isn't available on cluster members: %s", networkName, strings.Join(missing, ", "))
+		}
+	}
+
+	return nil
+}
+
+// FilterMembersByDevices removes the candidate members which can't satisfy the device requirements.
+// Members whose device availability can't be retrieved are kept but moved to the end of the list.
+// The order of the remaining candidates is otherwise preserved.
+func FilterMembersByDevices(s *state.State, members []db.NodeInfo, req DeviceRequirements) ([]db.NodeInfo, error) {
+	if req.IsEmpty() {
+		return members, nil
+	}
+
+	candidates := make([]db.NodeInfo, 0, len(members))
+	unknown := []db.NodeInfo{}
+
+	for _, member := range members {
+		devices, err := memberDevices(s, member)
+		if err != nil {
+			logger.Warn("Failed getting cluster member device availability", logger.Ctx{"member": member.Name, "err": err})
+			unknown = append(unknown, member)
+			continue
+		}
+
+		// Members without the extension can't report their devices.
+		if devices == nil {
+			unknown = append(unknown, member)
+			continue
+		}
+
+		if !req.SatisfiedBy(devices) {
+			logger.Debug("Skipping cluster member lacking required devices", logger.Ctx{"member": member.Name})
+			continue
+		}
+
+		candidates = append(candidates, member)
+	}
+
+	candidates = append(candidates, unknown...)
+
+	if len(candidates) == 0 {
+		return nil, api.StatusErrorf(503, "No cluster member has the required devices available")
+	}
+
+	return candidates, nil
+}
+
+// memberDevices returns the device availability of a cluster member.
+func memberDevices(s *state.State, member db.NodeInfo) (*api.ClusterMemberDevices, error) {
+	if member.Name == s.ServerName {
+		return resources.GetDevices()
+	}
+
+	client, err := Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to member: %w", err)
+	}
+
+	memberState, _, err := client.GetClusterMemberState(member.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return memberState.Devices, nil
+}
@@ -0,0 +1,108 @@
+package cluster_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/internal/server/cluster"
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestDeviceRequirements(t *testing.T) {
+	devices := deviceConfig.Devices{
+		"root": {"type": "disk", "path": "/", "pool": "default"},
+		"gpu0": {"type": "gpu"},
+		"gpu1": {"type": "gpu", "pci": "01:00.0"},
+		"ib0":  {"type": "infiniband", "nictype": "sriov", "parent": "ib0"},
+		"ib1":  {"type": "infiniband", "nictype": "physical", "parent": "ib1"},
+		"pci0": {"type": "pci", "address": "0000:02:00.0"},
+	}
+
+	req := cluster.GetDeviceRequirements(devices)
+	assert.ElementsMatch(t, []cluster.GPURequirement{{Type: "physical"}, {Type: "physical", PCI: "0000:01:00.0"}}, req.GPUs)
+	assert.Equal(t, uint64(1), req.InfinibandVFs)
+	assert.Equal(t, []string{"0000:02:00.0"}, req.PCIAddresses)
+	assert.False(t, req.IsEmpty())
+
+	assert.True(t, cluster.GetDeviceRequirements(deviceConfig.Devices{"root": devices["root"]}).IsEmpty())
+
+	// Members not reporting their GPUs are checked on the number of GPUs only.
+	available := &api.ClusterMemberDevices{
+		GPUFree:          2,
+		InfinibandVFFree: 1,
+		PCIFree:          []string{"0000:02:00.0"},
+	}
+
+	assert.True(t, req.SatisfiedBy(available))
+
+	available.GPUFree = 1
+	assert.False(t, req.SatisfiedBy(available))
+
+	available.GPUFree = 4
+	available.InfinibandVFFree = 0
+	assert.False(t, req.SatisfiedBy(available))
+
+	available.InfinibandVFFree = 8
+	available.PCIFree = []string{"0000:03:00.0"}
+	assert.False(t, req.SatisfiedBy(available))
+}
+
+func TestDeviceRequirementsGPUs(t *testing.T) {
+	drmID := uint64(1)
+	available := &api.ClusterMemberDevices{
+		GPUFree: 2,
+		GPUs: []api.ClusterMemberGPU{
+			{PCIAddress: "0000:01:00.0", VendorID: "10de", ProductID: "1c82", Types: []string{"physical", "mig"}},
+			{PCIAddress: "0000:02:00.0", DRMID: &drmID, VendorID: "1002", ProductID: "67df", Types: []string{"physical", "mdev"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		devices deviceConfig.Devices
+		want    bool
+	}{
+		{"Any GPU", deviceConfig.Devices{"gpu0": {"type": "gpu"}}, true},
+		{"Matching PCI address", deviceConfig.Devices{"gpu0": {"type": "gpu", "pci": "02:00.0"}}, true},
+		{"Missing PCI address", deviceConfig.Devices{"gpu0": {"type": "gpu", "pci": "0000:03:00.0"}}, false},
+		{"Matching DRM ID", deviceConfig.Devices{"gpu0": {"type": "gpu", "id": "1"}}, true},
+		{"Missing DRM ID", deviceConfig.Devices{"gpu0": {"type": "gpu", "id": "0"}}, false},
+		{"CDI ID", deviceConfig.Devices{"gpu0": {"type": "gpu", "id": "nvidia.com/gpu=0"}}, true},
+		{"Matching vendor", deviceConfig.Devices{"gpu0": {"type": "gpu", "vendorid": "10de"}}, true},
+		{"Missing product", deviceConfig.Devices{"gpu0": {"type": "gpu", "vendorid": "10de", "productid": "67df"}}, false},
+		{"Matching type", deviceConfig.Devices{"gpu0": {"type": "gpu", "gputype": "mig", "vendorid": "10de"}}, true},
+		{"Missing type", deviceConfig.Devices{"gpu0": {"type": "gpu", "gputype": "sriov"}}, false},
+		{"Physical GPUs aren't shared", deviceConfig.Devices{"gpu0": {"type": "gpu", "vendorid": "10de"}, "gpu1": {"type": "gpu", "vendorid": "10de"}}, false},
+		{"Other GPU types are shared", deviceConfig.Devices{"gpu0": {"type": "gpu", "gputype": "mdev"}, "gpu1": {"type": "gpu", "gputype": "mdev"}}, true},
+		{"Specific GPU placed first", deviceConfig.Devices{"gpu0": {"type": "gpu"}, "gpu1": {"type": "gpu", "pci": "0000:01:00.0"}}, true},
+		{"Too many GPUs", deviceConfig.Devices{"gpu0": {"type": "gpu"}, "gpu1": {"type": "gpu"}, "gpu2": {"type": "gpu"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cluster.GetDeviceRequirements(tt.devices).SatisfiedBy(available))
+		})
+	}
+}
+
+func TestResourceRequirements(t *testing.T) {
+	devices := deviceConfig.Devices{
+		"root":  {"type": "disk", "path": "/", "pool": "default"},
+		"data":  {"type": "disk", "path": "/data", "pool": "remote", "source": "data"},
+		"logs":  {"type": "disk", "path": "/logs", "pool": "default", "source": "logs"},
+		"host":  {"type": "disk", "path": "/host", "source": "/srv"},
+		"eth0":  {"type": "nic", "network": "ovn0"},
+		"eth1":  {"type": "nic", "nictype": "bridged", "parent": "br0"},
+		"eth2":  {"type": "nic", "network": "ovn0"},
+		"gpu0":  {"type": "gpu"},
+		"proxy": {"type": "proxy", "listen": "tcp:0.0.0.0:80", "connect": "tcp:127.0.0.1:80"},
+	}
+
+	req := cluster.GetResourceRequirements(devices)
+	assert.ElementsMatch(t, []string{"default", "remote"}, req.StoragePools)
+	assert.Equal(t, []string{"ovn0"}, req.Networks)
+
+	assert.Empty(t, cluster.GetResourceRequirements(deviceConfig.Devices{"gpu0": devices["gpu0"]}))
+}
@@ -25,6 +25,7 @@ type InstanceBackup struct {
 	InstanceOnly         bool
 	OptimizedStorage     bool
 	CompressionAlgorithm string
+	EncryptionPassphrase string
 }
 
 // StoragePoolVolumeBackup is a value object holding all db-related details about a storage volume backup.
@@ -37,6 +38,7 @@ type StoragePoolVolumeBackup struct {
 	VolumeOnly           bool
 	OptimizedStorage     bool
 	CompressionAlgorithm string
+	EncryptionPassphrase string
 }
 
 // StoragePoolBucketBackup is a value object holding all db-related details about a storage bucket backup.
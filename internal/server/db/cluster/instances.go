@@ -52,19 +52,20 @@ import (
 
 // Instance is a value object holding db-related details about an instance.
 type Instance struct {
-	ID           int
-	Project      string `db:"primary=yes&join=projects.name"`
-	Name         string `db:"primary=yes"`
-	Node         string `db:"join=nodes.name"`
-	Type         instancetype.Type
-	Snapshot     bool `db:"ignore"`
-	Architecture int
-	Ephemeral    bool
-	CreationDate time.Time
-	Stateful     bool
-	LastUseDate  sql.NullTime
-	Description  string `db:"coalesce=''"`
-	ExpiryDate   sql.NullTime
+	ID            int
+	Project       string `db:"primary=yes&join=projects.name"`
+	Name          string `db:"primary=yes"`
+	Node          string `db:"join=nodes.name"`
+	Type          instancetype.Type
+	Snapshot      bool `db:"ignore"`
+	Architecture  int
+	Ephemeral     bool
+	CreationDate  time.Time
+	Stateful      bool
+	LastUseDate   sql.NullTime
+	LastStartDate sql.NullTime
+	Description   string `db:"coalesce=''"`
+	ExpiryDate    sql.NullTime
 }
 
 // InstanceFilter specifies potential query parameter fields.
@@ -153,6 +154,7 @@ func (i *Instance) ToAPI(ctx context.Context, tx *sql.Tx, instanceDevices map[in
 		ExpandedDevices: expandedDevices.CloneNative(),
 		Name:            i.Name,
 		LastUsedAt:      i.LastUseDate.Time,
+		LastStartedAt:   i.LastStartDate.Time,
 		Location:        i.Node,
 		Type:            i.Type.String(),
 		Project:         i.Project,
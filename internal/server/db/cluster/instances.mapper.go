@@ -15,7 +15,7 @@ import (
 )
 
 var instanceObjects = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -23,7 +23,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByID = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -32,7 +32,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByProject = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -41,7 +41,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByProjectAndType = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -50,7 +50,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByProjectAndTypeAndNode = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -59,7 +59,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByProjectAndTypeAndNodeAndName = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -68,7 +68,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByProjectAndTypeAndName = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -77,7 +77,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByProjectAndName = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -86,7 +86,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByProjectAndNameAndNode = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -95,7 +95,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByProjectAndNode = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -104,7 +104,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByType = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -113,7 +113,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByTypeAndName = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -122,7 +122,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByTypeAndNameAndNode = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -131,7 +131,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByTypeAndNode = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -140,7 +140,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByNode = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -149,7 +149,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByNodeAndName = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -158,7 +158,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 `)
 
 var instanceObjectsByName = RegisterStmt(`
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances
   JOIN projects ON instances.project_id = projects.id
   JOIN nodes ON instances.node_id = nodes.id
@@ -173,8 +173,8 @@ SELECT instances.id FROM instances
 `)
 
 var instanceCreate = RegisterStmt(`
-INSERT INTO instances (project_id, name, node_id, type, architecture, ephemeral, creation_date, stateful, last_use_date, description, expiry_date)
-  VALUES ((SELECT projects.id FROM projects WHERE projects.name = ?), ?, (SELECT nodes.id FROM nodes WHERE nodes.name = ?), ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO instances (project_id, name, node_id, type, architecture, ephemeral, creation_date, stateful, last_use_date, last_start_date, description, expiry_date)
+  VALUES ((SELECT projects.id FROM projects WHERE projects.name = ?), ?, (SELECT nodes.id FROM nodes WHERE nodes.name = ?), ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `)
 
 var instanceRename = RegisterStmt(`
@@ -187,14 +187,14 @@ DELETE FROM instances WHERE project_id = (SELECT projects.id FROM projects WHERE
 
 var instanceUpdate = RegisterStmt(`
 UPDATE instances
-  SET project_id = (SELECT projects.id FROM projects WHERE projects.name = ?), name = ?, node_id = (SELECT nodes.id FROM nodes WHERE nodes.name = ?), type = ?, architecture = ?, ephemeral = ?, creation_date = ?, stateful = ?, last_use_date = ?, description = ?, expiry_date = ?
+  SET project_id = (SELECT projects.id FROM projects WHERE projects.name = ?), name = ?, node_id = (SELECT nodes.id FROM nodes WHERE nodes.name = ?), type = ?, architecture = ?, ephemeral = ?, creation_date = ?, stateful = ?, last_use_date = ?, last_start_date = ?, description = ?, expiry_date = ?
  WHERE id = ?
 `)
 
 // instanceColumns returns a string of column names to be used with a SELECT statement for the entity.
 // Use this function when building statements to retrieve database entries matching the Instance entity.
 func instanceColumns() string {
-	return "instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date"
+	return "instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date"
 }
 
 // getInstances can be used to run handwritten sql.Stmts to return a slice of objects.
@@ -203,7 +203,7 @@ func getInstances(ctx context.Context, stmt *sql.Stmt, args ...any) ([]Instance,
 
 	dest := func(scan func(dest ...any) error) error {
 		i := Instance{}
-		err := scan(&i.ID, &i.Project, &i.Name, &i.Node, &i.Type, &i.Architecture, &i.Ephemeral, &i.CreationDate, &i.Stateful, &i.LastUseDate, &i.Description, &i.ExpiryDate)
+		err := scan(&i.ID, &i.Project, &i.Name, &i.Node, &i.Type, &i.Architecture, &i.Ephemeral, &i.CreationDate, &i.Stateful, &i.LastUseDate, &i.LastStartDate, &i.Description, &i.ExpiryDate)
 		if err != nil {
 			return err
 		}
@@ -227,7 +227,7 @@ func getInstancesRaw(ctx context.Context, db dbtx, sql string, args ...any) ([]I
 
 	dest := func(scan func(dest ...any) error) error {
 		i := Instance{}
-		err := scan(&i.ID, &i.Project, &i.Name, &i.Node, &i.Type, &i.Architecture, &i.Ephemeral, &i.CreationDate, &i.Stateful, &i.LastUseDate, &i.Description, &i.ExpiryDate)
+		err := scan(&i.ID, &i.Project, &i.Name, &i.Node, &i.Type, &i.Architecture, &i.Ephemeral, &i.CreationDate, &i.Stateful, &i.LastUseDate, &i.LastStartDate, &i.Description, &i.ExpiryDate)
 		if err != nil {
 			return err
 		}
@@ -806,7 +806,7 @@ func CreateInstance(ctx context.Context, db dbtx, object Instance) (_ int64, _er
 		_err = mapErr(_err, "Instance")
 	}()
 
-	args := make([]any, 11)
+	args := make([]any, 12)
 
 	// Populate the statement arguments.
 	args[0] = object.Project
@@ -818,8 +818,9 @@ func CreateInstance(ctx context.Context, db dbtx, object Instance) (_ int64, _er
 	args[6] = object.CreationDate
 	args[7] = object.Stateful
 	args[8] = object.LastUseDate
-	args[9] = object.Description
-	args[10] = object.ExpiryDate
+	args[9] = object.LastStartDate
+	args[10] = object.Description
+	args[11] = object.ExpiryDate
 
 	// Prepared statement to use.
 	stmt, err := Stmt(db, instanceCreate)
@@ -970,7 +971,7 @@ func UpdateInstance(ctx context.Context, db tx, project string, name string, obj
 		return fmt.Errorf("Failed to get \"instanceUpdate\" prepared statement: %w", err)
 	}
 
-	result, err := stmt.Exec(object.Project, object.Name, object.Node, object.Type, object.Architecture, object.Ephemeral, object.CreationDate, object.Stateful, object.LastUseDate, object.Description, object.ExpiryDate, id)
+	result, err := stmt.Exec(object.Project, object.Name, object.Node, object.Type, object.Architecture, object.Ephemeral, object.CreationDate, object.Stateful, object.LastUseDate, object.LastStartDate, object.Description, object.ExpiryDate, id)
 	if err != nil {
 		return fmt.Errorf("Update \"instances\" entry failed: %w", err)
 	}
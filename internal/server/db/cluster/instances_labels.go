@@ -0,0 +1,285 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lxc/incus/v6/internal/server/db/query"
+)
+
+// Label selector operators.
+const (
+	LabelSelectorEquals    = "="
+	LabelSelectorNotEquals = "!="
+	LabelSelectorIn        = "in"
+	LabelSelectorNotIn     = "notin"
+	LabelSelectorExists    = "exists"
+	LabelSelectorNotExists = "!exists"
+)
+
+// LabelSelector is a single requirement of an instance label selector.
+type LabelSelector struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+var labelKeyRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._/-]{0,251}[a-zA-Z0-9])?$`)
+
+var labelValueRegexp = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9._-]{0,251}[a-zA-Z0-9])?)?$`)
+
+// ValidateLabel validates an instance label key and value.
+func ValidateLabel(key string, value string) error {
+	if !labelKeyRegexp.MatchString(key) {
+		return fmt.Errorf("Invalid label key %q", key)
+	}
+
+	if !labelValueRegexp.MatchString(value) {
+		return fmt.Errorf("Invalid value %q for label %q", value, key)
+	}
+
+	return nil
+}
+
+// ParseLabelSelectors parses a comma separated list of label selectors.
+//
+// Supported requirements are:
+//   - key=value (or key==value)
+//   - key!=value
+//   - key in (value1,value2)
+//   - key notin (value1,value2)
+//   - key
+//   - !key
+func ParseLabelSelectors(value string) ([]LabelSelector, error) {
+	selectors := []LabelSelector{}
+
+	// Split on commas that aren't within a value set.
+	var parts []string
+	depth := 0
+	start := 0
+	for i, c := range value {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, value[start:i])
+				start = i + 1
+			}
+		}
+
+		if depth < 0 || depth > 1 {
+			return nil, fmt.Errorf("Unbalanced parentheses in label selector %q", value)
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("Unbalanced parentheses in label selector %q", value)
+	}
+
+	parts = append(parts, value[start:])
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+
+			return nil, fmt.Errorf("Empty requirement in label selector %q", value)
+		}
+
+		selector, err := parseLabelSelector(part)
+		if err != nil {
+			return nil, err
+		}
+
+		selectors = append(selectors, *selector)
+	}
+
+	return selectors, nil
+}
+
+// parseLabelSelector parses a single label selector requirement.
+func parseLabelSelector(part string) (*LabelSelector, error) {
+	selector := LabelSelector{}
+
+	fields := strings.Fields(part)
+	if len(fields) >= 2 && (fields[1] == LabelSelectorIn || fields[1] == LabelSelectorNotIn) {
+		selector.Key = fields[0]
+		selector.Operator = fields[1]
+
+		set := strings.TrimSpace(strings.Join(fields[2:], " "))
+		if !strings.HasPrefix(set, "(") || !strings.HasSuffix(set, ")") {
+			return nil, fmt.Errorf("Invalid value set in label selector %q", part)
+		}
+
+		for _, v := range strings.Split(set[1:len(set)-1], ",") {
+			selector.Values = append(selector.Values, strings.TrimSpace(v))
+		}
+	} else if key, v, found := strings.Cut(part, "!="); found {
+		selector.Key = strings.TrimSpace(key)
+		selector.Operator = LabelSelectorNotEquals
+		selector.Values = []string{strings.TrimSpace(v)}
+	} else if key, v, found := strings.Cut(part, "=="); found {
+		selector.Key = strings.TrimSpace(key)
+		selector.Operator = LabelSelectorEquals
+		selector.Values = []string{strings.TrimSpace(v)}
+	} else if key, v, found := strings.Cut(part, "="); found {
+		selector.Key = strings.TrimSpace(key)
+		selector.Operator = LabelSelectorEquals
+		selector.Values = []string{strings.TrimSpace(v)}
+	} else if strings.HasPrefix(part, "!") {
+		selector.Key = strings.TrimSpace(part[1:])
+		selector.Operator = LabelSelectorNotExists
+	} else {
+		selector.Key = part
+		selector.Operator = LabelSelectorExists
+	}
+
+	for _, v := range append([]string{""}, selector.Values...) {
+		err := ValidateLabel(selector.Key, v)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid label selector %q: %w", part, err)
+		}
+	}
+
+	return &selector, nil
+}
+
+// GetInstanceLabels returns the labels of the instance with the given ID.
+func GetInstanceLabels(ctx context.Context, tx *sql.Tx, instanceID int) (map[string]string, error) {
+	labels := map[string]string{}
+
+	q := `SELECT key, value FROM instances_labels WHERE instance_id=?`
+	err := query.Scan(ctx, tx, q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		labels[key] = value
+
+		return nil
+	}, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+// GetAllInstanceLabels returns the labels of all instances, indexed by instance ID.
+func GetAllInstanceLabels(ctx context.Context, tx *sql.Tx) (map[int]map[string]string, error) {
+	labels := map[int]map[string]string{}
+
+	q := `SELECT instance_id, key, value FROM instances_labels`
+	err := query.Scan(ctx, tx, q, func(scan func(dest ...any) error) error {
+		var instanceID int
+		var key, value string
+
+		err := scan(&instanceID, &key, &value)
+		if err != nil {
+			return err
+		}
+
+		if labels[instanceID] == nil {
+			labels[instanceID] = map[string]string{}
+		}
+
+		labels[instanceID][key] = value
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+// UpdateInstanceLabels replaces the labels of the instance with the given ID.
+func UpdateInstanceLabels(ctx context.Context, tx *sql.Tx, instanceID int, labels map[string]string) error {
+	for key, value := range labels {
+		err := ValidateLabel(key, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM instances_labels WHERE instance_id=?`, instanceID)
+	if err != nil {
+		return fmt.Errorf("Failed deleting instance labels: %w", err)
+	}
+
+	for key, value := range labels {
+		_, err = tx.ExecContext(ctx, `INSERT INTO instances_labels (instance_id, key, value) VALUES (?, ?, ?)`, instanceID, key, value)
+		if err != nil {
+			return fmt.Errorf("Failed inserting instance label %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// GetInstanceIDsMatchingLabels returns the IDs of the instances matching all the label selectors.
+func GetInstanceIDsMatchingLabels(ctx context.Context, tx *sql.Tx, selectors []LabelSelector) (map[int]bool, error) {
+	var q strings.Builder
+	args := []any{}
+
+	q.WriteString(`SELECT id FROM instances`)
+
+	for i, selector := range selectors {
+		if i == 0 {
+			q.WriteString(" WHERE ")
+		} else {
+			q.WriteString(" AND ")
+		}
+
+		membership := "IN"
+		switch selector.Operator {
+		case LabelSelectorNotEquals, LabelSelectorNotIn, LabelSelectorNotExists:
+			membership = "NOT IN"
+		}
+
+		fmt.Fprintf(&q, "id %s (SELECT instance_id FROM instances_labels WHERE key = ?", membership)
+		args = append(args, selector.Key)
+
+		if len(selector.Values) > 0 {
+			q.WriteString(" AND value IN " + query.Params(len(selector.Values)))
+			for _, value := range selector.Values {
+				args = append(args, value)
+			}
+		}
+
+		q.WriteString(")")
+	}
+
+	ids := map[int]bool{}
+	err := query.Scan(ctx, tx, q.String(), func(scan func(dest ...any) error) error {
+		var id int
+
+		err := scan(&id)
+		if err != nil {
+			return err
+		}
+
+		ids[id] = true
+
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed filtering instances by labels: %w", err)
+	}
+
+	return ids, nil
+}
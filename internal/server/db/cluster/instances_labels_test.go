@@ -0,0 +1,136 @@
+//go:build linux && cgo && !agent
+
+package cluster_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/query"
+)
+
+func TestParseLabelSelectors(t *testing.T) {
+	selectors, err := cluster.ParseLabelSelectors("env=prod, tier != web,role in (db, cache),team notin (a),backup,!legacy")
+	require.NoError(t, err)
+
+	assert.Equal(t, []cluster.LabelSelector{
+		{Key: "env", Operator: cluster.LabelSelectorEquals, Values: []string{"prod"}},
+		{Key: "tier", Operator: cluster.LabelSelectorNotEquals, Values: []string{"web"}},
+		{Key: "role", Operator: cluster.LabelSelectorIn, Values: []string{"db", "cache"}},
+		{Key: "team", Operator: cluster.LabelSelectorNotIn, Values: []string{"a"}},
+		{Key: "backup", Operator: cluster.LabelSelectorExists},
+		{Key: "legacy", Operator: cluster.LabelSelectorNotExists},
+	}, selectors)
+
+	selectors, err = cluster.ParseLabelSelectors("env==prod")
+	require.NoError(t, err)
+	assert.Equal(t, []cluster.LabelSelector{{Key: "env", Operator: cluster.LabelSelectorEquals, Values: []string{"prod"}}}, selectors)
+
+	invalid := []string{
+		"env=prod,",
+		"role in (db",
+		"role in db",
+		"role in (db))",
+		"env=pr od",
+		"-env=prod",
+		"env=prod'; DROP TABLE instances; --",
+	}
+
+	for _, value := range invalid {
+		_, err := cluster.ParseLabelSelectors(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestGetInstanceIDsMatchingLabels(t *testing.T) {
+	schema := cluster.Schema()
+	db, err := schema.ExerciseUpdate(77, nil)
+	require.NoError(t, err)
+
+	_, err = db.Exec("INSERT INTO nodes (id, name, description, address, schema, api_extensions, arch) VALUES (1, 'none', '', '0.0.0.0', 1, 1, 1)")
+	require.NoError(t, err)
+
+	for i, name := range []string{"c1", "c2", "c3"} {
+		_, err = db.Exec("INSERT INTO instances (id, node_id, name, architecture, type, description, project_id) VALUES (?, 1, ?, 1, 0, '', 1)", i+1, name)
+		require.NoError(t, err)
+	}
+
+	ctx := context.Background()
+	labels := map[int]map[string]string{
+		1: {"env": "prod", "role": "db"},
+		2: {"env": "prod", "role": "web"},
+		3: {"env": "dev"},
+	}
+
+	err = query.Transaction(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+		for id, instanceLabels := range labels {
+			err := cluster.UpdateInstanceLabels(ctx, tx, id, instanceLabels)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	tests := map[string]map[int]bool{
+		"":                   {1: true, 2: true, 3: true},
+		"env=prod":           {1: true, 2: true},
+		"env=prod,role!=db":  {2: true},
+		"role in (db,web)":   {1: true, 2: true},
+		"env notin (prod)":   {3: true},
+		"role":               {1: true, 2: true},
+		"!role":              {3: true},
+		"env=prod,role=none": {},
+	}
+
+	for selector, expected := range tests {
+		selectors, err := cluster.ParseLabelSelectors(selector)
+		require.NoError(t, err)
+
+		err = query.Transaction(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+			ids, err := cluster.GetInstanceIDsMatchingLabels(ctx, tx, selectors)
+			if err != nil {
+				return err
+			}
+
+			assert.Equal(t, expected, ids, selector)
+
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	// Replacing the labels drops the old ones.
+	err = query.Transaction(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+		err := cluster.UpdateInstanceLabels(ctx, tx, 1, map[string]string{"env": "dev"})
+		if err != nil {
+			return err
+		}
+
+		current, err := cluster.GetInstanceLabels(ctx, tx, 1)
+		if err != nil {
+			return err
+		}
+
+		assert.Equal(t, map[string]string{"env": "dev"}, current)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Deleting the instance drops its labels.
+	_, err = db.Exec("DELETE FROM instances WHERE id = 2")
+	require.NoError(t, err)
+
+	var count int
+	err = db.QueryRow("SELECT count(*) FROM instances_labels WHERE instance_id = 2").Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
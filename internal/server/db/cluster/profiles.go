@@ -6,6 +6,7 @@ import (
 	"context"
 	"database/sql"
 
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/shared/api"
 )
@@ -123,24 +124,7 @@ func GetProfilesIfEnabled(ctx context.Context, tx *sql.Tx, projectName string, n
 // ExpandInstanceConfig expands the given instance config with the config
 // values of the given profiles.
 func ExpandInstanceConfig(config map[string]string, profiles []api.Profile) map[string]string {
-	expandedConfig := map[string]string{}
-
-	// Apply all the profiles
-	profileConfigs := make([]map[string]string, len(profiles))
-	for i, profile := range profiles {
-		profileConfigs[i] = profile.Config
-	}
-
-	for i := range profileConfigs {
-		for k, v := range profileConfigs[i] {
-			expandedConfig[k] = v
-		}
-	}
-
-	// Stick the given config on top
-	for k, v := range config {
-		expandedConfig[k] = v
-	}
+	expandedConfig, _ := internalInstance.ExpandConfig(config, profiles)
 
 	return expandedConfig
 }
@@ -148,26 +132,9 @@ func ExpandInstanceConfig(config map[string]string, profiles []api.Profile) map[
 // ExpandInstanceDevices expands the given instance devices with the devices
 // defined in the given profiles.
 func ExpandInstanceDevices(devices config.Devices, profiles []api.Profile) config.Devices {
-	expandedDevices := config.Devices{}
-
-	// Apply all the profiles
-	profileDevices := make([]config.Devices, len(profiles))
-	for i, profile := range profiles {
-		profileDevices[i] = config.NewDevices(profile.Devices)
-	}
-
-	for i := range profileDevices {
-		for k, v := range profileDevices[i] {
-			expandedDevices[k] = v
-		}
-	}
-
-	// Stick the given devices on top
-	for k, v := range devices {
-		expandedDevices[k] = v
-	}
+	expandedDevices, _ := internalInstance.ExpandDevices(devices.CloneNative(), profiles)
 
-	return expandedDevices
+	return config.NewDevices(expandedDevices)
 }
 
 // GetAllProfileConfigs returns a map of all profile configurations, keyed by database ID.
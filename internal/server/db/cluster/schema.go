@@ -118,6 +118,7 @@ CREATE TABLE "instances" (
     description TEXT NOT NULL,
     project_id INTEGER NOT NULL,
     expiry_date DATETIME,
+    last_start_date DATETIME,
     UNIQUE (project_id, name),
     FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
     FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
@@ -157,6 +158,16 @@ CREATE TABLE "instances_devices_config" (
     FOREIGN KEY (instance_device_id) REFERENCES "instances_devices" (id) ON DELETE CASCADE,
     UNIQUE (instance_device_id, key)
 );
+CREATE TABLE "instances_labels" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE,
+    UNIQUE (instance_id, key)
+);
+CREATE INDEX instances_labels_key_value_idx ON instances_labels (key,
+    value);
 CREATE INDEX instances_node_id_idx ON instances (node_id);
 CREATE TABLE "instances_profiles" (
     id INTEGER primary key AUTOINCREMENT NOT NULL,
@@ -266,6 +277,17 @@ CREATE TABLE "networks_config" (
     FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
     FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
 );
+CREATE TABLE "networks_dhcp_reservations" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_id INTEGER NOT NULL,
+    hwaddr TEXT NOT NULL,
+    ipv4_address TEXT NOT NULL,
+    ipv6_address TEXT NOT NULL,
+    hostname TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (network_id, hwaddr),
+    FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE
+);
 CREATE TABLE "networks_forwards" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     network_id INTEGER NOT NULL,
@@ -674,5 +696,5 @@ CREATE TABLE "warnings" (
 );
 CREATE UNIQUE INDEX warnings_unique_node_id_project_id_entity_type_code_entity_id_type_code ON warnings(IFNULL(node_id, -1), IFNULL(project_id, -1), entity_type_code, entity_id, type_code);
 
-INSERT INTO schema (version, updated_at) VALUES (76, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (79, strftime("%s"))
 `
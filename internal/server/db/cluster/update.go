@@ -113,6 +113,69 @@ var updates = map[int]schema.Update{
 	74: updateFromV73,
 	75: updateFromV74,
 	76: updateFromV75,
+	77: updateFromV76,
+	78: updateFromV77,
+	79: updateFromV78,
+}
+
+// updateFromV78 adds the networks_dhcp_reservations table.
+func updateFromV78(ctx context.Context, tx *sql.Tx) error {
+	q := `
+CREATE TABLE "networks_dhcp_reservations" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_id INTEGER NOT NULL,
+    hwaddr TEXT NOT NULL,
+    ipv4_address TEXT NOT NULL,
+    ipv6_address TEXT NOT NULL,
+    hostname TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (network_id, hwaddr),
+    FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE
+);
+`
+	_, err := tx.Exec(q)
+	if err != nil {
+		return fmt.Errorf("Failed adding networks_dhcp_reservations table: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV77 adds the last_start_date column to instances, initialized from the last use date which
+// was only updated on start until now.
+func updateFromV77(ctx context.Context, tx *sql.Tx) error {
+	q := `
+ALTER TABLE instances ADD COLUMN last_start_date DATETIME;
+UPDATE instances SET last_start_date = last_use_date;
+`
+	_, err := tx.Exec(q)
+	if err != nil {
+		return fmt.Errorf("Failed adding last_start_date column to instances: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV76 adds the instances_labels table.
+func updateFromV76(ctx context.Context, tx *sql.Tx) error {
+	q := `
+CREATE TABLE "instances_labels" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE,
+    UNIQUE (instance_id, key)
+);
+
+CREATE INDEX instances_labels_key_value_idx ON instances_labels (key, value);
+`
+	_, err := tx.Exec(q)
+	if err != nil {
+		return fmt.Errorf("Failed adding instances_labels table: %w", err)
+	}
+
+	return nil
 }
 
 func updateFromV75(ctx context.Context, tx *sql.Tx) error {
@@ -210,6 +210,47 @@ SELECT fingerprint
 	return fingerprints, nil
 }
 
+// GetImagesFingerprintsByProperty returns the fingerprints of the project's images having the given property,
+// keyed by the property value.
+func (c *ClusterTx) GetImagesFingerprintsByProperty(ctx context.Context, projectName string, key string) (map[string]string, error) {
+	q := `
+SELECT images_properties.value, images.fingerprint
+  FROM images
+  JOIN projects ON projects.id = images.project_id
+  JOIN images_properties ON images_properties.image_id = images.id
+ WHERE projects.name = ? AND images_properties.key = ?
+`
+
+	enabled, err := cluster.ProjectHasImages(ctx, c.tx, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("Check if project has images: %w", err)
+	}
+
+	if !enabled {
+		projectName = "default"
+	}
+
+	fingerprints := map[string]string{}
+	err = query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var value string
+		var fingerprint string
+
+		err := scan(&value, &fingerprint)
+		if err != nil {
+			return err
+		}
+
+		fingerprints[value] = fingerprint
+
+		return nil
+	}, projectName, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return fingerprints, nil
+}
+
 // CreateImageSource inserts a new image source.
 func (c *ClusterTx) CreateImageSource(ctx context.Context, id int, server string, protocol string, certificate string, alias string) error {
 	protocolInt := -1
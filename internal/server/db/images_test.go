@@ -106,3 +106,29 @@ func TestGetImage(t *testing.T) {
 		return nil
 	})
 }
+
+func TestGetImagesFingerprintsByProperty(t *testing.T) {
+	dbCluster, cleanup := db.NewTestCluster(t)
+	defer cleanup()
+
+	_ = dbCluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.CreateImage(ctx, "default", "abc", "x.gz", 16, false, false, "amd64", time.Now(), time.Now(), map[string]string{"build.cache_key": "key1", "os": "Debian"}, "container", nil)
+		require.NoError(t, err)
+
+		err = tx.CreateImage(ctx, "default", "def", "x.gz", 16, false, false, "amd64", time.Now(), time.Now(), map[string]string{"build.cache_key": "key2"}, "container", nil)
+		require.NoError(t, err)
+
+		err = tx.CreateImage(ctx, "default", "ghi", "x.gz", 16, false, false, "amd64", time.Now(), time.Now(), map[string]string{}, "container", nil)
+		require.NoError(t, err)
+
+		fingerprints, err := tx.GetImagesFingerprintsByProperty(ctx, "default", "build.cache_key")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"key1": "abc", "key2": "def"}, fingerprints)
+
+		fingerprints, err = tx.GetImagesFingerprintsByProperty(ctx, "default", "missing")
+		require.NoError(t, err)
+		assert.Empty(t, fingerprints)
+
+		return nil
+	})
+}
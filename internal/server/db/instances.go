@@ -34,16 +34,17 @@ type InstanceArgs struct {
 	BaseImage    string
 	CreationDate time.Time
 
-	Architecture int
-	Config       map[string]string
-	Description  string
-	Devices      deviceConfig.Devices
-	Ephemeral    bool
-	LastUsedDate time.Time
-	Name         string
-	Profiles     []api.Profile
-	Stateful     bool
-	ExpiryDate   time.Time
+	Architecture    int
+	Config          map[string]string
+	Description     string
+	Devices         deviceConfig.Devices
+	Ephemeral       bool
+	LastUsedDate    time.Time
+	LastStartedDate time.Time
+	Name            string
+	Profiles        []api.Profile
+	Stateful        bool
+	ExpiryDate      time.Time
 }
 
 // GetInstanceNames returns the names of all containers the given project.
@@ -56,6 +57,17 @@ SELECT instances.name FROM instances
 	return query.SelectStrings(ctx, c.tx, stmt, project)
 }
 
+// GetInstanceHWAddrs returns the MAC addresses used by the network devices of all instances, be they generated
+// or set in the instance devices.
+func (c *ClusterTx) GetInstanceHWAddrs(ctx context.Context) ([]string, error) {
+	stmt := `
+SELECT value FROM instances_config WHERE key LIKE 'volatile.%.hwaddr'
+UNION
+SELECT value FROM instances_devices_config WHERE key = 'hwaddr'
+`
+	return query.SelectStrings(ctx, c.tx, stmt)
+}
+
 // GetNodeAddressOfInstance returns the address of the node hosting the
 // instance with the given name in the given project.
 //
@@ -589,19 +601,20 @@ func (c *ClusterTx) InstancesToInstanceArgs(ctx context.Context, fillProfiles bo
 		}
 
 		args := InstanceArgs{
-			ID:           instance.ID,
-			Project:      instance.Project,
-			Name:         instance.Name,
-			Node:         instance.Node,
-			Type:         instance.Type,
-			Snapshot:     instance.Snapshot,
-			Architecture: instance.Architecture,
-			Ephemeral:    instance.Ephemeral,
-			CreationDate: instance.CreationDate,
-			Stateful:     instance.Stateful,
-			LastUsedDate: instance.LastUseDate.Time,
-			Description:  instance.Description,
-			ExpiryDate:   instance.ExpiryDate.Time,
+			ID:              instance.ID,
+			Project:         instance.Project,
+			Name:            instance.Name,
+			Node:            instance.Node,
+			Type:            instance.Type,
+			Snapshot:        instance.Snapshot,
+			Architecture:    instance.Architecture,
+			Ephemeral:       instance.Ephemeral,
+			CreationDate:    instance.CreationDate,
+			Stateful:        instance.Stateful,
+			LastUsedDate:    instance.LastUseDate.Time,
+			LastStartedDate: instance.LastStartDate.Time,
+			Description:     instance.Description,
+			ExpiryDate:      instance.ExpiryDate.Time,
 		}
 
 		instanceArgs[instance.ID] = args
@@ -794,6 +807,18 @@ func (c *ClusterTx) UpdateInstanceLastUsedDate(id int, date time.Time) error {
 	return nil
 }
 
+// UpdateInstanceLastStartedDate updates both the last_start_date and last_use_date fields of the
+// instance with the given ID.
+func (c *ClusterTx) UpdateInstanceLastStartedDate(id int, date time.Time) error {
+	str := `UPDATE instances SET last_start_date=?, last_use_date=? WHERE id=?`
+	_, err := c.tx.Exec(str, date, date, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetInstanceSnapshotsWithName returns all snapshots of a given instance in date created order, oldest first.
 func (c *ClusterTx) GetInstanceSnapshotsWithName(ctx context.Context, project string, name string) ([]cluster.Instance, error) {
 	instance, err := cluster.GetInstance(ctx, c.tx, project, name)
@@ -826,7 +851,7 @@ func (c *ClusterTx) GetInstanceSnapshotsWithName(ctx context.Context, project st
 // GetLocalInstanceWithVsockID returns all available instances with the given config key and value.
 func (c *ClusterTx) GetLocalInstanceWithVsockID(ctx context.Context, vsockID int) (*cluster.Instance, error) {
 	q := `
-SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, coalesce(instances.description, ''), instances.expiry_date
+SELECT instances.id, projects.name AS project, instances.name, nodes.name AS node, instances.type, instances.architecture, instances.ephemeral, instances.creation_date, instances.stateful, instances.last_use_date, instances.last_start_date, coalesce(instances.description, ''), instances.expiry_date
   FROM instances JOIN projects ON instances.project_id = projects.id JOIN nodes ON instances.node_id = nodes.id JOIN instances_config ON instances.id = instances_config.instance_id
   WHERE instances.node_id = ? AND instances.type = ? AND instances_config.key = "volatile.vsock_id" AND instances_config.value = ? LIMIT 1
   `
@@ -834,7 +859,7 @@ SELECT instances.id, projects.name AS project, instances.name, nodes.name AS nod
 	inargs := []any{c.nodeID, instancetype.VM, vsockID}
 	inst := cluster.Instance{}
 
-	err := c.tx.QueryRowContext(ctx, q, inargs...).Scan(&inst.ID, &inst.Project, &inst.Name, &inst.Node, &inst.Type, &inst.Architecture, &inst.Ephemeral, &inst.CreationDate, &inst.Stateful, &inst.LastUseDate, &inst.Description, &inst.ExpiryDate)
+	err := c.tx.QueryRowContext(ctx, q, inargs...).Scan(&inst.ID, &inst.Project, &inst.Name, &inst.Node, &inst.Type, &inst.Architecture, &inst.Ephemeral, &inst.CreationDate, &inst.Stateful, &inst.LastUseDate, &inst.LastStartDate, &inst.Description, &inst.ExpiryDate)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, api.StatusErrorf(http.StatusNotFound, "Instance not found")
@@ -0,0 +1,131 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/lxc/incus/v6/internal/server/db/query"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// CreateNetworkDHCPReservation creates a new Network DHCP reservation.
+func (c *ClusterTx) CreateNetworkDHCPReservation(ctx context.Context, networkID int64, info *api.NetworkDHCPReservationsPost) error {
+	_, err := c.tx.ExecContext(ctx, `
+		INSERT INTO networks_dhcp_reservations
+		(network_id, hwaddr, ipv4_address, ipv6_address, hostname, description)
+		VALUES (?, ?, ?, ?, ?, ?)
+		`, networkID, info.Hwaddr, info.IPv4Address, info.IPv6Address, info.Hostname, info.Description)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkDHCPReservation updates an existing Network DHCP reservation.
+func (c *ClusterTx) UpdateNetworkDHCPReservation(ctx context.Context, networkID int64, hwaddr string, info *api.NetworkDHCPReservationPut) error {
+	res, err := c.tx.ExecContext(ctx, `
+		UPDATE networks_dhcp_reservations
+		SET ipv4_address = ?, ipv6_address = ?, hostname = ?, description = ?
+		WHERE network_id = ? AND hwaddr = ?
+		`, info.IPv4Address, info.IPv6Address, info.Hostname, info.Description, networkID, hwaddr)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected <= 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Network DHCP reservation not found")
+	}
+
+	return nil
+}
+
+// DeleteNetworkDHCPReservation deletes an existing Network DHCP reservation.
+func (c *ClusterTx) DeleteNetworkDHCPReservation(ctx context.Context, networkID int64, hwaddr string) error {
+	res, err := c.tx.ExecContext(ctx, `
+		DELETE FROM networks_dhcp_reservations
+		WHERE network_id = ? AND hwaddr = ?
+		`, networkID, hwaddr)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected <= 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Network DHCP reservation not found")
+	}
+
+	return nil
+}
+
+// GetNetworkDHCPReservation returns the Network DHCP reservation for the given network ID and MAC address.
+func (c *ClusterTx) GetNetworkDHCPReservation(ctx context.Context, networkID int64, hwaddr string) (*api.NetworkDHCPReservation, error) {
+	reservations, err := c.GetNetworkDHCPReservations(ctx, networkID, hwaddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reservations) <= 0 {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Network DHCP reservation not found")
+	}
+
+	return &reservations[0], nil
+}
+
+// GetNetworkDHCPReservations returns the Network DHCP reservations for the given network ID.
+// If hwaddrs are specified, only the reservations for those MAC addresses are returned.
+func (c *ClusterTx) GetNetworkDHCPReservations(ctx context.Context, networkID int64, hwaddrs ...string) ([]api.NetworkDHCPReservation, error) {
+	q := &strings.Builder{}
+	args := []any{networkID}
+
+	q.WriteString(`
+	SELECT
+		hwaddr,
+		ipv4_address,
+		ipv6_address,
+		hostname,
+		description
+	FROM networks_dhcp_reservations
+	WHERE network_id = ?
+	`)
+
+	if len(hwaddrs) > 0 {
+		q.WriteString("AND hwaddr IN " + query.Params(len(hwaddrs)) + " ")
+		for _, hwaddr := range hwaddrs {
+			args = append(args, hwaddr)
+		}
+	}
+
+	q.WriteString("ORDER BY hwaddr")
+
+	reservations := []api.NetworkDHCPReservation{}
+	err := query.Scan(ctx, c.tx, q.String(), func(scan func(dest ...any) error) error {
+		reservation := api.NetworkDHCPReservation{}
+
+		err := scan(&reservation.Hwaddr, &reservation.IPv4Address, &reservation.IPv6Address, &reservation.Hostname, &reservation.Description)
+		if err != nil {
+			return err
+		}
+
+		reservations = append(reservations, reservation)
+
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
@@ -565,6 +565,7 @@ const (
 	NetworkTypeSriov                       // Network type sriov.
 	NetworkTypeOVN                         // Network type ovn.
 	NetworkTypePhysical                    // Network type physical.
+	NetworkTypeBond                        // Network type bond.
 )
 
 // NetworkNode represents a network node.
@@ -692,6 +693,8 @@ func networkFillType(network *api.Network, netType NetworkType) {
 		network.Type = "ovn"
 	case NetworkTypePhysical:
 		network.Type = "physical"
+	case NetworkTypeBond:
+		network.Type = "bond"
 	default:
 		network.Type = "" // Unknown
 	}
@@ -859,6 +862,7 @@ func (c *ClusterTx) RenameNetwork(ctx context.Context, project string, oldName s
 var NodeSpecificNetworkConfig = []string{
 	"bgp.ipv4.nexthop",
 	"bgp.ipv6.nexthop",
+	"bond.members",
 	"bridge.external_interfaces",
 	"parent",
 }
@@ -1146,6 +1146,75 @@ func (c *ClusterTx) GetCandidateMembers(ctx context.Context, allMembers []NodeIn
 	return candidateMembers, nil
 }
 
+// SpreadCandidateMembers reorders the candidate members to spread the instances of the given anti-affinity group
+// (set through the cluster.anti_affinity instance configuration key) across failure domains. Members in the failure
+// domains hosting the fewest instances of the group come first, then the ones hosting the fewest of them
+// themselves, otherwise the original order is kept. The instance with the skipInstance name isn't counted so that
+// it can be relocated.
+func (c *ClusterTx) SpreadCandidateMembers(ctx context.Context, candidateMembers []NodeInfo, projectName string, group string, skipInstance string) ([]NodeInfo, error) {
+	if group == "" || len(candidateMembers) < 2 {
+		return candidateMembers, nil
+	}
+
+	allMembers, err := c.GetNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting cluster members: %w", err)
+	}
+
+	addressDomains, err := c.GetNodesFailureDomains(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting failure domains: %w", err)
+	}
+
+	memberDomains := make(map[string]uint64, len(allMembers))
+	for _, member := range allMembers {
+		memberDomains[member.Name] = addressDomains[member.Address]
+	}
+
+	memberInstances := map[string]int{}
+	err = c.InstanceList(ctx, func(inst InstanceArgs, _ api.Project) error {
+		if inst.Name == skipInstance {
+			return nil
+		}
+
+		if ExpandInstanceConfig(inst.Config, inst.Profiles)["cluster.anti_affinity"] != group {
+			return nil
+		}
+
+		memberInstances[inst.Node]++
+
+		return nil
+	}, cluster.InstanceFilter{Project: &projectName})
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting instances of anti-affinity group %q: %w", group, err)
+	}
+
+	return SpreadMembers(candidateMembers, memberDomains, memberInstances), nil
+}
+
+// SpreadMembers returns the members sorted by the number of instances in their failure domain and then by their own
+// number of instances, keeping the original order for members which are equal on both counts. The failure domain IDs
+// and instance counts are indexed by member name.
+func SpreadMembers(members []NodeInfo, memberDomains map[string]uint64, memberInstances map[string]int) []NodeInfo {
+	domainInstances := map[uint64]int{}
+	for name, count := range memberInstances {
+		domainInstances[memberDomains[name]] += count
+	}
+
+	sorted := slices.Clone(members)
+	slices.SortStableFunc(sorted, func(a NodeInfo, b NodeInfo) int {
+		aDomain := domainInstances[memberDomains[a.Name]]
+		bDomain := domainInstances[memberDomains[b.Name]]
+		if aDomain != bDomain {
+			return aDomain - bDomain
+		}
+
+		return memberInstances[a.Name] - memberInstances[b.Name]
+	})
+
+	return sorted
+}
+
 // SetNodeVersion updates the schema and API version of the node with the
 // given id. This is used only in tests.
 func (c *ClusterTx) SetNodeVersion(id int64, version [2]int) error {
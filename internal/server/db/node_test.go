@@ -474,3 +474,90 @@ INSERT INTO instances (id, node_id, name, architecture, type, project_id, descri
 
 	assert.Equal(t, "buzz", members[0].Name)
 }
+
+// Candidate members in the failure domains hosting the fewest instances of an anti-affinity group come first.
+func TestSpreadCandidateMembers(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	buzz, err := tx.CreateNode("buzz", "1.2.3.4:666")
+	require.NoError(t, err)
+
+	rusp, err := tx.CreateNode("rusp", "5.6.7.8:666")
+	require.NoError(t, err)
+
+	kirk, err := tx.CreateNode("kirk", "9.10.11.12:666")
+	require.NoError(t, err)
+
+	require.NoError(t, tx.UpdateNodeFailureDomain(ctx, buzz, "az1"))
+	require.NoError(t, tx.UpdateNodeFailureDomain(ctx, rusp, "az2"))
+	require.NoError(t, tx.UpdateNodeFailureDomain(ctx, kirk, "az1"))
+
+	// Add an instance of the "db" group to buzz and an unrelated one to rusp.
+	_, err = tx.Tx().Exec(`
+INSERT INTO instances (id, node_id, name, architecture, type, project_id, description) VALUES (1, ?, 'db1', 1, 1, 1, '');
+INSERT INTO instances (id, node_id, name, architecture, type, project_id, description) VALUES (2, ?, 'web1', 1, 1, 1, '');
+INSERT INTO instances_config (instance_id, key, value) VALUES (1, 'cluster.anti_affinity', 'db');
+INSERT INTO instances_config (instance_id, key, value) VALUES (2, 'cluster.anti_affinity', 'web');
+`, buzz, rusp)
+	require.NoError(t, err)
+
+	candidates, err := tx.GetNodes(ctx)
+	require.NoError(t, err)
+
+	names := func(members []db.NodeInfo) []string {
+		result := make([]string, 0, len(members))
+		for _, member := range members {
+			result = append(result, member.Name)
+		}
+
+		return result
+	}
+
+	require.Equal(t, []string{"none", "buzz", "rusp", "kirk"}, names(candidates))
+
+	members, err := tx.SpreadCandidateMembers(ctx, candidates, "default", "db", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"none", "rusp", "kirk", "buzz"}, names(members))
+
+	// The instance being relocated isn't taken into account.
+	members, err = tx.SpreadCandidateMembers(ctx, candidates, "default", "db", "db1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"none", "buzz", "rusp", "kirk"}, names(members))
+
+	// Without anti-affinity group, the candidates are left alone.
+	members, err = tx.SpreadCandidateMembers(ctx, candidates, "default", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"none", "buzz", "rusp", "kirk"}, names(members))
+}
+
+func TestSpreadMembers(t *testing.T) {
+	members := []db.NodeInfo{{Name: "n1"}, {Name: "n2"}, {Name: "n3"}, {Name: "n4"}, {Name: "n5"}}
+	domains := map[string]uint64{"n1": 1, "n2": 1, "n3": 2, "n4": 2, "n5": 3}
+
+	names := func(members []db.NodeInfo) []string {
+		result := make([]string, 0, len(members))
+		for _, member := range members {
+			result = append(result, member.Name)
+		}
+
+		return result
+	}
+
+	// Empty failure domains first, then the ones with the fewest instances.
+	sorted := db.SpreadMembers(members, domains, map[string]int{"n1": 2, "n3": 1})
+	assert.Equal(t, []string{"n5", "n4", "n3", "n2", "n1"}, names(sorted))
+
+	// Within a failure domain, members with the fewest instances first.
+	sorted = db.SpreadMembers(members, domains, map[string]int{"n1": 1, "n3": 1, "n5": 1})
+	assert.Equal(t, []string{"n2", "n4", "n1", "n3", "n5"}, names(sorted))
+
+	// Instances on members which aren't candidates count towards their failure domain.
+	sorted = db.SpreadMembers(members[:4], map[string]uint64{"n1": 1, "n2": 1, "n3": 2, "n4": 2, "offline": 1}, map[string]int{"offline": 1})
+	assert.Equal(t, []string{"n3", "n4", "n1", "n2"}, names(sorted))
+
+	// The input isn't modified.
+	assert.Equal(t, []string{"n1", "n2", "n3", "n4", "n5"}, names(members))
+}
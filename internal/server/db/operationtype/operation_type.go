@@ -79,6 +79,15 @@ const (
 	BucketBackupRemove
 	BucketBackupRename
 	BucketBackupRestore
+	ProfileAssign
+	InstanceSyncClock
+	ImageBuild
+	InstanceHibernate
+	StoragePoolScrub
+	InstanceFileTransfer
+	VolumeConvert
+	StoragePoolMigrate
+	StoragePoolResize
 )
 
 // Description return a human-readable description of the operation type.
@@ -142,6 +151,8 @@ func (t Type) Description() string {
 		return "Deleting image"
 	case ImageToken:
 		return "Image download token"
+	case ImageBuild:
+		return "Building image"
 	case ImageRefresh:
 		return "Refreshing image"
 	case VolumeCopy:
@@ -210,6 +221,22 @@ func (t Type) Description() string {
 		return "Renaming bucket backup"
 	case BucketBackupRestore:
 		return "Restoring bucket backup"
+	case ProfileAssign:
+		return "Assigning profile to instances"
+	case InstanceSyncClock:
+		return "Synchronizing instance clock"
+	case InstanceHibernate:
+		return "Hibernating instance"
+	case StoragePoolScrub:
+		return "Scrubbing storage pool"
+	case InstanceFileTransfer:
+		return "Transferring instance file"
+	case VolumeConvert:
+		return "Converting storage volume"
+	case StoragePoolMigrate:
+		return "Migrating storage pool"
+	case StoragePoolResize:
+		return "Resizing storage pool"
 	default:
 		return "Executing operation"
 	}
@@ -238,6 +265,12 @@ func (t Type) Permission() (auth.ObjectType, auth.Entitlement) {
 		return auth.ObjectTypeInstance, auth.EntitlementCanUpdateState
 	case InstanceRestart:
 		return auth.ObjectTypeInstance, auth.EntitlementCanUpdateState
+	case InstanceSyncClock:
+		return auth.ObjectTypeInstance, auth.EntitlementCanUpdateState
+	case InstanceHibernate:
+		return auth.ObjectTypeInstance, auth.EntitlementCanUpdateState
+	case InstanceFileTransfer:
+		return auth.ObjectTypeInstance, auth.EntitlementCanAccessFiles
 	case CommandExec:
 		return auth.ObjectTypeInstance, auth.EntitlementCanExec
 	case SnapshotCreate:
@@ -253,6 +286,8 @@ func (t Type) Permission() (auth.ObjectType, auth.Entitlement) {
 
 	case InstanceCreate:
 		return auth.ObjectTypeProject, auth.EntitlementCanCreateInstances
+	case ImageBuild:
+		return auth.ObjectTypeProject, auth.EntitlementCanCreateImages
 	case InstanceUpdate:
 		return auth.ObjectTypeInstance, auth.EntitlementCanEdit
 	case InstanceRename:
@@ -291,6 +326,8 @@ func (t Type) Permission() (auth.ObjectType, auth.Entitlement) {
 		return auth.ObjectTypeStorageVolume, auth.EntitlementCanManageBackups
 	case CustomVolumeBackupRestore:
 		return auth.ObjectTypeStorageVolume, auth.EntitlementCanEdit
+	case VolumeConvert:
+		return auth.ObjectTypeStorageVolume, auth.EntitlementCanEdit
 
 	case BucketBackupCreate:
 		return auth.ObjectTypeStorageVolume, auth.EntitlementCanManageBackups
@@ -301,6 +338,16 @@ func (t Type) Permission() (auth.ObjectType, auth.Entitlement) {
 	case BucketBackupRestore:
 		return auth.ObjectTypeStorageVolume, auth.EntitlementCanEdit
 
+	case ProfileAssign:
+		return auth.ObjectTypeProfile, auth.EntitlementCanEdit
+
+	case StoragePoolScrub:
+		return auth.ObjectTypeStoragePool, auth.EntitlementCanEdit
+	case StoragePoolMigrate:
+		return auth.ObjectTypeStoragePool, auth.EntitlementCanEdit
+	case StoragePoolResize:
+		return auth.ObjectTypeStoragePool, auth.EntitlementCanEdit
+
 	default:
 		return "", ""
 	}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 
+	internalInstance "github.com/lxc/incus/v6/internal/instance"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/shared/api"
@@ -161,24 +162,7 @@ DELETE FROM profiles_devices_config WHERE profile_device_id NOT IN (SELECT id FR
 // ExpandInstanceConfig expands the given instance config with the config
 // values of the given profiles.
 func ExpandInstanceConfig(config map[string]string, profiles []api.Profile) map[string]string {
-	expandedConfig := map[string]string{}
-
-	// Apply all the profiles
-	profileConfigs := make([]map[string]string, len(profiles))
-	for i, profile := range profiles {
-		profileConfigs[i] = profile.Config
-	}
-
-	for i := range profileConfigs {
-		for k, v := range profileConfigs[i] {
-			expandedConfig[k] = v
-		}
-	}
-
-	// Stick the given config on top
-	for k, v := range config {
-		expandedConfig[k] = v
-	}
+	expandedConfig, _ := internalInstance.ExpandConfig(config, profiles)
 
 	return expandedConfig
 }
@@ -186,24 +170,7 @@ func ExpandInstanceConfig(config map[string]string, profiles []api.Profile) map[
 // ExpandInstanceDevices expands the given instance devices with the devices
 // defined in the given profiles.
 func ExpandInstanceDevices(devices deviceConfig.Devices, profiles []api.Profile) deviceConfig.Devices {
-	expandedDevices := deviceConfig.Devices{}
-
-	// Apply all the profiles
-	profileDevices := make([]deviceConfig.Devices, len(profiles))
-	for i, profile := range profiles {
-		profileDevices[i] = deviceConfig.NewDevices(profile.Devices)
-	}
-
-	for i := range profileDevices {
-		for k, v := range profileDevices[i] {
-			expandedDevices[k] = v
-		}
-	}
-
-	// Stick the given devices on top
-	for k, v := range devices {
-		expandedDevices[k] = v
-	}
+	expandedDevices, _ := internalInstance.ExpandDevices(devices.CloneNative(), profiles)
 
-	return expandedDevices
+	return deviceConfig.NewDevices(expandedDevices)
 }
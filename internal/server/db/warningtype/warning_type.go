@@ -56,6 +56,16 @@ const (
 	StoragePoolUnvailable
 	// UnableToUpdateClusterCertificate represents the unable to update cluster certificate warning.
 	UnableToUpdateClusterCertificate
+	// StorageVolumeSoftLimitExceeded represents a storage volume whose usage is above its soft limit.
+	StorageVolumeSoftLimitExceeded
+	// InstanceDeviceConflict represents an instance device conflicting with a device of another instance.
+	InstanceDeviceConflict
+	// InstanceAppArmorProfile represents a custom AppArmor profile likely to prevent an instance from working as expected.
+	InstanceAppArmorProfile
+	// NetworkConntrackLimitAboveHostMax represents a network connection tracking limit which can't be reached due to the host table size.
+	NetworkConntrackLimitAboveHostMax
+	// InfinibandBondFailover represents an infiniband virtual function left on a bond slave which is no longer the active one.
+	InfinibandBondFailover
 )
 
 // TypeNames associates a warning code to its name.
@@ -84,6 +94,11 @@ var TypeNames = map[Type]string{
 	InstanceTypeNotOperational:        "Instance type not operational",
 	StoragePoolUnvailable:             "Storage pool unavailable",
 	UnableToUpdateClusterCertificate:  "Unable to update cluster certificate",
+	StorageVolumeSoftLimitExceeded:    "Storage volume usage above soft limit",
+	InstanceDeviceConflict:            "Instance device conflicts with another instance",
+	InstanceAppArmorProfile:           "Custom AppArmor profile may not work as expected",
+	NetworkConntrackLimitAboveHostMax: "Network connection tracking limit exceeds the host table size",
+	InfinibandBondFailover:            "Infiniband virtual function isn't on the active bond slave",
 }
 
 // Severity returns the severity of the warning type.
@@ -137,6 +152,16 @@ func (t Type) Severity() Severity {
 		return SeverityHigh
 	case UnableToUpdateClusterCertificate:
 		return SeverityLow
+	case StorageVolumeSoftLimitExceeded:
+		return SeverityModerate
+	case InstanceDeviceConflict:
+		return SeverityModerate
+	case InstanceAppArmorProfile:
+		return SeverityModerate
+	case NetworkConntrackLimitAboveHostMax:
+		return SeverityModerate
+	case InfinibandBondFailover:
+		return SeverityModerate
 	}
 
 	return SeverityLow
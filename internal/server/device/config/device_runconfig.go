@@ -31,6 +31,8 @@ type MountEntryItem struct {
 	OwnerShift string      // Ownership shifting mode, use constants MountOwnerShiftNone, MountOwnerShiftStatic or MountOwnerShiftDynamic.
 	Limits     *DiskLimits // Disk limits.
 	Size       int64       // Expected disk size in bytes.
+	NewMedium  bool        // Replace the medium of the existing removable drive instead of adding a drive.
+	Remount    bool        // Apply the mount options to the existing mount instead of adding a mount.
 }
 
 // RootFSEntryItem represents the root filesystem options for an Instance.
@@ -79,3 +81,13 @@ type NICConfig struct {
 	MACAddress string `json:"mac_address"`
 	MTU        uint32 `json:"mtu"`
 }
+
+// DNSConfigFile shared constant used to indicate where DNS config is stored.
+const DNSConfigFile = "dns.json"
+
+// DNSConfig contains DNS resolver configuration to be passed into a VM and applied by the agent.
+type DNSConfig struct {
+	Nameservers []string `json:"nameservers"`
+	Search      []string `json:"search"`
+	Hostname    string   `json:"hostname,omitempty"`
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/sys/unix"
@@ -21,6 +24,7 @@ import (
 	"github.com/lxc/incus/v6/shared/idmap"
 	"github.com/lxc/incus/v6/shared/revert"
 	"github.com/lxc/incus/v6/shared/subprocess"
+	"github.com/lxc/incus/v6/shared/units"
 	"github.com/lxc/incus/v6/shared/util"
 )
 
@@ -111,17 +115,227 @@ func IsBlockdev(path string) bool {
 	return false
 }
 
+// diskBlockDevSupportsDiscard returns whether the block device at the given path supports discard requests.
+func diskBlockDevSupportsDiscard(sysfsPath string, path string) (bool, error) {
+	stat := unix.Stat_t{}
+	err := unix.Stat(path, &stat)
+	if err != nil {
+		return false, err
+	}
+
+	if stat.Mode&unix.S_IFMT != unix.S_IFBLK {
+		return false, fmt.Errorf("%q isn't a block device", path)
+	}
+
+	return diskBlockDevNumberSupportsDiscard(sysfsPath, unix.Major(stat.Rdev), unix.Minor(stat.Rdev))
+}
+
+// diskBlockDevNumberSupportsDiscard returns whether the block device with the given number supports discard requests.
+func diskBlockDevNumberSupportsDiscard(sysfsPath string, major uint32, minor uint32) (bool, error) {
+	devPath, err := filepath.EvalSymlinks(filepath.Join(sysfsPath, "dev", "block", fmt.Sprintf("%d:%d", major, minor)))
+	if err != nil {
+		return false, err
+	}
+
+	// Partitions don't have their own request queue, use the one of the parent disk.
+	queuePath := filepath.Join(devPath, "queue")
+	if !util.PathExists(queuePath) {
+		queuePath = filepath.Join(filepath.Dir(devPath), "queue")
+	}
+
+	content, err := os.ReadFile(filepath.Join(queuePath, "discard_max_bytes"))
+	if err != nil {
+		return false, err
+	}
+
+	maxBytes, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return false, err
+	}
+
+	return maxBytes > 0, nil
+}
+
+// diskBlockDevQueuePath returns the sysfs request queue directory of the block device at the given path.
+// Only whole block devices which aren't used by other host devices are accepted as tuning the queue of
+// anything else would affect more than the instance using it.
+func diskBlockDevQueuePath(sysfsPath string, path string) (string, error) {
+	stat := unix.Stat_t{}
+	err := unix.Stat(path, &stat)
+	if err != nil {
+		return "", err
+	}
+
+	if stat.Mode&unix.S_IFMT != unix.S_IFBLK {
+		return "", fmt.Errorf("%q isn't a block device", path)
+	}
+
+	return diskBlockDevNumberQueuePath(sysfsPath, unix.Major(stat.Rdev), unix.Minor(stat.Rdev))
+}
+
+// diskBlockDevNumberQueuePath returns the sysfs request queue directory of the block device with the given number.
+func diskBlockDevNumberQueuePath(sysfsPath string, major uint32, minor uint32) (string, error) {
+	devPath, err := filepath.EvalSymlinks(filepath.Join(sysfsPath, "dev", "block", fmt.Sprintf("%d:%d", major, minor)))
+	if err != nil {
+		return "", err
+	}
+
+	// Partitions share the request queue of their disk.
+	queuePath := filepath.Join(devPath, "queue")
+	if !util.PathExists(queuePath) {
+		return "", fmt.Errorf("Block device %q is a partition, its request queue is shared with the rest of the disk", filepath.Base(devPath))
+	}
+
+	// Devices stacked on top of this one (LVM, RAID, ...) are used by the host.
+	holders, err := os.ReadDir(filepath.Join(devPath, "holders"))
+	if err == nil && len(holders) > 0 {
+		return "", fmt.Errorf("Block device %q is in use by other host devices", filepath.Base(devPath))
+	}
+
+	return queuePath, nil
+}
+
+// diskQueueSchedulers returns the current and available I/O schedulers of a request queue.
+func diskQueueSchedulers(queuePath string) (string, []string, error) {
+	content, err := os.ReadFile(filepath.Join(queuePath, "scheduler"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The file looks like "mq-deadline kyber [bfq] none" with the current scheduler in brackets.
+	current := ""
+	available := []string{}
+	for _, field := range strings.Fields(string(content)) {
+		if strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]") {
+			field = strings.Trim(field, "[]")
+			current = field
+		}
+
+		available = append(available, field)
+	}
+
+	return current, available, nil
+}
+
+// diskQueueReadahead returns the read-ahead of a request queue in KiB.
+func diskQueueReadahead(queuePath string) (uint64, error) {
+	content, err := os.ReadFile(filepath.Join(queuePath, "read_ahead_kb"))
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// diskIOTuningMu serializes the changes to the I/O tuning of host block devices.
+var diskIOTuningMu sync.Mutex
+
+// diskIOTuningOwners maps the host request queues whose I/O tuning was changed to the disk device which changed it.
+var diskIOTuningOwners = map[string]string{}
+
+// diskIOTuningClaim records owner as the one tuning the request queue, failing if another owner already does.
+// The caller must hold diskIOTuningMu.
+func diskIOTuningClaim(queuePath string, owner string) error {
+	current, ok := diskIOTuningOwners[queuePath]
+	if ok && current != owner {
+		return fmt.Errorf("The I/O of the block device is already tuned by %s", current)
+	}
+
+	diskIOTuningOwners[queuePath] = owner
+
+	return nil
+}
+
+// diskIOTuningRelease forgets owner as the one tuning the request queue.
+// The caller must hold diskIOTuningMu.
+func diskIOTuningRelease(queuePath string, owner string) {
+	if diskIOTuningOwners[queuePath] == owner {
+		delete(diskIOTuningOwners, queuePath)
+	}
+}
+
+// diskBindMountOptions lists the mount options which can be applied to a bind-mounted storage volume.
+// Those only affect the mount point itself and can't be used to weaken the security of the host.
+var diskBindMountOptions = []string{
+	"async",
+	"dirsync",
+	"lazytime",
+	"noatime",
+	"nodev",
+	"nodiratime",
+	"noexec",
+	"nosuid",
+	"relatime",
+	"ro",
+	"rw",
+	"strictatime",
+	"sync",
+}
+
+// diskValidateBindMountOptions checks that all the provided mount options can be applied to a bind-mounted
+// storage volume.
+func diskValidateBindMountOptions(options []string) error {
+	for _, option := range options {
+		if !slices.Contains(diskBindMountOptions, option) {
+			return fmt.Errorf("Mount option %q isn't supported on storage volumes (allowed options are: %s), use the volume's \"block.mount_options\" for filesystem specific options", option, strings.Join(diskBindMountOptions, ", "))
+		}
+	}
+
+	return nil
+}
+
+// diskISOIdentifiers lists the identifiers found in the first volume descriptor of ISO 9660 and UDF images.
+var diskISOIdentifiers = []string{"CD001", "BEA01"}
+
+// diskCheckGrow checks that the new size of a disk isn't smaller than its old size.
+// An empty or zero old size means that the disk had no size limit set.
+func diskCheckGrow(oldSize string, newSize string) error {
+	oldBytes, err := units.ParseByteSizeString(oldSize)
+	if err != nil {
+		return err
+	}
+
+	newBytes, err := units.ParseByteSizeString(newSize)
+	if err != nil {
+		return err
+	}
+
+	if oldBytes > 0 && newBytes < oldBytes {
+		return fmt.Errorf("New size %q is smaller than the current size %q", newSize, oldSize)
+	}
+
+	return nil
+}
+
+// diskIsISO returns whether the file at the given path is an ISO 9660 or UDF image.
+func diskIsISO(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	// The volume descriptors start at the 17th sector of 2048 bytes, the identifier follows the descriptor type.
+	identifier := make([]byte, 5)
+	_, err = f.ReadAt(identifier, 16*2048+1)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return slices.Contains(diskISOIdentifiers, string(identifier)), nil
+}
+
 // DiskMount mounts a disk device.
 func DiskMount(srcPath string, dstPath string, recursive bool, propagation string, mountOptions []string, fsName string) error {
 	var err error
 
 	flags, mountOptionsStr := linux.ResolveMountOptions(mountOptions)
 
-	var readonly bool
-	if slices.Contains(mountOptions, "ro") {
-		readonly = true
-	}
-
 	// Detect the filesystem
 	if fsName == "none" {
 		flags |= unix.MS_BIND
@@ -160,12 +374,13 @@ func DiskMount(srcPath string, dstPath string, recursive bool, propagation strin
 		return fmt.Errorf("Unable to mount %q at %q with filesystem %q: %w", srcPath, dstPath, fsName, err)
 	}
 
-	// Remount bind mounts in readonly mode if requested
-	if readonly && flags&unix.MS_BIND == unix.MS_BIND {
-		flags = unix.MS_RDONLY | unix.MS_BIND | unix.MS_REMOUNT
+	// Per mount point flags are ignored when creating a bind mount, remount it to apply them.
+	mountFlags, _ := linux.ResolveMountOptions(mountOptions)
+	if mountFlags != 0 && flags&unix.MS_BIND == unix.MS_BIND {
+		flags = mountFlags | unix.MS_BIND | unix.MS_REMOUNT
 		err = unix.Mount("", dstPath, fsName, uintptr(flags), "")
 		if err != nil {
-			return fmt.Errorf("Unable to mount %q in readonly mode: %w", dstPath, err)
+			return fmt.Errorf("Unable to apply mount options to %q: %w", dstPath, err)
 		}
 	}
 
@@ -178,6 +393,18 @@ func DiskMount(srcPath string, dstPath string, recursive bool, propagation strin
 	return nil
 }
 
+// DiskMountRemount applies the per mount point options to an existing bind mount.
+func DiskMountRemount(path string, mountOptions []string) error {
+	flags, _ := linux.ResolveMountOptions(mountOptions)
+
+	err := unix.Mount("", path, "none", flags|unix.MS_BIND|unix.MS_REMOUNT, "")
+	if err != nil {
+		return fmt.Errorf("Unable to apply mount options to %q: %w", path, err)
+	}
+
+	return nil
+}
+
 // DiskMountClear unmounts and removes the mount path used for disk shares.
 func DiskMountClear(mntPath string) error {
 	if util.PathExists(mntPath) {
@@ -288,6 +515,19 @@ func diskCephfsOptions(clusterName string, userName string, fsName string, fsPat
 	return srcPath, fsOptions, nil
 }
 
+// diskVirtiofsdFallback returns whether a directory share using the given bus should fall back to 9p after
+// virtiofsd failed to start with err. This is only the case when virtiofsd isn't required and isn't supported by
+// the host or instance, for example because it's missing.
+func diskVirtiofsdFallback(busOption string, err error) bool {
+	if busOption == "virtiofs" {
+		return false
+	}
+
+	var errUnsupported UnsupportedError
+
+	return errors.As(err, &errUnsupported)
+}
+
 // DiskVMVirtiofsdStart starts a new virtiofsd process.
 // If the idmaps slice is supplied then the proxy process is run inside a user namespace using the supplied maps.
 // Returns UnsupportedError error if the host system or instance does not support virtiosfd, returns normal error
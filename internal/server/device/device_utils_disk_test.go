@@ -0,0 +1,232 @@
+package device
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskBlockDevNumberSupportsDiscard(t *testing.T) {
+	sysfsPath := t.TempDir()
+
+	writeFile := func(path string, content string) {
+		path = filepath.Join(sysfsPath, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	symlink := func(target string, path string) {
+		path = filepath.Join(sysfsPath, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.Symlink(filepath.Join(sysfsPath, target), path))
+	}
+
+	// A NVMe namespace with a partition and a disk without discard support.
+	writeFile("devices/nvme0n1/queue/discard_max_bytes", "2199023255040\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(sysfsPath, "devices/nvme0n1/nvme0n1p1"), 0o755))
+	writeFile("devices/sda/queue/discard_max_bytes", "0\n")
+
+	symlink("devices/nvme0n1", "dev/block/259:0")
+	symlink("devices/nvme0n1/nvme0n1p1", "dev/block/259:1")
+	symlink("devices/sda", "dev/block/8:0")
+
+	supported, err := diskBlockDevNumberSupportsDiscard(sysfsPath, 259, 0)
+	require.NoError(t, err)
+	assert.True(t, supported)
+
+	// Partitions use the request queue of their disk.
+	supported, err = diskBlockDevNumberSupportsDiscard(sysfsPath, 259, 1)
+	require.NoError(t, err)
+	assert.True(t, supported)
+
+	supported, err = diskBlockDevNumberSupportsDiscard(sysfsPath, 8, 0)
+	require.NoError(t, err)
+	assert.False(t, supported)
+
+	_, err = diskBlockDevNumberSupportsDiscard(sysfsPath, 8, 16)
+	assert.Error(t, err)
+}
+
+func TestDiskBlockDevNumberQueuePath(t *testing.T) {
+	sysfsPath := t.TempDir()
+
+	writeFile := func(path string, content string) {
+		path = filepath.Join(sysfsPath, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	symlink := func(target string, path string) {
+		path = filepath.Join(sysfsPath, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.Symlink(filepath.Join(sysfsPath, target), path))
+	}
+
+	// A dedicated disk, a partition and a disk used by LVM.
+	writeFile("devices/sdb/queue/scheduler", "mq-deadline kyber [bfq] none\n")
+	writeFile("devices/sdb/queue/read_ahead_kb", "128\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(sysfsPath, "devices/sdb/holders"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(sysfsPath, "devices/sdb/sdb1"), 0o755))
+	writeFile("devices/sdc/queue/scheduler", "[none] mq-deadline\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(sysfsPath, "devices/sdc/holders/dm-0"), 0o755))
+
+	symlink("devices/sdb", "dev/block/8:16")
+	symlink("devices/sdb/sdb1", "dev/block/8:17")
+	symlink("devices/sdc", "dev/block/8:32")
+
+	queuePath, err := diskBlockDevNumberQueuePath(sysfsPath, 8, 16)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(sysfsPath, "devices/sdb/queue"), queuePath)
+
+	current, available, err := diskQueueSchedulers(queuePath)
+	require.NoError(t, err)
+	assert.Equal(t, "bfq", current)
+	assert.Equal(t, []string{"mq-deadline", "kyber", "bfq", "none"}, available)
+
+	readahead, err := diskQueueReadahead(queuePath)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(128), readahead)
+
+	// Partitions share the queue of their disk.
+	_, err = diskBlockDevNumberQueuePath(sysfsPath, 8, 17)
+	assert.ErrorContains(t, err, "partition")
+
+	// Disks with holders are used by the host.
+	_, err = diskBlockDevNumberQueuePath(sysfsPath, 8, 32)
+	assert.ErrorContains(t, err, "in use")
+}
+
+func TestDiskValidateBindMountOptions(t *testing.T) {
+	assert.NoError(t, diskValidateBindMountOptions(nil))
+	assert.NoError(t, diskValidateBindMountOptions([]string{"ro", "noexec", "nosuid", "nodev", "noatime"}))
+
+	for _, option := range []string{"suid", "dev", "exec", "remount", "bind", "rbind", "mand", "discard", "uid=1000"} {
+		assert.Error(t, diskValidateBindMountOptions([]string{"noexec", option}), option)
+	}
+}
+
+func TestDiskIsISO(t *testing.T) {
+	dir := t.TempDir()
+
+	writeImage := func(name string, identifier string, size int) string {
+		content := make([]byte, size)
+		copy(content[16*2048+1:], identifier)
+
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, content, 0o644))
+
+		return path
+	}
+
+	for name, identifier := range map[string]string{"iso9660": "CD001", "udf": "BEA01"} {
+		isISO, err := diskIsISO(writeImage(name, identifier, 64*1024))
+		require.NoError(t, err)
+		assert.True(t, isISO, name)
+	}
+
+	isISO, err := diskIsISO(writeImage("raw", "", 64*1024))
+	require.NoError(t, err)
+	assert.False(t, isISO)
+
+	// Files too small to hold a volume descriptor aren't images.
+	small := filepath.Join(dir, "small")
+	require.NoError(t, os.WriteFile(small, []byte("CD001"), 0o644))
+	isISO, err = diskIsISO(small)
+	require.NoError(t, err)
+	assert.False(t, isISO)
+
+	_, err = diskIsISO(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestDiskCheckGrow(t *testing.T) {
+	// Growing or keeping the size.
+	assert.NoError(t, diskCheckGrow("10GiB", "20GiB"))
+	assert.NoError(t, diskCheckGrow("10GiB", "10240MiB"))
+
+	// Setting a limit on a disk that had none.
+	assert.NoError(t, diskCheckGrow("", "10GiB"))
+
+	// Shrinking.
+	assert.Error(t, diskCheckGrow("20GiB", "10GiB"))
+
+	// Invalid sizes.
+	assert.Error(t, diskCheckGrow("10GiB", "big"))
+	assert.Error(t, diskCheckGrow("big", "10GiB"))
+}
+
+func TestDiskIOTuningClaim(t *testing.T) {
+	queuePath := "/sys/devices/virtual/block/test/queue"
+	t.Cleanup(func() { delete(diskIOTuningOwners, queuePath) })
+
+	// Only one of the disks sharing the block device gets to tune it.
+	var wg sync.WaitGroup
+	var claimed atomic.Int32
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(owner string) {
+			defer wg.Done()
+
+			diskIOTuningMu.Lock()
+			defer diskIOTuningMu.Unlock()
+
+			if diskIOTuningClaim(queuePath, owner) == nil {
+				claimed.Add(1)
+			}
+		}(fmt.Sprintf("disk%d", i))
+	}
+
+	wg.Wait()
+	assert.Equal(t, int32(1), claimed.Load())
+
+	owner := diskIOTuningOwners[queuePath]
+
+	// Claiming again is allowed for the same disk only.
+	assert.NoError(t, diskIOTuningClaim(queuePath, owner))
+	assert.Error(t, diskIOTuningClaim(queuePath, "other"))
+
+	// Releasing by another disk keeps the claim.
+	diskIOTuningRelease(queuePath, "other")
+	assert.Error(t, diskIOTuningClaim(queuePath, "other"))
+
+	// Once released, another disk can tune the block device.
+	diskIOTuningRelease(queuePath, owner)
+	assert.NoError(t, diskIOTuningClaim(queuePath, "other"))
+}
+
+func TestDiskVirtiofsdFallback(t *testing.T) {
+	// A missing virtiofsd falls back to 9p unless virtio-fs is required.
+	assert.True(t, diskVirtiofsdFallback("auto", ErrMissingVirtiofsd))
+	assert.True(t, diskVirtiofsdFallback("", ErrMissingVirtiofsd))
+	assert.False(t, diskVirtiofsdFallback("virtiofs", ErrMissingVirtiofsd))
+
+	// So does any other lack of support.
+	assert.True(t, diskVirtiofsdFallback("auto", UnsupportedError{"SEV unsupported"}))
+	assert.False(t, diskVirtiofsdFallback("virtiofs", UnsupportedError{"SEV unsupported"}))
+
+	// Other failures are reported.
+	assert.False(t, diskVirtiofsdFallback("auto", fmt.Errorf("Failed to start virtiofsd")))
+}
+
+func TestDiskVMVirtiofsdStartMissing(t *testing.T) {
+	for _, path := range []string{"/usr/lib/qemu/virtiofsd", "/usr/libexec/virtiofsd", "/usr/lib/virtiofsd"} {
+		_, err := os.Stat(path)
+		if err == nil {
+			t.Skipf("virtiofsd is installed at %q", path)
+		}
+	}
+
+	t.Setenv("PATH", t.TempDir())
+
+	dir := t.TempDir()
+	_, _, err := DiskVMVirtiofsdStart("", nil, filepath.Join(dir, "virtiofsd.sock"), filepath.Join(dir, "virtiofsd.pid"), filepath.Join(dir, "virtiofsd.log"), dir, nil, "")
+	require.ErrorIs(t, err, ErrMissingVirtiofsd)
+	assert.True(t, diskVirtiofsdFallback("auto", err))
+}
@@ -1,8 +1,10 @@
 package device
 
 import (
+	"strconv"
 	"strings"
 
+	"github.com/lxc/incus/v6/internal/server/resources"
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
@@ -14,3 +16,30 @@ func gpuValidMigUUID(value string) error {
 
 	return validate.IsUUID(strings.TrimPrefix(value, "MIG-"))
 }
+
+// gpuInstanceNUMANodes returns the NUMA nodes the instance is restricted to, either through limits.cpu.nodes or
+// through its pinned CPUs. It returns nil if the instance isn't restricted to specific NUMA nodes.
+func gpuInstanceNUMANodes(instConfig map[string]string) ([]int64, error) {
+	numaNodes := instConfig["limits.cpu.nodes"]
+	if numaNodes == "balanced" {
+		numaNodes = instConfig["volatile.cpu.nodes"]
+	}
+
+	if numaNodes != "" {
+		return resources.ParseNumaNodeSet(numaNodes)
+	}
+
+	// A plain CPU count isn't pinned to any CPU.
+	limit := instConfig["limits.cpu"]
+	_, err := strconv.Atoi(limit)
+	if limit == "" || err == nil {
+		return nil, nil
+	}
+
+	cpus, err := resources.ParseCpuset(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return resources.GetNUMANodesForCPUs(cpus)
+}
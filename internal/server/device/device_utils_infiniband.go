@@ -1,9 +1,17 @@
 package device
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/state"
@@ -15,10 +23,27 @@ const IBDevPrefix = "infiniband.unix"
 
 // infinibandDevices extracts the infiniband parent device from the supplied nic list and any free
 // associated virtual functions (VFs) that are on the same card and port as the specified parent.
+// When the parent is a bond, its slaves must be supplied and the VFs of all of them are returned
+// without the slaves themselves.
 // This function expects that the supplied nic list does not include VFs that are already attached
 // to running instances.
-func infinibandDevices(nics *api.ResourcesNetwork, parent string) map[string]*api.ResourcesNetworkCardPort {
+func infinibandDevices(nics *api.ResourcesNetwork, parent string, slaves ...string) map[string]*api.ResourcesNetworkCardPort {
 	ibDevs := make(map[string]*api.ResourcesNetworkCardPort)
+
+	if len(slaves) > 0 {
+		for _, slave := range slaves {
+			for id, port := range infinibandDevices(nics, slave) {
+				// Skip the bond slaves themselves.
+				if id == slave {
+					continue
+				}
+
+				ibDevs[id] = port
+			}
+		}
+
+		return ibDevs
+	}
 	for _, card := range nics.Cards {
 		for _, port := range card.Ports {
 			// Skip non-infiniband ports.
@@ -40,8 +65,16 @@ func infinibandDevices(nics *api.ResourcesNetwork, parent string) map[string]*ap
 			continue
 		}
 
-		// Record if parent has been found as a physical function (PF).
-		parentDev, parentIsPF := ibDevs[parent]
+		// Record if parent has been found as a physical function (PF) of this card.
+		var parentDev *api.ResourcesNetworkCardPort
+		for i, port := range card.Ports {
+			if port.ID == parent && port.Protocol == "infiniband" {
+				parentDev = &card.Ports[i]
+				break
+			}
+		}
+
+		parentIsPF := parentDev != nil
 
 		for _, VF := range card.SRIOV.VFs {
 			for _, port := range VF.Ports {
@@ -69,6 +102,201 @@ func infinibandDevices(nics *api.ResourcesNetwork, parent string) map[string]*ap
 	return ibDevs
 }
 
+// infinibandBondSlaves returns the slaves of the bond interface, or nil if the interface isn't a bond.
+func infinibandBondSlaves(sysfsPath string, name string) ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(sysfsPath, "class", "net", name, "bonding", "slaves"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed getting slaves of bond %q: %w", name, err)
+	}
+
+	slaves := strings.Fields(string(content))
+	if len(slaves) == 0 {
+		return nil, fmt.Errorf("Bond %q doesn't have any slaves", name)
+	}
+
+	// Put the active slave first so it's preferred when allocating virtual functions.
+	activeSlave := infinibandBondActiveSlave(sysfsPath, name)
+	for i, slave := range slaves {
+		if slave == activeSlave {
+			slaves[0], slaves[i] = slaves[i], slaves[0]
+			break
+		}
+	}
+
+	return slaves, nil
+}
+
+// infinibandBondActiveSlave returns the active slave of the bond interface, or an empty string if there's none.
+func infinibandBondActiveSlave(sysfsPath string, name string) string {
+	content, err := os.ReadFile(filepath.Join(sysfsPath, "class", "net", name, "bonding", "active_slave"))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
+
+// infinibandBondInterval is how often the active slave of the bonds used as parents is checked.
+var infinibandBondInterval = 5 * time.Second
+
+// infinibandBondWatcher tracks the active slave of a bond on behalf of the devices using its virtual functions.
+type infinibandBondWatcher struct {
+	handlers map[string]func(activeSlave string)
+	stop     chan struct{}
+}
+
+// infinibandBondWatchers holds the watchers of the bonds in use, keyed by sysfs path and bond name.
+var infinibandBondWatchers = map[string]*infinibandBondWatcher{}
+
+var infinibandBondWatchersMu sync.Mutex
+
+// infinibandBondWatch calls the handler with the new active slave of the bond whenever it changes, until
+// infinibandBondUnwatch is called with the same key. The handler is called right away with the current one.
+func infinibandBondWatch(sysfsPath string, bond string, key string, handler func(activeSlave string)) {
+	infinibandBondWatchersMu.Lock()
+	defer infinibandBondWatchersMu.Unlock()
+
+	watchKey := filepath.Join(sysfsPath, bond)
+
+	activeSlave := infinibandBondActiveSlave(sysfsPath, bond)
+
+	w, ok := infinibandBondWatchers[watchKey]
+	if !ok {
+		w = &infinibandBondWatcher{
+			handlers: map[string]func(activeSlave string){},
+			stop:     make(chan struct{}),
+		}
+
+		infinibandBondWatchers[watchKey] = w
+
+		go w.run(sysfsPath, bond, activeSlave)
+	}
+
+	w.handlers[key] = handler
+
+	go handler(activeSlave)
+}
+
+// infinibandBondUnwatch stops calling the handler registered with the key, if any.
+func infinibandBondUnwatch(sysfsPath string, bond string, key string) {
+	infinibandBondWatchersMu.Lock()
+	defer infinibandBondWatchersMu.Unlock()
+
+	watchKey := filepath.Join(sysfsPath, bond)
+
+	w, ok := infinibandBondWatchers[watchKey]
+	if !ok {
+		return
+	}
+
+	delete(w.handlers, key)
+
+	if len(w.handlers) == 0 {
+		close(w.stop)
+		delete(infinibandBondWatchers, watchKey)
+	}
+}
+
+// run calls the handlers whenever the active slave of the bond changes until stopped.
+func (w *infinibandBondWatcher) run(sysfsPath string, bond string, activeSlave string) {
+	ticker := time.NewTicker(infinibandBondInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+
+		newActiveSlave := infinibandBondActiveSlave(sysfsPath, bond)
+		if newActiveSlave == activeSlave {
+			continue
+		}
+
+		activeSlave = newActiveSlave
+
+		infinibandBondWatchersMu.Lock()
+		handlers := make([]func(activeSlave string), 0, len(w.handlers))
+		for _, handler := range w.handlers {
+			handlers = append(handlers, handler)
+		}
+
+		infinibandBondWatchersMu.Unlock()
+
+		for _, handler := range handlers {
+			handler(activeSlave)
+		}
+	}
+}
+
+// infinibandBondCards returns the cards backing each slave of an infiniband bond.
+// All slaves must be infiniband ports of SR-IOV capable cards using the same driver so that the
+// virtual functions of any of them can be used interchangeably.
+func infinibandBondCards(nics *api.ResourcesNetwork, bond string, slaves []string) (map[string]*api.ResourcesNetworkCard, error) {
+	cards := make(map[string]*api.ResourcesNetworkCard, len(slaves))
+
+	for i := range nics.Cards {
+		card := &nics.Cards[i]
+
+		for _, port := range card.Ports {
+			if !slices.Contains(slaves, port.ID) {
+				continue
+			}
+
+			if port.Protocol != "infiniband" {
+				return nil, fmt.Errorf("Bond %q slave %q isn't an infiniband port", bond, port.ID)
+			}
+
+			if card.SRIOV == nil {
+				return nil, fmt.Errorf("Bond %q slave %q isn't SR-IOV capable", bond, port.ID)
+			}
+
+			cards[port.ID] = card
+		}
+	}
+
+	for _, slave := range slaves {
+		card, found := cards[slave]
+		if !found {
+			return nil, fmt.Errorf("Bond %q slave %q isn't an infiniband physical function", bond, slave)
+		}
+
+		if card.Driver != cards[slaves[0]].Driver {
+			return nil, fmt.Errorf("Bond %q slaves %q and %q use different drivers", bond, slaves[0], slave)
+		}
+	}
+
+	return cards, nil
+}
+
+// infinibandVFParent returns the bond slave owning the infiniband virtual function port.
+func infinibandVFParent(cards map[string]*api.ResourcesNetworkCard, vfDev *api.ResourcesNetworkCardPort) string {
+	for slave, card := range cards {
+		var slavePort uint64
+		for _, port := range card.Ports {
+			if port.ID == slave {
+				slavePort = port.Port
+				break
+			}
+		}
+
+		for _, vf := range card.SRIOV.VFs {
+			for _, port := range vf.Ports {
+				if port.ID == vfDev.ID && port.Port == slavePort {
+					return slave
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
 // infinibandAddDevices creates the UNIX devices for the provided IBF device and then configures the
 // supplied runConfig with the Cgroup rules and mount instructions to pass the device into instance.
 func infinibandAddDevices(s *state.State, devicesPath string, deviceName string, ibDev *api.ResourcesNetworkCardPort, runConf *deviceConfig.RunConfig) error {
@@ -150,3 +378,148 @@ func infinibandSetDevMAC(ibDev string, hwaddr string) error {
 
 	return fmt.Errorf("Invalid length")
 }
+
+// infinibandValidPKey validates an infiniband partition key, e.g. "0x8001" or "7fff".
+// Keys with and without the full membership bit (0x8000) are accepted but the partition number can't be zero.
+func infinibandValidPKey(value string) error {
+	_, err := infinibandParsePKey(value)
+	return err
+}
+
+// infinibandParsePKey parses an infiniband partition key in its 16-bit hexadecimal form.
+func infinibandParsePKey(value string) (uint16, error) {
+	hex := strings.TrimPrefix(strings.ToLower(value), "0x")
+	if hex == "" || len(hex) > 4 {
+		return 0, fmt.Errorf("Invalid partition key %q, must be a 16-bit hexadecimal value", value)
+	}
+
+	pkey, err := strconv.ParseUint(hex, 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid partition key %q, must be a 16-bit hexadecimal value", value)
+	}
+
+	if pkey&0x7fff == 0 {
+		return 0, fmt.Errorf("Invalid partition key %q, partition number can't be zero", value)
+	}
+
+	return uint16(pkey), nil
+}
+
+// infinibandVFPKeyIndexPath returns the sysfs directory holding the partition key table of the
+// parent port and the sysfs file mapping the default partition key of the virtual function to
+// one of its entries.
+func infinibandVFPKeyIndexPath(sysfsPath string, parent string, vfPCIAddress string) (string, string, error) {
+	parentPath := filepath.Join(sysfsPath, "class", "net", parent)
+
+	port, err := os.ReadFile(filepath.Join(parentPath, "dev_port"))
+	if err != nil {
+		return "", "", fmt.Errorf("Failed getting port of %q: %w", parent, err)
+	}
+
+	devPort, err := strconv.ParseUint(strings.TrimSpace(string(port)), 10, 64)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed parsing port of %q: %w", parent, err)
+	}
+
+	ibPort := strconv.FormatUint(devPort+1, 10)
+
+	// The partition key mapping is configured through the physical function.
+	pfPath := filepath.Join(parentPath, "device")
+	if _, err := os.Stat(filepath.Join(pfPath, "physfn")); err == nil {
+		pfPath = filepath.Join(pfPath, "physfn")
+	}
+
+	ibDevs, err := filepath.Glob(filepath.Join(pfPath, "infiniband", "*"))
+	if err != nil || len(ibDevs) == 0 {
+		return "", "", fmt.Errorf("Failed finding infiniband device of %q", parent)
+	}
+
+	ibPath := filepath.Join(sysfsPath, "class", "infiniband", filepath.Base(ibDevs[0]))
+	if _, err := os.Stat(filepath.Join(ibPath, "iov")); err != nil {
+		return "", "", fmt.Errorf("Parent device %q doesn't support configuring partition keys of virtual functions", parent)
+	}
+
+	pkeysPath := filepath.Join(ibPath, "ports", ibPort, "pkeys")
+	indexPath := filepath.Join(ibPath, "iov", vfPCIAddress, "ports", ibPort, "pkey_idx", "0")
+
+	return pkeysPath, indexPath, nil
+}
+
+// infinibandSetVFPKey maps the default partition key of the virtual function to the parent port
+// partition key table entry matching pkey. Returns the previously mapped index for restoration.
+func infinibandSetVFPKey(sysfsPath string, parent string, vfPCIAddress string, pkey string) (string, error) {
+	wantPKey, err := infinibandParsePKey(pkey)
+	if err != nil {
+		return "", err
+	}
+
+	pkeysPath, indexPath, err := infinibandVFPKeyIndexPath(sysfsPath, parent, vfPCIAddress)
+	if err != nil {
+		return "", err
+	}
+
+	oldIndex, err := os.ReadFile(indexPath)
+	if err != nil {
+		return "", fmt.Errorf("Failed getting partition key index of virtual function %q: %w", vfPCIAddress, err)
+	}
+
+	entries, err := os.ReadDir(pkeysPath)
+	if err != nil {
+		return "", fmt.Errorf("Failed listing partition keys of %q: %w", parent, err)
+	}
+
+	index := ""
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(pkeysPath, entry.Name()))
+		if err != nil {
+			return "", fmt.Errorf("Failed reading partition key %q of %q: %w", entry.Name(), parent, err)
+		}
+
+		entryPKey, err := infinibandParsePKey(strings.TrimSpace(string(content)))
+		if err != nil {
+			// Skip unused entries.
+			continue
+		}
+
+		if entryPKey == wantPKey {
+			index = entry.Name()
+			break
+		}
+	}
+
+	if index == "" {
+		return "", fmt.Errorf("Partition key %q isn't configured on %q", pkey, parent)
+	}
+
+	err = os.WriteFile(indexPath, []byte(index), 0)
+	if err != nil {
+		return "", fmt.Errorf("Failed setting partition key of virtual function %q: %w", vfPCIAddress, err)
+	}
+
+	return strings.TrimSpace(string(oldIndex)), nil
+}
+
+// infinibandRestoreVFPKey restores the partition key index previously mapped to the virtual function.
+func infinibandRestoreVFPKey(sysfsPath string, parent string, vfPCIAddress string, index string) error {
+	_, indexPath, err := infinibandVFPKeyIndexPath(sysfsPath, parent, vfPCIAddress)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(indexPath, []byte(index), 0)
+	if err != nil {
+		return fmt.Errorf("Failed restoring partition key of virtual function %q: %w", vfPCIAddress, err)
+	}
+
+	return nil
+}
+
+// infinibandVFPCIAddress returns the PCI address of the virtual function backing an infiniband interface.
+func infinibandVFPCIAddress(sysfsPath string, devName string) (string, error) {
+	devicePath, err := filepath.EvalSymlinks(filepath.Join(sysfsPath, "class", "net", devName, "device"))
+	if err != nil {
+		return "", fmt.Errorf("Failed getting PCI device of %q: %w", devName, err)
+	}
+
+	return filepath.Base(devicePath), nil
+}
@@ -0,0 +1,221 @@
+package device
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestInfinibandValidPKey(t *testing.T) {
+	for _, value := range []string{"0x8001", "8001", "0x7fff", "0xFFFF", "0x1", "1"} {
+		assert.NoError(t, infinibandValidPKey(value), value)
+	}
+
+	for _, value := range []string{"", "0x", "0x0000", "0x8000", "0x10000", "12345", "0xgggg", "-1"} {
+		assert.Error(t, infinibandValidPKey(value), value)
+	}
+}
+
+// ibTestSysfs creates a fake sysfs with an infiniband physical function "ib0" and its virtual function "ib1".
+func ibTestSysfs(t *testing.T) string {
+	sysfsPath := t.TempDir()
+
+	writeFile := func(path string, content string) {
+		path = filepath.Join(sysfsPath, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	symlink := func(target string, path string) {
+		path = filepath.Join(sysfsPath, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.Symlink(filepath.Join(sysfsPath, target), path))
+	}
+
+	writeFile("devices/0000:01:00.0/sriov_totalvfs", "8\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(sysfsPath, "devices/0000:01:00.0/infiniband/mlx4_0"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(sysfsPath, "devices/0000:01:00.1"), 0o755))
+
+	writeFile("class/net/ib0/dev_port", "0\n")
+	symlink("devices/0000:01:00.0", "class/net/ib0/device")
+	symlink("devices/0000:01:00.1", "class/net/ib1/device")
+
+	writeFile("class/infiniband/mlx4_0/ports/1/pkeys/0", "0xffff\n")
+	writeFile("class/infiniband/mlx4_0/ports/1/pkeys/1", "0x0000\n")
+	writeFile("class/infiniband/mlx4_0/ports/1/pkeys/2", "0x8001\n")
+	writeFile("class/infiniband/mlx4_0/iov/0000:01:00.1/ports/1/pkey_idx/0", "0\n")
+
+	return sysfsPath
+}
+
+func TestInfinibandSetVFPKey(t *testing.T) {
+	sysfsPath := ibTestSysfs(t)
+	indexPath := filepath.Join(sysfsPath, "class/infiniband/mlx4_0/iov/0000:01:00.1/ports/1/pkey_idx/0")
+
+	vfPCIAddress, err := infinibandVFPCIAddress(sysfsPath, "ib1")
+	require.NoError(t, err)
+	assert.Equal(t, "0000:01:00.1", vfPCIAddress)
+
+	// Map the virtual function to the configured partition key.
+	oldIndex, err := infinibandSetVFPKey(sysfsPath, "ib0", vfPCIAddress, "8001")
+	require.NoError(t, err)
+	assert.Equal(t, "0", oldIndex)
+
+	content, err := os.ReadFile(indexPath)
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(content))
+
+	// The membership bit is part of the partition key.
+	_, err = infinibandSetVFPKey(sysfsPath, "ib0", vfPCIAddress, "0x0001")
+	assert.Error(t, err)
+
+	// Restore the original mapping.
+	err = infinibandRestoreVFPKey(sysfsPath, "ib0", vfPCIAddress, oldIndex)
+	require.NoError(t, err)
+
+	content, err = os.ReadFile(indexPath)
+	require.NoError(t, err)
+	assert.Equal(t, "0", string(content))
+
+	// Parents without virtual function partition key support are rejected.
+	require.NoError(t, os.RemoveAll(filepath.Join(sysfsPath, "class/infiniband/mlx4_0/iov")))
+	_, err = infinibandSetVFPKey(sysfsPath, "ib0", vfPCIAddress, "0x8001")
+	assert.Error(t, err)
+}
+
+// ibTestBondNICs returns two dual VF infiniband cards whose ports "ib0" and "ib1" are bonded.
+func ibTestBondNICs() *api.ResourcesNetwork {
+	card := func(pf string, vfs ...string) api.ResourcesNetworkCard {
+		sriov := &api.ResourcesNetworkCardSRIOV{}
+		for _, vf := range vfs {
+			sriov.VFs = append(sriov.VFs, api.ResourcesNetworkCard{
+				Ports: []api.ResourcesNetworkCardPort{{ID: vf, Protocol: "infiniband"}},
+			})
+		}
+
+		return api.ResourcesNetworkCard{
+			Driver: "mlx5_core",
+			Ports:  []api.ResourcesNetworkCardPort{{ID: pf, Protocol: "infiniband"}},
+			SRIOV:  sriov,
+		}
+	}
+
+	return &api.ResourcesNetwork{
+		Cards: []api.ResourcesNetworkCard{
+			card("ib0", "ib0v0", "ib0v1"),
+			card("ib1", "ib1v0", "ib1v1"),
+			{Driver: "e1000e", Ports: []api.ResourcesNetworkCardPort{{ID: "eth0", Protocol: "ethernet"}}},
+		},
+	}
+}
+
+func TestInfinibandBondSlaves(t *testing.T) {
+	sysfsPath := t.TempDir()
+
+	// Non bond interfaces don't have slaves.
+	slaves, err := infinibandBondSlaves(sysfsPath, "ib0")
+	require.NoError(t, err)
+	assert.Nil(t, slaves)
+
+	bondingPath := filepath.Join(sysfsPath, "class/net/bond0/bonding")
+	require.NoError(t, os.MkdirAll(bondingPath, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(bondingPath, "slaves"), []byte("ib0 ib1\n"), 0o644))
+
+	slaves, err = infinibandBondSlaves(sysfsPath, "bond0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ib0", "ib1"}, slaves)
+
+	// The active slave comes first.
+	require.NoError(t, os.WriteFile(filepath.Join(bondingPath, "active_slave"), []byte("ib1\n"), 0o644))
+
+	slaves, err = infinibandBondSlaves(sysfsPath, "bond0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ib1", "ib0"}, slaves)
+
+	// Bonds without slaves can't be used.
+	require.NoError(t, os.WriteFile(filepath.Join(bondingPath, "slaves"), []byte("\n"), 0o644))
+
+	_, err = infinibandBondSlaves(sysfsPath, "bond0")
+	assert.Error(t, err)
+}
+
+func TestInfinibandDevicesBond(t *testing.T) {
+	nics := ibTestBondNICs()
+
+	ibDevs := infinibandDevices(nics, "ib0")
+	assert.ElementsMatch(t, []string{"ib0", "ib0v0", "ib0v1"}, slices.Collect(maps.Keys(ibDevs)))
+
+	// The virtual functions of all the slaves are returned without the slaves.
+	ibDevs = infinibandDevices(nics, "bond0", "ib0", "ib1")
+	assert.ElementsMatch(t, []string{"ib0v0", "ib0v1", "ib1v0", "ib1v1"}, slices.Collect(maps.Keys(ibDevs)))
+
+	cards, err := infinibandBondCards(nics, "bond0", []string{"ib0", "ib1"})
+	require.NoError(t, err)
+	assert.Equal(t, "ib0", infinibandVFParent(cards, ibDevs["ib0v1"]))
+	assert.Equal(t, "ib1", infinibandVFParent(cards, ibDevs["ib1v0"]))
+
+	// Slaves must all be SR-IOV capable infiniband ports using the same driver.
+	_, err = infinibandBondCards(nics, "bond0", []string{"ib0", "eth0"})
+	assert.Error(t, err)
+
+	_, err = infinibandBondCards(nics, "bond0", []string{"ib0", "ib2"})
+	assert.Error(t, err)
+
+	nics.Cards[1].Driver = "mlx4_core"
+	_, err = infinibandBondCards(nics, "bond0", []string{"ib0", "ib1"})
+	assert.Error(t, err)
+
+	nics.Cards[1].Driver = "mlx5_core"
+	nics.Cards[1].SRIOV = nil
+	_, err = infinibandBondCards(nics, "bond0", []string{"ib0", "ib1"})
+	assert.Error(t, err)
+}
+
+func TestInfinibandBondWatch(t *testing.T) {
+	interval := infinibandBondInterval
+	infinibandBondInterval = 10 * time.Millisecond
+	t.Cleanup(func() { infinibandBondInterval = interval })
+
+	sysfsPath := t.TempDir()
+	bondingPath := filepath.Join(sysfsPath, "class/net/bond0/bonding")
+	require.NoError(t, os.MkdirAll(bondingPath, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(bondingPath, "active_slave"), []byte("ib0\n"), 0o644))
+
+	seen := make(chan string, 10)
+	infinibandBondWatch(sysfsPath, "bond0", "c1/eth0", func(activeSlave string) { seen <- activeSlave })
+
+	// The handler is called with the current active slave first.
+	select {
+	case activeSlave := <-seen:
+		assert.Equal(t, "ib0", activeSlave)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handler wasn't called with the initial active slave")
+	}
+
+	// Then on failover.
+	require.NoError(t, os.WriteFile(filepath.Join(bondingPath, "active_slave"), []byte("ib1\n"), 0o644))
+	select {
+	case activeSlave := <-seen:
+		assert.Equal(t, "ib1", activeSlave)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handler wasn't called on failover")
+	}
+
+	// The watcher goes away along with its last handler.
+	infinibandBondUnwatch(sysfsPath, "bond0", "c1/eth0")
+	infinibandBondWatchersMu.Lock()
+	assert.Empty(t, infinibandBondWatchers)
+	infinibandBondWatchersMu.Unlock()
+
+	require.NoError(t, os.WriteFile(filepath.Join(bondingPath, "active_slave"), []byte("ib0\n"), 0o644))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, seen)
+}
@@ -1188,3 +1188,36 @@ func pingOverIfaceByName(deadline time.Time, address net.IP, parentInterface str
 
 	return nil
 }
+
+// networkValidRoutedAddress validates that an address can be routed to an instance.
+// It rejects unspecified, loopback, link-local, multicast and broadcast addresses.
+func networkValidRoutedAddress(value string) error {
+	addr := net.ParseIP(value)
+	if addr == nil {
+		return fmt.Errorf("Invalid IP address %q", value)
+	}
+
+	if addr.IsUnspecified() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsMulticast() || addr.IsInterfaceLocalMulticast() || addr.Equal(net.IPv4bcast) {
+		return fmt.Errorf("Address %q isn't a routable unicast address", value)
+	}
+
+	return nil
+}
+
+// networkHostAddressConflict returns an error if one of the addresses is already assigned to the host.
+func networkHostAddressConflict(addresses []string, hostAddresses []net.Addr) error {
+	for _, hostAddress := range hostAddresses {
+		hostNet, ok := hostAddress.(*net.IPNet)
+		if !ok {
+			continue
+		}
+
+		for _, address := range addresses {
+			if hostNet.IP.Equal(net.ParseIP(address)) {
+				return fmt.Errorf("Address %q is already in use on the host", address)
+			}
+		}
+	}
+
+	return nil
+}
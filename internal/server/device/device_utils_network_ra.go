@@ -0,0 +1,202 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/mdlayher/ndp"
+	"golang.org/x/net/ipv6"
+
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// networkRAInterval is the interval between unsolicited router advertisements.
+const networkRAInterval = 60 * time.Second
+
+// networkRALifetime is the lifetime of the information carried in router advertisements.
+// It is three times the advertisement interval, as recommended by RFC 8106.
+const networkRALifetime = 3 * networkRAInterval
+
+// networkRASenders tracks the router advertisement senders running on host-side NIC interfaces.
+var (
+	networkRASenders   = map[string]context.CancelFunc{}
+	networkRASendersMu sync.Mutex
+)
+
+// networkRouterAdvertisement returns the router advertisement pushing the given DNS servers (RDNSS) to an instance
+// NIC. No prefix information is included as addresses are statically configured. When defaultRoute is true, the
+// sender is also advertised as the default router.
+func networkRouterAdvertisement(hwaddr net.HardwareAddr, nameservers []netip.Addr, defaultRoute bool) *ndp.RouterAdvertisement {
+	ra := &ndp.RouterAdvertisement{}
+
+	if defaultRoute {
+		ra.RouterLifetime = networkRALifetime
+	}
+
+	if len(hwaddr) > 0 {
+		ra.Options = append(ra.Options, &ndp.LinkLayerAddress{
+			Direction: ndp.Source,
+			Addr:      hwaddr,
+		})
+	}
+
+	if len(nameservers) > 0 {
+		ra.Options = append(ra.Options, &ndp.RecursiveDNSServer{
+			Lifetime: networkRALifetime,
+			Servers:  nameservers,
+		})
+	}
+
+	return ra
+}
+
+// networkRAStart starts sending router advertisements with the given DNS servers on the host-side interface,
+// from the srcAddr link-local address (any link-local address of the interface if empty). Any sender already running
+// on the interface is replaced.
+func networkRAStart(devName string, srcAddr string, nameservers []netip.Addr, defaultRoute bool) {
+	networkRAStop(devName)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	networkRASendersMu.Lock()
+	networkRASenders[devName] = cancel
+	networkRASendersMu.Unlock()
+
+	go networkRARun(ctx, devName, srcAddr, nameservers, defaultRoute)
+}
+
+// networkRAStop stops the router advertisements sender running on the host-side interface, if any.
+func networkRAStop(devName string) {
+	networkRASendersMu.Lock()
+	defer networkRASendersMu.Unlock()
+
+	cancel, found := networkRASenders[devName]
+	if !found {
+		return
+	}
+
+	cancel()
+	delete(networkRASenders, devName)
+}
+
+// networkRARun sends router advertisements on the interface every networkRAInterval and in response to router
+// solicitations until the context is cancelled. The interface may not be up yet (for VMs until QEMU opens the TAP
+// device) or still be performing duplicate address detection, so opening it is retried.
+func networkRARun(ctx context.Context, devName string, srcAddr string, nameservers []netip.Addr, defaultRoute bool) {
+	l := logger.AddContext(logger.Ctx{"interface": devName})
+
+	listenAddr := ndp.LinkLocal
+	if srcAddr != "" {
+		listenAddr = ndp.Addr(srcAddr)
+	}
+
+	var conn *ndp.Conn
+	defer func() {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}()
+
+	// Close the connection to unblock any pending read once cancelled.
+	go func() {
+		<-ctx.Done()
+
+		networkRASendersMu.Lock()
+		defer networkRASendersMu.Unlock()
+
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}()
+
+	var ra *ndp.RouterAdvertisement
+
+	for ctx.Err() == nil {
+		if conn == nil {
+			iface, err := net.InterfaceByName(devName)
+			if err == nil {
+				var c *ndp.Conn
+				c, _, err = ndp.Listen(iface, listenAddr)
+				if err == nil {
+					err = c.JoinGroup(netip.IPv6LinkLocalAllRouters())
+					if err != nil {
+						_ = c.Close()
+					}
+				}
+
+				if err == nil {
+					var filter ipv6.ICMPFilter
+					filter.SetAll(true)
+					filter.Accept(ipv6.ICMPTypeRouterSolicitation)
+					_ = c.SetICMPFilter(&filter)
+
+					networkRASendersMu.Lock()
+					conn = c
+					networkRASendersMu.Unlock()
+
+					ra = networkRouterAdvertisement(iface.HardwareAddr, nameservers, defaultRoute)
+				}
+			}
+
+			if err != nil {
+				l.Debug("Failed opening interface for router advertisements, retrying", logger.Ctx{"err": err})
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+
+				continue
+			}
+		}
+
+		err := conn.WriteTo(ra, nil, netip.IPv6LinkLocalAllNodes())
+		if err != nil {
+			l.Debug("Failed sending router advertisement", logger.Ctx{"err": err})
+		}
+
+		// Answer router solicitations until the next unsolicited advertisement is due.
+		deadline := time.Now().Add(networkRAInterval)
+		for ctx.Err() == nil {
+			_ = conn.SetReadDeadline(deadline)
+
+			msg, _, from, err := conn.ReadFrom()
+			if err != nil {
+				var netErr net.Error
+				if !errors.As(err, &netErr) || !netErr.Timeout() {
+					l.Debug("Failed reading router solicitation", logger.Ctx{"err": err})
+				}
+
+				break
+			}
+
+			_, ok := msg.(*ndp.RouterSolicitation)
+			if !ok {
+				continue
+			}
+
+			err = conn.WriteTo(ra, nil, from)
+			if err != nil {
+				l.Debug("Failed answering router solicitation", logger.Ctx{"err": err})
+			}
+		}
+
+		// Reopen the interface if it went away (e.g. TAP device re-created).
+		if ctx.Err() == nil && time.Now().Before(deadline) {
+			_ = conn.Close()
+
+			networkRASendersMu.Lock()
+			conn = nil
+			networkRASendersMu.Unlock()
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package device
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/mdlayher/ndp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkRouterAdvertisement(t *testing.T) {
+	hwaddr := net.HardwareAddr{0x00, 0x16, 0x3e, 0x01, 0x02, 0x03}
+	nameservers := []netip.Addr{netip.MustParseAddr("2001:db8::53"), netip.MustParseAddr("2001:db8::54")}
+
+	b, err := ndp.MarshalMessage(networkRouterAdvertisement(hwaddr, nameservers, true))
+	require.NoError(t, err)
+
+	msg, err := ndp.ParseMessage(b)
+	require.NoError(t, err)
+
+	ra, ok := msg.(*ndp.RouterAdvertisement)
+	require.True(t, ok)
+
+	assert.Equal(t, networkRALifetime, ra.RouterLifetime)
+	assert.False(t, ra.ManagedConfiguration)
+	assert.False(t, ra.OtherConfiguration)
+
+	var rdnss *ndp.RecursiveDNSServer
+	var lla *ndp.LinkLayerAddress
+
+	for _, option := range ra.Options {
+		switch o := option.(type) {
+		case *ndp.RecursiveDNSServer:
+			rdnss = o
+		case *ndp.LinkLayerAddress:
+			lla = o
+		case *ndp.PrefixInformation:
+			t.Errorf("Unexpected prefix information option %v", o)
+		}
+	}
+
+	require.NotNil(t, rdnss)
+	assert.Equal(t, nameservers, rdnss.Servers)
+	assert.Equal(t, networkRALifetime, rdnss.Lifetime)
+
+	require.NotNil(t, lla)
+	assert.Equal(t, ndp.Source, lla.Direction)
+	assert.Equal(t, hwaddr, lla.Addr)
+}
+
+func TestNetworkRouterAdvertisementNoDefaultRoute(t *testing.T) {
+	nameservers := []netip.Addr{netip.MustParseAddr("fd00::53")}
+
+	b, err := ndp.MarshalMessage(networkRouterAdvertisement(nil, nameservers, false))
+	require.NoError(t, err)
+
+	msg, err := ndp.ParseMessage(b)
+	require.NoError(t, err)
+
+	ra, ok := msg.(*ndp.RouterAdvertisement)
+	require.True(t, ok)
+
+	// A zero router lifetime doesn't make the sender a default router but the DNS servers are still advertised.
+	assert.Zero(t, ra.RouterLifetime)
+	require.Len(t, ra.Options, 1)
+
+	rdnss, ok := ra.Options[0].(*ndp.RecursiveDNSServer)
+	require.True(t, ok)
+	assert.Equal(t, nameservers, rdnss.Servers)
+}
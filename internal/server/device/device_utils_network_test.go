@@ -0,0 +1,33 @@
+package device
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkValidRoutedAddress(t *testing.T) {
+	valid := []string{"192.0.2.10", "10.0.0.1", "2001:db8::10", "fd00::1"}
+	for _, addr := range valid {
+		assert.NoError(t, networkValidRoutedAddress(addr), addr)
+	}
+
+	invalid := []string{"", "foo", "0.0.0.0", "127.0.0.1", "169.254.0.1", "224.0.0.1", "255.255.255.255", "::", "::1", "fe80::1", "ff02::1"}
+	for _, addr := range invalid {
+		assert.Error(t, networkValidRoutedAddress(addr), addr)
+	}
+}
+
+func TestNetworkHostAddressConflict(t *testing.T) {
+	hostAddresses := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("192.0.2.1"), Mask: net.CIDRMask(24, 32)},
+		&net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(64, 128)},
+		&net.IPAddr{IP: net.ParseIP("198.51.100.1")},
+	}
+
+	assert.NoError(t, networkHostAddressConflict([]string{"192.0.2.10", "2001:db8::10"}, hostAddresses))
+	assert.NoError(t, networkHostAddressConflict(nil, hostAddresses))
+	assert.Error(t, networkHostAddressConflict([]string{"192.0.2.10", "192.0.2.1"}, hostAddresses))
+	assert.Error(t, networkHostAddressConflict([]string{"2001:db8:0::1"}, hostAddresses))
+}
@@ -0,0 +1,85 @@
+package device
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/sys"
+)
+
+// ibTestDevices returns the unix devices making up a test infiniband port.
+func ibTestDevices(verbMinor string) []deviceConfig.Device {
+	return []deviceConfig.Device{
+		{"source": "/dev/infiniband/issm0", "major": "231", "minor": "64"},
+		{"source": "/dev/infiniband/umad0", "major": "231", "minor": "0"},
+		{"source": "/dev/infiniband/uverbs" + verbMinor, "major": "231", "minor": "19" + verbMinor},
+	}
+}
+
+// ibTestSetup creates the host side files for an infiniband device and returns its run config.
+func ibTestSetup(t *testing.T, s *state.State, devicesPath string, deviceName string, devices []deviceConfig.Device) *deviceConfig.RunConfig {
+	runConf := deviceConfig.RunConfig{}
+
+	for _, dev := range devices {
+		err := unixDeviceSetup(s, devicesPath, IBDevPrefix, deviceName, dev, true, &runConf)
+		if errors.Is(err, unix.EPERM) {
+			t.Skip("Creating device nodes isn't permitted")
+		}
+
+		require.NoError(t, err)
+	}
+
+	return &runConf
+}
+
+func TestInfinibandCgroupRules(t *testing.T) {
+	s := &state.State{OS: &sys.OS{}}
+	devicesPath := t.TempDir()
+
+	// Attaching the first device allows all its character devices.
+	runConf := ibTestSetup(t, s, devicesPath, "ib0", ibTestDevices("0"))
+	assert.Equal(t, []deviceConfig.RunConfigItem{
+		{Key: "devices.allow", Value: "c 231:64 rwm"},
+		{Key: "devices.allow", Value: "c 231:0 rwm"},
+		{Key: "devices.allow", Value: "c 231:190 rwm"},
+	}, runConf.CGroups)
+	assert.Len(t, runConf.Mounts, 3)
+
+	// Attaching a second device sharing the issm and umad paths only adds its verb device.
+	runConf = ibTestSetup(t, s, devicesPath, "ib1", ibTestDevices("1"))
+	assert.Equal(t, []deviceConfig.RunConfigItem{
+		{Key: "devices.allow", Value: "c 231:191 rwm"},
+	}, runConf.CGroups)
+	assert.Len(t, runConf.Mounts, 1)
+
+	// Detaching the first device only denies the devices not shared with the second one.
+	runConf = &deviceConfig.RunConfig{}
+	err := unixDeviceRemove(devicesPath, IBDevPrefix, "ib0", "", runConf)
+	require.NoError(t, err)
+	assert.Equal(t, []deviceConfig.RunConfigItem{
+		{Key: "devices.deny", Value: "c 231:190 rwm"},
+	}, runConf.CGroups)
+	assert.Equal(t, []deviceConfig.MountEntryItem{
+		{TargetPath: "dev/infiniband/uverbs0"},
+	}, runConf.Mounts)
+
+	err = unixDeviceDeleteFiles(s, devicesPath, IBDevPrefix, "ib0", "")
+	require.NoError(t, err)
+
+	// Detaching the last device denies all remaining devices.
+	runConf = &deviceConfig.RunConfig{}
+	err = unixDeviceRemove(devicesPath, IBDevPrefix, "ib1", "", runConf)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []deviceConfig.RunConfigItem{
+		{Key: "devices.deny", Value: "c 231:64 rwm"},
+		{Key: "devices.deny", Value: "c 231:0 rwm"},
+		{Key: "devices.deny", Value: "c 231:191 rwm"},
+	}, runConf.CGroups)
+	assert.Len(t, runConf.Mounts, 3)
+}
@@ -64,6 +64,9 @@ const DiskDirectIO = "directio"
 // DiskIOUring is used to indicate disk should use io_uring if the system supports it.
 const DiskIOUring = "io_uring"
 
+// DiskDiscard is used to indicate discard requests should be passed through to the disk.
+const DiskDiscard = "discard"
+
 // DiskLoopBacked is used to indicate disk is backed onto a loop device.
 const DiskLoopBacked = "loop"
 
@@ -288,7 +291,7 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		// ---
 		//  type: string
 		//  required: no
-		//  shortdesc: File system specific mount options
+		//  shortdesc: File system specific mount options (limited to per mount point options such as `ro`, `noexec`, `nosuid`, `nodev` or `noatime` for storage volumes)
 		"raw.mount.options": validate.IsAny,
 
 		// gendoc:generate(entity=devices, group=disk, key=ceph.cluster_name)
@@ -331,18 +334,24 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		// For block devices (disks), this is one of:
 		// - `none` (default)
 		// - `writeback`
+		// - `writethrough`
+		// - `directsync`
 		// - `unsafe`
 		//
 		// For file systems (shared directories or custom volumes), this is one of:
 		// - `none` (default)
 		// - `metadata`
 		// - `unsafe`
+		//
+		// The `unsafe` mode ignores flush requests from the guest and can lead to data loss on host crash.
+		// On sources without direct I/O support (such as image files on ZFS or Btrfs), `none` and `directsync` fall back to `writeback` and `writethrough`.
+		// The `writethrough` and `directsync` modes are only supported on the `virtio-blk` and `virtio-scsi` buses.
 		// ---
 		//  type: string
 		//  default: `none`
 		//  required: no
 		//  shortdesc: Only for VMs: Override the caching mode for the device
-		"io.cache": validate.Optional(validate.IsOneOf("none", "metadata", "writeback", "unsafe")),
+		"io.cache": validate.Optional(validate.IsOneOf("none", "metadata", "writeback", "writethrough", "directsync", "unsafe")),
 
 		// gendoc:generate(entity=devices, group=disk, key=io.bus)
 		// This controls what bus a disk device should be attached to.
@@ -363,6 +372,52 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		//  required: no
 		//  shortdesc: Only for VMs: Override the bus for the device
 		"io.bus": validate.Optional(validate.IsOneOf("nvme", "virtio-blk", "virtio-scsi", "auto", "9p", "virtiofs", "usb")),
+
+		// gendoc:generate(entity=devices, group=disk, key=io.scheduler)
+		// The I/O scheduler is set on the host block device when the instance starts and the previous one
+		// is restored when it stops. It must be one of the schedulers the host offers for that device.
+		//
+		// Only whole host block devices which aren't used by anything else can be tuned this way.
+		// Partitions, storage pool volumes and shared paths are rejected as changing their queue would
+		// affect the rest of the host.
+		// Only one disk device at a time can tune a given host block device.
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: I/O scheduler of a dedicated host block device source
+		"io.scheduler": validate.Optional(validate.IsNotEmpty),
+
+		// gendoc:generate(entity=devices, group=disk, key=io.readahead)
+		// The read-ahead is set on the host block device when the instance starts and the previous value
+		// is restored when it stops. The same restrictions as for `io.scheduler` apply.
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Read-ahead (for example `512KiB`) of a dedicated host block device source
+		"io.readahead": validate.Optional(validate.IsSize),
+
+		// gendoc:generate(entity=devices, group=disk, key=discard)
+		// Discard (TRIM) requests from the guest are always passed through to the disk.
+		// When enabled, the writes of zeroes by the guest are turned into discards too (`detect-zeroes=unmap`),
+		// letting the block device reclaim that space as well. The block device must support discard.
+		// ---
+		//  type: bool
+		//  default: `false`
+		//  required: no
+		//  shortdesc: Only for VMs: Turn writes of zeroes into discards on a block device source
+		"discard": validate.Optional(validate.IsBool),
+
+		// gendoc:generate(entity=devices, group=disk, key=media)
+		// When set to `cdrom`, the disk is attached to the virtual machine as a removable drive.
+		// Its `source` must then be an ISO image (file or custom ISO volume) or be left unset for an empty drive.
+		// Changing the `source` of a running virtual machine replaces the medium in the drive,
+		// and the guest can eject the medium itself.
+		// ---
+		//  type: string
+		//  default: `disk`
+		//  required: no
+		//  shortdesc: Only for VMs: Type of media (`disk` or `cdrom`)
+		"media": validate.Optional(validate.IsOneOf("disk", "cdrom")),
 	}
 
 	err := d.config.Validate(rules)
@@ -378,11 +433,58 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("IO cache configuration cannot be applied to containers")
 	}
 
+	// The guest write cache can only be disabled on the virtio buses.
+	if slices.Contains([]string{"writethrough", "directsync"}, d.config["io.cache"]) && slices.Contains([]string{"nvme", "usb"}, d.config["io.bus"]) {
+		return fmt.Errorf("IO cache mode %q cannot be used with the %q bus", d.config["io.cache"], d.config["io.bus"])
+	}
+
+	if d.config["discard"] != "" {
+		if instConf.Type() == instancetype.Container {
+			return fmt.Errorf("Discard configuration cannot be applied to containers")
+		}
+
+		if internalInstance.IsRootDiskDevice(d.config) {
+			return fmt.Errorf("Discard configuration can only be applied to block device sources")
+		}
+	}
+
+	if d.config["io.scheduler"] != "" || d.config["io.readahead"] != "" {
+		if d.config["pool"] != "" || d.config["path"] == "/" {
+			return fmt.Errorf("I/O scheduler and read-ahead can't be applied to storage pool volumes as their block devices are shared with the host")
+		}
+
+		if d.sourceIsCeph() || d.sourceIsCephFs() || !filepath.IsAbs(d.config["source"]) {
+			return fmt.Errorf("I/O scheduler and read-ahead can only be applied to host block device sources")
+		}
+	}
+
+	if d.config["media"] == "cdrom" {
+		if instConf.Type() == instancetype.Container {
+			return fmt.Errorf("Removable media can't be used with containers")
+		}
+
+		if d.config["path"] != "" {
+			return fmt.Errorf("Removable media can't have a path defined")
+		}
+
+		if !slices.Contains([]string{"", "virtio-scsi"}, d.config["io.bus"]) {
+			return fmt.Errorf("Removable media can only be attached to the virtio-scsi bus")
+		}
+
+		if d.config["source"] == "" && d.config["pool"] != "" {
+			return fmt.Errorf("Removable media without a source can't have a pool defined")
+		}
+
+		if d.sourceIsCeph() || d.sourceIsCephFs() || slices.Contains([]string{diskSourceCloudInit, diskSourceAgent}, d.config["source"]) {
+			return fmt.Errorf("Removable media source must be an ISO image")
+		}
+	}
+
 	if d.config["required"] != "" && d.config["optional"] != "" {
 		return fmt.Errorf(`Cannot use both "required" and deprecated "optional" properties at the same time`)
 	}
 
-	if d.config["source"] == "" && d.config["path"] != "/" {
+	if d.config["source"] == "" && d.config["path"] != "/" && d.config["media"] != "cdrom" {
 		return fmt.Errorf(`Disk entry is missing the required "source" or "path" property`)
 	}
 
@@ -394,6 +496,14 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf(`Root disk entry must have a "pool" property set`)
 	}
 
+	// The root disk doesn't use the mount options.
+	if d.config["pool"] != "" && d.config["path"] != "/" && d.config["raw.mount.options"] != "" {
+		err := diskValidateBindMountOptions(util.SplitNTrimSpace(d.config["raw.mount.options"], ",", -1, true))
+		if err != nil {
+			return fmt.Errorf(`Invalid "raw.mount.options": %w`, err)
+		}
+	}
+
 	if d.config["size"] != "" && d.config["path"] != "/" {
 		return fmt.Errorf("Only the root disk may have a size quota")
 	}
@@ -446,6 +556,25 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("Missing source path %q for disk %q", d.config["source"], d.name)
 	}
 
+	// Host optical drives can be passed through with or without a medium, so only image files are checked.
+	if d.inst != nil && srcPathIsLocal && d.config["media"] == "cdrom" && util.PathExists(d.config["source"]) && !IsBlockdev(d.config["source"]) {
+		isISO, err := diskIsISO(d.config["source"])
+		if err != nil {
+			return fmt.Errorf("Failed checking source path %q for disk %q: %w", d.config["source"], d.name, err)
+		}
+
+		if !isISO {
+			return fmt.Errorf("Source path %q for disk %q isn't an ISO image", d.config["source"], d.name)
+		}
+	}
+
+	if d.inst != nil && (d.config["io.scheduler"] != "" || d.config["io.readahead"] != "") && util.PathExists(d.config["source"]) {
+		_, err := d.ioTuningQueuePath()
+		if err != nil {
+			return err
+		}
+	}
+
 	if d.config["pool"] != "" {
 		if d.config["shift"] != "" {
 			return fmt.Errorf(`The "shift" property cannot be used with custom storage volumes (set "security.shifted=true" on the volume instead)`)
@@ -573,6 +702,10 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 					return err
 				}
 
+				if d.config["media"] == "cdrom" && contentType != db.StoragePoolVolumeContentTypeISO {
+					return fmt.Errorf("Removable media can only use custom ISO volumes")
+				}
+
 				if contentType == db.StoragePoolVolumeContentTypeBlock {
 					if instConf.Type() == instancetype.Container {
 						return fmt.Errorf("Custom block volumes cannot be used on containers")
@@ -586,6 +719,10 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 						return fmt.Errorf("Custom block volume snapshots cannot be used directly")
 					}
 
+					if d.config["raw.mount.options"] != "" {
+						return fmt.Errorf("Custom block volumes cannot have mount options defined as they are passed to the instance as block devices")
+					}
+
 				} else if contentType == db.StoragePoolVolumeContentTypeISO {
 					if instConf.Type() == instancetype.Container {
 						return fmt.Errorf("Custom ISO volumes cannot be used on containers")
@@ -738,7 +875,19 @@ func (d *disk) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.max", "limits.read", "limits.write", "size", "size.state"}
+	fields := []string{"limits.max", "limits.read", "limits.write", "size", "size.state"}
+
+	// The medium of removable drives can be changed live.
+	if d.config["media"] == "cdrom" && oldDevice.(*disk).config["media"] == "cdrom" {
+		fields = append(fields, "source", "pool")
+	}
+
+	// The mount options of storage volumes can be applied live.
+	if d.config["pool"] != "" && d.config["path"] != "/" {
+		fields = append(fields, "raw.mount.options")
+	}
+
+	return fields
 }
 
 // Register calls mount for the disk volume (which should already be mounted) to reinitialize the reference counter
@@ -811,10 +960,17 @@ func (d *disk) Start() (*deviceConfig.RunConfig, error) {
 
 	err := d.validateEnvironment()
 	if err == nil {
-		if d.inst.Type() == instancetype.VM {
-			runConfig, err = d.startVM()
-		} else {
-			runConfig, err = d.startContainer()
+		err = d.applyIOTuning()
+		if err == nil {
+			if d.inst.Type() == instancetype.VM {
+				runConfig, err = d.startVM()
+			} else {
+				runConfig, err = d.startContainer()
+			}
+		}
+
+		if err != nil {
+			_ = d.restoreIOTuning()
 		}
 	}
 
@@ -1043,6 +1199,12 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 		opts = append(opts, fmt.Sprintf("cache=%s", d.config["io.cache"]))
 	}
 
+	// Removable media is always read-only.
+	isCDROM := d.config["media"] == "cdrom"
+	if isCDROM {
+		opts = append(opts, "ro")
+	}
+
 	// Add I/O limits if set.
 	var diskLimits *deviceConfig.DiskLimits
 	if d.config["limits.read"] != "" || d.config["limits.write"] != "" || d.config["limits.max"] != "" {
@@ -1078,6 +1240,17 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 			},
 		}
 
+		return &runConf, nil
+	} else if isCDROM && d.config["source"] == "" {
+		// Removable drive without a medium.
+		runConf.Mounts = []deviceConfig.MountEntryItem{
+			{
+				DevName: d.name,
+				FSType:  "iso9660",
+				Opts:    opts,
+			},
+		}
+
 		return &runConf, nil
 	} else if d.config["source"] == diskSourceAgent {
 		// This is a special virtual disk source that can be attached to a VM to provide agent binary and config.
@@ -1301,29 +1474,24 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 
 					revertFunc, unixListener, err := DiskVMVirtiofsdStart(d.state.OS.ExecPath, d.inst, sockPath, pidPath, logPath, mount.DevPath, rawIDMaps.Entries, d.config["io.cache"])
 					if err != nil {
-						if busOption == "virtiofs" {
+						if !diskVirtiofsdFallback(busOption, err) {
 							return err
 						}
 
-						var errUnsupported UnsupportedError
-						if errors.As(err, &errUnsupported) {
-							d.logger.Warn("Unable to use virtio-fs for device, using 9p as a fallback", logger.Ctx{"err": errUnsupported})
-							// Fallback to 9p-only.
-							busOption = "9p"
-
-							if errors.Is(errUnsupported, ErrMissingVirtiofsd) {
-								_ = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-									return tx.UpsertWarningLocalNode(ctx, d.inst.Project().Name, cluster.TypeInstance, d.inst.ID(), warningtype.MissingVirtiofsd, "Using 9p as a fallback")
-								})
-							} else {
-								// Resolve previous warning.
-								_ = warnings.ResolveWarningsByLocalNodeAndProjectAndType(d.state.DB.Cluster, d.inst.Project().Name, warningtype.MissingVirtiofsd)
-							}
-
-							return nil
+						d.logger.Warn("Unable to use virtio-fs for device, using 9p as a fallback", logger.Ctx{"err": err})
+						// Fallback to 9p-only.
+						busOption = "9p"
+
+						if errors.Is(err, ErrMissingVirtiofsd) {
+							_ = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+								return tx.UpsertWarningLocalNode(ctx, d.inst.Project().Name, cluster.TypeInstance, d.inst.ID(), warningtype.MissingVirtiofsd, "Using 9p as a fallback")
+							})
+						} else {
+							// Resolve previous warning.
+							_ = warnings.ResolveWarningsByLocalNodeAndProjectAndType(d.state.DB.Cluster, d.inst.Project().Name, warningtype.MissingVirtiofsd)
 						}
 
-						return err
+						return nil
 					}
 
 					reverter.Add(revertFunc)
@@ -1365,11 +1533,25 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 					return nil, err
 				}
 
-				err = validate.Optional(validate.IsOneOf("none", "writeback", "unsafe"))(d.config["io.cache"])
+				err = validate.Optional(validate.IsOneOf("none", "writeback", "writethrough", "directsync", "unsafe"))(d.config["io.cache"])
 				if err != nil {
 					return nil, err
 				}
 
+				// Check the source can handle discard requests before passing them through.
+				if util.IsTrue(d.config["discard"]) {
+					supported, err := diskBlockDevSupportsDiscard("/sys", mount.DevPath)
+					if err != nil {
+						return nil, fmt.Errorf("Failed checking discard support of %q: %w", mount.DevPath, err)
+					}
+
+					if !supported {
+						return nil, fmt.Errorf("Block device %q doesn't support discard", mount.DevPath)
+					}
+
+					mount.Opts = append(mount.Opts, DiskDiscard)
+				}
+
 				f, err := d.localSourceOpen(mount.DevPath)
 				if err != nil {
 					return nil, err
@@ -1381,7 +1563,7 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 
 				// Detect ISO files to set correct FSType before DevPath is encoded below.
 				// This is very important to support Windows ISO images (amongst other).
-				if strings.HasSuffix(mount.DevPath, ".iso") {
+				if isCDROM || strings.HasSuffix(mount.DevPath, ".iso") {
 					mount.FSType = "iso9660"
 				}
 
@@ -1446,6 +1628,14 @@ func (d *disk) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 		oldRootDiskDeviceMigrationSize := oldDevices[oldRootDiskDeviceKey]["size.state"]
 		newRootDiskDeviceMigrationSize := expandedDevices[newRootDiskDeviceKey]["size.state"]
 
+		// Running virtual machines can only have their root disk grown.
+		if d.inst.Type() == instancetype.VM && isRunning && newRootDiskDeviceSize != oldRootDiskDeviceSize {
+			err := diskCheckGrow(oldRootDiskDeviceSize, newRootDiskDeviceSize)
+			if err != nil {
+				return fmt.Errorf("The root disk of a running virtual machine can only be grown: %w", err)
+			}
+		}
+
 		// Apply disk quota changes.
 		if newRootDiskDeviceSize != oldRootDiskDeviceSize || oldRootDiskDeviceMigrationSize != newRootDiskDeviceMigrationSize {
 			// Remove any outstanding volatile apply_quota key if applying a new quota.
@@ -1492,8 +1682,25 @@ func (d *disk) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 		}
 	}
 
-	// Only apply IO limits if instance is running.
-	if isRunning {
+	// Replace the medium of removable drives of running virtual machines.
+	oldConfig := oldDevices[d.name]
+	if isRunning && d.inst.Type() == instancetype.VM && d.config["media"] == "cdrom" && (oldConfig["source"] != d.config["source"] || oldConfig["pool"] != d.config["pool"]) {
+		err := d.changeMedia(oldConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remount storage volumes of running instances with their new mount options.
+	if isRunning && d.config["pool"] != "" && d.config["path"] != "/" && oldConfig["raw.mount.options"] != d.config["raw.mount.options"] {
+		err := d.remount()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Only apply IO limits if instance is running and the drive isn't empty.
+	if isRunning && (d.config["media"] != "cdrom" || d.config["source"] != "") {
 		runConf := deviceConfig.RunConfig{}
 
 		if d.inst.Type() == instancetype.Container {
@@ -1535,6 +1742,91 @@ func (d *disk) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 	return nil
 }
 
+// remount applies the mount options to the storage volume of a running instance.
+// Containers get their own mount of the volume remounted, virtual machines the host side mount they use.
+func (d *disk) remount() error {
+	options := util.SplitNTrimSpace(d.config["raw.mount.options"], ",", -1, true)
+	if util.IsTrue(d.config["readonly"]) {
+		options = append(options, "ro")
+	}
+
+	if d.inst.Type() == instancetype.Container {
+		runConf := deviceConfig.RunConfig{}
+		runConf.Mounts = []deviceConfig.MountEntryItem{
+			{
+				DevName:    d.name,
+				TargetPath: strings.TrimPrefix(d.config["path"], "/"),
+				Opts:       options,
+				Remount:    true,
+			},
+		}
+
+		return d.inst.DeviceEventHandler(&runConf)
+	}
+
+	devPath := d.getDevicePath(d.name, d.config)
+	if !linux.IsMountPoint(devPath) {
+		return nil
+	}
+
+	return DiskMountRemount(devPath, options)
+}
+
+// changeMedia replaces the medium of the removable drive of a running virtual machine.
+func (d *disk) changeMedia(oldConfig deviceConfig.Device) error {
+	runConf, err := d.startVM()
+	if err != nil {
+		return err
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if runConf.Revert != nil {
+		reverter.Add(runConf.Revert)
+	}
+
+	for i := range runConf.Mounts {
+		runConf.Mounts[i].NewMedium = true
+	}
+
+	err = d.inst.DeviceEventHandler(&deviceConfig.RunConfig{Mounts: runConf.Mounts})
+	if err != nil {
+		return err
+	}
+
+	reverter.Success()
+
+	for _, hook := range runConf.PostHooks {
+		err := hook()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Release the custom volume which held the previous medium.
+	if oldConfig["pool"] != "" && oldConfig["source"] != "" {
+		pool, err := storagePools.LoadByName(d.state, oldConfig["pool"])
+		if err != nil {
+			return fmt.Errorf("Failed to get storage pool %q: %w", oldConfig["pool"], err)
+		}
+
+		storageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, d.inst.Project().Name, db.StoragePoolVolumeTypeCustom)
+		if err != nil {
+			return err
+		}
+
+		volName, _, _ := strings.Cut(oldConfig["source"], "/")
+
+		_, err = pool.UnmountCustomVolume(storageProjectName, volName, nil)
+		if err != nil && !errors.Is(err, storageDrivers.ErrInUse) {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // applyDeferredQuota attempts to apply the deferred quota specified in the volatile "apply_quota" key if set.
 // If successfully applies new quota then removes the volatile "apply_quota" key.
 func (d *disk) applyDeferredQuota() error {
@@ -2151,7 +2443,7 @@ func (d *disk) Stop() (*deviceConfig.RunConfig, error) {
 
 	// The disk device doesn't exist do nothing.
 	if !util.PathExists(devPath) {
-		return nil, nil
+		return nil, d.restoreIOTuning()
 	}
 
 	// Request an unmount of the device inside the instance.
@@ -2210,9 +2502,159 @@ func (d *disk) postStop() error {
 		}
 	}
 
+	err = d.restoreIOTuning()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// ioTuningQueuePath returns the host request queue directory to apply io.scheduler and io.readahead to.
+// The requested scheduler is checked against the ones available for the device.
+func (d *disk) ioTuningQueuePath() (string, error) {
+	queuePath, err := diskBlockDevQueuePath("/sys", d.config["source"])
+	if err != nil {
+		return "", fmt.Errorf("Can't tune I/O of disk %q: %w", d.name, err)
+	}
+
+	if d.config["io.scheduler"] != "" {
+		_, available, err := diskQueueSchedulers(queuePath)
+		if err != nil {
+			return "", fmt.Errorf("Failed getting I/O schedulers of %q: %w", d.config["source"], err)
+		}
+
+		if !slices.Contains(available, d.config["io.scheduler"]) {
+			return "", fmt.Errorf("I/O scheduler %q isn't available for %q (available: %s)", d.config["io.scheduler"], d.config["source"], strings.Join(available, ", "))
+		}
+	}
+
+	return queuePath, nil
+}
+
+// ioTuningOwner returns the name identifying this disk as the one tuning the I/O of its host block device.
+func (d *disk) ioTuningOwner() string {
+	return fmt.Sprintf("disk %q of instance %q in project %q", d.name, d.inst.Name(), d.inst.Project().Name)
+}
+
+// applyIOTuning sets io.scheduler and io.readahead on the host block device, recording the previous values
+// in volatile keys so they can be restored when the device stops.
+func (d *disk) applyIOTuning() error {
+	if d.config["io.scheduler"] == "" && d.config["io.readahead"] == "" {
+		return nil
+	}
+
+	// Serialize with the other disks, which may share the same host block device.
+	diskIOTuningMu.Lock()
+	defer diskIOTuningMu.Unlock()
+
+	queuePath, err := d.ioTuningQueuePath()
+	if err != nil {
+		return err
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// Only one disk at a time can tune a block device, or the values restored on stop would be mixed up.
+	err = diskIOTuningClaim(queuePath, d.ioTuningOwner())
+	if err != nil {
+		return fmt.Errorf("Can't tune I/O of disk %q: %w", d.name, err)
+	}
+
+	reverter.Add(func() { diskIOTuningRelease(queuePath, d.ioTuningOwner()) })
+
+	// Only record the original values once so that they survive an unclean stop.
+	v := d.volatileGet()
+	saved := map[string]string{}
+
+	if d.config["io.scheduler"] != "" {
+		current, _, err := diskQueueSchedulers(queuePath)
+		if err != nil {
+			return err
+		}
+
+		if v["last_state.io.scheduler"] == "" {
+			saved["last_state.io.scheduler"] = current
+		}
+	}
+
+	if d.config["io.readahead"] != "" {
+		current, err := diskQueueReadahead(queuePath)
+		if err != nil {
+			return err
+		}
+
+		if v["last_state.io.readahead"] == "" {
+			saved["last_state.io.readahead"] = strconv.FormatUint(current, 10)
+		}
+	}
+
+	if len(saved) > 0 {
+		err = d.volatileSet(saved)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.config["io.scheduler"] != "" {
+		err = os.WriteFile(filepath.Join(queuePath, "scheduler"), []byte(d.config["io.scheduler"]), 0)
+		if err != nil {
+			return fmt.Errorf("Failed setting I/O scheduler of %q: %w", d.config["source"], err)
+		}
+	}
+
+	if d.config["io.readahead"] != "" {
+		readahead, err := units.ParseByteSizeString(d.config["io.readahead"])
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filepath.Join(queuePath, "read_ahead_kb"), []byte(strconv.FormatInt(readahead/1024, 10)), 0)
+		if err != nil {
+			return fmt.Errorf("Failed setting read-ahead of %q: %w", d.config["source"], err)
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// restoreIOTuning restores the I/O scheduler and read-ahead recorded by applyIOTuning.
+func (d *disk) restoreIOTuning() error {
+	v := d.volatileGet()
+	if v["last_state.io.scheduler"] == "" && v["last_state.io.readahead"] == "" {
+		return nil
+	}
+
+	diskIOTuningMu.Lock()
+	defer diskIOTuningMu.Unlock()
+
+	queuePath, err := diskBlockDevQueuePath("/sys", d.config["source"])
+	if err != nil {
+		d.logger.Warn("Failed restoring I/O tuning of disk", logger.Ctx{"err": err})
+	} else {
+		defer diskIOTuningRelease(queuePath, d.ioTuningOwner())
+
+		if v["last_state.io.scheduler"] != "" {
+			err = os.WriteFile(filepath.Join(queuePath, "scheduler"), []byte(v["last_state.io.scheduler"]), 0)
+			if err != nil {
+				d.logger.Warn("Failed restoring I/O scheduler", logger.Ctx{"scheduler": v["last_state.io.scheduler"], "err": err})
+			}
+		}
+
+		if v["last_state.io.readahead"] != "" {
+			err = os.WriteFile(filepath.Join(queuePath, "read_ahead_kb"), []byte(v["last_state.io.readahead"]), 0)
+			if err != nil {
+				d.logger.Warn("Failed restoring read-ahead", logger.Ctx{"readahead": v["last_state.io.readahead"], "err": err})
+			}
+		}
+	}
+
+	return d.volatileSet(map[string]string{"last_state.io.scheduler": "", "last_state.io.readahead": ""})
+}
+
 // getDiskLimits calculates Block I/O limits.
 func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 	result := map[string]diskBlockLimit{}
@@ -2738,7 +3180,7 @@ func (d *disk) generateVMConfigDrive() (string, error) {
 	metaData := fmt.Sprintf(`instance-id: %s
 local-hostname: %s
 %s
-`, d.inst.Name(), d.inst.Name(), instanceConfig["user.meta-data"])
+`, d.inst.Name(), instance.Hostname(d.inst.Name(), instanceConfig), instanceConfig["user.meta-data"])
 
 	err = os.WriteFile(filepath.Join(scratchDir, "meta-data"), []byte(metaData), 0o400)
 	if err != nil {
@@ -22,6 +22,7 @@ func gpuValidationRules(requiredFields []string, optionalFields []string) map[st
 		"mig.ci":    validate.IsUint8,
 		"mig.uuid":  gpuValidMigUUID,
 		"mdev":      validate.IsAny,
+		"gpu.numa":  validate.IsUint32,
 	}
 
 	validators := map[string]func(value string) error{}
@@ -69,10 +70,11 @@ func gpuValidationRules(requiredFields []string, optionalFields []string) map[st
 }
 
 // Check if the device matches the given GPU card.
-// It matches based on vendorid, pci, productid or id setting of the device.
+// It matches based on vendorid, pci, productid, id or gpu.numa setting of the device.
 func gpuSelected(device config.Device, gpu api.ResourcesGPUCard) bool {
 	return !((device["vendorid"] != "" && gpu.VendorID != device["vendorid"]) ||
 		(device["pci"] != "" && gpu.PCIAddress != device["pci"]) ||
 		(device["productid"] != "" && gpu.ProductID != device["productid"]) ||
-		(device["id"] != "" && (gpu.DRM == nil || fmt.Sprintf("%d", gpu.DRM.ID) != device["id"])))
+		(device["id"] != "" && (gpu.DRM == nil || fmt.Sprintf("%d", gpu.DRM.ID) != device["id"])) ||
+		(device["gpu.numa"] != "" && fmt.Sprintf("%d", gpu.NUMANode) != device["gpu.numa"]))
 }
@@ -1,23 +1,30 @@
 package device
 
 import (
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
 	"golang.org/x/sys/unix"
 
 	"github.com/lxc/incus/v6/internal/linux"
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	pcidev "github.com/lxc/incus/v6/internal/server/device/pci"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/resources"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/util"
 )
 
@@ -72,6 +79,14 @@ func (d *gpuPhysical) validateConfig(instConf instance.ConfigReader) error {
 		//  required: no
 		//  shortdesc: The PCI address of the GPU device
 		"pci",
+
+		// gendoc:generate(entity=devices, group=gpu_physical, key=gpu.numa)
+		// Only GPUs on that NUMA node are selected.
+		// ---
+		//  type: integer
+		//  required: no
+		//  shortdesc: The NUMA node of the GPU device
+		"gpu.numa",
 	}
 
 	if instConf.Type() == instancetype.Container || instConf.Type() == instancetype.Any {
@@ -269,42 +284,32 @@ func (d *gpuPhysical) startVM() (*deviceConfig.RunConfig, error) {
 	}
 
 	saveData := make(map[string]string)
-	var pciAddress string
-
-	for _, gpu := range gpus.Cards {
-		// Skip any cards that are not selected.
-		if !gpuSelected(d.Config(), gpu) {
-			continue
-		}
 
-		// Check for existing running processes tied to the GPU.
-		// Failing early here in case of attached running processes to the card
-		// avoids a blocking call to os.WriteFile() when unbinding the device.
-		if gpu.Nvidia != nil && gpu.Nvidia.CardName != "" && util.PathExists(filepath.Join("/dev", gpu.Nvidia.CardName)) {
-			devPath := filepath.Join("/dev", gpu.Nvidia.CardName)
-			runningProcs, err := checkAttachedRunningProcesses(devPath)
-			if err != nil {
-				return nil, err
-			}
+	gpu, err := d.selectCard(gpus.Cards)
+	if err != nil {
+		return nil, err
+	}
 
-			if len(runningProcs) > 0 {
-				return nil, fmt.Errorf(
-					"Cannot use device %q, %d processes are still attached to it:\n\t%s",
-					devPath, len(runningProcs), strings.Join(runningProcs, "\n\t"),
-				)
-			}
+	// Check for existing running processes tied to the GPU.
+	// Failing early here in case of attached running processes to the card
+	// avoids a blocking call to os.WriteFile() when unbinding the device.
+	if gpu.Nvidia != nil && gpu.Nvidia.CardName != "" && util.PathExists(filepath.Join("/dev", gpu.Nvidia.CardName)) {
+		devPath := filepath.Join("/dev", gpu.Nvidia.CardName)
+		runningProcs, err := checkAttachedRunningProcesses(devPath)
+		if err != nil {
+			return nil, err
 		}
 
-		if pciAddress != "" {
-			return nil, fmt.Errorf("VMs cannot match multiple GPUs per device")
+		if len(runningProcs) > 0 {
+			return nil, fmt.Errorf(
+				"Cannot use device %q, %d processes are still attached to it:\n\t%s",
+				devPath, len(runningProcs), strings.Join(runningProcs, "\n\t"),
+			)
 		}
-
-		pciAddress = gpu.PCIAddress
 	}
 
-	if pciAddress == "" {
-		return nil, fmt.Errorf("Failed to detect requested GPU device")
-	}
+	pciAddress := gpu.PCIAddress
+	saveData["gpu.pci"] = pciAddress
 
 	// Make sure that vfio-pci is loaded.
 	err = linux.LoadModule("vfio-pci")
@@ -341,6 +346,103 @@ func (d *gpuPhysical) startVM() (*deviceConfig.RunConfig, error) {
 	return &runConf, nil
 }
 
+// selectCard picks the GPU to pass to the VM among the cards matching the device.
+// Cards in use by other instances or devices are skipped. Among the remaining ones, cards on the NUMA nodes the instance is
+// restricted to are preferred, then the card selected the last time the instance started, then the lowest PCI address.
+func (d *gpuPhysical) selectCard(cards []api.ResourcesGPUCard) (*api.ResourcesGPUCard, error) {
+	candidates := []api.ResourcesGPUCard{}
+	for _, gpu := range cards {
+		if gpuSelected(d.Config(), gpu) {
+			candidates = append(candidates, gpu)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("Failed to detect requested GPU device")
+	}
+
+	// A specific card was requested.
+	if d.config["pci"] != "" || d.config["id"] != "" {
+		if len(candidates) > 1 {
+			return nil, fmt.Errorf("VMs cannot match multiple GPUs per device")
+		}
+
+		return &candidates[0], nil
+	}
+
+	// Skip the cards passed to other instances or devices.
+	reserved, err := gpuPhysicalGetCardsInUse(d.state, d.inst, d.name)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates = slices.DeleteFunc(candidates, func(gpu api.ResourcesGPUCard) bool {
+		_, found := reserved[gpu.PCIAddress]
+		return found
+	})
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("All the matching GPUs are already in use")
+	}
+
+	numaNodes, err := gpuInstanceNUMANodes(d.inst.ExpandedConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	lastAddress := d.volatileGet()["gpu.pci"]
+
+	// rank orders the cards by preference, lowest first.
+	rank := func(gpu api.ResourcesGPUCard) int {
+		r := 0
+		if numaNodes != nil && !slices.Contains(numaNodes, int64(gpu.NUMANode)) {
+			r += 2
+		}
+
+		if gpu.PCIAddress != lastAddress {
+			r++
+		}
+
+		return r
+	}
+
+	slices.SortStableFunc(candidates, func(a api.ResourcesGPUCard, b api.ResourcesGPUCard) int {
+		return cmp.Or(cmp.Compare(rank(a), rank(b)), strings.Compare(a.PCIAddress, b.PCIAddress))
+	})
+
+	return &candidates[0], nil
+}
+
+// gpuPhysicalGetCardsInUse returns the PCI addresses of the GPUs passed to instances on the local member, other
+// than the ones passed through the given device.
+func gpuPhysicalGetCardsInUse(s *state.State, inst instance.Instance, deviceName string) (map[string]struct{}, error) {
+	reserved := map[string]struct{}{}
+
+	filter := dbCluster.InstanceFilter{Node: &s.ServerName}
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+			self := dbInst.Project == inst.Project().Name && dbInst.Name == inst.Name()
+
+			for key, value := range dbInst.Config {
+				if self && key == "volatile."+deviceName+".last_state.pci.slot.name" {
+					continue
+				}
+
+				if value != "" && strings.HasPrefix(key, "volatile.") && strings.HasSuffix(key, ".last_state.pci.slot.name") {
+					reserved[value] = struct{}{}
+				}
+			}
+
+			return nil
+		}, filter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reserved, nil
+}
+
 // pciDeviceDriverOverrideIOMMU overrides all functions in the specified device's IOMMU group (if exists) that
 // are functions of the device. If IOMMU group doesn't exist, only the device itself is overridden.
 // If restore argument is true, then IOMMU VF devices related to the main device have their driver override cleared
@@ -11,6 +11,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/ip"
 	"github.com/lxc/incus/v6/internal/server/resources"
+	"github.com/lxc/incus/v6/shared/revert"
 	"github.com/lxc/incus/v6/shared/util"
 )
 
@@ -87,6 +88,10 @@ func (d *infinibandPhysical) validateEnvironment() error {
 		return fmt.Errorf("Parent device '%s' doesn't exist", d.config["parent"])
 	}
 
+	if util.PathExists(fmt.Sprintf("/sys/class/net/%s/bonding", d.config["parent"])) {
+		return fmt.Errorf("Parent device '%s' is a bond, bonds are only supported with nictype=sriov", d.config["parent"])
+	}
+
 	return nil
 }
 
@@ -97,6 +102,9 @@ func (d *infinibandPhysical) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	reverter := revert.New()
+	defer reverter.Fail()
+
 	saveData := make(map[string]string)
 
 	// pciIOMMUGroup, used for VM physical passthrough.
@@ -134,6 +142,8 @@ func (d *infinibandPhysical) Start() (*deviceConfig.RunConfig, error) {
 			return nil, err
 		}
 
+		reverter.Add(func() { _ = networkRestorePhysicalNIC(saveData["host_name"], saveData) })
+
 		// Set the MAC address.
 		if d.config["hwaddr"] != "" {
 			err := infinibandSetDevMAC(saveData["host_name"], d.config["hwaddr"])
@@ -157,6 +167,7 @@ func (d *infinibandPhysical) Start() (*deviceConfig.RunConfig, error) {
 		}
 
 		// Configure runConf with infiniband setup instructions.
+		reverter.Add(func() { _ = unixDeviceDeleteFiles(d.state, d.inst.DevicesPath(), IBDevPrefix, d.name, "") })
 		err = infinibandAddDevices(d.state, d.inst.DevicesPath(), d.name, ibDev, &runConf)
 		if err != nil {
 			return nil, err
@@ -207,6 +218,8 @@ func (d *infinibandPhysical) Start() (*deviceConfig.RunConfig, error) {
 			}...)
 	}
 
+	reverter.Success()
+
 	return &runConf, nil
 }
 
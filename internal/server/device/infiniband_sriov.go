@@ -1,6 +1,7 @@
 package device
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,14 +9,20 @@ import (
 	"strings"
 
 	"github.com/lxc/incus/v6/internal/linux"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	pcidev "github.com/lxc/incus/v6/internal/server/device/pci"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/ip"
 	"github.com/lxc/incus/v6/internal/server/network"
+	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/resources"
+	"github.com/lxc/incus/v6/internal/server/warnings"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
 	"github.com/lxc/incus/v6/shared/revert"
 	"github.com/lxc/incus/v6/shared/util"
 )
@@ -31,6 +38,16 @@ func (d *infinibandSRIOV) validateConfig(instConf instance.ConfigReader) error {
 		"name",
 		"mtu",
 		"hwaddr",
+
+		// gendoc:generate(entity=devices, group=infiniband, key=infiniband.pkey)
+		// The partition key must be configured on the parent port. It can be given with or without the full membership bit (`0x8000`).
+		// Only supported with `nictype=sriov`.
+		// ---
+		//  type: string
+		//  required: no
+		//  defaultdesc: virtual function default
+		//  shortdesc: The partition key (P_Key) of the virtual function in hexadecimal, e.g. `0x8001`
+		"infiniband.pkey",
 	}
 
 	rules := nicValidationRules(requiredFields, optionalFields, instConf)
@@ -42,6 +59,14 @@ func (d *infinibandSRIOV) validateConfig(instConf instance.ConfigReader) error {
 		return infinibandValidMAC(value)
 	}
 
+	rules["infiniband.pkey"] = func(value string) error {
+		if value == "" {
+			return nil
+		}
+
+		return infinibandValidPKey(value)
+	}
+
 	err := d.config.Validate(rules)
 	if err != nil {
 		return err
@@ -60,54 +85,54 @@ func (d *infinibandSRIOV) validateEnvironment() error {
 		return fmt.Errorf("Parent device '%s' doesn't exist", d.config["parent"])
 	}
 
+	// Bond parents have their slaves validated when allocating a virtual function.
+	isBond := util.PathExists(fmt.Sprintf("/sys/class/net/%s/bonding", d.config["parent"]))
+
+	if d.config["infiniband.pkey"] != "" && !isBond {
+		parentDevicePath := fmt.Sprintf("/sys/class/net/%s/device", d.config["parent"])
+		if !util.PathExists(filepath.Join(parentDevicePath, "sriov_totalvfs")) && !util.PathExists(filepath.Join(parentDevicePath, "physfn")) {
+			return fmt.Errorf("Parent device '%s' isn't SR-IOV capable, infiniband.pkey can't be set", d.config["parent"])
+		}
+	}
+
 	return nil
 }
 
 func (d *infinibandSRIOV) startContainer() (*deviceConfig.RunConfig, error) {
+	reverter := revert.New()
+	defer reverter.Fail()
+
 	saveData := make(map[string]string)
 
-	// Load network interface info.
-	nics, err := resources.GetNetwork()
+	// Find and claim a free VF exclusively, this may be a live attach racing with other instances.
+	network.SRIOVVirtualFunctionMutex.Lock()
+	vfDev, vfParent, err := d.findFreeVFPort()
 	if err != nil {
+		network.SRIOVVirtualFunctionMutex.Unlock()
 		return nil, err
 	}
 
-	// Filter the network interfaces to just infiniband devices related to parent.
-	ibDevs := infinibandDevices(nics, d.config["parent"])
-
-	// We don't count the parent as an available VF.
-	delete(ibDevs, d.config["parent"])
+	saveData["host_name"] = vfDev.ID
+	saveData["last_state.vf.parent"] = vfParent
 
-	// Load any interfaces already allocated to other devices.
-	reservedDevices, err := network.SRIOVGetHostDevicesInUse(d.state)
+	// Record hwaddr and mtu before potentially modifying them.
+	err = networkSnapshotPhysicalNIC(saveData["host_name"], saveData)
 	if err != nil {
+		network.SRIOVVirtualFunctionMutex.Unlock()
 		return nil, err
 	}
 
-	// Remove reserved devices from available list.
-	for k := range reservedDevices {
-		delete(ibDevs, k)
-	}
-
-	if len(ibDevs) < 1 {
-		return nil, fmt.Errorf("All virtual functions on parent device are already in use")
-	}
-
-	// Get first VF device that is free.
-	var vfDev *api.ResourcesNetworkCardPort
-	for _, v := range ibDevs {
-		vfDev = v
-		break
-	}
-
-	saveData["host_name"] = vfDev.ID
-
-	// Record hwaddr and mtu before potentially modifying them.
-	err = networkSnapshotPhysicalNIC(saveData["host_name"], saveData)
+	// Mark the VF as in use by this device.
+	err = d.volatileSet(saveData)
 	if err != nil {
+		network.SRIOVVirtualFunctionMutex.Unlock()
 		return nil, err
 	}
 
+	network.SRIOVVirtualFunctionMutex.Unlock()
+
+	reverter.Add(func() { _ = d.postStop() })
+
 	// Set the MAC address.
 	if d.config["hwaddr"] != "" {
 		err := infinibandSetDevMAC(saveData["host_name"], d.config["hwaddr"])
@@ -130,6 +155,19 @@ func (d *infinibandSRIOV) startContainer() (*deviceConfig.RunConfig, error) {
 		}
 	}
 
+	// Set the partition key.
+	if d.config["infiniband.pkey"] != "" {
+		vfPCIAddress, err := infinibandVFPCIAddress("/sys", saveData["host_name"])
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.setPKey(vfParent, vfPCIAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	runConf := deviceConfig.RunConfig{}
 
 	// Configure runConf with infiniband setup instructions.
@@ -138,11 +176,6 @@ func (d *infinibandSRIOV) startContainer() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
-	err = d.volatileSet(saveData)
-	if err != nil {
-		return nil, err
-	}
-
 	runConf.NetworkInterface = []deviceConfig.RunConfigItem{
 		{Key: "type", Value: "phys"},
 		{Key: "name", Value: d.config["name"]},
@@ -150,9 +183,75 @@ func (d *infinibandSRIOV) startContainer() (*deviceConfig.RunConfig, error) {
 		{Key: "link", Value: saveData["host_name"]},
 	}
 
+	reverter.Success()
+
 	return &runConf, nil
 }
 
+// findFreeVFPort returns an infiniband virtual function on the parent that isn't used by another device
+// as well as the physical interface it belongs to (one of the slaves when the parent is a bond).
+func (d *infinibandSRIOV) findFreeVFPort() (*api.ResourcesNetworkCardPort, string, error) {
+	// Load network interface info.
+	nics, err := resources.GetNetwork()
+	if err != nil {
+		return nil, "", err
+	}
+
+	slaves, err := infinibandBondSlaves("/sys", d.config["parent"])
+	if err != nil {
+		return nil, "", err
+	}
+
+	var bondCards map[string]*api.ResourcesNetworkCard
+	if len(slaves) > 0 {
+		bondCards, err = infinibandBondCards(nics, d.config["parent"], slaves)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// Filter the network interfaces to just infiniband devices related to parent.
+	ibDevs := infinibandDevices(nics, d.config["parent"], slaves...)
+
+	// We don't count the parent as an available VF.
+	delete(ibDevs, d.config["parent"])
+
+	// Load any interfaces already allocated to other devices.
+	reservedDevices, err := network.SRIOVGetHostDevicesInUse(d.state)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Remove reserved devices from available list.
+	for k := range reservedDevices {
+		delete(ibDevs, k)
+	}
+
+	if len(ibDevs) < 1 {
+		return nil, "", fmt.Errorf("All virtual functions on parent device are already in use")
+	}
+
+	// Get a free VF device, preferring the ones with an active link so that a bond parent keeps
+	// working while one of its slaves is down.
+	var vfDev *api.ResourcesNetworkCardPort
+	for _, v := range ibDevs {
+		if vfDev == nil || (v.LinkDetected && !vfDev.LinkDetected) {
+			vfDev = v
+		}
+	}
+
+	if bondCards == nil {
+		return vfDev, d.config["parent"], nil
+	}
+
+	vfParent := infinibandVFParent(bondCards, vfDev)
+	if vfParent == "" {
+		return nil, "", fmt.Errorf("Failed finding bond slave of virtual function %q", vfDev.ID)
+	}
+
+	return vfDev, vfParent, nil
+}
+
 func (d *infinibandSRIOV) startVM() (*deviceConfig.RunConfig, error) {
 	saveData := make(map[string]string)
 
@@ -167,37 +266,76 @@ func (d *infinibandSRIOV) startVM() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
-	var parentPCIAddress string
+	// A bond parent allocates the virtual function from one of its slaves, starting with the active one.
+	parents := []string{d.config["parent"]}
 
-	for _, card := range nics.Cards {
-		found := false
+	slaves, err := infinibandBondSlaves("/sys", d.config["parent"])
+	if err != nil {
+		return nil, err
+	}
 
-		for _, port := range card.Ports {
-			if port.ID == d.config["parent"] {
-				found = true
-				break
-			}
+	if len(slaves) > 0 {
+		bondCards, err := infinibandBondCards(nics, d.config["parent"], slaves)
+		if err != nil {
+			return nil, err
 		}
 
-		if !found {
-			continue
+		// Skip the slaves which are down unless all of them are.
+		parents = nil
+		for _, slave := range slaves {
+			for _, port := range bondCards[slave].Ports {
+				if port.ID == slave && port.LinkDetected {
+					parents = append(parents, slave)
+				}
+			}
 		}
 
-		parentPCIAddress = card.PCIAddress
-		break
+		if len(parents) == 0 {
+			parents = slaves
+		}
 	}
 
-	// Get PCI information about the GPU device.
-	devicePath := filepath.Join("/sys/bus/pci/devices", parentPCIAddress)
+	var parentPCIAddress string
 
-	pciParentDev, err := pcidev.ParseUeventFile(filepath.Join(devicePath, "uevent"))
-	if err != nil {
-		return nil, fmt.Errorf("Failed to get PCI device info for %q: %w", parentPCIAddress, err)
-	}
+	vfID := -1
+	for _, parent := range parents {
+		parentPCIAddress = ""
 
-	vfID, err := d.findFreeVirtualFunction(pciParentDev)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to find free virtual function: %w", err)
+		for _, card := range nics.Cards {
+			found := false
+
+			for _, port := range card.Ports {
+				if port.ID == parent {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				continue
+			}
+
+			parentPCIAddress = card.PCIAddress
+			break
+		}
+
+		// Get PCI information about the GPU device.
+		devicePath := filepath.Join("/sys/bus/pci/devices", parentPCIAddress)
+
+		pciParentDev, err := pcidev.ParseUeventFile(filepath.Join(devicePath, "uevent"))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get PCI device info for %q: %w", parentPCIAddress, err)
+		}
+
+		vfID, err = d.findFreeVirtualFunction(pciParentDev)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to find free virtual function: %w", err)
+		}
+
+		if vfID != -1 {
+			saveData["last_state.vf.parent"] = parent
+			break
+		}
 	}
 
 	if vfID == -1 {
@@ -209,6 +347,16 @@ func (d *infinibandSRIOV) startVM() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// Set the partition key.
+	if d.config["infiniband.pkey"] != "" {
+		oldIndex, err := infinibandSetVFPKey("/sys", saveData["last_state.vf.parent"], vfPCIDev.SlotName, d.config["infiniband.pkey"])
+		if err != nil {
+			return nil, err
+		}
+
+		saveData["last_state.pkey.index"] = oldIndex
+	}
+
 	pciIOMMUGroup, err := pcidev.DeviceIOMMUGroup(vfPCIDev.SlotName)
 	if err != nil {
 		return nil, err
@@ -243,11 +391,77 @@ func (d *infinibandSRIOV) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	var runConf *deviceConfig.RunConfig
 	if d.inst.Type() == instancetype.VM {
-		return d.startVM()
+		runConf, err = d.startVM()
+	} else {
+		runConf, err = d.startContainer()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	d.bondWatch()
+
+	return runConf, nil
+}
+
+// Register sets up anything needed on startup.
+func (d *infinibandSRIOV) Register() error {
+	d.bondWatch()
+
+	return nil
+}
+
+// bondWatchKey returns the key identifying the device among the users of its bond parent.
+func (d *infinibandSRIOV) bondWatchKey() string {
+	return project.Instance(d.inst.Project().Name, d.inst.Name()) + "/" + d.name
+}
+
+// bondWatch tracks the active slave of a bond parent while the device uses a virtual function of one of its slaves.
+// As the virtual function can't follow a failover, a warning is raised while its slave isn't the active one.
+func (d *infinibandSRIOV) bondWatch() {
+	vfParent := d.volatileGet()["last_state.vf.parent"]
+	if vfParent == "" || vfParent == d.config["parent"] {
+		return
 	}
 
-	return d.startContainer()
+	s := d.state
+	projectName := d.inst.Project().Name
+	instanceID := d.inst.ID()
+	l := d.logger
+
+	infinibandBondWatch("/sys", d.config["parent"], d.bondWatchKey(), func(activeSlave string) {
+		if activeSlave == "" || activeSlave == vfParent {
+			err := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, projectName, warningtype.InfinibandBondFailover, cluster.TypeInstance, instanceID)
+			if err != nil {
+				l.Warn("Failed to resolve warning", logger.Ctx{"err": err})
+			}
+
+			return
+		}
+
+		msg := fmt.Sprintf("Virtual function is on bond slave %q while %q is now active, restart the instance to move it to the active slave", vfParent, activeSlave)
+		l.Warn(msg)
+
+		err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarningLocalNode(ctx, projectName, cluster.TypeInstance, instanceID, warningtype.InfinibandBondFailover, msg)
+		})
+		if err != nil {
+			l.Warn("Failed to create warning", logger.Ctx{"err": err})
+		}
+	})
+}
+
+// bondUnwatch stops tracking the active slave of a bond parent.
+func (d *infinibandSRIOV) bondUnwatch() {
+	infinibandBondUnwatch("/sys", d.config["parent"], d.bondWatchKey())
+
+	err := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(d.state.DB.Cluster, d.inst.Project().Name, warningtype.InfinibandBondFailover, cluster.TypeInstance, d.inst.ID())
+	if err != nil {
+		d.logger.Warn("Failed to resolve warning", logger.Ctx{"err": err})
+	}
 }
 
 // Stop is run when the device is removed from the instance.
@@ -278,9 +492,15 @@ func (d *infinibandSRIOV) postStop() error {
 			"last_state.pci.slot.name": "",
 			"last_state.pci.driver":    "",
 			"last_state.pci.parent":    "",
+			"last_state.pkey.index":    "",
+			"last_state.vf.parent":     "",
 		})
 	}()
 
+	if d.volatileGet()["last_state.vf.parent"] != "" {
+		d.bondUnwatch()
+	}
+
 	if d.inst.Type() == instancetype.Container {
 		// Remove infiniband host files for this device.
 		err := unixDeviceDeleteFiles(d.state, d.inst.DevicesPath(), IBDevPrefix, d.name, "")
@@ -298,6 +518,14 @@ func (d *infinibandSRIOV) postStop() error {
 		}
 	}
 
+	// Restore the partition key.
+	if v["last_state.pkey.index"] != "" {
+		err := d.restorePKey(v)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Unbind from vfio-pci and bind back to host driver.
 	if d.inst.Type() == instancetype.VM && v["last_state.pci.slot.name"] != "" {
 		pciDev := pcidev.Device{
@@ -320,6 +548,45 @@ func (d *infinibandSRIOV) postStop() error {
 	return nil
 }
 
+// setPKey maps the configured partition key to the virtual function and records the previous mapping
+// into volatile for restoration on detach.
+func (d *infinibandSRIOV) setPKey(vfParent string, vfPCIAddress string) error {
+	oldIndex, err := infinibandSetVFPKey("/sys", vfParent, vfPCIAddress, d.config["infiniband.pkey"])
+	if err != nil {
+		return err
+	}
+
+	return d.volatileSet(map[string]string{"last_state.pkey.index": oldIndex})
+}
+
+// restorePKey restores the partition key mapping of the virtual function recorded in volatile.
+func (d *infinibandSRIOV) restorePKey(volatile map[string]string) error {
+	var vfPCIAddress string
+
+	if d.inst.Type() == instancetype.VM {
+		vfPCIDev, err := d.getVFDevicePCISlot(volatile["last_state.pci.parent"], volatile["last_state.vf.id"])
+		if err != nil {
+			return err
+		}
+
+		vfPCIAddress = vfPCIDev.SlotName
+	} else {
+		var err error
+
+		vfPCIAddress, err = infinibandVFPCIAddress("/sys", volatile["host_name"])
+		if err != nil {
+			return err
+		}
+	}
+
+	vfParent := volatile["last_state.vf.parent"]
+	if vfParent == "" {
+		vfParent = d.config["parent"]
+	}
+
+	return infinibandRestoreVFPKey("/sys", vfParent, vfPCIAddress, volatile["last_state.pkey.index"])
+}
+
 // setupSriovParent configures a SR-IOV virtual function (VF) device on parent and stores original properties of
 // the physical device into voltatile for restoration on detach. Returns VF PCI device info.
 func (d *infinibandSRIOV) setupSriovParent(parentPCIAddress string, vfID int, volatile map[string]string) (pcidev.Device, error) {
@@ -1,14 +1,18 @@
 package device
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"slices"
 	"strings"
 
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/network/acl"
+	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
@@ -56,6 +60,7 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		"security.promiscuous":                 validate.Optional(validate.IsBool),
 		"mode":                                 validate.Optional(validate.IsOneOf("bridge", "vepa", "passthru", "private")),
 		"io.bus":                               validate.Optional(func(_ string) error { return nicCheckIsVM(instConf) }, validate.IsOneOf("virtio", "usb")),
+		"dns.nameservers":                      validate.Optional(validate.IsListOf(validate.IsNetworkAddress)),
 	}
 
 	validators := map[string]func(value string) error{}
@@ -136,6 +141,27 @@ func nicCheckDNSNameConflict(instNameA string, instNameB string) bool {
 	return strings.EqualFold(instNameA, instNameB)
 }
 
+// nicCheckDHCPReservationConflict checks that the NIC's MAC address and static IPs aren't used by any of the
+// network's DHCP reservations.
+func nicCheckDHCPReservationConflict(nicMAC net.HardwareAddr, nicIPs []net.IP, reservations []api.NetworkDHCPReservation) error {
+	for _, reservation := range reservations {
+		reservedMAC, _ := net.ParseMAC(reservation.Hwaddr)
+		if nicMAC != nil && reservedMAC != nil && bytes.Equal(nicMAC, reservedMAC) {
+			return api.StatusErrorf(http.StatusConflict, "MAC address %q is already used by a DHCP reservation", nicMAC.String())
+		}
+
+		for _, reservedIP := range []net.IP{net.ParseIP(reservation.IPv4Address), net.ParseIP(reservation.IPv6Address)} {
+			for _, nicIP := range nicIPs {
+				if nicIP != nil && reservedIP != nil && nicIP.Equal(reservedIP) {
+					return api.StatusErrorf(http.StatusConflict, "IP address %q is already reserved for %q", nicIP.String(), reservation.Hwaddr)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 // nicCheckIsVM returns if the given instance is a VM.
 func nicCheckIsVM(instConf instance.ConfigReader) error {
 	if instConf.Type() != instancetype.VM {
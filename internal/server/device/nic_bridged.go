@@ -1,24 +1,17 @@
 package device
 
 import (
-	"bufio"
 	"bytes"
 	"context"
-	"encoding/binary"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/fs"
-	"math/rand"
 	"net"
 	"net/http"
-	"os"
 	"slices"
 	"strconv"
 	"strings"
 
-	"github.com/google/gopacket"
-	"github.com/google/gopacket/layers"
 	"github.com/mdlayher/netx/eui64"
 
 	"github.com/lxc/incus/v6/internal/server/db"
@@ -609,6 +602,27 @@ func (d *nicBridged) checkAddressConflict() error {
 		ourNICMAC, _ = net.ParseMAC(d.volatileGet()["hwaddr"])
 	}
 
+	// Check the NIC's MAC address and static IPs aren't used by a DHCP reservation of the network.
+	if d.network != nil && d.network.Info().DHCPReservations {
+		var reservations []api.NetworkDHCPReservation
+
+		err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+
+			reservations, err = tx.GetNetworkDHCPReservations(ctx, d.network.ID())
+
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed loading DHCP reservations: %w", err)
+		}
+
+		err = nicCheckDHCPReservationConflict(ourNICMAC, []net.IP{ourNICIPs["ipv4.address"], ourNICIPs["ipv6.address"]}, reservations)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Check if any instance devices use this network.
 	// Managed bridge networks have a per-server DHCP daemon so perform a node level search.
 	filter := cluster.InstanceFilter{Node: &node}
@@ -638,12 +652,13 @@ func (d *nicBridged) checkAddressConflict() error {
 
 		// Check there isn't another instance with the same DNS name connected to a managed network
 		// that has DNS enabled and is connected to the same untagged VLAN.
-		if d.network != nil && d.network.Config()["dns.mode"] != "none" && nicCheckDNSNameConflict(d.inst.Name(), inst.Name) {
+		dnsName := instance.DNSName(inst.Name, db.ExpandInstanceConfig(inst.Config, inst.Profiles))
+		if d.network != nil && d.network.Config()["dns.mode"] != "none" && nicCheckDNSNameConflict(instance.DNSName(d.inst.Name(), d.inst.ExpandedConfig()), dnsName) {
 			if sameLogicalInstance {
-				return api.StatusErrorf(http.StatusConflict, "Instance DNS name %q conflict between %q and %q because both are connected to same network", strings.ToLower(inst.Name), d.name, nicName)
+				return api.StatusErrorf(http.StatusConflict, "Instance DNS name %q conflict between %q and %q because both are connected to same network", strings.ToLower(dnsName), d.name, nicName)
 			}
 
-			return api.StatusErrorf(http.StatusConflict, "Instance DNS name %q already used on network", strings.ToLower(inst.Name))
+			return api.StatusErrorf(http.StatusConflict, "Instance DNS name %q already used on network", strings.ToLower(dnsName))
 		}
 
 		// Check NIC's MAC address doesn't match this NIC's MAC address.
@@ -1190,7 +1205,7 @@ func (d *nicBridged) rebuildDnsmasqEntry() error {
 		}
 	}
 
-	err := dnsmasq.UpdateStaticEntry(d.config["parent"], d.inst.Project().Name, d.inst.Name(), d.Name(), d.network.Config(), d.config["hwaddr"], ipv4Address, ipv6Address)
+	err := dnsmasq.UpdateStaticEntry(d.config["parent"], d.inst.Project().Name, d.inst.Name(), instance.DNSName(d.inst.Name(), d.inst.ExpandedConfig()), d.Name(), d.network.Config(), d.config["hwaddr"], ipv4Address, ipv6Address)
 	if err != nil {
 		return err
 	}
@@ -1365,6 +1380,7 @@ func (d *nicBridged) setFilters() (err error) {
 		opts := &dhcpalloc.Options{
 			ProjectName: d.inst.Project().Name,
 			HostName:    d.inst.Name(),
+			DNSName:     instance.DNSName(d.inst.Name(), d.inst.ExpandedConfig()),
 			DeviceName:  d.Name(),
 			HostMAC:     mac,
 			Network:     d.network,
@@ -1585,218 +1601,49 @@ func (d *nicBridged) networkClearLease(name string, network string, hwaddr strin
 		}
 	}
 
-	// Iterate the dnsmasq leases file looking for matching leases for this instance to release.
-	file, err := os.Open(leaseFile)
+	leases, err := dnsmasq.Leases(network)
 	if err != nil {
 		return err
 	}
 
-	defer func() { _ = file.Close() }()
-
-	var dstDUID string
-	errs := []error{}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		fields := strings.Fields(scanner.Text())
-		fieldsLen := len(fields)
-
-		// Handle lease lines
-		if fieldsLen == 5 {
-			if (mode == clearLeaseAll || mode == clearLeaseIPv4Only) && srcMAC.String() == fields[1] { // Handle IPv4 leases by matching MAC address to lease.
-				srcIP := net.ParseIP(fields[2])
-
-				if dstIPv4 == nil {
-					logger.Warnf("Failed to release DHCPv4 lease for instance %q, IP %q, MAC %q, %v", name, srcIP, srcMAC, "No server address found")
-					continue // Can't send release packet if no dstIP found.
-				}
-
-				err = d.networkDHCPv4Release(srcMAC, srcIP, dstIPv4)
-				if err != nil {
-					errs = append(errs, fmt.Errorf("Failed to release DHCPv4 lease for instance %q, IP %q, MAC %q, %v", name, srcIP, srcMAC, err))
-				}
-			} else if (mode == clearLeaseAll || mode == clearLeaseIPv6Only) && name == fields[3] { // Handle IPv6 addresses by matching hostname to lease.
-				IAID := fields[1]
-				srcIP := net.ParseIP(fields[2])
-				DUID := fields[4]
-
-				// Skip IPv4 addresses.
-				if srcIP.To4() != nil {
-					continue
-				}
-
-				if dstIPv6 == nil {
-					logger.Warnf("Failed to release DHCPv6 lease for instance %q, IP %q, DUID %q, IAID %q: %q", name, srcIP, DUID, IAID, "No server address found")
-					continue // Can't send release packet if no dstIP found.
-				}
+	// Look for the leases of this instance to release.
+	matches := []dnsmasq.Lease{}
+	for _, lease := range leases {
+		if lease.IP.To4() != nil {
+			// Handle IPv4 leases by matching MAC address to lease.
+			if (mode != clearLeaseAll && mode != clearLeaseIPv4Only) || !bytes.Equal(lease.MAC, srcMAC) {
+				continue
+			}
 
-				if dstDUID == "" {
-					errs = append(errs, fmt.Errorf("Failed to release DHCPv6 lease for instance %q, IP %q, DUID %q, IAID %q: %s", name, srcIP, DUID, IAID, "No server DUID found"))
-					continue // Can't send release packet if no dstDUID found.
-				}
+			if dstIPv4 == nil {
+				logger.Warnf("Failed to release DHCPv4 lease for instance %q, IP %q, MAC %q, %v", name, lease.IP, srcMAC, "No server address found")
+				continue // Can't send release packet if no dstIP found.
+			}
+		} else {
+			// Handle IPv6 addresses by matching hostname to lease.
+			if (mode != clearLeaseAll && mode != clearLeaseIPv6Only) || lease.Hostname != name {
+				continue
+			}
 
-				err = d.networkDHCPv6Release(DUID, IAID, srcIP, dstIPv6, dstDUID)
-				if err != nil {
-					errs = append(errs, fmt.Errorf("Failed to release DHCPv6 lease for instance %q, IP %q, DUID %q, IAID %q: %w", name, srcIP, DUID, IAID, err))
-				}
+			if dstIPv6 == nil {
+				logger.Warnf("Failed to release DHCPv6 lease for instance %q, IP %q, DUID %q, IAID %q: %q", name, lease.IP, lease.DUID, lease.IAID, "No server address found")
+				continue // Can't send release packet if no dstIP found.
 			}
-		} else if fieldsLen == 2 && fields[0] == "duid" {
-			// Handle server DUID line needed for releasing IPv6 leases.
-			// This should come before the IPv6 leases in the lease file.
-			dstDUID = fields[1]
 		}
-	}
-
-	if len(errs) > 0 {
-		return fmt.Errorf("%v", errs)
-	}
-
-	err = scanner.Err()
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// networkDHCPv4Release sends a DHCPv4 release packet to a DHCP server.
-func (d *nicBridged) networkDHCPv4Release(srcMAC net.HardwareAddr, srcIP net.IP, dstIP net.IP) error {
-	dstAddr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:67", dstIP.String()))
-	if err != nil {
-		return err
-	}
-
-	conn, err := net.DialUDP("udp", nil, dstAddr)
-	if err != nil {
-		return err
-	}
-
-	defer func() { _ = conn.Close() }()
 
-	// Random DHCP transaction ID
-	xid := rand.Uint32()
-
-	// Construct a DHCP packet pretending to be from the source IP and MAC supplied.
-	dhcp := layers.DHCPv4{
-		Operation:    layers.DHCPOpRequest,
-		HardwareType: layers.LinkTypeEthernet,
-		ClientHWAddr: srcMAC,
-		ClientIP:     srcIP,
-		Xid:          xid,
-	}
-
-	// Add options to DHCP release packet.
-	dhcp.Options = append(dhcp.Options,
-		layers.NewDHCPOption(layers.DHCPOptMessageType, []byte{byte(layers.DHCPMsgTypeRelease)}),
-		layers.NewDHCPOption(layers.DHCPOptServerID, dstIP.To4()),
-	)
-
-	buf := gopacket.NewSerializeBuffer()
-	opts := gopacket.SerializeOptions{
-		ComputeChecksums: true,
-		FixLengths:       true,
-	}
-
-	err = gopacket.SerializeLayers(buf, opts, &dhcp)
-	if err != nil {
-		return err
-	}
-
-	_, err = conn.Write(buf.Bytes())
-	if err != nil {
-		return err
-	}
-
-	return conn.Close()
-}
-
-// networkDHCPv6Release sends a DHCPv6 release packet to a DHCP server.
-func (d *nicBridged) networkDHCPv6Release(srcDUID string, srcIAID string, srcIP net.IP, dstIP net.IP, dstDUID string) error {
-	dstAddr, err := net.ResolveUDPAddr("udp6", fmt.Sprintf("[%s]:547", dstIP.String()))
-	if err != nil {
-		return err
+		matches = append(matches, lease)
 	}
 
-	conn, err := net.DialUDP("udp6", nil, dstAddr)
-	if err != nil {
-		return err
-	}
-
-	defer func() { _ = conn.Close() }()
-
-	// Construct a DHCPv6 packet pretending to be from the source IP and MAC supplied.
-	dhcp := layers.DHCPv6{
-		MsgType: layers.DHCPv6MsgTypeRelease,
-	}
-
-	// Convert Server DUID from string to byte array
-	dstDUIDRaw, err := hex.DecodeString(strings.ReplaceAll(dstDUID, ":", ""))
-	if err != nil {
-		return err
-	}
-
-	// Convert DUID from string to byte array
-	srcDUIDRaw, err := hex.DecodeString(strings.ReplaceAll(srcDUID, ":", ""))
-	if err != nil {
-		return err
-	}
-
-	// Convert IAID string to int
-	srcIAIDRaw, err := strconv.ParseUint(srcIAID, 10, 32)
-	if err != nil {
-		return err
-	}
-
-	srcIAIDRaw32 := uint32(srcIAIDRaw)
-
-	// Build the Identity Association details option manually (as not provided by gopacket).
-	iaAddr := d.networkDHCPv6CreateIAAddress(srcIP)
-	ianaRaw := d.networkDHCPv6CreateIANA(srcIAIDRaw32, iaAddr)
-
-	// Add options to DHCP release packet.
-	dhcp.Options = append(dhcp.Options,
-		layers.NewDHCPv6Option(layers.DHCPv6OptServerID, dstDUIDRaw),
-		layers.NewDHCPv6Option(layers.DHCPv6OptClientID, srcDUIDRaw),
-		layers.NewDHCPv6Option(layers.DHCPv6OptIANA, ianaRaw),
-	)
-
-	buf := gopacket.NewSerializeBuffer()
-	opts := gopacket.SerializeOptions{
-		ComputeChecksums: true,
-		FixLengths:       true,
-	}
-
-	err = gopacket.SerializeLayers(buf, opts, &dhcp)
-	if err != nil {
-		return err
+	if len(matches) == 0 {
+		return nil
 	}
 
-	_, err = conn.Write(buf.Bytes())
+	err = dnsmasq.ReleaseLeases(network, dstIPv4, dstIPv6, matches)
 	if err != nil {
-		return err
+		return fmt.Errorf("Failed to release DHCP leases for instance %q: %w", name, err)
 	}
 
-	return conn.Close()
-}
-
-// networkDHCPv6CreateIANA creates a DHCPv6 Identity Association for Non-temporary Address (rfc3315 IA_NA) option.
-func (d *nicBridged) networkDHCPv6CreateIANA(IAID uint32, IAAddr []byte) []byte {
-	data := make([]byte, 12)
-	binary.BigEndian.PutUint32(data[0:4], IAID)       // Identity Association Identifier
-	binary.BigEndian.PutUint32(data[4:8], uint32(0))  // T1
-	binary.BigEndian.PutUint32(data[8:12], uint32(0)) // T2
-	data = append(data, IAAddr...)                    // Append the IA Address details
-	return data
-}
-
-// networkDHCPv6CreateIAAddress creates a DHCPv6 Identity Association Address (rfc3315) option.
-func (d *nicBridged) networkDHCPv6CreateIAAddress(IP net.IP) []byte {
-	data := make([]byte, 28)
-	binary.BigEndian.PutUint16(data[0:2], uint16(layers.DHCPv6OptIAAddr)) // Sub-Option type
-	binary.BigEndian.PutUint16(data[2:4], uint16(24))                     // Length (fixed at 24 bytes)
-	copy(data[4:20], IP)                                                  // IPv6 address to be released
-	binary.BigEndian.PutUint32(data[20:24], uint32(0))                    // Preferred liftetime
-	binary.BigEndian.PutUint32(data[24:28], uint32(0))                    // Valid lifetime
-	return data
+	return nil
 }
 
 // setupNativeBridgePortVLANs configures the bridge port with the specified VLAN settings on the native bridge.
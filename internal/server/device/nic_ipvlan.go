@@ -37,6 +37,11 @@ func (d *nicIPVLAN) validateConfig(instConf instance.ConfigReader) error {
 		return ErrUnsupportedDevType
 	}
 
+	// The host can't reach ipvlan interfaces, so there is no way to advertise DNS servers to them.
+	if d.config["dns.nameservers"] != "" {
+		return fmt.Errorf("DNS servers can't be pushed to ipvlan NICs as the host can't reach them, configure DNS inside the instance instead")
+	}
+
 	requiredFields := []string{"parent"}
 	optionalFields := []string{
 		// gendoc:generate(entity=devices, group=nic_ipvlan, key=name)
@@ -494,12 +494,13 @@ func (d *nicOVN) checkAddressConflict() error {
 
 		// Check there isn't another instance with the same DNS name connected to managed network.
 		sameLogicalInstanceNestedNIC := sameLogicalInstance && (d.config["nested"] != "" || nicConfig["nested"] != "")
-		if d.network != nil && !sameLogicalInstanceNestedNIC && nicCheckDNSNameConflict(d.inst.Name(), inst.Name) {
+		dnsName := instance.DNSName(inst.Name, db.ExpandInstanceConfig(inst.Config, inst.Profiles))
+		if d.network != nil && !sameLogicalInstanceNestedNIC && nicCheckDNSNameConflict(instance.DNSName(d.inst.Name(), d.inst.ExpandedConfig()), dnsName) {
 			if sameLogicalInstance {
-				return api.StatusErrorf(http.StatusConflict, "Instance DNS name %q conflict between %q and %q because both are connected to same network", strings.ToLower(inst.Name), d.name, nicName)
+				return api.StatusErrorf(http.StatusConflict, "Instance DNS name %q conflict between %q and %q because both are connected to same network", strings.ToLower(dnsName), d.name, nicName)
 			}
 
-			return api.StatusErrorf(http.StatusConflict, "Instance DNS name %q already used on network", strings.ToLower(inst.Name))
+			return api.StatusErrorf(http.StatusConflict, "Instance DNS name %q already used on network", strings.ToLower(dnsName))
 		}
 
 		// Check NIC's MAC address doesn't match this NIC's MAC address.
@@ -793,7 +794,7 @@ func (d *nicOVN) Start() (*deviceConfig.RunConfig, error) {
 	// Add new OVN logical switch port for instance.
 	logicalPortName, dnsIPs, err := d.network.InstanceDevicePortStart(&network.OVNInstanceNICSetupOpts{
 		InstanceUUID: d.inst.LocalConfig()["volatile.uuid"],
-		DNSName:      d.inst.Name(),
+		DNSName:      instance.DNSName(d.inst.Name(), d.inst.ExpandedConfig()),
 		DeviceName:   d.name,
 		DeviceConfig: d.config,
 		UplinkConfig: uplinkConfig,
@@ -995,7 +996,7 @@ func (d *nicOVN) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 			// Update OVN logical switch port for instance.
 			_, _, err := d.network.InstanceDevicePortStart(&network.OVNInstanceNICSetupOpts{
 				InstanceUUID: d.inst.LocalConfig()["volatile.uuid"],
-				DNSName:      d.inst.Name(),
+				DNSName:      instance.DNSName(d.inst.Name(), d.inst.ExpandedConfig()),
 				DeviceName:   d.name,
 				DeviceConfig: d.config,
 				UplinkConfig: uplinkConfig,
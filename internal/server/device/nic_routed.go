@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/fs"
 	"net"
+	"net/netip"
 	"strings"
 	"time"
 
@@ -236,6 +237,15 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		//  shortdesc: The VRF on the host in which the host-side interface and routes are created
 		"vrf",
 
+		// gendoc:generate(entity=devices, group=nic_routed, key=dns.nameservers)
+		// The IPv6 DNS servers are advertised to the instance as RDNSS in router advertisements sent on the
+		// host-side interface, which requires `ipv6.address` to be set.
+		// On virtual machines, all the DNS servers are also applied by the agent.
+		// ---
+		//  type: string
+		//  shortdesc: Comma-delimited list of DNS server addresses to push to the instance
+		"dns.nameservers",
+
 		// gendoc:generate(entity=devices, group=nic_routed, key=io.bus)
 		//
 		// ---
@@ -252,14 +262,14 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 	// ---
 	//  type: string
 	//  shortdesc: Comma-delimited list of IPv4 static addresses to add to the instance
-	rules["ipv4.address"] = validate.Optional(validate.IsListOf(validate.IsNetworkAddressV4))
+	rules["ipv4.address"] = validate.Optional(validate.IsListOf(validate.And(validate.IsNetworkAddressV4, networkValidRoutedAddress)))
 
 	// gendoc:generate(entity=devices, group=nic_routed, key=ipv6.address)
 	//
 	// ---
 	//  type: string
 	//  shortdesc: Comma-delimited list of IPv6 static addresses to add to the instance
-	rules["ipv6.address"] = validate.Optional(validate.IsListOf(validate.IsNetworkAddressV6))
+	rules["ipv6.address"] = validate.Optional(validate.IsListOf(validate.And(validate.IsNetworkAddressV6, networkValidRoutedAddress)))
 
 	// gendoc:generate(entity=devices, group=nic_routed, key=ipv4.neighbor_probe)
 	//
@@ -316,6 +326,19 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("The vlan setting can only be used when combined with a parent interface")
 	}
 
+	// Containers can only receive DNS servers through router advertisements.
+	if instConf.Type() == instancetype.Container {
+		for _, nameserver := range util.SplitNTrimSpace(d.config["dns.nameservers"], ",", -1, true) {
+			if net.ParseIP(nameserver).To4() != nil {
+				return fmt.Errorf("IPv4 DNS server %q can only be pushed to virtual machines", nameserver)
+			}
+
+			if d.config["ipv6.address"] == "" {
+				return fmt.Errorf("dns.nameservers requires ipv6.address to be set")
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -472,6 +495,19 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// Check that the instance addresses aren't used by the host itself.
+	hostAddresses, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting host addresses: %w", err)
+	}
+
+	for _, key := range []string{"ipv4.address", "ipv6.address"} {
+		err = networkHostAddressConflict(util.SplitNTrimSpace(d.config[key], ",", -1, true), hostAddresses)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Lock to avoid issues with containers starting in parallel.
 	networkCreateSharedDeviceLock.Lock()
 	defer networkCreateSharedDeviceLock.Unlock()
@@ -509,6 +545,11 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 		if err != nil {
 			return nil, err
 		}
+
+		err = d.setupParentProxyARP(d.effectiveParentName)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	saveData["host_name"] = d.config["host_name"]
@@ -729,6 +770,14 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// Start advertising the DNS servers to the instance.
+	err = d.Register()
+	if err != nil {
+		return nil, err
+	}
+
+	reverter.Add(func() { networkRAStop(saveData["host_name"]) })
+
 	// Perform instance NIC configuration.
 	runConf := deviceConfig.RunConfig{}
 	runConf.NetworkInterface = []deviceConfig.RunConfigItem{
@@ -814,6 +863,68 @@ func (d *nicRouted) setupParentSysctls(parentName string) error {
 	return nil
 }
 
+// setupParentProxyARP enables proxy ARP on the parent so that the host answers ARP requests for the instance
+// IPv4 addresses using the neighbour proxy entries. The setting is left enabled on stop as the parent may be
+// shared with other routed NICs.
+func (d *nicRouted) setupParentProxyARP(parentName string) error {
+	if d.config["ipv4.address"] == "" {
+		return nil
+	}
+
+	proxyARPPath := fmt.Sprintf("net/ipv4/conf/%s/proxy_arp", parentName)
+	sysctlVal, err := localUtil.SysctlGet(proxyARPPath)
+	if err != nil {
+		return fmt.Errorf("Error reading net sysctl %s: %w", proxyARPPath, err)
+	}
+
+	if sysctlVal == "1\n" {
+		return nil
+	}
+
+	err = localUtil.SysctlSet(proxyARPPath, "1")
+	if err != nil {
+		return fmt.Errorf("Error setting net sysctl %s: %w", proxyARPPath, err)
+	}
+
+	return nil
+}
+
+// Register sets up anything needed on startup.
+func (d *nicRouted) Register() error {
+	hostName := d.volatileGet()["host_name"]
+	if hostName == "" || d.config["ipv6.address"] == "" {
+		return nil
+	}
+
+	// Advertise the IPv6 DNS servers on the host-side interface.
+	var nameservers []netip.Addr
+	for _, nameserver := range util.SplitNTrimSpace(d.config["dns.nameservers"], ",", -1, true) {
+		addr, err := netip.ParseAddr(nameserver)
+		if err != nil {
+			return fmt.Errorf("Invalid DNS server %q: %w", nameserver, err)
+		}
+
+		if addr.Is6() {
+			nameservers = append(nameservers, addr)
+		}
+	}
+
+	if len(nameservers) == 0 {
+		return nil
+	}
+
+	// Router advertisements must be sent from a link-local address.
+	srcAddr := d.ipHostAddress("ipv6")
+	addr, err := netip.ParseAddr(srcAddr)
+	if err != nil || !addr.IsLinkLocalUnicast() {
+		srcAddr = ""
+	}
+
+	networkRAStart(hostName, srcAddr, nameservers, nicHasAutoGateway(d.config["ipv6.gateway"]))
+
+	return nil
+}
+
 // Update returns an error as most devices do not support live updates without being restarted.
 func (d *nicRouted) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 	v := d.volatileGet()
@@ -874,6 +985,11 @@ func (d *nicRouted) postStop() error {
 		d.effectiveParentName = network.GetHostDevice(d.config["parent"], d.config["vlan"])
 	}
 
+	// Stop advertising DNS servers.
+	if d.config["host_name"] != "" {
+		networkRAStop(d.config["host_name"])
+	}
+
 	// Delete host-side interface.
 	if network.InterfaceExists(d.config["host_name"]) {
 		// Removing host-side end of veth pair will delete the peer end too.
@@ -884,15 +1000,24 @@ func (d *nicRouted) postStop() error {
 	}
 
 	// Delete IP neighbour proxy entries on the parent.
-	if d.effectiveParentName != "" {
-		for _, key := range []string{"ipv4.address", "ipv6.address"} {
-			for _, addr := range util.SplitNTrimSpace(d.config[key], ",", -1, true) {
-				neighProxy := &ip.NeighProxy{
-					DevName: d.effectiveParentName,
-					Addr:    net.ParseIP(addr),
-				}
+	if d.effectiveParentName != "" && network.InterfaceExists(d.effectiveParentName) {
+		neighProxies, err := (&ip.NeighProxy{DevName: d.effectiveParentName}).Show()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Failed listing neighbour proxies on %q: %w", d.effectiveParentName, err))
+		}
+
+		for _, neighProxy := range neighProxies {
+			for _, key := range []string{"ipv4.address", "ipv6.address"} {
+				for _, addr := range util.SplitNTrimSpace(d.config[key], ",", -1, true) {
+					if !neighProxy.Addr.Equal(net.ParseIP(addr)) {
+						continue
+					}
 
-				_ = neighProxy.Delete()
+					err := neighProxy.Delete()
+					if err != nil {
+						errs = append(errs, fmt.Errorf("Failed deleting neighbour proxy %q from %q: %w", addr, d.effectiveParentName, err))
+					}
+				}
 			}
 		}
 	}
@@ -0,0 +1,65 @@
+package device
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestNICCheckDHCPReservationConflict(t *testing.T) {
+	reservations := []api.NetworkDHCPReservation{
+		{Hwaddr: "10:66:6a:00:00:01", NetworkDHCPReservationPut: api.NetworkDHCPReservationPut{IPv4Address: "10.0.0.10"}},
+		{Hwaddr: "10:66:6a:00:00:02", NetworkDHCPReservationPut: api.NetworkDHCPReservationPut{IPv6Address: "fd00::10"}},
+	}
+
+	mac := func(value string) net.HardwareAddr {
+		hwaddr, _ := net.ParseMAC(value)
+		return hwaddr
+	}
+
+	// No conflict.
+	assert.NoError(t, nicCheckDHCPReservationConflict(mac("10:66:6a:00:00:03"), []net.IP{net.ParseIP("10.0.0.11"), net.ParseIP("fd00::11")}, reservations))
+	assert.NoError(t, nicCheckDHCPReservationConflict(nil, []net.IP{nil, nil}, reservations))
+	assert.NoError(t, nicCheckDHCPReservationConflict(mac("10:66:6a:00:00:01"), nil, nil))
+
+	// MAC address reserved.
+	err := nicCheckDHCPReservationConflict(mac("10:66:6A:00:00:01"), nil, reservations)
+	assert.True(t, api.StatusErrorCheck(err, http.StatusConflict), err)
+
+	// Static IPs reserved, regardless of their presentation.
+	err = nicCheckDHCPReservationConflict(mac("10:66:6a:00:00:03"), []net.IP{net.ParseIP("10.0.0.10"), nil}, reservations)
+	assert.ErrorContains(t, err, "10:66:6a:00:00:01")
+
+	err = nicCheckDHCPReservationConflict(mac("10:66:6a:00:00:03"), []net.IP{nil, net.ParseIP("fd00:0::10")}, reservations)
+	assert.ErrorContains(t, err, "10:66:6a:00:00:02")
+}
+
+// nicTestInstance is an instance only implementing what the NIC validation checks rely on.
+type nicTestInstance struct {
+	instance.ConfigReader
+
+	instType instancetype.Type
+}
+
+func (i *nicTestInstance) Type() instancetype.Type {
+	return i.instType
+}
+
+func TestNICIPVLANRejectsNameservers(t *testing.T) {
+	d := &nicIPVLAN{deviceCommon: deviceCommon{config: deviceConfig.Device{
+		"type":            "nic",
+		"nictype":         "ipvlan",
+		"parent":          "eth0",
+		"dns.nameservers": "2001:db8::53",
+	}}}
+
+	err := d.validateConfig(&nicTestInstance{instType: instancetype.Container})
+	assert.ErrorContains(t, err, "DNS servers can't be pushed to ipvlan NICs")
+}
@@ -0,0 +1,204 @@
+package device
+
+import (
+	"fmt"
+	"strconv"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v6/internal/server/ip"
+	"github.com/lxc/incus/v6/internal/server/network"
+	"github.com/lxc/incus/v6/shared/revert"
+	"github.com/lxc/incus/v6/shared/validate"
+)
+
+type nicTunTap struct {
+	deviceCommon
+}
+
+// CanHotPlug returns whether the device can be managed whilst the instance is running. Returns true.
+func (d *nicTunTap) CanHotPlug() bool {
+	return true
+}
+
+// validateConfig checks the supplied config for correctness.
+func (d *nicTunTap) validateConfig(instConf instance.ConfigReader) error {
+	if !instanceSupported(instConf.Type(), instancetype.Container) {
+		return ErrUnsupportedDevType
+	}
+
+	optionalFields := []string{
+		// gendoc:generate(entity=devices, group=nic_tuntap, key=name)
+		//
+		// ---
+		//  type: string
+		//  default: kernel assigned
+		//  shortdesc: The name of the interface inside the instance
+		"name",
+
+		// gendoc:generate(entity=devices, group=nic_tuntap, key=mtu)
+		//
+		// ---
+		//  type: integer
+		//  default: kernel assigned
+		//  shortdesc: The Maximum Transmit Unit (MTU) of the new interface
+		"mtu",
+
+		// gendoc:generate(entity=devices, group=nic_tuntap, key=hwaddr)
+		//
+		// ---
+		//  type: string
+		//  default: randomly assigned
+		//  shortdesc: The MAC address of the new interface (only for `tap` mode)
+		"hwaddr",
+
+		// gendoc:generate(entity=devices, group=nic_tuntap, key=host_name)
+		//
+		// ---
+		//  type: string
+		//  default: randomly assigned
+		//  shortdesc: The name of the interface on the host before it is moved into the instance
+		"host_name",
+	}
+
+	rules := nicValidationRules([]string{}, optionalFields, instConf)
+
+	// gendoc:generate(entity=devices, group=nic_tuntap, key=mode)
+	//
+	// ---
+	//  type: string
+	//  default: `tun`
+	//  shortdesc: The type of interface to create (either `tun` for layer 3 or `tap` for layer 2)
+	rules["mode"] = validate.Optional(validate.IsOneOf("tun", "tap"))
+
+	err := d.config.Validate(rules)
+	if err != nil {
+		return err
+	}
+
+	if d.config["mode"] != "tap" && d.config["hwaddr"] != "" {
+		return fmt.Errorf("The hwaddr option can only be set in tap mode")
+	}
+
+	return nil
+}
+
+// validateEnvironment checks the runtime environment for correctness.
+func (d *nicTunTap) validateEnvironment() error {
+	if d.config["name"] == "" {
+		return fmt.Errorf("Requires name property to start")
+	}
+
+	return nil
+}
+
+// mode returns the configured mode of the interface.
+func (d *nicTunTap) mode() string {
+	if d.config["mode"] == "" {
+		return "tun"
+	}
+
+	return d.config["mode"]
+}
+
+// Start is run when the device is added to a running instance or instance is starting up.
+func (d *nicTunTap) Start() (*deviceConfig.RunConfig, error) {
+	err := d.validateEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	saveData := make(map[string]string)
+	saveData["host_name"] = d.config["host_name"]
+	if saveData["host_name"] == "" {
+		saveData["host_name"] = network.RandomDevName(d.mode())
+	}
+
+	// Create a persistent interface which is then moved into the instance.
+	tuntap := &ip.Tuntap{
+		Name: saveData["host_name"],
+		Mode: d.mode(),
+	}
+
+	err = tuntap.Add()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the %s interface %q: %w", d.mode(), saveData["host_name"], err)
+	}
+
+	reverter.Add(func() { _ = network.InterfaceRemove(saveData["host_name"]) })
+
+	if d.config["mtu"] != "" {
+		mtu, err := strconv.ParseUint(d.config["mtu"], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid MTU specified: %w", err)
+		}
+
+		link := &ip.Link{Name: saveData["host_name"]}
+		err = link.SetMTU(uint32(mtu))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to set the MTU %d: %w", mtu, err)
+		}
+	}
+
+	err = d.volatileSet(saveData)
+	if err != nil {
+		return nil, err
+	}
+
+	runConf := deviceConfig.RunConfig{}
+	runConf.NetworkInterface = []deviceConfig.RunConfigItem{
+		{Key: "type", Value: "phys"},
+		{Key: "name", Value: d.config["name"]},
+		{Key: "flags", Value: "up"},
+		{Key: "link", Value: saveData["host_name"]},
+	}
+
+	// Only tap interfaces have a MAC address.
+	if d.mode() == "tap" {
+		runConf.NetworkInterface = append(runConf.NetworkInterface, deviceConfig.RunConfigItem{Key: "hwaddr", Value: d.config["hwaddr"]})
+	}
+
+	reverter.Success()
+
+	return &runConf, nil
+}
+
+// Stop is run when the device is removed from the instance.
+func (d *nicTunTap) Stop() (*deviceConfig.RunConfig, error) {
+	runConf := deviceConfig.RunConfig{
+		PostHooks: []func() error{d.postStop},
+		NetworkInterface: []deviceConfig.RunConfigItem{
+			{Key: "link", Value: d.volatileGet()["host_name"]},
+		},
+	}
+
+	return &runConf, nil
+}
+
+// postStop is run after the device is removed from the instance.
+func (d *nicTunTap) postStop() error {
+	defer func() {
+		_ = d.volatileSet(map[string]string{
+			"host_name": "",
+		})
+	}()
+
+	hostName := d.config["host_name"]
+	if hostName == "" {
+		hostName = d.volatileGet()["host_name"]
+	}
+
+	// The interface is moved back to the host when the instance stops.
+	if hostName != "" && network.InterfaceExists(hostName) {
+		err := network.InterfaceRemove(hostName)
+		if err != nil {
+			return fmt.Errorf("Failed to remove interface %q: %w", hostName, err)
+		}
+	}
+
+	return nil
+}
@@ -50,6 +50,8 @@ func NICType(s *state.State, deviceProjectName string, d deviceConfig.Device) (s
 				nicType = "ovn"
 			case "physical":
 				nicType = "physical"
+			case "bond":
+				return "", fmt.Errorf("Network %q is a bond, which can only be used as a parent (for example through the \"parent\" property of a \"physical\" or \"macvlan\" NIC)", d["network"])
 			default:
 				return "", fmt.Errorf("Unrecognised NIC network type for network %q", d["network"])
 			}
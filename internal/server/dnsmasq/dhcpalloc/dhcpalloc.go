@@ -92,6 +92,7 @@ type Network interface {
 type Options struct {
 	ProjectName string
 	HostName    string
+	DNSName     string
 	DeviceName  string
 	HostMAC     net.HardwareAddr
 	Network     Network
@@ -396,7 +397,7 @@ func AllocateTask(opts *Options, f func(*Transaction) error) error {
 		}
 
 		// Write out new dnsmasq static host allocation config file.
-		err = dnsmasq.UpdateStaticEntry(opts.Network.Name(), opts.ProjectName, opts.HostName, opts.DeviceName, opts.Network.Config(), opts.HostMAC.String(), IPv4Str, IPv6Str)
+		err = dnsmasq.UpdateStaticEntry(opts.Network.Name(), opts.ProjectName, opts.HostName, opts.DNSName, opts.DeviceName, opts.Network.Config(), opts.HostMAC.String(), IPv4Str, IPv6Str)
 		if err != nil {
 			return err
 		}
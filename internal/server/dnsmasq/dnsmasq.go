@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +22,10 @@ import (
 
 const staticAllocationDeviceSeparator = "."
 
+// reservationFilePrefix is the file name prefix of DHCP reservations.
+// It starts with an underscore so it can't conflict with an instance device static allocation.
+const reservationFilePrefix = "_reservation."
+
 // DHCPAllocation represents an IP allocation from dnsmasq.
 type DHCPAllocation struct {
 	IP             net.IP
@@ -32,7 +37,8 @@ type DHCPAllocation struct {
 var ConfigMutex sync.Mutex
 
 // UpdateStaticEntry writes a single dhcp-host line for a network/instance combination.
-func UpdateStaticEntry(network string, projectName string, instanceName string, deviceName string, netConfig map[string]string, hwaddr string, ipv4Address string, ipv6Address string) error {
+// The instance is registered in DNS as dnsName.
+func UpdateStaticEntry(network string, projectName string, instanceName string, dnsName string, deviceName string, netConfig map[string]string, hwaddr string, ipv4Address string, ipv6Address string) error {
 	hwaddr = strings.ToLower(hwaddr)
 	line := hwaddr
 
@@ -46,7 +52,7 @@ func UpdateStaticEntry(network string, projectName string, instanceName string,
 	}
 
 	if netConfig["dns.mode"] == "" || netConfig["dns.mode"] == "managed" {
-		line += fmt.Sprintf(",%s", instanceName)
+		line += fmt.Sprintf(",%s", dnsName)
 	}
 
 	if line == hwaddr {
@@ -62,6 +68,36 @@ func UpdateStaticEntry(network string, projectName string, instanceName string,
 	return nil
 }
 
+// UpdateReservationEntry writes a single dhcp-host line for a network DHCP reservation.
+func UpdateReservationEntry(network string, hwaddr string, ipv4Address string, ipv6Address string, hostname string) error {
+	hwaddr = strings.ToLower(hwaddr)
+	line := hwaddr
+
+	// Generate the dhcp-host line
+	if ipv4Address != "" {
+		line += fmt.Sprintf(",%s", ipv4Address)
+	}
+
+	if ipv6Address != "" {
+		line += fmt.Sprintf(",[%s]", ipv6Address)
+	}
+
+	if hostname != "" {
+		line += fmt.Sprintf(",%s", hostname)
+	}
+
+	if line == hwaddr {
+		return nil
+	}
+
+	err := os.WriteFile(internalUtil.VarPath("networks", network, "dnsmasq.hosts", ReservationFileName(hwaddr)), []byte(line+"\n"), 0o644)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // RemoveStaticEntry removes a single dhcp-host line for a network/instance combination.
 func RemoveStaticEntry(network string, projectName string, instanceName string, deviceName string) error {
 	deviceStaticFileName := StaticAllocationFileName(projectName, instanceName, deviceName)
@@ -73,6 +109,37 @@ func RemoveStaticEntry(network string, projectName string, instanceName string,
 	return nil
 }
 
+// RenameStaticEntries renames the dhcp-host files of all devices of an instance following an instance rename.
+func RenameStaticEntries(network string, projectName string, oldInstanceName string, newInstanceName string) error {
+	hostsPath := internalUtil.VarPath("networks", network, "dnsmasq.hosts")
+
+	entries, err := os.ReadDir(hostsPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	oldPrefix := project.Instance(projectName, oldInstanceName) + staticAllocationDeviceSeparator
+	newPrefix := project.Instance(projectName, newInstanceName) + staticAllocationDeviceSeparator
+
+	for _, entry := range entries {
+		deviceName, found := strings.CutPrefix(entry.Name(), oldPrefix)
+		if !found || entry.IsDir() {
+			continue
+		}
+
+		err = os.Rename(filepath.Join(hostsPath, entry.Name()), filepath.Join(hostsPath, newPrefix+deviceName))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Kill kills dnsmasq for a particular network (or optionally reloads it).
 func Kill(name string, reload bool) error {
 	pidPath := internalUtil.VarPath("networks", name, "dnsmasq.pid")
@@ -281,3 +348,8 @@ func StaticAllocationFileName(projectName string, instanceName string, deviceNam
 
 	return strings.Join([]string{project.Instance(projectName, instanceName), escapedDeviceName}, staticAllocationDeviceSeparator)
 }
+
+// ReservationFileName returns the file name to use for a dnsmasq network DHCP reservation.
+func ReservationFileName(hwaddr string) string {
+	return reservationFilePrefix + strings.ReplaceAll(strings.ToLower(hwaddr), ":", "-")
+}
@@ -1,9 +1,16 @@
 package dnsmasq
 
 import (
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	internalUtil "github.com/lxc/incus/v6/internal/util"
 )
 
 func Test_staticAllocationFileName(t *testing.T) {
@@ -13,3 +20,185 @@ func Test_staticAllocationFileName(t *testing.T) {
 	fileName := StaticAllocationFileName(projectName, instanceName, deviceName)
 	assert.Equal(t, "test.project_test-instance.test-.--_----.device", fileName)
 }
+
+// Test that renaming an instance moves its static allocations and leaves other instances alone.
+func TestRenameStaticEntries(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	hostsPath := internalUtil.VarPath("networks", "incusbr0", "dnsmasq.hosts")
+	err := os.MkdirAll(hostsPath, 0o755)
+	require.NoError(t, err)
+
+	netConfig := map[string]string{}
+	err = UpdateStaticEntry("incusbr0", "default", "c1", "c1", "eth0", netConfig, "00:16:3e:00:00:01", "192.0.2.10", "")
+	require.NoError(t, err)
+
+	err = UpdateStaticEntry("incusbr0", "default", "c10", "c10", "eth0", netConfig, "00:16:3e:00:00:02", "192.0.2.11", "")
+	require.NoError(t, err)
+
+	err = UpdateStaticEntry("incusbr0", "foo", "c1", "c1", "eth0", netConfig, "00:16:3e:00:00:03", "192.0.2.12", "")
+	require.NoError(t, err)
+
+	err = RenameStaticEntries("incusbr0", "default", "c1", "c2")
+	require.NoError(t, err)
+
+	mac, IPv4, _, err := DHCPStaticAllocation("incusbr0", StaticAllocationFileName("default", "c2", "eth0"))
+	require.NoError(t, err)
+	assert.Equal(t, "00:16:3e:00:00:01", mac.String())
+	assert.Equal(t, "192.0.2.10", IPv4.IP.String())
+
+	assert.NoFileExists(t, filepath.Join(hostsPath, StaticAllocationFileName("default", "c1", "eth0")))
+	assert.FileExists(t, filepath.Join(hostsPath, StaticAllocationFileName("default", "c10", "eth0")))
+	assert.FileExists(t, filepath.Join(hostsPath, StaticAllocationFileName("foo", "c1", "eth0")))
+
+	// Networks without a hosts directory are skipped.
+	err = RenameStaticEntries("missing", "default", "c2", "c3")
+	assert.NoError(t, err)
+}
+
+// Test that reservation entries are written as dhcp-host lines keyed by the lowercased MAC address.
+func TestUpdateReservationEntry(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	hostsPath := internalUtil.VarPath("networks", "incusbr0", "dnsmasq.hosts")
+	err := os.MkdirAll(hostsPath, 0o755)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		hwaddr   string
+		ipv4     string
+		ipv6     string
+		hostname string
+		want     string
+	}{
+		{"IPv4 only", "00:16:3e:00:00:01", "192.0.2.10", "", "", "00:16:3e:00:00:01,192.0.2.10\n"},
+		{"IPv6 only", "00:16:3e:00:00:02", "", "2001:db8::10", "", "00:16:3e:00:00:02,[2001:db8::10]\n"},
+		{"Dual stack with hostname", "00:16:3E:00:00:03", "192.0.2.11", "2001:db8::11", "printer", "00:16:3e:00:00:03,192.0.2.11,[2001:db8::11],printer\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := UpdateReservationEntry("incusbr0", tt.hwaddr, tt.ipv4, tt.ipv6, tt.hostname)
+			require.NoError(t, err)
+
+			content, err := os.ReadFile(filepath.Join(hostsPath, ReservationFileName(tt.hwaddr)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+		})
+	}
+
+	// Entries are replaced rather than appended to.
+	err = UpdateReservationEntry("incusbr0", "00:16:3e:00:00:01", "192.0.2.20", "", "")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(hostsPath, ReservationFileName("00:16:3e:00:00:01")))
+	require.NoError(t, err)
+	assert.Equal(t, "00:16:3e:00:00:01,192.0.2.20\n", string(content))
+
+	// Nothing is written without any reserved field.
+	err = UpdateReservationEntry("incusbr0", "00:16:3e:00:00:04", "", "", "")
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(hostsPath, ReservationFileName("00:16:3e:00:00:04")))
+}
+
+func TestParseLeases(t *testing.T) {
+	content := `1700000000 00:16:3e:00:00:01 192.0.2.10 c1 01:00:16:3e:00:00:01
+1700000000 00:16:3e:00:00:02 192.0.2.11 * *
+duid 00:01:00:01:2c:8a:9b:1f:00:16:3e:ff:ff:ff
+1700000000 1234 2001:db8::10 c1 00:03:00:01:00:16:3e:00:00:01
+1700000000 5678 2001:db8::11 c2 00:02:00:00:ab:11:6f:9d:54:11:e8:15
+`
+
+	leases, serverDUID, err := parseLeases(content)
+	require.NoError(t, err)
+	assert.Equal(t, "00:01:00:01:2c:8a:9b:1f:00:16:3e:ff:ff:ff", serverDUID)
+	require.Len(t, leases, 4)
+
+	assert.Equal(t, "192.0.2.10", leases[0].IP.String())
+	assert.Equal(t, "00:16:3e:00:00:01", leases[0].MAC.String())
+	assert.Equal(t, "c1", leases[0].Hostname)
+
+	assert.Equal(t, "192.0.2.11", leases[1].IP.String())
+	assert.Equal(t, "", leases[1].Hostname)
+
+	// The MAC is derived from link-layer DUIDs only.
+	assert.Equal(t, "2001:db8::10", leases[2].IP.String())
+	assert.Equal(t, "1234", leases[2].IAID)
+	assert.Equal(t, "00:03:00:01:00:16:3e:00:00:01", leases[2].DUID)
+	assert.Equal(t, "00:16:3e:00:00:01", leases[2].MAC.String())
+
+	assert.Equal(t, "2001:db8::11", leases[3].IP.String())
+	assert.Nil(t, leases[3].MAC)
+
+	_, _, err = parseLeases("1700000000 00:16:3e:00:00:01 invalid c1 *\n")
+	assert.Error(t, err)
+}
+
+func TestDUIDMAC(t *testing.T) {
+	tests := map[string]string{
+		"00:01:00:01:2c:8a:9b:1f:00:16:3e:00:00:01": "00:16:3e:00:00:01", // DUID-LLT
+		"00:03:00:01:00:16:3e:00:00:02":             "00:16:3e:00:00:02", // DUID-LL
+		"00:03:00:20:00:16:3e:00:00:02":             "",                  // Non-ethernet hardware type.
+		"00:02:00:00:ab:11:6f:9d:54:11:e8:15":       "",                  // DUID-EN
+		"00:04:01":                                  "",
+		"invalid":                                   "",
+	}
+
+	for duid, mac := range tests {
+		assert.Equal(t, mac, duidMAC(duid).String(), duid)
+	}
+}
+
+// Test that every lease is attempted and that the release waits for dnsmasq to drop the released leases.
+func TestReleaseLeases(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	leasesPath := internalUtil.VarPath("networks", "incusbr0", "dnsmasq.leases")
+	err := os.MkdirAll(filepath.Dir(leasesPath), 0o755)
+	require.NoError(t, err)
+
+	kept := `1700000000 00:16:3e:00:00:01 192.0.2.10 c1 01:00:16:3e:00:00:01
+1700000000 1234 2001:db8::10 c1 00:03:00:01:00:16:3e:00:00:01
+`
+
+	err = os.WriteFile(leasesPath, []byte(kept+"1700000000 00:16:3e:00:00:02 192.0.2.11 c2 01:00:16:3e:00:00:02\n"), 0o644)
+	require.NoError(t, err)
+
+	leases, err := Leases("incusbr0")
+	require.NoError(t, err)
+	require.Len(t, leases, 3)
+
+	// The DHCPv4 lease without a MAC address and the DHCPv6 lease without a server address can't be released.
+	leases[0].MAC = nil
+
+	// Record the release packets rather than sending them.
+	released := []string{}
+	dhcpv4Release = func(srcMAC net.HardwareAddr, srcIP net.IP, dstIP net.IP) error {
+		released = append(released, srcMAC.String()+" "+srcIP.String()+" "+dstIP.String())
+		return nil
+	}
+
+	t.Cleanup(func() { dhcpv4Release = DHCPv4Release })
+
+	// Simulate dnsmasq removing the released lease from its leases file.
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_ = os.WriteFile(leasesPath, []byte(kept), 0o644)
+	}()
+
+	err = ReleaseLeases("incusbr0", net.ParseIP("192.0.2.1"), nil, leases)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"192.0.2.10"`)
+	assert.Contains(t, err.Error(), `"2001:db8::10"`)
+	assert.NotContains(t, err.Error(), "192.0.2.11")
+	assert.Equal(t, []string{"00:16:3e:00:00:02 192.0.2.11 192.0.2.1"}, released)
+
+	current, err := Leases("incusbr0")
+	require.NoError(t, err)
+	assert.Len(t, current, 2)
+
+	// Nothing to wait for when none of the leases could be released.
+	err = ReleaseLeases("incusbr0", nil, nil, current)
+	assert.Error(t, err)
+}
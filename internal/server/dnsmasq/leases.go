@@ -0,0 +1,397 @@
+package dnsmasq
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// leasesMutexes holds the locks serializing the lease releases of each network.
+var leasesMutexes = map[string]*sync.Mutex{}
+
+var leasesMutexesMu sync.Mutex
+
+// dhcpv4Release and dhcpv6Release send the release packets, overridden in tests.
+var (
+	dhcpv4Release = DHCPv4Release
+	dhcpv6Release = DHCPv6Release
+)
+
+// leasesMutex returns the lock serializing the lease releases of the network.
+func leasesMutex(network string) *sync.Mutex {
+	leasesMutexesMu.Lock()
+	defer leasesMutexesMu.Unlock()
+
+	mu, ok := leasesMutexes[network]
+	if !ok {
+		mu = &sync.Mutex{}
+		leasesMutexes[network] = mu
+	}
+
+	return mu
+}
+
+// Lease represents a dynamic lease from the dnsmasq leases file.
+type Lease struct {
+	IP       net.IP
+	Hostname string
+
+	// MAC is only set for DHCPv6 leases when it can be derived from the client DUID.
+	MAC net.HardwareAddr
+
+	// IAID and DUID are only set for DHCPv6 leases.
+	IAID string
+	DUID string
+}
+
+// duidMAC returns the MAC address contained in a link-layer DUID (rfc8415 DUID-LLT or DUID-LL) of an ethernet
+// client, or nil for any other DUID.
+func duidMAC(duid string) net.HardwareAddr {
+	raw, err := hex.DecodeString(strings.ReplaceAll(duid, ":", ""))
+	if err != nil || len(raw) < 4 {
+		return nil
+	}
+
+	duidType := binary.BigEndian.Uint16(raw[0:2])
+	hwType := binary.BigEndian.Uint16(raw[2:4])
+	if hwType != 1 {
+		return nil
+	}
+
+	if (duidType == 1 && len(raw) == 14) || (duidType == 3 && len(raw) == 10) {
+		return net.HardwareAddr(raw[len(raw)-6:])
+	}
+
+	return nil
+}
+
+// parseLeases parses the content of a dnsmasq leases file.
+// Returns the leases along with the server DUID (only present when DHCPv6 is in use).
+func parseLeases(content string) ([]Lease, string, error) {
+	leases := []Lease{}
+	serverDUID := ""
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) == 2 && fields[0] == "duid" {
+			serverDUID = fields[1]
+			continue
+		}
+
+		if len(fields) != 5 {
+			continue
+		}
+
+		lease := Lease{IP: net.ParseIP(fields[2])}
+		if lease.IP == nil {
+			return nil, "", fmt.Errorf("Invalid lease IP address %q", fields[2])
+		}
+
+		if fields[3] != "*" {
+			lease.Hostname = fields[3]
+		}
+
+		if lease.IP.To4() != nil {
+			lease.MAC, _ = net.ParseMAC(fields[1])
+		} else {
+			lease.IAID = fields[1]
+			lease.DUID = fields[4]
+			lease.MAC = duidMAC(fields[4])
+		}
+
+		leases = append(leases, lease)
+	}
+
+	return leases, serverDUID, nil
+}
+
+// readLeases reads the dnsmasq leases file of the network.
+// As dnsmasq rewrites the file in place, it is read until two consecutive reads match so that a partially
+// written file isn't parsed.
+func readLeases(network string) ([]Lease, string, error) {
+	leaseFile := internalUtil.VarPath("networks", network, "dnsmasq.leases")
+
+	var content []byte
+	for i := 0; i < 10; i++ {
+		newContent, err := os.ReadFile(leaseFile)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if i > 0 && bytes.Equal(content, newContent) {
+			return parseLeases(string(content))
+		}
+
+		content = newContent
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return nil, "", fmt.Errorf("Leases file of network %q keeps changing", network)
+}
+
+// Leases returns the dynamic leases of the network.
+// Returns no leases if dnsmasq isn't handing out leases for the network.
+func Leases(network string) ([]Lease, error) {
+	if !util.PathExists(internalUtil.VarPath("networks", network, "dnsmasq.leases")) {
+		return []Lease{}, nil
+	}
+
+	leases, _, err := readLeases(network)
+	if err != nil {
+		return nil, err
+	}
+
+	return leases, nil
+}
+
+// ReleaseLeases sends release packets to the dnsmasq instance of the network for the leases, then waits for
+// dnsmasq to remove them from its leases file so that the addresses can be handed out again.
+// The server addresses are the addresses of the network dnsmasq listens on.
+// All the leases are attempted, the errors for those which couldn't be released are returned together.
+// Releases are serialized per network so that waiting on one network's dnsmasq doesn't hold up the others.
+func ReleaseLeases(network string, serverIPv4 net.IP, serverIPv6 net.IP, leases []Lease) error {
+	mu := leasesMutex(network)
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, serverDUID, err := readLeases(network)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	released := []Lease{}
+	for _, lease := range leases {
+		err := releaseLease(lease, serverIPv4, serverIPv6, serverDUID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		released = append(released, lease)
+	}
+
+	// Wait for dnsmasq to process the release packets.
+	for i := 0; len(released) > 0; i++ {
+		current, _, err := readLeases(network)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		remaining := []string{}
+		for _, lease := range released {
+			for _, entry := range current {
+				if entry.IP.Equal(lease.IP) {
+					remaining = append(remaining, lease.IP.String())
+					break
+				}
+			}
+		}
+
+		if len(remaining) == 0 {
+			break
+		}
+
+		if i >= 50 {
+			errs = append(errs, fmt.Errorf("The DHCP server didn't release the leases for %s", strings.Join(remaining, ", ")))
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return errors.Join(errs...)
+}
+
+// releaseLease sends a release packet for the lease to the DHCP server.
+func releaseLease(lease Lease, serverIPv4 net.IP, serverIPv6 net.IP, serverDUID string) error {
+	if lease.IP.To4() != nil {
+		if lease.MAC == nil {
+			return fmt.Errorf("Failed releasing DHCPv4 lease %q: Unknown MAC address", lease.IP)
+		}
+
+		if serverIPv4 == nil {
+			return fmt.Errorf("Failed releasing DHCPv4 lease %q: No server address found", lease.IP)
+		}
+
+		err := dhcpv4Release(lease.MAC, lease.IP, serverIPv4)
+		if err != nil {
+			return fmt.Errorf("Failed releasing DHCPv4 lease %q: %w", lease.IP, err)
+		}
+
+		return nil
+	}
+
+	if serverIPv6 == nil {
+		return fmt.Errorf("Failed releasing DHCPv6 lease %q: No server address found", lease.IP)
+	}
+
+	if serverDUID == "" {
+		return fmt.Errorf("Failed releasing DHCPv6 lease %q: No server DUID found", lease.IP)
+	}
+
+	err := dhcpv6Release(lease.DUID, lease.IAID, lease.IP, serverIPv6, serverDUID)
+	if err != nil {
+		return fmt.Errorf("Failed releasing DHCPv6 lease %q: %w", lease.IP, err)
+	}
+
+	return nil
+}
+
+// DHCPv4Release sends a DHCPv4 release packet to a DHCP server.
+func DHCPv4Release(srcMAC net.HardwareAddr, srcIP net.IP, dstIP net.IP) error {
+	dstAddr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:67", dstIP.String()))
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialUDP("udp", nil, dstAddr)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	// Random DHCP transaction ID
+	xid := rand.Uint32()
+
+	// Construct a DHCP packet pretending to be from the source IP and MAC supplied.
+	dhcp := layers.DHCPv4{
+		Operation:    layers.DHCPOpRequest,
+		HardwareType: layers.LinkTypeEthernet,
+		ClientHWAddr: srcMAC,
+		ClientIP:     srcIP,
+		Xid:          xid,
+	}
+
+	// Add options to DHCP release packet.
+	dhcp.Options = append(dhcp.Options,
+		layers.NewDHCPOption(layers.DHCPOptMessageType, []byte{byte(layers.DHCPMsgTypeRelease)}),
+		layers.NewDHCPOption(layers.DHCPOptServerID, dstIP.To4()),
+	)
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{
+		ComputeChecksums: true,
+		FixLengths:       true,
+	}
+
+	err = gopacket.SerializeLayers(buf, opts, &dhcp)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Write(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// DHCPv6Release sends a DHCPv6 release packet to a DHCP server.
+func DHCPv6Release(srcDUID string, srcIAID string, srcIP net.IP, dstIP net.IP, dstDUID string) error {
+	dstAddr, err := net.ResolveUDPAddr("udp6", fmt.Sprintf("[%s]:547", dstIP.String()))
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialUDP("udp6", nil, dstAddr)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	// Construct a DHCPv6 packet pretending to be from the source IP and MAC supplied.
+	dhcp := layers.DHCPv6{
+		MsgType: layers.DHCPv6MsgTypeRelease,
+	}
+
+	// Convert Server DUID from string to byte array
+	dstDUIDRaw, err := hex.DecodeString(strings.ReplaceAll(dstDUID, ":", ""))
+	if err != nil {
+		return err
+	}
+
+	// Convert DUID from string to byte array
+	srcDUIDRaw, err := hex.DecodeString(strings.ReplaceAll(srcDUID, ":", ""))
+	if err != nil {
+		return err
+	}
+
+	// Convert IAID string to int
+	srcIAIDRaw, err := strconv.ParseUint(srcIAID, 10, 32)
+	if err != nil {
+		return err
+	}
+
+	srcIAIDRaw32 := uint32(srcIAIDRaw)
+
+	// Build the Identity Association details option manually (as not provided by gopacket).
+	iaAddr := dhcpv6CreateIAAddress(srcIP)
+	ianaRaw := dhcpv6CreateIANA(srcIAIDRaw32, iaAddr)
+
+	// Add options to DHCP release packet.
+	dhcp.Options = append(dhcp.Options,
+		layers.NewDHCPv6Option(layers.DHCPv6OptServerID, dstDUIDRaw),
+		layers.NewDHCPv6Option(layers.DHCPv6OptClientID, srcDUIDRaw),
+		layers.NewDHCPv6Option(layers.DHCPv6OptIANA, ianaRaw),
+	)
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{
+		ComputeChecksums: true,
+		FixLengths:       true,
+	}
+
+	err = gopacket.SerializeLayers(buf, opts, &dhcp)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Write(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// dhcpv6CreateIANA creates a DHCPv6 Identity Association for Non-temporary Address (rfc3315 IA_NA) option.
+func dhcpv6CreateIANA(IAID uint32, IAAddr []byte) []byte {
+	data := make([]byte, 12)
+	binary.BigEndian.PutUint32(data[0:4], IAID)       // Identity Association Identifier
+	binary.BigEndian.PutUint32(data[4:8], uint32(0))  // T1
+	binary.BigEndian.PutUint32(data[8:12], uint32(0)) // T2
+	data = append(data, IAAddr...)                    // Append the IA Address details
+	return data
+}
+
+// dhcpv6CreateIAAddress creates a DHCPv6 Identity Association Address (rfc3315) option.
+func dhcpv6CreateIAAddress(IP net.IP) []byte {
+	data := make([]byte, 28)
+	binary.BigEndian.PutUint16(data[0:2], uint16(layers.DHCPv6OptIAAddr)) // Sub-Option type
+	binary.BigEndian.PutUint16(data[2:4], uint16(24))                     // Length (fixed at 24 bytes)
+	copy(data[4:20], IP)                                                  // IPv6 address to be released
+	binary.BigEndian.PutUint32(data[20:24], uint32(0))                    // Preferred liftetime
+	binary.BigEndian.PutUint32(data[24:28], uint32(0))                    // Valid lifetime
+	return data
+}
@@ -64,8 +64,12 @@ func (e *listenerCommon) Close() {
 		return
 	}
 
-	logger.Debug("Event listener server handler stopped", logger.Ctx{"listener": e.ID(), "local": e.LocalAddr(), "remote": e.RemoteAddr()})
+	// Listeners which haven't been started yet don't have a connection.
+	if e.EventListenerConnection != nil {
+		logger.Debug("Event listener server handler stopped", logger.Ctx{"listener": e.ID(), "local": e.LocalAddr(), "remote": e.RemoteAddr()})
+
+		_ = e.EventListenerConnection.Close()
+	}
 
-	_ = e.EventListenerConnection.Close()
 	e.done.Cancel()
 }
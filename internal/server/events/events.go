@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"slices"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,6 +28,10 @@ const EventSourcePull = 1
 // EventSourcePush indicates the event was received from an event listener client connected to us.
 const EventSourcePush = 2
 
+// listenerPendingLimit is the number of events which can be waiting to be sent to a listener.
+// Listeners which fall further behind are disconnected. Replayed events don't count towards this limit.
+const listenerPendingLimit = 10000
+
 // InjectFunc is used to inject an event received by a listener into the local events dispatcher.
 type InjectFunc func(event api.Event, eventSource EventSource)
 
@@ -40,6 +45,10 @@ type Server struct {
 	listeners map[string]*Listener
 	notify    NotifyFunc
 	location  string
+
+	history   eventHistory
+	historyID string
+	sequence  uint64
 }
 
 // NewServer returns a new event server.
@@ -51,6 +60,7 @@ func NewServer(debug bool, verbose bool, notify NotifyFunc) *Server {
 		},
 		listeners: map[string]*Listener{},
 		notify:    notify,
+		historyID: uuid.New().String()[:8],
 	}
 
 	return server
@@ -67,6 +77,28 @@ func (s *Server) SetLocalLocation(location string) {
 
 // AddListener creates and returns a new event listener.
 func (s *Server) AddListener(projectName string, allProjects bool, projectPermissionFunc auth.PermissionChecker, connection EventListenerConnection, messageTypes []string, excludeSources []EventSource, recvFunc EventHandler, excludeLocations []string) (*Listener, error) {
+	return s.AddListenerSince(nil, projectName, allProjects, projectPermissionFunc, connection, messageTypes, excludeSources, recvFunc, excludeLocations)
+}
+
+// AddListenerSince creates and returns a new event listener.
+// If a history position is provided, the matching events recorded in the event history after that position
+// are sent to the listener before any new event.
+func (s *Server) AddListenerSince(since *HistoryPosition, projectName string, allProjects bool, projectPermissionFunc auth.PermissionChecker, connection EventListenerConnection, messageTypes []string, excludeSources []EventSource, recvFunc EventHandler, excludeLocations []string) (*Listener, error) {
+	listener, err := s.AddPendingListener(since, projectName, allProjects, projectPermissionFunc, messageTypes, excludeSources, recvFunc, excludeLocations)
+	if err != nil {
+		return nil, err
+	}
+
+	listener.Start(connection)
+
+	return listener, nil
+}
+
+// AddPendingListener creates and registers a new event listener which queues the events until it's started.
+// This allows checking that the listener can be added before setting up its connection.
+// If a history position is provided, the matching events recorded in the event history after that position
+// are queued before any new event, ErrHistoryUnavailable is returned if they aren't all in the history.
+func (s *Server) AddPendingListener(since *HistoryPosition, projectName string, allProjects bool, projectPermissionFunc auth.PermissionChecker, messageTypes []string, excludeSources []EventSource, recvFunc EventHandler, excludeLocations []string) (*Listener, error) {
 	if allProjects && projectName != "" {
 		return nil, fmt.Errorf("Cannot specify project name when listening for events on all projects")
 	}
@@ -79,11 +111,10 @@ func (s *Server) AddListener(projectName string, allProjects bool, projectPermis
 
 	listener := &Listener{
 		listenerCommon: listenerCommon{
-			EventListenerConnection: connection,
-			messageTypes:            messageTypes,
-			done:                    cancel.New(context.Background()),
-			id:                      uuid.New().String(),
-			recvFunc:                recvFunc,
+			messageTypes: messageTypes,
+			done:         cancel.New(context.Background()),
+			id:           uuid.New().String(),
+			recvFunc:     recvFunc,
 		},
 
 		allProjects:           allProjects,
@@ -91,6 +122,14 @@ func (s *Server) AddListener(projectName string, allProjects bool, projectPermis
 		projectPermissionFunc: projectPermissionFunc,
 		excludeSources:        excludeSources,
 		excludeLocations:      excludeLocations,
+		pendingLimit:          listenerPendingLimit,
+		pendingNotify:         make(chan struct{}, 1),
+	}
+
+	listener.remove = func() {
+		s.lock.Lock()
+		delete(s.listeners, listener.id)
+		s.lock.Unlock()
 	}
 
 	s.lock.Lock()
@@ -100,9 +139,23 @@ func (s *Server) AddListener(projectName string, allProjects bool, projectPermis
 		return nil, fmt.Errorf("A listener with ID %q already exists", listener.id)
 	}
 
-	s.listeners[listener.id] = listener
+	// Queue the replayed events while holding the lock so that no new event can be dispatched in between.
+	if since != nil {
+		entries, err := s.historySince(*since)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if listener.wants(entry.event, entry.source) {
+				listener.pending = append(listener.pending, entry.event)
+			}
+		}
+
+		listener.pendingLimit += len(listener.pending)
+	}
 
-	go listener.start()
+	s.listeners[listener.id] = listener
 
 	return listener, nil
 }
@@ -156,17 +209,8 @@ func (s *Server) Inject(event api.Event, eventSource EventSource) {
 }
 
 func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
-	sourceInSlice := func(source EventSource, sources []EventSource) bool {
-		for _, i := range sources {
-			if source == i {
-				return true
-			}
-		}
-
-		return false
-	}
-
 	s.lock.Lock()
+	defer s.lock.Unlock()
 
 	// Set the Location for local events to the local serverName if not already populated (do it here rather
 	// than in Send as the lock to read s.location has been taken here already).
@@ -174,66 +218,35 @@ func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
 		event.Location = s.location
 	}
 
+	// Number the event so that clients can request the events which followed it.
+	s.sequence++
+	event.Cursor = s.cursor(s.sequence)
+
 	// If a notification hook is present, then call it for locally produced events.
 	// This can be used to send local events to another target (such as an event-hub member).
 	if s.notify != nil && eventSource == EventSourceLocal {
 		s.notify(event)
 	}
 
-	listeners := s.listeners
-	for _, listener := range listeners {
-		// If the event is project specific, check if the listener is requesting events from that project.
-		if event.Project != "" && !listener.allProjects && event.Project != listener.projectName {
-			continue
-		}
-
-		// If the event is project specific, ensure we have permission to view it.
-		if event.Project != "" && !listener.projectPermissionFunc(auth.ObjectProject(event.Project)) {
-			continue
-		}
+	s.history.add(eventHistoryEntry{event: event, source: eventSource, sequence: s.sequence})
 
-		if sourceInSlice(eventSource, listener.excludeSources) {
+	for _, listener := range s.listeners {
+		// Make sure we're not done already
+		if listener.IsClosed() {
+			// Remove the listener from the list
+			delete(s.listeners, listener.id)
 			continue
 		}
 
-		if !slices.Contains(listener.messageTypes, event.Type) {
+		if !listener.wants(event, eventSource) {
 			continue
 		}
 
-		// If the event doesn't come from this member and has been excluded by listener, don't deliver it.
-		if eventSource != EventSourceLocal && slices.Contains(listener.excludeLocations, event.Location) {
-			continue
+		if !listener.queue(event) {
+			delete(s.listeners, listener.id)
 		}
-
-		go func(listener *Listener, event api.Event) {
-			// Check that the listener still exists
-			if listener == nil {
-				return
-			}
-
-			// Make sure we're not done already
-			if listener.IsClosed() {
-				// Remove the listener from the list
-				s.lock.Lock()
-				delete(s.listeners, listener.id)
-				s.lock.Unlock()
-				return
-			}
-
-			err := listener.WriteJSON(event)
-			if err != nil {
-				// Remove the listener from the list
-				s.lock.Lock()
-				delete(s.listeners, listener.id)
-				s.lock.Unlock()
-
-				listener.Close()
-			}
-		}(listener, event)
 	}
 
-	s.lock.Unlock()
-
 	return nil
 }
 
@@ -246,4 +259,113 @@ type Listener struct {
 	projectPermissionFunc auth.PermissionChecker
 	excludeSources        []EventSource
 	excludeLocations      []string
+
+	pending       []api.Event
+	pendingLimit  int
+	pendingLock   sync.Mutex
+	pendingNotify chan struct{}
+
+	remove func()
+}
+
+// Start sets the connection of a listener added with AddPendingListener and starts sending the queued events.
+func (l *Listener) Start(connection EventListenerConnection) {
+	l.lock.Lock()
+	l.EventListenerConnection = connection
+	closed := l.IsClosed()
+	l.lock.Unlock()
+
+	if closed {
+		_ = connection.Close()
+		return
+	}
+
+	go l.start()
+	go l.send()
+
+	// Send the events queued so far.
+	select {
+	case l.pendingNotify <- struct{}{}:
+	default:
+	}
+}
+
+// wants returns whether the event should be delivered to the listener.
+func (l *Listener) wants(event api.Event, eventSource EventSource) bool {
+	// If the event is project specific, check if the listener is requesting events from that project.
+	if event.Project != "" && !l.allProjects && event.Project != l.projectName {
+		return false
+	}
+
+	// If the event is project specific, ensure we have permission to view it.
+	if event.Project != "" && !l.projectPermissionFunc(auth.ObjectProject(event.Project)) {
+		return false
+	}
+
+	if slices.Contains(l.excludeSources, eventSource) {
+		return false
+	}
+
+	if !slices.Contains(l.messageTypes, event.Type) {
+		return false
+	}
+
+	// If the event doesn't come from this member and has been excluded by listener, don't deliver it.
+	if eventSource != EventSourceLocal && slices.Contains(l.excludeLocations, event.Location) {
+		return false
+	}
+
+	return true
+}
+
+// queue adds an event to the events waiting to be sent to the listener.
+// Returns false if the listener is too far behind, in which case it gets disconnected.
+func (l *Listener) queue(event api.Event) bool {
+	l.pendingLock.Lock()
+	if len(l.pending) >= l.pendingLimit {
+		l.pendingLock.Unlock()
+
+		logger.Warn("Disconnecting event listener which isn't keeping up with events", logger.Ctx{"listener": l.id, "pending": l.pendingLimit})
+
+		// Closing the connection can wait on a write in progress, so don't block the dispatch.
+		go l.Close()
+
+		return false
+	}
+
+	l.pending = append(l.pending, event)
+	l.pendingLock.Unlock()
+
+	select {
+	case l.pendingNotify <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// send writes the queued events to the listener in order until it's closed.
+// The listener is removed from the server if writing to it fails.
+func (l *Listener) send() {
+	for {
+		select {
+		case <-l.done.Done():
+			return
+		case <-l.pendingNotify:
+		}
+
+		l.pendingLock.Lock()
+		pending := l.pending
+		l.pending = nil
+		l.pendingLock.Unlock()
+
+		for _, event := range pending {
+			err := l.WriteJSON(event)
+			if err != nil {
+				l.remove()
+				l.Close()
+				return
+			}
+		}
+	}
 }
@@ -0,0 +1,239 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// testListenerConnection records the events written to it.
+type testListenerConnection struct {
+	events chan api.Event
+}
+
+func (c *testListenerConnection) Reader(ctx context.Context, recvFunc EventHandler) {
+	<-ctx.Done()
+}
+
+func (c *testListenerConnection) WriteJSON(event any) error {
+	c.events <- event.(api.Event)
+	return nil
+}
+
+func (c *testListenerConnection) Close() error {
+	return nil
+}
+
+func (c *testListenerConnection) LocalAddr() net.Addr {
+	return &net.UnixAddr{}
+}
+
+func (c *testListenerConnection) RemoteAddr() net.Addr {
+	return &net.UnixAddr{}
+}
+
+// testEventsSend sends lifecycle events numbered from first to last.
+func testEventsSend(t *testing.T, s *Server, first int, last int) {
+	for i := first; i <= last; i++ {
+		assert.NoError(t, s.Send("", api.EventTypeLifecycle, api.EventLifecycle{Action: strconv.Itoa(i)}))
+	}
+}
+
+// testEventsReceive returns the numbers of the next count events received on the connection.
+func testEventsReceive(t *testing.T, conn *testListenerConnection, count int) []string {
+	actions := []string{}
+	for range count {
+		select {
+		case event := <-conn.events:
+			lifecycle := api.EventLifecycle{}
+			require.NoError(t, json.Unmarshal(event.Metadata, &lifecycle))
+			actions = append(actions, lifecycle.Action)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for events, received %v", actions)
+		}
+	}
+
+	return actions
+}
+
+func TestEventHistory(t *testing.T) {
+	h := eventHistory{}
+	h.setSize(3, 0)
+
+	now := time.Now()
+
+	for i := uint64(1); i <= 5; i++ {
+		h.add(eventHistoryEntry{sequence: i, event: api.Event{Timestamp: now.Add(time.Duration(i) * time.Second)}})
+	}
+
+	sequences := func(entries []eventHistoryEntry) []uint64 {
+		result := []uint64{}
+		for _, entry := range entries {
+			result = append(result, entry.sequence)
+		}
+
+		return result
+	}
+
+	assert.Equal(t, []uint64{3, 4, 5}, sequences(h.list()))
+
+	entries, err := h.since(HistoryPosition{sequence: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{3, 4, 5}, sequences(entries))
+
+	entries, err = h.since(HistoryPosition{timestamp: now.Add(3 * time.Second)})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{4, 5}, sequences(entries))
+
+	_, err = h.since(HistoryPosition{sequence: 1})
+	assert.True(t, errors.Is(err, ErrHistoryUnavailable))
+
+	_, err = h.since(HistoryPosition{timestamp: now.Add(time.Second)})
+	assert.True(t, errors.Is(err, ErrHistoryUnavailable))
+
+	// Shrinking the history drops the oldest events.
+	h.setSize(2, 5)
+	assert.Equal(t, []uint64{4, 5}, sequences(h.list()))
+
+	_, err = h.since(HistoryPosition{sequence: 2})
+	assert.True(t, errors.Is(err, ErrHistoryUnavailable))
+
+	// Disabling the history drops all the events.
+	h.setSize(0, 5)
+	_, err = h.since(HistoryPosition{sequence: 5})
+	assert.True(t, errors.Is(err, ErrHistoryUnavailable))
+}
+
+func TestServerReplayThenLive(t *testing.T) {
+	s := NewServer(false, false, nil)
+	s.SetHistorySize(100)
+
+	testEventsSend(t, s, 1, 5)
+
+	since, err := s.ParseHistoryPosition(s.cursor(2))
+	require.NoError(t, err)
+
+	// Send events while the listener is being added, they must follow the replayed events without gaps or duplicates.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		testEventsSend(t, s, 6, 50)
+	}()
+
+	conn := &testListenerConnection{events: make(chan api.Event, 100)}
+	listener, err := s.AddListenerSince(since, "", true, nil, conn, []string{api.EventTypeLifecycle}, nil, nil, nil)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	<-done
+
+	expected := []string{}
+	for i := 3; i <= 50; i++ {
+		expected = append(expected, strconv.Itoa(i))
+	}
+
+	assert.Equal(t, expected, testEventsReceive(t, conn, len(expected)))
+}
+
+func TestServerReplayUnavailable(t *testing.T) {
+	s := NewServer(false, false, nil)
+
+	addListener := func(value string) error {
+		since, err := s.ParseHistoryPosition(value)
+		if err != nil {
+			return err
+		}
+
+		listener, err := s.AddPendingListener(since, "", true, nil, []string{api.EventTypeLifecycle}, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		listener.Close()
+
+		return nil
+	}
+
+	// The history is disabled by default.
+	err := addListener(s.cursor(0))
+	assert.True(t, errors.Is(err, ErrHistoryUnavailable))
+
+	s.SetHistorySize(2)
+	testEventsSend(t, s, 1, 4)
+
+	err = addListener(s.cursor(1))
+	assert.True(t, errors.Is(err, ErrHistoryUnavailable))
+
+	err = addListener("00000000:3")
+	assert.True(t, errors.Is(err, ErrHistoryUnavailable))
+
+	err = addListener(s.cursor(5))
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrHistoryUnavailable))
+
+	err = addListener("foo")
+	assert.Error(t, err)
+
+	since, err := s.ParseHistoryPosition(s.cursor(2))
+	require.NoError(t, err)
+
+	conn := &testListenerConnection{events: make(chan api.Event, 10)}
+	listener, err := s.AddListenerSince(since, "", true, nil, conn, []string{api.EventTypeLifecycle}, nil, nil, nil)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	assert.Equal(t, []string{"3", "4"}, testEventsReceive(t, conn, 2))
+}
+
+func TestServerPendingListener(t *testing.T) {
+	s := NewServer(false, false, nil)
+	s.SetHistorySize(100)
+
+	testEventsSend(t, s, 1, 3)
+
+	since, err := s.ParseHistoryPosition(s.cursor(1))
+	require.NoError(t, err)
+
+	// Events are queued until the listener is started.
+	listener, err := s.AddPendingListener(since, "", true, nil, []string{api.EventTypeLifecycle}, nil, nil, nil)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	testEventsSend(t, s, 4, 5)
+
+	conn := &testListenerConnection{events: make(chan api.Event, 10)}
+	listener.Start(conn)
+
+	assert.Equal(t, []string{"2", "3", "4", "5"}, testEventsReceive(t, conn, 4))
+}
+
+func TestServerSlowListener(t *testing.T) {
+	s := NewServer(false, false, nil)
+
+	listener, err := s.AddPendingListener(nil, "", true, nil, []string{api.EventTypeLifecycle}, nil, nil, nil)
+	require.NoError(t, err)
+
+	listener.pendingLimit = 2
+
+	// The listener is disconnected once it has too many events waiting to be sent.
+	testEventsSend(t, s, 1, 2)
+	assert.False(t, listener.IsClosed())
+
+	testEventsSend(t, s, 3, 3)
+
+	listener.Wait(context.Background())
+	assert.True(t, listener.IsClosed())
+
+	s.lock.Lock()
+	assert.NotContains(t, s.listeners, listener.ID())
+	s.lock.Unlock()
+}
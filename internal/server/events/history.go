@@ -0,0 +1,192 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// ErrHistoryUnavailable is returned when the events requested for replay aren't (or are no longer) in the event history.
+var ErrHistoryUnavailable = errors.New("Requested events aren't available in the event history")
+
+// HistoryPosition indicates from where in the event history events should be replayed.
+type HistoryPosition struct {
+	sequence  uint64
+	timestamp time.Time
+}
+
+// eventHistoryEntry is an event recorded in the event history.
+type eventHistoryEntry struct {
+	event    api.Event
+	source   EventSource
+	sequence uint64
+}
+
+// eventHistory is a bounded ring of the most recent events.
+type eventHistory struct {
+	entries []eventHistoryEntry
+	start   int
+	count   int
+
+	// The history holds all the events with a sequence number after completeAfter
+	// and all the events with a timestamp after completeSince.
+	completeAfter uint64
+	completeSince time.Time
+}
+
+// size returns the maximum number of events kept in the history.
+func (h *eventHistory) size() int {
+	return len(h.entries)
+}
+
+// setSize changes the maximum number of events kept in the history, dropping the oldest events if needed.
+// The sequence number of the last dispatched event is used as the start of the history when it gets enabled.
+func (h *eventHistory) setSize(size int, sequence uint64) {
+	if size == len(h.entries) {
+		return
+	}
+
+	if size <= 0 {
+		*h = eventHistory{}
+		return
+	}
+
+	if len(h.entries) == 0 {
+		h.completeAfter = sequence
+		h.completeSince = time.Now()
+	}
+
+	kept := h.list()
+	if len(kept) > size {
+		h.drop(kept[len(kept)-size-1])
+		kept = kept[len(kept)-size:]
+	}
+
+	h.entries = make([]eventHistoryEntry, size)
+	h.start = 0
+	h.count = copy(h.entries, kept)
+}
+
+// add records an event, replacing the oldest one if the history is full.
+func (h *eventHistory) add(entry eventHistoryEntry) {
+	if len(h.entries) == 0 {
+		return
+	}
+
+	if h.count < len(h.entries) {
+		h.entries[(h.start+h.count)%len(h.entries)] = entry
+		h.count++
+		return
+	}
+
+	h.drop(h.entries[h.start])
+	h.entries[h.start] = entry
+	h.start = (h.start + 1) % len(h.entries)
+}
+
+// drop updates the completeness markers of the history when an entry is removed from it.
+func (h *eventHistory) drop(entry eventHistoryEntry) {
+	h.completeAfter = entry.sequence
+
+	if entry.event.Timestamp.After(h.completeSince) {
+		h.completeSince = entry.event.Timestamp
+	}
+}
+
+// list returns the recorded events, from oldest to newest.
+func (h *eventHistory) list() []eventHistoryEntry {
+	entries := make([]eventHistoryEntry, 0, h.count)
+	for i := 0; i < h.count; i++ {
+		entries = append(entries, h.entries[(h.start+i)%len(h.entries)])
+	}
+
+	return entries
+}
+
+// since returns the recorded events which happened after the position, from oldest to newest.
+func (h *eventHistory) since(position HistoryPosition) ([]eventHistoryEntry, error) {
+	if len(h.entries) == 0 {
+		return nil, fmt.Errorf("Event history is disabled: %w", ErrHistoryUnavailable)
+	}
+
+	if position.timestamp.IsZero() {
+		if position.sequence < h.completeAfter {
+			return nil, fmt.Errorf("Events after the cursor were dropped from the event history: %w", ErrHistoryUnavailable)
+		}
+	} else if position.timestamp.Before(h.completeSince) {
+		return nil, fmt.Errorf("Events after %s aren't all in the event history: %w", position.timestamp.Format(time.RFC3339Nano), ErrHistoryUnavailable)
+	}
+
+	entries := []eventHistoryEntry{}
+	for _, entry := range h.list() {
+		if position.timestamp.IsZero() && entry.sequence <= position.sequence {
+			continue
+		}
+
+		if !position.timestamp.IsZero() && !entry.event.Timestamp.After(position.timestamp) {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// cursor returns the cursor of the event with the given sequence number.
+func (s *Server) cursor(sequence uint64) string {
+	return fmt.Sprintf("%s:%d", s.historyID, sequence)
+}
+
+// SetHistorySize sets the maximum number of events kept in the event history.
+// A size of 0 disables the event history.
+func (s *Server) SetHistorySize(size int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.history.setSize(size, s.sequence)
+}
+
+// ParseHistoryPosition parses the position from which events should be replayed.
+// The value is either an event cursor or an RFC3339 timestamp.
+// ErrHistoryUnavailable is returned if the cursor comes from another event history.
+func (s *Server) ParseHistoryPosition(value string) (*HistoryPosition, error) {
+	position := HistoryPosition{}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, value)
+	if err == nil {
+		position.timestamp = timestamp
+	} else {
+		historyID, sequence, found := strings.Cut(value, ":")
+		if !found {
+			return nil, fmt.Errorf("Invalid event cursor or timestamp %q", value)
+		}
+
+		position.sequence, err = strconv.ParseUint(sequence, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid event cursor %q: %w", value, err)
+		}
+
+		if historyID != s.historyID {
+			return nil, fmt.Errorf("Event cursor %q doesn't belong to this server's event history: %w", value, ErrHistoryUnavailable)
+		}
+	}
+
+	return &position, nil
+}
+
+// historySince returns the events recorded in the event history after the position.
+// ErrHistoryUnavailable is returned if the events which happened after that position aren't all in the history.
+// The server lock must be held.
+func (s *Server) historySince(position HistoryPosition) ([]eventHistoryEntry, error) {
+	if position.timestamp.IsZero() && position.sequence > s.sequence {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Event cursor %q is ahead of the event history", s.cursor(position.sequence))
+	}
+
+	return s.history.since(position)
+}
@@ -17,14 +17,22 @@ type SNATOpts struct {
 	SNATAddress net.IP     // SNAT IP address to use. If nil then MASQUERADE is used.
 }
 
+// ConntrackOpts specify how connection tracking limits are setup.
+type ConntrackOpts struct {
+	Max        uint64 // Maximum number of new connections tracked from the network (0 for unlimited).
+	TimeoutTCP uint64 // Timeout in seconds for established TCP connections (0 for the kernel default).
+	TimeoutUDP uint64 // Timeout in seconds for UDP streams (0 for the kernel default).
+}
+
 // Opts for setting up the firewall.
 type Opts struct {
-	FeaturesV4 *FeatureOpts // Enable IPv4 firewall with specified options. Off if not provided.
-	FeaturesV6 *FeatureOpts // Enable IPv6 firewall with specified options. Off if not provided.
-	SNATV4     *SNATOpts    // Enable IPv4 SNAT with specified options. Off if not provided.
-	SNATV6     *SNATOpts    // Enable IPv6 SNAT with specified options. Off if not provided.
-	ACL        bool         // Enable ACL during setup.
-	AddressSet bool         // Enable address sets, only for netfilter.
+	FeaturesV4 *FeatureOpts   // Enable IPv4 firewall with specified options. Off if not provided.
+	FeaturesV6 *FeatureOpts   // Enable IPv6 firewall with specified options. Off if not provided.
+	SNATV4     *SNATOpts      // Enable IPv4 SNAT with specified options. Off if not provided.
+	SNATV6     *SNATOpts      // Enable IPv6 SNAT with specified options. Off if not provided.
+	ACL        bool           // Enable ACL during setup.
+	AddressSet bool           // Enable address sets, only for netfilter.
+	Conntrack  *ConntrackOpts // Enable connection tracking limits with specified options. Off if not provided.
 }
 
 // ACLRule represents an ACL rule that can be added to a firewall.
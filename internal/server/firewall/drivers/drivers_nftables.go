@@ -131,7 +131,7 @@ func (d Nftables) Compat() (bool, error) {
 
 // nftGenericItem represents some common fields amongst the different nftables types.
 type nftGenericItem struct {
-	ItemType string `json:"-"`      // Type of item (table, chain or rule). Populated by Incus.
+	ItemType string `json:"-"`      // Type of item (table, chain, rule or ct timeout). Populated by Incus.
 	Family   string `json:"family"` // Family of item (ip, ip6, bridge etc).
 	Table    string `json:"table"`  // Table the item belongs to (for chains and rules).
 	Chain    string `json:"chain"`  // Chain the item belongs to (for rules).
@@ -169,6 +169,7 @@ func (d Nftables) nftParseRuleset() ([]nftGenericItem, error) {
 		rule, foundRule := item["rule"]
 		chain, foundChain := item["chain"]
 		table, foundTable := item["table"]
+		ctTimeout, foundCtTimeout := item["ct timeout"]
 		if foundRule {
 			rule.ItemType = "rule"
 			items = append(items, rule)
@@ -178,6 +179,9 @@ func (d Nftables) nftParseRuleset() ([]nftGenericItem, error) {
 		} else if foundTable {
 			table.ItemType = "table"
 			items = append(items, table)
+		} else if foundCtTimeout {
+			ctTimeout.ItemType = "ct timeout"
+			items = append(items, ctTimeout)
 		}
 	}
 
@@ -237,6 +241,50 @@ func (d Nftables) networkSetupForwardingPolicy(networkName string, ip4Allow *boo
 	return nil
 }
 
+// networkSetupConntrack configures the connection tracking limits and timeouts for traffic coming from the network.
+func (d Nftables) networkSetupConntrack(networkName string, opts *ConntrackOpts) error {
+	tplFields := d.networkConntrackTplFields(networkName, opts)
+
+	err := d.applyNftConfig(nftablesNetConntrack, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed adding connection tracking rules for network %q (%s): %w", networkName, tplFields["family"], err)
+	}
+
+	return nil
+}
+
+// networkConntrackTplFields returns the template fields of the connection tracking rules of a network.
+func (d Nftables) networkConntrackTplFields(networkName string, opts *ConntrackOpts) map[string]any {
+	timeouts := []map[string]any{}
+
+	if opts.TimeoutTCP > 0 {
+		timeouts = append(timeouts, map[string]any{
+			"protocol": "tcp",
+			"state":    "established",
+			"timeout":  opts.TimeoutTCP,
+		})
+	}
+
+	if opts.TimeoutUDP > 0 {
+		timeouts = append(timeouts, map[string]any{
+			"protocol": "udp",
+			"state":    "replied",
+			"timeout":  opts.TimeoutUDP,
+		})
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"networkName":    networkName,
+		"family":         "inet",
+		"timeouts":       timeouts,
+		"max":            opts.Max,
+	}
+
+	return tplFields
+}
+
 // networkSetupOutboundNAT configures outbound NAT.
 // If srcIP is non-nil then SNAT is used with the specified address, otherwise MASQUERADE mode is used.
 // Append mode is always on and so the append argument is ignored.
@@ -329,6 +377,13 @@ func (d Nftables) NetworkSetup(networkName string, opts Opts) error {
 		}
 	}
 
+	if opts.Conntrack != nil {
+		err := d.networkSetupConntrack(networkName, opts.Conntrack)
+		if err != nil {
+			return err
+		}
+	}
+
 	if opts.SNATV4 != nil || opts.SNATV6 != nil {
 		err := d.networkSetupOutboundNAT(networkName, opts.SNATV4, opts.SNATV6)
 		if err != nil {
@@ -377,7 +432,8 @@ func (d Nftables) NetworkClear(networkName string, _ bool, _ []uint) error {
 		"fwd", "pstrt", "in", "out", // Chains used for network operation rules.
 		"aclin", "aclout", "aclfwd", "acl", // Chains used by ACL rules.
 		"fwdprert", "fwdout", "fwdpstrt", // Chains used by Address Forward rules.
-		"egress", // Chains added for limits.priority option
+		"egress",           // Chains added for limits.priority option
+		"ctprert", "ctfwd", // Chains used by connection tracking limits.
 	}
 
 	// Remove chains created by network rules.
@@ -387,6 +443,12 @@ func (d Nftables) NetworkClear(networkName string, _ bool, _ []uint) error {
 		return fmt.Errorf("Failed clearing nftables rules for network %q: %w", networkName, err)
 	}
 
+	// Remove connection tracking timeout policies now that no chain references them.
+	err = d.removeCtTimeouts(networkName)
+	if err != nil {
+		return fmt.Errorf("Failed clearing nftables connection tracking timeouts for network %q: %w", networkName, err)
+	}
+
 	// Attempt to delete our address sets.
 	// This will fail so long as there are still rules referencing them (other networks).
 	_ = d.RemoveIncusAddressSets("bridge")
@@ -468,15 +530,12 @@ func (d Nftables) InstanceSetupBridgeFilter(projectName string, instanceName str
 	}
 
 	// Set the template fields for the ACL rules.
-	tplFields["aclInDropRules"] = nftRules.inDropRules
-	tplFields["aclInRejectRules"] = nftRules.inRejectRules
-	tplFields["aclInRejectRulesConverted"] = nftRules.inRejectRulesConverted
-	tplFields["aclInAcceptRules"] = append(nftRules.inAcceptRules4, nftRules.inAcceptRules6...)
+	tplFields["aclInRules"] = nftRules.inRules
+	tplFields["aclInRulesConverted"] = nftRules.inRulesConverted
 	tplFields["aclInDefaultRule"] = nftRules.defaultInRule
 	tplFields["aclInDefaultRuleConverted"] = nftRules.defaultInRuleConverted
 
-	tplFields["aclOutDropRules"] = nftRules.outDropRules
-	tplFields["aclOutAcceptRules"] = nftRules.outAcceptRules
+	tplFields["aclOutRules"] = nftRules.outRules
 	tplFields["aclOutDefaultRule"] = nftRules.defaultOutRule
 
 	// Required for basic connectivity
@@ -548,7 +607,19 @@ func (d Nftables) InstanceSetupProxyNAT(projectName string, instanceName string,
 		})
 	}
 
-	dnatRanges := getOptimisedDNATRanges(forward)
+	dnatRanges, dnatPortMap := splitDNATPortMap(getOptimisedDNATRanges(forward))
+	if len(dnatPortMap) > 0 {
+		listenPorts, targetPortMap := nftablesPortMap(dnatPortMap, false)
+		dnatRules = append(dnatRules, map[string]any{
+			"ipFamily":      ipFamily,
+			"protocol":      forward.Protocol,
+			"listenAddress": listenAddressStr,
+			"listenPorts":   listenPorts,
+			"targetDest":    nftablesAddress(ipFamily, targetAddressStr),
+			"targetPortMap": targetPortMap,
+		})
+	}
+
 	for listenPortRange, targetPortRange := range dnatRanges {
 		// Format the destination host/port as appropriate
 		targetDest := targetAddressStr
@@ -607,15 +678,11 @@ func (d Nftables) InstanceClearProxyNAT(projectName string, instanceName string,
 	return nil
 }
 
-// nftRulesCollection contains the ACL rules translated to NFT rules and split in groups.
+// nftRulesCollection contains the ACL rules translated to NFT rules and split by direction, in evaluation order.
 type nftRulesCollection struct {
-	inDropRules            []string
-	inRejectRules          []string
-	inRejectRulesConverted []string
-	inAcceptRules4         []string
-	inAcceptRules6         []string
-	outDropRules           []string
-	outAcceptRules         []string
+	inRules                []string
+	inRulesConverted       []string
+	outRules               []string
 	defaultInRule          string
 	defaultInRuleConverted string
 	defaultOutRule         string
@@ -624,13 +691,9 @@ type nftRulesCollection struct {
 // aclRulesToNftRules converts ACL rules applied to the device to NFT rules.
 func (d Nftables) aclRulesToNftRules(hostName string, aclRules []ACLRule) (*nftRulesCollection, error) {
 	nftRules := nftRulesCollection{
-		inDropRules:            make([]string, 0),
-		inRejectRules:          make([]string, 0),
-		inRejectRulesConverted: make([]string, 0), // To be used in the forward chain where reject is not supported
-		inAcceptRules4:         make([]string, 0),
-		inAcceptRules6:         make([]string, 0),
-		outDropRules:           make([]string, 0),
-		outAcceptRules:         make([]string, 0),
+		inRules:                make([]string, 0),
+		inRulesConverted:       make([]string, 0), // To be used in the forward chain where reject is not supported
+		outRules:               make([]string, 0),
 		defaultInRule:          "",
 		defaultInRuleConverted: "", // To be used in the forward chain where reject is not supported
 		defaultOutRule:         "",
@@ -701,22 +764,17 @@ func (d Nftables) aclRulesToNftRules(hostName string, aclRules []ACLRule) (*nftR
 			rule.Action = "drop"
 		}
 
-		nft4Rules, nft6Rules, newNftRules, err := d.aclRuleToNftRules(hostNameQuoted, rule)
+		_, _, newNftRules, err := d.aclRuleToNftRules(hostNameQuoted, rule)
 		if err != nil {
 			return nil, err
 		}
 
+		// Keep the rules in the order they were provided in, so rule priorities are respected.
 		switch rule.Direction {
 		case "ingress":
 			switch {
-			case rule.Action == "drop":
-				nftRules.outDropRules = append(nftRules.outDropRules, newNftRules...)
-
-			case rule.Action == "reject":
-				nftRules.outDropRules = append(nftRules.outDropRules, newNftRules...)
-
-			case rule.Action == "allow":
-				nftRules.outAcceptRules = append(nftRules.outAcceptRules, newNftRules...)
+			case rule.Action == "drop", rule.Action == "reject", rule.Action == "allow":
+				nftRules.outRules = append(nftRules.outRules, newNftRules...)
 
 			default:
 				return nil, fmt.Errorf("Unrecognised action %q", rule.Action)
@@ -724,11 +782,12 @@ func (d Nftables) aclRulesToNftRules(hostName string, aclRules []ACLRule) (*nftR
 
 		case "egress":
 			switch {
-			case rule.Action == "drop":
-				nftRules.inDropRules = append(nftRules.inDropRules, newNftRules...)
+			case rule.Action == "drop", rule.Action == "allow":
+				nftRules.inRules = append(nftRules.inRules, newNftRules...)
+				nftRules.inRulesConverted = append(nftRules.inRulesConverted, newNftRules...)
 
 			case rule.Action == "reject":
-				nftRules.inRejectRules = append(nftRules.inRejectRules, newNftRules...)
+				nftRules.inRules = append(nftRules.inRules, newNftRules...)
 
 				// Generate reject rule converted to a drop rule.
 				rule.Action = "drop"
@@ -738,16 +797,7 @@ func (d Nftables) aclRulesToNftRules(hostName string, aclRules []ACLRule) (*nftR
 					return nil, err
 				}
 
-				nftRules.inRejectRulesConverted = append(nftRules.inRejectRulesConverted, newNftRules...)
-
-			case rule.Action == "allow":
-				if len(nft4Rules) != 0 {
-					nftRules.inAcceptRules4 = append(nftRules.inAcceptRules4, nft4Rules...)
-				}
-
-				if len(nft6Rules) != 0 {
-					nftRules.inAcceptRules6 = append(nftRules.inAcceptRules6, nft6Rules...)
-				}
+				nftRules.inRulesConverted = append(nftRules.inRulesConverted, newNftRules...)
 
 			default:
 				return nil, fmt.Errorf("Unrecognised action %q", rule.Action)
@@ -800,25 +850,35 @@ func (d Nftables) aclRuleToNftRules(hostNameQuoted string, rule ACLRule) ([]stri
 // applyNftConfig loads the specified config template and then applies it to the common template before sending to
 // the nft command to be atomically applied to the system.
 func (d Nftables) applyNftConfig(tpl *template.Template, tplFields map[string]any) error {
+	config, err := d.renderNftConfig(tpl, tplFields)
+	if err != nil {
+		return err
+	}
+
+	err = subprocess.RunCommandWithFds(context.TODO(), strings.NewReader(config), nil, "nft", "-f", "-")
+	if err != nil {
+		return fmt.Errorf("Failed apply nftables config: %w", err)
+	}
+
+	return nil
+}
+
+// renderNftConfig renders the specified template within the common table template.
+func (d Nftables) renderNftConfig(tpl *template.Template, tplFields map[string]any) (string, error) {
 	// Load the specified template into the common template's parse tree under the nftableContentTemplate
 	// name so that the nftableContentTemplate template can use it with the generic name.
 	_, err := nftablesCommonTable.AddParseTree(nftablesContentTemplate, tpl.Tree)
 	if err != nil {
-		return fmt.Errorf("Failed loading %q template: %w", tpl.Name(), err)
+		return "", fmt.Errorf("Failed loading %q template: %w", tpl.Name(), err)
 	}
 
 	config := &strings.Builder{}
 	err = nftablesCommonTable.Execute(config, tplFields)
 	if err != nil {
-		return fmt.Errorf("Failed running %q template: %w", tpl.Name(), err)
+		return "", fmt.Errorf("Failed running %q template: %w", tpl.Name(), err)
 	}
 
-	err = subprocess.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
-	if err != nil {
-		return fmt.Errorf("Failed apply nftables config: %w", err)
-	}
-
-	return nil
+	return config.String(), nil
 }
 
 // removeChains removes the specified chains from the specified families.
@@ -863,6 +923,32 @@ func (d Nftables) removeChains(families []string, chainSuffix string, chains ...
 	return nil
 }
 
+// removeCtTimeouts removes the connection tracking timeout policies of the specified network.
+func (d Nftables) removeCtTimeouts(networkName string) error {
+	ruleset, err := d.nftParseRuleset()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, 2)
+	for _, protocol := range []string{"tcp", "udp"} {
+		names = append(names, fmt.Sprintf("ct%s%s%s%s", nftablesChainSeparator, networkName, nftablesChainSeparator, protocol))
+	}
+
+	for _, item := range ruleset {
+		if item.ItemType != "ct timeout" || item.Table != nftablesNamespace || !slices.Contains(names, item.Name) {
+			continue
+		}
+
+		_, err = subprocess.RunCommand("nft", "delete", "ct", "timeout", item.Family, nftablesNamespace, item.Name)
+		if err != nil {
+			return fmt.Errorf("Failed deleting nftables connection tracking timeout %q (%s): %w", item.Name, item.Family, err)
+		}
+	}
+
+	return nil
+}
+
 // InstanceSetupRPFilter activates reverse path filtering for the specified instance device on the host interface.
 func (d Nftables) InstanceSetupRPFilter(projectName string, instanceName string, deviceName string, hostName string) error {
 	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
@@ -1369,7 +1455,32 @@ func (d Nftables) NetworkApplyForwards(networkName string, rules []AddressForwar
 					})
 				}
 
-				dnatRanges := getOptimisedDNATRanges(&rule)
+				// Shifted port ranges are applied with a single rule using a port map.
+				dnatRanges, dnatPortMap := splitDNATPortMap(getOptimisedDNATRanges(&rule))
+				if len(dnatPortMap) > 0 {
+					listenPorts, targetPortMap := nftablesPortMap(dnatPortMap, false)
+					dnatRules = append(dnatRules, map[string]any{
+						"ipFamily":      ipFamily,
+						"protocol":      rule.Protocol,
+						"listenAddress": listenAddressStr,
+						"listenPorts":   listenPorts,
+						"targetDest":    nftablesAddress(ipFamily, targetAddressStr),
+						"targetPortMap": targetPortMap,
+					})
+
+					if rule.SNAT {
+						targetPorts, listenPortMap := nftablesPortMap(dnatPortMap, true)
+						snatRules = append(snatRules, map[string]any{
+							"ipFamily":      ipFamily,
+							"protocol":      rule.Protocol,
+							"listenAddress": nftablesAddress(ipFamily, listenAddressStr),
+							"listenPortMap": listenPortMap,
+							"targetAddress": targetAddressStr,
+							"targetPorts":   targetPorts,
+						})
+					}
+				}
+
 				for listenPortRange, targetPortRange := range dnatRanges {
 					// Format the destination host/port as appropriate
 					targetDest := targetAddressStr
@@ -1457,6 +1568,46 @@ func (d Nftables) NetworkApplyForwards(networkName string, rules []AddressForwar
 	return nil
 }
 
+// nftablesAddress returns the given address formatted to be followed by a port expression.
+func nftablesAddress(ipFamily string, address string) string {
+	if ipFamily == "ip6" {
+		return fmt.Sprintf("[%s]", address)
+	}
+
+	return address
+}
+
+// nftablesPortMap returns the set of ports matched by the given port map and the elements of an nftables
+// map translating them. When reverse is true, the map is inverted (e.g. to translate target ports back to
+// their listen ports).
+func nftablesPortMap(portMap map[uint64]uint64, reverse bool) (string, string) {
+	fromPorts := make([]uint64, 0, len(portMap))
+	toPorts := make(map[uint64]uint64, len(portMap))
+	for listenPort, targetPort := range portMap {
+		if reverse {
+			listenPort, targetPort = targetPort, listenPort
+		}
+
+		fromPorts = append(fromPorts, listenPort)
+		toPorts[listenPort] = targetPort
+	}
+
+	slices.Sort(fromPorts)
+
+	portRanges := portRangesFromSlice(fromPorts)
+	portRangeStrs := make([]string, 0, len(portRanges))
+	for _, portRange := range portRanges {
+		portRangeStrs = append(portRangeStrs, portRangeStr(portRange, "-"))
+	}
+
+	elements := make([]string, 0, len(fromPorts))
+	for _, port := range fromPorts {
+		elements = append(elements, fmt.Sprintf("%d : %d", port, toPorts[port]))
+	}
+
+	return fmt.Sprintf("{ %s }", strings.Join(portRangeStrs, ", ")), strings.Join(elements, ", ")
+}
+
 // NetworkApplyAddressSets creates or updates named nft sets for all address sets.
 func (d Nftables) NetworkApplyAddressSets(sets []AddressSet, nftTable string) error {
 	_, err := subprocess.RunCommand("nft", "create", "table", nftTable, nftablesNamespace)
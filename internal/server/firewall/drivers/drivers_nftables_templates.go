@@ -78,6 +78,33 @@ chain out{{.chainSeparator}}{{.networkName}} {
 }
 `))
 
+var nftablesNetConntrack = template.Must(template.New("nftablesNetConntrack").Parse(`
+{{ range .timeouts }}
+ct timeout ct{{$.chainSeparator}}{{$.networkName}}{{$.chainSeparator}}{{.protocol}} {
+	protocol {{.protocol}};
+	policy = { {{.state}}: {{.timeout}} };
+}
+{{ end }}
+
+{{ if .timeouts }}
+chain ctprert{{.chainSeparator}}{{.networkName}} {
+	type filter hook prerouting priority raw; policy accept;
+
+	{{ range .timeouts }}
+	iifname "{{$.networkName}}" meta l4proto {{.protocol}} ct timeout set "ct{{$.chainSeparator}}{{$.networkName}}{{$.chainSeparator}}{{.protocol}}"
+	{{ end }}
+}
+{{ end }}
+
+{{ if .max }}
+chain ctfwd{{.chainSeparator}}{{.networkName}} {
+	type filter hook forward priority filter; policy accept;
+
+	iifname "{{.networkName}}" ct state new ct count over {{.max}} drop
+}
+{{ end }}
+`))
+
 var nftablesNetProxyNAT = template.Must(template.New("nftablesNetProxyNAT").Parse(`
 add table {{.family}} {{.namespace}}
 add chain {{.family}} {{.namespace}} {{.chainPrefix}}prert{{.chainSeparator}}{{.label}} {type nat hook prerouting priority -100; policy accept;}
@@ -91,14 +118,14 @@ table {{.family}} {{.namespace}} {
 	chain {{.chainPrefix}}prert{{.chainSeparator}}{{.label}} {
 		type nat hook prerouting priority -100; policy accept;
 		{{ range .dnatRules }}
-		{{.ipFamily}} daddr {{.listenAddress}} {{ if .protocol }}{{.protocol}} dport {{.listenPorts}}{{ end }} dnat to {{.targetDest}}
+		{{.ipFamily}} daddr {{.listenAddress}} {{ if .protocol }}{{.protocol}} dport {{.listenPorts}}{{ end }} dnat to {{.targetDest}}{{ if .targetPortMap }} : {{.protocol}} dport map { {{.targetPortMap}} }{{ end }}
 		{{ end }}
 	}
 
 	chain {{.chainPrefix}}out{{.chainSeparator}}{{.label}} {
 		type nat hook output priority -100; policy accept;
 		{{ range .dnatRules }}
-		{{.ipFamily}} daddr {{.listenAddress}} {{ if .protocol }}{{.protocol}} dport {{.listenPorts}}{{ end }} dnat to {{.targetDest}}
+		{{.ipFamily}} daddr {{.listenAddress}} {{ if .protocol }}{{.protocol}} dport {{.listenPorts}}{{ end }} dnat to {{.targetDest}}{{ if .targetPortMap }} : {{.protocol}} dport map { {{.targetPortMap}} }{{ end }}
 		{{ end }}
 	}
 
@@ -108,7 +135,7 @@ table {{.family}} {{.namespace}} {
 		{{ if .targetHost }}
 		{{.ipFamily}} saddr {{.targetHost}} {{.ipFamily}} daddr {{.targetHost}} {{ if .protocol }}{{.protocol}} dport {{.targetPorts}}{{ end }} masquerade
 		{{ else }}
-		{{.ipFamily}} saddr {{.targetAddress}} {{.protocol}} sport {{.targetPorts}} snat to {{.listenAddress}}:{{.listenPorts}}
+		{{.ipFamily}} saddr {{.targetAddress}} {{.protocol}} sport {{.targetPorts}} snat to {{.listenAddress}}{{ if .listenPortMap }} : {{.protocol}} sport map { {{.listenPortMap}} }{{ else }}:{{.listenPorts}}{{ end }}
 		{{ end }}
 		{{ end }}
 	}
@@ -193,7 +220,7 @@ chain in{{.chainSeparator}}{{.deviceLabel}} {
 	type filter hook input priority -200; policy accept;
 
 	# Basic connectivity
-	{{ if or .aclInDropRules .aclInRejectRules .aclInAcceptRules .aclOutDropRules .aclOutAcceptRules .aclInDefaultRule }}
+	{{ if or .aclInRules .aclOutRules .aclInDefaultRule }}
 	ct state established,related accept
 
 	{{ if .dnsIPv4 }}
@@ -249,13 +276,7 @@ chain in{{.chainSeparator}}{{.deviceLabel}} {
 	{{ end }}
 
 	# ACLs
-	{{ range .aclInDropRules }}
-	{{.}}
-	{{ end }}
-	{{ range .aclInRejectRules }}
-	{{.}}
-	{{ end }}
-	{{ range .aclInAcceptRules }}
+	{{ range .aclInRules }}
 	{{.}}
 	{{ end }}
 
@@ -297,27 +318,15 @@ chain fwd{{.chainSeparator}}{{.deviceLabel}} {
 	{{ end }}
 
 	# Network ACLs
-	{{ if or .aclInDropRules .aclInRejectRulesConverted .aclInAcceptRules .aclOutDropRules .aclOutAcceptRules .aclInDefaultRuleConverted .aclOutDefaultRule }}
+	{{ if or .aclInRulesConverted .aclOutRules .aclInDefaultRuleConverted .aclOutDefaultRule }}
 	ct state established,related accept
 	{{ end }}
 
-	{{ range .aclInDropRules}}
-	{{.}}
-	{{ end }}
-
-	{{ range .aclInRejectRulesConverted}}
-	{{.}}
-	{{ end }}
-
-	{{ range .aclOutDropRules}}
+	{{ range .aclInRulesConverted }}
 	{{.}}
 	{{ end }}
 
-    {{ range .aclInAcceptRules }}
-	{{.}}
-	{{ end }}
-
-	{{ range .aclOutAcceptRules }}
+	{{ range .aclOutRules }}
 	{{.}}
 	{{ end }}
 
@@ -325,7 +334,7 @@ chain fwd{{.chainSeparator}}{{.deviceLabel}} {
 	iifname "{{.hostName}}" ether type != {arp, ip, ip6} drop
 	{{ end }}
 
-	{{ if or .aclInDropRules .aclInRejectRulesConverted .aclInAcceptRules .aclOutDropRules .aclOutAcceptRules .aclInDefaultRuleConverted .aclOutDefaultRule }}
+	{{ if or .aclInRulesConverted .aclOutRules .aclInDefaultRuleConverted .aclOutDefaultRule }}
 	iifname "{{.hostName}}" ether type arp accept
 	iifname "{{.hostName}}" ip6 nexthdr ipv6-icmp icmpv6 type { nd-neighbor-solicit, nd-neighbor-advert } accept
 	oifname "{{.hostName}}" ether type arp accept
@@ -337,7 +346,7 @@ chain fwd{{.chainSeparator}}{{.deviceLabel}} {
 	{{.aclOutDefaultRule}}
 }
 
-{{ if or .aclInDropRules .aclInRejectRulesConverted .aclInAcceptRules .aclOutDropRules .aclOutAcceptRules .aclInDefaultRule .aclOutDefaultRule }}
+{{ if or .aclInRulesConverted .aclOutRules .aclInDefaultRule .aclOutDefaultRule }}
 chain out{{.chainSeparator}}{{.deviceLabel}} {
 	type filter hook output priority filter; policy accept;
 
@@ -352,10 +361,7 @@ chain out{{.chainSeparator}}{{.deviceLabel}} {
 	oifname "{{.hostName}}" ip6 saddr fe80::/10 icmpv6 type {1, 2, 3, 4, 128, 134, 135, 136, 143} accept
 
 	# Network ACLs
-	{{ range .aclOutDropRules}}
-	{{.}}
-	{{ end }}
-	{{ range .aclOutAcceptRules}}
+	{{ range .aclOutRules }}
 	{{.}}
 	{{ end }}
 
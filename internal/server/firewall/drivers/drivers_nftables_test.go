@@ -0,0 +1,72 @@
+package drivers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the bridge ACL rules keep the order they're provided in.
+func TestNftablesACLRulesToNftRulesOrder(t *testing.T) {
+	d := Nftables{}
+
+	rules := []ACLRule{
+		{Direction: "egress", Action: "allow", Protocol: "tcp", DestinationPort: "80"},
+		{Direction: "egress", Action: "reject", Protocol: "tcp"},
+		{Direction: "ingress", Action: "allow", Protocol: "udp"},
+		{Direction: "ingress", Action: "reject"},
+		{Direction: "egress", Action: "reject"},
+		{Direction: "ingress", Action: "reject"},
+	}
+
+	nftRules, err := d.aclRulesToNftRules("veth0", rules)
+	require.NoError(t, err)
+
+	actions := func(rules []string) []string {
+		var actions []string
+		for _, rule := range rules {
+			fields := strings.Fields(rule)
+			actions = append(actions, fields[len(fields)-1])
+		}
+
+		return actions
+	}
+
+	assert.Equal(t, []string{"accept", "reject"}, actions(nftRules.inRules))
+	assert.Equal(t, []string{"accept", "drop"}, actions(nftRules.inRulesConverted))
+	assert.Equal(t, []string{"accept", "drop"}, actions(nftRules.outRules))
+}
+
+// Test the connection tracking rules of a network.
+func TestNftablesNetConntrack(t *testing.T) {
+	d := Nftables{}
+
+	render := func(opts *ConntrackOpts) string {
+		config, err := d.renderNftConfig(nftablesNetConntrack, d.networkConntrackTplFields("incusbr0", opts))
+		require.NoError(t, err)
+
+		return strings.Join(strings.Fields(config), " ")
+	}
+
+	// Limits and timeouts.
+	config := render(&ConntrackOpts{Max: 100, TimeoutTCP: 600, TimeoutUDP: 30})
+	assert.Contains(t, config, "table inet incus {")
+	assert.Contains(t, config, "ct timeout ct.incusbr0.tcp { protocol tcp; policy = { established: 600 }; }")
+	assert.Contains(t, config, "ct timeout ct.incusbr0.udp { protocol udp; policy = { replied: 30 }; }")
+	assert.Contains(t, config, `chain ctprert.incusbr0 { type filter hook prerouting priority raw; policy accept; iifname "incusbr0" meta l4proto tcp ct timeout set "ct.incusbr0.tcp" iifname "incusbr0" meta l4proto udp ct timeout set "ct.incusbr0.udp" }`)
+	assert.Contains(t, config, `chain ctfwd.incusbr0 { type filter hook forward priority filter; policy accept; iifname "incusbr0" ct state new ct count over 100 drop }`)
+
+	// Only a limit.
+	config = render(&ConntrackOpts{Max: 100})
+	assert.NotContains(t, config, "ct timeout")
+	assert.NotContains(t, config, "ctprert.incusbr0")
+	assert.Contains(t, config, "ct count over 100 drop")
+
+	// Only a timeout.
+	config = render(&ConntrackOpts{TimeoutUDP: 30})
+	assert.NotContains(t, config, "ct.incusbr0.tcp")
+	assert.Contains(t, config, "ct.incusbr0.udp")
+	assert.NotContains(t, config, "ctfwd.incusbr0")
+}
@@ -111,6 +111,37 @@ func getOptimisedDNATRanges(forward *AddressForward) map[[2]uint64][2]uint64 {
 	return snatRules
 }
 
+// splitDNATPortMap separates the single listen ports translated to a different single target port from the
+// given DNAT ranges and returns them as a map of listen port to target port along with the remaining ranges.
+//
+// This is the case when a listen port range is translated to a shifted target port range (e.g. "20000-20100"
+// to "30000-30100"), which would otherwise require one rule per port. Nftables is able to apply all of them
+// with a single rule using a port map. Nothing is split out unless there are at least two such ports.
+func splitDNATPortMap(dnatRanges map[[2]uint64][2]uint64) (map[[2]uint64][2]uint64, map[uint64]uint64) {
+	portMap := make(map[uint64]uint64)
+	for listenPortRange, targetPortRange := range dnatRanges {
+		if listenPortRange[1] == 1 && targetPortRange[1] == 1 && listenPortRange[0] != targetPortRange[0] {
+			portMap[listenPortRange[0]] = targetPortRange[0]
+		}
+	}
+
+	if len(portMap) < 2 {
+		return dnatRanges, nil
+	}
+
+	remainingRanges := make(map[[2]uint64][2]uint64, len(dnatRanges)-len(portMap))
+	for listenPortRange, targetPortRange := range dnatRanges {
+		_, found := portMap[listenPortRange[0]]
+		if found && listenPortRange[1] == 1 {
+			continue
+		}
+
+		remainingRanges[listenPortRange] = targetPortRange
+	}
+
+	return remainingRanges, portMap
+}
+
 // subnetMask returns the subnet mask of the given network as a string. Both IPv4 and IPv6 are handled.
 func subnetMask(ipNet *net.IPNet) string {
 	if ipNet.IP.To4() != nil {
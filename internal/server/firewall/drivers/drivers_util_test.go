@@ -145,3 +145,31 @@ func Test_getOptimisedSNATRanges(t *testing.T) {
 		assert.Equal(t, tt.expected, actual)
 	}
 }
+
+func TestSplitDNATPortMap(t *testing.T) {
+	// Shifted port ranges are split out into a port map.
+	dnatRanges, portMap := splitDNATPortMap(getOptimisedDNATRanges(&AddressForward{
+		ListenPorts: []uint64{80, 81, 82, 20000, 20001, 20002},
+		TargetPorts: []uint64{80, 81, 82, 30000, 30001, 30002},
+	}))
+
+	assert.Equal(t, map[[2]uint64][2]uint64{{80, 3}: {80, 3}}, dnatRanges)
+	assert.Equal(t, map[uint64]uint64{20000: 30000, 20001: 30001, 20002: 30002}, portMap)
+
+	listenPorts, targetPortMap := nftablesPortMap(portMap, false)
+	assert.Equal(t, "{ 20000-20002 }", listenPorts)
+	assert.Equal(t, "20000 : 30000, 20001 : 30001, 20002 : 30002", targetPortMap)
+
+	targetPorts, listenPortMap := nftablesPortMap(portMap, true)
+	assert.Equal(t, "{ 30000-30002 }", targetPorts)
+	assert.Equal(t, "30000 : 20000, 30001 : 20001, 30002 : 20002", listenPortMap)
+
+	// A single translated port is left alone.
+	dnatRanges, portMap = splitDNATPortMap(getOptimisedDNATRanges(&AddressForward{
+		ListenPorts: []uint64{80},
+		TargetPorts: []uint64{8080},
+	}))
+
+	assert.Equal(t, map[[2]uint64][2]uint64{{80, 1}: {8080, 1}}, dnatRanges)
+	assert.Nil(t, portMap)
+}
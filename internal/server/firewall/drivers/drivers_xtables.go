@@ -510,6 +510,46 @@ func (d Xtables) NetworkSetup(networkName string, opts Opts) error {
 		}
 	}
 
+	if opts.Conntrack != nil {
+		// Setup connection tracking limits last so that they are processed before any accept rule.
+		err := d.networkSetupConntrack(networkName, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// networkSetupConntrack configures the connection tracking limits for traffic coming from the network.
+// Per-network connection tracking timeouts aren't supported by the xtables driver.
+func (d Xtables) networkSetupConntrack(networkName string, opts Opts) error {
+	if opts.Conntrack.TimeoutTCP > 0 || opts.Conntrack.TimeoutUDP > 0 {
+		return fmt.Errorf("Connection tracking timeouts aren't supported by the xtables firewall driver")
+	}
+
+	if opts.Conntrack.Max == 0 {
+		return nil
+	}
+
+	ipVersions := []uint{}
+	if opts.FeaturesV4 != nil {
+		ipVersions = append(ipVersions, 4)
+	}
+
+	if opts.FeaturesV6 != nil {
+		ipVersions = append(ipVersions, 6)
+	}
+
+	comment := d.networkIPTablesComment(networkName)
+	for _, ipVersion := range ipVersions {
+		// A zero mask makes connlimit count all the connections matching the rule as a single group.
+		err := d.iptablesPrepend(ipVersion, comment, "filter", "FORWARD", "-i", networkName, "-m", "conntrack", "--ctstate", "NEW", "-m", "connlimit", "--connlimit-above", fmt.Sprintf("%d", opts.Conntrack.Max), "--connlimit-mask", "0", "-j", "DROP")
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
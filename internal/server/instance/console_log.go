@@ -0,0 +1,330 @@
+package instance
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/units"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// ConsoleLogLimits represents the size cap and rotation settings of an instance console log.
+type ConsoleLogLimits struct {
+	// Maximum size of the console log file in bytes (0 for no limit).
+	Size int64
+
+	// Number of rotated console log files to keep.
+	Rotate int
+
+	// Whether rotated console log files are gzip compressed.
+	Compress bool
+}
+
+// ConsoleLogLimitsFromConfig returns the console log limits defined in the given expanded instance config.
+func ConsoleLogLimitsFromConfig(config map[string]string) (*ConsoleLogLimits, error) {
+	limits := &ConsoleLogLimits{
+		Rotate:   1,
+		Compress: util.IsTrue(config["console.log.compress"]),
+	}
+
+	if config["console.log.size"] != "" {
+		size, err := units.ParseByteSizeString(config["console.log.size"])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid console.log.size: %w", err)
+		}
+
+		limits.Size = size
+	}
+
+	if config["console.log.rotate"] != "" {
+		rotate, err := strconv.ParseUint(config["console.log.rotate"], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid console.log.rotate: %w", err)
+		}
+
+		limits.Rotate = int(rotate)
+	}
+
+	return limits, nil
+}
+
+// consoleLogSegment represents a rotated console log file.
+type consoleLogSegment struct {
+	index int
+	path  string
+}
+
+// consoleLogSegments returns the rotated segments of the console log at path, most recent first.
+// A segment may exist both compressed and uncompressed, in which case the compressed one is older.
+func consoleLogSegments(path string) ([]consoleLogSegment, error) {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	prefix := filepath.Base(path) + "."
+	segments := []consoleLogSegment{}
+	for _, entry := range entries {
+		name, found := strings.CutPrefix(entry.Name(), prefix)
+		if !found {
+			continue
+		}
+
+		index, err := strconv.Atoi(strings.TrimSuffix(name, ".gz"))
+		if err != nil || index < 1 {
+			continue
+		}
+
+		segments = append(segments, consoleLogSegment{index: index, path: filepath.Join(filepath.Dir(path), entry.Name())})
+	}
+
+	slices.SortFunc(segments, func(a consoleLogSegment, b consoleLogSegment) int {
+		if a.index != b.index {
+			return a.index - b.index
+		}
+
+		// Uncompressed segments are more recent.
+		return strings.Compare(a.path, b.path)
+	})
+
+	return segments, nil
+}
+
+// consoleLogShift moves the rotated segments of the console log at path to the next index, freeing the first
+// one. Segments which would go beyond maxIndex are removed and moved segments are compressed if requested.
+func consoleLogShift(path string, maxIndex int, compress bool) error {
+	segments, err := consoleLogSegments(path)
+	if err != nil {
+		return err
+	}
+
+	// Start from the oldest segment so none get overwritten. Segments are renumbered from their position
+	// rather than their index, so that a segment existing both compressed and uncompressed isn't lost.
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		index := i + 2
+		if index > maxIndex {
+			err := os.Remove(segment.path)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		target := fmt.Sprintf("%s.%d", path, index)
+		if strings.HasSuffix(segment.path, ".gz") {
+			err = os.Rename(segment.path, target+".gz")
+		} else if compress {
+			err = consoleLogCompress(segment.path, target+".gz")
+		} else {
+			err = os.Rename(segment.path, target)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// consoleLogCompress writes a gzip compressed copy of the file at path to target and removes the original.
+func consoleLogCompress(path string, target string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	defer dst.Close()
+
+	gz := gzip.NewWriter(dst)
+
+	_, err = io.Copy(gz, src)
+	if err != nil {
+		return err
+	}
+
+	err = gz.Close()
+	if err != nil {
+		return err
+	}
+
+	err = dst.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// ConsoleLogRotate rotates the console log at path if writing size more bytes to it would go over the limit.
+func ConsoleLogRotate(path string, limits *ConsoleLogLimits, size int64) error {
+	if limits.Size <= 0 {
+		return nil
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	if fi.Size() == 0 || fi.Size()+size <= limits.Size {
+		return nil
+	}
+
+	if limits.Rotate == 0 {
+		return os.Truncate(path, 0)
+	}
+
+	err = consoleLogShift(path, limits.Rotate, limits.Compress)
+	if err != nil {
+		return err
+	}
+
+	if limits.Compress {
+		return consoleLogCompress(path, path+".1.gz")
+	}
+
+	return os.Rename(path, path+".1")
+}
+
+// ConsoleLogAdopt applies the rotation settings to a console log which was rotated into its first segment by
+// liblxc, making room for its next rotation. The configured number of rotated segments is kept after the
+// first one, which remains free for liblxc.
+func ConsoleLogAdopt(path string, limits *ConsoleLogLimits) error {
+	if !util.PathExists(path + ".1") {
+		return nil
+	}
+
+	if limits.Rotate > 1 {
+		return consoleLogShift(path, limits.Rotate+1, limits.Compress)
+	}
+
+	if limits.Compress {
+		return consoleLogCompress(path+".1", path+".1.gz")
+	}
+
+	return nil
+}
+
+// ConsoleLogReadRotated returns the content of the rotated segments of the console log at path, oldest first.
+func ConsoleLogReadRotated(path string) ([]byte, error) {
+	segments, err := consoleLogSegments(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	for i := len(segments) - 1; i >= 0; i-- {
+		f, err := os.Open(segments[i].path)
+		if err != nil {
+			return nil, err
+		}
+
+		var r io.Reader = f
+		if strings.HasSuffix(segments[i].path, ".gz") {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				_ = f.Close()
+				return nil, fmt.Errorf("Failed reading %q: %w", segments[i].path, err)
+			}
+
+			r = gz
+		}
+
+		data, err := io.ReadAll(r)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Failed reading %q: %w", segments[i].path, err)
+		}
+
+		content = append(content, data...)
+	}
+
+	return content, nil
+}
+
+// ConsoleLogRead returns the content of the console log at path, including its rotated segments.
+func ConsoleLogRead(path string) ([]byte, error) {
+	content, err := ConsoleLogReadRotated(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return append(content, data...), nil
+}
+
+// ConsoleLogSize returns the size on disk of the console log at path, including its rotated segments.
+func ConsoleLogSize(path string) (int64, error) {
+	segments, err := consoleLogSegments(path)
+	if err != nil {
+		return 0, err
+	}
+
+	paths := []string{path}
+	for _, segment := range segments {
+		paths = append(paths, segment.path)
+	}
+
+	var size int64
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return 0, err
+		}
+
+		size += fi.Size()
+	}
+
+	return size, nil
+}
+
+// ConsoleLogDeleteRotated removes the rotated segments of the console log at path.
+func ConsoleLogDeleteRotated(path string) error {
+	segments, err := consoleLogSegments(path)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		err := os.Remove(segment.path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
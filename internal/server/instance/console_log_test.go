@@ -0,0 +1,181 @@
+package instance
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleLogLimitsFromConfig(t *testing.T) {
+	limits, err := ConsoleLogLimitsFromConfig(map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, &ConsoleLogLimits{Rotate: 1}, limits)
+
+	limits, err = ConsoleLogLimitsFromConfig(map[string]string{
+		"console.log.size":     "1MiB",
+		"console.log.rotate":   "3",
+		"console.log.compress": "true",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &ConsoleLogLimits{Size: 1024 * 1024, Rotate: 3, Compress: true}, limits)
+}
+
+func TestConsoleLogRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "console.log")
+
+	write := func(content string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = f.WriteString(content)
+		require.NoError(t, err)
+	}
+
+	limits := &ConsoleLogLimits{Size: 10, Rotate: 2}
+
+	// Writing up to the limit doesn't rotate.
+	require.NoError(t, ConsoleLogRotate(path, limits, 5))
+	write("aaaaa")
+	require.NoError(t, ConsoleLogRotate(path, limits, 5))
+	write("bbbbb")
+	assert.NoFileExists(t, path+".1")
+
+	// Going over the limit does.
+	require.NoError(t, ConsoleLogRotate(path, limits, 1))
+	write("c")
+	assert.FileExists(t, path+".1")
+
+	content, err := ConsoleLogRead(path)
+	require.NoError(t, err)
+	assert.Equal(t, "aaaaabbbbbc", string(content))
+
+	// Only the configured number of rotated files are kept, oldest being dropped first.
+	for _, chunk := range []string{"dddddddddd", "eeeeeeeeee", "ffffffffff"} {
+		require.NoError(t, ConsoleLogRotate(path, limits, int64(len(chunk))))
+		write(chunk)
+	}
+
+	assert.FileExists(t, path+".2")
+	assert.NoFileExists(t, path+".3")
+
+	content, err = ConsoleLogRead(path)
+	require.NoError(t, err)
+	assert.Equal(t, "ddddddddddeeeeeeeeeeffffffffff", string(content))
+
+	size, err := ConsoleLogSize(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), size)
+
+	// Rotated files can be compressed, older uncompressed ones are compressed as they get shifted.
+	limits.Compress = true
+	require.NoError(t, ConsoleLogRotate(path, limits, 1))
+	write("g")
+	assert.FileExists(t, path+".1.gz")
+	assert.FileExists(t, path+".2.gz")
+	assert.NoFileExists(t, path+".1")
+	assert.NoFileExists(t, path+".2")
+
+	content, err = ConsoleLogRead(path)
+	require.NoError(t, err)
+	assert.Equal(t, "eeeeeeeeeeffffffffffg", string(content))
+
+	rotated, err := ConsoleLogReadRotated(path)
+	require.NoError(t, err)
+	assert.Equal(t, "eeeeeeeeeeffffffffff", string(rotated))
+
+	// Without rotated files, the log is truncated.
+	limits.Rotate = 0
+	write(strings.Repeat("h", 10))
+	require.NoError(t, ConsoleLogRotate(path, limits, 1))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+
+	// Rotated files can be removed.
+	require.NoError(t, ConsoleLogDeleteRotated(path))
+	assert.NoFileExists(t, path+".1.gz")
+	assert.NoFileExists(t, path+".2.gz")
+}
+
+func TestConsoleLogAdopt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "console.log")
+	limits := &ConsoleLogLimits{Size: 10, Rotate: 2, Compress: true}
+
+	// Files rotated by liblxc are shifted to make room for the next one.
+	require.NoError(t, os.WriteFile(path+".1", []byte("aaaaa"), 0o600))
+	require.NoError(t, ConsoleLogAdopt(path, limits))
+	assert.NoFileExists(t, path+".1")
+	assert.FileExists(t, path+".2.gz")
+
+	require.NoError(t, os.WriteFile(path+".1", []byte("bbbbb"), 0o600))
+	require.NoError(t, os.WriteFile(path, []byte("c"), 0o600))
+
+	content, err := ConsoleLogRead(path)
+	require.NoError(t, err)
+	assert.Equal(t, "aaaaabbbbbc", string(content))
+
+	// With a single rotated file, it is compressed in place.
+	limits.Rotate = 1
+	require.NoError(t, ConsoleLogAdopt(path, limits))
+	assert.FileExists(t, path+".1.gz")
+	assert.NoFileExists(t, path+".1")
+}
+
+func TestConsoleLogRotateMultiple(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "console.log")
+	limits := &ConsoleLogLimits{Size: 5, Rotate: 3}
+
+	for _, chunk := range []string{"aaaaa", "bbbbb", "ccccc", "ddddd", "eeeee"} {
+		require.NoError(t, ConsoleLogRotate(path, limits, int64(len(chunk))))
+		require.NoError(t, os.WriteFile(path, []byte(chunk), 0o600))
+	}
+
+	// All the configured rotated files are kept, each shifted along.
+	for index, chunk := range map[string]string{".1": "ddddd", ".2": "ccccc", ".3": "bbbbb"} {
+		data, err := os.ReadFile(path + index)
+		require.NoError(t, err)
+		assert.Equal(t, chunk, string(data))
+	}
+
+	assert.NoFileExists(t, path+".4")
+
+	content, err := ConsoleLogRead(path)
+	require.NoError(t, err)
+	assert.Equal(t, "bbbbbcccccdddddeeeee", string(content))
+}
+
+func TestConsoleLogAdoptMultiple(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "console.log")
+	limits := &ConsoleLogLimits{Size: 5, Rotate: 3, Compress: true}
+
+	// Simulate liblxc rotating the log into its first segment before each adoption.
+	for _, chunk := range []string{"aaaaa", "bbbbb", "ccccc", "ddddd", "eeeee"} {
+		require.NoError(t, os.WriteFile(path+".1", []byte(chunk), 0o600))
+		require.NoError(t, ConsoleLogAdopt(path, limits))
+	}
+
+	assert.NoFileExists(t, path+".1")
+	assert.FileExists(t, path+".2.gz")
+	assert.FileExists(t, path+".3.gz")
+	assert.FileExists(t, path+".4.gz")
+	assert.NoFileExists(t, path+".5.gz")
+
+	rotated, err := ConsoleLogReadRotated(path)
+	require.NoError(t, err)
+	assert.Equal(t, "cccccdddddeeeee", string(rotated))
+
+	// A segment existing both compressed and uncompressed is shifted without losing either.
+	require.NoError(t, os.WriteFile(path+".1", []byte("fffff"), 0o600))
+	require.NoError(t, os.Rename(path+".2.gz", path+".1.gz"))
+	require.NoError(t, ConsoleLogAdopt(path, limits))
+
+	rotated, err = ConsoleLogReadRotated(path)
+	require.NoError(t, err)
+	assert.Equal(t, "dddddeeeeefffff", string(rotated))
+}
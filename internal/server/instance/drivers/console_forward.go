@@ -0,0 +1,326 @@
+package drivers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/lxc/incus/v6/internal/server/logship"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// consoleForwardInterval is how often the logs of a container are checked for new output.
+// Virtual machines are instead checked whenever their QMP monitor checks on them.
+const consoleForwardInterval = time.Second
+
+// consoleForwardQueue is the number of console lines queued for a slow target before dropping new ones.
+const consoleForwardQueue = 4096
+
+// consoleForwardMaxLine is the length after which console output is forwarded without waiting for a line break.
+const consoleForwardMaxLine = 8 * 1024
+
+// consoleForwardMaxRead is the maximum amount of console output read at each check.
+const consoleForwardMaxRead = 1024 * 1024
+
+// consoleForwardDropWarning is the minimum delay between two warnings about dropped console lines.
+const consoleForwardDropWarning = time.Minute
+
+// consoleForwarders holds the console forwarders of the running instances, keyed by project and instance name.
+var consoleForwarders = map[string]*consoleForwarder{}
+
+var consoleForwardersMu sync.Mutex
+
+// consoleForwarder follows the logs of a running instance and forwards new output to a log target.
+type consoleForwarder struct {
+	logs   []*consoleForwardLog
+	flush  func() error
+	logger logger.Logger
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// consoleForwardLog is a log file followed by a consoleForwarder.
+type consoleForwardLog struct {
+	name    string
+	path    string
+	shipper *logship.Shipper
+	logger  logger.Logger
+
+	offset  int64
+	inode   uint64
+	partial []byte
+
+	reportedDropped uint64
+	lastDropWarning time.Time
+}
+
+// consoleForwardTarget returns the console log forwarding target of the instance, or nil if disabled.
+// The instance configuration takes precedence over the project one.
+func (d *common) consoleForwardTarget() (*logship.Target, error) {
+	value := d.expandedConfig["console.log.forward"]
+	if value == "" {
+		value = d.project.Config["console.log.forward"]
+	}
+
+	if value == "" || value == "none" {
+		return nil, nil
+	}
+
+	return logship.ParseTarget(value)
+}
+
+// consoleForwardStart starts forwarding the console output of the instance if configured, along with its init log if any.
+// The flush function, if any, is called before each check to bring the console log file up to date.
+// When starting the instance, the init log is forwarded from its beginning as it's rotated before each start.
+// Returns the new forwarder, or nil if forwarding isn't enabled.
+func (d *common) consoleForwardStart(flush func() error, initLog string, starting bool) *consoleForwarder {
+	target, err := d.consoleForwardTarget()
+	if err != nil {
+		d.logger.Warn("Invalid console log forwarding target", logger.Ctx{"err": err})
+		return nil
+	}
+
+	// Stop any previous forwarder, such as when the instance is rebooting.
+	d.consoleForwardStop()
+
+	if target == nil {
+		return nil
+	}
+
+	f := &consoleForwarder{
+		flush:  flush,
+		logger: d.logger.AddContext(logger.Ctx{"target": target.String()}),
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	logs := map[string]string{"console": d.ConsoleBufferLogPath()}
+	if initLog != "" {
+		logs["init"] = initLog
+	}
+
+	for _, name := range []string{"console", "init"} {
+		path, ok := logs[name]
+		if !ok {
+			continue
+		}
+
+		fields := map[string]string{
+			"instance":      d.name,
+			"instance_type": d.dbType.String(),
+			"project":       d.project.Name,
+			"log":           name,
+		}
+
+		if d.state.ServerName != "" && d.state.ServerName != "none" {
+			fields["location"] = d.state.ServerName
+		}
+
+		l := &consoleForwardLog{
+			name:    name,
+			path:    path,
+			shipper: logship.NewShipper(target, "incus", fields, consoleForwardQueue),
+			logger:  f.logger.AddContext(logger.Ctx{"log": name}),
+		}
+
+		// Only forward the output produced from now on, except for the init log of a starting instance.
+		fi, err := os.Stat(l.path)
+		if err == nil && (name != "init" || !starting) {
+			l.offset = fi.Size()
+			l.inode = consoleForwardInode(fi)
+		}
+
+		f.logs = append(f.logs, l)
+	}
+
+	consoleForwardersMu.Lock()
+	consoleForwarders[project.Instance(d.project.Name, d.name)] = f
+	consoleForwardersMu.Unlock()
+
+	f.logger.Debug("Started forwarding console output")
+
+	go f.run()
+
+	return f
+}
+
+// consoleForwardStop stops forwarding the console output of the instance, forwarding any remaining output first.
+// It doesn't wait for the remaining output to be delivered.
+func (d *common) consoleForwardStop() {
+	key := project.Instance(d.project.Name, d.name)
+
+	consoleForwardersMu.Lock()
+	f, ok := consoleForwarders[key]
+	if ok {
+		delete(consoleForwarders, key)
+	}
+
+	consoleForwardersMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	close(f.stop)
+	<-f.done
+
+	for _, l := range f.logs {
+		l.logger.Debug("Stopped forwarding console output", logger.Ctx{"dropped": l.shipper.Dropped()})
+	}
+}
+
+// consoleForwardInode returns the inode number of a file.
+func consoleForwardInode(fi fs.FileInfo) uint64 {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0
+	}
+
+	return st.Ino
+}
+
+// notify requests a check of the logs without waiting for it.
+func (f *consoleForwarder) notify() {
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run checks the logs for new output until stopped.
+// Without a flush function the logs are checked periodically, otherwise only when notified.
+func (f *consoleForwarder) run() {
+	defer close(f.done)
+
+	var tick <-chan time.Time
+	if f.flush == nil {
+		ticker := time.NewTicker(consoleForwardInterval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-f.stop:
+			f.check()
+			for _, l := range f.logs {
+				if len(l.partial) > 0 {
+					l.send(l.partial)
+				}
+
+				l.shipper.Close()
+			}
+
+			return
+		case <-tick:
+			f.check()
+		case <-f.wake:
+			f.check()
+		}
+	}
+}
+
+// check forwards the output added to the logs since the last check.
+func (f *consoleForwarder) check() {
+	if f.flush != nil {
+		// Forward what's left in the current console log as flushing may rotate it.
+		f.logs[0].read()
+
+		err := f.flush()
+		if err != nil {
+			f.logger.Debug("Failed flushing console output", logger.Ctx{"err": err})
+		}
+	}
+
+	for _, l := range f.logs {
+		l.read()
+
+		dropped := l.shipper.Dropped()
+		if dropped > l.reportedDropped && time.Since(l.lastDropWarning) > consoleForwardDropWarning {
+			l.logger.Warn("Dropped console lines as the log target isn't keeping up", logger.Ctx{"dropped": dropped - l.reportedDropped, "total": dropped})
+			l.reportedDropped = dropped
+			l.lastDropWarning = time.Now()
+		}
+	}
+}
+
+// read forwards the new lines of the log file.
+func (l *consoleForwardLog) read() {
+	fi, err := os.Stat(l.path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			l.logger.Debug("Failed checking log", logger.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	// Start from the beginning of the file if it was rotated or truncated.
+	inode := consoleForwardInode(fi)
+	if inode != l.inode || fi.Size() < l.offset {
+		l.inode = inode
+		l.offset = 0
+	}
+
+	if fi.Size() == l.offset {
+		return
+	}
+
+	file, err := os.Open(l.path)
+	if err != nil {
+		l.logger.Debug("Failed opening log", logger.Ctx{"err": err})
+		return
+	}
+
+	defer func() { _ = file.Close() }()
+
+	_, err = file.Seek(l.offset, io.SeekStart)
+	if err != nil {
+		return
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(file, consoleForwardMaxRead))
+	if err != nil {
+		l.logger.Debug("Failed reading log", logger.Ctx{"err": err})
+	}
+
+	l.offset += int64(len(buf))
+
+	data := append(l.partial, buf...)
+	for {
+		line, rest, found := bytes.Cut(data, []byte("\n"))
+		if !found {
+			break
+		}
+
+		l.send(line)
+		data = rest
+	}
+
+	if len(data) > consoleForwardMaxLine {
+		l.send(data)
+		data = nil
+	}
+
+	l.partial = bytes.Clone(data)
+}
+
+// send forwards a single line of output.
+func (l *consoleForwardLog) send(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+
+	l.shipper.Send(string(line))
+}
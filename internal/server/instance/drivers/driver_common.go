@@ -18,24 +18,30 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/sys/unix"
 
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/server/backup"
 	"github.com/lxc/incus/v6/internal/server/db"
 	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
 	"github.com/lxc/incus/v6/internal/server/device"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/device/nictype"
+	"github.com/lxc/incus/v6/internal/server/dnsmasq"
 	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v6/internal/server/instance/operationlock"
 	"github.com/lxc/incus/v6/internal/server/lifecycle"
 	"github.com/lxc/incus/v6/internal/server/locking"
+	"github.com/lxc/incus/v6/internal/server/network"
 	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/resources"
 	"github.com/lxc/incus/v6/internal/server/state"
 	storagePools "github.com/lxc/incus/v6/internal/server/storage"
+	"github.com/lxc/incus/v6/internal/server/warnings"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
@@ -50,6 +56,12 @@ var (
 	muInstancesLastRestart sync.Mutex
 )
 
+// Track instances waiting for the restart policy backoff before being restarted.
+var (
+	instancesPendingRestart   = map[int]context.CancelFunc{}
+	muInstancesPendingRestart sync.Mutex
+)
+
 // ErrExecCommandNotFound indicates the command is not found.
 var ErrExecCommandNotFound = api.StatusErrorf(http.StatusBadRequest, "Command not found")
 
@@ -62,6 +74,12 @@ var ErrInstanceIsStopped error = api.StatusErrorf(http.StatusBadRequest, "The in
 // muNUMA is used to serialize NUMA node selection.
 var muNUMA sync.Mutex
 
+// muHWAddrRange is used to serialize MAC address allocation from the project ranges.
+var muHWAddrRange sync.Mutex
+
+// instanceLastUsedInterval is the minimum time between two updates of an instance's last used date.
+const instanceLastUsedInterval = 5 * time.Minute
+
 // deviceManager is an interface that allows managing device lifecycle.
 type deviceManager interface {
 	deviceAdd(dev device.Device, instanceRunning bool) error
@@ -84,6 +102,7 @@ type common struct {
 	expandedDevices deviceConfig.Devices
 	expiryDate      time.Time
 	id              int
+	lastStartedDate time.Time
 	lastUsedDate    time.Time
 	localConfig     map[string]string
 	localDevices    deviceConfig.Devices
@@ -199,6 +218,166 @@ func (d *common) shouldAutoRestart() bool {
 	return false
 }
 
+// restartPolicyMaxBackoff is the longest delay applied between two restarts by boot.restart.policy.
+const restartPolicyMaxBackoff = 5 * time.Minute
+
+// restartPolicyCheck returns whether boot.restart.policy allows restarting an instance which stopped on its own
+// after having already been restarted restartCount times, and if so, how long to wait before doing so.
+// The failure argument indicates whether the instance stopped unexpectedly rather than being shut down cleanly.
+func restartPolicyCheck(config map[string]string, restartCount int, failure bool) (bool, time.Duration) {
+	switch config["boot.restart.policy"] {
+	case "always":
+	case "on-failure":
+		if !failure {
+			return false, 0
+		}
+
+	default:
+		return false, 0
+	}
+
+	maxRetries := 10
+	if config["boot.restart.max_retries"] != "" {
+		maxRetries, _ = strconv.Atoi(config["boot.restart.max_retries"])
+	}
+
+	if maxRetries > 0 && restartCount >= maxRetries {
+		return false, 0
+	}
+
+	backoff := time.Second
+	if config["boot.restart.backoff"] != "" {
+		seconds, _ := strconv.Atoi(config["boot.restart.backoff"])
+		backoff = time.Duration(seconds) * time.Second
+	}
+
+	// Double the delay on every consecutive restart.
+	delay := backoff
+	for range restartCount {
+		delay *= 2
+		if delay >= restartPolicyMaxBackoff {
+			return true, restartPolicyMaxBackoff
+		}
+	}
+
+	return true, min(delay, restartPolicyMaxBackoff)
+}
+
+// shouldRestart returns whether the instance should be restarted after it stopped on its own and how long to
+// wait before doing so. Restarts done under boot.restart.policy are counted in volatile.restart.count.
+func (d *common) shouldRestart(failure bool) (bool, time.Duration) {
+	// Fallback to boot.autorestart when no restart policy is set.
+	if d.expandedConfig["boot.restart.policy"] == "" {
+		return d.shouldAutoRestart(), 0
+	}
+
+	restartCount, _ := strconv.Atoi(d.localConfig["volatile.restart.count"])
+
+	restart, delay := restartPolicyCheck(d.expandedConfig, restartCount, failure)
+	if !restart {
+		// Check whether the restart was only prevented by boot.restart.max_retries.
+		allowed, _ := restartPolicyCheck(d.expandedConfig, 0, failure)
+		if allowed {
+			d.logger.Warn("Not restarting instance as the maximum number of restarts was reached", logger.Ctx{"restarts": restartCount})
+		}
+
+		return false, 0
+	}
+
+	err := d.VolatileSet(map[string]string{"volatile.restart.count": strconv.Itoa(restartCount + 1)})
+	if err != nil {
+		d.logger.Warn("Failed recording restart count", logger.Ctx{"err": err})
+	}
+
+	return true, delay
+}
+
+// restartAllowed returns whether an instance which stopped can be restarted by boot.autorestart or
+// boot.restart.policy. This is only the case when it stopped on its own, not when it was rebooted or
+// stopped through Incus.
+func restartAllowed(target string, op *operationlock.InstanceOperation) bool {
+	return target != "reboot" && op.GetInstanceInitiated() && !op.GetUserInitiated()
+}
+
+// restartWait waits for the restart policy backoff before restarting the instance.
+// It returns false if the wait was interrupted, either because the daemon is shutting down or because the
+// pending restart was cancelled through restartCancel.
+func (d *common) restartWait(delay time.Duration) bool {
+	ctx, cancel := context.WithCancel(d.state.ShutdownCtx)
+	defer cancel()
+
+	muInstancesPendingRestart.Lock()
+	instancesPendingRestart[d.id] = cancel
+	muInstancesPendingRestart.Unlock()
+
+	defer func() {
+		muInstancesPendingRestart.Lock()
+		delete(instancesPendingRestart, d.id)
+		muInstancesPendingRestart.Unlock()
+	}()
+
+	d.logger.Info("Waiting before restarting instance", logger.Ctx{"delay": delay})
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		d.logger.Info("Cancelled pending instance restart")
+		return false
+	}
+
+	return true
+}
+
+// restartCancel cancels a restart waiting for the restart policy backoff.
+// It returns whether a pending restart was cancelled.
+func (d *common) restartCancel() bool {
+	muInstancesPendingRestart.Lock()
+	defer muInstancesPendingRestart.Unlock()
+
+	cancel, ok := instancesPendingRestart[d.id]
+	if !ok {
+		return false
+	}
+
+	cancel()
+	delete(instancesPendingRestart, d.id)
+
+	return true
+}
+
+// restartCountReset clears the count of restarts done under boot.restart.policy.
+// This is done whenever the instance is started by the user.
+func (d *common) restartCountReset() {
+	if d.localConfig["volatile.restart.count"] == "" {
+		return
+	}
+
+	err := d.VolatileSet(map[string]string{"volatile.restart.count": ""})
+	if err != nil {
+		d.logger.Warn("Failed resetting restart count", logger.Ctx{"err": err})
+	}
+}
+
+// restartCount returns the number of restarts done under boot.restart.policy since the instance was last
+// started by the user.
+func (d *common) restartCount() int64 {
+	restartCount, _ := strconv.ParseInt(d.localConfig["volatile.restart.count"], 10, 64)
+	return restartCount
+}
+
+// restartLifecycleContext returns the lifecycle event context for a restart of the instance,
+// including the restart count when it was restarted under boot.restart.policy.
+func (d *common) restartLifecycleContext(autoRestart bool) map[string]any {
+	if !autoRestart || d.expandedConfig["boot.restart.policy"] == "" {
+		return nil
+	}
+
+	return map[string]any{"restart_count": d.restartCount()}
+}
+
 // ID gets instances's ID.
 func (d *common) ID() int {
 	return d.id
@@ -209,6 +388,11 @@ func (d *common) LastUsedDate() time.Time {
 	return d.lastUsedDate
 }
 
+// LastStartedDate returns the instance's last started date.
+func (d *common) LastStartedDate() time.Time {
+	return d.lastStartedDate
+}
+
 // LocalConfig returns the instance's local config.
 func (d *common) LocalConfig() map[string]string {
 	return d.localConfig
@@ -524,6 +708,28 @@ func (d *common) StoragePool() (string, error) {
 // SECTION: internal functions
 //
 
+// validateVolatileChanges checks that user requested changes to volatile keys only happen on stopped instances.
+// Volatile keys hold the runtime state of the instance, so altering them under a running instance can corrupt it.
+// Only the volatile keys named in the request are checked and passing back their current value is allowed,
+// so that clients can send back the full configuration they retrieved.
+func (d *common) validateVolatileChanges(requestConfig map[string]string, currentConfig map[string]string, isRunning bool) error {
+	if !isRunning {
+		return nil
+	}
+
+	for k, v := range requestConfig {
+		if !strings.HasPrefix(k, internalInstance.ConfigVolatilePrefix) {
+			continue
+		}
+
+		if currentConfig[k] != v {
+			return fmt.Errorf("Volatile key %q can only be modified while the instance is stopped", k)
+		}
+	}
+
+	return nil
+}
+
 // deviceVolatileReset resets a device's volatile data when its removed or updated in such a way
 // that it is removed then added immediately afterwards.
 func (d *common) deviceVolatileReset(devName string, oldConfig, newConfig deviceConfig.Device) error {
@@ -843,6 +1049,124 @@ func (d *common) snapshotCommon(inst instance.Instance, name string, expiry time
 	return nil
 }
 
+// snapshotHookDefaultTimeout is the time given to a snapshot hook to complete when snapshots.hooks.timeout isn't set.
+const snapshotHookDefaultTimeout = 30 * time.Second
+
+// snapshotHookKillTimeout is the time given to a timed out snapshot hook to exit once killed.
+const snapshotHookKillTimeout = 10 * time.Second
+
+// snapshotHookOutputMax is the maximum amount of the snapshot hook output recorded in the operation metadata.
+const snapshotHookOutputMax = 4096
+
+// runSnapshotHook runs the command configured for the given snapshot hook ("pre" or "post") inside of the
+// instance and records its exit status and output in the operation metadata.
+// Returns an error if the command can't be run, fails or times out.
+func (d *common) runSnapshotHook(inst instance.Instance, hook string) error {
+	command := d.expandedConfig["snapshots.hooks."+hook]
+	if command == "" || !inst.IsRunning() {
+		return nil
+	}
+
+	timeout := snapshotHookDefaultTimeout
+	if d.expandedConfig["snapshots.hooks.timeout"] != "" {
+		seconds, err := strconv.ParseUint(d.expandedConfig["snapshots.hooks.timeout"], 10, 32)
+		if err != nil {
+			return fmt.Errorf("Invalid snapshots.hooks.timeout: %w", err)
+		}
+
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	// Record the output in temporary files.
+	outputs := make([]*os.File, 2)
+	for i := range outputs {
+		f, err := os.CreateTemp(d.LogPath(), "snapshot_hook_")
+		if err != nil {
+			return fmt.Errorf("Failed creating snapshot %s hook output file: %w", hook, err)
+		}
+
+		defer func() {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}()
+
+		outputs[i] = f
+	}
+
+	d.logger.Debug("Running snapshot hook", logger.Ctx{"hook": hook, "command": command})
+
+	req := api.InstanceExecPost{
+		Command:     []string{"/bin/sh", "-c", command},
+		Environment: map[string]string{"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+		Cwd:         "/",
+	}
+
+	cmd, err := inst.Exec(req, nil, outputs[0], outputs[1])
+	if err != nil {
+		return fmt.Errorf("Failed running snapshot %s hook: %w", hook, err)
+	}
+
+	type hookResult struct {
+		status int
+		err    error
+	}
+
+	done := make(chan hookResult, 1)
+	go func() {
+		status, err := cmd.Wait()
+		done <- hookResult{status: status, err: err}
+	}()
+
+	var result hookResult
+
+	select {
+	case result = <-done:
+	case <-time.After(timeout):
+		// Kill the hook along with any process it started and wait for it to be gone.
+		err := cmd.Signal(unix.SIGKILL)
+		if err != nil {
+			d.logger.Warn("Failed killing snapshot hook", logger.Ctx{"hook": hook, "err": err})
+		}
+
+		select {
+		case <-done:
+		case <-time.After(snapshotHookKillTimeout):
+			d.logger.Warn("Snapshot hook still running after being killed", logger.Ctx{"hook": hook})
+		}
+
+		result = hookResult{status: -1, err: fmt.Errorf("Timed out after %s", timeout)}
+	}
+
+	// Surface the hook result in the operation.
+	metadata := map[string]any{"return": result.status}
+	for i, name := range []string{"stdout", "stderr"} {
+		content, err := os.ReadFile(outputs[i].Name())
+		if err != nil {
+			continue
+		}
+
+		if len(content) > snapshotHookOutputMax {
+			content = content[len(content)-snapshotHookOutputMax:]
+		}
+
+		metadata[name] = string(content)
+	}
+
+	if d.op != nil {
+		_ = d.op.ExtendMetadata(map[string]any{"snapshot_hook_" + hook: metadata})
+	}
+
+	if result.err != nil {
+		return fmt.Errorf("Snapshot %s hook failed: %w", hook, result.err)
+	}
+
+	if result.status != 0 {
+		return fmt.Errorf("Snapshot %s hook failed with exit status %d", hook, result.status)
+	}
+
+	return nil
+}
+
 // updateProgress updates the operation metadata with a new progress string.
 func (d *common) updateProgress(progress string) {
 	if d.op == nil {
@@ -888,6 +1212,54 @@ func (d *common) insertConfigkey(key string, value string) (string, error) {
 	return value, nil
 }
 
+// allocateInterfaceHWAddr generates a MAC address for a network device and stores it in the given config key.
+// When the project has a MAC address range, the address is drawn from it and stored in the same transaction
+// as the lookup of the addresses in use so that concurrent allocations can't get the same address.
+// Returns the stored value, which may have been filled in by something else.
+func (d *common) allocateInterfaceHWAddr(key string) (string, error) {
+	hwaddrRange := d.project.Config["network.hwaddr_range"]
+	if hwaddrRange == "" {
+		hwaddr, err := instance.DeviceNextInterfaceHWAddr()
+		if err != nil {
+			return "", err
+		}
+
+		return d.insertConfigkey(key, hwaddr)
+	}
+
+	muHWAddrRange.Lock()
+	defer muHWAddrRange.Unlock()
+
+	var hwaddr string
+	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Check if something else filled it in behind our back.
+		existingValue, err := tx.GetInstanceConfig(ctx, d.id, key)
+		if err == nil {
+			hwaddr = existingValue
+			return nil
+		} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		usedHWAddrs, err := tx.GetInstanceHWAddrs(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting MAC addresses in use: %w", err)
+		}
+
+		hwaddr, err = instance.DeviceNextInterfaceHWAddrInRange(hwaddrRange, usedHWAddrs)
+		if err != nil {
+			return fmt.Errorf("Failed allocating MAC address from project %q: %w", d.project.Name, err)
+		}
+
+		return tx.CreateInstanceConfig(ctx, d.id, map[string]string{key: hwaddr})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hwaddr, nil
+}
+
 // isRunningStatusCode returns if instance is running from status code.
 func (d *common) isRunningStatusCode(statusCode api.StatusCode) bool {
 	return statusCode != api.Error && statusCode != api.Stopped
@@ -908,10 +1280,19 @@ func (d *common) isStartableStatusCode(statusCode api.StatusCode) error {
 	return nil
 }
 
+// checkSnapshotsEnabled returns an error if snapshots are disabled for the instance.
+func (d *common) checkSnapshotsEnabled() error {
+	if util.IsFalse(d.expandedConfig["snapshots.enabled"]) {
+		return api.StatusErrorf(http.StatusBadRequest, "Snapshots are disabled for instance %q (snapshots.enabled is false)", d.name)
+	}
+
+	return nil
+}
+
 // getStartupSnapNameAndExpiry returns the name and expiry for a snapshot to be taken at startup.
 func (d *common) getStartupSnapNameAndExpiry(inst instance.Instance) (string, *time.Time, error) {
 	schedule := strings.ToLower(d.expandedConfig["snapshots.schedule"])
-	if schedule == "" {
+	if schedule == "" || util.IsFalse(d.expandedConfig["snapshots.enabled"]) {
 		return "", nil, nil
 	}
 
@@ -933,6 +1314,85 @@ func (d *common) getStartupSnapNameAndExpiry(inst instance.Instance) (string, *t
 	return name, &expiry, nil
 }
 
+// waitDependencies waits for the managed networks and storage pools used by the instance to become available
+// on this server, for up to the number of seconds set in boot.ready_timeout.
+// Without a timeout set, it returns straight away and any unavailable dependency is reported by the start checks.
+func (d *common) waitDependencies() error {
+	timeout, err := strconv.ParseInt(d.expandedConfig["boot.ready_timeout"], 10, 64)
+	if err != nil || timeout <= 0 {
+		return nil
+	}
+
+	networkProjectName := project.NetworkProjectFromRecord(&d.project)
+
+	// pending returns a description of the first dependency not yet available.
+	pending := func() string {
+		for _, entry := range d.expandedDevices.Sorted() {
+			dev := entry.Config
+
+			if dev["type"] == "nic" && dev["network"] != "" && !network.IsAvailable(networkProjectName, dev["network"]) {
+				return fmt.Sprintf("network %q", dev["network"])
+			}
+
+			if dev["type"] == "disk" && dev["pool"] != "" && !storagePools.IsAvailable(dev["pool"]) {
+				return fmt.Sprintf("storage pool %q", dev["pool"])
+			}
+		}
+
+		return ""
+	}
+
+	waitingFor := pending()
+	if waitingFor == "" {
+		return nil
+	}
+
+	d.logger.Info("Waiting for instance dependencies to become ready", logger.Ctx{"dependency": waitingFor, "timeout": timeout})
+
+	// Stop waiting when the server shuts down or the operation gets cancelled.
+	ctx, cancel := context.WithCancel(d.state.ShutdownCtx)
+	defer cancel()
+
+	progress := func(string) {}
+	if d.op != nil {
+		stop := context.AfterFunc(d.op.Context(), cancel)
+		defer stop()
+
+		progress = func(waitingFor string) {
+			_ = d.op.ExtendMetadata(map[string]any{"waiting_for": waitingFor})
+		}
+	}
+
+	return waitDependenciesReady(ctx, pending, time.Duration(timeout)*time.Second, time.Second, progress)
+}
+
+// waitDependenciesReady calls pending every interval until it returns an empty string, the timeout expires or the
+// context is done. The dependency being waited for is reported through progress, an empty one once all are ready.
+func waitDependenciesReady(ctx context.Context, pending func() string, timeout time.Duration, interval time.Duration, progress func(string)) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		waitingFor := pending()
+		progress(waitingFor)
+
+		if waitingFor == "" {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Stopped waiting for %s to become ready: %w", waitingFor, context.Cause(ctx))
+		case <-timer.C:
+			return fmt.Errorf("%w: Timed out after %s waiting for %s to become ready", instance.ErrDependenciesNotReady, timeout, waitingFor)
+		case <-ticker.C:
+		}
+	}
+}
+
 // validateStartup checks any constraints that would prevent start up from succeeding under normal circumstances.
 func (d *common) validateStartup(stateful bool, statusCode api.StatusCode) error {
 	// Because the root disk is special and is mounted before the root disk device is setup we duplicate the
@@ -1068,16 +1528,49 @@ func (d *common) recordLastState() error {
 		}
 
 		// Update time instance last started time.
-		err = tx.UpdateInstanceLastUsedDate(d.id, time.Now().UTC())
+		now := time.Now().UTC()
+		err = tx.UpdateInstanceLastStartedDate(d.id, now)
 		if err != nil {
-			err = fmt.Errorf("Error updating instance last used: %w", err)
+			err = fmt.Errorf("Error updating instance last started: %w", err)
 			return err
 		}
 
+		d.lastStartedDate = now
+		d.lastUsedDate = now
+
 		return nil
 	})
 }
 
+// resolveDeviceConflictWarnings resolves the device conflict warnings of the instance once it could be started,
+// its devices no longer conflicting with other instances.
+func (d *common) resolveDeviceConflictWarnings() {
+	err := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(d.state.DB.Cluster, d.project.Name, warningtype.InstanceDeviceConflict, dbCluster.TypeInstance, d.id)
+	if err != nil {
+		d.logger.Warn("Failed to resolve device conflict warnings", logger.Ctx{"err": err})
+	}
+}
+
+// recordLastUsed records the instance as being used through exec or console access.
+// To avoid a database write on every access, the date is only updated if the previous one is older than
+// instanceLastUsedInterval.
+func (d *common) recordLastUsed() {
+	now := time.Now().UTC()
+	if now.Sub(d.lastUsedDate) < instanceLastUsedInterval {
+		return
+	}
+
+	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateInstanceLastUsedDate(d.id, now)
+	})
+	if err != nil {
+		d.logger.Warn("Failed updating instance last used date", logger.Ctx{"err": err})
+		return
+	}
+
+	d.lastUsedDate = now
+}
+
 func (d *common) setCoreSched(pids []int) error {
 	if !d.state.OS.CoreScheduling {
 		return nil
@@ -1292,9 +1785,18 @@ func (d *common) devicesAdd(inst instance.Instance, instanceRunning bool) (rever
 			// This will allow instances to be created with conflicting devices (such as when copying
 			// or restoring a backup) and allows the user to manually fix the conflicts in order to
 			// allow the instance to start.
+			// A warning is recorded so that the conflict is reported to all clients until the instance
+			// manages to start.
 			if api.StatusErrorCheck(err, http.StatusConflict) {
 				d.logger.Error("Failed add validation for device, skipping add action", logger.Ctx{"device": entry.Name, "err": err})
 
+				warnErr := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+					return tx.UpsertWarningLocalNode(ctx, d.project.Name, dbCluster.TypeInstance, d.id, warningtype.InstanceDeviceConflict, fmt.Sprintf("Device %q: %v", entry.Name, err))
+				})
+				if warnErr != nil {
+					d.logger.Warn("Failed to create device conflict warning", logger.Ctx{"device": entry.Name, "err": warnErr})
+				}
+
 				continue
 			}
 
@@ -1318,6 +1820,115 @@ func (d *common) devicesAdd(inst instance.Instance, instanceRunning bool) (rever
 	return cleanup, nil
 }
 
+// loadKernelModules loads the kernel modules listed in linux.kernel_modules on the host.
+// This must happen before any of the instance's devices are started as those may rely on them.
+func (d *common) loadKernelModules(value string) error {
+	for _, module := range util.SplitNTrimSpace(value, ",", -1, true) {
+		if module == "" {
+			continue
+		}
+
+		err := linux.LoadModule(module)
+		if err != nil {
+			return fmt.Errorf("Failed to load kernel module %q, check that it's available on the host: %w", module, err)
+		}
+	}
+
+	return nil
+}
+
+// renameCheck returns an error if the instance can't currently be renamed.
+func (d *common) renameCheck() error {
+	// Snapshots are locked through their parent instance.
+	instName, _, _ := api.GetParentAndSnapshotName(d.name)
+
+	op := operationlock.Get(d.project.Name, instName)
+	if op != nil && op.ActionMatch(operationlock.ActionMigrate) {
+		return api.StatusErrorf(http.StatusConflict, "Renaming of instance not allowed while it is being migrated")
+	}
+
+	return nil
+}
+
+// renamePaths moves the log and runtime paths of the instance to those matching its new name.
+func (d *common) renamePaths(oldName string, newName string) (revert.Hook, error) {
+	// Snapshots don't have their own log and runtime paths.
+	if d.isSnapshot {
+		return func() {}, nil
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	oldFullName := project.Instance(d.project.Name, oldName)
+	newFullName := project.Instance(d.project.Name, newName)
+
+	for _, pathFunc := range []func(...string) string{internalUtil.LogPath, internalUtil.RunPath} {
+		oldPath := pathFunc(oldFullName)
+		newPath := pathFunc(newFullName)
+
+		_ = os.RemoveAll(newPath)
+		if !util.PathExists(oldPath) {
+			continue
+		}
+
+		err := os.Rename(oldPath, newPath)
+		if err != nil {
+			return nil, fmt.Errorf("Failed renaming %q to %q: %w", oldPath, newPath, err)
+		}
+
+		reverter.Add(func() { _ = os.Rename(newPath, oldPath) })
+	}
+
+	cleanup := reverter.Clone().Fail
+	reverter.Success()
+
+	return cleanup, nil
+}
+
+// renameDNSMasqStaticEntries renames the static DHCP allocations of the instance's bridged NICs.
+func (d *common) renameDNSMasqStaticEntries(oldName string, newName string) (revert.Hook, error) {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	dnsmasq.ConfigMutex.Lock()
+	defer dnsmasq.ConfigMutex.Unlock()
+
+	networks := []string{}
+	for _, entry := range d.expandedDevices.Sorted() {
+		if entry.Config["type"] != "nic" {
+			continue
+		}
+
+		nicType, err := nictype.NICType(d.state, d.project.Name, entry.Config)
+		if err != nil || nicType != "bridged" {
+			continue
+		}
+
+		networkName := entry.Config["network"]
+		if networkName == "" {
+			networkName = entry.Config["parent"]
+		}
+
+		if networkName == "" || slices.Contains(networks, networkName) {
+			continue
+		}
+
+		err = dnsmasq.RenameStaticEntries(networkName, d.project.Name, oldName, newName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed renaming DHCP static allocations on network %q: %w", networkName, err)
+		}
+
+		networks = append(networks, networkName)
+		reverter.Add(func() { _ = dnsmasq.RenameStaticEntries(networkName, d.project.Name, newName, oldName) })
+	}
+
+	cleanup := reverter.Clone().Fail
+	reverter.Success()
+
+	return cleanup, nil
+}
+
 // devicesRegister calls the Register() function on all of the instance's devices.
 func (d *common) devicesRegister(inst instance.Instance) {
 	for _, entry := range d.ExpandedDevices().Sorted() {
@@ -0,0 +1,233 @@
+package drivers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/instance"
+	"github.com/lxc/incus/v6/internal/server/instance/operationlock"
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// Test restartPolicyCheck.
+func TestRestartPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       map[string]string
+		restartCount int
+		failure      bool
+		restart      bool
+		delay        time.Duration
+	}{
+		{
+			name:    "No policy",
+			config:  map[string]string{},
+			failure: true,
+		},
+		{
+			name:    "Never",
+			config:  map[string]string{"boot.restart.policy": "never"},
+			failure: true,
+		},
+		{
+			name:    "On failure after crash",
+			config:  map[string]string{"boot.restart.policy": "on-failure"},
+			failure: true,
+			restart: true,
+			delay:   time.Second,
+		},
+		{
+			name:   "On failure after clean shutdown",
+			config: map[string]string{"boot.restart.policy": "on-failure"},
+		},
+		{
+			name:    "Always after clean shutdown",
+			config:  map[string]string{"boot.restart.policy": "always"},
+			restart: true,
+			delay:   time.Second,
+		},
+		{
+			name:         "Backoff doubles",
+			config:       map[string]string{"boot.restart.policy": "always", "boot.restart.backoff": "3"},
+			restartCount: 2,
+			restart:      true,
+			delay:        12 * time.Second,
+		},
+		{
+			name:         "Backoff is capped",
+			config:       map[string]string{"boot.restart.policy": "always", "boot.restart.max_retries": "0"},
+			restartCount: 50,
+			restart:      true,
+			delay:        restartPolicyMaxBackoff,
+		},
+		{
+			name:    "No backoff",
+			config:  map[string]string{"boot.restart.policy": "always", "boot.restart.backoff": "0"},
+			restart: true,
+		},
+		{
+			name:         "Default max retries reached",
+			config:       map[string]string{"boot.restart.policy": "always"},
+			restartCount: 10,
+		},
+		{
+			name:         "Max retries reached",
+			config:       map[string]string{"boot.restart.policy": "on-failure", "boot.restart.max_retries": "2"},
+			restartCount: 2,
+			failure:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restart, delay := restartPolicyCheck(tt.config, tt.restartCount, tt.failure)
+			assert.Equal(t, tt.restart, restart)
+			assert.Equal(t, tt.delay, delay)
+		})
+	}
+}
+
+// Test restartAllowed.
+func TestRestartAllowed(t *testing.T) {
+	tests := []struct {
+		name              string
+		target            string
+		instanceInitiated bool
+		userInitiated     bool
+		allowed           bool
+	}{
+		{
+			name:              "Stopped on its own",
+			target:            "stop",
+			instanceInitiated: true,
+			allowed:           true,
+		},
+		{
+			name:              "Rebooted",
+			target:            "reboot",
+			instanceInitiated: true,
+		},
+		{
+			name:          "Stopped by the user",
+			target:        "stop",
+			userInitiated: true,
+		},
+		{
+			// Virtual machines shut down by the user stop on their own in response to the powerdown request.
+			name:              "Shut down by the user",
+			target:            "stop",
+			instanceInitiated: true,
+			userInitiated:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, err := operationlock.Create("test", "c1", nil, operationlock.ActionStop, false, false)
+			require.NoError(t, err)
+
+			defer op.Done(nil)
+
+			op.SetInstanceInitiated(tt.instanceInitiated)
+			op.SetUserInitiated(tt.userInitiated)
+
+			assert.Equal(t, tt.allowed, restartAllowed(tt.target, op))
+		})
+	}
+
+	// Instances stopped without an operation weren't stopped on their own.
+	assert.False(t, restartAllowed("stop", nil))
+}
+
+// Test restartWait and restartCancel.
+func TestRestartWait(t *testing.T) {
+	shutdownCtx, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+
+	d := &common{id: 1, state: &state.State{ShutdownCtx: shutdownCtx}, logger: logger.AddContext(logger.Ctx{})}
+
+	// The wait completes once the delay elapsed.
+	assert.True(t, d.restartWait(10*time.Millisecond))
+	assert.False(t, d.restartCancel())
+
+	// Cancelling the pending restart interrupts the wait.
+	done := make(chan bool)
+	go func() { done <- d.restartWait(time.Minute) }()
+
+	assert.Eventually(t, d.restartCancel, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, <-done)
+
+	// Shutting down the daemon interrupts the wait.
+	go func() { done <- d.restartWait(time.Minute) }()
+
+	shutdown()
+	assert.False(t, <-done)
+}
+
+// Test waitDependenciesReady.
+func TestWaitDependenciesReady(t *testing.T) {
+	// pendingFor returns a pending function reporting a dependency for the given number of calls.
+	pendingFor := func(calls int) func() string {
+		return func() string {
+			calls--
+			if calls >= 0 {
+				return `network "net1"`
+			}
+
+			return ""
+		}
+	}
+
+	var reported []string
+	progress := func(waitingFor string) {
+		reported = append(reported, waitingFor)
+	}
+
+	// Dependencies becoming ready.
+	err := waitDependenciesReady(context.Background(), pendingFor(2), time.Minute, time.Millisecond, progress)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`network "net1"`, `network "net1"`, ""}, reported)
+
+	// Dependencies never becoming ready.
+	err = waitDependenciesReady(context.Background(), pendingFor(1<<30), 20*time.Millisecond, time.Millisecond, func(string) {})
+	assert.ErrorIs(t, err, instance.ErrDependenciesNotReady)
+
+	// Cancellation stops the wait straight away.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	err = waitDependenciesReady(ctx, pendingFor(1<<30), time.Minute, time.Minute, func(string) {})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, instance.ErrDependenciesNotReady)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestValidateVolatileChanges(t *testing.T) {
+	d := &common{}
+	current := map[string]string{
+		"limits.cpu":           "1",
+		"volatile.base_image":  "abc",
+		"volatile.eth0.hwaddr": "10:66:6a:00:00:01",
+	}
+
+	// Any key can be changed on a stopped instance.
+	assert.NoError(t, d.validateVolatileChanges(map[string]string{"limits.cpu": "2", "volatile.base_image": "def"}, current, false))
+
+	// Only regular keys can be changed on a running instance.
+	assert.NoError(t, d.validateVolatileChanges(map[string]string{"limits.cpu": "2", "user.volatile": "foo"}, current, true))
+	assert.Error(t, d.validateVolatileChanges(map[string]string{"limits.cpu": "2", "volatile.eth0.hwaddr": "10:66:6a:00:00:02"}, current, true))
+	assert.Error(t, d.validateVolatileChanges(map[string]string{"volatile.eth1.hwaddr": "10:66:6a:00:00:02"}, current, true))
+	assert.Error(t, d.validateVolatileChanges(map[string]string{"volatile.eth0.hwaddr": ""}, current, true))
+
+	// Sending back the current configuration is allowed.
+	assert.NoError(t, d.validateVolatileChanges(current, current, true))
+
+	// Volatile keys left out of the request aren't checked.
+	assert.NoError(t, d.validateVolatileChanges(map[string]string{"limits.cpu": "2"}, current, true))
+}
@@ -52,6 +52,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
 	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/internal/server/db/warningtype"
 	"github.com/lxc/incus/v6/internal/server/device"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
 	"github.com/lxc/incus/v6/internal/server/device/nictype"
@@ -72,6 +73,7 @@ import (
 	storageDrivers "github.com/lxc/incus/v6/internal/server/storage/drivers"
 	"github.com/lxc/incus/v6/internal/server/template"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
+	"github.com/lxc/incus/v6/internal/server/warnings"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
@@ -178,23 +180,24 @@ func lxcCreate(s *state.State, args db.InstanceArgs, p api.Project, op *operatio
 			state: s,
 			op:    op,
 
-			architecture: args.Architecture,
-			creationDate: args.CreationDate,
-			dbType:       args.Type,
-			description:  args.Description,
-			ephemeral:    args.Ephemeral,
-			expiryDate:   args.ExpiryDate,
-			id:           args.ID,
-			lastUsedDate: args.LastUsedDate,
-			localConfig:  args.Config,
-			localDevices: args.Devices,
-			logger:       logger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
-			name:         args.Name,
-			node:         args.Node,
-			profiles:     args.Profiles,
-			project:      p,
-			isSnapshot:   args.Snapshot,
-			stateful:     args.Stateful,
+			architecture:    args.Architecture,
+			creationDate:    args.CreationDate,
+			dbType:          args.Type,
+			description:     args.Description,
+			ephemeral:       args.Ephemeral,
+			expiryDate:      args.ExpiryDate,
+			id:              args.ID,
+			lastUsedDate:    args.LastUsedDate,
+			lastStartedDate: args.LastStartedDate,
+			localConfig:     args.Config,
+			localDevices:    args.Devices,
+			logger:          logger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
+			name:            args.Name,
+			node:            args.Node,
+			profiles:        args.Profiles,
+			project:         p,
+			isSnapshot:      args.Snapshot,
+			stateful:        args.Stateful,
 		},
 	}
 
@@ -225,12 +228,32 @@ func lxcCreate(s *state.State, args db.InstanceArgs, p api.Project, op *operatio
 
 	// When not a snapshot, perform full validation.
 	if !args.Snapshot {
+		err = internalInstance.ValidateProfilePriorities(d.profiles)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid profiles: %w", err)
+		}
+
 		// Validate expanded config (allows mixed instance types for profiles).
 		err = instance.ValidConfig(s.OS, d.expandedConfig, true, instancetype.Any)
 		if err != nil {
 			return nil, nil, fmt.Errorf("Invalid config: %w", err)
 		}
 
+		err = instance.ValidMemoryConfig(s.OS, d.expandedConfig, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid config: %w", err)
+		}
+
+		err = instance.ValidSysctlConfig(d.expandedConfig, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid config: %w", err)
+		}
+
+		err = instance.ValidRestartPolicyConfig(d.expandedConfig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid config: %w", err)
+		}
+
 		err = instance.ValidDevices(s, d.project, d.Type(), d.localDevices, d.expandedDevices)
 		if err != nil {
 			return nil, nil, fmt.Errorf("Invalid devices: %w", err)
@@ -383,23 +406,24 @@ func lxcInstantiate(s *state.State, args db.InstanceArgs, expandedDevices device
 		common: common{
 			state: s,
 
-			architecture: args.Architecture,
-			creationDate: args.CreationDate,
-			dbType:       args.Type,
-			description:  args.Description,
-			ephemeral:    args.Ephemeral,
-			expiryDate:   args.ExpiryDate,
-			id:           args.ID,
-			lastUsedDate: args.LastUsedDate,
-			localConfig:  args.Config,
-			localDevices: args.Devices,
-			logger:       logger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
-			name:         args.Name,
-			node:         args.Node,
-			profiles:     args.Profiles,
-			project:      p,
-			isSnapshot:   args.Snapshot,
-			stateful:     args.Stateful,
+			architecture:    args.Architecture,
+			creationDate:    args.CreationDate,
+			dbType:          args.Type,
+			description:     args.Description,
+			ephemeral:       args.Ephemeral,
+			expiryDate:      args.ExpiryDate,
+			id:              args.ID,
+			lastUsedDate:    args.LastUsedDate,
+			lastStartedDate: args.LastStartedDate,
+			localConfig:     args.Config,
+			localDevices:    args.Devices,
+			logger:          logger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
+			name:            args.Name,
+			node:            args.Node,
+			profiles:        args.Profiles,
+			project:         p,
+			isSnapshot:      args.Snapshot,
+			stateful:        args.Stateful,
 		},
 	}
 
@@ -718,11 +742,32 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 			return nil, err
 		}
 
-		err = lxcSetConfigItem(cc, "lxc.console.size", "auto")
+		consoleLogLimits, err := instance.ConsoleLogLimitsFromConfig(d.expandedConfig)
 		if err != nil {
 			return nil, err
 		}
 
+		if consoleLogLimits.Size > 0 {
+			// Let liblxc rotate the log file into its first rotated file, the others being handled by
+			// ConsoleLogAdopt when the log is retrieved or the container stops.
+			err = lxcSetConfigItem(cc, "lxc.console.size", fmt.Sprintf("%d", consoleLogLimits.Size))
+			if err != nil {
+				return nil, err
+			}
+
+			if consoleLogLimits.Rotate > 0 {
+				err = lxcSetConfigItem(cc, "lxc.console.rotate", "1")
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			err = lxcSetConfigItem(cc, "lxc.console.size", "auto")
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		// File to dump ringbuffer contents to when requested or
 		// container shutdown.
 		consoleBufferLogFile := d.ConsoleBufferLogPath()
@@ -975,7 +1020,7 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 	}
 
 	// Setup the hostname
-	err = lxcSetConfigItem(cc, "lxc.uts.name", d.Name())
+	err = lxcSetConfigItem(cc, "lxc.uts.name", instance.Hostname(d.name, d.expandedConfig))
 	if err != nil {
 		return nil, err
 	}
@@ -998,6 +1043,12 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 			if err != nil {
 				return nil, err
 			}
+		} else if apparmor.InstanceCustomProfile(d) != "" {
+			// Use the custom profile as-is, without stacking it with the container's namespace.
+			err := lxcSetConfigItem(cc, "lxc.apparmor.profile", apparmor.InstanceCustomProfile(d))
+			if err != nil {
+				return nil, err
+			}
 		} else {
 			// If not currently confined, use the container's profile
 			profile := apparmor.InstanceProfileName(d)
@@ -1146,44 +1197,14 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 				return nil, err
 			}
 
-			if memoryEnforce == "soft" {
-				err = cg.SetMemorySoftLimit(valueInt)
-				if err != nil {
-					return nil, err
-				}
-			} else {
-				err = cg.SetMemoryLimit(valueInt)
-				if err != nil {
-					return nil, err
-				}
-
-				if d.state.OS.CGInfo.Supports(cgroup.MemorySwap, cg) {
-					if util.IsTrueOrEmpty(memorySwap) || util.IsFalse(memorySwap) {
-						err = cg.SetMemorySwapLimit(0)
-						if err != nil {
-							return nil, err
-						}
-					} else {
-						// Additional memory as swap.
-						swapInt, err := units.ParseByteSizeString(memorySwap)
-						if err != nil {
-							return nil, err
-						}
-
-						err = cg.SetMemorySwapLimit(swapInt)
-						if err != nil {
-							return nil, err
-						}
-					}
-				}
+			swapInt, err := cgroup.MemorySwapLimit(memoryEnforce, memorySwap)
+			if err != nil {
+				return nil, err
+			}
 
-				// If on CGroup1, set soft limit to value 10% less than hard limit.
-				if slices.Contains([]cgroup.Layout{cgroup.CgroupsLegacy, cgroup.CgroupsHybrid}, d.state.OS.CGInfo.Layout) {
-					err = cg.SetMemorySoftLimit(int64(float64(valueInt) * 0.9))
-					if err != nil {
-						return nil, err
-					}
-				}
+			err = cg.SetMemoryLimits(valueInt, memoryEnforce, swapInt, d.state.OS.CGInfo.Supports(cgroup.MemorySwap, cg))
+			if err != nil {
+				return nil, err
 			}
 		}
 
@@ -1293,6 +1314,12 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	// Raw cgroup values, applied last to take precedence over the generated limits.
+	err = d.setRawCgroup(cg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Setup process limits
 	for k, v := range d.expandedConfig {
 		if strings.HasPrefix(k, "limits.kernel.") {
@@ -1308,10 +1335,12 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 	// Setup sysctls
 	for k, v := range d.expandedConfig {
 		// gendoc:generate(entity=instance, group=miscellaneous, key=linux.sysctl.*)
-		//
+		// The namespaced `sysctl` settings are `net.*`, `user.*`, `fs.mqueue.*`, `kernel.domainname` and the IPC `kernel.msg*`, `kernel.sem` and `kernel.shm*` keys.
+		// Sysctls outside of the container's namespaces (e.g. `vm.*`) are rejected.
+		// Changes to namespaced settings are applied to the running container, removed settings and the others take effect on the next start.
 		// ---
 		//  type: string
-		//  liveupdate: no
+		//  liveupdate: yes
 		//  condition: container
 		//  shortdesc: Override for the corresponding `sysctl` setting in the container
 		if strings.HasPrefix(k, "linux.sysctl.") {
@@ -1410,9 +1439,11 @@ func (d *lxc) devIncusEventSend(eventType string, eventMessage map[string]any) e
 	return d.state.DevIncusEvents.Send(d.ID(), eventType, eventMessage)
 }
 
-// RegisterDevices calls the Register() function on all of the instance's devices.
+// RegisterDevices calls the Register() function on all of the instance's devices
+// and resumes forwarding its console output.
 func (d *lxc) RegisterDevices() {
 	d.devicesRegister(d)
+	d.consoleForwardStart(nil, d.LogFilePath(), false)
 }
 
 // deviceStart loads a new device and calls its Start() function.
@@ -1701,7 +1732,14 @@ func (d *lxc) deviceDetachNIC(configCopy map[string]string, netIF []deviceConfig
 // If the mount DevPath is empty the mount action is treated as unmount.
 func (d *lxc) deviceHandleMounts(mounts []deviceConfig.MountEntryItem) error {
 	for _, mount := range mounts {
-		if mount.DevPath != "" {
+		if mount.Remount {
+			flags, _ := linux.ResolveMountOptions(mount.Opts)
+
+			err := d.remountMount(mount.TargetPath, int(flags))
+			if err != nil {
+				return fmt.Errorf("Failed to apply mount options for device inside container: %w", err)
+			}
+		} else if mount.DevPath != "" {
 			flags := 0
 
 			// Convert options into flags.
@@ -1926,6 +1964,12 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 	reverter := revert.New()
 	defer reverter.Fail()
 
+	// Load any required kernel modules.
+	err := d.loadKernelModules(d.expandedConfig["linux.kernel_modules"])
+	if err != nil {
+		return "", nil, err
+	}
+
 	// Assign NUMA node(s) if needed.
 	if d.expandedConfig["limits.cpu.nodes"] == "balanced" {
 		err := d.balanceNUMANodes()
@@ -1995,18 +2039,6 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		return "", nil, fmt.Errorf("The image used by this instance is incompatible with privileged containers. Please unset security.privileged on the instance")
 	}
 
-	// Load any required kernel modules
-	kernelModules := d.expandedConfig["linux.kernel_modules"]
-	if kernelModules != "" {
-		for _, module := range strings.Split(kernelModules, ",") {
-			module = strings.TrimPrefix(module, " ")
-			err := linux.LoadModule(module)
-			if err != nil {
-				return "", nil, fmt.Errorf("Failed to load kernel module '%s': %w", module, err)
-			}
-		}
-	}
-
 	// Rotate the log file.
 	logfile := d.LogFilePath()
 	if util.PathExists(logfile) {
@@ -2065,6 +2097,12 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		return "", nil, err
 	}
 
+	// Check the custom AppArmor profile
+	err = d.checkAppArmorProfile()
+	if err != nil {
+		return "", nil, err
+	}
+
 	// Cleanup any existing leftover devices
 	_ = d.removeUnixDevices()
 	_ = d.removeDiskDevices()
@@ -2292,6 +2330,14 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		}
 	}
 
+	// Join the external network namespace if requested.
+	if d.expandedConfig["linux.network_namespace"] != "" {
+		err = lxcSetConfigItem(cc, "lxc.namespace.share.net", internalInstance.NetworkNamespacePath(d.expandedConfig["linux.network_namespace"]))
+		if err != nil {
+			return "", nil, fmt.Errorf("Unable to set the network namespace: %w", err)
+		}
+	}
+
 	// Override NVIDIA_VISIBLE_DEVICES if we have devices that need it.
 	if len(nvidiaDevices) > 0 {
 		err = lxcSetConfigItem(cc, "lxc.environment", fmt.Sprintf("NVIDIA_VISIBLE_DEVICES=%s", strings.Join(nvidiaDevices, ",")))
@@ -2462,6 +2508,8 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 			return "", nil, err
 		}
 
+		hostname := instance.Hostname(d.name, d.expandedConfig)
+
 		err = os.WriteFile(filepath.Join(d.Path(), "network", "hosts"), []byte(fmt.Sprintf(`127.0.0.1   localhost
 127.0.1.1   %s
 
@@ -2470,7 +2518,7 @@ fe00::0 ip6-localnet
 ff00::0 ip6-mcastprefix
 ff02::1 ip6-allnodes
 ff02::2 ip6-allrouters
-`, d.name)), 0o644)
+`, hostname)), 0o644)
 		if err != nil {
 			return "", nil, err
 		}
@@ -2480,7 +2528,7 @@ ff02::2 ip6-allrouters
 			return "", nil, err
 		}
 
-		err = os.WriteFile(filepath.Join(d.Path(), "network", "hostname"), []byte(fmt.Sprintf("%s\n", d.name)), 0o644)
+		err = os.WriteFile(filepath.Join(d.Path(), "network", "hostname"), []byte(fmt.Sprintf("%s\n", hostname)), 0o644)
 		if err != nil {
 			return "", nil, err
 		}
@@ -2699,10 +2747,16 @@ func (d *lxc) Start(stateful bool) error {
 	d.logger.Debug("Start started", logger.Ctx{"stateful": stateful})
 	defer d.logger.Debug("Start finished", logger.Ctx{"stateful": stateful})
 
+	// Wait for the networks and storage pools used by the instance to be ready.
+	err := d.waitDependencies()
+	if err != nil {
+		return err
+	}
+
 	// Check that we are startable before creating an operation lock.
 	// Must happen before creating operation Start lock to avoid the status check returning Stopped due to the
 	// existence of a Start operation lock.
-	err := d.validateStartup(stateful, d.statusCode())
+	err = d.validateStartup(stateful, d.statusCode())
 	if err != nil {
 		return err
 	}
@@ -2736,6 +2790,10 @@ func (d *lxc) Start(stateful bool) error {
 
 	if op.Action() == "start" {
 		d.logger.Info("Starting instance", ctxMap)
+
+		// Reset the restart policy count when started by the user.
+		d.restartCancel()
+		d.restartCountReset()
 	}
 
 	// If stateful, restore now.
@@ -2907,6 +2965,35 @@ func (d *lxc) OnHook(hookName string, args map[string]string) error {
 	}
 }
 
+// checkAppArmorProfile checks that the custom AppArmor profile of the container is loaded, recording a warning
+// about the settings likely to prevent the container from working as expected.
+func (d *lxc) checkAppArmorProfile() error {
+	profileWarnings, err := apparmor.InstanceCustomProfileCheck(d.state.OS, d)
+	if err != nil {
+		return err
+	}
+
+	if len(profileWarnings) == 0 {
+		err := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(d.state.DB.Cluster, d.project.Name, warningtype.InstanceAppArmorProfile, cluster.TypeInstance, d.id)
+		if err != nil {
+			d.logger.Warn("Failed to resolve AppArmor profile warnings", logger.Ctx{"err": err})
+		}
+
+		return nil
+	}
+
+	d.logger.Warn("Custom AppArmor profile may not work as expected", logger.Ctx{"profile": apparmor.InstanceCustomProfile(d), "warnings": profileWarnings})
+
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, d.project.Name, cluster.TypeInstance, d.id, warningtype.InstanceAppArmorProfile, strings.Join(profileWarnings, "; "))
+	})
+	if err != nil {
+		d.logger.Warn("Failed to create AppArmor profile warning", logger.Ctx{"err": err})
+	}
+
+	return nil
+}
+
 // onStart implements the start hook.
 func (d *lxc) onStart(_ map[string]string) error {
 	// Make sure we can't call go-lxc functions by mistake
@@ -2953,6 +3040,10 @@ func (d *lxc) onStart(_ map[string]string) error {
 		return err
 	}
 
+	d.resolveDeviceConflictWarnings()
+
+	d.consoleForwardStart(nil, d.LogFilePath(), true)
+
 	return nil
 }
 
@@ -2974,6 +3065,50 @@ func (d *lxc) validateStartup(stateful bool, statusCode api.StatusCode) error {
 		return fmt.Errorf("The image used by this instance requires nesting. Please set security.nesting=true on the instance")
 	}
 
+	// Ensure the external network namespace exists.
+	if d.expandedConfig["linux.network_namespace"] != "" {
+		err = d.checkNetworkNamespace()
+		if err != nil {
+			return err
+		}
+
+		err = lxcCheckNetworkNamespacePath(internalInstance.NetworkNamespacePath(d.expandedConfig["linux.network_namespace"]))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lxcCheckNetworkNamespacePath checks that the path refers to an existing namespace.
+func lxcCheckNetworkNamespacePath(netnsPath string) error {
+	var st unix.Statfs_t
+	err := unix.Statfs(netnsPath, &st)
+	if err != nil {
+		return fmt.Errorf("Failed to access network namespace %q: %w", netnsPath, err)
+	}
+
+	if st.Type != unix.NSFS_MAGIC {
+		return fmt.Errorf("%q isn't a network namespace", netnsPath)
+	}
+
+	return nil
+}
+
+// checkNetworkNamespace checks that no NIC devices are used alongside linux.network_namespace, as the
+// interfaces of an external network namespace aren't managed by Incus.
+func (d *lxc) checkNetworkNamespace() error {
+	if d.expandedConfig["linux.network_namespace"] == "" {
+		return nil
+	}
+
+	for name, dev := range d.expandedDevices {
+		if dev["type"] == "nic" || dev["type"] == "infiniband" {
+			return fmt.Errorf("Device %q can't be used with %q, remove the NIC devices from the instance", name, "linux.network_namespace")
+		}
+	}
+
 	return nil
 }
 
@@ -2989,6 +3124,11 @@ func (d *lxc) Stop(stateful bool) error {
 
 	// Must be run prior to creating the operation lock.
 	if !d.IsRunning() {
+		// Stopping an instance waiting to be restarted by its restart policy cancels the restart.
+		if d.restartCancel() {
+			return nil
+		}
+
 		return ErrInstanceIsStopped
 	}
 
@@ -3003,6 +3143,9 @@ func (d *lxc) Stop(stateful bool) error {
 		return err
 	}
 
+	// Indicate to the onStop hook that the instance was stopped through Incus.
+	op.SetUserInitiated(true)
+
 	ctxMap := logger.Ctx{
 		"action":    op.Action(),
 		"created":   d.creationDate,
@@ -3166,6 +3309,11 @@ func (d *lxc) Shutdown(timeout time.Duration) error {
 			return fmt.Errorf("The instance cannot be cleanly shutdown as in %s status", statusCode)
 		}
 
+		// Stopping an instance waiting to be restarted by its restart policy cancels the restart.
+		if d.restartCancel() {
+			return nil
+		}
+
 		return ErrInstanceIsStopped
 	}
 
@@ -3180,6 +3328,9 @@ func (d *lxc) Shutdown(timeout time.Duration) error {
 		return err
 	}
 
+	// Indicate to the onStop hook that the instance was stopped through Incus.
+	op.SetUserInitiated(true)
+
 	// If frozen, resume so the signal can be handled.
 	if d.IsFrozen() {
 		err := d.Unfreeze()
@@ -3329,6 +3480,15 @@ func (d *lxc) onStop(args map[string]string) error {
 		d.logger.Error("Failed recording last power state", logger.Ctx{"err": err})
 	}
 
+	// Forward the remaining console output before the log gets rotated.
+	d.consoleForwardStop()
+
+	// Apply the console log rotation settings to the final log.
+	err = d.consoleLogAdopt()
+	if err != nil {
+		d.logger.Warn("Failed rotating console log", logger.Ctx{"err": err})
+	}
+
 	go func(d *lxc, target string, op *operationlock.InstanceOperation) {
 		d.fromHook = false
 		err = nil
@@ -3423,13 +3583,22 @@ func (d *lxc) onStop(args map[string]string) error {
 		}
 
 		// Determine if instance should be auto-restarted.
+		// Containers don't report how they exited, so the stop is never considered a failure.
 		var autoRestart bool
-		if target != "reboot" && op.GetInstanceInitiated() && d.shouldAutoRestart() {
-			autoRestart = true
+		var restartDelay time.Duration
+		if restartAllowed(target, op) {
+			autoRestart, restartDelay = d.shouldRestart(false)
+		}
 
+		if autoRestart {
 			// Mark current shutdown as complete.
 			op.Done(nil)
 
+			// Wait for the restart policy backoff.
+			if restartDelay > 0 && !d.restartWait(restartDelay) {
+				return
+			}
+
 			// Create a new restart operation.
 			op, err = operationlock.CreateWaitGet(d.Project().Name, d.Name(), d.op, operationlock.ActionRestart, nil, true, false)
 			if err == nil {
@@ -3437,6 +3606,11 @@ func (d *lxc) onStop(args map[string]string) error {
 			} else {
 				d.logger.Error("Failed to setup new restart operation", logger.Ctx{"err": err})
 			}
+
+			// Skip the restart if the instance was started in the meantime.
+			if restartDelay > 0 && d.IsRunning() {
+				return
+			}
 		}
 
 		// Log and emit lifecycle if not user triggered
@@ -3462,7 +3636,7 @@ func (d *lxc) onStop(args map[string]string) error {
 				return
 			}
 
-			d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceRestarted.Event(d, nil))
+			d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceRestarted.Event(d, d.restartLifecycleContext(autoRestart)))
 
 			return
 		}
@@ -3725,6 +3899,7 @@ func (d *lxc) Render() (any, any, error) {
 	instState.Devices = d.localDevices.CloneNative()
 	instState.Ephemeral = d.ephemeral
 	instState.LastUsedAt = d.lastUsedDate
+	instState.LastStartedAt = d.lastStartedDate
 	instState.Profiles = profileNames
 	instState.Stateful = d.stateful
 	instState.Project = d.project.Name
@@ -3812,14 +3987,14 @@ func (d *lxc) renderState(statusCode api.StatusCode, hostInterfaces []net.Interf
 	pid := d.InitPID()
 	processesState, _ := d.processesState(pid)
 
+	var err error
 	if d.isRunningStatusCode(statusCode) {
-		var err error
-
 		status.CPU = d.cpuState()
 		status.Memory = d.memoryState()
 		status.Network = d.networkState(hostInterfaces)
 		status.Pid = int64(pid)
 		status.Processes = processesState
+		status.Clock = d.clockState()
 
 		status.StartedAt, err = d.processStartedAt(d.InitPID())
 		if err != nil {
@@ -3829,11 +4004,44 @@ func (d *lxc) renderState(statusCode api.StatusCode, hostInterfaces []net.Interf
 
 	status.Disk = d.diskState()
 
+	status.ConsoleLogSize, err = instance.ConsoleLogSize(d.ConsoleBufferLogPath())
+	if err != nil {
+		d.logger.Warn("Error getting console log size", logger.Ctx{"err": err})
+	}
+
+	status.RestartCount = d.restartCount()
+
 	d.release()
 
 	return &status, nil
 }
 
+// clockState returns the clock information of the container, which always uses the host clock.
+func (d *lxc) clockState() *api.InstanceStateClock {
+	clock := &api.InstanceStateClock{Time: time.Now()}
+
+	synchronized, err := linux.ClockSynchronized()
+	if err == nil {
+		if synchronized {
+			clock.NTPStatus = "synchronized"
+		} else {
+			clock.NTPStatus = "unsynchronized"
+		}
+	}
+
+	return clock
+}
+
+// SyncClock isn't supported for containers as they use the host clock.
+func (d *lxc) SyncClock() error {
+	return fmt.Errorf("Containers use the host clock")
+}
+
+// Hibernate isn't supported for containers.
+func (d *lxc) Hibernate() error {
+	return fmt.Errorf("Hibernation is only supported for virtual machines")
+}
+
 // RenderState renders just the running state of the instance.
 func (d *lxc) RenderState(hostInterfaces []net.Interface) (*api.InstanceState, error) {
 	return d.renderState(d.statusCode(), hostInterfaces)
@@ -3841,11 +4049,29 @@ func (d *lxc) RenderState(hostInterfaces []net.Interface) (*api.InstanceState, e
 
 // snapshot creates a snapshot of the instance.
 func (d *lxc) snapshot(name string, expiry time.Time, stateful bool) error {
+	err := d.checkSnapshotsEnabled()
+	if err != nil {
+		return err
+	}
+
 	// Check that migration.stateful is set for stateful actions.
 	if stateful && util.IsFalseOrEmpty(d.expandedConfig["migration.stateful"]) {
 		return fmt.Errorf("Stateful snapshots require that the instance has migration.stateful be set to true")
 	}
 
+	// Give the workload a chance to quiesce before taking the snapshot.
+	err = d.runSnapshotHook(d, "pre")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err := d.runSnapshotHook(d, "post")
+		if err != nil {
+			d.logger.Warn("Failed running snapshot post hook", logger.Ctx{"err": err})
+		}
+	}()
+
 	// Deal with state.
 	if stateful {
 		// Quick checks.
@@ -4148,6 +4374,9 @@ func (d *lxc) cleanup() {
 
 // Delete deletes the instance.
 func (d *lxc) Delete(force bool) error {
+	// Don't restart the instance while it's being deleted.
+	d.restartCancel()
+
 	// Setup a new operation.
 	op, err := operationlock.CreateWaitGet(d.Project().Name, d.Name(), d.op, operationlock.ActionDelete, nil, false, false)
 	if err != nil {
@@ -4317,6 +4546,11 @@ func (d *lxc) Rename(newName string, applyTemplateTrigger bool) error {
 		return fmt.Errorf("Renaming of running instance not allowed")
 	}
 
+	err = d.renameCheck()
+	if err != nil {
+		return err
+	}
+
 	// Clean things up.
 	d.cleanup()
 
@@ -4325,18 +4559,34 @@ func (d *lxc) Rename(newName string, applyTemplateTrigger bool) error {
 		return fmt.Errorf("Failed loading instance storage pool: %w", err)
 	}
 
+	reverter := revert.New()
+	defer reverter.Fail()
+
 	if d.IsSnapshot() {
+		_, oldSnapName, _ := api.GetParentAndSnapshotName(oldName)
 		_, newSnapName, _ := api.GetParentAndSnapshotName(newName)
 		err = pool.RenameInstanceSnapshot(d, newSnapName, nil)
 		if err != nil {
 			return fmt.Errorf("Rename instance snapshot: %w", err)
 		}
+
+		reverter.Add(func() {
+			d.name = newName
+			_ = pool.RenameInstanceSnapshot(d, oldSnapName, nil)
+			d.name = oldName
+		})
 	} else {
 		err = pool.RenameInstance(d, newName, nil)
 		if err != nil {
 			return fmt.Errorf("Rename instance: %w", err)
 		}
 
+		reverter.Add(func() {
+			d.name = newName
+			_ = pool.RenameInstance(d, oldName, nil)
+			d.name = oldName
+		})
+
 		if applyTemplateTrigger {
 			err = d.DeferTemplateApply(instance.TemplateTriggerRename)
 			if err != nil {
@@ -4345,78 +4595,51 @@ func (d *lxc) Rename(newName string, applyTemplateTrigger bool) error {
 		}
 	}
 
-	if !d.IsSnapshot() {
-		var results []string
-
-		err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-			var err error
+	// Rename the instance and snapshot database entries.
+	renameDB := func(oldName string, newName string) error {
+		return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			if d.IsSnapshot() {
+				oldParts := strings.SplitN(oldName, internalInstance.SnapshotDelimiter, 2)
+				newParts := strings.SplitN(newName, internalInstance.SnapshotDelimiter, 2)
+				return cluster.RenameInstanceSnapshot(ctx, tx.Tx(), d.project.Name, oldParts[0], oldParts[1], newParts[1])
+			}
 
-			// Rename all the instance snapshot database entries.
-			results, err = tx.GetInstanceSnapshotsNames(ctx, d.project.Name, oldName)
+			// Snapshots are renamed first as they're looked up through their parent.
+			results, err := tx.GetInstanceSnapshotsNames(ctx, d.project.Name, oldName)
 			if err != nil {
-				d.logger.Error("Failed to get instance snapshots", ctxMap)
-
-				return fmt.Errorf("Failed to get instance snapshots: Failed getting instance snapshot names: %w", err)
+				return fmt.Errorf("Failed getting instance snapshot names: %w", err)
 			}
 
 			for _, sname := range results {
-				// Rename the snapshot.
 				oldSnapName := strings.SplitN(sname, internalInstance.SnapshotDelimiter, 2)[1]
 				baseSnapName := filepath.Base(sname)
 
 				err := cluster.RenameInstanceSnapshot(ctx, tx.Tx(), d.project.Name, oldName, oldSnapName, baseSnapName)
 				if err != nil {
-					d.logger.Error("Failed renaming snapshot", ctxMap)
-					return fmt.Errorf("Failed renaming snapshot: %w", err)
+					return fmt.Errorf("Failed renaming snapshot %q: %w", oldSnapName, err)
 				}
 			}
 
-			return nil
+			return cluster.RenameInstance(ctx, tx.Tx(), d.project.Name, oldName, newName)
 		})
-		if err != nil {
-			return err
-		}
 	}
 
-	// Rename the instance database entry.
-	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		if d.IsSnapshot() {
-			oldParts := strings.SplitN(oldName, internalInstance.SnapshotDelimiter, 2)
-			newParts := strings.SplitN(newName, internalInstance.SnapshotDelimiter, 2)
-			return cluster.RenameInstanceSnapshot(ctx, tx.Tx(), d.project.Name, oldParts[0], oldParts[1], newParts[1])
-		}
-
-		return cluster.RenameInstance(ctx, tx.Tx(), d.project.Name, oldName, newName)
-	})
+	err = renameDB(oldName, newName)
 	if err != nil {
 		d.logger.Error("Failed renaming instance", ctxMap)
 		return fmt.Errorf("Failed renaming instance: %w", err)
 	}
 
-	// Rename the logging path.
-	newFullName := project.Instance(d.Project().Name, d.Name())
-	_ = os.RemoveAll(internalUtil.LogPath(newFullName))
-	if util.PathExists(d.LogPath()) {
-		err := os.Rename(d.LogPath(), internalUtil.LogPath(newFullName))
-		if err != nil {
-			d.logger.Error("Failed renaming instance", ctxMap)
-			return fmt.Errorf("Failed renaming instance: %w", err)
-		}
-	}
+	reverter.Add(func() { _ = renameDB(newName, oldName) })
 
-	// Rename the runtime path.
-	newFullName = project.Instance(d.Project().Name, d.Name())
-	_ = os.RemoveAll(internalUtil.RunPath(newFullName))
-	if util.PathExists(d.RunPath()) {
-		err := os.Rename(d.RunPath(), internalUtil.RunPath(newFullName))
-		if err != nil {
-			d.logger.Error("Failed renaming instance", ctxMap)
-			return fmt.Errorf("Failed renaming instance: %w", err)
-		}
+	// Rename the logging and runtime paths.
+	cleanup, err := d.renamePaths(oldName, newName)
+	if err != nil {
+		d.logger.Error("Failed renaming instance", ctxMap)
+		return fmt.Errorf("Failed renaming instance: %w", err)
 	}
 
-	reverter := revert.New()
-	defer reverter.Fail()
+	reverter.Add(cleanup)
 
 	// Set the new name in the struct.
 	d.name = newName
@@ -4447,6 +4670,16 @@ func (d *lxc) Rename(newName string, applyTemplateTrigger bool) error {
 
 	d.cConfig = false
 
+	if !d.IsSnapshot() {
+		// Move the static DHCP allocations over to the new name.
+		cleanup, err = d.renameDNSMasqStaticEntries(oldName, newName)
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(cleanup)
+	}
+
 	// Update lease files.
 	err = network.UpdateDNSMasqStatic(d.state, "")
 	if err != nil {
@@ -4755,18 +4988,48 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 			}
 		}
 
+		err = d.validateVolatileChanges(args.Config, oldLocalConfig, d.IsRunning())
+		if err != nil {
+			return err
+		}
+
+		err = internalInstance.ValidateProfilePriorities(d.profiles)
+		if err != nil {
+			return fmt.Errorf("Invalid profiles: %w", err)
+		}
+
 		// Do some validation of the config diff (allows mixed instance types for profiles).
 		err = instance.ValidConfig(d.state.OS, d.expandedConfig, true, instancetype.Any)
 		if err != nil {
 			return fmt.Errorf("Invalid expanded config: %w", err)
 		}
 
+		err = instance.ValidMemoryConfig(d.state.OS, d.expandedConfig, oldExpandedConfig)
+		if err != nil {
+			return fmt.Errorf("Invalid expanded config: %w", err)
+		}
+
+		err = instance.ValidSysctlConfig(d.expandedConfig, oldExpandedConfig)
+		if err != nil {
+			return fmt.Errorf("Invalid expanded config: %w", err)
+		}
+
+		err = instance.ValidRestartPolicyConfig(d.expandedConfig)
+		if err != nil {
+			return fmt.Errorf("Invalid expanded config: %w", err)
+		}
+
 		// Do full expanded validation of the devices diff.
 		err = instance.ValidDevices(d.state, d.project, d.Type(), d.localDevices, d.expandedDevices)
 		if err != nil {
 			return fmt.Errorf("Invalid expanded devices: %w", err)
 		}
 
+		err = d.checkNetworkNamespace()
+		if err != nil {
+			return err
+		}
+
 		// Validate root device
 		_, oldRootDev, oldErr := internalInstance.GetRootDiskDevice(oldExpandedDevices.CloneNative())
 		_, newRootDev, newErr := internalInstance.GetRootDiskDevice(d.expandedDevices.CloneNative())
@@ -4810,11 +5073,16 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 	}
 
 	// If apparmor changed, re-validate the apparmor profile (even if not running).
-	if slices.Contains(changedConfig, "raw.apparmor") || slices.Contains(changedConfig, "security.nesting") {
+	if slices.Contains(changedConfig, "raw.apparmor") || slices.Contains(changedConfig, "security.nesting") || slices.Contains(changedConfig, "security.apparmor.profile") {
 		err = apparmor.InstanceValidate(d.state.OS, d, nil)
 		if err != nil {
 			return fmt.Errorf("Parse AppArmor profile: %w", err)
 		}
+
+		err = d.checkAppArmorProfile()
+		if err != nil {
+			return err
+		}
 	}
 
 	if slices.Contains(changedConfig, "security.idmap.isolated") || slices.Contains(changedConfig, "security.idmap.base") || slices.Contains(changedConfig, "security.idmap.size") || slices.Contains(changedConfig, "raw.idmap") || slices.Contains(changedConfig, "security.privileged") {
@@ -4860,12 +5128,34 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 			return err
 		}
 
+		// Reset the cgroup files no longer set through raw.cgroup and re-apply the limits managing them.
+		if slices.Contains(changedConfig, "raw.cgroup") {
+			resets, err := cgroup.RawReset(oldExpandedConfig["raw.cgroup"], d.expandedConfig["raw.cgroup"])
+			if err != nil {
+				return err
+			}
+
+			for _, entry := range resets {
+				err = cg.SetRaw(entry.Key, entry.Value)
+				if err != nil {
+					return fmt.Errorf("Failed resetting cgroup file %q: %w", entry.Key, err)
+				}
+
+				limitKey := lxcRawCgroupLimitKey(entry.Key)
+				if limitKey != "" && !slices.Contains(changedConfig, limitKey) {
+					changedConfig = append(changedConfig, limitKey)
+				}
+			}
+		}
+
 		// Live update the container config
 		for _, key := range changedConfig {
 			value := d.expandedConfig[key]
 
-			if key == "raw.apparmor" || key == "security.nesting" {
-				// Update the AppArmor profile
+			if key == "raw.apparmor" || key == "security.nesting" || key == "security.apparmor.profile" {
+				// Update the AppArmor profile.
+				// Running processes keep the profile they were started with, so switching between the generated
+				// and a custom profile only reloads the generated one (or checks the custom one is loaded).
 				err = apparmor.InstanceLoad(d.state.OS, d, nil)
 				if err != nil {
 					return err
@@ -4898,13 +5188,10 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 						}
 					}
 				}
-			} else if key == "linux.kernel_modules" && value != "" {
-				for _, module := range strings.Split(value, ",") {
-					module = strings.TrimPrefix(module, " ")
-					err := linux.LoadModule(module)
-					if err != nil {
-						return fmt.Errorf("Failed to load kernel module '%s': %w", module, err)
-					}
+			} else if key == "linux.kernel_modules" {
+				err = d.loadKernelModules(value)
+				if err != nil {
+					return err
 				}
 			} else if key == "limits.disk.priority" {
 				if !d.state.OS.CGInfo.Supports(cgroup.Blkio, cg) {
@@ -5006,51 +5293,18 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 				}
 
 				// Set the new values
-				if memoryEnforce == "soft" {
-					// Set new limit.
-					err = cg.SetMemorySoftLimit(memoryInt)
+				if memoryInt > 0 {
+					swapInt, err := cgroup.MemorySwapLimit(memoryEnforce, memorySwap)
 					if err != nil {
 						revertMemory()
 						return err
 					}
-				} else {
-					err = cg.SetMemoryLimit(memoryInt)
+
+					err = cg.SetMemoryLimits(memoryInt, memoryEnforce, swapInt, d.state.OS.CGInfo.Supports(cgroup.MemorySwap, cg))
 					if err != nil {
 						revertMemory()
 						return err
 					}
-
-					if d.state.OS.CGInfo.Supports(cgroup.MemorySwap, cg) {
-						if util.IsTrueOrEmpty(memorySwap) || util.IsFalse(memorySwap) {
-							err = cg.SetMemorySwapLimit(0)
-							if err != nil {
-								revertMemory()
-								return err
-							}
-						} else {
-							// Additional memory as swap.
-							swapInt, err := units.ParseByteSizeString(memorySwap)
-							if err != nil {
-								revertMemory()
-								return err
-							}
-
-							err = cg.SetMemorySwapLimit(swapInt)
-							if err != nil {
-								revertMemory()
-								return err
-							}
-						}
-					}
-
-					// If on Cgroup1, set soft limit to value 10% less than hard limit.
-					if memoryInt > 0 && slices.Contains([]cgroup.Layout{cgroup.CgroupsLegacy, cgroup.CgroupsHybrid}, d.state.OS.CGInfo.Layout) {
-						err = cg.SetMemorySoftLimit(int64(float64(memoryInt) * 0.9))
-						if err != nil {
-							revertMemory()
-							return err
-						}
-					}
 				}
 
 				if !d.state.OS.CGInfo.Supports(cgroup.MemorySwappiness, cg) {
@@ -5156,6 +5410,26 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 				if err != nil {
 					return err
 				}
+			} else if strings.HasPrefix(key, "linux.sysctl.") {
+				// The previous value isn't known, removed sysctls only go away on restart.
+				// Sysctls outside of the container's namespaces are only applied on start.
+				sysctl := strings.TrimPrefix(key, "linux.sysctl.")
+				if value == "" || internalInstance.SysctlNamespace(sysctl) == "" {
+					continue
+				}
+
+				err = d.setSysctl(strings.TrimPrefix(key, "linux.sysctl."), value)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Re-apply the raw cgroup values so they keep precedence over any changed limits.
+		if slices.ContainsFunc(changedConfig, func(key string) bool { return key == "raw.cgroup" || strings.HasPrefix(key, "limits.") }) {
+			err = d.setRawCgroup(cg)
+			if err != nil {
+				return err
 			}
 		}
 	}
@@ -5168,6 +5442,14 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 		}
 	}
 
+	// Re-apply the image templates on next start to write the new hostname.
+	if !d.IsSnapshot() && slices.Contains(changedConfig, "dns.hostname") {
+		err = d.DeferTemplateApply(instance.TemplateTriggerRename)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Finally, apply the changes to the database
 	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		// Snapshots should update only their descriptions and expiry date.
@@ -7061,6 +7343,7 @@ func (d *lxc) templateApplyNow(trigger instance.TemplateTrigger) error {
 	// Generate the container metadata
 	containerMeta := make(map[string]string)
 	containerMeta["name"] = d.name
+	containerMeta["hostname"] = instance.Hostname(d.name, d.expandedConfig)
 	containerMeta["type"] = "container"
 	containerMeta["architecture"] = arch
 
@@ -7077,6 +7360,7 @@ func (d *lxc) templateApplyNow(trigger instance.TemplateTrigger) error {
 	}
 
 	// Go through the templates
+	hostnameTemplated := false
 	for tplPath, tpl := range metadata.Templates {
 		err = func(tplPath string, tpl *api.ImageMetadataTemplate) error {
 			var w *os.File
@@ -7211,6 +7495,10 @@ func (d *lxc) templateApplyNow(trigger instance.TemplateTrigger) error {
 				return err
 			}
 
+			if tplPath == "/etc/hostname" {
+				hostnameTemplated = true
+			}
+
 			return w.Close()
 		}(tplPath, tpl)
 		if err != nil {
@@ -7218,6 +7506,27 @@ func (d *lxc) templateApplyNow(trigger instance.TemplateTrigger) error {
 		}
 	}
 
+	// Image templates write the hostname from the instance name, replace it with the configured one.
+	if hostnameTemplated && d.expandedConfig["dns.hostname"] != "" {
+		// Don't follow a symlink placed there by the container.
+		f, err := os.OpenFile(filepath.Join(d.RootfsPath(), "etc", "hostname"), os.O_WRONLY|os.O_TRUNC|unix.O_NOFOLLOW, 0)
+		if err != nil {
+			return fmt.Errorf("Failed to open hostname file: %w", err)
+		}
+
+		defer func() { _ = f.Close() }()
+
+		_, err = f.WriteString(d.expandedConfig["dns.hostname"] + "\n")
+		if err != nil {
+			return fmt.Errorf("Failed to write hostname: %w", err)
+		}
+
+		err = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -7572,6 +7881,7 @@ func (d *lxc) Console(protocol string) (*os.File, chan error, error) {
 		_ = cmd.Process.Kill()
 	}()
 
+	d.recordLastUsed()
 	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceConsole.Event(d, logger.Ctx{"type": instance.ConsoleTypeConsole}))
 
 	return ptx, chDisconnect, nil
@@ -7589,6 +7899,13 @@ func (d *lxc) ConsoleLog(opts liblxc.ConsoleLogOptions) (string, error) {
 		return "", err
 	}
 
+	if opts.WriteToLogFile {
+		err = d.consoleLogAdopt()
+		if err != nil {
+			return "", fmt.Errorf("Failed rotating console log: %w", err)
+		}
+	}
+
 	if opts.ClearLog {
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceConsoleReset.Event(d, nil))
 	} else if opts.ReadLog && opts.WriteToLogFile {
@@ -7598,6 +7915,16 @@ func (d *lxc) ConsoleLog(opts liblxc.ConsoleLogOptions) (string, error) {
 	return string(msg), nil
 }
 
+// consoleLogAdopt applies the console log rotation settings to the log file rotated by liblxc.
+func (d *lxc) consoleLogAdopt() error {
+	limits, err := instance.ConsoleLogLimitsFromConfig(d.expandedConfig)
+	if err != nil {
+		return err
+	}
+
+	return instance.ConsoleLogAdopt(d.ConsoleBufferLogPath(), limits)
+}
+
 // Exec executes a command inside the instance.
 func (d *lxc) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, stderr *os.File) (instance.Cmd, error) {
 	// Generate the LXC config if missing.
@@ -7706,6 +8033,7 @@ func (d *lxc) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, st
 
 	d.logger.Debug("Retrieved PID of executing child process", logger.Ctx{"attachedPid": attachedPid})
 
+	d.recordLastUsed()
 	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceExec.Event(d, logger.Ctx{"command": req.Command}))
 
 	instCmd := &lxcCmd{
@@ -8290,6 +8618,40 @@ func (d *lxc) removeMount(mount string) error {
 	return nil
 }
 
+// remountMount applies the per mount point flags to an existing bind mount of the container.
+func (d *lxc) remountMount(mount string, flags int) error {
+	pid := d.InitPID()
+	if pid == -1 {
+		return fmt.Errorf("Can't remount in stopped container")
+	}
+
+	if !strings.HasPrefix(mount, "/") {
+		mount = "/" + mount
+	}
+
+	pidFdNr, pidFd := d.inheritInitPidFd()
+	if pidFdNr >= 0 {
+		defer func() { _ = pidFd.Close() }()
+	}
+
+	_, err := subprocess.RunCommandInheritFds(
+		context.TODO(),
+		[]*os.File{pidFd},
+		d.state.OS.ExecPath,
+		"forkmount",
+		"go-remount",
+		"--",
+		fmt.Sprintf("%d", pid),
+		fmt.Sprintf("%d", pidFdNr),
+		mount,
+		fmt.Sprintf("%d", flags))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // InsertSeccompUnixDevice inserts a seccomp device.
 func (d *lxc) InsertSeccompUnixDevice(prefix string, m deviceConfig.Device, pid int) error {
 	if pid < 0 {
@@ -8453,21 +8815,16 @@ func (d *lxc) FillNetworkDevice(name string, m deviceConfig.Device) (deviceConfi
 		return nil, err
 	}
 
-	// Fill in the MAC address.
-	if !slices.Contains([]string{"physical", "ipvlan"}, nicType) && m["hwaddr"] == "" {
+	// Fill in the MAC address (tun interfaces don't have one).
+	isTun := nicType == "tuntap" && m["mode"] != "tap"
+	if !slices.Contains([]string{"physical", "ipvlan"}, nicType) && !isTun && m["hwaddr"] == "" {
 		configKey := fmt.Sprintf("volatile.%s.hwaddr", name)
 		volatileHwaddr := d.localConfig[configKey]
 		if volatileHwaddr == "" {
-			// Generate a new MAC address.
-			volatileHwaddr, err = instance.DeviceNextInterfaceHWAddr()
-			if err != nil || volatileHwaddr == "" {
-				return nil, fmt.Errorf("Failed generating %q: %w", configKey, err)
-			}
-
-			// Update the database and update volatileHwaddr with stored value.
-			volatileHwaddr, err = d.insertConfigkey(configKey, volatileHwaddr)
+			// Generate a new MAC address and update volatileHwaddr with stored value.
+			volatileHwaddr, err = d.allocateInterfaceHWAddr(configKey)
 			if err != nil {
-				return nil, fmt.Errorf("Failed storing generated config key %q: %w", configKey, err)
+				return nil, fmt.Errorf("Failed generating %q: %w", configKey, err)
 			}
 
 			// Set stored value into current instance config.
@@ -8614,6 +8971,46 @@ func (d *lxc) InitPID() int {
 	return cc.InitPid()
 }
 
+// setSysctl writes a namespaced sysctl from within the user namespace and the namespace the sysctl is attached to
+// of the running container.
+func (d *lxc) setSysctl(name string, value string) error {
+	namespace := internalInstance.SysctlNamespace(name)
+	if namespace == "" {
+		return fmt.Errorf("Sysctl %q isn't namespaced", name)
+	}
+
+	if strings.Contains(name, "/") {
+		return fmt.Errorf("Invalid sysctl %q", name)
+	}
+
+	pid := d.InitPID()
+	if pid <= 0 {
+		return fmt.Errorf("Container isn't running")
+	}
+
+	pidFdNr, pidFd := d.inheritInitPidFd()
+	if pidFdNr >= 0 {
+		defer func() { _ = pidFd.Close() }()
+	}
+
+	_, stderr, err := subprocess.RunCommandSplit(
+		context.TODO(),
+		nil,
+		[]*os.File{pidFd},
+		d.state.OS.ExecPath,
+		"forksysctl",
+		fmt.Sprintf("%d", pid),
+		fmt.Sprintf("%d", pidFdNr),
+		namespace,
+		strings.ReplaceAll(name, ".", "/"),
+		value)
+	if err != nil {
+		return fmt.Errorf("Failed setting sysctl %q: %s", name, strings.TrimSpace(stderr))
+	}
+
+	return nil
+}
+
 // InitPidFd returns pidfd of init process.
 func (d *lxc) InitPidFd() (*os.File, error) {
 	// Load the go-lxc struct
@@ -8724,6 +9121,39 @@ func (d *lxc) CGroup() (*cgroup.CGroup, error) {
 	return d.cgroup(cc, true)
 }
 
+// lxcRawCgroupLimitKey returns the limits option managing a cgroup file which can be set through raw.cgroup.
+func lxcRawCgroupLimitKey(key string) string {
+	switch key {
+	case "cpu.max", "cpu.weight":
+		return "limits.cpu.allowance"
+	case "io.weight", "io.bfq.weight":
+		return "limits.disk.priority"
+	case "memory.high", "memory.max", "memory.swap.max":
+		return "limits.memory"
+	case "pids.max":
+		return "limits.processes"
+	}
+
+	return ""
+}
+
+// setRawCgroup writes the values of raw.cgroup to the cgroup.
+func (d *lxc) setRawCgroup(cg *cgroup.CGroup) error {
+	entries, err := cgroup.ParseRaw(d.expandedConfig["raw.cgroup"])
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = cg.SetRaw(entry.Key, entry.Value)
+		if err != nil {
+			return fmt.Errorf("Failed setting cgroup file %q to %q: %w", entry.Key, entry.Value, err)
+		}
+	}
+
+	return nil
+}
+
 func (d *lxc) cgroup(cc *liblxc.Container, running bool) (*cgroup.CGroup, error) {
 	if cc == nil {
 		return nil, fmt.Errorf("Container not initialized for cgroup")
@@ -8937,7 +9367,9 @@ func (d *lxc) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, error
 	}
 
 	// Get filesystem stats
-	fsStats, err := d.getFSStats()
+	excludedDevices := instance.MetricsExcludedDevices(d.expandedConfig)
+
+	fsStats, err := d.fsMetrics(excludedDevices, d.state.GlobalConfig.MetricsCacheTTLFilesystem())
 	if err != nil {
 		d.logger.Warn("Failed to get fs stats", logger.Ctx{"err": err})
 	} else {
@@ -8947,7 +9379,27 @@ func (d *lxc) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, error
 	// Get network stats
 	networkState := d.networkState(hostInterfaces)
 
+	// Figure out the interface names of the excluded NIC devices.
+	excludedInterfaces := []string{}
+	for _, devName := range excludedDevices {
+		dev, ok := d.expandedDevices[devName]
+		if !ok || dev["type"] != "nic" {
+			continue
+		}
+
+		nicName := dev["name"]
+		if nicName == "" {
+			nicName = d.localConfig[fmt.Sprintf("volatile.%s.name", devName)]
+		}
+
+		excludedInterfaces = append(excludedInterfaces, nicName)
+	}
+
 	for name, state := range networkState {
+		if slices.Contains(excludedInterfaces, name) {
+			continue
+		}
+
 		labels := map[string]string{"device": name}
 
 		out.AddSamples(metrics.NetworkReceiveBytesTotal, metrics.Sample{Value: float64(state.Counters.BytesReceived), Labels: labels})
@@ -8971,7 +9423,19 @@ func (d *lxc) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, error
 	return out, nil
 }
 
-func (d *lxc) getFSStats() (*metrics.MetricSet, error) {
+// lxcFSMetricsCache holds the filesystem usage metrics of containers as those are expensive to collect.
+var lxcFSMetricsCache = metrics.NewCache()
+
+// fsMetrics returns the filesystem usage metrics, reusing those collected less than ttl ago.
+func (d *lxc) fsMetrics(excludedDevices []string, ttl time.Duration) (*metrics.MetricSet, error) {
+	key := fmt.Sprintf("%d/%s", d.id, strings.Join(excludedDevices, ","))
+
+	return lxcFSMetricsCache.Get(key, ttl, func() (*metrics.MetricSet, error) {
+		return d.getFSStats(excludedDevices)
+	})
+}
+
+func (d *lxc) getFSStats(excludedDevices []string) (*metrics.MetricSet, error) {
 	type mountInfo struct {
 		Mountpoint string
 		FSType     string
@@ -8994,11 +9458,16 @@ func (d *lxc) getFSStats() (*metrics.MetricSet, error) {
 	}
 
 	// Get disk devices
-	for _, dev := range d.expandedDevices {
+	for devName, dev := range d.expandedDevices {
 		if dev["type"] != "disk" || dev["path"] == "" {
 			continue
 		}
 
+		// Skip devices excluded from metrics.
+		if slices.Contains(excludedDevices, devName) {
+			continue
+		}
+
 		var statfs *unix.Statfs_t
 		labels := make(map[string]string)
 		realDev := ""
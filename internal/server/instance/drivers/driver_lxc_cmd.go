@@ -21,8 +21,15 @@ func (c *lxcCmd) PID() int {
 }
 
 // Signal sends a signal to the command.
+// SIGKILL is sent to the whole process tree so that no process outlives a killed command.
 func (c *lxcCmd) Signal(sig unix.Signal) error {
-	err := unix.Kill(c.attachedChildPid, sig)
+	var err error
+	if sig == unix.SIGKILL {
+		err = linux.KillProcessTree(c.attachedChildPid)
+	} else {
+		err = unix.Kill(c.attachedChildPid, sig)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,100 @@
+package drivers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
+	"github.com/lxc/incus/v6/internal/server/metrics"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// BenchmarkFSMetrics measures collecting the filesystem metrics of a container with disk devices and merging them
+// into its metric set, as done on every scrape, with and without the filesystem metrics cache.
+func BenchmarkFSMetrics(b *testing.B) {
+	devices := deviceConfig.Devices{}
+	for i := 0; i < 10; i++ {
+		devices[fmt.Sprintf("disk%d", i)] = deviceConfig.Device{"type": "disk", "path": fmt.Sprintf("/mnt/disk%d", i), "source": b.TempDir()}
+	}
+
+	d := &lxc{common: common{id: 1, name: "c1", project: api.Project{Name: "default"}, expandedDevices: devices}}
+
+	for _, ttl := range []time.Duration{0, time.Minute} {
+		b.Run(fmt.Sprintf("ttl=%s", ttl), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				fsStats, err := d.fsMetrics(nil, ttl)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				out := metrics.NewMetricSet(map[string]string{"project": d.project.Name, "name": d.name, "type": "container"})
+				out.Merge(fsStats)
+			}
+		})
+	}
+}
+
+// Test that NIC devices can't be used alongside an external network namespace.
+func TestLXCCheckNetworkNamespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		devices deviceConfig.Devices
+		wantErr bool
+	}{
+		{
+			name:    "nic without namespace",
+			config:  map[string]string{},
+			devices: deviceConfig.Devices{"eth0": {"type": "nic", "network": "incusbr0"}},
+		},
+		{
+			name:    "namespace without nic",
+			config:  map[string]string{"linux.network_namespace": "foo"},
+			devices: deviceConfig.Devices{"root": {"type": "disk", "path": "/", "pool": "default"}},
+		},
+		{
+			name:    "namespace with nic",
+			config:  map[string]string{"linux.network_namespace": "foo"},
+			devices: deviceConfig.Devices{"eth0": {"type": "nic", "network": "incusbr0"}},
+			wantErr: true,
+		},
+		{
+			name:    "namespace with infiniband",
+			config:  map[string]string{"linux.network_namespace": "foo"},
+			devices: deviceConfig.Devices{"ib0": {"type": "infiniband", "parent": "ib0", "nictype": "physical"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &lxc{common: common{name: "c1", expandedConfig: tt.config, expandedDevices: tt.devices}}
+
+			err := d.checkNetworkNamespace()
+			if tt.wantErr && err == nil {
+				t.Error("Expected an error")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// Test that only namespace files are accepted as network namespaces.
+func TestLXCCheckNetworkNamespacePath(t *testing.T) {
+	err := lxcCheckNetworkNamespacePath("/proc/self/ns/net")
+	if err != nil {
+		t.Errorf("Unexpected error for a network namespace: %v", err)
+	}
+
+	err = lxcCheckNetworkNamespacePath(t.TempDir())
+	if err == nil {
+		t.Error("Expected an error for a regular directory")
+	}
+
+	err = lxcCheckNetworkNamespacePath("/run/netns/incus-test-missing")
+	if err == nil {
+		t.Error("Expected an error for a missing namespace")
+	}
+}
@@ -144,23 +144,24 @@ func qemuInstantiate(s *state.State, args db.InstanceArgs, expandedDevices devic
 		common: common{
 			state: s,
 
-			architecture: args.Architecture,
-			creationDate: args.CreationDate,
-			dbType:       args.Type,
-			description:  args.Description,
-			ephemeral:    args.Ephemeral,
-			expiryDate:   args.ExpiryDate,
-			id:           args.ID,
-			lastUsedDate: args.LastUsedDate,
-			localConfig:  args.Config,
-			localDevices: args.Devices,
-			logger:       logger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
-			name:         args.Name,
-			node:         args.Node,
-			profiles:     args.Profiles,
-			project:      p,
-			isSnapshot:   args.Snapshot,
-			stateful:     args.Stateful,
+			architecture:    args.Architecture,
+			creationDate:    args.CreationDate,
+			dbType:          args.Type,
+			description:     args.Description,
+			ephemeral:       args.Ephemeral,
+			expiryDate:      args.ExpiryDate,
+			id:              args.ID,
+			lastUsedDate:    args.LastUsedDate,
+			lastStartedDate: args.LastStartedDate,
+			localConfig:     args.Config,
+			localDevices:    args.Devices,
+			logger:          logger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
+			name:            args.Name,
+			node:            args.Node,
+			profiles:        args.Profiles,
+			project:         p,
+			isSnapshot:      args.Snapshot,
+			stateful:        args.Stateful,
 		},
 	}
 
@@ -203,23 +204,24 @@ func qemuCreate(s *state.State, args db.InstanceArgs, p api.Project, op *operati
 			state: s,
 			op:    op,
 
-			architecture: args.Architecture,
-			creationDate: args.CreationDate,
-			dbType:       args.Type,
-			description:  args.Description,
-			ephemeral:    args.Ephemeral,
-			expiryDate:   args.ExpiryDate,
-			id:           args.ID,
-			lastUsedDate: args.LastUsedDate,
-			localConfig:  args.Config,
-			localDevices: args.Devices,
-			logger:       logger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
-			name:         args.Name,
-			node:         args.Node,
-			profiles:     args.Profiles,
-			project:      p,
-			isSnapshot:   args.Snapshot,
-			stateful:     args.Stateful,
+			architecture:    args.Architecture,
+			creationDate:    args.CreationDate,
+			dbType:          args.Type,
+			description:     args.Description,
+			ephemeral:       args.Ephemeral,
+			expiryDate:      args.ExpiryDate,
+			id:              args.ID,
+			lastUsedDate:    args.LastUsedDate,
+			lastStartedDate: args.LastStartedDate,
+			localConfig:     args.Config,
+			localDevices:    args.Devices,
+			logger:          logger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
+			name:            args.Name,
+			node:            args.Node,
+			profiles:        args.Profiles,
+			project:         p,
+			isSnapshot:      args.Snapshot,
+			stateful:        args.Stateful,
 		},
 	}
 
@@ -256,6 +258,11 @@ func qemuCreate(s *state.State, args db.InstanceArgs, p api.Project, op *operati
 
 	// When not a snapshot, perform full validation.
 	if !args.Snapshot {
+		err = internalInstance.ValidateProfilePriorities(d.profiles)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid profiles: %w", err)
+		}
+
 		// Validate expanded config (allows mixed instance types for profiles).
 		err = instance.ValidConfig(s.OS, d.expandedConfig, true, instancetype.Any)
 		if err != nil {
@@ -506,7 +513,8 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 				d.logger.Debug("Instance stopped", logger.Ctx{"target": target, "reason": data["reason"]})
 			}
 
-			err = d.onStop(target)
+			// Anything but a clean shutdown from within the guest is considered a failure.
+			err = d.onStop(target, entry != "guest-shutdown")
 			if err != nil {
 				d.logger.Error("Failed to cleanly stop instance", logger.Ctx{"err": err})
 				return
@@ -647,8 +655,9 @@ func (d *qemu) pidWait(timeout time.Duration) bool {
 	return true
 }
 
-// onStop is run when the instance stops.
-func (d *qemu) onStop(target string) error {
+// onStop is run when the instance stops. The failure argument indicates whether the instance stopped
+// unexpectedly rather than being shut down cleanly.
+func (d *qemu) onStop(target string, failure bool) error {
 	d.logger.Debug("onStop hook started", logger.Ctx{"target": target})
 	defer d.logger.Debug("onStop hook finished", logger.Ctx{"target": target})
 
@@ -677,6 +686,9 @@ func (d *qemu) onStop(target string) error {
 		d.logger.Error("VM process failed to stop", logger.Ctx{"timeout": waitTimeout})
 	}
 
+	// Forward the remaining console output.
+	d.consoleForwardStop()
+
 	// Record power state.
 	err = d.VolatileSet(map[string]string{
 		"volatile.last_state.power": instance.PowerStateStopped,
@@ -710,12 +722,21 @@ func (d *qemu) onStop(target string) error {
 
 	// Determine if instance should be auto-restarted.
 	var autoRestart bool
-	if target != "reboot" && op.GetInstanceInitiated() && d.shouldAutoRestart() {
-		autoRestart = true
+	var restartDelay time.Duration
+	if restartAllowed(target, op) {
+		autoRestart, restartDelay = d.shouldRestart(failure)
+	}
 
+	if autoRestart {
 		// Mark current shutdown as complete.
 		op.Done(nil)
 
+		// Wait for the restart policy backoff in the background as this is run from the monitor event handler.
+		if restartDelay > 0 {
+			go d.restartAfterBackoff(restartDelay)
+			return nil
+		}
+
 		// Create a new restart operation.
 		op, err = operationlock.CreateWaitGet(d.Project().Name, d.Name(), d.op, operationlock.ActionRestart, nil, true, false)
 		if err == nil {
@@ -740,7 +761,7 @@ func (d *qemu) onStop(target string) error {
 			return err
 		}
 
-		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceRestarted.Event(d, nil))
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceRestarted.Event(d, d.restartLifecycleContext(autoRestart)))
 	} else if d.ephemeral {
 		// Destroy ephemeral virtual machines.
 		err = d.delete(true)
@@ -753,6 +774,35 @@ func (d *qemu) onStop(target string) error {
 	return nil
 }
 
+// restartAfterBackoff restarts the instance under its restart policy once the backoff delay elapsed.
+func (d *qemu) restartAfterBackoff(delay time.Duration) {
+	if !d.restartWait(delay) {
+		return
+	}
+
+	// Create a new restart operation.
+	op, err := operationlock.CreateWaitGet(d.Project().Name, d.Name(), d.op, operationlock.ActionRestart, nil, true, false)
+	if err == nil {
+		defer op.Done(nil)
+	} else {
+		d.logger.Error("Failed to setup new restart operation", logger.Ctx{"err": err})
+	}
+
+	// Skip the restart if the instance was started in the meantime.
+	if d.IsRunning() {
+		return
+	}
+
+	err = d.Start(false)
+	if err != nil {
+		op.Done(err)
+		d.logger.Error("Failed restarting instance", logger.Ctx{"err": err})
+		return
+	}
+
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceRestarted.Event(d, d.restartLifecycleContext(true)))
+}
+
 // Shutdown shuts the instance down.
 func (d *qemu) Shutdown(timeout time.Duration) error {
 	d.logger.Debug("Shutdown started", logger.Ctx{"timeout": timeout})
@@ -765,6 +815,11 @@ func (d *qemu) Shutdown(timeout time.Duration) error {
 			return fmt.Errorf("The instance cannot be cleanly shutdown as in %s status", statusCode)
 		}
 
+		// Stopping an instance waiting to be restarted by its restart policy cancels the restart.
+		if d.restartCancel() {
+			return nil
+		}
+
 		return ErrInstanceIsStopped
 	}
 
@@ -804,9 +859,12 @@ func (d *qemu) Shutdown(timeout time.Duration) error {
 		return err
 	}
 
+	d.hookPreStop(monitor)
+
 	// Indicate to the onStop hook that if the VM stops it was due to a clean shutdown because the VM responded
-	// to the powerdown request.
+	// to the powerdown request, and that the shutdown was requested through Incus so must not trigger a restart.
 	op.SetInstanceInitiated(true)
+	op.SetUserInitiated(true)
 
 	// Send the system_powerdown command.
 	err = monitor.Powerdown()
@@ -1141,9 +1199,35 @@ func (d *qemu) validateStartup(stateful bool, statusCode api.StatusCode) error {
 		}
 	}
 
+	// Ensure the entropy source can be read.
+	rngSource, err := os.Open(d.rngSource())
+	if err != nil {
+		return fmt.Errorf("Failed opening entropy source %q: %w", d.rngSource(), err)
+	}
+
+	defer func() { _ = rngSource.Close() }()
+
+	rngSourceInfo, err := rngSource.Stat()
+	if err != nil {
+		return fmt.Errorf("Failed checking entropy source %q: %w", d.rngSource(), err)
+	}
+
+	if rngSourceInfo.IsDir() {
+		return fmt.Errorf("Entropy source %q is a directory", d.rngSource())
+	}
+
 	return nil
 }
 
+// rngSource returns the host entropy source to use for the VM's random number generator.
+func (d *qemu) rngSource() string {
+	if d.expandedConfig["rng.source"] != "" {
+		return d.expandedConfig["rng.source"]
+	}
+
+	return "/dev/urandom"
+}
+
 func (d *qemu) checkStateStorage() error {
 	// For some operations, the "size.state" of the instance root disk device must be larger than the instance memory.
 	// Otherwise, there will not be enough disk space to write the instance state to disk during any subsequent stops.
@@ -1213,26 +1297,36 @@ func (d *qemu) runStartupScriptlet(monitor *qmp.Monitor, stage string) error {
 	return nil
 }
 
+// runQMPCommands runs a JSON list of QMP commands at the given stage.
+func runQMPCommands(monitor *qmp.Monitor, stage string, commands string) error {
+	var commandList []map[string]any
+	err := json.Unmarshal([]byte(commands), &commandList)
+	if err != nil {
+		err = fmt.Errorf("Failed to parse QMP commands at %s stage (expected JSON list of objects): %w", stage, err)
+		return err
+	}
+
+	for _, command := range commandList {
+		jsonCommand, _ := json.Marshal(command)
+		err = monitor.RunJSON(jsonCommand, nil, true)
+		if err != nil {
+			err = fmt.Errorf("Failed to run QMP command %s at %s stage: %w", jsonCommand, stage, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
 // startupHook executes QMP commands and runs startup scriptlets at early, pre-start and post-start
 // stages.
 func (d *qemu) startupHook(monitor *qmp.Monitor, stage string) error {
 	commands, ok := d.expandedConfig["raw.qemu.qmp."+stage]
 	if ok {
-		var commandList []map[string]any
-		err := json.Unmarshal([]byte(commands), &commandList)
+		err := runQMPCommands(monitor, stage, commands)
 		if err != nil {
-			err = fmt.Errorf("Failed to parse QMP commands at %s stage (expected JSON list of objects): %w", stage, err)
 			return err
 		}
-
-		for _, command := range commandList {
-			jsonCommand, _ := json.Marshal(command)
-			err = monitor.RunJSON(jsonCommand, nil, true)
-			if err != nil {
-				err = fmt.Errorf("Failed to run QMP command %s at %s stage: %w", jsonCommand, stage, err)
-				return err
-			}
-		}
 	}
 
 	return d.runStartupScriptlet(monitor, stage)
@@ -1243,10 +1337,16 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	d.logger.Debug("Start started", logger.Ctx{"stateful": stateful})
 	defer d.logger.Debug("Start finished", logger.Ctx{"stateful": stateful})
 
+	// Wait for the networks and storage pools used by the instance to be ready.
+	err := d.waitDependencies()
+	if err != nil {
+		return err
+	}
+
 	// Check that we are startable before creating an operation lock.
 	// Must happen before creating operation Start lock to avoid the status check returning Stopped due to the
 	// existence of a Start operation lock.
-	err := d.validateStartup(stateful, d.statusCode())
+	err = d.validateStartup(stateful, d.statusCode())
 	if err != nil {
 		return err
 	}
@@ -1266,6 +1366,12 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 
 	defer op.Done(err)
 
+	// Reset the restart policy count when started by the user.
+	if op.Action() == operationlock.ActionStart {
+		d.restartCancel()
+		d.restartCountReset()
+	}
+
 	// Assign NUMA node(s) if needed.
 	if d.expandedConfig["limits.cpu.nodes"] == "balanced" {
 		err := d.balanceNUMANodes()
@@ -1282,6 +1388,13 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		return err
 	}
 
+	// Load any required kernel modules.
+	err = d.loadKernelModules(d.expandedConfig["linux.kernel_modules"])
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
 	reverter := revert.New()
 	defer reverter.Fail()
 
@@ -1569,7 +1682,22 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	cpuType := "host"
 
 	// Handle CPU flags.
-	if d.state.ServerClustered && util.IsTrue(d.expandedConfig["migration.stateful"]) {
+	if d.expandedConfig["limits.cpu.model"] != "" {
+		// Use the CPU model set on the instance, recording it so it stays the same across restarts.
+		cpuType, err = d.cpuModel()
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
+		if d.localConfig["volatile.cpu.model"] != cpuType {
+			err = d.VolatileSet(map[string]string{"volatile.cpu.model": cpuType})
+			if err != nil {
+				op.Done(err)
+				return err
+			}
+		}
+	} else if d.state.ServerClustered && util.IsTrue(d.expandedConfig["migration.stateful"]) {
 		// Get the cluster group config.
 		var groupConfig map[string]string
 		err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -1634,6 +1762,29 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		}
 	}
 
+	// Expose the virtualization extensions to the guest if requested.
+	if util.IsTrue(d.expandedConfig["limits.nested"]) {
+		nestedFlag, err := d.nestedCPUFlag()
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
+		if slices.Contains(cpuExtensions, "-"+nestedFlag) || slices.Contains(cpuExtensions, nestedFlag+"=off") {
+			err = fmt.Errorf("Nested virtualization requires the %q CPU flag which is disabled by the cluster group CPU flags", nestedFlag)
+			op.Done(err)
+			return err
+		}
+
+		if slices.Contains(strings.Split(cpuType, ","), "-"+nestedFlag) {
+			err = fmt.Errorf("Nested virtualization requires the %q CPU flag which is disabled by limits.cpu.model", nestedFlag)
+			op.Done(err)
+			return err
+		}
+
+		cpuExtensions = append(cpuExtensions, nestedFlag)
+	}
+
 	if len(cpuExtensions) > 0 {
 		cpuType += "," + strings.Join(cpuExtensions, ",")
 	}
@@ -1694,6 +1845,13 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 			op.Done(err)
 			return fmt.Errorf("Error updating instance stateful flag: %w", err)
 		}
+
+		// The saved state is gone, so the instance is no longer hibernated.
+		err = d.VolatileSet(map[string]string{"volatile.last_state.hibernated": ""})
+		if err != nil {
+			op.Done(err)
+			return err
+		}
 	}
 
 	// Set RTC to localtime on Windows.
@@ -1798,6 +1956,15 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		qemuArgs = append(qemuArgs, fields...)
 	}
 
+	// Append the arguments generated by the pre-start hook.
+	hookArgs, err := d.hookQEMUArgs()
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
+	qemuArgs = append(qemuArgs, hookArgs...)
+
 	d.cmdArgs = qemuArgs
 
 	// Precompile the QEMU scriptlet
@@ -2042,6 +2209,13 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 
 	// Finish handling stateful start.
 	if stateful {
+		// Make the guest see the configured MAC addresses if the state came from another instance.
+		err = d.replugRestoredNICs(monitor)
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
 		// Cleanup state.
 		_ = os.Remove(d.StatePath())
 		d.stateful = false
@@ -2053,6 +2227,12 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 			op.Done(err)
 			return fmt.Errorf("Error updating instance stateful flag: %w", err)
 		}
+
+		err = d.VolatileSet(map[string]string{"volatile.last_state.hibernated": ""})
+		if err != nil {
+			op.Done(err)
+			return err
+		}
 	}
 
 	// Record last start state.
@@ -2062,10 +2242,16 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		return err
 	}
 
+	d.resolveDeviceConflictWarnings()
+
 	reverter.Success()
 
 	// Post-start startup hook
 	err = d.startupHook(monitor, "post-start")
+	if err == nil {
+		err = d.hookQMP(monitor, "post-start")
+	}
+
 	if err != nil {
 		op.Done(err)
 
@@ -2084,10 +2270,18 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		return err
 	}
 
+	d.consoleForwardResume()
+
 	if op.Action() == "start" {
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStarted.Event(d, nil))
 	}
 
+	// The state of a restored or migrated VM may come from another instance and its clock is behind by the
+	// time it spent saved or in transit.
+	if stateful {
+		go d.agentAfterResume(true)
+	}
+
 	// The VM started cleanly so now enable the unexpected disconnection event to ensure the onStop hook is
 	// run if QMP unexpectedly disconnects.
 	monitor.SetOnDisconnectEvent(true)
@@ -2182,6 +2376,108 @@ func (d *qemu) setupSEV(fdFiles *[]*os.File) (*qemuSevOpts, error) {
 	return sevOpts, nil
 }
 
+// nestedCPUFlag checks that nested virtualization is enabled on the host and returns the CPU flag exposing
+// the virtualization extensions to the guest.
+func (d *qemu) nestedCPUFlag() (string, error) {
+	if d.architecture != osarch.ARCH_64BIT_INTEL_X86 {
+		return "", errors.New("Nested virtualization is only available on x86_64 systems")
+	}
+
+	for _, module := range []string{"kvm_intel", "kvm_amd"} {
+		nested, err := os.ReadFile(fmt.Sprintf("/sys/module/%s/parameters/nested", module))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return "", fmt.Errorf("Failed checking nested virtualization support: %w", err)
+		}
+
+		if !slices.Contains([]string{"Y", "1"}, strings.TrimSpace(string(nested))) {
+			return "", fmt.Errorf("Nested virtualization is disabled on the host, set the %q module parameter \"nested=1\" to enable it", module)
+		}
+
+		if module == "kvm_amd" {
+			return "svm", nil
+		}
+
+		return "vmx", nil
+	}
+
+	return "", errors.New("Nested virtualization requires the kvm_intel or kvm_amd kernel module to be loaded")
+}
+
+// cpuModel returns the CPU model to use for the VM, either as previously resolved or from limits.cpu.model.
+// The model is checked against the CPU models and features supported on this host.
+func (d *qemu) cpuModel() (string, error) {
+	value := d.localConfig["volatile.cpu.model"]
+	if value == "" {
+		value = d.expandedConfig["limits.cpu.model"]
+	}
+
+	info := DriverStatuses()[instancetype.VM].Info
+	cpuModels, _ := info.Features["cpu_models"].(map[string]qmp.CPUDefinition)
+	cpuFlags, _ := info.Features["flags"].(map[string]bool)
+
+	return qemuResolveCPUModel(value, cpuModels, cpuFlags)
+}
+
+// qemuResolveCPUModel resolves a CPU model name and its list of CPU features to enable (+) or disable (-).
+// Model aliases are replaced by the versioned model they point to so the resulting CPU doesn't change with
+// the QEMU version. The model and features are checked against those supported on the host when known.
+func qemuResolveCPUModel(value string, cpuModels map[string]qmp.CPUDefinition, cpuFlags map[string]bool) (string, error) {
+	fields := strings.Split(value, ",")
+	model := fields[0]
+	features := fields[1:]
+
+	for _, feature := range features {
+		if cpuFlags == nil {
+			break
+		}
+
+		// QEMU accepts both dashes and underscores in feature names.
+		name := feature[1:]
+		_, ok := cpuFlags[name]
+		_, okDash := cpuFlags[strings.ReplaceAll(name, "_", "-")]
+		_, okUnderscore := cpuFlags[strings.ReplaceAll(name, "-", "_")]
+		if !ok && !okDash && !okUnderscore {
+			return "", fmt.Errorf("Unknown CPU feature %q", name)
+		}
+	}
+
+	if model == "host" || cpuModels == nil {
+		return value, nil
+	}
+
+	definition, ok := cpuModels[model]
+	if !ok {
+		return "", fmt.Errorf("CPU model %q isn't supported on this host", model)
+	}
+
+	if definition.AliasOf != "" {
+		model = definition.AliasOf
+
+		definition, ok = cpuModels[model]
+		if !ok {
+			return "", fmt.Errorf("CPU model %q isn't supported on this host", model)
+		}
+	}
+
+	// Features missing on the host are only acceptable if explicitly disabled.
+	missing := []string{}
+	for _, feature := range definition.UnavailableFeatures {
+		if !slices.Contains(features, "-"+feature) {
+			missing = append(missing, feature)
+		}
+	}
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("CPU model %q can't be used on this host, missing CPU features: %s", model, strings.Join(missing, ", "))
+	}
+
+	return strings.Join(append([]string{model}, features...), ","), nil
+}
+
 // getAgentConnectionInfo returns the connection info the agent needs to connect to the server.
 func (d *qemu) getAgentConnectionInfo() (*agentAPI.API10Put, error) {
 	addr := d.state.Endpoints.VsockAddress()
@@ -2370,9 +2666,11 @@ func (d *qemu) qemuArchConfig(arch int) (string, string, error) {
 	return "", "", fmt.Errorf("Architecture isn't supported for virtual machines")
 }
 
-// RegisterDevices calls the Register() function on all of the instance's devices.
+// RegisterDevices calls the Register() function on all of the instance's devices
+// and resumes forwarding its console output.
 func (d *qemu) RegisterDevices() {
 	d.devicesRegister(d)
+	d.consoleForwardResume()
 }
 
 func (d *qemu) saveConnectionInfo(connInfo *agentAPI.API10Put) error {
@@ -2593,6 +2891,26 @@ func (d *qemu) deviceAttachBlockDevice(deviceName string, configCopy map[string]
 	return nil
 }
 
+// deviceChangeMedia replaces the medium of a removable drive of the running instance.
+func (d *qemu) deviceChangeMedia(mount deviceConfig.MountEntryItem) error {
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler(), d.QMPLogFilePath())
+	if err != nil {
+		return fmt.Errorf("Failed to connect to QMP monitor: %w", err)
+	}
+
+	monHook, err := d.addDriveConfig(nil, nil, mount)
+	if err != nil {
+		return fmt.Errorf("Failed to add drive config: %w", err)
+	}
+
+	err = monHook(monitor)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (d *qemu) deviceDetachPath(deviceName string, rawConfig deviceConfig.Device) error {
 	escapedDeviceName := linux.PathNameEncode(deviceName)
 	deviceID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName)
@@ -2641,9 +2959,17 @@ func (d *qemu) deviceDetachBlockDevice(deviceName string, rawConfig deviceConfig
 	deviceID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName)
 	blockDevName := d.blockNodeName(escapedDeviceName)
 
-	err = monitor.RemoveFDFromFDSet(blockDevName)
-	if err != nil {
-		return err
+	// The medium of a removable drive may use the alternate node name.
+	blockDevNames := []string{blockDevName}
+	if rawConfig["media"] == "cdrom" {
+		blockDevNames = append(blockDevNames, qmp.MediaAltNodeName(blockDevName))
+	}
+
+	for _, name := range blockDevNames {
+		err = monitor.RemoveFDFromFDSet(name)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = monitor.RemoveDevice(deviceID)
@@ -2653,19 +2979,21 @@ func (d *qemu) deviceDetachBlockDevice(deviceName string, rawConfig deviceConfig
 
 	waitDuration := time.Duration(time.Second * time.Duration(10))
 	waitUntil := time.Now().Add(waitDuration)
-	for {
-		err = monitor.RemoveBlockDevice(blockDevName)
-		if err == nil {
-			break
-		}
+	for _, name := range blockDevNames {
+		for {
+			err = monitor.RemoveBlockDevice(name)
+			if err == nil {
+				break
+			}
 
-		if api.StatusErrorCheck(err, http.StatusLocked) {
-			time.Sleep(time.Second * time.Duration(2))
-			continue
-		}
+			if api.StatusErrorCheck(err, http.StatusLocked) {
+				time.Sleep(time.Second * time.Duration(2))
+				continue
+			}
 
-		if time.Now().After(waitUntil) {
-			return fmt.Errorf("Failed to detach block device after %v", waitDuration)
+			if time.Now().After(waitUntil) {
+				return fmt.Errorf("Failed to detach block device after %v", waitDuration)
+			}
 		}
 	}
 
@@ -3280,6 +3608,12 @@ func (d *qemu) generateConfigShare() error {
 			}
 		}
 
+		// Add the DNS config.
+		err = d.writeDNSConfig()
+		if err != nil {
+			return err
+		}
+
 		// Writing the connection info the config drive allows the agent to start /dev/incus very
 		// early. This is important for systemd services which want or require /dev/incus/sock.
 		connInfo, err := d.getAgentConnectionInfo()
@@ -3329,6 +3663,7 @@ func (d *qemu) templateApplyNow(trigger instance.TemplateTrigger, path string) e
 	// Generate the instance metadata.
 	instanceMeta := make(map[string]string)
 	instanceMeta["name"] = d.name
+	instanceMeta["hostname"] = instance.Hostname(d.name, d.expandedConfig)
 	instanceMeta["type"] = "virtual-machine"
 	instanceMeta["architecture"] = arch
 
@@ -3581,11 +3916,30 @@ func (d *qemu) generateQemuConfig(machineDefinition string, cpuInfo *cpuTopology
 	conf = append(conf, qemuBalloon(&balloonOpts)...)
 
 	devBus, devAddr, multi = bus.allocate(busFunctionGroupGeneric)
-	rngOpts := qemuDevOpts{
-		busName:       bus.name,
-		devBus:        devBus,
-		devAddr:       devAddr,
-		multifunction: multi,
+	rngOpts := qemuRNGOpts{
+		dev: qemuDevOpts{
+			busName:       bus.name,
+			devBus:        devBus,
+			devAddr:       devAddr,
+			multifunction: multi,
+		},
+		source: d.rngSource(),
+	}
+
+	if d.expandedConfig["limits.rng.max_bytes"] != "" {
+		rngOpts.maxBytes, err = units.ParseByteSizeString(d.expandedConfig["limits.rng.max_bytes"])
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing limits.rng.max_bytes: %w", err)
+		}
+	}
+
+	if d.expandedConfig["limits.rng.period"] != "" {
+		period, err := strconv.ParseUint(d.expandedConfig["limits.rng.period"], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing limits.rng.period: %w", err)
+		}
+
+		rngOpts.period = uint32(period)
 	}
 
 	conf = append(conf, qemuRNG(&rngOpts)...)
@@ -4227,6 +4581,11 @@ func (d *qemu) addDriveDirConfig(conf *[]cfg.Section, bus *qemuBus, fdFiles *[]*
 
 // addDriveConfig adds the qemu config required for adding a supplementary drive.
 func (d *qemu) addDriveConfig(qemuDev map[string]any, bootIndexes map[string]int, driveConf deviceConfig.MountEntryItem) (monitorHook, error) {
+	// Removable drives can be used without a medium.
+	if driveConf.DevPath == "" && driveConf.FSType == "iso9660" {
+		return d.addEmptyDriveConfig(qemuDev, bootIndexes, driveConf)
+	}
+
 	aioMode := "native" // Use native kernel async IO and O_DIRECT by default.
 	cacheMode := "none" // Bypass host cache, use O_DIRECT semantics by default.
 	media := "disk"
@@ -4243,6 +4602,7 @@ func (d *qemu) addDriveConfig(qemuDev map[string]any, bootIndexes map[string]int
 	}
 
 	var isBlockDev bool
+	directIOSupported := true
 
 	// Detect device caches and I/O modes.
 	if isRBDImage {
@@ -4297,12 +4657,14 @@ func (d *qemu) addDriveConfig(qemuDev map[string]any, bootIndexes map[string]int
 			if fsType == "zfs" || fsType == "btrfs" {
 				aioMode = "threads"
 				cacheMode = "writeback" // Use host cache, with neither O_DSYNC nor O_DIRECT semantics.
+				directIOSupported = false
 			} else {
 				// Use host cache, with neither O_DSYNC nor O_DIRECT semantics if filesystem
 				// doesn't support Direct I/O.
 				f, err := os.OpenFile(srcDevPath, unix.O_DIRECT|unix.O_RDONLY, 0)
 				if err != nil {
 					cacheMode = "writeback"
+					directIOSupported = false
 				} else {
 					_ = f.Close() // Don't leak FD.
 				}
@@ -4342,36 +4704,27 @@ func (d *qemu) addDriveConfig(qemuDev map[string]any, bootIndexes map[string]int
 			continue
 		}
 
-		cacheMode = strings.TrimPrefix(opt, "cache=")
-		break
-	}
+		requestedCacheMode := strings.TrimPrefix(opt, "cache=")
 
-	// QMP uses two separate values for the cache.
-	directCache := true   // Bypass host cache, use O_DIRECT semantics by default.
-	noFlushCache := false // Don't ignore any flush requests for the device.
+		// The modes bypassing the host cache require the backing file to support O_DIRECT.
+		var fallback bool
+		cacheMode, fallback = qemuDiskCacheMode(requestedCacheMode, directIOSupported)
+		if fallback {
+			d.logger.Warn("Disk source doesn't support direct I/O, using host cache instead", logger.Ctx{"device": driveConf.DevName, "requested": requestedCacheMode, "cacheMode": cacheMode})
+		}
 
-	if cacheMode == "unsafe" {
-		aioMode = "threads"
-		directCache = false
-		noFlushCache = true
-	} else if cacheMode == "writeback" {
-		aioMode = "threads"
-		directCache = false
+		if cacheMode == "unsafe" {
+			d.logger.Warn("Using unsafe cache I/O as requested, guest data may be lost or corrupted on host crash", logger.Ctx{"device": driveConf.DevName})
+		}
+
+		break
 	}
 
+	aioMode, directCache, noFlushCache, writeCache := qemuDiskCacheOptions(cacheMode, aioMode)
+
 	escapedDeviceName := linux.PathNameEncode(driveConf.DevName)
 
-	blockDev := map[string]any{
-		"aio": aioMode,
-		"cache": map[string]any{
-			"direct":   directCache,
-			"no-flush": noFlushCache,
-		},
-		"discard":   "unmap", // Forward as an unmap request. This is the same as `discard=on` in the qemu config file.
-		"driver":    "file",
-		"node-name": d.blockNodeName(escapedDeviceName),
-		"read-only": false,
-	}
+	blockDev := qemuBlockDev(d.blockNodeName(escapedDeviceName), aioMode, directCache, noFlushCache, driveConf.Opts)
 
 	var rbdSecret string
 
@@ -4453,6 +4806,11 @@ func (d *qemu) addDriveConfig(qemuDev map[string]any, bootIndexes map[string]int
 	qemuDev["drive"] = blockDev["node-name"].(string)
 	qemuDev["serial"] = fmt.Sprintf("%s%s", qemuBlockDevIDPrefix, escapedDeviceName)
 
+	if !writeCache && slices.Contains([]string{"virtio-blk", "virtio-scsi"}, bus) {
+		// Have every write reported as complete only once it reached the host storage (O_DSYNC semantics).
+		qemuDev["write-cache"] = "off"
+	}
+
 	if bus == "virtio-scsi" {
 		qemuDev["device_id"] = d.blockNodeName(escapedDeviceName)
 		qemuDev["channel"] = 0
@@ -4493,6 +4851,8 @@ func (d *qemu) addDriveConfig(qemuDev map[string]any, bootIndexes map[string]int
 
 		nodeName := d.blockNodeName(escapedDeviceName)
 
+		var f *os.File
+
 		if isRBDImage {
 			secretID := fmt.Sprintf("pool_%s_%s", blockDev["pool"], blockDev["user"])
 
@@ -4513,32 +4873,43 @@ func (d *qemu) addDriveConfig(qemuDev map[string]any, bootIndexes map[string]int
 				permissions |= unix.O_DIRECT
 			}
 
-			f, err := os.OpenFile(driveConf.DevPath, permissions, 0)
+			var err error
+			f, err = os.OpenFile(driveConf.DevPath, permissions, 0)
 			if err != nil {
 				return fmt.Errorf("Failed opening file descriptor for disk device %q: %w", driveConf.DevName, err)
 			}
 
 			defer func() { _ = f.Close() }()
 
-			info, err := m.SendFileWithFDSet(nodeName, f, readonly)
-			if err != nil {
-				return fmt.Errorf("Failed sending file descriptor of %q for disk device %q: %w", f.Name(), driveConf.DevName, err)
-			}
+			// ChangeMedia sends the file descriptor of a new medium itself, keeping the current medium until the new one is inserted.
+			if !driveConf.NewMedium {
+				info, err := m.SendFileWithFDSet(nodeName, f, readonly)
+				if err != nil {
+					return fmt.Errorf("Failed sending file descriptor of %q for disk device %q: %w", f.Name(), driveConf.DevName, err)
+				}
 
-			reverter.Add(func() {
-				_ = m.RemoveFDFromFDSet(nodeName)
-			})
+				reverter.Add(func() {
+					_ = m.RemoveFDFromFDSet(nodeName)
+				})
 
-			blockDev["filename"] = fmt.Sprintf("/dev/fdset/%d", info.ID)
+				blockDev["filename"] = fmt.Sprintf("/dev/fdset/%d", info.ID)
+			}
 		}
 
-		err := m.AddBlockDevice(blockDev, qemuDev)
-		if err != nil {
-			return fmt.Errorf("Failed adding block device for disk device %q: %w", driveConf.DevName, err)
+		if driveConf.NewMedium {
+			err := m.ChangeMedia(qemuDev["id"].(string), nodeName, blockDev, f, readonly)
+			if err != nil {
+				return fmt.Errorf("Failed changing medium of disk device %q: %w", driveConf.DevName, err)
+			}
+		} else {
+			err := m.AddBlockDevice(blockDev, qemuDev)
+			if err != nil {
+				return fmt.Errorf("Failed adding block device for disk device %q: %w", driveConf.DevName, err)
+			}
 		}
 
 		if driveConf.Limits != nil {
-			err = m.SetBlockThrottle(qemuDev["id"].(string), int(driveConf.Limits.ReadBytes), int(driveConf.Limits.WriteBytes), int(driveConf.Limits.ReadIOps), int(driveConf.Limits.WriteIOps))
+			err := m.SetBlockThrottle(qemuDev["id"].(string), int(driveConf.Limits.ReadBytes), int(driveConf.Limits.WriteBytes), int(driveConf.Limits.ReadIOps), int(driveConf.Limits.WriteIOps))
 			if err != nil {
 				return fmt.Errorf("Failed applying limits for disk device %q: %w", driveConf.DevName, err)
 			}
@@ -4551,6 +4922,117 @@ func (d *qemu) addDriveConfig(qemuDev map[string]any, bootIndexes map[string]int
 	return monHook, nil
 }
 
+// addEmptyDriveConfig adds the qemu config required for adding a removable drive without a medium,
+// or for removing the medium of an existing one.
+func (d *qemu) addEmptyDriveConfig(qemuDev map[string]any, bootIndexes map[string]int, driveConf deviceConfig.MountEntryItem) (monitorHook, error) {
+	escapedDeviceName := linux.PathNameEncode(driveConf.DevName)
+	nodeName := d.blockNodeName(escapedDeviceName)
+
+	if qemuDev == nil {
+		qemuDev = map[string]any{}
+	}
+
+	qemuDev["id"] = fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName)
+	qemuDev["serial"] = fmt.Sprintf("%s%s", qemuBlockDevIDPrefix, escapedDeviceName)
+	qemuDev["device_id"] = nodeName
+	qemuDev["driver"] = "scsi-cd"
+	qemuDev["channel"] = 0
+	qemuDev["lun"] = 1
+	qemuDev["bus"] = "qemu_scsi.0"
+
+	if bootIndexes != nil {
+		qemuDev["bootindex"] = bootIndexes[driveConf.DevName]
+	}
+
+	monHook := func(m *qmp.Monitor) error {
+		if driveConf.NewMedium {
+			err := m.ChangeMedia(qemuDev["id"].(string), nodeName, nil, nil, false)
+			if err != nil {
+				return fmt.Errorf("Failed removing medium of disk device %q: %w", driveConf.DevName, err)
+			}
+
+			return nil
+		}
+
+		err := m.AddDevice(qemuDev)
+		if err != nil {
+			return fmt.Errorf("Failed adding empty drive for disk device %q: %w", driveConf.DevName, err)
+		}
+
+		return nil
+	}
+
+	return monHook, nil
+}
+
+// qemuDiskCacheMode returns the cache mode to use for a disk whose requested cache mode is cacheMode.
+// The modes bypassing the host cache fall back to their equivalent using it when the disk source doesn't support
+// direct I/O, in which case true is returned as well.
+func qemuDiskCacheMode(cacheMode string, directIOSupported bool) (string, bool) {
+	if directIOSupported {
+		return cacheMode, false
+	}
+
+	switch cacheMode {
+	case "none":
+		return "writeback", true
+	case "directsync":
+		return "writethrough", true
+	}
+
+	return cacheMode, false
+}
+
+// qemuDiskCacheOptions returns the AIO mode, whether to bypass the host cache, whether to ignore flush requests
+// and whether to expose a volatile write cache to the guest for the given cache mode.
+// QMP uses two separate values for the cache, the guest write cache is set on the device.
+func qemuDiskCacheOptions(cacheMode string, aioMode string) (string, bool, bool, bool) {
+	directCache := true   // Bypass host cache, use O_DIRECT semantics by default.
+	noFlushCache := false // Don't ignore any flush requests for the device.
+	writeCache := true    // Expose a volatile write cache to the guest.
+
+	switch cacheMode {
+	case "unsafe":
+		aioMode = "threads"
+		directCache = false
+		noFlushCache = true
+	case "writeback":
+		aioMode = "threads"
+		directCache = false
+	case "writethrough":
+		aioMode = "threads"
+		directCache = false
+		writeCache = false
+	case "directsync":
+		writeCache = false
+	}
+
+	return aioMode, directCache, noFlushCache, writeCache
+}
+
+// qemuBlockDev returns the base blockdev options of a drive.
+func qemuBlockDev(nodeName string, aioMode string, directCache bool, noFlushCache bool, opts []string) map[string]any {
+	blockDev := map[string]any{
+		"aio": aioMode,
+		"cache": map[string]any{
+			"direct":   directCache,
+			"no-flush": noFlushCache,
+		},
+		"discard":   "unmap", // Forward as an unmap request. This is the same as `discard=on` in the qemu config file.
+		"driver":    "file",
+		"node-name": nodeName,
+		"read-only": false,
+	}
+
+	// Guest discard requests are always forwarded, the discard option of the device additionally
+	// turns the guest writes of zeroes into discards so the block device can reclaim that space too.
+	if slices.Contains(opts, device.DiskDiscard) && !slices.Contains(opts, "ro") {
+		blockDev["detect-zeroes"] = "unmap"
+	}
+
+	return blockDev
+}
+
 // addNetDevConfig adds the qemu config required for adding a network device.
 // The qemuDev map is expected to be preconfigured with the settings for an existing port to use for the device.
 func (d *qemu) addNetDevConfig(busName string, qemuDev map[string]any, bootIndexes map[string]int, nicConfig []deviceConfig.RunConfigItem) (monitorHook, error) {
@@ -4893,6 +5375,74 @@ func (d *qemu) writeNICDevConfig(mtuStr string, devName string, nicName string,
 	return nil
 }
 
+// qemuAgentConfigEvents returns the config events to send to the agent for the changed keys, sorted by key.
+// Those cover the user.* keys exposed through devIncus and the dns.* keys applied by the agent itself.
+func qemuAgentConfigEvents(changedConfig []string, oldConfig map[string]string, newConfig map[string]string) []map[string]any {
+	keys := []string{}
+	for _, key := range changedConfig {
+		if util.StringHasPrefix(key, "user.", "dns.") {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	events := make([]map[string]any, 0, len(keys))
+	for _, key := range keys {
+		events = append(events, map[string]any{
+			"key":       key,
+			"old_value": oldConfig[key],
+			"value":     newConfig[key],
+		})
+	}
+
+	return events
+}
+
+// writeDNSConfig writes the DNS config into the config drive, removing it if no DNS settings are set.
+// This will be used by the agent to configure the DNS resolver inside the VM guest.
+func (d *qemu) writeDNSConfig() error {
+	dnsFile := filepath.Join(d.Path(), "config", deviceConfig.DNSConfigFile)
+
+	dnsConfig := deviceConfig.DNSConfig{
+		Nameservers: util.SplitNTrimSpace(d.expandedConfig["dns.nameservers"], ",", -1, true),
+		Search:      util.SplitNTrimSpace(d.expandedConfig["dns.search"], ",", -1, true),
+		Hostname:    d.expandedConfig["dns.hostname"],
+	}
+
+	// Fallback to the DNS servers of NICs without DHCP (e.g. routed).
+	if len(dnsConfig.Nameservers) == 0 {
+		for _, entry := range d.expandedDevices.Sorted() {
+			if entry.Config["type"] != "nic" {
+				continue
+			}
+
+			dnsConfig.Nameservers = append(dnsConfig.Nameservers, util.SplitNTrimSpace(entry.Config["dns.nameservers"], ",", -1, true)...)
+		}
+	}
+
+	if len(dnsConfig.Nameservers) == 0 && len(dnsConfig.Search) == 0 && dnsConfig.Hostname == "" {
+		err := os.Remove(dnsFile)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("Failed removing DNS config: %w", err)
+		}
+
+		return nil
+	}
+
+	dnsConfigBytes, err := json.Marshal(dnsConfig)
+	if err != nil {
+		return fmt.Errorf("Failed encoding DNS config: %w", err)
+	}
+
+	err = os.WriteFile(dnsFile, dnsConfigBytes, 0o400)
+	if err != nil {
+		return fmt.Errorf("Failed writing DNS config: %w", err)
+	}
+
+	return nil
+}
+
 // addPCIDevConfig adds the qemu config required for adding a raw PCI device.
 func (d *qemu) addPCIDevConfig(conf *[]cfg.Section, bus *qemuBus, pciConfig []deviceConfig.RunConfigItem) error {
 	var devName, pciSlotName string
@@ -5164,6 +5714,11 @@ func (d *qemu) Stop(stateful bool) error {
 	// Also Stop() is called from migrateSendLive in some cases, and instance status will be Frozen then.
 	statusCode := d.statusCode()
 	if !d.isRunningStatusCode(statusCode) && statusCode != api.Error && statusCode != api.Frozen {
+		// Stopping an instance waiting to be restarted by its restart policy cancels the restart.
+		if d.restartCancel() {
+			return nil
+		}
+
 		return ErrInstanceIsStopped
 	}
 
@@ -5199,6 +5754,9 @@ func (d *qemu) Stop(stateful bool) error {
 		return err
 	}
 
+	// Indicate to the onStop hook that the instance was stopped through Incus.
+	op.SetUserInitiated(true)
+
 	// Connect to the monitor.
 	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler(), d.QMPLogFilePath())
 	if err != nil {
@@ -5213,7 +5771,7 @@ func (d *qemu) Stop(stateful bool) error {
 		}
 
 		// Wait for QEMU process to exit and perform device cleanup.
-		err = d.onStop("stop")
+		err = d.onStop("stop", false)
 		if err != nil {
 			op.Done(err)
 			return err
@@ -5223,6 +5781,8 @@ func (d *qemu) Stop(stateful bool) error {
 		return nil
 	}
 
+	d.hookPreStop(monitor)
+
 	// Handle stateful stop.
 	if stateful {
 		// Dump the state.
@@ -5305,6 +5865,80 @@ func (d *qemu) Stop(stateful bool) error {
 	return nil
 }
 
+// Hibernate saves the full VM state to disk and stops the QEMU process, releasing the host memory.
+// The instance is resumed from the saved state on its next stateful start.
+func (d *qemu) Hibernate() error {
+	if !d.IsRunning() {
+		return ErrInstanceIsStopped
+	}
+
+	if util.IsFalseOrEmpty(d.expandedConfig["migration.stateful"]) {
+		return fmt.Errorf("Hibernation requires migration.stateful to be set to true")
+	}
+
+	// Confirm there is enough space to hold the memory state.
+	err := d.checkHibernateStorage()
+	if err != nil {
+		return err
+	}
+
+	err = d.VolatileSet(map[string]string{"volatile.last_state.hibernated": "true"})
+	if err != nil {
+		return err
+	}
+
+	err = d.Stop(true)
+	if err != nil {
+		_ = d.VolatileSet(map[string]string{"volatile.last_state.hibernated": ""})
+		return err
+	}
+
+	return nil
+}
+
+// checkHibernateStorage checks that the state can be written both within the configured
+// "size.state" and the space currently available on the volume holding the state file.
+func (d *qemu) checkHibernateStorage() error {
+	err := d.checkStateStorage()
+	if err != nil {
+		return err
+	}
+
+	memoryLimitStr := qemudefault.MemSize
+	if d.expandedConfig["limits.memory"] != "" {
+		memoryLimitStr = d.expandedConfig["limits.memory"]
+	}
+
+	memoryLimit, err := ParseMemoryStr(memoryLimitStr)
+	if err != nil {
+		return err
+	}
+
+	return qemuCheckHibernateSpace(d.StatePath(), memoryLimit)
+}
+
+// qemuCheckHibernateSpace checks that the filesystem holding statePath has room for a state of the given size,
+// accounting for an existing state file which gets replaced.
+func qemuCheckHibernateSpace(statePath string, size int64) error {
+	var statfs unix.Statfs_t
+	err := unix.Statfs(filepath.Dir(statePath), &statfs)
+	if err != nil {
+		return fmt.Errorf("Failed getting free space for the instance state: %w", err)
+	}
+
+	available := int64(statfs.Bavail) * int64(statfs.Bsize)
+	fi, err := os.Stat(statePath)
+	if err == nil {
+		available += fi.Size()
+	}
+
+	if available < size {
+		return fmt.Errorf("Not enough free space to hibernate the instance (%s needed, %s available)", units.GetByteSizeStringIEC(size, 2), units.GetByteSizeStringIEC(available, 2))
+	}
+
+	return nil
+}
+
 // Unfreeze restores the instance to running.
 func (d *qemu) Unfreeze() error {
 	// Connect to the monitor.
@@ -5319,6 +5953,8 @@ func (d *qemu) Unfreeze() error {
 		return err
 	}
 
+	go d.agentAfterResume(false)
+
 	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceResumed.Event(d, nil))
 	return nil
 }
@@ -5330,9 +5966,26 @@ func (d *qemu) IsPrivileged() bool {
 
 // snapshot creates a snapshot of the instance.
 func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
-	var err error
 	var monitor *qmp.Monitor
 
+	err := d.checkSnapshotsEnabled()
+	if err != nil {
+		return err
+	}
+
+	// Give the workload a chance to quiesce before taking the snapshot.
+	err = d.runSnapshotHook(d, "pre")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err := d.runSnapshotHook(d, "post")
+		if err != nil {
+			d.logger.Warn("Failed running snapshot post hook", logger.Ctx{"err": err})
+		}
+	}()
+
 	// Deal with state.
 	if stateful {
 		// Confirm the instance has stateful migration enabled.
@@ -5535,6 +6188,11 @@ func (d *qemu) Rename(newName string, applyTemplateTrigger bool) error {
 		return fmt.Errorf("Renaming of running instance not allowed")
 	}
 
+	err = d.renameCheck()
+	if err != nil {
+		return err
+	}
+
 	// Clean things up.
 	d.cleanup()
 
@@ -5543,18 +6201,34 @@ func (d *qemu) Rename(newName string, applyTemplateTrigger bool) error {
 		return fmt.Errorf("Failed loading instance storage pool: %w", err)
 	}
 
+	reverter := revert.New()
+	defer reverter.Fail()
+
 	if d.IsSnapshot() {
+		_, oldSnapName, _ := api.GetParentAndSnapshotName(oldName)
 		_, newSnapName, _ := api.GetParentAndSnapshotName(newName)
 		err = pool.RenameInstanceSnapshot(d, newSnapName, nil)
 		if err != nil {
 			return fmt.Errorf("Rename instance snapshot: %w", err)
 		}
+
+		reverter.Add(func() {
+			d.name = newName
+			_ = pool.RenameInstanceSnapshot(d, oldSnapName, nil)
+			d.name = oldName
+		})
 	} else {
 		err = pool.RenameInstance(d, newName, nil)
 		if err != nil {
 			return fmt.Errorf("Rename instance: %w", err)
 		}
 
+		reverter.Add(func() {
+			d.name = newName
+			_ = pool.RenameInstance(d, oldName, nil)
+			d.name = oldName
+		})
+
 		if applyTemplateTrigger {
 			err = d.DeferTemplateApply(instance.TemplateTriggerRename)
 			if err != nil {
@@ -5563,77 +6237,51 @@ func (d *qemu) Rename(newName string, applyTemplateTrigger bool) error {
 		}
 	}
 
-	if !d.IsSnapshot() {
-		var results []string
-
-		err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-			var err error
+	// Rename the instance and snapshot database entries.
+	renameDB := func(oldName string, newName string) error {
+		return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			if d.IsSnapshot() {
+				oldParts := strings.SplitN(oldName, internalInstance.SnapshotDelimiter, 2)
+				newParts := strings.SplitN(newName, internalInstance.SnapshotDelimiter, 2)
+				return dbCluster.RenameInstanceSnapshot(ctx, tx.Tx(), d.project.Name, oldParts[0], oldParts[1], newParts[1])
+			}
 
-			// Rename all the instance snapshot database entries.
-			results, err = tx.GetInstanceSnapshotsNames(ctx, d.project.Name, oldName)
+			// Snapshots are renamed first as they're looked up through their parent.
+			results, err := tx.GetInstanceSnapshotsNames(ctx, d.project.Name, oldName)
 			if err != nil {
-				d.logger.Error("Failed to get instance snapshots", ctxMap)
-				return fmt.Errorf("Failed to get instance snapshots: Failed getting instance snapshot names: %w", err)
+				return fmt.Errorf("Failed getting instance snapshot names: %w", err)
 			}
 
 			for _, sname := range results {
-				// Rename the snapshot.
 				oldSnapName := strings.SplitN(sname, internalInstance.SnapshotDelimiter, 2)[1]
 				baseSnapName := filepath.Base(sname)
 
 				err := dbCluster.RenameInstanceSnapshot(ctx, tx.Tx(), d.project.Name, oldName, oldSnapName, baseSnapName)
 				if err != nil {
-					d.logger.Error("Failed renaming snapshot", ctxMap)
-					return err
+					return fmt.Errorf("Failed renaming snapshot %q: %w", oldSnapName, err)
 				}
 			}
 
-			return nil
+			return dbCluster.RenameInstance(ctx, tx.Tx(), d.project.Name, oldName, newName)
 		})
-		if err != nil {
-			return err
-		}
 	}
 
-	// Rename the instance database entry.
-	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		if d.IsSnapshot() {
-			oldParts := strings.SplitN(oldName, internalInstance.SnapshotDelimiter, 2)
-			newParts := strings.SplitN(newName, internalInstance.SnapshotDelimiter, 2)
-			return dbCluster.RenameInstanceSnapshot(ctx, tx.Tx(), d.project.Name, oldParts[0], oldParts[1], newParts[1])
-		}
-
-		return dbCluster.RenameInstance(ctx, tx.Tx(), d.project.Name, oldName, newName)
-	})
+	err = renameDB(oldName, newName)
 	if err != nil {
 		d.logger.Error("Failed renaming instance", ctxMap)
-		return err
+		return fmt.Errorf("Failed renaming instance: %w", err)
 	}
 
-	// Rename the logging path.
-	newFullName := project.Instance(d.Project().Name, d.Name())
-	_ = os.RemoveAll(internalUtil.LogPath(newFullName))
-	if util.PathExists(d.LogPath()) {
-		err := os.Rename(d.LogPath(), internalUtil.LogPath(newFullName))
-		if err != nil {
-			d.logger.Error("Failed renaming instance", ctxMap)
-			return err
-		}
-	}
+	reverter.Add(func() { _ = renameDB(newName, oldName) })
 
-	// Rename the runtime path.
-	newFullName = project.Instance(d.Project().Name, d.Name())
-	_ = os.RemoveAll(internalUtil.RunPath(newFullName))
-	if util.PathExists(d.RunPath()) {
-		err := os.Rename(d.RunPath(), internalUtil.RunPath(newFullName))
-		if err != nil {
-			d.logger.Error("Failed renaming instance", ctxMap)
-			return err
-		}
+	// Rename the logging and runtime paths.
+	cleanup, err := d.renamePaths(oldName, newName)
+	if err != nil {
+		d.logger.Error("Failed renaming instance", ctxMap)
+		return fmt.Errorf("Failed renaming instance: %w", err)
 	}
 
-	reverter := revert.New()
-	defer reverter.Fail()
+	reverter.Add(cleanup)
 
 	// Set the new name in the struct.
 	d.name = newName
@@ -5659,6 +6307,16 @@ func (d *qemu) Rename(newName string, applyTemplateTrigger bool) error {
 		reverter.Add(func() { _ = b.Rename(oldName) })
 	}
 
+	if !d.IsSnapshot() {
+		// Move the static DHCP allocations over to the new name.
+		cleanup, err = d.renameDNSMasqStaticEntries(oldName, newName)
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(cleanup)
+	}
+
 	// Update lease files.
 	err = network.UpdateDNSMasqStatic(d.state, "")
 	if err != nil {
@@ -5918,6 +6576,16 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 	}
 
 	if userRequested {
+		err = d.validateVolatileChanges(args.Config, oldLocalConfig, d.IsRunning())
+		if err != nil {
+			return err
+		}
+
+		err = internalInstance.ValidateProfilePriorities(d.profiles)
+		if err != nil {
+			return fmt.Errorf("Invalid profiles: %w", err)
+		}
+
 		// Do some validation of the config diff (allows mixed instance types for profiles).
 		err = instance.ValidConfig(d.state.OS, d.expandedConfig, true, instancetype.Any)
 		if err != nil {
@@ -5968,7 +6636,10 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 		// Only certain keys can be changed on a running VM.
 		liveUpdateKeys := []string{
 			"cluster.evacuate",
+			"dns.nameservers",
+			"dns.search",
 			"limits.memory",
+			"linux.kernel_modules",
 			"security.agent.metrics",
 			"security.csm",
 			"security.protection.delete",
@@ -5981,6 +6652,7 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 			"cloud-init.",
 			"environment.",
 			"image.",
+			"metrics.",
 			"snapshots.",
 			"user.",
 			"volatile.",
@@ -5993,8 +6665,9 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 				return true
 			}
 
+			// CPU count changes need hotplug support, pinning changes are checked when applied.
 			if key == "limits.cpu" {
-				return d.architectureSupportsCPUHotplug()
+				return true
 			}
 
 			if slices.Contains(liveUpdateKeys, key) {
@@ -6021,12 +6694,10 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 
 			if key == "limits.cpu" {
 				oldValue := oldExpandedConfig["limits.cpu"]
-
+				oldPinned := false
 				if oldValue != "" {
 					_, err := strconv.Atoi(oldValue)
-					if err != nil {
-						return fmt.Errorf("Cannot update key %q when using CPU pinning and the VM is running", key)
-					}
+					oldPinned = err != nil
 				}
 
 				// If the key is being unset, set it to default value.
@@ -6035,14 +6706,22 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 				}
 
 				limit, err := strconv.Atoi(value)
-				if err != nil {
-					return fmt.Errorf("Cannot change CPU pinning when VM is running")
-				}
+				if err == nil && !oldPinned {
+					if !d.architectureSupportsCPUHotplug() {
+						return fmt.Errorf("Key %q cannot be updated when VM is running", key)
+					}
 
-				// Hotplug the CPUs.
-				err = d.setCPUs(nil, limit)
-				if err != nil {
-					return fmt.Errorf("Failed updating cpu limit: %w", err)
+					// Hotplug the CPUs.
+					err = d.setCPUs(nil, limit)
+					if err != nil {
+						return fmt.Errorf("Failed updating cpu limit: %w", err)
+					}
+				} else {
+					// Re-pin the existing vCPUs.
+					err = d.updateCPUPins(value)
+					if err != nil {
+						return fmt.Errorf("Failed updating CPU pinning: %w", err)
+					}
 				}
 			} else if key == "limits.memory" {
 				err = d.updateMemoryLimit(value)
@@ -6062,6 +6741,17 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 				if err != nil {
 					return err
 				}
+			} else if key == "linux.kernel_modules" {
+				err = d.loadKernelModules(value)
+				if err != nil {
+					return err
+				}
+			} else if key == "dns.nameservers" || key == "dns.search" {
+				// Keep the config drive in sync, the agent itself is notified below.
+				err = d.writeDNSConfig()
+				if err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -6087,6 +6777,11 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 		}
 	}
 
+	// Resolve the CPU model again on next start.
+	if slices.Contains(changedConfig, "limits.cpu.model") {
+		delete(d.localConfig, "volatile.cpu.model")
+	}
+
 	// Re-generate the instance-id if needed.
 	if !d.IsSnapshot() && d.needsNewInstanceID(changedConfig, oldExpandedDevices) {
 		err = d.resetInstanceID()
@@ -6152,18 +6847,8 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 	reverter.Success()
 
 	if isRunning {
-		// Send devIncus notifications only for user.* key changes
-		for _, key := range changedConfig {
-			if !strings.HasPrefix(key, "user.") {
-				continue
-			}
-
-			msg := map[string]any{
-				"key":       key,
-				"old_value": oldExpandedConfig[key],
-				"value":     d.expandedConfig[key],
-			}
-
+		// Forward the config changes relevant to the guest to the agent.
+		for _, msg := range qemuAgentConfigEvents(changedConfig, oldExpandedConfig, d.expandedConfig) {
 			err = d.devIncusEventSend("config", msg)
 			if err != nil {
 				return err
@@ -6493,6 +7178,9 @@ func (d *qemu) init() error {
 
 // Delete the instance.
 func (d *qemu) Delete(force bool) error {
+	// Don't restart the instance while it's being deleted.
+	d.restartCancel()
+
 	// Setup a new operation.
 	op, err := operationlock.CreateWaitGet(d.Project().Name, d.Name(), d.op, operationlock.ActionDelete, nil, false, false)
 	if err != nil {
@@ -7650,6 +8338,14 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 		useStateConn = true
 	}
 
+	// Ensure the CPU model is usable on this host before any state gets transferred.
+	if args.Live && d.expandedConfig["limits.cpu.model"] != "" {
+		_, err = d.cpuModel()
+		if err != nil {
+			return fmt.Errorf("Incompatible CPU model for live migration: %w", err)
+		}
+	}
+
 	// Send response to source.
 	d.logger.Debug("Sending migration response to source")
 	err = args.ControlSend(respHeader)
@@ -8130,6 +8826,7 @@ func (d *qemu) Console(protocol string) (*os.File, chan error, error) {
 		_ = d.consoleSwapSocketWithRB()
 	}()
 
+	d.recordLastUsed()
 	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceConsole.Event(d, logger.Ctx{"type": protocol}))
 
 	return file, chDisconnect, nil
@@ -8201,6 +8898,7 @@ func (d *qemu) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, s
 		controlResCh:     controlResCh,
 	}
 
+	d.recordLastUsed()
 	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceExec.Event(d, logger.Ctx{"command": req.Command}))
 
 	reverter.Success()
@@ -8273,7 +8971,7 @@ func (d *qemu) Render() (any, any, error) {
 		ExpandedConfig:  d.expandedConfig,
 		ExpandedDevices: d.expandedDevices.CloneNative(),
 		Name:            d.name,
-		Status:          statusCode.String(),
+		Status:          d.status(statusCode),
 		StatusCode:      statusCode,
 		Location:        d.node,
 		Type:            d.Type().String(),
@@ -8286,6 +8984,7 @@ func (d *qemu) Render() (any, any, error) {
 	instState.Devices = d.localDevices.CloneNative()
 	instState.Ephemeral = d.ephemeral
 	instState.LastUsedAt = d.lastUsedDate
+	instState.LastStartedAt = d.lastStartedDate
 	instState.Profiles = profileNames
 	instState.Stateful = d.stateful
 	instState.Project = d.project.Name
@@ -8442,13 +9141,20 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 		}
 	}
 
-	status.Status = statusCode.String()
+	status.Status = d.status(statusCode)
 	status.StatusCode = statusCode
 	status.Disk, err = d.diskState()
 	if err != nil && !errors.Is(err, storageDrivers.ErrNotSupported) {
 		d.logger.Warn("Error getting disk usage", logger.Ctx{"err": err})
 	}
 
+	status.ConsoleLogSize, err = instance.ConsoleLogSize(d.ConsoleBufferLogPath())
+	if err != nil {
+		d.logger.Warn("Error getting console log size", logger.Ctx{"err": err})
+	}
+
+	status.RestartCount = d.restartCount()
+
 	return status, nil
 }
 
@@ -8499,14 +9205,143 @@ func (d *qemu) agentGetState() (*api.InstanceState, error) {
 
 	defer agent.Disconnect()
 
+	start := time.Now()
 	status, _, err := agent.GetInstanceState("")
 	if err != nil {
 		return nil, err
 	}
 
+	if status.Clock != nil && !status.Clock.Time.IsZero() {
+		status.Clock.Offset = qemuClockOffset(status.Clock.Time, start, time.Now()).Nanoseconds()
+	}
+
 	return status, nil
 }
 
+// qemuClockOffset returns the offset of the VM clock from the host clock, given a time reported by the VM
+// during a request to the agent which started and ended at the provided host times.
+func qemuClockOffset(vmTime time.Time, start time.Time, end time.Time) time.Duration {
+	// Assume the VM time was taken halfway through the request.
+	return vmTime.Sub(start.Add(end.Sub(start) / 2))
+}
+
+// SyncClock sets the VM clock to the host time through the agent.
+func (d *qemu) SyncClock() error {
+	if !d.IsRunning() {
+		return ErrInstanceIsStopped
+	}
+
+	client, err := d.getAgentClient()
+	if err != nil {
+		return err
+	}
+
+	agentArgs := &incus.ConnectionArgs{SkipGetServer: true}
+	agent, err := incus.ConnectIncusHTTP(agentArgs, client)
+	if err != nil {
+		return fmt.Errorf("Failed connecting to agent: %w", err)
+	}
+
+	defer agent.Disconnect()
+
+	_, _, err = agent.RawQuery("PUT", "/1.0/clock", agentAPI.ClockPut{Time: time.Now()}, "")
+	if err != nil {
+		return fmt.Errorf("Failed setting the VM clock: %w", err)
+	}
+
+	return nil
+}
+
+// replugRestoredNICs re-plugs the NICs whose MAC address restored from a saved state differs from their
+// configured one. This happens when the state was copied from another instance, in which case the guest would
+// otherwise keep using the MAC addresses of the source instance.
+func (d *qemu) replugRestoredNICs(monitor *qmp.Monitor) error {
+	for _, entry := range d.expandedDevices.Sorted() {
+		if entry.Config["type"] != "nic" {
+			continue
+		}
+
+		hwaddr := d.localConfig[fmt.Sprintf("volatile.%s.hwaddr", entry.Name)]
+		if entry.Config["hwaddr"] != "" {
+			hwaddr = entry.Config["hwaddr"]
+		}
+
+		if hwaddr == "" {
+			continue
+		}
+
+		// NICs which aren't emulated by QEMU (like passed through physical functions) don't report a MAC.
+		deviceID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, linux.PathNameEncode(entry.Name))
+		mac, err := monitor.GetNICMACAddress(deviceID)
+		if err != nil || strings.EqualFold(mac, hwaddr) {
+			continue
+		}
+
+		d.logger.Info("Re-plugging NIC with restored MAC address", logger.Ctx{"device": entry.Name, "restored": mac, "hwaddr": hwaddr})
+
+		dev, err := d.deviceLoad(d, entry.Name, entry.Config)
+		if err != nil {
+			return fmt.Errorf("Failed loading NIC %q: %w", entry.Name, err)
+		}
+
+		err = d.deviceStop(dev, true, "")
+		if err != nil {
+			return fmt.Errorf("Failed detaching NIC %q: %w", entry.Name, err)
+		}
+
+		_, err = d.deviceStart(dev, true)
+		if err != nil {
+			return fmt.Errorf("Failed attaching NIC %q: %w", entry.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// agentAfterResume updates the agent once it's reachable after the VM got resumed (unpaused, restored or
+// migrated). When agent.sync_clock is enabled, the VM clock is set to the host time as it doesn't account for
+// the time the VM wasn't running. For restored VMs, the host vsock address is also advertised again as the
+// state may come from another instance.
+func (d *qemu) agentAfterResume(restored bool) {
+	syncClock := util.IsTrue(d.expandedConfig["agent.sync_clock"])
+	if !syncClock && !restored {
+		return
+	}
+
+	for range 30 {
+		var err error
+		if syncClock {
+			err = d.SyncClock()
+		} else {
+			err = d.advertiseVsockAddress()
+		}
+
+		if err == nil {
+			if !syncClock {
+				return
+			}
+
+			d.logger.Debug("Synchronized VM clock after resume")
+
+			if restored {
+				err = d.advertiseVsockAddress()
+				if err != nil {
+					d.logger.Debug("Failed advertising vsock address after resume", logger.Ctx{"err": err})
+				}
+			}
+
+			return
+		}
+
+		if !errors.Is(err, errQemuAgentOffline) {
+			d.logger.Debug("Failed updating the agent after resume", logger.Ctx{"err": err})
+			return
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
 // IsRunning returns whether or not the instance is running.
 func (d *qemu) IsRunning() bool {
 	return d.isRunningStatusCode(d.statusCode())
@@ -8583,6 +9418,15 @@ func (d *qemu) DeviceEventHandler(runConf *deviceConfig.RunConfig) error {
 
 	// Handle disk reconfiguration.
 	for _, mount := range runConf.Mounts {
+		if mount.NewMedium {
+			err := d.deviceChangeMedia(mount)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		if mount.Limits == nil && mount.Size == 0 {
 			continue
 		}
@@ -8744,6 +9588,16 @@ func (d *qemu) InitPID() int {
 	return pid
 }
 
+// status returns the status name for the given status code. Hibernated instances keep the stopped status code
+// but are reported with their own status name.
+func (d *qemu) status(statusCode api.StatusCode) string {
+	if statusCode == api.Stopped && d.stateful && util.IsTrue(d.localConfig["volatile.last_state.hibernated"]) {
+		return api.InstanceStatusHibernated
+	}
+
+	return statusCode.String()
+}
+
 func (d *qemu) statusCode() api.StatusCode {
 	// Shortcut to avoid spamming QMP during ongoing operations.
 	op := operationlock.Get(d.Project().Name, d.Name())
@@ -8842,16 +9696,10 @@ func (d *qemu) FillNetworkDevice(name string, m deviceConfig.Device) (deviceConf
 		configKey := fmt.Sprintf("volatile.%s.hwaddr", name)
 		volatileHwaddr := d.localConfig[configKey]
 		if volatileHwaddr == "" {
-			// Generate a new MAC address.
-			volatileHwaddr, err = instance.DeviceNextInterfaceHWAddr()
-			if err != nil || volatileHwaddr == "" {
-				return nil, fmt.Errorf("Failed generating %q: %w", configKey, err)
-			}
-
-			// Update the database and update volatileHwaddr with stored value.
-			volatileHwaddr, err = d.insertConfigkey(configKey, volatileHwaddr)
+			// Generate a new MAC address and update volatileHwaddr with stored value.
+			volatileHwaddr, err = d.allocateInterfaceHWAddr(configKey)
 			if err != nil {
-				return nil, fmt.Errorf("Failed storing generated config key %q: %w", configKey, err)
+				return nil, fmt.Errorf("Failed generating %q: %w", configKey, err)
 			}
 
 			// Set stored value into current instance config.
@@ -9345,6 +10193,19 @@ func (d *qemu) checkFeatures(hostArch int, qemuPath string) (map[string]any, err
 		}
 
 		features["flags"] = cpuFlags
+
+		// Get the CPU models usable with KVM on this host.
+		definitions, err := monitor.QueryCPUDefinitions()
+		if err != nil {
+			logger.Debug("Failed querying CPU definitions during VM feature check", logger.Ctx{"err": err})
+		} else {
+			cpuModels := make(map[string]qmp.CPUDefinition, len(definitions))
+			for _, definition := range definitions {
+				cpuModels[definition.Name] = definition
+			}
+
+			features["cpu_models"] = cpuModels
+		}
 	}
 
 	return features, nil
@@ -9398,11 +10259,31 @@ func (d *qemu) getAgentMetrics() (*metrics.MetricSet, error) {
 
 	defer agent.Disconnect()
 
-	resp, _, err := agent.RawQuery("GET", "/1.0/metrics", nil, "")
+	// Have the agent skip the devices excluded from metrics.
+	mountpoints, hwaddrs := d.agentMetricsExcluded()
+
+	query := url.Values{}
+	for _, mountpoint := range mountpoints {
+		query.Add("exclude-mountpoint", mountpoint)
+	}
+
+	for _, hwaddr := range hwaddrs {
+		query.Add("exclude-hwaddr", hwaddr)
+	}
+
+	metricsURL := "/1.0/metrics"
+	if len(query) > 0 {
+		metricsURL += "?" + query.Encode()
+	}
+
+	start := time.Now()
+	resp, _, err := agent.RawQuery("GET", metricsURL, nil, "")
 	if err != nil {
 		return nil, err
 	}
 
+	end := time.Now()
+
 	var m metrics.Metrics
 
 	err = json.Unmarshal(resp.Metadata, &m)
@@ -9415,13 +10296,50 @@ func (d *qemu) getAgentMetrics() (*metrics.MetricSet, error) {
 		return nil, err
 	}
 
+	// Older agents don't report their time.
+	if !m.Time.IsZero() {
+		metricSet.AddSamples(metrics.ClockOffsetSeconds, metrics.Sample{Value: qemuClockOffset(m.Time, start, end).Seconds()})
+	}
+
 	return metricSet, nil
 }
 
-func (d *qemu) getNetworkState() (map[string]api.InstanceStateNetwork, error) {
+// agentMetricsExcluded returns the guest mount points and MAC addresses of the devices excluded from metrics.
+func (d *qemu) agentMetricsExcluded() ([]string, []string) {
+	var mountpoints []string
+	var hwaddrs []string
+
+	for _, devName := range instance.MetricsExcludedDevices(d.expandedConfig) {
+		dev, ok := d.expandedDevices[devName]
+		if !ok {
+			continue
+		}
+
+		switch dev["type"] {
+		case "disk":
+			if dev["path"] != "" {
+				mountpoints = append(mountpoints, dev["path"])
+			}
+
+		case "nic":
+			hwaddr := dev["hwaddr"]
+			if hwaddr == "" {
+				hwaddr = d.localConfig[fmt.Sprintf("volatile.%s.hwaddr", devName)]
+			}
+
+			if hwaddr != "" {
+				hwaddrs = append(hwaddrs, hwaddr)
+			}
+		}
+	}
+
+	return mountpoints, hwaddrs
+}
+
+func (d *qemu) getNetworkState(excludedDevices ...string) (map[string]api.InstanceStateNetwork, error) {
 	networks := map[string]api.InstanceStateNetwork{}
 	for k, m := range d.ExpandedDevices() {
-		if m["type"] != "nic" {
+		if m["type"] != "nic" || slices.Contains(excludedDevices, k) {
 			continue
 		}
 
@@ -9654,6 +10572,78 @@ func (d *qemu) architectureSupportsCPUHotplug() bool {
 	return found
 }
 
+// updateCPUPins applies a new limits.cpu value to the vCPU threads of the running VM without changing their
+// number, as pinned VMs don't support CPU hotplug. With a CPU set, each vCPU (hotplugged ones included) gets pinned
+// to its host CPU, with a CPU count, the vCPUs float across the host CPUs again.
+func (d *qemu) updateCPUPins(limit string) error {
+	// This also validates the CPU set against the host CPUs.
+	cpuInfo, err := d.cpuTopology(limit)
+	if err != nil {
+		return err
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler(), d.QMPLogFilePath())
+	if err != nil {
+		return err
+	}
+
+	pids, err := monitor.GetCPUs()
+	if err != nil {
+		return err
+	}
+
+	if cpuInfo.vcpus == nil {
+		if cpuInfo.cores != len(pids) {
+			return fmt.Errorf("Cannot change the number of vCPUs (%d) when removing CPU pinning while the VM is running", len(pids))
+		}
+
+		// Let the vCPUs use any of the host CPUs which aren't isolated.
+		cpus, err := resources.GetCPU()
+		if err != nil {
+			return err
+		}
+
+		isolatedCPUs := resources.GetCPUIsolated()
+
+		set := unix.CPUSet{}
+		for _, socket := range cpus.Sockets {
+			for _, core := range socket.Cores {
+				for _, thread := range core.Threads {
+					if !slices.Contains(isolatedCPUs, thread.ID) {
+						set.Set(int(thread.ID))
+					}
+				}
+			}
+		}
+
+		for _, pid := range pids {
+			err := unix.SchedSetaffinity(pid, &set)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Re-apply the NUMA restrictions like for hotplugged vCPUs.
+		return d.postCPUHotplug(monitor)
+	}
+
+	if len(cpuInfo.vcpus) != len(pids) {
+		return fmt.Errorf("Cannot change the number of vCPUs (%d) when changing CPU pinning while the VM is running", len(pids))
+	}
+
+	for i, pid := range pids {
+		set := unix.CPUSet{}
+		set.Set(int(cpuInfo.vcpus[uint64(i)]))
+
+		err := unix.SchedSetaffinity(pid, &set)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (d *qemu) postCPUHotplug(monitor *qmp.Monitor) error {
 	// Get the vCPU PID list.
 	pids, err := monitor.GetCPUs()
@@ -9725,7 +10715,8 @@ func (d *qemu) postCPUHotplug(monitor *qmp.Monitor) error {
 	return nil
 }
 
-// ConsoleLog returns all output sent to the instance's console's ring buffer since startup.
+// ConsoleLog returns all output sent to the instance's console's ring buffer since startup, including the
+// rotated console log files.
 func (d *qemu) ConsoleLog() (string, error) {
 	// Setup a new operation.
 	op, err := operationlock.CreateWaitGet(d.Project().Name, d.Name(), d.op, operationlock.ActionConsoleRetrieve, []operationlock.Action{operationlock.ActionRestart, operationlock.ActionRestore, operationlock.ActionMigrate}, false, true)
@@ -9738,51 +10729,96 @@ func (d *qemu) ConsoleLog() (string, error) {
 		defer op.Done(nil)
 	}
 
+	err = d.consoleLogFlush()
+	if err != nil {
+		return "", err
+	}
+
+	// Read and return the complete log for this instance, including its rotated files.
+	// If there's no log file yet, such as right at VM creation, this returns an empty string.
+	fullLog, err := instance.ConsoleLogRead(d.ConsoleBufferLogPath())
+	if err != nil {
+		return "", err
+	}
+
+	return string(fullLog), nil
+}
+
+// consoleLogFlush appends the output sent to the instance's console's ring buffer to the console log file.
+func (d *qemu) consoleLogFlush() error {
 	// Check if the agent is running.
 	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler(), d.QMPLogFilePath())
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	logString, err := monitor.RingbufRead("console")
 	if err != nil {
 		// If a VM was started by an older version of Incus which was then upgraded, its
 		// console device won't be a ring buffer. We don't want to cause an error in this
-		// case, so just return an empty string.
+		// case, so just skip it.
 		if errors.Is(err, qmp.ErrNotARingbuf) {
-			return "", nil
+			return nil
 		}
 
-		return "", err
+		return err
 	}
 
-	// If we got data back, append it to the log file for this instance.
-	if logString != "" {
-		logFile, err := os.OpenFile(d.ConsoleBufferLogPath(), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
-		if err != nil {
-			return "", err
-		}
+	if logString == "" {
+		return nil
+	}
 
-		defer logFile.Close()
+	limits, err := instance.ConsoleLogLimitsFromConfig(d.expandedConfig)
+	if err != nil {
+		return err
+	}
 
-		_, err = logFile.WriteString(logString)
-		if err != nil {
-			return "", err
-		}
+	// Rotate the log file first if the new data would take it over its size limit.
+	err = instance.ConsoleLogRotate(d.ConsoleBufferLogPath(), limits, int64(len(logString)))
+	if err != nil {
+		return fmt.Errorf("Failed rotating console log: %w", err)
 	}
 
-	// Read and return the complete log for this instance.
-	fullLog, err := os.ReadFile(d.ConsoleBufferLogPath())
+	logFile, err := os.OpenFile(d.ConsoleBufferLogPath(), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
 	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			// If there's no log file yet, such as right at VM creation, return an empty string.
-			return "", nil
-		}
+		return err
+	}
 
-		return "", err
+	defer logFile.Close()
+
+	_, err = logFile.WriteString(logString)
+	if err != nil {
+		return err
 	}
 
-	return string(fullLog), nil
+	return nil
+}
+
+// consoleForwardFlush brings the console log file up to date for forwarding.
+// Nothing is done while an operation is ongoing on the instance, to not get in its way.
+func (d *qemu) consoleForwardFlush() error {
+	if operationlock.Get(d.project.Name, d.name) != nil {
+		return nil
+	}
+
+	return d.consoleLogFlush()
+}
+
+// consoleForwardResume starts forwarding the console output of the instance if configured.
+// The console is checked for new output whenever the QMP monitor checks on the VM.
+func (d *qemu) consoleForwardResume() {
+	f := d.consoleForwardStart(d.consoleForwardFlush, "", false)
+	if f == nil {
+		return
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler(), d.QMPLogFilePath())
+	if err != nil {
+		d.logger.Warn("Failed connecting to monitor to forward console output", logger.Ctx{"err": err})
+		return
+	}
+
+	monitor.SetCheckHandler(f.notify)
 }
 
 // consoleSwapRBWithSocket swaps the qemu backend for the instance's console to a unix socket.
@@ -9853,6 +10889,10 @@ func (d *qemu) ConsoleScreenshot(screenshotFile *os.File) error {
 	// Take the screenshot.
 	err = monitor.Screendump(screenshotFile.Name())
 	if err != nil {
+		if errors.Is(err, qmp.ErrNoGraphicalConsole) {
+			return api.StatusErrorf(http.StatusBadRequest, "Instance doesn't have a VGA console")
+		}
+
 		return fmt.Errorf("Failed taking screenshot: %w", err)
 	}
 
@@ -252,10 +252,13 @@ func TestQemuConfigTemplates(t *testing.T) {
 
 	t.Run("qemu_rng", func(t *testing.T) {
 		testCases := []struct {
-			opts     qemuDevOpts
+			opts     qemuRNGOpts
 			expected string
 		}{{
-			qemuDevOpts{"pci", "qemu_pcie0", "00.1", false},
+			qemuRNGOpts{
+				dev:    qemuDevOpts{"pci", "qemu_pcie0", "00.1", false},
+				source: "/dev/urandom",
+			},
 			`# Random number generator
 			[object "qemu_rng"]
 			filename = "/dev/urandom"
@@ -268,7 +271,10 @@ func TestQemuConfigTemplates(t *testing.T) {
 			rng = "qemu_rng"
 			`,
 		}, {
-			qemuDevOpts{"ccw", "qemu_pcie0", "00.1", true},
+			qemuRNGOpts{
+				dev:    qemuDevOpts{"ccw", "qemu_pcie0", "00.1", true},
+				source: "/dev/urandom",
+			},
 			`# Random number generator
 			[object "qemu_rng"]
 			filename = "/dev/urandom"
@@ -279,6 +285,61 @@ func TestQemuConfigTemplates(t *testing.T) {
 			multifunction = "on"
 			rng = "qemu_rng"
 			`,
+		}, {
+			qemuRNGOpts{
+				dev:      qemuDevOpts{"pci", "qemu_pcie0", "00.1", false},
+				source:   "/dev/hwrng",
+				maxBytes: 1024,
+			},
+			`# Random number generator
+			[object "qemu_rng"]
+			filename = "/dev/hwrng"
+			qom-type = "rng-random"
+
+			[device "dev-qemu_rng"]
+			addr = "00.1"
+			bus = "qemu_pcie0"
+			driver = "virtio-rng-pci"
+			max-bytes = "1024"
+			rng = "qemu_rng"
+			`,
+		}, {
+			qemuRNGOpts{
+				dev:      qemuDevOpts{"pci", "qemu_pcie0", "00.1", false},
+				source:   "/dev/hwrng",
+				maxBytes: 1024,
+				period:   500,
+			},
+			`# Random number generator
+			[object "qemu_rng"]
+			filename = "/dev/hwrng"
+			qom-type = "rng-random"
+
+			[device "dev-qemu_rng"]
+			addr = "00.1"
+			bus = "qemu_pcie0"
+			driver = "virtio-rng-pci"
+			max-bytes = "1024"
+			period = "500"
+			rng = "qemu_rng"
+			`,
+		}, {
+			qemuRNGOpts{
+				dev:    qemuDevOpts{"pci", "qemu_pcie0", "00.1", false},
+				source: "/dev/hwrng",
+				period: 500,
+			},
+			`# Random number generator
+			[object "qemu_rng"]
+			filename = "/dev/hwrng"
+			qom-type = "rng-random"
+
+			[device "dev-qemu_rng"]
+			addr = "00.1"
+			bus = "qemu_pcie0"
+			driver = "virtio-rng-pci"
+			rng = "qemu_rng"
+			`,
 		}}
 		for _, tc := range testCases {
 			runTest(tc.expected, qemuRNG(&tc.opts))
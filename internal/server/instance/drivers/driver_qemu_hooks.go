@@ -0,0 +1,119 @@
+package drivers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/lxc/incus/v6/internal/server/instance/drivers/qmp"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/subprocess"
+)
+
+// qemuHookTimeout is the maximum time a raw.qemu.hook.* script is allowed to run for.
+const qemuHookTimeout = 30 * time.Second
+
+// qemuRunHook runs the hook script with the given environment and returns its standard output.
+// The script must be an executable regular file and is killed if still running after the timeout.
+func qemuRunHook(path string, env []string, timeout time.Duration) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed accessing hook script: %w", err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("Hook script %q isn't a regular file", path)
+	}
+
+	if info.Mode().Perm()&0o111 == 0 {
+		return "", fmt.Errorf("Hook script %q isn't executable", path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stdout, _, err := subprocess.RunCommandSplit(ctx, append(os.Environ(), env...), nil, path)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("Hook script %q timed out after %s", path, timeout)
+		}
+
+		return "", err
+	}
+
+	return stdout, nil
+}
+
+// runHook runs the raw.qemu.hook.<stage> script if set, providing it the VM's context through its environment,
+// and returns its output.
+func (d *qemu) runHook(stage string) (string, error) {
+	path := d.expandedConfig["raw.qemu.hook."+stage]
+	if path == "" {
+		return "", nil
+	}
+
+	env := []string{
+		"INCUS_HOOK_STAGE=" + stage,
+		"INCUS_INSTANCE_NAME=" + d.Name(),
+		"INCUS_INSTANCE_PROJECT=" + d.Project().Name,
+		"INCUS_INSTANCE_PATH=" + d.Path(),
+		"INCUS_INSTANCE_LOG_PATH=" + d.LogPath(),
+	}
+
+	// The QEMU process only exists past the pre-start stage.
+	if stage != "pre-start" {
+		pid, err := d.pid()
+		if err == nil && pid > 0 {
+			env = append(env, fmt.Sprintf("INCUS_INSTANCE_PID=%d", pid))
+		}
+	}
+
+	out, err := qemuRunHook(path, env, qemuHookTimeout)
+	if err != nil {
+		return "", fmt.Errorf("Failed running QEMU %s hook: %w", stage, err)
+	}
+
+	return out, nil
+}
+
+// hookQEMUArgs runs the pre-start hook and returns the additional QEMU arguments it generated.
+func (d *qemu) hookQEMUArgs() ([]string, error) {
+	out, err := d.runHook("pre-start")
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := shellquote.Split(out)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing QEMU pre-start hook output: %w", err)
+	}
+
+	return fields, nil
+}
+
+// hookQMP runs the post-start or pre-stop hook and then the QMP commands it output (as a JSON list of objects).
+func (d *qemu) hookQMP(monitor *qmp.Monitor, stage string) error {
+	out, err := d.runHook(stage)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(out) == "" {
+		return nil
+	}
+
+	return runQMPCommands(monitor, stage+" hook", out)
+}
+
+// hookPreStop runs the pre-stop hook. Failures are only logged so they can't prevent the VM from stopping.
+func (d *qemu) hookPreStop(monitor *qmp.Monitor) {
+	err := d.hookQMP(monitor, "pre-stop")
+	if err != nil {
+		d.logger.Warn("Failed running QEMU pre-stop hook", logger.Ctx{"err": err})
+	}
+}
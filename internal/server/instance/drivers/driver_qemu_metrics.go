@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/lxc/incus/v6/internal/server/instance"
 	"github.com/lxc/incus/v6/internal/server/instance/drivers/qemudefault"
 	"github.com/lxc/incus/v6/internal/server/instance/drivers/qmp"
 	"github.com/lxc/incus/v6/internal/server/instance/instancetype"
@@ -47,7 +48,7 @@ func (d *qemu) getQemuMetrics() (*metrics.MetricSet, error) {
 		out.Disk = diskStats
 	}
 
-	networkState, err := d.getNetworkState()
+	networkState, err := d.getNetworkState(instance.MetricsExcludedDevices(d.expandedConfig)...)
 	if err != nil {
 		d.logger.Warn("Failed to get network metrics", logger.Ctx{"err": err})
 	} else {
@@ -335,20 +335,35 @@ func qemuBalloon(opts *qemuDevOpts) []cfg.Section {
 	}}
 }
 
-func qemuRNG(opts *qemuDevOpts) []cfg.Section {
+type qemuRNGOpts struct {
+	dev      qemuDevOpts
+	source   string
+	maxBytes int64
+	period   uint32
+}
+
+func qemuRNG(opts *qemuRNGOpts) []cfg.Section {
 	entries := qemuDeviceEntries(&qemuDevEntriesOpts{
-		dev:     *opts,
+		dev:     opts.dev,
 		pciName: "virtio-rng-pci",
 		ccwName: "virtio-rng-ccw",
 	})
 	entries["rng"] = "qemu_rng"
 
+	if opts.maxBytes > 0 {
+		entries["max-bytes"] = fmt.Sprintf("%d", opts.maxBytes)
+
+		if opts.period > 0 {
+			entries["period"] = fmt.Sprintf("%d", opts.period)
+		}
+	}
+
 	return []cfg.Section{{
 		Name:    `object "qemu_rng"`,
 		Comment: "Random number generator",
 		Entries: map[string]string{
 			"qom-type": "rng-random",
-			"filename": "/dev/urandom",
+			"filename": opts.source,
 		},
 	}, {
 		Name:    `device "dev-qemu_rng"`,
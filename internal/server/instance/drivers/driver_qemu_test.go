@@ -0,0 +1,291 @@
+package drivers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/internal/server/device"
+	"github.com/lxc/incus/v6/internal/server/instance/drivers/qmp"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Test qemuBlockDev.
+func TestQemuBlockDev(t *testing.T) {
+	tests := []struct {
+		name     string
+		aioMode  string
+		direct   bool
+		noFlush  bool
+		opts     []string
+		blockDev map[string]any
+	}{
+		{
+			name:    "Default",
+			aioMode: "native",
+			direct:  true,
+			blockDev: map[string]any{
+				"aio":       "native",
+				"cache":     map[string]any{"direct": true, "no-flush": false},
+				"discard":   "unmap",
+				"driver":    "file",
+				"node-name": "incus_disk1",
+				"read-only": false,
+			},
+		},
+		{
+			name:    "Discard",
+			aioMode: "threads",
+			noFlush: true,
+			opts:    []string{"bus=virtio-blk", device.DiskDiscard},
+			blockDev: map[string]any{
+				"aio":           "threads",
+				"cache":         map[string]any{"direct": false, "no-flush": true},
+				"detect-zeroes": "unmap",
+				"discard":       "unmap",
+				"driver":        "file",
+				"node-name":     "incus_disk1",
+				"read-only":     false,
+			},
+		},
+		{
+			name:    "Discard on a read-only disk",
+			aioMode: "native",
+			direct:  true,
+			opts:    []string{device.DiskDiscard, "ro"},
+			blockDev: map[string]any{
+				"aio":       "native",
+				"cache":     map[string]any{"direct": true, "no-flush": false},
+				"discard":   "unmap",
+				"driver":    "file",
+				"node-name": "incus_disk1",
+				"read-only": false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockDev := qemuBlockDev("incus_disk1", tt.aioMode, tt.direct, tt.noFlush, tt.opts)
+			assert.Equal(t, tt.blockDev, blockDev)
+		})
+	}
+}
+
+// Test qemuDiskCacheMode.
+func TestQemuDiskCacheMode(t *testing.T) {
+	for _, mode := range []string{"none", "writeback", "writethrough", "directsync", "unsafe"} {
+		cacheMode, fallback := qemuDiskCacheMode(mode, true)
+		assert.Equal(t, mode, cacheMode)
+		assert.False(t, fallback)
+	}
+
+	// Sources without direct I/O support (e.g. images on zfs or btrfs) use the host cache.
+	tests := map[string]string{
+		"none":         "writeback",
+		"directsync":   "writethrough",
+		"writeback":    "writeback",
+		"writethrough": "writethrough",
+		"unsafe":       "unsafe",
+	}
+
+	for mode, expected := range tests {
+		cacheMode, fallback := qemuDiskCacheMode(mode, false)
+		assert.Equal(t, expected, cacheMode, mode)
+		assert.Equal(t, mode != expected, fallback, mode)
+	}
+}
+
+// Test qemuDiskCacheOptions.
+func TestQemuDiskCacheOptions(t *testing.T) {
+	tests := []struct {
+		cacheMode    string
+		aioMode      string
+		directCache  bool
+		noFlushCache bool
+		writeCache   bool
+	}{
+		{cacheMode: "none", aioMode: "native", directCache: true, writeCache: true},
+		{cacheMode: "directsync", aioMode: "native", directCache: true},
+		{cacheMode: "writeback", aioMode: "threads", writeCache: true},
+		{cacheMode: "writethrough", aioMode: "threads"},
+		{cacheMode: "unsafe", aioMode: "threads", noFlushCache: true, writeCache: true},
+	}
+
+	for _, tt := range tests {
+		aioMode, directCache, noFlushCache, writeCache := qemuDiskCacheOptions(tt.cacheMode, "native")
+		assert.Equal(t, tt.aioMode, aioMode, tt.cacheMode)
+		assert.Equal(t, tt.directCache, directCache, tt.cacheMode)
+		assert.Equal(t, tt.noFlushCache, noFlushCache, tt.cacheMode)
+		assert.Equal(t, tt.writeCache, writeCache, tt.cacheMode)
+	}
+}
+
+// Test qemuClockOffset.
+func TestQemuClockOffset(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Millisecond)
+
+	// The VM time is compared with the middle of the request.
+	assert.Equal(t, time.Duration(0), qemuClockOffset(start.Add(5*time.Millisecond), start, end))
+	assert.Equal(t, 2*time.Second, qemuClockOffset(start.Add(2*time.Second+5*time.Millisecond), start, end))
+	assert.Equal(t, -time.Minute, qemuClockOffset(start.Add(-time.Minute+5*time.Millisecond), start, end))
+}
+
+// Test qemuResolveCPUModel.
+func TestQemuResolveCPUModel(t *testing.T) {
+	cpuModels := map[string]qmp.CPUDefinition{
+		"EPYC":         {Name: "EPYC", AliasOf: "EPYC-v4"},
+		"EPYC-v4":      {Name: "EPYC-v4"},
+		"Skylake":      {Name: "Skylake", UnavailableFeatures: []string{"hle", "rtm"}},
+		"Cascadelake":  {Name: "Cascadelake", AliasOf: "Cascadelake-v9"},
+		"Icelake-v1":   {Name: "Icelake-v1"},
+		"Broadwell-v1": {Name: "Broadwell-v1"},
+	}
+
+	cpuFlags := map[string]bool{"avx2": true, "svm": true, "hle": false, "rtm": false, "pcid": true, "tsc-deadline": true}
+
+	// Aliases are resolved to the versioned model.
+	model, err := qemuResolveCPUModel("EPYC,+avx2,-svm", cpuModels, cpuFlags)
+	assert.NoError(t, err)
+	assert.Equal(t, "EPYC-v4,+avx2,-svm", model)
+
+	model, err = qemuResolveCPUModel("Icelake-v1", cpuModels, cpuFlags)
+	assert.NoError(t, err)
+	assert.Equal(t, "Icelake-v1", model)
+
+	// Models with features missing on the host are only usable with those features disabled.
+	_, err = qemuResolveCPUModel("Skylake", cpuModels, cpuFlags)
+	assert.ErrorContains(t, err, "missing CPU features: hle, rtm")
+
+	model, err = qemuResolveCPUModel("Skylake,-hle,-rtm", cpuModels, cpuFlags)
+	assert.NoError(t, err)
+	assert.Equal(t, "Skylake,-hle,-rtm", model)
+
+	// Unknown models and features are rejected.
+	_, err = qemuResolveCPUModel("Foo", cpuModels, cpuFlags)
+	assert.Error(t, err)
+
+	_, err = qemuResolveCPUModel("Cascadelake", cpuModels, cpuFlags)
+	assert.Error(t, err)
+
+	_, err = qemuResolveCPUModel("host,+foo", cpuModels, cpuFlags)
+	assert.ErrorContains(t, err, "Unknown CPU feature")
+
+	// Feature names may use either dashes or underscores.
+	model, err = qemuResolveCPUModel("host,+tsc_deadline", cpuModels, cpuFlags)
+	assert.NoError(t, err)
+	assert.Equal(t, "host,+tsc_deadline", model)
+
+	// Without any host information, the model is used as-is.
+	model, err = qemuResolveCPUModel("Foo,+bar", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Foo,+bar", model)
+}
+
+// Test qemuRunHook.
+func TestQemuRunHook(t *testing.T) {
+	dir := t.TempDir()
+
+	writeHook := func(name string, content string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		err := os.WriteFile(path, []byte(content), mode)
+		require.NoError(t, err)
+
+		return path
+	}
+
+	// The output is returned and the context is passed through the environment.
+	path := writeHook("args", "#!/bin/sh\necho \"-name $INCUS_INSTANCE_NAME\"\n", 0o700)
+	out, err := qemuRunHook(path, []string{"INCUS_INSTANCE_NAME=v1"}, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "-name v1\n", out)
+
+	// Scripts must be executable.
+	path = writeHook("noexec", "#!/bin/sh\ntrue\n", 0o600)
+	_, err = qemuRunHook(path, nil, time.Second)
+	assert.ErrorContains(t, err, "isn't executable")
+
+	_, err = qemuRunHook(dir, nil, time.Second)
+	assert.ErrorContains(t, err, "isn't a regular file")
+
+	_, err = qemuRunHook(filepath.Join(dir, "missing"), nil, time.Second)
+	assert.Error(t, err)
+
+	// The error output of failing scripts is reported.
+	path = writeHook("fail", "#!/bin/sh\necho \"missing firmware\" >&2\nexit 1\n", 0o700)
+	_, err = qemuRunHook(path, nil, time.Second)
+	assert.ErrorContains(t, err, "missing firmware")
+
+	// Scripts are killed on timeout.
+	path = writeHook("slow", "#!/bin/sh\nexec sleep 10\n", 0o700)
+	_, err = qemuRunHook(path, nil, 100*time.Millisecond)
+	assert.ErrorContains(t, err, "timed out")
+}
+
+func TestQemuCheckHibernateSpace(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "state")
+
+	// Small states fit, huge ones don't.
+	require.NoError(t, qemuCheckHibernateSpace(statePath, 1024))
+	assert.ErrorContains(t, qemuCheckHibernateSpace(statePath, 1<<62), "Not enough free space")
+
+	// An existing state file counts as available space as it gets replaced.
+	var statfs unix.Statfs_t
+	require.NoError(t, unix.Statfs(dir, &statfs))
+	available := int64(statfs.Bavail) * int64(statfs.Bsize)
+
+	f, err := os.Create(statePath)
+	require.NoError(t, err)
+	require.NoError(t, f.Truncate(1<<40))
+	require.NoError(t, f.Close())
+	require.NoError(t, qemuCheckHibernateSpace(statePath, available+(1<<39)))
+
+	// The state directory must exist.
+	assert.ErrorContains(t, qemuCheckHibernateSpace(filepath.Join(dir, "missing", "state"), 1024), "Failed getting free space")
+}
+
+func TestQemuStatus(t *testing.T) {
+	hibernated := map[string]string{"volatile.last_state.hibernated": "true"}
+
+	tests := []struct {
+		name       string
+		stateful   bool
+		config     map[string]string
+		statusCode api.StatusCode
+		want       string
+	}{
+		{"Stopped", false, nil, api.Stopped, "Stopped"},
+		{"Stateful stop", true, nil, api.Stopped, "Stopped"},
+		{"Hibernated", true, hibernated, api.Stopped, api.InstanceStatusHibernated},
+		{"Stateless start discarded the state", false, hibernated, api.Stopped, "Stopped"},
+		{"Running", true, hibernated, api.Running, "Running"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &qemu{common: common{stateful: tt.stateful, localConfig: tt.config}}
+			assert.Equal(t, tt.want, d.status(tt.statusCode))
+		})
+	}
+}
+
+func TestQemuAgentConfigEvents(t *testing.T) {
+	oldConfig := map[string]string{"user.foo": "old", "dns.search": "example.com", "limits.cpu": "1"}
+	newConfig := map[string]string{"user.foo": "new", "dns.nameservers": "192.0.2.1", "limits.cpu": "2"}
+
+	events := qemuAgentConfigEvents([]string{"user.foo", "limits.cpu", "dns.search", "dns.nameservers"}, oldConfig, newConfig)
+	assert.Equal(t, []map[string]any{
+		{"key": "dns.nameservers", "old_value": "", "value": "192.0.2.1"},
+		{"key": "dns.search", "old_value": "example.com", "value": ""},
+		{"key": "user.foo", "old_value": "old", "value": "new"},
+	}, events)
+
+	assert.Empty(t, qemuAgentConfigEvents([]string{"limits.cpu"}, oldConfig, newConfig))
+}
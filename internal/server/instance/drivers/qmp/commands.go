@@ -74,6 +74,14 @@ type CPUModel struct {
 	Flags map[string]any `json:"props"`
 }
 
+// CPUDefinition contains information about a CPU model known to QEMU.
+type CPUDefinition struct {
+	Name                string   `json:"name"`
+	AliasOf             string   `json:"alias-of,omitempty"`
+	Deprecated          bool     `json:"deprecated"`
+	UnavailableFeatures []string `json:"unavailable-features,omitempty"`
+}
+
 // MemoryDevice contains information about a memory device.
 type MemoryDevice struct {
 	Type string          `json:"type"`
@@ -155,6 +163,21 @@ func (m *Monitor) QueryCPUModel(model string) (*CPUModel, error) {
 	return &resp.Return.Model, nil
 }
 
+// QueryCPUDefinitions returns the CPU models supported by QEMU.
+func (m *Monitor) QueryCPUDefinitions() ([]CPUDefinition, error) {
+	// Prepare the response.
+	var resp struct {
+		Return []CPUDefinition `json:"return"`
+	}
+
+	err := m.Run("query-cpu-definitions", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query CPU definitions: %w", err)
+	}
+
+	return resp.Return, nil
+}
+
 // Status returns the current VM status.
 func (m *Monitor) Status() (string, error) {
 	// Prepare the response.
@@ -889,6 +912,31 @@ func (m *Monitor) QueryPCI() ([]PCIDevice, error) {
 	return nil, nil
 }
 
+// GetNICMACAddress returns the MAC address of a NIC as currently seen by the guest.
+// This can differ from the configured one after the state of another VM was restored.
+func (m *Monitor) GetNICMACAddress(deviceID string) (string, error) {
+	// Prepare the response.
+	var resp struct {
+		Return []struct {
+			Name    string `json:"name"`
+			MainMAC string `json:"main-mac"`
+		} `json:"return"`
+	}
+
+	err := m.Run("query-rx-filter", map[string]any{"name": deviceID}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("Failed querying NIC %q: %w", deviceID, err)
+	}
+
+	for _, nic := range resp.Return {
+		if nic.Name == deviceID {
+			return nic.MainMAC, nil
+		}
+	}
+
+	return "", fmt.Errorf("NIC %q not found", deviceID)
+}
+
 // BlockStats represents block device stats.
 type BlockStats struct {
 	BytesWritten    int `json:"wr_bytes"`
@@ -1208,6 +1256,164 @@ func (m *Monitor) Eject(id string) error {
 	return nil
 }
 
+// ejectOpenTray ejects the medium of a removable drive if its tray is open.
+// The tray may have been closed again by a media change since the guest opened it.
+func (m *Monitor) ejectOpenTray(id string) error {
+	m.mediaLock.Lock()
+	defer m.mediaLock.Unlock()
+
+	var resp struct {
+		Return []struct {
+			Qdev     string `json:"qdev"`
+			TrayOpen bool   `json:"tray_open"`
+		} `json:"return"`
+	}
+
+	err := m.Run("query-block", nil, &resp)
+	if err != nil {
+		return err
+	}
+
+	for _, block := range resp.Return {
+		if block.Qdev == id && !block.TrayOpen {
+			return nil
+		}
+	}
+
+	return m.Eject(id)
+}
+
+// MediaAltNodeName returns the alternate name of the block node of a removable drive.
+// The block node of a new medium gets the name not used by the current one, so that the current medium can be
+// put back if the new one can't be inserted.
+func MediaAltNodeName(nodeName string) string {
+	first := nodeName[:1]
+	if strings.ToUpper(first) == first {
+		return strings.ToLower(first) + nodeName[1:]
+	}
+
+	return strings.ToUpper(first) + nodeName[1:]
+}
+
+// mediaNodeName returns the name of the block node currently inserted in a removable drive, if any.
+func (m *Monitor) mediaNodeName(id string) (string, error) {
+	var resp struct {
+		Return []struct {
+			Qdev     string `json:"qdev"`
+			Inserted *struct {
+				NodeName string `json:"node-name"`
+			} `json:"inserted"`
+		} `json:"return"`
+	}
+
+	err := m.Run("query-block", nil, &resp)
+	if err != nil {
+		return "", fmt.Errorf("Failed querying block devices: %w", err)
+	}
+
+	for _, block := range resp.Return {
+		if block.Qdev == id && block.Inserted != nil {
+			return block.Inserted.NodeName, nil
+		}
+	}
+
+	return "", nil
+}
+
+// ChangeMedia replaces the medium of a removable drive.
+// If blockDev is set, it's first added as a block node named after nodeName or MediaAltNodeName(nodeName),
+// whichever isn't used by the current medium, using the file descriptor of file if provided.
+// The current medium is then swapped for the new one, or just removed if blockDev is nil. On failure, the
+// current medium is put back in place. Its block node and file descriptor are only removed once done.
+func (m *Monitor) ChangeMedia(id string, nodeName string, blockDev map[string]any, file *os.File, readonly bool) error {
+	m.mediaLock.Lock()
+	defer m.mediaLock.Unlock()
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	oldNodeName, err := m.mediaNodeName(id)
+	if err != nil {
+		return err
+	}
+
+	if blockDev != nil {
+		newNodeName := nodeName
+		if oldNodeName == nodeName {
+			newNodeName = MediaAltNodeName(nodeName)
+		}
+
+		blockDev["node-name"] = newNodeName
+
+		if file != nil {
+			info, err := m.SendFileWithFDSet(newNodeName, file, readonly)
+			if err != nil {
+				return fmt.Errorf("Failed sending file descriptor of %q: %w", file.Name(), err)
+			}
+
+			reverter.Add(func() { _ = m.RemoveFDFromFDSet(newNodeName) })
+
+			blockDev["filename"] = fmt.Sprintf("/dev/fdset/%d", info.ID)
+		}
+
+		err = m.Run("blockdev-add", blockDev, nil)
+		if err != nil {
+			return fmt.Errorf("Failed adding block device: %w", err)
+		}
+
+		reverter.Add(func() { _ = m.RemoveBlockDevice(newNodeName) })
+	}
+
+	err = m.Run("blockdev-open-tray", map[string]any{"id": id, "force": true}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed opening tray: %w", err)
+	}
+
+	reverter.Add(func() { _ = m.Run("blockdev-close-tray", map[string]any{"id": id}, nil) })
+
+	if oldNodeName != "" {
+		err = m.Run("blockdev-remove-medium", map[string]any{"id": id}, nil)
+		if err != nil {
+			return fmt.Errorf("Failed removing medium: %w", err)
+		}
+
+		reverter.Add(func() {
+			_ = m.Run("blockdev-insert-medium", map[string]any{"id": id, "node-name": oldNodeName}, nil)
+		})
+	}
+
+	if blockDev != nil {
+		err = m.Run("blockdev-insert-medium", map[string]any{"id": id, "node-name": blockDev["node-name"]}, nil)
+		if err != nil {
+			return fmt.Errorf("Failed inserting medium: %w", err)
+		}
+
+		reverter.Add(func() { _ = m.Run("blockdev-remove-medium", map[string]any{"id": id}, nil) })
+	}
+
+	err = m.Run("blockdev-close-tray", map[string]any{"id": id}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed closing tray: %w", err)
+	}
+
+	reverter.Success()
+
+	// Drop the previous medium.
+	if oldNodeName != "" {
+		err = m.RemoveBlockDevice(oldNodeName)
+		if err != nil {
+			return err
+		}
+
+		err = m.RemoveFDFromFDSet(oldNodeName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // UpdateBlockSize updates the size of a disk.
 func (m *Monitor) UpdateBlockSize(id string) error {
 	var args struct {
@@ -1414,7 +1620,17 @@ func (m *Monitor) Screendump(filename string) error {
 		Return struct{} `json:"return"`
 	}
 
-	return m.Run("screendump", args, &queryResp)
+	err := m.Run("screendump", args, &queryResp)
+	if err != nil {
+		// Headless VMs don't have any console to take the screendump from.
+		if strings.Contains(err.Error(), "screendump from") {
+			return ErrNoGraphicalConsole
+		}
+
+		return err
+	}
+
+	return nil
 }
 
 // DumpGuestMemory dumps guest memory to a file.
@@ -0,0 +1,289 @@
+package qmp
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCommand is a command received by the fake QMP server.
+type testCommand struct {
+	Execute   string         `json:"execute"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// testServer is a fake QMP server recording the commands it receives.
+type testServer struct {
+	conn     net.Conn
+	replies  map[string]string
+	commands []testCommand
+	lock     sync.Mutex
+
+	// ringbufRead is closed once the monitor did its initial ringbuffer read.
+	ringbufRead chan struct{}
+}
+
+// newTestMonitor returns a monitor connected to a fake QMP server.
+// Commands are answered with the reply registered for them, or an empty object.
+// Replies starting with "error:" are sent as errors.
+func newTestMonitor(t *testing.T, replies map[string]string) (*Monitor, *testServer) {
+	path := filepath.Join(t.TempDir(), "qmp.monitor")
+
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	server := &testServer{replies: replies, ringbufRead: make(chan struct{})}
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		server.lock.Lock()
+		server.conn = conn
+		server.lock.Unlock()
+
+		server.write(`{"QMP": {"version": {"qemu": {"micro": 0, "minor": 0, "major": 9}, "package": ""}, "capabilities": ["oob"]}}`)
+
+		// The monitor checks it's still alive after errors with a single-quoted command.
+		decoder := json.NewDecoder(quoteReader{conn})
+		for {
+			cmd := testCommand{}
+			err := decoder.Decode(&cmd)
+			if err != nil {
+				return
+			}
+
+			server.lock.Lock()
+			reply, ok := server.replies[cmd.Execute]
+			if !ok {
+				reply = "{}"
+			}
+
+			if cmd.Execute == "ringbuf-read" {
+				reply = `""`
+
+				select {
+				case <-server.ringbufRead:
+				default:
+					close(server.ringbufRead)
+				}
+			} else if cmd.Execute != "qmp_capabilities" && cmd.Execute != "query-version" {
+				server.commands = append(server.commands, cmd)
+			}
+
+			server.lock.Unlock()
+
+			desc, isError := strings.CutPrefix(reply, "error:")
+			if isError {
+				server.write(`{"error": {"class": "GenericError", "desc": "` + desc + `"}}`)
+				continue
+			}
+
+			server.write(`{"return": ` + reply + `}`)
+		}
+	}()
+
+	m, err := Connect(path, "", nil, "")
+	require.NoError(t, err)
+
+	t.Cleanup(m.Disconnect)
+
+	// Don't disconnect while the monitor is still starting.
+	<-server.ringbufRead
+
+	return m, server
+}
+
+// quoteReader replaces single quotes with double quotes in the data read from r.
+type quoteReader struct {
+	r io.Reader
+}
+
+// Read implements io.Reader.
+func (q quoteReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+	for i := range n {
+		if p[i] == '\'' {
+			p[i] = '"'
+		}
+	}
+
+	return n, err
+}
+
+// write sends a message to the client.
+func (s *testServer) write(message string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, _ = s.conn.Write([]byte(message + "\r\n"))
+}
+
+// executed returns the names of the commands received so far.
+func (s *testServer) executed() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	names := []string{}
+	for _, cmd := range s.commands {
+		names = append(names, cmd.Execute)
+	}
+
+	return names
+}
+
+// command returns the last received command with the given name.
+func (s *testServer) command(name string) *testCommand {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i := len(s.commands) - 1; i >= 0; i-- {
+		if s.commands[i].Execute == name {
+			return &s.commands[i]
+		}
+	}
+
+	return nil
+}
+
+func TestChangeMediaEject(t *testing.T) {
+	m, server := newTestMonitor(t, map[string]string{
+		"query-block":  `[{"qdev": "dev-incus_cd", "inserted": {"node-name": "incus_cd"}}]`,
+		"query-fdsets": `[{"fdset-id": 2, "fds": [{"fd": 10, "opaque": "rdonly:incus_cd"}]}]`,
+	})
+
+	require.NoError(t, m.ChangeMedia("dev-incus_cd", "incus_cd", nil, nil, true))
+
+	assert.Equal(t, []string{"query-block", "blockdev-open-tray", "blockdev-remove-medium", "blockdev-close-tray", "blockdev-del", "query-fdsets", "remove-fd"}, server.executed())
+	assert.Equal(t, map[string]any{"id": "dev-incus_cd", "force": true}, server.command("blockdev-open-tray").Arguments)
+	assert.Equal(t, map[string]any{"node-name": "incus_cd"}, server.command("blockdev-del").Arguments)
+	assert.Equal(t, map[string]any{"fdset-id": float64(2)}, server.command("remove-fd").Arguments)
+}
+
+func TestChangeMediaInsert(t *testing.T) {
+	m, server := newTestMonitor(t, map[string]string{"query-block": `[{"qdev": "dev-incus_cd"}]`, "add-fd": `{"fdset-id": 3, "fd": 11}`})
+
+	f, err := os.CreateTemp(t.TempDir(), "new.iso")
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	blockDev := map[string]any{"driver": "file", "node-name": "incus_cd", "read-only": true}
+	require.NoError(t, m.ChangeMedia("dev-incus_cd", "incus_cd", blockDev, f, true))
+
+	assert.Equal(t, []string{"query-block", "add-fd", "blockdev-add", "blockdev-open-tray", "blockdev-insert-medium", "blockdev-close-tray"}, server.executed())
+	assert.Equal(t, "/dev/fdset/3", server.command("blockdev-add").Arguments["filename"])
+	assert.Equal(t, map[string]any{"id": "dev-incus_cd", "node-name": "incus_cd"}, server.command("blockdev-insert-medium").Arguments)
+}
+
+// Changing the source of a disk of a running VM swaps the current medium for a new one.
+func TestChangeMediaReplace(t *testing.T) {
+	replies := map[string]string{
+		"query-block":  `[{"qdev": "dev-incus_cd", "inserted": {"node-name": "incus_cd"}}]`,
+		"add-fd":       `{"fdset-id": 3, "fd": 11}`,
+		"query-fdsets": `[{"fdset-id": 2, "fds": [{"fd": 10, "opaque": "rdonly:incus_cd"}]}, {"fdset-id": 3, "fds": [{"fd": 11, "opaque": "rdonly:Incus_cd"}]}]`,
+	}
+
+	m, server := newTestMonitor(t, replies)
+
+	f, err := os.CreateTemp(t.TempDir(), "new.iso")
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	// The new medium is added under the alternate node name before the current one is removed.
+	blockDev := map[string]any{"driver": "file", "node-name": "incus_cd", "read-only": true}
+	require.NoError(t, m.ChangeMedia("dev-incus_cd", "incus_cd", blockDev, f, true))
+
+	assert.Equal(t, []string{"query-block", "add-fd", "blockdev-add", "blockdev-open-tray", "blockdev-remove-medium", "blockdev-insert-medium", "blockdev-close-tray", "blockdev-del", "query-fdsets", "remove-fd"}, server.executed())
+	assert.Equal(t, "Incus_cd", server.command("blockdev-add").Arguments["node-name"])
+	assert.Equal(t, "rdonly:Incus_cd", server.command("add-fd").Arguments["opaque"])
+	assert.Equal(t, map[string]any{"id": "dev-incus_cd", "node-name": "Incus_cd"}, server.command("blockdev-insert-medium").Arguments)
+	assert.Equal(t, map[string]any{"node-name": "incus_cd"}, server.command("blockdev-del").Arguments)
+	assert.Equal(t, map[string]any{"fdset-id": float64(2)}, server.command("remove-fd").Arguments)
+
+	// The next change goes back to the original node name.
+	server.lock.Lock()
+	replies["query-block"] = `[{"qdev": "dev-incus_cd", "inserted": {"node-name": "Incus_cd"}}]`
+	server.commands = nil
+	server.lock.Unlock()
+
+	blockDev = map[string]any{"driver": "file", "node-name": "incus_cd", "read-only": true}
+	require.NoError(t, m.ChangeMedia("dev-incus_cd", "incus_cd", blockDev, f, true))
+	assert.Equal(t, "incus_cd", server.command("blockdev-add").Arguments["node-name"])
+	assert.Equal(t, map[string]any{"node-name": "Incus_cd"}, server.command("blockdev-del").Arguments)
+	assert.Equal(t, map[string]any{"fdset-id": float64(3)}, server.command("remove-fd").Arguments)
+}
+
+// A failure to insert the new medium puts the current one back in place.
+func TestChangeMediaReplaceFailure(t *testing.T) {
+	m, server := newTestMonitor(t, map[string]string{
+		"query-block":            `[{"qdev": "dev-incus_cd", "inserted": {"node-name": "incus_cd"}}]`,
+		"blockdev-insert-medium": "error:Failed inserting",
+	})
+
+	blockDev := map[string]any{"driver": "file", "node-name": "incus_cd", "filename": "/new.iso", "read-only": true}
+	require.Error(t, m.ChangeMedia("dev-incus_cd", "incus_cd", blockDev, nil, true))
+
+	assert.Equal(t, []string{"query-block", "blockdev-add", "blockdev-open-tray", "blockdev-remove-medium", "blockdev-insert-medium", "blockdev-insert-medium", "blockdev-close-tray", "blockdev-del"}, server.executed())
+	assert.Equal(t, map[string]any{"id": "dev-incus_cd", "node-name": "incus_cd"}, server.command("blockdev-insert-medium").Arguments)
+	assert.Equal(t, map[string]any{"node-name": "Incus_cd"}, server.command("blockdev-del").Arguments)
+
+	// A new medium which can't be opened leaves the drive untouched.
+	m, server = newTestMonitor(t, map[string]string{
+		"query-block":  `[{"qdev": "dev-incus_cd", "inserted": {"node-name": "incus_cd"}}]`,
+		"blockdev-add": "error:Could not open",
+	})
+
+	require.Error(t, m.ChangeMedia("dev-incus_cd", "incus_cd", blockDev, nil, true))
+	assert.Equal(t, []string{"query-block", "blockdev-add"}, server.executed())
+}
+
+func TestMediaAltNodeName(t *testing.T) {
+	assert.Equal(t, "Incus_cd", MediaAltNodeName("incus_cd"))
+	assert.Equal(t, "incus_cd", MediaAltNodeName("Incus_cd"))
+}
+
+func TestEjectOpenTray(t *testing.T) {
+	replies := map[string]string{"query-block": `[{"qdev": "dev-incus_cd", "tray_open": false}]`}
+	m, server := newTestMonitor(t, replies)
+
+	// Media which were changed since the guest opened the tray are left alone.
+	server.write(`{"event": "DEVICE_TRAY_MOVED", "data": {"id": "dev-incus_cd", "tray-open": true}, "timestamp": {"seconds": 0, "microseconds": 0}}`)
+	assert.Eventually(t, func() bool { return len(server.executed()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"query-block"}, server.executed())
+
+	// Media of open trays are ejected.
+	server.lock.Lock()
+	replies["query-block"] = `[{"qdev": "dev-incus_cd", "tray_open": true}]`
+	server.lock.Unlock()
+
+	require.NoError(t, m.ejectOpenTray("dev-incus_cd"))
+	assert.Equal(t, []string{"query-block", "query-block", "eject"}, server.executed())
+	assert.Equal(t, map[string]any{"id": "dev-incus_cd"}, server.command("eject").Arguments)
+}
+
+func TestGetNICMACAddress(t *testing.T) {
+	m, server := newTestMonitor(t, map[string]string{"query-rx-filter": `[{"name": "dev-incus_eth0", "main-mac": "10:66:6a:00:00:01"}]`})
+
+	mac, err := m.GetNICMACAddress("dev-incus_eth0")
+	require.NoError(t, err)
+	assert.Equal(t, "10:66:6a:00:00:01", mac)
+	assert.Equal(t, map[string]any{"name": "dev-incus_eth0"}, server.command("query-rx-filter").Arguments)
+
+	_, err = m.GetNICMACAddress("dev-incus_eth1")
+	assert.Error(t, err)
+}
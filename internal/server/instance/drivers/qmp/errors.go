@@ -12,3 +12,6 @@ var ErrMonitorBadConsole = fmt.Errorf("Requested console couldn't be found")
 
 // ErrNotARingbuf is returned when the requested device isn't a ring buffer.
 var ErrNotARingbuf = fmt.Errorf("Requested device isn't a ring buffer")
+
+// ErrNoGraphicalConsole is returned when the VM doesn't have a graphical console.
+var ErrNoGraphicalConsole = fmt.Errorf("No graphical console available")
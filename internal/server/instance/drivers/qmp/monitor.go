@@ -53,12 +53,35 @@ type Monitor struct {
 	serialCharDev     string
 	onDisconnectEvent bool
 	logFile           string
+
+	// mediaLock serializes media changes and the ejection of media opened by the guest.
+	mediaLock sync.Mutex
+
+	checkHandler   func()
+	checkHandlerMu sync.Mutex
+}
+
+// SetCheckHandler sets a function called whenever the monitor checks on the VM,
+// that is on every event and at least every 10 seconds.
+func (m *Monitor) SetCheckHandler(handler func()) {
+	m.checkHandlerMu.Lock()
+	defer m.checkHandlerMu.Unlock()
+
+	m.checkHandler = handler
 }
 
 // start handles the background goroutines for event handling and monitoring the ringbuffer.
 func (m *Monitor) start() error {
 	// Ringbuffer monitoring function.
 	checkBuffer := func() {
+		m.checkHandlerMu.Lock()
+		handler := m.checkHandler
+		m.checkHandlerMu.Unlock()
+
+		if handler != nil {
+			handler()
+		}
+
 		// Prepare the response.
 		var resp struct {
 			Return string `json:"return"`
@@ -120,7 +143,7 @@ func (m *Monitor) start() error {
 					id, ok := e.Data["id"].(string)
 					if ok {
 						go func() {
-							err = m.Eject(id)
+							err = m.ejectOpenTray(id)
 							if err != nil {
 								logger.Warnf("Unable to eject media %q: %v", id, err)
 							}
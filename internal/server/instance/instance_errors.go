@@ -6,3 +6,7 @@ import (
 
 // ErrNotImplemented is the "Not implemented" error.
 var ErrNotImplemented = fmt.Errorf("Not implemented")
+
+// ErrDependenciesNotReady is returned when the networks or storage pools used by an instance didn't become
+// ready within its boot.ready_timeout.
+var ErrDependenciesNotReady = fmt.Errorf("Instance dependencies not ready")
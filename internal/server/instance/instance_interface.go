@@ -84,6 +84,8 @@ type Instance interface {
 	Restart(timeout time.Duration) error
 	Rebuild(img *api.Image, op *operations.Operation) error
 	Unfreeze() error
+	SyncClock() error
+	Hibernate() error
 
 	ReloadDevice(devName string) error
 	RegisterDevices()
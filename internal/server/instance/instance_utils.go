@@ -5,9 +5,11 @@ import (
 	"context"
 	"crypto/rand"
 	"database/sql"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -22,8 +24,10 @@ import (
 
 	incus "github.com/lxc/incus/v6/client"
 	"github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/migration"
 	"github.com/lxc/incus/v6/internal/server/backup"
+	"github.com/lxc/incus/v6/internal/server/cgroup"
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
 	deviceConfig "github.com/lxc/incus/v6/internal/server/device/config"
@@ -92,6 +96,19 @@ func ValidConfig(sysOS *sys.OS, config map[string]string, expanded bool, instanc
 			return fmt.Errorf("Image keys can only be set on instances")
 		}
 
+		if k == instance.ConfigProfilePriorityKeys {
+			if instanceType != instancetype.Any || expanded {
+				return fmt.Errorf("%q can only be set on profiles", k)
+			}
+
+			err := validProfilePriorityKeys(config)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		err := validConfigKey(sysOS, k, v, instanceType)
 		if err != nil {
 			return err
@@ -131,6 +148,16 @@ func ValidConfig(sysOS *sys.OS, config map[string]string, expanded bool, instanc
 		return fmt.Errorf("security.syscalls.allow is mutually exclusive with security.syscalls.deny*")
 	}
 
+	profile, isProfile := config["security.syscalls.profile"]
+	if isProfile && (rawSeccomp || isAllow) {
+		return fmt.Errorf("security.syscalls.profile is mutually exclusive with raw.seccomp and security.syscalls.allow")
+	}
+
+	_, err = seccomp.ParseProfile(profile)
+	if err != nil {
+		return err
+	}
+
 	_, err = seccomp.SyscallInterceptMountFilter(config)
 	if err != nil {
 		return err
@@ -140,10 +167,106 @@ func ValidConfig(sysOS *sys.OS, config map[string]string, expanded bool, instanc
 		return fmt.Errorf("No uid/gid allocation configured. In this mode, only privileged containers are supported")
 	}
 
+	if config["security.apparmor.profile"] != "" && config["raw.apparmor"] != "" {
+		return fmt.Errorf("security.apparmor.profile is mutually exclusive with raw.apparmor")
+	}
+
 	if util.IsTrue(config["security.privileged"]) && util.IsTrue(config["nvidia.runtime"]) {
 		return fmt.Errorf("nvidia.runtime is incompatible with privileged containers")
 	}
 
+	if instanceType == instancetype.Container && expanded {
+		err = ValidMemoryConfig(sysOS, config, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidMemoryConfig validates the combination of memory limit options of a container's expanded config against
+// the host. When updating an existing container, oldConfig is its previous expanded config and the check is
+// skipped if none of the memory limit options changed, so that existing containers remain editable.
+func ValidMemoryConfig(sysOS *sys.OS, config map[string]string, oldConfig map[string]string) error {
+	if oldConfig != nil {
+		changed := false
+		for _, key := range []string{"limits.memory", "limits.memory.enforce", "limits.memory.swap"} {
+			if config[key] != oldConfig[key] {
+				changed = true
+				break
+			}
+		}
+
+		if !changed {
+			return nil
+		}
+	}
+
+	if config["limits.memory.enforce"] != "" && config["limits.memory"] == "" {
+		return fmt.Errorf("limits.memory.enforce requires limits.memory to be set")
+	}
+
+	// Only swap sizes depend on the host.
+	swap := config["limits.memory.swap"]
+	if swap == "" || util.IsTrue(swap) || util.IsFalse(swap) {
+		return nil
+	}
+
+	if config["limits.memory"] == "" {
+		return fmt.Errorf("limits.memory.swap can only be set to a size along with limits.memory")
+	}
+
+	if !sysOS.CGInfo.Supports(cgroup.MemorySwap, nil) {
+		return fmt.Errorf("limits.memory.swap can't be set to a size as swap accounting isn't available on the host")
+	}
+
+	if config["limits.memory.enforce"] == "soft" && sysOS.CGInfo.Layout != cgroup.CgroupsUnified {
+		return fmt.Errorf("limits.memory.swap can only be set to a size with soft memory enforcement on cgroup v2 hosts")
+	}
+
+	swapTotal, err := linux.GetMeminfo("SwapTotal")
+	if err != nil {
+		return fmt.Errorf("Failed getting the host swap size: %w", err)
+	}
+
+	if swapTotal <= 0 {
+		return fmt.Errorf("limits.memory.swap can't be set to a size as the host has no swap")
+	}
+
+	return nil
+}
+
+// ValidSysctlConfig validates the linux.sysctl.* options of a container's expanded config. Only sysctls attached
+// to one of the container's namespaces can be set. When updating an existing container, oldConfig is its previous
+// expanded config and only the changed options are checked, so that existing containers remain editable.
+func ValidSysctlConfig(config map[string]string, oldConfig map[string]string) error {
+	for key, value := range config {
+		if !strings.HasPrefix(key, "linux.sysctl.") {
+			continue
+		}
+
+		if oldConfig != nil && oldConfig[key] == value {
+			continue
+		}
+
+		sysctl := strings.TrimPrefix(key, "linux.sysctl.")
+		if instance.SysctlNamespace(sysctl) == "" {
+			return fmt.Errorf("Sysctl %q isn't namespaced and can't be set for a container", sysctl)
+		}
+	}
+
+	return nil
+}
+
+// ValidRestartPolicyConfig validates the boot.restart.policy option of a container's expanded config. Containers
+// don't report how they exited, so they can't tell a failure from a clean shutdown and only support the "never"
+// and "always" policies.
+func ValidRestartPolicyConfig(config map[string]string) error {
+	if config["boot.restart.policy"] == "on-failure" {
+		return fmt.Errorf("boot.restart.policy can't be set to \"on-failure\" on containers")
+	}
+
 	return nil
 }
 
@@ -165,6 +288,11 @@ func validConfigKey(os *sys.OS, key string, value string, instanceType instancet
 		return lxcValidConfig(value)
 	}
 
+	if key == "raw.cgroup" {
+		_, err := cgroup.ParseRaw(value)
+		return err
+	}
+
 	if key == "security.syscalls.deny_compat" || key == "security.syscalls.blacklist_compat" {
 		for _, arch := range os.Architectures {
 			if arch == osarch.ARCH_64BIT_INTEL_X86 ||
@@ -204,6 +332,22 @@ func lxcParseRawLXC(line string) (string, string, error) {
 	return key, val, nil
 }
 
+// validProfilePriorityKeys checks that the keys marked as high priority by a profile are set by that profile.
+func validProfilePriorityKeys(config map[string]string) error {
+	for _, key := range instance.ProfilePriorityKeys(config) {
+		if key == instance.ConfigProfilePriorityKeys {
+			return fmt.Errorf("%q can't be marked as high priority", key)
+		}
+
+		_, found := config[key]
+		if !found {
+			return fmt.Errorf("High priority key %q isn't set by the profile", key)
+		}
+	}
+
+	return nil
+}
+
 func lxcValidConfig(rawLxc string) error {
 	for _, line := range strings.Split(rawLxc, "\n") {
 		key, _, err := lxcParseRawLXC(line)
@@ -484,6 +628,106 @@ func DeviceNextInterfaceHWAddr() (string, error) {
 	return ret.String(), nil
 }
 
+// ParseHWAddrRange parses a MAC address range (e.g. "02:00:00:00:00:00-02:00:00:ff:ff:ff") and returns its
+// first and last addresses. All the addresses of the range must be locally administered unicast addresses.
+func ParseHWAddrRange(value string) (uint64, uint64, error) {
+	startStr, endStr, found := strings.Cut(value, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("MAC address range must be in the form <first>-<last>")
+	}
+
+	parse := func(value string) (net.HardwareAddr, error) {
+		hwaddr, err := net.ParseMAC(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(hwaddr) != 6 {
+			return nil, fmt.Errorf("Invalid MAC address %q, must be 48 bits", value)
+		}
+
+		if hwaddr[0]&0x01 != 0 {
+			return nil, fmt.Errorf("Invalid MAC address %q, must be unicast", value)
+		}
+
+		if hwaddr[0]&0x02 == 0 {
+			return nil, fmt.Errorf("Invalid MAC address %q, must have the locally administered bit set", value)
+		}
+
+		return hwaddr, nil
+	}
+
+	start, err := parse(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	end, err := parse(endStr)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Sharing the first byte ensures every address in the range has the same unicast and locally administered bits.
+	if start[0] != end[0] {
+		return 0, 0, fmt.Errorf("First and last MAC addresses of the range must share their first byte")
+	}
+
+	startInt := binary.BigEndian.Uint64(append([]byte{0, 0}, start...))
+	endInt := binary.BigEndian.Uint64(append([]byte{0, 0}, end...))
+	if startInt > endInt {
+		return 0, 0, fmt.Errorf("First MAC address of the range must not be greater than the last one")
+	}
+
+	return startInt, endInt, nil
+}
+
+// DeviceNextInterfaceHWAddrInRange generates a random MAC address within the given range which isn't in use.
+func DeviceNextInterfaceHWAddrInRange(hwaddrRange string, usedHWAddrs []string) (string, error) {
+	start, end, err := ParseHWAddrRange(hwaddrRange)
+	if err != nil {
+		return "", err
+	}
+
+	used := make(map[uint64]struct{}, len(usedHWAddrs))
+	for _, usedHWAddr := range usedHWAddrs {
+		hwaddr, err := net.ParseMAC(usedHWAddr)
+		if err != nil || len(hwaddr) != 6 {
+			continue
+		}
+
+		value := binary.BigEndian.Uint64(append([]byte{0, 0}, hwaddr...))
+		if value >= start && value <= end {
+			used[value] = struct{}{}
+		}
+	}
+
+	size := end - start + 1
+	if uint64(len(used)) >= size {
+		return "", fmt.Errorf("No MAC address left in range %q", hwaddrRange)
+	}
+
+	// Start from a random address and use the next free one.
+	offset, err := rand.Int(rand.Reader, new(big.Int).SetUint64(size))
+	if err != nil {
+		return "", err
+	}
+
+	for i := uint64(0); i < size; i++ {
+		value := start + (offset.Uint64()+i)%size
+		_, found := used[value]
+		if found {
+			continue
+		}
+
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, value)
+
+		return net.HardwareAddr(buf[2:]).String(), nil
+	}
+
+	return "", fmt.Errorf("No MAC address left in range %q", hwaddrRange)
+}
+
 // BackupLoadByName load an instance backup from the database.
 func BackupLoadByName(s *state.State, project, name string) (*backup.InstanceBackup, error) {
 	var args db.InstanceBackup
@@ -1348,3 +1592,38 @@ func ResourceUsage(instConfig map[string]string, instDevices map[string]map[stri
 
 	return cpuUsage, memoryUsage, diskUsage, nil
 }
+
+// MetricsEnabled returns whether metrics should be collected for an instance with the given expanded config.
+// The serverDefault value is used when the instance doesn't set metrics.enabled.
+func MetricsEnabled(expandedConfig map[string]string, serverDefault bool) bool {
+	value := expandedConfig["metrics.enabled"]
+	if value == "" {
+		return serverDefault
+	}
+
+	return util.IsTrue(value)
+}
+
+// MetricsExcludedDevices returns the names of the devices excluded from metrics for an instance with the given expanded config.
+func MetricsExcludedDevices(expandedConfig map[string]string) []string {
+	return util.SplitNTrimSpace(expandedConfig["metrics.exclude_devices"], ",", -1, true)
+}
+
+// Hostname returns the hostname to use inside an instance with the given name and expanded config.
+// This is dns.hostname when set, the instance name otherwise.
+func Hostname(name string, expandedConfig map[string]string) string {
+	hostname := expandedConfig["dns.hostname"]
+	if hostname == "" {
+		return name
+	}
+
+	return hostname
+}
+
+// DNSName returns the name under which an instance with the given name and expanded config is registered
+// in the DNS of managed networks. This is the first label of its hostname.
+func DNSName(name string, expandedConfig map[string]string) string {
+	dnsName, _, _ := strings.Cut(Hostname(name, expandedConfig), ".")
+
+	return dnsName
+}
@@ -0,0 +1,118 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHWAddrRange(t *testing.T) {
+	start, end, err := ParseHWAddrRange("02:00:00:00:00:00-02:00:00:00:ff:ff")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0x020000000000), start)
+	assert.Equal(t, uint64(0x02000000ffff), end)
+
+	for _, value := range []string{
+		"",
+		"02:00:00:00:00:00",
+		"02:00:00:00:00:00-foo",
+		"10:66:6a:00:00:00-10:66:6a:ff:ff:ff",       // Not locally administered.
+		"03:00:00:00:00:00-03:00:00:00:00:ff",       // Multicast.
+		"02:00:00:00:00:00-06:00:00:00:00:00",       // Not sharing the first byte.
+		"02:00:00:00:01:00-02:00:00:00:00:ff",       // Reversed.
+		"02:00:00:00:00:00:00:00-02:00:00:00:ff:ff", // Not 48 bits.
+	} {
+		_, _, err := ParseHWAddrRange(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestDeviceNextInterfaceHWAddrInRange(t *testing.T) {
+	hwaddrRange := "02:aa:bb:00:00:00-02:aa:bb:00:00:03"
+
+	// Addresses are unique within the range.
+	used := []string{"02:aa:bb:00:00:01", "10:66:6a:00:00:00", "invalid"}
+	for range 3 {
+		hwaddr, err := DeviceNextInterfaceHWAddrInRange(hwaddrRange, used)
+		require.NoError(t, err)
+		assert.Contains(t, []string{"02:aa:bb:00:00:00", "02:aa:bb:00:00:02", "02:aa:bb:00:00:03"}, hwaddr)
+		assert.NotContains(t, used, hwaddr)
+
+		used = append(used, hwaddr)
+	}
+
+	// The range is exhausted.
+	_, err := DeviceNextInterfaceHWAddrInRange(hwaddrRange, used)
+	assert.ErrorContains(t, err, "No MAC address left")
+
+	// A single address range.
+	hwaddr, err := DeviceNextInterfaceHWAddrInRange("02:aa:bb:00:00:00-02:aa:bb:00:00:00", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "02:aa:bb:00:00:00", hwaddr)
+}
+
+func TestMetricsEnabled(t *testing.T) {
+	// The server default applies when unset.
+	assert.True(t, MetricsEnabled(map[string]string{}, true))
+	assert.False(t, MetricsEnabled(map[string]string{}, false))
+
+	// The instance setting takes precedence.
+	assert.False(t, MetricsEnabled(map[string]string{"metrics.enabled": "false"}, true))
+	assert.True(t, MetricsEnabled(map[string]string{"metrics.enabled": "true"}, false))
+}
+
+func TestMetricsExcludedDevices(t *testing.T) {
+	assert.Empty(t, MetricsExcludedDevices(map[string]string{}))
+	assert.Equal(t, []string{"eth0", "data"}, MetricsExcludedDevices(map[string]string{"metrics.exclude_devices": "eth0, data"}))
+}
+
+func TestHostname(t *testing.T) {
+	// The instance name is used by default.
+	assert.Equal(t, "c1", Hostname("c1", map[string]string{}))
+	assert.Equal(t, "c1", DNSName("c1", map[string]string{}))
+
+	assert.Equal(t, "web", Hostname("c1", map[string]string{"dns.hostname": "web"}))
+	assert.Equal(t, "web", DNSName("c1", map[string]string{"dns.hostname": "web"}))
+
+	// Only the first label is registered in DNS.
+	assert.Equal(t, "web.example.com", Hostname("c1", map[string]string{"dns.hostname": "web.example.com"}))
+	assert.Equal(t, "web", DNSName("c1", map[string]string{"dns.hostname": "web.example.com"}))
+}
+
+func TestValidMemoryConfig(t *testing.T) {
+	// Memory enforcement requires a memory limit.
+	require.NoError(t, ValidMemoryConfig(nil, map[string]string{}, nil))
+	require.NoError(t, ValidMemoryConfig(nil, map[string]string{"limits.memory": "1GiB", "limits.memory.enforce": "soft"}, nil))
+	require.Error(t, ValidMemoryConfig(nil, map[string]string{"limits.memory.enforce": "soft"}, nil))
+
+	// Swap sizes require a memory limit.
+	require.NoError(t, ValidMemoryConfig(nil, map[string]string{"limits.memory.swap": "false"}, nil))
+	require.Error(t, ValidMemoryConfig(nil, map[string]string{"limits.memory.swap": "1GiB"}, nil))
+
+	// Existing containers can be updated as long as the memory limits aren't changed.
+	oldConfig := map[string]string{"limits.memory.enforce": "soft"}
+	require.NoError(t, ValidMemoryConfig(nil, map[string]string{"limits.memory.enforce": "soft", "limits.cpu": "2"}, oldConfig))
+	require.Error(t, ValidMemoryConfig(nil, map[string]string{"limits.memory.enforce": "hard"}, oldConfig))
+}
+
+func TestValidSysctlConfig(t *testing.T) {
+	// Namespaced sysctls can always be set.
+	require.NoError(t, ValidSysctlConfig(map[string]string{"linux.sysctl.net.ipv4.ip_forward": "1"}, nil))
+
+	// Other sysctls are rejected, including on privileged containers.
+	require.Error(t, ValidSysctlConfig(map[string]string{"linux.sysctl.vm.swappiness": "10"}, nil))
+	require.Error(t, ValidSysctlConfig(map[string]string{"linux.sysctl.vm.swappiness": "10", "security.privileged": "true"}, nil))
+
+	// Existing containers can be updated as long as the sysctl isn't changed.
+	oldConfig := map[string]string{"linux.sysctl.kernel.panic": "10"}
+	require.NoError(t, ValidSysctlConfig(map[string]string{"linux.sysctl.kernel.panic": "10", "limits.cpu": "2"}, oldConfig))
+	require.Error(t, ValidSysctlConfig(map[string]string{"linux.sysctl.kernel.panic": "20"}, oldConfig))
+}
+
+func TestValidRestartPolicyConfig(t *testing.T) {
+	require.NoError(t, ValidRestartPolicyConfig(map[string]string{}))
+	require.NoError(t, ValidRestartPolicyConfig(map[string]string{"boot.restart.policy": "never"}))
+	require.NoError(t, ValidRestartPolicyConfig(map[string]string{"boot.restart.policy": "always"}))
+	require.Error(t, ValidRestartPolicyConfig(map[string]string{"boot.restart.policy": "on-failure"}))
+}
@@ -58,6 +58,7 @@ type InstanceOperation struct {
 	instanceName      string
 	reusable          bool
 	instanceInitiated bool
+	userInitiated     bool
 	op                *operations.Operation
 }
 
@@ -239,6 +240,26 @@ func (op *InstanceOperation) GetInstanceInitiated() bool {
 	return op.instanceInitiated
 }
 
+// SetUserInitiated sets the user initiated marker, indicating the operation was requested through Incus.
+func (op *InstanceOperation) SetUserInitiated(userInitiated bool) {
+	// This function can be called on a nil struct.
+	if op == nil {
+		return
+	}
+
+	op.userInitiated = userInitiated
+}
+
+// GetUserInitiated gets the user initiated marker.
+func (op *InstanceOperation) GetUserInitiated() bool {
+	// This function can be called on a nil struct.
+	if op == nil {
+		return false
+	}
+
+	return op.userInitiated
+}
+
 // GetOperation gets the API background operation.
 func (op *InstanceOperation) GetOperation() *operations.Operation {
 	// This function can be called on a nil struct.
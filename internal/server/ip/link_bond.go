@@ -0,0 +1,33 @@
+package ip
+
+// Bond represents arguments for link of type bond.
+type Bond struct {
+	Link
+	Mode         string
+	LACPRate     string
+	MIIFrequency string
+}
+
+// additionalArgs generates bond specific arguments.
+func (bond *Bond) additionalArgs() []string {
+	args := []string{}
+
+	if bond.Mode != "" {
+		args = append(args, "mode", bond.Mode)
+	}
+
+	if bond.LACPRate != "" {
+		args = append(args, "lacp_rate", bond.LACPRate)
+	}
+
+	if bond.MIIFrequency != "" {
+		args = append(args, "miimon", bond.MIIFrequency)
+	}
+
+	return args
+}
+
+// Add adds new virtual link.
+func (bond *Bond) Add() error {
+	return bond.Link.add("bond", bond.additionalArgs())
+}
@@ -0,0 +1,15 @@
+package ip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBondAdditionalArgs(t *testing.T) {
+	bond := &Bond{Mode: "802.3ad", LACPRate: "fast", MIIFrequency: "100"}
+	assert.Equal(t, []string{"mode", "802.3ad", "lacp_rate", "fast", "miimon", "100"}, bond.additionalArgs())
+
+	bond = &Bond{Mode: "active-backup"}
+	assert.Equal(t, []string{"mode", "active-backup"}, bond.additionalArgs())
+}
@@ -21,6 +21,11 @@ func (n *NeighProxy) Show() ([]NeighProxy, error) {
 		return nil, err
 	}
 
+	return parseNeighProxy(n.DevName, out), nil
+}
+
+// parseNeighProxy parses the output of `ip neigh show proxy dev <devName>`.
+func parseNeighProxy(devName string, out string) []NeighProxy {
 	lines := util.SplitNTrimSpace(out, "\n", -1, true)
 	entries := make([]NeighProxy, 0, len(lines))
 
@@ -36,12 +41,12 @@ func (n *NeighProxy) Show() ([]NeighProxy, error) {
 		}
 
 		entries = append(entries, NeighProxy{
-			DevName: n.DevName,
+			DevName: devName,
 			Addr:    ip,
 		})
 	}
 
-	return entries, nil
+	return entries
 }
 
 // Add a neighbour proxy entry.
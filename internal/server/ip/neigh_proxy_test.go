@@ -0,0 +1,26 @@
+package ip
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNeighProxy(t *testing.T) {
+	out := `192.0.2.10  proxy
+2001:db8::10  proxy
+
+invalid proxy
+198.51.100.1 dev eth0 proxy
+`
+
+	expected := []NeighProxy{
+		{DevName: "eth0", Addr: net.ParseIP("192.0.2.10")},
+		{DevName: "eth0", Addr: net.ParseIP("2001:db8::10")},
+		{DevName: "eth0", Addr: net.ParseIP("198.51.100.1")},
+	}
+
+	assert.Equal(t, expected, parseNeighProxy("eth0", out))
+	assert.Empty(t, parseNeighProxy("eth0", ""))
+}
@@ -12,14 +12,19 @@ type Tuntap struct {
 	Master     string
 }
 
-// Add adds new tuntap interface.
-func (t *Tuntap) Add() error {
+// args generates the arguments used to create the tuntap interface.
+func (t *Tuntap) args() []string {
 	cmd := []string{"tuntap", "add", "name", t.Name, "mode", t.Mode}
 	if t.MultiQueue {
 		cmd = append(cmd, "multi_queue")
 	}
 
-	_, err := subprocess.RunCommand("ip", cmd...)
+	return cmd
+}
+
+// Add adds new tuntap interface.
+func (t *Tuntap) Add() error {
+	_, err := subprocess.RunCommand("ip", t.args()...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,15 @@
+package ip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTuntapArgs(t *testing.T) {
+	tuntap := &Tuntap{Name: "tun0", Mode: "tun"}
+	assert.Equal(t, []string{"tuntap", "add", "name", "tun0", "mode", "tun"}, tuntap.args())
+
+	tuntap = &Tuntap{Name: "tap0", Mode: "tap", MultiQueue: true}
+	assert.Equal(t, []string{"tuntap", "add", "name", "tap0", "mode", "tap", "multi_queue"}, tuntap.args())
+}
@@ -16,10 +16,11 @@ type NetworkAction string
 
 // All supported lifecycle events for network devices.
 const (
-	NetworkCreated = NetworkAction(api.EventLifecycleNetworkCreated)
-	NetworkDeleted = NetworkAction(api.EventLifecycleNetworkDeleted)
-	NetworkUpdated = NetworkAction(api.EventLifecycleNetworkUpdated)
-	NetworkRenamed = NetworkAction(api.EventLifecycleNetworkRenamed)
+	NetworkCreated      = NetworkAction(api.EventLifecycleNetworkCreated)
+	NetworkDeleted      = NetworkAction(api.EventLifecycleNetworkDeleted)
+	NetworkUpdated      = NetworkAction(api.EventLifecycleNetworkUpdated)
+	NetworkRenamed      = NetworkAction(api.EventLifecycleNetworkRenamed)
+	NetworkLeaseDeleted = NetworkAction(api.EventLifecycleNetworkLeaseDeleted)
 )
 
 // Event creates the lifecycle event for an action on a network device.
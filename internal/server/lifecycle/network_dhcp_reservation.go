@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"github.com/lxc/incus/v6/internal/version"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// NetworkDHCPReservationAction represents a lifecycle event action for network DHCP reservations.
+type NetworkDHCPReservationAction string
+
+// All supported lifecycle events for network DHCP reservations.
+const (
+	NetworkDHCPReservationCreated = NetworkDHCPReservationAction(api.EventLifecycleNetworkDHCPReservationCreated)
+	NetworkDHCPReservationDeleted = NetworkDHCPReservationAction(api.EventLifecycleNetworkDHCPReservationDeleted)
+	NetworkDHCPReservationUpdated = NetworkDHCPReservationAction(api.EventLifecycleNetworkDHCPReservationUpdated)
+)
+
+// Event creates the lifecycle event for an action on a network DHCP reservation.
+func (a NetworkDHCPReservationAction) Event(n network, hwaddr string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "networks", n.Name(), "dhcp-reservations", hwaddr).Project(n.Project())
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -0,0 +1,264 @@
+package logship
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TargetJournald is the target value forwarding logs to the journald instance of the host.
+const TargetJournald = "journald"
+
+// backoffMin is the delay before the first reconnection attempt to an unreachable target.
+const backoffMin = time.Second
+
+// backoffMax is the maximum delay between reconnection attempts to an unreachable target.
+const backoffMax = time.Minute
+
+// closeTimeout is how long the queued messages are still delivered for once the shipper is closed.
+const closeTimeout = 2 * time.Second
+
+// Target represents a log forwarding destination.
+type Target struct {
+	// Protocol is one of "journald", "udp" or "tcp".
+	Protocol string
+
+	// Address is the host and port of remote syslog targets.
+	Address string
+}
+
+// String returns the configuration value of the target.
+func (t *Target) String() string {
+	if t.Protocol == TargetJournald {
+		return TargetJournald
+	}
+
+	return fmt.Sprintf("%s://%s", t.Protocol, t.Address)
+}
+
+// ParseTarget parses a log forwarding destination.
+// Valid values are "journald" or a syslog address in the form "[udp://|tcp://]<host>[:<port>]".
+func ParseTarget(value string) (*Target, error) {
+	if value == TargetJournald {
+		return &Target{Protocol: TargetJournald}, nil
+	}
+
+	protocol := "udp"
+	address := value
+
+	before, after, found := strings.Cut(value, "://")
+	if found {
+		protocol = before
+		address = after
+	}
+
+	if protocol != "udp" && protocol != "tcp" {
+		return nil, fmt.Errorf("Unsupported log target protocol %q", protocol)
+	}
+
+	if address == "" {
+		return nil, fmt.Errorf("Log target address cannot be empty")
+	}
+
+	_, _, err := net.SplitHostPort(address)
+	if err != nil {
+		address = net.JoinHostPort(strings.Trim(address, "[]"), "514")
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return nil, fmt.Errorf("Invalid log target address %q", value)
+	}
+
+	return &Target{Protocol: protocol, Address: address}, nil
+}
+
+// ValidateTarget validates a log forwarding destination.
+func ValidateTarget(value string) error {
+	_, err := ParseTarget(value)
+
+	return err
+}
+
+// Message represents a single log line to forward.
+type Message struct {
+	Time time.Time
+	Text string
+}
+
+// sink is a connection to a log target.
+type sink interface {
+	send(msg Message) error
+	close() error
+}
+
+// Shipper forwards log lines to a target from a bounded queue.
+// Lines are dropped rather than blocking the sender when the target is slow or unreachable.
+type Shipper struct {
+	target *Target
+	tag    string
+	fields map[string]string
+
+	queue    chan Message
+	dropped  atomic.Uint64
+	stopOnce sync.Once
+	stopping chan struct{}
+	done     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	// dial connects to the target, overridden in tests.
+	dial func(ctx context.Context) (sink, error)
+}
+
+// NewShipper returns a new Shipper forwarding to the given target and starts it.
+// The tag identifies the source of the lines and the fields are attached to every line as structured data.
+// Up to size lines are queued while the target is slow or unreachable.
+func NewShipper(target *Target, tag string, fields map[string]string, size int) *Shipper {
+	s := newShipper(target, tag, fields, size)
+	go s.run()
+
+	return s
+}
+
+// newShipper returns a new Shipper without starting it.
+func newShipper(target *Target, tag string, fields map[string]string, size int) *Shipper {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &Shipper{
+		target:   target,
+		tag:      tag,
+		fields:   fields,
+		queue:    make(chan Message, size),
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	s.dial = func(ctx context.Context) (sink, error) {
+		if target.Protocol == TargetJournald {
+			return dialJournald(tag, fields)
+		}
+
+		return dialSyslog(ctx, target, tag, fields)
+	}
+
+	return s
+}
+
+// Send queues a line for forwarding.
+// Returns false if the line was dropped because the queue is full or the shipper is closed.
+func (s *Shipper) Send(text string) bool {
+	select {
+	case <-s.stopping:
+		return false
+	default:
+	}
+
+	select {
+	case s.queue <- Message{Time: time.Now(), Text: text}:
+		return true
+	default:
+		s.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped returns the number of lines dropped since the shipper was started.
+func (s *Shipper) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close stops the shipper without waiting for it.
+// The queued lines are still delivered in the background, giving up on them after a short while.
+func (s *Shipper) Close() {
+	s.stopOnce.Do(func() {
+		close(s.stopping)
+
+		go func() {
+			select {
+			case <-s.done:
+			case <-time.After(closeTimeout):
+			}
+
+			s.cancel()
+		}()
+	})
+}
+
+// run delivers the queued lines until the shipper is closed.
+func (s *Shipper) run() {
+	var conn sink
+
+	defer func() {
+		if conn != nil {
+			_ = conn.close()
+		}
+
+		close(s.done)
+	}()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case msg := <-s.queue:
+			if !s.deliver(&conn, msg) {
+				return
+			}
+
+		case <-s.stopping:
+			// Deliver whatever is left in the queue.
+			for {
+				select {
+				case msg := <-s.queue:
+					if !s.deliver(&conn, msg) {
+						return
+					}
+
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver sends a line to the target, reconnecting with an increasing delay until it succeeds.
+// Returns false if the shipper was stopped before the line could be delivered.
+func (s *Shipper) deliver(conn *sink, msg Message) bool {
+	backoff := backoffMin
+
+	for {
+		if *conn == nil {
+			c, err := s.dial(s.ctx)
+			if err == nil {
+				*conn = c
+			}
+		}
+
+		if *conn != nil {
+			err := (*conn).send(msg)
+			if err == nil {
+				return true
+			}
+
+			// Reconnect on the next attempt.
+			_ = (*conn).close()
+			*conn = nil
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, backoffMax)
+	}
+}
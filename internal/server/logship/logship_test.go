@@ -0,0 +1,187 @@
+package logship
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "journald", want: "journald"},
+		{value: "10.0.0.1", want: "udp://10.0.0.1:514"},
+		{value: "udp://logs.example.net:1514", want: "udp://logs.example.net:1514"},
+		{value: "tcp://[2001:db8::1]", want: "tcp://[2001:db8::1]:514"},
+		{value: "tcp://[2001:db8::1]:601", want: "tcp://[2001:db8::1]:601"},
+		{value: "tls://10.0.0.1", wantErr: true},
+		{value: "tcp://", wantErr: true},
+		{value: "tcp://:514", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		target, err := ParseTarget(tt.value)
+		if tt.wantErr {
+			assert.Error(t, err, tt.value)
+			continue
+		}
+
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, target.String())
+	}
+}
+
+func TestSyslogStructuredData(t *testing.T) {
+	sd := syslogStructuredData(map[string]string{"project": "default", "instance": `c"1]`})
+	assert.Equal(t, `[incus@32473 instance="c\"1\]" project="default"]`, sd)
+
+	assert.Equal(t, "-", syslogStructuredData(nil))
+}
+
+func TestShipperSyslogTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer func() { _ = listener.Close() }()
+
+	target, err := ParseTarget("tcp://" + listener.Addr().String())
+	require.NoError(t, err)
+
+	s := NewShipper(target, "incus", map[string]string{"instance": "c1"}, 10)
+	assert.True(t, s.Send("hello world"))
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+
+	defer func() { _ = conn.Close() }()
+
+	// Read the octet counted frame.
+	reader := bufio.NewReader(conn)
+	length, err := reader.ReadString(' ')
+	require.NoError(t, err)
+
+	size, err := strconv.Atoi(strings.TrimSpace(length))
+	require.NoError(t, err)
+
+	frame := make([]byte, size)
+	_, err = io.ReadFull(reader, frame)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(string(frame), "<14>1 "))
+	assert.True(t, strings.HasSuffix(string(frame), ` incus - - [incus@32473 instance="c1"] hello world`))
+
+	s.Close()
+	<-s.done
+	assert.Equal(t, uint64(0), s.Dropped())
+}
+
+// blockingSink is a sink which only accepts lines once released.
+type blockingSink struct {
+	release chan struct{}
+	lines   chan string
+}
+
+func (b *blockingSink) send(msg Message) error {
+	<-b.release
+	b.lines <- msg.Text
+	return nil
+}
+
+func (b *blockingSink) close() error {
+	return nil
+}
+
+func TestShipperDrop(t *testing.T) {
+	b := &blockingSink{release: make(chan struct{}), lines: make(chan string, 100)}
+
+	s := newShipper(&Target{Protocol: "udp", Address: "127.0.0.1:514"}, "incus", nil, 2)
+	s.dial = func(ctx context.Context) (sink, error) { return b, nil }
+	go s.run()
+
+	// The first line is picked up by the delivery loop, the next two fill the queue.
+	assert.True(t, s.Send("1"))
+	assert.Eventually(t, func() bool { return len(s.queue) == 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, s.Send("2"))
+	assert.True(t, s.Send("3"))
+
+	// Never block the sender.
+	assert.False(t, s.Send("4"))
+	assert.False(t, s.Send("5"))
+	assert.Equal(t, uint64(2), s.Dropped())
+
+	close(b.release)
+	s.Close()
+	<-s.done
+
+	close(b.lines)
+	lines := []string{}
+	for line := range b.lines {
+		lines = append(lines, line)
+	}
+
+	assert.Equal(t, []string{"1", "2", "3"}, lines)
+	assert.False(t, s.Send("6"))
+}
+
+func TestShipperReconnect(t *testing.T) {
+	attempts := 0
+	b := &blockingSink{release: make(chan struct{}), lines: make(chan string, 10)}
+	close(b.release)
+
+	s := newShipper(&Target{Protocol: "udp", Address: "127.0.0.1:514"}, "incus", nil, 10)
+	s.dial = func(ctx context.Context) (sink, error) {
+		attempts++
+		if attempts < 2 {
+			return nil, errors.New("Unreachable")
+		}
+
+		return b, nil
+	}
+
+	go s.run()
+
+	assert.True(t, s.Send("hello"))
+
+	select {
+	case line := <-b.lines:
+		assert.Equal(t, "hello", line)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the line to be delivered")
+	}
+
+	s.Close()
+	<-s.done
+	assert.Equal(t, 2, attempts)
+}
+
+func TestShipperCloseUnreachable(t *testing.T) {
+	s := newShipper(&Target{Protocol: "udp", Address: "127.0.0.1:514"}, "incus", nil, 10)
+	s.dial = func(ctx context.Context) (sink, error) { return nil, errors.New("Unreachable") }
+	go s.run()
+
+	assert.True(t, s.Send("hello"))
+
+	// Closing never waits for the delivery.
+	start := time.Now()
+	s.Close()
+	assert.Less(t, time.Since(start), closeTimeout)
+
+	// The queued lines are given up on after a short while.
+	select {
+	case <-s.done:
+	case <-time.After(closeTimeout + 5*time.Second):
+		t.Fatal("Timed out waiting for the shipper to stop")
+	}
+}
@@ -0,0 +1,80 @@
+package logship
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// journaldSocket is the path of the journald native protocol socket.
+const journaldSocket = "/run/systemd/journal/socket"
+
+// journaldSink sends messages to the host journald using its native protocol.
+type journaldSink struct {
+	conn   *net.UnixConn
+	fields []byte
+}
+
+// dialJournald connects to the host journald.
+func dialJournald(tag string, fields map[string]string) (sink, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to journald: %w", err)
+	}
+
+	var buf bytes.Buffer
+	journaldField(&buf, "SYSLOG_IDENTIFIER", tag)
+	journaldField(&buf, "PRIORITY", "6")
+
+	for key, value := range fields {
+		journaldField(&buf, journaldFieldName(key), value)
+	}
+
+	return &journaldSink{
+		conn:   conn,
+		fields: buf.Bytes(),
+	}, nil
+}
+
+// journaldFieldName returns the journal field name for a structured data key.
+func journaldFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+
+		return '_'
+	}, strings.ToUpper(key))
+
+	return "INCUS_" + name
+}
+
+// journaldField appends a field to a journal entry, using the binary encoding for values holding a line break.
+func journaldField(buf *bytes.Buffer, name string, value string) {
+	if !strings.Contains(value, "\n") {
+		fmt.Fprintf(buf, "%s=%s\n", name, value)
+		return
+	}
+
+	buf.WriteString(name + "\n")
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+}
+
+// send sends a line to journald.
+func (s *journaldSink) send(msg Message) error {
+	var buf bytes.Buffer
+	buf.Write(s.fields)
+	journaldField(&buf, "MESSAGE", msg.Text)
+
+	_, err := s.conn.Write(buf.Bytes())
+
+	return err
+}
+
+// close closes the connection to journald.
+func (s *journaldSink) close() error {
+	return s.conn.Close()
+}
@@ -1,5 +1,9 @@
 package metrics
 
+import (
+	"time"
+)
+
 // Metrics represents instance metrics.
 type Metrics struct {
 	CPU            []CPUMetrics        `json:"cpu_seconds_total" yaml:"cpu_seconds_total"`
@@ -9,6 +13,7 @@ type Metrics struct {
 	Memory         MemoryMetrics       `json:"memory" yaml:"memory"`
 	Network        []NetworkMetrics    `json:"network" yaml:"network"`
 	ProcessesTotal uint64              `json:"procs_total" yaml:"procs_total"`
+	Time           time.Time           `json:"time" yaml:"time"`
 }
 
 // CPUMetrics represents CPU metrics for an instance.
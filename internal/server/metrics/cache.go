@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// cacheEntry is a metric set stored in a Cache along with the time it was computed.
+type cacheEntry struct {
+	metrics  *MetricSet
+	computed time.Time
+}
+
+// Cache holds recently computed metric sets so that frequent or concurrent scrapes can reuse them.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCache returns a new empty Cache.
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]cacheEntry)}
+}
+
+// Get returns the metric set cached under key if it was computed less than ttl ago.
+// Otherwise fill is called to compute it and the result is stored in the cache.
+// A ttl of zero or less disables caching. The returned set is a copy which callers are free to modify.
+func (c *Cache) Get(key string, ttl time.Duration, fill func() (*MetricSet, error)) (*MetricSet, error) {
+	now := time.Now()
+
+	if ttl > 0 {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		c.mu.Unlock()
+
+		if ok && now.Sub(entry.computed) < ttl {
+			return entry.metrics.Clone(), nil
+		}
+	}
+
+	metricSet, err := fill()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ttl <= 0 {
+		delete(c.entries, key)
+		return metricSet, nil
+	}
+
+	// Drop entries which went stale so that removed keys don't accumulate.
+	for k, v := range c.entries {
+		if now.Sub(v.computed) >= ttl {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = cacheEntry{metrics: metricSet, computed: now}
+
+	return metricSet.Clone(), nil
+}
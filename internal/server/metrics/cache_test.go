@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Get(t *testing.T) {
+	c := NewCache()
+	calls := 0
+	fill := func() (*MetricSet, error) {
+		calls++
+		m := NewMetricSet(nil)
+		m.AddSamples(FilesystemSizeBytes, Sample{Value: float64(calls)})
+		return m, nil
+	}
+
+	// The first call computes the metrics.
+	m, err := c.Get("foo", time.Minute, fill)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Equal(t, float64(1), m.set[FilesystemSizeBytes][0].Value)
+
+	// Fresh metrics are reused.
+	m, err = c.Get("foo", time.Minute, fill)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Equal(t, float64(1), m.set[FilesystemSizeBytes][0].Value)
+
+	// Keys are cached independently.
+	_, err = c.Get("bar", time.Minute, fill)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	// Stale metrics are recomputed.
+	time.Sleep(10 * time.Millisecond)
+	m, err = c.Get("foo", time.Millisecond, fill)
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+	require.Equal(t, float64(3), m.set[FilesystemSizeBytes][0].Value)
+
+	// The stale entry for bar got dropped.
+	require.NotContains(t, c.entries, "bar")
+
+	// A zero TTL disables caching.
+	_, err = c.Get("foo", 0, fill)
+	require.NoError(t, err)
+	_, err = c.Get("foo", 0, fill)
+	require.NoError(t, err)
+	require.Equal(t, 5, calls)
+	require.Empty(t, c.entries)
+
+	// Errors aren't cached.
+	_, err = c.Get("foo", time.Minute, func() (*MetricSet, error) { return nil, errors.New("failed") })
+	require.Error(t, err)
+	_, err = c.Get("foo", time.Minute, fill)
+	require.NoError(t, err)
+	require.Equal(t, 6, calls)
+}
+
+func TestCache_GetIsolated(t *testing.T) {
+	c := NewCache()
+	fill := func() (*MetricSet, error) {
+		m := NewMetricSet(nil)
+		m.AddSamples(FilesystemSizeBytes, Sample{Value: 1, Labels: map[string]string{"device": "sda"}})
+		return m, nil
+	}
+
+	// Merging a returned set into an instance set adds the instance labels to its samples.
+	for _, name := range []string{"c1", "c2"} {
+		m, err := c.Get("foo", time.Minute, fill)
+		require.NoError(t, err)
+
+		out := NewMetricSet(map[string]string{"project": "default", "name": name})
+		out.Merge(m)
+		require.Equal(t, name, out.set[FilesystemSizeBytes][0].Labels["name"])
+	}
+
+	// The cached set is left untouched.
+	require.Equal(t, map[string]string{"device": "sda"}, c.entries["foo"].metrics.set[FilesystemSizeBytes][0].Labels)
+}
+
+// benchmarkFill simulates an expensive metrics collection.
+func benchmarkFill() (*MetricSet, error) {
+	m := NewMetricSet(map[string]string{"project": "default", "name": "c1"})
+	for i := 0; i < 1000; i++ {
+		m.AddSamples(FilesystemSizeBytes, Sample{Value: float64(i)})
+	}
+
+	return m, nil
+}
+
+func BenchmarkCache_Uncached(b *testing.B) {
+	c := NewCache()
+	for i := 0; i < b.N; i++ {
+		_, _ = c.Get("c1", 0, benchmarkFill)
+	}
+}
+
+func BenchmarkCache_Cached(b *testing.B) {
+	c := NewCache()
+	for i := 0; i < b.N; i++ {
+		_, _ = c.Get("c1", time.Minute, benchmarkFill)
+	}
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// instanceTraceMaxAge is how long an instance keeps referencing the trace of the last API request made against it.
+const instanceTraceMaxAge = 24 * time.Hour
+
+// Exemplar represents an OpenMetrics exemplar referencing a trace.
+type Exemplar struct {
+	TraceID   string
+	Timestamp time.Time
+}
+
+// ExemplarFromTraceParent returns an exemplar for the trace of a W3C "traceparent" header value.
+// It returns nil if the value is invalid or if the trace isn't sampled.
+func ExemplarFromTraceParent(traceParent string, timestamp time.Time) *Exemplar {
+	// Expect "<version>-<trace-id>-<parent-id>-<flags>".
+	fields := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(fields) < 4 || len(fields[0]) != 2 || len(fields[1]) != 32 || len(fields[2]) != 16 || len(fields[3]) != 2 {
+		return nil
+	}
+
+	// Version 00 has exactly four fields and ff is forbidden.
+	if fields[0] == "ff" || (fields[0] == "00" && len(fields) != 4) {
+		return nil
+	}
+
+	for _, field := range fields[:4] {
+		if strings.ToLower(field) != field {
+			return nil
+		}
+
+		_, err := hex.DecodeString(field)
+		if err != nil {
+			return nil
+		}
+	}
+
+	// All zero trace and parent IDs are invalid.
+	if strings.Trim(fields[1], "0") == "" || strings.Trim(fields[2], "0") == "" {
+		return nil
+	}
+
+	// Only reference traces which are being recorded.
+	flags, _ := strconv.ParseUint(fields[3], 16, 8)
+	if flags&0x01 == 0 {
+		return nil
+	}
+
+	return &Exemplar{TraceID: fields[1], Timestamp: timestamp}
+}
+
+// format returns the exemplar as it's appended to a sample with the given value.
+func (e *Exemplar) format(value float64) string {
+	out := fmt.Sprintf(`# {trace_id="%s"} %s`, e.TraceID, strconv.FormatFloat(value, 'g', -1, 64))
+	if !e.Timestamp.IsZero() {
+		out += " " + strconv.FormatFloat(float64(e.Timestamp.UnixMilli())/1000, 'f', 3, 64)
+	}
+
+	return out
+}
+
+// InstanceTraces keeps track of the latest traced API request made against each instance, so that the
+// counters of the instance can reference it.
+type InstanceTraces struct {
+	mu     sync.Mutex
+	traces map[string]Exemplar
+}
+
+// NewInstanceTraces returns a new empty InstanceTraces.
+func NewInstanceTraces() *InstanceTraces {
+	return &InstanceTraces{traces: map[string]Exemplar{}}
+}
+
+// Record sets the exemplar referencing the latest trace of the given instance.
+func (t *InstanceTraces) Record(projectName string, instanceName string, exemplar Exemplar) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Forget the traces of instances which haven't been acted upon for a while, including deleted ones.
+	for key, entry := range t.traces {
+		if exemplar.Timestamp.Sub(entry.Timestamp) > instanceTraceMaxAge {
+			delete(t.traces, key)
+		}
+	}
+
+	t.traces[projectName+"/"+instanceName] = exemplar
+}
+
+// Exemplar returns the exemplar referencing the latest trace of the instance identified by the project and
+// name labels of a sample, or nil if there is none.
+func (t *InstanceTraces) Exemplar(labels map[string]string) *Exemplar {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	exemplar, ok := t.traces[labels["project"]+"/"+labels["name"]]
+	if !ok || time.Since(exemplar.Timestamp) > instanceTraceMaxAge {
+		return nil
+	}
+
+	return &exemplar
+}
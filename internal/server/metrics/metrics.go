@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"fmt"
+	"maps"
 	"sort"
 	"strconv"
 	"strings"
@@ -86,7 +87,34 @@ func (m *MetricSet) Merge(metricSet *MetricSet) {
 	}
 }
 
+// Clone returns a deep copy of the MetricSet, so that merging it into another set doesn't affect the original.
+func (m *MetricSet) Clone() *MetricSet {
+	out := NewMetricSet(maps.Clone(m.labels))
+
+	for metricType, samples := range m.set {
+		cloned := make([]Sample, 0, len(samples))
+		for _, sample := range samples {
+			cloned = append(cloned, Sample{Value: sample.Value, Labels: maps.Clone(sample.Labels)})
+		}
+
+		out.set[metricType] = cloned
+	}
+
+	return out
+}
+
 func (m *MetricSet) String() string {
+	return m.format(false, nil)
+}
+
+// OpenMetrics returns the OpenMetrics representation of the set.
+// The exemplars function (if any) returns the exemplar to attach to a counter sample based on its labels.
+func (m *MetricSet) OpenMetrics(exemplars func(labels map[string]string) *Exemplar) string {
+	return m.format(true, exemplars)
+}
+
+// format renders the set in the Prometheus text format or in the OpenMetrics one.
+func (m *MetricSet) format(openMetrics bool, exemplars func(labels map[string]string) *Exemplar) string {
 	var out strings.Builder
 	metricTypes := []MetricType{}
 
@@ -100,25 +128,36 @@ func (m *MetricSet) String() string {
 	})
 
 	for _, metricType := range metricTypes {
-		// Add HELP message as specified by OpenMetrics
-		_, err := out.WriteString(MetricHeaders[metricType] + "\n")
-		if err != nil {
-			return ""
-		}
-
+		metricName := MetricNames[metricType]
 		metricTypeName := ""
 
 		// ProcsTotal is a gauge according to the OpenMetrics spec as its value can decrease.
-		if metricType == ProcsTotal || metricType == CPUs || metricType == GoGoroutines || metricType == GoHeapObjects {
+		if metricType == ProcsTotal || metricType == CPUs || metricType == GoGoroutines || metricType == GoHeapObjects || metricType == ClockOffsetSeconds {
 			metricTypeName = "gauge"
-		} else if strings.HasSuffix(MetricNames[metricType], "_total") || strings.HasSuffix(MetricNames[metricType], "_seconds") {
+		} else if strings.HasSuffix(metricName, "_total") || strings.HasSuffix(metricName, "_seconds") {
 			metricTypeName = "counter"
-		} else if strings.HasSuffix(MetricNames[metricType], "_bytes") {
+		} else if strings.HasSuffix(metricName, "_bytes") {
 			metricTypeName = "gauge"
 		}
 
+		// OpenMetrics names counter families without the "_total" suffix which all their samples must have.
+		familyName := metricName
+		if openMetrics && metricTypeName == "counter" {
+			if strings.HasSuffix(metricName, "_total") {
+				familyName = strings.TrimSuffix(metricName, "_total")
+			} else {
+				metricTypeName = "gauge"
+			}
+		}
+
+		// Add HELP message as specified by OpenMetrics
+		_, err := out.WriteString(strings.Replace(MetricHeaders[metricType], metricName, familyName, 1) + "\n")
+		if err != nil {
+			return ""
+		}
+
 		// Add TYPE message as specified by OpenMetrics
-		_, err = out.WriteString(fmt.Sprintf("# TYPE %s %s\n", MetricNames[metricType], metricTypeName))
+		_, err = out.WriteString(fmt.Sprintf("# TYPE %s %s\n", familyName, metricTypeName))
 		if err != nil {
 			return ""
 		}
@@ -146,10 +185,18 @@ func (m *MetricSet) String() string {
 
 			valueStr := strconv.FormatFloat(sample.Value, 'g', -1, 64)
 
+			// Reference the related trace on the counter samples.
+			if openMetrics && metricTypeName == "counter" && exemplars != nil {
+				exemplar := exemplars(sample.Labels)
+				if exemplar != nil {
+					valueStr += " " + exemplar.format(sample.Value)
+				}
+			}
+
 			if labels != "" {
-				_, err = out.WriteString(fmt.Sprintf("%s{%s} %s\n", MetricNames[metricType], labels, valueStr))
+				_, err = out.WriteString(fmt.Sprintf("%s{%s} %s\n", metricName, labels, valueStr))
 			} else {
-				_, err = out.WriteString(fmt.Sprintf("%s %s\n", MetricNames[metricType], valueStr))
+				_, err = out.WriteString(fmt.Sprintf("%s %s\n", metricName, valueStr))
 			}
 
 			if err != nil {
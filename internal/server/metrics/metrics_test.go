@@ -1,7 +1,10 @@
 package metrics
 
 import (
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -56,3 +59,117 @@ func TestMetricSet_FilterSamples(t *testing.T) {
 		require.Contains(t, hasKeys, "project")
 	}
 }
+
+func TestExemplarFromTraceParent(t *testing.T) {
+	now := time.Unix(1700000000, 123000000)
+
+	exemplar := ExemplarFromTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", now)
+	require.NotNil(t, exemplar)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", exemplar.TraceID)
+	require.Equal(t, now, exemplar.Timestamp)
+
+	// Future versions may add fields.
+	require.NotNil(t, ExemplarFromTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", now))
+
+	for _, traceParent := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",       // Not sampled.
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",       // Invalid trace ID.
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",       // Invalid parent ID.
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",       // Upper case.
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",        // Short trace ID.
+		"00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01",       // Not hexadecimal.
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", // Extra field in version 00.
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",       // Forbidden version.
+	} {
+		require.Nil(t, ExemplarFromTraceParent(traceParent, now), traceParent)
+	}
+}
+
+func TestMetricSet_OpenMetrics(t *testing.T) {
+	m := NewMetricSet(map[string]string{"project": "default", "name": "c1"})
+	m.AddSamples(CPUSecondsTotal, Sample{Value: 10.5, Labels: map[string]string{"cpu": "0", "mode": "user"}})
+	m.AddSamples(MemoryMemFreeBytes, Sample{Value: 1024})
+	m.AddSamples(ProcsTotal, Sample{Value: 5})
+	m.AddSamples(UptimeSeconds, Sample{Value: 60})
+
+	traced := func(labels map[string]string) *Exemplar {
+		if labels["name"] != "c1" {
+			return nil
+		}
+
+		return &Exemplar{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", Timestamp: time.Unix(1700000000, 123000000)}
+	}
+
+	// The plain output doesn't change.
+	plain := m.String()
+	require.NotContains(t, plain, "trace_id")
+	require.Contains(t, plain, "# TYPE incus_cpu_seconds_total counter\n")
+	require.Contains(t, plain, "# TYPE incus_uptime_seconds counter\n")
+
+	out := m.OpenMetrics(traced)
+
+	// Counters are described without the "_total" suffix and carry the exemplar.
+	require.Contains(t, out, "# HELP incus_cpu_seconds The total number of CPU time used in seconds.\n")
+	require.Contains(t, out, "# TYPE incus_cpu_seconds counter\n")
+	require.Contains(t, out, `incus_cpu_seconds_total{cpu="0",mode="user",name="c1",project="default"} 10.5 # {trace_id="4bf92f3577b34da6a3ce929d0e0e4736"} 10.5 1700000000.123`+"\n")
+
+	// Gauges and counters lacking the "_total" suffix don't.
+	require.Contains(t, out, "# TYPE incus_uptime_seconds gauge\n")
+	require.Contains(t, out, `incus_uptime_seconds{name="c1",project="default"} 60`+"\n")
+	require.Contains(t, out, "# TYPE incus_procs_total gauge\n")
+	require.Contains(t, out, `incus_procs_total{name="c1",project="default"} 5`+"\n")
+	require.Contains(t, out, `incus_memory_MemFree_bytes{name="c1",project="default"} 1024`+"\n")
+	require.True(t, strings.HasSuffix(out, "# EOF\n"))
+
+	// Validate the syntax of every sample line.
+	sampleRegex := regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*(\{[^{}]*\})? [^ #]+( # \{[a-z_]+="[^"]*"(,[a-z_]+="[^"]*")*\} [^ ]+( [0-9]+(\.[0-9]+)?)?)?$`)
+	exemplars := 0
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			continue
+		}
+
+		require.Regexp(t, sampleRegex, line)
+
+		if strings.Contains(line, " # {") {
+			exemplars++
+
+			// The exemplar label set is limited to 128 characters.
+			labelSet := line[strings.Index(line, " # {")+4 : strings.LastIndex(line, "}")]
+			require.LessOrEqual(t, len(strings.NewReplacer("=", "", `"`, "", ",", "").Replace(labelSet)), 128)
+		}
+	}
+
+	require.Equal(t, 1, exemplars)
+
+	// Without trace, the OpenMetrics output has no exemplar.
+	require.NotContains(t, m.OpenMetrics(nil), "trace_id")
+	require.NotContains(t, m.OpenMetrics(func(labels map[string]string) *Exemplar { return nil }), "trace_id")
+}
+
+func TestInstanceTraces(t *testing.T) {
+	traces := NewInstanceTraces()
+	now := time.Now()
+
+	// Nothing is referenced before a trace is recorded.
+	require.Nil(t, traces.Exemplar(map[string]string{"project": "default", "name": "c1"}))
+
+	traces.Record("default", "c1", Exemplar{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", Timestamp: now.Add(-instanceTraceMaxAge - time.Minute)})
+	traces.Record("default", "c2", Exemplar{TraceID: "0af7651916cd43dd8448eb211c80319c", Timestamp: now})
+
+	// Only the samples of the traced instance reference it.
+	exemplar := traces.Exemplar(map[string]string{"project": "default", "name": "c2", "cpu": "0"})
+	require.NotNil(t, exemplar)
+	require.Equal(t, "0af7651916cd43dd8448eb211c80319c", exemplar.TraceID)
+	require.Nil(t, traces.Exemplar(map[string]string{"project": "other", "name": "c2"}))
+	require.Nil(t, traces.Exemplar(map[string]string{"project": "default"}))
+
+	// Old traces are no longer referenced and get forgotten.
+	require.Nil(t, traces.Exemplar(map[string]string{"project": "default", "name": "c1"}))
+	require.NotContains(t, traces.traces, "default/c1")
+
+	// The latest trace of an instance replaces the previous one.
+	traces.Record("default", "c2", Exemplar{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", Timestamp: now})
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traces.Exemplar(map[string]string{"project": "default", "name": "c2"}).TraceID)
+}
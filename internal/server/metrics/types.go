@@ -144,12 +144,15 @@ const (
 	GoOtherSysBytes
 	// GoNextGCBytes represents the number of heap bytes when next garbage collection will take place.
 	GoNextGCBytes
+	// ClockOffsetSeconds represents the offset of the instance clock from the host clock in seconds.
+	ClockOffsetSeconds
 )
 
 // MetricNames associates a metric type to its name.
 var MetricNames = map[MetricType]string{
 	CPUSecondsTotal:             "incus_cpu_seconds_total",
 	CPUs:                        "incus_cpu_effective_total",
+	ClockOffsetSeconds:          "incus_clock_offset_seconds",
 	DiskReadBytesTotal:          "incus_disk_read_bytes_total",
 	DiskReadsCompletedTotal:     "incus_disk_reads_completed_total",
 	DiskWrittenBytesTotal:       "incus_disk_written_bytes_total",
@@ -218,6 +221,7 @@ var MetricNames = map[MetricType]string{
 var MetricHeaders = map[MetricType]string{
 	CPUSecondsTotal:             "# HELP incus_cpu_seconds_total The total number of CPU time used in seconds.",
 	CPUs:                        "# HELP incus_cpu_effective_total The total number of effective CPUs.",
+	ClockOffsetSeconds:          "# HELP incus_clock_offset_seconds The offset of the instance clock from the host clock in seconds.",
 	DiskReadBytesTotal:          "# HELP incus_disk_read_bytes_total The total number of bytes read.",
 	DiskReadsCompletedTotal:     "# HELP incus_disk_reads_completed_total The total number of completed reads.",
 	DiskWrittenBytesTotal:       "# HELP incus_disk_written_bytes_total The total number of bytes written.",
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"slices"
+	"strconv"
 
 	"github.com/lxc/incus/v6/internal/migration"
 	backupConfig "github.com/lxc/incus/v6/internal/server/backup/config"
@@ -260,6 +261,14 @@ func progressWrapperRender(op *operations.Operation, key string, description str
 
 	if meta[key] != progress {
 		meta[key] = progress
+
+		// Structured progress data for API callers.
+		meta["progress"] = map[string]string{
+			"stage":     key,
+			"processed": strconv.FormatInt(progressInt, 10),
+			"speed":     strconv.FormatInt(speedInt, 10),
+		}
+
 		_ = op.UpdateMetadata(meta)
 	}
 }
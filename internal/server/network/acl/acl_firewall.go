@@ -3,6 +3,7 @@ package acl
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/lxc/incus/v6/internal/server/db"
 	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
@@ -23,12 +24,32 @@ func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName
 	return s.Firewall.NetworkApplyACLRules(aclNet.Name, rules)
 }
 
+// aclFirewallRule is a network firewall ACL rule along with the ACL rule it was generated from.
+type aclFirewallRule struct {
+	aclRule      api.NetworkACLRule
+	firewallRule firewallDrivers.ACLRule
+}
+
+// firewallSortACLRules returns the firewall ACL rules in evaluation order. Rules with a higher priority come
+// first, then rules with the same priority are ordered by action (drop, reject, allow and allow-stateless).
+// Otherwise the rules keep their order.
+func firewallSortACLRules(rules []aclFirewallRule) []firewallDrivers.ACLRule {
+	sorted := slices.Clone(rules)
+	slices.SortStableFunc(sorted, func(a aclFirewallRule, b aclFirewallRule) int {
+		return compareRules(a.aclRule, b.aclRule)
+	})
+
+	firewallRules := make([]firewallDrivers.ACLRule, 0, len(sorted))
+	for _, rule := range sorted {
+		firewallRules = append(firewallRules, rule.firewallRule)
+	}
+
+	return firewallRules
+}
+
 // FirewallACLRules returns ACL rules for network firewall.
 func FirewallACLRules(s *state.State, aclDeviceName string, aclProjectName string, config map[string]string) ([]firewallDrivers.ACLRule, error) {
-	var dropRules []firewallDrivers.ACLRule
-	var rejectRules []firewallDrivers.ACLRule
-	var allowRules []firewallDrivers.ACLRule
-	var allowStatelessRules []firewallDrivers.ACLRule
+	var aclRules []aclFirewallRule
 
 	// convertACLRules converts the ACL rules to Firewall ACL rules.
 	convertACLRules := func(direction string, logPrefix string, rules ...api.NetworkACLRule) error {
@@ -37,6 +58,11 @@ func FirewallACLRules(s *state.State, aclDeviceName string, aclProjectName strin
 				continue
 			}
 
+			// TODO: add NOTRACK support for allow-stateless.
+			if !slices.Contains(ruleActionOrder, rule.Action) {
+				return fmt.Errorf("Unrecognised action %q", rule.Action)
+			}
+
 			firewallACLRule := firewallDrivers.ACLRule{
 				Direction:       direction,
 				Action:          rule.Action,
@@ -55,18 +81,7 @@ func FirewallACLRules(s *state.State, aclDeviceName string, aclProjectName strin
 				firewallACLRule.LogName = fmt.Sprintf("%s-%s-%d", logPrefix, direction, ruleIndex)
 			}
 
-			switch {
-			case rule.Action == "drop":
-				dropRules = append(dropRules, firewallACLRule)
-			case rule.Action == "reject":
-				rejectRules = append(rejectRules, firewallACLRule)
-			case rule.Action == "allow":
-				allowRules = append(allowRules, firewallACLRule)
-			case rule.Action == "allow-stateless": // TODO: add NOTRACK support
-				allowStatelessRules = append(allowStatelessRules, firewallACLRule)
-			default:
-				return fmt.Errorf("Unrecognised action %q", rule.Action)
-			}
+			aclRules = append(aclRules, aclFirewallRule{aclRule: rule, firewallRule: firewallACLRule})
 		}
 
 		return nil
@@ -100,11 +115,7 @@ func FirewallACLRules(s *state.State, aclDeviceName string, aclProjectName strin
 		}
 	}
 
-	var rules []firewallDrivers.ACLRule
-	rules = append(rules, dropRules...)
-	rules = append(rules, rejectRules...)
-	rules = append(rules, allowRules...)
-	rules = append(rules, allowStatelessRules...)
+	rules := firewallSortACLRules(aclRules)
 
 	// Add the automatic default ACL rule for the network.
 	egressAction, egressLogged := firewallACLDefaults(config, "egress")
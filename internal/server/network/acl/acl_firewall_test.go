@@ -0,0 +1,47 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	firewallDrivers "github.com/lxc/incus/v6/internal/server/firewall/drivers"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Test that firewall rules are generated by priority and then by action.
+func TestFirewallSortACLRules(t *testing.T) {
+	rule := func(action string, priority int, destination string) aclFirewallRule {
+		return aclFirewallRule{
+			aclRule:      api.NetworkACLRule{Action: action, Priority: priority, Destination: destination},
+			firewallRule: firewallDrivers.ACLRule{Action: action, Destination: destination},
+		}
+	}
+
+	rules := []aclFirewallRule{
+		rule("allow", 0, "10.0.0.1"),
+		rule("drop", 0, "10.0.0.2"),
+		rule("allow-stateless", 0, "10.0.0.3"),
+		rule("allow", 10, "10.0.0.4"),
+		rule("reject", 0, "10.0.0.5"),
+		rule("drop", 5, "10.0.0.6"),
+		rule("allow", 10, "10.0.0.7"),
+		rule("drop", 0, "10.0.0.8"),
+	}
+
+	var destinations []string
+	for _, rule := range firewallSortACLRules(rules) {
+		destinations = append(destinations, rule.Destination)
+	}
+
+	assert.Equal(t, []string{
+		"10.0.0.4", // allow, priority 10.
+		"10.0.0.7", // allow, priority 10.
+		"10.0.0.6", // drop, priority 5.
+		"10.0.0.2", // drop.
+		"10.0.0.8", // drop.
+		"10.0.0.5", // reject.
+		"10.0.0.1", // allow.
+		"10.0.0.3", // allow-stateless.
+	}, destinations)
+}
@@ -38,6 +38,11 @@ const (
 	ovnACLPriorityPortGroupDrop          = 500
 )
 
+// ovnACLPriorityRuleStep is added to the priorities of port group rules for each level of ACL rule priority.
+// It needs to be higher than the range used by the action based priorities (including the 10 OVN adds to reject
+// rules) so that a rule with a higher priority is always tested first, whatever its action.
+const ovnACLPriorityRuleStep = 300
+
 // ovnACLPortGroupPrefix prefix used when naming ACL related port groups in OVN.
 const ovnACLPortGroupPrefix = "incus_acl"
 
@@ -522,6 +527,9 @@ func ovnRuleCriteriaToOVNACLRule(s *state.State, direction string, rule *api.Net
 		portGroupRule.Priority = ovnACLPriorityPortGroupDrop
 	}
 
+	// Move the rule above all the rules with a lower priority.
+	portGroupRule.Priority += rule.Priority * ovnACLPriorityRuleStep
+
 	var matchParts []string
 
 	// Add directional port filter so we only apply this rule to the ports in the port group.
@@ -0,0 +1,49 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Test that OVN rule priorities follow the rule priority first and the action second.
+func TestOVNRuleCriteriaToOVNACLRulePriority(t *testing.T) {
+	// Rules in expected evaluation order.
+	rules := []api.NetworkACLRule{
+		{Action: "drop", Priority: 2},
+		{Action: "allow-stateless", Priority: 2},
+		{Action: "reject", Priority: 1},
+		{Action: "allow", Priority: 1},
+		{Action: "drop"},
+		{Action: "reject"},
+		{Action: "allow"},
+	}
+
+	var priorities []int
+	for _, rule := range rules {
+		ovnRule, _, _, err := ovnRuleCriteriaToOVNACLRule(nil, "ingress", &rule, "incus_acl1", nil, nil)
+		require.NoError(t, err)
+
+		priority := ovnRule.Priority
+		if ovnRule.Action == "reject" {
+			priority += 10 // OVN raises the priority of reject rules.
+		}
+
+		priorities = append(priorities, priority)
+	}
+
+	for i := 1; i < len(priorities); i++ {
+		assert.Greater(t, priorities[i-1], priorities[i], "Rule %d must be evaluated before rule %d", i-1, i)
+	}
+
+	// The lowest rule priority keeps the action based OVN priorities.
+	assert.Equal(t, ovnACLPriorityPortGroupDrop, priorities[4])
+
+	// Stateless rules share the priority of stateful ones.
+	ovnRule, _, _, err := ovnRuleCriteriaToOVNACLRule(nil, "ingress", &api.NetworkACLRule{Action: "allow-stateless"}, "incus_acl1", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ovnACLPriorityPortGroupAllow, ovnRule.Priority)
+}
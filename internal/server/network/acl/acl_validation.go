@@ -2,11 +2,19 @@ package acl
 
 import (
 	"fmt"
+	"slices"
 
+	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/util"
 	"github.com/lxc/incus/v6/shared/validate"
 )
 
+// icmpErrorTypes lists the ICMP message types reporting errors about other packets, per ICMP protocol.
+var icmpErrorTypes = map[string][]string{
+	"icmp4": {"3", "4", "5", "11", "12"},
+	"icmp6": {"1", "2", "3", "4"},
+}
+
 // ValidName checks the ACL name is valid.
 func ValidName(name string) error {
 	if name == "" {
@@ -27,3 +35,45 @@ func ValidName(name string) error {
 
 	return nil
 }
+
+// validateStatelessRule checks that an allow-stateless rule doesn't rely on connection tracking.
+// Stateless rules allow traffic regardless of any existing connection, so they can't be used to allow the ICMP
+// error messages which are otherwise only accepted when related to a tracked connection.
+func validateStatelessRule(rule api.NetworkACLRule) error {
+	if rule.Action != "allow-stateless" {
+		return nil
+	}
+
+	errorTypes, ok := icmpErrorTypes[rule.Protocol]
+	if !ok {
+		return nil
+	}
+
+	if rule.ICMPType == "" {
+		return fmt.Errorf("Stateless %q rules must specify an ICMP type as error messages can't be allowed without connection tracking", rule.Protocol)
+	}
+
+	if slices.Contains(errorTypes, rule.ICMPType) {
+		return fmt.Errorf("Stateless %q rules cannot allow ICMP error messages (type %s) as those rely on connection tracking", rule.Protocol, rule.ICMPType)
+	}
+
+	return nil
+}
+
+// statelessRuleExists checks whether the rule is already in the list, ignoring its state and description.
+// This allows existing rules which predate the stateless rule validation to be kept.
+func statelessRuleExists(rules []api.NetworkACLRule, rule api.NetworkACLRule) bool {
+	rule.State = ""
+	rule.Description = ""
+
+	for _, r := range rules {
+		r.State = ""
+		r.Description = ""
+
+		if r == rule {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,44 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestValidateStatelessRule(t *testing.T) {
+	valid := []api.NetworkACLRule{
+		{Action: "allow", Protocol: "icmp4"},
+		{Action: "allow-stateless"},
+		{Action: "allow-stateless", Protocol: "udp", DestinationPort: "53"},
+		{Action: "allow-stateless", Protocol: "icmp4", ICMPType: "8"},
+		{Action: "allow-stateless", Protocol: "icmp6", ICMPType: "128"},
+	}
+
+	for _, rule := range valid {
+		assert.NoError(t, validateStatelessRule(rule), rule)
+	}
+
+	invalid := []api.NetworkACLRule{
+		{Action: "allow-stateless", Protocol: "icmp4"},
+		{Action: "allow-stateless", Protocol: "icmp4", ICMPType: "3"},
+		{Action: "allow-stateless", Protocol: "icmp6", ICMPType: "2"},
+	}
+
+	for _, rule := range invalid {
+		assert.Error(t, validateStatelessRule(rule), rule)
+	}
+}
+
+func TestStatelessRuleExists(t *testing.T) {
+	rules := []api.NetworkACLRule{
+		{Action: "allow-stateless", Protocol: "icmp4", State: "enabled"},
+	}
+
+	assert.True(t, statelessRuleExists(rules, api.NetworkACLRule{Action: "allow-stateless", Protocol: "icmp4", State: "enabled"}))
+	assert.True(t, statelessRuleExists(rules, api.NetworkACLRule{Action: "allow-stateless", Protocol: "icmp4", State: "logged", Description: "Ping"}))
+	assert.False(t, statelessRuleExists(rules, api.NetworkACLRule{Action: "allow-stateless", Protocol: "icmp6", State: "enabled"}))
+	assert.False(t, statelessRuleExists(nil, rules[0]))
+}
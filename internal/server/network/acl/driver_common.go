@@ -2,6 +2,7 @@ package acl
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -59,6 +60,21 @@ var (
 // ValidActions defines valid actions for rules.
 var ValidActions = []string{"allow", "allow-stateless", "drop", "reject"}
 
+// ruleActionOrder defines the order in which rules with the same priority are evaluated, based on their action.
+var ruleActionOrder = []string{"drop", "reject", "allow", "allow-stateless"}
+
+// ruleMaxPriority is the highest priority a rule can have.
+const ruleMaxPriority = 100
+
+// compareRules compares two rules by evaluation order, highest priority first and then by action.
+func compareRules(a api.NetworkACLRule, b api.NetworkACLRule) int {
+	if a.Priority != b.Priority {
+		return cmp.Compare(b.Priority, a.Priority)
+	}
+
+	return cmp.Compare(slices.Index(ruleActionOrder, a.Action), slices.Index(ruleActionOrder, b.Action))
+}
+
 // common represents a Network ACL.
 type common struct {
 	logger      logger.Logger
@@ -232,6 +248,14 @@ func (d *common) validateConfig(info *api.NetworkACLPut) error {
 			return fmt.Errorf("Invalid ingress rule %d: %w", i, err)
 		}
 
+		// Only check stateless rules being added so that existing ACLs can still be updated.
+		if !statelessRuleExists(d.info.Ingress, ingressRule) {
+			err = validateStatelessRule(ingressRule)
+			if err != nil {
+				return fmt.Errorf("Invalid ingress rule %d: %w", i, err)
+			}
+		}
+
 		// Check for duplicates.
 		for ri, r := range info.Ingress {
 			if ri == i {
@@ -251,6 +275,14 @@ func (d *common) validateConfig(info *api.NetworkACLPut) error {
 			return fmt.Errorf("Invalid egress rule %d: %w", i, err)
 		}
 
+		// Only check stateless rules being added so that existing ACLs can still be updated.
+		if !statelessRuleExists(d.info.Egress, egressRule) {
+			err = validateStatelessRule(egressRule)
+			if err != nil {
+				return fmt.Errorf("Invalid egress rule %d: %w", i, err)
+			}
+		}
+
 		// Check for duplicates.
 		for ri, r := range info.Egress {
 			if ri == i {
@@ -310,6 +342,11 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 		return fmt.Errorf("State must be one of: %s", strings.Join(validStates, ", "))
 	}
 
+	// Validate Priority field.
+	if rule.Priority < 0 || rule.Priority > ruleMaxPriority {
+		return fmt.Errorf("Priority must be between 0 and %d", ruleMaxPriority)
+	}
+
 	var acls map[string]int64
 
 	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
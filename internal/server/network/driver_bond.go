@@ -0,0 +1,450 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/internal/server/cluster/request"
+	"github.com/lxc/incus/v6/internal/server/db"
+	"github.com/lxc/incus/v6/internal/server/ip"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/revert"
+	"github.com/lxc/incus/v6/shared/util"
+	"github.com/lxc/incus/v6/shared/validate"
+)
+
+// bondRecreateKeys are the config keys which require the bond interface to be recreated when changed.
+// Changes to bond.members are applied to the running bond.
+var bondRecreateKeys = []string{"bond.mode", "bond.lacp_rate", "bond.mii_frequency"}
+
+// bond represents a bond network.
+type bond struct {
+	common
+}
+
+// DBType returns the network type DB ID.
+func (n *bond) DBType() db.NetworkType {
+	return db.NetworkTypeBond
+}
+
+// ValidateName validates network name.
+func (n *bond) ValidateName(name string) error {
+	err := validate.IsInterfaceName(name)
+	if err != nil {
+		return err
+	}
+
+	// Apply common name validation that applies to all network types.
+	return n.common.ValidateName(name)
+}
+
+// Validate network config.
+func (n *bond) Validate(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// gendoc:generate(entity=network_bond, group=common, key=bond.members)
+		//
+		// ---
+		// type: string
+		// condition: -
+		// shortdesc: Comma-separated list of existing interfaces to add to the bond
+		"bond.members": validate.Required(validate.IsNotEmpty, validate.IsListOf(validate.IsInterfaceName)),
+
+		// gendoc:generate(entity=network_bond, group=common, key=bond.mode)
+		//
+		// ---
+		// type: string
+		// condition: -
+		// defaultdesc: `active-backup`
+		// shortdesc: Bonding mode (`active-backup`, `802.3ad` or `balance-xor`)
+		"bond.mode": validate.Optional(validate.IsOneOf("active-backup", "802.3ad", "balance-xor")),
+
+		// gendoc:generate(entity=network_bond, group=common, key=bond.lacp_rate)
+		//
+		// ---
+		// type: string
+		// condition: `802.3ad` mode
+		// defaultdesc: `slow`
+		// shortdesc: Rate at which LACPDUs are requested from the link partner (`slow` or `fast`)
+		"bond.lacp_rate": validate.Optional(validate.IsOneOf("slow", "fast")),
+
+		// gendoc:generate(entity=network_bond, group=common, key=bond.mii_frequency)
+		//
+		// ---
+		// type: integer
+		// condition: -
+		// defaultdesc: `100`
+		// shortdesc: How often to check the link state of the members (in milliseconds)
+		"bond.mii_frequency": validate.Optional(validate.IsUint32),
+
+		// gendoc:generate(entity=network_bond, group=common, key=mtu)
+		//
+		// ---
+		// type: integer
+		// condition: -
+		// shortdesc: The MTU of the bond interface
+		"mtu": validate.Optional(validate.IsNetworkMTU),
+
+		"volatile.last_state.created": validate.Optional(validate.IsBool),
+	}
+
+	// gendoc:generate(entity=network_bond, group=common, key=user.*)
+	//
+	// ---
+	// type: string
+	// shortdesc: User-provided free-form key/value pairs
+
+	err := n.validate(config, rules)
+	if err != nil {
+		return err
+	}
+
+	if config["bond.lacp_rate"] != "" && config["bond.mode"] != "802.3ad" {
+		return fmt.Errorf("The bond.lacp_rate option can only be set when bond.mode is 802.3ad")
+	}
+
+	members := util.SplitNTrimSpace(config["bond.members"], ",", -1, true)
+	for i, member := range members {
+		if member == n.name {
+			return fmt.Errorf("Bond %q cannot be a member of itself", n.name)
+		}
+
+		if slices.Contains(members[i+1:], member) {
+			return fmt.Errorf("Bond member %q is specified more than once", member)
+		}
+	}
+
+	return nil
+}
+
+// bondMemberMaster returns the name of the interface the member is currently enslaved to (if any).
+func bondMemberMaster(member string) string {
+	target, err := os.Readlink(fmt.Sprintf("/sys/class/net/%s/master", member))
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Base(target)
+}
+
+// bondMembers returns the members currently enslaved to the bond interface.
+func bondMembers(bondName string) []string {
+	content, err := os.ReadFile(fmt.Sprintf("/sys/class/net/%s/bonding/slaves", bondName))
+	if err != nil {
+		return nil
+	}
+
+	return strings.Fields(string(content))
+}
+
+// bondRemovedMembers returns the enslaved interfaces which were members of the bond in the old config but aren't
+// anymore in the new one.
+func bondRemovedMembers(oldConfig map[string]string, newConfig map[string]string, enslaved []string) []string {
+	oldMembers := util.SplitNTrimSpace(oldConfig["bond.members"], ",", -1, true)
+	newMembers := util.SplitNTrimSpace(newConfig["bond.members"], ",", -1, true)
+
+	var removed []string
+	for _, member := range enslaved {
+		if slices.Contains(oldMembers, member) && !slices.Contains(newMembers, member) {
+			removed = append(removed, member)
+		}
+	}
+
+	return removed
+}
+
+// Delete deletes a network.
+func (n *bond) Delete(clientType request.ClientType) error {
+	n.logger.Debug("Delete", logger.Ctx{"clientType": clientType})
+
+	err := n.Stop()
+	if err != nil {
+		return err
+	}
+
+	return n.common.delete(clientType)
+}
+
+// Rename renames a network.
+func (n *bond) Rename(newName string) error {
+	n.logger.Debug("Rename", logger.Ctx{"newName": newName})
+
+	if InterfaceExists(newName) {
+		return fmt.Errorf("Network interface %q already exists", newName)
+	}
+
+	// Bring the bond down, releasing its members.
+	running := InterfaceExists(n.name)
+	if running {
+		err := n.Stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Rename common steps.
+	err := n.common.rename(newName)
+	if err != nil {
+		return err
+	}
+
+	// Bring the bond back up under its new name.
+	if running {
+		err = n.Start()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start creates the bond interface and adds its members.
+func (n *bond) Start() error {
+	n.logger.Debug("Start")
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	reverter.Add(func() { n.setUnavailable() })
+
+	err := n.setup()
+	if err != nil {
+		return err
+	}
+
+	reverter.Success()
+
+	// Ensure network is marked as available now its started.
+	n.setAvailable()
+
+	return nil
+}
+
+func (n *bond) setup() error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// Check the members exist and aren't already used by another interface.
+	members := util.SplitNTrimSpace(n.config["bond.members"], ",", -1, true)
+	for _, member := range members {
+		if !InterfaceExists(member) {
+			return fmt.Errorf("Bond member %q not found", member)
+		}
+
+		master := bondMemberMaster(member)
+		if master != "" && master != n.name {
+			return fmt.Errorf("Bond member %q is already enslaved to %q", member, master)
+		}
+	}
+
+	// Create the bond interface.
+	created := false
+	if InterfaceExists(n.name) {
+		if !util.PathExists(fmt.Sprintf("/sys/class/net/%s/bonding", n.name)) {
+			return fmt.Errorf("Existing interface %q isn't a bond", n.name)
+		}
+	} else {
+		miiFrequency := n.config["bond.mii_frequency"]
+		if miiFrequency == "" {
+			miiFrequency = "100"
+		}
+
+		bondLink := &ip.Bond{
+			Link:         ip.Link{Name: n.name},
+			Mode:         n.config["bond.mode"],
+			LACPRate:     n.config["bond.lacp_rate"],
+			MIIFrequency: miiFrequency,
+		}
+
+		if bondLink.Mode == "" {
+			bondLink.Mode = "active-backup"
+		}
+
+		err := bondLink.Add()
+		if err != nil {
+			return fmt.Errorf("Failed creating bond %q: %w", n.name, err)
+		}
+
+		created = true
+		reverter.Add(func() { _ = InterfaceRemove(n.name) })
+	}
+
+	// Set the MTU.
+	bondLink := &ip.Link{Name: n.name}
+	if n.config["mtu"] != "" {
+		mtu, err := strconv.ParseUint(n.config["mtu"], 10, 32)
+		if err != nil {
+			return fmt.Errorf("Invalid MTU %q: %w", n.config["mtu"], err)
+		}
+
+		err = bondLink.SetMTU(uint32(mtu))
+		if err != nil {
+			return fmt.Errorf("Failed setting MTU %q on %q: %w", n.config["mtu"], bondLink.Name, err)
+		}
+	}
+
+	// Add the members, which must be down to be enslaved.
+	for _, member := range members {
+		if bondMemberMaster(member) == n.name {
+			continue
+		}
+
+		memberLink := &ip.Link{Name: member}
+		err := memberLink.SetDown()
+		if err != nil {
+			return fmt.Errorf("Failed bringing down bond member %q: %w", member, err)
+		}
+
+		err = memberLink.SetMaster(n.name)
+		if err != nil {
+			return fmt.Errorf("Failed adding member %q to bond %q: %w", member, n.name, err)
+		}
+
+		reverter.Add(func() { _ = memberLink.SetNoMaster() })
+	}
+
+	err := bondLink.SetUp()
+	if err != nil {
+		return fmt.Errorf("Failed bringing up bond %q: %w", n.name, err)
+	}
+
+	// Record if we created this device or not (if we have not already recorded that we created it previously),
+	// so it can be removed on stop. This way we won't overwrite the setting on daemon restart.
+	if util.IsFalseOrEmpty(n.config["volatile.last_state.created"]) {
+		n.config["volatile.last_state.created"] = fmt.Sprintf("%t", created)
+		err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateNetwork(ctx, n.project, n.name, n.description, n.config)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed saving volatile config: %w", err)
+		}
+	}
+
+	reverter.Success()
+	return nil
+}
+
+// Stop releases the bond members and removes the bond interface if it was created by us.
+func (n *bond) Stop() error {
+	n.logger.Debug("Stop")
+
+	if util.IsTrue(n.config["volatile.last_state.created"]) && InterfaceExists(n.name) {
+		for _, member := range bondMembers(n.name) {
+			memberLink := &ip.Link{Name: member}
+			err := memberLink.SetNoMaster()
+			if err != nil {
+				return fmt.Errorf("Failed removing member %q from bond %q: %w", member, n.name, err)
+			}
+		}
+
+		err := InterfaceRemove(n.name)
+		if err != nil {
+			return fmt.Errorf("Failed removing bond %q: %w", n.name, err)
+		}
+	}
+
+	// Remove last state config.
+	delete(n.config, "volatile.last_state.created")
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateNetwork(ctx, n.project, n.name, n.description, n.config)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed removing volatile config: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates the network. Accepts notification boolean indicating if this update request is coming from a
+// cluster notification, in which case do not update the database, just apply local changes needed.
+func (n *bond) Update(newNetwork api.NetworkPut, targetNode string, clientType request.ClientType) error {
+	n.logger.Debug("Update", logger.Ctx{"clientType": clientType, "newNetwork": newNetwork})
+
+	dbUpdateNeeded, changedKeys, oldNetwork, err := n.common.configChanged(newNetwork)
+	if err != nil {
+		return err
+	}
+
+	if !dbUpdateNeeded {
+		return nil // Nothing changed.
+	}
+
+	// If the network as a whole has not had any previous creation attempts, or the node itself is still
+	// pending, then don't apply the new settings to the node, just to the database record (ready for the
+	// actual global create request to be initiated).
+	if n.Status() == api.NetworkStatusPending || n.LocalStatus() == api.NetworkStatusPending {
+		return n.common.update(newNetwork, targetNode, clientType)
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	recreate := false
+	for _, key := range bondRecreateKeys {
+		if slices.Contains(changedKeys, key) {
+			recreate = true
+			break
+		}
+	}
+
+	// We only need to check in the database once, not on every clustered node.
+	if clientType == request.ClientTypeNormal && recreate {
+		isUsed, err := n.IsUsed(false)
+		if isUsed || err != nil {
+			return fmt.Errorf("Cannot update bond configuration when in use")
+		}
+	}
+
+	if recreate {
+		err = n.Stop()
+		if err != nil {
+			return err
+		}
+
+		// Remove the volatile last state from submitted new config if present.
+		delete(newNetwork.Config, "volatile.last_state.created")
+	}
+
+	// Define a function which reverts everything.
+	reverter.Add(func() {
+		// Reset changes to all nodes and database.
+		_ = n.common.update(oldNetwork, targetNode, clientType)
+	})
+
+	// Apply changes to all nodes and database.
+	err = n.common.update(newNetwork, targetNode, clientType)
+	if err != nil {
+		return err
+	}
+
+	// Release the members removed from the running bond, setup adds the new ones.
+	if !recreate {
+		for _, member := range bondRemovedMembers(oldNetwork.Config, newNetwork.Config, bondMembers(n.name)) {
+			memberLink := &ip.Link{Name: member}
+			err = memberLink.SetNoMaster()
+			if err != nil {
+				return fmt.Errorf("Failed removing member %q from bond %q: %w", member, n.name, err)
+			}
+
+			reverter.Add(func() {
+				_ = memberLink.SetDown()
+				_ = memberLink.SetMaster(n.name)
+			})
+		}
+	}
+
+	err = n.setup()
+	if err != nil {
+		return err
+	}
+
+	reverter.Success()
+
+	return nil
+}
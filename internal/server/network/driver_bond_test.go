@@ -0,0 +1,23 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_bondRemovedMembers(t *testing.T) {
+	oldConfig := map[string]string{"bond.members": "eth0,eth1,eth2"}
+
+	// Members dropped from the config are released.
+	assert.Equal(t, []string{"eth1"}, bondRemovedMembers(oldConfig, map[string]string{"bond.members": "eth0,eth2,eth3"}, []string{"eth0", "eth1", "eth2"}))
+
+	// Members which aren't enslaved anymore are skipped.
+	assert.Empty(t, bondRemovedMembers(oldConfig, map[string]string{"bond.members": "eth0"}, []string{"eth0"}))
+
+	// Interfaces added to the bond outside of its config are left alone.
+	assert.Empty(t, bondRemovedMembers(oldConfig, map[string]string{"bond.members": "eth0,eth1,eth2"}, []string{"eth0", "eth1", "eth2", "eth9"}))
+
+	// Unchanged members.
+	assert.Empty(t, bondRemovedMembers(oldConfig, oldConfig, []string{"eth0", "eth1", "eth2"}))
+}
@@ -1,10 +1,12 @@
 package network
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io/fs"
+	"math"
 	"net"
 	"net/http"
 	"os"
@@ -12,6 +14,8 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mdlayher/netx/eui64"
@@ -60,6 +64,7 @@ func (n *bridge) DBType() db.NetworkType {
 func (n *bridge) Info() Info {
 	info := n.common.Info()
 	info.AddressForwards = true
+	info.DHCPReservations = true
 
 	return info
 }
@@ -215,6 +220,33 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Bridge MTU (default varies if tunnel in use)
 		"bridge.mtu": validate.Optional(validate.IsNetworkMTU),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=conntrack.max)
+		//
+		// ---
+		//  type: integer
+		//  condition: -
+		//  default: -
+		//  shortdesc: Maximum number of new connections tracked from the network, further new connections are dropped
+		"conntrack.max": validate.Optional(validate.IsInRange(1, math.MaxUint32)),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=conntrack.timeout.tcp)
+		//
+		// ---
+		//  type: integer
+		//  condition: `nftables` firewall
+		//  default: -
+		//  shortdesc: Timeout in seconds for established TCP connections from the network (kernel default if unset)
+		"conntrack.timeout.tcp": validate.Optional(validate.IsInRange(1, math.MaxUint32)),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=conntrack.timeout.udp)
+		//
+		// ---
+		//  type: integer
+		//  condition: `nftables` firewall
+		//  default: -
+		//  shortdesc: Timeout in seconds for UDP streams from the network (kernel default if unset)
+		"conntrack.timeout.udp": validate.Optional(validate.IsInRange(1, math.MaxUint32)),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.address)
 		//
 		// ---
@@ -748,6 +780,11 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
+	// Per-network connection tracking timeouts require nftables.
+	if (config["conntrack.timeout.tcp"] != "" || config["conntrack.timeout.udp"] != "") && n.state.Firewall.String() != "nftables" {
+		return fmt.Errorf("Connection tracking timeouts require the nftables firewall driver")
+	}
+
 	// Check using same MAC address on every cluster node is safe.
 	if config["bridge.hwaddr"] != "" {
 		err = n.checkClusterWideMACSafe(config)
@@ -1240,7 +1277,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		fwClearIPVersions = append(fwClearIPVersions, 6)
 	}
 
-	if len(fwClearIPVersions) > 0 {
+	if len(fwClearIPVersions) > 0 || usesConntrackLimits(n.config) || usesConntrackLimits(oldConfig) {
 		n.logger.Debug("Clearing firewall")
 		err = n.state.Firewall.NetworkClear(n.name, false, fwClearIPVersions)
 		if err != nil {
@@ -1263,6 +1300,35 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		fwOpts.ACL = true
 	}
 
+	if usesConntrackLimits(n.config) {
+		fwOpts.Conntrack = &firewallDrivers.ConntrackOpts{}
+
+		if n.config["conntrack.max"] != "" {
+			fwOpts.Conntrack.Max, err = strconv.ParseUint(n.config["conntrack.max"], 10, 32)
+			if err != nil {
+				return fmt.Errorf("Invalid conntrack.max value: %w", err)
+			}
+		}
+
+		if n.config["conntrack.timeout.tcp"] != "" {
+			fwOpts.Conntrack.TimeoutTCP, err = strconv.ParseUint(n.config["conntrack.timeout.tcp"], 10, 32)
+			if err != nil {
+				return fmt.Errorf("Invalid conntrack.timeout.tcp value: %w", err)
+			}
+		}
+
+		if n.config["conntrack.timeout.udp"] != "" {
+			fwOpts.Conntrack.TimeoutUDP, err = strconv.ParseUint(n.config["conntrack.timeout.udp"], 10, 32)
+			if err != nil {
+				return fmt.Errorf("Invalid conntrack.timeout.udp value: %w", err)
+			}
+		}
+
+		n.conntrackHostMaxWarning(fwOpts.Conntrack.Max)
+	} else {
+		n.conntrackHostMaxWarning(0)
+	}
+
 	// Snapshot container specific IPv4 routes (added with boot proto) before removing IPv4 addresses.
 	// This is because the kernel removes any static routes on an interface when all addresses removed.
 	ctRoutes, err := n.bootRoutesV4()
@@ -2017,7 +2083,7 @@ func (n *bridge) Stop() error {
 		fwClearIPVersions = append(fwClearIPVersions, 6)
 	}
 
-	if len(fwClearIPVersions) > 0 {
+	if len(fwClearIPVersions) > 0 || usesConntrackLimits(n.config) {
 		n.logger.Debug("Deleting firewall")
 		err := n.state.Firewall.NetworkClear(n.name, true, fwClearIPVersions)
 		if err != nil {
@@ -2025,6 +2091,12 @@ func (n *bridge) Stop() error {
 		}
 	}
 
+	// Stop the HTTP(S) forward listeners.
+	err = forwardProxiesRemove(n.id)
+	if err != nil {
+		return err
+	}
+
 	// Kill any existing dnsmasq daemon for this network
 	err = dnsmasq.Kill(n.name, false)
 	if err != nil {
@@ -2040,6 +2112,65 @@ func (n *bridge) Stop() error {
 	return nil
 }
 
+// conntrackHostMaxWarning raises a warning when the connection tracking limit of the network can't be reached
+// due to the host table being smaller, and resolves it otherwise.
+func (n *bridge) conntrackHostMaxWarning(limit uint64) {
+	hostMax, err := conntrackHostMax()
+	if err == nil && limit > hostMax {
+		msg := fmt.Sprintf("Network limit of %d exceeds the host table size of %d", limit, hostMax)
+		n.logger.Warn("Network connection tracking limit exceeds the host table size", logger.Ctx{"max": limit, "hostMax": hostMax})
+
+		err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarningLocalNode(ctx, n.project, dbCluster.TypeNetwork, int(n.id), warningtype.NetworkConntrackLimitAboveHostMax, msg)
+		})
+		if err != nil {
+			n.logger.Warn("Failed to create warning", logger.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(n.state.DB.Cluster, n.project, warningtype.NetworkConntrackLimitAboveHostMax, dbCluster.TypeNetwork, int(n.id))
+	if err != nil {
+		n.logger.Warn("Failed to resolve warning", logger.Ctx{"err": err})
+	}
+}
+
+// State returns the network state, including connection tracking usage when limits are configured.
+func (n *bridge) State() (*api.NetworkState, error) {
+	state, err := n.common.State()
+	if err != nil {
+		return nil, err
+	}
+
+	if !usesConntrackLimits(n.config) {
+		return state, nil
+	}
+
+	// The connection tracking table may not be readable (e.g. missing module), don't fail the whole state.
+	count, err := conntrackCount(n.name)
+	if err != nil {
+		n.logger.Warn("Failed counting tracked connections", logger.Ctx{"err": err})
+		return state, nil
+	}
+
+	state.Conntrack = &api.NetworkStateConntrack{Count: count}
+
+	if n.config["conntrack.max"] != "" {
+		state.Conntrack.Max, err = strconv.ParseUint(n.config["conntrack.max"], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hostMax, err := conntrackHostMax()
+	if err == nil {
+		state.Conntrack.HostMax = hostMax
+	}
+
+	return state, nil
+}
+
 // Update updates the network. Accepts notification boolean indicating if this update request is coming from a
 // cluster notification, in which case do not update the database, just apply local changes needed.
 func (n *bridge) Update(newNetwork api.NetworkPut, targetNode string, clientType request.ClientType) error {
@@ -2307,6 +2438,11 @@ func (n *bridge) forwardConvertToFirewallForwards(listenAddress net.IP, defaultT
 	}
 
 	for _, portMap := range portMaps {
+		// HTTP(S) ports are handled by the forward listeners rather than the firewall.
+		if len(portMap.hostnames) > 0 {
+			continue
+		}
+
 		vips = append(vips, firewallDrivers.AddressForward{
 			ListenAddress: listenAddress,
 			Protocol:      portMap.protocol,
@@ -2320,6 +2456,44 @@ func (n *bridge) forwardConvertToFirewallForwards(listenAddress net.IP, defaultT
 	return vips
 }
 
+// forwardConvertToProxies adds the HTTP(S) ports of a forward to the forward listeners, indexed by listen address
+// and port. The listeners are bound to the bridge address of the same IP family as the listen address.
+func (n *bridge) forwardConvertToProxies(listeners map[string]*forwardProxy, listenAddress net.IP, portMaps []*forwardPortMap) error {
+	for _, portMap := range portMaps {
+		if len(portMap.hostnames) == 0 {
+			continue
+		}
+
+		netIPKey := "ipv4.address"
+		if listenAddress.To4() == nil {
+			netIPKey = "ipv6.address"
+		}
+
+		bindAddress, _, err := net.ParseCIDR(n.config[netIPKey])
+		if err != nil {
+			return fmt.Errorf("The %q protocol requires %q to be set on the network", portMap.protocol, netIPKey)
+		}
+
+		listenPort := portMap.listenPorts[0]
+		targetPort := listenPort
+		if len(portMap.target.ports) > 0 {
+			targetPort = portMap.target.ports[0]
+		}
+
+		listen := net.JoinHostPort(listenAddress.String(), strconv.FormatUint(listenPort, 10))
+		if listeners[listen] == nil {
+			listeners[listen] = &forwardProxy{protocol: portMap.protocol, bindAddress: bindAddress}
+		}
+
+		listeners[listen].routes = append(listeners[listen].routes, forwardProxyRoute{
+			hostnames: portMap.hostnames,
+			target:    net.JoinHostPort(portMap.target.address.String(), strconv.FormatUint(targetPort, 10)),
+		})
+	}
+
+	return nil
+}
+
 // bridgeProjectNetworks takes a map of all networks in all projects and returns a filtered map of bridge networks.
 func (n *bridge) bridgeProjectNetworks(projectNetworks map[string]map[int64]api.Network) map[string][]*api.Network {
 	bridgeProjectNetworks := make(map[string][]*api.Network)
@@ -2894,6 +3068,7 @@ func (n *bridge) forwardSetupFirewall() error {
 
 	var fwForwards []firewallDrivers.AddressForward
 	ipVersions := make(map[uint]struct{})
+	proxies := make(map[string]*forwardProxy)
 
 	for _, forward := range forwards {
 		// Convert listen address to subnet so we can check its valid and can be used.
@@ -2915,6 +3090,36 @@ func (n *bridge) forwardSetupFirewall() error {
 		}
 
 		fwForwards = append(fwForwards, n.forwardConvertToFirewallForwards(listenAddressNet.IP, net.ParseIP(forward.Config["target_address"]), portMaps)...)
+		err = n.forwardConvertToProxies(proxies, listenAddressNet.IP, portMaps)
+		if err != nil {
+			return fmt.Errorf("Failed validating HTTP(S) address forward for listen address %q: %w", forward.ListenAddress, err)
+		}
+	}
+
+	// Start the HTTP(S) forward listeners and forward their listen ports to them.
+	proxyAddresses, err := forwardProxiesApply(n.id, proxies)
+	if err != nil {
+		return fmt.Errorf("Failed applying HTTP(S) address forwards: %w", err)
+	}
+
+	for listen, proxyAddress := range proxyAddresses {
+		listenHost, listenPort, err := net.SplitHostPort(listen)
+		if err != nil {
+			return err
+		}
+
+		port, err := strconv.ParseUint(listenPort, 10, 16)
+		if err != nil {
+			return err
+		}
+
+		fwForwards = append(fwForwards, firewallDrivers.AddressForward{
+			ListenAddress: net.ParseIP(listenHost),
+			Protocol:      "tcp",
+			TargetAddress: proxyAddress.IP,
+			ListenPorts:   []uint64{port},
+			TargetPorts:   []uint64{uint64(proxyAddress.Port)},
+		})
 	}
 
 	if len(forwards) > 0 {
@@ -2951,6 +3156,289 @@ func (n *bridge) forwardSetupFirewall() error {
 	return nil
 }
 
+// dhcpReservationValidate validates a DHCP reservation against the network's subnets and the addresses already
+// in use on the network.
+func (n *bridge) dhcpReservationValidate(hwaddr string, reservation *api.NetworkDHCPReservationPut) error {
+	reservedIPs, err := n.dhcpReservationAddresses(hwaddr, reservation)
+	if err != nil {
+		return err
+	}
+
+	// Check the addresses aren't used by another reservation.
+	var reservations []api.NetworkDHCPReservation
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		reservations, err = tx.GetNetworkDHCPReservations(ctx, n.ID())
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading DHCP reservations: %w", err)
+	}
+
+	// Check the addresses aren't currently leased to another client on any cluster member.
+	leases, err := n.dhcpReservationDynamicLeases()
+	if err != nil {
+		return fmt.Errorf("Failed loading DHCP leases: %w", err)
+	}
+
+	err = dhcpReservationConflicts(hwaddr, reservedIPs, reservations, leases)
+	if err != nil {
+		return err
+	}
+
+	// Check the MAC and addresses aren't used by an instance NIC on any cluster member.
+	reservedMAC, _ := net.ParseMAC(hwaddr)
+
+	return UsedByInstanceDevices(n.state, n.project, n.name, n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		nicMAC, _ := net.ParseMAC(nicConfig["hwaddr"])
+		if nicMAC == nil {
+			nicMAC, _ = net.ParseMAC(inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)])
+		}
+
+		if nicMAC != nil && bytes.Equal(nicMAC, reservedMAC) {
+			return api.StatusErrorf(http.StatusConflict, "MAC address %q is already used by an instance NIC", nicMAC.String())
+		}
+
+		for key, ip := range reservedIPs {
+			nicIP := net.ParseIP(nicConfig[key])
+			if nicIP != nil && nicIP.Equal(ip) {
+				return api.StatusErrorf(http.StatusConflict, "IP address %q is already assigned to an instance NIC", ip.String())
+			}
+		}
+
+		return nil
+	})
+}
+
+// dhcpReservationAddresses validates the fields of a DHCP reservation against the network's config and returns
+// the reserved addresses keyed by the matching NIC config key.
+func (n *bridge) dhcpReservationAddresses(hwaddr string, reservation *api.NetworkDHCPReservationPut) (map[string]net.IP, error) {
+	err := validate.IsNetworkMAC(hwaddr)
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid MAC address %q", hwaddr)
+	}
+
+	if reservation.IPv4Address == "" && reservation.IPv6Address == "" {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "At least one of IPv4 or IPv6 address must be reserved")
+	}
+
+	if reservation.Hostname != "" {
+		err = validate.IsHostname(reservation.Hostname)
+		if err != nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid host name %q: %v", reservation.Hostname, err)
+		}
+	}
+
+	// Check the addresses are within the DHCP subnets and aren't the gateway addresses.
+	reservedIPs := map[string]net.IP{}
+
+	if reservation.IPv4Address != "" {
+		ip := net.ParseIP(reservation.IPv4Address)
+		if ip == nil || ip.To4() == nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid IPv4 address %q", reservation.IPv4Address)
+		}
+
+		subnet := n.DHCPv4Subnet()
+		if subnet == nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "DHCPv4 isn't enabled on network %q", n.name)
+		}
+
+		if !subnet.Contains(ip) {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "IPv4 address %q isn't within the network's subnet %q", ip.String(), subnet.String())
+		}
+
+		reservedIPs["ipv4.address"] = ip
+	}
+
+	if reservation.IPv6Address != "" {
+		ip := net.ParseIP(reservation.IPv6Address)
+		if ip == nil || ip.To4() != nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid IPv6 address %q", reservation.IPv6Address)
+		}
+
+		subnet := n.DHCPv6Subnet()
+		if subnet == nil || util.IsFalseOrEmpty(n.config["ipv6.dhcp.stateful"]) {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Stateful DHCPv6 isn't enabled on network %q", n.name)
+		}
+
+		if !subnet.Contains(ip) {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "IPv6 address %q isn't within the network's subnet %q", ip.String(), subnet.String())
+		}
+
+		reservedIPs["ipv6.address"] = ip
+	}
+
+	for _, key := range []string{"ipv4.address", "ipv6.address"} {
+		gatewayIP, _, _ := net.ParseCIDR(n.config[key])
+		if gatewayIP != nil && reservedIPs[key] != nil && gatewayIP.Equal(reservedIPs[key]) {
+			return nil, api.StatusErrorf(http.StatusConflict, "IP address %q is the network's own address", gatewayIP.String())
+		}
+	}
+
+	return reservedIPs, nil
+}
+
+// dhcpReservationDynamicLeases returns the dynamic leases handed out by the DHCP server of every cluster member.
+func (n *bridge) dhcpReservationDynamicLeases() ([]api.NetworkLease, error) {
+	// Notifier mode only returns the local dynamic leases, without filtering them by project.
+	leases, err := n.Leases(n.project, request.ClientTypeNotifier)
+	if err != nil {
+		return nil, err
+	}
+
+	notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return nil, err
+	}
+
+	var leasesMu sync.Mutex
+	err = notifier(func(client incus.InstanceServer) error {
+		memberLeases, err := client.UseProject(n.project).GetNetworkLeases(n.name)
+		if err != nil {
+			return err
+		}
+
+		leasesMu.Lock()
+		leases = append(leases, memberLeases...)
+		leasesMu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return leases, nil
+}
+
+// dhcpReservationConflicts checks that the addresses reserved for hwaddr aren't already reserved for another MAC
+// address or dynamically leased to another client. Leases whose client MAC address isn't known, as is the case for
+// DHCPv6, can't be told apart from the reserving client and are ignored.
+func dhcpReservationConflicts(hwaddr string, reservedIPs map[string]net.IP, reservations []api.NetworkDHCPReservation, leases []api.NetworkLease) error {
+	reservedMAC, _ := net.ParseMAC(hwaddr)
+
+	for _, other := range reservations {
+		otherMAC, _ := net.ParseMAC(other.Hwaddr)
+		if bytes.Equal(otherMAC, reservedMAC) {
+			continue // Skip ourselves.
+		}
+
+		for _, otherIP := range []net.IP{net.ParseIP(other.IPv4Address), net.ParseIP(other.IPv6Address)} {
+			for _, ip := range reservedIPs {
+				if otherIP != nil && otherIP.Equal(ip) {
+					return api.StatusErrorf(http.StatusConflict, "IP address %q is already reserved for %q", ip.String(), other.Hwaddr)
+				}
+			}
+		}
+	}
+
+	for _, lease := range leases {
+		if lease.Type != "dynamic" {
+			continue
+		}
+
+		leaseMAC, _ := net.ParseMAC(lease.Hwaddr)
+		if leaseMAC == nil || bytes.Equal(leaseMAC, reservedMAC) {
+			continue
+		}
+
+		leaseIP := net.ParseIP(lease.Address)
+		for _, ip := range reservedIPs {
+			if leaseIP != nil && leaseIP.Equal(ip) {
+				return api.StatusErrorf(http.StatusConflict, "IP address %q is currently leased to %q", ip.String(), leaseMAC.String())
+			}
+		}
+	}
+
+	return nil
+}
+
+// dhcpReservationsApply regenerates the DHCP host entries on this member and notifies the other members to do
+// the same if the request doesn't come from another member.
+func (n *bridge) dhcpReservationsApply(clientType request.ClientType, notify func(client incus.InstanceServer) error) error {
+	err := UpdateDNSMasqStatic(n.state, n.name)
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNotifier {
+		return nil
+	}
+
+	notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return err
+	}
+
+	return notifier(func(client incus.InstanceServer) error {
+		return notify(client.UseProject(n.project))
+	})
+}
+
+// DHCPReservationCreate creates a DHCP reservation.
+func (n *bridge) DHCPReservationCreate(reservation api.NetworkDHCPReservationsPost, clientType request.ClientType) error {
+	if clientType != request.ClientTypeNotifier {
+		err := n.dhcpReservationValidate(reservation.Hwaddr, &reservation.NetworkDHCPReservationPut)
+		if err != nil {
+			return err
+		}
+
+		err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			_, err := tx.GetNetworkDHCPReservation(ctx, n.ID(), reservation.Hwaddr)
+			if err == nil {
+				return api.StatusErrorf(http.StatusConflict, "A DHCP reservation for that MAC address already exists")
+			}
+
+			return tx.CreateNetworkDHCPReservation(ctx, n.ID(), &reservation)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return n.dhcpReservationsApply(clientType, func(client incus.InstanceServer) error {
+		return client.CreateNetworkDHCPReservation(n.name, reservation)
+	})
+}
+
+// DHCPReservationUpdate updates a DHCP reservation.
+func (n *bridge) DHCPReservationUpdate(hwaddr string, req api.NetworkDHCPReservationPut, clientType request.ClientType) error {
+	if clientType != request.ClientTypeNotifier {
+		err := n.dhcpReservationValidate(hwaddr, &req)
+		if err != nil {
+			return err
+		}
+
+		err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateNetworkDHCPReservation(ctx, n.ID(), hwaddr, &req)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return n.dhcpReservationsApply(clientType, func(client incus.InstanceServer) error {
+		return client.UpdateNetworkDHCPReservation(n.name, hwaddr, req, "")
+	})
+}
+
+// DHCPReservationDelete deletes a DHCP reservation.
+func (n *bridge) DHCPReservationDelete(hwaddr string, clientType request.ClientType) error {
+	if clientType != request.ClientTypeNotifier {
+		err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.DeleteNetworkDHCPReservation(ctx, n.ID(), hwaddr)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return n.dhcpReservationsApply(clientType, func(client incus.InstanceServer) error {
+		return client.DeleteNetworkDHCPReservation(n.name, hwaddr)
+	})
+}
+
 // Leases returns a list of leases for the bridged network. It will reach out to other cluster members as needed.
 // The projectName passed here refers to the initial project from the API request which may differ from the network's project.
 func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
@@ -3004,6 +3492,31 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 					}
 				}
 			}
+
+			// Add the DHCP reservations.
+			var reservations []api.NetworkDHCPReservation
+			err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				reservations, err = tx.GetNetworkDHCPReservations(ctx, n.ID())
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, reservation := range reservations {
+				for _, address := range []string{reservation.IPv4Address, reservation.IPv6Address} {
+					if address == "" {
+						continue
+					}
+
+					leases = append(leases, api.NetworkLease{
+						Hostname: reservation.Hostname,
+						Address:  address,
+						Hwaddr:   reservation.Hwaddr,
+						Type:     "reservation",
+					})
+				}
+			}
 		}
 
 		// Get all the instances in the requested project that are connected to this network.
@@ -3154,6 +3667,79 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 	return leases, nil
 }
 
+// LeaseDelete releases the dynamic leases matching the IP or MAC address from the network's DHCP server.
+// It will reach out to other cluster members as needed.
+func (n *bridge) LeaseDelete(address string, clientType request.ClientType) error {
+	matchIP := net.ParseIP(address)
+
+	var matchMAC net.HardwareAddr
+	if matchIP == nil {
+		var err error
+
+		matchMAC, err = net.ParseMAC(address)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid lease address %q, must be an IP or MAC address", address)
+		}
+	}
+
+	leases, err := dnsmasq.Leases(n.name)
+	if err != nil {
+		return fmt.Errorf("Failed loading leases: %w", err)
+	}
+
+	matches := []dnsmasq.Lease{}
+	for _, lease := range leases {
+		if (matchIP != nil && lease.IP.Equal(matchIP)) || (matchMAC != nil && bytes.Equal(lease.MAC, matchMAC)) {
+			matches = append(matches, lease)
+		}
+	}
+
+	var found atomic.Bool
+	if len(matches) > 0 {
+		found.Store(true)
+
+		serverIPv4, _, _ := net.ParseCIDR(n.config["ipv4.address"])
+		serverIPv6, _, _ := net.ParseCIDR(n.config["ipv6.address"])
+
+		err = dnsmasq.ReleaseLeases(n.name, serverIPv4, serverIPv6, matches)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Release the matching leases from the other servers.
+	if clientType == request.ClientTypeNormal {
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAlive)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			err := client.UseProject(n.project).DeleteNetworkLease(n.name, address)
+			if err != nil {
+				if api.StatusErrorCheck(err, http.StatusNotFound) {
+					return nil
+				}
+
+				return err
+			}
+
+			found.Store(true)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if !found.Load() {
+		return api.StatusErrorf(http.StatusNotFound, "Lease not found")
+	}
+
+	return nil
+}
+
 // UsesDNSMasq indicates if network's config indicates if it needs to use dnsmasq.
 func (n *bridge) UsesDNSMasq() bool {
 	// Skip dnsmasq when no connectivity is configured.
@@ -0,0 +1,131 @@
+package network
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestBridgeDHCPReservationAddresses(t *testing.T) {
+	n := &bridge{common: common{name: "incusbr0", config: map[string]string{
+		"ipv4.address":       "192.0.2.1/24",
+		"ipv6.address":       "2001:db8::1/64",
+		"ipv6.dhcp.stateful": "true",
+	}}}
+
+	reservedIPs, err := n.dhcpReservationAddresses("00:16:3e:00:00:01", &api.NetworkDHCPReservationPut{IPv4Address: "192.0.2.10", IPv6Address: "2001:db8::10", Hostname: "printer"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]net.IP{"ipv4.address": net.ParseIP("192.0.2.10"), "ipv6.address": net.ParseIP("2001:db8::10")}, reservedIPs)
+
+	tests := []struct {
+		name        string
+		hwaddr      string
+		reservation api.NetworkDHCPReservationPut
+		status      int
+	}{
+		{"Invalid MAC", "invalid", api.NetworkDHCPReservationPut{IPv4Address: "192.0.2.10"}, http.StatusBadRequest},
+		{"No address", "00:16:3e:00:00:01", api.NetworkDHCPReservationPut{Hostname: "printer"}, http.StatusBadRequest},
+		{"Invalid hostname", "00:16:3e:00:00:01", api.NetworkDHCPReservationPut{IPv4Address: "192.0.2.10", Hostname: "-printer"}, http.StatusBadRequest},
+		{"IPv6 in IPv4 field", "00:16:3e:00:00:01", api.NetworkDHCPReservationPut{IPv4Address: "2001:db8::10"}, http.StatusBadRequest},
+		{"IPv4 in IPv6 field", "00:16:3e:00:00:01", api.NetworkDHCPReservationPut{IPv6Address: "192.0.2.10"}, http.StatusBadRequest},
+		{"IPv4 outside subnet", "00:16:3e:00:00:01", api.NetworkDHCPReservationPut{IPv4Address: "198.51.100.10"}, http.StatusBadRequest},
+		{"IPv6 outside subnet", "00:16:3e:00:00:01", api.NetworkDHCPReservationPut{IPv6Address: "2001:db8:1::10"}, http.StatusBadRequest},
+		{"IPv4 gateway", "00:16:3e:00:00:01", api.NetworkDHCPReservationPut{IPv4Address: "192.0.2.1"}, http.StatusConflict},
+		{"IPv6 gateway", "00:16:3e:00:00:01", api.NetworkDHCPReservationPut{IPv6Address: "2001:db8::1"}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := n.dhcpReservationAddresses(tt.hwaddr, &tt.reservation)
+			status, found := api.StatusErrorMatch(err)
+			require.True(t, found, "Unexpected error: %v", err)
+			assert.Equal(t, tt.status, status)
+		})
+	}
+
+	// DHCPv4 disabled.
+	n.config["ipv4.dhcp"] = "false"
+	_, err = n.dhcpReservationAddresses("00:16:3e:00:00:01", &api.NetworkDHCPReservationPut{IPv4Address: "192.0.2.10"})
+	assert.Error(t, err)
+
+	// Stateless DHCPv6.
+	n.config["ipv6.dhcp.stateful"] = "false"
+	_, err = n.dhcpReservationAddresses("00:16:3e:00:00:01", &api.NetworkDHCPReservationPut{IPv6Address: "2001:db8::10"})
+	assert.Error(t, err)
+}
+
+func TestDHCPReservationConflicts(t *testing.T) {
+	reservedIPs := map[string]net.IP{"ipv4.address": net.ParseIP("192.0.2.10"), "ipv6.address": net.ParseIP("2001:db8::10")}
+
+	tests := []struct {
+		name         string
+		reservations []api.NetworkDHCPReservation
+		leases       []api.NetworkLease
+		wantErr      bool
+	}{
+		{
+			name: "No conflict",
+			reservations: []api.NetworkDHCPReservation{
+				{Hwaddr: "00:16:3e:00:00:02", NetworkDHCPReservationPut: api.NetworkDHCPReservationPut{IPv4Address: "192.0.2.11"}},
+			},
+			leases: []api.NetworkLease{
+				{Hwaddr: "00:16:3e:00:00:02", Address: "192.0.2.12", Type: "dynamic"},
+			},
+		},
+		{
+			name: "Own reservation",
+			reservations: []api.NetworkDHCPReservation{
+				{Hwaddr: "00:16:3E:00:00:01", NetworkDHCPReservationPut: api.NetworkDHCPReservationPut{IPv4Address: "192.0.2.10"}},
+			},
+		},
+		{
+			name: "Reserved for another MAC",
+			reservations: []api.NetworkDHCPReservation{
+				{Hwaddr: "00:16:3e:00:00:02", NetworkDHCPReservationPut: api.NetworkDHCPReservationPut{IPv6Address: "2001:db8::10"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Own dynamic lease",
+			leases: []api.NetworkLease{
+				{Hwaddr: "00:16:3e:00:00:01", Address: "192.0.2.10", Type: "dynamic"},
+			},
+		},
+		{
+			name: "Dynamically leased to another MAC",
+			leases: []api.NetworkLease{
+				{Hwaddr: "00:16:3e:00:00:02", Address: "192.0.2.10", Type: "dynamic"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Dynamic IPv6 lease without known MAC",
+			leases: []api.NetworkLease{
+				{Address: "2001:db8::10", Type: "dynamic"},
+			},
+		},
+		{
+			name: "Static lease",
+			leases: []api.NetworkLease{
+				{Hwaddr: "00:16:3e:00:00:02", Address: "192.0.2.10", Type: "static"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := dhcpReservationConflicts("00:16:3e:00:00:01", reservedIPs, tt.reservations, tt.leases)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, api.StatusErrorCheck(err, http.StatusConflict), "Unexpected error: %v", err)
+		})
+	}
+}
@@ -37,12 +37,14 @@ type Info struct {
 	AddressForwards    bool // Indicates if driver supports address forwards.
 	LoadBalancers      bool // Indicates if driver supports load balancers.
 	Peering            bool // Indicates if the driver supports network peering.
+	DHCPReservations   bool // Indicates if the driver supports DHCP reservations.
 }
 
 // forwardTarget represents a single port forward target.
 type forwardTarget struct {
 	address net.IP
 	ports   []uint64
+	weight  int // Only used by load balancers.
 }
 
 // forwardPortMap represents a mapping of listen port(s) to target port(s) for a protocol/target address pair.
@@ -51,6 +53,7 @@ type forwardPortMap struct {
 	protocol    string
 	target      forwardTarget
 	snat        bool
+	hostnames   []string
 }
 
 type loadBalancerPortMap struct {
@@ -935,7 +938,7 @@ func (n *common) forwardValidate(listenAddress net.IP, forward *api.NetworkForwa
 	}
 
 	// Validate port rules.
-	validPortProcols := []string{"tcp", "udp"}
+	validPortProcols := []string{"tcp", "udp", "http", "https"}
 
 	// Used to ensure that each listen port is only used once.
 	listenPorts := map[string]map[int64]struct{}{
@@ -943,6 +946,11 @@ func (n *common) forwardValidate(listenAddress net.IP, forward *api.NetworkForwa
 		"udp": make(map[int64]struct{}),
 	}
 
+	// Used to ensure that each hostname is only used once per HTTP(S) listen port.
+	// HTTP(S) listen ports can be shared between port specifications with different hostnames.
+	httpListenPorts := make(map[int64]string)
+	httpHostnames := make(map[int64]map[string]struct{})
+
 	// Maps portSpecID to a portMap struct.
 	portMaps := make([]*forwardPortMap, 0, len(forward.Ports))
 	for portSpecID, portSpec := range forward.Ports {
@@ -950,6 +958,32 @@ func (n *common) forwardValidate(listenAddress net.IP, forward *api.NetworkForwa
 			return nil, fmt.Errorf("Invalid port protocol in port specification %d, protocol must be one of: %s", portSpecID, strings.Join(validPortProcols, ", "))
 		}
 
+		isHTTP := slices.Contains([]string{"http", "https"}, portSpec.Protocol)
+		if isHTTP {
+			if n.netType != "bridge" {
+				return nil, fmt.Errorf("The %q protocol can only be used with bridge networks", portSpec.Protocol)
+			}
+
+			// The listeners are bound to the network's own address.
+			if netSubnet == nil {
+				return nil, fmt.Errorf("The %q protocol requires %q to be set on the network", portSpec.Protocol, netIPKey)
+			}
+
+			if defaultTargetAddress != nil {
+				return nil, fmt.Errorf("The %q protocol cannot be used together with a default target address in port specification %d", portSpec.Protocol, portSpecID)
+			}
+
+			if portSpec.SNAT {
+				return nil, fmt.Errorf("SNAT cannot be used with the %q protocol in port specification %d", portSpec.Protocol, portSpecID)
+			}
+
+			if portSpec.Hostnames == "" {
+				return nil, fmt.Errorf("Missing hostnames in port specification %d", portSpecID)
+			}
+		} else if portSpec.Hostnames != "" {
+			return nil, fmt.Errorf("Hostnames can only be used with the http and https protocols in port specification %d", portSpecID)
+		}
+
 		targetAddress := net.ParseIP(portSpec.TargetAddress)
 		if targetAddress == nil {
 			return nil, fmt.Errorf("Invalid target address in port specification %d", portSpecID)
@@ -984,21 +1018,69 @@ func (n *common) forwardValidate(listenAddress net.IP, forward *api.NetworkForwa
 			snat:     portSpec.SNAT,
 		}
 
-		for _, pr := range listenPortRanges {
-			portFirst, portRange, err := ParsePortRange(pr)
-			if err != nil {
-				return nil, fmt.Errorf("Invalid listen port in port specification %d: %w", portSpecID, err)
+		if isHTTP {
+			listenPort, err := strconv.ParseUint(portSpec.ListenPort, 10, 16)
+			if err != nil || listenPort == 0 {
+				return nil, fmt.Errorf("The %q protocol requires a single listen port in port specification %d", portSpec.Protocol, portSpecID)
 			}
 
-			for i := int64(0); i < portRange; i++ {
-				port := portFirst + i
-				_, found := listenPorts[portSpec.Protocol][port]
+			port := int64(listenPort)
+
+			// HTTP(S) listeners accept TCP connections so can't share a port with a TCP forward.
+			_, found := listenPorts["tcp"][port]
+			if found {
+				return nil, fmt.Errorf("Duplicate listen port %d for protocol %q in port specification %d", port, portSpec.Protocol, portSpecID)
+			}
+
+			protocol, found := httpListenPorts[port]
+			if found && protocol != portSpec.Protocol {
+				return nil, fmt.Errorf("Listen port %d is already used by the %q protocol in port specification %d", port, protocol, portSpecID)
+			}
+
+			httpListenPorts[port] = portSpec.Protocol
+			if httpHostnames[port] == nil {
+				httpHostnames[port] = make(map[string]struct{})
+			}
+
+			for _, hostname := range util.SplitNTrimSpace(portSpec.Hostnames, ",", -1, true) {
+				hostname = forwardNormalizeHostname(hostname)
+
+				err = forwardValidateHostname(hostname)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid hostname %q in port specification %d: %w", hostname, portSpecID, err)
+				}
+
+				_, found := httpHostnames[port][hostname]
 				if found {
-					return nil, fmt.Errorf("Duplicate listen port %d for protocol %q in port specification %d", port, portSpec.Protocol, portSpecID)
+					return nil, fmt.Errorf("Duplicate hostname %q for listen port %d in port specification %d", hostname, port, portSpecID)
 				}
 
-				listenPorts[portSpec.Protocol][port] = struct{}{}
-				portMap.listenPorts = append(portMap.listenPorts, uint64(port))
+				httpHostnames[port][hostname] = struct{}{}
+				portMap.hostnames = append(portMap.hostnames, hostname)
+			}
+
+			portMap.listenPorts = append(portMap.listenPorts, listenPort)
+		} else {
+			for _, pr := range listenPortRanges {
+				portFirst, portRange, err := ParsePortRange(pr)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid listen port in port specification %d: %w", portSpecID, err)
+				}
+
+				for i := int64(0); i < portRange; i++ {
+					port := portFirst + i
+					_, found := listenPorts[portSpec.Protocol][port]
+					if found {
+						return nil, fmt.Errorf("Duplicate listen port %d for protocol %q in port specification %d", port, portSpec.Protocol, portSpecID)
+					}
+
+					if portSpec.Protocol == "tcp" && httpListenPorts[port] != "" {
+						return nil, fmt.Errorf("Listen port %d is already used by the %q protocol in port specification %d", port, httpListenPorts[port], portSpecID)
+					}
+
+					listenPorts[portSpec.Protocol][port] = struct{}{}
+					portMap.listenPorts = append(portMap.listenPorts, uint64(port))
+				}
 			}
 		}
 
@@ -1026,9 +1108,18 @@ func (n *common) forwardValidate(listenAddress net.IP, forward *api.NetworkForwa
 				}
 			}
 
+			// When both sides are a single port range, they are mapped offset by offset so must be the same size.
+			portSpectTargetPortsLen := len(portMap.target.ports)
+			if len(listenPortRanges) == 1 && len(targetPortRanges) == 1 && portSpectTargetPortsLen > 1 && len(portMap.listenPorts) > 1 && len(portMap.listenPorts) != portSpectTargetPortsLen {
+				return nil, fmt.Errorf("Listen port range %q (%d ports) and target port range %q (%d ports) must be the same size in port specification %d", listenPortRanges[0], len(portMap.listenPorts), targetPortRanges[0], portSpectTargetPortsLen, portSpecID)
+			}
+
+			if isHTTP && portSpectTargetPortsLen != 1 {
+				return nil, fmt.Errorf("The %q protocol requires a single target port in port specification %d", portSpec.Protocol, portSpecID)
+			}
+
 			// Only check if the target port count matches the listen port count if the target ports
 			// don't equal 1, because we allow many-to-one type mapping.
-			portSpectTargetPortsLen := len(portMap.target.ports)
 			if portSpectTargetPortsLen != 1 && len(portMap.listenPorts) != portSpectTargetPortsLen {
 				return nil, fmt.Errorf("Mismatch of listen port(s) and target port(s) count in port specification %d", portSpecID)
 			}
@@ -1055,6 +1146,21 @@ func (n *common) ForwardDelete(listenAddress string, clientType request.ClientTy
 	return ErrNotImplemented
 }
 
+// DHCPReservationCreate returns ErrNotImplemented for drivers that do not support DHCP reservations.
+func (n *common) DHCPReservationCreate(reservation api.NetworkDHCPReservationsPost, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// DHCPReservationUpdate returns ErrNotImplemented for drivers that do not support DHCP reservations.
+func (n *common) DHCPReservationUpdate(hwaddr string, newReservation api.NetworkDHCPReservationPut, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// DHCPReservationDelete returns ErrNotImplemented for drivers that do not support DHCP reservations.
+func (n *common) DHCPReservationDelete(hwaddr string, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
 // forwardBGPSetupPrefixes exports external forward addresses as prefixes.
 func (n *common) forwardBGPSetupPrefixes() error {
 	var fwdListenAddresses map[int64]string
@@ -1314,6 +1420,10 @@ func (n *common) loadBalancerValidate(listenAddress net.IP, forward *api.Network
 			return nil, fmt.Errorf("Duplicate name %q in backend specification %d", backendSpec.Name, backendSpecID)
 		}
 
+		if backendSpec.Weight != nil && *backendSpec.Weight <= 0 {
+			return nil, fmt.Errorf("Weight must be a positive integer for backend %q", backendSpec.Name)
+		}
+
 		targetAddress := net.ParseIP(backendSpec.TargetAddress)
 		if targetAddress == nil {
 			return nil, fmt.Errorf("Invalid target address for backend %q", backendSpec.Name)
@@ -1349,6 +1459,16 @@ func (n *common) loadBalancerValidate(listenAddress net.IP, forward *api.Network
 		backendsByName[backendSpec.Name] = &target
 	}
 
+	// Apply the backend weights.
+	weights, weighted := loadBalancerBackendWeights(forward.Backends)
+	if !weighted {
+		return nil, fmt.Errorf("Backend weights add up to more than %d, even once reduced by their greatest common divisor", loadBalancerMaxWeight)
+	}
+
+	for name, target := range backendsByName {
+		target.weight = weights[name]
+	}
+
 	// Check ports config.
 	portMaps := make([]*loadBalancerPortMap, 0, len(forward.Ports))
 	for portSpecID, portSpec := range forward.Ports {
@@ -1436,6 +1556,16 @@ func (n *common) Leases(projectName string, clientType request.ClientType) ([]ap
 	return nil, ErrNotImplemented
 }
 
+// Trace returns ErrNotImplemented for drivers that don't support packet tracing.
+func (n *common) Trace(projectName string, req api.NetworkTracePost) (*api.NetworkTrace, error) {
+	return nil, ErrNotImplemented
+}
+
+// LeaseDelete returns ErrNotImplemented for drivers that don't support releasing leases.
+func (n *common) LeaseDelete(address string, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
 // PeerCrete returns ErrNotImplemented for drivers that do not support forwards.
 func (n *common) PeerCreate(forward api.NetworkPeersPost) error {
 	return ErrNotImplemented
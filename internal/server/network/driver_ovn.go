@@ -206,6 +206,23 @@ func (n *ovn) State() (*api.NetworkState, error) {
 		}
 	}
 
+	// Get the DHCP options currently programmed on the internal switch.
+	var dhcpv4Options map[string]string
+	var dhcpv6Options map[string]string
+
+	dhcpOpts, err := n.ovnnb.GetLogicalSwitchDHCPOptions(context.TODO(), logicalSwitchName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dhcpOpt := range dhcpOpts {
+		if dhcpOpt.CIDR.IP.To4() != nil {
+			dhcpv4Options = dhcpOpt.Options
+		} else {
+			dhcpv6Options = dhcpOpt.Options
+		}
+	}
+
 	// Get the switch MTU.
 	mtu := int(n.getBridgeMTU())
 	if mtu == 0 {
@@ -224,6 +241,8 @@ func (n *ovn) State() (*api.NetworkState, error) {
 			LogicalSwitch: string(logicalSwitchName),
 			UplinkIPv4:    uplinkIPv4,
 			UplinkIPv6:    uplinkIPv6,
+			DHCPv4Options: dhcpv4Options,
+			DHCPv6Options: dhcpv6Options,
 		},
 	}, nil
 }
@@ -658,6 +677,40 @@ func (n *ovn) Validate(config map[string]string) error {
 		ovnVolatileUplinkIPv6: validate.Optional(validate.IsNetworkAddressV6),
 	}
 
+	// Add dynamic validation rules.
+	for k := range config {
+		// Raw DHCP option keys have the option code in their name, extract the suffix.
+		code, isDHCPv4 := strings.CutPrefix(k, "ipv4.dhcp.option.")
+		if isDHCPv4 {
+			// gendoc:generate(entity=network_ovn, group=common, key=ipv4.dhcp.option.CODE)
+			//
+			// ---
+			//  type: string
+			//  condition: IPv4 DHCP
+			//  shortdesc: Raw DHCPv4 option to pass to instances, identified by its option code (only options supported by OVN and not managed by other keys are allowed)
+			rules[k] = func(value string) error {
+				_, _, err := networkOVN.DHCPv4Option(code, value)
+				return err
+			}
+
+			continue
+		}
+
+		code, isDHCPv6 := strings.CutPrefix(k, "ipv6.dhcp.option.")
+		if isDHCPv6 {
+			// gendoc:generate(entity=network_ovn, group=common, key=ipv6.dhcp.option.CODE)
+			//
+			// ---
+			//  type: string
+			//  condition: IPv6 DHCP
+			//  shortdesc: Raw DHCPv6 option to pass to instances, identified by its option code (only options supported by OVN and not managed by other keys are allowed)
+			rules[k] = func(value string) error {
+				_, _, err := networkOVN.DHCPv6Option(code, value)
+				return err
+			}
+		}
+	}
+
 	err := n.validate(config, rules)
 	if err != nil {
 		return err
@@ -2907,6 +2960,21 @@ func (n *ovn) setup(update bool) error {
 			DNSSearchList:      n.getDNSSearchList(),
 			StaticRoutes:       n.config["ipv4.dhcp.routes"],
 			RecursiveDNSServer: dnsIPv4,
+			ExtraOptions:       map[string]string{},
+		}
+
+		for k, v := range n.config {
+			code, found := strings.CutPrefix(k, "ipv4.dhcp.option.")
+			if !found {
+				continue
+			}
+
+			name, value, err := networkOVN.DHCPv4Option(code, v)
+			if err != nil {
+				return err
+			}
+
+			opts.ExtraOptions[name] = value
 		}
 
 		err = n.ovnnb.UpdateLogicalSwitchDHCPv4Options(context.TODO(), n.getIntSwitchName(), dhcpv4UUID, dhcpV4Subnet, opts)
@@ -2925,6 +2993,21 @@ func (n *ovn) setup(update bool) error {
 			ServerID:           routerMAC,
 			DNSSearchList:      n.getDNSSearchList(),
 			RecursiveDNSServer: dnsIPv6,
+			ExtraOptions:       map[string]string{},
+		}
+
+		for k, v := range n.config {
+			code, found := strings.CutPrefix(k, "ipv6.dhcp.option.")
+			if !found {
+				continue
+			}
+
+			name, value, err := networkOVN.DHCPv6Option(code, v)
+			if err != nil {
+				return err
+			}
+
+			opts.ExtraOptions[name] = value
 		}
 
 		err = n.ovnnb.UpdateLogicalSwitchDHCPv6Options(context.TODO(), n.getIntSwitchName(), dhcpv6UUID, dhcpV6Subnet, opts)
@@ -5234,7 +5317,7 @@ func (n *ovn) handleDependencyChange(uplinkName string, uplinkConfig map[string]
 						n.logger.Debug("Re-adding instance OVN NIC port to apply ingress mode changes", logger.Ctx{"project": inst.Project, "instance": inst.Name, "device": devName})
 						_, _, err = n.InstanceDevicePortStart(&OVNInstanceNICSetupOpts{
 							InstanceUUID: instanceUUID,
-							DNSName:      inst.Name,
+							DNSName:      instance.DNSName(inst.Name, db.ExpandInstanceConfig(inst.Config, inst.Profiles)),
 							DeviceName:   devName,
 							DeviceConfig: devConfig,
 							UplinkConfig: uplinkConfig,
@@ -5265,6 +5348,7 @@ func (n *ovn) handleDependencyChange(uplinkName string, uplinkConfig map[string]
 }
 
 // forwardFlattenVIPs flattens forwards into format compatible with OVN load balancers.
+// Consecutive listen ports going to consecutive target ports (or to the same target port) are grouped into port ranges.
 func (n *ovn) forwardFlattenVIPs(listenAddress net.IP, defaultTargetAddress net.IP, portMaps []*forwardPortMap) []networkOVN.OVNLoadBalancerVIP {
 	var vips []networkOVN.OVNLoadBalancerVIP
 
@@ -5277,6 +5361,7 @@ func (n *ovn) forwardFlattenVIPs(listenAddress net.IP, defaultTargetAddress net.
 
 	for _, portMap := range portMaps {
 		targetPortsLen := len(portMap.target.ports)
+		var vip *networkOVN.OVNLoadBalancerVIP
 
 		for i, lp := range portMap.listenPorts {
 			targetPort := lp // Default to using same port as listen port for target port.
@@ -5290,17 +5375,44 @@ func (n *ovn) forwardFlattenVIPs(listenAddress net.IP, defaultTargetAddress net.
 				targetPort = portMap.target.ports[i]
 			}
 
-			vips = append(vips, networkOVN.OVNLoadBalancerVIP{
-				ListenAddress: listenAddress,
-				Protocol:      portMap.protocol,
-				ListenPort:    lp,
+			// Extend the current port range when the listen port follows it and the target port either
+			// follows the target port range or is the single target port.
+			if vip != nil && lp == vip.ListenPort+vip.ListenPortCount {
+				target := &vip.Targets[0]
+
+				if targetPortsLen == 1 && targetPort == target.Port {
+					vip.ListenPortCount++
+					continue
+				}
+
+				if targetPortsLen != 1 && targetPort == target.Port+target.PortCount {
+					vip.ListenPortCount++
+					target.PortCount++
+					continue
+				}
+			}
+
+			if vip != nil {
+				vips = append(vips, *vip)
+			}
+
+			vip = &networkOVN.OVNLoadBalancerVIP{
+				ListenAddress:   listenAddress,
+				Protocol:        portMap.protocol,
+				ListenPort:      lp,
+				ListenPortCount: 1,
 				Targets: []networkOVN.OVNLoadBalancerTarget{
 					{
-						Address: portMap.target.address,
-						Port:    targetPort,
+						Address:   portMap.target.address,
+						Port:      targetPort,
+						PortCount: 1,
 					},
 				},
-			})
+			}
+		}
+
+		if vip != nil {
+			vips = append(vips, *vip)
 		}
 	}
 
@@ -5668,10 +5780,13 @@ func (n *ovn) loadBalancerFlattenVIPs(listenAddress net.IP, portMaps []*loadBala
 					targetPort = target.ports[i]
 				}
 
-				vip.Targets = append(vip.Targets, networkOVN.OVNLoadBalancerTarget{
-					Address: target.address,
-					Port:    targetPort,
-				})
+				// OVN picks a backend evenly, so weights are applied by repeating the backend.
+				for w := 0; w < max(target.weight, 1); w++ {
+					vip.Targets = append(vip.Targets, networkOVN.OVNLoadBalancerTarget{
+						Address: target.address,
+						Port:    targetPort,
+					})
+				}
 			}
 
 			vips = append(vips, vip)
@@ -5981,6 +6096,7 @@ func (n *ovn) LoadBalancerUpdate(listenAddress string, req api.NetworkLoadBalanc
 // LoadBalancerState returns the current state of the load balancer.
 func (n *ovn) LoadBalancerState(lb api.NetworkLoadBalancer) (*api.NetworkLoadBalancerState, error) {
 	lbState := &api.NetworkLoadBalancerState{}
+	lbState.BackendWeight, _ = loadBalancerBackendWeights(lb.Backends)
 
 	if util.IsTrue(lb.Config["healthcheck"]) {
 		lbState.BackendHealth = map[string]api.NetworkLoadBalancerStateBackendHealth{}
@@ -6219,6 +6335,165 @@ func (n *ovn) Leases(projectName string, clientType request.ClientType) ([]api.N
 	return leases, nil
 }
 
+// ovnTraceEndpoint is one end of a traced packet on the internal switch.
+type ovnTraceEndpoint struct {
+	portName networkOVN.OVNSwitchPort
+	hwAddr   net.HardwareAddr
+}
+
+// Trace runs an OVN logical flow trace for a packet between two addresses, at least one of which must belong to
+// an instance NIC on the network. The other address is considered to be reached through the network's router.
+// Only the network ACLs and instances visible from the given project are named in the trace output.
+func (n *ovn) Trace(projectName string, req api.NetworkTracePost) (*api.NetworkTrace, error) {
+	ctx := context.TODO()
+
+	srcIP := net.ParseIP(req.Source)
+	if srcIP == nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid source address %q", req.Source)
+	}
+
+	dstIP := net.ParseIP(req.Destination)
+	if dstIP == nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid destination address %q", req.Destination)
+	}
+
+	if (srcIP.To4() == nil) != (dstIP.To4() == nil) {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Source and destination addresses must be of the same family")
+	}
+
+	protocol := req.Protocol
+	if protocol == "" {
+		protocol = "tcp"
+	}
+
+	if !slices.Contains([]string{"tcp", "udp", "icmp"}, protocol) {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid protocol %q, must be one of tcp, udp or icmp", protocol)
+	}
+
+	if protocol == "icmp" && req.Port != 0 {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "A port can't be used with the icmp protocol")
+	}
+
+	if protocol != "icmp" && (req.Port < 1 || req.Port > 65535) {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "A destination port between 1 and 65535 is required with the %s protocol", protocol)
+	}
+
+	// Describe the OVN objects which may show up in the trace in Incus terms.
+	labels := map[string]string{
+		string(n.getIntSwitchName()):                fmt.Sprintf("Internal switch of network %q", n.name),
+		string(n.getIntSwitchRouterPortName()):      fmt.Sprintf("Router port of network %q", n.name),
+		string(n.getRouterName()):                   fmt.Sprintf("Router of network %q", n.name),
+		string(acl.OVNIntSwitchPortGroupName(n.id)): fmt.Sprintf("Default ACL rules of network %q", n.name),
+	}
+
+	err := n.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		acls, err := dbCluster.GetNetworkACLs(ctx, tx.Tx(), dbCluster.NetworkACLFilter{Project: &n.project})
+		if err != nil {
+			return err
+		}
+
+		for _, netACL := range acls {
+			label := fmt.Sprintf("Network ACL %q", netACL.Name)
+			labels[string(acl.OVNACLPortGroupName(int64(netACL.ID)))] = label
+			labels[string(acl.OVNACLNetworkPortGroupName(int64(netACL.ID), n.id))] = label
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network ACLs: %w", err)
+	}
+
+	// Find the instance NICs owning the addresses.
+	var src, dst *ovnTraceEndpoint
+	err = UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		instanceUUID := inst.Config["volatile.uuid"]
+		if instanceUUID == "" {
+			return nil
+		}
+
+		portName := n.getInstanceDevicePortName(instanceUUID, nicName)
+
+		// Instances from other projects sharing the network are left unnamed.
+		if inst.Project == projectName {
+			labels[string(portName)] = fmt.Sprintf("Instance %q NIC %q", inst.Name, nicName)
+		}
+
+		devIPs, err := n.InstanceDevicePortIPs(instanceUUID, nicName)
+		if err != nil {
+			return nil // The NIC isn't started.
+		}
+
+		hwAddr := nicConfig["hwaddr"]
+		if hwAddr == "" {
+			hwAddr = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+		}
+
+		mac, err := net.ParseMAC(hwAddr)
+		if err != nil {
+			return nil
+		}
+
+		for _, ip := range devIPs {
+			if ip.Equal(srcIP) {
+				src = &ovnTraceEndpoint{portName: portName, hwAddr: mac}
+			}
+
+			if ip.Equal(dstIP) {
+				dst = &ovnTraceEndpoint{portName: portName, hwAddr: mac}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if src == nil && dst == nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Neither the source nor the destination address belong to a running instance on network %q", n.name)
+	}
+
+	// Addresses outside of the network are reached through its router.
+	routerMAC, err := n.getRouterMAC()
+	if err != nil {
+		return nil, err
+	}
+
+	if src == nil {
+		src = &ovnTraceEndpoint{portName: n.getIntSwitchRouterPortName(), hwAddr: routerMAC}
+	}
+
+	if dst == nil {
+		dst = &ovnTraceEndpoint{hwAddr: routerMAC}
+	}
+
+	// Build the microflow.
+	ipFamily := "ip4"
+	icmpMatch := "icmp4.type == 8"
+	if srcIP.To4() == nil {
+		ipFamily = "ip6"
+		icmpMatch = "icmp6.type == 128"
+	}
+
+	microflow := fmt.Sprintf(`inport == "%s" && eth.src == %s && eth.dst == %s && %s.src == %s && %s.dst == %s && ip.ttl == 64`, src.portName, src.hwAddr.String(), dst.hwAddr.String(), ipFamily, srcIP.String(), ipFamily, dstIP.String())
+	if protocol == "icmp" {
+		microflow += " && " + icmpMatch
+	} else {
+		microflow += fmt.Sprintf(" && %s.src == 32768 && %s.dst == %d", protocol, protocol, req.Port)
+	}
+
+	output, err := n.ovnsb.Trace(ctx, n.getIntSwitchName(), microflow)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.NetworkTrace{
+		Microflow: microflow,
+		Output:    networkOVN.TraceAnnotate(output, labels),
+	}, nil
+}
+
 // localPeerCreate creates a network peering with another local network.
 func (n *ovn) localPeerCreate(peer api.NetworkPeersPost) error {
 	ctx := context.TODO()
@@ -0,0 +1,140 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	networkOVN "github.com/lxc/incus/v6/internal/server/network/ovn"
+)
+
+func TestLoadBalancerFlattenVIPs(t *testing.T) {
+	listenAddress := net.ParseIP("198.51.100.1")
+	c1 := net.ParseIP("10.0.0.1")
+	c2 := net.ParseIP("10.0.0.2")
+
+	target := func(address net.IP, port uint64) networkOVN.OVNLoadBalancerTarget {
+		return networkOVN.OVNLoadBalancerTarget{Address: address, Port: port}
+	}
+
+	tests := []struct {
+		name     string
+		portMaps []*loadBalancerPortMap
+		want     []networkOVN.OVNLoadBalancerVIP
+	}{
+		{
+			name: "Unweighted backends",
+			portMaps: []*loadBalancerPortMap{{
+				listenPorts: []uint64{80},
+				protocol:    "tcp",
+				targets:     []forwardTarget{{address: c1}, {address: c2, ports: []uint64{8080}}},
+			}},
+			want: []networkOVN.OVNLoadBalancerVIP{{
+				ListenAddress: listenAddress,
+				Protocol:      "tcp",
+				ListenPort:    80,
+				Targets:       []networkOVN.OVNLoadBalancerTarget{target(c1, 80), target(c2, 8080)},
+			}},
+		},
+		{
+			name: "Weighted backends",
+			portMaps: []*loadBalancerPortMap{{
+				listenPorts: []uint64{80},
+				protocol:    "tcp",
+				targets:     []forwardTarget{{address: c1, weight: 3}, {address: c2, weight: 1}},
+			}},
+			want: []networkOVN.OVNLoadBalancerVIP{{
+				ListenAddress: listenAddress,
+				Protocol:      "tcp",
+				ListenPort:    80,
+				Targets:       []networkOVN.OVNLoadBalancerTarget{target(c1, 80), target(c1, 80), target(c1, 80), target(c2, 80)},
+			}},
+		},
+		{
+			name: "Weighted backends with port ranges",
+			portMaps: []*loadBalancerPortMap{{
+				listenPorts: []uint64{80, 81},
+				protocol:    "udp",
+				targets:     []forwardTarget{{address: c1, ports: []uint64{90, 91}, weight: 2}, {address: c2, ports: []uint64{100}}},
+			}},
+			want: []networkOVN.OVNLoadBalancerVIP{
+				{
+					ListenAddress: listenAddress,
+					Protocol:      "udp",
+					ListenPort:    80,
+					Targets:       []networkOVN.OVNLoadBalancerTarget{target(c1, 90), target(c1, 90), target(c2, 100)},
+				},
+				{
+					ListenAddress: listenAddress,
+					Protocol:      "udp",
+					ListenPort:    81,
+					Targets:       []networkOVN.OVNLoadBalancerTarget{target(c1, 91), target(c1, 91), target(c2, 100)},
+				},
+			},
+		},
+	}
+
+	n := &ovn{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, n.loadBalancerFlattenVIPs(listenAddress, tt.portMaps))
+		})
+	}
+}
+
+func TestForwardFlattenVIPs(t *testing.T) {
+	listenAddress := net.ParseIP("198.51.100.1")
+	c1 := net.ParseIP("10.0.0.1")
+
+	vip := func(protocol string, listenPort uint64, listenPortCount uint64, targetPort uint64, targetPortCount uint64) networkOVN.OVNLoadBalancerVIP {
+		return networkOVN.OVNLoadBalancerVIP{
+			ListenAddress:   listenAddress,
+			Protocol:        protocol,
+			ListenPort:      listenPort,
+			ListenPortCount: listenPortCount,
+			Targets:         []networkOVN.OVNLoadBalancerTarget{{Address: c1, Port: targetPort, PortCount: targetPortCount}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		portMaps []*forwardPortMap
+		want     []networkOVN.OVNLoadBalancerVIP
+	}{
+		{
+			name: "Same ports",
+			portMaps: []*forwardPortMap{{
+				listenPorts: []uint64{80, 81, 82, 443},
+				protocol:    "tcp",
+				target:      forwardTarget{address: c1},
+			}},
+			want: []networkOVN.OVNLoadBalancerVIP{vip("tcp", 80, 3, 80, 3), vip("tcp", 443, 1, 443, 1)},
+		},
+		{
+			name: "Shifted port range",
+			portMaps: []*forwardPortMap{{
+				listenPorts: []uint64{20000, 20001, 20002, 20003},
+				protocol:    "udp",
+				target:      forwardTarget{address: c1, ports: []uint64{30000, 30001, 30002, 40000}},
+			}},
+			want: []networkOVN.OVNLoadBalancerVIP{vip("udp", 20000, 3, 30000, 3), vip("udp", 20003, 1, 40000, 1)},
+		},
+		{
+			name: "Single target port",
+			portMaps: []*forwardPortMap{{
+				listenPorts: []uint64{80, 81, 82},
+				protocol:    "tcp",
+				target:      forwardTarget{address: c1, ports: []uint64{8080}},
+			}},
+			want: []networkOVN.OVNLoadBalancerVIP{vip("tcp", 80, 3, 8080, 1)},
+		},
+	}
+
+	n := &ovn{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, n.forwardFlattenVIPs(listenAddress, nil, tt.portMaps))
+		})
+	}
+}
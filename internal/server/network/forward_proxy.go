@@ -0,0 +1,419 @@
+package network
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/netutil"
+
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/validate"
+)
+
+// forwardProxyTimeout is the time allowed for a client to send its request headers or TLS hello and for the
+// connection to the target to be established.
+const forwardProxyTimeout = 30 * time.Second
+
+// forwardProxyIdleTimeout is the time after which a connection without any traffic is closed.
+const forwardProxyIdleTimeout = 2 * time.Minute
+
+// forwardProxyWriteTimeout is the time allowed for sending a HTTP response back to the client.
+// Upgraded connections (such as websockets) aren't subject to it and only use the idle timeout.
+const forwardProxyWriteTimeout = 10 * time.Minute
+
+// forwardProxyMaxConnections is the default number of connections a listener handles at once.
+// Further connections wait to be accepted until others are closed.
+const forwardProxyMaxConnections = 1024
+
+// forwardProxies tracks the running HTTP(S) forward listeners, indexed by network ID and then listen address.
+var forwardProxies = map[int64]map[string]*forwardProxy{}
+
+var forwardProxiesMu sync.Mutex
+
+// forwardProxyRoute routes requests for any of the hostnames to the target address.
+type forwardProxyRoute struct {
+	hostnames []string
+	target    string
+}
+
+// forwardProxy is a listener routing HTTP requests or TLS connections to targets based on the requested hostname.
+// It listens on a local address of the network, to which the firewall forwards the traffic of the listen address.
+type forwardProxy struct {
+	protocol    string
+	bindAddress net.IP
+	listener    net.Listener
+	server      *http.Server
+
+	// Maximum number of concurrent connections, defaults to forwardProxyMaxConnections.
+	maxConnections int
+
+	mu     sync.RWMutex
+	routes []forwardProxyRoute
+}
+
+// forwardNormalizeHostname returns the hostname in lowercase and without any trailing dot, as hostnames are
+// matched regardless of case and of being fully qualified.
+func forwardNormalizeHostname(hostname string) string {
+	return strings.TrimSuffix(strings.ToLower(hostname), ".")
+}
+
+// forwardValidateHostname validates a hostname pattern of a HTTP(S) forward port.
+// The first label can be a "*" wildcard.
+func forwardValidateHostname(hostname string) error {
+	if len(hostname) > 253 {
+		return fmt.Errorf("Hostname must be at most 253 characters long")
+	}
+
+	if net.ParseIP(hostname) != nil {
+		return fmt.Errorf("Hostname cannot be an IP address")
+	}
+
+	labels := strings.Split(hostname, ".")
+	for i, label := range labels {
+		if i == 0 && label == "*" {
+			if len(labels) < 2 {
+				return fmt.Errorf("Wildcard must be followed by a domain")
+			}
+
+			continue
+		}
+
+		// Purely numeric labels are valid in domain names.
+		_, err := strconv.ParseUint(label, 10, 64)
+		if err == nil && len(label) <= 63 {
+			continue
+		}
+
+		err = validate.IsHostname(label)
+		if err != nil {
+			return fmt.Errorf("Invalid label %q: %w", label, err)
+		}
+	}
+
+	return nil
+}
+
+// forwardHostnameMatch returns whether the hostname matches the pattern.
+// A "*.example.com" pattern matches any subdomain of example.com but not example.com itself.
+func forwardHostnameMatch(pattern string, hostname string) bool {
+	suffix, isWildcard := strings.CutPrefix(pattern, "*")
+	if !isWildcard {
+		return pattern == hostname
+	}
+
+	return len(hostname) > len(suffix) && strings.HasSuffix(hostname, suffix)
+}
+
+// target returns the target address for the requested hostname.
+// Exact matches take precedence over wildcards, and longer wildcards take precedence over shorter ones.
+func (p *forwardProxy) target(hostname string) string {
+	host, _, err := net.SplitHostPort(hostname)
+	if err == nil {
+		hostname = host
+	}
+
+	hostname = forwardNormalizeHostname(hostname)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	target := ""
+	targetPattern := ""
+	for _, route := range p.routes {
+		for _, pattern := range route.hostnames {
+			if pattern == hostname {
+				return route.target
+			}
+
+			if len(pattern) > len(targetPattern) && forwardHostnameMatch(pattern, hostname) {
+				target = route.target
+				targetPattern = pattern
+			}
+		}
+	}
+
+	return target
+}
+
+// setRoutes replaces the routes of the proxy.
+func (p *forwardProxy) setRoutes(routes []forwardProxyRoute) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.routes = routes
+}
+
+// ServeHTTP routes HTTP requests to the target matching the Host header.
+func (p *forwardProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := p.target(r.Host)
+	if target == "" {
+		http.Error(w, "No target for the requested host", http.StatusNotFound)
+		return
+	}
+
+	// Upgraded connections are long-lived, so only keep the idle timeout for them.
+	if r.Header.Get("Upgrade") != "" {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(&url.URL{Scheme: "http", Host: target})
+			pr.SetXForwarded()
+			pr.Out.Host = pr.In.Host
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Debug("Failed forwarding HTTP request", logger.Ctx{"host": r.Host, "target": target, "err": err})
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+
+	proxy.ServeHTTP(w, r)
+}
+
+// forwardProxyReadOnlyConn is used to parse a TLS client hello without responding to it.
+type forwardProxyReadOnlyConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (c forwardProxyReadOnlyConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c forwardProxyReadOnlyConn) Write(b []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+// forwardProxyIdleConn closes the connection when no data is read or written for the idle timeout.
+type forwardProxyIdleConn struct {
+	net.Conn
+}
+
+func (c forwardProxyIdleConn) Read(b []byte) (int, error) {
+	_ = c.Conn.SetDeadline(time.Now().Add(forwardProxyIdleTimeout))
+
+	return c.Conn.Read(b)
+}
+
+func (c forwardProxyIdleConn) Write(b []byte) (int, error) {
+	_ = c.Conn.SetDeadline(time.Now().Add(forwardProxyIdleTimeout))
+
+	return c.Conn.Write(b)
+}
+
+// forwardProxyPeekSNI returns the server name requested in the TLS client hello sent on the connection.
+// It also returns the data read from the connection, which needs to be replayed to the target.
+func forwardProxyPeekSNI(conn net.Conn) (string, io.Reader, error) {
+	var hello *tls.ClientHelloInfo
+	buf := &bytes.Buffer{}
+
+	errHelloRead := errors.New("Client hello read")
+	_ = tls.Server(forwardProxyReadOnlyConn{Conn: conn, reader: io.TeeReader(conn, buf)}, &tls.Config{
+		GetConfigForClient: func(info *tls.ClientHelloInfo) (*tls.Config, error) {
+			hello = info
+			return nil, errHelloRead
+		},
+	}).Handshake()
+
+	if hello == nil {
+		return "", nil, fmt.Errorf("Failed reading TLS client hello")
+	}
+
+	return hello.ServerName, buf, nil
+}
+
+// serveTLS routes TLS connections to the target matching the requested server name.
+// The TLS session isn't terminated, the connection is passed through to the target as-is.
+func (p *forwardProxy) serveTLS(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetReadDeadline(time.Now().Add(forwardProxyTimeout))
+	serverName, hello, err := forwardProxyPeekSNI(conn)
+	if err != nil {
+		logger.Debug("Failed reading TLS server name", logger.Ctx{"remote": conn.RemoteAddr().String(), "err": err})
+		return
+	}
+
+	_ = conn.SetReadDeadline(time.Time{})
+
+	target := p.target(serverName)
+	if target == "" {
+		return
+	}
+
+	targetConn, err := net.DialTimeout("tcp", target, forwardProxyTimeout)
+	if err != nil {
+		logger.Debug("Failed connecting to TLS target", logger.Ctx{"serverName": serverName, "target": target, "err": err})
+		return
+	}
+
+	defer func() { _ = targetConn.Close() }()
+
+	done := make(chan struct{}, 2)
+
+	go func() {
+		_, _ = io.Copy(forwardProxyIdleConn{Conn: targetConn}, io.MultiReader(hello, forwardProxyIdleConn{Conn: conn}))
+		tcpConn, ok := targetConn.(*net.TCPConn)
+		if ok {
+			_ = tcpConn.CloseWrite()
+		}
+
+		done <- struct{}{}
+	}()
+
+	go func() {
+		_, _ = io.Copy(forwardProxyIdleConn{Conn: conn}, forwardProxyIdleConn{Conn: targetConn})
+		tcpConn, ok := conn.(*net.TCPConn)
+		if ok {
+			_ = tcpConn.CloseWrite()
+		}
+
+		done <- struct{}{}
+	}()
+
+	<-done
+	<-done
+}
+
+// start starts listening on a random port of the bind address.
+func (p *forwardProxy) start() error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(p.bindAddress.String(), "0"))
+	if err != nil {
+		return err
+	}
+
+	maxConnections := p.maxConnections
+	if maxConnections <= 0 {
+		maxConnections = forwardProxyMaxConnections
+	}
+
+	p.listener = listener
+
+	if p.protocol == "http" {
+		p.server = &http.Server{
+			Handler:           p,
+			ReadHeaderTimeout: forwardProxyTimeout,
+			WriteTimeout:      forwardProxyWriteTimeout,
+			IdleTimeout:       forwardProxyIdleTimeout,
+		}
+
+		go func() { _ = p.server.Serve(netutil.LimitListener(listener, maxConnections)) }()
+
+		return nil
+	}
+
+	// Connections are limited here rather than with a limited listener to keep access to the TCP connections.
+	slots := make(chan struct{}, maxConnections)
+
+	go func() {
+		for {
+			slots <- struct{}{}
+
+			conn, err := listener.Accept()
+			if err != nil {
+				<-slots
+
+				if errors.Is(err, net.ErrClosed) {
+					return
+				}
+
+				continue
+			}
+
+			go func() {
+				defer func() { <-slots }()
+
+				p.serveTLS(conn)
+			}()
+		}
+	}()
+
+	return nil
+}
+
+// stop stops the listener.
+func (p *forwardProxy) stop() error {
+	if p.server != nil {
+		return p.server.Close()
+	}
+
+	return p.listener.Close()
+}
+
+// forwardProxiesApply starts, updates and stops the HTTP(S) forward listeners of a network to match the
+// supplied listeners, indexed by forward listen address and port.
+// Returns the address each listener is bound to, indexed the same way, for the firewall to forward to.
+func forwardProxiesApply(networkID int64, listeners map[string]*forwardProxy) (map[string]*net.TCPAddr, error) {
+	forwardProxiesMu.Lock()
+	defer forwardProxiesMu.Unlock()
+
+	running := forwardProxies[networkID]
+	if running == nil {
+		running = make(map[string]*forwardProxy)
+		forwardProxies[networkID] = running
+	}
+
+	// Stop the listeners that are no longer needed or which changed protocol or bind address.
+	for listenAddress, p := range running {
+		listener, found := listeners[listenAddress]
+		if found && listener.protocol == p.protocol && listener.bindAddress.Equal(p.bindAddress) {
+			continue
+		}
+
+		err := p.stop()
+		if err != nil {
+			return nil, fmt.Errorf("Failed stopping %s forward listener for %q: %w", p.protocol, listenAddress, err)
+		}
+
+		delete(running, listenAddress)
+	}
+
+	// Update the routes of the existing listeners and start the new ones.
+	boundAddresses := make(map[string]*net.TCPAddr, len(listeners))
+	for listenAddress, listener := range listeners {
+		p, found := running[listenAddress]
+		if found {
+			p.setRoutes(listener.routes)
+		} else {
+			err := listener.start()
+			if err != nil {
+				return nil, fmt.Errorf("Failed starting %s forward listener for %q: %w", listener.protocol, listenAddress, err)
+			}
+
+			running[listenAddress] = listener
+			p = listener
+		}
+
+		addr, ok := p.listener.Addr().(*net.TCPAddr)
+		if !ok {
+			return nil, fmt.Errorf("Unexpected address type for %s forward listener for %q", p.protocol, listenAddress)
+		}
+
+		boundAddresses[listenAddress] = addr
+	}
+
+	if len(running) == 0 {
+		delete(forwardProxies, networkID)
+	}
+
+	return boundAddresses, nil
+}
+
+// forwardProxiesRemove stops all the HTTP(S) forward listeners of a network.
+func forwardProxiesRemove(networkID int64) error {
+	_, err := forwardProxiesApply(networkID, nil)
+
+	return err
+}
@@ -0,0 +1,208 @@
+package network
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardValidateHostname(t *testing.T) {
+	for _, hostname := range []string{"example.com", "*.example.com", "www.example.com", "1.example.com", "localhost"} {
+		assert.NoError(t, forwardValidateHostname(hostname), hostname)
+	}
+
+	for _, hostname := range []string{"", "*", "www.*.example.com", "-example.com", "example..com", "192.0.2.1", "exa_mple.com", strings.Repeat("a", 64) + ".com"} {
+		assert.Error(t, forwardValidateHostname(hostname), hostname)
+	}
+}
+
+func TestForwardNormalizeHostname(t *testing.T) {
+	tests := map[string]string{
+		"example.com":      "example.com",
+		"Example.com":      "example.com",
+		"WWW.EXAMPLE.COM.": "www.example.com",
+		"*.Example.com":    "*.example.com",
+		"*.example.com.":   "*.example.com",
+		"localhost":        "localhost",
+	}
+
+	for hostname, normalized := range tests {
+		assert.Equal(t, normalized, forwardNormalizeHostname(hostname), hostname)
+	}
+}
+
+func TestForwardProxyTarget(t *testing.T) {
+	p := &forwardProxy{
+		routes: []forwardProxyRoute{
+			{hostnames: []string{"example.com", "*.example.com"}, target: "192.0.2.1:80"},
+			{hostnames: []string{"*.dev.example.com"}, target: "192.0.2.2:80"},
+			{hostnames: []string{"www.dev.example.com"}, target: "192.0.2.3:80"},
+		},
+	}
+
+	tests := map[string]string{
+		"example.com":          "192.0.2.1:80",
+		"EXAMPLE.com:8080":     "192.0.2.1:80",
+		"example.com.":         "192.0.2.1:80",
+		"www.example.com":      "192.0.2.1:80",
+		"api.dev.example.com":  "192.0.2.2:80",
+		"www.dev.example.com":  "192.0.2.3:80",
+		"example.net":          "",
+		"notexample.com":       "",
+		"www.example.com.evil": "",
+	}
+
+	for hostname, target := range tests {
+		assert.Equal(t, target, p.target(hostname), hostname)
+	}
+}
+
+// forwardProxyTestStart starts a listener on the loopback address routing to the backends.
+func forwardProxyTestStart(t *testing.T, protocol string, backends map[string]*httptest.Server) string {
+	p := &forwardProxy{protocol: protocol, bindAddress: net.ParseIP("127.0.0.1")}
+	for hostname, backend := range backends {
+		p.routes = append(p.routes, forwardProxyRoute{hostnames: []string{hostname}, target: backend.Listener.Addr().String()})
+	}
+
+	require.NoError(t, p.start())
+	t.Cleanup(func() { _ = p.stop() })
+
+	return p.listener.Addr().String()
+}
+
+// forwardProxyTestBackend returns a handler replying with the backend name and the requested host.
+func forwardProxyTestBackend(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "%s %s", name, r.Host)
+	})
+}
+
+func TestForwardProxyHTTP(t *testing.T) {
+	backendA := httptest.NewServer(forwardProxyTestBackend("a"))
+	defer backendA.Close()
+
+	backendB := httptest.NewServer(forwardProxyTestBackend("b"))
+	defer backendB.Close()
+
+	listenAddress := forwardProxyTestStart(t, "http", map[string]*httptest.Server{
+		"a.example.com": backendA,
+		"*.example.net": backendB,
+	})
+
+	get := func(host string) (int, string) {
+		req, err := http.NewRequest("GET", "http://"+listenAddress+"/", nil)
+		require.NoError(t, err)
+		req.Host = host
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := get("a.example.com")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "a a.example.com", body)
+
+	status, body = get("www.example.net")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "b www.example.net", body)
+
+	status, _ = get("b.example.com")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestForwardProxyHTTPS(t *testing.T) {
+	backendA := httptest.NewTLSServer(forwardProxyTestBackend("a"))
+	defer backendA.Close()
+
+	backendB := httptest.NewTLSServer(forwardProxyTestBackend("b"))
+	defer backendB.Close()
+
+	listenAddress := forwardProxyTestStart(t, "https", map[string]*httptest.Server{
+		"a.example.com": backendA,
+		"b.example.com": backendB,
+	})
+
+	get := func(serverName string) (string, error) {
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{ServerName: serverName, InsecureSkipVerify: true},
+			},
+		}
+
+		resp, err := client.Get("https://" + listenAddress + "/")
+		if err != nil {
+			return "", err
+		}
+
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+
+		return string(body), nil
+	}
+
+	body, err := get("a.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "a "+listenAddress, body)
+
+	body, err = get("b.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "b "+listenAddress, body)
+
+	// Connections for unknown server names are closed.
+	_, err = get("c.example.com")
+	assert.Error(t, err)
+}
+
+func TestForwardProxyMaxConnections(t *testing.T) {
+	backend := httptest.NewTLSServer(forwardProxyTestBackend("a"))
+	defer backend.Close()
+
+	p := &forwardProxy{protocol: "https", bindAddress: net.ParseIP("127.0.0.1"), maxConnections: 1}
+	p.routes = []forwardProxyRoute{{hostnames: []string{"a.example.com"}, target: backend.Listener.Addr().String()}}
+	require.NoError(t, p.start())
+	defer func() { _ = p.stop() }()
+
+	listenAddress := p.listener.Addr().String()
+
+	handshake := func() error {
+		conn, err := net.Dial("tcp", listenAddress)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		_ = conn.SetDeadline(time.Now().Add(500 * time.Millisecond))
+
+		return tls.Client(conn, &tls.Config{ServerName: "a.example.com", InsecureSkipVerify: true}).Handshake()
+	}
+
+	// An idle connection holds the only slot.
+	conn, err := net.Dial("tcp", listenAddress)
+	require.NoError(t, err)
+
+	assert.Error(t, handshake())
+
+	// The next connection is handled once the slot is released.
+	require.NoError(t, conn.Close())
+	assert.NoError(t, handshake())
+}
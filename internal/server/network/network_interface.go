@@ -57,12 +57,21 @@ type Network interface {
 	// Status.
 	State() (*api.NetworkState, error)
 	Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error)
+	LeaseDelete(address string, clientType request.ClientType) error
+
+	// Debugging.
+	Trace(projectName string, req api.NetworkTracePost) (*api.NetworkTrace, error)
 
 	// Address Forwards.
 	ForwardCreate(forward api.NetworkForwardsPost, clientType request.ClientType) error
 	ForwardUpdate(listenAddress string, newForward api.NetworkForwardPut, clientType request.ClientType) error
 	ForwardDelete(listenAddress string, clientType request.ClientType) error
 
+	// DHCP reservations.
+	DHCPReservationCreate(reservation api.NetworkDHCPReservationsPost, clientType request.ClientType) error
+	DHCPReservationUpdate(hwaddr string, newReservation api.NetworkDHCPReservationPut, clientType request.ClientType) error
+	DHCPReservationDelete(hwaddr string, clientType request.ClientType) error
+
 	// Load Balancers.
 	LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clientType request.ClientType) error
 	LoadBalancerUpdate(listenAddress string, newLoadBalancer api.NetworkLoadBalancerPut, clientType request.ClientType) error
@@ -11,6 +11,7 @@ import (
 )
 
 var drivers = map[string]func() Network{
+	"bond":     func() Network { return &bond{} },
 	"bridge":   func() Network { return &bridge{} },
 	"macvlan":  func() Network { return &macvlan{} },
 	"sriov":    func() Network { return &sriov{} },
@@ -45,6 +46,23 @@ func LoadByType(driverType string) (Type, error) {
 	return n, nil
 }
 
+// LoadUncreated instantiates a network of the given type which doesn't exist in the database, so that a proposed
+// config can be validated against it.
+func LoadUncreated(s *state.State, projectName string, name string, driverType string, config map[string]string) (Network, error) {
+	driverFunc, ok := drivers[driverType]
+	if !ok {
+		return nil, ErrUnknownDriver
+	}
+
+	n := driverFunc()
+	err := n.init(s, -1, projectName, &api.Network{Name: name, Type: driverType, NetworkPut: api.NetworkPut{Config: config}}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
 // LoadByName loads an instantiated network from the database by project and name.
 func LoadByName(s *state.State, projectName string, name string) (Network, error) {
 	var id int64
@@ -19,6 +19,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/vishvananda/netlink"
+
 	"github.com/lxc/incus/v6/internal/iprange"
 	"github.com/lxc/incus/v6/internal/server/db"
 	"github.com/lxc/incus/v6/internal/server/db/cluster"
@@ -484,7 +486,7 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 				}
 			}
 
-			entries[d["parent"]] = append(entries[d["parent"]], []string{d["hwaddr"], inst.Project().Name, inst.Name(), d["ipv4.address"], d["ipv6.address"], deviceName})
+			entries[d["parent"]] = append(entries[d["parent"]], []string{d["hwaddr"], inst.Project().Name, inst.Name(), d["ipv4.address"], d["ipv6.address"], deviceName, instance.DNSName(inst.Name(), inst.ExpandedConfig())})
 		}
 	}
 
@@ -526,6 +528,7 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 			ipv4Address := entry[3]
 			ipv6Address := entry[4]
 			deviceName := entry[5]
+			dnsName := entry[6]
 			line := hwaddr
 
 			// Look for duplicates.
@@ -562,7 +565,25 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 			}
 
 			// Generate the dhcp-host line.
-			err := dnsmasq.UpdateStaticEntry(network, projectName, cName, deviceName, config, hwaddr, ipv4Address, ipv6Address)
+			err := dnsmasq.UpdateStaticEntry(network, projectName, cName, dnsName, deviceName, config, hwaddr, ipv4Address, ipv6Address)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Apply the DHCP reservations.
+		var reservations []api.NetworkDHCPReservation
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			reservations, err = tx.GetNetworkDHCPReservations(ctx, n.ID())
+
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed loading DHCP reservations for network %q: %w", network, err)
+		}
+
+		for _, reservation := range reservations {
+			err = dnsmasq.UpdateReservationEntry(network, reservation.Hwaddr, reservation.IPv4Address, reservation.IPv6Address, reservation.Hostname)
 			if err != nil {
 				return err
 			}
@@ -926,6 +947,75 @@ func usesIPv4Firewall(netConfig map[string]string) bool {
 	return false
 }
 
+// usesConntrackLimits returns whether network config will need connection tracking limits in the firewall.
+func usesConntrackLimits(netConfig map[string]string) bool {
+	if netConfig == nil {
+		return false
+	}
+
+	return netConfig["conntrack.max"] != "" || netConfig["conntrack.timeout.tcp"] != "" || netConfig["conntrack.timeout.udp"] != ""
+}
+
+// conntrackHostMax returns the size of the host connection tracking table.
+func conntrackHostMax() (uint64, error) {
+	content, err := os.ReadFile("/proc/sys/net/netfilter/nf_conntrack_max")
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// conntrackCount returns the number of tracked connections originating from the specified interface.
+// As the tracked connections don't record their input interface, those whose source address is routed
+// through the interface are counted.
+func conntrackCount(ifName string) (uint64, error) {
+	link, err := netlink.LinkByName(ifName)
+	if err != nil {
+		return 0, err
+	}
+
+	routes, err := netlink.RouteList(link, netlink.FAMILY_ALL)
+	if err != nil {
+		return 0, err
+	}
+
+	subnets := []*net.IPNet{}
+	for _, route := range routes {
+		if route.Dst != nil {
+			subnets = append(subnets, route.Dst)
+		}
+	}
+
+	var flows []*netlink.ConntrackFlow
+	for _, family := range []netlink.InetFamily{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+		familyFlows, err := netlink.ConntrackTableList(netlink.ConntrackTable, family)
+		if err != nil {
+			return 0, err
+		}
+
+		flows = append(flows, familyFlows...)
+	}
+
+	return conntrackCountFrom(flows, subnets), nil
+}
+
+// conntrackCountFrom returns the number of flows originating from the specified subnets.
+func conntrackCountFrom(flows []*netlink.ConntrackFlow, subnets []*net.IPNet) uint64 {
+	var count uint64
+
+	for _, flow := range flows {
+		for _, subnet := range subnets {
+			if subnet.Contains(flow.Forward.SrcIP) {
+				count++
+				break
+			}
+		}
+	}
+
+	return count
+}
+
 // usesIPv6Firewall returns whether network config will need to use the IPv6 firewall.
 func usesIPv6Firewall(netConfig map[string]string) bool {
 	if netConfig == nil {
@@ -1481,3 +1571,50 @@ func validateExternalInterfaces(value string) error {
 
 	return nil
 }
+
+// loadBalancerMaxWeight is the maximum combined weight of the backends of a load balancer.
+// Weights are applied by listing each backend as many times as its weight, this limits the size of the resulting backend lists.
+const loadBalancerMaxWeight = 256
+
+// loadBalancerBackendWeights returns the effective weight of each load balancer backend.
+// Unset weights default to 1 and all weights are reduced by their greatest common divisor.
+// If the combined weight still exceeds loadBalancerMaxWeight, weighted selection can't be applied, every backend
+// is given the same weight and false is returned.
+func loadBalancerBackendWeights(backends []api.NetworkLoadBalancerBackend) (map[string]int, bool) {
+	weights := make(map[string]int, len(backends))
+
+	gcd := func(a int, b int) int {
+		for b != 0 {
+			a, b = b, a%b
+		}
+
+		return a
+	}
+
+	divisor := 0
+	for _, backend := range backends {
+		weight := 1
+		if backend.Weight != nil {
+			weight = *backend.Weight
+		}
+
+		weights[backend.Name] = weight
+		divisor = gcd(divisor, weight)
+	}
+
+	total := 0
+	for name := range weights {
+		weights[name] /= divisor
+		total += weights[name]
+	}
+
+	if total <= loadBalancerMaxWeight {
+		return weights, true
+	}
+
+	for name := range weights {
+		weights[name] = 1
+	}
+
+	return weights, false
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/vishvananda/netlink"
+
 	"github.com/lxc/incus/v6/internal/iprange"
+	"github.com/lxc/incus/v6/shared/api"
 )
 
 func Example_parseIPRange() {
@@ -137,3 +140,52 @@ func Example_ipRangesOverlap() {
 	// Range1: 10.1.1.4, Range2: 10.1.1.8-10.1.1.9, overlapped: false
 	// Range1: 10.1.1.8-10.1.1.9, Range2: 10.1.1.4, overlapped: false
 }
+
+func Example_loadBalancerBackendWeights() {
+	weight := func(weight int) *int { return &weight }
+
+	backendSets := [][]api.NetworkLoadBalancerBackend{
+		{{Name: "c1"}, {Name: "c2"}},
+		{{Name: "c1", Weight: weight(4)}, {Name: "c2", Weight: weight(2)}, {Name: "c3"}},
+		{{Name: "c1", Weight: weight(30)}, {Name: "c2", Weight: weight(20)}},
+		{{Name: "c1", Weight: weight(300)}, {Name: "c2", Weight: weight(1)}},
+	}
+
+	for _, backends := range backendSets {
+		weights, weighted := loadBalancerBackendWeights(backends)
+		fmt.Printf("Weights: %v, weighted: %t\n", weights, weighted)
+	}
+
+	// Output:
+	// Weights: map[c1:1 c2:1], weighted: true
+	// Weights: map[c1:4 c2:2 c3:1], weighted: true
+	// Weights: map[c1:3 c2:2], weighted: true
+	// Weights: map[c1:1 c2:1], weighted: false
+}
+
+func Example_conntrackCountFrom() {
+	_, bridgeSubnetV4, _ := net.ParseCIDR("10.0.0.0/24")
+	_, bridgeSubnetV6, _ := net.ParseCIDR("fd42::/64")
+	_, routedSubnet, _ := net.ParseCIDR("192.0.2.0/28")
+
+	flow := func(srcIP string) *netlink.ConntrackFlow {
+		return &netlink.ConntrackFlow{Forward: netlink.IPTuple{SrcIP: net.ParseIP(srcIP)}}
+	}
+
+	flows := []*netlink.ConntrackFlow{
+		flow("10.0.0.10"),
+		flow("10.0.0.11"),
+		flow("fd42::10"),
+		flow("192.0.2.5"),
+		flow("10.0.1.10"),
+		flow("198.51.100.1"),
+	}
+
+	fmt.Println(conntrackCountFrom(flows, []*net.IPNet{bridgeSubnetV4, bridgeSubnetV6}))
+	fmt.Println(conntrackCountFrom(flows, []*net.IPNet{bridgeSubnetV4, bridgeSubnetV6, routedSubnet}))
+	fmt.Println(conntrackCountFrom(flows, nil))
+
+	// Output: 3
+	// 4
+	// 0
+}
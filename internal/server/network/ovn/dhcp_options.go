@@ -0,0 +1,203 @@
+package ovn
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/util"
+	"github.com/lxc/incus/v6/shared/validate"
+)
+
+// dhcpOptionType describes how the value of a DHCP option is encoded in the DHCP_Options table.
+type dhcpOptionType int
+
+const (
+	dhcpOptionTypeIPv4 dhcpOptionType = iota
+	dhcpOptionTypeIPv4List
+	dhcpOptionTypeBool
+	dhcpOptionTypeUint8
+	dhcpOptionTypeUint32
+	dhcpOptionTypeString
+	dhcpOptionTypeStaticRoutes
+	dhcpOptionTypeDomainList
+)
+
+// dhcpOption is a DHCP option supported by OVN's native DHCP server.
+type dhcpOption struct {
+	name      string
+	valueType dhcpOptionType
+}
+
+// dhcpDomainLabelRegex matches a single label of a domain name.
+var dhcpDomainLabelRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// dhcpv4Options lists the DHCPv4 options OVN knows how to encode, indexed by option code.
+var dhcpv4Options = map[uint64]dhcpOption{
+	7:   {name: "log_server", valueType: dhcpOptionTypeIPv4List},
+	9:   {name: "lpr_server", valueType: dhcpOptionTypeIPv4List},
+	12:  {name: "hostname", valueType: dhcpOptionTypeString},
+	16:  {name: "swap_server", valueType: dhcpOptionTypeIPv4},
+	19:  {name: "ip_forward_enable", valueType: dhcpOptionTypeBool},
+	21:  {name: "policy_filter", valueType: dhcpOptionTypeIPv4List},
+	23:  {name: "default_ttl", valueType: dhcpOptionTypeUint8},
+	28:  {name: "broadcast_address", valueType: dhcpOptionTypeIPv4},
+	31:  {name: "router_discovery", valueType: dhcpOptionTypeBool},
+	32:  {name: "router_solicitation", valueType: dhcpOptionTypeIPv4},
+	35:  {name: "arp_cache_timeout", valueType: dhcpOptionTypeUint32},
+	36:  {name: "ethernet_encap", valueType: dhcpOptionTypeBool},
+	37:  {name: "tcp_ttl", valueType: dhcpOptionTypeUint8},
+	38:  {name: "tcp_keepalive_interval", valueType: dhcpOptionTypeUint32},
+	41:  {name: "nis_server", valueType: dhcpOptionTypeIPv4List},
+	42:  {name: "ntp_server", valueType: dhcpOptionTypeIPv4List},
+	44:  {name: "netbios_name_server", valueType: dhcpOptionTypeIPv4List},
+	46:  {name: "netbios_node_type", valueType: dhcpOptionTypeUint8},
+	58:  {name: "T1", valueType: dhcpOptionTypeUint32},
+	59:  {name: "T2", valueType: dhcpOptionTypeUint32},
+	66:  {name: "tftp_server", valueType: dhcpOptionTypeString},
+	67:  {name: "bootfile_name", valueType: dhcpOptionTypeString},
+	119: {name: "domain_search_list", valueType: dhcpOptionTypeDomainList},
+	150: {name: "tftp_server_address", valueType: dhcpOptionTypeIPv4List},
+	210: {name: "path_prefix", valueType: dhcpOptionTypeString},
+	249: {name: "ms_classless_static_route", valueType: dhcpOptionTypeStaticRoutes},
+	252: {name: "wpad", valueType: dhcpOptionTypeString},
+	253: {name: "next_server", valueType: dhcpOptionTypeIPv4},
+	254: {name: "bootfile_name_alt", valueType: dhcpOptionTypeString},
+}
+
+// dhcpv4OptionsReserved lists the DHCPv4 options that Incus manages itself, along with the setting to use instead.
+var dhcpv4OptionsReserved = map[uint64]string{
+	1:   "ipv4.address",
+	3:   "ipv4.address",
+	6:   "dns.nameservers",
+	15:  "dns.domain",
+	26:  "bridge.mtu",
+	51:  "ipv4.dhcp.expiry",
+	54:  "ipv4.address",
+	121: "ipv4.dhcp.routes",
+}
+
+// dhcpv6Options lists the DHCPv6 options OVN knows how to encode, indexed by option code.
+var dhcpv6Options = map[uint64]dhcpOption{
+	39:  {name: "fqdn", valueType: dhcpOptionTypeString},
+	59:  {name: "bootfile_name", valueType: dhcpOptionTypeString},
+	254: {name: "bootfile_name_alt", valueType: dhcpOptionTypeString},
+}
+
+// dhcpv6OptionsReserved lists the DHCPv6 options that Incus manages itself, along with the setting to use instead.
+var dhcpv6OptionsReserved = map[uint64]string{
+	2:  "bridge.hwaddr",
+	5:  "ipv6.dhcp.stateful",
+	23: "dns.nameservers",
+	24: "dns.search",
+}
+
+// DHCPv4Option validates a raw DHCPv4 option and returns the OVN option name and encoded value for it.
+func DHCPv4Option(code string, value string) (string, string, error) {
+	return dhcpOptionEncode(dhcpv4Options, dhcpv4OptionsReserved, code, value)
+}
+
+// DHCPv6Option validates a raw DHCPv6 option and returns the OVN option name and encoded value for it.
+func DHCPv6Option(code string, value string) (string, string, error) {
+	return dhcpOptionEncode(dhcpv6Options, dhcpv6OptionsReserved, code, value)
+}
+
+// dhcpOptionEncode looks up the option code in the supported options and encodes the value for OVN.
+func dhcpOptionEncode(options map[uint64]dhcpOption, reserved map[uint64]string, code string, value string) (string, string, error) {
+	codeNum, err := strconv.ParseUint(code, 10, 8)
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid DHCP option code %q", code)
+	}
+
+	setting, found := reserved[codeNum]
+	if found {
+		return "", "", fmt.Errorf("DHCP option %d is managed through %q", codeNum, setting)
+	}
+
+	option, found := options[codeNum]
+	if !found {
+		return "", "", fmt.Errorf("DHCP option %d isn't supported by OVN", codeNum)
+	}
+
+	encoded, err := dhcpOptionEncodeValue(option.valueType, value)
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid value for DHCP option %d (%s): %w", codeNum, option.name, err)
+	}
+
+	return option.name, encoded, nil
+}
+
+// dhcpOptionEncodeValue validates value against the option type and returns it in the form OVN expects.
+func dhcpOptionEncodeValue(valueType dhcpOptionType, value string) (string, error) {
+	switch valueType {
+	case dhcpOptionTypeIPv4:
+		err := validate.IsNetworkAddressV4(value)
+		if err != nil {
+			return "", err
+		}
+
+		return value, nil
+	case dhcpOptionTypeIPv4List:
+		addresses := util.SplitNTrimSpace(value, ",", -1, true)
+		for _, address := range addresses {
+			err := validate.IsNetworkAddressV4(address)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		return fmt.Sprintf("{%s}", strings.Join(addresses, ",")), nil
+	case dhcpOptionTypeBool:
+		if util.IsTrue(value) {
+			return "1", nil
+		} else if util.IsFalse(value) {
+			return "0", nil
+		}
+
+		return "", fmt.Errorf("Invalid boolean %q", value)
+	case dhcpOptionTypeUint8, dhcpOptionTypeUint32:
+		bitSize := 32
+		if valueType == dhcpOptionTypeUint8 {
+			bitSize = 8
+		}
+
+		number, err := strconv.ParseUint(value, 10, bitSize)
+		if err != nil {
+			return "", fmt.Errorf("Must be an unsigned %d-bit integer", bitSize)
+		}
+
+		return strconv.FormatUint(number, 10), nil
+	case dhcpOptionTypeString:
+		if value == "" || strings.ContainsAny(value, "\"\\") {
+			return "", fmt.Errorf("Must be a non-empty string without quotes or backslashes")
+		}
+
+		// Special quoting to allow arbitrary strings.
+		return fmt.Sprintf(`"%s"`, value), nil
+	case dhcpOptionTypeStaticRoutes:
+		err := validate.IsDHCPRouteList(value)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("{%s}", value), nil
+	case dhcpOptionTypeDomainList:
+		domains := util.SplitNTrimSpace(value, ",", -1, true)
+		if len(domains) == 0 {
+			return "", fmt.Errorf("Must be a comma-separated list of domains")
+		}
+
+		for _, domain := range domains {
+			for _, label := range strings.Split(domain, ".") {
+				if !dhcpDomainLabelRegex.MatchString(label) {
+					return "", fmt.Errorf("Invalid domain %q", domain)
+				}
+			}
+		}
+
+		return fmt.Sprintf(`"%s"`, strings.Join(domains, ",")), nil
+	}
+
+	return "", fmt.Errorf("Unknown DHCP option type")
+}
@@ -0,0 +1,67 @@
+package ovn
+
+import (
+	"testing"
+)
+
+func TestDHCPv4Option(t *testing.T) {
+	tests := []struct {
+		code      string
+		value     string
+		wantName  string
+		wantValue string
+		wantErr   bool
+	}{
+		{code: "42", value: "10.0.0.1, 10.0.0.2", wantName: "ntp_server", wantValue: "{10.0.0.1,10.0.0.2}"},
+		{code: "19", value: "true", wantName: "ip_forward_enable", wantValue: "1"},
+		{code: "23", value: "64", wantName: "default_ttl", wantValue: "64"},
+		{code: "23", value: "256", wantErr: true},
+		{code: "67", value: "ipxe.efi", wantName: "bootfile_name", wantValue: `"ipxe.efi"`},
+		{code: "67", value: `ipxe".efi`, wantErr: true},
+		{code: "249", value: "10.1.0.0/16,10.0.0.1", wantName: "ms_classless_static_route", wantValue: "{10.1.0.0/16,10.0.0.1}"},
+		{code: "119", value: "example.com, lab.example.org", wantName: "domain_search_list", wantValue: `"example.com,lab.example.org"`},
+		{code: "119", value: "example..com", wantErr: true},
+		{code: "119", value: `example.com"`, wantErr: true},
+		{code: "119", value: "", wantErr: true},
+		{code: "42", value: "fd00::1", wantErr: true},
+		{code: "3", value: "10.0.0.1", wantErr: true},
+		{code: "200", value: "foo", wantErr: true},
+		{code: "foo", value: "foo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		name, value, err := DHCPv4Option(tt.code, tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("DHCPv4Option(%q, %q) expected an error", tt.code, tt.value)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("DHCPv4Option(%q, %q) unexpected error: %v", tt.code, tt.value, err)
+			continue
+		}
+
+		if name != tt.wantName || value != tt.wantValue {
+			t.Errorf("DHCPv4Option(%q, %q) = %q, %q; want %q, %q", tt.code, tt.value, name, value, tt.wantName, tt.wantValue)
+		}
+	}
+}
+
+func TestDHCPv6Option(t *testing.T) {
+	name, value, err := DHCPv6Option("59", "http://boot/ipxe.efi")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if name != "bootfile_name" || value != `"http://boot/ipxe.efi"` {
+		t.Errorf("Unexpected result %q, %q", name, value)
+	}
+
+	_, _, err = DHCPv6Option("23", "fd00::1")
+	if err == nil {
+		t.Errorf("Expected managed option to be rejected")
+	}
+}
@@ -96,8 +96,9 @@ type OVNIPv6RAOpts struct {
 
 // OVNDHCPOptsSet is an existing DHCP options set in the northbound database.
 type OVNDHCPOptsSet struct {
-	UUID OVNDHCPOptionsUUID
-	CIDR *net.IPNet
+	UUID    OVNDHCPOptionsUUID
+	CIDR    *net.IPNet
+	Options map[string]string
 }
 
 // OVNDHCPv4Opts IPv4 DHCP options that can be applied to a switch port.
@@ -112,6 +113,7 @@ type OVNDHCPv4Opts struct {
 	Netmask            string
 	DNSSearchList      []string
 	StaticRoutes       string
+	ExtraOptions       map[string]string // Additional raw options keyed by OVN option name.
 }
 
 // OVNDHCPv6Opts IPv6 DHCP option set that can be created (and then applied to a switch port by resulting ID).
@@ -119,6 +121,7 @@ type OVNDHCPv6Opts struct {
 	ServerID           net.HardwareAddr
 	RecursiveDNSServer []net.IP
 	DNSSearchList      []string
+	ExtraOptions       map[string]string // Additional raw options keyed by OVN option name.
 }
 
 // OVNSwitchPortOpts options that can be applied to a switch port.
@@ -147,8 +150,9 @@ type OVNACLRule struct {
 
 // OVNLoadBalancerTarget represents an OVN load balancer Virtual IP target.
 type OVNLoadBalancerTarget struct {
-	Address net.IP
-	Port    uint64
+	Address   net.IP
+	Port      uint64
+	PortCount uint64 // Number of target ports the listen port range is shifted to. When unset, all listen ports go to Port.
 }
 
 // OVNLoadBalancerHealthCheck represents an OVN load balancer health checker.
@@ -164,11 +168,46 @@ type OVNLoadBalancerHealthCheck struct {
 
 // OVNLoadBalancerVIP represents a OVN load balancer Virtual IP entry.
 type OVNLoadBalancerVIP struct {
-	HealthCheck   *OVNLoadBalancerHealthCheck
-	Protocol      string // Either "tcp" or "udp". But only applies to port based VIPs.
-	ListenAddress net.IP
-	ListenPort    uint64
-	Targets       []OVNLoadBalancerTarget
+	HealthCheck     *OVNLoadBalancerHealthCheck
+	Protocol        string // Either "tcp" or "udp". But only applies to port based VIPs.
+	ListenAddress   net.IP
+	ListenPort      uint64
+	ListenPortCount uint64 // Number of consecutive listen ports starting at ListenPort. Defaults to a single port.
+	Targets         []OVNLoadBalancerTarget
+}
+
+// expand returns a VIP for each port of the VIP listen port range, as OVN load balancers only take single ports.
+func (v OVNLoadBalancerVIP) expand() ([]OVNLoadBalancerVIP, error) {
+	if v.ListenPortCount <= 1 {
+		return []OVNLoadBalancerVIP{v}, nil
+	}
+
+	for _, target := range v.Targets {
+		if target.PortCount > 1 && target.PortCount != v.ListenPortCount {
+			return nil, fmt.Errorf("The listen and target port ranges must be the same size")
+		}
+	}
+
+	vips := make([]OVNLoadBalancerVIP, 0, v.ListenPortCount)
+	for offset := range v.ListenPortCount {
+		vip := v
+		vip.ListenPort = v.ListenPort + offset
+		vip.ListenPortCount = 1
+		vip.Targets = make([]OVNLoadBalancerTarget, 0, len(v.Targets))
+
+		for _, target := range v.Targets {
+			if target.PortCount > 1 {
+				target.Port += offset
+				target.PortCount = 1
+			}
+
+			vip.Targets = append(vip.Targets, target)
+		}
+
+		vips = append(vips, vip)
+	}
+
+	return vips, nil
 }
 
 // OVNRouterRoute represents a static route added to a logical router.
@@ -1251,9 +1290,8 @@ func (o *NB) UpdateLogicalSwitchDHCPv4Options(ctx context.Context, switchName OV
 		dhcpOption.ExternalIDs = map[string]string{}
 	}
 
-	if dhcpOption.Options == nil {
-		dhcpOption.Options = map[string]string{}
-	}
+	// Start from an empty set so options which are no longer configured get removed.
+	dhcpOption.Options = map[string]string{}
 
 	dhcpOption.ExternalIDs[ovnExtIDIncusSwitch] = string(switchName)
 	dhcpOption.Cidr = subnet.String()
@@ -1315,6 +1353,10 @@ func (o *NB) UpdateLogicalSwitchDHCPv4Options(ctx context.Context, switchName OV
 		delete(dhcpOption.Options, "classless_static_route")
 	}
 
+	for name, value := range opts.ExtraOptions {
+		dhcpOption.Options[name] = value
+	}
+
 	// Prepare the changes.
 	operations := []ovsdb.Operation{}
 	if dhcpOption.UUID == "" {
@@ -1367,9 +1409,8 @@ func (o *NB) UpdateLogicalSwitchDHCPv6Options(ctx context.Context, switchName OV
 		dhcpOption.ExternalIDs = map[string]string{}
 	}
 
-	if dhcpOption.Options == nil {
-		dhcpOption.Options = map[string]string{}
-	}
+	// Start from an empty set so options which are no longer configured get removed.
+	dhcpOption.Options = map[string]string{}
 
 	dhcpOption.ExternalIDs[ovnExtIDIncusSwitch] = string(switchName)
 	dhcpOption.Cidr = subnet.String()
@@ -1397,6 +1438,10 @@ func (o *NB) UpdateLogicalSwitchDHCPv6Options(ctx context.Context, switchName OV
 		delete(dhcpOption.Options, "dns_server")
 	}
 
+	for name, value := range opts.ExtraOptions {
+		dhcpOption.Options[name] = value
+	}
+
 	// Prepare the changes.
 	operations := []ovsdb.Operation{}
 	if dhcpOption.UUID == "" {
@@ -1450,8 +1495,9 @@ func (o *NB) GetLogicalSwitchDHCPOptions(ctx context.Context, switchName OVNSwit
 		}
 
 		dhcpOpts = append(dhcpOpts, OVNDHCPOptsSet{
-			UUID: OVNDHCPOptionsUUID(dhcpOption.UUID),
-			CIDR: cidr,
+			UUID:    OVNDHCPOptionsUUID(dhcpOption.UUID),
+			CIDR:    cidr,
+			Options: dhcpOption.Options,
 		})
 	}
 
@@ -3094,8 +3140,19 @@ func (o *NB) CreateLoadBalancer(ctx context.Context, loadBalancerName OVNLoadBal
 	// Keep track of health check settings.
 	healthChecks := map[string]*OVNLoadBalancerHealthCheck{}
 
+	// Expand the port ranges, OVN load balancers only take single ports.
+	portVIPs := make([]OVNLoadBalancerVIP, 0, len(vips))
+	for _, vip := range vips {
+		expandedVIPs, err := vip.expand()
+		if err != nil {
+			return err
+		}
+
+		portVIPs = append(portVIPs, expandedVIPs...)
+	}
+
 	// Build up the commands to add VIPs to the load balancer.
-	for _, r := range vips {
+	for _, r := range portVIPs {
 		if r.ListenAddress == nil {
 			return fmt.Errorf("Missing VIP listen address")
 		}
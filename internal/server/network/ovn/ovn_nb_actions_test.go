@@ -0,0 +1,51 @@
+package ovn
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOVNLoadBalancerVIPExpand(t *testing.T) {
+	listenAddress := net.ParseIP("198.51.100.1")
+	c1 := net.ParseIP("10.0.0.1")
+	c2 := net.ParseIP("10.0.0.2")
+
+	// A shifted port range is expanded port by port, while a single target port receives all of them.
+	vips, err := OVNLoadBalancerVIP{
+		Protocol:        "tcp",
+		ListenAddress:   listenAddress,
+		ListenPort:      20000,
+		ListenPortCount: 2,
+		Targets:         []OVNLoadBalancerTarget{{Address: c1, Port: 30000, PortCount: 2}, {Address: c2, Port: 8080}},
+	}.expand()
+	require.NoError(t, err)
+
+	assert.Equal(t, []OVNLoadBalancerVIP{
+		{
+			Protocol:        "tcp",
+			ListenAddress:   listenAddress,
+			ListenPort:      20000,
+			ListenPortCount: 1,
+			Targets:         []OVNLoadBalancerTarget{{Address: c1, Port: 30000, PortCount: 1}, {Address: c2, Port: 8080}},
+		},
+		{
+			Protocol:        "tcp",
+			ListenAddress:   listenAddress,
+			ListenPort:      20001,
+			ListenPortCount: 1,
+			Targets:         []OVNLoadBalancerTarget{{Address: c1, Port: 30001, PortCount: 1}, {Address: c2, Port: 8080}},
+		},
+	}, vips)
+
+	// Ranges of different sizes are rejected.
+	_, err = OVNLoadBalancerVIP{
+		ListenAddress:   listenAddress,
+		ListenPort:      20000,
+		ListenPortCount: 3,
+		Targets:         []OVNLoadBalancerTarget{{Address: c1, Port: 30000, PortCount: 2}},
+	}.expand()
+	assert.Error(t, err)
+}
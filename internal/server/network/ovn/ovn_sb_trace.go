@@ -0,0 +1,90 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+)
+
+// traceNameRegex matches the names of OVN objects as they appear in the ovn-trace output.
+var traceNameRegex = regexp.MustCompile(`[A-Za-z0-9_.-]+`)
+
+// Trace runs ovn-trace against the southbound database for a microflow entering the given logical datapath
+// and returns the detailed output describing every logical flow the packet goes through.
+func (o *SB) Trace(ctx context.Context, datapath OVNSwitch, microflow string) (string, error) {
+	args := []string{"--db", o.dbAddr}
+
+	if strings.Contains(o.dbAddr, "ssl:") {
+		// The tool only takes the SSL material as files.
+		tmpDir, err := os.MkdirTemp("", "incus_ovn_trace_")
+		if err != nil {
+			return "", err
+		}
+
+		defer func() { _ = os.RemoveAll(tmpDir) }()
+
+		files := []struct {
+			flag    string
+			name    string
+			content string
+		}{
+			{flag: "--private-key", name: "client.key", content: o.sslClientKey},
+			{flag: "--certificate", name: "client.crt", content: o.sslClientCert},
+			{flag: "--ca-cert", name: "ca.crt", content: o.sslCACert},
+		}
+
+		for _, file := range files {
+			if file.content == "" {
+				continue
+			}
+
+			path := filepath.Join(tmpDir, file.name)
+			err := os.WriteFile(path, []byte(file.content), 0o600)
+			if err != nil {
+				return "", err
+			}
+
+			args = append(args, file.flag, path)
+		}
+	}
+
+	args = append(args, "--detailed", string(datapath), microflow)
+
+	output, err := subprocess.RunCommandContext(ctx, "ovn-trace", args...)
+	if err != nil {
+		return "", fmt.Errorf("Failed running ovn-trace: %w", err)
+	}
+
+	return output, nil
+}
+
+// TraceAnnotate adds a comment below every line of the ovn-trace output which refers to one of the given OVN
+// object names, describing what the object is in Incus terms (network ACL, instance NIC...).
+func TraceAnnotate(output string, labels map[string]string) string {
+	var sb strings.Builder
+
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+
+		indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+		seen := map[string]bool{}
+
+		for _, name := range traceNameRegex.FindAllString(line, -1) {
+			label, found := labels[name]
+			if !found || seen[name] {
+				continue
+			}
+
+			seen[name] = true
+			fmt.Fprintf(&sb, "%s    # %s: %s\n", indent, name, label)
+		}
+	}
+
+	return sb.String()
+}
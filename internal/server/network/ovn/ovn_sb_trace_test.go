@@ -0,0 +1,36 @@
+package ovn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceAnnotate(t *testing.T) {
+	output := `ingress(dp="incus-net1-ls-int", inport="incus-net1-instance-1234-eth0")
+---------------------------------------------------------------------
+ 8. ls_in_acl (northd.c:6520): ip && inport == @incus_acl1 && ip4.dst == 10.0.0.1, priority 1300, uuid 6a2b3c4d
+    drop;
+ 9. ls_in_acl (northd.c:6521): inport == @incus_acl10_net1, priority 1000, uuid 7a2b3c4d
+    next;
+`
+
+	labels := map[string]string{
+		"incus-net1-instance-1234-eth0": `Instance "c1" NIC "eth0"`,
+		"incus_acl1":                    `Network ACL "web"`,
+		"incus_acl10_net1":              `Network ACL "db"`,
+	}
+
+	expected := `ingress(dp="incus-net1-ls-int", inport="incus-net1-instance-1234-eth0")
+    # incus-net1-instance-1234-eth0: Instance "c1" NIC "eth0"
+---------------------------------------------------------------------
+ 8. ls_in_acl (northd.c:6520): ip && inport == @incus_acl1 && ip4.dst == 10.0.0.1, priority 1300, uuid 6a2b3c4d
+     # incus_acl1: Network ACL "web"
+    drop;
+ 9. ls_in_acl (northd.c:6521): inport == @incus_acl10_net1, priority 1000, uuid 7a2b3c4d
+     # incus_acl10_net1: Network ACL "db"
+    next;
+`
+
+	assert.Equal(t, expected, TraceAnnotate(output, labels))
+}
@@ -3,6 +3,7 @@ package node
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/lxc/incus/v6/internal/ports"
 	"github.com/lxc/incus/v6/internal/server/config"
@@ -125,6 +126,16 @@ func (c *Config) SyslogSocket() bool {
 	return c.m.GetBool("core.syslog_socket")
 }
 
+// InstancesAutostartConcurrency returns the maximum number of instances started at the same time on host boot.
+func (c *Config) InstancesAutostartConcurrency() int64 {
+	return c.m.GetInt64("instances.autostart.concurrency")
+}
+
+// InstancesHostShutdownTimeout returns the maximum time given to instances to stop on host shutdown.
+func (c *Config) InstancesHostShutdownTimeout() time.Duration {
+	return time.Duration(c.m.GetInt64("instances.host_shutdown_timeout")) * time.Second
+}
+
 // Dump current configuration keys and their values. Keys with values matching
 // their defaults are omitted.
 func (c *Config) Dump() map[string]string {
@@ -253,6 +264,28 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to enable the syslog unixgram socket listener
 	"core.syslog_socket": {Validator: validate.Optional(validate.IsBool), Type: config.Bool},
 
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.autostart.concurrency)
+	// Instances are started by decreasing {config:option}`instance-boot:boot.autostart.priority`, and an instance only
+	// frees its slot once its {config:option}`instance-boot:boot.autostart.delay` has elapsed.
+	// ---
+	//  type: integer
+	//  scope: local
+	//  defaultdesc: `1`
+	//  shortdesc: Maximum number of instances started at the same time on host boot
+	"instances.autostart.concurrency": {Type: config.Int64, Default: "1", Validator: validate.Optional(validate.IsInRange(1, 1024))},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.host_shutdown_timeout)
+	// Instances are stopped by decreasing {config:option}`instance-boot:boot.stop.priority`, each within its own
+	// {config:option}`instance-boot:boot.host_shutdown_timeout`. Instances still running once this timeout is reached
+	// are forcefully stopped. When running under systemd, the stop timeout of the service is extended to match.
+	// Set to `0` for no overall timeout.
+	// ---
+	//  type: integer
+	//  scope: local
+	//  defaultdesc: `0`
+	//  shortdesc: Number of seconds given to all instances to stop on host shutdown
+	"instances.host_shutdown_timeout": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
 	// gendoc:generate(entity=server, group=miscellaneous, key=network.ovs.connection)
 	//
 	// ---
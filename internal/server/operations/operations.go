@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 
+	internalIO "github.com/lxc/incus/v6/internal/io"
 	"github.com/lxc/incus/v6/internal/server/auth"
 	"github.com/lxc/incus/v6/internal/server/db/operationtype"
 	"github.com/lxc/incus/v6/internal/server/events"
@@ -20,6 +21,7 @@ import (
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/cancel"
 	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/revert"
 	"github.com/lxc/incus/v6/shared/util"
 )
 
@@ -101,6 +103,7 @@ type Operation struct {
 	err         error
 	readonly    bool
 	canceler    *cancel.HTTPRequestCanceller
+	rateLimiter *internalIO.RateLimiter
 	description string
 	objectType  auth.ObjectType
 	entitlement auth.Entitlement
@@ -116,6 +119,15 @@ type Operation struct {
 	// Indicates if operation has finished.
 	finished *cancel.Canceller
 
+	// Cancelled when the operation is cancelled to stop forward progress of the Run hook.
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+
+	// Compensating steps run when the operation is cancelled while running.
+	rollback        *revert.Reverter
+	rollbackAllowed bool
+	rollbackCancel  chan error
+
 	// Locking for concurrent access to the Operation
 	lock sync.Mutex
 
@@ -145,6 +157,8 @@ func OperationCreate(s *state.State, projectName string, opClass OperationClass,
 	op.url = fmt.Sprintf("/%s/operations/%s", version.APIVersion, op.id)
 	op.resources = opResources
 	op.finished = cancel.New(context.Background())
+	op.ctx, op.ctxCancel = context.WithCancel(context.Background())
+	op.rollback = revert.New()
 	op.state = s
 	op.logger = logger.AddContext(logger.Ctx{"operation": op.id, "project": op.projectName, "class": op.class.String(), "description": op.description})
 
@@ -235,7 +249,9 @@ func (op *Operation) done() {
 	op.onRun = nil
 	op.onCancel = nil
 	op.onConnect = nil
+	op.rateLimiter = nil
 	op.finished.Cancel()
+	op.ctxCancel()
 	op.lock.Unlock()
 
 	go func() {
@@ -287,6 +303,35 @@ func (op *Operation) Start() error {
 	if op.onRun != nil {
 		go func(op *Operation) {
 			err := op.onRun(op)
+
+			op.lock.Lock()
+			chanCancel := op.rollbackCancel
+
+			// The Run hook has returned, there's nothing left to interrupt.
+			op.rollbackAllowed = false
+			op.lock.Unlock()
+
+			if chanCancel != nil {
+				// The operation was cancelled while running, undo whatever the Run hook completed.
+				op.logger.Debug("Rolling back cancelled operation", logger.Ctx{"err": err})
+				op.rollback.Fail()
+
+				op.lock.Lock()
+				op.status = api.Cancelled
+				op.lock.Unlock()
+				op.done()
+				chanCancel <- nil
+
+				op.logger.Debug("Cancelled operation")
+				_, md, _ := op.Render()
+
+				op.lock.Lock()
+				op.sendEvent(md)
+				op.lock.Unlock()
+
+				return
+			}
+
 			if err != nil {
 				op.lock.Lock()
 				op.status = api.Failure
@@ -306,6 +351,7 @@ func (op *Operation) Start() error {
 
 			op.lock.Lock()
 			op.status = api.Success
+			op.rollback.Success()
 			op.lock.Unlock()
 			op.done()
 
@@ -348,6 +394,36 @@ func (op *Operation) Cancel() (chan error, error) {
 
 	oldStatus := op.status
 	op.status = api.Cancelling
+
+	if op.rollbackAllowed && op.onRun != nil {
+		// Let the Run hook return and roll back its changes before reporting the operation as cancelled.
+		op.rollbackCancel = chanCancel
+		onCancel := op.onCancel
+		op.lock.Unlock()
+
+		op.ctxCancel()
+
+		if op.canceler != nil {
+			_ = op.canceler.Cancel()
+		}
+
+		if onCancel != nil {
+			err := onCancel(op)
+			if err != nil {
+				op.logger.Warn("Failed to interrupt cancelled operation", logger.Ctx{"err": err})
+			}
+		}
+
+		op.logger.Debug("Cancelling operation")
+		_, md, _ := op.Render()
+
+		op.lock.Lock()
+		op.sendEvent(md)
+		op.lock.Unlock()
+
+		return chanCancel, nil
+	}
+
 	op.lock.Unlock()
 
 	hasOnCancel := op.onCancel != nil
@@ -464,6 +540,10 @@ func (op *Operation) mayCancel() bool {
 		return true
 	}
 
+	if op.rollbackAllowed && op.onRun != nil {
+		return true
+	}
+
 	return false
 }
 
@@ -501,6 +581,11 @@ func (op *Operation) Render() (string, *api.Operation, error) {
 		Resources:   renderedResources,
 		Metadata:    op.metadata,
 		MayCancel:   op.mayCancel(),
+		MayThrottle: op.rateLimiter != nil,
+	}
+
+	if op.rateLimiter != nil {
+		retOp.Bandwidth = op.rateLimiter.Limit()
 	}
 
 	if op.state != nil {
@@ -659,11 +744,85 @@ func (op *Operation) Resources() map[string][]api.URL {
 	return op.resources
 }
 
+// AllowRollback makes the operation cancelable while running.
+// When cancelled, the context of the operation is cancelled to stop the forward progress of its Run hook and the
+// steps registered through AddRollback are run once the hook has returned, before the operation is reported as
+// cancelled. Must be called before the operation is started.
+func (op *Operation) AllowRollback() {
+	op.lock.Lock()
+	op.rollbackAllowed = true
+	op.lock.Unlock()
+}
+
+// AddRollback registers a compensating step undoing a change completed by the Run hook.
+// Steps are run in reverse order if the operation is cancelled and discarded if it completes.
+func (op *Operation) AddRollback(hook revert.Hook) {
+	op.lock.Lock()
+	op.rollback.Add(hook)
+	op.lock.Unlock()
+}
+
+// Context returns a context which is cancelled when the operation is cancelled or finished.
+func (op *Operation) Context() context.Context {
+	return op.ctx
+}
+
 // SetCanceler sets a canceler.
 func (op *Operation) SetCanceler(canceler *cancel.HTTPRequestCanceller) {
 	op.canceler = canceler
 }
 
+// RateLimiter returns the rate limiter applied to the data transferred by the operation.
+// A new one without any limit is set if the operation doesn't have one yet, so that all the transfers of
+// the operation share the same limit.
+func (op *Operation) RateLimiter() *internalIO.RateLimiter {
+	op.lock.Lock()
+	defer op.lock.Unlock()
+
+	if op.rateLimiter == nil {
+		op.rateLimiter = internalIO.NewRateLimiter(0)
+	}
+
+	return op.rateLimiter
+}
+
+// Throttle limits the bandwidth of the operation to the given number of bytes per second.
+// A limit of zero removes the limit. It returns an error if the operation isn't running or doesn't
+// support throttling.
+func (op *Operation) Throttle(limit int64) error {
+	op.lock.Lock()
+	if op.status != api.Pending && op.status != api.Running {
+		op.lock.Unlock()
+		return fmt.Errorf("Only pending or running operations can be throttled")
+	}
+
+	if op.rateLimiter == nil {
+		op.lock.Unlock()
+		return fmt.Errorf("Operation %q doesn't support throttling", op.description)
+	}
+
+	if limit < 0 {
+		op.lock.Unlock()
+		return fmt.Errorf("Bandwidth limit can't be negative")
+	}
+
+	limiter := op.rateLimiter
+	op.updatedAt = time.Now()
+	op.lock.Unlock()
+
+	// Applying the limit may run external tools, so don't hold the lock meanwhile.
+	limiter.SetLimit(limit)
+
+	op.logger.Debug("Updated bandwidth limit for operation", logger.Ctx{"bandwidth": limit})
+	_, md, _ := op.Render()
+
+	op.lock.Lock()
+	op.sendEvent(md)
+	op.lock.Unlock()
+
+	return nil
+}
+
 // Permission returns the operations auth.ObjectType and auth.Entitlement.
 func (op *Operation) Permission() (auth.ObjectType, auth.Entitlement) {
 	return op.objectType, op.entitlement
@@ -0,0 +1,126 @@
+package operations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/db/operationtype"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestOperationCancelRollback(t *testing.T) {
+	created := map[string]bool{}
+	started := make(chan struct{})
+
+	run := func(op *Operation) error {
+		created["volume"] = true
+		op.AddRollback(func() { delete(created, "volume") })
+
+		created["record"] = true
+		op.AddRollback(func() { delete(created, "record") })
+
+		close(started)
+
+		// Keep going until cancelled, then complete the current step anyway.
+		<-op.Context().Done()
+		created["device"] = true
+		op.AddRollback(func() { delete(created, "device") })
+
+		return nil
+	}
+
+	op, err := OperationCreate(nil, "", OperationClassTask, operationtype.InstanceCreate, nil, nil, run, nil, nil, nil)
+	require.NoError(t, err)
+
+	// Operations are only cancelable while running if they can roll back.
+	assert.False(t, op.mayCancel())
+	op.AllowRollback()
+	assert.True(t, op.mayCancel())
+
+	require.NoError(t, op.Start())
+	<-started
+
+	chanCancel, err := op.Cancel()
+	require.NoError(t, err)
+
+	select {
+	case err := <-chanCancel:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the operation to be cancelled")
+	}
+
+	assert.Equal(t, api.Cancelled, op.Status())
+	assert.Empty(t, created)
+}
+
+func TestOperationRollbackDiscarded(t *testing.T) {
+	rolledBack := false
+
+	run := func(op *Operation) error {
+		op.AddRollback(func() { rolledBack = true })
+		return nil
+	}
+
+	op, err := OperationCreate(nil, "", OperationClassTask, operationtype.InstanceCreate, nil, nil, run, nil, nil, nil)
+	require.NoError(t, err)
+
+	op.AllowRollback()
+	require.NoError(t, op.Start())
+	require.NoError(t, op.Wait(context.Background()))
+
+	assert.Equal(t, api.Success, op.Status())
+	assert.False(t, rolledBack)
+
+	// Finished operations can't be cancelled.
+	_, err = op.Cancel()
+	assert.Error(t, err)
+}
+
+func TestOperationThrottle(t *testing.T) {
+	started := make(chan struct{})
+
+	run := func(op *Operation) error {
+		close(started)
+		<-op.Context().Done()
+		return nil
+	}
+
+	op, err := OperationCreate(nil, "", OperationClassTask, operationtype.ImageDownload, nil, nil, run, nil, nil, nil)
+	require.NoError(t, err)
+
+	// Operations without a rate limiter can't be throttled.
+	assert.Error(t, op.Throttle(1000))
+
+	limiter := op.RateLimiter()
+
+	require.NoError(t, op.Start())
+	<-started
+
+	// All the transfers of the operation share its rate limiter.
+	assert.Same(t, limiter, op.RateLimiter())
+
+	require.NoError(t, op.Throttle(1000))
+	assert.Equal(t, int64(1000), limiter.Limit())
+	assert.Error(t, op.Throttle(-1))
+
+	_, md, err := op.Render()
+	require.NoError(t, err)
+	assert.True(t, md.MayThrottle)
+	assert.Equal(t, int64(1000), md.Bandwidth)
+
+	// Finished operations can't be throttled.
+	op.ctxCancel()
+	require.NoError(t, op.Wait(context.Background()))
+	assert.Error(t, op.Throttle(0))
+
+	// The rate limiter is released once the operation is done.
+	_, md, err = op.Render()
+	require.NoError(t, err)
+	assert.False(t, md.MayThrottle)
+	assert.Zero(t, md.Bandwidth)
+}
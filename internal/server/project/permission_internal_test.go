@@ -75,3 +75,18 @@ func TestParseHostIDMapRange(t *testing.T) {
 		assert.Equal(t, idmaps, expected)
 	}
 }
+
+func TestLowLevelSnapshotHooks(t *testing.T) {
+	for _, key := range []string{"snapshots.hooks.pre", "snapshots.hooks.post"} {
+		assert.True(t, isContainerLowLevelOptionForbidden(key), key)
+		assert.True(t, isVMLowLevelOptionForbidden(key), key)
+	}
+
+	assert.False(t, isContainerLowLevelOptionForbidden("snapshots.hooks.timeout"))
+	assert.False(t, isVMLowLevelOptionForbidden("snapshots.hooks.timeout"))
+}
+
+func TestLowLevelNetworkNamespace(t *testing.T) {
+	// Joining an arbitrary network namespace requires the low-level options to be allowed.
+	assert.True(t, isContainerLowLevelOptionForbidden("linux.network_namespace"))
+}
@@ -881,13 +881,18 @@ func isContainerLowLevelOptionForbidden(key string) bool {
 		"boot.host_shutdown_timeout",
 		"linux.kernel_modules",
 		"limits.memory.swap",
+		"linux.network_namespace",
 		"raw.apparmor",
+		"raw.cgroup",
 		"raw.idmap",
 		"raw.lxc",
 		"raw.seccomp",
+		"security.apparmor.profile",
 		"security.guestapi.images",
 		"security.idmap.base",
 		"security.idmap.size",
+		"snapshots.hooks.post",
+		"snapshots.hooks.pre",
 	},
 		key) {
 		return true
@@ -902,14 +907,21 @@ func isVMLowLevelOptionForbidden(key string) bool {
 		"boot.host_shutdown_action",
 		"boot.host_shutdown_timeout",
 		"limits.memory.hugepages",
+		"linux.kernel_modules",
 		"raw.apparmor",
 		"raw.idmap",
 		"raw.qemu",
 		"raw.qemu.conf",
+		"raw.qemu.hook.post-start",
+		"raw.qemu.hook.pre-start",
+		"raw.qemu.hook.pre-stop",
 		"raw.qemu.qmp.early",
 		"raw.qemu.qmp.post-start",
 		"raw.qemu.qmp.pre-start",
 		"raw.qemu.scriptlet",
+		"rng.source",
+		"snapshots.hooks.post",
+		"snapshots.hooks.pre",
 	},
 		key)
 }
@@ -917,7 +929,12 @@ func isVMLowLevelOptionForbidden(key string) bool {
 // AllowInstanceUpdate returns an error if any project-specific limit or
 // restriction is violated when updating an existing instance.
 func AllowInstanceUpdate(tx *db.ClusterTx, projectName, instanceName string, req api.InstancePut, currentConfig map[string]string) error {
-	var updatedInstance *api.Instance
+	return AllowInstancesUpdate(tx, projectName, map[string]api.InstancePut{instanceName: req}, map[string]map[string]string{instanceName: currentConfig})
+}
+
+// AllowInstancesUpdate returns an error if any project-specific limit or
+// restriction is violated when updating several existing instances at once.
+func AllowInstancesUpdate(tx *db.ClusterTx, projectName string, reqs map[string]api.InstancePut, currentConfigs map[string]map[string]string) error {
 	info, err := fetchProject(tx, projectName, true)
 	if err != nil {
 		return err
@@ -927,29 +944,29 @@ func AllowInstanceUpdate(tx *db.ClusterTx, projectName, instanceName string, req
 		return nil
 	}
 
-	// Change the instance being updated.
+	// Change the instances being updated.
 	for i, instance := range info.Instances {
-		if instance.Name != instanceName {
+		req, found := reqs[instance.Name]
+		if !found {
 			continue
 		}
 
 		info.Instances[i].Profiles = req.Profiles
 		info.Instances[i].Config = req.Config
 		info.Instances[i].Devices = req.Devices
-		updatedInstance = &info.Instances[i]
-	}
 
-	instType, err := instancetype.New(updatedInstance.Type)
-	if err != nil {
-		return err
-	}
+		instType, err := instancetype.New(instance.Type)
+		if err != nil {
+			return err
+		}
 
-	// Special case restriction checks on volatile.* keys, since we want to
-	// detect if they were changed or added.
-	err = checkRestrictionsOnVolatileConfig(
-		info.Project, instType, updatedInstance.Name, req.Config, currentConfig, false)
-	if err != nil {
-		return err
+		// Special case restriction checks on volatile.* keys, since we want to
+		// detect if they were changed or added.
+		err = checkRestrictionsOnVolatileConfig(
+			info.Project, instType, instance.Name, req.Config, currentConfigs[instance.Name], false)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = checkRestrictionsAndAggregateLimits(tx, info)
@@ -0,0 +1,162 @@
+package ratelimit
+
+import (
+	"hash/maphash"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// shardCount is the number of independently locked bucket maps, spreading the lock contention across keys.
+const shardCount = 64
+
+// pruneInterval is how often the shards are checked for buckets which can be forgotten.
+const pruneInterval = time.Minute
+
+// Limits represents the token bucket configuration of a Limiter.
+type Limits struct {
+	// Number of tokens added to a bucket per second (0 or lower disables the limiter).
+	Rate float64
+
+	// Maximum number of tokens held by a bucket.
+	Burst int64
+}
+
+// Result represents the outcome of taking a token from a bucket.
+type Result struct {
+	// Whether the request is allowed.
+	Allowed bool
+
+	// Configured number of tokens added per second.
+	Limit float64
+
+	// Number of tokens left in the bucket.
+	Remaining int64
+
+	// How long to wait until a token becomes available (only set when not allowed).
+	RetryAfter time.Duration
+}
+
+// bucket represents the state of a single token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// shard is a lock protected subset of the buckets.
+type shard struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// Limiter is a token bucket rate limiter keeping a separate bucket per key.
+type Limiter struct {
+	limits atomic.Pointer[Limits]
+	seed   maphash.Seed
+	shards [shardCount]shard
+
+	// Unix time in nanoseconds of the last sweep of all shards.
+	lastPrune atomic.Int64
+
+	// now returns the current time, overridden in tests.
+	now func() time.Time
+}
+
+// NewLimiter returns a new Limiter using the given limits.
+func NewLimiter(limits Limits) *Limiter {
+	l := &Limiter{
+		seed: maphash.MakeSeed(),
+		now:  time.Now,
+	}
+
+	for i := range l.shards {
+		l.shards[i].buckets = map[string]*bucket{}
+	}
+
+	l.SetLimits(limits)
+
+	return l
+}
+
+// SetLimits replaces the limits of the limiter.
+// Existing buckets are kept, their tokens being capped to the new burst on their next use.
+func (l *Limiter) SetLimits(limits Limits) {
+	l.limits.Store(&limits)
+}
+
+// Limits returns the current limits of the limiter.
+func (l *Limiter) Limits() Limits {
+	return *l.limits.Load()
+}
+
+// Enabled returns whether the limiter restricts anything.
+func (l *Limiter) Enabled() bool {
+	limits := l.limits.Load()
+
+	return limits.Rate > 0 && limits.Burst > 0
+}
+
+// Allow takes a token from the bucket of the given key if one is available.
+func (l *Limiter) Allow(key string) Result {
+	limits := l.limits.Load()
+	if limits.Rate <= 0 || limits.Burst <= 0 {
+		return Result{Allowed: true, Remaining: -1}
+	}
+
+	now := l.now()
+
+	// Sweep all shards periodically, stale buckets may live in shards which are no longer accessed.
+	lastPrune := l.lastPrune.Load()
+	if now.Sub(time.Unix(0, lastPrune)) > pruneInterval && l.lastPrune.CompareAndSwap(lastPrune, now.UnixNano()) {
+		l.prune(limits, now)
+	}
+
+	s := &l.shards[maphash.String(l.seed, key)%shardCount]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limits.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	// Refill the bucket based on the time elapsed since it was last used.
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(limits.Burst), b.tokens+elapsed*limits.Rate)
+		b.last = now
+	}
+
+	b.tokens = math.Min(float64(limits.Burst), b.tokens)
+
+	result := Result{Limit: limits.Rate}
+	if b.tokens < 1 {
+		result.RetryAfter = time.Duration((1 - b.tokens) / limits.Rate * float64(time.Second))
+		return result
+	}
+
+	b.tokens--
+	result.Allowed = true
+	result.Remaining = int64(b.tokens)
+
+	return result
+}
+
+// prune removes the buckets which would have been fully refilled by now, as they are equivalent to new ones.
+func (l *Limiter) prune(limits *Limits, now time.Time) {
+	for i := range l.shards {
+		s := &l.shards[i]
+
+		s.mu.Lock()
+		for key, b := range s.buckets {
+			if b.tokens+now.Sub(b.last).Seconds()*limits.Rate >= float64(limits.Burst) {
+				delete(s.buckets, key)
+			}
+		}
+
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(Limits{Rate: 2, Burst: 3})
+	l.now = func() time.Time { return now }
+
+	// The burst is available straight away.
+	for i := 2; i >= 0; i-- {
+		result := l.Allow("foo")
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(i), result.Remaining)
+	}
+
+	result := l.Allow("foo")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	// Other keys have their own bucket.
+	assert.True(t, l.Allow("bar").Allowed)
+
+	// Tokens are added back over time.
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, l.Allow("foo").Allowed)
+	assert.False(t, l.Allow("foo").Allowed)
+
+	// Never beyond the burst.
+	now = now.Add(time.Hour)
+	for range 3 {
+		assert.True(t, l.Allow("foo").Allowed)
+	}
+
+	assert.False(t, l.Allow("foo").Allowed)
+
+	// Lowering the burst caps existing buckets.
+	now = now.Add(time.Hour)
+	l.SetLimits(Limits{Rate: 2, Burst: 1})
+	assert.True(t, l.Allow("foo").Allowed)
+	assert.False(t, l.Allow("foo").Allowed)
+
+	// A disabled limiter allows everything.
+	l.SetLimits(Limits{})
+	assert.False(t, l.Enabled())
+	assert.True(t, l.Allow("foo").Allowed)
+}
+
+func TestLimiterPrune(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(Limits{Rate: 0.01, Burst: 10})
+	l.now = func() time.Time { return now }
+
+	count := func() int {
+		count := 0
+		for i := range l.shards {
+			count += len(l.shards[i].buckets)
+		}
+
+		return count
+	}
+
+	// Each bucket needs 100s to refill, except the drained one which needs 1000s.
+	for i := range 100 {
+		l.Allow(fmt.Sprintf("key%d", i))
+	}
+
+	for range 10 {
+		l.Allow("drained")
+	}
+
+	assert.Equal(t, 101, count())
+
+	// Buckets which aren't refilled yet are kept.
+	now = now.Add(pruneInterval + time.Second)
+	l.Allow("foo")
+	assert.Equal(t, 102, count())
+
+	// No sweep happens before the next interval.
+	now = now.Add(50 * time.Second)
+	l.Allow("bar")
+	assert.Equal(t, 103, count())
+
+	// Refilled buckets are forgotten from every shard, whichever key triggers the sweep.
+	now = now.Add(11 * time.Second)
+	l.Allow("baz")
+	assert.Equal(t, 4, count())
+
+	now = now.Add(time.Hour)
+	l.Allow("baz")
+	assert.Equal(t, 1, count())
+}
+
+func TestLimiterConcurrent(t *testing.T) {
+	l := NewLimiter(Limits{Rate: 0.001, Burst: 100})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for range 50 {
+				if l.Allow("foo").Allowed {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 100, allowed)
+}
@@ -40,4 +40,10 @@ const (
 
 	// HeaderForwardedProtocol is the forwarded protocol field in request header.
 	HeaderForwardedProtocol = "X-Incus-forwarded-protocol"
+
+	// HeaderRateLimitLimit is the number of API requests per second allowed for the client.
+	HeaderRateLimitLimit = "X-Incus-ratelimit-limit"
+
+	// HeaderRateLimitRemaining is the number of API requests the client can still make in a burst.
+	HeaderRateLimitRemaining = "X-Incus-ratelimit-remaining"
 )
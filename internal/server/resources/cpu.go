@@ -105,6 +105,27 @@ func ParseNumaNodeSet(numaNodeSet string) ([]int64, error) {
 	return nodes, nil
 }
 
+// GetNUMANodesForCPUs returns the NUMA nodes the given CPU threads are part of.
+func GetNUMANodesForCPUs(cpuIDs []int64) ([]int64, error) {
+	cpu, err := GetCPU()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []int64{}
+	for _, socket := range cpu.Sockets {
+		for _, core := range socket.Cores {
+			for _, thread := range core.Threads {
+				if slices.Contains(cpuIDs, thread.ID) && !slices.Contains(nodes, int64(thread.NUMANode)) {
+					nodes = append(nodes, int64(thread.NUMANode))
+				}
+			}
+		}
+	}
+
+	return nodes, nil
+}
+
 func getCPUCache(path string) ([]api.ResourcesCPUCache, error) {
 	caches := []api.ResourcesCPUCache{}
 
@@ -0,0 +1,111 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// GetDevices returns the availability of the GPU, infiniband and PCI devices on the system.
+func GetDevices() (*api.ClusterMemberDevices, error) {
+	gpu, err := GetGPU()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve GPU information: %w", err)
+	}
+
+	network, err := GetNetwork()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve network information: %w", err)
+	}
+
+	pci, err := GetPCI()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve PCI information: %w", err)
+	}
+
+	return DevicesAvailability(gpu, network, pci), nil
+}
+
+// DevicesAvailability computes the device availability from the GPU, network and PCI resources.
+// Devices bound to vfio-pci are considered in use by a virtual machine and infiniband virtual
+// functions without a host interface are considered in use by a container.
+func DevicesAvailability(gpu *api.ResourcesGPU, network *api.ResourcesNetwork, pci *api.ResourcesPCI) *api.ClusterMemberDevices {
+	devices := api.ClusterMemberDevices{GPUs: []api.ClusterMemberGPU{}, PCIFree: []string{}}
+
+	if gpu != nil {
+		for _, card := range gpu.Cards {
+			devices.GPUTotal++
+
+			if card.Driver != "vfio-pci" {
+				devices.GPUFree++
+				devices.GPUs = append(devices.GPUs, memberGPU(card))
+			}
+		}
+	}
+
+	if network != nil {
+		for _, card := range network.Cards {
+			if card.SRIOV == nil || !isInfinibandCard(card) {
+				continue
+			}
+
+			for _, vf := range card.SRIOV.VFs {
+				devices.InfinibandVFTotal++
+
+				if vf.Driver != "vfio-pci" && isInfinibandCard(vf) {
+					devices.InfinibandVFFree++
+				}
+			}
+		}
+	}
+
+	if pci != nil {
+		for _, dev := range pci.Devices {
+			if dev.Driver != "vfio-pci" {
+				devices.PCIFree = append(devices.PCIFree, dev.PCIAddress)
+			}
+		}
+	}
+
+	return &devices
+}
+
+// memberGPU returns the details of a GPU used to match GPU devices against it.
+func memberGPU(card api.ResourcesGPUCard) api.ClusterMemberGPU {
+	gpu := api.ClusterMemberGPU{
+		PCIAddress: card.PCIAddress,
+		VendorID:   card.VendorID,
+		ProductID:  card.ProductID,
+		Types:      []string{"physical"},
+	}
+
+	if card.DRM != nil {
+		id := card.DRM.ID
+		gpu.DRMID = &id
+	}
+
+	if len(card.Mdev) > 0 {
+		gpu.Types = append(gpu.Types, "mdev")
+	}
+
+	if card.Nvidia != nil {
+		gpu.Types = append(gpu.Types, "mig")
+	}
+
+	if card.SRIOV != nil && card.SRIOV.MaximumVFs > 0 {
+		gpu.Types = append(gpu.Types, "sriov")
+	}
+
+	return gpu
+}
+
+// isInfinibandCard returns whether any of the card ports uses the infiniband protocol.
+func isInfinibandCard(card api.ResourcesNetworkCard) bool {
+	for _, port := range card.Ports {
+		if port.Protocol == "infiniband" {
+			return true
+		}
+	}
+
+	return false
+}
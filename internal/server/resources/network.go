@@ -598,6 +598,21 @@ func GetNetworkState(name string) (*api.NetworkState, error) {
 			bonding.LowerDevices = strings.Split(strings.TrimSpace(string(strValue)), " ")
 		}
 
+		// Active device.
+		strValue, err = os.ReadFile(filepath.Join(bondPath, "active_slave"))
+		if err == nil {
+			bonding.ActiveDevice = strings.TrimSpace(string(strValue))
+		}
+
+		// Lower devices state.
+		bonding.LowerDevicesState = map[string]string{}
+		for _, device := range bonding.LowerDevices {
+			strValue, err = os.ReadFile(fmt.Sprintf("/sys/class/net/%s/bonding_slave/mii_status", device))
+			if err == nil {
+				bonding.LowerDevicesState[device] = strings.TrimSpace(string(strValue))
+			}
+		}
+
 		network.Bond = &bonding
 	}
 
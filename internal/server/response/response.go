@@ -148,6 +148,12 @@ func SyncResponsePlain(success bool, compress bool, metadata string) Response {
 	return &syncResponse{success: success, metadata: metadata, plaintext: true, compress: compress}
 }
 
+// SyncResponsePlainHeaders returns a new syncResponse with plaintext and headers.
+// A Content-Type header overrides the default text/plain one.
+func SyncResponsePlainHeaders(success bool, compress bool, metadata string, headers map[string]string) Response {
+	return &syncResponse{success: success, metadata: metadata, plaintext: true, compress: compress, headers: headers}
+}
+
 func (r *syncResponse) Render(w http.ResponseWriter) error {
 	// Set an appropriate ETag header
 	if r.etag != nil {
@@ -171,7 +177,7 @@ func (r *syncResponse) Render(w http.ResponseWriter) error {
 	}
 
 	// Handle plain text headers.
-	if r.plaintext {
+	if r.plaintext && r.headers["Content-Type"] == "" {
 		w.Header().Set("Content-Type", "text/plain")
 	}
 
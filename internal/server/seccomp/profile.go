@@ -0,0 +1,160 @@
+//go:build linux && cgo
+
+package seccomp
+
+/*
+#include <seccomp.h>
+#include <stdbool.h>
+#include <stdlib.h>
+
+// Syscalls missing on the native architecture resolve to negative pseudo numbers
+// rather than __NR_SCMP_ERROR, so that the names known on any architecture are valid.
+static bool is_valid_syscall_name(const char *name)
+{
+	return seccomp_syscall_resolve_name(name) != __NR_SCMP_ERROR;
+}
+*/
+// #cgo pkg-config: libseccomp
+import "C"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unsafe"
+)
+
+// ProfileModeExtend merges the custom profile rules with the default policy.
+const ProfileModeExtend = "extend"
+
+// ProfileModeReplace uses the custom profile rules instead of the default policy.
+const ProfileModeReplace = "replace"
+
+// ProfileRule represents a single rule of a custom seccomp profile.
+type ProfileRule struct {
+	// Names of the syscalls the rule applies to.
+	Names []string `json:"names"`
+
+	// Action to take (allow, errno, kill, trap or log).
+	Action string `json:"action"`
+
+	// Errno to return when the action is errno (defaults to EPERM).
+	Errno *int `json:"errno,omitempty"`
+}
+
+// Profile represents a custom seccomp profile as set in security.syscalls.profile.
+type Profile struct {
+	Rules []ProfileRule `json:"rules"`
+}
+
+var profileActions = []string{"allow", "errno", "kill", "trap", "log"}
+
+// ParseProfile parses and validates a custom seccomp profile.
+func ParseProfile(value string) (*Profile, error) {
+	profile := Profile{}
+
+	if strings.TrimSpace(value) == "" {
+		return &profile, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&profile)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid seccomp profile: %w", err)
+	}
+
+	for i, rule := range profile.Rules {
+		if len(rule.Names) == 0 {
+			return nil, fmt.Errorf("Seccomp profile rule %d has no syscall names", i)
+		}
+
+		for _, name := range rule.Names {
+			if !IsValidSyscallName(name) {
+				return nil, fmt.Errorf("Seccomp profile rule %d references unknown syscall %q", i, name)
+			}
+		}
+
+		if !slices.Contains(profileActions, rule.Action) {
+			return nil, fmt.Errorf("Seccomp profile rule %d has invalid action %q (must be one of %s)", i, rule.Action, strings.Join(profileActions, ", "))
+		}
+
+		if rule.Errno != nil {
+			if rule.Action != "errno" {
+				return nil, fmt.Errorf("Seccomp profile rule %d sets an errno on a non-errno action", i)
+			}
+
+			if *rule.Errno < 0 || *rule.Errno > 4095 {
+				return nil, fmt.Errorf("Seccomp profile rule %d has out of range errno %d", i, *rule.Errno)
+			}
+		}
+	}
+
+	return &profile, nil
+}
+
+// allowed returns whether the profile explicitly allows the syscall.
+func (p *Profile) allowed(name string) bool {
+	for _, rule := range p.Rules {
+		if rule.Action == "allow" && slices.Contains(rule.Names, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FilterPolicy removes the rules of an LXC seccomp policy which apply to syscalls allowed by the profile.
+func (p *Profile) FilterPolicy(policy string) string {
+	var out strings.Builder
+
+	for _, line := range strings.SplitAfter(policy, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && p.allowed(fields[0]) {
+			continue
+		}
+
+		out.WriteString(line)
+	}
+
+	return out.String()
+}
+
+// Policy returns the deny rules of the profile in the LXC seccomp policy format.
+// Allow rules aren't rendered as they only apply when filtering a deny policy.
+func (p *Profile) Policy() string {
+	var out strings.Builder
+
+	for _, rule := range p.Rules {
+		action := rule.Action
+		switch rule.Action {
+		case "allow":
+			continue
+		case "errno":
+			errno := 1
+			if rule.Errno != nil {
+				errno = *rule.Errno
+			}
+
+			action = fmt.Sprintf("errno %d", errno)
+		}
+
+		for _, name := range rule.Names {
+			fmt.Fprintf(&out, "%s %s\n", name, action)
+		}
+	}
+
+	return out.String()
+}
+
+// IsValidSyscallName returns whether the name is a known Linux syscall on any supported architecture.
+// The names are resolved by libseccomp, the same way as when LXC compiles the policy.
+func IsValidSyscallName(name string) bool {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	return bool(C.is_valid_syscall_name(cName))
+}
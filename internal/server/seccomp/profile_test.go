@@ -0,0 +1,47 @@
+//go:build linux && cgo
+
+package seccomp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProfile(t *testing.T) {
+	// Empty profile.
+	profile, err := ParseProfile("")
+	assert.NoError(t, err)
+	assert.Empty(t, profile.Rules)
+
+	// Valid profile.
+	profile, err = ParseProfile(`{"rules": [{"names": ["keyctl", "add_key"], "action": "errno", "errno": 38}, {"names": ["kexec_load"], "action": "allow"}]}`)
+	assert.NoError(t, err)
+	assert.Len(t, profile.Rules, 2)
+
+	// Invalid profiles.
+	tests := map[string]string{
+		"invalid JSON":         `{"rules": [`,
+		"unknown field":        `{"rules": [], "foo": "bar"}`,
+		"missing names":        `{"rules": [{"action": "kill"}]}`,
+		"unknown syscall":      `{"rules": [{"names": ["not_a_syscall"], "action": "kill"}]}`,
+		"invalid action":       `{"rules": [{"names": ["keyctl"], "action": "deny"}]}`,
+		"errno on kill action": `{"rules": [{"names": ["keyctl"], "action": "kill", "errno": 1}]}`,
+		"errno out of range":   `{"rules": [{"names": ["keyctl"], "action": "errno", "errno": 5000}]}`,
+	}
+
+	for name, value := range tests {
+		_, err := ParseProfile(value)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestProfilePolicy(t *testing.T) {
+	profile, err := ParseProfile(`{"rules": [{"names": ["keyctl", "add_key"], "action": "errno", "errno": 38}, {"names": ["ptrace"], "action": "errno"}, {"names": ["kexec_load"], "action": "allow"}, {"names": ["bpf"], "action": "kill"}]}`)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "keyctl errno 38\nadd_key errno 38\nptrace errno 1\nbpf kill\n", profile.Policy())
+
+	policy := "[all]\nkexec_load errno 38\ninit_module errno 38\n"
+	assert.Equal(t, "[all]\ninit_module errno 38\n", profile.FilterPolicy(policy))
+}
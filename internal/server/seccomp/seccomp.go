@@ -649,6 +649,7 @@ func InstanceNeedsPolicy(c Instance) bool {
 		"security.syscalls.deny",
 		"security.syscalls.whitelist",
 		"security.syscalls.blacklist",
+		"security.syscalls.profile",
 	}
 
 	for _, k := range keys {
@@ -779,9 +780,17 @@ func seccompGetPolicyContent(s *state.State, c Instance) (string, error) {
 			defaultFlag, ok = config["security.syscalls.blacklist_default"]
 		}
 
-		if !ok || util.IsTrue(defaultFlag) {
-			policy += defaultSeccompPolicy
+		profile, err := ParseProfile(config["security.syscalls.profile"])
+		if err != nil {
+			return "", err
 		}
+
+		if (!ok || util.IsTrue(defaultFlag)) && config["security.syscalls.profile.mode"] != ProfileModeReplace {
+			policy += profile.FilterPolicy(defaultSeccompPolicy)
+		}
+
+		// Custom profile rules
+		policy += profile.Policy()
 	}
 
 	// Syscall interception
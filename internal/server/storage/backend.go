@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
@@ -272,6 +273,47 @@ func (b *backend) GetResources() (*api.ResourcesStoragePool, error) {
 	return b.driver.GetResources()
 }
 
+// ScrubStatus returns the status of the last (or current) scrub of the pool, or nil if the driver doesn't support
+// scrubbing.
+func (b *backend) ScrubStatus() (*api.StoragePoolScrub, error) {
+	if b.Status() == api.StoragePoolStatusPending {
+		return nil, errors.New("The pool is in pending state")
+	}
+
+	status, err := b.driver.ScrubStatus()
+	if err != nil {
+		if errors.Is(err, drivers.ErrNotSupported) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// Scrub runs a data integrity check of the pool and waits for it to complete.
+func (b *backend) Scrub(op *operations.Operation) error {
+	l := b.logger.AddContext(nil)
+	l.Debug("Scrub started")
+	defer l.Debug("Scrub finished")
+
+	if b.Status() == api.StoragePoolStatusPending {
+		return errors.New("The pool is in pending state")
+	}
+
+	err := b.driver.Scrub(op)
+	if err != nil {
+		if errors.Is(err, drivers.ErrNotSupported) {
+			return api.StatusErrorf(http.StatusBadRequest, "Storage pool driver %q doesn't support scrubbing", b.driver.Info().Name)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
 // IsUsed returns whether the storage pool is used by any volumes or profiles (excluding image volumes).
 func (b *backend) IsUsed() (bool, error) {
 	usedBy, err := UsedBy(context.TODO(), b.state, b, true, true, db.StoragePoolVolumeTypeNameImage)
@@ -306,11 +348,16 @@ func (b *backend) Update(clientType request.ClientType, newDesc string, newConfi
 	// Prevent shrinking the storage pool.
 	newSize, sizeChanged := changedConfig["size"]
 	if sizeChanged {
-		oldSizeBytes, _ := units.ParseByteSizeString(b.db.Config["size"])
-		newSizeBytes, _ := units.ParseByteSizeString(newSize)
+		err = poolResizeValidate(b.db.Config["size"], newSize, func() (uint64, error) {
+			res, err := b.driver.GetResources()
+			if err != nil {
+				return 0, err
+			}
 
-		if newSizeBytes < oldSizeBytes {
-			return fmt.Errorf("Pool cannot be shrunk")
+			return res.Space.Used, nil
+		})
+		if err != nil {
+			return err
 		}
 	}
 
@@ -5401,6 +5448,209 @@ func (b *backend) RenameCustomVolume(projectName string, volName string, newVolN
 	return nil
 }
 
+// ConvertCustomVolume converts a custom volume between the filesystem and block content types, preserving its data.
+// The converted volume is created alongside the existing one and only replaces it once all data was copied,
+// the existing volume is then deleted.
+func (b *backend) ConvertCustomVolume(projectName string, volName string, contentType drivers.ContentType, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName, "contentType": contentType})
+	l.Debug("ConvertCustomVolume started")
+	defer l.Debug("ConvertCustomVolume finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	if contentType != drivers.ContentTypeFS && contentType != drivers.ContentTypeBlock {
+		return fmt.Errorf("Volumes can only be converted to the %q or %q content types", drivers.ContentTypeFS, drivers.ContentTypeBlock)
+	}
+
+	volume, err := VolumeDBGet(b, projectName, volName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	srcContentType := drivers.ContentType(volume.ContentType)
+	if srcContentType == contentType {
+		return fmt.Errorf("Volume is already of content type %q", contentType)
+	}
+
+	if srcContentType != drivers.ContentTypeFS && srcContentType != drivers.ContentTypeBlock {
+		return fmt.Errorf("Volumes of content type %q cannot be converted", srcContentType)
+	}
+
+	snapshots, err := VolumeDBSnapshotsGet(b, projectName, volName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) > 0 {
+		return fmt.Errorf("Volumes with snapshots cannot be converted")
+	}
+
+	var backups []db.StoragePoolVolumeBackup
+
+	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		backups, err = tx.GetStoragePoolVolumeBackups(ctx, projectName, volName, b.ID())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(backups) > 0 {
+		return fmt.Errorf("Volumes with backups cannot be converted")
+	}
+
+	// Refuse converting volumes which are attached to instances or profiles, whether running or not.
+	err = VolumeUsedByInstanceDevices(b.state, b.name, projectName, &volume.StorageVolume, true, func(inst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+		return fmt.Errorf("Volume is attached to instance %q", inst.Name)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = VolumeUsedByProfileDevices(b.state, b.name, projectName, &volume.StorageVolume, func(profileID int64, profile api.Profile, project api.Project, usedByDevices []string) error {
+		return fmt.Errorf("Volume is attached to profile %q", profile.Name)
+	})
+	if err != nil {
+		return err
+	}
+
+	used, err := VolumeUsedByDaemon(b.state, b.name, volName)
+	if err != nil {
+		return err
+	}
+
+	if used {
+		return fmt.Errorf("Volume is used by Incus itself and cannot be converted")
+	}
+
+	volStorageName := project.StorageVolume(projectName, volName)
+	srcVol := b.GetVolume(drivers.VolumeTypeCustom, srcContentType, volStorageName, volume.Config)
+
+	// Drop the keys which only apply to the previous content type and the on-disk idmap state.
+	config := maps.Clone(volume.Config)
+	for _, key := range []string{"security.shared", "security.shifted", "security.unmapped", "volatile.idmap.last", "volatile.idmap.next"} {
+		delete(config, key)
+	}
+
+	// Check the data will fit into the new block volume.
+	if contentType == drivers.ContentTypeBlock {
+		blockVol := b.GetVolume(drivers.VolumeTypeCustom, contentType, volStorageName, config)
+
+		sizeBytes, err := units.ParseByteSizeString(blockVol.ConfigSize())
+		if err != nil {
+			return err
+		}
+
+		usedBytes, err := b.driver.GetVolumeUsage(srcVol)
+		if err == nil && sizeBytes > 0 && usedBytes > sizeBytes {
+			return fmt.Errorf("Volume data (%s) doesn't fit in a block volume of size %s, set a larger size first", units.GetByteSizeStringIEC(usedBytes, 2), units.GetByteSizeStringIEC(sizeBytes, 2))
+		}
+	}
+
+	// The converted volume is created under a temporary name and the existing one is moved aside
+	// under another one while they're swapped, so make sure neither is in use.
+	tmpVolName := volName + ".convert"
+	oldVolName := volName + ".convert-old"
+
+	for _, name := range []string{tmpVolName, oldVolName} {
+		_, err = VolumeDBGet(b, projectName, name, drivers.VolumeTypeCustom)
+		if err == nil {
+			return fmt.Errorf("Volume %q already exists and prevents converting volume %q", name, volName)
+		} else if !response.IsNotFoundError(err) {
+			return err
+		}
+	}
+
+	// renameVolume renames a custom volume both in the database and on disk and returns the renamed volume.
+	renameVolume := func(vol drivers.Volume, oldName string, newName string) (drivers.Volume, error) {
+		renameDB := func(oldName string, newName string) error {
+			return b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.RenameStoragePoolVolume(ctx, projectName, oldName, newName, db.StoragePoolVolumeTypeCustom, b.ID())
+			})
+		}
+
+		err := renameDB(oldName, newName)
+		if err != nil {
+			return drivers.Volume{}, fmt.Errorf("Failed renaming volume %q to %q: %w", oldName, newName, err)
+		}
+
+		newStorageName := project.StorageVolume(projectName, newName)
+		err = b.driver.RenameVolume(vol, newStorageName, op)
+		if err != nil {
+			_ = renameDB(newName, oldName)
+			return drivers.Volume{}, fmt.Errorf("Failed renaming volume %q to %q: %w", oldName, newName, err)
+		}
+
+		return b.GetVolume(drivers.VolumeTypeCustom, vol.ContentType(), newStorageName, vol.Config()), nil
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	err = VolumeDBCreate(b, projectName, tmpVolName, volume.Description, drivers.VolumeTypeCustom, false, config, volume.CreatedAt, time.Time{}, contentType, true, true)
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(func() { _ = VolumeDBDelete(b, projectName, tmpVolName, drivers.VolumeTypeCustom) })
+
+	tmpVolume, err := VolumeDBGet(b, projectName, tmpVolName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	tmpVol := b.GetVolume(drivers.VolumeTypeCustom, contentType, project.StorageVolume(projectName, tmpVolName), tmpVolume.Config)
+
+	err = b.driver.ConvertVolume(tmpVol, srcVol, op)
+	if err != nil {
+		if errors.Is(err, drivers.ErrNotSupported) {
+			return fmt.Errorf("Storage pool doesn't support converting volumes to content type %q", contentType)
+		}
+
+		return fmt.Errorf("Failed converting volume: %w", err)
+	}
+
+	reverter.Add(func() { _ = b.driver.DeleteVolume(tmpVol, op) })
+
+	// Move the existing volume aside, it's only deleted once the converted volume replaced it.
+	vols := map[string]drivers.Volume{volName: srcVol, tmpVolName: tmpVol}
+	err = swapVolume(func(oldName string, newName string) error {
+		vol, err := renameVolume(vols[oldName], oldName, newName)
+		if err != nil {
+			return err
+		}
+
+		vols[newName] = vol
+		return nil
+	}, volName, tmpVolName, oldVolName)
+	if err != nil {
+		return err
+	}
+
+	oldVol := vols[oldVolName]
+
+	reverter.Success()
+
+	// The converted volume is in place, failing to delete the old one only leaves it behind under its temporary name.
+	err = b.driver.DeleteVolume(oldVol, op)
+	if err != nil {
+		l.Warn("Failed deleting original volume after conversion", logger.Ctx{"volName": oldVolName, "err": err})
+	} else {
+		err = VolumeDBDelete(b, projectName, oldVolName, drivers.VolumeTypeCustom)
+		if err != nil {
+			l.Warn("Failed deleting original volume record after conversion", logger.Ctx{"volName": oldVolName, "err": err})
+		}
+	}
+
+	vol := b.GetVolume(drivers.VolumeTypeCustom, contentType, volStorageName, tmpVolume.Config)
+	b.state.Events.SendLifecycle(projectName, lifecycle.StorageVolumeUpdated.Event(vol, string(vol.Type()), projectName, op, logger.Ctx{"content_type": contentType}))
+
+	return nil
+}
+
 // detectChangedConfig returns the config that has changed between current and new config maps.
 // Also returns a boolean indicating whether all of the changed keys start with "user.".
 // Deleted keys will be returned as having an empty string value.
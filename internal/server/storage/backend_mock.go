@@ -105,6 +105,14 @@ func (b *mockBackend) ApplyPatch(name string) error {
 	return nil
 }
 
+func (b *mockBackend) Scrub(op *operations.Operation) error {
+	return nil
+}
+
+func (b *mockBackend) ScrubStatus() (*api.StoragePoolScrub, error) {
+	return nil, nil
+}
+
 func (b *mockBackend) GetVolume(volType drivers.VolumeType, contentType drivers.ContentType, volName string, volConfig map[string]string) drivers.Volume {
 	return drivers.Volume{}
 }
@@ -295,6 +303,10 @@ func (b *mockBackend) RenameCustomVolume(projectName string, volName string, new
 	return nil
 }
 
+func (b *mockBackend) ConvertCustomVolume(projectName string, volName string, contentType drivers.ContentType, op *operations.Operation) error {
+	return nil
+}
+
 func (b *mockBackend) UpdateCustomVolume(projectName string, volName string, newDesc string, newConfig map[string]string, op *operations.Operation) error {
 	return nil
 }
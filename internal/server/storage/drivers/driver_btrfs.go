@@ -7,7 +7,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 
 	"golang.org/x/sys/unix"
 
@@ -19,6 +21,7 @@ import (
 	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/internal/version"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
 	"github.com/lxc/incus/v6/shared/revert"
 	"github.com/lxc/incus/v6/shared/subprocess"
 	"github.com/lxc/incus/v6/shared/units"
@@ -363,16 +366,7 @@ func (d *btrfs) Update(changedConfig map[string]string) error {
 		}
 
 		// Resize loop file
-		f, err := os.OpenFile(loopPath, os.O_RDWR, 0o600)
-		if err != nil {
-			return err
-		}
-
-		defer func() { _ = f.Close() }()
-
-		sizeBytes, _ := units.ParseByteSizeString(size)
-
-		err = f.Truncate(sizeBytes)
+		err := loopFileGrow(loopPath, size)
 		if err != nil {
 			return err
 		}
@@ -487,6 +481,78 @@ func (d *btrfs) GetResources() (*api.ResourcesStoragePool, error) {
 	return genericVFSGetResources(d)
 }
 
+// Scrub starts a scrub of the btrfs filesystem and waits for it to complete.
+func (d *btrfs) Scrub(op *operations.Operation) error {
+	mountPath := GetPoolMountPath(d.name)
+
+	// Apply the bandwidth limit of the operation to the scrub of all the devices while it runs.
+	if op != nil {
+		var limitMu sync.Mutex
+		limited := false
+		setLimit := func(limit int64) {
+			limitMu.Lock()
+			defer limitMu.Unlock()
+
+			_, err := subprocess.RunCommand("btrfs", "scrub", "limit", "-a", "-l", strconv.FormatInt(limit, 10), mountPath)
+			if err != nil {
+				d.logger.Warn("Failed setting scrub bandwidth limit", logger.Ctx{"limit": limit, "err": err})
+				return
+			}
+
+			limited = limit > 0
+		}
+
+		unregister := op.RateLimiter().OnChange(setLimit)
+		defer func() {
+			unregister()
+
+			limitMu.Lock()
+			reset := limited
+			limitMu.Unlock()
+
+			// The limit is kept by the filesystem, don't let it apply to later scrubs.
+			if reset {
+				setLimit(0)
+			}
+		}()
+	}
+
+	// Run the scrub in the foreground as the status of a scrub started in the background may not be reported yet.
+	done := make(chan error, 1)
+	go func() {
+		_, err := subprocess.RunCommand("btrfs", "scrub", "start", "-B", mountPath)
+		done <- err
+	}()
+
+	stop := func() error {
+		_, err := subprocess.RunCommand("btrfs", "scrub", "cancel", mountPath)
+		return err
+	}
+
+	err := d.waitScrub(d.ScrubStatus, stop, done, op)
+	if err != nil {
+		// Attach to an already running scrub, leaving it running if the operation is cancelled.
+		var runErr subprocess.RunError
+		if errors.As(err, &runErr) && strings.Contains(runErr.StdErr().String(), "already running") {
+			return d.waitScrub(d.ScrubStatus, nil, nil, op)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// ScrubStatus returns the status of the last scrub of the btrfs filesystem.
+func (d *btrfs) ScrubStatus() (*api.StoragePoolScrub, error) {
+	output, err := subprocess.RunCommand("btrfs", "scrub", "status", GetPoolMountPath(d.name))
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting scrub status: %w", err)
+	}
+
+	return parseBtrfsScrubStatus(output)
+}
+
 // MigrationType returns the type of transfer methods to be used when doing migrations between pools in preference order.
 func (d *btrfs) MigrationTypes(contentType ContentType, refresh bool, copySnapshots bool, clusterMove bool, storageMove bool) []localMigration.Type {
 	var rsyncFeatures []string
@@ -10,9 +10,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unsafe"
 
 	"github.com/google/uuid"
@@ -526,6 +528,26 @@ type BTRFSMetaDataHeader struct {
 	Subvolumes []BTRFSSubVolume `json:"subvolumes" yaml:"subvolumes"` // Sub volumes inside the volume (including the top level ones).
 }
 
+// btrfsSendParents works out the parent each sent snapshot is transferred against, keyed by
+// snapshot name, along with the parent of the main volume. The snapshots list holds all source
+// snapshots oldest first. Those which aren't sent are already on the target, so the latest snapshot
+// preceding a sent one is always held by both sides, even when older snapshots were deleted on the
+// source since the last copy. An empty parent means the subvolume is sent in full.
+func btrfsSendParents(snapshots []string, send []string) (map[string]string, string) {
+	parents := make(map[string]string, len(send))
+	parent := ""
+
+	for _, snapName := range snapshots {
+		if slices.Contains(send, snapName) {
+			parents[snapName] = parent
+		}
+
+		parent = snapName
+	}
+
+	return parents, parent
+}
+
 // restorationHeader scans the volume and any specified snapshots, returning a header containing subvolume metadata
 // for use in restoring a volume and its snapshots onto another system. The metadata returned represents how the
 // subvolumes should be restored, not necessarily how they are on disk now. Most of the time this is the same,
@@ -658,3 +680,81 @@ func (d *btrfs) receiveSubVolume(r io.Reader, receivePath string, tracker *iopro
 
 	return subVolPath, nil
 }
+
+// parseBtrfsScrubStatus parses the output of "btrfs scrub status" into the scrub status.
+func parseBtrfsScrubStatus(output string) (*api.StoragePoolScrub, error) {
+	status := &api.StoragePoolScrub{Status: api.StoragePoolScrubStatusNone}
+
+	var duration time.Duration
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "Scrub started":
+			status.StartedAt, err = time.ParseInLocation(time.ANSIC, strings.Join(strings.Fields(value), " "), time.Local)
+			if err != nil {
+				return nil, fmt.Errorf("Failed parsing scrub start time: %w", err)
+			}
+
+		case "Status":
+			switch value {
+			case "running":
+				status.Status = api.StoragePoolScrubStatusRunning
+			case "finished":
+				status.Status = api.StoragePoolScrubStatusFinished
+				status.Progress = 100
+			case "aborted", "interrupted":
+				status.Status = api.StoragePoolScrubStatusCanceled
+			default:
+				return nil, fmt.Errorf("Unknown scrub status %q", value)
+			}
+
+		case "Duration":
+			duration, err = parseScrubDuration(value)
+			if err != nil {
+				return nil, err
+			}
+
+		case "Bytes scrubbed":
+			// Format: <size>  (<percent>%)
+			_, percent, found := strings.Cut(value, "(")
+			if !found {
+				continue
+			}
+
+			status.Progress, err = strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(percent), "%)"), 64)
+			if err != nil {
+				return nil, fmt.Errorf("Failed parsing scrub progress %q: %w", value, err)
+			}
+
+		case "Error summary":
+			// Format: "no errors found" or a list of "<type>=<count>".
+			for _, field := range strings.Fields(value) {
+				_, count, found := strings.Cut(field, "=")
+				if !found {
+					continue
+				}
+
+				errorCount, err := strconv.ParseUint(count, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("Failed parsing scrub errors %q: %w", field, err)
+				}
+
+				status.Errors += errorCount
+			}
+		}
+	}
+
+	if status.Status != api.StoragePoolScrubStatusNone && status.Status != api.StoragePoolScrubStatusRunning {
+		status.FinishedAt = status.StartedAt.Add(duration)
+	}
+
+	return status, nil
+}
@@ -0,0 +1,199 @@
+package drivers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func Test_parseBtrfsScrubStatus(t *testing.T) {
+	date := func(value string) time.Time {
+		ts, err := time.ParseInLocation(time.ANSIC, value, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return ts
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		want    *api.StoragePoolScrub
+		wantErr bool
+	}{
+		{
+			"Never scrubbed",
+			`UUID:             5f1c4b5e-0d1a-4f0c-9a4e-4a0b6f1d2c3e
+	no stats available
+`,
+			&api.StoragePoolScrub{Status: api.StoragePoolScrubStatusNone},
+			false,
+		},
+		{
+			"Scrub finished",
+			`UUID:             5f1c4b5e-0d1a-4f0c-9a4e-4a0b6f1d2c3e
+Scrub started:    Sun Oct 11 00:24:02 2026
+Status:           finished
+Duration:         0:01:05
+Total to scrub:   9.73GiB
+Rate:             153.26MiB/s
+Error summary:    no errors found
+`,
+			&api.StoragePoolScrub{
+				Status:     api.StoragePoolScrubStatusFinished,
+				StartedAt:  date("Sun Oct 11 00:24:02 2026"),
+				FinishedAt: date("Sun Oct 11 00:25:07 2026"),
+				Progress:   100,
+			},
+			false,
+		},
+		{
+			"Scrub finished with errors",
+			`UUID:             5f1c4b5e-0d1a-4f0c-9a4e-4a0b6f1d2c3e
+Scrub started:    Sun Oct 11 00:24:02 2026
+Status:           finished
+Duration:         0:00:10
+Total to scrub:   9.73GiB
+Rate:             996.35MiB/s
+Error summary:    read=1 csum=3
+  Corrected:      3
+  Uncorrectable:  1
+  Unverified:     0
+`,
+			&api.StoragePoolScrub{
+				Status:     api.StoragePoolScrubStatusFinished,
+				StartedAt:  date("Sun Oct 11 00:24:02 2026"),
+				FinishedAt: date("Sun Oct 11 00:24:12 2026"),
+				Progress:   100,
+				Errors:     4,
+			},
+			false,
+		},
+		{
+			"Scrub running",
+			`UUID:             5f1c4b5e-0d1a-4f0c-9a4e-4a0b6f1d2c3e
+Scrub started:    Sun Oct 11 00:24:02 2026
+Status:           running
+Duration:         0:00:20
+Time left:        0:00:45
+ETA:              Sun Oct 11 00:25:07 2026
+Total to scrub:   9.73GiB
+Bytes scrubbed:   2.99GiB  (30.73%)
+Rate:             153.26MiB/s
+Error summary:    no errors found
+`,
+			&api.StoragePoolScrub{
+				Status:    api.StoragePoolScrubStatusRunning,
+				StartedAt: date("Sun Oct 11 00:24:02 2026"),
+				Progress:  30.73,
+			},
+			false,
+		},
+		{
+			"Scrub aborted",
+			`UUID:             5f1c4b5e-0d1a-4f0c-9a4e-4a0b6f1d2c3e
+Scrub started:    Sun Oct 11 00:24:02 2026
+Status:           aborted
+Duration:         0:00:03
+Total to scrub:   9.73GiB
+Rate:             153.26MiB/s
+Error summary:    no errors found
+`,
+			&api.StoragePoolScrub{
+				Status:     api.StoragePoolScrubStatusCanceled,
+				StartedAt:  date("Sun Oct 11 00:24:02 2026"),
+				FinishedAt: date("Sun Oct 11 00:24:05 2026"),
+			},
+			false,
+		},
+		{
+			"Unknown status",
+			`Status:           unknown
+`,
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBtrfsScrubStatus(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBtrfsScrubStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseBtrfsScrubStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_btrfsSendParents(t *testing.T) {
+	tests := []struct {
+		name          string
+		snapshots     []string
+		send          []string
+		wantParents   map[string]string
+		wantVolParent string
+	}{
+		{
+			"Initial copy",
+			[]string{"snap0", "snap1"},
+			[]string{"snap0", "snap1"},
+			map[string]string{"snap0": "", "snap1": "snap0"},
+			"snap1",
+		},
+		{
+			"Refresh of the main volume only",
+			[]string{"snap0", "snap1"},
+			[]string{},
+			map[string]string{},
+			"snap1",
+		},
+		{
+			"Refresh with new snapshots",
+			[]string{"snap0", "snap1", "snap2", "snap3"},
+			[]string{"snap2", "snap3"},
+			map[string]string{"snap2": "snap1", "snap3": "snap2"},
+			"snap3",
+		},
+		{
+			"Refresh after deleting the oldest source snapshot",
+			[]string{"snap1", "snap3"},
+			[]string{"snap3"},
+			map[string]string{"snap3": "snap1"},
+			"snap3",
+		},
+		{
+			"Refresh of a snapshot missing on the target",
+			[]string{"snap0", "snap1", "snap2"},
+			[]string{"snap1"},
+			map[string]string{"snap1": "snap0"},
+			"snap2",
+		},
+		{
+			"Refresh without snapshots",
+			[]string{},
+			[]string{},
+			map[string]string{},
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parents, volParent := btrfsSendParents(tt.snapshots, tt.send)
+			if !reflect.DeepEqual(parents, tt.wantParents) {
+				t.Errorf("btrfsSendParents() parents = %v, want %v", parents, tt.wantParents)
+			}
+
+			if volParent != tt.wantVolParent {
+				t.Errorf("btrfsSendParents() volume parent = %q, want %q", volParent, tt.wantVolParent)
+			}
+		})
+	}
+}
@@ -950,6 +950,11 @@ func (d *btrfs) RefreshVolume(vol Volume, srcVol Volume, srcSnapshots []Volume,
 	return nil
 }
 
+// ConvertVolume creates vol holding the data of srcVol converted to vol's content type.
+func (d *btrfs) ConvertVolume(vol Volume, srcVol Volume, op *operations.Operation) error {
+	return genericVFSConvertVolume(d, vol, srcVol, op)
+}
+
 // DeleteVolume deletes a volume of the storage device. If any snapshots of the volume remain then
 // this function will return an error.
 func (d *btrfs) DeleteVolume(vol Volume, op *operations.Operation) error {
@@ -1458,27 +1463,29 @@ func (d *btrfs) migrateVolumeOptimized(vol Volume, conn io.ReadWriteCloser, volS
 	lastVolPath := "" // Used as parent for differential transfers.
 
 	if !vol.IsSnapshot() && !volSrcArgs.VolumeOnly {
-		snapshots, err := vol.Snapshots(op)
-		if err != nil {
-			return err
-		}
+		// When refreshing, send each snapshot and the main volume against the latest snapshot
+		// both sides have in common so only the differences are transferred.
+		var parents map[string]string
+		var volParent string
 
 		if volSrcArgs.Refresh {
-			for i, snap := range snapshots {
-				if i == 0 {
-					continue
-				}
+			snapshots, err := d.volumeSnapshotsSorted(vol, op)
+			if err != nil {
+				return err
+			}
 
-				_, snapName, _ := api.GetParentAndSnapshotName(snap.name)
+			parents, volParent = btrfsSendParents(snapshots, volSrcArgs.Snapshots)
+		}
 
-				if len(volSrcArgs.Snapshots) > 0 && snapName == volSrcArgs.Snapshots[0] {
-					lastVolPath = snapshots[i-1].MountPath()
-					break
+		for _, snapName := range volSrcArgs.Snapshots {
+			if volSrcArgs.Refresh {
+				lastVolPath = ""
+				if parents[snapName] != "" {
+					parentVol, _ := vol.NewSnapshot(parents[snapName])
+					lastVolPath = parentVol.MountPath()
 				}
 			}
-		}
 
-		for _, snapName := range volSrcArgs.Snapshots {
 			snapVol, _ := vol.NewSnapshot(snapName)
 			err := sendVolume(snapVol, snapVol.MountPath(), lastVolPath)
 			if err != nil {
@@ -1488,11 +1495,9 @@ func (d *btrfs) migrateVolumeOptimized(vol Volume, conn io.ReadWriteCloser, volS
 			lastVolPath = snapVol.MountPath()
 		}
 
-		// If no snapshots are to be copied (because they are on the target already), but snapshots
-		// exist on the source, use the latest snapshot as the parent in order to speed up
-		// optimized refresh.
-		if volSrcArgs.Refresh && len(volSrcArgs.Snapshots) == 0 && len(snapshots) > 0 {
-			lastVolPath = snapshots[len(snapshots)-1].MountPath()
+		if volSrcArgs.Refresh && volParent != "" {
+			parentVol, _ := vol.NewSnapshot(volParent)
+			lastVolPath = parentVol.MountPath()
 		}
 	}
 
@@ -651,6 +651,11 @@ func (d *ceph) RefreshVolume(vol Volume, srcVol Volume, srcSnapshots []Volume, a
 	return genericVFSCopyVolume(d, nil, vol, srcVol, srcSnapshots, true, allowInconsistent, op)
 }
 
+// ConvertVolume creates vol holding the data of srcVol converted to vol's content type.
+func (d *ceph) ConvertVolume(vol Volume, srcVol Volume, op *operations.Operation) error {
+	return genericVFSConvertVolume(d, vol, srcVol, op)
+}
+
 // DeleteVolume deletes a volume of the storage device. If any snapshots of the volume remain then
 // this function will return an error.
 func (d *ceph) DeleteVolume(vol Volume, op *operations.Operation) error {
@@ -116,7 +116,7 @@ func (d *cephfs) CreateVolumeFromCopy(vol Volume, srcVol Volume, copySnapshots b
 				// Mount the source snapshot.
 				err = srcSnapshot.MountTask(func(srcMountPath string, op *operations.Operation) error {
 					// Copy the snapshot.
-					_, err = rsync.LocalCopy(srcMountPath, mountPath, bwlimit, false)
+					_, err = rsync.LocalCopyLimited(srcMountPath, mountPath, bwlimit, operationRateLimiter(op), false)
 					return err
 				}, op)
 
@@ -139,7 +139,7 @@ func (d *cephfs) CreateVolumeFromCopy(vol Volume, srcVol Volume, copySnapshots b
 
 		// Copy source to destination (mounting each volume if needed).
 		err = srcVol.MountTask(func(srcMountPath string, op *operations.Operation) error {
-			_, err := rsync.LocalCopy(srcMountPath, mountPath, bwlimit, false)
+			_, err := rsync.LocalCopyLimited(srcMountPath, mountPath, bwlimit, operationRateLimiter(op), false)
 			return err
 		}, op)
 		if err != nil {
@@ -581,7 +581,7 @@ func (d *cephfs) RestoreVolume(vol Volume, snapshotName string, op *operations.O
 
 	// Restore using rsync.
 	bwlimit := d.config["rsync.bwlimit"]
-	output, err := rsync.LocalCopy(cephSnapPath, vol.MountPath(), bwlimit, false)
+	output, err := rsync.LocalCopyLimited(cephSnapPath, vol.MountPath(), bwlimit, operationRateLimiter(op), false)
 	if err != nil {
 		return fmt.Errorf("Failed to rsync volume: %s: %w", string(output), err)
 	}
@@ -1,6 +1,7 @@
 package drivers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/lxc/incus/v6/internal/instancewriter"
 	"github.com/lxc/incus/v6/internal/linux"
@@ -18,6 +20,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/operations"
 	"github.com/lxc/incus/v6/internal/server/project"
 	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
 	"github.com/lxc/incus/v6/shared/revert"
 	"github.com/lxc/incus/v6/shared/subprocess"
@@ -264,6 +267,81 @@ func (d *common) ApplyPatch(name string) error {
 	return patch()
 }
 
+// Scrub isn't supported by default.
+func (d *common) Scrub(op *operations.Operation) error {
+	return ErrNotSupported
+}
+
+// ScrubStatus isn't supported by default.
+func (d *common) ScrubStatus() (*api.StoragePoolScrub, error) {
+	return nil, ErrNotSupported
+}
+
+// waitScrub polls the scrub status until the scrub is no longer running, reporting its progress on the operation.
+// When done is provided, the scrub runs in the foreground and its completion is reported through it instead.
+// If the operation is cancelled, it stops waiting and stops the scrub through the stop function, when provided.
+func (d *common) waitScrub(getStatus func() (*api.StoragePoolScrub, error), stop func() error, done <-chan error, op *operations.Operation) error {
+	ctx := context.Background()
+	if op != nil {
+		ctx = op.Context()
+	}
+
+	for {
+		status, err := getStatus()
+		if err != nil {
+			return err
+		}
+
+		// The status of a foreground scrub may not be updated yet, so only rely on it otherwise.
+		if done == nil && status.Status != api.StoragePoolScrubStatusRunning {
+			if status.Status == api.StoragePoolScrubStatusCanceled {
+				return fmt.Errorf("Scrub of pool %q was canceled", d.name)
+			}
+
+			return nil
+		}
+
+		if op != nil && status.Status == api.StoragePoolScrubStatusRunning {
+			meta := op.Metadata()
+			if meta == nil {
+				meta = make(map[string]any)
+			}
+
+			progress := fmt.Sprintf("%.2f%%", status.Progress)
+			if meta["scrub_progress"] != progress {
+				meta["scrub_progress"] = progress
+				_ = op.UpdateMetadata(meta)
+			}
+		}
+
+		select {
+		case err := <-done:
+			if err != nil {
+				return err
+			}
+
+			// Check how the scrub ended now that its status is up to date.
+			done = nil
+			continue
+		case <-ctx.Done():
+			if stop != nil {
+				err := stop()
+				if err != nil {
+					return fmt.Errorf("Failed stopping scrub: %w", err)
+				}
+			}
+
+			// Wait for the foreground scrub to exit.
+			if done != nil {
+				<-done
+			}
+
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
 // moveGPTAltHeader moves the GPT alternative header to the end of the disk device supplied.
 // If the device supplied is not detected as not being a GPT disk then no action is taken and nil is returned.
 // If the required sgdisk command is not available a warning is logged, but no error is returned, as really it is
@@ -361,6 +439,11 @@ func (d *common) RefreshVolume(vol Volume, srcVol Volume, srcSnapshots []Volume,
 	return ErrNotSupported
 }
 
+// ConvertVolume isn't supported by default.
+func (d *common) ConvertVolume(vol Volume, srcVol Volume, op *operations.Operation) error {
+	return ErrNotSupported
+}
+
 // DeleteVolume destroys the on-disk state of a volume.
 func (d *common) DeleteVolume(vol Volume, op *operations.Operation) error {
 	return ErrNotSupported
@@ -0,0 +1,54 @@
+package drivers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// Test that a foreground scrub is waited for even though its status isn't reported yet.
+func TestWaitScrubForeground(t *testing.T) {
+	d := &common{name: "pool1"}
+
+	tests := []struct {
+		name    string
+		final   string
+		doneErr error
+		wantErr string
+	}{
+		{name: "finished", final: api.StoragePoolScrubStatusFinished},
+		{name: "canceled", final: api.StoragePoolScrubStatusCanceled, wantErr: "canceled"},
+		{name: "failed", final: api.StoragePoolScrubStatusFinished, doneErr: errors.New("Scrub failed"), wantErr: "Scrub failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan error, 1)
+			finished := false
+
+			// The status of the previous scrub is reported until the foreground scrub exits.
+			getStatus := func() (*api.StoragePoolScrub, error) {
+				if !finished {
+					finished = true
+					done <- tt.doneErr
+
+					return &api.StoragePoolScrub{Status: api.StoragePoolScrubStatusFinished}, nil
+				}
+
+				return &api.StoragePoolScrub{Status: tt.final}, nil
+			}
+
+			err := d.waitScrub(getStatus, nil, done, nil)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.True(t, finished)
+		})
+	}
+}
@@ -164,6 +164,11 @@ func (d *dir) RefreshVolume(vol Volume, srcVol Volume, srcSnapshots []Volume, al
 	return genericVFSCopyVolume(d, d.setupInitialQuota, vol, srcVol, srcSnapshots, true, allowInconsistent, op)
 }
 
+// ConvertVolume creates vol holding the data of srcVol converted to vol's content type.
+func (d *dir) ConvertVolume(vol Volume, srcVol Volume, op *operations.Operation) error {
+	return genericVFSConvertVolume(d, vol, srcVol, op)
+}
+
 // DeleteVolume deletes a volume of the storage device. If any snapshots of the volume remain then
 // this function will return an error.
 func (d *dir) DeleteVolume(vol Volume, op *operations.Operation) error {
@@ -452,7 +457,7 @@ func (d *dir) CreateVolumeSnapshot(snapVol Volume, op *operations.Operation) err
 		d.Logger().Debug("Copying filesystem volume", logger.Ctx{"sourcePath": srcPath, "targetPath": snapPath, "bwlimit": bwlimit, "rsyncArgs": rsyncArgs})
 
 		// Copy filesystem volume into snapshot directory.
-		_, err = rsync.LocalCopy(srcPath, snapPath, bwlimit, true, rsyncArgs...)
+		_, err = rsync.LocalCopyLimited(srcPath, snapPath, bwlimit, operationRateLimiter(op), true, rsyncArgs...)
 		if err != nil {
 			return err
 		}
@@ -592,7 +597,7 @@ func (d *dir) RestoreVolume(vol Volume, snapshotName string, op *operations.Oper
 		}
 
 		bwlimit := d.config["rsync.bwlimit"]
-		_, err := rsync.LocalCopy(srcPath, volPath, bwlimit, true, rsyncArgs...)
+		_, err := rsync.LocalCopyLimited(srcPath, volPath, bwlimit, operationRateLimiter(op), true, rsyncArgs...)
 		if err != nil {
 			return fmt.Errorf("Failed to rsync volume: %w", err)
 		}
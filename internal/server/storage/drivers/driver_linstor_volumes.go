@@ -369,6 +369,11 @@ func (d *linstor) RefreshVolume(vol Volume, srcVol Volume, srcSnapshots []Volume
 	return genericVFSCopyVolume(d, nil, vol, srcVol, srcSnapshots, true, allowInconsistent, op)
 }
 
+// ConvertVolume creates vol holding the data of srcVol converted to vol's content type.
+func (d *linstor) ConvertVolume(vol Volume, srcVol Volume, op *operations.Operation) error {
+	return genericVFSConvertVolume(d, vol, srcVol, op)
+}
+
 // DeleteVolume deletes a volume of the storage device.
 func (d *linstor) DeleteVolume(vol Volume, op *operations.Operation) error {
 	l := d.logger.AddContext(logger.Ctx{"volume": vol.Name()})
@@ -662,16 +662,7 @@ func (d *lvm) Update(changedConfig map[string]string) error {
 		}
 
 		// Resize loop file
-		f, err := os.OpenFile(loopPath, os.O_RDWR, 0o600)
-		if err != nil {
-			return err
-		}
-
-		defer func() { _ = f.Close() }()
-
-		sizeBytes, _ := units.ParseByteSizeString(size)
-
-		err = f.Truncate(sizeBytes)
+		err := loopFileGrow(loopPath, size)
 		if err != nil {
 			return err
 		}
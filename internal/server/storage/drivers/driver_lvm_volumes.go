@@ -193,6 +193,11 @@ func (d *lvm) RefreshVolume(vol Volume, srcVol Volume, srcSnapshots []Volume, al
 	return genericVFSCopyVolume(d, nil, vol, srcVol, srcSnapshots, true, allowInconsistent, op)
 }
 
+// ConvertVolume creates vol holding the data of srcVol converted to vol's content type.
+func (d *lvm) ConvertVolume(vol Volume, srcVol Volume, op *operations.Operation) error {
+	return genericVFSConvertVolume(d, vol, srcVol, op)
+}
+
 // DeleteVolume deletes a volume of the storage device. If any snapshots of the volume remain then this function
 // will return an error.
 func (d *lvm) DeleteVolume(vol Volume, op *operations.Operation) error {
@@ -1413,7 +1418,7 @@ func (d *lvm) RestoreVolume(vol Volume, snapshotName string, op *operations.Oper
 			if snapVol.IsVMBlock() || snapVol.contentType == ContentTypeFS {
 				bwlimit := d.config["rsync.bwlimit"]
 				d.Logger().Debug("Copying filesystem volume", logger.Ctx{"sourcePath": srcMountPath, "targetPath": mountPath, "bwlimit": bwlimit})
-				_, err := rsync.LocalCopy(srcMountPath, mountPath, bwlimit, true)
+				_, err := rsync.LocalCopyLimited(srcMountPath, mountPath, bwlimit, operationRateLimiter(op), true)
 				if err != nil {
 					return err
 				}
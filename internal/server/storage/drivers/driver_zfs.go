@@ -566,16 +566,7 @@ func (d *zfs) Update(changedConfig map[string]string) error {
 		}
 
 		// Resize loop file
-		f, err := os.OpenFile(loopPath, os.O_RDWR, 0o600)
-		if err != nil {
-			return err
-		}
-
-		defer func() { _ = f.Close() }()
-
-		sizeBytes, _ := units.ParseByteSizeString(size)
-
-		err = f.Truncate(sizeBytes)
+		err := loopFileGrow(loopPath, size)
 		if err != nil {
 			return err
 		}
@@ -707,6 +698,43 @@ func (d *zfs) Unmount() (bool, error) {
 	return true, nil
 }
 
+// Scrub starts a scrub of the zpool and waits for it to complete.
+// ZFS can't scrub a single dataset, so the whole zpool is scrubbed even when the pool only uses a dataset of it.
+func (d *zfs) Scrub(op *operations.Operation) error {
+	status, err := d.ScrubStatus()
+	if err != nil {
+		return err
+	}
+
+	poolName := strings.Split(d.config["zfs.pool_name"], "/")[0]
+
+	// Attach to an already running scrub, leaving it running if the operation is cancelled.
+	var stop func() error
+	if status.Status != api.StoragePoolScrubStatusRunning {
+		_, err = subprocess.RunCommand("zpool", "scrub", poolName)
+		if err != nil {
+			return fmt.Errorf("Failed starting scrub: %w", err)
+		}
+
+		stop = func() error {
+			_, err := subprocess.RunCommand("zpool", "scrub", "-s", poolName)
+			return err
+		}
+	}
+
+	return d.waitScrub(d.ScrubStatus, stop, nil, op)
+}
+
+// ScrubStatus returns the status of the last scrub of the zpool.
+func (d *zfs) ScrubStatus() (*api.StoragePoolScrub, error) {
+	output, err := subprocess.RunCommand("zpool", "status", "-p", strings.Split(d.config["zfs.pool_name"], "/")[0])
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting scrub status: %w", err)
+	}
+
+	return parseZpoolScrubStatus(output)
+}
+
 func (d *zfs) GetResources() (*api.ResourcesStoragePool, error) {
 	// Get the total amount of space.
 	availableStr, err := d.getDatasetProperty(d.config["zfs.pool_name"], "available")
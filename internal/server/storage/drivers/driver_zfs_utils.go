@@ -8,7 +8,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -435,6 +437,72 @@ type ZFSMetaDataHeader struct {
 	SnapshotDatasets []ZFSDataset `json:"snapshot_datasets" yaml:"snapshot_datasets"`
 }
 
+// zfsRefreshPlan describes how to bring an existing target volume up to date with its source.
+type zfsRefreshPlan struct {
+	// Snapshots are the names of the source snapshots to send, oldest first.
+	Snapshots []string
+
+	// Common is the latest source snapshot which is also on the target, used as the base of the
+	// incremental streams. It is empty if there is no such snapshot.
+	Common string
+
+	// Reset indicates that the target is missing a requested snapshot older than the common one,
+	// so its snapshots have to be deleted and all source snapshots sent in full.
+	Reset bool
+}
+
+// zfsRefreshPlanFor compares the source and target snapshot datasets by GUID to work out which
+// snapshots need to be sent on refresh. The wanted list holds the names of the snapshots requested
+// by the target, missing snapshots older than the common one are skipped unless requested.
+// Both the source and the target compute the plan, so it must only depend on its arguments.
+func zfsRefreshPlanFor(source []ZFSDataset, target []ZFSDataset, wanted []string) zfsRefreshPlan {
+	plan := zfsRefreshPlan{}
+
+	targetGUIDs := make(map[string]bool, len(target))
+	for _, dataset := range target {
+		targetGUIDs[dataset.GUID] = true
+	}
+
+	commonIndex := -1
+	for i, dataset := range source {
+		if targetGUIDs[dataset.GUID] {
+			plan.Common = dataset.Name
+			commonIndex = i
+		}
+	}
+
+	for i, dataset := range source {
+		if targetGUIDs[dataset.GUID] {
+			continue
+		}
+
+		// Snapshots older than the common one cannot be added to the target incrementally.
+		if i < commonIndex {
+			if slices.Contains(wanted, dataset.Name) {
+				plan.Reset = true
+			}
+
+			continue
+		}
+
+		plan.Snapshots = append(plan.Snapshots, dataset.Name)
+	}
+
+	// Without any common snapshot, the target snapshots are unrelated to the source ones.
+	if commonIndex < 0 && len(target) > 0 {
+		plan.Reset = true
+	}
+
+	if plan.Reset {
+		plan.Snapshots = make([]string, 0, len(source))
+		for _, dataset := range source {
+			plan.Snapshots = append(plan.Snapshots, dataset.Name)
+		}
+	}
+
+	return plan
+}
+
 func (d *zfs) datasetHeader(vol Volume, snapshots []string) (*ZFSMetaDataHeader, error) {
 	migrationHeader := ZFSMetaDataHeader{
 		SnapshotDatasets: make([]ZFSDataset, len(snapshots)),
@@ -472,3 +540,108 @@ func (d *zfs) delegateDataset(vol Volume, pid int) error {
 func ZFSSupportsDelegation() bool {
 	return zfsDelegate
 }
+
+// parseZpoolScrubStatus parses the scan section of "zpool status -p" into the scrub status.
+func parseZpoolScrubStatus(output string) (*api.StoragePoolScrub, error) {
+	status := &api.StoragePoolScrub{Status: api.StoragePoolScrubStatusNone}
+
+	// Extract the scan section (the "scan:" line and its continuation lines).
+	var scan []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if len(scan) == 0 {
+			after, found := strings.CutPrefix(line, "scan:")
+			if found {
+				scan = append(scan, strings.TrimSpace(after))
+			}
+
+			continue
+		}
+
+		if line == "" || strings.HasSuffix(strings.Fields(line)[0], ":") {
+			break
+		}
+
+		scan = append(scan, line)
+	}
+
+	// The scan line is omitted on pools which were never scrubbed.
+	if len(scan) == 0 {
+		return status, nil
+	}
+
+	parseDate := func(value string) (time.Time, error) {
+		return time.ParseInLocation(time.ANSIC, strings.Join(strings.Fields(value), " "), time.Local)
+	}
+
+	line := scan[0]
+	switch {
+	case strings.HasPrefix(line, "scrub in progress since "), strings.HasPrefix(line, "scrub paused since "):
+		var err error
+
+		_, date, _ := strings.Cut(line, " since ")
+		status.StartedAt, err = parseDate(date)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing scrub start time: %w", err)
+		}
+
+		status.Status = api.StoragePoolScrubStatusRunning
+		if strings.HasPrefix(line, "scrub paused") {
+			status.Status = api.StoragePoolScrubStatusCanceled
+		}
+
+		// Look for the "N% done" progress.
+		for _, extra := range scan[1:] {
+			for _, field := range strings.Split(extra, ",") {
+				field = strings.TrimSpace(field)
+				percent, found := strings.CutSuffix(field, "% done")
+				if !found {
+					continue
+				}
+
+				status.Progress, err = strconv.ParseFloat(percent, 64)
+				if err != nil {
+					return nil, fmt.Errorf("Failed parsing scrub progress %q: %w", field, err)
+				}
+			}
+		}
+
+	case strings.HasPrefix(line, "scrub repaired "):
+		// Format: scrub repaired <bytes> in <duration> with <errors> errors on <date>
+		_, rest, _ := strings.Cut(line, " in ")
+		duration, rest, _ := strings.Cut(rest, " with ")
+		errorCount, date, _ := strings.Cut(rest, " errors on ")
+
+		var err error
+		status.FinishedAt, err = parseDate(date)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing scrub end time: %w", err)
+		}
+
+		elapsed, err := parseScrubDuration(duration)
+		if err != nil {
+			return nil, err
+		}
+
+		status.Errors, err = strconv.ParseUint(strings.TrimSpace(errorCount), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing scrub errors %q: %w", errorCount, err)
+		}
+
+		status.Status = api.StoragePoolScrubStatusFinished
+		status.StartedAt = status.FinishedAt.Add(-elapsed)
+		status.Progress = 100
+
+	case strings.HasPrefix(line, "scrub canceled on "):
+		var err error
+
+		status.FinishedAt, err = parseDate(strings.TrimPrefix(line, "scrub canceled on "))
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing scrub end time: %w", err)
+		}
+
+		status.Status = api.StoragePoolScrubStatusCanceled
+	}
+
+	return status, nil
+}
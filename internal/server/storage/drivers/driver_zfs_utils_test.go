@@ -0,0 +1,222 @@
+package drivers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func Test_zfs_refreshPlanFor(t *testing.T) {
+	snap := func(names ...string) []ZFSDataset {
+		datasets := make([]ZFSDataset, 0, len(names))
+		for _, name := range names {
+			datasets = append(datasets, ZFSDataset{Name: name, GUID: "guid-" + name})
+		}
+
+		return datasets
+	}
+
+	tests := []struct {
+		name   string
+		source []ZFSDataset
+		target []ZFSDataset
+		wanted []string
+		want   zfsRefreshPlan
+	}{
+		{
+			"Target up to date",
+			snap("snap0", "snap1"),
+			snap("snap0", "snap1"),
+			nil,
+			zfsRefreshPlan{Common: "snap1"},
+		},
+		{
+			"Only new snapshots are sent",
+			snap("snap0", "snap1", "snap2", "snap3"),
+			snap("snap0", "snap1"),
+			[]string{"snap2", "snap3"},
+			zfsRefreshPlan{Snapshots: []string{"snap2", "snap3"}, Common: "snap1"},
+		},
+		{
+			"Snapshots deleted on the source",
+			snap("snap2", "snap3", "snap4"),
+			snap("snap0", "snap1", "snap2", "snap3"),
+			[]string{"snap4"},
+			zfsRefreshPlan{Snapshots: []string{"snap4"}, Common: "snap3"},
+		},
+		{
+			"Only the latest snapshot is common",
+			snap("snap0", "snap1", "snap2", "snap3"),
+			snap("snap1"),
+			[]string{"snap2", "snap3"},
+			zfsRefreshPlan{Snapshots: []string{"snap2", "snap3"}, Common: "snap1"},
+		},
+		{
+			"Older snapshots not requested",
+			snap("snap0", "snap1", "snap2"),
+			snap("snap1"),
+			[]string{"snap2"},
+			zfsRefreshPlan{Snapshots: []string{"snap2"}, Common: "snap1"},
+		},
+		{
+			"Requested snapshot older than the common one",
+			snap("snap0", "snap1", "snap2"),
+			snap("snap1"),
+			[]string{"snap0", "snap2"},
+			zfsRefreshPlan{Snapshots: []string{"snap0", "snap1", "snap2"}, Common: "snap1", Reset: true},
+		},
+		{
+			"Snapshot recreated with the same name",
+			snap("snap0", "snap1"),
+			[]ZFSDataset{{Name: "snap0", GUID: "guid-snap0"}, {Name: "snap1", GUID: "other"}},
+			[]string{"snap1"},
+			zfsRefreshPlan{Snapshots: []string{"snap1"}, Common: "snap0"},
+		},
+		{
+			"No snapshot in common",
+			snap("snap0", "snap1"),
+			snap("other"),
+			[]string{"snap0", "snap1"},
+			zfsRefreshPlan{Snapshots: []string{"snap0", "snap1"}, Reset: true},
+		},
+		{
+			"No snapshots on the target",
+			snap("snap0", "snap1"),
+			nil,
+			[]string{"snap0", "snap1"},
+			zfsRefreshPlan{Snapshots: []string{"snap0", "snap1"}},
+		},
+		{
+			"No snapshots on the source",
+			nil,
+			nil,
+			nil,
+			zfsRefreshPlan{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := zfsRefreshPlanFor(tt.source, tt.target, tt.wanted)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("zfsRefreshPlanFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseZpoolScrubStatus(t *testing.T) {
+	date := func(value string) time.Time {
+		ts, err := time.ParseInLocation(time.ANSIC, value, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return ts
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   *api.StoragePoolScrub
+	}{
+		{
+			"Never scrubbed",
+			`  pool: default
+ state: ONLINE
+config:
+
+	NAME        STATE     READ WRITE CKSUM
+	default     ONLINE       0     0     0
+	  sda       ONLINE       0     0     0
+
+errors: No known data errors
+`,
+			&api.StoragePoolScrub{Status: api.StoragePoolScrubStatusNone},
+		},
+		{
+			"Scrub finished",
+			`  pool: default
+ state: ONLINE
+  scan: scrub repaired 0 in 00:01:05 with 2 errors on Sun Oct 11 00:24:02 2026
+config:
+
+	NAME        STATE     READ WRITE CKSUM
+	default     ONLINE       0     0     0
+`,
+			&api.StoragePoolScrub{
+				Status:     api.StoragePoolScrubStatusFinished,
+				StartedAt:  date("Sun Oct 11 00:22:57 2026"),
+				FinishedAt: date("Sun Oct 11 00:24:02 2026"),
+				Progress:   100,
+				Errors:     2,
+			},
+		},
+		{
+			"Scrub finished after days",
+			`  scan: scrub repaired 0 in 1 days 00:00:05 with 0 errors on Mon Oct  5 12:00:05 2026
+config:
+`,
+			&api.StoragePoolScrub{
+				Status:     api.StoragePoolScrubStatusFinished,
+				StartedAt:  date("Sun Oct  4 12:00:00 2026"),
+				FinishedAt: date("Mon Oct  5 12:00:05 2026"),
+				Progress:   100,
+			},
+		},
+		{
+			"Scrub in progress",
+			`  pool: default
+ state: ONLINE
+  scan: scrub in progress since Sun Oct 11 00:24:02 2026
+	1320702976 / 10737418240 scanned at 100/s, 858993459 / 10737418240 issued at 50/s
+	0 repaired, 8.00% done, 00:03:00 to go
+config:
+`,
+			&api.StoragePoolScrub{
+				Status:    api.StoragePoolScrubStatusRunning,
+				StartedAt: date("Sun Oct 11 00:24:02 2026"),
+				Progress:  8,
+			},
+		},
+		{
+			"Scrub canceled",
+			`  scan: scrub canceled on Sun Oct 11 00:24:02 2026
+config:
+`,
+			&api.StoragePoolScrub{
+				Status:     api.StoragePoolScrubStatusCanceled,
+				FinishedAt: date("Sun Oct 11 00:24:02 2026"),
+			},
+		},
+		{
+			"No scrub requested",
+			`  scan: none requested
+config:
+`,
+			&api.StoragePoolScrub{Status: api.StoragePoolScrubStatusNone},
+		},
+		{
+			"Resilver only",
+			`  scan: resilvered 1.20G in 00:00:10 with 0 errors on Sun Oct 11 00:24:02 2026
+config:
+`,
+			&api.StoragePoolScrub{Status: api.StoragePoolScrubStatusNone},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseZpoolScrubStatus(tt.output)
+			if err != nil {
+				t.Fatalf("parseZpoolScrubStatus() unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseZpoolScrubStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
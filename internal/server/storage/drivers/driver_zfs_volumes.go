@@ -962,32 +962,20 @@ func (d *zfs) CreateVolumeFromMigration(vol Volume, conn io.ReadWriteCloser, vol
 		}
 
 		// Generate list of snapshots which need to be synced, i.e. are available on the source but not on the target.
-		for _, srcSnapshot := range migrationHeader.SnapshotDatasets {
-			found := false
-
-			for _, dstSnapshot := range respSnapshots {
-				if srcSnapshot.GUID == dstSnapshot.GUID {
-					found = true
-					break
-				}
-			}
+		wanted := make([]string, 0, len(volTargetArgs.Snapshots))
+		for _, snap := range volTargetArgs.Snapshots {
+			wanted = append(wanted, snap.GetName())
+		}
 
-			if !found {
-				syncSnapshots = append(syncSnapshots, &migration.Snapshot{Name: &srcSnapshot.Name})
-			}
+		plan := zfsRefreshPlanFor(migrationHeader.SnapshotDatasets, respSnapshots, wanted)
+		for _, snapName := range plan.Snapshots {
+			syncSnapshots = append(syncSnapshots, &migration.Snapshot{Name: &snapName})
 		}
 
-		// The following scenario will result in a failure:
-		// - The source has more than one snapshot
-		// - The target has at least one of these snapshot, but not the very first
-		//
-		// It will fail because the source tries sending the first snapshot using `zfs send <first>`.
-		// Since the target does have snapshots, `zfs receive` will fail with:
-		//     cannot receive new filesystem stream: destination has snapshots
-		//
-		// We therefore need to check the snapshots, and delete all target snapshots if the above
-		// scenario is true.
-		if !volumeOnly && len(respSnapshots) > 0 && len(migrationHeader.SnapshotDatasets) > 0 && respSnapshots[0].GUID != migrationHeader.SnapshotDatasets[0].GUID {
+		// The target snapshots can only be kept if the missing ones are all newer than the latest
+		// snapshot in common with the source, as `zfs receive` can't insert older snapshots.
+		// Otherwise delete all target snapshots so that the source sends everything in full.
+		if !volumeOnly && plan.Reset {
 			for _, snapVol := range snapshots {
 				// Delete
 				err = d.DeleteVolume(snapVol, op)
@@ -998,13 +986,6 @@ func (d *zfs) CreateVolumeFromMigration(vol Volume, conn io.ReadWriteCloser, vol
 
 			// Let the source know that we don't have any snapshots.
 			respSnapshots = []ZFSDataset{}
-
-			// Let the source know that we need all snapshots.
-			syncSnapshots = []*migration.Snapshot{}
-
-			for _, dataset := range migrationHeader.SnapshotDatasets {
-				syncSnapshots = append(syncSnapshots, &migration.Snapshot{Name: &dataset.Name})
-			}
 		} else {
 			// Delete local snapshots which exist on the target but not on the source.
 			for _, snapVol := range snapshots {
@@ -1489,6 +1470,11 @@ func (d *zfs) RefreshVolume(vol Volume, srcVol Volume, srcSnapshots []Volume, al
 	return nil
 }
 
+// ConvertVolume creates vol holding the data of srcVol converted to vol's content type.
+func (d *zfs) ConvertVolume(vol Volume, srcVol Volume, op *operations.Operation) error {
+	return genericVFSConvertVolume(d, vol, srcVol, op)
+}
+
 // DeleteVolume deletes a volume of the storage device. If any snapshots of the volume remain then
 // this function will return an error.
 // For image volumes, both filesystem and block volumes will be removed.
@@ -2584,10 +2570,12 @@ func (d *zfs) MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *loc
 		return fmt.Errorf("Filesystem zvol detected in source but target does not support receiving zvols")
 	}
 
-	incrementalStream := true
-	var migrationHeader ZFSMetaDataHeader
+	// The latest snapshot the target has in common with the source, used as the base of incremental streams.
+	commonSnapshot := ""
 
 	if volSrcArgs.Refresh && slices.Contains(volSrcArgs.MigrationType.Features, migration.ZFSFeatureMigrationHeader) {
+		var migrationHeader ZFSMetaDataHeader
+
 		buf, err := io.ReadAll(conn)
 		if err != nil {
 			return fmt.Errorf("Failed reading ZFS migration header: %w", err)
@@ -2598,9 +2586,12 @@ func (d *zfs) MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *loc
 			return fmt.Errorf("Failed decoding ZFS migration header: %w", err)
 		}
 
-		// If the target has no snapshots we cannot use incremental streams and will do a normal copy operation instead.
-		if len(migrationHeader.SnapshotDatasets) == 0 {
-			incrementalStream = false
+		// Compute the same plan as the target to know which snapshots it expects.
+		plan := zfsRefreshPlanFor(srcMigrationHeader.SnapshotDatasets, migrationHeader.SnapshotDatasets, volSrcArgs.Snapshots)
+		commonSnapshot = plan.Common
+
+		// If the target has no snapshot in common we cannot use incremental streams and will do a normal copy operation instead.
+		if commonSnapshot == "" {
 			volSrcArgs.Refresh = false
 		}
 
@@ -2608,30 +2599,31 @@ func (d *zfs) MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *loc
 
 		// Override volSrcArgs.Snapshots to only include snapshots which need to be sent.
 		if !volSrcArgs.VolumeOnly {
-			for _, srcDataset := range srcMigrationHeader.SnapshotDatasets {
-				found := false
-
-				for _, dstDataset := range migrationHeader.SnapshotDatasets {
-					if srcDataset.GUID == dstDataset.GUID {
-						found = true
-						break
-					}
-				}
+			volSrcArgs.Snapshots = plan.Snapshots
+		}
+	} else if volSrcArgs.Refresh {
+		// Without the migration header, assume the target has all the snapshots preceding the ones being sent.
+		snapshots, err := d.VolumeSnapshots(vol, op)
+		if err != nil {
+			return err
+		}
 
-				if !found {
-					volSrcArgs.Snapshots = append(volSrcArgs.Snapshots, srcDataset.Name)
-				}
+		for _, snapName := range snapshots {
+			if len(volSrcArgs.Snapshots) > 0 && snapName == volSrcArgs.Snapshots[0] {
+				break
 			}
+
+			commonSnapshot = snapName
 		}
 	}
 
-	return d.migrateVolumeOptimized(vol, conn, volSrcArgs, incrementalStream, op)
+	return d.migrateVolumeOptimized(vol, conn, volSrcArgs, commonSnapshot, op)
 }
 
-func (d *zfs) migrateVolumeOptimized(vol Volume, conn io.ReadWriteCloser, volSrcArgs *localMigration.VolumeSourceArgs, incremental bool, op *operations.Operation) error {
+func (d *zfs) migrateVolumeOptimized(vol Volume, conn io.ReadWriteCloser, volSrcArgs *localMigration.VolumeSourceArgs, commonSnapshot string, op *operations.Operation) error {
 	if vol.IsVMBlock() {
 		fsVol := vol.NewVMBlockFilesystemVolume()
-		err := d.migrateVolumeOptimized(fsVol, conn, volSrcArgs, incremental, op)
+		err := d.migrateVolumeOptimized(fsVol, conn, volSrcArgs, commonSnapshot, op)
 		if err != nil {
 			return err
 		}
@@ -2646,22 +2638,9 @@ func (d *zfs) migrateVolumeOptimized(vol Volume, conn io.ReadWriteCloser, volSrc
 
 		// Figure out parent and current subvolumes.
 		parent := ""
-		if i == 0 && volSrcArgs.Refresh {
-			snapshots, err := vol.Snapshots(op)
-			if err != nil {
-				return err
-			}
-
-			for k, snap := range snapshots {
-				if k == 0 {
-					continue
-				}
-
-				if snap.name == fmt.Sprintf("%s/%s", vol.name, snapName) {
-					parent = d.dataset(snapshots[k-1], false)
-					break
-				}
-			}
+		if i == 0 && commonSnapshot != "" {
+			commonVol, _ := vol.NewSnapshot(commonSnapshot)
+			parent = d.dataset(commonVol, false)
 		} else if i > 0 {
 			oldSnapshot, _ := vol.NewSnapshot(volSrcArgs.Snapshots[i-1])
 			parent = d.dataset(oldSnapshot, false)
@@ -2706,16 +2685,10 @@ func (d *zfs) migrateVolumeOptimized(vol Volume, conn io.ReadWriteCloser, volSrc
 		}()
 	}
 
-	// Get parent snapshot of the main volume which can then be used to send an incremental stream.
-	if volSrcArgs.Refresh && incremental {
-		localSnapshots, err := vol.Snapshots(op)
-		if err != nil {
-			return err
-		}
-
-		if len(localSnapshots) > 0 {
-			finalParent = d.dataset(localSnapshots[len(localSnapshots)-1], false)
-		}
+	// If no snapshots were sent, send the main volume incrementally from the common snapshot.
+	if finalParent == "" && commonSnapshot != "" {
+		commonVol, _ := vol.NewSnapshot(commonSnapshot)
+		finalParent = d.dataset(commonVol, false)
 	}
 
 	// Send the volume itself.
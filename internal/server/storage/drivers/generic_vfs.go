@@ -11,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"golang.org/x/sys/unix"
+
 	"github.com/lxc/incus/v6/internal/instancewriter"
 	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/migration"
@@ -1008,7 +1010,7 @@ func genericVFSCopyVolume(d Driver, initVolume func(vol Volume) (revert.Hook, er
 	// Define function to send a filesystem volume.
 	sendFSVol := func(srcPath string, targetPath string) error {
 		d.Logger().Debug("Copying filesystem volume", logger.Ctx{"sourcePath": srcPath, "targetPath": targetPath, "bwlimit": bwlimit, "rsyncArgs": rsyncArgs})
-		_, err := rsync.LocalCopy(srcPath, targetPath, bwlimit, true, rsyncArgs...)
+		_, err := rsync.LocalCopyLimited(srcPath, targetPath, bwlimit, operationRateLimiter(op), true, rsyncArgs...)
 
 		status, _ := linux.ExitStatus(err)
 		if allowInconsistent && status == 24 {
@@ -1135,6 +1137,121 @@ func genericVFSCopyVolume(d Driver, initVolume func(vol Volume) (revert.Hook, er
 	return nil
 }
 
+// genericVFSConvertVolume creates vol holding the data of srcVol converted to vol's content type.
+// When converting to a block volume, a new filesystem is created on it and the source files copied into it.
+// When converting to a filesystem volume, the filesystem found on the source block volume is mounted read-only
+// and its files copied out of it.
+func genericVFSConvertVolume(d Driver, vol Volume, srcVol Volume, op *operations.Operation) error {
+	if vol.volType != VolumeTypeCustom || srcVol.volType != VolumeTypeCustom {
+		return fmt.Errorf("Only custom volumes can be converted")
+	}
+
+	if vol.contentType == srcVol.contentType {
+		return fmt.Errorf("Content type of source and target must be different")
+	}
+
+	for _, contentType := range []ContentType{vol.contentType, srcVol.contentType} {
+		if contentType != ContentTypeFS && contentType != ContentTypeBlock {
+			return fmt.Errorf("Volumes of content type %q cannot be converted", contentType)
+		}
+	}
+
+	bwlimit := d.Config()["rsync.bwlimit"]
+	toBlock := vol.contentType == ContentTypeBlock
+
+	blockVol, fsVol := vol, srcVol
+	if !toBlock {
+		blockVol, fsVol = srcVol, vol
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	err := d.CreateVolume(vol, nil, op)
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(func() { _ = d.DeleteVolume(vol, op) })
+
+	err = fsVol.MountTask(func(fsMountPath string, op *operations.Operation) error {
+		return blockVol.MountTask(func(_ string, op *operations.Operation) error {
+			devPath, err := d.GetVolumeDiskPath(blockVol)
+			if err != nil {
+				return err
+			}
+
+			// File backed block volumes need a loop device in order to be mounted.
+			if !linux.IsBlockdevPath(devPath) {
+				devPath, err = loopDeviceSetup(devPath)
+				if err != nil {
+					return err
+				}
+
+				defer func() { _ = loopDeviceAutoDetach(devPath) }()
+			}
+
+			var fsType string
+			var mountFlags uintptr
+
+			if toBlock {
+				fsType = vol.ConfigBlockFilesystem()
+				msg, err := makeFSType(devPath, fsType, nil)
+				if err != nil {
+					return fmt.Errorf("Failed creating %q filesystem on block volume: %s: %w", fsType, msg, err)
+				}
+			} else {
+				// Only block volumes directly holding a filesystem (no partition table) can be converted.
+				fsType, err = fsProbe(devPath)
+				if err != nil || fsType == "" {
+					return fmt.Errorf("Block volume %q doesn't contain a filesystem", srcVol.name)
+				}
+
+				// The filesystem may have been crafted by an instance, so don't trust anything it holds.
+				mountFlags = unix.MS_RDONLY | unix.MS_NOSUID | unix.MS_NODEV | unix.MS_NOEXEC
+			}
+
+			mountPath, err := os.MkdirTemp(GetPoolMountPath(d.Name()), "convert.")
+			if err != nil {
+				return fmt.Errorf("Failed creating temporary mount path: %w", err)
+			}
+
+			defer func() { _ = os.Remove(mountPath) }()
+
+			err = TryMount(devPath, mountPath, fsType, mountFlags, "")
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = TryUnmount(mountPath, 0) }()
+
+			srcPath, targetPath := fsMountPath, mountPath
+			if !toBlock {
+				srcPath, targetPath = mountPath, fsMountPath
+			}
+
+			d.Logger().Debug("Converting volume", logger.Ctx{"sourcePath": srcPath, "targetPath": targetPath, "contentType": vol.contentType, "bwlimit": bwlimit})
+			_, err = rsync.LocalCopyLimited(srcPath, targetPath, bwlimit, operationRateLimiter(op), true)
+			if err != nil {
+				return err
+			}
+
+			// Ensure the mounted directory of a new filesystem volume has the correct permissions set.
+			if !toBlock {
+				return vol.EnsureMountPath()
+			}
+
+			return nil
+		}, op)
+	}, op)
+	if err != nil {
+		return err
+	}
+
+	reverter.Success()
+	return nil
+}
+
 // genericVFSListVolumes returns a list of volumes in storage pool.
 func genericVFSListVolumes(d Driver) ([]Volume, error) {
 	var vols []Volume
@@ -0,0 +1,112 @@
+package drivers
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/internal/server/sys"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// Test ConvertVolume rejects invalid conversions on all drivers before touching any storage.
+func Test_genericVFSConvertVolume(t *testing.T) {
+	tests := []struct {
+		name   string
+		vol    Volume
+		srcVol Volume
+		err    string
+	}{
+		{
+			name:   "Same content type",
+			vol:    Volume{volType: VolumeTypeCustom, contentType: ContentTypeBlock},
+			srcVol: Volume{volType: VolumeTypeCustom, contentType: ContentTypeBlock},
+			err:    "Content type of source and target must be different",
+		},
+		{
+			name:   "Instance volume",
+			vol:    Volume{volType: VolumeTypeVM, contentType: ContentTypeBlock},
+			srcVol: Volume{volType: VolumeTypeContainer, contentType: ContentTypeFS},
+			err:    "Only custom volumes can be converted",
+		},
+		{
+			name:   "ISO volume",
+			vol:    Volume{volType: VolumeTypeCustom, contentType: ContentTypeFS},
+			srcVol: Volume{volType: VolumeTypeCustom, contentType: ContentTypeISO},
+			err:    `Volumes of content type "iso" cannot be converted`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := genericVFSConvertVolume(&dir{}, tt.vol, tt.srcVol, nil)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+// Test a filesystem volume converted to a block volume and back keeps its data.
+func Test_genericVFSConvertVolumeRoundTrip(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Converting volumes requires root")
+	}
+
+	for _, tool := range []string{"mkfs.ext4", "blkid", "rsync"} {
+		_, err := exec.LookPath(tool)
+		if err != nil {
+			t.Skipf("Missing %q", tool)
+		}
+	}
+
+	_, err := os.Stat("/dev/loop-control")
+	if err != nil {
+		t.Skip("Loop devices aren't available")
+	}
+
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	d := &dir{}
+	d.init(&state.State{OS: &sys.OS{}}, "test", map[string]string{}, logger.AddContext(nil), func(volType VolumeType, volName string) (int64, error) { return volIDQuotaSkip, nil }, nil)
+
+	config := map[string]string{"size": "64MiB"}
+	fsVol := NewVolume(d, "test", VolumeTypeCustom, ContentTypeFS, "default_foo", config, nil)
+	blockVol := NewVolume(d, "test", VolumeTypeCustom, ContentTypeBlock, "default_foo.convert", config, nil)
+	convertedVol := NewVolume(d, "test", VolumeTypeCustom, ContentTypeFS, "default_foo.convert-back", config, nil)
+
+	require.NoError(t, d.CreateVolume(fsVol, nil, nil))
+	require.NoError(t, os.MkdirAll(filepath.Join(fsVol.MountPath(), "dir"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(fsVol.MountPath(), "dir", "file"), []byte("data"), 0o600))
+
+	require.NoError(t, d.ConvertVolume(blockVol, fsVol, nil))
+
+	devPath, err := d.GetVolumeDiskPath(blockVol)
+	require.NoError(t, err)
+	assert.FileExists(t, devPath)
+
+	require.NoError(t, d.ConvertVolume(convertedVol, blockVol, nil))
+
+	content, err := os.ReadFile(filepath.Join(convertedVol.MountPath(), "dir", "file"))
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+
+	// The source volumes are left untouched for the caller to swap and delete.
+	assert.FileExists(t, filepath.Join(fsVol.MountPath(), "dir", "file"))
+	assert.FileExists(t, devPath)
+}
+
+func Test_ConvertVolumeNotSupported(t *testing.T) {
+	for driverName, d := range map[string]Driver{"cephfs": &cephfs{}, "cephobject": &cephobject{}} {
+		t.Run(driverName, func(t *testing.T) {
+			vol := Volume{volType: VolumeTypeCustom, contentType: ContentTypeBlock}
+			srcVol := Volume{volType: VolumeTypeCustom, contentType: ContentTypeFS}
+
+			err := d.ConvertVolume(vol, srcVol, nil)
+			assert.ErrorIs(t, err, ErrNotSupported)
+		})
+	}
+}
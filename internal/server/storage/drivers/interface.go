@@ -50,6 +50,12 @@ type Driver interface {
 	Update(changedConfig map[string]string) error
 	ApplyPatch(name string) error
 
+	// Scrub runs a data integrity check of the pool and waits for it to complete.
+	Scrub(op *operations.Operation) error
+
+	// ScrubStatus returns the status of the last (or current) scrub of the pool.
+	ScrubStatus() (*api.StoragePoolScrub, error)
+
 	// Buckets.
 	ValidateBucket(bucket Volume) error
 	GetBucketURL(bucketName string) *url.URL
@@ -67,6 +73,10 @@ type Driver interface {
 	CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Operation) error
 	CreateVolumeFromCopy(vol Volume, srcVol Volume, copySnapshots bool, allowInconsistent bool, op *operations.Operation) error
 	RefreshVolume(vol Volume, srcVol Volume, srcSnapshots []Volume, allowInconsistent bool, op *operations.Operation) error
+
+	// ConvertVolume creates vol holding the data of srcVol converted to vol's content type.
+	ConvertVolume(vol Volume, srcVol Volume, op *operations.Operation) error
+
 	DeleteVolume(vol Volume, op *operations.Operation) error
 	RenameVolume(vol Volume, newName string, op *operations.Operation) error
 	UpdateVolume(vol Volume, changedConfig map[string]string) error
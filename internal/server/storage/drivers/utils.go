@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"unsafe"
@@ -17,6 +18,7 @@ import (
 	"golang.org/x/sys/unix"
 
 	internalInstance "github.com/lxc/incus/v6/internal/instance"
+	internalIO "github.com/lxc/incus/v6/internal/io"
 	"github.com/lxc/incus/v6/internal/linux"
 	"github.com/lxc/incus/v6/internal/server/operations"
 	internalUtil "github.com/lxc/incus/v6/internal/util"
@@ -24,6 +26,7 @@ import (
 	"github.com/lxc/incus/v6/shared/idmap"
 	"github.com/lxc/incus/v6/shared/logger"
 	"github.com/lxc/incus/v6/shared/subprocess"
+	"github.com/lxc/incus/v6/shared/units"
 	"github.com/lxc/incus/v6/shared/util"
 )
 
@@ -866,6 +869,55 @@ func loopFileSizeDefault() (uint64, error) {
 	return 0, fmt.Errorf("Insufficient free space to create default sized 5GiB pool")
 }
 
+// loopFileGrow grows the loop file at loopPath to the provided size.
+// It refuses to shrink the file and checks that the host filesystem has enough free space for the growth.
+func loopFileGrow(loopPath string, size string) error {
+	sizeBytes, err := units.ParseByteSizeString(size)
+	if err != nil {
+		return fmt.Errorf("Invalid pool size %q: %w", size, err)
+	}
+
+	fi, err := os.Stat(loopPath)
+	if err != nil {
+		return err
+	}
+
+	if sizeBytes < fi.Size() {
+		return fmt.Errorf("Pool cannot be shrunk below its current size of %s", units.GetByteSizeStringIEC(fi.Size(), 2))
+	}
+
+	if sizeBytes == fi.Size() {
+		return nil
+	}
+
+	// Check that the host has enough free space to back the additional capacity.
+	st := unix.Statfs_t{}
+	err = unix.Statfs(filepath.Dir(loopPath), &st)
+	if err != nil {
+		return fmt.Errorf("Failed getting free space of %q: %w", filepath.Dir(loopPath), err)
+	}
+
+	growBytes := uint64(sizeBytes - fi.Size())
+	freeBytes := uint64(st.Frsize) * st.Bavail
+	if growBytes > freeBytes {
+		return fmt.Errorf("Insufficient free space on host to grow pool by %s (%s available)", units.GetByteSizeStringIEC(int64(growBytes), 2), units.GetByteSizeStringIEC(int64(freeBytes), 2))
+	}
+
+	f, err := os.OpenFile(loopPath, os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	err = f.Truncate(sizeBytes)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
 // loopFileSetup sets up a loop device for the provided sourcePath.
 // It tries to enable direct I/O if supported.
 func loopDeviceSetup(sourcePath string) (string, error) {
@@ -1010,3 +1062,49 @@ func roundAbove(above, val int64) int64 {
 
 	return rounded
 }
+
+// parseScrubDuration parses a scrub duration as reported by "zpool status" and "btrfs scrub status"
+// ("[<days> days ]<hours>:<minutes>:<seconds>").
+func parseScrubDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	var duration time.Duration
+
+	days, clock, found := strings.Cut(value, " days ")
+	if found {
+		count, err := strconv.ParseUint(days, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid scrub duration %q: %w", value, err)
+		}
+
+		duration = time.Duration(count) * 24 * time.Hour
+	} else {
+		clock = value
+	}
+
+	fields := strings.Split(clock, ":")
+	if len(fields) != 3 {
+		return 0, fmt.Errorf("Invalid scrub duration %q", value)
+	}
+
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		count, err := strconv.ParseUint(fields[i], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid scrub duration %q: %w", value, err)
+		}
+
+		duration += time.Duration(count) * unit
+	}
+
+	return duration, nil
+}
+
+// operationRateLimiter returns the rate limiter of the operation, letting its bandwidth be limited while it runs.
+// It returns nil without an operation.
+func operationRateLimiter(op *operations.Operation) *internalIO.RateLimiter {
+	if op == nil {
+		return nil
+	}
+
+	return op.RateLimiter()
+}
@@ -1,9 +1,15 @@
 package drivers
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	internalUtil "github.com/lxc/incus/v6/internal/util"
 )
 
 // Test GetVolumeMountPath.
@@ -35,3 +41,116 @@ func TestGetVolumeMountPath(t *testing.T) {
 	expected = GetPoolMountPath(poolName) + "/virtual-machines/testvol"
 	assert.Equal(t, expected, path)
 }
+
+// Test loopFileGrow.
+func TestLoopFileGrow(t *testing.T) {
+	loopPath := filepath.Join(t.TempDir(), "pool.img")
+
+	f, err := os.Create(loopPath)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Truncate(1024*1024))
+	assert.NoError(t, f.Close())
+
+	// Test growing the file.
+	err = loopFileGrow(loopPath, "2MiB")
+	assert.NoError(t, err)
+
+	fi, err := os.Stat(loopPath)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2*1024*1024), fi.Size())
+
+	// Test same size is a no-op.
+	err = loopFileGrow(loopPath, "2MiB")
+	assert.NoError(t, err)
+
+	// Test shrinking is refused.
+	err = loopFileGrow(loopPath, "1MiB")
+	assert.Error(t, err)
+
+	fi, err = os.Stat(loopPath)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2*1024*1024), fi.Size())
+
+	// Test growing beyond the available host space is refused.
+	err = loopFileGrow(loopPath, "1EiB")
+	assert.Error(t, err)
+
+	// Test invalid size.
+	err = loopFileGrow(loopPath, "foo")
+	assert.Error(t, err)
+
+	// Test missing file.
+	err = loopFileGrow(filepath.Join(t.TempDir(), "missing.img"), "2MiB")
+	assert.Error(t, err)
+}
+
+// Test that the drivers only grow loop-backed pools, without shrinking them.
+func TestLoopPoolUpdateSize(t *testing.T) {
+	t.Setenv("INCUS_DIR", t.TempDir())
+
+	err := os.MkdirAll(internalUtil.VarPath("disks"), 0o700)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		driver func(source string) Driver
+	}{
+		{"btrfs", func(source string) Driver {
+			return &btrfs{common: common{name: "pool-btrfs", config: map[string]string{"source": source}}}
+		}},
+		{"lvm", func(source string) Driver {
+			return &lvm{common: common{name: "pool-lvm", config: map[string]string{"source": source, "lvm.vg_name": "pool-lvm"}}}
+		}},
+		{"zfs", func(source string) Driver {
+			return &zfs{common: common{name: "pool-zfs", config: map[string]string{"source": source, "zfs.pool_name": "pool-zfs"}}}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loopPath := loopFilePath("pool-" + tt.name)
+
+			f, err := os.Create(loopPath)
+			require.NoError(t, err)
+			require.NoError(t, f.Truncate(2*1024*1024))
+			require.NoError(t, f.Close())
+
+			// Pools which aren't backed by a loop file can't be resized.
+			err = tt.driver("/dev/sdb").Update(map[string]string{"size": "4MiB"})
+			assert.EqualError(t, err, "Cannot resize non-loopback pools")
+
+			// Loop-backed pools can't be shrunk.
+			err = tt.driver(loopPath).Update(map[string]string{"size": "1MiB"})
+			assert.ErrorContains(t, err, "cannot be shrunk")
+
+			// Loop-backed pools can't grow beyond the space available on the host.
+			err = tt.driver(loopPath).Update(map[string]string{"size": "1EiB"})
+			assert.ErrorContains(t, err, "Insufficient free space")
+
+			fi, err := os.Stat(loopPath)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2*1024*1024), fi.Size())
+		})
+	}
+}
+
+// Test parseScrubDuration.
+func TestParseScrubDuration(t *testing.T) {
+	duration, err := parseScrubDuration("0:01:05")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute+5*time.Second, duration)
+
+	duration, err = parseScrubDuration("125:00:00")
+	assert.NoError(t, err)
+	assert.Equal(t, 125*time.Hour, duration)
+
+	duration, err = parseScrubDuration("2 days 01:00:30")
+	assert.NoError(t, err)
+	assert.Equal(t, 49*time.Hour+30*time.Second, duration)
+
+	_, err = parseScrubDuration("00:05")
+	assert.Error(t, err)
+
+	_, err = parseScrubDuration("x days 00:00:05")
+	assert.Error(t, err)
+}
@@ -532,6 +532,65 @@ func (v Volume) ConfigSize() string {
 	return size
 }
 
+// ConfigSizeSoftLimit returns the usage in bytes above which a warning is raised for the volume, based on the
+// "size.soft_limit" config key. The soft limit can either be a size or a percentage of the volume's size and must
+// be below the volume's size. Returns -1 if no soft limit is set.
+func (v Volume) ConfigSizeSoftLimit() (int64, error) {
+	softLimit := v.config["size.soft_limit"]
+	if softLimit == "" {
+		return -1, nil
+	}
+
+	var sizeBytes int64
+	size := v.ConfigSize()
+	if size != "" {
+		var err error
+		sizeBytes, err = units.ParseByteSizeString(size)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	percentage, isPercentage := strings.CutSuffix(softLimit, "%")
+	if isPercentage {
+		value, err := strconv.ParseFloat(percentage, 64)
+		if err != nil {
+			return -1, fmt.Errorf("Invalid soft limit percentage %q: %w", softLimit, err)
+		}
+
+		if value <= 0 || value >= 100 {
+			return -1, fmt.Errorf("Soft limit percentage must be between 0%% and 100%%")
+		}
+
+		if sizeBytes <= 0 {
+			return -1, fmt.Errorf("A soft limit percentage requires the volume size to be set")
+		}
+
+		return int64(float64(sizeBytes) * value / 100), nil
+	}
+
+	softLimitBytes, err := units.ParseByteSizeString(softLimit)
+	if err != nil {
+		return -1, err
+	}
+
+	if softLimitBytes <= 0 {
+		return -1, fmt.Errorf("Soft limit must be greater than zero")
+	}
+
+	if sizeBytes > 0 && softLimitBytes >= sizeBytes {
+		return -1, fmt.Errorf("Soft limit %q must be below the volume size %q", softLimit, size)
+	}
+
+	return softLimitBytes, nil
+}
+
+// VolumeSoftLimitExceeded returns whether the used bytes of a volume reached the soft limit returned by
+// ConfigSizeSoftLimit. A negative soft limit is never exceeded.
+func VolumeSoftLimitExceeded(usedBytes int64, softLimitBytes int64) bool {
+	return softLimitBytes >= 0 && usedBytes >= softLimitBytes
+}
+
 // ConfigSizeFromSource derives the volume size to use for a new volume when copying from a source volume.
 // Where possible (if the source volume has a volatile.rootfs.size property), it checks that the source volume
 // isn't larger than the volume's "size" setting and the pool's "volume.size" setting.
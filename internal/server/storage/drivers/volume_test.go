@@ -110,3 +110,90 @@ func Test_Volume_ConfigSizeFromSource(t *testing.T) {
 		assert.Equal(t, test.err, err)
 	}
 }
+
+// Test Volume_ConfigSizeSoftLimit.
+func Test_Volume_ConfigSizeSoftLimit(t *testing.T) {
+	driver := dir{}
+
+	tests := []struct {
+		name     string
+		config   map[string]string
+		limit    int64
+		hasError bool
+	}{
+		{
+			name:   "No soft limit",
+			config: map[string]string{"size": "1GiB"},
+			limit:  -1,
+		},
+		{
+			name:   "Percentage of the volume size",
+			config: map[string]string{"size": "1GiB", "size.soft_limit": "80%"},
+			limit:  858993459,
+		},
+		{
+			name:   "Absolute size",
+			config: map[string]string{"size": "1GiB", "size.soft_limit": "512MiB"},
+			limit:  536870912,
+		},
+		{
+			name:   "Absolute size without volume size",
+			config: map[string]string{"size.soft_limit": "512MiB"},
+			limit:  536870912,
+		},
+		{
+			name:     "Percentage without volume size",
+			config:   map[string]string{"size.soft_limit": "80%"},
+			limit:    -1,
+			hasError: true,
+		},
+		{
+			name:     "Percentage out of range",
+			config:   map[string]string{"size": "1GiB", "size.soft_limit": "100%"},
+			limit:    -1,
+			hasError: true,
+		},
+		{
+			name:     "Absolute size above the volume size",
+			config:   map[string]string{"size": "1GiB", "size.soft_limit": "2GiB"},
+			limit:    -1,
+			hasError: true,
+		},
+		{
+			name:     "Invalid size",
+			config:   map[string]string{"size": "1GiB", "size.soft_limit": "foo"},
+			limit:    -1,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vol := Volume{driver: &driver, volType: VolumeTypeCustom, contentType: ContentTypeFS, config: test.config}
+			limit, err := vol.ConfigSizeSoftLimit()
+			assert.Equal(t, test.limit, limit)
+			assert.Equal(t, test.hasError, err != nil)
+		})
+	}
+}
+
+// Test that the soft limit is crossed once the usage reaches it.
+func Test_Volume_ConfigSizeSoftLimit_Crossing(t *testing.T) {
+	driver := dir{}
+	vol := Volume{driver: &driver, volType: VolumeTypeCustom, contentType: ContentTypeFS, config: map[string]string{"size": "100MiB", "size.soft_limit": "50%"}}
+
+	limit, err := vol.ConfigSizeSoftLimit()
+	assert.NoError(t, err)
+
+	for _, test := range []struct {
+		used     int64
+		exceeded bool
+	}{
+		{used: 0, exceeded: false},
+		{used: 50*1024*1024 - 1, exceeded: false},
+		{used: 50 * 1024 * 1024, exceeded: true},
+		{used: 99 * 1024 * 1024, exceeded: true},
+	} {
+		assert.Equal(t, test.exceeded, VolumeSoftLimitExceeded(test.used, limit))
+	}
+}
@@ -59,6 +59,8 @@ type Pool interface {
 	Unmount() (bool, error)
 
 	ApplyPatch(name string) error
+	Scrub(op *operations.Operation) error
+	ScrubStatus() (*api.StoragePoolScrub, error)
 
 	GetVolume(volumeType drivers.VolumeType, contentType drivers.ContentType, name string, config map[string]string) drivers.Volume
 
@@ -121,6 +123,7 @@ type Pool interface {
 	CreateCustomVolumeFromCopy(projectName string, srcProjectName string, volName, desc string, config map[string]string, srcPoolName, srcVolName string, snapshots bool, op *operations.Operation) error
 	UpdateCustomVolume(projectName string, volName string, newDesc string, newConfig map[string]string, op *operations.Operation) error
 	RenameCustomVolume(projectName string, volName string, newVolName string, op *operations.Operation) error
+	ConvertCustomVolume(projectName string, volName string, contentType drivers.ContentType, op *operations.Operation) error
 	DeleteCustomVolume(projectName string, volName string, op *operations.Operation) error
 	GetCustomVolumeDisk(projectName string, volName string) (string, error)
 	GetCustomVolumeUsage(projectName string, volName string) (*VolumeUsage, error)
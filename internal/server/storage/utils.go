@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
@@ -35,6 +36,7 @@ import (
 	"github.com/lxc/incus/v6/shared/archive"
 	"github.com/lxc/incus/v6/shared/ioprogress"
 	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/units"
 	"github.com/lxc/incus/v6/shared/util"
 	"github.com/lxc/incus/v6/shared/validate"
 )
@@ -223,6 +225,168 @@ func VolumeDBGet(pool Pool, projectName string, volumeName string, volumeType dr
 	return dbVolume, nil
 }
 
+// volumeDefaultKeys are the volume config keys which can also be defaulted at the project and server level.
+var volumeDefaultKeys = []string{"block.filesystem", "block.mount_options", "size", "zfs.blocksize"}
+
+// volumeDefaults is a set of inherited volume defaults along with where they came from.
+type volumeDefaults struct {
+	source string
+	config map[string]string
+}
+
+// volumeFillInheritedConfig fills the config of a new custom volume with the project and server level defaults
+// for the keys set neither on the volume nor on the pool. The effective order is volume > pool > project > server,
+// so this must be called before the driver fills in its own defaults.
+// It returns where each of the inherited keys came from.
+func volumeFillInheritedConfig(pool *backend, projectName string, vol drivers.Volume) (map[string]string, error) {
+	poolConfig := pool.Driver().Config()
+
+	// Get the project level defaults.
+	var projectConfig map[string]string
+	err := pool.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		p, err := dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		projectConfig = p.Config
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading project %q: %w", projectName, err)
+	}
+
+	defaults := []volumeDefaults{
+		{source: "project", config: map[string]string{}},
+		{source: "server", config: map[string]string{}},
+	}
+
+	for _, key := range volumeDefaultKeys {
+		defaults[0].config[key] = projectConfig["storage.volume."+key]
+	}
+
+	if pool.state.GlobalConfig != nil {
+		defaults[1].config = pool.state.GlobalConfig.StorageVolumeDefaults()
+	}
+
+	// Whether the volume is block backed may depend on the pool's volume defaults (e.g. zfs.block_mode).
+	probeVol := vol.Clone()
+	err = pool.Driver().FillVolumeConfig(probeVol)
+	if err != nil {
+		return nil, err
+	}
+
+	blockFS := probeVol.IsBlockBacked() && vol.ContentType() == drivers.ContentTypeFS
+
+	check := func(key string, value string) (bool, error) {
+		testConfig := maps.Clone(poolConfig)
+		if testConfig == nil {
+			testConfig = map[string]string{}
+		}
+
+		// Skip keys the pool's driver doesn't support.
+		testConfig["volume."+key] = ""
+		err := pool.Driver().Validate(testConfig)
+		if err != nil {
+			return false, nil
+		}
+
+		// Check the inherited value is valid for the pool's driver.
+		testConfig["volume."+key] = value
+		err = pool.Driver().Validate(testConfig)
+		if err != nil {
+			return false, fmt.Errorf("Invalid default for %q on pool %q: %w", key, pool.Name(), err)
+		}
+
+		return true, nil
+	}
+
+	return volumeApplyDefaults(vol.Config(), poolConfig, blockFS, defaults, check)
+}
+
+// volumeApplyDefaults sets each of volumeDefaultKeys set neither in config nor in the pool's volume.* config to the
+// first non-empty value found in defaults. The block settings are only applied when blockFS is true.
+// The check function reports whether the pool supports a key and whether the value is valid for it.
+// It returns where each of the applied keys came from.
+func volumeApplyDefaults(config map[string]string, poolConfig map[string]string, blockFS bool, defaults []volumeDefaults, check func(key string, value string) (bool, error)) (map[string]string, error) {
+	sources := map[string]string{}
+
+	for _, key := range volumeDefaultKeys {
+		// Volume and pool settings take precedence.
+		if config[key] != "" || poolConfig["volume."+key] != "" {
+			continue
+		}
+
+		// The block settings only apply to filesystem volumes backed by a block device.
+		if strings.HasPrefix(key, "block.") && !blockFS {
+			continue
+		}
+
+		for _, entry := range defaults {
+			value := entry.config[key]
+			if value == "" {
+				continue
+			}
+
+			supported, err := check(key, value)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid %s default: %w", entry.source, err)
+			}
+
+			if supported {
+				config[key] = value
+				sources[key] = entry.source
+			}
+
+			break
+		}
+	}
+
+	return sources, nil
+}
+
+// volumeConfigSources returns the volatile.config_source value of a new custom volume, once the driver defaults
+// have been filled. The keys in inherited came from the project or server defaults, those in volumeKeys were set
+// on the volume itself and the others came from the pool when it has a matching volume.* key.
+// Values filled in by the driver alone aren't recorded.
+func volumeConfigSources(config map[string]string, poolConfig map[string]string, volumeKeys []string, inherited map[string]string) string {
+	sources := maps.Clone(inherited)
+	if sources == nil {
+		sources = map[string]string{}
+	}
+
+	for k := range config {
+		_, found := sources[k]
+		if found || slices.Contains(volumeKeys, k) {
+			continue
+		}
+
+		if poolConfig["volume."+k] != "" {
+			sources[k] = "pool"
+		}
+	}
+
+	keys := make([]string, 0, len(sources))
+	for k := range sources {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	entries := make([]string, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, k+"="+sources[k])
+	}
+
+	return strings.Join(entries, ",")
+}
+
 // VolumeDBCreate creates a volume in the database.
 // If volumeConfig is supplied, it is modified with any driver level default config options (if not set).
 // If removeUnknownKeys is true, any unknown config keys are removed from volumeConfig rather than failing.
@@ -259,29 +423,12 @@ func VolumeDBCreate(pool Pool, projectName string, volumeName string, volumeDesc
 		return err
 	}
 
-	// Make sure that we don't pass a nil to the next function.
-	if volumeConfig == nil {
-		volumeConfig = map[string]string{}
-	}
-
 	volType, err := VolumeDBTypeToType(volDBType)
 	if err != nil {
 		return err
 	}
 
-	vol := drivers.NewVolume(pool.Driver(), pool.Name(), volType, contentType, volumeName, volumeConfig, pool.Driver().Config())
-
-	// Set source indicator.
-	vol.SetHasSource(hasSource)
-
-	// Fill default config.
-	err = pool.Driver().FillVolumeConfig(vol)
-	if err != nil {
-		return err
-	}
-
-	// Validate config.
-	err = pool.Driver().ValidateVolume(vol, removeUnknownKeys)
+	vol, err := volumeDBValidate(p, projectName, volumeName, volType, snapshot, volumeConfig, contentType, removeUnknownKeys, hasSource)
 	if err != nil {
 		return err
 	}
@@ -303,6 +450,73 @@ func VolumeDBCreate(pool Pool, projectName string, volumeName string, volumeDesc
 	return nil
 }
 
+// volumeDBValidate fills the default config of a volume about to be recorded in the database and validates it.
+func volumeDBValidate(p *backend, projectName string, volumeName string, volType drivers.VolumeType, snapshot bool, volumeConfig map[string]string, contentType drivers.ContentType, removeUnknownKeys bool, hasSource bool) (drivers.Volume, error) {
+	// Make sure that we don't pass a nil to the next function.
+	if volumeConfig == nil {
+		volumeConfig = map[string]string{}
+	}
+
+	vol := drivers.NewVolume(p.Driver(), p.Name(), volType, contentType, volumeName, volumeConfig, p.Driver().Config())
+
+	// Set source indicator.
+	vol.SetHasSource(hasSource)
+
+	// Keep track of the keys set on the volume itself.
+	volumeKeys := make([]string, 0, len(volumeConfig))
+	for k := range volumeConfig {
+		volumeKeys = append(volumeKeys, k)
+	}
+
+	// Fill the project and server level defaults for new custom volumes.
+	var inherited map[string]string
+	trackSources := volType == drivers.VolumeTypeCustom && !snapshot && !hasSource
+	if trackSources {
+		// Never carry over a stale record of the config sources.
+		volumeKeys = slices.DeleteFunc(volumeKeys, func(k string) bool { return k == "volatile.config_source" })
+		delete(vol.Config(), "volatile.config_source")
+
+		var err error
+		inherited, err = volumeFillInheritedConfig(p, projectName, vol)
+		if err != nil {
+			return drivers.Volume{}, err
+		}
+	}
+
+	// Fill default config.
+	err := p.Driver().FillVolumeConfig(vol)
+	if err != nil {
+		return drivers.Volume{}, err
+	}
+
+	if trackSources {
+		sources := volumeConfigSources(vol.Config(), p.Driver().Config(), volumeKeys, inherited)
+		if sources != "" {
+			vol.Config()["volatile.config_source"] = sources
+		}
+	}
+
+	// Validate config.
+	err = p.Driver().ValidateVolume(vol, removeUnknownKeys)
+	if err != nil {
+		return drivers.Volume{}, err
+	}
+
+	return vol, nil
+}
+
+// VolumeValidate validates the config of a custom volume the same way as on creation, without creating it.
+func VolumeValidate(pool Pool, projectName string, volumeName string, contentType drivers.ContentType, volumeConfig map[string]string) error {
+	p, ok := pool.(*backend)
+	if !ok {
+		return fmt.Errorf("Pool is not a backend")
+	}
+
+	_, err := volumeDBValidate(p, projectName, volumeName, drivers.VolumeTypeCustom, false, maps.Clone(volumeConfig), contentType, false, false)
+
+	return err
+}
+
 // VolumeDBDelete deletes a volume from the database.
 func VolumeDBDelete(pool Pool, projectName string, volumeName string, volumeType drivers.VolumeType) error {
 	p, ok := pool.(*backend)
@@ -547,6 +761,23 @@ func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error
 		rules["block.filesystem"] = validate.IsAny
 	}
 
+	// volatile.config_source records where the defaults of custom volumes came from.
+	if vol.Type() == drivers.VolumeTypeCustom {
+		rules["volatile.config_source"] = validate.IsAny
+	}
+
+	// size.soft_limit raises a warning when the usage of a custom volume goes above it.
+	if vol.Type() == drivers.VolumeTypeCustom {
+		rules["size.soft_limit"] = func(value string) error {
+			if value == "" {
+				return nil
+			}
+
+			_, err := vol.ConfigSizeSoftLimit()
+			return err
+		}
+	}
+
 	// volatile.rootfs.size is only used for image volumes.
 	if vol.Type() == drivers.VolumeTypeImage {
 		rules["volatile.rootfs.size"] = validate.Optional(validate.IsInt64)
@@ -975,6 +1206,98 @@ func VolumeUsedByInstanceDevices(s *state.State, poolName string, projectName st
 	})
 }
 
+// VolumeUsedByInstanceSnapshotDevices finds instance snapshots with disk devices referencing the specified volume,
+// which would be attached to the instance again when restoring the snapshot. It calls snapshotFunc for each of them.
+func VolumeUsedByInstanceSnapshotDevices(s *state.State, poolName string, projectName string, vol *api.StorageVolume, snapshotFunc func(snap cluster.InstanceSnapshot, usedByDevices []string) error) error {
+	// Convert the volume type name to our internal integer representation.
+	volumeType, err := VolumeTypeNameToDBType(vol.Type)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		diskType := cluster.TypeDisk
+		snapshotDevices, err := cluster.GetDevices(ctx, tx.Tx(), "instances_snapshots", "instance_snapshot", cluster.DeviceFilter{Type: &diskType})
+		if err != nil {
+			return fmt.Errorf("Failed loading instance snapshot devices: %w", err)
+		}
+
+		// Match the volume name against the "source" property of the disks in the same pool as the volume.
+		usedByDevices := map[int][]string{}
+		for snapshotID, devices := range snapshotDevices {
+			for _, dev := range devices {
+				if dev.Config["pool"] != poolName {
+					continue
+				}
+
+				if strings.Split(dev.Config["source"], "/")[0] == vol.Name {
+					usedByDevices[snapshotID] = append(usedByDevices[snapshotID], dev.Name)
+				}
+			}
+		}
+
+		if len(usedByDevices) == 0 {
+			return nil
+		}
+
+		snapshots, err := cluster.GetInstanceSnapshots(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading instance snapshots: %w", err)
+		}
+
+		instances, err := cluster.GetInstances(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading instances: %w", err)
+		}
+
+		instanceNodes := make(map[string]string, len(instances))
+		for _, inst := range instances {
+			instanceNodes[inst.Project+"/"+inst.Name] = inst.Node
+		}
+
+		projects, err := cluster.GetProjects(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading projects: %w", err)
+		}
+
+		projectsByName := make(map[string]*api.Project, len(projects))
+		for _, dbProject := range projects {
+			p, err := dbProject.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			projectsByName[p.Name] = p
+		}
+
+		for _, snap := range snapshots {
+			devices, ok := usedByDevices[snap.ID]
+			if !ok {
+				continue
+			}
+
+			// If the volume has a specific cluster member which is different than the instance then skip as
+			// the snapshot cannot be using this volume.
+			if vol.Location != "" && instanceNodes[snap.Project+"/"+snap.Instance] != vol.Location {
+				continue
+			}
+
+			// Check the snapshot's storage project is the same as the volume's project.
+			p, ok := projectsByName[snap.Project]
+			if !ok || project.StorageVolumeProjectFromRecord(p, volumeType) != projectName {
+				continue
+			}
+
+			err = snapshotFunc(snap, devices)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 // VolumeUsedByExclusiveRemoteInstancesWithProfiles checks if custom volume is exclusively attached to a remote
 // instance. Returns the remote instance that has the volume exclusively attached. Returns nil if volume available.
 func VolumeUsedByExclusiveRemoteInstancesWithProfiles(s *state.State, poolName string, projectName string, vol *api.StorageVolume) (*db.InstanceArgs, error) {
@@ -1220,3 +1543,45 @@ func VolumeSnapshotsToMigrationSnapshots(snapshots []*api.StorageVolumeSnapshot,
 
 	return migrationSnapshots, nil
 }
+
+// swapVolume replaces the volume volName with the volume newVolName, moving the former aside as oldVolName.
+// If the new volume can't take its place, the original volume is moved back.
+func swapVolume(rename func(oldName string, newName string) error, volName string, newVolName string, oldVolName string) error {
+	err := rename(volName, oldVolName)
+	if err != nil {
+		return err
+	}
+
+	err = rename(newVolName, volName)
+	if err != nil {
+		_ = rename(oldVolName, volName)
+		return err
+	}
+
+	return nil
+}
+
+// poolResizeValidate checks that a pool can be resized from oldSize to newSize.
+// Pools can't be shrunk, when attempted the pool usage is used to report whether the data would still fit.
+func poolResizeValidate(oldSize string, newSize string, usedBytes func() (uint64, error)) error {
+	oldSizeBytes, err := units.ParseByteSizeString(oldSize)
+	if err != nil {
+		return fmt.Errorf("Invalid current pool size %q: %w", oldSize, err)
+	}
+
+	newSizeBytes, err := units.ParseByteSizeString(newSize)
+	if err != nil {
+		return fmt.Errorf("Invalid pool size %q: %w", newSize, err)
+	}
+
+	if newSizeBytes >= oldSizeBytes {
+		return nil
+	}
+
+	used, err := usedBytes()
+	if err == nil && uint64(newSizeBytes) < used {
+		return fmt.Errorf("Pool cannot be resized below its used capacity of %s", units.GetByteSizeStringIEC(int64(used), 2))
+	}
+
+	return fmt.Errorf("Pool cannot be shrunk")
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_volumeApplyDefaults(t *testing.T) {
+	defaults := []volumeDefaults{
+		{source: "project", config: map[string]string{"size": "20GiB"}},
+		{source: "server", config: map[string]string{"size": "10GiB", "block.filesystem": "xfs", "block.mount_options": "noatime", "zfs.blocksize": "32KiB"}},
+	}
+
+	supportAll := func(key string, value string) (bool, error) { return true, nil }
+
+	tests := []struct {
+		name       string
+		config     map[string]string
+		poolConfig map[string]string
+		blockFS    bool
+		check      func(key string, value string) (bool, error)
+		expConfig  map[string]string
+		expSources map[string]string
+		expErr     string
+	}{
+		{
+			name:       "Project before server",
+			config:     map[string]string{},
+			blockFS:    true,
+			check:      supportAll,
+			expConfig:  map[string]string{"size": "20GiB", "block.filesystem": "xfs", "block.mount_options": "noatime", "zfs.blocksize": "32KiB"},
+			expSources: map[string]string{"size": "project", "block.filesystem": "server", "block.mount_options": "server", "zfs.blocksize": "server"},
+		},
+		{
+			name:       "Volume and pool take precedence",
+			config:     map[string]string{"size": "5GiB"},
+			poolConfig: map[string]string{"volume.block.filesystem": "ext4"},
+			blockFS:    true,
+			check:      supportAll,
+			expConfig:  map[string]string{"size": "5GiB", "block.mount_options": "noatime", "zfs.blocksize": "32KiB"},
+			expSources: map[string]string{"block.mount_options": "server", "zfs.blocksize": "server"},
+		},
+		{
+			name:       "Block settings need a block backed filesystem",
+			config:     map[string]string{},
+			check:      supportAll,
+			expConfig:  map[string]string{"size": "20GiB", "zfs.blocksize": "32KiB"},
+			expSources: map[string]string{"size": "project", "zfs.blocksize": "server"},
+		},
+		{
+			name:   "Unsupported keys are skipped",
+			config: map[string]string{},
+			check: func(key string, value string) (bool, error) {
+				return key != "zfs.blocksize", nil
+			},
+			expConfig:  map[string]string{"size": "20GiB"},
+			expSources: map[string]string{"size": "project"},
+		},
+		{
+			name:   "Invalid value",
+			config: map[string]string{},
+			check: func(key string, value string) (bool, error) {
+				return true, errors.New("Bad value")
+			},
+			expErr: "Invalid project default: Bad value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources, err := volumeApplyDefaults(tt.config, tt.poolConfig, tt.blockFS, defaults, tt.check)
+			if tt.expErr != "" {
+				assert.EqualError(t, err, tt.expErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expConfig, tt.config)
+			assert.Equal(t, tt.expSources, sources)
+		})
+	}
+}
+
+func Test_volumeConfigSources(t *testing.T) {
+	// Config after the driver filled in its defaults: "block.filesystem" comes from the driver alone.
+	config := map[string]string{
+		"size":                "20GiB",
+		"block.filesystem":    "ext4",
+		"block.mount_options": "discard",
+		"zfs.blocksize":       "32KiB",
+		"snapshots.expiry":    "1d",
+	}
+
+	poolConfig := map[string]string{
+		"volume.block.mount_options": "discard",
+		"volume.snapshots.expiry":    "1w",
+	}
+
+	sources := volumeConfigSources(config, poolConfig, []string{"snapshots.expiry"}, map[string]string{"size": "project", "zfs.blocksize": "server"})
+	assert.Equal(t, "block.mount_options=pool,size=project,zfs.blocksize=server", sources)
+
+	assert.Equal(t, "", volumeConfigSources(map[string]string{"size": "1GiB"}, nil, []string{"size"}, nil))
+}
+
+func Test_swapVolume(t *testing.T) {
+	tests := []struct {
+		name        string
+		failRenames map[string]bool
+		err         string
+		volumes     []string
+	}{
+		{
+			name:    "Swapped",
+			volumes: []string{"foo", "foo.convert-old"},
+		},
+		{
+			name:        "Moving aside fails",
+			failRenames: map[string]bool{"foo.convert-old": true},
+			err:         `Failed renaming to "foo.convert-old"`,
+			volumes:     []string{"foo", "foo.convert"},
+		},
+		{
+			name:        "Replacing fails",
+			failRenames: map[string]bool{"foo.convert->foo": true},
+			err:         `Failed renaming to "foo"`,
+			volumes:     []string{"foo", "foo.convert"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map the volume names to their content.
+			volumes := map[string]string{"foo": "original", "foo.convert": "converted"}
+
+			rename := func(oldName string, newName string) error {
+				if tt.failRenames[newName] || tt.failRenames[oldName+"->"+newName] {
+					return fmt.Errorf("Failed renaming to %q", newName)
+				}
+
+				volumes[newName] = volumes[oldName]
+				delete(volumes, oldName)
+				return nil
+			}
+
+			err := swapVolume(rename, "foo", "foo.convert", "foo.convert-old")
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.Equal(t, "original", volumes["foo"])
+				assert.Equal(t, "converted", volumes["foo.convert"])
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "converted", volumes["foo"])
+				assert.Equal(t, "original", volumes["foo.convert-old"])
+			}
+
+			assert.ElementsMatch(t, tt.volumes, slices.Collect(maps.Keys(volumes)))
+		})
+	}
+}
+
+func Test_poolResizeValidate(t *testing.T) {
+	usedBytes := func() (uint64, error) { return 3 * 1024 * 1024 * 1024, nil }
+	usageFailed := func() (uint64, error) { return 0, errors.New("Pool not mounted") }
+
+	tests := []struct {
+		name      string
+		oldSize   string
+		newSize   string
+		usedBytes func() (uint64, error)
+		err       string
+	}{
+		{name: "Grow", oldSize: "10GiB", newSize: "20GiB", usedBytes: usedBytes},
+		{name: "Same size", oldSize: "10GiB", newSize: "10GiB", usedBytes: usedBytes},
+		{name: "Size set on a pool without one", oldSize: "", newSize: "20GiB", usedBytes: usedBytes},
+		{name: "Shrink above used capacity", oldSize: "10GiB", newSize: "5GiB", usedBytes: usedBytes, err: "Pool cannot be shrunk"},
+		{name: "Shrink below used capacity", oldSize: "10GiB", newSize: "2GiB", usedBytes: usedBytes, err: "Pool cannot be resized below its used capacity of 3.00GiB"},
+		{name: "Shrink with unknown usage", oldSize: "10GiB", newSize: "2GiB", usedBytes: usageFailed, err: "Pool cannot be shrunk"},
+		{name: "Size unset", oldSize: "10GiB", newSize: "", usedBytes: usedBytes, err: "Pool cannot be resized below its used capacity of 3.00GiB"},
+		{name: "Invalid size", oldSize: "10GiB", newSize: "foo", usedBytes: usedBytes, err: `Invalid pool size "foo": Invalid value: foo`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := poolResizeValidate(tt.oldSize, tt.newSize, tt.usedBytes)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
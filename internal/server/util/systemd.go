@@ -0,0 +1,29 @@
+package util
+
+import (
+	"net"
+	"os"
+)
+
+// SystemdNotify sends a state update (such as "EXTEND_TIMEOUT_USEC=...") to the service manager.
+// It does nothing when not running as a systemd service with notification access.
+func SystemdNotify(state string) error {
+	socketPath := os.Getenv("NOTIFY_SOCKET")
+	if socketPath == "" {
+		return nil
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write([]byte(state))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
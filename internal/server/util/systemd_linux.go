@@ -0,0 +1,125 @@
+//go:build linux
+
+package util
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// SystemdShutdownInhibitor is a systemd "delay" inhibitor lock on system shutdown.
+type SystemdShutdownInhibitor struct {
+	mu       sync.Mutex
+	released bool
+	lock     *exec.Cmd
+	monitor  *exec.Cmd
+}
+
+// SystemdInhibitShutdown takes a systemd "delay" inhibitor lock on system shutdown and watches logind for the
+// PrepareForShutdown signal. When a shutdown or reboot starts, onShutdown is called with a function releasing the
+// lock, which is also released once onShutdown returns, letting the shutdown proceed.
+// Nothing is held when systemd-inhibit or busctl aren't available.
+func SystemdInhibitShutdown(why string, onShutdown func(release func())) (*SystemdShutdownInhibitor, error) {
+	inhibitor := &SystemdShutdownInhibitor{}
+
+	_, err := exec.LookPath("systemd-inhibit")
+	if err != nil {
+		inhibitor.released = true
+		return inhibitor, nil
+	}
+
+	_, err = exec.LookPath("busctl")
+	if err != nil {
+		inhibitor.released = true
+		return inhibitor, nil
+	}
+
+	// Watch logind before taking the lock so that no shutdown goes unnoticed while it's held.
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	inhibitor.monitor = exec.Command("busctl", "monitor", "--system", "--json=short", "--match", "type='signal',sender='org.freedesktop.login1',interface='org.freedesktop.login1.Manager',member='PrepareForShutdown'")
+	inhibitor.monitor.Stdout = writer
+	inhibitor.monitor.SysProcAttr = &unix.SysProcAttr{Setpgid: true}
+
+	err = inhibitor.monitor.Start()
+	_ = writer.Close()
+	if err != nil {
+		_ = reader.Close()
+		return nil, err
+	}
+
+	inhibitor.lock = exec.Command("systemd-inhibit", "--what=shutdown", "--who=Incus", "--why="+why, "--mode=delay", "sleep", "infinity")
+	inhibitor.lock.SysProcAttr = &unix.SysProcAttr{Setpgid: true}
+
+	err = inhibitor.lock.Start()
+	if err != nil {
+		stopProcessGroup(inhibitor.monitor)
+		_ = reader.Close()
+		return nil, err
+	}
+
+	go func() {
+		defer func() { _ = reader.Close() }()
+
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			var msg struct {
+				Member  string `json:"member"`
+				Payload struct {
+					Data []any `json:"data"`
+				} `json:"payload"`
+			}
+
+			err := json.Unmarshal(scanner.Bytes(), &msg)
+			if err != nil || msg.Member != "PrepareForShutdown" {
+				continue
+			}
+
+			// The signal is also sent with false when a shutdown is cancelled.
+			if len(msg.Payload.Data) != 1 || msg.Payload.Data[0] != true {
+				continue
+			}
+
+			inhibitor.mu.Lock()
+			released := inhibitor.released
+			inhibitor.mu.Unlock()
+
+			if !released {
+				onShutdown(inhibitor.Release)
+				inhibitor.Release()
+			}
+
+			return
+		}
+	}()
+
+	return inhibitor, nil
+}
+
+// Release releases the inhibitor lock and stops watching for shutdowns. It can safely be called more than once.
+func (i *SystemdShutdownInhibitor) Release() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.released {
+		return
+	}
+
+	i.released = true
+	stopProcessGroup(i.lock)
+	stopProcessGroup(i.monitor)
+}
+
+// stopProcessGroup kills the process group led by the command and waits for it to exit.
+func stopProcessGroup(cmd *exec.Cmd) {
+	_ = unix.Kill(-cmd.Process.Pid, unix.SIGKILL)
+	_ = cmd.Wait()
+}
@@ -0,0 +1,146 @@
+//go:build linux
+
+package util_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/internal/server/util"
+)
+
+// setupFakeInhibit puts fake systemd-inhibit and busctl commands on PATH. The fake systemd-inhibit records its
+// arguments and PID in the returned directory, and the fake busctl reports a shutdown once the "shutdown" file
+// appears there.
+func setupFakeInhibit(t *testing.T) string {
+	dir := t.TempDir()
+
+	inhibit := `#!/bin/sh
+echo "$@" > ` + filepath.Join(dir, "args") + `
+echo $$ > ` + filepath.Join(dir, "pid.tmp") + `
+mv ` + filepath.Join(dir, "pid.tmp") + " " + filepath.Join(dir, "pid") + `
+while [ "$1" != "sleep" ]; do shift; done
+exec "$@"
+`
+
+	busctl := `#!/bin/sh
+echo '{"type":"signal","member":"PrepareForShutdown","payload":{"type":"b","data":[false]}}'
+while [ ! -e ` + filepath.Join(dir, "shutdown") + ` ]; do sleep 0.05; done
+echo '{"type":"signal","member":"PrepareForShutdown","payload":{"type":"b","data":[true]}}'
+exec sleep infinity
+`
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "systemd-inhibit"), []byte(inhibit), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "busctl"), []byte(busctl), 0o755))
+	t.Setenv("PATH", dir+":"+os.Getenv("PATH"))
+
+	return dir
+}
+
+// lockPID waits for the fake systemd-inhibit to start and returns its PID.
+func lockPID(t *testing.T, dir string) int {
+	var content []byte
+	require.Eventually(t, func() bool {
+		var err error
+		content, err = os.ReadFile(filepath.Join(dir, "pid"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	require.NoError(t, err)
+
+	return pid
+}
+
+func processRunning(pid int) bool {
+	return unix.Kill(pid, 0) == nil
+}
+
+func TestSystemdInhibitShutdown(t *testing.T) {
+	dir := setupFakeInhibit(t)
+
+	called := make(chan struct{})
+	finish := make(chan struct{})
+	inhibitor, err := util.SystemdInhibitShutdown("Stopping instances", func(release func()) {
+		close(called)
+		<-finish
+	})
+	require.NoError(t, err)
+
+	defer inhibitor.Release()
+
+	pid := lockPID(t, dir)
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	require.NoError(t, err)
+	require.Equal(t, "--what=shutdown --who=Incus --why=Stopping instances --mode=delay sleep infinity\n", string(args))
+
+	// A cancelled shutdown doesn't call onShutdown.
+	select {
+	case <-called:
+		t.Fatal("onShutdown called without a shutdown")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// The lock is held while onShutdown runs and released once it returns.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shutdown"), nil, 0o644))
+
+	select {
+	case <-called:
+	case <-time.After(5 * time.Second):
+		t.Fatal("onShutdown not called on shutdown")
+	}
+
+	require.True(t, processRunning(pid))
+	close(finish)
+	require.Eventually(t, func() bool { return !processRunning(pid) }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSystemdInhibitShutdownRelease(t *testing.T) {
+	dir := setupFakeInhibit(t)
+
+	finish := make(chan struct{})
+	defer close(finish)
+
+	// Releasing from onShutdown (such as when the deadline expires) lets the shutdown proceed before it returns.
+	inhibitor, err := util.SystemdInhibitShutdown("Stopping instances", func(release func()) {
+		release()
+		<-finish
+	})
+	require.NoError(t, err)
+
+	pid := lockPID(t, dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shutdown"), nil, 0o644))
+	require.Eventually(t, func() bool { return !processRunning(pid) }, 5*time.Second, 10*time.Millisecond)
+
+	// Releasing again is a no-op.
+	inhibitor.Release()
+
+	// Releasing before any shutdown stops watching for it.
+	dir = setupFakeInhibit(t)
+	inhibitor, err = util.SystemdInhibitShutdown("Stopping instances", func(release func()) {
+		t.Error("onShutdown called after release")
+	})
+	require.NoError(t, err)
+
+	pid = lockPID(t, dir)
+	inhibitor.Release()
+	require.False(t, processRunning(pid))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shutdown"), nil, 0o644))
+	time.Sleep(200 * time.Millisecond)
+}
+
+func TestSystemdInhibitShutdownMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	inhibitor, err := util.SystemdInhibitShutdown("Stopping instances", func(release func()) {})
+	require.NoError(t, err)
+	inhibitor.Release()
+}
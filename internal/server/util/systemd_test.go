@@ -0,0 +1,31 @@
+package util_test
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v6/internal/server/util"
+)
+
+func TestSystemdNotify(t *testing.T) {
+	// Nothing is sent outside of systemd.
+	t.Setenv("NOTIFY_SOCKET", "")
+	require.NoError(t, util.SystemdNotify("READY=1"))
+
+	socketPath := filepath.Join(t.TempDir(), "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	require.NoError(t, err)
+
+	defer func() { _ = conn.Close() }()
+
+	t.Setenv("NOTIFY_SOCKET", socketPath)
+	require.NoError(t, util.SystemdNotify("EXTEND_TIMEOUT_USEC=1000000"))
+
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "EXTEND_TIMEOUT_USEC=1000000", string(buf[:n]))
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// ImageRefreshChanges represents what refreshing an image from its source would change.
+type ImageRefreshChanges struct {
+	// Fingerprint of the current image.
+	OldFingerprint string
+
+	// Fingerprint of the latest image available from the source.
+	NewFingerprint string
+
+	// Difference in size between the latest and the current image.
+	SizeDelta int64
+
+	// Properties which differ between the current and the latest image, mapped to their old and new values.
+	Properties map[string][2]string
+}
+
+// UpdateAvailable returns whether the source has a different image than the current one.
+func (c *ImageRefreshChanges) UpdateAvailable() bool {
+	return c.OldFingerprint != c.NewFingerprint
+}
+
+// ImageRefreshDiff compares the given image with the latest one available from its source.
+func ImageRefreshDiff(current *api.Image, latest *api.Image) *ImageRefreshChanges {
+	changes := &ImageRefreshChanges{
+		OldFingerprint: current.Fingerprint,
+		NewFingerprint: latest.Fingerprint,
+		SizeDelta:      latest.Size - current.Size,
+		Properties:     map[string][2]string{},
+	}
+
+	for key, value := range current.Properties {
+		if latest.Properties[key] != value {
+			changes.Properties[key] = [2]string{value, latest.Properties[key]}
+		}
+	}
+
+	for key, value := range latest.Properties {
+		_, found := current.Properties[key]
+		if !found {
+			changes.Properties[key] = [2]string{"", value}
+		}
+	}
+
+	return changes
+}
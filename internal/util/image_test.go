@@ -0,0 +1,44 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestImageRefreshDiff(t *testing.T) {
+	current := &api.Image{
+		Fingerprint: "aaaa",
+		Size:        100,
+		ImagePut: api.ImagePut{
+			Properties: map[string]string{"os": "Debian", "serial": "20250101", "variant": "default"},
+		},
+	}
+
+	latest := &api.Image{
+		Fingerprint: "bbbb",
+		Size:        120,
+		ImagePut: api.ImagePut{
+			Properties: map[string]string{"os": "Debian", "serial": "20250201", "release": "trixie"},
+		},
+	}
+
+	changes := internalUtil.ImageRefreshDiff(current, latest)
+	assert.True(t, changes.UpdateAvailable())
+	assert.Equal(t, "aaaa", changes.OldFingerprint)
+	assert.Equal(t, "bbbb", changes.NewFingerprint)
+	assert.Equal(t, int64(20), changes.SizeDelta)
+	assert.Equal(t, map[string][2]string{
+		"serial":  {"20250101", "20250201"},
+		"variant": {"default", ""},
+		"release": {"", "trixie"},
+	}, changes.Properties)
+
+	// Same image.
+	changes = internalUtil.ImageRefreshDiff(current, current)
+	assert.False(t, changes.UpdateAvailable())
+	assert.Empty(t, changes.Properties)
+}
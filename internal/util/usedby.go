@@ -0,0 +1,55 @@
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// UsedByDescription returns a human readable description of an entry of a "used_by" list,
+// for example `instance "c1" (project "foo")`. Entries which aren't recognized are returned as is.
+func UsedByDescription(entry string) string {
+	u, err := url.Parse(entry)
+	if err != nil {
+		return entry
+	}
+
+	path, ok := strings.CutPrefix(u.EscapedPath(), "/1.0")
+	if !ok {
+		return entry
+	}
+
+	var description string
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		parts[i], err = url.PathUnescape(part)
+		if err != nil {
+			return entry
+		}
+	}
+
+	switch {
+	case path == "" || path == "/":
+		return "server"
+	case len(parts) == 2 && parts[0] == "instances":
+		description = fmt.Sprintf("instance %q", parts[1])
+	case len(parts) == 4 && parts[0] == "instances" && parts[2] == "snapshots":
+		description = fmt.Sprintf("instance snapshot %q", parts[1]+"/"+parts[3])
+	case len(parts) == 2 && parts[0] == "profiles":
+		description = fmt.Sprintf("profile %q", parts[1])
+	case len(parts) == 2 && parts[0] == "images":
+		description = fmt.Sprintf("image %q", parts[1])
+	case len(parts) == 2 && parts[0] == "networks":
+		description = fmt.Sprintf("network %q", parts[1])
+	default:
+		return entry
+	}
+
+	project := u.Query().Get("project")
+	if project != "" && project != "default" {
+		description += fmt.Sprintf(" (project %q)", project)
+	}
+
+	return description
+}
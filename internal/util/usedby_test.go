@@ -0,0 +1,29 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+)
+
+func TestUsedByDescription(t *testing.T) {
+	cases := map[string]string{
+		"/1.0":                               "server",
+		"/1.0/instances/c1":                  `instance "c1"`,
+		"/1.0/instances/c1?project=default":  `instance "c1"`,
+		"/1.0/instances/c1?project=foo":      `instance "c1" (project "foo")`,
+		"/1.0/instances/c1/snapshots/snap0":  `instance snapshot "c1/snap0"`,
+		"/1.0/profiles/default?project=foo":  `profile "default" (project "foo")`,
+		"/1.0/images/abcdef?target=member1":  `image "abcdef"`,
+		"/1.0/networks/incusbr0":             `network "incusbr0"`,
+		"/1.0/instances/c%2F1":               `instance "c/1"`,
+		"/1.0/storage-pools/default/volumes": "/1.0/storage-pools/default/volumes",
+		"/2.0/instances/c1":                  "/2.0/instances/c1",
+	}
+
+	for entry, expected := range cases {
+		assert.Equal(t, expected, internalUtil.UsedByDescription(entry), entry)
+	}
+}
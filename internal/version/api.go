@@ -483,6 +483,84 @@ var APIExtensions = []string{
 	"instance_nic_routed_host_tables",
 	"instance_publish_split",
 	"init_preseed_certificates",
+	"instance_syscalls_profile",
+	"instance_labels",
+	"cluster_member_device_availability",
+	"infiniband_pkey",
+	"infiniband_bond_parent",
+	"instance_snapshot_hooks",
+	"network_conntrack",
+	"cluster_evacuation_progress",
+	"disk_discard",
+	"profile_assign_bulk",
+	"instance_state_clock",
+	"network_forward_port_ranges",
+	"instance_console_log_rotation",
+	"projects_network_hwaddr_range",
+	"disk_volume_mount_options",
+	"server_api_rate_limit",
+	"instance_limits_nested",
+	"operation_cancel_rollback",
+	"network_acl_stateless_validation",
+	"instance_volatile_guard",
+	"instance_console_forward",
+	"cluster_database_status",
+	"instances_autostart_concurrency",
+	"instance_cpu_model",
+	"instance_last_started",
+	"instance_dns",
+	"network_load_balancer_weight",
+	"metrics_exclude",
+	"image_copy_compression",
+	"disk_io_cache_sync",
+	"metrics_exemplars",
+	"instance_kernel_modules",
+	"storage_volume_defaults",
+	"config_validate",
+	"instance_dns_hostname",
+	"backup_encryption",
+	"instance_cpu_live_pinning",
+	"instance_ready_timeout",
+	"image_build",
+	"gpu_physical_numa",
+	"instance_template_triggers",
+	"network_dhcp_reservations",
+	"instance_hibernate",
+	"storage_pool_scrub",
+	"profile_priority_keys",
+	"network_ovn_dhcp_options",
+	"cluster_member_database_roles",
+	"container_network_namespace",
+	"metrics_cache_ttl",
+	"disk_io_scheduler",
+	"instance_file_transfer_progress",
+	"operation_throttle",
+	"network_ovn_trace",
+	"image_mirror",
+	"instances_host_shutdown_timeout",
+	"storage_volume_used_by_snapshots",
+	"network_acl_rule_priority",
+	"instance_rng",
+	"cluster_anti_affinity",
+	"nic_routed_dns",
+	"backup_import_selective",
+	"instance_qemu_hooks",
+	"storage_volume_convert",
+	"network_type_bond",
+	"instance_restart_policy",
+	"instance_copy_stateful",
+	"storage_volume_soft_limit",
+	"nic_tuntap",
+	"instance_snapshots_enabled",
+	"network_forward_http",
+	"instance_raw_cgroup",
+	"network_lease_delete",
+	"instance_memory_soft_swap",
+	"storage_pool_migrate",
+	"instance_apparmor_profile",
+	"event_history",
+	"disk_media_cdrom",
+	"image_refresh_dry_run",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
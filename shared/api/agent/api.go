@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // API10Put contains the fields which are needed for the incus-agent to connect to Incus.
 type API10Put struct {
 	// Context ID
@@ -18,3 +22,10 @@ type API10Put struct {
 	// Example: true
 	DevIncus bool `json:"dev_incus" yaml:"dev_incus"`
 }
+
+// ClockPut contains the fields which are needed for the incus-agent to set the instance clock.
+type ClockPut struct {
+	// Time to set the instance clock to
+	// Example: 2021-03-23T20:00:00-04:00
+	Time time.Time `json:"time" yaml:"time"`
+}
@@ -256,6 +256,99 @@ type ClusterMemberStatePost struct {
 	Mode string `json:"mode" yaml:"mode"`
 }
 
+// ClusterMemberEvacuationInstance represents the progress of an instance during a cluster member evacuation.
+// It's reported in the "evacuation_instances" field of the evacuation operation metadata.
+//
+// swagger:model
+//
+// API extension: cluster_evacuation_progress.
+type ClusterMemberEvacuationInstance struct {
+	// Name of the instance
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Evacuation action applied to the instance
+	// Example: live-migrate
+	Action string `json:"action" yaml:"action"`
+
+	// Current state of the instance evacuation (pending, stopping, stopped, migrating, live-migrating, starting, done, skipped or failed)
+	// Example: migrating
+	Status string `json:"status" yaml:"status"`
+
+	// Cluster member the instance is moved to
+	// Example: server02
+	Target string `json:"target" yaml:"target"`
+
+	// Number of bytes transferred so far
+	// Example: 1073741824
+	BytesTransferred uint64 `json:"bytes_transferred" yaml:"bytes_transferred"`
+
+	// Estimated number of bytes to transfer, snapshots included (0 if unknown)
+	// Example: 4294967296
+	BytesTotal uint64 `json:"bytes_total" yaml:"bytes_total"`
+
+	// Estimated remaining time of the transfer in seconds (0 if unknown)
+	// Example: 120
+	ETA uint64 `json:"eta" yaml:"eta"`
+}
+
+// ClusterDatabase represents the state of the cluster database.
+//
+// swagger:model
+//
+// API extension: cluster_database_status.
+type ClusterDatabase struct {
+	// Members of the database cluster
+	Members []ClusterDatabaseMember `json:"members" yaml:"members"`
+
+	// Maximum number of voting members (cluster.max_voters)
+	// Example: 3
+	MaxVoters int64 `json:"max_voters" yaml:"max_voters"`
+
+	// Maximum number of stand-by members (cluster.max_standby)
+	// Example: 2
+	MaxStandBy int64 `json:"max_standby" yaml:"max_standby"`
+
+	// Number of online voting members needed for the database to be available
+	// Example: 2
+	Quorum int `json:"quorum" yaml:"quorum"`
+}
+
+// ClusterDatabaseMember represents a member of the database cluster.
+//
+// swagger:model
+//
+// API extension: cluster_database_status.
+type ClusterDatabaseMember struct {
+	// Raft node ID
+	// Example: 1
+	ID uint64 `json:"id" yaml:"id"`
+
+	// Name of the cluster member
+	// Example: server01
+	ServerName string `json:"server_name" yaml:"server_name"`
+
+	// Address of the cluster member
+	// Example: 10.0.0.30:8443
+	Address string `json:"address" yaml:"address"`
+
+	// Raft role (voter, stand-by or spare)
+	// Example: voter
+	Role string `json:"role" yaml:"role"`
+
+	// Whether the member is the database leader
+	// Example: true
+	Leader bool `json:"leader" yaml:"leader"`
+
+	// Whether the member is online
+	// Example: true
+	Online bool `json:"online" yaml:"online"`
+}
+
 // ClusterGroupsPost represents the fields available for a new cluster group.
 //
 // swagger:model
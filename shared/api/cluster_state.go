@@ -25,4 +25,66 @@ type ClusterMemberSysInfo struct {
 type ClusterMemberState struct {
 	SysInfo      ClusterMemberSysInfo        `json:"sysinfo" yaml:"sysinfo"`
 	StoragePools map[string]StoragePoolState `json:"storage_pools" yaml:"storage_pools"`
+
+	// Availability of specialized devices, unset when it couldn't be retrieved
+	//
+	// API extension: cluster_member_device_availability
+	Devices *ClusterMemberDevices `json:"devices,omitempty" yaml:"devices,omitempty"`
+}
+
+// ClusterMemberDevices represents the availability of specialized devices on a cluster member.
+//
+// swagger:model
+//
+// API extension: cluster_member_device_availability.
+type ClusterMemberDevices struct {
+	// Total number of GPUs
+	// Example: 2
+	GPUTotal uint64 `json:"gpu_total" yaml:"gpu_total"`
+
+	// Number of GPUs not passed through to a virtual machine
+	// Example: 1
+	GPUFree uint64 `json:"gpu_free" yaml:"gpu_free"`
+
+	// GPUs not passed through to a virtual machine
+	GPUs []ClusterMemberGPU `json:"gpus" yaml:"gpus"`
+
+	// Total number of infiniband virtual functions
+	// Example: 8
+	InfinibandVFTotal uint64 `json:"infiniband_vf_total" yaml:"infiniband_vf_total"`
+
+	// Number of infiniband virtual functions not attached to an instance
+	// Example: 6
+	InfinibandVFFree uint64 `json:"infiniband_vf_free" yaml:"infiniband_vf_free"`
+
+	// PCI addresses of the devices not passed through to a virtual machine
+	// Example: ["0000:05:00.0"]
+	PCIFree []string `json:"pci_free" yaml:"pci_free"`
+}
+
+// ClusterMemberGPU represents a GPU available on a cluster member.
+//
+// swagger:model
+//
+// API extension: cluster_member_device_availability.
+type ClusterMemberGPU struct {
+	// PCI address
+	// Example: 0000:01:00.0
+	PCIAddress string `json:"pci_address" yaml:"pci_address"`
+
+	// DRM card ID, unset when the GPU has no DRM card
+	// Example: 0
+	DRMID *uint64 `json:"drm_id,omitempty" yaml:"drm_id,omitempty"`
+
+	// PCI ID of the vendor
+	// Example: 10de
+	VendorID string `json:"vendor_id" yaml:"vendor_id"`
+
+	// PCI ID of the product
+	// Example: 1c82
+	ProductID string `json:"product_id" yaml:"product_id"`
+
+	// GPU device types the GPU can be used with (physical, mdev, mig or sriov)
+	// Example: ["physical", "mdev"]
+	Types []string `json:"types" yaml:"types"`
 }
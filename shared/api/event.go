@@ -41,6 +41,12 @@ type Event struct {
 	//
 	// API extension: event_project
 	Project string `yaml:"project,omitempty" json:"project,omitempty"`
+
+	// Cursor identifying the event in the server's event history
+	// Example: 1f0d5b4c:1042
+	//
+	// API extension: event_history
+	Cursor string `yaml:"cursor,omitempty" json:"cursor,omitempty"`
 }
 
 // ToLogging creates log record for the event.
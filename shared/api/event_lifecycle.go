@@ -76,6 +76,9 @@ const (
 	EventLifecycleNetworkAddressSetUpdated          = "network-address-set-updated"
 	EventLifecycleNetworkCreated                    = "network-created"
 	EventLifecycleNetworkDeleted                    = "network-deleted"
+	EventLifecycleNetworkDHCPReservationCreated     = "network-dhcp-reservation-created"
+	EventLifecycleNetworkDHCPReservationDeleted     = "network-dhcp-reservation-deleted"
+	EventLifecycleNetworkDHCPReservationUpdated     = "network-dhcp-reservation-updated"
 	EventLifecycleNetworkForwardCreated             = "network-forward-created"
 	EventLifecycleNetworkForwardDeleted             = "network-forward-deleted"
 	EventLifecycleNetworkForwardUpdated             = "network-forward-updated"
@@ -83,6 +86,7 @@ const (
 	EventLifecycleNetworkIntegrationDeleted         = "network-integration-deleted"
 	EventLifecycleNetworkIntegrationRenamed         = "network-integration-renamed"
 	EventLifecycleNetworkIntegrationUpdated         = "network-integration-updated"
+	EventLifecycleNetworkLeaseDeleted               = "network-lease-deleted"
 	EventLifecycleNetworkLoadBalancerCreated        = "network-load-balancer-created"
 	EventLifecycleNetworkLoadBalancerDeleted        = "network-load-balancer-deleted"
 	EventLifecycleNetworkLoadBalancerUpdated        = "network-load-balancer-updated"
@@ -351,3 +351,119 @@ type ImageMetadataTemplate struct {
 	// API extension: image_template_permissions
 	Mode string `json:"mode,omitempty" yaml:"mode,omitempty"`
 }
+
+// ImageBuildPost represents the fields required to build a new image from a recipe
+//
+// swagger:model
+//
+// API extension: image_build.
+type ImageBuildPost struct {
+	// Recipe to build the image from
+	Recipe ImageBuildRecipe `json:"recipe" yaml:"recipe"`
+
+	// Values of the build arguments declared by the recipe
+	// Example: {"version": "1.2"}
+	Args map[string]string `json:"args" yaml:"args"`
+
+	// Aliases to set on the resulting image, existing ones are moved over to it
+	// Example: [{"name": "foo"}, {"name": "bar"}]
+	Aliases []ImageAlias `json:"aliases" yaml:"aliases"`
+
+	// Whether the resulting image is available to unauthenticated users
+	// Example: false
+	Public bool `json:"public" yaml:"public"`
+
+	// Compression algorithm to use for the resulting image
+	// Example: gzip
+	CompressionAlgorithm string `json:"compression_algorithm" yaml:"compression_algorithm"`
+
+	// Whether to ignore the images cached by previous builds
+	// Example: false
+	NoCache bool `json:"no_cache" yaml:"no_cache"`
+}
+
+// ImageBuildRecipe represents the recipe of an image build
+//
+// swagger:model
+//
+// API extension: image_build.
+type ImageBuildRecipe struct {
+	// Base image to build from
+	Source InstanceSource `json:"source" yaml:"source"`
+
+	// Type of the builder instance (container or virtual-machine)
+	// Example: container
+	Type InstanceType `json:"type" yaml:"type"`
+
+	// Profiles to apply to the builder instance
+	// Example: ["default"]
+	Profiles []string `json:"profiles" yaml:"profiles"`
+
+	// Configuration of the builder instance
+	// Example: {"security.nesting": "true"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Build arguments and their default values
+	// Example: {"version": "1.0"}
+	Args map[string]string `json:"args" yaml:"args"`
+
+	// Steps to run in the builder instance
+	Steps []ImageBuildStep `json:"steps" yaml:"steps"`
+
+	// Properties of the resulting image
+	// Example: {"os": "Debian", "release": "12", "description": "Web server"}
+	Properties map[string]string `json:"properties" yaml:"properties"`
+}
+
+// ImageBuildStep represents a step of an image build, either running a command or creating a file
+//
+// swagger:model
+//
+// API extension: image_build.
+type ImageBuildStep struct {
+	// Command to run
+	// Example: ["apt-get", "install", "-y", "nginx"]
+	Exec []string `json:"exec,omitempty" yaml:"exec,omitempty"`
+
+	// Environment variables to set for the command
+	// Example: {"DEBIAN_FRONTEND": "noninteractive"}
+	Environment map[string]string `json:"environment,omitempty" yaml:"environment,omitempty"`
+
+	// Working directory of the command
+	// Example: /root
+	Cwd string `json:"cwd,omitempty" yaml:"cwd,omitempty"`
+
+	// File to create
+	File *ImageBuildFile `json:"file,omitempty" yaml:"file,omitempty"`
+
+	// Whether to cache the result of this step for future builds
+	// Example: true
+	Cache bool `json:"cache,omitempty" yaml:"cache,omitempty"`
+}
+
+// ImageBuildFile represents a file created by an image build step
+//
+// swagger:model
+//
+// API extension: image_build.
+type ImageBuildFile struct {
+	// Path of the file in the builder instance
+	// Example: /etc/nginx/sites-enabled/default
+	Path string `json:"path" yaml:"path"`
+
+	// Content of the file
+	// Example: server { listen 80; }
+	Content string `json:"content" yaml:"content"`
+
+	// File owner UID
+	// Example: 0
+	UID int64 `json:"uid" yaml:"uid"`
+
+	// File owner GID
+	// Example: 0
+	GID int64 `json:"gid" yaml:"gid"`
+
+	// File mode, in octal
+	// Example: 0644
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty"`
+}
@@ -229,10 +229,16 @@ type Instance struct {
 	// Example: 101
 	StatusCode StatusCode `json:"status_code" yaml:"status_code"`
 
-	// Last start timestamp
+	// Last use timestamp (start, exec or console)
 	// Example: 2021-03-23T20:00:00-04:00
 	LastUsedAt time.Time `json:"last_used_at" yaml:"last_used_at"`
 
+	// Last start timestamp
+	// Example: 2021-03-23T20:00:00-04:00
+	//
+	// API extension: instance_last_started
+	LastStartedAt time.Time `json:"last_started_at" yaml:"last_started_at"`
+
 	// What cluster member this instance is located on
 	// Example: server01
 	Location string `json:"location" yaml:"location"`
@@ -246,6 +252,23 @@ type Instance struct {
 	//
 	// API extension: instance_all_projects
 	Project string `json:"project" yaml:"project"`
+
+	// Instance labels
+	// Example: {"env": "prod"}
+	//
+	// API extension: instance_labels
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// InstanceLabels represents the labels of an instance.
+//
+// swagger:model
+//
+// API extension: instance_labels.
+type InstanceLabels struct {
+	// Instance labels
+	// Example: {"env": "prod", "role": "db"}
+	Labels map[string]string `json:"labels" yaml:"labels"`
 }
 
 // InstanceFull is a combination of Instance, InstanceBackup, InstanceState and InstanceSnapshot.
@@ -377,4 +400,10 @@ type InstanceSource struct {
 	//
 	// API extension: instance_allow_inconsistent_copy
 	AllowInconsistent bool `json:"allow_inconsistent" yaml:"allow_inconsistent"`
+
+	// Whether to copy the running state of the source into a new running instance (for copy)
+	// Example: false
+	//
+	// API extension: instance_copy_stateful
+	Stateful bool `json:"stateful,omitempty" yaml:"stateful,omitempty"`
 }
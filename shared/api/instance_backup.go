@@ -31,6 +31,12 @@ type InstanceBackupsPost struct {
 	//
 	// API extension: backup_compression_algorithm
 	CompressionAlgorithm string `json:"compression_algorithm" yaml:"compression_algorithm"`
+
+	// Passphrase to encrypt the backup with (not stored)
+	// Example: my-secret-passphrase
+	//
+	// API extension: backup_encryption
+	EncryptionPassphrase string `json:"encryption_passphrase,omitempty" yaml:"encryption_passphrase,omitempty"`
 }
 
 // InstanceBackup represents an instance backup.
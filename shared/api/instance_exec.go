@@ -4,9 +4,17 @@ package api
 //
 // API extension: instances.
 type InstanceExecControl struct {
-	Command string            `json:"command" yaml:"command"`
-	Args    map[string]string `json:"args" yaml:"args"`
-	Signal  int               `json:"signal" yaml:"signal"`
+	// Control command ("window-resize" or "signal")
+	// Example: window-resize
+	Command string `json:"command" yaml:"command"`
+
+	// Command arguments ("width" and "height" in characters for "window-resize")
+	// Example: {"width": "80", "height": "25"}
+	Args map[string]string `json:"args" yaml:"args"`
+
+	// Signal number to forward to the command (for "signal")
+	// Example: 15
+	Signal int `json:"signal" yaml:"signal"`
 }
 
 // InstanceExecPost represents an instance exec request.
@@ -4,13 +4,19 @@ import (
 	"time"
 )
 
+// InstanceStatusHibernated is the status of virtual machines whose state was saved to disk by hibernation.
+// Their status code remains Stopped.
+//
+// API extension: instance_hibernate.
+const InstanceStatusHibernated = "Hibernated"
+
 // InstanceStatePut represents the modifiable fields of an instance's state.
 //
 // swagger:model
 //
 // API extension: instances.
 type InstanceStatePut struct {
-	// State change action (start, stop, restart, freeze, unfreeze)
+	// State change action (start, stop, restart, freeze, unfreeze, sync-clock, hibernate)
 	// Example: start
 	Action string `json:"action" yaml:"action"`
 
@@ -33,7 +39,7 @@ type InstanceStatePut struct {
 //
 // API extension: instances.
 type InstanceState struct {
-	// Current status (Running, Stopped, Frozen or Error)
+	// Current status (Running, Stopped, Hibernated, Frozen or Error)
 	// Example: Running
 	Status string `json:"status" yaml:"status"`
 
@@ -70,6 +76,23 @@ type InstanceState struct {
 	//
 	// API extension: instances_state_os_info.
 	OSInfo *InstanceStateOSInfo `json:"os_info" yaml:"os_info"`
+
+	// Clock information.
+	//
+	// API extension: instance_state_clock.
+	Clock *InstanceStateClock `json:"clock" yaml:"clock"`
+
+	// Size of the console log in bytes, including its rotated files
+	// Example: 1048576
+	//
+	// API extension: instance_console_log_rotation.
+	ConsoleLogSize int64 `json:"console_log_size" yaml:"console_log_size"`
+
+	// Number of times the instance was restarted by its restart policy since it was last started
+	// Example: 2
+	//
+	// API extension: instance_restart_policy.
+	RestartCount int64 `json:"restart_count" yaml:"restart_count"`
 }
 
 // InstanceStateDisk represents the disk information section of an instance's state.
@@ -258,3 +281,22 @@ type InstanceStateOSInfo struct {
 	// Example: myhost.mydomain.local
 	FQDN string `json:"fqdn" yaml:"fqdn"`
 }
+
+// InstanceStateClock represents the clock information section of an instance's state.
+//
+// swagger:model
+//
+// API extension: instance_state_clock.
+type InstanceStateClock struct {
+	// Current time in the instance.
+	// Example: 2021-03-23T20:00:00-04:00
+	Time time.Time `json:"time" yaml:"time"`
+
+	// Offset of the instance clock from the host clock (in nanoseconds).
+	// Example: 1500000
+	Offset int64 `json:"offset" yaml:"offset"`
+
+	// NTP synchronization status of the instance clock (synchronized, unsynchronized or empty if unknown).
+	// Example: synchronized
+	NTPStatus string `json:"ntp_status" yaml:"ntp_status"`
+}
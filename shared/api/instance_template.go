@@ -0,0 +1,34 @@
+package api
+
+// InstanceTemplate represents an instance file template and its triggers.
+//
+// swagger:model
+//
+// API extension: instance_template_triggers.
+type InstanceTemplate struct {
+	// Template file name
+	// Example: hostname.tpl
+	Name string `json:"name" yaml:"name"`
+
+	// List of paths in the instance rendered from this template
+	Triggers []InstanceTemplateTrigger `json:"triggers" yaml:"triggers"`
+}
+
+// InstanceTemplateTrigger represents a path in the instance rendered from a template.
+//
+// swagger:model
+//
+// API extension: instance_template_triggers.
+type InstanceTemplateTrigger struct {
+	// Path of the generated file in the instance
+	// Example: /etc/hostname
+	Path string `json:"path" yaml:"path"`
+
+	// When to trigger the template (create, copy or start)
+	// Example: ["create", "copy"]
+	When []string `json:"when" yaml:"when"`
+
+	// Whether to trigger only if the file is missing
+	// Example: false
+	CreateOnly bool `json:"create_only" yaml:"create_only"`
+}
@@ -188,6 +188,11 @@ type NetworkState struct {
 	//
 	// API extension: network_state_ovn
 	OVN *NetworkStateOVN `json:"ovn" yaml:"ovn"`
+
+	// Connection tracking information
+	//
+	// API extension: network_conntrack
+	Conntrack *NetworkStateConntrack `json:"conntrack" yaml:"conntrack"`
 }
 
 // NetworkStateAddress represents a network address
@@ -265,6 +270,18 @@ type NetworkStateBond struct {
 	// List of devices that are part of the bond
 	// Example: ["eth0", "eth1"]
 	LowerDevices []string `json:"lower_devices" yaml:"lower_devices"`
+
+	// Currently active device (active-backup mode)
+	// Example: eth0
+	//
+	// API extension: network_type_bond
+	ActiveDevice string `json:"active_device" yaml:"active_device"`
+
+	// Link state of each device that is part of the bond
+	// Example: {"eth0": "up", "eth1": "down"}
+	//
+	// API extension: network_type_bond
+	LowerDevicesState map[string]string `json:"lower_devices_state" yaml:"lower_devices_state"`
 }
 
 // NetworkStateBridge represents bridge specific state
@@ -346,4 +363,35 @@ type NetworkStateOVN struct {
 	//
 	// API extension: network_ovn_state_addresses
 	UplinkIPv6 string `json:"uplink_ipv6" yaml:"uplink_ipv6"`
+
+	// DHCPv4 options programmed in OVN for the network
+	// Example: {"lease_time": "3600", "ntp_server": "{10.0.0.1}"}
+	//
+	// API extension: network_ovn_dhcp_options
+	DHCPv4Options map[string]string `json:"dhcpv4_options" yaml:"dhcpv4_options"`
+
+	// DHCPv6 options programmed in OVN for the network
+	// Example: {"server_id": "00:16:3e:00:00:01"}
+	//
+	// API extension: network_ovn_dhcp_options
+	DHCPv6Options map[string]string `json:"dhcpv6_options" yaml:"dhcpv6_options"`
+}
+
+// NetworkStateConntrack represents the connection tracking state of a network
+//
+// swagger:model
+//
+// API extension: network_conntrack.
+type NetworkStateConntrack struct {
+	// Number of tracked connections originating from the network
+	// Example: 1024
+	Count uint64 `json:"count" yaml:"count"`
+
+	// Maximum number of tracked connections for the network (0 when unlimited)
+	// Example: 65536
+	Max uint64 `json:"max" yaml:"max"`
+
+	// Size of the host connection tracking table
+	// Example: 262144
+	HostMax uint64 `json:"host_max" yaml:"host_max"`
 }
@@ -50,6 +50,12 @@ type NetworkACLRule struct {
 	// State of the rule
 	// Example: enabled
 	State string `json:"state" yaml:"state"`
+
+	// Priority of the rule (rules with a higher priority are evaluated first)
+	// Example: 10
+	//
+	// API extension: network_acl_rule_priority
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
 }
 
 // Normalise normalises the fields in the rule so that they are comparable with ones stored.
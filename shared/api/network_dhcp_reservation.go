@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net"
+	"strings"
+)
+
+// NetworkDHCPReservationsPost represents the fields of a new network DHCP reservation
+//
+// swagger:model
+//
+// API extension: network_dhcp_reservations.
+type NetworkDHCPReservationsPost struct {
+	NetworkDHCPReservationPut `yaml:",inline"`
+
+	// The MAC address the reservation applies to
+	// Example: 10:66:6a:a4:a5:63
+	Hwaddr string `json:"hwaddr" yaml:"hwaddr"`
+}
+
+// Normalise normalises the fields in the reservation so that they are comparable with ones stored.
+func (r *NetworkDHCPReservationsPost) Normalise() {
+	mac, err := net.ParseMAC(r.Hwaddr)
+	if err == nil {
+		r.Hwaddr = mac.String() // Replace with canonical form if specified.
+	}
+
+	r.NetworkDHCPReservationPut.Normalise()
+}
+
+// NetworkDHCPReservationPut represents the modifiable fields of a network DHCP reservation
+//
+// swagger:model
+//
+// API extension: network_dhcp_reservations.
+type NetworkDHCPReservationPut struct {
+	// Description of the reservation
+	// Example: Office printer
+	Description string `json:"description" yaml:"description"`
+
+	// Reserved IPv4 address
+	// Example: 10.0.0.10
+	IPv4Address string `json:"ipv4_address" yaml:"ipv4_address"`
+
+	// Reserved IPv6 address
+	// Example: fd42:4242:4242:1010::10
+	IPv6Address string `json:"ipv6_address" yaml:"ipv6_address"`
+
+	// Host name handed out with the reservation (optional)
+	// Example: printer
+	Hostname string `json:"hostname" yaml:"hostname"`
+}
+
+// Normalise normalises the fields in the reservation so that they are comparable with ones stored.
+func (r *NetworkDHCPReservationPut) Normalise() {
+	r.Description = strings.TrimSpace(r.Description)
+	r.Hostname = strings.TrimSpace(r.Hostname)
+
+	for _, address := range []*string{&r.IPv4Address, &r.IPv6Address} {
+		ip := net.ParseIP(strings.TrimSpace(*address))
+		if ip != nil {
+			*address = ip.String() // Replace with canonical form if specified.
+		}
+	}
+}
+
+// NetworkDHCPReservation used for displaying a network DHCP reservation.
+//
+// swagger:model
+//
+// API extension: network_dhcp_reservations.
+type NetworkDHCPReservation struct {
+	NetworkDHCPReservationPut `yaml:",inline"`
+
+	// The MAC address the reservation applies to
+	// Example: 10:66:6a:a4:a5:63
+	Hwaddr string `json:"hwaddr" yaml:"hwaddr"`
+}
+
+// Etag returns the values used for etag generation.
+func (r *NetworkDHCPReservation) Etag() []any {
+	return []any{r.Hwaddr, r.Description, r.IPv4Address, r.IPv6Address, r.Hostname}
+}
+
+// Writable converts a full NetworkDHCPReservation struct into a NetworkDHCPReservationPut struct (filters read-only fields).
+func (r *NetworkDHCPReservation) Writable() NetworkDHCPReservationPut {
+	return r.NetworkDHCPReservationPut
+}
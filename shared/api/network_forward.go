@@ -15,7 +15,7 @@ type NetworkForwardPort struct {
 	// Example: My web server forward
 	Description string `json:"description" yaml:"description"`
 
-	// Protocol for port forward (either tcp or udp)
+	// Protocol for port forward (either tcp, udp, http or https)
 	// Example: tcp
 	Protocol string `json:"protocol" yaml:"protocol"`
 
@@ -36,6 +36,12 @@ type NetworkForwardPort struct {
 	//
 	// API extension: network_forward_snat
 	SNAT bool `json:"snat" yaml:"snat"`
+
+	// Hostnames to route to the target for the http and https protocols (comma delimited, wildcards allowed)
+	// Example: example.com,*.example.net
+	//
+	// API extension: network_forward_http
+	Hostnames string `json:"hostnames,omitempty" yaml:"hostnames,omitempty"`
 }
 
 // Normalise normalises the fields in the rule so that they are comparable with ones stored.
@@ -64,6 +70,16 @@ func (p *NetworkForwardPort) Normalise() {
 	}
 
 	p.TargetPort = strings.Join(subjects, ",")
+
+	// Remove space from Hostnames list and make it lowercase.
+	if p.Hostnames != "" {
+		subjects = strings.Split(p.Hostnames, ",")
+		for i, s := range subjects {
+			subjects[i] = strings.ToLower(strings.TrimSpace(s))
+		}
+
+		p.Hostnames = strings.Join(subjects, ",")
+	}
 }
 
 // NetworkForwardsPost represents the fields of a new network address forward
@@ -26,6 +26,12 @@ type NetworkLoadBalancerBackend struct {
 	// TargetAddress to forward ListenPorts to
 	// Example: 198.51.100.2
 	TargetAddress string `json:"target_address" yaml:"target_address"`
+
+	// Weight of the backend relative to the others (defaults to 1, must be positive when set)
+	// Example: 2
+	//
+	// API extension: network_load_balancer_weight
+	Weight *int `json:"weight,omitempty" yaml:"weight,omitempty"`
 }
 
 // Normalise normalises the fields in the load balancer backend so that they are comparable with ones stored.
@@ -165,6 +171,12 @@ func (f *NetworkLoadBalancer) Writable() NetworkLoadBalancerPut {
 // API extension: network_load_balancer_state.
 type NetworkLoadBalancerState struct {
 	BackendHealth map[string]NetworkLoadBalancerStateBackendHealth `json:"backend_health" yaml:"backend_health"`
+
+	// Effective weight of each backend
+	// Example: {"c1-http": 2, "c2-http": 1}
+	//
+	// API extension: network_load_balancer_weight
+	BackendWeight map[string]int `json:"backend_weight" yaml:"backend_weight"`
 }
 
 // NetworkLoadBalancerStateBackendHealth represents the health of a particular load-balancer backend
@@ -0,0 +1,39 @@
+package api
+
+// NetworkTracePost represents the packet to trace through a network
+//
+// swagger:model
+//
+// API extension: network_ovn_trace.
+type NetworkTracePost struct {
+	// Source address of the packet
+	// Example: 10.0.0.2
+	Source string `json:"source" yaml:"source"`
+
+	// Destination address of the packet
+	// Example: 10.0.0.3
+	Destination string `json:"destination" yaml:"destination"`
+
+	// Protocol of the packet (tcp, udp or icmp)
+	// Example: tcp
+	Protocol string `json:"protocol" yaml:"protocol"`
+
+	// Destination port of the packet (for tcp and udp)
+	// Example: 80
+	Port int `json:"port" yaml:"port"`
+}
+
+// NetworkTrace represents the result of a packet trace through a network
+//
+// swagger:model
+//
+// API extension: network_ovn_trace.
+type NetworkTrace struct {
+	// Logical flow that was traced
+	// Example: inport == "incus-net1-instance-5e2e0b93-eth0" && eth.src == 10:66:6a:4f:7e:1d && ...
+	Microflow string `json:"microflow" yaml:"microflow"`
+
+	// Trace output, annotated with the Incus objects involved
+	// Example: ingress(dp="incus-net1-ls-int", inport="incus-net1-instance-5e2e0b93-eth0") ...
+	Output string `json:"output" yaml:"output"`
+}
@@ -67,6 +67,29 @@ type Operation struct {
 	//
 	// API extension: operation_location
 	Location string `json:"location" yaml:"location"`
+
+	// Whether the bandwidth of the operation can be limited
+	// Example: true
+	//
+	// API extension: operation_throttle
+	MayThrottle bool `json:"may_throttle" yaml:"may_throttle"`
+
+	// Current bandwidth limit of the operation in bytes per second (0 when unlimited)
+	// Example: 10000000
+	//
+	// API extension: operation_throttle
+	Bandwidth int64 `json:"bandwidth" yaml:"bandwidth"`
+}
+
+// OperationPut represents the modifiable fields of an operation
+//
+// swagger:model
+//
+// API extension: operation_throttle.
+type OperationPut struct {
+	// Bandwidth limit of the operation in bytes per second (0 to remove the limit)
+	// Example: 10000000
+	Bandwidth int64 `json:"bandwidth" yaml:"bandwidth"`
 }
 
 // ToCertificateAddToken creates a certificate add token from the operation metadata.
@@ -176,3 +199,28 @@ func (op *Operation) ToClusterJoinToken() (*ClusterMemberJoinToken, error) {
 
 	return &joinToken, nil
 }
+
+// ToProfileAssignInstances returns the per-instance outcome of a profile assignment from the operation metadata.
+func (op *Operation) ToProfileAssignInstances() ([]ProfileAssignInstance, error) {
+	instances, ok := op.Metadata["instances"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("Operation instances is type %T not []any", op.Metadata["instances"])
+	}
+
+	results := make([]ProfileAssignInstance, 0, len(instances))
+	for i, instance := range instances {
+		fields, ok := instance.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("Operation instance index %d is type %T not map[string]any", i, instance)
+		}
+
+		result := ProfileAssignInstance{}
+		result.Name, _ = fields["name"].(string)
+		result.Status, _ = fields["status"].(string)
+		result.Error, _ = fields["error"].(string)
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
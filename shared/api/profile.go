@@ -71,3 +71,33 @@ func (profile *Profile) Writable() ProfilePut {
 func (profile *Profile) URL(apiVersion string, projectName string) *URL {
 	return NewURL().Path(apiVersion, "profiles", profile.Name).Project(projectName)
 }
+
+// ProfileAssignPost represents the fields required to add a profile to multiple instances
+//
+// swagger:model
+//
+// API extension: profile_assign_bulk.
+type ProfileAssignPost struct {
+	// Names of the instances to add the profile to
+	// Example: ["c1", "v1"]
+	Instances []string `json:"instances" yaml:"instances"`
+}
+
+// ProfileAssignInstance represents the outcome of adding a profile to one of the instances
+//
+// swagger:model
+//
+// API extension: profile_assign_bulk.
+type ProfileAssignInstance struct {
+	// Name of the instance
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Status of the assignment (pending, unchanged, applied, failed, reverted)
+	// Example: applied
+	Status string `json:"status" yaml:"status"`
+
+	// Error message if the validation or update failed
+	// Example: Device "eth0" is already defined differently by profile "default"
+	Error string `json:"error" yaml:"error"`
+}
@@ -786,6 +786,11 @@ type ResourcesStoragePool struct {
 
 	// DIsk inode usage
 	Inodes ResourcesStoragePoolInodes `json:"inodes,omitempty" yaml:"inodes,omitempty"`
+
+	// Status of the last data integrity scrub (for pools supporting it)
+	//
+	// API extension: storage_pool_scrub
+	Scrub *StoragePoolScrub `json:"scrub,omitempty" yaml:"scrub,omitempty"`
 }
 
 // ResourcesStoragePoolSpace represents the space available to a given storage pool
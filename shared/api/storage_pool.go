@@ -98,3 +98,22 @@ func (storagePool *StoragePool) Writable() StoragePoolPut {
 type StoragePoolState struct {
 	ResourcesStoragePool `yaml:",inline"`
 }
+
+// StoragePoolMigratePost represents the fields required to move all the volumes of a storage pool to another pool
+//
+// swagger:model
+//
+// API extension: storage_pool_migrate.
+type StoragePoolMigratePost struct {
+	// Name of the storage pool to move the volumes to
+	// Example: remote
+	Pool string `json:"pool" yaml:"pool"`
+
+	// Whether to stop the running instances using the volumes and start them again once moved
+	// Example: true
+	Stop bool `json:"stop" yaml:"stop"`
+
+	// Whether to delete the source storage pool once all the volumes are moved
+	// Example: false
+	DeleteSource bool `json:"delete_source" yaml:"delete_source"`
+}
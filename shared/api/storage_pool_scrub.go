@@ -0,0 +1,44 @@
+package api
+
+import (
+	"time"
+)
+
+// StoragePoolScrubStatusNone indicates that the storage pool was never scrubbed.
+const StoragePoolScrubStatusNone = "none"
+
+// StoragePoolScrubStatusRunning indicates that a scrub of the storage pool is in progress.
+const StoragePoolScrubStatusRunning = "running"
+
+// StoragePoolScrubStatusFinished indicates that the last scrub of the storage pool completed.
+const StoragePoolScrubStatusFinished = "finished"
+
+// StoragePoolScrubStatusCanceled indicates that the last scrub of the storage pool was interrupted.
+const StoragePoolScrubStatusCanceled = "canceled"
+
+// StoragePoolScrub represents the status of the last data integrity scrub of a storage pool.
+//
+// swagger:model
+//
+// API extension: storage_pool_scrub.
+type StoragePoolScrub struct {
+	// Status of the scrub (none, running, finished or canceled)
+	// Example: finished
+	Status string `json:"status" yaml:"status"`
+
+	// When the scrub started
+	// Example: 2026-10-11T00:24:02Z
+	StartedAt time.Time `json:"started_at" yaml:"started_at"`
+
+	// When the scrub completed (unset while running)
+	// Example: 2026-10-11T00:31:12Z
+	FinishedAt time.Time `json:"finished_at" yaml:"finished_at"`
+
+	// Percentage of the pool data scrubbed
+	// Example: 100
+	Progress float64 `json:"progress" yaml:"progress"`
+
+	// Number of errors found
+	// Example: 0
+	Errors uint64 `json:"errors" yaml:"errors"`
+}
@@ -75,6 +75,18 @@ type StorageVolumePost struct {
 	//
 	// API extension: cluster_internal_custom_volume_copy
 	Source StorageVolumeSource `json:"source" yaml:"source"`
+
+	// New content type (filesystem or block) to convert the volume to
+	// Example: block
+	//
+	// API extension: storage_volume_convert
+	ContentType string `json:"content_type,omitempty" yaml:"content_type,omitempty"`
+
+	// Confirm that the filesystem of a block volume may be mounted on the host to convert it to a filesystem volume
+	// Example: false
+	//
+	// API extension: storage_volume_convert
+	AllowHostMount bool `json:"allow_host_mount,omitempty" yaml:"allow_host_mount,omitempty"`
 }
 
 // StorageVolumePostTarget represents the migration target host and operation
@@ -56,6 +56,12 @@ type StorageVolumeBackupsPost struct {
 	// What compression algorithm to use
 	// Example: gzip
 	CompressionAlgorithm string `json:"compression_algorithm" yaml:"compression_algorithm"`
+
+	// Passphrase to encrypt the backup with (not stored)
+	// Example: my-secret-passphrase
+	//
+	// API extension: backup_encryption
+	EncryptionPassphrase string `json:"encryption_passphrase,omitempty" yaml:"encryption_passphrase,omitempty"`
 }
 
 // StorageVolumeBackupPost represents the fields available for the renaming of a volume backup
@@ -0,0 +1,69 @@
+package api
+
+// ConfigValidatePost represents a configuration to validate without applying it.
+//
+// swagger:model
+//
+// API extension: config_validate.
+type ConfigValidatePost struct {
+	// Kind of entity the configuration is for (instance, profile, network, storage-pool or storage-volume)
+	// Example: instance
+	Entity string `json:"entity" yaml:"entity"`
+
+	// Name of the entity (the configuration is validated as an update if it already exists)
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Instance type, network type, storage pool driver or storage volume content type
+	// Example: container
+	Type string `json:"type" yaml:"type"`
+
+	// Storage pool of a storage volume
+	// Example: default
+	Pool string `json:"pool" yaml:"pool"`
+
+	// List of profiles applied to an instance
+	// Example: ["default"]
+	Profiles []string `json:"profiles" yaml:"profiles"`
+
+	// Configuration map
+	// Example: {"limits.cpu": "4"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Devices of an instance or profile
+	// Example: {"root": {"type": "disk", "pool": "default", "path": "/"}}
+	Devices map[string]map[string]string `json:"devices" yaml:"devices"`
+}
+
+// ConfigValidateResult represents the result of a configuration validation.
+//
+// swagger:model
+//
+// API extension: config_validate.
+type ConfigValidateResult struct {
+	// Whether the configuration is valid
+	// Example: false
+	Valid bool `json:"valid" yaml:"valid"`
+
+	// List of validation errors
+	Errors []ConfigValidateError `json:"errors" yaml:"errors"`
+}
+
+// ConfigValidateError represents a single configuration validation error.
+//
+// swagger:model
+//
+// API extension: config_validate.
+type ConfigValidateError struct {
+	// Part of the request the error relates to (name, config, devices or project)
+	// Example: config
+	Section string `json:"section" yaml:"section"`
+
+	// Configuration key or device name the error relates to (empty if it can't be attributed to one)
+	// Example: limits.cpu
+	Key string `json:"key" yaml:"key"`
+
+	// Error message
+	// Example: Invalid value for limits.cpu
+	Message string `json:"message" yaml:"message"`
+}
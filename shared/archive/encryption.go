@@ -0,0 +1,342 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// EncryptionMagic is the magic string found at the start of encrypted archives.
+const EncryptionMagic = "INCUSENC"
+
+// ErrInvalidPassphrase is returned when an encrypted archive can't be decrypted with the provided passphrase.
+var ErrInvalidPassphrase = errors.New("Invalid passphrase")
+
+// Default parameters used for new encrypted archives.
+const (
+	encryptionChunkSize  = 64 * 1024
+	encryptionScryptN    = 1 << 15
+	encryptionScryptR    = 8
+	encryptionScryptP    = 1
+	encryptionMaxHeader  = 4096
+	encryptionMinLength  = 8
+	encryptionNonceFinal = 1
+)
+
+// EncryptionHeader is the metadata stored in clear text at the start of an encrypted archive.
+// It holds everything needed to derive the key from the passphrase and to decrypt the stream.
+type EncryptionHeader struct {
+	Version   int    `json:"version"`
+	Cipher    string `json:"cipher"`
+	KDF       string `json:"kdf"`
+	Salt      []byte `json:"salt"`
+	ScryptN   int    `json:"scrypt_n"`
+	ScryptR   int    `json:"scrypt_r"`
+	ScryptP   int    `json:"scrypt_p"`
+	ChunkSize int    `json:"chunk_size"`
+	KeyCheck  []byte `json:"key_check"`
+}
+
+// IsEncrypted returns whether the given leading bytes of a file are those of an encrypted archive.
+func IsEncrypted(header []byte) bool {
+	return bytes.HasPrefix(header, []byte(EncryptionMagic))
+}
+
+// ValidateEncryptionPassphrase checks that a passphrase is suitable to encrypt an archive.
+func ValidateEncryptionPassphrase(passphrase string) error {
+	if len(passphrase) < encryptionMinLength {
+		return fmt.Errorf("Encryption passphrase must be at least %d characters long", encryptionMinLength)
+	}
+
+	return nil
+}
+
+// deriveKey derives the encryption key and the key check value from a passphrase.
+func (h *EncryptionHeader) deriveKey(passphrase string) ([]byte, []byte, error) {
+	if h.KDF != "scrypt" {
+		return nil, nil, fmt.Errorf("Unsupported key derivation function %q", h.KDF)
+	}
+
+	derived, err := scrypt.Key([]byte(passphrase), h.Salt, h.ScryptN, h.ScryptR, h.ScryptP, 2*chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed deriving encryption key: %w", err)
+	}
+
+	keyCheck := sha256.Sum256(derived[chacha20poly1305.KeySize:])
+
+	return derived[:chacha20poly1305.KeySize], keyCheck[:], nil
+}
+
+// encryptionNonce returns the nonce of a chunk. It's made of the chunk counter and a flag marking the final
+// chunk, so that reordered, dropped or truncated chunks fail authentication.
+func encryptionNonce(counter uint64, final bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[chacha20poly1305.NonceSize-9:], counter)
+
+	if final {
+		nonce[chacha20poly1305.NonceSize-1] = encryptionNonceFinal
+	}
+
+	return nonce
+}
+
+// encryptionWriter encrypts a stream chunk by chunk as it's written.
+type encryptionWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+	closed  bool
+}
+
+// NewEncryptionWriter returns a writer encrypting everything written to it with a key derived from the
+// passphrase, writing the result to w. The data is processed in fixed-size chunks so that streams of any size
+// can be encrypted without buffering them.
+// Close must be called to write the final chunk, it doesn't close w.
+func NewEncryptionWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	err := ValidateEncryptionPassphrase(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	header := EncryptionHeader{
+		Version:   1,
+		Cipher:    "chacha20poly1305",
+		KDF:       "scrypt",
+		Salt:      make([]byte, 32),
+		ScryptN:   encryptionScryptN,
+		ScryptR:   encryptionScryptR,
+		ScryptP:   encryptionScryptP,
+		ChunkSize: encryptionChunkSize,
+	}
+
+	_, err = rand.Read(header.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating salt: %w", err)
+	}
+
+	key, keyCheck, err := header.deriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	header.KeyCheck = keyCheck
+
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write the magic, the header length and the header itself.
+	prefix := make([]byte, len(EncryptionMagic)+4)
+	copy(prefix, EncryptionMagic)
+	binary.BigEndian.PutUint32(prefix[len(EncryptionMagic):], uint32(len(headerBytes)))
+
+	_, err = w.Write(append(prefix, headerBytes...))
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptionWriter{
+		w:    w,
+		aead: aead,
+		buf:  make([]byte, 0, encryptionChunkSize),
+	}, nil
+}
+
+// Write buffers the data and writes out the encrypted chunks as they fill up.
+func (e *encryptionWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("Write to closed encryption writer")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// Only write a full chunk once more data comes in, so the last one can be flagged as such on close.
+		if len(e.buf) == cap(e.buf) {
+			err := e.writeChunk(false)
+			if err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(e.buf[len(e.buf):cap(e.buf)], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+// Close writes the final chunk.
+func (e *encryptionWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+
+	e.closed = true
+
+	return e.writeChunk(true)
+}
+
+// writeChunk encrypts and writes the buffered data.
+func (e *encryptionWriter) writeChunk(final bool) error {
+	sealed := e.aead.Seal(nil, encryptionNonce(e.counter, final), e.buf, nil)
+
+	_, err := e.w.Write(sealed)
+	if err != nil {
+		return err
+	}
+
+	e.counter++
+	e.buf = e.buf[:0]
+
+	return nil
+}
+
+// decryptionReader decrypts a stream produced by an encryptionWriter.
+type decryptionReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	chunk   []byte
+	buf     []byte
+	counter uint64
+	done    bool
+}
+
+// NewDecryptionReader returns a reader decrypting the encrypted archive read from r.
+// The header is read and the passphrase checked against it straight away, ErrInvalidPassphrase being returned
+// if it doesn't match. Any tampering with or truncation of the data is reported when reading it.
+func NewDecryptionReader(r io.Reader, passphrase string) (io.Reader, error) {
+	bufReader := bufio.NewReader(r)
+
+	prefix := make([]byte, len(EncryptionMagic)+4)
+	_, err := io.ReadFull(bufReader, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading encryption header: %w", err)
+	}
+
+	if !IsEncrypted(prefix) {
+		return nil, errors.New("Data isn't encrypted")
+	}
+
+	headerLen := binary.BigEndian.Uint32(prefix[len(EncryptionMagic):])
+	if headerLen > encryptionMaxHeader {
+		return nil, errors.New("Invalid encryption header")
+	}
+
+	headerBytes := make([]byte, headerLen)
+	_, err = io.ReadFull(bufReader, headerBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading encryption header: %w", err)
+	}
+
+	header := EncryptionHeader{}
+	err = json.Unmarshal(headerBytes, &header)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing encryption header: %w", err)
+	}
+
+	if header.Version != 1 || header.Cipher != "chacha20poly1305" {
+		return nil, fmt.Errorf("Unsupported encryption format (version %d, cipher %q)", header.Version, header.Cipher)
+	}
+
+	if header.ChunkSize <= 0 || header.ChunkSize > 16*1024*1024 {
+		return nil, fmt.Errorf("Invalid encryption chunk size %d", header.ChunkSize)
+	}
+
+	// Bound the key derivation cost as the header isn't trusted.
+	if header.ScryptN > 1<<20 || header.ScryptR > 32 || header.ScryptP > 16 {
+		return nil, errors.New("Invalid key derivation parameters")
+	}
+
+	key, keyCheck, err := header.deriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare(keyCheck, header.KeyCheck) != 1 {
+		return nil, ErrInvalidPassphrase
+	}
+
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptionReader{
+		r:     bufReader,
+		aead:  aead,
+		chunk: make([]byte, header.ChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Read returns the decrypted data, decrypting the next chunk when needed.
+func (d *decryptionReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+
+		err := d.readChunk()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+
+	return n, nil
+}
+
+// readChunk reads and decrypts the next chunk.
+func (d *decryptionReader) readChunk() error {
+	n, err := io.ReadFull(d.r, d.chunk)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		if errors.Is(err, io.EOF) {
+			return errors.New("Encrypted data is truncated")
+		}
+
+		return err
+	}
+
+	// A short chunk is the final one, a full one only if nothing follows it.
+	final := n < len(d.chunk)
+	if !final {
+		_, err = d.r.Peek(1)
+		if errors.Is(err, io.EOF) {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := d.aead.Open(d.chunk[:0], encryptionNonce(d.counter, final), d.chunk[:n], nil)
+	if err != nil {
+		return errors.New("Encrypted data is corrupted or truncated")
+	}
+
+	d.buf = plain
+	d.counter++
+	d.done = final
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package archive
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+// encryptData returns data encrypted with the passphrase.
+func encryptData(t *testing.T, data []byte, passphrase string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w, err := NewEncryptionWriter(&buf, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, encryptionChunkSize - 1, encryptionChunkSize, encryptionChunkSize + 1, 3 * encryptionChunkSize} {
+		data := make([]byte, size)
+		_, _ = rand.Read(data)
+
+		encrypted := encryptData(t, data, "passphrase")
+		if !IsEncrypted(encrypted) {
+			t.Fatalf("Size %d: encrypted data isn't detected as such", size)
+		}
+
+		r, err := NewDecryptionReader(bytes.NewReader(encrypted), "passphrase")
+		if err != nil {
+			t.Fatalf("Size %d: %v", size, err)
+		}
+
+		decrypted, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Size %d: %v", size, err)
+		}
+
+		if !bytes.Equal(data, decrypted) {
+			t.Fatalf("Size %d: decrypted data doesn't match", size)
+		}
+	}
+}
+
+func TestEncryptionInvalidPassphrase(t *testing.T) {
+	encrypted := encryptData(t, []byte("data"), "passphrase")
+
+	_, err := NewDecryptionReader(bytes.NewReader(encrypted), "wrong passphrase")
+	if !errors.Is(err, ErrInvalidPassphrase) {
+		t.Fatalf("Expected ErrInvalidPassphrase, got %v", err)
+	}
+}
+
+func TestEncryptionTruncated(t *testing.T) {
+	data := make([]byte, 2*encryptionChunkSize+10)
+	encrypted := encryptData(t, data, "passphrase")
+
+	// Drop the final chunk, leaving only full chunks.
+	truncated := encrypted[:len(encrypted)-(10+16)]
+
+	r, err := NewDecryptionReader(bytes.NewReader(truncated), "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = io.ReadAll(r)
+	if err == nil {
+		t.Fatal("Expected an error reading truncated data")
+	}
+}
+
+func TestEncryptionShortPassphrase(t *testing.T) {
+	_, err := NewEncryptionWriter(io.Discard, "short")
+	if err == nil {
+		t.Fatal("Expected an error with a short passphrase")
+	}
+}
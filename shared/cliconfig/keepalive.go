@@ -22,9 +22,13 @@ func (c *Config) handleKeepAlive(remote Remote, name string, args *incus.Connect
 		return nil, err
 	}
 
+	// The local socket is always reached directly, the remote proxy is handled by the keepalive process.
+	localArgs := *args
+	localArgs.Proxy = nil
+
 	// Attempt to use the existing socket.
 	socketPath := filepath.Join(socketDir, fmt.Sprintf("%s.socket", name))
-	d, err := incus.ConnectIncusUnix(socketPath, args)
+	d, err := incus.ConnectIncusUnix(socketPath, &localArgs)
 	if err != nil {
 		// Delete any existing sockets.
 		_ = os.Remove(socketPath)
@@ -50,7 +54,7 @@ func (c *Config) handleKeepAlive(remote Remote, name string, args *incus.Connect
 		}
 
 		// Connect to the proxy.
-		d, err = incus.ConnectIncusUnix(socketPath, args)
+		d, err = incus.ConnectIncusUnix(socketPath, &localArgs)
 		if err != nil {
 			return nil, err
 		}
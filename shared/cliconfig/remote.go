@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"runtime"
 	"slices"
@@ -27,14 +29,92 @@ import (
 type Remote struct {
 	Addr      string `yaml:"addr"`
 	AuthType  string `yaml:"auth_type,omitempty"`
+	CACert    string `yaml:"ca_cert,omitempty"`
 	KeepAlive int    `yaml:"keepalive,omitempty"`
 	Project   string `yaml:"project,omitempty"`
 	Protocol  string `yaml:"protocol,omitempty"`
+	Proxy     string `yaml:"proxy,omitempty"`
 	Public    bool   `yaml:"public"`
 	Global    bool   `yaml:"-"`
 	Static    bool   `yaml:"-"`
 }
 
+// ParseProxy validates a remote proxy URL.
+// Supported schemes are http (using HTTP CONNECT) as well as socks5 and socks5h.
+// Proxies reached over TLS aren't supported as websocket connections can't go through them.
+// Credentials may be passed as part of the URL.
+func ParseProxy(value string) (*url.URL, error) {
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid proxy URL %q: %w", value, err)
+	}
+
+	if !slices.Contains([]string{"http", "socks5", "socks5h"}, proxyURL.Scheme) {
+		return nil, fmt.Errorf("Unsupported proxy scheme %q (must be http, socks5 or socks5h)", proxyURL.Scheme)
+	}
+
+	if proxyURL.Hostname() == "" {
+		return nil, fmt.Errorf("Invalid proxy URL %q: Missing host", value)
+	}
+
+	if proxyURL.Path != "" && proxyURL.Path != "/" {
+		return nil, fmt.Errorf("Invalid proxy URL %q: Unexpected path", value)
+	}
+
+	return proxyURL, nil
+}
+
+// ProxyFunc returns the proxy function to use when connecting to the remote.
+// A nil function is returned when no proxy is configured, in which case the environment is used.
+func (r Remote) ProxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	if r.Proxy == "" {
+		return nil, nil
+	}
+
+	proxyURL, err := ParseProxy(r.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.ProxyURL(proxyURL), nil
+}
+
+// LoadCACert reads a PEM bundle of CA certificates to use as the trust anchor of a remote.
+// It fails unless the bundle contains at least one valid certificate.
+func LoadCACert(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed reading CA certificate %q: %w", path, err)
+	}
+
+	found := false
+	rest := content
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		_, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("Invalid CA certificate in %q: %w", path, err)
+		}
+
+		found = true
+	}
+
+	if !found {
+		return "", fmt.Errorf("No CA certificate found in %q", path)
+	}
+
+	return string(content), nil
+}
+
 // ParseRemote splits remote and object.
 func (c *Config) ParseRemote(raw string) (string, string, error) {
 	result := strings.SplitN(raw, ":", 2)
@@ -246,6 +326,16 @@ func (c *Config) getConnectionArgs(name string) (*incus.ConnectionArgs, error) {
 		AuthType:  remote.AuthType,
 	}
 
+	// Per-remote proxy (not applicable to Unix sockets)
+	if !strings.HasPrefix(remote.Addr, "unix:") {
+		proxyFunc, err := remote.ProxyFunc()
+		if err != nil {
+			return nil, err
+		}
+
+		args.Proxy = proxyFunc
+	}
+
 	if args.AuthType == api.AuthenticationMethodOIDC {
 		if c.oidcTokens == nil {
 			c.oidcTokens = map[string]*oidc.Tokens[*oidc.IDTokenClaims]{}
@@ -281,6 +371,16 @@ func (c *Config) getConnectionArgs(name string) (*incus.ConnectionArgs, error) {
 		return &args, nil
 	}
 
+	// Custom CA to validate the server certificate against.
+	if remote.CACert != "" {
+		content, err := LoadCACert(remote.CACert)
+		if err != nil {
+			return nil, err
+		}
+
+		args.TLSCA = content
+	}
+
 	// Server certificate
 	if util.PathExists(c.ServerCertPath(name)) {
 		content, err := os.ReadFile(c.ServerCertPath(name))
@@ -320,8 +420,8 @@ func (c *Config) getConnectionArgs(name string) (*incus.ConnectionArgs, error) {
 		args.TLSClientCert = string(content)
 	}
 
-	// Client CA
-	if util.PathExists(pathClientCA) {
+	// Client CA (unless the remote has its own)
+	if args.TLSCA == "" && util.PathExists(pathClientCA) {
 		content, err := os.ReadFile(pathClientCA)
 		if err != nil {
 			return nil, err
@@ -21,6 +21,7 @@ import (
 	"math/big"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/user"
 	"path/filepath"
@@ -407,6 +408,12 @@ func CertFingerprintStr(c string) (string, error) {
 
 // GetRemoteCertificate gets the x509 certificate from a remote HTTPS server.
 func GetRemoteCertificate(address string, useragent string) (*x509.Certificate, error) {
+	return GetRemoteCertificateWithProxy(address, useragent, nil)
+}
+
+// GetRemoteCertificateWithProxy gets the x509 certificate from a remote HTTPS server using the provided proxy function.
+// When proxyFunc is nil, the proxy is taken from the environment.
+func GetRemoteCertificateWithProxy(address string, useragent string, proxyFunc func(req *http.Request) (*url.URL, error)) (*x509.Certificate, error) {
 	// Setup a permissive TLS config
 	tlsConfig, err := GetTLSConfig(nil)
 	if err != nil {
@@ -424,6 +431,11 @@ func GetRemoteCertificate(address string, useragent string) (*x509.Certificate,
 		TLSHandshakeTimeout:   time.Second * 5,
 	}
 
+	// Allow overriding the proxy
+	if proxyFunc != nil {
+		tr.Proxy = proxyFunc
+	}
+
 	// Connect
 	req, err := http.NewRequest("GET", address, nil)
 	if err != nil {
@@ -770,6 +770,29 @@ func IsDeviceName(name string) error {
 	return nil
 }
 
+// IsKernelModuleName checks name is a valid kernel module name, 1-55 characters long, doesn't start with a hyphen
+// and contains only alphanumeric, hyphen and underscore characters.
+func IsKernelModuleName(name string) error {
+	if len(name) < 1 || len(name) > 55 {
+		return fmt.Errorf("Name must be 1-55 characters long")
+	}
+
+	if strings.HasPrefix(name, "-") {
+		return fmt.Errorf(`Name must not start with "-" character`)
+	}
+
+	match, err := regexp.MatchString(`^[-_a-zA-Z0-9]+$`, name)
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		return fmt.Errorf("Name can only contain alphanumeric, hyphen and underscore characters")
+	}
+
+	return nil
+}
+
 // IsRequestURL checks value is a valid HTTP/HTTPS request URL.
 func IsRequestURL(value string) error {
 	if value == "" {
@@ -2,6 +2,7 @@ package validate_test
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/lxc/incus/v6/shared/validate"
 )
@@ -64,6 +65,33 @@ func ExampleIsPCIAddress() {
 	// , false
 }
 
+func ExampleIsKernelModuleName() {
+	tests := []string{
+		"overlay",
+		"nf_nat",
+		"ib-uverbs",
+		"",
+		"../overlay",            // path
+		"nf_nat,kvm",            // list
+		"-r",                    // option
+		strings.Repeat("a", 56), // too long
+	}
+
+	for _, v := range tests {
+		err := validate.IsKernelModuleName(v)
+		fmt.Printf("%s, %t\n", v, err == nil)
+	}
+
+	// Output: overlay, true
+	// nf_nat, true
+	// ib-uverbs, true
+	// , false
+	// ../overlay, false
+	// nf_nat,kvm, false
+	// -r, false
+	// aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa, false
+}
+
 func ExampleOptional() {
 	tests := []string{
 		"",
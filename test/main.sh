@@ -282,6 +282,8 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_container_devices_nic_ipvlan "container devices - nic - ipvlan"
     run_test test_container_devices_nic_sriov "container devices - nic - sriov"
     run_test test_container_devices_nic_routed "container devices - nic - routed"
+    run_test test_container_devices_nic_tuntap "container devices - nic - tuntap"
+    run_test test_container_network_namespace "container network namespace"
     run_test test_container_devices_infiniband_physical "container devices - infiniband - physical"
     run_test test_container_devices_infiniband_sriov "container devices - infiniband - sriov"
     run_test test_container_devices_proxy "container devices - proxy"
@@ -298,34 +300,44 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_image_list_all_aliases "image list all aliases"
     run_test test_image_auto_update "image auto-update"
     run_test test_image_prefer_cached "image prefer cached"
+    run_test test_image_mirror "image mirror"
     run_test test_image_import_dir "import image from directory"
     run_test test_image_import_with_reuse "import image with reuse flag"
     run_test test_image_refresh "image refresh"
     run_test test_image_split "image split"
+    run_test test_image_build "image build"
     run_test test_image_acl "image acl"
     run_test test_cloud_init "cloud-init"
     run_test test_exec "exec"
     run_test test_exec_exit_code "exec exit code"
     run_test test_concurrent_exec "concurrent exec"
     run_test test_concurrent "concurrent startup"
+    run_test test_container_restart_policy "container restart policy"
     run_test test_snapshots "container snapshots"
     run_test test_snap_restore "snapshot restores"
     run_test test_snap_expiry "snapshot expiry"
     run_test test_snap_schedule "snapshot scheduling"
+    run_test test_snap_disabled "snapshot disabling"
     run_test test_snap_volume_db_recovery "snapshot volume database record recovery"
     run_test test_config_profiles "profiles and configuration"
+    run_test test_config_profiles_usage "profiles usage"
+    run_test test_config_profiles_from_instance "profiles from instance"
     run_test test_config_edit "container configuration edit"
+    run_test test_config_validate "configuration validation"
     run_test test_property "container property"
     run_test test_config_edit_container_snapshot_pool_config "container and snapshot volume configuration edit"
     run_test test_container_metadata "manage container metadata and templates"
     run_test test_container_snapshot_config "container snapshot configuration"
     run_test test_server_config "server configuration"
+    run_test test_event_history "event history replay"
     run_test test_filemanip "file manipulations"
     run_test test_network "network management"
     run_test test_network_dhcp_routes "network dhcp routes"
+    run_test test_network_bond "network bond"
     run_test test_network_acl "network ACL management"
     run_test test_address_set "network address set"
     run_test test_network_forward "network address forwards"
+    run_test test_network_forward_http "network address forwards - http"
     run_test test_network_zone "network DNS zones"
     run_test test_idmap "id mapping"
     run_test test_template "file templating"
@@ -354,8 +366,14 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_storage_bucket_export "storage buckets export and import"
     run_test test_storage_volume_import "storage volume import"
     run_test test_storage_volume_initial_config "storage volume initial configuration"
+    run_test test_storage_volume_convert "storage volume content type conversion"
+    run_test test_storage_volume_soft_limit "storage volume soft limit"
+    run_test test_storage_pool_migrate "storage pool migration"
     run_test test_resources "resources"
     run_test test_kernel_limits "kernel limits"
+    run_test test_container_raw_cgroup "container raw cgroup"
+    run_test test_container_memory_limits "container memory limits"
+    run_test test_container_apparmor "container AppArmor customization"
     run_test test_console "console"
     run_test test_query "query"
     run_test test_storage_local_volume_handling "storage local volume handling"
@@ -364,6 +382,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_backup_rename "backup rename"
     run_test test_backup_volume_export "backup volume export"
     run_test test_backup_export_import_instance_only "backup export and import instance only"
+    run_test test_backup_import_selective "backup selective import"
     run_test test_backup_volume_rename_delete "backup volume rename and delete"
     run_test test_backup_different_instance_uuid "backup instance and check instance UUIDs"
     run_test test_backup_volume_expiry "backup volume expiry"
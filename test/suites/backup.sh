@@ -1022,3 +1022,48 @@ test_backup_export_import_instance_only() {
   rm "${INCUS_DIR}/c1.tar.gz"
   incus delete -f c1
 }
+
+test_backup_import_selective() {
+  poolName=$(incus profile device get default root pool)
+
+  ensure_import_testimage
+  ensure_has_localhost_remote "${INCUS_ADDR}"
+
+  # Create an instance with multiple snapshots.
+  incus init testimage c1
+  incus config set c1 user.foo=bar
+  incus snapshot create c1 snap0
+  incus snapshot create c1 snap1
+  incus snapshot create c1 snap2
+
+  incus export c1 "${INCUS_DIR}/c1.tar.gz"
+  incus delete -f c1
+
+  # Conflicting selections and missing snapshots are rejected.
+  ! incus import "${INCUS_DIR}/c1.tar.gz" --config-only --snapshot snap0 || false
+  ! incus import "${INCUS_DIR}/c1.tar.gz" --instance-only --snapshot snap0 || false
+  ! incus import "${INCUS_DIR}/c1.tar.gz" --snapshot snap3 || false
+  ! incus info c1 || false
+
+  # Restore a single snapshot.
+  incus import "${INCUS_DIR}/c1.tar.gz" --snapshot snap1
+  [ "$(incus query "/1.0/instances/c1/snapshots?recursion=1" | jq -r '.[].name')" = "snap1" ]
+  [ "$(incus query "/1.0/storage-pools/${poolName}/volumes/container/c1/snapshots" | jq "length == 1")" = "true" ]
+  incus start c1
+  incus delete -f c1
+
+  # Restore without snapshots.
+  incus import "${INCUS_DIR}/c1.tar.gz" --instance-only
+  [ "$(incus query "/1.0/instances/c1/snapshots" | jq "length == 0")" = "true" ]
+  [ "$(incus query "/1.0/storage-pools/${poolName}/volumes/container/c1/snapshots" | jq "length == 0")" = "true" ]
+  incus delete -f c1
+
+  # Restore only the config.
+  incus import "${INCUS_DIR}/c1.tar.gz" --config-only
+  [ "$(incus config get c1 user.foo)" = "bar" ]
+  [ "$(incus query "/1.0/instances/c1/snapshots" | jq "length == 0")" = "true" ]
+  ! incus file pull c1/etc/hostname - || false
+  incus delete -f c1
+
+  rm "${INCUS_DIR}/c1.tar.gz"
+}
@@ -140,6 +140,52 @@ test_basic_usage() {
   incus copy bar foo
   incus delete foo
 
+  # Test that copies get a new identity unless --keep-identity is passed
+  srcID="$(incus config get bar volatile.cloud-init.instance-id)"
+  incus config set bar volatile.eth0.hwaddr=10:66:6a:00:00:01
+  incus copy bar foo
+  [ "$(incus config get foo volatile.cloud-init.instance-id)" != "${srcID}" ]
+  [ "$(incus config get foo volatile.eth0.hwaddr)" = "" ]
+  incus delete foo
+  incus copy bar foo --keep-identity
+  [ "$(incus config get foo volatile.cloud-init.instance-id)" = "${srcID}" ]
+  [ "$(incus config get foo volatile.eth0.hwaddr)" = "10:66:6a:00:00:01" ]
+  incus delete foo
+  incus config unset bar volatile.eth0.hwaddr
+
+  # Test that stateful copies are refused for containers and snapshots
+  ! incus copy bar foo --stateful || false
+  ! incus copy bar foo --stateful --stateless || false
+  incus snapshot create bar snap0
+  ! incus copy bar/snap0 foo --stateful || false
+  incus snapshot delete bar snap0
+  ! incus list -c n --format csv | grep -x foo || false
+
+  # Test stateful copies of virtual machines when the server supports them
+  if incus info | grep -q '^  driver: .*qemu'; then
+    incus init --empty --vm vm1 -c migration.stateful=true -c security.secureboot=false
+    incus start vm1
+
+    # Stateful copies require snapshots to be enabled
+    incus config set vm1 snapshots.enabled=false
+    ! incus copy vm1 vm2 --stateful || false
+    incus config unset vm1 snapshots.enabled
+
+    incus copy vm1 vm2 --stateful
+    [ "$(incus list -c s --format csv vm2)" = "RUNNING" ]
+    [ "$(incus list -c s --format csv vm1)" = "RUNNING" ]
+    [ "$(incus config get vm1 volatile.uuid)" != "$(incus config get vm2 volatile.uuid)" ]
+    if [ -n "$(incus config get vm1 volatile.eth0.hwaddr)" ]; then
+      [ "$(incus config get vm1 volatile.eth0.hwaddr)" != "$(incus config get vm2 volatile.eth0.hwaddr)" ]
+    fi
+
+    # The temporary snapshot is removed and the copy has no snapshots
+    [ "$(incus query /1.0/instances/vm1/snapshots | jq length)" = "0" ]
+    [ "$(incus query /1.0/instances/vm2/snapshots | jq length)" = "0" ]
+
+    incus delete -f vm1 vm2
+  fi
+
   # gen untrusted cert
   gen_cert client3
 
@@ -416,8 +462,11 @@ test_basic_usage() {
   # Test last_used_at field is working properly
   incus init testimage last-used-at-test
   incus list last-used-at-test  --format json | jq -r '.[].last_used_at' | grep '1970-01-01T00:00:00Z'
+  incus list last-used-at-test last_started_before=1h --format csv -c n | grep -Fx last-used-at-test
   incus start last-used-at-test
   incus list last-used-at-test  --format json | jq -r '.[].last_used_at' | grep -v '1970-01-01T00:00:00Z'
+  [ "$(incus list last-used-at-test --format json | jq -r '.[].last_started_at')" = "$(incus list last-used-at-test --format json | jq -r '.[].last_used_at')" ]
+  [ -z "$(incus list last-used-at-test last_started_before=1h --format csv -c n)" ]
   incus delete last-used-at-test --force
 
   # Test user, group and cwd
@@ -247,6 +247,11 @@ test_clustering_membership() {
   INCUS_DIR="${INCUS_THREE_DIR}" incus cluster list | grep -Fc "database-standby" | grep -Fx 2
   INCUS_DIR="${INCUS_FIVE_DIR}" incus cluster list | grep -Fc "database " | grep -Fx 3
 
+  # Check the database status.
+  INCUS_DIR="${INCUS_TWO_DIR}" incus cluster database status -f csv | grep -Fc ",VOTER," | grep -Fx 3
+  INCUS_DIR="${INCUS_TWO_DIR}" incus cluster database status -f csv | grep -Fc ",STAND-BY," | grep -Fx 2
+  INCUS_DIR="${INCUS_FOUR_DIR}" incus cluster database status -f csv | grep "^node1," | grep -q ",YES,ONLINE$"
+
   # Show a single node
   INCUS_DIR="${INCUS_TWO_DIR}" incus cluster show node5 | grep -q "node5"
 
@@ -2660,6 +2665,7 @@ test_clustering_failure_domains() {
   printf "roles: []\nfailure_domain: \"az3\"\ngroups: [\"default\"]" | INCUS_DIR="${INCUS_THREE_DIR}" incus cluster edit node6
 
   INCUS_DIR="${INCUS_ONE_DIR}" incus cluster show node2 | grep -q "failure_domain: az2"
+  INCUS_DIR="${INCUS_ONE_DIR}" incus cluster info node2 | grep -q "failure_domain: az2"
 
   # Shutdown a node in az2, its replacement is picked from az2.
   INCUS_DIR="${INCUS_TWO_DIR}" incus admin shutdown
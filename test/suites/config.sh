@@ -148,6 +148,20 @@ test_config_profiles() {
   incus profile remove foo one
   [ "$(incus list -f json foo | jq -r '.[0].profiles | join(" ")')" = "" ]
 
+  # check high priority profile keys
+  incus profile set one user.prio=one
+  incus profile set two user.prio=two
+  incus profile set one profile.priority_keys=user.prio
+  incus profile assign foo one,two
+  [ "$(incus config get foo user.prio --expanded)" = "one" ]
+  ! incus profile set two profile.priority_keys=user.prio || false
+  ! incus profile set two profile.priority_keys=user.missing || false
+  ! incus config set foo profile.priority_keys=user.prio || false
+  incus profile assign foo ""
+  incus profile unset one profile.priority_keys
+  incus profile unset one user.prio
+  incus profile unset two user.prio
+
   # check that we can create a profile with a description
   incus profile create foo --description bar
   incus profile ls | grep -q bar
@@ -271,6 +285,77 @@ test_config_profiles() {
   incus delete foo
 }
 
+test_config_profiles_usage() {
+  ensure_import_testimage
+
+  incus profile create shared
+  incus profile create unused
+
+  # Create instances sharing the profile, including one in a project using the default project's profiles.
+  incus init testimage u1 -p default -p shared
+  incus init testimage u2 -p default -p shared
+  incus init testimage u3
+  incus project create usage -c features.images=false -c features.profiles=false
+  incus init testimage u4 -p default -p shared --project usage
+
+  # Check the instances using the profile are listed.
+  [ "$(incus profile list-instances shared -f csv -c n | sort | xargs)" = "u1 u2 u4" ]
+  incus profile list-instances shared -f csv -c ne | grep -xF "u4,usage"
+  incus profile list-instances shared -f json | jq -e 'length == 3'
+  [ "$(incus profile list-instances unused -f csv)" = "" ]
+  ! incus profile list-instances shared -c nX || false
+  ! incus profile list-instances missing || false
+
+  # Check deleting the profile lists the instances using it.
+  OUTPUT="$(! incus profile delete shared 2>&1)"
+  echo "${OUTPUT}" | grep -F "Profile is currently in use by 3 instance(s)"
+  echo "${OUTPUT}" | grep -F "u1"
+  echo "${OUTPUT}" | grep -F "u4 (project \"usage\")"
+
+  # Check the listing follows profile removal.
+  incus profile remove u2 shared
+  [ "$(incus profile list-instances shared -f csv -c n | sort | xargs)" = "u1 u4" ]
+
+  incus delete u1 u2 u3
+  incus delete u4 --project usage
+  incus project delete usage
+  incus profile delete shared
+  incus profile delete unused
+}
+
+test_config_profiles_from_instance() {
+  ensure_import_testimage
+
+  incus init testimage tuned -c limits.cpu=1 -c user.foo=bar
+  incus config device add tuned eth1 nic nictype=p2p hwaddr=00:16:3e:00:00:01 host_name=tunedeth1
+
+  # Check the instance configuration is copied, without the volatile and instance specific keys.
+  incus profile create fromtuned --from-instance tuned
+  incus profile get fromtuned limits.cpu | grep -xF 1
+  incus profile get fromtuned user.foo | grep -xF bar
+  incus profile device get fromtuned eth1 nictype | grep -xF p2p
+  [ "$(incus profile device get fromtuned eth1 hwaddr)" = "" ]
+  [ "$(incus profile device get fromtuned eth1 host_name)" = "" ]
+  incus config show tuned | grep -q "volatile\."
+  ! incus profile show fromtuned | grep -q "volatile\." || false
+  ! incus profile show fromtuned | grep -q "image\." || false
+
+  # Check the instance specific keys can be kept.
+  incus profile create fromtuned-full --from-instance tuned --include-instance-specific
+  incus profile device get fromtuned-full eth1 hwaddr | grep -xF 00:16:3e:00:00:01
+  ! incus profile show fromtuned-full | grep -q "volatile\." || false
+
+  # Check the resulting profile can be used to create an instance.
+  incus init testimage reused -p fromtuned
+  incus config show reused --expanded | grep -F "limits.cpu: \"1\""
+
+  ! incus profile create missing --from-instance missing || false
+  ! incus profile create fromtuned-invalid --include-instance-specific || false
+
+  incus delete tuned reused
+  incus profile delete fromtuned
+  incus profile delete fromtuned-full
+}
 
 test_config_edit() {
     if ! tty -s; then
@@ -295,6 +380,34 @@ test_config_edit() {
     incus delete foo
 }
 
+test_config_validate() {
+    ensure_import_testimage
+
+    # Check a valid configuration is accepted.
+    printf 'profiles: []\nconfig:\n  limits.cpu: "1"\n' | incus config validate foo
+
+    # Check errors are reported against the offending keys and devices.
+    output="$(printf 'profiles: []\nconfig:\n  limits.cpu: abc\n  user.foo: bar\n' | incus config validate foo || true)"
+    echo "${output}" | grep -q 'config "limits.cpu"'
+    ! echo "${output}" | grep -q 'user.foo' || false
+
+    output="$(printf 'profiles: []\ndevices:\n  eth0:\n    type: nic\n    nictype: bogus\n' | incus config validate foo || true)"
+    echo "${output}" | grep -q 'devices "eth0"'
+
+    # Check an existing instance isn't modified.
+    incus init testimage foo -s "incustest-$(basename "${INCUS_DIR}")"
+    incus config show foo | sed 's/^config:$/config:\n  limits.cpu: "2"/' | incus config validate foo
+    [ "$(incus config get foo limits.cpu)" = "" ]
+    ! printf 'config:\n  limits.cpu: abc\n' | incus config validate foo || false
+    incus delete foo
+
+    # Check the other entities.
+    incus profile show default | incus config validate --entity=profile default
+    ! printf 'config:\n  boot.autostart: maybe\n' | incus config validate --entity=profile default || false
+    ! printf 'driver: dir\nconfig:\n  bogus: value\n' | incus config validate --entity=storage-pool newpool || false
+    ! incus storage list -f csv | grep -q '^newpool,' || false
+}
+
 test_property() {
   ensure_import_testimage
 
@@ -400,6 +513,19 @@ test_container_metadata() {
     incus config template delete c my.tpl
     ! incus config template list c | grep -q my.tpl || false
 
+    # template triggers can be set
+    echo "{{ instance.name }}" | incus config template create c name.tpl --path /etc/instance-name --when create,start
+    incus config template list c --format csv | grep -q "^name.tpl,/etc/instance-name,\"create, start\"$"
+    incus config metadata show c | grep -q "template: name.tpl"
+
+    # invalid templates are rejected
+    ! echo "{{ instance.name" | incus config template create c broken.tpl || false
+    ! echo "ok" | incus config template create c broken.tpl --path /etc/broken --when reboot || false
+
+    # removing a template removes its triggers
+    incus config template delete c name.tpl
+    ! incus config metadata show c | grep -q "template: name.tpl" || false
+
     incus delete c
 }
 
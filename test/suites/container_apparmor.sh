@@ -0,0 +1,62 @@
+test_container_apparmor() {
+  if [ ! -e /sys/module/apparmor/ ] || ! command -v apparmor_parser >/dev/null; then
+    echo "==> SKIP: AppArmor customization tests (missing kernel support)"
+    return
+  fi
+
+  ensure_import_testimage
+
+  # Rules from raw.apparmor are appended to the generated profile and applied to running containers.
+  incus launch testimage c1
+  incus exec c1 -- touch /tmp/denied-raw
+  incus config set c1 raw.apparmor="deny /tmp/denied-raw w,"
+  grep -qF "deny /tmp/denied-raw w," "${INCUS_DIR}/security/apparmor/profiles/incus-c1"
+  ! incus exec c1 -- touch /tmp/denied-raw || false
+  ! incus config set c1 raw.apparmor="invalid rule" || false
+  incus config unset c1 raw.apparmor
+  incus exec c1 -- touch /tmp/denied-raw
+
+  # Custom profiles must be loaded.
+  ! incus config set c1 security.apparmor.profile=incus-test-missing || false
+
+  cat > "${TEST_DIR}/custom.profile" << EOF
+profile incus-test-custom flags=(attach_disconnected,mediate_deleted) {
+  capability,
+  change_profile,
+  file,
+  mount,
+  network,
+  pivot_root,
+  ptrace,
+  signal,
+  umount,
+  unix,
+
+  deny /tmp/denied-custom w,
+}
+EOF
+  apparmor_parser -r "${TEST_DIR}/custom.profile"
+
+  # Custom profiles can't be combined with raw.apparmor.
+  ! incus config set c1 security.apparmor.profile=incus-test-custom raw.apparmor="deny /tmp/foo w," || false
+  incus config set c1 security.apparmor.profile=incus-test-custom
+
+  # The custom profile is used once the container restarts.
+  incus exec c1 -- touch /tmp/denied-custom
+  incus restart c1 --force
+  [ "$(incus exec c1 -- cat /proc/self/attr/current)" = "incus-test-custom (enforce)" ]
+  ! incus exec c1 -- touch /tmp/denied-custom || false
+
+  # The container can't start once the custom profile is unloaded.
+  incus stop c1 --force
+  apparmor_parser -R "${TEST_DIR}/custom.profile"
+  ! incus start c1 || false
+
+  # Going back to the generated profile.
+  incus config unset c1 security.apparmor.profile
+  incus start c1
+  incus exec c1 -- touch /tmp/denied-custom
+
+  incus delete -f c1
+  rm "${TEST_DIR}/custom.profile"
+}
@@ -11,6 +11,7 @@ test_container_devices_disk() {
   test_container_devices_disk_cephfs
   test_container_devices_disk_socket
   test_container_devices_disk_char
+  test_container_devices_disk_media
 
   incus delete -f foo
 }
@@ -117,6 +118,17 @@ test_container_devices_raw_mount_options() {
   ! incus exec foo-priv -- touch /mnt/foo || false
   incus config device remove foo-priv loop_raw_mount_options
 
+  # Mount options of custom volumes, applied live.
+  incus storage volume create "$(incus profile device get default root pool)" vol-opts
+  incus config device add foo-priv vol-opts disk pool="$(incus profile device get default root pool)" source=vol-opts path=/opts raw.mount.options=noexec,discard
+  incus exec foo-priv -- grep -q " /opts .*noexec" /proc/mounts
+  incus config device set foo-priv vol-opts raw.mount.options=nosuid
+  incus exec foo-priv -- grep " /opts " /proc/mounts | grep -q nosuid
+  ! incus exec foo-priv -- grep " /opts " /proc/mounts | grep -q noexec || false
+  ! incus config device set foo-priv vol-opts raw.mount.options=uid=123 || false
+  incus config device remove foo-priv vol-opts
+  incus storage volume delete "$(incus profile device get default root pool)" vol-opts
+
   incus delete -f foo-priv
   # shellcheck disable=SC2154
   deconfigure_loop_device "${loop_file_1}" "${loop_device_1}"
@@ -184,6 +196,55 @@ test_container_devices_disk_char() {
   incus stop foo -f
 }
 
+test_container_devices_disk_media() {
+  # Removable media is only available to virtual machines.
+  ! incus config device add foo cdrom disk media=cdrom || false
+  ! incus config device add foo cdrom disk media=floppy source=/dev/zero path=/root/zero || false
+
+  # Profiles can hold empty removable drives.
+  incus profile create media
+  incus profile device add media cdrom disk media=cdrom
+  ! incus profile device set media cdrom path=/mnt || false
+  ! incus profile device set media cdrom io.bus=virtio-blk || false
+  ! incus profile device set media cdrom pool=default || false
+  ! incus profile device set media cdrom source=cloud-init:config || false
+  incus profile delete media
+
+  if incus info | grep -q '^  driver: .*qemu'; then
+    # Fake ISO images, QEMU only needs the files to be readable.
+    for iso in a b; do
+      truncate -s 1M "${TEST_DIR}/${iso}.iso"
+      printf 'CD001' | dd of="${TEST_DIR}/${iso}.iso" bs=1 seek=32769 conv=notrunc
+    done
+
+    truncate -s 1M "${TEST_DIR}/not-an.iso"
+
+    incus init --empty --vm v1 -c security.secureboot=false
+    incus config device add v1 cdrom disk media=cdrom source="${TEST_DIR}/a.iso"
+    incus start v1
+
+    # Change the medium of the running virtual machine, back and forth to use both block node names.
+    incus config device set v1 cdrom source="${TEST_DIR}/b.iso"
+    incus config device set v1 cdrom source="${TEST_DIR}/a.iso"
+    [ "$(incus list -c s --format csv v1)" = "RUNNING" ]
+
+    # Invalid media are refused and leave the current one in place.
+    ! incus config device set v1 cdrom source="${TEST_DIR}/not-an.iso" || false
+    [ "$(incus config device get v1 cdrom source)" = "${TEST_DIR}/a.iso" ]
+
+    # Eject and insert again.
+    incus config device unset v1 cdrom source
+    incus config device set v1 cdrom source="${TEST_DIR}/b.iso"
+
+    # The drive can still be removed.
+    incus config device remove v1 cdrom
+    [ "$(incus list -c s --format csv v1)" = "RUNNING" ]
+
+    incus delete -f v1
+    rm "${TEST_DIR}/a.iso" "${TEST_DIR}/b.iso" "${TEST_DIR}/not-an.iso"
+  fi
+}
+
 test_container_devices_disk_subpath() {
   POOL=$(incus profile device get default root pool)
 
@@ -474,6 +474,24 @@ test_container_devices_nic_bridged() {
     false
   fi
 
+  # Check dnsmasq host file and snapshots follow an instance rename.
+  incus snapshot create "${ctName}" snap0
+  incus rename "${ctName}" "${ctName}-renamed"
+
+  if [ -f "${INCUS_DIR}/networks/${brName}/dnsmasq.hosts/${ctName}.eth0" ] ; then
+    echo "dnsmasq host config file not removed on rename"
+    false
+  fi
+
+  if ! grep "192.0.2.200.*,${ctName}-renamed$" "${INCUS_DIR}/networks/${brName}/dnsmasq.hosts/${ctName}-renamed.eth0" ; then
+    echo "dnsmasq host config not updated with new instance name"
+    false
+  fi
+
+  incus snapshot show "${ctName}-renamed" snap0
+  incus rename "${ctName}-renamed" "${ctName}"
+  incus snapshot delete "${ctName}" snap0
+
   incus config device remove "${ctName}" eth0
 
   if grep "192.0.2.200" "${INCUS_DIR}/networks/${brName}/dnsmasq.hosts/${ctName}.eth0" ; then
@@ -633,8 +651,19 @@ test_container_devices_nic_bridged() {
     ipv4.address=192.0.2.232 \
     hwaddr="" # Remove static MAC so that copies use new MAC (as changing MAC triggers device remove/add on snapshot restore).
   grep -F "192.0.2.232" "${INCUS_DIR}/networks/${brName}/dnsmasq.hosts/${ctName}.eth0"
-  incus copy "${ctName}" foo # Gets new MAC address but IPs still conflict.
+  incus copy "${ctName}" foo 2> "${TEST_DIR}/copy.err" # Gets new MAC address but IPs still conflict.
+  grep -F "192.0.2.232" "${TEST_DIR}/copy.err"
+  ! grep -F "MAC address" "${TEST_DIR}/copy.err" || false
+  rm "${TEST_DIR}/copy.err"
   ! stat "${INCUS_DIR}/networks/${brName}/dnsmasq.hosts/foo.eth0" || false
+  incus query "/1.0/warnings?recursion=1" | jq -r '.[] | select(.status == "new") | .last_message' | grep -F "192.0.2.232"
+
+  # Copies keeping the identity of their source share its MAC address.
+  incus copy "${ctName}" foo2 --keep-identity 2> "${TEST_DIR}/copy.err"
+  grep -F "MAC address $(incus config get "${ctName}" volatile.eth0.hwaddr)" "${TEST_DIR}/copy.err"
+  rm "${TEST_DIR}/copy.err"
+  incus delete foo2
+
   incus snapshot create foo
   incus export foo foo.tar.gz
   ! incus start foo || false
@@ -643,6 +672,8 @@ test_container_devices_nic_bridged() {
     ipv6.address=2001:db8::3
   grep -F "192.0.2.233" "${INCUS_DIR}/networks/${brName}/dnsmasq.hosts/foo.eth0"
   incus start foo
+  [ "$(incus config get foo volatile.eth0.hwaddr)" != "$(incus config get "${ctName}" volatile.eth0.hwaddr)" ]
+  ! incus query "/1.0/warnings?recursion=1" | jq -r '.[] | select(.status == "new") | .last_message' | grep -F "192.0.2.232" || false
   incus stop -f foo
 
   # Test container snapshot with conflicting addresses can be restored.
@@ -156,6 +156,12 @@ test_container_devices_nic_bridged_acl() {
   ! incus exec "${ctPrefix}A" -- ping -c2 -4 -W5 192.0.2.1 || false
   ! incus exec "${ctPrefix}A" -- ping -c2 -6 -W5 2001:db8::1 || false
 
+  # Check a higher priority allow rule is evaluated before the drop rules.
+  incus network acl rule add "${brName}A" egress action=allow destination=192.0.2.1/32 protocol=icmp4 icmp_type=8 priority=10
+  incus exec "${ctPrefix}A" -- ping -c2 -4 -W5 192.0.2.1
+  incus network acl rule remove "${brName}A" egress priority=10
+  ! incus exec "${ctPrefix}A" -- ping -c2 -4 -W5 192.0.2.1 || false
+
   # Check ingress ICMPv4 ping is blocked.
   ! ping -c1 -4 192.0.2.2 || false
 
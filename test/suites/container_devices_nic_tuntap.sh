@@ -0,0 +1,49 @@
+test_container_devices_nic_tuntap() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${INCUS_ADDR}"
+
+  ctName="nt$$"
+
+  # Record how many nics we started with.
+  startNicCount=$(find /sys/class/net | wc -l)
+
+  incus init testimage "${ctName}"
+
+  # Check invalid configuration is rejected.
+  ! incus config device add "${ctName}" tun0 nic nictype=tuntap name=tun0 mode=invalid || false
+  ! incus config device add "${ctName}" tun0 nic nictype=tuntap name=tun0 hwaddr=00:16:3e:00:00:01 || false
+
+  # Check the device node used to attach to the interfaces is present.
+  incus start "${ctName}"
+  incus exec "${ctName}" -- test -c /dev/net/tun
+
+  # Test hot plugging a tun interface.
+  incus config device add "${ctName}" tun0 nic nictype=tuntap name=tun0 host_name="${ctName}tun" mtu=1400
+  incus exec "${ctName}" -- ip link show tun0 | grep -F "mtu 1400"
+  incus exec "${ctName}" -- ip -d link show tun0 | grep -F "tun type tun"
+  ! ip link show "${ctName}tun" || false
+
+  # Test hot plugging a tap interface with a custom MAC address.
+  incus config device add "${ctName}" tap0 nic nictype=tuntap name=tap0 mode=tap hwaddr=00:16:3e:00:00:01
+  incus exec "${ctName}" -- ip link show tap0 | grep -F "00:16:3e:00:00:01"
+  incus exec "${ctName}" -- ip -d link show tap0 | grep -F "tun type tap"
+
+  # Check the interfaces are removed from the host on hot unplug.
+  incus config device remove "${ctName}" tun0
+  ! incus exec "${ctName}" -- ip link show tun0 || false
+  ! ip link show "${ctName}tun" || false
+
+  # Check the interfaces are removed from the host on stop.
+  incus stop -f "${ctName}"
+  incus start "${ctName}"
+  incus exec "${ctName}" -- ip -d link show tap0 | grep -F "tun type tap"
+  incus stop -f "${ctName}"
+  incus delete -f "${ctName}"
+
+  # Check we haven't left any NICS lying around.
+  endNicCount=$(find /sys/class/net | wc -l)
+  if [ "$startNicCount" != "$endNicCount" ]; then
+    echo "leftover NICS detected"
+    false
+  fi
+}
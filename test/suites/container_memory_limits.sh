@@ -0,0 +1,62 @@
+test_container_memory_limits() {
+  if [ ! -e "/sys/fs/cgroup/cgroup.controllers" ]; then
+    echo "==> SKIP: memory limit modes require cgroup v2"
+    return
+  fi
+
+  ensure_import_testimage
+
+  # Check invalid combinations are rejected.
+  ! incus init testimage c1 -c limits.memory.enforce=soft || false
+  ! incus init testimage c1 -c limits.memory.swap=128MiB || false
+
+  # Check hard enforcement.
+  incus init testimage c1 -c limits.memory=256MiB
+  incus start c1
+  cgroupPath="/sys/fs/cgroup/lxc.payload.c1"
+  [ "$(cat "${cgroupPath}/memory.max")" = "268435456" ]
+  [ "$(cat "${cgroupPath}/memory.high")" = "max" ]
+
+  if [ -e "${cgroupPath}/memory.swap.max" ]; then
+    [ "$(cat "${cgroupPath}/memory.swap.max")" = "0" ]
+  fi
+
+  # Check soft enforcement on live update.
+  incus config set c1 limits.memory.enforce=soft
+  [ "$(cat "${cgroupPath}/memory.max")" = "max" ]
+  [ "$(cat "${cgroupPath}/memory.high")" = "268435456" ]
+
+  if [ -e "${cgroupPath}/memory.swap.max" ]; then
+    [ "$(cat "${cgroupPath}/memory.swap.max")" = "max" ]
+
+    incus config set c1 limits.memory.swap=false
+    [ "$(cat "${cgroupPath}/memory.swap.max")" = "0" ]
+    incus config unset c1 limits.memory.swap
+  fi
+
+  # Check swap sizes, when the host has swap.
+  if [ -e "${cgroupPath}/memory.swap.max" ] && [ "$(awk '/^SwapTotal:/ {print $2}' /proc/meminfo)" != "0" ]; then
+    incus config set c1 limits.memory.swap=128MiB
+    [ "$(cat "${cgroupPath}/memory.swap.max")" = "134217728" ]
+
+    incus config set c1 limits.memory.enforce=hard
+    [ "$(cat "${cgroupPath}/memory.max")" = "268435456" ]
+    [ "$(cat "${cgroupPath}/memory.swap.max")" = "134217728" ]
+
+    # Check the values are applied on start.
+    incus restart c1 -f
+    [ "$(cat "${cgroupPath}/memory.max")" = "268435456" ]
+    [ "$(cat "${cgroupPath}/memory.swap.max")" = "134217728" ]
+  else
+    ! incus config set c1 limits.memory.swap=128MiB || false
+  fi
+
+  # Check removing the limit resets the cgroup.
+  incus config unset c1 limits.memory.swap
+  incus config unset c1 limits.memory.enforce
+  incus config unset c1 limits.memory
+  [ "$(cat "${cgroupPath}/memory.max")" = "max" ]
+  [ "$(cat "${cgroupPath}/memory.high")" = "max" ]
+
+  incus delete -f c1
+}
@@ -0,0 +1,41 @@
+test_container_network_namespace() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${INCUS_ADDR}"
+
+  nsName="incusns$$"
+  ctName="nsct$$"
+
+  ip netns add "${nsName}"
+  ip netns exec "${nsName}" ip link add dummy0 type dummy
+
+  # Validation of the key.
+  ! incus init testimage "${ctName}" -c linux.network_namespace=foo/bar || false
+  ! incus init testimage "${ctName}" -c linux.network_namespace=/run/netns/../foo || false
+  ! incus init testimage "${ctName}" -c linux.network_namespace=/proc/1/ns/net || false
+
+  # The container can't be started with NIC devices.
+  incus init testimage "${ctName}" -c linux.network_namespace="${nsName}"
+  incus config device add "${ctName}" eth0 nic nictype=p2p
+  ! incus start "${ctName}" || false
+  incus config device remove "${ctName}" eth0
+
+  # The container can't be started if the namespace doesn't exist.
+  incus config set "${ctName}" linux.network_namespace=missing
+  ! incus start "${ctName}" || false
+
+  # The container joins the existing network namespace.
+  incus config set "${ctName}" linux.network_namespace="${nsName}"
+  incus profile device list default | grep -q eth0 && incus config device add "${ctName}" eth0 none
+  incus start "${ctName}"
+  incus exec "${ctName}" -- ip link show dummy0
+
+  # NIC devices can't be hotplugged.
+  ! incus config device add "${ctName}" eth1 nic nictype=p2p || false
+
+  # The namespace is left alone when the container stops and is deleted.
+  incus delete -f "${ctName}"
+  [ -e "/run/netns/${nsName}" ]
+  ip netns exec "${nsName}" ip link show dummy0
+
+  ip netns delete "${nsName}"
+}
@@ -0,0 +1,38 @@
+test_container_raw_cgroup() {
+  if [ ! -e "/sys/fs/cgroup/cgroup.controllers" ]; then
+    echo "==> SKIP: raw.cgroup requires cgroup v2"
+    return
+  fi
+
+  ensure_import_testimage
+
+  # Check invalid entries are rejected.
+  ! incus init testimage c1 -c raw.cgroup="cgroup.procs = 1" || false
+  ! incus init testimage c1 -c raw.cgroup="cpuset.cpus = 0" || false
+  ! incus init testimage c1 -c raw.cgroup="memory.high" || false
+  ! incus init testimage c1 -c raw.cgroup="memory.high =" || false
+
+  # Check the values are applied on start, after the generated limits.
+  incus init testimage c1 -c limits.memory=256MiB -c raw.cgroup="$(printf 'memory.high = 134217728\n# Comment\npids.max = 100')"
+  incus start c1
+  cgroupPath="/sys/fs/cgroup/lxc.payload.c1"
+  [ "$(cat "${cgroupPath}/memory.high")" = "134217728" ]
+  [ "$(cat "${cgroupPath}/memory.max")" = "268435456" ]
+  [ "$(cat "${cgroupPath}/pids.max")" = "100" ]
+
+  # Check the values are applied on live update.
+  incus config set c1 raw.cgroup="$(printf 'memory.high = 67108864\npids.max = 200')"
+  [ "$(cat "${cgroupPath}/memory.high")" = "67108864" ]
+  [ "$(cat "${cgroupPath}/pids.max")" = "200" ]
+
+  # Check the values keep precedence over live updated limits.
+  incus config set c1 limits.processes=50
+  [ "$(cat "${cgroupPath}/pids.max")" = "200" ]
+
+  # Check the removed values are reset on restart.
+  incus config unset c1 raw.cgroup
+  incus restart c1 -f
+  [ "$(cat "${cgroupPath}/pids.max")" = "50" ]
+
+  incus delete -f c1
+}
@@ -0,0 +1,84 @@
+test_container_restart_policy() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${INCUS_ADDR}"
+
+  # Wait for the container init process to be replaced, returning its new PID.
+  wait_new_init() {
+    for _ in $(seq 60); do
+      NEW_INIT=$(incus info "${1}" | awk '/^PID:/ {print $2}' || true)
+      if [ -n "${NEW_INIT}" ] && [ "${NEW_INIT}" != "${2}" ]; then
+        echo "${NEW_INIT}"
+        return 0
+      fi
+
+      sleep 0.5
+    done
+
+    return 1
+  }
+
+  # Wait for the container to be stopped and stay stopped.
+  wait_stopped() {
+    for _ in $(seq 60); do
+      if [ "$(incus list -c s --format csv "${1}")" = "STOPPED" ]; then
+        sleep 2
+        [ "$(incus list -c s --format csv "${1}")" = "STOPPED" ]
+        return
+      fi
+
+      sleep 0.5
+    done
+
+    return 1
+  }
+
+  # Check invalid configurations are rejected.
+  incus init testimage c1
+  ! incus config set c1 boot.restart.policy=invalid || false
+  ! incus config set c1 boot.restart.max_retries=-1 || false
+  ! incus config set c1 boot.restart.backoff=abc || false
+
+  incus config set c1 boot.restart.policy=on-failure boot.restart.backoff=0 boot.restart.max_retries=2
+  incus start c1
+
+  # Simulate a crash and check the container is restarted.
+  OLD_INIT=$(incus info c1 | awk '/^PID:/ {print $2}')
+  kill -9 "${OLD_INIT}"
+  OLD_INIT=$(wait_new_init c1 "${OLD_INIT}")
+  [ "$(incus config get c1 volatile.restart.count)" = "1" ]
+  incus info c1 | grep -q "^Restarts: 1$"
+  [ "$(incus query /1.0/instances/c1/state | jq -r .restart_count)" = "1" ]
+
+  kill -9 "${OLD_INIT}"
+  OLD_INIT=$(wait_new_init c1 "${OLD_INIT}")
+  [ "$(incus config get c1 volatile.restart.count)" = "2" ]
+
+  # Check the container is left stopped once the maximum number of restarts is reached.
+  kill -9 "${OLD_INIT}"
+  wait_stopped c1
+
+  # Check the restart count is reset when the container is started by the user.
+  incus start c1
+  [ "$(incus config get c1 volatile.restart.count)" = "" ]
+
+  # Check a stop requested through Incus doesn't trigger a restart.
+  incus stop -f c1
+  wait_stopped c1
+
+  # Check stopping the container while it waits for the backoff cancels the restart.
+  incus config set c1 boot.restart.policy=always boot.restart.backoff=30
+  incus start c1
+  kill -9 "$(incus info c1 | awk '/^PID:/ {print $2}')"
+  wait_stopped c1
+  incus stop c1
+  [ "$(incus list -c s --format csv c1)" = "STOPPED" ]
+  ! incus stop c1 || false
+
+  # Check the never policy doesn't restart the container.
+  incus config set c1 boot.restart.policy=never
+  incus start c1
+  kill -9 "$(incus info c1 | awk '/^PID:/ {print $2}')"
+  wait_stopped c1
+
+  incus delete -f c1
+}
@@ -0,0 +1,61 @@
+test_event_history() {
+  # The history is disabled by default.
+  ! incus monitor --type=lifecycle --since="$(date -u +%Y-%m-%dT%H:%M:%SZ)" || false
+
+  before="$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+  sleep 1
+  incus config set core.event_history_size=100
+
+  # Events from before the history was enabled can't be replayed.
+  ! incus monitor --type=lifecycle --since="${before}" || false
+
+  sleep 1
+  after="$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+  sleep 1
+
+  incus profile create history1
+  incus profile create history2
+  incus profile create history3
+
+  # Replaying from a timestamp sends the events kept in the history.
+  incus monitor --type=lifecycle --format=json --since="${after}" > "${TEST_DIR}/history-all.log" &
+  monitorPID=$!
+  sleep 2
+  kill -9 "${monitorPID}" || true
+
+  for profile in history1 history2 history3; do
+    jq -e --arg source "/1.0/profiles/${profile}" 'select(.metadata.source == $source and .metadata.action == "profile-created")' "${TEST_DIR}/history-all.log"
+  done
+
+  cursor="$(jq -r 'select(.metadata.source == "/1.0/profiles/history1" and .metadata.action == "profile-created") | .cursor' "${TEST_DIR}/history-all.log")"
+  [ -n "${cursor}" ]
+
+  # Replaying from a cursor sends the events which followed it, then the new events.
+  incus monitor --type=lifecycle --format=json --since="${cursor}" > "${TEST_DIR}/history-cursor.log" &
+  monitorPID=$!
+  sleep 2
+  incus profile create history4
+  sleep 2
+  kill -9 "${monitorPID}" || true
+
+  ! jq -e 'select(.metadata.source == "/1.0/profiles/history1" and .metadata.action == "profile-created")' "${TEST_DIR}/history-cursor.log" || false
+  for profile in history2 history3 history4; do
+    jq -e --arg source "/1.0/profiles/${profile}" 'select(.metadata.source == $source and .metadata.action == "profile-created")' "${TEST_DIR}/history-cursor.log"
+  done
+
+  # Events dropped from the history can't be replayed anymore.
+  incus config set core.event_history_size=1
+  incus profile create history5
+  ! incus monitor --type=lifecycle --since="${cursor}" || false
+
+  # Invalid cursors are rejected.
+  ! incus monitor --type=lifecycle --since=invalid || false
+  ! incus monitor --type=lifecycle --since=00000000:1 || false
+
+  incus config unset core.event_history_size
+  for profile in history1 history2 history3 history4 history5; do
+    incus profile delete "${profile}"
+  done
+
+  rm -f "${TEST_DIR}/history-all.log" "${TEST_DIR}/history-cursor.log"
+}
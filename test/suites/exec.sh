@@ -37,6 +37,15 @@ test_exec() {
   stdOutURL=$(incus query  /1.0/operations/"${opID}" | jq '.metadata.output["1"]')
   incus query "${stdOutURL}" | grep -F "hello"
 
+  # Check that a file fed as stdin reaches end-of-input, with and without a pseudo-terminal.
+  printf 'echo foo\necho bar' > "${TEST_DIR}/exec-stdin"
+  timeout 30 incus exec x1 --stdin-file "${TEST_DIR}/exec-stdin" -- sh -c '! test -t 0 && cat' | grep -xF "echo bar"
+  timeout 30 incus exec x1 --stdin-file "${TEST_DIR}/exec-stdin" --pty -- sh -c 'test -t 0 && cat > /root/stdin' > /dev/null
+  [ "$(incus exec x1 -- cat /root/stdin)" = "$(cat "${TEST_DIR}/exec-stdin")" ]
+  ! incus exec x1 --stdin-file "${TEST_DIR}/exec-stdin" -n -- true || false
+  ! incus exec x1 --stdin-file "${TEST_DIR}/exec-stdin" --pty -T -- true || false
+  rm "${TEST_DIR}/exec-stdin"
+
   incus stop "${name}" --force
   incus delete "${name}"
 }
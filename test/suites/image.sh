@@ -214,3 +214,63 @@ test_image_split() {
   incus delete c
   incus image delete splitimage
 }
+
+test_image_build() {
+  ensure_import_testimage
+
+  cat > build.yaml << EOF2
+base: testimage
+args:
+  greeting: hello
+properties:
+  description: built image
+steps:
+  - run: echo \${greeting} > /greeting
+    cache: true
+  - file:
+      path: /etc/built
+      content: \${greeting}
+      mode: "0600"
+EOF2
+
+  incus image build build.yaml --alias built --arg greeting=world
+  incus image info built | grep -q "description: built image"
+  fp="$(incus image info built | awk '/^Fingerprint/ {print $2}')"
+
+  # Check the steps were applied.
+  incus launch built c
+  [ "$(incus exec c -- cat /greeting)" = "world" ]
+  [ "$(incus exec c -- cat /etc/built)" = "world" ]
+  [ "$(incus exec c -- stat -c %a /etc/built)" = "600" ]
+  incus delete -f c
+
+  # No builder instance is left behind.
+  ! incus list -c n -f csv | grep -q "^build-" || false
+
+  # An identical build reuses the image.
+  incus image build build.yaml --alias built --arg greeting=world
+  [ "$(incus image info built | awk '/^Fingerprint/ {print $2}')" = "${fp}" ]
+
+  # A different argument produces a new image and moves the alias.
+  incus image build build.yaml --alias built --arg greeting=other
+  [ "$(incus image info built | awk '/^Fingerprint/ {print $2}')" != "${fp}" ]
+
+  # Failing steps and undeclared arguments are reported.
+  cat > build-fail.yaml << EOF2
+base: testimage
+steps:
+  - exec: ["false"]
+EOF2
+
+  ! incus image build build-fail.yaml || false
+  ! incus image build build.yaml --arg unknown=1 || false
+  ! incus list -c n -f csv | grep -q "^build-" || false
+
+  # Remove the built and cached images.
+  testfp="$(incus image info testimage | awk '/^Fingerprint/ {print $2}')"
+  for img in $(incus image list -c F -f csv); do
+    [ "${img}" = "${testfp}" ] || incus image delete "${img}"
+  done
+
+  rm -f build.yaml build-fail.yaml
+}
@@ -0,0 +1,76 @@
+test_image_mirror() {
+  # shellcheck disable=2039,3043
+  local INCUS2_DIR INCUS2_ADDR
+  INCUS2_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${INCUS2_DIR}"
+  spawn_incus "${INCUS2_DIR}" true
+  INCUS2_ADDR=$(cat "${INCUS2_DIR}/incus.addr")
+
+  # Fetches happen in the background, retry the lookup until the fetched image shows up.
+  mirror_lookup() {
+    for _ in $(seq 30); do
+      if "$@" 2>/dev/null; then
+        return 0
+      fi
+
+      sleep 1
+    done
+
+    return 1
+  }
+
+  (INCUS_DIR=${INCUS2_DIR} deps/import-busybox --alias mirrorimage --public)
+  fp1="$(INCUS_DIR=${INCUS2_DIR} incus image info mirrorimage | awk '/^Fingerprint/ {print $2}')"
+
+  ! incus image info "${fp1}" || false
+
+  incus config set images.mirror.server "https://${INCUS2_ADDR}"
+  incus config set images.mirror.protocol incus
+  incus config set images.mirror.certificate "$(cat "${INCUS2_DIR}/server.crt")"
+
+  # Cache miss, the image gets fetched from the upstream server along with its alias.
+  mirror_lookup incus query /1.0/images/aliases/mirrorimage
+  [ "$(incus query /1.0/images/aliases/mirrorimage | jq -r .target)" = "${fp1}" ]
+  incus image info "${fp1}" | grep -q "^Cached: yes"
+  incus image info "${fp1}" | grep -q "^Public: yes"
+
+  # Cache hit, the same image is served.
+  [ "$(incus query /1.0/images/aliases/mirrorimage | jq -r .target)" = "${fp1}" ]
+  [ "$(incus image list -c F --format csv | grep -c "^${fp1}$")" = "1" ]
+
+  # Replace the upstream image, the alias keeps its cached target until checked again upstream.
+  (INCUS_DIR=${INCUS2_DIR} incus image delete mirrorimage)
+  (INCUS_DIR=${INCUS2_DIR} deps/import-busybox --alias mirrorimage --public --template create)
+  fp2="$(INCUS_DIR=${INCUS2_DIR} incus image info mirrorimage | awk '/^Fingerprint/ {print $2}')"
+  [ "${fp1}" != "${fp2}" ]
+  [ "$(incus query /1.0/images/aliases/mirrorimage | jq -r .target)" = "${fp1}" ]
+
+  # Deleting the local alias gets it checked again, the alias follows the upstream one.
+  incus image alias delete mirrorimage
+  mirror_lookup incus query /1.0/images/aliases/mirrorimage
+  [ "$(incus query /1.0/images/aliases/mirrorimage | jq -r .target)" = "${fp2}" ]
+  incus image info "${fp1}"
+
+  # Images can be fetched by fingerprint, evicting the least recently used ones when over the cache size.
+  (INCUS_DIR=${INCUS2_DIR} deps/import-busybox --public --template start)
+  fp3="$(INCUS_DIR=${INCUS2_DIR} incus image list -c F --format csv | grep -v -e "${fp2}")"
+  incus config set images.mirror.cache_size 1B
+  mirror_lookup incus query "/1.0/images/${fp3}"
+  [ "$(incus query "/1.0/images/${fp3}" | jq -r .fingerprint)" = "${fp3}" ]
+  ! incus image list -c F --format csv | grep -q -e "${fp1}" -e "${fp2}" || false
+
+  # Untrusted clients trigger fetches too and are served the cached public image.
+  (INCUS_DIR=${INCUS2_DIR} incus image alias create mirroruntrusted "${fp3}")
+  mirror_lookup curl -k -s --fail "https://${INCUS_ADDR}/1.0/images/aliases/mirroruntrusted" >/dev/null
+  incus image alias list --format csv | grep -q "^mirroruntrusted,"
+
+  # Cached images are still served once the upstream server is gone.
+  kill_incus "${INCUS2_DIR}"
+  [ "$(incus query "/1.0/images/${fp3}" | jq -r .fingerprint)" = "${fp3}" ]
+
+  incus config unset images.mirror.cache_size
+  incus config unset images.mirror.certificate
+  incus config unset images.mirror.protocol
+  incus config unset images.mirror.server
+  incus image delete "${fp3}"
+}
@@ -19,6 +19,10 @@ do_copy() {
   # shellcheck disable=2039,3043
   local target_pool="${2}"
 
+  # Copies from the separate dir pool are transferred with rsync.
+  # shellcheck disable=2039,3043
+  local rsync_copy="${source_pool}"
+
   # Make sure the containers don't exist
   incus rm -f c1 c2 || true
 
@@ -98,5 +102,36 @@ do_copy() {
   ! incus config show c2/snap2 || false
   ! incus storage volume snapshot show "${target_pool}" container/c2/snap2 || false
 
+  # Deleting the oldest source snapshot shouldn't prevent an incremental refresh
+  incus snapshot delete c1 snap0
+  incus exec c1 -- touch /root/testfile2
+  incus snapshot create c1 snap3
+  # shellcheck disable=2086
+  incus copy c1 c2 --refresh ${targetPoolFlag}
+  ! incus config show c2/snap0 || false
+  incus config show c2/snap1
+  incus config show c2/snap3
+  incus start c2
+  incus exec c2 -- test -f /root/testfile2
+  incus stop -f c2
+
+  # Refreshing over rsync only transfers the differences, leaving unchanged files in place
+  if [ -n "${rsync_copy}" ]; then
+    incus exec c1 -- dd if=/dev/urandom of=/root/bigfile bs=1M count=16
+    # shellcheck disable=2086
+    incus copy c1 c2 --refresh ${targetPoolFlag}
+    incus start c2
+    inode="$(incus exec c2 -- stat -c %i /root/bigfile)"
+    incus stop -f c2
+
+    incus exec c1 -- touch /root/testfile3
+    # shellcheck disable=2086
+    incus copy c1 c2 --refresh ${targetPoolFlag}
+    incus start c2
+    [ "$(incus exec c2 -- stat -c %i /root/bigfile)" = "${inode}" ]
+    incus exec c2 -- test -f /root/testfile3
+    incus stop -f c2
+  fi
+
   incus rm -f c1 c2
 }
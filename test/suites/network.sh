@@ -102,6 +102,27 @@ test_network() {
   incus network list-allocations localhost: | grep -e "/1.0/networks/inct$$" -e "/1.0/instances/nettest"
   incus network list-allocations localhost: | grep -e "${v4_addr}" -e "${v6_addr}"
 
+  # DHCP reservations
+  v4_resv="$(incus network get inct$$ ipv4.address | cut -d/ -f1)1"
+  incus network dhcp-reservation create inct$$ 10:66:6a:a4:a5:63 "${v4_resv}" --hostname printer
+  grep -q "10:66:6a:a4:a5:63,${v4_resv},printer" "${INCUS_DIR}/networks/inct$$/dnsmasq.hosts/_reservation.10-66-6a-a4-a5-63"
+  incus network list-leases inct$$ | grep RESERVATION | grep -q "${v4_resv}"
+  incus network info inct$$ | grep -q "10:66:6a:a4:a5:63: ${v4_resv} (printer)"
+  ! incus network dhcp-reservation create inct$$ 10:66:6a:a4:a5:64 "${v4_resv}" || false
+  ! incus network dhcp-reservation create inct$$ 10:66:6a:a4:a5:64 "${v4_addr}" || false
+  ! incus network dhcp-reservation create inct$$ 10:66:6a:a4:a5:64 192.0.2.1 || false
+  incus network dhcp-reservation delete inct$$ 10:66:6a:a4:a5:63
+  [ ! -e "${INCUS_DIR}/networks/inct$$/dnsmasq.hosts/_reservation.10-66-6a-a4-a5-63" ]
+
+  # DHCP lease deletion
+  incus exec nettest -- udhcpc -f -i eth0 -n -q -t5
+  grep -q " ${v4_addr} " "${INCUS_DIR}/networks/inct$$/dnsmasq.leases"
+  incus network delete-lease inct$$ "${v4_addr}"
+  ! grep -q " ${v4_addr} " "${INCUS_DIR}/networks/inct$$/dnsmasq.leases" || false
+  ! incus network delete-lease inct$$ "${v4_addr}" || false
+  ! incus network delete-lease inct$$ 10:66:6a:a4:a5:65 || false
+  ! incus network delete-lease inct$$ invalid || false
+
   incus delete nettest -f
   incus network delete inct$$
 }
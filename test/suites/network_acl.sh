@@ -98,6 +98,19 @@ EOF
  incus network acl show testacl | grep -c2 'state: enabled' # Default state enabled for new rules.
  incus network acl show testacl | grep "description: Test ACL rule description"
 
+ # ACL rule priorities.
+ ! incus network acl rule add testacl ingress action=allow priority=101 || false # Invalid priority
+ ! incus network acl rule add testacl ingress action=allow priority=foo || false # Invalid priority
+ incus network acl rule add testacl ingress action=drop source=192.168.2.0/24
+ incus network acl rule add testacl ingress action=allow source=192.168.2.10/32 priority=10
+ incus network acl show testacl | grep "priority: 10"
+ ! incus network acl rule move testacl ingress up action=allow || false # Fail if match multiple rules.
+ ! incus network acl rule move testacl ingress up priority=10 || false # Already evaluated first.
+ incus network acl rule move testacl ingress up source=192.168.2.0/24
+ [ "$(incus query /1.0/network-acls/testacl | jq '.ingress[] | select(.source == "192.168.2.0/24") | .priority')" = "11" ]
+ incus network acl rule move testacl ingress down source=192.168.2.0/24
+ [ "$(incus query /1.0/network-acls/testacl | jq '.ingress[] | select(.source == "192.168.2.0/24") | .priority')" = "9" ]
+
  # ACL rule removal.
  incus network acl rule add testacl ingress action=allow source=192.168.1.3/32 protocol=tcp destination=192.168.1.1-192.168.1.3 destination_port=22,2222-2223 description="removal rule test"
  ! incus network acl rule remove testacl ingress || false # Fail if match multiple rules with no filter and no --force.
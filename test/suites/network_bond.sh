@@ -0,0 +1,55 @@
+test_network_bond() {
+  ensure_has_localhost_remote "${INCUS_ADDR}"
+
+  bondName="incbond$$"
+  member1="incbm1$$"
+  member2="incbm2$$"
+  member3="incbm3$$"
+
+  ip link add "${member1}" type dummy
+  ip link add "${member2}" type dummy
+  ip link add "${member3}" type dummy
+
+  # Check that invalid configurations are rejected.
+  ! incus network create "${bondName}" --type=bond || false
+  ! incus network create "${bondName}" --type=bond bond.members="${member1}" bond.mode=invalid || false
+  ! incus network create "${bondName}" --type=bond bond.members="${member1}" bond.lacp_rate=fast || false
+  ! incus network create "${bondName}" --type=bond bond.members="${member1},${member1}" || false
+  ! incus network create "${bondName}" --type=bond bond.members=incbmmissing || false
+  ! incus network show "${bondName}" || false
+
+  # Create a bond and check the members were enslaved.
+  incus network create "${bondName}" --type=bond bond.members="${member1},${member2}" bond.mode=802.3ad bond.lacp_rate=fast mtu=1400
+  [ -d "/sys/class/net/${bondName}/bonding" ]
+  grep -q "^802.3ad" "/sys/class/net/${bondName}/bonding/mode"
+  grep -q "^fast" "/sys/class/net/${bondName}/bonding/lacp_rate"
+  [ "$(cat "/sys/class/net/${bondName}/mtu")" = "1400" ]
+  [ "$(basename "$(readlink "/sys/class/net/${member1}/master")")" = "${bondName}" ]
+  [ "$(basename "$(readlink "/sys/class/net/${member2}/master")")" = "${bondName}" ]
+  incus network info "${bondName}" | grep -q "Lower devices state:"
+
+  # Check that members of another bond can't be reused.
+  ! incus network create "${bondName}b" --type=bond bond.members="${member1}" || false
+
+  # Change the members and mode, recreating the bond.
+  incus network set "${bondName}" bond.lacp_rate= bond.mode=active-backup bond.members="${member2},${member3}"
+  grep -q "^active-backup" "/sys/class/net/${bondName}/bonding/mode"
+  [ ! -e "/sys/class/net/${member1}/master" ]
+  [ "$(basename "$(readlink "/sys/class/net/${member3}/master")")" = "${bondName}" ]
+  incus network info "${bondName}" | grep -q "Active device:"
+
+  # Check that the bond configuration can't be changed while in use.
+  incus network create "${bondName}p" --type=macvlan parent="${bondName}"
+  ! incus network set "${bondName}" bond.members="${member2}" || false
+  incus network delete "${bondName}p"
+
+  # Delete the bond and check the members were released.
+  incus network delete "${bondName}"
+  [ ! -e "/sys/class/net/${bondName}" ]
+  [ ! -e "/sys/class/net/${member2}/master" ]
+  [ ! -e "/sys/class/net/${member3}/master" ]
+
+  ip link delete "${member1}"
+  ip link delete "${member2}"
+  ip link delete "${member3}"
+}
@@ -156,3 +156,57 @@ test_network_forward() {
     ! nft -nn list chain inet incus "fwdpstrt.${netName}" || false
   fi
 }
+
+test_network_forward_http() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${INCUS_ADDR}"
+
+  netName=inct$$
+
+  incus network create "${netName}" \
+        ipv4.address=192.0.2.1/24 \
+        ipv6.address=none
+
+  incus network forward create "${netName}" 198.51.100.1
+
+  # Check invalid HTTP port specifications are rejected.
+  ! incus network forward port add "${netName}" 198.51.100.1 http 80 192.0.2.2 || false
+  ! incus network forward port add "${netName}" 198.51.100.1 http 80-81 192.0.2.2 --hostnames c1.example.com || false
+  ! incus network forward port add "${netName}" 198.51.100.1 http 80 192.0.2.2 --hostnames "c1.*.example.com" || false
+  ! incus network forward port add "${netName}" 198.51.100.1 tcp 80 192.0.2.2 --hostnames c1.example.com || false
+
+  # Check several HTTP port specifications can share a listen port with different hostnames.
+  incus network forward port add "${netName}" 198.51.100.1 http 80 192.0.2.2 --hostnames c1.example.com
+  incus network forward port add "${netName}" 198.51.100.1 http 80 192.0.2.3 --hostnames "*.example.net"
+  ! incus network forward port add "${netName}" 198.51.100.1 http 80 192.0.2.3 --hostnames C1.example.com || false
+  ! incus network forward port add "${netName}" 198.51.100.1 https 80 192.0.2.3 --hostnames c3.example.com || false
+  ! incus network forward port add "${netName}" 198.51.100.1 tcp 80 192.0.2.3 || false
+  ! incus network forward set "${netName}" 198.51.100.1 target_address=192.0.2.4 || false
+  incus network forward show "${netName}" 198.51.100.1 | grep -F "hostnames: '*.example.net'"
+
+  # Spin up a web server in two containers.
+  for i in 2 3; do
+    incus launch testimage "c${i}" -n "${netName}"
+    incus exec "c${i}" -- ip addr add "192.0.2.${i}/24" dev eth0
+    incus exec "c${i}" -- sh -c "while true; do printf 'HTTP/1.0 200 OK\r\n\r\nc${i}\n' | nc -l -p 80 >/dev/null; done" </dev/null >/dev/null 2>&1 &
+  done
+
+  sleep 1
+
+  # Check the requests are routed based on their Host header.
+  [ "$(curl -s -H "Host: c1.example.com" http://198.51.100.1/)" = "c2" ]
+  [ "$(curl -s -H "Host: www.example.net" http://198.51.100.1/)" = "c3" ]
+  [ "$(curl -s -o /dev/null -w "%{http_code}" -H "Host: c3.example.com" http://198.51.100.1/)" = "404" ]
+
+  # Check removing a port specification updates the routing.
+  incus network forward port remove "${netName}" 198.51.100.1 http 80 --hostnames "*.example.net"
+  [ "$(curl -s -o /dev/null -w "%{http_code}" -H "Host: www.example.net" http://198.51.100.1/)" = "404" ]
+  [ "$(curl -s -H "Host: c1.example.com" http://198.51.100.1/)" = "c2" ]
+
+  # Check deleting the forward stops the listener.
+  incus network forward delete "${netName}" 198.51.100.1
+  ! curl -s -m 5 -H "Host: c1.example.com" http://198.51.100.1/ || false
+
+  incus delete -f c2 c3
+  incus network delete "${netName}"
+}
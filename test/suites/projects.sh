@@ -950,6 +950,8 @@ test_projects_restrictions() {
   ! incus profile set default "raw.idmap=both 0 0" || false
   ! incus init testimage c1 -c "raw.idmap=both 0 0" || false
   ! incus init testimage c1 -c volatile.uuid="foo" || false
+  ! incus init testimage c1 -c linux.network_namespace=foo || false
+  ! incus init testimage c1 -c raw.cgroup="pids.max = 10" || false
 
   # It's not possible to create privileged containers.
   ! incus profile set default security.privileged=true || false
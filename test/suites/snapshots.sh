@@ -367,6 +367,37 @@ test_snap_schedule() {
   incus rm -f c1 c2 c3 c4 c5
 }
 
+test_snap_disabled() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${INCUS_ADDR}"
+
+  incus launch testimage c1
+  incus snapshot create c1 snap0
+
+  # Check manual snapshots are refused.
+  incus config set c1 snapshots.enabled=false
+  ! incus snapshot create c1 snap1 || false
+  incus snapshot create c1 snap1 2>&1 | grep -F "Snapshots are disabled"
+  ! incus info c1 | grep -q snap1 || false
+
+  # Check scheduled snapshots are skipped.
+  incus config set c1 snapshots.schedule='@startup'
+  incus restart c1 -f
+  [ "$(incus query /1.0/instances/c1/snapshots | jq 'length')" = "1" ]
+
+  # Check existing snapshots can still be used.
+  incus snapshot restore c1 snap0
+  incus snapshot delete c1 snap0
+  [ "$(incus query /1.0/instances/c1/snapshots | jq 'length')" = "0" ]
+
+  # Check snapshots can be taken again once re-enabled.
+  incus config unset c1 snapshots.enabled
+  incus snapshot create c1 snap1
+  incus info c1 | grep -q snap1
+
+  incus rm -f c1
+}
+
 test_snap_volume_db_recovery() {
   # shellcheck disable=2039,3043
   local incus_backend
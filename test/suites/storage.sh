@@ -38,6 +38,14 @@ test_storage() {
   incus storage volume set "$storage_pool" "$storage_volume" user.abc def
   [ "$(incus storage volume get "$storage_pool" "$storage_volume" user.abc)" = "def" ]
 
+  # Scrub the pool (only zfs and btrfs support it)
+  if [ "${incus_backend}" = "zfs" ] || [ "${incus_backend}" = "btrfs" ]; then
+    incus storage scrub "$storage_pool"
+    incus storage info "$storage_pool" | grep -q 'scrub status: finished'
+  else
+    ! incus storage scrub "$storage_pool" || false
+  fi
+
   incus storage volume delete "$storage_pool" "$storage_volume"
 
   # Test copying pool volume.* key to the volume with prefix stripped at volume creation time
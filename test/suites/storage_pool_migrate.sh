@@ -0,0 +1,65 @@
+test_storage_pool_migrate() {
+  ensure_import_testimage
+
+  # shellcheck disable=2039,3043
+  local incus_backend src dst
+  incus_backend=$(storage_backend "$INCUS_DIR")
+  src="incustest-$(basename "${INCUS_DIR}")-src"
+  dst="incustest-$(basename "${INCUS_DIR}")-dst"
+
+  incus storage create "${src}" "${incus_backend}"
+  incus storage create "${dst}" dir
+
+  # Add a profile using the source pool for its root disk.
+  incus profile create migrate
+  incus profile device add migrate root disk path=/ pool="${src}"
+
+  # Fill the source pool with an instance, a custom volume and snapshots.
+  incus launch testimage c1 -p default -p migrate
+  incus snapshot create c1 snap0
+  incus storage volume create "${src}" vol1
+  incus storage volume snapshot create "${src}" vol1 snap0
+  incus storage volume attach "${src}" vol1 c1 /mnt
+  incus exec c1 -- sh -c "echo foobar > /mnt/testfile"
+
+  # Invalid requests are rejected.
+  ! incus storage migrate "${src}" "${src}" || false
+  ! incus storage migrate "${src}" invalid || false
+
+  # Running instances are only handled when requested.
+  ! incus storage migrate "${src}" "${dst}" || false
+
+  # A volume failing to move stops the migration, leaving it on the source pool only and the instances running.
+  incus storage volume create "${src}" vol2
+  incus storage volume attach "${src}" vol2 c1 /mnt2
+  touch "${INCUS_DIR}/storage-pools/${dst}/custom/default_vol2"
+  ! incus storage migrate "${src}" "${dst}" --stop --delete-source || false
+  rm -f "${INCUS_DIR}/storage-pools/${dst}/custom/default_vol2"
+  incus storage show "${src}"
+  [ "$(incus list c1 -c s -f csv)" = "RUNNING" ]
+  [ "$(incus config device get c1 root pool)" = "${dst}" ]
+  [ "$(incus config device get c1 vol2 pool)" = "${src}" ]
+  incus storage volume show "${src}" vol2
+  ! incus storage volume show "${dst}" vol2 || false
+
+  # Running the migration again moves the remaining volumes.
+  incus storage migrate "${src}" "${dst}" --stop --delete-source
+
+  # Everything moved to the target pool and the source pool is gone.
+  ! incus storage show "${src}" || false
+  [ "$(incus list c1 -c s -f csv)" = "RUNNING" ]
+  [ "$(incus config device get c1 root pool)" = "${dst}" ]
+  [ "$(incus config device get c1 vol1 pool)" = "${dst}" ]
+  [ "$(incus config device get c1 vol2 pool)" = "${dst}" ]
+  [ "$(incus profile device get migrate root pool)" = "${dst}" ]
+  incus storage volume show "${dst}" container/c1/snap0
+  incus storage volume show "${dst}" vol1/snap0
+  [ "$(incus exec c1 -- cat /mnt/testfile)" = "foobar" ]
+
+  # Clean up.
+  incus delete -f c1
+  incus storage volume delete "${dst}" vol1
+  incus storage volume delete "${dst}" vol2
+  incus profile delete migrate
+  incus storage delete "${dst}"
+}
@@ -83,6 +83,15 @@ test_storage_volume_snapshots() {
   # is attached to the container
   ! incus storage volume snapshot restore "${storage_pool}" "${storage_volume}" snap0 || false
 
+  # Restoring into a new volume leaves the attached volume untouched
+  incus storage volume snapshot restore "${storage_pool}" "${storage_volume}" foo --target-new "${storage_volume}-foo"
+  ! incus storage volume snapshot restore "${storage_pool}" "${storage_volume}" foo --target-new "${storage_volume}-foo" || false
+  ! incus exec c1 -- test -f /mnt/testfile || false
+  incus storage volume attach "${storage_pool}" "${storage_volume}-foo" c1 /mnt2
+  [ "$(incus exec c1 -- cat /mnt2/testfile)" = 'foobar' ]
+  incus storage volume detach "${storage_pool}" "${storage_volume}-foo" c1
+  incus storage volume delete "${storage_pool}" "${storage_volume}-foo"
+
   incus stop -f c1
   incus storage volume snapshot restore "${storage_pool}" "${storage_volume}" foo
 
@@ -119,6 +128,9 @@ test_storage_volume_snapshots() {
   incus storage volume create "${storage_pool}" "vol1" --type block
   incus storage volume snapshot create "${storage_pool}" "vol1" "snap0"
   incus storage volume snapshot restore "${storage_pool}" "vol1" "snap0"
+  incus storage volume snapshot restore "${storage_pool}" "vol1" "snap0" --target-new "vol2"
+  incus storage volume show "${storage_pool}" "vol2" | grep -Fx "content_type: block"
+  incus storage volume delete "${storage_pool}" "vol2"
   incus storage volume delete "${storage_pool}" "vol1"
 
   # Check filesystem specific config keys cannot be applied on type block volumes.
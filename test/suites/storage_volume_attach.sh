@@ -97,8 +97,23 @@ test_storage_volume_attach() {
   incus restart --force c2
   incus exec c2 -- stat -c '%a' /testvolume | grep 700
 
+  # check that the volume lists the instances and snapshots referencing it
+  incus snapshot create c2 snap0
+  incus storage volume info "incustest-$(basename "${INCUS_DIR}")" testvolume | grep -F -- '- instance "c1"'
+  incus storage volume info "incustest-$(basename "${INCUS_DIR}")" testvolume | grep -F -- '- instance "c2"'
+  incus storage volume info "incustest-$(basename "${INCUS_DIR}")" testvolume | grep -F -- '- instance snapshot "c2/snap0"'
+
+  # check that deleting the volume while attached reports the instances using it
+  ! incus storage volume delete "incustest-$(basename "${INCUS_DIR}")" testvolume || false
+  incus storage volume delete "incustest-$(basename "${INCUS_DIR}")" testvolume 2>&1 | grep -F 'still in use by' | grep -F 'instance "c1"' | grep -F 'instance "c2"'
+
+  # snapshots still referencing the volume don't prevent its deletion
+  incus storage volume detach "incustest-$(basename "${INCUS_DIR}")" testvolume c1
+  incus storage volume detach "incustest-$(basename "${INCUS_DIR}")" testvolume c2
+  [ "$(incus query "/1.0/storage-pools/incustest-$(basename "${INCUS_DIR}")/volumes/custom/testvolume" | jq '.used_by | length')" = "1" ]
+  incus storage volume delete "incustest-$(basename "${INCUS_DIR}")" testvolume
+
   # delete containers
   incus delete -f c1
   incus delete -f c2
-  incus storage volume delete "incustest-$(basename "${INCUS_DIR}")" testvolume
 }
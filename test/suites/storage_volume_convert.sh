@@ -0,0 +1,109 @@
+test_storage_volume_convert() {
+  ensure_import_testimage
+
+  # shellcheck disable=2039,3043
+  local incus_backend pool
+  incus_backend=$(storage_backend "$INCUS_DIR")
+  pool=$(incus profile device get default root pool)
+
+  incus storage volume create "${pool}" vol1
+  if [ "${incus_backend}" != "dir" ]; then
+    incus storage volume set "${pool}" vol1 size=64MiB
+  fi
+
+  # Fill the volume with some data.
+  incus launch testimage c1
+  incus storage volume attach "${pool}" vol1 c1 /mnt
+  echo foobar > "${TEST_DIR}/testfile"
+  incus file push "${TEST_DIR}/testfile" c1/mnt/testfile
+
+  # Attached volumes can't be converted.
+  ! incus storage volume convert "${pool}" vol1 --content-type=block || false
+  incus storage volume detach "${pool}" vol1 c1
+
+  # Invalid content types are rejected.
+  ! incus storage volume convert "${pool}" vol1 --content-type=iso || false
+
+  # Convert the filesystem volume to a block volume.
+  incus storage volume convert "${pool}" vol1 --content-type=block
+  incus storage volume show "${pool}" vol1 | grep -q '^content_type: block$'
+  ! incus storage volume convert "${pool}" vol1 --content-type=block || false
+
+  # Convert it back and check the data was preserved.
+  incus storage volume convert "${pool}" vol1 --content-type=filesystem
+  incus storage volume show "${pool}" vol1 | grep -q '^content_type: filesystem$'
+  incus storage volume attach "${pool}" vol1 c1 /mnt
+  [ "$(incus exec c1 -- cat /mnt/testfile)" = "foobar" ]
+  incus storage volume detach "${pool}" vol1 c1
+
+  # Volumes whose temporary names are in use can't be converted.
+  incus storage volume create "${pool}" vol1.convert-old
+  ! incus storage volume convert "${pool}" vol1 --content-type=block || false
+  incus storage volume show "${pool}" vol1 | grep -q '^content_type: filesystem$'
+  incus storage volume delete "${pool}" vol1.convert-old
+
+  # Volumes with snapshots can't be converted.
+  incus storage volume snapshot create "${pool}" vol1 snap0
+  ! incus storage volume convert "${pool}" vol1 --content-type=block || false
+  incus storage volume snapshot delete "${pool}" vol1 snap0
+
+  # Block volumes which don't directly hold a filesystem can't be converted.
+  incus storage volume create "${pool}" vol2 --type=block size=64MiB
+  ! incus storage volume convert "${pool}" vol2 --content-type=filesystem || false
+  incus storage volume show "${pool}" vol2 | grep -q '^content_type: block$'
+
+  incus storage volume delete "${pool}" vol1
+  incus storage volume delete "${pool}" vol2
+
+  # Convert volumes back and forth on every available storage driver.
+  # shellcheck disable=2039,3043
+  local driver pool_base
+  pool_base="incustest-$(basename "${INCUS_DIR}")-convert"
+
+  for driver in $(available_storage_backends); do
+    case "${driver}" in
+      btrfs|zfs)
+        incus storage create "${pool_base}-${driver}" "${driver}" size=1GiB
+        ;;
+      ceph)
+        incus storage create "${pool_base}-${driver}" ceph volume.size=64MiB ceph.osd.pg_num=16
+        ;;
+      lvm)
+        incus storage create "${pool_base}-${driver}" lvm volume.size=64MiB
+        ;;
+      linstor)
+        incus storage create "${pool_base}-${driver}" linstor volume.size=1GiB linstor.resource_group.place_count=1
+        ;;
+      *)
+        incus storage create "${pool_base}-${driver}" "${driver}"
+        ;;
+    esac
+
+    incus storage volume create "${pool_base}-${driver}" vol1
+    if [ "${driver}" != "dir" ]; then
+      incus storage volume set "${pool_base}-${driver}" vol1 size=64MiB
+    fi
+
+    incus storage volume attach "${pool_base}-${driver}" vol1 c1 /mnt
+    incus file push "${TEST_DIR}/testfile" c1/mnt/testfile
+    incus storage volume detach "${pool_base}-${driver}" vol1 c1
+
+    incus storage volume convert "${pool_base}-${driver}" vol1 --content-type=block
+    incus storage volume show "${pool_base}-${driver}" vol1 | grep -q '^content_type: block$'
+    incus storage volume convert "${pool_base}-${driver}" vol1 --content-type=filesystem
+    incus storage volume show "${pool_base}-${driver}" vol1 | grep -q '^content_type: filesystem$'
+
+    # Only the converted volume is left.
+    [ "$(incus storage volume list "${pool_base}-${driver}" -c n -f csv)" = "vol1" ]
+
+    incus storage volume attach "${pool_base}-${driver}" vol1 c1 /mnt
+    [ "$(incus exec c1 -- cat /mnt/testfile)" = "foobar" ]
+    incus storage volume detach "${pool_base}-${driver}" vol1 c1
+
+    incus storage volume delete "${pool_base}-${driver}" vol1
+    incus storage delete "${pool_base}-${driver}"
+  done
+
+  incus delete -f c1
+  rm -f "${TEST_DIR}/testfile"
+}
@@ -0,0 +1,25 @@
+test_storage_volume_soft_limit() {
+  # shellcheck disable=2039,3043
+  local pool
+  pool=$(incus profile device get default root pool)
+
+  incus storage volume create "${pool}" vol1 size=64MiB
+
+  # Soft limits can be a percentage or a size below the volume size.
+  incus storage volume set "${pool}" vol1 size.soft_limit=80%
+  [ "$(incus storage volume get "${pool}" vol1 size.soft_limit)" = "80%" ]
+  incus storage volume set "${pool}" vol1 size.soft_limit=32MiB
+  [ "$(incus storage volume get "${pool}" vol1 size.soft_limit)" = "32MiB" ]
+
+  # Soft limits at or above the volume size are rejected.
+  ! incus storage volume set "${pool}" vol1 size.soft_limit=64MiB || false
+  ! incus storage volume set "${pool}" vol1 size.soft_limit=100% || false
+  ! incus storage volume set "${pool}" vol1 size.soft_limit=0% || false
+  ! incus storage volume set "${pool}" vol1 size.soft_limit=foo || false
+
+  # The volume can't be shrunk below its soft limit.
+  ! incus storage volume set "${pool}" vol1 size=16MiB || false
+  incus storage volume unset "${pool}" vol1 size.soft_limit
+
+  incus storage volume delete "${pool}" vol1
+}